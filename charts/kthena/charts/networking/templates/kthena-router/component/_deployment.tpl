@@ -0,0 +1,255 @@
+{{/*
+Deployment of a kthena-router instance.
+It takes a dict with the following keys:
+  root:          the root context
+  name:          name of the Deployment, also used as the component label
+  replicas:      number of router replicas
+  profile:       name of the router profile
+  routeSelector: label selector of the ModelRoutes served by this instance, empty means all
+  webhook:       whether this instance runs the admission webhook server
+*/}}
+{{- define "kthena-router.deployment" -}}
+{{- $root := .root }}
+{{- $webhook := .webhook }}
+{{- $profile := get $root.Values.kthenaRouter.profiles .profile }}
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .name }}
+  namespace: {{ $root.Release.Namespace }}
+  labels:
+    app.kubernetes.io/component: {{ .name }}
+    {{- include "kthena.labels" $root | nindent 4 }}
+spec:
+  replicas: {{ .replicas }}
+  selector:
+    matchLabels:
+      app.kubernetes.io/component: {{ .name }}
+      {{- include "kthena.selectorLabels" $root | nindent 6 }}
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/component: {{ .name }}
+        {{- include "kthena.labels" $root | nindent 8 }}
+    spec:
+      containers:
+        - name: kthena-router
+          image: "{{ $root.Values.kthenaRouter.image.repository }}:{{ $root.Values.kthenaRouter.image.tag }}"
+          imagePullPolicy: {{ $root.Values.kthenaRouter.image.pullPolicy }}
+          args:
+            - --port={{ $root.Values.kthenaRouter.port }}
+            - --debug-port={{ $root.Values.kthenaRouter.debugPort }}
+            {{- if $root.Values.kthenaRouter.stateAPIPort }}
+            - --state-api-port={{ $root.Values.kthenaRouter.stateAPIPort }}
+            {{- end }}
+            {{- if $root.Values.kthenaRouter.grpcPort }}
+            - --grpc-port={{ $root.Values.kthenaRouter.grpcPort }}
+            {{- end }}
+            - --enable-webhook={{ $webhook }}
+            - --enable-gateway-api={{ $root.Values.kthenaRouter.gatewayAPI.enabled }}
+            - --profile={{ .profile }}
+            {{- if .routeSelector }}
+            - --model-route-selector={{ .routeSelector }}
+            {{- end }}
+            {{- range include "kthena-router.profileArgs" $profile | fromYamlArray }}
+            - {{ . }}
+            {{- end }}
+            {{- if $root.Values.kthenaRouter.streamHeartbeatInterval }}
+            - --stream-heartbeat-interval={{ $root.Values.kthenaRouter.streamHeartbeatInterval }}
+            {{- end }}
+            {{- if $root.Values.kthenaRouter.metricsScrapeInterval }}
+            - --metrics-scrape-interval={{ $root.Values.kthenaRouter.metricsScrapeInterval }}
+            {{- end }}
+            {{- if $root.Values.kthenaRouter.gatewayAPI.enabled }}
+            - --enable-gateway-api-inference-extension={{ $root.Values.kthenaRouter.gatewayAPI.inferenceExtension }}
+            {{- end }}
+          {{- if $webhook }}
+            - --webhook-port={{ $root.Values.kthenaRouter.webhook.port }}
+            - --webhook-tls-cert-file={{ $root.Values.kthenaRouter.webhook.tls.certFile }}
+            - --webhook-tls-private-key-file={{ $root.Values.kthenaRouter.webhook.tls.keyFile }}
+            - --cert-secret-name={{ $root.Values.kthenaRouter.webhook.tls.secretName }}
+            - --webhook-service-name={{ $root.Values.kthenaRouter.webhook.tls.serviceName }}
+          {{- end }}
+          {{- with ($root.Values.global).tls }}
+          {{- with .minVersion }}
+            - --tls-min-version={{ . }}
+          {{- end }}
+          {{- with .cipherSuites }}
+            - --tls-cipher-suites={{ join "," . }}
+          {{- end }}
+          {{- end }}
+          {{- with $root.Values.kthenaRouter.watchNamespace }}
+            - --watch-namespace={{ . }}
+          {{- end }}
+          {{- with $root.Values.kthenaRouter.podSelector }}
+            - "--pod-selector={{ . }}"
+          {{- end }}
+          {{- if $root.Values.kthenaRouter.kubeAPIQPS }}
+            - --kube-api-qps={{ $root.Values.kthenaRouter.kubeAPIQPS }}
+          {{- end }}
+          {{- if $root.Values.kthenaRouter.kubeAPIBurst }}
+            - --kube-api-burst={{ $root.Values.kthenaRouter.kubeAPIBurst }}
+          {{- end }}
+          {{- if and (eq $root.Values.global.certManagementMode "cert-manager") $root.Values.kthenaRouter.tls.enabled }}
+            - --tls-cert=/etc/router-tls/tls.crt
+            - --tls-key=/etc/router-tls/tls.key
+          {{- with $root.Values.kthenaRouter.tls.clientCAFile }}
+            - --tls-client-ca-file={{ . }}
+          {{- end }}
+          {{- end }}
+          ports:
+            - containerPort: {{ $root.Values.kthenaRouter.port }}
+              name: http
+          {{- if $webhook }}
+            - containerPort: {{ $root.Values.kthenaRouter.webhook.port }}
+              name: webhook
+          {{- end }}
+          {{- if $root.Values.kthenaRouter.grpcPort }}
+            - containerPort: {{ $root.Values.kthenaRouter.grpcPort }}
+              name: grpc
+          {{- end }}
+          env:
+          {{- with ($root.Values.global).fips140 }}
+            - name: GODEBUG
+              value: fips140={{ . }}
+          {{- end }}
+            - name: POD_NAMESPACE
+              valueFrom:
+                fieldRef:
+                  fieldPath: metadata.namespace
+            - name: POD_NAME
+              valueFrom:
+                fieldRef:
+                  fieldPath: metadata.name
+            - name: POD_UID
+              valueFrom:
+                fieldRef:
+                  fieldPath: metadata.uid
+            - name: REDIS_HOST
+              valueFrom:
+                configMapKeyRef:
+                  name: redis-config
+                  key: REDIS_HOST
+                  optional: true
+            - name: REDIS_PORT
+              valueFrom:
+                configMapKeyRef:
+                  name: redis-config
+                  key: REDIS_PORT
+                  optional: true
+            - name: REDIS_PASSWORD
+              valueFrom:
+                secretKeyRef:
+                  name: redis-secret
+                  key: password
+                  optional: true
+            # Fairness scheduling configuration
+            - name: ENABLE_FAIRNESS_SCHEDULING
+              value: {{ $root.Values.kthenaRouter.fairness.enabled | quote }}
+            {{- if $root.Values.kthenaRouter.fairness.enabled }}
+            - name: FAIRNESS_WINDOW_SIZE
+              value: {{ $root.Values.kthenaRouter.fairness.windowSize | quote }}
+            - name: FAIRNESS_INPUT_TOKEN_WEIGHT
+              value: {{ $root.Values.kthenaRouter.fairness.inputTokenWeight | quote }}
+            - name: FAIRNESS_OUTPUT_TOKEN_WEIGHT
+              value: {{ $root.Values.kthenaRouter.fairness.outputTokenWeight | quote }}
+            {{- end }}
+            # Access log configuration
+            - name: ACCESS_LOG_ENABLED
+              value: {{ $root.Values.kthenaRouter.accessLog.enabled | quote }}
+            - name: ACCESS_LOG_FORMAT
+              value: {{ $root.Values.kthenaRouter.accessLog.format | quote }}
+            - name: ACCESS_LOG_OUTPUT
+              value: {{ $root.Values.kthenaRouter.accessLog.output | quote }}
+          {{- if and $profile $profile.resource }}
+          resources: {{- toYaml $profile.resource | nindent 12 }}
+          {{- else }}
+          resources: {{- toYaml $root.Values.kthenaRouter.resource | nindent 12 }}
+          {{- end }}
+          livenessProbe:
+            httpGet:
+              path: /healthz
+              port: {{ $root.Values.kthenaRouter.port }}
+              {{- if and (eq $root.Values.global.certManagementMode "cert-manager") $root.Values.kthenaRouter.tls.enabled }}
+              scheme: HTTPS
+              {{- end }}
+            initialDelaySeconds: 1
+            periodSeconds: 5
+          readinessProbe:
+            httpGet:
+              path: /healthz
+              port: {{ $root.Values.kthenaRouter.port }}
+              {{- if and (eq $root.Values.global.certManagementMode "cert-manager") $root.Values.kthenaRouter.tls.enabled }}
+              scheme: HTTPS
+              {{- end }}
+            initialDelaySeconds: 1
+            periodSeconds: 5
+          volumeMounts:
+          - name: scheduler-config
+            mountPath: /etc/config/routerConfiguration.yaml
+            subPath: routerConfiguration
+          {{- if and (eq $root.Values.global.certManagementMode "cert-manager") $root.Values.kthenaRouter.tls.enabled }}
+          - name: router-tls-certs
+            mountPath: /etc/tls
+            readOnly: true
+          {{- end }}
+          {{- if $webhook }}
+          - name: webhook-certs
+            mountPath: /etc/tls
+            readOnly: true
+          {{- end }}
+          {{- range $root.Values.kthenaRouter.tokenizerVolumes }}
+          - name: tokenizer-{{ .name }}
+            mountPath: /etc/tokenizers/{{ .name }}
+            readOnly: true
+          {{- end }}
+      volumes:
+        - name: scheduler-config
+          configMap:
+            name: kthena-router-config
+        {{- if and (eq $root.Values.global.certManagementMode "cert-manager") $root.Values.kthenaRouter.tls.enabled }}
+        - name: router-tls-certs
+          secret:
+            secretName: {{ $root.Values.kthenaRouter.tls.secretName }}
+        {{- end }}
+        {{- if $webhook }}
+        - name: webhook-certs
+          secret:
+            secretName: {{ $root.Values.kthenaRouter.webhook.tls.secretName }}
+            optional: true
+        {{- end }}
+        {{- range $root.Values.kthenaRouter.tokenizerVolumes }}
+        - name: tokenizer-{{ .name }}
+          {{- toYaml (omit . "name") | nindent 10 }}
+        {{- end }}
+      serviceAccountName: kthena-router
+{{- end }}
+
+{{/*
+Router flags overriding the defaults of a profile, as a YAML list. It takes the profile of the values, or nil.
+*/}}
+{{- define "kthena-router.profileArgs" -}}
+{{- $args := list }}
+{{- with . }}
+{{- if .maxConcurrentRequests }}
+{{- $args = append $args (printf "--max-concurrent-requests=%v" .maxConcurrentRequests) }}
+{{- end }}
+{{- if .streamBufferSize }}
+{{- $args = append $args (printf "--stream-buffer-size=%v" .streamBufferSize) }}
+{{- end }}
+{{- if .maxBufferedBodyBytes }}
+{{- $args = append $args (printf "--max-buffered-body-bytes=%d" (.maxBufferedBodyBytes | int64)) }}
+{{- end }}
+{{- if .memoryWatermarkBytes }}
+{{- $args = append $args (printf "--memory-watermark-bytes=%d" (.memoryWatermarkBytes | int64)) }}
+{{- end }}
+{{- if .features }}
+{{- $features := list }}
+{{- range $feature, $enabled := .features }}
+{{- $features = append $features (printf "%s=%t" $feature $enabled) }}
+{{- end }}
+{{- $args = append $args (printf "--profile-features=%s" (join "," $features)) }}
+{{- end }}
+{{- end }}
+{{- toYaml $args }}
+{{- end }}
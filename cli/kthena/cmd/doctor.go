@@ -0,0 +1,720 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/volcano-sh/kthena/client-go/clientset/versioned"
+	networkingv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	workloadv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/utils/ptr"
+)
+
+var (
+	doctorNamespace     string
+	doctorRouterURL     string
+	doctorAPIKey        string
+	doctorEndToEnd      bool
+	doctorTestNamespace string
+	doctorMockImage     string
+	doctorTimeout       time.Duration
+)
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check a Kthena installation",
+	Long: `Run live checks against the Kthena installation of the current kubeconfig context, and print
+how to fix the checks which fail.
+
+The checks cover:
+- the CRDs: installed, established, serving v1alpha1 and up to date with this CLI
+- the components: the Kthena deployments are available and run the same version
+- the webhooks: their services have ready endpoints, their CA bundle is set, their rules match
+  served resources, and a ModelRoute created in dry-run mode is admitted
+- the gateway: the router is ready and lists the models, with --router-url
+- a sample route end to end: a mock model server with its ModelServer and ModelRoute is deployed,
+  a chat completion is sent through the router and the resources are deleted, with --e2e
+
+The command fails if any check fails.
+
+Examples:
+  kthena doctor
+  kthena doctor -n kthena-system --router-url http://localhost:8080
+  kthena doctor --router-url http://localhost:8080 --e2e`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().StringVarP(&doctorNamespace, "namespace", "n", "kthena-system", "Namespace Kthena is installed in")
+	doctorCmd.Flags().StringVar(&doctorRouterURL, "router-url", "", "URL of the kthena-router, e.g. http://localhost:8080 with a port-forward of its service")
+	doctorCmd.Flags().StringVar(&doctorAPIKey, "api-key", "", "API key sent to the router when it requires authentication")
+	doctorCmd.Flags().BoolVar(&doctorEndToEnd, "e2e", false, "Deploy a mock model server and a route to it, and send a request through the router")
+	doctorCmd.Flags().StringVar(&doctorTestNamespace, "test-namespace", "default", "Namespace of the resources created by the dry-run and end-to-end checks")
+	doctorCmd.Flags().StringVar(&doctorMockImage, "mock-image", "ghcr.io/volcano-sh/kthena-mock-engine:latest", "Image of the mock model server of the end-to-end check")
+	doctorCmd.Flags().DurationVar(&doctorTimeout, "timeout", 3*time.Minute, "Time the checks may take, including the deployment of the end-to-end check")
+}
+
+type checkStatus string
+
+const (
+	checkPass checkStatus = "PASS"
+	checkWarn checkStatus = "WARN"
+	checkFail checkStatus = "FAIL"
+	checkSkip checkStatus = "SKIP"
+)
+
+// checkResult is the outcome of a check, Hint tells how to fix it when it doesn't pass.
+type checkResult struct {
+	Check   string
+	Status  checkStatus
+	Message string
+	Hint    string
+}
+
+// kthenaCRDs are the CRDs of Kthena, with the spec type of the version served to this CLI. The fields of the spec
+// type must all be in the schema of the CRD, otherwise the CRD is older than the CLI.
+var kthenaCRDs = []struct {
+	group    string
+	version  string
+	resource string
+	spec     interface{}
+}{
+	{networkingv1alpha1.GroupName, "v1alpha1", "modelroutes", networkingv1alpha1.ModelRouteSpec{}},
+	{networkingv1alpha1.GroupName, "v1alpha1", "modelservers", networkingv1alpha1.ModelServerSpec{}},
+	{networkingv1alpha1.GroupName, "v1alpha1", "ratelimitpolicies", networkingv1alpha1.RateLimitPolicySpec{}},
+	{networkingv1alpha1.GroupName, "v1alpha1", "tokenquotas", networkingv1alpha1.TokenQuotaSpec{}},
+	{workloadv1alpha1.GroupName, "v1alpha1", "autoscalingpolicies", workloadv1alpha1.AutoscalingPolicySpec{}},
+	{workloadv1alpha1.GroupName, "v1alpha1", "autoscalingpolicybindings", workloadv1alpha1.AutoscalingPolicyBindingSpec{}},
+	{workloadv1alpha1.GroupName, "v1alpha1", "modelboosters", workloadv1alpha1.ModelBoosterSpec{}},
+	{workloadv1alpha1.GroupName, "v1alpha1", "modelbundles", workloadv1alpha1.ModelBundleSpec{}},
+	{workloadv1alpha1.GroupName, "v1alpha1", "modelservings", workloadv1alpha1.ModelServingSpec{}},
+}
+
+// doctor runs the checks of an installation and collects their results.
+type doctor struct {
+	kube          kubernetes.Interface
+	apiExtensions apiextensionsclientset.Interface
+	kthena        versioned.Interface
+	httpClient    *http.Client
+	// installed are the names of the Kthena CRDs installed and established.
+	installed map[string]bool
+	results   []checkResult
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	config, err := clientcmd.BuildConfigFromFlags("", clientcmd.RecommendedHomeFile)
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %v", err)
+	}
+	kube, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create kubernetes client: %v", err)
+	}
+	apiExtensions, err := apiextensionsclientset.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create apiextensions client: %v", err)
+	}
+	kthena, err := versioned.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create kthena client: %v", err)
+	}
+
+	d := &doctor{
+		kube:          kube,
+		apiExtensions: apiExtensions,
+		kthena:        kthena,
+		httpClient:    &http.Client{Timeout: 10 * time.Second},
+		installed:     map[string]bool{},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+
+	d.checkCRDs(ctx)
+	d.checkComponents(ctx)
+	d.checkWebhooks(ctx)
+	d.checkAdmission(ctx)
+	d.checkGateway(ctx)
+	d.checkEndToEnd(ctx)
+	return d.print(os.Stdout)
+}
+
+func (d *doctor) report(check string, status checkStatus, hint, format string, args ...interface{}) {
+	d.results = append(d.results, checkResult{Check: check, Status: status, Message: fmt.Sprintf(format, args...), Hint: hint})
+}
+
+// print writes the results of the checks, and returns an error if any failed.
+func (d *doctor) print(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STATUS\tCHECK\tMESSAGE")
+	failed := 0
+	for _, result := range d.results {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.Status, result.Check, result.Message)
+		if result.Status != checkPass && result.Hint != "" {
+			fmt.Fprintf(w, "\t\t-> %s\n", result.Hint)
+		}
+		if result.Status == checkFail {
+			failed++
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(d.results))
+	}
+	fmt.Fprintln(out, "\nNo check failed.")
+	return nil
+}
+
+// crdHint tells how to install or upgrade the CRDs of a group: Helm installs the CRDs of a chart but never upgrades them.
+func crdHint(group string) string {
+	chart, _, _ := strings.Cut(group, ".")
+	return fmt.Sprintf("Helm doesn't upgrade CRDs, apply them from the chart of your Kthena version: kubectl apply --server-side -f charts/kthena/charts/%s/crds/", chart)
+}
+
+func (d *doctor) checkCRDs(ctx context.Context) {
+	for _, expected := range kthenaCRDs {
+		name := expected.resource + "." + expected.group
+		check := "crd/" + name
+		crd, err := d.apiExtensions.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			d.report(check, checkFail, crdHint(expected.group), "not installed")
+			continue
+		}
+		if err != nil {
+			d.report(check, checkFail, "", "cannot get the CRD: %v", err)
+			continue
+		}
+		if !crdEstablished(crd) {
+			d.report(check, checkFail, "kubectl describe crd "+name, "not established, the API server doesn't serve it")
+			continue
+		}
+		d.installed[name] = true
+
+		index := slices.IndexFunc(crd.Spec.Versions, func(version apiextensionsv1.CustomResourceDefinitionVersion) bool {
+			return version.Name == expected.version
+		})
+		if index < 0 || !crd.Spec.Versions[index].Served {
+			d.report(check, checkFail, crdHint(expected.group), "doesn't serve %s", expected.version)
+			continue
+		}
+		missing, unknown := compareSpecFields(crd.Spec.Versions[index].Schema, reflect.TypeOf(expected.spec))
+		switch {
+		case len(missing) > 0:
+			d.report(check, checkFail, crdHint(expected.group), "older than this CLI, the spec lacks %s", strings.Join(missing, ", "))
+		case len(unknown) > 0:
+			d.report(check, checkWarn, "upgrade the kthena CLI to the version of the installation",
+				"newer than this CLI, the spec has %s", strings.Join(unknown, ", "))
+		default:
+			d.report(check, checkPass, "", "installed, serves %s", expected.version)
+		}
+	}
+}
+
+func crdEstablished(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	for _, condition := range crd.Status.Conditions {
+		if condition.Type == apiextensionsv1.Established {
+			return condition.Status == apiextensionsv1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// compareSpecFields returns the fields of the spec type missing from the spec of the schema, and the fields of the
+// schema unknown to the spec type. Nothing is compared without a structural schema of the spec.
+func compareSpecFields(validation *apiextensionsv1.CustomResourceValidation, spec reflect.Type) (missing, unknown []string) {
+	if validation == nil || validation.OpenAPIV3Schema == nil {
+		return nil, nil
+	}
+	specSchema, ok := validation.OpenAPIV3Schema.Properties["spec"]
+	if !ok || len(specSchema.Properties) == 0 {
+		return nil, nil
+	}
+	fields := jsonFields(spec)
+	for _, field := range fields {
+		if _, ok := specSchema.Properties[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	for field := range specSchema.Properties {
+		if !slices.Contains(fields, field) {
+			unknown = append(unknown, field)
+		}
+	}
+	slices.Sort(missing)
+	slices.Sort(unknown)
+	return missing, unknown
+}
+
+// jsonFields returns the JSON names of the fields of a struct type, including those of its embedded structs.
+func jsonFields(t reflect.Type) []string {
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			fields = append(fields, jsonFields(embedded)...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, name)
+	}
+	return fields
+}
+
+func (d *doctor) checkComponents(ctx context.Context) {
+	deployments, err := d.kube.AppsV1().Deployments(doctorNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		d.report("components", checkFail, "", "cannot list the deployments of namespace %s: %v", doctorNamespace, err)
+		return
+	}
+	// versions are the deployments running each version of the Kthena images.
+	versions := map[string][]string{}
+	found := false
+	for _, deployment := range deployments.Items {
+		if !strings.HasPrefix(deployment.Name, "kthena-") {
+			continue
+		}
+		found = true
+		check := "deployment/" + deployment.Name
+		desired := ptr.Deref(deployment.Spec.Replicas, 1)
+		if deployment.Status.AvailableReplicas < desired {
+			d.report(check, checkFail, fmt.Sprintf("kubectl -n %s describe deployment %s, and check the logs of its pods", doctorNamespace, deployment.Name),
+				"%d of %d replicas available", deployment.Status.AvailableReplicas, desired)
+		} else {
+			d.report(check, checkPass, "", "%d of %d replicas available", deployment.Status.AvailableReplicas, desired)
+		}
+		for _, container := range deployment.Spec.Template.Spec.Containers {
+			if strings.Contains(container.Image, "kthena") {
+				version := imageVersion(container.Image)
+				if !slices.Contains(versions[version], deployment.Name) {
+					versions[version] = append(versions[version], deployment.Name)
+				}
+			}
+		}
+	}
+	if !found {
+		d.report("components", checkFail, "install Kthena with its Helm chart, or pass the namespace it is installed in with --namespace",
+			"no Kthena deployment in namespace %s", doctorNamespace)
+		return
+	}
+	switch len(versions) {
+	case 0:
+	case 1:
+		for version := range versions {
+			d.report("versions", checkPass, "", "the components run %s", version)
+		}
+	default:
+		var details []string
+		for version, names := range versions {
+			details = append(details, fmt.Sprintf("%s (%s)", version, strings.Join(names, ", ")))
+		}
+		slices.Sort(details)
+		d.report("versions", checkWarn, "upgrade all the components together with helm upgrade",
+			"the components run different versions: %s", strings.Join(details, "; "))
+	}
+}
+
+// imageVersion returns the tag or the digest of an image reference, latest if it has neither.
+func imageVersion(image string) string {
+	if _, digest, ok := strings.Cut(image, "@"); ok {
+		return digest
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if _, tag, ok := strings.Cut(name, ":"); ok {
+		return tag
+	}
+	return "latest"
+}
+
+// kthenaWebhook is a webhook of a Kthena validating or mutating webhook configuration.
+type kthenaWebhook struct {
+	name         string
+	clientConfig admissionregistrationv1.WebhookClientConfig
+	rules        []admissionregistrationv1.RuleWithOperations
+}
+
+func (d *doctor) checkWebhooks(ctx context.Context) {
+	var webhooks []kthenaWebhook
+	validating, err := d.kube.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		d.report("webhooks", checkFail, "", "cannot list the validating webhook configurations: %v", err)
+		return
+	}
+	for _, configuration := range validating.Items {
+		if strings.HasPrefix(configuration.Name, "kthena-") {
+			for _, webhook := range configuration.Webhooks {
+				webhooks = append(webhooks, kthenaWebhook{name: webhook.Name, clientConfig: webhook.ClientConfig, rules: webhook.Rules})
+			}
+		}
+	}
+	mutating, err := d.kube.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		d.report("webhooks", checkFail, "", "cannot list the mutating webhook configurations: %v", err)
+		return
+	}
+	for _, configuration := range mutating.Items {
+		if strings.HasPrefix(configuration.Name, "kthena-") {
+			for _, webhook := range configuration.Webhooks {
+				webhooks = append(webhooks, kthenaWebhook{name: webhook.Name, clientConfig: webhook.ClientConfig, rules: webhook.Rules})
+			}
+		}
+	}
+	if len(webhooks) == 0 {
+		d.report("webhooks", checkWarn, "enable the webhooks of the Helm chart", "no Kthena webhook is configured, the resources are not validated")
+		return
+	}
+
+	for _, webhook := range webhooks {
+		check := "webhook/" + webhook.name
+		if service := webhook.clientConfig.Service; service != nil {
+			if message, hint := d.serviceReady(ctx, service.Namespace, service.Name); message != "" {
+				d.report(check, checkFail, hint, "%s", message)
+				continue
+			}
+		}
+		if len(webhook.clientConfig.CABundle) == 0 {
+			d.report(check, checkFail, "check cert-manager or the certificate of the webhook, or set global.webhook.caBundle of the chart",
+				"no CA bundle, the API server can't verify the webhook server")
+			continue
+		}
+		if unserved := d.unservedRules(webhook.rules); len(unserved) > 0 {
+			d.report(check, checkFail, "the webhook configuration doesn't match the API, reinstall Kthena with the chart of its version",
+				"its rules match no served resource, it never runs: %s", strings.Join(unserved, ", "))
+			continue
+		}
+		d.report(check, checkPass, "", "service ready, CA bundle set")
+	}
+}
+
+// serviceReady returns why the service of a webhook can't serve it and how to fix it, nothing if it has a ready
+// endpoint.
+func (d *doctor) serviceReady(ctx context.Context, namespace, name string) (message, hint string) {
+	if _, err := d.kube.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{}); err != nil {
+		return fmt.Sprintf("cannot get service %s/%s: %v", namespace, name, err), "reinstall Kthena with its Helm chart"
+	}
+	endpointSlices, err := d.kube.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + name,
+	})
+	if err != nil {
+		return fmt.Sprintf("cannot list the endpoints of service %s/%s: %v", namespace, name, err), ""
+	}
+	for _, slice := range endpointSlices.Items {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				return "", ""
+			}
+		}
+	}
+	return fmt.Sprintf("service %s/%s has no ready endpoint", namespace, name),
+		fmt.Sprintf("kubectl -n %s get pods, the pods serving the webhook may be crashing or not scheduled", namespace)
+}
+
+// unservedRules returns the group/version/resource of the rules of a webhook which the API server doesn't serve.
+func (d *doctor) unservedRules(rules []admissionregistrationv1.RuleWithOperations) []string {
+	var unserved []string
+	for _, rule := range rules {
+		for _, group := range rule.APIGroups {
+			for _, version := range rule.APIVersions {
+				groupVersion := version
+				if group != "" {
+					groupVersion = group + "/" + version
+				}
+				for _, resource := range rule.Resources {
+					if group == "*" || version == "*" || resource == "*" {
+						continue
+					}
+					resource, _, _ = strings.Cut(resource, "/")
+					list, err := d.kube.Discovery().ServerResourcesForGroupVersion(groupVersion)
+					if err == nil && slices.ContainsFunc(list.APIResources, func(r metav1.APIResource) bool { return r.Name == resource }) {
+						continue
+					}
+					unserved = append(unserved, groupVersion+"/"+resource)
+				}
+			}
+		}
+	}
+	return unserved
+}
+
+// checkAdmission creates a ModelRoute in dry-run mode, which goes through the webhooks like a real one.
+func (d *doctor) checkAdmission(ctx context.Context) {
+	const check = "admission"
+	if !d.installed["modelroutes."+networkingv1alpha1.GroupName] {
+		d.report(check, checkSkip, "", "the ModelRoute CRD is not installed")
+		return
+	}
+	route := &networkingv1alpha1.ModelRoute{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: "kthena-doctor-", Namespace: doctorTestNamespace},
+		Spec: networkingv1alpha1.ModelRouteSpec{
+			ModelName: "kthena-doctor",
+			Rules: []*networkingv1alpha1.Rule{{
+				Name:         "default",
+				TargetModels: []*networkingv1alpha1.TargetModel{{ModelServerName: "kthena-doctor"}},
+			}},
+		},
+	}
+	_, err := d.kthena.NetworkingV1alpha1().ModelRoutes(doctorTestNamespace).Create(ctx, route, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+	if err != nil {
+		hint := ""
+		if strings.Contains(err.Error(), "failed calling webhook") {
+			hint = "the API server can't call a webhook, see the webhook checks"
+		}
+		d.report(check, checkFail, hint, "a ModelRoute created in dry-run mode was rejected: %v", err)
+		return
+	}
+	d.report(check, checkPass, "", "a ModelRoute created in dry-run mode was admitted")
+}
+
+// routerRequest sends a request to the router, with the API key if set, and returns the status and body of the response.
+func (d *doctor) routerRequest(ctx context.Context, method, path string, body []byte) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(doctorRouterURL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if doctorAPIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+doctorAPIKey)
+	}
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, data, err
+}
+
+func (d *doctor) checkGateway(ctx context.Context) {
+	const check = "gateway"
+	if doctorRouterURL == "" {
+		d.report(check, checkSkip, fmt.Sprintf("kubectl -n %s port-forward svc/kthena-router 8080:80, and pass --router-url http://localhost:8080", doctorNamespace),
+			"no --router-url")
+		return
+	}
+	status, body, err := d.routerRequest(ctx, http.MethodGet, "/readyz", nil)
+	if err != nil {
+		d.report(check, checkFail, "check the URL, the kthena-router service and the port-forward", "the router is not reachable at %s: %v", doctorRouterURL, err)
+		return
+	}
+	if status != http.StatusOK {
+		d.report(check, checkFail, fmt.Sprintf("the router hasn't synced the resources of the cluster, check its logs: kubectl -n %s logs deployment/kthena-router", doctorNamespace),
+			"the router is not ready: %d %s", status, strings.TrimSpace(string(body)))
+		return
+	}
+	d.report(check, checkPass, "", "the router at %s is ready", doctorRouterURL)
+
+	status, body, err = d.routerRequest(ctx, http.MethodGet, "/v1/models", nil)
+	switch {
+	case err != nil:
+		d.report("models", checkFail, "", "cannot list the models: %v", err)
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		d.report("models", checkWarn, "pass an API key of the router with --api-key", "the router requires authentication to list the models")
+	case status != http.StatusOK:
+		d.report("models", checkFail, "", "cannot list the models: %d %s", status, strings.TrimSpace(string(body)))
+	default:
+		var list struct {
+			Data []struct {
+				ID string `json:"id"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(body, &list); err != nil {
+			d.report("models", checkFail, "", "invalid model list: %v", err)
+			return
+		}
+		if len(list.Data) == 0 {
+			d.report("models", checkWarn, "create a ModelServer and a ModelRoute for your models", "the router doesn't route any model")
+			return
+		}
+		d.report("models", checkPass, "", "the router routes %d models", len(list.Data))
+	}
+}
+
+// checkEndToEnd deploys a mock model server with a ModelServer and a ModelRoute, sends a chat completion through the
+// router to it, and deletes them.
+func (d *doctor) checkEndToEnd(ctx context.Context) {
+	const check = "end-to-end"
+	if !doctorEndToEnd {
+		d.report(check, checkSkip, "pass --e2e to deploy a mock model server and send a request to it through the router", "not requested")
+		return
+	}
+	if doctorRouterURL == "" {
+		d.report(check, checkSkip, "", "no --router-url")
+		return
+	}
+	if !d.installed["modelroutes."+networkingv1alpha1.GroupName] || !d.installed["modelservers."+networkingv1alpha1.GroupName] {
+		d.report(check, checkSkip, "", "the ModelRoute and ModelServer CRDs are not installed")
+		return
+	}
+
+	name := "kthena-doctor-" + rand.String(5)
+	labels := map[string]string{"app": name}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: doctorTestNamespace, Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To[int32](1),
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "engine",
+						Image: doctorMockImage,
+						Args:  []string{"--models=" + name},
+						Ports: []corev1.ContainerPort{{ContainerPort: 8000}},
+						ReadinessProbe: &corev1.Probe{
+							ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/health", Port: intstr.FromInt32(8000)}},
+						},
+					}},
+				},
+			},
+		},
+	}
+	modelServer := &networkingv1alpha1.ModelServer{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: doctorTestNamespace},
+		Spec: networkingv1alpha1.ModelServerSpec{
+			WorkloadSelector: &networkingv1alpha1.WorkloadSelector{MatchLabels: labels},
+			WorkloadPort:     networkingv1alpha1.WorkloadPort{Port: 8000},
+			InferenceEngine:  networkingv1alpha1.VLLM,
+		},
+	}
+	modelRoute := &networkingv1alpha1.ModelRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: doctorTestNamespace},
+		Spec: networkingv1alpha1.ModelRouteSpec{
+			ModelName: name,
+			Rules: []*networkingv1alpha1.Rule{{
+				Name:         "default",
+				TargetModels: []*networkingv1alpha1.TargetModel{{ModelServerName: name}},
+			}},
+		},
+	}
+	defer d.deleteEndToEnd(name)
+
+	if _, err := d.kube.AppsV1().Deployments(doctorTestNamespace).Create(ctx, deployment, metav1.CreateOptions{}); err != nil {
+		d.report(check, checkFail, "", "cannot create the mock model server: %v", err)
+		return
+	}
+	if _, err := d.kthena.NetworkingV1alpha1().ModelServers(doctorTestNamespace).Create(ctx, modelServer, metav1.CreateOptions{}); err != nil {
+		d.report(check, checkFail, "", "cannot create the ModelServer: %v", err)
+		return
+	}
+	if _, err := d.kthena.NetworkingV1alpha1().ModelRoutes(doctorTestNamespace).Create(ctx, modelRoute, metav1.CreateOptions{}); err != nil {
+		d.report(check, checkFail, "", "cannot create the ModelRoute: %v", err)
+		return
+	}
+
+	err := wait.PollUntilContextCancel(ctx, 2*time.Second, true, func(ctx context.Context) (bool, error) {
+		current, err := d.kube.AppsV1().Deployments(doctorTestNamespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
+		}
+		return current.Status.AvailableReplicas > 0, nil
+	})
+	if err != nil {
+		d.report(check, checkFail, fmt.Sprintf("check that the image %s can be pulled: kubectl -n %s describe pods -l app=%s", doctorMockImage, doctorTestNamespace, name),
+			"the mock model server is not available")
+		return
+	}
+
+	// The router serves the route once it has seen the ModelRoute, the ModelServer and the ready pod.
+	body, _ := json.Marshal(map[string]interface{}{
+		"model":      name,
+		"messages":   []map[string]string{{"role": "user", "content": "ping"}},
+		"max_tokens": 4,
+	})
+	start := time.Now()
+	var lastStatus int
+	var lastBody []byte
+	var lastErr error
+	err = wait.PollUntilContextCancel(ctx, 2*time.Second, true, func(ctx context.Context) (bool, error) {
+		lastStatus, lastBody, lastErr = d.routerRequest(ctx, http.MethodPost, "/v1/chat/completions", body)
+		return lastErr == nil && lastStatus == http.StatusOK, nil
+	})
+	if err != nil {
+		message := fmt.Sprintf("%d %s", lastStatus, strings.TrimSpace(string(lastBody)))
+		if lastErr != nil {
+			message = lastErr.Error()
+		}
+		d.report(check, checkFail, fmt.Sprintf("check the logs of the router: kubectl -n %s logs deployment/kthena-router", doctorNamespace),
+			"the request through the router failed: %s", message)
+		return
+	}
+	d.report(check, checkPass, "", "a chat completion was served through the router after %s", time.Since(start).Round(time.Second))
+}
+
+// deleteEndToEnd deletes the resources of the end-to-end check, even if the checks timed out.
+func (d *doctor) deleteEndToEnd(name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	propagation := metav1.DeletePropagationBackground
+	options := metav1.DeleteOptions{PropagationPolicy: &propagation}
+	deletions := []struct {
+		kind string
+		err  error
+	}{
+		{"ModelRoute", d.kthena.NetworkingV1alpha1().ModelRoutes(doctorTestNamespace).Delete(ctx, name, options)},
+		{"ModelServer", d.kthena.NetworkingV1alpha1().ModelServers(doctorTestNamespace).Delete(ctx, name, options)},
+		{"Deployment", d.kube.AppsV1().Deployments(doctorTestNamespace).Delete(ctx, name, options)},
+	}
+	for _, deletion := range deletions {
+		if deletion.err != nil && !apierrors.IsNotFound(deletion.err) {
+			fmt.Fprintf(os.Stderr, "failed to delete %s %s/%s: %v\n", deletion.kind, doctorTestNamespace, name, deletion.err)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kthenafake "github.com/volcano-sh/kthena/client-go/clientset/versioned/fake"
+	networkingv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func newTestDoctor(kubeObjects ...runtime.Object) *doctor {
+	return &doctor{
+		kube:          kubefake.NewSimpleClientset(kubeObjects...),
+		apiExtensions: apiextensionsfake.NewSimpleClientset(),
+		kthena:        kthenafake.NewSimpleClientset(),
+		httpClient:    http.DefaultClient,
+		installed:     map[string]bool{},
+	}
+}
+
+// resultsOf returns the status and message of the results of a check.
+func resultsOf(d *doctor, check string) []string {
+	var results []string
+	for _, result := range d.results {
+		if result.Check == check {
+			results = append(results, fmt.Sprintf("%s %s", result.Status, result.Message))
+		}
+	}
+	return results
+}
+
+// testCRD returns an established CRD serving v1alpha1 whose spec schema has the fields.
+func testCRD(name string, established bool, fields ...string) *apiextensionsv1.CustomResourceDefinition {
+	properties := map[string]apiextensionsv1.JSONSchemaProps{}
+	for _, field := range fields {
+		properties[field] = apiextensionsv1.JSONSchemaProps{}
+	}
+	status := apiextensionsv1.ConditionFalse
+	if established {
+		status = apiextensionsv1.ConditionTrue
+	}
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+				Name:   "v1alpha1",
+				Served: true,
+				Schema: &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+					Properties: map[string]apiextensionsv1.JSONSchemaProps{"spec": {Properties: properties}},
+				}},
+			}},
+		},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{
+			Conditions: []apiextensionsv1.CustomResourceDefinitionCondition{{Type: apiextensionsv1.Established, Status: status}},
+		},
+	}
+}
+
+func TestDoctorCheckCRDs(t *testing.T) {
+	policyFields := jsonFields(reflect.TypeOf(networkingv1alpha1.RateLimitPolicySpec{}))
+	d := newTestDoctor()
+	d.apiExtensions = apiextensionsfake.NewSimpleClientset(
+		testCRD("modelroutes.networking.serving.volcano.sh", true, jsonFields(reflect.TypeOf(networkingv1alpha1.ModelRouteSpec{}))...),
+		testCRD("modelservers.networking.serving.volcano.sh", false),
+		testCRD("ratelimitpolicies.networking.serving.volcano.sh", true, policyFields[1:]...),
+		testCRD("tokenquotas.networking.serving.volcano.sh", true,
+			append(jsonFields(reflect.TypeOf(networkingv1alpha1.TokenQuotaSpec{})), "newField")...),
+	)
+
+	d.checkCRDs(context.Background())
+	assert.Equal(t, []string{"PASS installed, serves v1alpha1"}, resultsOf(d, "crd/modelroutes.networking.serving.volcano.sh"))
+	assert.Equal(t, []string{"FAIL not established, the API server doesn't serve it"}, resultsOf(d, "crd/modelservers.networking.serving.volcano.sh"))
+	assert.Equal(t, []string{"FAIL older than this CLI, the spec lacks " + policyFields[0]}, resultsOf(d, "crd/ratelimitpolicies.networking.serving.volcano.sh"))
+	assert.Equal(t, []string{"WARN newer than this CLI, the spec has newField"}, resultsOf(d, "crd/tokenquotas.networking.serving.volcano.sh"))
+	assert.Equal(t, []string{"FAIL not installed"}, resultsOf(d, "crd/modelservings.workload.serving.volcano.sh"))
+	// Only the established CRDs are installed, the checks depending on them run.
+	assert.Equal(t, map[string]bool{
+		"modelroutes.networking.serving.volcano.sh":       true,
+		"ratelimitpolicies.networking.serving.volcano.sh": true,
+		"tokenquotas.networking.serving.volcano.sh":       true,
+	}, d.installed)
+}
+
+func TestJSONFields(t *testing.T) {
+	type Embedded struct {
+		Inline string `json:"inline"`
+	}
+	type spec struct {
+		Embedded `json:",inline"`
+		Name     string `json:"name,omitempty"`
+		Untagged string
+		Ignored  string `json:"-"`
+		internal string
+	}
+	assert.Equal(t, []string{"inline", "name", "Untagged"}, jsonFields(reflect.TypeOf(spec{})))
+}
+
+func TestImageVersion(t *testing.T) {
+	tests := map[string]string{
+		"ghcr.io/volcano-sh/kthena-router:v0.3.0":               "v0.3.0",
+		"localhost:5000/kthena-router":                          "latest",
+		"localhost:5000/kthena-router:dev":                      "dev",
+		"ghcr.io/volcano-sh/kthena-router@sha256:0123456789abc": "sha256:0123456789abc",
+	}
+	for image, version := range tests {
+		assert.Equal(t, version, imageVersion(image), image)
+	}
+}
+
+func testDeployment(name, image string, available int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: doctorNamespace},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To[int32](2),
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: image}}}},
+		},
+		Status: appsv1.DeploymentStatus{AvailableReplicas: available},
+	}
+}
+
+func TestDoctorCheckComponents(t *testing.T) {
+	doctorNamespace = "kthena-system"
+
+	d := newTestDoctor()
+	d.checkComponents(context.Background())
+	assert.Equal(t, []string{"FAIL no Kthena deployment in namespace kthena-system"}, resultsOf(d, "components"))
+
+	d = newTestDoctor(
+		testDeployment("kthena-router", "ghcr.io/volcano-sh/kthena-router:v0.3.0", 2),
+		testDeployment("kthena-controller-manager", "ghcr.io/volcano-sh/kthena-controller-manager:v0.2.0", 1),
+		testDeployment("redis", "redis:7", 0),
+	)
+	d.checkComponents(context.Background())
+	assert.Equal(t, []string{"PASS 2 of 2 replicas available"}, resultsOf(d, "deployment/kthena-router"))
+	assert.Equal(t, []string{"FAIL 1 of 2 replicas available"}, resultsOf(d, "deployment/kthena-controller-manager"))
+	assert.Empty(t, resultsOf(d, "deployment/redis"))
+	assert.Equal(t, []string{"WARN the components run different versions: v0.2.0 (kthena-controller-manager); v0.3.0 (kthena-router)"},
+		resultsOf(d, "versions"))
+}
+
+func TestDoctorCheckWebhooks(t *testing.T) {
+	d := newTestDoctor()
+	d.checkWebhooks(context.Background())
+	assert.Equal(t, []string{"WARN no Kthena webhook is configured, the resources are not validated"}, resultsOf(d, "webhooks"))
+
+	service := func(name string) admissionregistrationv1.WebhookClientConfig {
+		return admissionregistrationv1.WebhookClientConfig{
+			Service:  &admissionregistrationv1.ServiceReference{Namespace: "kthena-system", Name: name},
+			CABundle: []byte("ca"),
+		}
+	}
+	rules := func(group string) []admissionregistrationv1.RuleWithOperations {
+		return []admissionregistrationv1.RuleWithOperations{{
+			Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create},
+			Rule:       admissionregistrationv1.Rule{APIGroups: []string{group}, APIVersions: []string{"v1alpha1"}, Resources: []string{"modelroutes"}},
+		}}
+	}
+	noCABundle := service("kthena-router-webhook")
+	noCABundle.CABundle = nil
+	d = newTestDoctor(
+		&admissionregistrationv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "kthena-router-validating-webhook"},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{
+				{Name: "served.kthena.io", ClientConfig: service("kthena-router-webhook"), Rules: rules("networking.serving.volcano.sh")},
+				{Name: "unserved.kthena.io", ClientConfig: service("kthena-router-webhook"), Rules: rules("networking.volcano.sh")},
+				{Name: "no-ca-bundle.kthena.io", ClientConfig: noCABundle, Rules: rules("networking.serving.volcano.sh")},
+				{Name: "no-endpoint.kthena.io", ClientConfig: service("kthena-down-webhook"), Rules: rules("networking.serving.volcano.sh")},
+			},
+		},
+		&admissionregistrationv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-webhook"},
+			Webhooks:   []admissionregistrationv1.ValidatingWebhook{{Name: "other.example.com", Rules: rules("example.com")}},
+		},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "kthena-system", Name: "kthena-router-webhook"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "kthena-system", Name: "kthena-down-webhook"}},
+		&discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kthena-system", Name: "kthena-router-webhook-1",
+				Labels: map[string]string{discoveryv1.LabelServiceName: "kthena-router-webhook"}},
+			Endpoints: []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)}}},
+		},
+		&discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kthena-system", Name: "kthena-down-webhook-1",
+				Labels: map[string]string{discoveryv1.LabelServiceName: "kthena-down-webhook"}},
+			Endpoints: []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.2"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false)}}},
+		},
+	)
+	d.kube.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{
+		GroupVersion: "networking.serving.volcano.sh/v1alpha1",
+		APIResources: []metav1.APIResource{{Name: "modelroutes"}},
+	}}
+
+	d.checkWebhooks(context.Background())
+	assert.Equal(t, []string{"PASS service ready, CA bundle set"}, resultsOf(d, "webhook/served.kthena.io"))
+	assert.Equal(t, []string{"FAIL its rules match no served resource, it never runs: networking.volcano.sh/v1alpha1/modelroutes"},
+		resultsOf(d, "webhook/unserved.kthena.io"))
+	assert.Equal(t, []string{"FAIL no CA bundle, the API server can't verify the webhook server"}, resultsOf(d, "webhook/no-ca-bundle.kthena.io"))
+	assert.Equal(t, []string{"FAIL service kthena-system/kthena-down-webhook has no ready endpoint"}, resultsOf(d, "webhook/no-endpoint.kthena.io"))
+	assert.Empty(t, resultsOf(d, "webhook/other.example.com"))
+}
+
+func TestDoctorCheckAdmission(t *testing.T) {
+	d := newTestDoctor()
+	d.checkAdmission(context.Background())
+	assert.Equal(t, []string{"SKIP the ModelRoute CRD is not installed"}, resultsOf(d, "admission"))
+
+	d = newTestDoctor()
+	d.installed["modelroutes."+networkingv1alpha1.GroupName] = true
+	d.checkAdmission(context.Background())
+	assert.Equal(t, []string{"PASS a ModelRoute created in dry-run mode was admitted"}, resultsOf(d, "admission"))
+}
+
+func TestDoctorCheckGateway(t *testing.T) {
+	defer func() { doctorRouterURL, doctorAPIKey = "", "" }()
+	var models string
+	router := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/readyz":
+			w.WriteHeader(http.StatusOK)
+		case r.Header.Get("Authorization") != "Bearer secret":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			fmt.Fprint(w, models)
+		}
+	}))
+	defer router.Close()
+
+	tests := []struct {
+		name       string
+		routerURL  string
+		apiKey     string
+		models     string
+		wantModels []string
+	}{
+		{
+			name:      "no router url",
+			routerURL: "",
+		},
+		{
+			name:       "authentication required",
+			routerURL:  router.URL,
+			wantModels: []string{"WARN the router requires authentication to list the models"},
+		},
+		{
+			name:       "no model",
+			routerURL:  router.URL,
+			apiKey:     "secret",
+			models:     `{"data":[]}`,
+			wantModels: []string{"WARN the router doesn't route any model"},
+		},
+		{
+			name:       "models",
+			routerURL:  router.URL + "/",
+			apiKey:     "secret",
+			models:     `{"data":[{"id":"llama"},{"id":"qwen"}]}`,
+			wantModels: []string{"PASS the router routes 2 models"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doctorRouterURL, doctorAPIKey, models = tt.routerURL, tt.apiKey, tt.models
+			d := newTestDoctor()
+			d.checkGateway(context.Background())
+			if tt.routerURL == "" {
+				assert.Equal(t, []string{"SKIP no --router-url"}, resultsOf(d, "gateway"))
+				return
+			}
+			assert.Equal(t, []string{"PASS the router at " + tt.routerURL + " is ready"}, resultsOf(d, "gateway"))
+			assert.Equal(t, tt.wantModels, resultsOf(d, "models"))
+		})
+	}
+}
+
+func TestDoctorPrint(t *testing.T) {
+	d := newTestDoctor()
+	d.report("gateway", checkPass, "ignored hint", "ready")
+	var out bytes.Buffer
+	require.NoError(t, d.print(&out))
+	assert.Equal(t, "STATUS  CHECK    MESSAGE\nPASS    gateway  ready\n\nNo check failed.\n", out.String())
+
+	d.report("crd/modelroutes", checkFail, "apply the CRDs", "not installed")
+	out.Reset()
+	assert.EqualError(t, d.print(&out), "1 of 2 checks failed")
+	assert.Contains(t, out.String(), "FAIL    crd/modelroutes  not installed\n                         -> apply the CRDs\n")
+	assert.NotContains(t, out.String(), "ignored hint")
+}
@@ -34,6 +34,7 @@ It allows you to:
 - Create manifests from predefined templates with custom values
 - List and view Kthena resources in Kubernetes clusters
 - Manage inference workloads, models, and autoscaling policies
+- Check that an installation works
 
 Examples:
   kthena get templates
@@ -41,7 +42,8 @@ Examples:
   kthena get template DeepSeek-R1-Distill-Qwen-32B -o yaml
   kthena create manifest --name my-model --template DeepSeek-R1-Distill-Qwen-32B
   kthena get model-boosters
-  kthena get model-servings --all-namespaces`,
+  kthena get model-servings --all-namespaces
+  kthena doctor`,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
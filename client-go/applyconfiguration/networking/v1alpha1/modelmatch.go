@@ -25,9 +25,10 @@ import (
 // ModelMatchApplyConfiguration represents a declarative configuration of the ModelMatch type for use
 // with apply.
 type ModelMatchApplyConfiguration struct {
-	Headers map[string]*networkingv1alpha1.StringMatch `json:"headers,omitempty"`
-	Uri     *StringMatchApplyConfiguration             `json:"uri,omitempty"`
-	Body    *BodyMatchApplyConfiguration               `json:"body,omitempty"`
+	Headers    map[string]*networkingv1alpha1.StringMatch `json:"headers,omitempty"`
+	Uri        *StringMatchApplyConfiguration             `json:"uri,omitempty"`
+	Body       *BodyMatchApplyConfiguration               `json:"body,omitempty"`
+	Expression *string                                    `json:"expression,omitempty"`
 }
 
 // ModelMatchApplyConfiguration constructs a declarative configuration of the ModelMatch type for use with
@@ -65,3 +66,11 @@ func (b *ModelMatchApplyConfiguration) WithBody(value *BodyMatchApplyConfigurati
 	b.Body = value
 	return b
 }
+
+// WithExpression sets the Expression field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Expression field is set to the value of the last call.
+func (b *ModelMatchApplyConfiguration) WithExpression(value string) *ModelMatchApplyConfiguration {
+	b.Expression = &value
+	return b
+}
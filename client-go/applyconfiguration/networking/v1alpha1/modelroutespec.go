@@ -26,11 +26,27 @@ import (
 // ModelRouteSpecApplyConfiguration represents a declarative configuration of the ModelRouteSpec type for use
 // with apply.
 type ModelRouteSpecApplyConfiguration struct {
-	ModelName    *string                      `json:"modelName,omitempty"`
-	LoraAdapters []string                     `json:"loraAdapters,omitempty"`
-	ParentRefs   []v1.ParentReference         `json:"parentRefs,omitempty"`
-	Rules        []*networkingv1alpha1.Rule   `json:"rules,omitempty"`
-	RateLimit    *RateLimitApplyConfiguration `json:"rateLimit,omitempty"`
+	ModelName         *string                              `json:"modelName,omitempty"`
+	LoraAdapters      []string                             `json:"loraAdapters,omitempty"`
+	Aliases           []string                             `json:"aliases,omitempty"`
+	ModelNameMatch    *StringMatchApplyConfiguration       `json:"modelNameMatch,omitempty"`
+	ParentRefs        []v1.ParentReference                 `json:"parentRefs,omitempty"`
+	Rules             []*networkingv1alpha1.Rule           `json:"rules,omitempty"`
+	RateLimit         *RateLimitApplyConfiguration         `json:"rateLimit,omitempty"`
+	DefaultParameters *DefaultParametersApplyConfiguration `json:"defaultParameters,omitempty"`
+	SLO               *SLOApplyConfiguration               `json:"slo,omitempty"`
+	HeaderPolicy      *HeaderPolicyApplyConfiguration      `json:"headerPolicy,omitempty"`
+	Fallback          *FallbackApplyConfiguration          `json:"fallback,omitempty"`
+	Mirror            *MirrorApplyConfiguration            `json:"mirror,omitempty"`
+	Evaluation        *EvaluationApplyConfiguration        `json:"evaluation,omitempty"`
+	Rollout           *RolloutApplyConfiguration           `json:"rollout,omitempty"`
+	Priority          *PriorityApplyConfiguration          `json:"priority,omitempty"`
+	Timeouts          *TimeoutsApplyConfiguration          `json:"timeouts,omitempty"`
+	SessionAffinity   *SessionAffinityApplyConfiguration   `json:"sessionAffinity,omitempty"`
+	Observability     *ObservabilityApplyConfiguration     `json:"observability,omitempty"`
+	Hedging           *HedgingApplyConfiguration           `json:"hedging,omitempty"`
+	Concurrency       *ConcurrencyApplyConfiguration       `json:"concurrency,omitempty"`
+	StructuredOutput  *StructuredOutputApplyConfiguration  `json:"structuredOutput,omitempty"`
 }
 
 // ModelRouteSpecApplyConfiguration constructs a declarative configuration of the ModelRouteSpec type for use with
@@ -57,6 +73,24 @@ func (b *ModelRouteSpecApplyConfiguration) WithLoraAdapters(values ...string) *M
 	return b
 }
 
+// WithAliases adds the given value to the Aliases field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Aliases field.
+func (b *ModelRouteSpecApplyConfiguration) WithAliases(values ...string) *ModelRouteSpecApplyConfiguration {
+	for i := range values {
+		b.Aliases = append(b.Aliases, values[i])
+	}
+	return b
+}
+
+// WithModelNameMatch sets the ModelNameMatch field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ModelNameMatch field is set to the value of the last call.
+func (b *ModelRouteSpecApplyConfiguration) WithModelNameMatch(value *StringMatchApplyConfiguration) *ModelRouteSpecApplyConfiguration {
+	b.ModelNameMatch = value
+	return b
+}
+
 // WithParentRefs adds the given value to the ParentRefs field in the declarative configuration
 // and returns the receiver, so that objects can be build by chaining "With" function invocations.
 // If called multiple times, values provided by each call will be appended to the ParentRefs field.
@@ -87,3 +121,115 @@ func (b *ModelRouteSpecApplyConfiguration) WithRateLimit(value *RateLimitApplyCo
 	b.RateLimit = value
 	return b
 }
+
+// WithDefaultParameters sets the DefaultParameters field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DefaultParameters field is set to the value of the last call.
+func (b *ModelRouteSpecApplyConfiguration) WithDefaultParameters(value *DefaultParametersApplyConfiguration) *ModelRouteSpecApplyConfiguration {
+	b.DefaultParameters = value
+	return b
+}
+
+// WithSLO sets the SLO field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SLO field is set to the value of the last call.
+func (b *ModelRouteSpecApplyConfiguration) WithSLO(value *SLOApplyConfiguration) *ModelRouteSpecApplyConfiguration {
+	b.SLO = value
+	return b
+}
+
+// WithHeaderPolicy sets the HeaderPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the HeaderPolicy field is set to the value of the last call.
+func (b *ModelRouteSpecApplyConfiguration) WithHeaderPolicy(value *HeaderPolicyApplyConfiguration) *ModelRouteSpecApplyConfiguration {
+	b.HeaderPolicy = value
+	return b
+}
+
+// WithFallback sets the Fallback field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Fallback field is set to the value of the last call.
+func (b *ModelRouteSpecApplyConfiguration) WithFallback(value *FallbackApplyConfiguration) *ModelRouteSpecApplyConfiguration {
+	b.Fallback = value
+	return b
+}
+
+// WithMirror sets the Mirror field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Mirror field is set to the value of the last call.
+func (b *ModelRouteSpecApplyConfiguration) WithMirror(value *MirrorApplyConfiguration) *ModelRouteSpecApplyConfiguration {
+	b.Mirror = value
+	return b
+}
+
+// WithEvaluation sets the Evaluation field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Evaluation field is set to the value of the last call.
+func (b *ModelRouteSpecApplyConfiguration) WithEvaluation(value *EvaluationApplyConfiguration) *ModelRouteSpecApplyConfiguration {
+	b.Evaluation = value
+	return b
+}
+
+// WithRollout sets the Rollout field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Rollout field is set to the value of the last call.
+func (b *ModelRouteSpecApplyConfiguration) WithRollout(value *RolloutApplyConfiguration) *ModelRouteSpecApplyConfiguration {
+	b.Rollout = value
+	return b
+}
+
+// WithPriority sets the Priority field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Priority field is set to the value of the last call.
+func (b *ModelRouteSpecApplyConfiguration) WithPriority(value *PriorityApplyConfiguration) *ModelRouteSpecApplyConfiguration {
+	b.Priority = value
+	return b
+}
+
+// WithTimeouts sets the Timeouts field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Timeouts field is set to the value of the last call.
+func (b *ModelRouteSpecApplyConfiguration) WithTimeouts(value *TimeoutsApplyConfiguration) *ModelRouteSpecApplyConfiguration {
+	b.Timeouts = value
+	return b
+}
+
+// WithSessionAffinity sets the SessionAffinity field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SessionAffinity field is set to the value of the last call.
+func (b *ModelRouteSpecApplyConfiguration) WithSessionAffinity(value *SessionAffinityApplyConfiguration) *ModelRouteSpecApplyConfiguration {
+	b.SessionAffinity = value
+	return b
+}
+
+// WithObservability sets the Observability field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Observability field is set to the value of the last call.
+func (b *ModelRouteSpecApplyConfiguration) WithObservability(value *ObservabilityApplyConfiguration) *ModelRouteSpecApplyConfiguration {
+	b.Observability = value
+	return b
+}
+
+// WithHedging sets the Hedging field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Hedging field is set to the value of the last call.
+func (b *ModelRouteSpecApplyConfiguration) WithHedging(value *HedgingApplyConfiguration) *ModelRouteSpecApplyConfiguration {
+	b.Hedging = value
+	return b
+}
+
+// WithConcurrency sets the Concurrency field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Concurrency field is set to the value of the last call.
+func (b *ModelRouteSpecApplyConfiguration) WithConcurrency(value *ConcurrencyApplyConfiguration) *ModelRouteSpecApplyConfiguration {
+	b.Concurrency = value
+	return b
+}
+
+// WithStructuredOutput sets the StructuredOutput field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the StructuredOutput field is set to the value of the last call.
+func (b *ModelRouteSpecApplyConfiguration) WithStructuredOutput(value *StructuredOutputApplyConfiguration) *ModelRouteSpecApplyConfiguration {
+	b.StructuredOutput = value
+	return b
+}
@@ -25,12 +25,21 @@ import (
 // ModelServerSpecApplyConfiguration represents a declarative configuration of the ModelServerSpec type for use
 // with apply.
 type ModelServerSpecApplyConfiguration struct {
-	Model            *string                             `json:"model,omitempty"`
-	InferenceEngine  *networkingv1alpha1.InferenceEngine `json:"inferenceEngine,omitempty"`
-	WorkloadSelector *WorkloadSelectorApplyConfiguration `json:"workloadSelector,omitempty"`
-	WorkloadPort     *WorkloadPortApplyConfiguration     `json:"workloadPort,omitempty"`
-	TrafficPolicy    *TrafficPolicyApplyConfiguration    `json:"trafficPolicy,omitempty"`
-	KVConnector      *KVConnectorSpecApplyConfiguration  `json:"kvConnector,omitempty"`
+	Model                       *string                                 `json:"model,omitempty"`
+	InferenceEngine             *networkingv1alpha1.InferenceEngine     `json:"inferenceEngine,omitempty"`
+	WorkloadSelector            *WorkloadSelectorApplyConfiguration     `json:"workloadSelector,omitempty"`
+	Discovery                   *DiscoveryApplyConfiguration            `json:"discovery,omitempty"`
+	WorkloadPort                *WorkloadPortApplyConfiguration         `json:"workloadPort,omitempty"`
+	TrafficPolicy               *TrafficPolicyApplyConfiguration        `json:"trafficPolicy,omitempty"`
+	KVConnector                 *KVConnectorSpecApplyConfiguration      `json:"kvConnector,omitempty"`
+	MaxContextLength            *int32                                  `json:"maxContextLength,omitempty"`
+	MaxConcurrentRequestsPerPod *int32                                  `json:"maxConcurrentRequestsPerPod,omitempty"`
+	LoadBalancingPolicy         *networkingv1alpha1.LoadBalancingPolicy `json:"loadBalancingPolicy,omitempty"`
+	SlowStart                   *SlowStartApplyConfiguration            `json:"slowStart,omitempty"`
+	PrefillCoalescing           *PrefillCoalescingApplyConfiguration    `json:"prefillCoalescing,omitempty"`
+	WarmUp                      *WarmUpApplyConfiguration               `json:"warmUp,omitempty"`
+	Pricing                     *PricingApplyConfiguration              `json:"pricing,omitempty"`
+	Capabilities                []networkingv1alpha1.Capability         `json:"capabilities,omitempty"`
 }
 
 // ModelServerSpecApplyConfiguration constructs a declarative configuration of the ModelServerSpec type for use with
@@ -63,6 +72,14 @@ func (b *ModelServerSpecApplyConfiguration) WithWorkloadSelector(value *Workload
 	return b
 }
 
+// WithDiscovery sets the Discovery field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Discovery field is set to the value of the last call.
+func (b *ModelServerSpecApplyConfiguration) WithDiscovery(value *DiscoveryApplyConfiguration) *ModelServerSpecApplyConfiguration {
+	b.Discovery = value
+	return b
+}
+
 // WithWorkloadPort sets the WorkloadPort field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the WorkloadPort field is set to the value of the last call.
@@ -86,3 +103,69 @@ func (b *ModelServerSpecApplyConfiguration) WithKVConnector(value *KVConnectorSp
 	b.KVConnector = value
 	return b
 }
+
+// WithMaxContextLength sets the MaxContextLength field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MaxContextLength field is set to the value of the last call.
+func (b *ModelServerSpecApplyConfiguration) WithMaxContextLength(value int32) *ModelServerSpecApplyConfiguration {
+	b.MaxContextLength = &value
+	return b
+}
+
+// WithMaxConcurrentRequestsPerPod sets the MaxConcurrentRequestsPerPod field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MaxConcurrentRequestsPerPod field is set to the value of the last call.
+func (b *ModelServerSpecApplyConfiguration) WithMaxConcurrentRequestsPerPod(value int32) *ModelServerSpecApplyConfiguration {
+	b.MaxConcurrentRequestsPerPod = &value
+	return b
+}
+
+// WithLoadBalancingPolicy sets the LoadBalancingPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LoadBalancingPolicy field is set to the value of the last call.
+func (b *ModelServerSpecApplyConfiguration) WithLoadBalancingPolicy(value networkingv1alpha1.LoadBalancingPolicy) *ModelServerSpecApplyConfiguration {
+	b.LoadBalancingPolicy = &value
+	return b
+}
+
+// WithSlowStart sets the SlowStart field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SlowStart field is set to the value of the last call.
+func (b *ModelServerSpecApplyConfiguration) WithSlowStart(value *SlowStartApplyConfiguration) *ModelServerSpecApplyConfiguration {
+	b.SlowStart = value
+	return b
+}
+
+// WithPrefillCoalescing sets the PrefillCoalescing field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PrefillCoalescing field is set to the value of the last call.
+func (b *ModelServerSpecApplyConfiguration) WithPrefillCoalescing(value *PrefillCoalescingApplyConfiguration) *ModelServerSpecApplyConfiguration {
+	b.PrefillCoalescing = value
+	return b
+}
+
+// WithWarmUp sets the WarmUp field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the WarmUp field is set to the value of the last call.
+func (b *ModelServerSpecApplyConfiguration) WithWarmUp(value *WarmUpApplyConfiguration) *ModelServerSpecApplyConfiguration {
+	b.WarmUp = value
+	return b
+}
+
+// WithPricing sets the Pricing field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Pricing field is set to the value of the last call.
+func (b *ModelServerSpecApplyConfiguration) WithPricing(value *PricingApplyConfiguration) *ModelServerSpecApplyConfiguration {
+	b.Pricing = value
+	return b
+}
+
+// WithCapabilities adds the given value to the Capabilities field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Capabilities field.
+func (b *ModelServerSpecApplyConfiguration) WithCapabilities(values ...networkingv1alpha1.Capability) *ModelServerSpecApplyConfiguration {
+	for i := range values {
+		b.Capabilities = append(b.Capabilities, values[i])
+	}
+	return b
+}
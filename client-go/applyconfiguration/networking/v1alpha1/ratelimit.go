@@ -27,8 +27,14 @@ import (
 type RateLimitApplyConfiguration struct {
 	InputTokensPerUnit  *uint32                            `json:"inputTokensPerUnit,omitempty"`
 	OutputTokensPerUnit *uint32                            `json:"outputTokensPerUnit,omitempty"`
+	ImagesPerUnit       *uint32                            `json:"imagesPerUnit,omitempty"`
+	MegapixelsPerUnit   *uint32                            `json:"megapixelsPerUnit,omitempty"`
+	ItemsPerUnit        *uint32                            `json:"itemsPerUnit,omitempty"`
+	BytesPerUnit        *uint32                            `json:"bytesPerUnit,omitempty"`
 	Unit                *networkingv1alpha1.RateLimitUnit  `json:"unit,omitempty"`
+	Burst               *uint32                            `json:"burst,omitempty"`
 	Global              *GlobalRateLimitApplyConfiguration `json:"global,omitempty"`
+	DegradeTo           *string                            `json:"degradeTo,omitempty"`
 }
 
 // RateLimitApplyConfiguration constructs a declarative configuration of the RateLimit type for use with
@@ -53,6 +59,38 @@ func (b *RateLimitApplyConfiguration) WithOutputTokensPerUnit(value uint32) *Rat
 	return b
 }
 
+// WithImagesPerUnit sets the ImagesPerUnit field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ImagesPerUnit field is set to the value of the last call.
+func (b *RateLimitApplyConfiguration) WithImagesPerUnit(value uint32) *RateLimitApplyConfiguration {
+	b.ImagesPerUnit = &value
+	return b
+}
+
+// WithMegapixelsPerUnit sets the MegapixelsPerUnit field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MegapixelsPerUnit field is set to the value of the last call.
+func (b *RateLimitApplyConfiguration) WithMegapixelsPerUnit(value uint32) *RateLimitApplyConfiguration {
+	b.MegapixelsPerUnit = &value
+	return b
+}
+
+// WithItemsPerUnit sets the ItemsPerUnit field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ItemsPerUnit field is set to the value of the last call.
+func (b *RateLimitApplyConfiguration) WithItemsPerUnit(value uint32) *RateLimitApplyConfiguration {
+	b.ItemsPerUnit = &value
+	return b
+}
+
+// WithBytesPerUnit sets the BytesPerUnit field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the BytesPerUnit field is set to the value of the last call.
+func (b *RateLimitApplyConfiguration) WithBytesPerUnit(value uint32) *RateLimitApplyConfiguration {
+	b.BytesPerUnit = &value
+	return b
+}
+
 // WithUnit sets the Unit field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the Unit field is set to the value of the last call.
@@ -61,6 +99,14 @@ func (b *RateLimitApplyConfiguration) WithUnit(value networkingv1alpha1.RateLimi
 	return b
 }
 
+// WithBurst sets the Burst field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Burst field is set to the value of the last call.
+func (b *RateLimitApplyConfiguration) WithBurst(value uint32) *RateLimitApplyConfiguration {
+	b.Burst = &value
+	return b
+}
+
 // WithGlobal sets the Global field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the Global field is set to the value of the last call.
@@ -68,3 +114,11 @@ func (b *RateLimitApplyConfiguration) WithGlobal(value *GlobalRateLimitApplyConf
 	b.Global = value
 	return b
 }
+
+// WithDegradeTo sets the DegradeTo field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DegradeTo field is set to the value of the last call.
+func (b *RateLimitApplyConfiguration) WithDegradeTo(value string) *RateLimitApplyConfiguration {
+	b.DegradeTo = &value
+	return b
+}
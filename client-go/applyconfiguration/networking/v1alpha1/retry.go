@@ -27,6 +27,8 @@ import (
 type RetryApplyConfiguration struct {
 	Attempts      *int32       `json:"attempts,omitempty"`
 	RetryInterval *v1.Duration `json:"retryInterval,omitempty"`
+	Budget        *v1.Duration `json:"budget,omitempty"`
+	StatusCodes   []int32      `json:"statusCodes,omitempty"`
 }
 
 // RetryApplyConfiguration constructs a declarative configuration of the Retry type for use with
@@ -50,3 +52,21 @@ func (b *RetryApplyConfiguration) WithRetryInterval(value v1.Duration) *RetryApp
 	b.RetryInterval = &value
 	return b
 }
+
+// WithBudget sets the Budget field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Budget field is set to the value of the last call.
+func (b *RetryApplyConfiguration) WithBudget(value v1.Duration) *RetryApplyConfiguration {
+	b.Budget = &value
+	return b
+}
+
+// WithStatusCodes adds the given value to the StatusCodes field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the StatusCodes field.
+func (b *RetryApplyConfiguration) WithStatusCodes(values ...int32) *RetryApplyConfiguration {
+	for i := range values {
+		b.StatusCodes = append(b.StatusCodes, values[i])
+	}
+	return b
+}
@@ -21,8 +21,10 @@ package v1alpha1
 // WorkloadPortApplyConfiguration represents a declarative configuration of the WorkloadPort type for use
 // with apply.
 type WorkloadPortApplyConfiguration struct {
-	Port     *int32  `json:"port,omitempty"`
-	Protocol *string `json:"protocol,omitempty"`
+	Port     *int32                        `json:"port,omitempty"`
+	Protocol *string                       `json:"protocol,omitempty"`
+	TLS      *BackendTLSApplyConfiguration `json:"tls,omitempty"`
+	GRPCPort *int32                        `json:"grpcPort,omitempty"`
 }
 
 // WorkloadPortApplyConfiguration constructs a declarative configuration of the WorkloadPort type for use with
@@ -46,3 +48,19 @@ func (b *WorkloadPortApplyConfiguration) WithProtocol(value string) *WorkloadPor
 	b.Protocol = &value
 	return b
 }
+
+// WithTLS sets the TLS field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TLS field is set to the value of the last call.
+func (b *WorkloadPortApplyConfiguration) WithTLS(value *BackendTLSApplyConfiguration) *WorkloadPortApplyConfiguration {
+	b.TLS = value
+	return b
+}
+
+// WithGRPCPort sets the GRPCPort field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the GRPCPort field is set to the value of the last call.
+func (b *WorkloadPortApplyConfiguration) WithGRPCPort(value int32) *WorkloadPortApplyConfiguration {
+	b.GRPCPort = &value
+	return b
+}
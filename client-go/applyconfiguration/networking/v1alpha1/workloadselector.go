@@ -18,11 +18,16 @@ limitations under the License.
 
 package v1alpha1
 
+import (
+	v1 "k8s.io/client-go/applyconfigurations/meta/v1"
+)
+
 // WorkloadSelectorApplyConfiguration represents a declarative configuration of the WorkloadSelector type for use
 // with apply.
 type WorkloadSelectorApplyConfiguration struct {
-	MatchLabels map[string]string          `json:"matchLabels,omitempty"`
-	PDGroup     *PDGroupApplyConfiguration `json:"pdGroup,omitempty"`
+	MatchLabels      map[string]string                               `json:"matchLabels,omitempty"`
+	MatchExpressions []v1.LabelSelectorRequirementApplyConfiguration `json:"matchExpressions,omitempty"`
+	PDGroup          *PDGroupApplyConfiguration                      `json:"pdGroup,omitempty"`
 }
 
 // WorkloadSelectorApplyConfiguration constructs a declarative configuration of the WorkloadSelector type for use with
@@ -45,6 +50,19 @@ func (b *WorkloadSelectorApplyConfiguration) WithMatchLabels(entries map[string]
 	return b
 }
 
+// WithMatchExpressions adds the given value to the MatchExpressions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the MatchExpressions field.
+func (b *WorkloadSelectorApplyConfiguration) WithMatchExpressions(values ...*v1.LabelSelectorRequirementApplyConfiguration) *WorkloadSelectorApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithMatchExpressions")
+		}
+		b.MatchExpressions = append(b.MatchExpressions, *values[i])
+	}
+	return b
+}
+
 // WithPDGroup sets the PDGroup field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the PDGroup field is set to the value of the last call.
@@ -34,44 +34,124 @@ import (
 func ForKind(kind schema.GroupVersionKind) interface{} {
 	switch kind {
 	// Group=networking.serving.volcano.sh, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithKind("BackendTLS"):
+		return &networkingv1alpha1.BackendTLSApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("BodyFieldMatch"):
+		return &networkingv1alpha1.BodyFieldMatchApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("BodyMatch"):
 		return &networkingv1alpha1.BodyMatchApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("BusinessHours"):
+		return &networkingv1alpha1.BusinessHoursApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("Concurrency"):
+		return &networkingv1alpha1.ConcurrencyApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ConsulDiscovery"):
+		return &networkingv1alpha1.ConsulDiscoveryApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("DNSDiscovery"):
+		return &networkingv1alpha1.DNSDiscoveryApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("DefaultParameters"):
+		return &networkingv1alpha1.DefaultParametersApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("Discovery"):
+		return &networkingv1alpha1.DiscoveryApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("EndpointSliceDiscovery"):
+		return &networkingv1alpha1.EndpointSliceDiscoveryApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("Evaluation"):
+		return &networkingv1alpha1.EvaluationApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("Fallback"):
+		return &networkingv1alpha1.FallbackApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("GlobalRateLimit"):
 		return &networkingv1alpha1.GlobalRateLimitApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("HeaderFilter"):
+		return &networkingv1alpha1.HeaderFilterApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("HeaderPolicy"):
+		return &networkingv1alpha1.HeaderPolicyApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("HealthCheck"):
+		return &networkingv1alpha1.HealthCheckApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("Hedging"):
+		return &networkingv1alpha1.HedgingApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("InteractiveReservation"):
+		return &networkingv1alpha1.InteractiveReservationApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("KVConnectorSpec"):
 		return &networkingv1alpha1.KVConnectorSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("LatencyObjective"):
+		return &networkingv1alpha1.LatencyObjectiveApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("Mirror"):
+		return &networkingv1alpha1.MirrorApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("ModelMatch"):
 		return &networkingv1alpha1.ModelMatchApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("ModelRoute"):
 		return &networkingv1alpha1.ModelRouteApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("ModelRouteSpec"):
 		return &networkingv1alpha1.ModelRouteSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ModelRouteStatus"):
+		return &networkingv1alpha1.ModelRouteStatusApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("ModelServer"):
 		return &networkingv1alpha1.ModelServerApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("ModelServerSpec"):
 		return &networkingv1alpha1.ModelServerSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("Observability"):
+		return &networkingv1alpha1.ObservabilityApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("PDGroup"):
 		return &networkingv1alpha1.PDGroupApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("PrefillCoalescing"):
+		return &networkingv1alpha1.PrefillCoalescingApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("Pricing"):
+		return &networkingv1alpha1.PricingApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("Priority"):
+		return &networkingv1alpha1.PriorityApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("RateLimit"):
 		return &networkingv1alpha1.RateLimitApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("RateLimitKey"):
+		return &networkingv1alpha1.RateLimitKeyApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("RateLimitPolicy"):
+		return &networkingv1alpha1.RateLimitPolicyApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("RateLimitPolicySpec"):
+		return &networkingv1alpha1.RateLimitPolicySpecApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("RedisConfig"):
 		return &networkingv1alpha1.RedisConfigApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("Retry"):
 		return &networkingv1alpha1.RetryApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("Rollout"):
+		return &networkingv1alpha1.RolloutApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("RolloutStatus"):
+		return &networkingv1alpha1.RolloutStatusApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("Rule"):
 		return &networkingv1alpha1.RuleApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("SecretKeyReference"):
+		return &networkingv1alpha1.SecretKeyReferenceApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("SessionAffinity"):
+		return &networkingv1alpha1.SessionAffinityApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("SLO"):
+		return &networkingv1alpha1.SLOApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("SlowStart"):
+		return &networkingv1alpha1.SlowStartApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("StaticDiscovery"):
+		return &networkingv1alpha1.StaticDiscoveryApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("StringMatch"):
 		return &networkingv1alpha1.StringMatchApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("StructuredOutput"):
+		return &networkingv1alpha1.StructuredOutputApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("TargetModel"):
 		return &networkingv1alpha1.TargetModelApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("Timeouts"):
+		return &networkingv1alpha1.TimeoutsApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("TokenQuota"):
+		return &networkingv1alpha1.TokenQuotaApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("TokenQuotaSpec"):
+		return &networkingv1alpha1.TokenQuotaSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("TokenQuotaStatus"):
+		return &networkingv1alpha1.TokenQuotaStatusApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("TrafficPolicy"):
 		return &networkingv1alpha1.TrafficPolicyApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WarmUp"):
+		return &networkingv1alpha1.WarmUpApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("WorkloadPort"):
 		return &networkingv1alpha1.WorkloadPortApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("WorkloadSelector"):
 		return &networkingv1alpha1.WorkloadSelectorApplyConfiguration{}
 
 		// Group=workload.serving.volcano.sh, Version=v1alpha1
+	case workloadv1alpha1.SchemeGroupVersion.WithKind("AcceleratorRequirements"):
+		return &applyconfigurationworkloadv1alpha1.AcceleratorRequirementsApplyConfiguration{}
 	case workloadv1alpha1.SchemeGroupVersion.WithKind("AutoscalingPolicy"):
 		return &applyconfigurationworkloadv1alpha1.AutoscalingPolicyApplyConfiguration{}
 	case workloadv1alpha1.SchemeGroupVersion.WithKind("AutoscalingPolicyBehavior"):
@@ -90,6 +170,8 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &applyconfigurationworkloadv1alpha1.AutoscalingPolicySpecApplyConfiguration{}
 	case workloadv1alpha1.SchemeGroupVersion.WithKind("AutoscalingPolicyStablePolicy"):
 		return &applyconfigurationworkloadv1alpha1.AutoscalingPolicyStablePolicyApplyConfiguration{}
+	case workloadv1alpha1.SchemeGroupVersion.WithKind("DisruptionBudget"):
+		return &applyconfigurationworkloadv1alpha1.DisruptionBudgetApplyConfiguration{}
 	case workloadv1alpha1.SchemeGroupVersion.WithKind("GangPolicy"):
 		return &applyconfigurationworkloadv1alpha1.GangPolicyApplyConfiguration{}
 	case workloadv1alpha1.SchemeGroupVersion.WithKind("HeterogeneousTarget"):
@@ -108,6 +190,12 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &applyconfigurationworkloadv1alpha1.ModelBoosterApplyConfiguration{}
 	case workloadv1alpha1.SchemeGroupVersion.WithKind("ModelBoosterSpec"):
 		return &applyconfigurationworkloadv1alpha1.ModelBoosterSpecApplyConfiguration{}
+	case workloadv1alpha1.SchemeGroupVersion.WithKind("ModelBundle"):
+		return &applyconfigurationworkloadv1alpha1.ModelBundleApplyConfiguration{}
+	case workloadv1alpha1.SchemeGroupVersion.WithKind("ModelBundleSpec"):
+		return &applyconfigurationworkloadv1alpha1.ModelBundleSpecApplyConfiguration{}
+	case workloadv1alpha1.SchemeGroupVersion.WithKind("ModelBundleStatus"):
+		return &applyconfigurationworkloadv1alpha1.ModelBundleStatusApplyConfiguration{}
 	case workloadv1alpha1.SchemeGroupVersion.WithKind("ModelServing"):
 		return &applyconfigurationworkloadv1alpha1.ModelServingApplyConfiguration{}
 	case workloadv1alpha1.SchemeGroupVersion.WithKind("ModelServingSpec"):
@@ -120,6 +208,10 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &applyconfigurationworkloadv1alpha1.ModelWorkerApplyConfiguration{}
 	case workloadv1alpha1.SchemeGroupVersion.WithKind("NetworkTopology"):
 		return &applyconfigurationworkloadv1alpha1.NetworkTopologyApplyConfiguration{}
+	case workloadv1alpha1.SchemeGroupVersion.WithKind("NodePool"):
+		return &applyconfigurationworkloadv1alpha1.NodePoolApplyConfiguration{}
+	case workloadv1alpha1.SchemeGroupVersion.WithKind("Placement"):
+		return &applyconfigurationworkloadv1alpha1.PlacementApplyConfiguration{}
 	case workloadv1alpha1.SchemeGroupVersion.WithKind("PluginScope"):
 		return &applyconfigurationworkloadv1alpha1.PluginScopeApplyConfiguration{}
 	case workloadv1alpha1.SchemeGroupVersion.WithKind("PluginSpec"):
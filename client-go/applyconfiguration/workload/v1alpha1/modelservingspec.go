@@ -25,12 +25,15 @@ import (
 // ModelServingSpecApplyConfiguration represents a declarative configuration of the ModelServingSpec type for use
 // with apply.
 type ModelServingSpecApplyConfiguration struct {
-	Replicas        *int32                             `json:"replicas,omitempty"`
-	SchedulerName   *string                            `json:"schedulerName,omitempty"`
-	Plugins         []PluginSpecApplyConfiguration     `json:"plugins,omitempty"`
-	Template        *ServingGroupApplyConfiguration    `json:"template,omitempty"`
-	RolloutStrategy *RolloutStrategyApplyConfiguration `json:"rolloutStrategy,omitempty"`
-	RecoveryPolicy  *workloadv1alpha1.RecoveryPolicy   `json:"recoveryPolicy,omitempty"`
+	Replicas         *int32                                     `json:"replicas,omitempty"`
+	SchedulerName    *string                                    `json:"schedulerName,omitempty"`
+	Plugins          []PluginSpecApplyConfiguration             `json:"plugins,omitempty"`
+	Template         *ServingGroupApplyConfiguration            `json:"template,omitempty"`
+	RolloutStrategy  *RolloutStrategyApplyConfiguration         `json:"rolloutStrategy,omitempty"`
+	RecoveryPolicy   *workloadv1alpha1.RecoveryPolicy           `json:"recoveryPolicy,omitempty"`
+	DisruptionBudget *DisruptionBudgetApplyConfiguration        `json:"disruptionBudget,omitempty"`
+	Accelerator      *AcceleratorRequirementsApplyConfiguration `json:"accelerator,omitempty"`
+	Placement        *PlacementApplyConfiguration               `json:"placement,omitempty"`
 }
 
 // ModelServingSpecApplyConfiguration constructs a declarative configuration of the ModelServingSpec type for use with
@@ -91,3 +94,27 @@ func (b *ModelServingSpecApplyConfiguration) WithRecoveryPolicy(value workloadv1
 	b.RecoveryPolicy = &value
 	return b
 }
+
+// WithDisruptionBudget sets the DisruptionBudget field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DisruptionBudget field is set to the value of the last call.
+func (b *ModelServingSpecApplyConfiguration) WithDisruptionBudget(value *DisruptionBudgetApplyConfiguration) *ModelServingSpecApplyConfiguration {
+	b.DisruptionBudget = value
+	return b
+}
+
+// WithAccelerator sets the Accelerator field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Accelerator field is set to the value of the last call.
+func (b *ModelServingSpecApplyConfiguration) WithAccelerator(value *AcceleratorRequirementsApplyConfiguration) *ModelServingSpecApplyConfiguration {
+	b.Accelerator = value
+	return b
+}
+
+// WithPlacement sets the Placement field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Placement field is set to the value of the last call.
+func (b *ModelServingSpecApplyConfiguration) WithPlacement(value *PlacementApplyConfiguration) *ModelServingSpecApplyConfiguration {
+	b.Placement = value
+	return b
+}
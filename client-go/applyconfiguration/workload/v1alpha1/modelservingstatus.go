@@ -19,21 +19,23 @@ limitations under the License.
 package v1alpha1
 
 import (
+	resource "k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/client-go/applyconfigurations/meta/v1"
 )
 
 // ModelServingStatusApplyConfiguration represents a declarative configuration of the ModelServingStatus type for use
 // with apply.
 type ModelServingStatusApplyConfiguration struct {
-	ObservedGeneration *int64                           `json:"observedGeneration,omitempty"`
-	Replicas           *int32                           `json:"replicas,omitempty"`
-	CurrentReplicas    *int32                           `json:"currentReplicas,omitempty"`
-	UpdatedReplicas    *int32                           `json:"updatedReplicas,omitempty"`
-	AvailableReplicas  *int32                           `json:"availableReplicas,omitempty"`
-	CurrentRevision    *string                          `json:"currentRevision,omitempty"`
-	UpdateRevision     *string                          `json:"updateRevision,omitempty"`
-	Conditions         []v1.ConditionApplyConfiguration `json:"conditions,omitempty"`
-	LabelSelector      *string                          `json:"labelSelector,omitempty"`
+	ObservedGeneration  *int64                           `json:"observedGeneration,omitempty"`
+	Replicas            *int32                           `json:"replicas,omitempty"`
+	CurrentReplicas     *int32                           `json:"currentReplicas,omitempty"`
+	UpdatedReplicas     *int32                           `json:"updatedReplicas,omitempty"`
+	AvailableReplicas   *int32                           `json:"availableReplicas,omitempty"`
+	CurrentRevision     *string                          `json:"currentRevision,omitempty"`
+	UpdateRevision      *string                          `json:"updateRevision,omitempty"`
+	Conditions          []v1.ConditionApplyConfiguration `json:"conditions,omitempty"`
+	LabelSelector       *string                          `json:"labelSelector,omitempty"`
+	ProjectedHourlyCost *resource.Quantity               `json:"projectedHourlyCost,omitempty"`
 }
 
 // ModelServingStatusApplyConfiguration constructs a declarative configuration of the ModelServingStatus type for use with
@@ -118,3 +120,11 @@ func (b *ModelServingStatusApplyConfiguration) WithLabelSelector(value string) *
 	b.LabelSelector = &value
 	return b
 }
+
+// WithProjectedHourlyCost sets the ProjectedHourlyCost field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ProjectedHourlyCost field is set to the value of the last call.
+func (b *ModelServingStatusApplyConfiguration) WithProjectedHourlyCost(value resource.Quantity) *ModelServingStatusApplyConfiguration {
+	b.ProjectedHourlyCost = &value
+	return b
+}
@@ -36,6 +36,14 @@ func (c *FakeNetworkingV1alpha1) ModelServers(namespace string) v1alpha1.ModelSe
 	return newFakeModelServers(c, namespace)
 }
 
+func (c *FakeNetworkingV1alpha1) RateLimitPolicies(namespace string) v1alpha1.RateLimitPolicyInterface {
+	return newFakeRateLimitPolicies(c, namespace)
+}
+
+func (c *FakeNetworkingV1alpha1) TokenQuotas(namespace string) v1alpha1.TokenQuotaInterface {
+	return newFakeTokenQuotas(c, namespace)
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeNetworkingV1alpha1) RESTClient() rest.Interface {
@@ -21,3 +21,7 @@ package v1alpha1
 type ModelRouteExpansion interface{}
 
 type ModelServerExpansion interface{}
+
+type RateLimitPolicyExpansion interface{}
+
+type TokenQuotaExpansion interface{}
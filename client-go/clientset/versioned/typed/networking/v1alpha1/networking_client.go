@@ -30,6 +30,8 @@ type NetworkingV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	ModelRoutesGetter
 	ModelServersGetter
+	RateLimitPoliciesGetter
+	TokenQuotasGetter
 }
 
 // NetworkingV1alpha1Client is used to interact with features provided by the networking.serving.volcano.sh group.
@@ -45,6 +47,14 @@ func (c *NetworkingV1alpha1Client) ModelServers(namespace string) ModelServerInt
 	return newModelServers(c, namespace)
 }
 
+func (c *NetworkingV1alpha1Client) RateLimitPolicies(namespace string) RateLimitPolicyInterface {
+	return newRateLimitPolicies(c, namespace)
+}
+
+func (c *NetworkingV1alpha1Client) TokenQuotas(namespace string) TokenQuotaInterface {
+	return newTokenQuotas(c, namespace)
+}
+
 // NewForConfig creates a new NetworkingV1alpha1Client for the given config.
 // NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
 // where httpClient was generated with rest.HTTPClientFor(c).
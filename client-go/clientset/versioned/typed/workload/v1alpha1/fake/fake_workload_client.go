@@ -40,6 +40,10 @@ func (c *FakeWorkloadV1alpha1) ModelBoosters(namespace string) v1alpha1.ModelBoo
 	return newFakeModelBoosters(c, namespace)
 }
 
+func (c *FakeWorkloadV1alpha1) ModelBundles(namespace string) v1alpha1.ModelBundleInterface {
+	return newFakeModelBundles(c, namespace)
+}
+
 func (c *FakeWorkloadV1alpha1) ModelServings(namespace string) v1alpha1.ModelServingInterface {
 	return newFakeModelServings(c, namespace)
 }
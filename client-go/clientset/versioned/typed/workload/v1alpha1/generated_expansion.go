@@ -24,4 +24,6 @@ type AutoscalingPolicyBindingExpansion interface{}
 
 type ModelBoosterExpansion interface{}
 
+type ModelBundleExpansion interface{}
+
 type ModelServingExpansion interface{}
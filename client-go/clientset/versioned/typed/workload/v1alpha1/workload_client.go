@@ -31,6 +31,7 @@ type WorkloadV1alpha1Interface interface {
 	AutoscalingPoliciesGetter
 	AutoscalingPolicyBindingsGetter
 	ModelBoostersGetter
+	ModelBundlesGetter
 	ModelServingsGetter
 }
 
@@ -51,6 +52,10 @@ func (c *WorkloadV1alpha1Client) ModelBoosters(namespace string) ModelBoosterInt
 	return newModelBoosters(c, namespace)
 }
 
+func (c *WorkloadV1alpha1Client) ModelBundles(namespace string) ModelBundleInterface {
+	return newModelBundles(c, namespace)
+}
+
 func (c *WorkloadV1alpha1Client) ModelServings(namespace string) ModelServingInterface {
 	return newModelServings(c, namespace)
 }
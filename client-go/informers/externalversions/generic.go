@@ -58,6 +58,10 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1alpha1().ModelRoutes().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("modelservers"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1alpha1().ModelServers().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("ratelimitpolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1alpha1().RateLimitPolicies().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("tokenquotas"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1alpha1().TokenQuotas().Informer()}, nil
 
 		// Group=workload.serving.volcano.sh, Version=v1alpha1
 	case workloadv1alpha1.SchemeGroupVersion.WithResource("autoscalingpolicies"):
@@ -66,6 +70,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Workload().V1alpha1().AutoscalingPolicyBindings().Informer()}, nil
 	case workloadv1alpha1.SchemeGroupVersion.WithResource("modelboosters"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Workload().V1alpha1().ModelBoosters().Informer()}, nil
+	case workloadv1alpha1.SchemeGroupVersion.WithResource("modelbundles"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Workload().V1alpha1().ModelBundles().Informer()}, nil
 	case workloadv1alpha1.SchemeGroupVersion.WithResource("modelservings"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Workload().V1alpha1().ModelServings().Informer()}, nil
 
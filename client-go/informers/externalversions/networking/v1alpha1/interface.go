@@ -28,6 +28,10 @@ type Interface interface {
 	ModelRoutes() ModelRouteInformer
 	// ModelServers returns a ModelServerInformer.
 	ModelServers() ModelServerInformer
+	// RateLimitPolicies returns a RateLimitPolicyInformer.
+	RateLimitPolicies() RateLimitPolicyInformer
+	// TokenQuotas returns a TokenQuotaInformer.
+	TokenQuotas() TokenQuotaInformer
 }
 
 type version struct {
@@ -50,3 +54,13 @@ func (v *version) ModelRoutes() ModelRouteInformer {
 func (v *version) ModelServers() ModelServerInformer {
 	return &modelServerInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// RateLimitPolicies returns a RateLimitPolicyInformer.
+func (v *version) RateLimitPolicies() RateLimitPolicyInformer {
+	return &rateLimitPolicyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// TokenQuotas returns a TokenQuotaInformer.
+func (v *version) TokenQuotas() TokenQuotaInformer {
+	return &tokenQuotaInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
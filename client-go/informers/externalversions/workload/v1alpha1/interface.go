@@ -30,6 +30,8 @@ type Interface interface {
 	AutoscalingPolicyBindings() AutoscalingPolicyBindingInformer
 	// ModelBoosters returns a ModelBoosterInformer.
 	ModelBoosters() ModelBoosterInformer
+	// ModelBundles returns a ModelBundleInformer.
+	ModelBundles() ModelBundleInformer
 	// ModelServings returns a ModelServingInformer.
 	ModelServings() ModelServingInformer
 }
@@ -60,6 +62,11 @@ func (v *version) ModelBoosters() ModelBoosterInformer {
 	return &modelBoosterInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// ModelBundles returns a ModelBundleInformer.
+func (v *version) ModelBundles() ModelBundleInformer {
+	return &modelBundleInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // ModelServings returns a ModelServingInformer.
 func (v *version) ModelServings() ModelServingInformer {
 	return &modelServingInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
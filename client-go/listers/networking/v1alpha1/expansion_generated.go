@@ -33,3 +33,19 @@ type ModelServerListerExpansion interface{}
 // ModelServerNamespaceListerExpansion allows custom methods to be added to
 // ModelServerNamespaceLister.
 type ModelServerNamespaceListerExpansion interface{}
+
+// RateLimitPolicyListerExpansion allows custom methods to be added to
+// RateLimitPolicyLister.
+type RateLimitPolicyListerExpansion interface{}
+
+// RateLimitPolicyNamespaceListerExpansion allows custom methods to be added to
+// RateLimitPolicyNamespaceLister.
+type RateLimitPolicyNamespaceListerExpansion interface{}
+
+// TokenQuotaListerExpansion allows custom methods to be added to
+// TokenQuotaLister.
+type TokenQuotaListerExpansion interface{}
+
+// TokenQuotaNamespaceListerExpansion allows custom methods to be added to
+// TokenQuotaNamespaceLister.
+type TokenQuotaNamespaceListerExpansion interface{}
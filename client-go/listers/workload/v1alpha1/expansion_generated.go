@@ -42,6 +42,14 @@ type ModelBoosterListerExpansion interface{}
 // ModelBoosterNamespaceLister.
 type ModelBoosterNamespaceListerExpansion interface{}
 
+// ModelBundleListerExpansion allows custom methods to be added to
+// ModelBundleLister.
+type ModelBundleListerExpansion interface{}
+
+// ModelBundleNamespaceListerExpansion allows custom methods to be added to
+// ModelBundleNamespaceLister.
+type ModelBundleNamespaceListerExpansion interface{}
+
 // ModelServingListerExpansion allows custom methods to be added to
 // ModelServingLister.
 type ModelServingListerExpansion interface{}
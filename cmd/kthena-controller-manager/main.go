@@ -29,12 +29,18 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/pflag"
 	clientset "github.com/volcano-sh/kthena/client-go/clientset/versioned"
 	"github.com/volcano-sh/kthena/pkg/controller"
+	"github.com/volcano-sh/kthena/pkg/controller/metrics"
+	garbagecollection "github.com/volcano-sh/kthena/pkg/garbage-collection-controller/controller"
 	modelboosterwebhook "github.com/volcano-sh/kthena/pkg/model-booster-controller/webhook"
 	modelservingwebhook "github.com/volcano-sh/kthena/pkg/model-serving-controller/webhook"
+	rollout "github.com/volcano-sh/kthena/pkg/rollout-controller/controller"
+	"github.com/volcano-sh/kthena/pkg/tlsconfig"
 	webhookcert "github.com/volcano-sh/kthena/pkg/webhook/cert"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
@@ -49,13 +55,17 @@ type webhookConfig struct {
 	serviceName    string
 	kubeAPIQPS     float32
 	kubeAPIBurst   int
+	tlsConfig      *tls.Config
 }
 
 func main() {
 	var enableWebhook bool
+	var metricsPort int
 	var wc webhookConfig
 	var cc controller.Config
 	var controllers []string
+	var tlsMinVersion string
+	var tlsCipherSuites []string
 	// Initialize klog flags
 	klog.InitFlags(nil)
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
@@ -70,14 +80,53 @@ func main() {
 	pflag.StringVar(&wc.serviceName, "service-name", "kthena-controller-manager-webhook", "Service name for the webhook server")
 	pflag.BoolVar(&cc.EnableLeaderElection, "leader-elect", false, "Enable leader election for controller. "+
 		"Enabling this will ensure there is only one active controller. Default is false.")
+	pflag.DurationVar(&cc.LeaseDuration, "leader-elect-lease-duration", 15*time.Second, "The duration that non-leader candidates will wait "+
+		"after observing a leadership renewal until attempting to acquire leadership.")
+	pflag.DurationVar(&cc.RenewDeadline, "leader-elect-renew-deadline", 10*time.Second, "The interval between attempts by the acting leader "+
+		"to renew its leadership before it stops leading. Must be less than the lease duration.")
+	pflag.DurationVar(&cc.RetryPeriod, "leader-elect-retry-period", 2*time.Second, "The duration the candidates should wait between "+
+		"attempts to acquire or renew leadership.")
+	pflag.BoolVar(&cc.WarmStandby, "leader-elect-warm-standby", true, "If true, instances which are not leading keep the informer "+
+		"caches of the controllers in sync, so that failover doesn't wait for a full resync. Only used with --leader-elect.")
 	pflag.IntVar(&cc.Workers, "workers", 5, "number of workers to run. Default is 5")
+	pflag.StringToIntVar(&cc.ControllerWorkers, "controller-workers", nil, "Number of workers of each controller, overriding --workers, "+
+		"e.g. 'modelserving=20,modelbooster=2'.")
+	pflag.DurationVar(&cc.Informers.ResyncPeriod, "informer-resync-period", 0, "The resync period of the informers of the controllers. If 0, informers don't resync.")
+	pflag.StringVar(&cc.Informers.Namespace, "watch-namespace", "", "Namespace the controllers watch and reconcile resources in. If empty, all namespaces are watched.")
+	pflag.StringVar(&cc.Informers.LabelSelector, "watch-label-selector", "", "Label selector of the ModelBoosters, ModelServings, "+
+		"LeaderWorkerSets and AutoscalingPolicyBindings reconciled by the controllers, e.g. 'team=a'. If empty, all of them are reconciled.")
+	pflag.IntVar(&metricsPort, "metrics-port", 8080, "Port that the metrics endpoint listens on. If 0, metrics are not served.")
 	pflag.StringSliceVar(&controllers, "controllers", []string{"*"}, "A list of controllers to enable. '*' enables all controllers, 'foo' enables the controller "+
-		"named 'foo', '-foo' disables the controller named 'foo'.\nIf both '+foo' and '-foo' are set simultaneously, then controller named 'foo' will be enabled.\nAll controllers: 'modelserving', 'modelbooster', 'autoscaler'")
+		"named 'foo', '-foo' disables the controller named 'foo'.\nIf both '+foo' and '-foo' are set simultaneously, then controller named 'foo' will be enabled.\nAll controllers: 'modelserving', 'modelbooster', 'autoscaler', 'storagemigration', 'garbagecollection', 'rollout', 'bundle', 'tenantrouter'")
+	pflag.DurationVar(&cc.GarbageCollection.Interval, "garbage-collection-interval", garbagecollection.DefaultInterval, "The interval of the "+
+		"collection of the orphaned resources generated by the controllers. An orphan is deleted when found by two consecutive collections.")
+	pflag.BoolVar(&cc.GarbageCollection.DryRun, "garbage-collection-dry-run", false, "If true, the orphaned resources generated by the "+
+		"controllers are reported in the logs and metrics but not deleted.")
+	pflag.DurationVar(&cc.Rollout.Interval, "rollout-interval", rollout.DefaultInterval, "The interval of the reconciliation of the "+
+		"rollouts of the ModelRoutes. The canaries are analyzed at the interval of their rollout.")
+	pflag.StringVar(&cc.Rollout.PrometheusAddress, "rollout-prometheus-address", "", "Address of the Prometheus server scraping the "+
+		"routers the canaries of the rollouts are analyzed with, e.g. 'http://prometheus:9090'. If empty, the rollout controller is disabled.")
 	pflag.Float32Var(&cc.KubeAPIQPS, "kube-api-qps", 0, "QPS to use while talking with kubernetes apiserver. If 0, use default value.")
 	pflag.IntVar(&cc.KubeAPIBurst, "kube-api-burst", 0, "Burst to use while talking with kubernetes apiserver. If 0, use default value.")
+	pflag.StringVar(&tlsMinVersion, "tls-min-version", tlsconfig.DefaultMinVersion, "Minimum TLS version accepted by the webhook server. One of: 1.2, 1.3.")
+	pflag.StringSliceVar(&tlsCipherSuites, "tls-cipher-suites", nil, "Comma-separated list of TLS 1.2 cipher suites accepted by the webhook server, "+
+		"e.g. 'TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256'. If empty, the Go default cipher suites are used.")
 	pflag.Parse()
 
 	cc.Controllers = parseControllers(controllers)
+	if _, err := labels.Parse(cc.Informers.LabelSelector); err != nil {
+		klog.Fatalf("invalid watch label selector %q: %v", cc.Informers.LabelSelector, err)
+	}
+	tlsConfig, err := tlsconfig.New(tlsMinVersion, tlsCipherSuites)
+	if err != nil {
+		klog.Fatalf("invalid TLS configuration: %v", err)
+	}
+	wc.tlsConfig = tlsConfig
+	if tlsconfig.FIPSEnabled() {
+		klog.Info("FIPS 140-3 mode is enabled")
+	}
+	// Workqueues report their metrics only if created after the provider is set.
+	metrics.RegisterWorkqueueMetrics()
 
 	pflag.CommandLine.VisitAll(func(f *pflag.Flag) {
 		klog.Infof("Flag: %s, Value: %s", f.Name, f.Value.String())
@@ -100,9 +149,33 @@ func main() {
 			}
 		}()
 	}
+	if metricsPort > 0 {
+		go serveMetrics(ctx, metricsPort)
+	}
 	controller.SetupController(ctx, cc)
 }
 
+// serveMetrics serves the Prometheus metrics until ctx is done.
+func serveMetrics(ctx context.Context, port int) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		klog.Infof("Starting metrics server on %s", server.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			klog.Errorf("failed to start metrics server: %v", err)
+		}
+	}()
+	<-ctx.Done()
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = server.Shutdown(ctxTimeout)
+}
+
 const validatingWebhookName = "kthena-controller-manager-validating-webhook"
 const mutatingWebhookName = "kthena-controller-manager-mutating-webhook"
 
@@ -204,9 +277,7 @@ func setupWebhook(ctx context.Context, wc webhookConfig) error {
 		Handler:      mux,
 		ReadTimeout:  time.Duration(wc.webhookTimeout) * time.Second,
 		WriteTimeout: time.Duration(wc.webhookTimeout) * time.Second,
-		TLSConfig: &tls.Config{
-			MinVersion: tls.VersionTLS12,
-		},
+		TLSConfig:    wc.tlsConfig,
 	}
 
 	// Wait for both cert and key files to exist (in case they are mounted by Kubernetes)
@@ -215,9 +286,17 @@ func setupWebhook(ctx context.Context, wc webhookConfig) error {
 		return fmt.Errorf("TLS cert/key files not found, webhook server cannot start")
 	}
 
+	// The certificate is reloaded when it is rotated
+	reloader, err := tlsconfig.NewCertReloader(wc.tlsCertFile, wc.tlsPrivateKey)
+	if err != nil {
+		return fmt.Errorf("failed to load webhook certificate: %v", err)
+	}
+	go reloader.Run(ctx)
+	server.TLSConfig = reloader.ServerConfig(server.TLSConfig)
+
 	go func() {
 		klog.Infof("Starting webhook server on %s", server.Addr)
-		if err := server.ListenAndServeTLS(wc.tlsCertFile, wc.tlsPrivateKey); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		if err := server.ListenAndServeTLS("", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			klog.Fatalf("failed to start unified webhook server: %v", err)
 		}
 	}()
@@ -261,9 +340,14 @@ func waitForCertsReady(keyFile, CertFile string) bool {
 func parseControllers(controllers []string) map[string]bool {
 	// defaultControllers defines all available controllers as enabled
 	defaultControllers := map[string]bool{
-		controller.ModelServingController: true,
-		controller.ModelBoosterController: true,
-		controller.AutoscalerController:   true,
+		controller.ModelServingController:      true,
+		controller.ModelBoosterController:      true,
+		controller.AutoscalerController:        true,
+		controller.StorageMigrationController:  true,
+		controller.GarbageCollectionController: true,
+		controller.RolloutController:           true,
+		controller.BundleController:            true,
+		controller.TenantRouterController:      true,
 	}
 
 	enableControllers := make(map[string]bool)
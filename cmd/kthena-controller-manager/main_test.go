@@ -34,18 +34,28 @@ func TestParseControllers(t *testing.T) {
 			name:  "wildcard_only",
 			input: []string{"*"},
 			expected: map[string]bool{
-				controller.ModelServingController: true,
-				controller.ModelBoosterController: true,
-				controller.AutoscalerController:   true,
+				controller.ModelServingController:      true,
+				controller.ModelBoosterController:      true,
+				controller.AutoscalerController:        true,
+				controller.StorageMigrationController:  true,
+				controller.GarbageCollectionController: true,
+				controller.RolloutController:           true,
+				controller.BundleController:            true,
+				controller.TenantRouterController:      true,
 			},
 		},
 		{
 			name:  "wildcard_with_other_controllers",
 			input: []string{"*", "modelserving"},
 			expected: map[string]bool{
-				controller.ModelServingController: true,
-				controller.ModelBoosterController: true,
-				controller.AutoscalerController:   true,
+				controller.ModelServingController:      true,
+				controller.ModelBoosterController:      true,
+				controller.AutoscalerController:        true,
+				controller.StorageMigrationController:  true,
+				controller.GarbageCollectionController: true,
+				controller.RolloutController:           true,
+				controller.BundleController:            true,
+				controller.TenantRouterController:      true,
 			},
 		},
 		{
@@ -69,6 +79,13 @@ func TestParseControllers(t *testing.T) {
 				controller.AutoscalerController: true,
 			},
 		},
+		{
+			name:  "single_controller_storagemigration",
+			input: []string{"storagemigration"},
+			expected: map[string]bool{
+				controller.StorageMigrationController: true,
+			},
+		},
 		{
 			name:  "multiple_controllers",
 			input: []string{"modelserving", "modelbooster"},
@@ -79,11 +96,13 @@ func TestParseControllers(t *testing.T) {
 		},
 		{
 			name:  "all_controllers_explicit",
-			input: []string{"modelserving", "modelbooster", "autoscaler"},
+			input: []string{"modelserving", "modelbooster", "autoscaler", "storagemigration", "garbagecollection"},
 			expected: map[string]bool{
-				controller.ModelServingController: true,
-				controller.ModelBoosterController: true,
-				controller.AutoscalerController:   true,
+				controller.ModelServingController:      true,
+				controller.ModelBoosterController:      true,
+				controller.AutoscalerController:        true,
+				controller.StorageMigrationController:  true,
+				controller.GarbageCollectionController: true,
 			},
 		},
 		{
@@ -98,9 +117,14 @@ func TestParseControllers(t *testing.T) {
 			name:  "invalid_controller_name",
 			input: []string{"invalid"},
 			expected: map[string]bool{
-				controller.ModelServingController: true,
-				controller.ModelBoosterController: true,
-				controller.AutoscalerController:   true,
+				controller.ModelServingController:      true,
+				controller.ModelBoosterController:      true,
+				controller.AutoscalerController:        true,
+				controller.StorageMigrationController:  true,
+				controller.GarbageCollectionController: true,
+				controller.RolloutController:           true,
+				controller.BundleController:            true,
+				controller.TenantRouterController:      true,
 			},
 		},
 		{
@@ -114,18 +138,28 @@ func TestParseControllers(t *testing.T) {
 			name:  "only_commas",
 			input: []string{",,"},
 			expected: map[string]bool{
-				controller.ModelServingController: true,
-				controller.ModelBoosterController: true,
-				controller.AutoscalerController:   true,
+				controller.ModelServingController:      true,
+				controller.ModelBoosterController:      true,
+				controller.AutoscalerController:        true,
+				controller.StorageMigrationController:  true,
+				controller.GarbageCollectionController: true,
+				controller.RolloutController:           true,
+				controller.BundleController:            true,
+				controller.TenantRouterController:      true,
 			},
 		},
 		{
 			name:  "invalid_with_no_valid_controllers",
 			input: []string{"invalid1", "invalid2"},
 			expected: map[string]bool{
-				controller.ModelServingController: true,
-				controller.ModelBoosterController: true,
-				controller.AutoscalerController:   true,
+				controller.ModelServingController:      true,
+				controller.ModelBoosterController:      true,
+				controller.AutoscalerController:        true,
+				controller.StorageMigrationController:  true,
+				controller.GarbageCollectionController: true,
+				controller.RolloutController:           true,
+				controller.BundleController:            true,
+				controller.TenantRouterController:      true,
 			},
 		},
 		{
@@ -139,9 +173,14 @@ func TestParseControllers(t *testing.T) {
 			name:  "invalid_controller",
 			input: []string{"*modelserving"},
 			expected: map[string]bool{
-				controller.ModelServingController: true,
-				controller.ModelBoosterController: true,
-				controller.AutoscalerController:   true,
+				controller.ModelServingController:      true,
+				controller.ModelBoosterController:      true,
+				controller.AutoscalerController:        true,
+				controller.StorageMigrationController:  true,
+				controller.GarbageCollectionController: true,
+				controller.RolloutController:           true,
+				controller.BundleController:            true,
+				controller.TenantRouterController:      true,
 			},
 		},
 	}
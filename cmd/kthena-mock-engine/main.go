@@ -0,0 +1,96 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/mockengine"
+)
+
+func main() {
+	var (
+		port   string
+		config mockengine.Config
+	)
+
+	// MODEL_NAME is supported for compatibility with the previous mock images.
+	defaultModels := []string{mockengine.DefaultModel}
+	if model := os.Getenv("MODEL_NAME"); model != "" {
+		defaultModels = []string{model}
+	}
+
+	klog.InitFlags(nil)
+	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
+	pflag.StringVar(&port, "port", "8000", "Server listen port, serving the OpenAI-compatible API and the metrics")
+	pflag.StringSliceVar(&config.Models, "models", defaultModels, "Comma-separated list of the served models. Defaults to the MODEL_NAME environment variable if set.")
+	pflag.BoolVar(&config.StrictModels, "strict-models", false, "Reject the requests for models which are neither served nor loaded LoRA adapters, instead of serving any model.")
+	pflag.DurationVar(&config.TTFT, "ttft", mockengine.DefaultTTFT, "Time to the first token of a response")
+	pflag.DurationVar(&config.TPOT, "tpot", mockengine.DefaultTPOT, "Time per output token after the first one")
+	pflag.Float64Var(&config.Jitter, "jitter", 0, "Fraction, between 0 and 1, by which the latencies randomly vary")
+	pflag.IntVar(&config.OutputTokens, "output-tokens", mockengine.DefaultOutputTokens, "Number of tokens of a response, unless the request sets a lower max_tokens")
+	pflag.IntVar(&config.MaxRunningRequests, "max-running-requests", mockengine.DefaultMaxRunningRequests, "Number of requests generated concurrently, the others wait")
+	pflag.Float64Var(&config.FailureRate, "failure-rate", 0, "Fraction, between 0 and 1, of the requests failing with --failure-status")
+	pflag.IntVar(&config.FailureStatus, "failure-status", http.StatusInternalServerError, "HTTP status of the failed requests")
+	defer klog.Flush()
+	pflag.Parse()
+
+	if config.Jitter < 0 || config.Jitter > 1 {
+		klog.Fatalf("invalid jitter: %v", config.Jitter)
+	}
+	if config.FailureRate < 0 || config.FailureRate > 1 {
+		klog.Fatalf("invalid failure rate: %v", config.FailureRate)
+	}
+	if config.FailureStatus < 400 || config.FailureStatus > 599 {
+		klog.Fatalf("invalid failure status: %d", config.FailureStatus)
+	}
+
+	pflag.CommandLine.VisitAll(func(f *pflag.Flag) {
+		klog.Infof("Flag: %s, Value: %s", f.Name, f.Value.String())
+	})
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	server := &http.Server{
+		Addr:    ":" + port,
+		Handler: mockengine.New(config).Handler(),
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			klog.Errorf("Failed to shutdown server: %v", err)
+		}
+	}()
+
+	klog.Infof("Serving models %v on port %s", config.Models, port)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		klog.Fatalf("Failed to start server: %v", err)
+	}
+}
@@ -24,12 +24,12 @@ import (
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog/v2"
+	inferenceinformers "sigs.k8s.io/gateway-api-inference-extension/client-go/informers/externalversions"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gatewayclientset "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
 	gatewayinformers "sigs.k8s.io/gateway-api/pkg/client/informers/externalversions"
@@ -38,6 +38,7 @@ import (
 	kthenaInformers "github.com/volcano-sh/kthena/client-go/informers/externalversions"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/controller"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/gatewayapi"
 )
 
 type Controller interface {
@@ -50,19 +51,8 @@ type aggregatedController struct {
 
 var _ Controller = &aggregatedController{}
 
-func startControllers(store datastore.Store, stop <-chan struct{}, enableGatewayAPI bool, defaultPort string, enableGatewayAPIInferenceExtension bool, kubeAPIQPS float32, kubeAPIBurst int) Controller {
-	cfg, err := clientcmd.BuildConfigFromFlags("", "")
-	if err != nil {
-		klog.Fatalf("Error building kubeconfig: %s", err.Error())
-	}
-	// Set QPS and Burst if provided
-	if kubeAPIQPS > 0 {
-		cfg.QPS = kubeAPIQPS
-	}
-	if kubeAPIBurst > 0 {
-		cfg.Burst = kubeAPIBurst
-	}
-
+func startControllers(store datastore.Store, stop <-chan struct{}, enableGatewayAPI bool, defaultPort string, enableGatewayAPIInferenceExtension bool, kubeAPIQPS float32, kubeAPIBurst int, modelRouteSelector, watchNamespace, podSelector string, watchModelServings, watchSecrets, watchRateLimitPolicies, watchTokenQuotas, watchEndpointSlices bool) Controller {
+	cfg := buildKubeConfig(kubeAPIQPS, kubeAPIBurst)
 	kubeClient, err := kubernetes.NewForConfig(cfg)
 	if err != nil {
 		klog.Fatalf("Error building kubernetes clientset: %s", err.Error())
@@ -73,14 +63,68 @@ func startControllers(store datastore.Store, stop <-chan struct{}, enableGateway
 		klog.Fatalf("Error building kthena clientset: %s", err.Error())
 	}
 
-	kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
-	kthenaInformerFactory := kthenaInformers.NewSharedInformerFactory(kthenaClient, 0)
+	if watchNamespace != "" {
+		klog.Infof("Only watching resources in namespace %q", watchNamespace)
+	}
+	// Pods are the largest watched resource, they may be scoped by a label selector matching the model server pods only.
+	kubeInformerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, 0,
+		informers.WithNamespace(watchNamespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = podSelector
+		}))
+	kthenaInformerFactory := kthenaInformers.NewSharedInformerFactoryWithOptions(kthenaClient, 0, kthenaInformers.WithNamespace(watchNamespace))
+
+	// ModelRoutes may be scoped by a label selector, e.g. for tenant routers,
+	// so they get a dedicated informer factory to leave the other resources unfiltered.
+	modelRouteInformerFactory := kthenaInformerFactory
+	if modelRouteSelector != "" {
+		klog.Infof("Only watching ModelRoutes matching label selector %q", modelRouteSelector)
+		modelRouteInformerFactory = kthenaInformers.NewSharedInformerFactoryWithOptions(kthenaClient, 0,
+			kthenaInformers.WithNamespace(watchNamespace),
+			kthenaInformers.WithTweakListOptions(func(options *metav1.ListOptions) {
+				options.LabelSelector = modelRouteSelector
+			}))
+	}
+
+	modelRouteController := controller.NewModelRouteController(modelRouteInformerFactory, store)
+	// EndpointSlices get a dedicated informer factory, the pod selector doesn't apply to them.
+	var endpointSliceInformerFactory informers.SharedInformerFactory
+	if watchEndpointSlices {
+		endpointSliceInformerFactory = informers.NewSharedInformerFactoryWithOptions(kubeClient, 0, informers.WithNamespace(watchNamespace))
+	}
+	modelServerController := controller.NewModelServerController(kthenaInformerFactory, kubeInformerFactory, endpointSliceInformerFactory, store)
+	// ModelServings only add metadata to the model catalog, the router doesn't wait for them to be ready
+	var modelServingController *controller.ModelServingController
+	if watchModelServings {
+		modelServingController = controller.NewModelServingController(kthenaInformerFactory, store)
+	}
+
+	var rateLimitPolicyController *controller.RateLimitPolicyController
+	if watchRateLimitPolicies {
+		rateLimitPolicyController = controller.NewRateLimitPolicyController(kthenaInformerFactory, store)
+	}
+
+	var tokenQuotaController *controller.TokenQuotaController
+	if watchTokenQuotas {
+		tokenQuotaController = controller.NewTokenQuotaController(kthenaInformerFactory, store)
+	}
 
-	modelRouteController := controller.NewModelRouteController(kthenaInformerFactory, store)
-	modelServerController := controller.NewModelServerController(kthenaInformerFactory, kubeInformerFactory, store)
+	// Secrets get a dedicated informer factory, the pod selector doesn't apply to them.
+	var secretController *controller.SecretController
+	if watchSecrets {
+		secretInformerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, 0, informers.WithNamespace(watchNamespace))
+		secretController = controller.NewSecretController(secretInformerFactory, kthenaInformerFactory, store)
+		secretInformerFactory.Start(stop)
+	}
 
 	kubeInformerFactory.Start(stop)
 	kthenaInformerFactory.Start(stop)
+	if endpointSliceInformerFactory != nil {
+		endpointSliceInformerFactory.Start(stop)
+	}
+	if modelRouteInformerFactory != kthenaInformerFactory {
+		modelRouteInformerFactory.Start(stop)
+	}
 
 	go func() {
 		if err := modelRouteController.Run(stop); err != nil {
@@ -94,17 +138,50 @@ func startControllers(store datastore.Store, stop <-chan struct{}, enableGateway
 		}
 	}()
 
+	if modelServingController != nil {
+		go func() {
+			if err := modelServingController.Run(stop); err != nil {
+				klog.Fatalf("Error running model serving controller: %s", err.Error())
+			}
+		}()
+	}
+
 	controllers := []Controller{
 		modelRouteController,
 		modelServerController,
 	}
+	if rateLimitPolicyController != nil {
+		go func() {
+			if err := rateLimitPolicyController.Run(stop); err != nil {
+				klog.Fatalf("Error running rate limit policy controller: %s", err.Error())
+			}
+		}()
+		controllers = append(controllers, rateLimitPolicyController)
+	}
+	if tokenQuotaController != nil {
+		go func() {
+			if err := tokenQuotaController.Run(stop); err != nil {
+				klog.Fatalf("Error running token quota controller: %s", err.Error())
+			}
+		}()
+		controllers = append(controllers, tokenQuotaController)
+	}
+	if secretController != nil {
+		go func() {
+			if err := secretController.Run(stop); err != nil {
+				klog.Fatalf("Error running secret controller: %s", err.Error())
+			}
+		}()
+		controllers = append(controllers, secretController)
+	}
 
 	// Gateway API controllers are optional
 	if enableGatewayAPI {
-		gatewayClient, err := gatewayclientset.NewForConfig(cfg)
+		gatewayClients, err := gatewayapi.NewClients(cfg)
 		if err != nil {
-			klog.Fatalf("Error building gateway clientset: %s", err.Error())
+			klog.Fatalf("Error building gateway clientsets: %s", err.Error())
 		}
+		gatewayClient := gatewayClients.Gateway
 
 		// Ensure default GatewayClass exists before starting controllers
 		if err := ensureDefaultGatewayClass(gatewayClient); err != nil {
@@ -112,19 +189,25 @@ func startControllers(store datastore.Store, stop <-chan struct{}, enableGateway
 		}
 
 		// Ensure default Gateway exists before starting controllers
-		if err := ensureDefaultGateway(gatewayClient, defaultPort); err != nil {
+		if err := ensureDefaultGateway(gatewayClient, defaultPort, watchNamespace); err != nil {
 			klog.Fatalf("Failed to ensure default Gateway: %s", err.Error())
 		}
 
-		gatewayInformerFactory := gatewayinformers.NewSharedInformerFactory(gatewayClient, 0)
+		gatewayInformerFactory := gatewayinformers.NewSharedInformerFactoryWithOptions(gatewayClient, 0, gatewayinformers.WithNamespace(watchNamespace))
 		gatewayController := controller.NewGatewayController(gatewayInformerFactory, store)
 
 		// Gateway API Inference Extension controllers are optional
 		var httpRouteController *controller.HTTPRouteController
+		var grpcRouteController *controller.GRPCRouteController
 		if enableGatewayAPIInferenceExtension {
 			httpRouteController = controller.NewHTTPRouteController(gatewayInformerFactory, store)
+			grpcRouteController = controller.NewGRPCRouteController(gatewayInformerFactory, store)
 		}
 
+		// The status of the routes is reported for each of their parent Gateways
+		routeStatusController := controller.NewRouteStatusController(kthenaClient, modelRouteInformerFactory,
+			gatewayClient, gatewayInformerFactory, enableGatewayAPIInferenceExtension, store)
+
 		// Start informer factory after all controllers that use it are created
 		gatewayInformerFactory.Start(stop)
 
@@ -134,18 +217,20 @@ func startControllers(store datastore.Store, stop <-chan struct{}, enableGateway
 			}
 		}()
 
+		go func() {
+			if err := routeStatusController.Run(stop); err != nil {
+				klog.Fatalf("Error running route status controller: %s", err.Error())
+			}
+		}()
+
 		controllers = append(controllers, gatewayController)
 
 		// Gateway API Inference Extension controllers are optional
 		if enableGatewayAPIInferenceExtension {
-			dynamicClient, err := dynamic.NewForConfig(cfg)
-			if err != nil {
-				klog.Fatalf("Error building dynamic client: %s", err.Error())
-			}
-			dynamicInformerFactory := dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 0)
-			inferencePoolController := controller.NewInferencePoolController(dynamicInformerFactory, store)
+			inferenceInformerFactory := inferenceinformers.NewSharedInformerFactoryWithOptions(gatewayClients.Inference, 0, inferenceinformers.WithNamespace(watchNamespace))
+			inferencePoolController := controller.NewInferencePoolController(inferenceInformerFactory, store)
 
-			dynamicInformerFactory.Start(stop)
+			inferenceInformerFactory.Start(stop)
 
 			go func() {
 				if err := httpRouteController.Run(stop); err != nil {
@@ -153,13 +238,19 @@ func startControllers(store datastore.Store, stop <-chan struct{}, enableGateway
 				}
 			}()
 
+			go func() {
+				if err := grpcRouteController.Run(stop); err != nil {
+					klog.Fatalf("Error running grpcroute controller: %s", err.Error())
+				}
+			}()
+
 			go func() {
 				if err := inferencePoolController.Run(stop); err != nil {
 					klog.Fatalf("Error running inferencepool controller: %s", err.Error())
 				}
 			}()
 
-			controllers = append(controllers, httpRouteController, inferencePoolController)
+			controllers = append(controllers, httpRouteController, grpcRouteController, inferencePoolController)
 		} else {
 			klog.Info("Gateway API Inference Extension controllers are disabled")
 		}
@@ -172,6 +263,21 @@ func startControllers(store datastore.Store, stop <-chan struct{}, enableGateway
 	}
 }
 
+func buildKubeConfig(kubeAPIQPS float32, kubeAPIBurst int) *rest.Config {
+	cfg, err := clientcmd.BuildConfigFromFlags("", "")
+	if err != nil {
+		klog.Fatalf("Error building kubeconfig: %s", err.Error())
+	}
+	// Set QPS and Burst if provided
+	if kubeAPIQPS > 0 {
+		cfg.QPS = kubeAPIQPS
+	}
+	if kubeAPIBurst > 0 {
+		cfg.Burst = kubeAPIBurst
+	}
+	return cfg
+}
+
 func (c *aggregatedController) HasSynced() bool {
 	for _, controller := range c.controllers {
 		if !controller.HasSynced() {
@@ -219,14 +325,17 @@ func ensureDefaultGatewayClass(gatewayClient gatewayclientset.Interface) error {
 	return nil
 }
 
-// ensureDefaultGateway creates the default Gateway if it doesn't exist
-func ensureDefaultGateway(gatewayClient gatewayclientset.Interface, defaultPort string) error {
+// ensureDefaultGateway creates the default Gateway if it doesn't exist.
+// It is created in the watched namespace if any, so that the router sees it.
+func ensureDefaultGateway(gatewayClient gatewayclientset.Interface, defaultPort, watchNamespace string) error {
 	ctx := context.Background()
 	namespace := "default"
 	name := "default"
 
 	// Get namespace from environment variable if available, otherwise use "default"
-	if podNamespace := os.Getenv("POD_NAMESPACE"); podNamespace != "" {
+	if watchNamespace != "" {
+		namespace = watchNamespace
+	} else if podNamespace := os.Getenv("POD_NAMESPACE"); podNamespace != "" {
 		namespace = podNamespace
 	}
 
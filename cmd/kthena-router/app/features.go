@@ -0,0 +1,158 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	networkingv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	workloadv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/permissions"
+)
+
+const (
+	gatewayGroup = "gateway.networking.k8s.io"
+	// permissionsRecheckInterval is the interval between two reviews of the permissions of the disabled features.
+	permissionsRecheckInterval = time.Minute
+)
+
+// Optional features of the router and the permissions they need on top of the ModelRoute and ModelServer ones.
+// A feature missing any of them is disabled at startup.
+var (
+	gatewayAPIFeature = permissions.Feature{
+		Name: "Gateway API",
+		Rules: []permissions.Rule{
+			{Group: gatewayGroup, Resource: "gatewayclasses", Verbs: []string{"get", "create"}},
+			{Group: gatewayGroup, Resource: "gateways", Verbs: []string{"get", "create", "list", "watch"}},
+		},
+	}
+
+	gatewayAPIInferenceExtensionFeature = permissions.Feature{
+		Name: "Gateway API Inference Extension",
+		Rules: []permissions.Rule{
+			{Group: gatewayGroup, Resource: "httproutes", Verbs: []string{"list", "watch"}},
+			{Group: gatewayGroup, Resource: "grpcroutes", Verbs: []string{"list", "watch"}},
+			{Group: "inference.networking.k8s.io", Resource: "inferencepools", Verbs: []string{"list", "watch"}},
+		},
+	}
+
+	modelCatalogMetadataFeature = permissions.Feature{
+		Name: "model catalog metadata",
+		Rules: []permissions.Rule{
+			{Group: workloadv1alpha1.GroupName, Resource: "modelservings", Verbs: []string{"list", "watch"}},
+		},
+	}
+
+	rateLimitPoliciesFeature = permissions.Feature{
+		Name: "rate limit policies",
+		Rules: []permissions.Rule{
+			{Group: networkingv1alpha1.GroupName, Resource: "ratelimitpolicies", Verbs: []string{"list", "watch"}},
+		},
+	}
+
+	tokenQuotasFeature = permissions.Feature{
+		Name: "token quotas",
+		Rules: []permissions.Rule{
+			{Group: networkingv1alpha1.GroupName, Resource: "tokenquotas", Verbs: []string{"list", "watch"}},
+			{Group: networkingv1alpha1.GroupName, Resource: "tokenquotas/status", Verbs: []string{"get", "update"}},
+		},
+	}
+
+	backendCABundlesFeature = permissions.Feature{
+		Name: "https model server CA bundles",
+		Rules: []permissions.Rule{
+			{Resource: "secrets", Verbs: []string{"list", "watch"}},
+		},
+	}
+
+	endpointSliceDiscoveryFeature = permissions.Feature{
+		Name: "EndpointSlice discovery",
+		Rules: []permissions.Rule{
+			{Group: "discovery.k8s.io", Resource: "endpointslices", Verbs: []string{"list", "watch"}},
+		},
+	}
+)
+
+// disableForbiddenFeatures turns off the enabled optional features the router is not granted the permissions of.
+func (s *Server) disableForbiddenFeatures(ctx context.Context) {
+	kubeClient, err := kubernetes.NewForConfig(buildKubeConfig(s.KubeAPIQPS, s.KubeAPIBurst))
+	if err != nil {
+		klog.Fatalf("Error building kubernetes clientset: %s", err.Error())
+	}
+	s.watchModelServings = modelServingsServed(kubeClient) && permissions.Enabled(ctx, kubeClient, s.WatchNamespace, modelCatalogMetadataFeature)
+	s.watchSecrets = permissions.Enabled(ctx, kubeClient, s.WatchNamespace, backendCABundlesFeature)
+	s.watchEndpointSlices = permissions.Enabled(ctx, kubeClient, s.WatchNamespace, endpointSliceDiscoveryFeature)
+	s.watchRateLimitPolicies = networkingKindServed(kubeClient, networkingv1alpha1.RateLimitPolicyKind) &&
+		permissions.Enabled(ctx, kubeClient, s.WatchNamespace, rateLimitPoliciesFeature)
+	s.watchTokenQuotas = networkingKindServed(kubeClient, networkingv1alpha1.TokenQuotaKind) &&
+		permissions.Enabled(ctx, kubeClient, s.WatchNamespace, tokenQuotasFeature)
+	// The features are enabled at startup only, a disabled one granted its permissions is reported so that the
+	// operator restarts the router.
+	recorder, pod := s.podEvents()
+	go permissions.WatchGranted(ctx, kubeClient, permissionsRecheckInterval, permissions.RestartRequired("router", recorder, pod))
+
+	if !s.EnableGatewayAPI {
+		return
+	}
+	if !permissions.Enabled(ctx, kubeClient, s.WatchNamespace, gatewayAPIFeature) {
+		s.EnableGatewayAPI = false
+		s.EnableGatewayAPIInferenceExtension = false
+		return
+	}
+	if s.EnableGatewayAPIInferenceExtension && !permissions.Enabled(ctx, kubeClient, s.WatchNamespace, gatewayAPIInferenceExtensionFeature) {
+		s.EnableGatewayAPIInferenceExtension = false
+	}
+}
+
+// modelServingsServed reports whether the ModelServing API is served, it isn't when only the networking
+// component of kthena is installed.
+func modelServingsServed(client kubernetes.Interface) bool {
+	resources, err := client.Discovery().ServerResourcesForGroupVersion(workloadv1alpha1.SchemeGroupVersion.String())
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.Warningf("Unable to discover the ModelServing API, the model catalog won't report the ModelServing metadata: %v", err)
+		}
+		return false
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Kind == "ModelServing" {
+			return true
+		}
+	}
+	return false
+}
+
+// networkingKindServed reports whether the API of a kind of the networking group is served, the kinds added by a
+// release aren't until the CRDs of an upgraded installation are updated.
+func networkingKindServed(client kubernetes.Interface, kind string) bool {
+	resources, err := client.Discovery().ServerResourcesForGroupVersion(networkingv1alpha1.SchemeGroupVersion.String())
+	if err != nil {
+		klog.Warningf("Unable to discover the %s API, the %s resources are ignored: %v", kind, kind, err)
+		return false
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -20,6 +20,8 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -27,23 +29,66 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
+	clientset "github.com/volcano-sh/kthena/client-go/clientset/versioned"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/debug"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/router"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/snapshot"
+	"github.com/volcano-sh/kthena/pkg/tlsconfig"
 )
 
 const (
 	gracefulShutdownTimeout = 15 * time.Second
-	routerConfigFile        = "/etc/config/routerConfiguration.yaml"
+	// DefaultRouterConfigFile is where the router configuration ConfigMap is mounted.
+	DefaultRouterConfigFile = "/etc/config/routerConfiguration.yaml"
 )
 
-func NewRouter(store datastore.Store) *router.Router {
+func NewRouter(store datastore.Store, routerConfigFile string) *router.Router {
 	return router.NewRouter(store, routerConfigFile)
 }
 
+// podEvents returns the recorder of the events of the router pod and its reference, nil if the pod is unknown.
+func (s *Server) podEvents() (record.EventRecorder, *corev1.ObjectReference) {
+	name, namespace := os.Getenv("POD_NAME"), os.Getenv("POD_NAMESPACE")
+	if name == "" || namespace == "" {
+		return nil, nil
+	}
+	kubeClient, err := kubernetes.NewForConfig(buildKubeConfig(s.KubeAPIQPS, s.KubeAPIBurst))
+	if err != nil {
+		klog.Errorf("Error building kubernetes clientset, the events of the router pod are not recorded: %v", err)
+		return nil, nil
+	}
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events(namespace)})
+	recorder := broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "kthena-router"})
+	return recorder, &corev1.ObjectReference{
+		Kind:       "Pod",
+		APIVersion: "v1",
+		Namespace:  namespace,
+		Name:       name,
+		UID:        types.UID(os.Getenv("POD_UID")),
+	}
+}
+
+// startTokenQuotaFlush persists the tokens consumed by the router in the status of the TokenQuotas.
+func (s *Server) startTokenQuotaFlush(ctx context.Context, r *router.Router) {
+	kthenaClient, err := clientset.NewForConfig(buildKubeConfig(s.KubeAPIQPS, s.KubeAPIBurst))
+	if err != nil {
+		klog.Errorf("Error building kthena clientset, the tokens consumed in the token quotas are not persisted: %v", err)
+		return
+	}
+	r.StartTokenQuotaFlush(ctx, kthenaClient)
+}
+
 // Starts router
 func (s *Server) startRouter(ctx context.Context, router *router.Router, store datastore.Store) {
 	gin.SetMode(gin.ReleaseMode)
@@ -51,6 +96,24 @@ func (s *Server) startRouter(ctx context.Context, router *router.Router, store d
 	// Start debug server on localhost
 	s.startDebugServer(ctx, store)
 
+	if s.StateAPIPort > 0 {
+		s.startStateServer(ctx, store)
+	}
+
+	if s.EnableTLS {
+		// The certificate is reloaded when it is rotated
+		reloader, err := tlsconfig.NewCertReloader(s.TLSCertFile, s.TLSKeyFile)
+		if err != nil {
+			klog.Fatalf("failed to load TLS certificate: %v", err)
+		}
+		go reloader.Run(ctx)
+		s.certReloader = reloader
+	}
+
+	if s.GRPCPort > 0 {
+		s.startGRPCServer(ctx, router)
+	}
+
 	// Gateway API features are optional
 	if s.EnableGatewayAPI {
 		// Create listener manager for dynamic Gateway listener management
@@ -102,6 +165,7 @@ func (s *Server) startDebugServer(ctx context.Context, store datastore.Store) {
 		debugGroup.GET("/pods", debugHandler.ListPods)
 		debugGroup.GET("/gateways", debugHandler.ListGateways)
 		debugGroup.GET("/httproutes", debugHandler.ListHTTPRoutes)
+		debugGroup.GET("/grpcroutes", debugHandler.ListGRPCRoutes)
 		debugGroup.GET("/inferencepools", debugHandler.ListInferencePools)
 
 		// Get specific resources
@@ -110,8 +174,10 @@ func (s *Server) startDebugServer(ctx context.Context, store datastore.Store) {
 		debugGroup.GET("/namespaces/:namespace/pods/:name", debugHandler.GetPod)
 		debugGroup.GET("/namespaces/:namespace/gateways/:name", debugHandler.GetGateway)
 		debugGroup.GET("/namespaces/:namespace/httproutes/:name", debugHandler.GetHTTPRoute)
+		debugGroup.GET("/namespaces/:namespace/grpcroutes/:name", debugHandler.GetGRPCRoute)
 		debugGroup.GET("/namespaces/:namespace/inferencepools/:name", debugHandler.GetInferencePool)
 	}
+	engine.GET("/debug/routing_changes", debugHandler.ListRoutingChanges)
 
 	server := &http.Server{
 		Addr:    fmt.Sprintf("localhost:%d", s.DebugPort),
@@ -137,6 +203,90 @@ func (s *Server) startDebugServer(ctx context.Context, store datastore.Store) {
 	}()
 }
 
+// startStateServer starts the read-only server of the snapshots of the state of the router on localhost,
+// for the sidecars consuming it. Like the debug server, it serves the state of the cluster without authentication
+// so it is not accessible from outside.
+func (s *Server) startStateServer(ctx context.Context, store datastore.Store) {
+	publisher := snapshot.NewPublisher(store, snapshot.DefaultInterval)
+	go publisher.Run(ctx)
+
+	engine := gin.New()
+	engine.Use(gin.Recovery())
+	engine.GET(snapshot.Path, publisher.Handler())
+
+	server := &http.Server{
+		Addr:    fmt.Sprintf("localhost:%d", s.StateAPIPort),
+		Handler: engine.Handler(),
+	}
+	go func() {
+		klog.Infof("Starting state API server on localhost:%d", s.StateAPIPort)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			klog.Fatalf("State API server listen failed: %v", err)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		klog.Info("Shutting down state API server ...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), gracefulShutdownTimeout)
+		defer cancel()
+		// The watches never complete, they are closed with the server.
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			klog.Errorf("State API server shutdown failed: %v", err)
+			server.Close()
+		}
+		klog.Info("State API server exited")
+	}()
+}
+
+// startGRPCServer starts the listener routing the KServe v2 inference gRPC calls by model, e.g. the calls of the
+// Triton clients, independently of the Gateways.
+func (s *Server) startGRPCServer(ctx context.Context, router *router.Router) {
+	engine := gin.New()
+	engine.Use(gin.Recovery(), AccessLogMiddleware(router), AuthMiddleware(router))
+	engine.Any("/*path", router.HandlerFunc())
+
+	// gRPC clients connect over HTTP/2, cleartext ones with prior knowledge.
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+	server := &http.Server{
+		Addr:      fmt.Sprintf(":%d", s.GRPCPort),
+		Handler:   engine.Handler(),
+		TLSConfig: s.TLSConfig.Clone(),
+		Protocols: protocols,
+	}
+	if s.certReloader != nil {
+		server.TLSConfig = s.certReloader.ServerConfig(s.TLSConfig)
+	}
+	go func() {
+		klog.Infof("Starting gRPC server on port %d", s.GRPCPort)
+		var err error
+		if s.EnableTLS {
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			klog.Fatalf("gRPC server listen failed: %v", err)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		klog.Info("Shutting down gRPC server ...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), gracefulShutdownTimeout)
+		defer cancel()
+		// The streaming calls may not complete, they are closed with the server.
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			klog.Errorf("gRPC server shutdown failed: %v", err)
+			server.Close()
+		}
+		klog.Info("gRPC server exited")
+	}()
+}
+
 // startDefaultServer starts the default HTTP server on fixed port
 // This server handles healthz, readyz, metrics, and /v1/*path
 func (s *Server) startDefaultServer(ctx context.Context, router *router.Router, store datastore.Store) {
@@ -171,17 +321,18 @@ func (s *Server) startDefaultServer(ctx context.Context, router *router.Router,
 	v1Group.Any("/*path", router.HandlerFunc())
 
 	server := &http.Server{
-		Addr:    ":" + s.Port,
-		Handler: engine.Handler(),
+		Addr:      ":" + s.Port,
+		Handler:   engine.Handler(),
+		TLSConfig: s.TLSConfig.Clone(),
+	}
+	if s.certReloader != nil {
+		server.TLSConfig = s.certReloader.ServerConfig(s.TLSConfig)
 	}
 	go func() {
 		klog.Infof("Starting default server on port %s", s.Port)
 		var err error
 		if s.EnableTLS {
-			if s.TLSCertFile == "" || s.TLSKeyFile == "" {
-				klog.Fatalf("TLS enabled but cert or key file not specified")
-			}
-			err = server.ListenAndServeTLS(s.TLSCertFile, s.TLSKeyFile)
+			err = server.ListenAndServeTLS("", "")
 		} else {
 			err = server.ListenAndServe()
 		}
@@ -244,10 +395,11 @@ func NewListenerManager(ctx context.Context, router *router.Router, store datast
 	}
 }
 
-// findBestMatchingListener finds the best matching listener for a request
-// Returns the listener config and true if found, nil and false otherwise
-// NOTE: Caller must hold lm.mu lock
-func (lm *ListenerManager) findBestMatchingListener(port int32, hostname string) (*ListenerConfig, bool) {
+// findMatchingGateways finds the Gateways whose listeners best match a request, the listeners of the exact hostname
+// if any, else those matching all hostnames. The Gateways sharing the port and the hostname of a request all
+// receive it, so that the routes attached to any of them match it.
+// Returns the keys of the Gateways, in the order of their listeners, and true if found, nil and false otherwise
+func (lm *ListenerManager) findMatchingGateways(port int32, hostname string) ([]string, bool) {
 	lm.mu.RLock()
 	portInfo, exists := lm.portListeners[port]
 	if !exists {
@@ -259,25 +411,29 @@ func (lm *ListenerManager) findBestMatchingListener(port int32, hostname string)
 	portInfo.mu.RLock()
 	defer portInfo.mu.RUnlock()
 
-	if len(portInfo.Listeners) == 0 {
-		return nil, false
-	}
-
-	// First, try to find an exact hostname match
+	var exact, wildcard []string
 	for i := range portInfo.Listeners {
 		listener := &portInfo.Listeners[i]
-		if listener.Hostname != nil && *listener.Hostname == hostname {
-			return listener, true
+		switch {
+		case listener.Hostname != nil && *listener.Hostname == hostname:
+			if !slices.Contains(exact, listener.GatewayKey) {
+				exact = append(exact, listener.GatewayKey)
+			}
+		case listener.Hostname == nil:
+			// TODO: support wildcard hostname matching
+			if !slices.Contains(wildcard, listener.GatewayKey) {
+				wildcard = append(wildcard, listener.GatewayKey)
+			}
 		}
 	}
 
-	// If no exact match, try to find a listener without hostname restriction (wildcard)
-	// TODO: support wildcard hostname matching
-	for i := range portInfo.Listeners {
-		listener := &portInfo.Listeners[i]
-		if listener.Hostname == nil {
-			return listener, true
-		}
+	// First, prefer the listeners of the exact hostname
+	if len(exact) > 0 {
+		return exact, true
+	}
+	// If no exact match, use the listeners without hostname restriction (wildcard)
+	if len(wildcard) > 0 {
+		return wildcard, true
 	}
 
 	// No match found
@@ -320,7 +476,7 @@ func (lm *ListenerManager) createPortHandler(port int32) gin.HandlerFunc {
 			hostname = hostname[:idx]
 		}
 
-		listenerConfig, found := lm.findBestMatchingListener(port, hostname)
+		gatewayKeys, found := lm.findMatchingGateways(port, hostname)
 		if !found {
 			c.JSON(http.StatusNotFound, gin.H{
 				"message": "No matching listener found",
@@ -328,8 +484,8 @@ func (lm *ListenerManager) createPortHandler(port int32) gin.HandlerFunc {
 			return
 		}
 
-		// Set gateway key in context so router can filter ModelRoutes by gateway
-		c.Set(router.GatewayKey, listenerConfig.GatewayKey)
+		// Set gateway keys in context so router can filter ModelRoutes by gateway
+		c.Set(router.GatewayKey, gatewayKeys)
 
 		// Apply middleware and route
 		AccessLogMiddleware(lm.router)(c)
@@ -411,9 +567,9 @@ func (lm *ListenerManager) removeListenerFromPort(port int32, configToRemove Lis
 	portInfo.mu.Unlock()
 }
 
-// addListenerToPort adds a listener config to a port
+// addListenerToPort adds a listener config to a port, serving the router certificate if enableTLS is set
 // NOTE: Caller must hold lm.mu lock
-func (lm *ListenerManager) addListenerToPort(port int32, config ListenerConfig, enableTLS bool, tlsCertFile, tlsKeyFile string) {
+func (lm *ListenerManager) addListenerToPort(port int32, config ListenerConfig, enableTLS bool) {
 	portInfo, exists := lm.portListeners[port]
 	if !exists {
 		// Create new port listener
@@ -421,9 +577,22 @@ func (lm *ListenerManager) addListenerToPort(port int32, config ListenerConfig,
 		engine.Use(gin.Recovery())
 		engine.Any("/*path", lm.createPortHandler(port))
 
+		// gRPC clients connect over HTTP/2, cleartext ones with prior knowledge.
+		protocols := new(http.Protocols)
+		protocols.SetHTTP1(true)
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
 		server := &http.Server{
-			Addr:    ":" + strconv.Itoa(int(port)),
-			Handler: engine.Handler(),
+			Addr:      ":" + strconv.Itoa(int(port)),
+			Handler:   engine.Handler(),
+			TLSConfig: lm.server.TLSConfig.Clone(),
+			Protocols: protocols,
+		}
+		if enableTLS {
+			if lm.server.certReloader == nil {
+				klog.Fatalf("TLS enabled but cert or key file not specified for port %d", port)
+			}
+			server.TLSConfig = lm.server.certReloader.ServerConfig(lm.server.TLSConfig)
 		}
 
 		portInfo = &PortListenerInfo{
@@ -437,21 +606,18 @@ func (lm *ListenerManager) addListenerToPort(port int32, config ListenerConfig,
 		portInfo.ShutdownFunc = cancel
 
 		// Start the server
-		go func(p int32, srv *http.Server, ctx context.Context, tls bool, cert, key string) {
+		go func(p int32, srv *http.Server, ctx context.Context, tls bool) {
 			klog.Infof("Starting Gateway listener server on port %d", p)
 			var err error
 			if tls {
-				if cert == "" || key == "" {
-					klog.Fatalf("TLS enabled but cert or key file not specified for port %d", p)
-				}
-				err = srv.ListenAndServeTLS(cert, key)
+				err = srv.ListenAndServeTLS("", "")
 			} else {
 				err = srv.ListenAndServe()
 			}
 			if err != nil && err != http.ErrServerClosed {
 				klog.Errorf("listen failed for port %d: %v", p, err)
 			}
-		}(port, server, listenerCtx, enableTLS, tlsCertFile, tlsKeyFile)
+		}(port, server, listenerCtx, enableTLS)
 
 		// Start graceful shutdown goroutine
 		go func(p int32, srv *http.Server, cancel context.CancelFunc) {
@@ -509,14 +675,10 @@ func (lm *ListenerManager) StartListenersForGateway(gateway *gatewayv1.Gateway)
 			// Check if this is the default port to determine TLS settings
 			defaultPort, _ := strconv.Atoi(lm.server.Port)
 			enableTLS := false
-			tlsCertFile := ""
-			tlsKeyFile := ""
 			if int32(defaultPort) == config.Port {
 				enableTLS = lm.server.EnableTLS
-				tlsCertFile = lm.server.TLSCertFile
-				tlsKeyFile = lm.server.TLSKeyFile
 			}
-			lm.addListenerToPort(config.Port, config, enableTLS, tlsCertFile, tlsKeyFile)
+			lm.addListenerToPort(config.Port, config, enableTLS)
 		}
 	}
 
@@ -0,0 +1,46 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindMatchingGateways(t *testing.T) {
+	hostname := "api.example.com"
+	lm := NewListenerManager(context.Background(), nil, nil, nil)
+	lm.portListeners[80] = &PortListenerInfo{Listeners: []ListenerConfig{
+		{GatewayKey: "default/gw-a", ListenerName: "http", Port: 80},
+		{GatewayKey: "default/gw-b", ListenerName: "http", Port: 80},
+		{GatewayKey: "default/gw-a", ListenerName: "other", Port: 80},
+		{GatewayKey: "default/gw-c", ListenerName: "api", Port: 80, Hostname: &hostname},
+	}}
+
+	gatewayKeys, found := lm.findMatchingGateways(80, "localhost")
+	assert.True(t, found)
+	assert.Equal(t, []string{"default/gw-a", "default/gw-b"}, gatewayKeys, "all the gateways of the port receive the request")
+
+	gatewayKeys, found = lm.findMatchingGateways(80, hostname)
+	assert.True(t, found)
+	assert.Equal(t, []string{"default/gw-c"}, gatewayKeys, "the listeners of the exact hostname are preferred")
+
+	_, found = lm.findMatchingGateways(8080, "localhost")
+	assert.False(t, found)
+}
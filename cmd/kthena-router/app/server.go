@@ -18,17 +18,23 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
+	"time"
 
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
 	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/profile"
+	"github.com/volcano-sh/kthena/pkg/tlsconfig"
 )
 
 type Server struct {
 	store                              datastore.Store
 	controllers                        Controller
 	listenerManager                    *ListenerManager
+	certReloader                       *tlsconfig.CertReloader
 	EnableTLS                          bool
 	TLSCertFile                        string
 	TLSKeyFile                         string
@@ -38,6 +44,40 @@ type Server struct {
 	DebugPort                          int
 	KubeAPIQPS                         float32
 	KubeAPIBurst                       int
+	// StateAPIPort is the port the snapshots of the state of the router are served on, on localhost. Zero disables the
+	// state API.
+	StateAPIPort int
+	// GRPCPort is the port the KServe v2 inference gRPC calls are routed on by model. Zero disables the gRPC listener.
+	GRPCPort int
+	// Profile is the performance envelope of this router instance.
+	Profile profile.Profile
+	// ModelRouteSelector is a label selector restricting the ModelRoutes served by this router.
+	// Empty means all ModelRoutes are served.
+	ModelRouteSelector string
+	// WatchNamespace restricts the watched resources to a namespace. Empty means all namespaces are watched.
+	WatchNamespace string
+	// PodSelector is a label selector restricting the watched pods. Empty means all pods are watched.
+	PodSelector string
+	// StreamHeartbeatInterval is the idle interval after which heartbeats are sent on streamed responses.
+	// Zero disables heartbeats.
+	StreamHeartbeatInterval time.Duration
+	// MetricsScrapeInterval is the interval between two scrapes of the metrics of the inference engine pods.
+	MetricsScrapeInterval time.Duration
+	// TLSConfig sets the TLS versions and cipher suites accepted by the TLS listeners. Nil means the Go defaults.
+	TLSConfig *tls.Config
+	// RouterConfigFile is the path of the router configuration file.
+	RouterConfigFile string
+
+	// watchModelServings is set when the ModelServings can be watched for the metadata of the model catalog.
+	watchModelServings bool
+	// watchSecrets is set when the Secrets can be watched for the CA bundles of the https ModelServers.
+	watchSecrets bool
+	// watchRateLimitPolicies is set when the RateLimitPolicies can be watched.
+	watchRateLimitPolicies bool
+	// watchTokenQuotas is set when the TokenQuotas can be watched and their status updated.
+	watchTokenQuotas bool
+	// watchEndpointSlices is set when the EndpointSlices can be watched for the instances of the ModelServers.
+	watchEndpointSlices bool
 }
 
 func NewServer(port string, enableTLS bool, cert, key string, enableGatewayAPI bool, enableGatewayAPIInferenceExtension bool, debugPort int, kubeAPIQPS float32, kubeAPIBurst int) *Server {
@@ -52,6 +92,13 @@ func NewServer(port string, enableTLS bool, cert, key string, enableGatewayAPI b
 		DebugPort:                          debugPort,
 		KubeAPIQPS:                         kubeAPIQPS,
 		KubeAPIBurst:                       kubeAPIBurst,
+		Profile: profile.Profile{
+			Name:             profile.Custom,
+			StreamBufferSize: profile.DefaultStreamBufferSize,
+		},
+		StreamHeartbeatInterval: handlers.DefaultStreamHeartbeatInterval,
+		MetricsScrapeInterval:   datastore.DefaultMetricsScrapeInterval,
+		RouterConfigFile:        DefaultRouterConfigFile,
 	}
 }
 
@@ -61,9 +108,16 @@ func (s *Server) Run(ctx context.Context) {
 	s.store = store
 
 	// must be run before the controller, because it will register callbacks
-	r := NewRouter(store)
+	r := NewRouter(store, s.RouterConfigFile)
+	r.ApplyProfile(s.Profile)
+	handlers.SetStreamHeartbeatInterval(s.StreamHeartbeatInterval)
+	datastore.SetMetricsScrapeInterval(s.MetricsScrapeInterval)
+	r.StartEventExport(ctx)
+	r.StartSLOTracking(ctx)
+	r.StartLoadShedding(ctx, s.podEvents)
 	// start controller
-	s.controllers = startControllers(store, ctx.Done(), s.EnableGatewayAPI, s.Port, s.EnableGatewayAPIInferenceExtension, s.KubeAPIQPS, s.KubeAPIBurst)
+	s.disableForbiddenFeatures(ctx)
+	s.controllers = startControllers(store, ctx.Done(), s.EnableGatewayAPI, s.Port, s.EnableGatewayAPIInferenceExtension, s.KubeAPIQPS, s.KubeAPIBurst, s.ModelRouteSelector, s.WatchNamespace, s.PodSelector, s.watchModelServings, s.watchSecrets, s.watchRateLimitPolicies, s.watchTokenQuotas, s.watchEndpointSlices)
 
 	// Start store's periodic update loop after controllers have synced
 	if !cache.WaitForCacheSync(ctx.Done(), s.controllers.HasSynced) {
@@ -71,6 +125,9 @@ func (s *Server) Run(ctx context.Context) {
 	}
 	klog.Infof("Controllers have synced, starting store periodic update loop")
 	store.Run(ctx)
+	if s.watchTokenQuotas {
+		s.startTokenQuotaFlush(ctx, r)
+	}
 	// start router
 	s.startRouter(ctx, r, store)
 
@@ -18,6 +18,7 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"flag"
 	"fmt"
 	"os"
@@ -26,12 +27,18 @@ import (
 	"time"
 
 	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 
 	"github.com/volcano-sh/kthena/cmd/kthena-router/app"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/profile"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/webhook"
+	"github.com/volcano-sh/kthena/pkg/tlsconfig"
 	webhookcert "github.com/volcano-sh/kthena/pkg/webhook/cert"
 )
 
@@ -51,8 +58,26 @@ func main() {
 		certSecretName                     string
 		serviceName                        string
 		debugPort                          int
+		stateAPIPort                       int
+		grpcPort                           int
 		kubeAPIQPS                         float32
 		kubeAPIBurst                       int
+		profileName                        string
+		maxConcurrentRequests              int
+		streamBufferSize                   int
+		maxBufferedBodyBytes               int64
+		memoryWatermarkBytes               int64
+		profileFeatures                    map[string]string
+		streamHeartbeatInterval            time.Duration
+		metricsScrapeInterval              time.Duration
+		modelRouteSelector                 string
+		watchNamespace                     string
+		podSelector                        string
+		tlsMinVersion                      string
+		tlsCipherSuites                    []string
+		tlsClientCA                        string
+		routerConfigFile                   string
+		validateConfig                     bool
 	)
 
 	klog.InitFlags(nil)
@@ -60,6 +85,7 @@ func main() {
 	pflag.StringVar(&routerPort, "port", "8080", "Server listen port")
 	pflag.StringVar(&tlsCert, "tls-cert", "", "TLS certificate file path")
 	pflag.StringVar(&tlsKey, "tls-key", "", "TLS key file path")
+	pflag.StringVar(&tlsClientCA, "tls-client-ca-file", "", "Path to the CA certificates verifying the client certificates presented to the router, for the mtls authenticator. If empty, client certificates are not requested.")
 	pflag.BoolVar(&enableWebhook, "enable-webhook", true, "Enable built-in admission webhook server")
 	pflag.BoolVar(&enableGatewayAPI, "enable-gateway-api", false, "Enable Gateway API related features")
 	pflag.BoolVar(&enableGatewayAPIInferenceExtension, "enable-gateway-api-inference-extension", false, "Enable Gateway API Inference Extension features (requires --enable-gateway-api)")
@@ -69,14 +95,35 @@ func main() {
 	pflag.StringVar(&certSecretName, "cert-secret-name", "kthena-router-webhook-certs", "Name of the secret to store auto-generated webhook certificates")
 	pflag.StringVar(&serviceName, "webhook-service-name", "kthena-router-webhook", "Service name for the webhook server")
 	pflag.IntVar(&debugPort, "debug-port", 15000, "The port for the debug server (localhost only)")
+	pflag.IntVar(&grpcPort, "grpc-port", 0, "The port the KServe v2 inference gRPC calls, e.g. of the Triton clients, are routed on by model. If 0, the gRPC listener is disabled.")
+	pflag.IntVar(&stateAPIPort, "state-api-port", 0, "The port of the read-only API serving the snapshots of the state of the router, on localhost only. If 0, the state API is disabled.")
 	pflag.Float32Var(&kubeAPIQPS, "kube-api-qps", 0, "QPS to use while talking with kubernetes apiserver. If 0, use default value.")
 	pflag.IntVar(&kubeAPIBurst, "kube-api-burst", 0, "Burst to use while talking with kubernetes apiserver. If 0, use default value.")
+	pflag.StringVar(&profileName, "profile", profile.Custom, "Router profile which sets the performance envelope. One of: small, medium, large, custom.")
+	pflag.IntVar(&maxConcurrentRequests, "max-concurrent-requests", 0, "Maximum number of concurrent inference requests. If 0, no limit. Overrides the value of the profile.")
+	pflag.IntVar(&streamBufferSize, "stream-buffer-size", profile.DefaultStreamBufferSize, "Buffer size in bytes for reading upstream streaming responses. Overrides the value of the profile.")
+	pflag.Int64Var(&maxBufferedBodyBytes, "max-buffered-body-bytes", 0, "Maximum number of request body bytes buffered by the router at the same time. If 0, no limit. Overrides the value of the profile.")
+	pflag.Int64Var(&memoryWatermarkBytes, "memory-watermark-bytes", 0, "Memory used by the router above which new requests are rejected. If 0, no limit. Overrides the value of the profile.")
+	pflag.StringToStringVar(&profileFeatures, "profile-features", nil, "Router features enabled or disabled, e.g. queue=true,serverTiming=false. One of: loadShedding, queue, serverTiming. Overrides the features of the profile.")
+	pflag.DurationVar(&streamHeartbeatInterval, "stream-heartbeat-interval", handlers.DefaultStreamHeartbeatInterval, "Idle interval after which a keep-alive comment is sent on streamed responses. If 0, heartbeats are disabled.")
+	pflag.DurationVar(&metricsScrapeInterval, "metrics-scrape-interval", datastore.DefaultMetricsScrapeInterval, "Interval between two scrapes of the metrics of the inference engine pods. The metrics of a pod not scraped for 3 intervals are stale.")
+	pflag.StringVar(&modelRouteSelector, "model-route-selector", "", "Label selector of the ModelRoutes served by this router, e.g. 'networking.serving.volcano.sh/tenant=team-a'. If empty, all ModelRoutes are served.")
+	pflag.StringVar(&watchNamespace, "watch-namespace", "", "Namespace of the ModelRoutes, ModelServers, pods and Gateway API resources watched by this router. If empty, all namespaces are watched.")
+	pflag.StringVar(&podSelector, "pod-selector", "", "Label selector of the pods watched by this router, which must match the pods of the served ModelServers. If empty, all pods are watched.")
+	pflag.StringVar(&tlsMinVersion, "tls-min-version", tlsconfig.DefaultMinVersion, "Minimum TLS version accepted by the router and webhook listeners. One of: 1.2, 1.3.")
+	pflag.StringSliceVar(&tlsCipherSuites, "tls-cipher-suites", nil, "Comma-separated list of TLS 1.2 cipher suites accepted by the router and webhook listeners, "+
+		"e.g. 'TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256'. If empty, the Go default cipher suites are used.")
+	pflag.StringVar(&routerConfigFile, "router-config-file", app.DefaultRouterConfigFile, "Path to the router configuration file.")
+	pflag.BoolVar(&validateConfig, "validate-config", false, "Validate the flags and the router configuration file, then exit with a non-zero status if they are invalid.")
 	defer klog.Flush()
 	pflag.Parse()
 
 	if (tlsCert != "" && tlsKey == "") || (tlsCert == "" && tlsKey != "") {
 		klog.Fatal("tls-cert and tls-key must be specified together")
 	}
+	if tlsClientCA != "" && tlsCert == "" {
+		klog.Fatal("tls-client-ca-file requires tls-cert and tls-key")
+	}
 
 	if enableGatewayAPIInferenceExtension && !enableGatewayAPI {
 		klog.Fatal("--enable-gateway-api-inference-extension requires --enable-gateway-api to be enabled")
@@ -90,6 +137,69 @@ func main() {
 		klog.Fatalf("invalid debug port: %d", debugPort)
 	}
 
+	if stateAPIPort < 0 || stateAPIPort > 65535 {
+		klog.Fatalf("invalid state API port: %d", stateAPIPort)
+	}
+	if grpcPort < 0 || grpcPort > 65535 {
+		klog.Fatalf("invalid gRPC port: %d", grpcPort)
+	}
+
+	routerProfile, err := profile.Get(profileName)
+	if err != nil {
+		klog.Fatalf("invalid router profile: %v", err)
+	}
+	if pflag.CommandLine.Changed("max-concurrent-requests") {
+		routerProfile.MaxConcurrentRequests = maxConcurrentRequests
+	}
+	if pflag.CommandLine.Changed("stream-buffer-size") {
+		routerProfile.StreamBufferSize = streamBufferSize
+	}
+	if pflag.CommandLine.Changed("max-buffered-body-bytes") {
+		routerProfile.MaxBufferedBodyBytes = maxBufferedBodyBytes
+	}
+	if pflag.CommandLine.Changed("memory-watermark-bytes") {
+		routerProfile.MemoryWatermarkBytes = memoryWatermarkBytes
+	}
+	if err := routerProfile.SetFeatures(profileFeatures); err != nil {
+		klog.Fatalf("invalid router profile features: %v", err)
+	}
+	if err := routerProfile.Validate(); err != nil {
+		klog.Fatalf("invalid router profile %q: %v", routerProfile.Name, err)
+	}
+
+	if streamHeartbeatInterval < 0 {
+		klog.Fatalf("invalid stream heartbeat interval: %v", streamHeartbeatInterval)
+	}
+
+	if metricsScrapeInterval <= 0 {
+		klog.Fatalf("invalid metrics scrape interval: %v", metricsScrapeInterval)
+	}
+
+	if _, err := labels.Parse(modelRouteSelector); err != nil {
+		klog.Fatalf("invalid model route selector %q: %v", modelRouteSelector, err)
+	}
+
+	if _, err := labels.Parse(podSelector); err != nil {
+		klog.Fatalf("invalid pod selector %q: %v", podSelector, err)
+	}
+
+	tlsConfig, err := tlsconfig.New(tlsMinVersion, tlsCipherSuites)
+	if err != nil {
+		klog.Fatalf("invalid TLS configuration: %v", err)
+	}
+	if tlsconfig.FIPSEnabled() {
+		klog.Info("FIPS 140-3 mode is enabled")
+	}
+
+	if validateConfig {
+		if _, err := conf.ParseRouterConfig(routerConfigFile); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("router configuration %s is valid\n", routerConfigFile)
+		return
+	}
+
 	pflag.CommandLine.VisitAll(func(f *pflag.Flag) {
 		klog.Infof("Flag: %s, Value: %s", f.Name, f.Value.String())
 	})
@@ -106,12 +216,28 @@ func main() {
 	}()
 
 	if enableWebhook {
-		go runWebhook(ctx, webhookPort, webhookCert, webhookKey, certSecretName, serviceName, kubeAPIQPS, kubeAPIBurst)
+		go runWebhook(ctx, webhookPort, webhookCert, webhookKey, certSecretName, serviceName, kubeAPIQPS, kubeAPIBurst, tlsConfig)
 	} else {
 		klog.Info("Webhook server is disabled")
 	}
 
-	app.NewServer(routerPort, tlsCert != "" && tlsKey != "", tlsCert, tlsKey, enableGatewayAPI, enableGatewayAPIInferenceExtension, debugPort, kubeAPIQPS, kubeAPIBurst).Run(ctx)
+	server := app.NewServer(routerPort, tlsCert != "" && tlsKey != "", tlsCert, tlsKey, enableGatewayAPI, enableGatewayAPIInferenceExtension, debugPort, kubeAPIQPS, kubeAPIBurst)
+	server.Profile = routerProfile
+	server.RouterConfigFile = routerConfigFile
+	server.StateAPIPort = stateAPIPort
+	server.GRPCPort = grpcPort
+	server.ModelRouteSelector = modelRouteSelector
+	server.WatchNamespace = watchNamespace
+	server.PodSelector = podSelector
+	server.StreamHeartbeatInterval = streamHeartbeatInterval
+	server.MetricsScrapeInterval = metricsScrapeInterval
+	server.TLSConfig = tlsConfig
+	if tlsClientCA != "" {
+		if server.TLSConfig, err = tlsconfig.WithClientCA(tlsConfig, tlsClientCA); err != nil {
+			klog.Fatalf("invalid TLS client CA: %v", err)
+		}
+	}
+	server.Run(ctx)
 }
 
 // ensureWebhookCertificate generates a certificate secret if needed and returns the CA bundle.
@@ -127,7 +253,7 @@ func ensureWebhookCertificate(ctx context.Context, kubeClient kubernetes.Interfa
 
 // runWebhook starts the webhook server and manages certificate acquisition with precedence:
 // Secret -> existing cert files -> auto-generate new certs.
-func runWebhook(ctx context.Context, port int, certFile, keyFile, secretName, serviceName string, kubeAPIQPS float32, kubeAPIBurst int) {
+func runWebhook(ctx context.Context, port int, certFile, keyFile, secretName, serviceName string, kubeAPIQPS float32, kubeAPIBurst int, tlsConfig *tls.Config) {
 	config, err := rest.InClusterConfig()
 	if err != nil {
 		klog.Fatalf("Failed to get kube config: %v", err)
@@ -175,7 +301,7 @@ func runWebhook(ctx context.Context, port int, certFile, keyFile, secretName, se
 		}
 	}
 
-	validator := webhook.NewKthenaRouterValidator(kubeClient, port)
+	validator := webhook.NewKthenaRouterValidator(kubeClient, port, tlsConfig)
 
 	// Wait for both cert and key files to exist (in case they are mounted by Kubernetes)
 	ok := waitForCertsReady(keyFile, certFile)
@@ -71,6 +71,8 @@ const sidebars: SidebarsConfig = {
             'user-guide/rate-limit',
             "user-guide/gateway-api-support",
             'user-guide/gateway-inference-extension-support',
+            'user-guide/model-catalog',
+            'user-guide/kserve-grpc-inference',
           ],
         },
         {
@@ -98,7 +100,7 @@ const sidebars: SidebarsConfig = {
     {
       type: 'category',
       label: 'General',
-      items: ['general/cert-manager', 'general/faq', 'general/prometheus', 'general/data-parallel-deployment'],
+      items: ['general/cert-manager', 'general/fips', 'general/mock-engine', 'general/faq', 'general/prometheus', 'general/data-parallel-deployment'],
     },
     {
       type: 'category',
@@ -191,6 +193,7 @@ const sidebars: SidebarsConfig = {
                 { type: 'doc', id: 'reference/kthena-cli/kthena_describe_template', label: 'Describe template' },
               ],
             },
+            { type: 'doc', id: 'reference/kthena-cli/kthena_doctor', label: 'Doctor' },
           ],
         },
         {
@@ -0,0 +1,28 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+const (
+	// TenantLabelKey is the ModelRoute label key for the tenant owning the route.
+	// A tenant router only serves the ModelRoutes labeled with its tenant name.
+	TenantLabelKey = "networking.serving.volcano.sh/tenant"
+
+	// TenantWeightsAnnotationKey is the ModelRoute annotation key for the weights of the tenants sharing the route
+	// with fairness scheduling, as a comma separated list of tenant=weight, e.g. "team-a=3,team-b=1".
+	// The tenants without a weight have a weight of 1.
+	TenantWeightsAnnotationKey = "networking.serving.volcano.sh/tenant-weights"
+)
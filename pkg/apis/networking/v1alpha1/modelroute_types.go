@@ -22,7 +22,7 @@ import (
 )
 
 // ModelRouteSpec defines the desired state of ModelRoute.
-// +kubebuilder:validation:XValidation:rule="self.modelName != \"\" || size(self.loraAdapters) > 0", message="ModelName and LoraAdapters cannot both be empty"
+// +kubebuilder:validation:XValidation:rule="self.modelName != \"\" || size(self.loraAdapters) > 0 || has(self.modelNameMatch)", message="ModelName, LoraAdapters and ModelNameMatch cannot all be empty"
 type ModelRouteSpec struct {
 	// `model` in the LLM request, it could be a base model name, lora adapter name or even
 	// a virtual model name. This field is used to match scenarios other than model adapter name and
@@ -37,6 +37,23 @@ type ModelRouteSpec struct {
 	// +kubebuilder:validation:MaxItems=10
 	LoraAdapters []string `json:"loraAdapters,omitempty"`
 
+	// Aliases are other names of the model matched by the ModelRoute, e.g. the name of a hosted model its clients are
+	// migrated from. The `model` of the requests naming an alias is rewritten to the ModelName.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxItems=16
+	Aliases []string `json:"aliases,omitempty"`
+
+	// ModelNameMatch matches the `model` of the requests by prefix or regular expression, e.g. to serve the fine-tune
+	// variants of a model behind one ModelRoute. The ModelRoutes naming the model exactly, as their ModelName, an alias
+	// or a lora adapter, take precedence. Among the matching patterns, the longest prefix is preferred to the shorter
+	// ones and the prefixes are preferred to the regular expressions, the ties are broken by namespace and name.
+	// The `model` of the requests is forwarded as is.
+	//
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="has(self.prefix) != has(self.regex) && !has(self.exact)",message="exactly one of prefix and regex must be set"
+	ModelNameMatch *StringMatch `json:"modelNameMatch,omitempty"`
+
 	// ParentRefs references the Gateways that this ModelRoute should be attached to.
 	// If empty, the ModelRoute will be attached to all Gateways in the same namespace.
 	// +optional
@@ -53,6 +70,409 @@ type ModelRouteSpec struct {
 	// There is no limitation if this field is not set.
 	// +optional
 	RateLimit *RateLimit `json:"rateLimit,omitempty"`
+
+	// DefaultParameters are the sampling parameters injected into the LLM requests which don't set them.
+	// +optional
+	DefaultParameters *DefaultParameters `json:"defaultParameters,omitempty"`
+
+	// SLO declares the service level objectives of the model, the router exports the burn rates of their error budgets.
+	// +optional
+	SLO *SLO `json:"slo,omitempty"`
+
+	// HeaderPolicy controls which client headers are forwarded to the model servers and which model server headers
+	// are returned to the clients. The Authorization header is never forwarded unless it is explicitly allowed.
+	// +optional
+	HeaderPolicy *HeaderPolicy `json:"headerPolicy,omitempty"`
+
+	// Fallback lists the ModelServers the requests are retried on, in order, when the ModelServer selected by the
+	// rules fails before the response is sent to the client.
+	// +optional
+	Fallback *Fallback `json:"fallback,omitempty"`
+
+	// Mirror duplicates a share of the requests to a candidate ModelServer, e.g. to load test a new version of the
+	// model with the production traffic. The responses of the mirrored requests are discarded.
+	// +optional
+	Mirror *Mirror `json:"mirror,omitempty"`
+
+	// Evaluation shadows a share of the successful requests and their responses to an evaluation sink scoring the
+	// quality of the responses. The scores are exported per ModelServer, and gate the canary of the rollout with its
+	// MinEvaluationScore.
+	// +optional
+	Evaluation *Evaluation `json:"evaluation,omitempty"`
+
+	// Rollout progressively shifts the traffic the rules send to a stable ModelServer to a canary one, as long as the
+	// error rate and the latency of the canary stay within their thresholds, and rolls it back otherwise. The rollout
+	// controller of the controller manager records the progress in the status of the ModelRoute.
+	// +optional
+	Rollout *Rollout `json:"rollout,omitempty"`
+
+	// Priority is the traffic class and the level of the requests of the ModelRoute in the admission of the router.
+	// The batch requests leave a share of the concurrency of their model servers to the interactive ones during
+	// business hours, and the requests of lower levels are shed first once a shared model server saturates.
+	// +optional
+	Priority *Priority `json:"priority,omitempty"`
+
+	// Timeouts bound the time the requests of the ModelRoute wait on the model servers. There is no timeout by default.
+	// +optional
+	Timeouts *Timeouts `json:"timeouts,omitempty"`
+
+	// SessionAffinity sends the requests of the same session, e.g. the turns of a chat conversation, to the same
+	// model server pod. There is no session affinity by default.
+	// +optional
+	SessionAffinity *SessionAffinity `json:"sessionAffinity,omitempty"`
+
+	// Observability tunes how much of the requests of the ModelRoute the router records, e.g. to keep high-QPS routes
+	// from flooding the access log.
+	// +optional
+	Observability *Observability `json:"observability,omitempty"`
+
+	// Hedging sends a copy of the requests which are slow to answer to a second model server pod, and keeps the
+	// response of the pod answering first. It increases the load of the model servers, there is no hedging by default.
+	// +optional
+	Hedging *Hedging `json:"hedging,omitempty"`
+
+	// Concurrency limits the requests of the ModelRoute in flight at the same time, and sets what happens to the
+	// requests beyond it or beyond the MaxConcurrentRequestsPerPod of its model servers.
+	// +optional
+	Concurrency *Concurrency `json:"concurrency,omitempty"`
+
+	// StructuredOutput validates the responses of the requests asking for a JSON object or a JSON schema with their
+	// response_format, and samples them again when they are not valid, smaller models frequently emitting malformed
+	// structured output. The responses are not validated by default.
+	// +optional
+	StructuredOutput *StructuredOutput `json:"structuredOutput,omitempty"`
+}
+
+// StructuredOutput is the validation of the responses of the requests asking for structured output. Only the
+// non-streaming responses of the model servers which are not PD disaggregated are validated.
+type StructuredOutput struct {
+	// Retries is the number of times a request whose response is not valid JSON, or not valid against the JSON schema
+	// of its response_format, is sent again to the model server. The request fails with a 502
+	// invalid_structured_output error once the retries are exhausted, unless the Fallback of the ModelRoute serves it.
+	// Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=5
+	Retries *int32 `json:"retries,omitempty"`
+}
+
+// ConcurrencyOverflow is what happens to the requests beyond a concurrency limit.
+type ConcurrencyOverflow string
+
+const (
+	// ConcurrencyOverflowReject rejects the requests beyond the limit with 429.
+	ConcurrencyOverflowReject ConcurrencyOverflow = "Reject"
+	// ConcurrencyOverflowQueue holds the requests beyond the limit until a request completes, they are rejected with
+	// 429 once the queue timeout expires.
+	ConcurrencyOverflowQueue ConcurrencyOverflow = "Queue"
+)
+
+// Concurrency is the limit of the requests of a ModelRoute in flight at the same time.
+type Concurrency struct {
+	// MaxConcurrentRequests is the number of requests of the ModelRoute in flight at the same time, on all its model
+	// servers. There is no limit if it is not set.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrentRequests *int32 `json:"maxConcurrentRequests,omitempty"`
+	// Overflow is what happens to the requests beyond MaxConcurrentRequests, or beyond the MaxConcurrentRequestsPerPod
+	// of the model servers of the ModelRoute.
+	// +optional
+	// +kubebuilder:default="Reject"
+	// +kubebuilder:validation:Enum=Reject;Queue
+	Overflow ConcurrencyOverflow `json:"overflow,omitempty"`
+	// QueueTimeout is how long a request waits for a slot with the Queue overflow before being rejected.
+	// Defaults to 30s.
+	// +optional
+	QueueTimeout *metav1.Duration `json:"queueTimeout,omitempty"`
+}
+
+type TrafficClass string
+
+const (
+	// TrafficClassInteractive requests are always admitted.
+	TrafficClassInteractive TrafficClass = "Interactive"
+	// TrafficClassBatch requests are admitted within the concurrency left to them.
+	TrafficClassBatch TrafficClass = "Batch"
+)
+
+// Priority is the traffic class and the level of the requests of a ModelRoute.
+// +kubebuilder:validation:XValidation:rule="self.class != 'Batch' || has(self.maxConcurrentRequests)", message="maxConcurrentRequests is required for the Batch class"
+type Priority struct {
+	// Class is the traffic class of the requests.
+	// +optional
+	// +kubebuilder:default="Interactive"
+	// +kubebuilder:validation:Enum=Interactive;Batch
+	Class TrafficClass `json:"class,omitempty"`
+	// MaxConcurrentRequests is the number of requests each model server of the ModelRoute handles at the same time,
+	// counting the requests of every ModelRoute. The batch requests are rejected once it is reached.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrentRequests *int32 `json:"maxConcurrentRequests,omitempty"`
+	// InteractiveReservation reserves a share of MaxConcurrentRequests to the interactive requests during business
+	// hours, the batch requests are rejected once the rest is used.
+	// +optional
+	InteractiveReservation *InteractiveReservation `json:"interactiveReservation,omitempty"`
+	// Level ranks the ModelRoutes sharing a model server, the higher the more important. Once the model server is
+	// saturated, the requests of the ModelRoute are rejected while requests of a ModelRoute of a higher level are in
+	// flight on it. Defaults to 0.
+	// +optional
+	Level *int32 `json:"level,omitempty"`
+	// SaturationPercent is the share of the capacity of a model server, its MaxConcurrentRequestsPerPod times the
+	// number of its pods, from which it is saturated for the requests of the ModelRoute. Defaults to 90. Model
+	// servers without MaxConcurrentRequestsPerPod are never saturated.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	SaturationPercent *int32 `json:"saturationPercent,omitempty"`
+}
+
+// InteractiveReservation is a share of the concurrency of the model servers not admitting batch requests.
+type InteractiveReservation struct {
+	// Percentage of MaxConcurrentRequests reserved to the interactive requests.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Percentage int32 `json:"percentage"`
+	// BusinessHours are when the share is reserved, it is open to the batch requests the rest of the time.
+	BusinessHours BusinessHours `json:"businessHours"`
+}
+
+// BusinessHours is a daily time window on some days of the week.
+type BusinessHours struct {
+	// TimeZone is the IANA time zone of the window, e.g. "Europe/Paris". Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+	// Days are the days of the week the window starts on, Monday to Friday by default.
+	// +optional
+	// +kubebuilder:validation:MaxItems=7
+	// +kubebuilder:validation:items:Enum=Monday;Tuesday;Wednesday;Thursday;Friday;Saturday;Sunday
+	Days []string `json:"days,omitempty"`
+	// Start of the window, "HH:MM".
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+	// End of the window, "HH:MM". The window ends the next day if it is not after Start.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+}
+
+// Timeouts of the requests of a ModelRoute. A timeout which is not set is not enforced.
+type Timeouts struct {
+	// Connect is the time to connect to a model server pod. The request is retried on the next pod selected for it
+	// when it expires.
+	// +optional
+	Connect *metav1.Duration `json:"connect,omitempty"`
+	// FirstToken is the time a streaming request waits for the first event of the response of a model server pod,
+	// including the time to connect. The request is retried on the next pod selected for it when it expires.
+	// +optional
+	FirstToken *metav1.Duration `json:"firstToken,omitempty"`
+	// Request is the total time of a request, including its retries and fallbacks. A response being streamed when
+	// it expires is interrupted.
+	// +optional
+	Request *metav1.Duration `json:"request,omitempty"`
+}
+
+// Hedging bounds the time a request of a ModelRoute waits on the first model server pod selected for it before it is
+// also sent to the next one. The request on the pod answering last is canceled.
+type Hedging struct {
+	// Delay is the time a streaming request waits for the first event of the response, or a non-streaming request for
+	// the response, before it is hedged.
+	Delay metav1.Duration `json:"delay"`
+}
+
+type SessionAffinityType string
+
+const (
+	// SessionAffinityCookie pins the sessions with a cookie naming the pod, set on the responses.
+	SessionAffinityCookie SessionAffinityType = "Cookie"
+	// SessionAffinityConsistentHash pins the sessions by hashing their key on the pods, all the router replicas
+	// pick the same pod for a session.
+	SessionAffinityConsistentHash SessionAffinityType = "ConsistentHash"
+)
+
+// SessionAffinity pins the requests of a session to a model server pod. A pinned pod which is no longer serving
+// is replaced by the pod scheduled for the request.
+type SessionAffinity struct {
+	// Type is how the sessions are pinned to the pods.
+	// +optional
+	// +kubebuilder:default="ConsistentHash"
+	// +kubebuilder:validation:Enum=Cookie;ConsistentHash
+	Type SessionAffinityType `json:"type,omitempty"`
+	// Header is the request header holding the session key of the ConsistentHash type. The `user` field of the
+	// request body is the key of the requests without the header, the requests without a key are not pinned.
+	// +optional
+	Header string `json:"header,omitempty"`
+	// CookieName is the name of the cookie of the Cookie type. Defaults to "kthena-session".
+	// +optional
+	CookieName string `json:"cookieName,omitempty"`
+	// TTL is the time a session stays pinned to its pod after its last request. Defaults to 30m.
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+}
+
+// Fallback is an ordered chain of alternative ModelServers.
+type Fallback struct {
+	// ModelServerNames are the ModelServers in the namespace of the ModelRoute tried in order.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=8
+	ModelServerNames []string `json:"modelServerNames"`
+	// StatusCodes are the status codes of the model server responses triggering a fallback, the connection errors
+	// and timeouts always trigger it. Defaults to 500, 502, 503 and 504.
+	// +optional
+	// +kubebuilder:validation:MaxItems=16
+	// +kubebuilder:validation:items:Minimum=400
+	// +kubebuilder:validation:items:Maximum=599
+	StatusCodes []int32 `json:"statusCodes,omitempty"`
+	// MaxDepth is the maximum number of fallback ModelServers tried for a request, all of them by default.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxDepth *int32 `json:"maxDepth,omitempty"`
+}
+
+// Mirror is the ModelServer the requests of a ModelRoute are mirrored to. A request is mirrored once the ModelServer
+// selected by the rules admitted it, the mirrored requests are dropped when the router mirrors too many at once.
+type Mirror struct {
+	// ModelServerName is the ModelServer in the namespace of the ModelRoute the requests are mirrored to.
+	ModelServerName string `json:"modelServerName"`
+	// Percentage of the requests mirrored.
+	// +optional
+	// +kubebuilder:default=100
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Percentage *int32 `json:"percentage,omitempty"`
+}
+
+// Evaluation is the sink service the pairs of requests and responses of a ModelRoute are shadowed to. The router
+// POSTs a sampled pair once its response succeeded, and expects the score of the response between 0 and 1 in return.
+// Nobody waits on the evaluations, they are dropped when the router evaluates too many responses at once.
+type Evaluation struct {
+	// URL is the HTTP endpoint of the evaluation sink, e.g. "http://evaluator.default.svc:8080/evaluate".
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+	// Percentage of the successful requests evaluated.
+	// +optional
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Percentage *int32 `json:"percentage,omitempty"`
+	// Timeout bounds the evaluation of a response by the sink. Defaults to 30s.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// Rollout is a canary release of a ModelServer replacing another one of the rules, modelled after Flagger. The canary
+// is analyzed over each interval with the metrics of the routers: a successful analysis advances the canary weight to
+// the next step, the rollout is rolled back after FailureThreshold failed analyses.
+type Rollout struct {
+	// StableModelServerName is the ModelServer targeted by the rules whose traffic is shifted.
+	StableModelServerName string `json:"stableModelServerName"`
+	// CanaryModelServerName is the ModelServer in the namespace of the ModelRoute the traffic is shifted to. Changing
+	// it starts a new rollout.
+	CanaryModelServerName string `json:"canaryModelServerName"`
+	// Steps are the increasing percentages of the traffic of the stable ModelServer sent to the canary one. Defaults
+	// to 5, 25, 50 and 100.
+	// +optional
+	// +kubebuilder:validation:MaxItems=16
+	// +kubebuilder:validation:items:Minimum=1
+	// +kubebuilder:validation:items:Maximum=100
+	Steps []int32 `json:"steps,omitempty"`
+	// Interval is the time the canary is analyzed at each step. Defaults to 5m.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// MaxErrorRate is the maximum percentage of the requests sent to the canary failing with a server error, e.g. "1".
+	// Defaults to "1".
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(100|[0-9]{1,2}(\.[0-9]+)?)$`
+	MaxErrorRate *string `json:"maxErrorRate,omitempty"`
+	// MaxLatency is the maximum 99th percentile of the end-to-end duration of the requests sent to the canary. The
+	// latency is not analyzed by default.
+	// +optional
+	MaxLatency *metav1.Duration `json:"maxLatency,omitempty"`
+	// MinEvaluationScore is the minimum average score of the responses of the canary evaluated by the evaluation
+	// sink of the ModelRoute, e.g. "0.8". The scores are not analyzed by default.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(0(\.[0-9]+)?|1(\.0+)?)$`
+	MinEvaluationScore *string `json:"minEvaluationScore,omitempty"`
+	// FailureThreshold is the number of failed analyses rolling the canary back.
+	// +optional
+	// +kubebuilder:default=2
+	// +kubebuilder:validation:Minimum=1
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+}
+
+// Observability is the sampling of the requests of a ModelRoute in the records of the router.
+type Observability struct {
+	// AccessLogPercentage is the percentage of the requests written to the access log. The requests failing with a
+	// server error are always written. The SLOs, feedback and usage events are not sampled.
+	// +optional
+	// +kubebuilder:default=100
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	AccessLogPercentage *int32 `json:"accessLogPercentage,omitempty"`
+}
+
+// HeaderPolicy controls the propagation of the headers between the clients and the model servers.
+type HeaderPolicy struct {
+	// Request filters the headers of the client requests forwarded to the model servers.
+	// +optional
+	Request *HeaderFilter `json:"request,omitempty"`
+	// Response filters the headers of the model server responses returned to the clients.
+	// +optional
+	Response *HeaderFilter `json:"response,omitempty"`
+	// RequestHeaderModifier adds, sets and removes headers of the requests forwarded to the model servers, e.g. to
+	// inject the credentials of the model servers. It is applied after Request filtered the client headers.
+	// +optional
+	RequestHeaderModifier *gatewayv1.HTTPHeaderFilter `json:"requestHeaderModifier,omitempty"`
+	// ResponseHeaderModifier adds, sets and removes headers of the model server responses returned to the clients,
+	// e.g. to strip internal headers. It is applied after Response filtered the model server headers.
+	// +optional
+	ResponseHeaderModifier *gatewayv1.HTTPHeaderFilter `json:"responseHeaderModifier,omitempty"`
+}
+
+// HeaderFilter is an allow and deny list of header names, matched case-insensitively. A name ending with `*` matches
+// every header starting with it, e.g. `X-Tenant-*`. Content-Type, Content-Length, Content-Encoding and
+// Transfer-Encoding are always propagated.
+type HeaderFilter struct {
+	// Allow lists the headers propagated. All headers not denied are propagated if it is empty.
+	// +optional
+	// +kubebuilder:validation:MaxItems=64
+	Allow []string `json:"allow,omitempty"`
+	// Deny lists the headers removed, it takes precedence over Allow.
+	// +optional
+	// +kubebuilder:validation:MaxItems=64
+	Deny []string `json:"deny,omitempty"`
+}
+
+// DefaultParameters are sampling parameters applied to LLM requests when clients omit them,
+// e.g. to bound the generation length instead of relying on the unbounded defaults of the inference engine.
+type DefaultParameters struct {
+	// MaxTokens is the `max_tokens` injected into requests setting neither `max_tokens` nor `max_completion_tokens`.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxTokens *int32 `json:"maxTokens,omitempty"`
+	// Temperature is the `temperature` injected into requests not setting it, between "0" and "2".
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(([01](\.[0-9]+)?)|(2(\.0+)?))$`
+	Temperature *string `json:"temperature,omitempty"`
+}
+
+// SLO declares the service level objectives of the requests sent through a ModelRoute. The router exports how fast
+// their error budgets are consumed, so that alerting can be driven off error budgets rather than static thresholds.
+type SLO struct {
+	// Availability is the target percentage of the requests not failing with a server error, e.g. "99.9".
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]{1,2}(\.[0-9]+)?$`
+	Availability *string `json:"availability,omitempty"`
+	// Latency is the objective for the end-to-end duration of the successful requests.
+	// +optional
+	Latency *LatencyObjective `json:"latency,omitempty"`
+}
+
+// LatencyObjective is the target percentage of the successful requests served within a threshold.
+type LatencyObjective struct {
+	// Threshold is the end-to-end duration the requests are expected to be served within, e.g. "2s".
+	Threshold metav1.Duration `json:"threshold"`
+	// Target is the target percentage of the successful requests served within the threshold, e.g. "99".
+	// +kubebuilder:validation:Pattern=`^[0-9]{1,2}(\.[0-9]+)?$`
+	Target string `json:"target"`
 }
 
 type Rule struct {
@@ -84,6 +504,17 @@ type ModelMatch struct {
 	// Body contains conditions to match request body content
 	// +optional
 	Body *BodyMatch `json:"body,omitempty"`
+
+	// Expression is a CEL expression over the request, evaluating to true when the request matches, for the
+	// conditions the other matches can't express. It may read `request.method`, `request.path`, `request.headers`
+	// by lower case name, `request.model`, `request.body`, the parsed JSON body, `request.tokens`, the input tokens
+	// counted by the router, and `consumer.subject`, `consumer.authenticator`, `consumer.team` and `consumer.org`,
+	// e.g. `request.tokens > 4000 && consumer.team == "research"`. An expression failing to evaluate, e.g. because it
+	// reads a body field the request doesn't have, doesn't match.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=4096
+	Expression *string `json:"expression,omitempty"`
 }
 
 // BodyMatch defines the predicate used to match request body content
@@ -92,6 +523,31 @@ type BodyMatch struct {
 	// If this field is not specified, any model or lora adapter will be matched.
 	// +optional
 	Model *string `json:"model,omitempty"`
+	// Fields match other fields of the JSON body of the requests, e.g. to route the tool calling requests to the
+	// model servers supporting tools. All the fields must match.
+	// +optional
+	// +kubebuilder:validation:MaxItems=16
+	Fields []BodyFieldMatch `json:"fields,omitempty"`
+}
+
+// BodyFieldMatch matches a field of the JSON body of the requests. The field must be present if neither Value nor
+// Present is set.
+//
+// +kubebuilder:validation:XValidation:rule="!(has(self.value) && has(self.present))",message="value and present are mutually exclusive"
+type BodyFieldMatch struct {
+	// Path is the dot-separated path of the field, e.g. `metadata.team`. The elements of the arrays are addressed by
+	// their index, e.g. `messages.0.role`.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=256
+	Path string `json:"path"`
+	// Value matches the value of the field. The strings are matched as is, the numbers and the booleans by their
+	// JSON representation, the objects and the arrays never match.
+	// +optional
+	Value *StringMatch `json:"value,omitempty"`
+	// Present matches whether the field is present. A field set to null, an empty array or an empty object, e.g.
+	// `"tools": []`, is not present.
+	// +optional
+	Present *bool `json:"present,omitempty"`
 }
 
 // StringMatch defines the matching conditions for string fields.
@@ -129,14 +585,49 @@ type RateLimit struct {
 	// +optional
 	// +kubebuilder:validation:Minimum=1
 	OutputTokensPerUnit *uint32 `json:"outputTokensPerUnit,omitempty"`
+	// ImagesPerUnit is the maximum number of images generated per unit of time, for the image generation endpoint
+	// which isn't subject to the token limits.
+	// If this field is not set, there is no limit on images.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ImagesPerUnit *uint32 `json:"imagesPerUnit,omitempty"`
+	// MegapixelsPerUnit is the maximum number of megapixels of the images generated per unit of time.
+	// If this field is not set, there is no limit on megapixels.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4000000
+	MegapixelsPerUnit *uint32 `json:"megapixelsPerUnit,omitempty"`
+	// ItemsPerUnit is the maximum number of input items per unit of time: the inputs of embeddings and
+	// classification requests, the documents of rerank requests and the prompts of completions requests batching
+	// several of them. The other requests count as one item.
+	// If this field is not set, there is no limit on items.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ItemsPerUnit *uint32 `json:"itemsPerUnit,omitempty"`
+	// BytesPerUnit is the maximum size in bytes of the request bodies per unit of time.
+	// If this field is not set, there is no limit on bytes.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	BytesPerUnit *uint32 `json:"bytesPerUnit,omitempty"`
 	// Unit is the time unit for the rate limit.
 	// +kubebuilder:default=second
 	// +kubebuilder:validation:Enum=second;minute;hour;day;month
 	Unit RateLimitUnit `json:"unit"`
+	// Burst is the number of units of time the unused limits accumulate over. The requests may burst above the
+	// steady rate of the limits until they use up to Burst times the limits at once. Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	Burst *uint32 `json:"burst,omitempty"`
 	// Global contains configuration for global rate limiting using distributed storage.
 	// If this field is set, global rate limiting will be used; otherwise, local rate limiting will be used.
 	// +optional
 	Global *GlobalRateLimit `json:"global,omitempty"`
+	// DegradeTo is the name of a ModelServer, in the namespace of the ModelRoute, serving the requests exceeding the
+	// token limits, e.g. a cheaper and smaller model, instead of rejecting them with 429. The requests degraded to it
+	// aren't counted against the limits.
+	// +optional
+	DegradeTo *string `json:"degradeTo,omitempty"`
 }
 
 // GlobalRateLimit contains configuration for global rate limiting
@@ -166,6 +657,53 @@ const (
 
 // ModelRouteStatus defines the observed state of ModelRoute.
 type ModelRouteStatus struct {
+	// Rollout is the progress of the rollout of the canary ModelServer.
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+	// Parents is the status of the ModelRoute for each of its parent Gateways, whether the Gateway accepted it and
+	// whether its ModelServers exist. The ModelRoutes without parentRefs have no parents.
+	// +optional
+	// +listType=atomic
+	// +kubebuilder:validation:MaxItems=32
+	Parents []gatewayv1.RouteParentStatus `json:"parents,omitempty"`
+}
+
+// RolloutPhase is the phase of a rollout.
+type RolloutPhase string
+
+const (
+	// RolloutProgressing is the phase of a rollout analyzing the canary at its current step.
+	RolloutProgressing RolloutPhase = "Progressing"
+	// RolloutSucceeded is the phase of a rollout whose last step was analyzed successfully. The canary keeps the weight
+	// of the last step until the rules are updated and the rollout removed.
+	RolloutSucceeded RolloutPhase = "Succeeded"
+	// RolloutRolledBack is the phase of a rollout whose canary failed too many analyses, it receives no traffic.
+	RolloutRolledBack RolloutPhase = "RolledBack"
+)
+
+// RolloutStatus is the progress of a rollout, the routers send CanaryWeight percent of the traffic of the stable
+// ModelServer to the canary one.
+type RolloutStatus struct {
+	// CanaryModelServerName is the canary ModelServer of the rollout.
+	CanaryModelServerName string `json:"canaryModelServerName"`
+	// Phase is the phase of the rollout.
+	Phase RolloutPhase `json:"phase"`
+	// Step is the index of the current step of the rollout.
+	Step int32 `json:"step"`
+	// CanaryWeight is the percentage of the traffic of the stable ModelServer sent to the canary one.
+	CanaryWeight int32 `json:"canaryWeight"`
+	// FailedAnalyses is the number of failed analyses of the canary.
+	// +optional
+	FailedAnalyses int32 `json:"failedAnalyses,omitempty"`
+	// LastAnalysisTime is the last time the canary was analyzed, or the start of the rollout.
+	// +optional
+	LastAnalysisTime metav1.Time `json:"lastAnalysisTime,omitempty"`
+	// LastTransitionTime is the last time the canary weight changed.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Message describes the result of the last analysis.
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -33,9 +34,14 @@ type ModelServerSpec struct {
 	InferenceEngine InferenceEngine `json:"inferenceEngine"`
 	// WorkloadSelector is used to match the model serving instances.
 	// Currently, they must be pods within the same namespace as modelServer object.
-	//
-	// +kubebuilder:validation:Required
-	WorkloadSelector *WorkloadSelector `json:"workloadSelector"`
+	// It is required unless the instances are found by discovery.
+	// +optional
+	WorkloadSelector *WorkloadSelector `json:"workloadSelector,omitempty"`
+
+	// Discovery finds the model serving instances which are not pods of the cluster, e.g. the inference engines of
+	// bare-metal hosts. It is exclusive with workloadSelector.
+	// +optional
+	Discovery *Discovery `json:"discovery,omitempty"`
 
 	// WorkloadPort defines the port and protocol configuration for the model server.
 	WorkloadPort WorkloadPort `json:"workloadPort,omitempty"`
@@ -47,11 +53,151 @@ type ModelServerSpec struct {
 	// KVConnector specifies the KV connector configuration for PD disaggregated routing
 	// +optional
 	KVConnector *KVConnectorSpec `json:"kvConnector,omitempty"`
+
+	// MaxContextLength is the maximum number of tokens (prompt plus completion) the served model accepts,
+	// e.g. the `--max-model-len` of vLLM. Requests exceeding it are rejected by the router
+	// with a `context_length_exceeded` error instead of failing in the inference engine.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxContextLength *int32 `json:"maxContextLength,omitempty"`
+
+	// MaxConcurrentRequestsPerPod is the number of requests the router sends to each pod of the model server at the
+	// same time, counting the requests of every ModelRoute, e.g. the `--max-num-seqs` of vLLM. The pods at the limit
+	// are not selected, the requests finding all the pods at the limit overflow as set by the Concurrency of their
+	// ModelRoute, and are rejected with 429 by default. The pods of PD disaggregated model servers are not limited.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrentRequestsPerPod *int32 `json:"maxConcurrentRequestsPerPod,omitempty"`
+
+	// LoadBalancingPolicy selects the pods of the model server the requests are sent to, instead of the scheduler
+	// plugins configured in the router.
+	// +optional
+	LoadBalancingPolicy LoadBalancingPolicy `json:"loadBalancingPolicy,omitempty"`
+
+	// SlowStart ramps up the share of the requests sent to the pods which just became ready, e.g. new replicas with
+	// empty caches or still compiling their kernels, instead of sending them a full share at once.
+	// +optional
+	SlowStart *SlowStart `json:"slowStart,omitempty"`
+
+	// PrefillCoalescing coalesces the prefill of the concurrent requests sharing a long prompt prefix, e.g. the
+	// few-shot examples of an eval sweep, in PD disaggregated mode.
+	// +optional
+	PrefillCoalescing *PrefillCoalescing `json:"prefillCoalescing,omitempty"`
+
+	// WarmUp sends a request to each new pod of the model server before the router routes requests to it, so that
+	// the CUDA graphs capture and kernel compilation triggered by the first request are off the critical path.
+	// +optional
+	WarmUp *WarmUp `json:"warmUp,omitempty"`
+
+	// Pricing is the cost of the tokens served by the model server, which the requests are charged to the cost limits
+	// of the RateLimitPolicies once their usage is known.
+	// +optional
+	Pricing *Pricing `json:"pricing,omitempty"`
+
+	// Capabilities are the capabilities of the served model. The requests requiring a capability, e.g. the chat
+	// completions with images requiring vision or with tools requiring tools, are only routed to the ModelServers
+	// declaring it. A ModelServer which declares no capability is assumed to have them all but vision.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=8
+	Capabilities []Capability `json:"capabilities,omitempty"`
 }
 
+// Capability is a capability of a served model that some requests require.
+//
+// +kubebuilder:validation:Enum=tools;json_mode;vision;reasoning
+type Capability string
+
+const (
+	// CapabilityTools is the capability of the models calling the tools or functions declared by the requests.
+	CapabilityTools Capability = "tools"
+	// CapabilityJSONMode is the capability of the models generating the JSON objects or the JSON schema requested by
+	// the response_format of the requests.
+	CapabilityJSONMode Capability = "json_mode"
+	// CapabilityVision is the capability of the models accepting images in the content of the chat messages.
+	CapabilityVision Capability = "vision"
+	// CapabilityReasoning is the capability of the models reasoning with the effort requested by the
+	// reasoning_effort or reasoning of the requests.
+	CapabilityReasoning Capability = "reasoning"
+)
+
+// Pricing is the cost of the tokens of a model server, in the currency units the cost limits of the RateLimitPolicies
+// are expressed in, e.g. dollars.
+type Pricing struct {
+	// InputPer1KTokens is the cost of 1000 input tokens, e.g. 0.0005.
+	// +optional
+	InputPer1KTokens *resource.Quantity `json:"inputPer1KTokens,omitempty"`
+	// OutputPer1KTokens is the cost of 1000 output tokens, e.g. 0.0015.
+	// +optional
+	OutputPer1KTokens *resource.Quantity `json:"outputPer1KTokens,omitempty"`
+}
+
+// WarmUp is the request the router sends to a new pod of a model server. The pod is not routed requests to until
+// the warm-up request completes, fails or times out.
+type WarmUp struct {
+	// Prompt is the prompt of the warm-up completion request.
+	// +optional
+	// +kubebuilder:default=Hello
+	Prompt string `json:"prompt,omitempty"`
+	// MaxTokens is the maximum number of tokens generated for the warm-up request.
+	// +optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	MaxTokens *int32 `json:"maxTokens,omitempty"`
+	// Timeout is the maximum time the router waits for the warm-up request, after which the pod is routed requests
+	// anyway. Defaults to 1m.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// PrefillCoalescing holds the requests whose prompt starts like the one of a request being prefilled until its
+// prefill completes, then sends them to the same prefill pod, whose prefix cache holds their common prefix.
+type PrefillCoalescing struct {
+	// MaxWait is the maximum time a request waits for the prefill of the request it is coalesced with.
+	MaxWait metav1.Duration `json:"maxWait"`
+	// MinPrefixLength is the number of leading characters of the prompts the requests are coalesced on. The requests
+	// with shorter prompts are not coalesced.
+	// +optional
+	// +kubebuilder:default=1024
+	// +kubebuilder:validation:Minimum=1
+	MinPrefixLength *int32 `json:"minPrefixLength,omitempty"`
+}
+
+// SlowStart is the warm-up of the pods of a model server. A warming up pod is skipped by a share of the requests it
+// would have been sent, whatever the load balancing policy.
+type SlowStart struct {
+	// Window is the time after a pod became ready during which its share of the requests grows linearly from
+	// MinPercentage to a full share.
+	Window metav1.Duration `json:"window"`
+	// MinPercentage is the share of the requests a pod gets when it just became ready, in percent of a full share.
+	// +optional
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MinPercentage *int32 `json:"minPercentage,omitempty"`
+}
+
+// LoadBalancingPolicy defines how the router selects the pod of a model server a request is sent to.
+//
+// +kubebuilder:validation:Enum=prefixCacheAware;leastLatency;roundRobin;random
+type LoadBalancingPolicy string
+
+const (
+	// LoadBalancingPolicyPrefixCacheAware sends the requests of the same session, or sharing the same prompt prefix,
+	// e.g. a long system prompt, to the same pod, so that they hit its prefix cache.
+	LoadBalancingPolicyPrefixCacheAware LoadBalancingPolicy = "prefixCacheAware"
+	// LoadBalancingPolicyLeastLatency sends the requests to the pod with the lowest moving average of the time to
+	// first token observed by the router.
+	LoadBalancingPolicyLeastLatency LoadBalancingPolicy = "leastLatency"
+	// LoadBalancingPolicyRoundRobin sends the requests to the pods in turn.
+	LoadBalancingPolicyRoundRobin LoadBalancingPolicy = "roundRobin"
+	// LoadBalancingPolicyRandom sends the requests to a random pod.
+	LoadBalancingPolicyRandom LoadBalancingPolicy = "random"
+)
+
 // InferenceEngine defines the inference framework used by the modelServer to serve LLM requests.
 //
-// +kubebuilder:validation:Enum=vLLM;SGLang
+// +kubebuilder:validation:Enum=vLLM;SGLang;Triton
 type InferenceEngine string
 
 const (
@@ -59,6 +205,9 @@ const (
 	VLLM InferenceEngine = "vLLM"
 	// https://github.com/sgl-project/sglang
 	SGLang InferenceEngine = "SGLang"
+	// Triton serves any model with the KServe v2 inference protocol, routed by the gRPC listener of the router.
+	// https://github.com/triton-inference-server/server
+	Triton InferenceEngine = "Triton"
 )
 
 // WorkloadSelector is used to match the model serving instances.
@@ -68,6 +217,11 @@ type WorkloadSelector struct {
 	// All serving instances must match these labels.
 	// +kube:validation:Required
 	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+	// MatchExpressions further select the model serving instances by their labels, e.g. the subset of the instances
+	// of a given version. The selector is evaluated on the current labels of the pods, the pods whose labels change,
+	// e.g. during a rolling update, join or leave the model server without recreating it.
+	// +optional
+	MatchExpressions []metav1.LabelSelectorRequirement `json:"matchExpressions,omitempty"`
 	// PDGroup is used to further match different roles of the model serving instances,
 	// mainly used in case like PD disaggregation.
 	PDGroup *PDGroup `json:"pdGroup,omitempty"`
@@ -85,6 +239,109 @@ type PDGroup struct {
 	DecodeLabels map[string]string `json:"decodeLabels"`
 }
 
+// DiscoveryType is the source the model serving instances are discovered from.
+type DiscoveryType string
+
+const (
+	// DiscoveryTypeEndpointSlice discovers the ready endpoints of the EndpointSlices of a Service.
+	DiscoveryTypeEndpointSlice DiscoveryType = "EndpointSlice"
+	// DiscoveryTypeStatic uses a static list of IP addresses.
+	DiscoveryTypeStatic DiscoveryType = "Static"
+	// DiscoveryTypeDNS resolves the targets of a DNS SRV record.
+	DiscoveryTypeDNS DiscoveryType = "DNS"
+	// DiscoveryTypeConsul queries the passing instances of a Consul service.
+	DiscoveryTypeConsul DiscoveryType = "Consul"
+)
+
+// Discovery finds the model serving instances of a model server out of its pods. The instances are served on the
+// port of workloadPort, they are health checked by the router and routed to like the pods once healthy.
+type Discovery struct {
+	// Type is the source of the instances, its field configures it.
+	// +kubebuilder:validation:Enum=EndpointSlice;Static;DNS;Consul
+	Type DiscoveryType `json:"type"`
+	// EndpointSlice discovers the instances of the EndpointSlices of a Service.
+	// +optional
+	EndpointSlice *EndpointSliceDiscovery `json:"endpointSlice,omitempty"`
+	// Static lists the instances.
+	// +optional
+	Static *StaticDiscovery `json:"static,omitempty"`
+	// DNS discovers the instances of a DNS SRV record.
+	// +optional
+	DNS *DNSDiscovery `json:"dns,omitempty"`
+	// Consul discovers the instances of a Consul service.
+	// +optional
+	Consul *ConsulDiscovery `json:"consul,omitempty"`
+	// RefreshInterval is the interval between two discoveries of the instances, 30s by default. The EndpointSlices
+	// are watched, their changes are applied immediately.
+	// +optional
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+	// HealthCheck configures the health checks of the instances.
+	// +optional
+	HealthCheck *HealthCheck `json:"healthCheck,omitempty"`
+}
+
+// EndpointSliceDiscovery discovers the ready endpoints of the EndpointSlices of a Service in the namespace of the
+// model server, e.g. of a Service without selector whose EndpointSlices list the addresses of external hosts.
+type EndpointSliceDiscovery struct {
+	// ServiceName is the name of the Service.
+	// +kubebuilder:validation:MinLength=1
+	ServiceName string `json:"serviceName"`
+}
+
+// StaticDiscovery is a static list of instances.
+type StaticDiscovery struct {
+	// Addresses are the IP addresses of the instances.
+	// +listType=set
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=256
+	Addresses []string `json:"addresses"`
+}
+
+// DNSDiscovery discovers the instances of the targets of a DNS SRV record. The targets whose port isn't the port of
+// workloadPort are ignored.
+type DNSDiscovery struct {
+	// Name is the name of the SRV record, e.g. _vllm._tcp.inference.example.com.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// ConsulDiscovery discovers the instances of a Consul service passing their health checks. The instances whose port
+// isn't the port of workloadPort are ignored.
+type ConsulDiscovery struct {
+	// Address is the URL of the Consul HTTP API, e.g. http://consul.example.com:8500.
+	// +kubebuilder:validation:MinLength=1
+	Address string `json:"address"`
+	// Service is the name of the Consul service.
+	// +kubebuilder:validation:MinLength=1
+	Service string `json:"service"`
+	// Tag filters the instances of the service by tag.
+	// +optional
+	Tag string `json:"tag,omitempty"`
+	// Datacenter is the datacenter of the service, the datacenter of the queried agent by default.
+	// +optional
+	Datacenter string `json:"datacenter,omitempty"`
+}
+
+// HealthCheck configures the HTTP health checks of the discovered instances. An instance is routed to once it
+// passes a health check, and until it fails unhealthyThreshold consecutive ones.
+type HealthCheck struct {
+	// Path is the path of the health checks, the health endpoint of the inference engine by default, e.g. /health
+	// for vLLM.
+	// +optional
+	Path string `json:"path,omitempty"`
+	// Interval is the interval between two health checks of an instance, 10s by default.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// Timeout is the timeout of a health check, 2s by default.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// UnhealthyThreshold is the number of consecutive failed health checks after which an instance is no longer
+	// routed to, 3 by default.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	UnhealthyThreshold *int32 `json:"unhealthyThreshold,omitempty"`
+}
+
 // WorkloadPort defines the port and protocol configuration for the model server.
 type WorkloadPort struct {
 	// The port of the model server. The number must be between 1 and 65535.
@@ -99,6 +356,54 @@ type WorkloadPort struct {
 	// +kubebuilder:default="http"
 	// +kubebuilder:validation:Enum=http;https
 	Protocol string `json:"protocol,omitempty"`
+
+	// TLS configures the connections to the model server when the protocol is "https".
+	// +optional
+	TLS *BackendTLS `json:"tls,omitempty"`
+
+	// GRPCPort is the port of the KServe v2 inference gRPC service of the model server, e.g. 8001 for Triton. The
+	// KServe v2 gRPC calls are sent to Port if unset.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	GRPCPort *int32 `json:"grpcPort,omitempty"`
+}
+
+type TLSVerification string
+
+const (
+	// TLSVerificationFull verifies the certificate chain and that the certificate is valid for the server name.
+	TLSVerificationFull TLSVerification = "Full"
+	// TLSVerificationCertificateChain verifies the certificate chain only, e.g. for certificates not listing the pod IPs.
+	TLSVerificationCertificateChain TLSVerification = "CertificateChain"
+	// TLSVerificationNone doesn't verify the certificates of the model server.
+	TLSVerificationNone TLSVerification = "None"
+)
+
+// BackendTLS configures the TLS connections of the router to the model server pods.
+type BackendTLS struct {
+	// Verification is how the certificates of the model server are verified.
+	// +optional
+	// +kubebuilder:default="Full"
+	// +kubebuilder:validation:Enum=Full;CertificateChain;None
+	Verification TLSVerification `json:"verification,omitempty"`
+	// ServerName is sent in the SNI extension and verified against the certificates, the pod IP by default.
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+	// CABundle references the PEM encoded CA certificates verifying the model server, e.g. of an internal PKI.
+	// The system roots are used if it is not set.
+	// +optional
+	CABundle *SecretKeyReference `json:"caBundle,omitempty"`
+}
+
+// SecretKeyReference references a key of a Secret in the namespace of the referencing object.
+type SecretKeyReference struct {
+	// Name of the Secret.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Key of the Secret data, "ca.crt" by default.
+	// +optional
+	Key string `json:"key,omitempty"`
 }
 
 type KVConnectorType string
@@ -131,6 +436,9 @@ type TrafficPolicy struct {
 	// TODO: add LoadBalancer policy
 }
 
+// Retry sends an inference request which failed on a model server pod before any byte of its response reached the
+// client to the next pod selected for it, excluding the pods it already failed on. A response which started is never
+// retried. Without a retry policy, a failed request is retried on every pod selected for it, whatever the failure.
 type Retry struct {
 	// The maximum number of times an individual inference request to a model server should be retried.
 	// If the maximum number of retries has been done without a successgful response, the request will be considered failed.
@@ -139,6 +447,17 @@ type Retry struct {
 	// RetryInterval is the interval between retries.
 	// +kubebuilder:default="100ms"
 	RetryInterval *metav1.Duration `json:"retryInterval,omitempty"`
+	// Budget is the time from the first attempt after which a failed request is no longer retried, e.g. to keep
+	// the retries within the timeout of the clients. There is no budget by default.
+	// +optional
+	Budget *metav1.Duration `json:"budget,omitempty"`
+	// StatusCodes are the status codes of the model server responses which are retried, the connection errors and the
+	// connect and first token timeouts are always retried. Defaults to 502, 503 and 504.
+	// +optional
+	// +kubebuilder:validation:MaxItems=16
+	// +kubebuilder:validation:items:Minimum=400
+	// +kubebuilder:validation:items:Maximum=599
+	StatusCodes []int32 `json:"statusCodes,omitempty"`
 }
 
 // ModelServerStatus defines the observed state of ModelServer.
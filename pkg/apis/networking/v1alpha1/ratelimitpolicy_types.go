@@ -0,0 +1,105 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RateLimitPolicySpec defines the limits of each caller of the ModelRoutes selected by a RateLimitPolicy.
+type RateLimitPolicySpec struct {
+	// ModelRouteSelector selects the ModelRoutes of the namespace of the policy whose callers are limited.
+	// An empty selector selects all the ModelRoutes of the namespace.
+	// +kubebuilder:validation:Required
+	ModelRouteSelector metav1.LabelSelector `json:"modelRouteSelector"`
+	// Key identifies the callers, each caller gets its own limits.
+	// +kubebuilder:validation:Required
+	Key RateLimitKey `json:"key"`
+	// RequestsPerUnit is the maximum number of requests of a caller per unit of time.
+	// If this field is not set, there is no limit on requests.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	RequestsPerUnit *uint32 `json:"requestsPerUnit,omitempty"`
+	// InputTokensPerUnit is the maximum number of input tokens of a caller per unit of time.
+	// If this field is not set, there is no limit on input tokens.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	InputTokensPerUnit *uint32 `json:"inputTokensPerUnit,omitempty"`
+	// OutputTokensPerUnit is the maximum number of output tokens of a caller per unit of time.
+	// If this field is not set, there is no limit on output tokens.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	OutputTokensPerUnit *uint32 `json:"outputTokensPerUnit,omitempty"`
+	// CostPerUnit is the maximum cost of the tokens of a caller per unit of time, e.g. 5 for $5 per hour, priced by
+	// the Pricing of the ModelServers serving its requests. The cost of a request is charged once its usage is known,
+	// the requests of a caller are rejected while the cost of its previous requests exceeds the limit.
+	// If this field is not set, there is no limit on cost.
+	// +optional
+	CostPerUnit *resource.Quantity `json:"costPerUnit,omitempty"`
+	// Unit is the time unit for the limits.
+	// +kubebuilder:default=minute
+	// +kubebuilder:validation:Enum=second;minute;hour;day;month
+	Unit RateLimitUnit `json:"unit,omitempty"`
+}
+
+// RateLimitKey identifies the callers limited by a RateLimitPolicy. The requests without the key share the limits
+// of a single anonymous caller.
+// +kubebuilder:validation:XValidation:rule="self.type != 'Header' || has(self.header)", message="header is required for the Header type"
+type RateLimitKey struct {
+	// Type is where the key is taken from:
+	// Consumer is the authenticated caller, the subject of its JWT, API key or client certificate.
+	// APIKey is the API key sent in the X-API-Key header, or the bearer token of the Authorization header.
+	// Header is the value of the Header header.
+	// +kubebuilder:validation:Required
+	Type RateLimitKeyType `json:"type"`
+	// Header is the name of the header holding the key of the Header type.
+	// +optional
+	// +kubebuilder:validation:MaxLength=256
+	Header string `json:"header,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Consumer;APIKey;Header
+type RateLimitKeyType string
+
+const (
+	RateLimitKeyConsumer RateLimitKeyType = "Consumer"
+	RateLimitKeyAPIKey   RateLimitKeyType = "APIKey"
+	RateLimitKeyHeader   RateLimitKeyType = "Header"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +genclient
+//
+// RateLimitPolicy gives each caller of the ModelRoutes it selects its own rate limits, so that the consumers of a
+// model get independent budgets.
+type RateLimitPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RateLimitPolicySpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// RateLimitPolicyList contains a list of RateLimitPolicy.
+type RateLimitPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RateLimitPolicy `json:"items"`
+}
@@ -29,6 +29,10 @@ const ModelServerKind = "ModelServer"
 
 const ModelRouteKind = "ModelRoute"
 
+const RateLimitPolicyKind = "RateLimitPolicy"
+
+const TokenQuotaKind = "TokenQuota"
+
 // GroupVersion specifies the group and the version used to register the objects.
 var GroupVersion = v1.GroupVersion{Group: GroupName, Version: "v1alpha1"}
 
@@ -64,6 +68,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&ModelRouteList{},
 		&ModelServer{},
 		&ModelServerList{},
+		&RateLimitPolicy{},
+		&RateLimitPolicyList{},
+		&TokenQuota{},
+		&TokenQuotaList{},
 	)
 	// AddToGroupVersion allows the serialization of client types like ListOptions.
 	v1.AddToGroupVersion(scheme, SchemeGroupVersion)
@@ -0,0 +1,87 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TokenQuotaSpec defines the tokens the requests to the ModelRoutes selected by a TokenQuota may consume per period.
+type TokenQuotaSpec struct {
+	// ModelRouteSelector selects the ModelRoutes of the namespace of the quota whose requests consume it.
+	// An empty selector selects all the ModelRoutes of the namespace.
+	// +kubebuilder:validation:Required
+	ModelRouteSelector metav1.LabelSelector `json:"modelRouteSelector"`
+	// Consumers are the subjects of the authenticated callers sharing the quota, e.g. the members of a team: the
+	// subjects of their JWT, API key or client certificate. All the callers of the selected ModelRoutes share the quota
+	// if it is empty.
+	// +optional
+	// +listType=set
+	Consumers []string `json:"consumers,omitempty"`
+	// Tokens is the maximum number of input and output tokens consumed per period.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	Tokens int64 `json:"tokens"`
+	// Period is the period the tokens are counted over. The periods start at midnight UTC, on the first day of the
+	// month for the month period.
+	// +kubebuilder:default=month
+	// +kubebuilder:validation:Enum=day;month
+	Period RateLimitUnit `json:"period,omitempty"`
+}
+
+// TokenQuotaStatus reports the tokens consumed in the current period, by all the router replicas.
+type TokenQuotaStatus struct {
+	// PeriodStart is the start of the period the tokens are counted over.
+	// +optional
+	PeriodStart *metav1.Time `json:"periodStart,omitempty"`
+	// ConsumedTokens is the number of tokens consumed since the start of the period.
+	// +optional
+	ConsumedTokens int64 `json:"consumedTokens,omitempty"`
+	// RemainingTokens is the number of tokens remaining until the end of the period.
+	// +optional
+	RemainingTokens int64 `json:"remainingTokens,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Tokens",type=integer,JSONPath=`.spec.tokens`
+// +kubebuilder:printcolumn:name="Period",type=string,JSONPath=`.spec.period`
+// +kubebuilder:printcolumn:name="Consumed",type=integer,JSONPath=`.status.consumedTokens`
+// +kubebuilder:printcolumn:name="Remaining",type=integer,JSONPath=`.status.remainingTokens`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +genclient
+//
+// TokenQuota is a long-horizon budget of tokens, e.g. 10M tokens per month for a team, shared by the callers of the
+// ModelRoutes it selects. The tokens consumed are persisted in its status, so that they survive the restarts of the
+// router.
+type TokenQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TokenQuotaSpec   `json:"spec"`
+	Status TokenQuotaStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TokenQuotaList contains a list of TokenQuota.
+type TokenQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TokenQuota `json:"items"`
+}
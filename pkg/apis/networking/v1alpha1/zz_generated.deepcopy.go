@@ -26,123 +26,1116 @@ import (
 	"sigs.k8s.io/gateway-api/apis/v1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackendTLS) DeepCopyInto(out *BackendTLS) {
+	*out = *in
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendTLS.
+func (in *BackendTLS) DeepCopy() *BackendTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(BackendTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BodyFieldMatch) DeepCopyInto(out *BodyFieldMatch) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(StringMatch)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Present != nil {
+		in, out := &in.Present, &out.Present
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BodyFieldMatch.
+func (in *BodyFieldMatch) DeepCopy() *BodyFieldMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(BodyFieldMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BodyMatch) DeepCopyInto(out *BodyMatch) {
 	*out = *in
-	if in.Model != nil {
-		in, out := &in.Model, &out.Model
-		*out = new(string)
+	if in.Model != nil {
+		in, out := &in.Model, &out.Model
+		*out = new(string)
+		**out = **in
+	}
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]BodyFieldMatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BodyMatch.
+func (in *BodyMatch) DeepCopy() *BodyMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(BodyMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BusinessHours) DeepCopyInto(out *BusinessHours) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BusinessHours.
+func (in *BusinessHours) DeepCopy() *BusinessHours {
+	if in == nil {
+		return nil
+	}
+	out := new(BusinessHours)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Concurrency) DeepCopyInto(out *Concurrency) {
+	*out = *in
+	if in.MaxConcurrentRequests != nil {
+		in, out := &in.MaxConcurrentRequests, &out.MaxConcurrentRequests
+		*out = new(int32)
+		**out = **in
+	}
+	if in.QueueTimeout != nil {
+		in, out := &in.QueueTimeout, &out.QueueTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Concurrency.
+func (in *Concurrency) DeepCopy() *Concurrency {
+	if in == nil {
+		return nil
+	}
+	out := new(Concurrency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsulDiscovery) DeepCopyInto(out *ConsulDiscovery) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsulDiscovery.
+func (in *ConsulDiscovery) DeepCopy() *ConsulDiscovery {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsulDiscovery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSDiscovery) DeepCopyInto(out *DNSDiscovery) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSDiscovery.
+func (in *DNSDiscovery) DeepCopy() *DNSDiscovery {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSDiscovery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultParameters) DeepCopyInto(out *DefaultParameters) {
+	*out = *in
+	if in.MaxTokens != nil {
+		in, out := &in.MaxTokens, &out.MaxTokens
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Temperature != nil {
+		in, out := &in.Temperature, &out.Temperature
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultParameters.
+func (in *DefaultParameters) DeepCopy() *DefaultParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Discovery) DeepCopyInto(out *Discovery) {
+	*out = *in
+	if in.EndpointSlice != nil {
+		in, out := &in.EndpointSlice, &out.EndpointSlice
+		*out = new(EndpointSliceDiscovery)
+		**out = **in
+	}
+	if in.Static != nil {
+		in, out := &in.Static, &out.Static
+		*out = new(StaticDiscovery)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(DNSDiscovery)
+		**out = **in
+	}
+	if in.Consul != nil {
+		in, out := &in.Consul, &out.Consul
+		*out = new(ConsulDiscovery)
+		**out = **in
+	}
+	if in.RefreshInterval != nil {
+		in, out := &in.RefreshInterval, &out.RefreshInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(HealthCheck)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Discovery.
+func (in *Discovery) DeepCopy() *Discovery {
+	if in == nil {
+		return nil
+	}
+	out := new(Discovery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointSliceDiscovery) DeepCopyInto(out *EndpointSliceDiscovery) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointSliceDiscovery.
+func (in *EndpointSliceDiscovery) DeepCopy() *EndpointSliceDiscovery {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointSliceDiscovery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Evaluation) DeepCopyInto(out *Evaluation) {
+	*out = *in
+	if in.Percentage != nil {
+		in, out := &in.Percentage, &out.Percentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Evaluation.
+func (in *Evaluation) DeepCopy() *Evaluation {
+	if in == nil {
+		return nil
+	}
+	out := new(Evaluation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Fallback) DeepCopyInto(out *Fallback) {
+	*out = *in
+	if in.ModelServerNames != nil {
+		in, out := &in.ModelServerNames, &out.ModelServerNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StatusCodes != nil {
+		in, out := &in.StatusCodes, &out.StatusCodes
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxDepth != nil {
+		in, out := &in.MaxDepth, &out.MaxDepth
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Fallback.
+func (in *Fallback) DeepCopy() *Fallback {
+	if in == nil {
+		return nil
+	}
+	out := new(Fallback)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlobalRateLimit) DeepCopyInto(out *GlobalRateLimit) {
+	*out = *in
+	if in.Redis != nil {
+		in, out := &in.Redis, &out.Redis
+		*out = new(RedisConfig)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlobalRateLimit.
+func (in *GlobalRateLimit) DeepCopy() *GlobalRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(GlobalRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HeaderFilter) DeepCopyInto(out *HeaderFilter) {
+	*out = *in
+	if in.Allow != nil {
+		in, out := &in.Allow, &out.Allow
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Deny != nil {
+		in, out := &in.Deny, &out.Deny
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeaderFilter.
+func (in *HeaderFilter) DeepCopy() *HeaderFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(HeaderFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HeaderPolicy) DeepCopyInto(out *HeaderPolicy) {
+	*out = *in
+	if in.Request != nil {
+		in, out := &in.Request, &out.Request
+		*out = new(HeaderFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Response != nil {
+		in, out := &in.Response, &out.Response
+		*out = new(HeaderFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RequestHeaderModifier != nil {
+		in, out := &in.RequestHeaderModifier, &out.RequestHeaderModifier
+		*out = new(v1.HTTPHeaderFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResponseHeaderModifier != nil {
+		in, out := &in.ResponseHeaderModifier, &out.ResponseHeaderModifier
+		*out = new(v1.HTTPHeaderFilter)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeaderPolicy.
+func (in *HeaderPolicy) DeepCopy() *HeaderPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(HeaderPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthCheck) DeepCopyInto(out *HealthCheck) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.UnhealthyThreshold != nil {
+		in, out := &in.UnhealthyThreshold, &out.UnhealthyThreshold
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheck.
+func (in *HealthCheck) DeepCopy() *HealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Hedging) DeepCopyInto(out *Hedging) {
+	*out = *in
+	out.Delay = in.Delay
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Hedging.
+func (in *Hedging) DeepCopy() *Hedging {
+	if in == nil {
+		return nil
+	}
+	out := new(Hedging)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InteractiveReservation) DeepCopyInto(out *InteractiveReservation) {
+	*out = *in
+	in.BusinessHours.DeepCopyInto(&out.BusinessHours)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InteractiveReservation.
+func (in *InteractiveReservation) DeepCopy() *InteractiveReservation {
+	if in == nil {
+		return nil
+	}
+	out := new(InteractiveReservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVConnectorSpec) DeepCopyInto(out *KVConnectorSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVConnectorSpec.
+func (in *KVConnectorSpec) DeepCopy() *KVConnectorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KVConnectorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LatencyObjective) DeepCopyInto(out *LatencyObjective) {
+	*out = *in
+	out.Threshold = in.Threshold
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LatencyObjective.
+func (in *LatencyObjective) DeepCopy() *LatencyObjective {
+	if in == nil {
+		return nil
+	}
+	out := new(LatencyObjective)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Mirror) DeepCopyInto(out *Mirror) {
+	*out = *in
+	if in.Percentage != nil {
+		in, out := &in.Percentage, &out.Percentage
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Mirror.
+func (in *Mirror) DeepCopy() *Mirror {
+	if in == nil {
+		return nil
+	}
+	out := new(Mirror)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelMatch) DeepCopyInto(out *ModelMatch) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]*StringMatch, len(*in))
+		for key, val := range *in {
+			var outVal *StringMatch
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = new(StringMatch)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Uri != nil {
+		in, out := &in.Uri, &out.Uri
+		*out = new(StringMatch)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Body != nil {
+		in, out := &in.Body, &out.Body
+		*out = new(BodyMatch)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Expression != nil {
+		in, out := &in.Expression, &out.Expression
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelMatch.
+func (in *ModelMatch) DeepCopy() *ModelMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelRoute) DeepCopyInto(out *ModelRoute) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelRoute.
+func (in *ModelRoute) DeepCopy() *ModelRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ModelRoute) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelRouteList) DeepCopyInto(out *ModelRouteList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ModelRoute, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelRouteList.
+func (in *ModelRouteList) DeepCopy() *ModelRouteList {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelRouteList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ModelRouteList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelRouteSpec) DeepCopyInto(out *ModelRouteSpec) {
+	*out = *in
+	if in.LoraAdapters != nil {
+		in, out := &in.LoraAdapters, &out.LoraAdapters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Aliases != nil {
+		in, out := &in.Aliases, &out.Aliases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ModelNameMatch != nil {
+		in, out := &in.ModelNameMatch, &out.ModelNameMatch
+		*out = new(StringMatch)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ParentRefs != nil {
+		in, out := &in.ParentRefs, &out.ParentRefs
+		*out = make([]v1.ParentReference, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]*Rule, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Rule)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(RateLimit)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DefaultParameters != nil {
+		in, out := &in.DefaultParameters, &out.DefaultParameters
+		*out = new(DefaultParameters)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SLO != nil {
+		in, out := &in.SLO, &out.SLO
+		*out = new(SLO)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HeaderPolicy != nil {
+		in, out := &in.HeaderPolicy, &out.HeaderPolicy
+		*out = new(HeaderPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Fallback != nil {
+		in, out := &in.Fallback, &out.Fallback
+		*out = new(Fallback)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Mirror != nil {
+		in, out := &in.Mirror, &out.Mirror
+		*out = new(Mirror)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Evaluation != nil {
+		in, out := &in.Evaluation, &out.Evaluation
+		*out = new(Evaluation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(Rollout)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(Priority)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeouts != nil {
+		in, out := &in.Timeouts, &out.Timeouts
+		*out = new(Timeouts)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SessionAffinity != nil {
+		in, out := &in.SessionAffinity, &out.SessionAffinity
+		*out = new(SessionAffinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Observability != nil {
+		in, out := &in.Observability, &out.Observability
+		*out = new(Observability)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hedging != nil {
+		in, out := &in.Hedging, &out.Hedging
+		*out = new(Hedging)
+		**out = **in
+	}
+	if in.Concurrency != nil {
+		in, out := &in.Concurrency, &out.Concurrency
+		*out = new(Concurrency)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StructuredOutput != nil {
+		in, out := &in.StructuredOutput, &out.StructuredOutput
+		*out = new(StructuredOutput)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelRouteSpec.
+func (in *ModelRouteSpec) DeepCopy() *ModelRouteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelRouteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelRouteStatus) DeepCopyInto(out *ModelRouteStatus) {
+	*out = *in
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Parents != nil {
+		in, out := &in.Parents, &out.Parents
+		*out = make([]v1.RouteParentStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelRouteStatus.
+func (in *ModelRouteStatus) DeepCopy() *ModelRouteStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelRouteStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelServer) DeepCopyInto(out *ModelServer) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelServer.
+func (in *ModelServer) DeepCopy() *ModelServer {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelServer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ModelServer) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelServerList) DeepCopyInto(out *ModelServerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ModelServer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelServerList.
+func (in *ModelServerList) DeepCopy() *ModelServerList {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelServerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ModelServerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelServerSpec) DeepCopyInto(out *ModelServerSpec) {
+	*out = *in
+	if in.Model != nil {
+		in, out := &in.Model, &out.Model
+		*out = new(string)
+		**out = **in
+	}
+	if in.WorkloadSelector != nil {
+		in, out := &in.WorkloadSelector, &out.WorkloadSelector
+		*out = new(WorkloadSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Discovery != nil {
+		in, out := &in.Discovery, &out.Discovery
+		*out = new(Discovery)
+		(*in).DeepCopyInto(*out)
+	}
+	in.WorkloadPort.DeepCopyInto(&out.WorkloadPort)
+	if in.TrafficPolicy != nil {
+		in, out := &in.TrafficPolicy, &out.TrafficPolicy
+		*out = new(TrafficPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KVConnector != nil {
+		in, out := &in.KVConnector, &out.KVConnector
+		*out = new(KVConnectorSpec)
+		**out = **in
+	}
+	if in.MaxContextLength != nil {
+		in, out := &in.MaxContextLength, &out.MaxContextLength
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxConcurrentRequestsPerPod != nil {
+		in, out := &in.MaxConcurrentRequestsPerPod, &out.MaxConcurrentRequestsPerPod
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SlowStart != nil {
+		in, out := &in.SlowStart, &out.SlowStart
+		*out = new(SlowStart)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PrefillCoalescing != nil {
+		in, out := &in.PrefillCoalescing, &out.PrefillCoalescing
+		*out = new(PrefillCoalescing)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WarmUp != nil {
+		in, out := &in.WarmUp, &out.WarmUp
+		*out = new(WarmUp)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Pricing != nil {
+		in, out := &in.Pricing, &out.Pricing
+		*out = new(Pricing)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Capabilities != nil {
+		in, out := &in.Capabilities, &out.Capabilities
+		*out = make([]Capability, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelServerSpec.
+func (in *ModelServerSpec) DeepCopy() *ModelServerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelServerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelServerStatus) DeepCopyInto(out *ModelServerStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelServerStatus.
+func (in *ModelServerStatus) DeepCopy() *ModelServerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelServerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Observability) DeepCopyInto(out *Observability) {
+	*out = *in
+	if in.AccessLogPercentage != nil {
+		in, out := &in.AccessLogPercentage, &out.AccessLogPercentage
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Observability.
+func (in *Observability) DeepCopy() *Observability {
+	if in == nil {
+		return nil
+	}
+	out := new(Observability)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PDGroup) DeepCopyInto(out *PDGroup) {
+	*out = *in
+	if in.PrefillLabels != nil {
+		in, out := &in.PrefillLabels, &out.PrefillLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.DecodeLabels != nil {
+		in, out := &in.DecodeLabels, &out.DecodeLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PDGroup.
+func (in *PDGroup) DeepCopy() *PDGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(PDGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrefillCoalescing) DeepCopyInto(out *PrefillCoalescing) {
+	*out = *in
+	out.MaxWait = in.MaxWait
+	if in.MinPrefixLength != nil {
+		in, out := &in.MinPrefixLength, &out.MinPrefixLength
+		*out = new(int32)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BodyMatch.
-func (in *BodyMatch) DeepCopy() *BodyMatch {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrefillCoalescing.
+func (in *PrefillCoalescing) DeepCopy() *PrefillCoalescing {
 	if in == nil {
 		return nil
 	}
-	out := new(BodyMatch)
+	out := new(PrefillCoalescing)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GlobalRateLimit) DeepCopyInto(out *GlobalRateLimit) {
+func (in *Priority) DeepCopyInto(out *Priority) {
 	*out = *in
-	if in.Redis != nil {
-		in, out := &in.Redis, &out.Redis
-		*out = new(RedisConfig)
+	if in.MaxConcurrentRequests != nil {
+		in, out := &in.MaxConcurrentRequests, &out.MaxConcurrentRequests
+		*out = new(int32)
+		**out = **in
+	}
+	if in.InteractiveReservation != nil {
+		in, out := &in.InteractiveReservation, &out.InteractiveReservation
+		*out = new(InteractiveReservation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Level != nil {
+		in, out := &in.Level, &out.Level
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SaturationPercent != nil {
+		in, out := &in.SaturationPercent, &out.SaturationPercent
+		*out = new(int32)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlobalRateLimit.
-func (in *GlobalRateLimit) DeepCopy() *GlobalRateLimit {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Priority.
+func (in *Priority) DeepCopy() *Priority {
 	if in == nil {
 		return nil
 	}
-	out := new(GlobalRateLimit)
+	out := new(Priority)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KVConnectorSpec) DeepCopyInto(out *KVConnectorSpec) {
+func (in *Pricing) DeepCopyInto(out *Pricing) {
 	*out = *in
+	if in.InputPer1KTokens != nil {
+		in, out := &in.InputPer1KTokens, &out.InputPer1KTokens
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.OutputPer1KTokens != nil {
+		in, out := &in.OutputPer1KTokens, &out.OutputPer1KTokens
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVConnectorSpec.
-func (in *KVConnectorSpec) DeepCopy() *KVConnectorSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Pricing.
+func (in *Pricing) DeepCopy() *Pricing {
 	if in == nil {
 		return nil
 	}
-	out := new(KVConnectorSpec)
+	out := new(Pricing)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ModelMatch) DeepCopyInto(out *ModelMatch) {
+func (in *RateLimit) DeepCopyInto(out *RateLimit) {
 	*out = *in
-	if in.Headers != nil {
-		in, out := &in.Headers, &out.Headers
-		*out = make(map[string]*StringMatch, len(*in))
-		for key, val := range *in {
-			var outVal *StringMatch
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				inVal := (*in)[key]
-				in, out := &inVal, &outVal
-				*out = new(StringMatch)
-				(*in).DeepCopyInto(*out)
-			}
-			(*out)[key] = outVal
-		}
+	if in.InputTokensPerUnit != nil {
+		in, out := &in.InputTokensPerUnit, &out.InputTokensPerUnit
+		*out = new(uint32)
+		**out = **in
 	}
-	if in.Uri != nil {
-		in, out := &in.Uri, &out.Uri
-		*out = new(StringMatch)
-		(*in).DeepCopyInto(*out)
+	if in.OutputTokensPerUnit != nil {
+		in, out := &in.OutputTokensPerUnit, &out.OutputTokensPerUnit
+		*out = new(uint32)
+		**out = **in
 	}
-	if in.Body != nil {
-		in, out := &in.Body, &out.Body
-		*out = new(BodyMatch)
+	if in.ImagesPerUnit != nil {
+		in, out := &in.ImagesPerUnit, &out.ImagesPerUnit
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.MegapixelsPerUnit != nil {
+		in, out := &in.MegapixelsPerUnit, &out.MegapixelsPerUnit
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.ItemsPerUnit != nil {
+		in, out := &in.ItemsPerUnit, &out.ItemsPerUnit
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.BytesPerUnit != nil {
+		in, out := &in.BytesPerUnit, &out.BytesPerUnit
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.Burst != nil {
+		in, out := &in.Burst, &out.Burst
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.Global != nil {
+		in, out := &in.Global, &out.Global
+		*out = new(GlobalRateLimit)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DegradeTo != nil {
+		in, out := &in.DegradeTo, &out.DegradeTo
+		*out = new(string)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelMatch.
-func (in *ModelMatch) DeepCopy() *ModelMatch {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimit.
+func (in *RateLimit) DeepCopy() *RateLimit {
 	if in == nil {
 		return nil
 	}
-	out := new(ModelMatch)
+	out := new(RateLimit)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ModelRoute) DeepCopyInto(out *ModelRoute) {
+func (in *RateLimitKey) DeepCopyInto(out *RateLimitKey) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimitKey.
+func (in *RateLimitKey) DeepCopy() *RateLimitKey {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimitKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateLimitPolicy) DeepCopyInto(out *RateLimitPolicy) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelRoute.
-func (in *ModelRoute) DeepCopy() *ModelRoute {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimitPolicy.
+func (in *RateLimitPolicy) DeepCopy() *RateLimitPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(ModelRoute)
+	out := new(RateLimitPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ModelRoute) DeepCopyObject() runtime.Object {
+func (in *RateLimitPolicy) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -150,31 +1143,31 @@ func (in *ModelRoute) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ModelRouteList) DeepCopyInto(out *ModelRouteList) {
+func (in *RateLimitPolicyList) DeepCopyInto(out *RateLimitPolicyList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ModelRoute, len(*in))
+		*out = make([]RateLimitPolicy, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelRouteList.
-func (in *ModelRouteList) DeepCopy() *ModelRouteList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimitPolicyList.
+func (in *RateLimitPolicyList) DeepCopy() *RateLimitPolicyList {
 	if in == nil {
 		return nil
 	}
-	out := new(ModelRouteList)
+	out := new(RateLimitPolicyList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ModelRouteList) DeepCopyObject() runtime.Object {
+func (in *RateLimitPolicyList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -182,344 +1175,476 @@ func (in *ModelRouteList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ModelRouteSpec) DeepCopyInto(out *ModelRouteSpec) {
+func (in *RateLimitPolicySpec) DeepCopyInto(out *RateLimitPolicySpec) {
 	*out = *in
-	if in.LoraAdapters != nil {
-		in, out := &in.LoraAdapters, &out.LoraAdapters
-		*out = make([]string, len(*in))
+	in.ModelRouteSelector.DeepCopyInto(&out.ModelRouteSelector)
+	out.Key = in.Key
+	if in.RequestsPerUnit != nil {
+		in, out := &in.RequestsPerUnit, &out.RequestsPerUnit
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.InputTokensPerUnit != nil {
+		in, out := &in.InputTokensPerUnit, &out.InputTokensPerUnit
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.OutputTokensPerUnit != nil {
+		in, out := &in.OutputTokensPerUnit, &out.OutputTokensPerUnit
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.CostPerUnit != nil {
+		in, out := &in.CostPerUnit, &out.CostPerUnit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimitPolicySpec.
+func (in *RateLimitPolicySpec) DeepCopy() *RateLimitPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimitPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisConfig) DeepCopyInto(out *RedisConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisConfig.
+func (in *RedisConfig) DeepCopy() *RedisConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Retry) DeepCopyInto(out *Retry) {
+	*out = *in
+	if in.RetryInterval != nil {
+		in, out := &in.RetryInterval, &out.RetryInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Budget != nil {
+		in, out := &in.Budget, &out.Budget
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.StatusCodes != nil {
+		in, out := &in.StatusCodes, &out.StatusCodes
+		*out = make([]int32, len(*in))
 		copy(*out, *in)
 	}
-	if in.ParentRefs != nil {
-		in, out := &in.ParentRefs, &out.ParentRefs
-		*out = make([]v1.ParentReference, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Retry.
+func (in *Retry) DeepCopy() *Retry {
+	if in == nil {
+		return nil
 	}
-	if in.Rules != nil {
-		in, out := &in.Rules, &out.Rules
-		*out = make([]*Rule, len(*in))
+	out := new(Retry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Rollout) DeepCopyInto(out *Rollout) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxErrorRate != nil {
+		in, out := &in.MaxErrorRate, &out.MaxErrorRate
+		*out = new(string)
+		**out = **in
+	}
+	if in.MaxLatency != nil {
+		in, out := &in.MaxLatency, &out.MaxLatency
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MinEvaluationScore != nil {
+		in, out := &in.MinEvaluationScore, &out.MinEvaluationScore
+		*out = new(string)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Rollout.
+func (in *Rollout) DeepCopy() *Rollout {
+	if in == nil {
+		return nil
+	}
+	out := new(Rollout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStatus) DeepCopyInto(out *RolloutStatus) {
+	*out = *in
+	in.LastAnalysisTime.DeepCopyInto(&out.LastAnalysisTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStatus.
+func (in *RolloutStatus) DeepCopy() *RolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Rule) DeepCopyInto(out *Rule) {
+	*out = *in
+	if in.ModelMatch != nil {
+		in, out := &in.ModelMatch, &out.ModelMatch
+		*out = new(ModelMatch)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TargetModels != nil {
+		in, out := &in.TargetModels, &out.TargetModels
+		*out = make([]*TargetModel, len(*in))
 		for i := range *in {
 			if (*in)[i] != nil {
 				in, out := &(*in)[i], &(*out)[i]
-				*out = new(Rule)
+				*out = new(TargetModel)
 				(*in).DeepCopyInto(*out)
 			}
 		}
 	}
-	if in.RateLimit != nil {
-		in, out := &in.RateLimit, &out.RateLimit
-		*out = new(RateLimit)
-		(*in).DeepCopyInto(*out)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Rule.
+func (in *Rule) DeepCopy() *Rule {
+	if in == nil {
+		return nil
+	}
+	out := new(Rule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SLO) DeepCopyInto(out *SLO) {
+	*out = *in
+	if in.Availability != nil {
+		in, out := &in.Availability, &out.Availability
+		*out = new(string)
+		**out = **in
+	}
+	if in.Latency != nil {
+		in, out := &in.Latency, &out.Latency
+		*out = new(LatencyObjective)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelRouteSpec.
-func (in *ModelRouteSpec) DeepCopy() *ModelRouteSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SLO.
+func (in *SLO) DeepCopy() *SLO {
 	if in == nil {
 		return nil
 	}
-	out := new(ModelRouteSpec)
+	out := new(SLO)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ModelRouteStatus) DeepCopyInto(out *ModelRouteStatus) {
+func (in *SecretKeyReference) DeepCopyInto(out *SecretKeyReference) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelRouteStatus.
-func (in *ModelRouteStatus) DeepCopy() *ModelRouteStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyReference.
+func (in *SecretKeyReference) DeepCopy() *SecretKeyReference {
 	if in == nil {
 		return nil
 	}
-	out := new(ModelRouteStatus)
+	out := new(SecretKeyReference)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ModelServer) DeepCopyInto(out *ModelServer) {
+func (in *SessionAffinity) DeepCopyInto(out *SessionAffinity) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelServer.
-func (in *ModelServer) DeepCopy() *ModelServer {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SessionAffinity.
+func (in *SessionAffinity) DeepCopy() *SessionAffinity {
 	if in == nil {
 		return nil
 	}
-	out := new(ModelServer)
+	out := new(SessionAffinity)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ModelServer) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ModelServerList) DeepCopyInto(out *ModelServerList) {
+func (in *SlowStart) DeepCopyInto(out *SlowStart) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]ModelServer, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	out.Window = in.Window
+	if in.MinPercentage != nil {
+		in, out := &in.MinPercentage, &out.MinPercentage
+		*out = new(int32)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelServerList.
-func (in *ModelServerList) DeepCopy() *ModelServerList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlowStart.
+func (in *SlowStart) DeepCopy() *SlowStart {
 	if in == nil {
 		return nil
 	}
-	out := new(ModelServerList)
+	out := new(SlowStart)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ModelServerList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticDiscovery) DeepCopyInto(out *StaticDiscovery) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	return nil
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticDiscovery.
+func (in *StaticDiscovery) DeepCopy() *StaticDiscovery {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticDiscovery)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ModelServerSpec) DeepCopyInto(out *ModelServerSpec) {
+func (in *StringMatch) DeepCopyInto(out *StringMatch) {
 	*out = *in
-	if in.Model != nil {
-		in, out := &in.Model, &out.Model
+	if in.Exact != nil {
+		in, out := &in.Exact, &out.Exact
 		*out = new(string)
 		**out = **in
 	}
-	if in.WorkloadSelector != nil {
-		in, out := &in.WorkloadSelector, &out.WorkloadSelector
-		*out = new(WorkloadSelector)
-		(*in).DeepCopyInto(*out)
-	}
-	out.WorkloadPort = in.WorkloadPort
-	if in.TrafficPolicy != nil {
-		in, out := &in.TrafficPolicy, &out.TrafficPolicy
-		*out = new(TrafficPolicy)
-		(*in).DeepCopyInto(*out)
+	if in.Prefix != nil {
+		in, out := &in.Prefix, &out.Prefix
+		*out = new(string)
+		**out = **in
 	}
-	if in.KVConnector != nil {
-		in, out := &in.KVConnector, &out.KVConnector
-		*out = new(KVConnectorSpec)
+	if in.Regex != nil {
+		in, out := &in.Regex, &out.Regex
+		*out = new(string)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelServerSpec.
-func (in *ModelServerSpec) DeepCopy() *ModelServerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StringMatch.
+func (in *StringMatch) DeepCopy() *StringMatch {
 	if in == nil {
 		return nil
 	}
-	out := new(ModelServerSpec)
+	out := new(StringMatch)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ModelServerStatus) DeepCopyInto(out *ModelServerStatus) {
+func (in *StructuredOutput) DeepCopyInto(out *StructuredOutput) {
 	*out = *in
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelServerStatus.
-func (in *ModelServerStatus) DeepCopy() *ModelServerStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StructuredOutput.
+func (in *StructuredOutput) DeepCopy() *StructuredOutput {
 	if in == nil {
 		return nil
 	}
-	out := new(ModelServerStatus)
+	out := new(StructuredOutput)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PDGroup) DeepCopyInto(out *PDGroup) {
+func (in *TargetModel) DeepCopyInto(out *TargetModel) {
 	*out = *in
-	if in.PrefillLabels != nil {
-		in, out := &in.PrefillLabels, &out.PrefillLabels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.DecodeLabels != nil {
-		in, out := &in.DecodeLabels, &out.DecodeLabels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(uint32)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PDGroup.
-func (in *PDGroup) DeepCopy() *PDGroup {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetModel.
+func (in *TargetModel) DeepCopy() *TargetModel {
 	if in == nil {
 		return nil
 	}
-	out := new(PDGroup)
+	out := new(TargetModel)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RateLimit) DeepCopyInto(out *RateLimit) {
+func (in *Timeouts) DeepCopyInto(out *Timeouts) {
 	*out = *in
-	if in.InputTokensPerUnit != nil {
-		in, out := &in.InputTokensPerUnit, &out.InputTokensPerUnit
-		*out = new(uint32)
+	if in.Connect != nil {
+		in, out := &in.Connect, &out.Connect
+		*out = new(metav1.Duration)
 		**out = **in
 	}
-	if in.OutputTokensPerUnit != nil {
-		in, out := &in.OutputTokensPerUnit, &out.OutputTokensPerUnit
-		*out = new(uint32)
+	if in.FirstToken != nil {
+		in, out := &in.FirstToken, &out.FirstToken
+		*out = new(metav1.Duration)
 		**out = **in
 	}
-	if in.Global != nil {
-		in, out := &in.Global, &out.Global
-		*out = new(GlobalRateLimit)
-		(*in).DeepCopyInto(*out)
+	if in.Request != nil {
+		in, out := &in.Request, &out.Request
+		*out = new(metav1.Duration)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimit.
-func (in *RateLimit) DeepCopy() *RateLimit {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Timeouts.
+func (in *Timeouts) DeepCopy() *Timeouts {
 	if in == nil {
 		return nil
 	}
-	out := new(RateLimit)
+	out := new(Timeouts)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RedisConfig) DeepCopyInto(out *RedisConfig) {
+func (in *TokenQuota) DeepCopyInto(out *TokenQuota) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisConfig.
-func (in *RedisConfig) DeepCopy() *RedisConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TokenQuota.
+func (in *TokenQuota) DeepCopy() *TokenQuota {
 	if in == nil {
 		return nil
 	}
-	out := new(RedisConfig)
+	out := new(TokenQuota)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Retry) DeepCopyInto(out *Retry) {
-	*out = *in
-	if in.RetryInterval != nil {
-		in, out := &in.RetryInterval, &out.RetryInterval
-		*out = new(metav1.Duration)
-		**out = **in
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Retry.
-func (in *Retry) DeepCopy() *Retry {
-	if in == nil {
-		return nil
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TokenQuota) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
-	out := new(Retry)
-	in.DeepCopyInto(out)
-	return out
+	return nil
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Rule) DeepCopyInto(out *Rule) {
+func (in *TokenQuotaList) DeepCopyInto(out *TokenQuotaList) {
 	*out = *in
-	if in.ModelMatch != nil {
-		in, out := &in.ModelMatch, &out.ModelMatch
-		*out = new(ModelMatch)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.TargetModels != nil {
-		in, out := &in.TargetModels, &out.TargetModels
-		*out = make([]*TargetModel, len(*in))
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TokenQuota, len(*in))
 		for i := range *in {
-			if (*in)[i] != nil {
-				in, out := &(*in)[i], &(*out)[i]
-				*out = new(TargetModel)
-				(*in).DeepCopyInto(*out)
-			}
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Rule.
-func (in *Rule) DeepCopy() *Rule {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TokenQuotaList.
+func (in *TokenQuotaList) DeepCopy() *TokenQuotaList {
 	if in == nil {
 		return nil
 	}
-	out := new(Rule)
+	out := new(TokenQuotaList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TokenQuotaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StringMatch) DeepCopyInto(out *StringMatch) {
+func (in *TokenQuotaSpec) DeepCopyInto(out *TokenQuotaSpec) {
 	*out = *in
-	if in.Exact != nil {
-		in, out := &in.Exact, &out.Exact
-		*out = new(string)
-		**out = **in
-	}
-	if in.Prefix != nil {
-		in, out := &in.Prefix, &out.Prefix
-		*out = new(string)
-		**out = **in
-	}
-	if in.Regex != nil {
-		in, out := &in.Regex, &out.Regex
-		*out = new(string)
-		**out = **in
+	in.ModelRouteSelector.DeepCopyInto(&out.ModelRouteSelector)
+	if in.Consumers != nil {
+		in, out := &in.Consumers, &out.Consumers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StringMatch.
-func (in *StringMatch) DeepCopy() *StringMatch {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TokenQuotaSpec.
+func (in *TokenQuotaSpec) DeepCopy() *TokenQuotaSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(StringMatch)
+	out := new(TokenQuotaSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TargetModel) DeepCopyInto(out *TargetModel) {
+func (in *TokenQuotaStatus) DeepCopyInto(out *TokenQuotaStatus) {
 	*out = *in
-	if in.Weight != nil {
-		in, out := &in.Weight, &out.Weight
-		*out = new(uint32)
-		**out = **in
+	if in.PeriodStart != nil {
+		in, out := &in.PeriodStart, &out.PeriodStart
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetModel.
-func (in *TargetModel) DeepCopy() *TargetModel {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TokenQuotaStatus.
+func (in *TokenQuotaStatus) DeepCopy() *TokenQuotaStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(TargetModel)
+	out := new(TokenQuotaStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -549,9 +1674,44 @@ func (in *TrafficPolicy) DeepCopy() *TrafficPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WarmUp) DeepCopyInto(out *WarmUp) {
+	*out = *in
+	if in.MaxTokens != nil {
+		in, out := &in.MaxTokens, &out.MaxTokens
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WarmUp.
+func (in *WarmUp) DeepCopy() *WarmUp {
+	if in == nil {
+		return nil
+	}
+	out := new(WarmUp)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadPort) DeepCopyInto(out *WorkloadPort) {
 	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(BackendTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GRPCPort != nil {
+		in, out := &in.GRPCPort, &out.GRPCPort
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadPort.
@@ -574,6 +1734,13 @@ func (in *WorkloadSelector) DeepCopyInto(out *WorkloadSelector) {
 			(*out)[key] = val
 		}
 	}
+	if in.MatchExpressions != nil {
+		in, out := &in.MatchExpressions, &out.MatchExpressions
+		*out = make([]metav1.LabelSelectorRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.PDGroup != nil {
 		in, out := &in.PDGroup, &out.PDGroup
 		*out = new(PDGroup)
@@ -0,0 +1,104 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	networking "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ModelBundleSpec defines the ModelServing, ModelServer and ModelRoute of a model, applied together by the bundle
+// controller. They are named after the ModelBundle and validated by their own schema and webhooks when applied.
+type ModelBundleSpec struct {
+	// ModelServing is the spec of the ModelServing of the model.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Type=object
+	ModelServing ModelServingSpec `json:"modelServing"`
+	// ModelServer is the spec of the ModelServer of the model, its workload selector must select the pods of the
+	// ModelServing.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Type=object
+	ModelServer networking.ModelServerSpec `json:"modelServer"`
+	// ModelRoute is the spec of the ModelRoute of the model.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Type=object
+	ModelRoute networking.ModelRouteSpec `json:"modelRoute"`
+}
+
+// ModelBundleStatus defines the observed state of ModelBundle.
+type ModelBundleStatus struct {
+	// ObservedGeneration is the latest generation of the ModelBundle the bundle controller processed.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// ActiveGeneration is the generation of the ModelBundle whose ModelServer and ModelRoute are applied. The ModelServer
+	// and ModelRoute of the previous generation keep serving the model until the ModelServing of the next one is
+	// available.
+	// +optional
+	ActiveGeneration int64 `json:"activeGeneration,omitempty"`
+	// Conditions represents the latest available observations of the ModelBundle's state.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ModelBundleConditionType is a condition of a ModelBundle.
+type ModelBundleConditionType string
+
+const (
+	// ModelBundleReady is true once the ModelServer and ModelRoute of the current generation of the ModelBundle are
+	// applied, after its ModelServing became available.
+	ModelBundleReady ModelBundleConditionType = "Ready"
+)
+
+// Reasons of the Ready condition of the ModelBundles.
+const (
+	ModelBundleApplied                  = "Applied"
+	ModelBundleModelServingNotAvailable = "ModelServingNotAvailable"
+	ModelBundleApplyFailed              = "ApplyFailed"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Active",type=integer,JSONPath=`.status.activeGeneration`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +genclient
+
+// ModelBundle applies the ModelServing, ModelServer and ModelRoute of a model as one snapshot: on every change, the
+// ModelServing is applied first, and the ModelServer and ModelRoute only once it is available, so that the model is
+// never routed to pods which are not serving it yet.
+type ModelBundle struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ModelBundleSpec   `json:"spec,omitempty"`
+	Status ModelBundleStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ModelBundleList contains a list of ModelBundle.
+type ModelBundleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ModelBundle `json:"items"`
+}
@@ -17,7 +17,9 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -63,6 +65,127 @@ type ModelServingSpec struct {
 	// +kubebuilder:validation:Enum={ServingGroupRecreate,RoleRecreate,None}
 	// +optional
 	RecoveryPolicy RecoveryPolicy `json:"recoveryPolicy,omitempty"`
+
+	// DisruptionBudget makes the controller manage PodDisruptionBudgets limiting the voluntary disruptions
+	// of the pods, such as the node drains of cluster upgrades.
+	// +optional
+	DisruptionBudget *DisruptionBudget `json:"disruptionBudget,omitempty"`
+
+	// Accelerator declares the accelerators of the pods of the roles once, instead of in each role template.
+	// The controller expands it into the resources, node selectors and tolerations of the pods.
+	// +optional
+	Accelerator *AcceleratorRequirements `json:"accelerator,omitempty"`
+
+	// Placement prices the node pools the pods may be scheduled on. The controller projects the hourly cost
+	// of the ModelServing from the pools of the nodes of its pods, and the LowestCost policy makes the pods
+	// prefer the cheaper pools.
+	// +optional
+	Placement *Placement `json:"placement,omitempty"`
+}
+
+// DisruptionBudget defines the PodDisruptionBudgets managed by the controller for a ModelServing.
+// Evicting any pod of a multi-node replica of a role, entry or worker, breaks the whole replica,
+// so the budgets count replicas rather than pods.
+// With the RoleRecreate and None recovery policies, a PodDisruptionBudget is managed for each role,
+// counting the replicas of the role in all the ServingGroups.
+// With the ServingGroupRecreate recovery policy, evicting a pod recreates its whole ServingGroup,
+// so a single PodDisruptionBudget is managed, counting the ServingGroups.
+type DisruptionBudget struct {
+	// MinAvailable is the number of the replicas of each role, or of the ServingGroups with the
+	// ServingGroupRecreate recovery policy, which must stay available during voluntary disruptions.
+	// Value can be an absolute number (ex: 1) or a percentage of the replicas (ex: 50%).
+	// Absolute number is calculated from percentage by rounding up.
+	// By default, a fixed value of 1 is used.
+	// +kubebuilder:validation:XIntOrString
+	// +kubebuilder:default=1
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// MinRoleAvailable overrides MinAvailable for the named roles.
+	// It is ignored with the ServingGroupRecreate recovery policy.
+	// +optional
+	MinRoleAvailable map[string]intstr.IntOrString `json:"minRoleAvailable,omitempty"`
+}
+
+// AcceleratorRequirements defines the accelerators of the pods of a ModelServing.
+// The first container of each entry and worker pod requests Count accelerators of ResourceName,
+// the pods tolerate the NoSchedule taints of ResourceName, and are scheduled on the nodes whose
+// accelerators are of Type and have at least Memory.
+// The resources, node selectors and node affinities set in a role template take precedence.
+type AcceleratorRequirements struct {
+	// ResourceName is the extended resource of the accelerators, such as nvidia.com/gpu or huawei.com/ascend-1980.
+	// +kubebuilder:default="nvidia.com/gpu"
+	// +optional
+	ResourceName corev1.ResourceName `json:"resourceName,omitempty"`
+
+	// Count is the number of accelerators of each pod.
+	// +kubebuilder:validation:Minimum=1
+	Count int32 `json:"count"`
+
+	// Type is the product name of the accelerators, such as NVIDIA-H100-80GB-HBM3.
+	// The pods are scheduled on the nodes whose TypeLabelKey label is Type.
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// TypeLabelKey is the label of the nodes holding the product name of their accelerators.
+	// +kubebuilder:default="nvidia.com/gpu.product"
+	// +optional
+	TypeLabelKey string `json:"typeLabelKey,omitempty"`
+
+	// Memory is the minimum memory of each accelerator, such as 80Gi.
+	// The pods are scheduled on the nodes whose MemoryLabelKey label, in MiB, is at least Memory.
+	// +optional
+	Memory *resource.Quantity `json:"memory,omitempty"`
+
+	// MemoryLabelKey is the label of the nodes holding the memory of their accelerators in MiB.
+	// +kubebuilder:default="nvidia.com/gpu.memory"
+	// +optional
+	MemoryLabelKey string `json:"memoryLabelKey,omitempty"`
+
+	// Roles limits the requirements to the named roles, all the roles if empty.
+	// +optional
+	Roles []string `json:"roles,omitempty"`
+}
+
+// PlacementPolicy is how the pods of a ModelServing are placed on the node pools.
+type PlacementPolicy string
+
+const (
+	// PlacementPolicyNone leaves the choice of the nodes to the scheduler, the pools only price the pods.
+	PlacementPolicyNone PlacementPolicy = "None"
+	// PlacementPolicyLowestCost makes the pods prefer the nodes of the cheaper pools, for the latency-tolerant models.
+	PlacementPolicyLowestCost PlacementPolicy = "LowestCost"
+)
+
+// Placement defines the node pools of different costs the pods of a ModelServing may be scheduled on.
+// The pods are not restricted to the pools, the pods on the nodes of none of them are not priced.
+type Placement struct {
+	// Policy is how the pods are placed on the pools.
+	// With LowestCost, the pods prefer the pools by increasing cost, through preferred node affinity terms.
+	// +kubebuilder:default=None
+	// +kubebuilder:validation:Enum={None,LowestCost}
+	// +optional
+	Policy PlacementPolicy `json:"policy,omitempty"`
+
+	// Pools are the node pools. The pool of a node is the first one whose NodeSelector matches its labels.
+	// +kubebuilder:validation:MinItems=1
+	// +listType=map
+	// +listMapKey=name
+	Pools []NodePool `json:"pools"`
+}
+
+// NodePool is a set of nodes of the same cost, such as the spot GPU nodes of a region.
+type NodePool struct {
+	// Name of the pool.
+	Name string `json:"name"`
+
+	// NodeSelector selects the nodes of the pool by their labels.
+	// +kubebuilder:validation:MinProperties=1
+	NodeSelector map[string]string `json:"nodeSelector"`
+
+	// HourlyCost is the cost of running a pod of the ModelServing for an hour on a node of the pool,
+	// such as the hourly price of its accelerators, in the currency of the cost reports.
+	HourlyCost resource.Quantity `json:"hourlyCost"`
 }
 
 type RecoveryPolicy string
@@ -224,6 +347,11 @@ type ModelServingStatus struct {
 
 	// LabelSelector is a label query over pods that should match the replica count.
 	LabelSelector string `json:"labelSelector,omitempty"`
+
+	// ProjectedHourlyCost is the hourly cost of the scheduled pods of the ModelServing, priced by the
+	// pools of their nodes. It is only set with a placement, once the controller may read the nodes.
+	// +optional
+	ProjectedHourlyCost *resource.Quantity `json:"projectedHourlyCost,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -42,6 +42,7 @@ var (
 	ModelServingKind                = SchemeGroupVersion.WithKind("ModelServing")
 	ModelServingListKind            = SchemeGroupVersion.WithKind("ModelServingList")
 	ModelKind                       = SchemeGroupVersion.WithKind("ModelBooster")
+	ModelBundleKind                 = SchemeGroupVersion.WithKind("ModelBundle")
 	AutoscalingPolicyKind           = SchemeGroupVersion.WithKind("AutoscalingPolicy")
 	AutoscalingPolicyBindingKind    = SchemeGroupVersion.WithKind("AutoscalingPolicyBinding")
 	ModelServingEntryPodLeaderLabel = "leader"
@@ -70,6 +71,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&ModelServingList{},
 		&ModelBooster{},
 		&ModelBoosterList{},
+		&ModelBundle{},
+		&ModelBundleList{},
 		&AutoscalingPolicy{},
 		&AutoscalingPolicyList{},
 		&AutoscalingPolicyBinding{},
@@ -19,6 +19,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/volcano-sh/kthena/pkg/autoscaler/autoscaler"
@@ -29,6 +30,7 @@ import (
 	workloadLister "github.com/volcano-sh/kthena/client-go/listers/workload/v1alpha1"
 	workload "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
 	"github.com/volcano-sh/kthena/pkg/autoscaler/util"
+	"github.com/volcano-sh/kthena/pkg/controller/options"
 	"istio.io/istio/pkg/util/sets"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -49,6 +51,7 @@ type AutoscaleController struct {
 	// client for custom resource
 	client                             clientset.Interface
 	namespace                          string
+	labelSelector                      string
 	autoscalingPoliciesLister          workloadLister.AutoscalingPolicyLister
 	autoscalingPoliciesInformer        cache.Controller
 	autoscalingPoliciesBindingLister   workloadLister.AutoscalingPolicyBindingLister
@@ -59,10 +62,11 @@ type AutoscaleController struct {
 	podsInformer                       cache.Controller
 	scalerMap                          map[string]*autoscaler.Autoscaler
 	optimizerMap                       map[string]*autoscaler.Optimizer
+	informersOnce                      sync.Once
 }
 
-func NewAutoscaleController(kubeClient kubernetes.Interface, client clientset.Interface, namespace string) *AutoscaleController {
-	informerFactory := informersv1alpha1.NewSharedInformerFactory(client, 0)
+func NewAutoscaleController(kubeClient kubernetes.Interface, client clientset.Interface, namespace string, opts options.InformerOptions) *AutoscaleController {
+	informerFactory := informersv1alpha1.NewSharedInformerFactoryWithOptions(client, opts.ResyncPeriod, informersv1alpha1.WithNamespace(opts.Namespace))
 	modelInferInformer := informerFactory.Workload().V1alpha1().ModelServings()
 	autoscalingPoliciesInformer := informerFactory.Workload().V1alpha1().AutoscalingPolicies()
 	autoscalingPoliciesBindingInformer := informerFactory.Workload().V1alpha1().AutoscalingPolicyBindings()
@@ -73,7 +77,7 @@ func NewAutoscaleController(kubeClient kubernetes.Interface, client clientset.In
 		return nil
 	}
 	kubeInformerFactory := informers.NewSharedInformerFactoryWithOptions(
-		kubeClient, 0, informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+		kubeClient, opts.ResyncPeriod, informers.WithNamespace(opts.Namespace), informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
 			opts.LabelSelector = selector.String()
 		}),
 	)
@@ -82,6 +86,7 @@ func NewAutoscaleController(kubeClient kubernetes.Interface, client clientset.In
 		kubeClient:                         kubeClient,
 		client:                             client,
 		namespace:                          namespace,
+		labelSelector:                      opts.LabelSelector,
 		autoscalingPoliciesLister:          autoscalingPoliciesInformer.Lister(),
 		autoscalingPoliciesInformer:        autoscalingPoliciesInformer.Informer(),
 		autoscalingPoliciesBindingLister:   autoscalingPoliciesBindingInformer.Lister(),
@@ -99,25 +104,32 @@ func NewAutoscaleController(kubeClient kubernetes.Interface, client clientset.In
 func (ac *AutoscaleController) Run(ctx context.Context) {
 	defer utilruntime.HandleCrash()
 
-	// start informers
-	go ac.autoscalingPoliciesInformer.RunWithContext(ctx)
-	go ac.autoscalingPoliciesBindingInformer.RunWithContext(ctx)
-	go ac.modelServingInformer.RunWithContext(ctx)
-	go ac.podsInformer.RunWithContext(ctx)
+	ac.WarmUp(ctx)
+
+	klog.Info("start autoscale controller")
+	// Reconciling stops with ctx, as another instance may have taken over the leadership.
+	go wait.UntilWithContext(ctx, ac.Reconcile, util.AutoscalingSyncPeriodSeconds*time.Second)
+
+	<-ctx.Done()
+	klog.Info("shut down autoscale controller")
+}
+
+// WarmUp starts the informers and waits for their caches to sync, without reconciling.
+// Informers are started once, ctx of the first call stops them.
+func (ac *AutoscaleController) WarmUp(ctx context.Context) {
+	ac.informersOnce.Do(func() {
+		// start informers
+		go ac.autoscalingPoliciesInformer.RunWithContext(ctx)
+		go ac.autoscalingPoliciesBindingInformer.RunWithContext(ctx)
+		go ac.modelServingInformer.RunWithContext(ctx)
+		go ac.podsInformer.RunWithContext(ctx)
+	})
 	cache.WaitForCacheSync(ctx.Done(),
 		ac.autoscalingPoliciesInformer.HasSynced,
 		ac.autoscalingPoliciesBindingInformer.HasSynced,
 		ac.modelServingInformer.HasSynced,
 		ac.podsInformer.HasSynced,
 	)
-
-	klog.Info("start autoscale controller")
-	go wait.Until(func() {
-		ac.Reconcile(ctx)
-	}, util.AutoscalingSyncPeriodSeconds*time.Second, nil)
-
-	<-ctx.Done()
-	klog.Info("shut down autoscale controller")
 }
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -126,7 +138,7 @@ func (ac *AutoscaleController) Reconcile(ctx context.Context) {
 	klog.V(4).Info("start to reconcile")
 	ctx, cancel := context.WithTimeout(ctx, util.AutoscaleCtxTimeoutSeconds*time.Second)
 	defer cancel()
-	bindingList, err := ac.client.WorkloadV1alpha1().AutoscalingPolicyBindings(ac.namespace).List(ctx, metav1.ListOptions{})
+	bindingList, err := ac.client.WorkloadV1alpha1().AutoscalingPolicyBindings(ac.namespace).List(ctx, metav1.ListOptions{LabelSelector: ac.labelSelector})
 	if err != nil {
 		klog.Errorf("failed to list autoscaling policy bindings, err: %v", err)
 		return
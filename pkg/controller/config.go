@@ -16,6 +16,14 @@ limitations under the License.
 
 package controller
 
+import (
+	"time"
+
+	"github.com/volcano-sh/kthena/pkg/controller/options"
+	garbagecollection "github.com/volcano-sh/kthena/pkg/garbage-collection-controller/controller"
+	rollout "github.com/volcano-sh/kthena/pkg/rollout-controller/controller"
+)
+
 type Config struct {
 	EnableLeaderElection bool
 	Workers              int
@@ -24,4 +32,29 @@ type Config struct {
 	Controllers          map[string]bool
 	KubeAPIQPS           float32
 	KubeAPIBurst         int
+
+	// LeaseDuration, RenewDeadline and RetryPeriod tune the leader election, see leaderelection.LeaderElectionConfig.
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+	// WarmStandby makes the instances which are not leading keep the informer caches of the controllers in sync,
+	// so that a new leader starts reconciling without waiting for a full list of the watched resources.
+	WarmStandby bool
+
+	// Informers tune and scope the informers of the controllers.
+	Informers options.InformerOptions
+	// ControllerWorkers overrides Workers for the controllers it contains, by controller name.
+	ControllerWorkers map[string]int
+	// GarbageCollection configures the collection of the orphaned resources generated by the controllers.
+	GarbageCollection garbagecollection.Options
+	// Rollout configures the canary rollouts of the ModelRoutes.
+	Rollout rollout.Options
+}
+
+// WorkersOf returns the number of workers of a controller.
+func (c Config) WorkersOf(controller string) int {
+	if workers, ok := c.ControllerWorkers[controller]; ok && workers > 0 {
+		return workers
+	}
+	return c.Workers
 }
@@ -0,0 +1,37 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigWorkersOf(t *testing.T) {
+	cc := Config{
+		Workers: 5,
+		ControllerWorkers: map[string]int{
+			ModelServingController: 20,
+			ModelBoosterController: 0,
+		},
+	}
+
+	assert.Equal(t, 20, cc.WorkersOf(ModelServingController))
+	assert.Equal(t, 5, cc.WorkersOf(ModelBoosterController))
+	assert.Equal(t, 5, cc.WorkersOf(StorageMigrationController))
+}
@@ -23,16 +23,28 @@ import (
 
 	clientset "github.com/volcano-sh/kthena/client-go/clientset/versioned"
 	autoscaler "github.com/volcano-sh/kthena/pkg/autoscaler/controller"
+	garbagecollection "github.com/volcano-sh/kthena/pkg/garbage-collection-controller/controller"
 	modelbooster "github.com/volcano-sh/kthena/pkg/model-booster-controller/controller"
 	"github.com/volcano-sh/kthena/pkg/model-booster-controller/utils"
+	modelbundle "github.com/volcano-sh/kthena/pkg/model-bundle-controller/controller"
 	modelserving "github.com/volcano-sh/kthena/pkg/model-serving-controller/controller"
+	"github.com/volcano-sh/kthena/pkg/permissions"
+	rollout "github.com/volcano-sh/kthena/pkg/rollout-controller/controller"
+	storagemigration "github.com/volcano-sh/kthena/pkg/storage-migration-controller/controller"
+	tenantrouter "github.com/volcano-sh/kthena/pkg/tenant-router-controller/controller"
+	corev1 "k8s.io/api/core/v1"
 	apiextclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/leaderelection"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	volcanoClientSet "volcano.sh/apis/pkg/client/clientset/versioned"
 )
@@ -43,10 +55,17 @@ const (
 	defaultRetryPeriod   = 2 * time.Second
 	leaderElectionId     = "kthena.controller-manager"
 	leaseName            = "lease.kthena.controller-manager"
+	// permissionsRecheckInterval is the interval between two reviews of the permissions of the disabled features.
+	permissionsRecheckInterval = time.Minute
 
-	ModelServingController = "modelserving"
-	ModelBoosterController = "modelbooster"
-	AutoscalerController   = "autoscaler"
+	ModelServingController      = "modelserving"
+	ModelBoosterController      = "modelbooster"
+	AutoscalerController        = "autoscaler"
+	StorageMigrationController  = "storagemigration"
+	GarbageCollectionController = "garbagecollection"
+	RolloutController           = "rollout"
+	BundleController            = "bundle"
+	TenantRouterController      = "tenantrouter"
 )
 
 func SetupController(ctx context.Context, cc Config) {
@@ -63,53 +82,129 @@ func SetupController(ctx context.Context, cc Config) {
 	}
 	kubeClient := kubernetes.NewForConfigOrDie(config)
 	client := clientset.NewForConfigOrDie(config)
-	volcanoClient, err := volcanoClientSet.NewForConfig(config)
-	if err != nil {
-		klog.Fatalf("failed to create volcano client: %v", err)
-	}
 	apiextClient, err := apiextclient.NewForConfig(config)
 	if err != nil {
 		klog.Fatalf("failed to create apiext client: %v", err)
 	}
 
+	// Optional features missing permissions are disabled, the core controllers fail on Forbidden errors.
+	watchNamespace := cc.Informers.Namespace
+	// Without a volcano client, the PodGroup manager disables gang scheduling.
+	var volcanoClient volcanoClientSet.Interface
+	if cc.Controllers[ModelServingController] && permissions.Enabled(ctx, kubeClient, watchNamespace, gangSchedulingFeature) {
+		volcanoClient, err = volcanoClientSet.NewForConfig(config)
+		if err != nil {
+			klog.Fatalf("failed to create volcano client: %v", err)
+		}
+	}
+
 	var mc *modelbooster.ModelBoosterController
 	var msc *modelserving.ModelServingController
 	var lwsc *modelserving.LWSController
 	var ac *autoscaler.AutoscaleController
+	var smc *storagemigration.StorageMigrationController
+	var gcc *garbagecollection.GarbageCollectionController
+	var rc *rollout.RolloutController
+	var bc *modelbundle.ModelBundleController
+	var trc *tenantrouter.TenantRouterController
 
 	for ctrl, enable := range cc.Controllers {
 		if enable {
 			switch ctrl {
 			case ModelBoosterController:
-				mc = modelbooster.NewModelBoosterController(kubeClient, client)
+				mc = modelbooster.NewModelBoosterController(kubeClient, client, cc.Informers)
 			case ModelServingController:
-				msc, err = modelserving.NewModelServingController(kubeClient, client, volcanoClient, apiextClient)
+				msc, err = modelserving.NewModelServingController(kubeClient, client, volcanoClient, apiextClient, cc.Informers)
 				if err != nil {
 					klog.Fatalf("failed to create ModelServing controller: %v", err)
 				}
-				lwsc, err = modelserving.InitializeLWSController(config, kubeClient, client)
+				if permissions.Enabled(ctx, kubeClient, watchNamespace, disruptionBudgetFeature) {
+					if err := msc.EnableDisruptionBudgets(cc.Informers); err != nil {
+						klog.Fatalf("failed to enable PodDisruptionBudgets: %v", err)
+					}
+				}
+				// The nodes are cluster scoped.
+				if permissions.Enabled(ctx, kubeClient, "", placementCostFeature) {
+					msc.EnablePlacementCost(cc.Informers)
+				}
+				lwsc, err = modelserving.InitializeLWSController(config, kubeClient, client, cc.Informers)
 				if err != nil {
 					klog.Errorf("Failed to initialize LWS controller: %v", err)
 				} else if lwsc == nil {
 					klog.Info("LeaderWorkerSet CRD not found, LWS support disabled")
+				} else if !permissions.Enabled(ctx, kubeClient, watchNamespace, leaderWorkerSetFeature) {
+					lwsc = nil
 				}
 			case AutoscalerController:
+				if !permissions.Enabled(ctx, kubeClient, watchNamespace, autoscalerFeature) {
+					break
+				}
+				namespace, err := utils.GetInClusterNameSpace()
+				if err != nil {
+					klog.Fatalf("failed to get in-cluster namespace: %v", err)
+				}
+				ac = autoscaler.NewAutoscaleController(kubeClient, client, namespace, cc.Informers)
+			case StorageMigrationController:
+				// Objects of all namespaces are migrated.
+				if !permissions.Enabled(ctx, kubeClient, "", storageMigrationFeature) {
+					break
+				}
+				dynamicClient, err := dynamic.NewForConfig(config)
+				if err != nil {
+					klog.Fatalf("failed to create dynamic client: %v", err)
+				}
+				smc = storagemigration.NewStorageMigrationController(apiextClient, dynamicClient, cc.Informers)
+			case GarbageCollectionController:
+				if !permissions.Enabled(ctx, kubeClient, watchNamespace, garbageCollectionFeature) {
+					break
+				}
+				// PodGroups are only collected with gang scheduling.
+				gcc = garbagecollection.NewGarbageCollectionController(kubeClient, client, volcanoClient, cc.Informers, cc.GarbageCollection)
+			case RolloutController:
+				// The canaries are analyzed with the metrics of the routers scraped by Prometheus.
+				if cc.Rollout.PrometheusAddress == "" {
+					klog.Info("No Prometheus address, rollout controller disabled")
+					break
+				}
+				if !permissions.Enabled(ctx, kubeClient, watchNamespace, rolloutFeature) {
+					break
+				}
+				rc, err = rollout.NewRolloutController(client, cc.Informers, cc.Rollout)
+				if err != nil {
+					klog.Fatalf("failed to create rollout controller: %v", err)
+				}
+			case BundleController:
+				if !permissions.Enabled(ctx, kubeClient, watchNamespace, bundleFeature) {
+					break
+				}
+				bc = modelbundle.NewModelBundleController(client, cc.Informers)
+			case TenantRouterController:
+				// The tenant routers are deployed next to the shared router, in the namespace of the release.
 				namespace, err := utils.GetInClusterNameSpace()
 				if err != nil {
 					klog.Fatalf("failed to get in-cluster namespace: %v", err)
 				}
-				ac = autoscaler.NewAutoscaleController(kubeClient, client, namespace)
+				if !permissions.Enabled(ctx, kubeClient, namespace, tenantRouterFeature) {
+					break
+				}
+				trc = tenantrouter.NewTenantRouterController(kubeClient, namespace, cc.Informers)
 			}
 		}
 	}
 
+	// The features are enabled at startup only, a disabled one granted its permissions is reported so that the
+	// operator restarts the controller manager.
+	recorder, pod := podEvents(kubeClient)
+	go permissions.WatchGranted(ctx, kubeClient, permissionsRecheckInterval,
+		permissions.RestartRequired("controller manager", recorder, pod))
+
 	startControllers := func(ctx context.Context) {
 		if mc != nil {
-			go mc.Run(ctx, cc.Workers)
+			go mc.Run(ctx, cc.WorkersOf(ModelBoosterController))
 			klog.Info("ModelBooster controller started")
 		}
 		if msc != nil {
-			go msc.Run(ctx, cc.Workers)
+			go msc.Run(ctx, cc.WorkersOf(ModelServingController))
 			klog.Info("ModelServing controller started")
 
 			if lwsc != nil {
@@ -125,14 +220,60 @@ func SetupController(ctx context.Context, cc Config) {
 			go ac.Run(ctx)
 			klog.Info("Autoscaler controller started")
 		}
+		if smc != nil {
+			go smc.Run(ctx, cc.WorkersOf(StorageMigrationController))
+			klog.Info("StorageMigration controller started")
+		}
+		if gcc != nil {
+			go gcc.Run(ctx)
+			klog.Info("GarbageCollection controller started")
+		}
+		if rc != nil {
+			go rc.Run(ctx)
+			klog.Info("Rollout controller started")
+		}
+		if bc != nil {
+			go bc.Run(ctx, cc.WorkersOf(BundleController))
+			klog.Info("ModelBundle controller started")
+		}
+		if trc != nil {
+			go trc.Run(ctx, cc.WorkersOf(TenantRouterController))
+			klog.Info("TenantRouter controller started")
+		}
 	}
 
 	if cc.EnableLeaderElection {
+		if cc.WarmStandby {
+			// Keep the informer caches in sync while waiting for the leadership, so that failover doesn't
+			// wait for a full list of the watched resources.
+			if mc != nil {
+				go mc.WarmUp(ctx)
+			}
+			if msc != nil {
+				go msc.WarmUp(ctx)
+			}
+			if lwsc != nil {
+				go lwsc.WarmUp(ctx)
+			}
+			if ac != nil {
+				go ac.WarmUp(ctx)
+			}
+			if smc != nil {
+				go smc.WarmUp(ctx)
+			}
+			if bc != nil {
+				go bc.WarmUp(ctx)
+			}
+			if trc != nil {
+				go trc.WarmUp(ctx)
+			}
+			klog.Info("Warming up controllers as standby")
+		}
 		startedLeading := func(ctx context.Context) {
 			startControllers(ctx)
 			klog.Info("Start as leader")
 		}
-		leaderElector, err := initLeaderElector(kubeClient, startedLeading)
+		leaderElector, err := initLeaderElector(ctx, kubeClient, cc, startedLeading)
 		if err != nil {
 			panic(err)
 		}
@@ -145,23 +286,29 @@ func SetupController(ctx context.Context, cc Config) {
 }
 
 // initLeaderElector inits a leader elector for leader election
-func initLeaderElector(kubeClient kubernetes.Interface, startedLeading func(ctx context.Context)) (*leaderelection.LeaderElector, error) {
+func initLeaderElector(ctx context.Context, kubeClient kubernetes.Interface, cc Config, startedLeading func(ctx context.Context)) (*leaderelection.LeaderElector, error) {
 	resourceLock, err := newResourceLock(kubeClient)
 	if err != nil {
 		return nil, err
 	}
 	leaderElector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
 		Lock:          resourceLock,
-		LeaseDuration: defaultLeaseDuration,
-		RenewDeadline: defaultRenewDeadline,
-		RetryPeriod:   defaultRetryPeriod,
+		LeaseDuration: durationOrDefault(cc.LeaseDuration, defaultLeaseDuration),
+		RenewDeadline: durationOrDefault(cc.RenewDeadline, defaultRenewDeadline),
+		RetryPeriod:   durationOrDefault(cc.RetryPeriod, defaultRetryPeriod),
 		Callbacks: leaderelection.LeaderCallbacks{
 			OnStartedLeading: startedLeading,
 			OnStoppedLeading: func() {
-				klog.Error("leader election lost")
+				if ctx.Err() != nil {
+					klog.Info("leader election released")
+					return
+				}
+				// The controllers are stopped and can't be restarted, exit so that this instance comes back as standby.
+				klog.Fatal("leader election lost")
 			},
 		},
-		ReleaseOnCancel: false,
+		// Release the lease on shutdown, e.g. during node drains, so that a standby takes over without waiting for it to expire.
+		ReleaseOnCancel: true,
 		Name:            leaderElectionId,
 	})
 	if err != nil {
@@ -170,6 +317,25 @@ func initLeaderElector(kubeClient kubernetes.Interface, startedLeading func(ctx
 	return leaderElector, nil
 }
 
+// podEvents returns the recorder of the events of the controller manager pod and its reference, nil if the pod is
+// unknown.
+func podEvents(kubeClient kubernetes.Interface) (record.EventRecorder, *corev1.ObjectReference) {
+	name, namespace := os.Getenv("POD_NAME"), os.Getenv("POD_NAMESPACE")
+	if name == "" || namespace == "" {
+		return nil, nil
+	}
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events(namespace)})
+	recorder := broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "kthena-controller-manager"})
+	return recorder, &corev1.ObjectReference{
+		Kind:       "Pod",
+		APIVersion: "v1",
+		Namespace:  namespace,
+		Name:       name,
+		UID:        types.UID(os.Getenv("POD_UID")),
+	}
+}
+
 // newResourceLock returns a lease lock which is used to elect leader
 func newResourceLock(client kubernetes.Interface) (*resourcelock.LeaseLock, error) {
 	namespace, err := utils.GetInClusterNameSpace()
@@ -193,3 +359,10 @@ func newResourceLock(client kubernetes.Interface) (*resourcelock.LeaseLock, erro
 		},
 	}, nil
 }
+
+func durationOrDefault(d, defaultDuration time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return defaultDuration
+}
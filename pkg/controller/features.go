@@ -0,0 +1,117 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/volcano-sh/kthena/pkg/permissions"
+)
+
+const workloadGroup = "workload.serving.volcano.sh"
+
+// Optional features of the controller manager and the permissions they need on top of the core ModelServing and
+// ModelBooster ones. A feature missing any of them is disabled at startup.
+var (
+	autoscalerFeature = permissions.Feature{
+		Name: "autoscaler",
+		Rules: []permissions.Rule{
+			{Group: workloadGroup, Resource: "autoscalingpolicies", Verbs: []string{"get", "list", "watch"}},
+			{Group: workloadGroup, Resource: "autoscalingpolicybindings", Verbs: []string{"get", "list", "watch"}},
+			{Group: workloadGroup, Resource: "modelservings", Verbs: []string{"update"}},
+		},
+	}
+
+	bundleFeature = permissions.Feature{
+		Name: "bundle",
+		Rules: []permissions.Rule{
+			{Group: workloadGroup, Resource: "modelbundles", Verbs: []string{"get", "list", "watch"}},
+			{Group: workloadGroup, Resource: "modelbundles/status", Verbs: []string{"update"}},
+		},
+	}
+
+	disruptionBudgetFeature = permissions.Feature{
+		Name: "disruption budget",
+		Rules: []permissions.Rule{
+			{Group: "policy", Resource: "poddisruptionbudgets", Verbs: []string{"create", "get", "list", "watch", "update", "delete"}},
+		},
+	}
+
+	garbageCollectionFeature = permissions.Feature{
+		Name: "garbage collection",
+		Rules: []permissions.Rule{
+			{Group: workloadGroup, Resource: "modelboosters", Verbs: []string{"list"}},
+			{Group: workloadGroup, Resource: "modelservings", Verbs: []string{"list", "delete"}},
+			{Group: workloadGroup, Resource: "autoscalingpolicies", Verbs: []string{"list", "delete"}},
+			{Group: workloadGroup, Resource: "autoscalingpolicybindings", Verbs: []string{"list", "delete"}},
+			{Group: "networking.serving.volcano.sh", Resource: "modelservers", Verbs: []string{"list", "delete"}},
+			{Group: "networking.serving.volcano.sh", Resource: "modelroutes", Verbs: []string{"list", "delete"}},
+			{Resource: "pods", Verbs: []string{"list"}},
+			{Resource: "services", Verbs: []string{"list", "delete"}},
+		},
+	}
+
+	gangSchedulingFeature = permissions.Feature{
+		Name: "gang scheduling",
+		Rules: []permissions.Rule{
+			{Group: "scheduling.volcano.sh", Resource: "podgroups", Verbs: []string{"create", "get", "list", "watch", "update", "delete"}},
+		},
+	}
+
+	leaderWorkerSetFeature = permissions.Feature{
+		Name: "LeaderWorkerSet support",
+		Rules: []permissions.Rule{
+			{Group: "leaderworkerset.x-k8s.io", Resource: "leaderworkersets", Verbs: []string{"get", "list", "watch", "update"}},
+		},
+	}
+
+	placementCostFeature = permissions.Feature{
+		Name: "placement cost",
+		Rules: []permissions.Rule{
+			{Resource: "nodes", Verbs: []string{"get", "list", "watch"}},
+		},
+	}
+
+	rolloutFeature = permissions.Feature{
+		Name: "rollout",
+		Rules: []permissions.Rule{
+			{Group: "networking.serving.volcano.sh", Resource: "modelroutes", Verbs: []string{"list"}},
+			{Group: "networking.serving.volcano.sh", Resource: "modelroutes/status", Verbs: []string{"update"}},
+		},
+	}
+
+	storageMigrationFeature = permissions.Feature{
+		Name: "storage migration",
+		Rules: []permissions.Rule{
+			{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions", Verbs: []string{"get", "list", "watch"}},
+			{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions/status", Verbs: []string{"update"}},
+			{Group: workloadGroup, Resource: "modelservings", Verbs: []string{"list", "update"}},
+			{Group: workloadGroup, Resource: "modelboosters", Verbs: []string{"list", "update"}},
+			{Group: workloadGroup, Resource: "autoscalingpolicies", Verbs: []string{"list", "update"}},
+			{Group: workloadGroup, Resource: "autoscalingpolicybindings", Verbs: []string{"list", "update"}},
+			{Group: "networking.serving.volcano.sh", Resource: "modelservers", Verbs: []string{"list", "update"}},
+			{Group: "networking.serving.volcano.sh", Resource: "modelroutes", Verbs: []string{"list", "update"}},
+		},
+	}
+
+	tenantRouterFeature = permissions.Feature{
+		Name: "tenant routers",
+		Rules: []permissions.Rule{
+			{Resource: "configmaps", Verbs: []string{"get", "list", "watch"}},
+			{Group: "apps", Resource: "deployments", Verbs: []string{"create", "get", "list", "watch", "update", "delete"}},
+			{Resource: "services", Verbs: []string{"create", "get", "list", "watch", "update", "delete"}},
+		},
+	}
+)
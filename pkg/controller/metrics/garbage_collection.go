@@ -0,0 +1,53 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const garbageCollectionSubsystem = "kthena_controller_garbage_collection"
+
+var (
+	orphanedResources = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: garbageCollectionSubsystem,
+		Name:      "orphaned_resources",
+		Help:      "Number of orphaned generated resources found by the last garbage collection, by kind and reason",
+	}, []string{"kind", "reason"})
+
+	deletedResources = promauto.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: garbageCollectionSubsystem,
+		Name:      "deleted_resources_total",
+		Help:      "Total number of orphaned generated resources deleted by the garbage collection",
+	}, []string{"kind"})
+)
+
+// ResetOrphanedResources clears the orphaned resources reported by the previous garbage collection.
+func ResetOrphanedResources() {
+	orphanedResources.Reset()
+}
+
+// RecordOrphanedResource records an orphaned resource of kind found by the garbage collection.
+func RecordOrphanedResource(kind, reason string) {
+	orphanedResources.WithLabelValues(kind, reason).Inc()
+}
+
+// RecordDeletedResource records the deletion of an orphaned resource of kind.
+func RecordDeletedResource(kind string) {
+	deletedResources.WithLabelValues(kind).Inc()
+}
@@ -0,0 +1,53 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const rolloutSubsystem = "kthena_controller_rollout"
+
+var (
+	canaryWeight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: rolloutSubsystem,
+		Name:      "canary_weight",
+		Help:      "Percentage of the traffic of the stable model server sent to the canary of the rollout of a ModelRoute",
+	}, []string{"model_route"})
+
+	rolloutAnalyses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: rolloutSubsystem,
+		Name:      "analyses_total",
+		Help:      "Total number of analyses of the canaries of the rollouts, by result: succeeded, failed, inconclusive or error",
+	}, []string{"model_route", "result"})
+)
+
+// SetCanaryWeight records the canary weight of the rollout of a ModelRoute.
+func SetCanaryWeight(modelRoute string, weight int32) {
+	canaryWeight.WithLabelValues(modelRoute).Set(float64(weight))
+}
+
+// DeleteCanaryWeight removes the canary weight of a ModelRoute without rollout.
+func DeleteCanaryWeight(modelRoute string) {
+	canaryWeight.DeleteLabelValues(modelRoute)
+}
+
+// RecordRolloutAnalysis records an analysis of the canary of the rollout of a ModelRoute.
+func RecordRolloutAnalysis(modelRoute, result string) {
+	rolloutAnalyses.WithLabelValues(modelRoute, result).Inc()
+}
@@ -0,0 +1,47 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestWorkqueueMetrics(t *testing.T) {
+	RegisterWorkqueueMetrics()
+	depth.Reset()
+	adds.Reset()
+	retries.Reset()
+	queue := workqueue.NewTypedRateLimitingQueueWithConfig(workqueue.DefaultTypedControllerRateLimiter[string](),
+		workqueue.TypedRateLimitingQueueConfig[string]{Name: "ModelServings"})
+	defer queue.ShutDown()
+
+	queue.Add("default/a")
+	queue.Add("default/b")
+	assert.Equal(t, float64(2), testutil.ToFloat64(depth.WithLabelValues("ModelServings")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(adds.WithLabelValues("ModelServings")))
+
+	item, _ := queue.Get()
+	queue.Done(item)
+	assert.Equal(t, float64(1), testutil.ToFloat64(depth.WithLabelValues("ModelServings")))
+
+	queue.AddRateLimited(item)
+	assert.Equal(t, float64(1), testutil.ToFloat64(retries.WithLabelValues("ModelServings")))
+}
@@ -0,0 +1,39 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// InformerOptions tune and scope the informers of the controllers.
+type InformerOptions struct {
+	// ResyncPeriod is the resync period of the informers, 0 disables resyncs.
+	ResyncPeriod time.Duration
+	// Namespace restricts the watched namespaced resources to a single namespace. If empty, all namespaces are watched.
+	Namespace string
+	// LabelSelector restricts the resources created by users, such as ModelBoosters and ModelServings, which are reconciled.
+	// Resources created by the controllers are selected by the labels the controllers set on them.
+	LabelSelector string
+}
+
+// TweakListOptions applies the label selector to the list options of an informer.
+func (o InformerOptions) TweakListOptions(opts *metav1.ListOptions) {
+	opts.LabelSelector = o.LabelSelector
+}
@@ -0,0 +1,365 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	volcanoclient "volcano.sh/apis/pkg/client/clientset/versioned"
+
+	clientset "github.com/volcano-sh/kthena/client-go/clientset/versioned"
+	networking "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	workloadv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/controller/metrics"
+	"github.com/volcano-sh/kthena/pkg/controller/options"
+	boosterutils "github.com/volcano-sh/kthena/pkg/model-booster-controller/utils"
+)
+
+// DefaultInterval is the default time between two garbage collections.
+const DefaultInterval = 10 * time.Minute
+
+// Reasons a generated resource is orphaned.
+const (
+	// ReasonOwnerNotFound is the reason of a resource whose owner was deleted, e.g. with the orphan propagation policy,
+	// or recreated.
+	ReasonOwnerNotFound = "OwnerNotFound"
+	// ReasonBackendRemoved is the reason of a resource generated for a backend the ModelBooster no longer has.
+	ReasonBackendRemoved = "BackendRemoved"
+	// ReasonAutoscalingDisabled is the reason of an autoscaling policy or binding of a ModelBooster without autoscaling.
+	ReasonAutoscalingDisabled = "AutoscalingDisabled"
+	// ReasonGangSchedulingDisabled is the reason of a PodGroup of a ModelServing not scheduled by Volcano.
+	ReasonGangSchedulingDisabled = "GangSchedulingDisabled"
+	// ReasonServingGroupRemoved is the reason of a Service or a PodGroup of a serving group without pods.
+	ReasonServingGroupRemoved = "ServingGroupRemoved"
+)
+
+// Options configure the garbage collection.
+type Options struct {
+	// Interval is the time between two garbage collections.
+	Interval time.Duration
+	// DryRun reports the orphaned resources without deleting them.
+	DryRun bool
+}
+
+// Orphan is a resource generated by a controller which is no longer wanted by the resource it was generated for.
+type Orphan struct {
+	Kind      string
+	Namespace string
+	Name      string
+	UID       types.UID
+	// Owner is the resource the orphan was generated for, as kind/name.
+	Owner  string
+	Reason string
+
+	client deleter
+}
+
+func (o Orphan) String() string {
+	return fmt.Sprintf("%s %s/%s of %s (%s)", o.Kind, o.Namespace, o.Name, o.Owner, o.Reason)
+}
+
+// deleter deletes the resources of a kind in a namespace, e.g. a typed client.
+type deleter interface {
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+}
+
+// GarbageCollectionController deletes the resources generated by the ModelBooster and ModelServing controllers which
+// are no longer wanted, e.g. the ModelServers of a removed backend or the PodGroups of a ModelServing no longer gang
+// scheduled. The controllers only clean up what they generated while they observe the change, and deleting a parent
+// with the orphan propagation policy leaves its children behind, which the router keeps routing to.
+//
+// The owner of a generated resource is tracked by the labels the controllers set on it: the model-uid label for the
+// resources of a ModelBooster, the ModelServing name label and the controller reference for those of a ModelServing.
+// An orphan is deleted when it is found by two consecutive collections, so that resources being created or recreated
+// by the controllers are not deleted.
+type GarbageCollectionController struct {
+	kubeClient   kubernetes.Interface
+	kthenaClient clientset.Interface
+	// volcanoClient is nil without gang scheduling, PodGroups are then not collected.
+	volcanoClient volcanoclient.Interface
+	namespace     string
+	options       Options
+
+	// candidates are the UIDs of the orphans found by the previous collection.
+	candidates sets.Set[types.UID]
+}
+
+func NewGarbageCollectionController(kubeClient kubernetes.Interface, kthenaClient clientset.Interface, volcanoClient volcanoclient.Interface,
+	informerOptions options.InformerOptions, opts Options) *GarbageCollectionController {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	return &GarbageCollectionController{
+		kubeClient:    kubeClient,
+		kthenaClient:  kthenaClient,
+		volcanoClient: volcanoClient,
+		namespace:     informerOptions.Namespace,
+		options:       opts,
+		candidates:    sets.New[types.UID](),
+	}
+}
+
+func (c *GarbageCollectionController) Run(ctx context.Context) {
+	defer utilruntime.HandleCrash()
+
+	klog.Infof("start garbage collection controller, interval %s, dry run %t", c.options.Interval, c.options.DryRun)
+	wait.UntilWithContext(ctx, c.collect, c.options.Interval)
+	klog.Info("shut down garbage collection controller")
+}
+
+// collect finds the orphaned resources and deletes those found by the previous collection, or only reports them in
+// dry run mode.
+func (c *GarbageCollectionController) collect(ctx context.Context) {
+	orphans, err := c.FindOrphans(ctx)
+	if err != nil {
+		klog.Errorf("failed to find orphaned resources: %v", err)
+		return
+	}
+	klog.Infof("Garbage collection found %d orphaned resources", len(orphans))
+
+	metrics.ResetOrphanedResources()
+	candidates := sets.New[types.UID]()
+	for _, orphan := range orphans {
+		metrics.RecordOrphanedResource(orphan.Kind, orphan.Reason)
+		if c.options.DryRun {
+			klog.Infof("Orphaned %s, not deleted in dry run mode", orphan)
+			continue
+		}
+		if !c.candidates.Has(orphan.UID) {
+			klog.V(2).Infof("Orphaned %s, deleting it if still orphaned on the next collection", orphan)
+			candidates.Insert(orphan.UID)
+			continue
+		}
+		// The precondition prevents deleting a resource recreated with the same name since it was listed.
+		err := orphan.client.Delete(ctx, orphan.Name, metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &orphan.UID}})
+		if err != nil && !apierrors.IsNotFound(err) && !apierrors.IsConflict(err) {
+			klog.Errorf("failed to delete orphaned %s: %v", orphan, err)
+			candidates.Insert(orphan.UID)
+			continue
+		}
+		metrics.RecordDeletedResource(orphan.Kind)
+		klog.Infof("Deleted orphaned %s", orphan)
+	}
+	c.candidates = candidates
+}
+
+// FindOrphans returns the orphaned resources generated by the ModelBooster and ModelServing controllers.
+func (c *GarbageCollectionController) FindOrphans(ctx context.Context) ([]Orphan, error) {
+	boosterOrphans, err := c.findModelBoosterOrphans(ctx)
+	if err != nil {
+		return nil, err
+	}
+	servingOrphans, err := c.findModelServingOrphans(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append(boosterOrphans, servingOrphans...), nil
+}
+
+// findModelBoosterOrphans returns the orphaned ModelServings, ModelServers, ModelRoutes, AutoscalingPolicies and
+// AutoscalingPolicyBindings generated for ModelBoosters.
+func (c *GarbageCollectionController) findModelBoosterOrphans(ctx context.Context) ([]Orphan, error) {
+	boosters, err := c.kthenaClient.WorkloadV1alpha1().ModelBoosters(c.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ModelBoosters: %w", err)
+	}
+	owners := make(map[types.UID]*workloadv1alpha1.ModelBooster, len(boosters.Items))
+	for i := range boosters.Items {
+		owners[boosters.Items[i].UID] = &boosters.Items[i]
+	}
+
+	var orphans []Orphan
+	find := func(kind string, obj metav1.Object, client deleter) {
+		if obj.GetDeletionTimestamp() != nil {
+			return
+		}
+		owner := owners[types.UID(obj.GetLabels()[boosterutils.OwnerUIDKey])]
+		if reason := modelBoosterOrphanReason(kind, obj, owner); reason != "" {
+			orphans = append(orphans, newOrphan(kind, obj, workloadv1alpha1.ModelKind.Kind+"/"+obj.GetLabels()[boosterutils.ModelNameLabelKey], reason, client))
+		}
+	}
+	listOptions := metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{boosterutils.ManageBy: workloadv1alpha1.GroupName}).String(),
+	}
+
+	servings, err := c.kthenaClient.WorkloadV1alpha1().ModelServings(c.namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ModelServings: %w", err)
+	}
+	for i := range servings.Items {
+		ms := &servings.Items[i]
+		find(workloadv1alpha1.ModelServingKind.Kind, ms, c.kthenaClient.WorkloadV1alpha1().ModelServings(ms.Namespace))
+	}
+	modelServers, err := c.kthenaClient.NetworkingV1alpha1().ModelServers(c.namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ModelServers: %w", err)
+	}
+	for i := range modelServers.Items {
+		modelServer := &modelServers.Items[i]
+		find(networking.ModelServerKind, modelServer, c.kthenaClient.NetworkingV1alpha1().ModelServers(modelServer.Namespace))
+	}
+	modelRoutes, err := c.kthenaClient.NetworkingV1alpha1().ModelRoutes(c.namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ModelRoutes: %w", err)
+	}
+	for i := range modelRoutes.Items {
+		modelRoute := &modelRoutes.Items[i]
+		find(networking.ModelRouteKind, modelRoute, c.kthenaClient.NetworkingV1alpha1().ModelRoutes(modelRoute.Namespace))
+	}
+	policies, err := c.kthenaClient.WorkloadV1alpha1().AutoscalingPolicies(c.namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list AutoscalingPolicies: %w", err)
+	}
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+		find(workloadv1alpha1.AutoscalingPolicyKind.Kind, policy, c.kthenaClient.WorkloadV1alpha1().AutoscalingPolicies(policy.Namespace))
+	}
+	bindings, err := c.kthenaClient.WorkloadV1alpha1().AutoscalingPolicyBindings(c.namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list AutoscalingPolicyBindings: %w", err)
+	}
+	for i := range bindings.Items {
+		binding := &bindings.Items[i]
+		find(workloadv1alpha1.AutoscalingPolicyBindingKind.Kind, binding, c.kthenaClient.WorkloadV1alpha1().AutoscalingPolicyBindings(binding.Namespace))
+	}
+	return orphans, nil
+}
+
+// modelBoosterOrphanReason returns why a resource of kind generated for the owner ModelBooster is orphaned, empty if
+// the ModelBooster still wants it. owner is nil if the ModelBooster doesn't exist.
+func modelBoosterOrphanReason(kind string, obj metav1.Object, owner *workloadv1alpha1.ModelBooster) string {
+	switch {
+	case owner == nil:
+		return ReasonOwnerNotFound
+	case (kind == workloadv1alpha1.AutoscalingPolicyKind.Kind || kind == workloadv1alpha1.AutoscalingPolicyBindingKind.Kind) &&
+		owner.Spec.AutoscalingPolicy == nil:
+		return ReasonAutoscalingDisabled
+	// The ModelRoute is generated for the ModelBooster, not for a backend.
+	case kind != networking.ModelRouteKind && obj.GetLabels()[boosterutils.BackendNameLabelKey] != owner.Spec.Backend.Name:
+		return ReasonBackendRemoved
+	}
+	return ""
+}
+
+// findModelServingOrphans returns the orphaned headless Services and PodGroups generated for ModelServings.
+func (c *GarbageCollectionController) findModelServingOrphans(ctx context.Context) ([]Orphan, error) {
+	servings, err := c.kthenaClient.WorkloadV1alpha1().ModelServings(c.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ModelServings: %w", err)
+	}
+	owners := make(map[string]*workloadv1alpha1.ModelServing, len(servings.Items))
+	for i := range servings.Items {
+		ms := &servings.Items[i]
+		owners[ms.Namespace+"/"+ms.Name] = ms
+	}
+
+	requirement, err := labels.NewRequirement(workloadv1alpha1.ModelServingNameLabelKey, selection.Exists, nil)
+	if err != nil {
+		return nil, err
+	}
+	listOptions := metav1.ListOptions{LabelSelector: labels.NewSelector().Add(*requirement).String()}
+	pods, err := c.kubeClient.CoreV1().Pods(c.namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	// The serving groups and the roles of the serving groups which have pods.
+	groups, roles := sets.New[string](), sets.New[string]()
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		group := pod.Namespace + "/" + pod.Labels[workloadv1alpha1.GroupNameLabelKey]
+		groups.Insert(group)
+		roles.Insert(group + "/" + pod.Labels[workloadv1alpha1.RoleIDKey])
+	}
+
+	var orphans []Orphan
+	find := func(kind string, obj metav1.Object, client deleter, reason func(*workloadv1alpha1.ModelServing, string) string) {
+		if obj.GetDeletionTimestamp() != nil {
+			return
+		}
+		name := obj.GetLabels()[workloadv1alpha1.ModelServingNameLabelKey]
+		owner := owners[obj.GetNamespace()+"/"+name]
+		// A resource left by a deleted ModelServing may have the name of a new one.
+		if ref := metav1.GetControllerOf(obj); owner != nil && ref != nil && ref.Kind == workloadv1alpha1.ModelServingKind.Kind && ref.UID != owner.UID {
+			owner = nil
+		}
+		group := obj.GetNamespace() + "/" + obj.GetLabels()[workloadv1alpha1.GroupNameLabelKey]
+		if owner == nil {
+			orphans = append(orphans, newOrphan(kind, obj, workloadv1alpha1.ModelServingKind.Kind+"/"+name, ReasonOwnerNotFound, client))
+		} else if r := reason(owner, group); r != "" {
+			orphans = append(orphans, newOrphan(kind, obj, workloadv1alpha1.ModelServingKind.Kind+"/"+name, r, client))
+		}
+	}
+
+	services, err := c.kubeClient.CoreV1().Services(c.namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	for i := range services.Items {
+		svc := &services.Items[i]
+		find("Service", svc, c.kubeClient.CoreV1().Services(svc.Namespace), func(_ *workloadv1alpha1.ModelServing, group string) string {
+			if !roles.Has(group + "/" + svc.Labels[workloadv1alpha1.RoleIDKey]) {
+				return ReasonServingGroupRemoved
+			}
+			return ""
+		})
+	}
+
+	if c.volcanoClient == nil {
+		return orphans, nil
+	}
+	podGroups, err := c.volcanoClient.SchedulingV1beta1().PodGroups(c.namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PodGroups: %w", err)
+	}
+	for i := range podGroups.Items {
+		podGroup := &podGroups.Items[i]
+		find("PodGroup", podGroup, c.volcanoClient.SchedulingV1beta1().PodGroups(podGroup.Namespace), func(owner *workloadv1alpha1.ModelServing, group string) string {
+			switch {
+			case owner.Spec.SchedulerName != "volcano":
+				return ReasonGangSchedulingDisabled
+			case !groups.Has(group):
+				return ReasonServingGroupRemoved
+			}
+			return ""
+		})
+	}
+	return orphans, nil
+}
+
+func newOrphan(kind string, obj metav1.Object, owner, reason string, client deleter) Orphan {
+	return Orphan{
+		Kind:      kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		UID:       obj.GetUID(),
+		Owner:     owner,
+		Reason:    reason,
+		client:    client,
+	}
+}
@@ -0,0 +1,166 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	schedulingv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+	volcanofake "volcano.sh/apis/pkg/client/clientset/versioned/fake"
+
+	kthenafake "github.com/volcano-sh/kthena/client-go/clientset/versioned/fake"
+	networking "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	workloadv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/controller/options"
+	boosterutils "github.com/volcano-sh/kthena/pkg/model-booster-controller/utils"
+)
+
+func boosterMeta(name, model, uid, backend string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: "default",
+		Name:      name,
+		UID:       types.UID(name + "-uid"),
+		Labels: map[string]string{
+			boosterutils.ModelNameLabelKey:   model,
+			boosterutils.BackendNameLabelKey: backend,
+			boosterutils.ManageBy:            workloadv1alpha1.GroupName,
+			boosterutils.OwnerUIDKey:         uid,
+		},
+	}
+}
+
+func servingMeta(name, modelServing, group, roleID string, owner *workloadv1alpha1.ModelServing) metav1.ObjectMeta {
+	meta := metav1.ObjectMeta{
+		Namespace: "default",
+		Name:      name,
+		UID:       types.UID(name + "-uid"),
+		Labels: map[string]string{
+			workloadv1alpha1.ModelServingNameLabelKey: modelServing,
+			workloadv1alpha1.GroupNameLabelKey:        group,
+			workloadv1alpha1.RoleIDKey:                roleID,
+		},
+	}
+	if owner != nil {
+		meta.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(owner, workloadv1alpha1.ModelServingKind)}
+	}
+	return meta
+}
+
+func newTestController(dryRun bool) *GarbageCollectionController {
+	booster := &workloadv1alpha1.ModelBooster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "llama", UID: "llama-uid"},
+		Spec:       workloadv1alpha1.ModelBoosterSpec{Backend: workloadv1alpha1.ModelBackend{Name: "b1"}},
+	}
+	gangScheduled := &workloadv1alpha1.ModelServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ms", UID: "ms-uid"},
+		Spec:       workloadv1alpha1.ModelServingSpec{SchedulerName: "volcano"},
+	}
+	defaultScheduled := &workloadv1alpha1.ModelServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other", UID: "other-uid"},
+	}
+	recreated := &workloadv1alpha1.ModelServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "recreated", UID: "old-uid"},
+	}
+
+	kthenaClient := kthenafake.NewSimpleClientset(
+		booster, gangScheduled, defaultScheduled,
+		&workloadv1alpha1.ModelServing{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "recreated", UID: "new-uid"}},
+		&networking.ModelServer{ObjectMeta: boosterMeta("llama-b1", "llama", "llama-uid", "b1")},
+		&networking.ModelServer{ObjectMeta: boosterMeta("llama-b0", "llama", "llama-uid", "b0")},
+		&networking.ModelServer{ObjectMeta: boosterMeta("deleted-b1", "deleted", "deleted-uid", "b1")},
+		&networking.ModelRoute{ObjectMeta: boosterMeta("llama", "llama", "llama-uid", "")},
+		&workloadv1alpha1.AutoscalingPolicy{ObjectMeta: boosterMeta("llama-b1", "llama", "llama-uid", "b1")},
+	)
+	kubeClient := kubefake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: servingMeta("ms-0-prefill-0-0", "ms", "ms-0", "prefill-0", gangScheduled)},
+		&corev1.Pod{ObjectMeta: servingMeta("other-0-prefill-0-0", "other", "other-0", "prefill-0", defaultScheduled)},
+		&corev1.Service{ObjectMeta: servingMeta("ms-0-prefill-0-0", "ms", "ms-0", "prefill-0", gangScheduled)},
+		&corev1.Service{ObjectMeta: servingMeta("ms-1-prefill-0-0", "ms", "ms-1", "prefill-0", gangScheduled)},
+		&corev1.Service{ObjectMeta: servingMeta("deleted-0-prefill-0-0", "deleted", "deleted-0", "prefill-0", nil)},
+		&corev1.Service{ObjectMeta: servingMeta("recreated-0-prefill-0-0", "recreated", "recreated-0", "prefill-0", recreated)},
+	)
+	volcanoClient := volcanofake.NewSimpleClientset(
+		&schedulingv1beta1.PodGroup{ObjectMeta: servingMeta("ms-0", "ms", "ms-0", "", gangScheduled)},
+		&schedulingv1beta1.PodGroup{ObjectMeta: servingMeta("ms-1", "ms", "ms-1", "", gangScheduled)},
+		&schedulingv1beta1.PodGroup{ObjectMeta: servingMeta("other-0", "other", "other-0", "", defaultScheduled)},
+	)
+	return NewGarbageCollectionController(kubeClient, kthenaClient, volcanoClient, options.InformerOptions{}, Options{DryRun: dryRun})
+}
+
+func TestFindOrphans(t *testing.T) {
+	c := newTestController(false)
+
+	orphans, err := c.FindOrphans(context.Background())
+	require.NoError(t, err)
+	var found []string
+	for _, orphan := range orphans {
+		found = append(found, orphan.String())
+	}
+	assert.ElementsMatch(t, []string{
+		"ModelServer default/llama-b0 of ModelBooster/llama (BackendRemoved)",
+		"ModelServer default/deleted-b1 of ModelBooster/deleted (OwnerNotFound)",
+		"AutoscalingPolicy default/llama-b1 of ModelBooster/llama (AutoscalingDisabled)",
+		"Service default/ms-1-prefill-0-0 of ModelServing/ms (ServingGroupRemoved)",
+		"Service default/deleted-0-prefill-0-0 of ModelServing/deleted (OwnerNotFound)",
+		"Service default/recreated-0-prefill-0-0 of ModelServing/recreated (OwnerNotFound)",
+		"PodGroup default/ms-1 of ModelServing/ms (ServingGroupRemoved)",
+		"PodGroup default/other-0 of ModelServing/other (GangSchedulingDisabled)",
+	}, found)
+}
+
+func TestCollect(t *testing.T) {
+	tests := []struct {
+		name    string
+		dryRun  bool
+		deleted bool
+	}{
+		{name: "deletes orphans found twice", deleted: true},
+		{name: "dry run", dryRun: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestController(tt.dryRun)
+			modelServers := c.kthenaClient.NetworkingV1alpha1().ModelServers("default")
+
+			c.collect(context.Background())
+			_, err := modelServers.Get(context.Background(), "llama-b0", metav1.GetOptions{})
+			require.NoError(t, err, "an orphan is not deleted when first found")
+
+			c.collect(context.Background())
+			_, err = modelServers.Get(context.Background(), "llama-b0", metav1.GetOptions{})
+			assert.Equal(t, tt.deleted, err != nil)
+			_, err = modelServers.Get(context.Background(), "llama-b1", metav1.GetOptions{})
+			assert.NoError(t, err)
+
+			orphans, err := c.FindOrphans(context.Background())
+			require.NoError(t, err)
+			if tt.deleted {
+				assert.Empty(t, orphans)
+			} else {
+				assert.Len(t, orphans, 8)
+			}
+		})
+	}
+}
@@ -18,8 +18,10 @@ package accesslog
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"time"
 )
@@ -101,6 +103,9 @@ func (l *accessLoggerImpl) Log(entry *AccessLogEntry) error {
 	if entry == nil {
 		return nil
 	}
+	if entry.SampledOut && entry.StatusCode < http.StatusInternalServerError {
+		return nil
+	}
 
 	var output string
 	var err error
@@ -147,6 +152,7 @@ func (l *accessLoggerImpl) formatJSON(entry *AccessLogEntry) (string, error) {
 func (l *accessLoggerImpl) formatText(entry *AccessLogEntry) (string, error) {
 	// Format: [timestamp] "METHOD /path PROTOCOL" status_code [error=type:message]
 	// model_name=name model_route=route model_server=server selected_pod=pod request_id=id tokens=input/output
+	// [audio_seconds=seconds] [images=count(megapixels) image_cost=cost] [documents=count]
 	// timings=total(req+upstream+resp)ms
 
 	timestamp := entry.Timestamp.Format(time.RFC3339Nano)
@@ -182,6 +188,15 @@ func (l *accessLoggerImpl) formatText(entry *AccessLogEntry) (string, error) {
 	if entry.InputTokens > 0 || entry.OutputTokens > 0 {
 		line += fmt.Sprintf(" tokens=%d/%d", entry.InputTokens, entry.OutputTokens)
 	}
+	if entry.AudioSeconds > 0 {
+		line += fmt.Sprintf(" audio_seconds=%.3f", entry.AudioSeconds)
+	}
+	if entry.Images > 0 {
+		line += fmt.Sprintf(" images=%d(%.2fMP) image_cost=%g", entry.Images, entry.ImageMegapixels, entry.ImageCost)
+	}
+	if entry.Documents > 0 {
+		line += fmt.Sprintf(" documents=%d", entry.Documents)
+	}
 
 	// Add complete timing breakdown with total and breakdown
 	line += fmt.Sprintf(" timings=%dms(%d+%d+%d)",
@@ -203,3 +218,33 @@ func (l *noopAccessLogger) Log(entry *AccessLogEntry) error {
 func (l *noopAccessLogger) Close() error {
 	return nil
 }
+
+// multiAccessLogger writes every entry to several loggers
+type multiAccessLogger struct {
+	loggers []AccessLogger
+}
+
+// NewMultiAccessLogger creates a logger that writes entries to all the given loggers
+func NewMultiAccessLogger(loggers ...AccessLogger) AccessLogger {
+	return &multiAccessLogger{loggers: loggers}
+}
+
+func (l *multiAccessLogger) Log(entry *AccessLogEntry) error {
+	var errs []error
+	for _, logger := range l.loggers {
+		if err := logger.Log(entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (l *multiAccessLogger) Close() error {
+	var errs []error
+	for _, logger := range l.loggers {
+		if err := logger.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
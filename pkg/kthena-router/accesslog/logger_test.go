@@ -17,7 +17,9 @@ limitations under the License.
 package accesslog
 
 import (
+	"bytes"
 	"encoding/json"
+	"io"
 	"testing"
 	"time"
 
@@ -266,3 +268,28 @@ func TestAccessLoggerConfig(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotNil(t, logger)
 }
+
+func TestAccessLogger_Sampling(t *testing.T) {
+	var buf bytes.Buffer
+	logger := &accessLoggerImpl{config: &AccessLoggerConfig{Format: FormatText, Enabled: true}, writer: nopWriteCloser{&buf}}
+
+	ctx := NewAccessLogContext("sampled", "POST", "/v1/chat/completions", "HTTP/1.1", "llama2-7b")
+	ctx.SetSampling(0)
+	require.NoError(t, logger.Log(ctx.ToAccessLogEntry(200)))
+	assert.Empty(t, buf.String(), "requests sampled out must not be logged")
+
+	require.NoError(t, logger.Log(ctx.ToAccessLogEntry(503)))
+	assert.Contains(t, buf.String(), "503", "server errors must always be logged")
+
+	buf.Reset()
+	ctx = NewAccessLogContext("kept", "POST", "/v1/chat/completions", "HTTP/1.1", "llama2-7b")
+	ctx.SetSampling(100)
+	require.NoError(t, logger.Log(ctx.ToAccessLogEntry(200)))
+	assert.Contains(t, buf.String(), "200")
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
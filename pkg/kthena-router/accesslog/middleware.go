@@ -20,6 +20,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
 )
 
 const (
@@ -36,6 +38,8 @@ func AccessLogMiddleware(logger AccessLogger) gin.HandlerFunc {
 			requestID = uuid.New().String()
 			c.Request.Header.Set("x-request-id", requestID)
 		}
+		// Return the request ID so that clients can refer to the request, e.g. to report feedback
+		c.Header("x-request-id", requestID)
 
 		// Create access log context
 		ctx := NewAccessLogContext(
@@ -52,6 +56,9 @@ func AccessLogMiddleware(logger AccessLogger) gin.HandlerFunc {
 		// Process request
 		c.Next()
 
+		// The caller is authenticated by the handlers of the request
+		ctx.Consumer = c.GetString(common.UserIdKey)
+
 		// Log the access entry after request completion
 		statusCode := c.Writer.Status()
 		entry := ctx.ToAccessLogEntry(statusCode)
@@ -94,6 +101,34 @@ func SetTokenCounts(c *gin.Context, inputTokens, outputTokens int) {
 	}
 }
 
+// SetAudioSeconds sets the duration of the audio of an audio request in the access log context
+func SetAudioSeconds(c *gin.Context, seconds float64) {
+	if ctx := GetAccessLogContext(c); ctx != nil {
+		ctx.SetAudioSeconds(seconds)
+	}
+}
+
+// SetImageUsage sets the images generated by an image generation request in the access log context
+func SetImageUsage(c *gin.Context, images int, megapixels, cost float64) {
+	if ctx := GetAccessLogContext(c); ctx != nil {
+		ctx.SetImageUsage(images, megapixels, cost)
+	}
+}
+
+// SetDocuments sets the number of documents reranked or of inputs classified or embedded in the access log context
+func SetDocuments(c *gin.Context, documents int) {
+	if ctx := GetAccessLogContext(c); ctx != nil {
+		ctx.SetDocuments(documents)
+	}
+}
+
+// SetSampling samples the request in the access log context, keeping the given percentage of the requests
+func SetSampling(c *gin.Context, percentage int32) {
+	if ctx := GetAccessLogContext(c); ctx != nil {
+		ctx.SetSampling(percentage)
+	}
+}
+
 // SetError sets error information in the access log context
 func SetError(c *gin.Context, errorType, message string) {
 	if ctx := GetAccessLogContext(c); ctx != nil {
@@ -17,6 +17,7 @@ limitations under the License.
 package accesslog
 
 import (
+	"math/rand"
 	"time"
 )
 
@@ -43,11 +44,28 @@ type AccessLogEntry struct {
 	InputTokens  int `json:"input_tokens,omitempty"`
 	OutputTokens int `json:"output_tokens,omitempty"`
 
+	// AudioSeconds is the duration of the audio transcribed or translated, audio requests are metered by duration.
+	AudioSeconds float64 `json:"audio_seconds,omitempty"`
+
+	// Image generation requests are metered by the images generated, their megapixels and cost.
+	Images          int     `json:"images,omitempty"`
+	ImageMegapixels float64 `json:"image_megapixels,omitempty"`
+	ImageCost       float64 `json:"image_cost,omitempty"`
+
+	// Documents is the number of documents reranked or of inputs classified.
+	Documents int `json:"documents,omitempty"`
+
 	// Timing breakdown (in milliseconds) - flattened fields
 	DurationTotal              int64 `json:"duration_total"`
 	DurationRequestProcessing  int64 `json:"duration_request_processing"`
 	DurationUpstreamProcessing int64 `json:"duration_upstream_processing"`
 	DurationResponseProcessing int64 `json:"duration_response_processing"`
+
+	// SampledOut entries are left out of the access log unless they failed with a server error.
+	SampledOut bool `json:"-"`
+
+	// Consumer is the authenticated caller which sent the request, kept out of the access log.
+	Consumer string `json:"-"`
 }
 
 // ErrorInfo contains error details for failed requests
@@ -73,6 +91,17 @@ type AccessLogContext struct {
 	InputTokens  int
 	OutputTokens int
 
+	// Duration of the audio of audio requests
+	AudioSeconds float64
+
+	// Images generated by image generation requests
+	Images          int
+	ImageMegapixels float64
+	ImageCost       float64
+
+	// Documents reranked or inputs classified
+	Documents int
+
 	// Timing checkpoints
 	RequestProcessingStart  time.Time
 	RequestProcessingEnd    time.Time
@@ -86,6 +115,12 @@ type AccessLogContext struct {
 
 	// Final status
 	StatusCode int
+
+	// SampledOut is set when the request is left out of the access log by the sampling of its ModelRoute
+	SampledOut bool
+
+	// Consumer is the authenticated caller which sent the request
+	Consumer string
 }
 
 // NewAccessLogContext creates a new context for tracking request lifecycle
@@ -115,6 +150,28 @@ func (ctx *AccessLogContext) SetTokenCounts(inputTokens, outputTokens int) {
 	ctx.OutputTokens = outputTokens
 }
 
+// SetAudioSeconds sets the duration of the audio of an audio request
+func (ctx *AccessLogContext) SetAudioSeconds(seconds float64) {
+	ctx.AudioSeconds = seconds
+}
+
+// SetImageUsage sets the images generated by an image generation request, their megapixels and cost
+func (ctx *AccessLogContext) SetImageUsage(images int, megapixels, cost float64) {
+	ctx.Images = images
+	ctx.ImageMegapixels = megapixels
+	ctx.ImageCost = cost
+}
+
+// SetDocuments sets the number of documents reranked or of inputs classified
+func (ctx *AccessLogContext) SetDocuments(documents int) {
+	ctx.Documents = documents
+}
+
+// SetSampling samples the request in the access log, keeping the given percentage of the requests
+func (ctx *AccessLogContext) SetSampling(percentage int32) {
+	ctx.SampledOut = rand.Int31n(100) >= percentage
+}
+
 // SetError sets error information
 func (ctx *AccessLogContext) SetError(errorType, message string) {
 	ctx.Error = &ErrorInfo{
@@ -189,10 +246,17 @@ func (ctx *AccessLogContext) ToAccessLogEntry(statusCode int) *AccessLogEntry {
 		RequestID:                  ctx.RequestID,
 		InputTokens:                ctx.InputTokens,
 		OutputTokens:               ctx.OutputTokens,
+		AudioSeconds:               ctx.AudioSeconds,
+		Images:                     ctx.Images,
+		ImageMegapixels:            ctx.ImageMegapixels,
+		ImageCost:                  ctx.ImageCost,
+		Documents:                  ctx.Documents,
+		Consumer:                   ctx.Consumer,
 		DurationTotal:              total,
 		DurationRequestProcessing:  requestProcessing,
 		DurationUpstreamProcessing: upstreamProcessing,
 		DurationResponseProcessing: responseProcessing,
+		SampledOut:                 ctx.SampledOut,
 	}
 
 	return entry
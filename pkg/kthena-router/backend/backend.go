@@ -24,6 +24,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/volcano-sh/kthena/pkg/kthena-router/backend/sglang"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/backend/triton"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/backend/vllm"
 )
 
@@ -36,6 +37,7 @@ type MetricsProvider interface {
 
 var engineRegistry = map[string]MetricsProvider{
 	"SGLang": sglang.NewSglangEngine(),
+	"Triton": triton.NewTritonEngine(),
 	"vLLM":   vllm.NewVllmEngine(),
 }
 
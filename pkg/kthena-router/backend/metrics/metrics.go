@@ -19,15 +19,21 @@ package metrics
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/expfmt"
 	"github.com/prometheus/common/model"
 )
 
+// scrapeTimeout bounds the scrape of the metrics of a pod, whose metrics are kept until stale if it doesn't answer.
+const scrapeTimeout = 3 * time.Second
+
+var scrapeClient = &http.Client{Timeout: scrapeTimeout}
+
 // This function refer to aibrix(https://github.com/vllm-project/aibrix/blob/main/pkg/metrics/utils.go)
 func ParseMetricsURL(url string) (map[string]*dto.MetricFamily, error) {
-	resp, err := http.Get(url)
+	resp, err := scrapeClient.Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to fetch metrics from %s: %v", url, err)
 	}
@@ -0,0 +1,89 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package triton
+
+import (
+	"fmt"
+
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/backend/metrics"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/utils"
+)
+
+var (
+	// RequestWaitingNum is reported for each model of the server, the requests waiting for all of them are summed.
+	RequestWaitingNum = "nv_inference_pending_request_count"
+)
+
+var (
+	GaugeMetrics = []string{
+		RequestWaitingNum,
+	}
+
+	mapOfMetricsName = map[string]string{
+		RequestWaitingNum: utils.RequestWaitingNum,
+	}
+)
+
+type tritonEngine struct {
+	// The address of triton's query metrics is http://{model server}:MetricPort/metrics
+	// Default is 8002
+	MetricPort uint32
+}
+
+func NewTritonEngine() *tritonEngine {
+	return &tritonEngine{
+		MetricPort: 8002,
+	}
+}
+
+func (engine *tritonEngine) GetPodMetrics(pod *corev1.Pod) (map[string]*dto.MetricFamily, error) {
+	url := fmt.Sprintf("http://%s:%d/metrics", pod.Status.PodIP, engine.MetricPort)
+	allMetrics, err := metrics.ParseMetricsURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return allMetrics, nil
+}
+
+func (engine *tritonEngine) GetCountMetricsInfo(allMetrics map[string]*dto.MetricFamily) map[string]float64 {
+	wantMetrics := make(map[string]float64)
+	for _, metricName := range GaugeMetrics {
+		metricInfo, exist := allMetrics[metricName]
+		if !exist {
+			continue
+		}
+		for _, metric := range metricInfo.Metric {
+			wantMetrics[mapOfMetricsName[metricName]] += metric.GetGauge().GetValue()
+		}
+	}
+
+	return wantMetrics
+}
+
+// GetHistogramPodMetrics returns no metrics, triton doesn't report the latencies of the tokens.
+func (engine *tritonEngine) GetHistogramPodMetrics(allMetrics map[string]*dto.MetricFamily, previousHistogram map[string]*dto.Histogram) (map[string]float64, map[string]*dto.Histogram) {
+	return map[string]float64{}, map[string]*dto.Histogram{}
+}
+
+// GetPodModels returns no models, the models of triton are routed by the ModelRoutes only.
+func (engine *tritonEngine) GetPodModels(pod *corev1.Pod) ([]string, error) {
+	return nil, nil
+}
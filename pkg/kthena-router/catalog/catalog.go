@@ -0,0 +1,235 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package catalog records the metadata of the models served by the router. The metadata is discovered from the
+// ModelRoutes, ModelServers and pods of the models, and declared by annotations on their ModelServings.
+package catalog
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	workloadv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+)
+
+// Annotations of ModelServings declaring the metadata of the models they serve.
+const (
+	annotationPrefix = "catalog.serving.volcano.sh/"
+	// ContextLengthAnnotation is the maximum number of tokens of a request. The lowest of the context lengths of the
+	// ModelServings and the maxContextLength of the ModelServers of a model is reported.
+	ContextLengthAnnotation = annotationPrefix + "context-length"
+	// ModalitiesAnnotation is the comma separated list of the modalities of the model, e.g. "text,image".
+	ModalitiesAnnotation = annotationPrefix + "modalities"
+	// LicenseAnnotation is the license of the model, e.g. "apache-2.0".
+	LicenseAnnotation = annotationPrefix + "license"
+	// OwnerAnnotation is the team or person owning the model.
+	OwnerAnnotation = annotationPrefix + "owner"
+	// CostClassAnnotation is the cost class of the model, e.g. "premium".
+	CostClassAnnotation = annotationPrefix + "cost-class"
+	// EvalScoresAnnotation is the comma separated list of the evaluation scores of the model, e.g. "mmlu=0.71,gsm8k=0.52".
+	EvalScoresAnnotation = annotationPrefix + "eval-scores"
+)
+
+// defaultOwner owns the models which don't declare an owner.
+const defaultOwner = "kthena"
+
+// Model is the catalog entry of a served model. It is an OpenAI model object, extended with the metadata of the model.
+type Model struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+	// Parent is the base model of a LoRA adapter, empty for base models.
+	Parent string `json:"parent,omitempty"`
+	// MaxModelLen is the context length of the model, zero if unknown.
+	MaxModelLen int32              `json:"max_model_len,omitempty"`
+	Modalities  []string           `json:"modalities,omitempty"`
+	License     string             `json:"license,omitempty"`
+	CostClass   string             `json:"cost_class,omitempty"`
+	EvalScores  map[string]float64 `json:"eval_scores,omitempty"`
+	// ModelServers lists the ModelServers the model is routed to, as namespace/name.
+	ModelServers []string `json:"model_servers,omitempty"`
+	// ModelServings lists the ModelServings running the pods of the model, as namespace/name. Their annotations are
+	// applied in this order.
+	ModelServings []string `json:"model_servings,omitempty"`
+	// ReadyEndpoints is the number of pods serving the model.
+	ReadyEndpoints int `json:"ready_endpoints"`
+}
+
+// ModelList is the response of the /v1/models endpoint.
+type ModelList struct {
+	Object string  `json:"object"`
+	Data   []Model `json:"data"`
+}
+
+// Catalog lists the models served by the router.
+type Catalog struct {
+	store datastore.Store
+}
+
+func New(store datastore.Store) *Catalog {
+	return &Catalog{store: store}
+}
+
+// List returns the models served by the ModelRoutes, sorted by ID.
+func (c *Catalog) List() []Model {
+	models := map[string]*Model{}
+	for _, route := range c.store.GetAllModelRoutes() {
+		if route.Spec.ModelName != "" {
+			c.addRoute(models, route.Spec.ModelName, "", route)
+			for _, alias := range route.Spec.Aliases {
+				c.addRoute(models, alias, "", route)
+			}
+		}
+		for _, lora := range route.Spec.LoraAdapters {
+			c.addRoute(models, lora, route.Spec.ModelName, route)
+		}
+	}
+
+	result := make([]Model, 0, len(models))
+	for _, model := range models {
+		slices.Sort(model.ModelServers)
+		slices.Sort(model.ModelServings)
+		for _, serving := range model.ModelServings {
+			namespace, name, _ := strings.Cut(serving, "/")
+			if modelServing := c.store.GetModelServing(types.NamespacedName{Namespace: namespace, Name: name}); modelServing != nil {
+				model.applyAnnotations(modelServing)
+			}
+		}
+		if model.OwnedBy == "" {
+			model.OwnedBy = defaultOwner
+		}
+		result = append(result, *model)
+	}
+	slices.SortFunc(result, func(a, b Model) int { return strings.Compare(a.ID, b.ID) })
+	return result
+}
+
+// Get returns the model with id.
+func (c *Catalog) Get(id string) (Model, bool) {
+	for _, model := range c.List() {
+		if model.ID == id {
+			return model, true
+		}
+	}
+	return Model{}, false
+}
+
+// addRoute merges the metadata of the ModelServers the route sends the model to in the entry of the model.
+func (c *Catalog) addRoute(models map[string]*Model, id, parent string, route *aiv1alpha1.ModelRoute) {
+	model, ok := models[id]
+	if !ok {
+		model = &Model{ID: id, Object: "model", Created: route.CreationTimestamp.Unix(), Parent: parent}
+		models[id] = model
+	}
+	if created := route.CreationTimestamp.Unix(); created < model.Created {
+		model.Created = created
+	}
+
+	for _, rule := range route.Spec.Rules {
+		for _, target := range rule.TargetModels {
+			name := types.NamespacedName{Namespace: route.Namespace, Name: target.ModelServerName}
+			if slices.Contains(model.ModelServers, name.String()) {
+				continue
+			}
+			modelServer := c.store.GetModelServer(name)
+			if modelServer == nil {
+				continue
+			}
+			model.ModelServers = append(model.ModelServers, name.String())
+			if modelServer.Spec.MaxContextLength != nil {
+				model.MaxModelLen = minContextLength(model.MaxModelLen, *modelServer.Spec.MaxContextLength)
+			}
+
+			pods, _ := c.store.GetPodsByModelServer(name)
+			model.ReadyEndpoints += len(pods)
+			for _, pod := range pods {
+				servingName, ok := pod.Pod.Labels[workloadv1alpha1.ModelServingNameLabelKey]
+				if !ok {
+					continue
+				}
+				serving := types.NamespacedName{Namespace: pod.Pod.Namespace, Name: servingName}
+				if slices.Contains(model.ModelServings, serving.String()) {
+					continue
+				}
+				model.ModelServings = append(model.ModelServings, serving.String())
+			}
+		}
+	}
+}
+
+// applyAnnotations sets the metadata declared by the annotations of the ModelServing. When the ModelServings of a
+// model declare different values, the lowest context length is kept, and the value of the first one otherwise.
+func (m *Model) applyAnnotations(modelServing *workloadv1alpha1.ModelServing) {
+	annotations := modelServing.Annotations
+	if value, ok := annotations[ContextLengthAnnotation]; ok {
+		length, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || length <= 0 {
+			klog.Warningf("Invalid %s annotation %q of ModelServing %s/%s", ContextLengthAnnotation, value, modelServing.Namespace, modelServing.Name)
+		} else {
+			m.MaxModelLen = minContextLength(m.MaxModelLen, int32(length))
+		}
+	}
+	if value := annotations[ModalitiesAnnotation]; value != "" && len(m.Modalities) == 0 {
+		m.Modalities = splitList(value)
+	}
+	if value := annotations[LicenseAnnotation]; value != "" && m.License == "" {
+		m.License = value
+	}
+	if value := annotations[OwnerAnnotation]; value != "" && m.OwnedBy == "" {
+		m.OwnedBy = value
+	}
+	if value := annotations[CostClassAnnotation]; value != "" && m.CostClass == "" {
+		m.CostClass = value
+	}
+	for _, item := range splitList(annotations[EvalScoresAnnotation]) {
+		name, value, _ := strings.Cut(item, "=")
+		score, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			klog.Warningf("Invalid %s annotation item %q of ModelServing %s/%s", EvalScoresAnnotation, item, modelServing.Namespace, modelServing.Name)
+			continue
+		}
+		if m.EvalScores == nil {
+			m.EvalScores = map[string]float64{}
+		}
+		if _, ok := m.EvalScores[strings.TrimSpace(name)]; !ok {
+			m.EvalScores[strings.TrimSpace(name)] = score
+		}
+	}
+}
+
+func minContextLength(current, length int32) int32 {
+	if current == 0 || length < current {
+		return length
+	}
+	return current
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,175 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	workloadv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+)
+
+func newStore(t *testing.T) datastore.Store {
+	store := datastore.New()
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			Model:            ptr.To("llama-3-8b"),
+			InferenceEngine:  "vLLM",
+			MaxContextLength: ptr.To[int32](8192),
+		},
+	}
+	pods := sets.New[types.NamespacedName]()
+	for _, name := range []string{"llama-0", "llama-1"} {
+		pods.Insert(types.NamespacedName{Namespace: "default", Name: name})
+	}
+	require.NoError(t, store.AddOrUpdateModelServer(modelServer, pods))
+	for name := range pods {
+		require.NoError(t, store.AddOrUpdatePod(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name.Name, Namespace: "default", Labels: map[string]string{
+				workloadv1alpha1.ModelServingNameLabelKey: "llama",
+			}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}, []*aiv1alpha1.ModelServer{modelServer}))
+	}
+	require.NoError(t, store.AddOrUpdateModelServing(&workloadv1alpha1.ModelServing{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama", Namespace: "default", Annotations: map[string]string{
+			ContextLengthAnnotation: "4096",
+			ModalitiesAnnotation:    "text, image",
+			LicenseAnnotation:       "llama3",
+			OwnerAnnotation:         "ml-platform",
+			CostClassAnnotation:     "standard",
+			EvalScoresAnnotation:    "mmlu=0.66, gsm8k=invalid, humaneval=0.62",
+		}},
+	}))
+	require.NoError(t, store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama", Namespace: "default"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName:    "llama",
+			LoraAdapters: []string{"llama-sql"},
+			Rules: []*aiv1alpha1.Rule{{
+				TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "llama"}},
+			}},
+		},
+	}))
+	return store
+}
+
+func TestList(t *testing.T) {
+	models := New(newStore(t)).List()
+	require.Len(t, models, 2)
+
+	expected := Model{
+		ID:             "llama",
+		Object:         "model",
+		Created:        models[0].Created,
+		OwnedBy:        "ml-platform",
+		MaxModelLen:    4096,
+		Modalities:     []string{"text", "image"},
+		License:        "llama3",
+		CostClass:      "standard",
+		EvalScores:     map[string]float64{"mmlu": 0.66, "humaneval": 0.62},
+		ModelServers:   []string{"default/llama"},
+		ModelServings:  []string{"default/llama"},
+		ReadyEndpoints: 2,
+	}
+	assert.Equal(t, expected, models[0])
+	expected.ID, expected.Parent = "llama-sql", "llama"
+	assert.Equal(t, expected, models[1])
+}
+
+func TestListWithoutModelServing(t *testing.T) {
+	store := newStore(t)
+	require.NoError(t, store.DeleteModelServing(types.NamespacedName{Namespace: "default", Name: "llama"}))
+
+	model, ok := New(store).Get("llama")
+	require.True(t, ok)
+	assert.Equal(t, "kthena", model.OwnedBy)
+	assert.Equal(t, int32(8192), model.MaxModelLen)
+	assert.Empty(t, model.License)
+	assert.Equal(t, 2, model.ReadyEndpoints)
+}
+
+func TestHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := New(newStore(t)).Handler(nil)
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, path, nil)
+		handler(c)
+		return w
+	}
+
+	w := serve(http.MethodGet, "/v1/models")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list ModelList
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, "list", list.Object)
+	assert.Len(t, list.Data, 2)
+
+	w = serve(http.MethodGet, "/v1/models/llama-sql")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"parent":"llama"`)
+
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/v1/models/unknown").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodPost, "/v1/models").Code)
+
+	// The caller only sees the models it may call.
+	handler = New(newStore(t)).Handler(func(ctx *gin.Context, model string) error {
+		if model != "llama" {
+			return fmt.Errorf("model %s not allowed", model)
+		}
+		return nil
+	})
+	w = serve(http.MethodGet, "/v1/models")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, "llama", list.Data[0].ID)
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/v1/models/llama").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/v1/models/llama-sql").Code)
+}
+
+func TestListAliases(t *testing.T) {
+	store := newStore(t)
+	route := store.GetModelRoute("default/llama").DeepCopy()
+	route.Spec.Aliases = []string{"gpt-4o"}
+	require.NoError(t, store.AddOrUpdateModelRoute(route))
+
+	alias, ok := New(store).Get("gpt-4o")
+	require.True(t, ok)
+	model, ok := New(store).Get("llama")
+	require.True(t, ok)
+	model.ID = alias.ID
+	assert.Equal(t, model, alias)
+}
@@ -0,0 +1,61 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalog
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Path is the endpoint listing the served models, Path/<model> returns a single model.
+const Path = "/v1/models"
+
+// IsPath reports whether path is an endpoint of the catalog.
+func IsPath(path string) bool {
+	return path == Path || strings.HasPrefix(path, Path+"/")
+}
+
+// Handler serves the catalog endpoints:
+// GET Path lists the served models, GET Path/<model> returns the model, which may contain slashes.
+// The models the caller may not call, those for which authorize returns an error, are neither listed nor returned.
+// A nil authorize allows every model.
+func (c *Catalog) Handler(authorize func(ctx *gin.Context, model string) error) gin.HandlerFunc {
+	allowed := func(ctx *gin.Context, model string) bool {
+		return authorize == nil || authorize(ctx, model) == nil
+	}
+	return func(ctx *gin.Context) {
+		if ctx.Request.Method != http.MethodGet {
+			ctx.AbortWithStatusJSON(http.StatusMethodNotAllowed, gin.H{"message": "method not allowed"})
+			return
+		}
+		id := strings.TrimPrefix(strings.TrimPrefix(ctx.Request.URL.Path, Path), "/")
+		if id == "" {
+			models := slices.DeleteFunc(c.List(), func(model Model) bool { return !allowed(ctx, model.ID) })
+			ctx.JSON(http.StatusOK, ModelList{Object: "list", Data: models})
+			return
+		}
+		model, ok := c.Get(id)
+		if !ok || !allowed(ctx, id) {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "model " + id + " not found"})
+			return
+		}
+		ctx.JSON(http.StatusOK, model)
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package celmatch evaluates the CEL expressions matching the requests to the rules of the ModelRoutes, for the
+// conditions the static header, URI and body matches can't express.
+package celmatch
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+)
+
+// costLimit bounds the cost of evaluating an expression on a request, e.g. an expression iterating over the
+// messages of a huge conversation, so that a rule can't stall the routing.
+const costLimit = 1000000
+
+// Attributes are the attributes of a request an expression is evaluated over.
+type Attributes struct {
+	// Method and Path are the method and the path of the request.
+	Method string
+	Path   string
+	// Headers are the headers of the request, by lower case name. The values of a repeated header are joined with
+	// commas.
+	Headers map[string]string
+	// Model is the model the request is routed for.
+	Model string
+	// Body is the parsed JSON body of the request.
+	Body map[string]any
+	// Tokens is the number of input tokens of the request counted by the router, 0 if it doesn't count them.
+	Tokens int
+	// Consumer describes the caller of the request: its subject, the authenticator which authenticated it, and its
+	// team and org in the consumer quotas. The attributes unknown are empty.
+	Consumer map[string]string
+}
+
+var (
+	envOnce sync.Once
+	env     *cel.Env
+	envErr  error
+
+	// programs caches the compiled programs by expression, the expressions of the ModelRoutes being evaluated on
+	// every request they route.
+	programs sync.Map
+)
+
+// newEnv declares the variables of the expressions: request, with the fields method, path, headers, model, body
+// and tokens, and consumer.
+func newEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("request", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("consumer", cel.MapType(cel.StringType, cel.StringType)),
+		ext.Strings(),
+	)
+}
+
+// Compile compiles an expression, which must evaluate to a bool.
+func Compile(expression string) (cel.Program, error) {
+	if program, ok := programs.Load(expression); ok {
+		return program.(cel.Program), nil
+	}
+	envOnce.Do(func() {
+		env, envErr = newEnv()
+	})
+	if envErr != nil {
+		return nil, envErr
+	}
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("expression must evaluate to a bool, not %v", ast.OutputType())
+	}
+	program, err := env.Program(ast, cel.CostLimit(costLimit))
+	if err != nil {
+		return nil, err
+	}
+	programs.Store(expression, program)
+	return program, nil
+}
+
+// Match reports whether the request of the attributes matches the expression. An expression which fails to
+// evaluate, e.g. because it reads a body field the request doesn't have, doesn't match.
+func Match(expression string, attributes *Attributes) (bool, error) {
+	program, err := Compile(expression)
+	if err != nil {
+		return false, err
+	}
+	// The attributes of the consumers are always declared, so that the expressions reading them don't fail on the
+	// requests of unknown consumers.
+	consumer := map[string]string{"subject": "", "authenticator": "", "team": "", "org": ""}
+	for name, value := range attributes.Consumer {
+		consumer[name] = value
+	}
+	body := attributes.Body
+	if body == nil {
+		body = map[string]any{}
+	}
+	headers := attributes.Headers
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	result, _, err := program.Eval(map[string]any{
+		"request": map[string]any{
+			"method":  attributes.Method,
+			"path":    attributes.Path,
+			"headers": headers,
+			"model":   attributes.Model,
+			"body":    body,
+			"tokens":  attributes.Tokens,
+		},
+		"consumer": consumer,
+	})
+	if err != nil {
+		return false, err
+	}
+	matched, ok := result.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression evaluated to %v, not a bool", result.Type())
+	}
+	return matched, nil
+}
+
+// Headers returns the headers of a request by lower case name, the values of a repeated header joined with commas.
+func Headers(header map[string][]string) map[string]string {
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	return headers
+}
@@ -0,0 +1,171 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package celmatch
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chatRequest is a tool calling chat request of a consumer of the research team, as parsed by the router.
+var chatRequest = &Attributes{
+	Method:  http.MethodPost,
+	Path:    "/v1/chat/completions",
+	Headers: Headers(http.Header{"X-Tenant": {"acme"}, "User-Agent": {"openai-python/1.40.0"}}),
+	Model:   "llama-3-70b",
+	Body: map[string]any{
+		"model":  "llama-3-70b",
+		"stream": true,
+		"messages": []any{
+			map[string]any{"role": "system", "content": "You are a helpful assistant."},
+			map[string]any{"role": "user", "content": "What's the weather in Paris?"},
+		},
+		"tools":    []any{map[string]any{"type": "function", "function": map[string]any{"name": "get_weather"}}},
+		"metadata": map[string]any{"priority": "high"},
+	},
+	Tokens:   5200,
+	Consumer: map[string]string{"subject": "alice", "authenticator": "jwt", "team": "research", "org": "acme"},
+}
+
+// TestExamples tests the example expressions of the documentation, they must be kept in sync.
+func TestExamples(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		want       bool
+	}{
+		{
+			name:       "long prompts",
+			expression: `request.tokens > 4000`,
+			want:       true,
+		},
+		{
+			name:       "long prompts of a team",
+			expression: `request.tokens > 4000 && consumer.team == "research"`,
+			want:       true,
+		},
+		{
+			name:       "tool calling requests",
+			expression: `has(request.body.tools) && size(request.body.tools) > 0`,
+			want:       true,
+		},
+		{
+			name:       "calls of a given function",
+			expression: `has(request.body.tools) && request.body.tools.exists(t, t.function.name == "get_weather")`,
+			want:       true,
+		},
+		{
+			name:       "long conversations",
+			expression: `has(request.body.messages) && size(request.body.messages) > 10`,
+			want:       false,
+		},
+		{
+			name:       "streamed requests",
+			expression: `has(request.body.stream) && request.body.stream == true`,
+			want:       true,
+		},
+		{
+			name:       "requests with a system prompt",
+			expression: `has(request.body.messages) && request.body.messages.exists(m, m.role == "system")`,
+			want:       true,
+		},
+		{
+			name:       "high priority metadata",
+			expression: `has(request.body.metadata) && request.body.metadata.priority == "high"`,
+			want:       true,
+		},
+		{
+			name:       "header of a tenant",
+			expression: `"x-tenant" in request.headers && request.headers["x-tenant"] in ["acme", "globex"]`,
+			want:       true,
+		},
+		{
+			name:       "clients by user agent",
+			expression: `request.headers["user-agent"].startsWith("openai-python/")`,
+			want:       true,
+		},
+		{
+			name:       "consumers authenticated with an API key",
+			expression: `consumer.authenticator == "apiKey"`,
+			want:       false,
+		},
+		{
+			name:       "consumers of an org but a team",
+			expression: `consumer.org == "acme" && consumer.team != "research"`,
+			want:       false,
+		},
+		{
+			name:       "prompts mentioning code",
+			expression: `request.body.messages.exists(m, m.role == "user" && m.content.lowerAscii().contains("python"))`,
+			want:       false,
+		},
+		{
+			name:       "consumers by the initial of their subject",
+			expression: `consumer.subject.size() > 0 && consumer.subject.charAt(0) in ["a", "b"]`,
+			want:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, err := Match(tt.expression, chatRequest)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, matched)
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		wantErr    string
+	}{
+		{name: "bool", expression: `request.path == "/v1/completions"`},
+		{name: "dyn", expression: `request.body.stream`},
+		{name: "syntax error", expression: `request.tokens >`, wantErr: "Syntax error"},
+		{name: "undeclared variable", expression: `user.name == "alice"`, wantErr: "undeclared reference to 'user'"},
+		{name: "not a bool", expression: `consumer.team`, wantErr: "expression must evaluate to a bool, not string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.expression)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestMatch_EvaluationErrors(t *testing.T) {
+	request := &Attributes{Path: "/v1/completions", Body: map[string]any{"prompt": "hi"}}
+	// A body field the request doesn't have fails to evaluate.
+	_, err := Match(`request.body.messages.size() > 1`, request)
+	assert.Error(t, err)
+	// A dyn expression not evaluating to a bool fails.
+	_, err = Match(`request.body.prompt`, request)
+	assert.Error(t, err)
+	// The attributes of an unknown consumer and the headers of a request without them are empty.
+	matched, err := Match(`consumer.team == "" && request.headers.size() == 0 && request.tokens == 0`, request)
+	require.NoError(t, err)
+	assert.True(t, matched)
+}
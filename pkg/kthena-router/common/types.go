@@ -19,6 +19,9 @@ package common
 const (
 	UserIdKey     = "user_id"
 	TokenUsageKey = "token_usage"
+	// OutputTokensChargeKey is the context key of the func(tokens int) charging the output tokens streamed so far to
+	// the rate limits of the request.
+	OutputTokensChargeKey = "output_tokens_charge"
 )
 
 // Message represents a single message in a chat conversation
@@ -32,6 +35,20 @@ type ChatMessage struct {
 	// Text is used for direct prompt input (completion mode)
 	Text string `json:"text,omitempty"`
 
+	// TokenIDs is the number of tokens of the prompts given as token ids (completion mode), which have no text
+	TokenIDs int `json:"-"`
+
 	// Messages is used for chat conversation input (chat mode)
 	Messages []Message `json:"messages,omitempty"`
+
+	// Images are the images of the content of the messages (chat mode), which have no text
+	Images []Image `json:"-"`
+}
+
+// Image is an image part of the content of a chat message.
+type Image struct {
+	// URL is the URL of the image, or the data URL of its base64 encoded bytes.
+	URL string
+	// Detail is the detail the image is processed with: low, high or auto. Empty if the request doesn't set it.
+	Detail string
 }
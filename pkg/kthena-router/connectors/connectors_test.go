@@ -529,3 +529,33 @@ func TestHTTPConnectorProxy(t *testing.T) {
 		}
 	})
 }
+
+func TestOnPrefillDone(t *testing.T) {
+	for _, connector := range []KVConnector{NewHTTPConnector(), NewNIXLConnector()} {
+		t.Run(connector.Name(), func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/v1/completions", nil)
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = req
+
+			calls := 0
+			var prefillErr error
+			OnPrefillDone(c, func(err error) {
+				calls++
+				prefillErr = err
+			})
+			reqBody := map[string]interface{}{
+				"model":  "test-model",
+				"prompt": "test prompt",
+			}
+			if _, err := connector.Proxy(c, reqBody, "127.0.0.1:1", "127.0.0.1:2"); err == nil {
+				t.Fatal("Expected Proxy to fail on the unreachable prefill pod")
+			}
+			if calls != 1 {
+				t.Errorf("Expected the prefill callback to be called once, got %d", calls)
+			}
+			if prefillErr == nil {
+				t.Error("Expected the prefill callback to get the prefill error")
+			}
+		})
+	}
+}
@@ -87,6 +87,7 @@ func (h *HTTPConnector) Proxy(c *gin.Context, reqBody map[string]interface{}, pr
 	}
 
 	err := h.prefill(h.prefillRequest, prefillAddr)
+	prefillDone(c, err)
 
 	// End prefill phase metrics and handle upstream requests
 	if metricsRecorder != nil {
@@ -29,3 +29,30 @@ type KVConnector interface {
 	// Returns the number of output tokens consumed, or error if the operation fails
 	Proxy(c *gin.Context, reqBody map[string]interface{}, prefillAddr, decodeAddr string) (int, error)
 }
+
+// prefillDoneKey is the key of the callback run when the prefill of the request completed in its gin context.
+const prefillDoneKey = "prefillDone"
+
+// OnPrefillDone registers f to be called by the connectors once the prefill of the request completed, before its
+// decode starts, with the error of the prefill. The callbacks are called in the order they are registered.
+func OnPrefillDone(c *gin.Context, f func(err error)) {
+	if value, exists := c.Get(prefillDoneKey); exists {
+		if previous, ok := value.(func(error)); ok {
+			next := f
+			f = func(err error) {
+				previous(err)
+				next(err)
+			}
+		}
+	}
+	c.Set(prefillDoneKey, f)
+}
+
+// prefillDone runs the callback registered with OnPrefillDone, if any.
+func prefillDone(c *gin.Context, err error) {
+	if value, exists := c.Get(prefillDoneKey); exists {
+		if f, ok := value.(func(error)); ok {
+			f(err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connectors
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	streamDataPrefix = []byte("data:")
+	streamDone       = []byte("[DONE]")
+
+	// errStreamTruncated is the cause of a decode stream which ended without its [DONE] event, the decode pod has
+	// either closed the connection or the response could not be read anymore.
+	errStreamTruncated = errors.New("decode stream ended before [DONE]")
+)
+
+// DecodeInterruptedError is returned when the stream of a decode pod broke after its status has been forwarded to
+// the client. It holds the completion forwarded so far, the generation can be resumed from it on another
+// prefill/decode pair.
+type DecodeInterruptedError struct {
+	// Generated is the text of the completion forwarded to the client.
+	Generated string
+	// Tokens is the number of tokens forwarded to the client, one per content event.
+	Tokens int
+	// Finished is set if the model server finished the generation, only the end of the stream was lost.
+	Finished bool
+	// Resumable is cleared if the completion can't be continued from its text, i.e. it has tool calls or several
+	// choices.
+	Resumable bool
+}
+
+func (e *DecodeInterruptedError) Error() string {
+	return fmt.Sprintf("%v after %d tokens", errStreamTruncated, e.Tokens)
+}
+
+func (e *DecodeInterruptedError) Unwrap() error {
+	return errStreamTruncated
+}
+
+// streamProgress follows the completion of a decode stream forwarded to the client.
+type streamProgress struct {
+	generated bytes.Buffer
+	tokens    int
+	finished  bool
+	done      bool
+	resumable bool
+}
+
+type streamEvent struct {
+	Choices []struct {
+		Index int `json:"index"`
+		// Text is the content of a completion event.
+		Text  string `json:"text"`
+		Delta struct {
+			Content   string          `json:"content"`
+			ToolCalls json.RawMessage `json:"tool_calls"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+}
+
+func newStreamProgress() *streamProgress {
+	return &streamProgress{resumable: true}
+}
+
+// observe records a line of the stream forwarded to the client.
+func (p *streamProgress) observe(line []byte) {
+	data, ok := bytes.CutPrefix(bytes.TrimSpace(line), streamDataPrefix)
+	if !ok {
+		return
+	}
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, streamDone) {
+		p.done = true
+		return
+	}
+	var event streamEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return
+	}
+	for _, choice := range event.Choices {
+		if choice.Index != 0 || len(choice.Delta.ToolCalls) > 0 {
+			p.resumable = false
+			continue
+		}
+		content := choice.Delta.Content + choice.Text
+		if content != "" {
+			p.generated.WriteString(content)
+			p.tokens++
+		}
+		if choice.FinishReason != nil && *choice.FinishReason != "" {
+			p.finished = true
+		}
+	}
+}
+
+// interruption returns the error of a stream which ended with err, nil if it completed.
+func (p *streamProgress) interruption(err error) error {
+	if err != nil || p.done {
+		return err
+	}
+	return &DecodeInterruptedError{
+		Generated: p.generated.String(),
+		Tokens:    p.tokens,
+		Finished:  p.finished,
+		Resumable: p.resumable,
+	}
+}
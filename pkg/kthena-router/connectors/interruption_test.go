@@ -0,0 +1,103 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connectors
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoderProxy_Interruption(t *testing.T) {
+	const (
+		hello    = `data: {"choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"}}]}`
+		world    = `data: {"choices":[{"index":0,"delta":{"content":" world"}}]}`
+		finish   = `data: {"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`
+		toolCall = `data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"name":"f"}}]}}]}`
+		done     = `data: [DONE]`
+	)
+	tests := []struct {
+		name    string
+		events  []string
+		abort   bool
+		wantErr *DecodeInterruptedError
+	}{
+		{
+			name:   "complete stream",
+			events: []string{hello, world, finish, done},
+		},
+		{
+			name:    "decode pod dies midway",
+			events:  []string{hello, world},
+			abort:   true,
+			wantErr: &DecodeInterruptedError{Generated: "Hello world", Tokens: 2, Resumable: true},
+		},
+		{
+			name:    "stream closed without [DONE]",
+			events:  []string{hello},
+			wantErr: &DecodeInterruptedError{Generated: "Hello", Tokens: 1, Resumable: true},
+		},
+		{
+			name:    "generation finished",
+			events:  []string{hello, world, finish},
+			abort:   true,
+			wantErr: &DecodeInterruptedError{Generated: "Hello world", Tokens: 2, Finished: true, Resumable: true},
+		},
+		{
+			name:    "tool calls",
+			events:  []string{hello, toolCall},
+			abort:   true,
+			wantErr: &DecodeInterruptedError{Generated: "Hello", Tokens: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				for _, event := range tt.events {
+					fmt.Fprintf(w, "%s\n\n", event)
+				}
+				w.(http.Flusher).Flush()
+				if tt.abort {
+					panic(http.ErrAbortHandler)
+				}
+			}))
+			defer decoder.Close()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+			req, _ := http.NewRequest(http.MethodPost, decoder.URL+"/v1/chat/completions", nil)
+			_, err := decoderProxy(c, req)
+
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			var interrupted *DecodeInterruptedError
+			require.ErrorAs(t, err, &interrupted)
+			assert.Equal(t, tt.wantErr, interrupted)
+			assert.ErrorIs(t, err, errStreamTruncated)
+			assert.Contains(t, w.Body.String(), "Hello")
+		})
+	}
+}
@@ -77,6 +77,7 @@ func (n *NIXLConnector) Proxy(c *gin.Context, reqBody map[string]interface{}, pr
 
 	// 1. send prefill request
 	kvTransferParams, err := n.prefill(n.prefillRequest, prefillAddr)
+	prefillDone(c, err)
 
 	// End prefill phase metrics and handle upstream requests
 	if metricsRecorder != nil {
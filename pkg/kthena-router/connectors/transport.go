@@ -17,7 +17,6 @@ limitations under the License.
 package connectors
 
 import (
-	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
@@ -57,11 +56,7 @@ func decoderProxy(c *gin.Context, req *http.Request) (int, error) {
 	}
 
 	// Copy response headers
-	for k, vv := range resp.Header {
-		for _, v := range vv {
-			c.Header(k, v)
-		}
-	}
+	handlers.CopyResponseHeaders(c, resp.Header)
 
 	c.Status(resp.StatusCode)
 
@@ -118,6 +113,10 @@ func BuildDecodeRequest(c *gin.Context, req *http.Request, modelRequest map[stri
 	req.URL.Scheme = "http"
 	req.Body = io.NopCloser(bytes.NewBuffer(body))
 	req.ContentLength = int64(len(body))
+	// The request is sent again when it is retried on another pod.
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
 
 	return req
 }
@@ -175,33 +174,28 @@ func isStreamingResponse(resp *http.Response) bool {
 // handleStreamingResponse handles streaming responses
 func handleStreamingResponse(c *gin.Context, resp *http.Response) (int, error) {
 	totalOutputTokens := 0
-	reader := bufio.NewReader(resp.Body)
-	c.Stream(func(w io.Writer) bool {
-		line, err := reader.ReadBytes('\n')
-		if len(line) > 0 {
+	progress := newStreamProgress()
+	tokens := handlers.NewStreamTokenCounter(c)
+	err := handlers.ForwardStream(c, resp.Body, handlers.StreamOptions{
+		OnLine: func(line []byte) []byte {
+			progress.observe(line)
 			// Try to parse usage from this line
 			parsed := handlers.ParseStreamRespForUsage(string(line))
+			tokens.Observe(parsed)
 			if parsed.Usage.CompletionTokens > 0 {
 				klog.V(4).Infof("Parsed usage: %+v", parsed.Usage)
 				// Accumulate output tokens
 				totalOutputTokens += parsed.Usage.CompletionTokens
 				// Check if token usage should be filtered
 				if v, ok := c.Get(common.TokenUsageKey); ok && v.(bool) {
-					return true
+					return nil
 				}
 			}
-			// Forward to downstream
-			_, _ = w.Write(line)
-		}
-		if err != nil {
-			if err != io.EOF {
-				klog.Errorf("error reading stream body: %v", err)
-			}
-			return false
-		}
-		return true
+			return line
+		},
 	})
-	return totalOutputTokens, nil
+	// A broken upstream stream ends without an error, it is detected by its missing [DONE] event.
+	return totalOutputTokens, progress.interruption(err)
 }
 
 // handleNonStreamingResponse handles non-streaming responses
@@ -0,0 +1,179 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	gatewayinformers "sigs.k8s.io/gateway-api/pkg/client/informers/externalversions"
+	gatewaylisters "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+)
+
+type GRPCRouteController struct {
+	grpcRouteLister gatewaylisters.GRPCRouteLister
+	grpcRouteSynced cache.InformerSynced
+	registration    cache.ResourceEventHandlerRegistration
+
+	workqueue   workqueue.TypedRateLimitingInterface[any]
+	initialSync *atomic.Bool
+	store       datastore.Store
+}
+
+func NewGRPCRouteController(
+	gatewayInformerFactory gatewayinformers.SharedInformerFactory,
+	store datastore.Store,
+) *GRPCRouteController {
+	grpcRouteInformer := gatewayInformerFactory.Gateway().V1().GRPCRoutes()
+
+	controller := &GRPCRouteController{
+		grpcRouteLister: grpcRouteInformer.Lister(),
+		grpcRouteSynced: grpcRouteInformer.Informer().HasSynced,
+		workqueue:       workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[any]()),
+		initialSync:     &atomic.Bool{},
+		store:           store,
+	}
+
+	controller.registration, _ = grpcRouteInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.enqueueGRPCRoute,
+		UpdateFunc: func(old, new interface{}) { controller.enqueueGRPCRoute(new) },
+		DeleteFunc: controller.enqueueGRPCRoute,
+	})
+	// The GRPCRoutes are processed again when their Gateways change, e.g. when a route was created before its
+	// Gateway.
+	store.RegisterCallback("Gateway", controller.onGatewayChange)
+
+	return controller
+}
+
+func (c *GRPCRouteController) Run(stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+	defer c.workqueue.ShutDown()
+
+	if ok := cache.WaitForCacheSync(stopCh, c.grpcRouteSynced); !ok {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+	c.workqueue.Add(initialSyncSignal)
+
+	go wait.Until(c.runWorker, time.Second, stopCh)
+
+	<-stopCh
+	return nil
+}
+
+func (c *GRPCRouteController) HasSynced() bool {
+	return c.initialSync.Load()
+}
+
+func (c *GRPCRouteController) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *GRPCRouteController) processNextWorkItem() bool {
+	obj, shutdown := c.workqueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.workqueue.Done(obj)
+
+	if obj == initialSyncSignal {
+		klog.V(2).Info("initial grpc routes have been synced")
+		c.workqueue.Forget(obj)
+		c.initialSync.Store(true)
+		return true
+	}
+
+	var key string
+	var ok bool
+	if key, ok = obj.(string); !ok {
+		c.workqueue.Forget(obj)
+		utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+		return true
+	}
+
+	if err := c.syncHandler(key); err != nil {
+		if c.workqueue.NumRequeues(key) < maxRetries {
+			klog.Errorf("error syncing grpcroute %q: %s, requeuing", key, err.Error())
+			c.workqueue.AddRateLimited(key)
+			return true
+		}
+		klog.Errorf("giving up on syncing grpcroute %q after %d retries: %s", key, maxRetries, err)
+		c.workqueue.Forget(obj)
+	}
+	return true
+}
+
+func (c *GRPCRouteController) syncHandler(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+		return nil
+	}
+
+	grpcRoute, err := c.grpcRouteLister.GRPCRoutes(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		_ = c.store.DeleteGRPCRoute(key)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Only process GRPCRoutes that reference a Gateway of the kthena-router GatewayClass, the routes no longer
+	// referencing one are removed from the store.
+	if !referencesKthenaGateway(c.store, grpcRoute.Namespace, grpcRoute.Spec.ParentRefs) {
+		klog.V(4).Infof("Skipping GRPCRoute %s/%s: does not reference kthena-router Gateway", namespace, name)
+		_ = c.store.DeleteGRPCRoute(key)
+		return nil
+	}
+
+	return c.store.AddOrUpdateGRPCRoute(grpcRoute)
+}
+
+func (c *GRPCRouteController) onGatewayChange(data datastore.EventData) {
+	routes, err := c.grpcRouteLister.List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	gatewayKey := data.Gateway.String()
+	for _, route := range routes {
+		if referencesGateway(route.Namespace, route.Spec.ParentRefs, gatewayKey) {
+			c.enqueueGRPCRoute(route)
+		}
+	}
+}
+
+func (c *GRPCRouteController) enqueueGRPCRoute(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.workqueue.Add(key)
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
@@ -62,6 +63,9 @@ func NewHTTPRouteController(
 		UpdateFunc: func(old, new interface{}) { controller.enqueueHTTPRoute(new) },
 		DeleteFunc: controller.enqueueHTTPRoute,
 	})
+	// The HTTPRoutes are processed again when their Gateways change, e.g. when a route was created before its
+	// Gateway.
+	store.RegisterCallback("Gateway", controller.onGatewayChange)
 
 	return controller
 }
@@ -140,34 +144,31 @@ func (c *HTTPRouteController) syncHandler(key string) error {
 		return err
 	}
 
-	// Only process HTTPRoutes that reference kthena-router GatewayClass
-	// Check if any parentRef references a Gateway with kthena-router GatewayClass
-	shouldProcess := false
-	for _, parentRef := range httpRoute.Spec.ParentRefs {
-		if parentRef.Kind != nil && *parentRef.Kind == "Gateway" {
-			gatewayNamespace := httpRoute.Namespace
-			if parentRef.Namespace != nil {
-				gatewayNamespace = string(*parentRef.Namespace)
-			}
-			gatewayKey := fmt.Sprintf("%s/%s", gatewayNamespace, string(parentRef.Name))
-			gw := c.store.GetGateway(gatewayKey)
-			if gw != nil && gw.Spec.GatewayClassName != "" {
-				if string(gw.Spec.GatewayClassName) == DefaultGatewayClassName {
-					shouldProcess = true
-					break
-				}
-			}
-		}
-	}
-
-	if !shouldProcess {
+	// Only process HTTPRoutes that reference a Gateway of the kthena-router GatewayClass, the routes no longer
+	// referencing one are removed from the store.
+	if !referencesKthenaGateway(c.store, httpRoute.Namespace, httpRoute.Spec.ParentRefs) {
 		klog.V(4).Infof("Skipping HTTPRoute %s/%s: does not reference kthena-router Gateway", namespace, name)
+		_ = c.store.DeleteHTTPRoute(key)
 		return nil
 	}
 
 	return c.store.AddOrUpdateHTTPRoute(httpRoute)
 }
 
+func (c *HTTPRouteController) onGatewayChange(data datastore.EventData) {
+	routes, err := c.httpRouteLister.List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	gatewayKey := data.Gateway.String()
+	for _, route := range routes {
+		if referencesGateway(route.Namespace, route.Spec.ParentRefs, gatewayKey) {
+			c.enqueueHTTPRoute(route)
+		}
+	}
+}
+
 func (c *HTTPRouteController) enqueueHTTPRoute(obj interface{}) {
 	key, err := cache.MetaNamespaceKeyFunc(obj)
 	if err != nil {
@@ -21,22 +21,22 @@ import (
 	"sync/atomic"
 	"time"
 
-	"k8s.io/apimachinery/pkg/runtime"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
-	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
+	inferenceinformers "sigs.k8s.io/gateway-api-inference-extension/client-go/informers/externalversions"
+	inferencelisters "sigs.k8s.io/gateway-api-inference-extension/client-go/listers/api/v1"
 
 	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
-	inferencev1 "sigs.k8s.io/gateway-api-inference-extension/api/v1"
 )
 
 type InferencePoolController struct {
-	inferencePoolInformer cache.SharedIndexInformer
-	inferencePoolSynced   cache.InformerSynced
-	registration          cache.ResourceEventHandlerRegistration
+	inferencePoolLister inferencelisters.InferencePoolLister
+	inferencePoolSynced cache.InformerSynced
+	registration        cache.ResourceEventHandlerRegistration
 
 	workqueue   workqueue.TypedRateLimitingInterface[any]
 	initialSync *atomic.Bool
@@ -44,21 +44,20 @@ type InferencePoolController struct {
 }
 
 func NewInferencePoolController(
-	dynamicInformerFactory dynamicinformer.DynamicSharedInformerFactory,
+	inferenceInformerFactory inferenceinformers.SharedInformerFactory,
 	store datastore.Store,
 ) *InferencePoolController {
-	gvr := inferencev1.SchemeGroupVersion.WithResource("inferencepools")
-	inferencePoolInformer := dynamicInformerFactory.ForResource(gvr).Informer()
+	inferencePoolInformer := inferenceInformerFactory.Inference().V1().InferencePools()
 
 	controller := &InferencePoolController{
-		inferencePoolInformer: inferencePoolInformer,
-		inferencePoolSynced:   inferencePoolInformer.HasSynced,
-		workqueue:             workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[any]()),
-		initialSync:           &atomic.Bool{},
-		store:                 store,
+		inferencePoolLister: inferencePoolInformer.Lister(),
+		inferencePoolSynced: inferencePoolInformer.Informer().HasSynced,
+		workqueue:           workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[any]()),
+		initialSync:         &atomic.Bool{},
+		store:               store,
 	}
 
-	controller.registration, _ = inferencePoolInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+	controller.registration, _ = inferencePoolInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc:    controller.enqueueInferencePool,
 		UpdateFunc: func(old, new interface{}) { controller.enqueueInferencePool(new) },
 		DeleteFunc: controller.enqueueInferencePool,
@@ -126,29 +125,19 @@ func (c *InferencePoolController) processNextWorkItem() bool {
 }
 
 func (c *InferencePoolController) syncHandler(key string) error {
-	_, _, err := cache.SplitMetaNamespaceKey(key)
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
 		utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
 		return nil
 	}
 
-	obj, exists, err := c.inferencePoolInformer.GetIndexer().GetByKey(key)
-	if err != nil {
-		return err
-	}
-	if !exists {
+	inferencePool, err := c.inferencePoolLister.InferencePools(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
 		_ = c.store.DeleteInferencePool(key)
 		return nil
 	}
-
-	unstructuredObj, ok := obj.(runtime.Unstructured)
-	if !ok {
-		return fmt.Errorf("invalid object type: %T", obj)
-	}
-
-	inferencePool := &inferencev1.InferencePool{}
-	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredObj.UnstructuredContent(), inferencePool); err != nil {
-		return fmt.Errorf("failed to convert unstructured to InferencePool: %w", err)
+	if err != nil {
+		return err
 	}
 
 	return c.store.AddOrUpdateInferencePool(inferencePool)
@@ -0,0 +1,64 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	inferencev1 "sigs.k8s.io/gateway-api-inference-extension/api/v1"
+	inferencefake "sigs.k8s.io/gateway-api-inference-extension/client-go/clientset/versioned/fake"
+	inferenceinformers "sigs.k8s.io/gateway-api-inference-extension/client-go/informers/externalversions"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+)
+
+func TestInferencePoolController_SyncHandler(t *testing.T) {
+	pool := &inferencev1.InferencePool{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pool"},
+		Spec: inferencev1.InferencePoolSpec{
+			Selector:    inferencev1.LabelSelector{MatchLabels: map[inferencev1.LabelKey]inferencev1.LabelValue{"app": "llm"}},
+			TargetPorts: []inferencev1.Port{{Number: 8000}},
+		},
+	}
+	client := inferencefake.NewSimpleClientset(pool)
+	informerFactory := inferenceinformers.NewSharedInformerFactory(client, 0)
+	store := datastore.New()
+	controller := NewInferencePoolController(informerFactory, store)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	informerFactory.Start(stop)
+	require.True(t, waitForCacheSync(t, 5*time.Second, controller.inferencePoolSynced))
+
+	require.NoError(t, controller.syncHandler("default/pool"))
+	stored := store.GetInferencePool("default/pool")
+	require.NotNil(t, stored)
+	assert.Equal(t, inferencev1.PortNumber(8000), stored.Spec.TargetPorts[0].Number)
+
+	require.NoError(t, client.InferenceV1().InferencePools("default").Delete(context.Background(), "pool", metav1.DeleteOptions{}))
+	require.True(t, waitForObjectInCache(t, 2*time.Second, func() bool {
+		_, err := controller.inferencePoolLister.InferencePools("default").Get("pool")
+		return err != nil
+	}))
+	require.NoError(t, controller.syncHandler("default/pool"))
+	assert.Nil(t, store.GetInferencePool("default/pool"))
+}
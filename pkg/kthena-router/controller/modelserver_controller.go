@@ -23,6 +23,7 @@ import (
 
 	"istio.io/istio/pkg/util/sets"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -31,6 +32,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
 	corelisters "k8s.io/client-go/listers/core/v1"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
@@ -39,6 +41,7 @@ import (
 	listerv1alpha1 "github.com/volcano-sh/kthena/client-go/listers/networking/v1alpha1"
 	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/discovery"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/utils"
 )
 
@@ -62,6 +65,8 @@ type ModelServerController struct {
 
 	modelServerSynced cache.InformerSynced
 	podSynced         cache.InformerSynced
+	// endpointSliceSynced is nil when the EndpointSlices are not watched.
+	endpointSliceSynced cache.InformerSynced
 
 	// Event handler registrations
 	modelServerRegistration cache.ResourceEventHandlerRegistration
@@ -70,11 +75,16 @@ type ModelServerController struct {
 	workqueue   workqueue.TypedRateLimitingInterface[QueueItem]
 	initialSync *atomic.Bool
 	store       datastore.Store
+	// discovery finds the instances of the ModelServers with discovery, which are not pods.
+	discovery *discovery.Manager
 }
 
+// NewModelServerController returns the controller of the ModelServers and of their pods. endpointSliceInformerFactory
+// is nil when the EndpointSlices are not watched, the ModelServers discovering them then have no instances.
 func NewModelServerController(
 	kthenaInformerFactory informersv1alpha1.SharedInformerFactory,
 	kubeInformerFactory informers.SharedInformerFactory,
+	endpointSliceInformerFactory informers.SharedInformerFactory,
 	store datastore.Store,
 ) *ModelServerController {
 	modelServerInformer := kthenaInformerFactory.Networking().V1alpha1().ModelServers()
@@ -90,6 +100,21 @@ func NewModelServerController(
 		store:             store,
 	}
 
+	var endpointSliceLister discoverylisters.EndpointSliceLister
+	if endpointSliceInformerFactory != nil {
+		endpointSliceInformer := endpointSliceInformerFactory.Discovery().V1().EndpointSlices()
+		endpointSliceLister = endpointSliceInformer.Lister()
+		controller.endpointSliceSynced = endpointSliceInformer.Informer().HasSynced
+		_, _ = endpointSliceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: controller.refreshEndpointSlice,
+			UpdateFunc: func(old, new interface{}) {
+				controller.refreshEndpointSlice(new)
+			},
+			DeleteFunc: controller.refreshEndpointSlice,
+		})
+	}
+	controller.discovery = discovery.NewManager(store, endpointSliceLister)
+
 	// Register ModelServer event handlers
 	controller.modelServerRegistration, _ = modelServerInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: controller.enqueueModelServer,
@@ -115,7 +140,11 @@ func (c *ModelServerController) Run(stopCh <-chan struct{}) error {
 	defer utilruntime.HandleCrash()
 	defer c.workqueue.ShutDown()
 
-	if ok := cache.WaitForCacheSync(stopCh, c.modelServerSynced, c.podSynced); !ok {
+	synced := []cache.InformerSynced{c.modelServerSynced, c.podSynced}
+	if c.endpointSliceSynced != nil {
+		synced = append(synced, c.endpointSliceSynced)
+	}
+	if ok := cache.WaitForCacheSync(stopCh, synced...); !ok {
 		return fmt.Errorf("failed to wait for caches to sync")
 	}
 	// add initialSync signal
@@ -124,6 +153,7 @@ func (c *ModelServerController) Run(stopCh <-chan struct{}) error {
 	go wait.Until(c.runWorker, time.Second, stopCh)
 
 	<-stopCh
+	c.discovery.StopAll()
 	return nil
 }
 
@@ -184,6 +214,7 @@ func (c *ModelServerController) syncModelServerHandler(key string) error {
 
 	ms, err := c.modelServerLister.ModelServers(namespace).Get(name)
 	if errors.IsNotFound(err) {
+		c.discovery.Stop(types.NamespacedName{Namespace: namespace, Name: name})
 		_ = c.store.DeleteModelServer(types.NamespacedName{Namespace: namespace, Name: name})
 		return nil
 	}
@@ -191,7 +222,12 @@ func (c *ModelServerController) syncModelServerHandler(key string) error {
 		return err
 	}
 
-	selector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{MatchLabels: ms.Spec.WorkloadSelector.MatchLabels})
+	if ms.Spec.Discovery == nil {
+		// The discovery of the model server may have been replaced by a workload selector
+		c.discovery.Stop(utils.GetNamespaceName(ms))
+	}
+
+	selector, err := workloadSelector(ms)
 	if err != nil {
 		return fmt.Errorf("invalid selector: %v", err)
 	}
@@ -208,8 +244,28 @@ func (c *ModelServerController) syncModelServerHandler(key string) error {
 		}
 	}
 
+	// The pods the model server no longer selects, e.g. because its selector changed, are re-evaluated below
+	previousPods, _ := c.store.GetPodsByModelServer(utils.GetNamespaceName(ms))
+
 	_ = c.store.AddOrUpdateModelServer(ms, pods)
 
+	for _, podInfo := range previousPods {
+		if pods.Contains(utils.GetNamespaceName(podInfo.Pod)) || discovery.IsDiscovered(podInfo.Pod) {
+			continue
+		}
+		if err := c.addOrUpdatePod(podInfo.Pod); err != nil {
+			klog.Warningf("failed to re-evaluate pod %s/%s: %v", podInfo.Pod.Namespace, podInfo.Pod.Name, err)
+		}
+	}
+
+	if ms.Spec.Discovery != nil {
+		// The instances are added to the store by the discovery once healthy
+		if err := c.discovery.Sync(ms); err != nil {
+			return fmt.Errorf("failed to discover the instances of ModelServer %s/%s: %v", ms.Namespace, ms.Name, err)
+		}
+		return nil
+	}
+
 	// Get already bound pods to avoid unnecessary updates
 	existingPods, err := c.store.GetPodsByModelServer(utils.GetNamespaceName(ms))
 	if err != nil {
@@ -283,22 +339,38 @@ func (c *ModelServerController) addOrUpdatePod(pod *corev1.Pod) error {
 
 	servers := []*aiv1alpha1.ModelServer{}
 	for _, item := range modelServers {
-		selector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{MatchLabels: item.Spec.WorkloadSelector.MatchLabels})
+		selector, err := workloadSelector(item)
 		if err != nil || !selector.Matches(labels.Set(pod.Labels)) {
 			continue
 		}
 		servers = append(servers, item)
 	}
 
-	if len(servers) > 0 {
-		if err := c.store.AddOrUpdatePod(pod, servers); err != nil {
-			return fmt.Errorf("failed to add or update pod %s/%s in data store: %v", pod.Namespace, pod.Name, err)
+	// The labels of the pod may have changed so that no ModelServer selects it anymore, e.g. during a rolling update
+	if len(servers) == 0 {
+		if podName := utils.GetNamespaceName(pod); c.store.GetPodInfo(podName) != nil {
+			return c.store.DeletePod(podName)
 		}
+		return nil
+	}
+	if err := c.store.AddOrUpdatePod(pod, servers); err != nil {
+		return fmt.Errorf("failed to add or update pod %s/%s in data store: %v", pod.Namespace, pod.Name, err)
 	}
 
 	return nil
 }
 
+// workloadSelector returns the selector of the pods of the ModelServer. The ModelServers with discovery select no pod.
+func workloadSelector(ms *aiv1alpha1.ModelServer) (labels.Selector, error) {
+	if ms.Spec.WorkloadSelector == nil {
+		return labels.Nothing(), nil
+	}
+	return metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+		MatchLabels:      ms.Spec.WorkloadSelector.MatchLabels,
+		MatchExpressions: ms.Spec.WorkloadSelector.MatchExpressions,
+	})
+}
+
 func (c *ModelServerController) enqueueModelServer(obj interface{}) {
 	var key string
 	var err error
@@ -325,6 +397,20 @@ func (c *ModelServerController) enqueuePod(obj interface{}) {
 	})
 }
 
+// refreshEndpointSlice discovers again the instances of the ModelServers discovering the Service of an EndpointSlice.
+func (c *ModelServerController) refreshEndpointSlice(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	endpointSlice, ok := obj.(*discoveryv1.EndpointSlice)
+	if !ok {
+		return
+	}
+	if service := endpointSlice.Labels[discoveryv1.LabelServiceName]; service != "" {
+		c.discovery.RefreshService(endpointSlice.Namespace, service)
+	}
+}
+
 // isPodReady checks if the pod is in a running state and has a PodReady condition set to true.
 func isPodReady(pod *corev1.Pod) bool {
 	if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
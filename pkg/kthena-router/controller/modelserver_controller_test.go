@@ -56,6 +56,7 @@ func TestModelServerController_ModelServerLifecycle(t *testing.T) {
 	controller := NewModelServerController(
 		kthenaInformerFactory,
 		kubeInformerFactory,
+		nil,
 		store,
 	)
 
@@ -296,6 +297,7 @@ func TestModelServerController_PodLifecycle(t *testing.T) {
 	controller := NewModelServerController(
 		kthenaInformerFactory,
 		kubeInformerFactory,
+		nil,
 		store,
 	)
 
@@ -512,6 +514,7 @@ func TestModelServerController_ErrorHandling(t *testing.T) {
 	controller := NewModelServerController(
 		kthenaInformerFactory,
 		kubeInformerFactory,
+		nil,
 		store,
 	)
 
@@ -556,6 +559,7 @@ func TestModelServerController_WorkQueueProcessing(t *testing.T) {
 	controller := NewModelServerController(
 		kthenaInformerFactory,
 		kubeInformerFactory,
+		nil,
 		store,
 	)
 
@@ -631,6 +635,7 @@ func TestModelServerController_PodSelectionLogic(t *testing.T) {
 	controller := NewModelServerController(
 		kthenaInformerFactory,
 		kubeInformerFactory,
+		nil,
 		store,
 	)
 
@@ -819,6 +824,7 @@ func TestModelServerController_ComprehensiveLifecycleTest(t *testing.T) {
 	controller := NewModelServerController(
 		kthenaInformerFactory,
 		kubeInformerFactory,
+		nil,
 		store,
 	)
 
@@ -998,6 +1004,7 @@ func TestModelServerController_SharedPods(t *testing.T) {
 	controller := NewModelServerController(
 		kthenaInformerFactory,
 		kubeInformerFactory,
+		nil,
 		store,
 	)
 
@@ -1087,6 +1094,180 @@ func TestModelServerController_SharedPods(t *testing.T) {
 	assert.True(t, pod2Info.HasModelServer(ms2Name), "pod2 should reference ms2")
 }
 
+func TestModelServerController_PodLabelChanges(t *testing.T) {
+	patch := setupMockBackend()
+	defer patch.Reset()
+
+	kubeClient := kubefake.NewSimpleClientset()
+	kthenaClient := kthenafake.NewSimpleClientset()
+	kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	kthenaInformerFactory := informersv1alpha1.NewSharedInformerFactory(kthenaClient, 0)
+	store := datastore.New()
+	controller := NewModelServerController(kthenaInformerFactory, kubeInformerFactory, nil, store)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go controller.Run(stop)
+	kthenaInformerFactory.Start(stop)
+	kubeInformerFactory.Start(stop)
+
+	// The stable ModelServer selects the v1 pods, the canary one the v2 and v3 pods
+	stable := &aiv1alpha1.ModelServer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "stable"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			InferenceEngine: aiv1alpha1.VLLM,
+			WorkloadSelector: &aiv1alpha1.WorkloadSelector{
+				MatchLabels: map[string]string{"app": "llama", "version": "v1"},
+			},
+		},
+	}
+	canary := &aiv1alpha1.ModelServer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "canary"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			InferenceEngine: aiv1alpha1.VLLM,
+			WorkloadSelector: &aiv1alpha1.WorkloadSelector{
+				MatchLabels: map[string]string{"app": "llama"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "version", Operator: metav1.LabelSelectorOpIn, Values: []string{"v2", "v3"}},
+				},
+			},
+		},
+	}
+	for _, ms := range []*aiv1alpha1.ModelServer{stable, canary} {
+		_, err := kthenaClient.NetworkingV1alpha1().ModelServers("default").Create(context.Background(), ms, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "llama-0",
+			Labels:    map[string]string{"app": "llama", "version": "v1"},
+		},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	podName := utils.GetNamespaceName(pod)
+	_, err := kubeClient.CoreV1().Pods("default").Create(context.Background(), pod, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	boundTo := func(names ...string) func() bool {
+		return func() bool {
+			podInfo := store.GetPodInfo(podName)
+			if podInfo == nil {
+				return len(names) == 0
+			}
+			servers := podInfo.GetModelServers()
+			if servers.Len() != len(names) {
+				return false
+			}
+			for _, name := range names {
+				if !servers.Contains(types.NamespacedName{Namespace: "default", Name: name}) {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	assert.True(t, waitForObjectInCache(t, 2*time.Second, boundTo("stable")), "the v1 pod should be bound to the stable ModelServer")
+
+	relabel := func(version string) {
+		pod.Labels["version"] = version
+		_, err := kubeClient.CoreV1().Pods("default").Update(context.Background(), pod, metav1.UpdateOptions{})
+		assert.NoError(t, err)
+	}
+
+	relabel("v2")
+	assert.True(t, waitForObjectInCache(t, 2*time.Second, boundTo("canary")), "the v2 pod should move to the canary ModelServer")
+	pods, err := store.GetPodsByModelServer(utils.GetNamespaceName(stable))
+	assert.NoError(t, err)
+	assert.Empty(t, pods)
+
+	relabel("v4")
+	assert.True(t, waitForObjectInCache(t, 2*time.Second, boundTo()), "the v4 pod should leave the store")
+
+	// The pods the selector of a ModelServer no longer matches leave it
+	relabel("v3")
+	assert.True(t, waitForObjectInCache(t, 2*time.Second, boundTo("canary")), "the v3 pod should join the canary ModelServer")
+	canary.Spec.WorkloadSelector.MatchExpressions[0].Values = []string{"v2"}
+	_, err = kthenaClient.NetworkingV1alpha1().ModelServers("default").Update(context.Background(), canary, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	assert.True(t, waitForObjectInCache(t, 2*time.Second, boundTo()), "the v3 pod should leave the canary ModelServer")
+}
+
+func TestModelServerController_Discovery(t *testing.T) {
+	patch := setupMockBackend()
+	defer patch.Reset()
+
+	kubeClient := kubefake.NewSimpleClientset()
+	kthenaClient := kthenafake.NewSimpleClientset()
+	kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	kthenaInformerFactory := informersv1alpha1.NewSharedInformerFactory(kthenaClient, 0)
+	store := datastore.New()
+	controller := NewModelServerController(kthenaInformerFactory, kubeInformerFactory, nil, store)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go controller.Run(stop)
+	kthenaInformerFactory.Start(stop)
+	kubeInformerFactory.Start(stop)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "llama-0",
+			Labels:    map[string]string{"app": "llama"},
+		},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	_, err := kubeClient.CoreV1().Pods("default").Create(context.Background(), pod, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	ms := &aiv1alpha1.ModelServer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "llama"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			InferenceEngine: aiv1alpha1.VLLM,
+			WorkloadSelector: &aiv1alpha1.WorkloadSelector{
+				MatchLabels: map[string]string{"app": "llama"},
+			},
+		},
+	}
+	_, err = kthenaClient.NetworkingV1alpha1().ModelServers("default").Create(context.Background(), ms, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	assert.True(t, waitForObjectInCache(t, 2*time.Second, func() bool {
+		return store.GetPodInfo(utils.GetNamespaceName(pod)) != nil
+	}), "the pod should be bound to the ModelServer")
+
+	// The ModelServer discovering its instances no longer selects the pods
+	ms.Spec.WorkloadSelector = nil
+	ms.Spec.Discovery = &aiv1alpha1.Discovery{
+		Type:   aiv1alpha1.DiscoveryTypeStatic,
+		Static: &aiv1alpha1.StaticDiscovery{Addresses: []string{"192.0.2.1"}},
+	}
+	_, err = kthenaClient.NetworkingV1alpha1().ModelServers("default").Update(context.Background(), ms, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	assert.True(t, waitForObjectInCache(t, 2*time.Second, func() bool {
+		return store.GetPodInfo(utils.GetNamespaceName(pod)) == nil && store.GetModelServer(utils.GetNamespaceName(ms)).Spec.Discovery != nil
+	}), "the pod should leave the ModelServer")
+
+	// The EndpointSlice discovery fails when the EndpointSlices are not watched
+	err = controller.discovery.Sync(&aiv1alpha1.ModelServer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "sglang"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			Discovery: &aiv1alpha1.Discovery{
+				Type:          aiv1alpha1.DiscoveryTypeEndpointSlice,
+				EndpointSlice: &aiv1alpha1.EndpointSliceDiscovery{ServiceName: "sglang-hosts"},
+			},
+		},
+	})
+	assert.EqualError(t, err, "the EndpointSlices are not watched by the router")
+}
+
 // Helper functions for testing
 
 // waitForCacheSync waits for the informer caches to sync with a timeout
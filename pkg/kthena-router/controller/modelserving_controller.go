@@ -0,0 +1,155 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	informersv1alpha1 "github.com/volcano-sh/kthena/client-go/informers/externalversions"
+	listersv1alpha1 "github.com/volcano-sh/kthena/client-go/listers/workload/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+)
+
+// ModelServingController caches the ModelServings, whose annotations describe the served models in the model catalog.
+type ModelServingController struct {
+	modelServingLister listersv1alpha1.ModelServingLister
+	modelServingSynced cache.InformerSynced
+	registration       cache.ResourceEventHandlerRegistration
+
+	workqueue   workqueue.TypedRateLimitingInterface[any]
+	initialSync *atomic.Bool
+	store       datastore.Store
+}
+
+func NewModelServingController(
+	kthenaInformerFactory informersv1alpha1.SharedInformerFactory,
+	store datastore.Store,
+) *ModelServingController {
+	modelServingInformer := kthenaInformerFactory.Workload().V1alpha1().ModelServings()
+
+	controller := &ModelServingController{
+		modelServingLister: modelServingInformer.Lister(),
+		modelServingSynced: modelServingInformer.Informer().HasSynced,
+		workqueue:          workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[any]()),
+		initialSync:        &atomic.Bool{},
+		store:              store,
+	}
+
+	controller.registration, _ = modelServingInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.enqueueModelServing,
+		UpdateFunc: func(old, new interface{}) { controller.enqueueModelServing(new) },
+		DeleteFunc: controller.enqueueModelServing,
+	})
+
+	return controller
+}
+
+func (c *ModelServingController) Run(stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+	defer c.workqueue.ShutDown()
+
+	if ok := cache.WaitForCacheSync(stopCh, c.modelServingSynced); !ok {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+	c.workqueue.Add(initialSyncSignal)
+
+	go wait.Until(c.runWorker, time.Second, stopCh)
+
+	<-stopCh
+	return nil
+}
+
+func (c *ModelServingController) HasSynced() bool {
+	return c.initialSync.Load()
+}
+
+func (c *ModelServingController) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *ModelServingController) processNextWorkItem() bool {
+	obj, shutdown := c.workqueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.workqueue.Done(obj)
+
+	if obj == initialSyncSignal {
+		klog.V(2).Info("initial model servings have been synced")
+		c.workqueue.Forget(obj)
+		c.initialSync.Store(true)
+		return true
+	}
+
+	var key string
+	var ok bool
+	if key, ok = obj.(string); !ok {
+		c.workqueue.Forget(obj)
+		utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+		return true
+	}
+
+	if err := c.syncHandler(key); err != nil {
+		if c.workqueue.NumRequeues(key) < maxRetries {
+			klog.Errorf("error syncing modelserving %q: %s, requeuing", key, err.Error())
+			c.workqueue.AddRateLimited(key)
+			return true
+		}
+		klog.Errorf("giving up on syncing modelserving %q after %d retries: %s", key, maxRetries, err)
+		c.workqueue.Forget(obj)
+	}
+	return true
+}
+
+func (c *ModelServingController) syncHandler(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+		return nil
+	}
+
+	modelServing, err := c.modelServingLister.ModelServings(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		_ = c.store.DeleteModelServing(types.NamespacedName{Namespace: namespace, Name: name})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.store.AddOrUpdateModelServing(modelServing)
+}
+
+func (c *ModelServingController) enqueueModelServing(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.workqueue.Add(key)
+}
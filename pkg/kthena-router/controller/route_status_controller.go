@@ -0,0 +1,388 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayclientset "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
+	gatewayinformers "sigs.k8s.io/gateway-api/pkg/client/informers/externalversions"
+	gatewaylisters "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1"
+
+	clientset "github.com/volcano-sh/kthena/client-go/clientset/versioned"
+	informersv1alpha1 "github.com/volcano-sh/kthena/client-go/informers/externalversions"
+	listerv1alpha1 "github.com/volcano-sh/kthena/client-go/listers/networking/v1alpha1"
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/gatewayapi"
+)
+
+// routeStatusResyncPeriod is the period the status of all the routes is computed again, to follow the changes of
+// their backends.
+const routeStatusResyncPeriod = 30 * time.Second
+
+const (
+	modelRouteKind = "ModelRoute"
+	httpRouteKind  = "HTTPRoute"
+)
+
+// routeStatusKey is the key of a route in the workqueue of the RouteStatusController.
+type routeStatusKey struct {
+	kind string
+	name types.NamespacedName
+}
+
+// RouteStatusController reports the status of the ModelRoutes, and of the HTTPRoutes if they are routed, for each of
+// their parent Gateways: whether the Gateway accepted the route and whether its backends exist.
+type RouteStatusController struct {
+	kthenaClient     clientset.Interface
+	gatewayClient    gatewayclientset.Interface
+	modelRouteLister listerv1alpha1.ModelRouteLister
+	httpRouteLister  gatewaylisters.HTTPRouteLister
+	synced           []cache.InformerSynced
+
+	workqueue workqueue.TypedRateLimitingInterface[routeStatusKey]
+	store     datastore.Store
+}
+
+// NewRouteStatusController creates a RouteStatusController, the status of the HTTPRoutes is reported only if
+// withHTTPRoutes is set.
+func NewRouteStatusController(
+	kthenaClient clientset.Interface,
+	kthenaInformerFactory informersv1alpha1.SharedInformerFactory,
+	gatewayClient gatewayclientset.Interface,
+	gatewayInformerFactory gatewayinformers.SharedInformerFactory,
+	withHTTPRoutes bool,
+	store datastore.Store,
+) *RouteStatusController {
+	modelRouteInformer := kthenaInformerFactory.Networking().V1alpha1().ModelRoutes()
+
+	controller := &RouteStatusController{
+		kthenaClient:     kthenaClient,
+		gatewayClient:    gatewayClient,
+		modelRouteLister: modelRouteInformer.Lister(),
+		synced:           []cache.InformerSynced{modelRouteInformer.Informer().HasSynced},
+		workqueue:        workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[routeStatusKey]()),
+		store:            store,
+	}
+
+	_, _ = modelRouteInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { controller.enqueue(modelRouteKind, obj) },
+		UpdateFunc: func(old, new interface{}) { controller.enqueue(modelRouteKind, new) },
+	})
+	if withHTTPRoutes {
+		httpRouteInformer := gatewayInformerFactory.Gateway().V1().HTTPRoutes()
+		controller.httpRouteLister = httpRouteInformer.Lister()
+		controller.synced = append(controller.synced, httpRouteInformer.Informer().HasSynced)
+		_, _ = httpRouteInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { controller.enqueue(httpRouteKind, obj) },
+			UpdateFunc: func(old, new interface{}) { controller.enqueue(httpRouteKind, new) },
+		})
+	}
+	// The routes are accepted or rejected by the Gateways of the store.
+	store.RegisterCallback("Gateway", func(data datastore.EventData) { controller.enqueueAll() })
+
+	return controller
+}
+
+func (c *RouteStatusController) Run(stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+	defer c.workqueue.ShutDown()
+
+	if ok := cache.WaitForCacheSync(stopCh, c.synced...); !ok {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+
+	go wait.Until(c.runWorker, time.Second, stopCh)
+	// The backends of the routes have no events, the status of the routes is computed again periodically.
+	go wait.Until(c.enqueueAll, routeStatusResyncPeriod, stopCh)
+
+	<-stopCh
+	return nil
+}
+
+func (c *RouteStatusController) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *RouteStatusController) processNextWorkItem() bool {
+	key, shutdown := c.workqueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.workqueue.Done(key)
+
+	if err := c.syncHandler(context.TODO(), key); err != nil {
+		if c.workqueue.NumRequeues(key) < maxRetries {
+			klog.Errorf("error updating the status of %s %s: %s, requeuing", key.kind, key.name, err.Error())
+			c.workqueue.AddRateLimited(key)
+			return true
+		}
+		klog.Errorf("giving up on updating the status of %s %s after %d retries: %s", key.kind, key.name, maxRetries, err)
+	}
+	c.workqueue.Forget(key)
+	return true
+}
+
+func (c *RouteStatusController) syncHandler(ctx context.Context, key routeStatusKey) error {
+	var err error
+	switch key.kind {
+	case modelRouteKind:
+		err = c.updateModelRouteStatus(ctx, key.name)
+	case httpRouteKind:
+		err = c.updateHTTPRouteStatus(ctx, key.name)
+	}
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// updateModelRouteStatus updates the parents of the status of a ModelRoute if they changed.
+func (c *RouteStatusController) updateModelRouteStatus(ctx context.Context, name types.NamespacedName) error {
+	modelRoute, err := c.modelRouteLister.ModelRoutes(name.Namespace).Get(name.Name)
+	if err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(modelRouteParents(c.store, modelRoute), modelRoute.Status.Parents) {
+		return nil
+	}
+	client := c.kthenaClient.NetworkingV1alpha1().ModelRoutes(name.Namespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current, err := client.Get(ctx, name.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		parents := modelRouteParents(c.store, current)
+		if equality.Semantic.DeepEqual(parents, current.Status.Parents) {
+			return nil
+		}
+		current.Status.Parents = parents
+		_, err = client.UpdateStatus(ctx, current, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// updateHTTPRouteStatus updates the parents of the status of an HTTPRoute if they changed.
+func (c *RouteStatusController) updateHTTPRouteStatus(ctx context.Context, name types.NamespacedName) error {
+	httpRoute, err := c.httpRouteLister.HTTPRoutes(name.Namespace).Get(name.Name)
+	if err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(httpRouteParents(c.store, httpRoute), httpRoute.Status.Parents) {
+		return nil
+	}
+	client := c.gatewayClient.GatewayV1().HTTPRoutes(name.Namespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current, err := client.Get(ctx, name.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		parents := httpRouteParents(c.store, current)
+		if equality.Semantic.DeepEqual(parents, current.Status.Parents) {
+			return nil
+		}
+		current.Status.Parents = parents
+		_, err = client.UpdateStatus(ctx, current, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+func (c *RouteStatusController) enqueue(kind string, obj interface{}) {
+	object, err := meta.Accessor(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.workqueue.Add(routeStatusKey{kind: kind, name: types.NamespacedName{Namespace: object.GetNamespace(), Name: object.GetName()}})
+}
+
+func (c *RouteStatusController) enqueueAll() {
+	modelRoutes, err := c.modelRouteLister.List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, modelRoute := range modelRoutes {
+		c.enqueue(modelRouteKind, modelRoute)
+	}
+	if c.httpRouteLister == nil {
+		return
+	}
+	httpRoutes, err := c.httpRouteLister.List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, httpRoute := range httpRoutes {
+		c.enqueue(httpRouteKind, httpRoute)
+	}
+}
+
+// modelRouteParents returns the status of a ModelRoute for each of its parentRefs. The ModelRoutes are only routed by
+// the kthena-router, their parents are all reported.
+func modelRouteParents(store datastore.Store, modelRoute *aiv1alpha1.ModelRoute) []gatewayv1.RouteParentStatus {
+	resolvedRefs := modelRouteResolvedRefs(store, modelRoute)
+	var parents []gatewayv1.RouteParentStatus
+	for _, parentRef := range modelRoute.Spec.ParentRefs {
+		parents = append(parents, parentStatus(store, modelRoute.Namespace, modelRoute.Generation, parentRef, resolvedRefs, modelRoute.Status.Parents))
+	}
+	return parents
+}
+
+// httpRouteParents returns the status of an HTTPRoute for each of its parentRefs referencing a Gateway of the
+// kthena-router GatewayClass. The status reported by the controllers of the other parents is kept, and the parents are
+// never nil as they are required by the HTTPRoutes.
+func httpRouteParents(store datastore.Store, httpRoute *gatewayv1.HTTPRoute) []gatewayv1.RouteParentStatus {
+	parents := []gatewayv1.RouteParentStatus{}
+	for _, parent := range httpRoute.Status.Parents {
+		if parent.ControllerName != ControllerName {
+			parents = append(parents, parent)
+		}
+	}
+	resolvedRefs := httpRouteResolvedRefs(store, httpRoute)
+	for _, parentRef := range httpRoute.Spec.ParentRefs {
+		if !referencesKthenaGateway(store, httpRoute.Namespace, []gatewayv1.ParentReference{parentRef}) {
+			continue
+		}
+		parents = append(parents, parentStatus(store, httpRoute.Namespace, httpRoute.Generation, parentRef, resolvedRefs, httpRoute.Status.Parents))
+	}
+	return parents
+}
+
+// parentStatus returns the status of a route of the namespace for a parentRef. The Accepted condition tells whether
+// the parent is a Gateway of the store with a listener matching the parentRef, resolvedRefs is the ResolvedRefs
+// condition of the route. The conditions keep their last transition time in the previous status of the route.
+func parentStatus(store datastore.Store, namespace string, generation int64, parentRef gatewayv1.ParentReference,
+	resolvedRefs metav1.Condition, previous []gatewayv1.RouteParentStatus) gatewayv1.RouteParentStatus {
+	accepted := metav1.Condition{
+		Type:    string(gatewayv1.RouteConditionAccepted),
+		Status:  metav1.ConditionTrue,
+		Reason:  string(gatewayv1.RouteReasonAccepted),
+		Message: "Route is accepted",
+	}
+	if gatewayKey, ok := datastore.GatewayParentKey(namespace, parentRef); !ok {
+		accepted.Status = metav1.ConditionFalse
+		accepted.Reason = string(gatewayv1.RouteReasonUnsupportedValue)
+		accepted.Message = "The parent is not a Gateway"
+	} else if gateway := store.GetGateway(gatewayKey); gateway == nil {
+		accepted.Status = metav1.ConditionFalse
+		accepted.Reason = string(gatewayv1.RouteReasonNoMatchingParent)
+		accepted.Message = fmt.Sprintf("Gateway %s of the %s GatewayClass not found", gatewayKey, DefaultGatewayClassName)
+	} else if !datastore.ParentRefMatchesListener(parentRef, gateway) {
+		accepted.Status = metav1.ConditionFalse
+		accepted.Reason = string(gatewayv1.RouteReasonNoMatchingParent)
+		accepted.Message = fmt.Sprintf("No listener of Gateway %s matches the sectionName and port of the parentRef", gatewayKey)
+	}
+
+	status := gatewayv1.RouteParentStatus{
+		ParentRef:      parentRef,
+		ControllerName: ControllerName,
+	}
+	for _, parent := range previous {
+		if parent.ControllerName == ControllerName && reflect.DeepEqual(parent.ParentRef, parentRef) {
+			status.Conditions = append(status.Conditions, parent.Conditions...)
+			break
+		}
+	}
+	for _, condition := range []metav1.Condition{accepted, resolvedRefs} {
+		condition.ObservedGeneration = generation
+		meta.SetStatusCondition(&status.Conditions, condition)
+	}
+	return status
+}
+
+// modelRouteResolvedRefs returns the ResolvedRefs condition of a ModelRoute, whether the ModelServers its rules target
+// exist.
+func modelRouteResolvedRefs(store datastore.Store, modelRoute *aiv1alpha1.ModelRoute) metav1.Condition {
+	var missing []string
+	for _, rule := range modelRoute.Spec.Rules {
+		for _, target := range rule.TargetModels {
+			name := types.NamespacedName{Namespace: modelRoute.Namespace, Name: target.ModelServerName}
+			if store.GetModelServer(name) == nil {
+				missing = append(missing, target.ModelServerName)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return metav1.Condition{
+			Type:    string(gatewayv1.RouteConditionResolvedRefs),
+			Status:  metav1.ConditionFalse,
+			Reason:  string(gatewayv1.RouteReasonBackendNotFound),
+			Message: fmt.Sprintf("ModelServers not found: %s", strings.Join(missing, ", ")),
+		}
+	}
+	return resolvedRefsCondition()
+}
+
+// httpRouteResolvedRefs returns the ResolvedRefs condition of an HTTPRoute, whether its backends are existing
+// InferencePools, the only backends routed by the kthena-router.
+func httpRouteResolvedRefs(store datastore.Store, httpRoute *gatewayv1.HTTPRoute) metav1.Condition {
+	var missing []string
+	for _, rule := range httpRoute.Spec.Rules {
+		for _, backendRef := range rule.BackendRefs {
+			name, ok := gatewayapi.InferencePoolBackend(httpRoute.Namespace, backendRef.BackendRef)
+			if !ok {
+				return metav1.Condition{
+					Type:    string(gatewayv1.RouteConditionResolvedRefs),
+					Status:  metav1.ConditionFalse,
+					Reason:  string(gatewayv1.RouteReasonInvalidKind),
+					Message: fmt.Sprintf("Backend %s is not an %s", backendRef.Name, gatewayapi.InferencePoolKind),
+				}
+			}
+			if store.GetInferencePool(name.String()) == nil {
+				missing = append(missing, name.String())
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return metav1.Condition{
+			Type:    string(gatewayv1.RouteConditionResolvedRefs),
+			Status:  metav1.ConditionFalse,
+			Reason:  string(gatewayv1.RouteReasonBackendNotFound),
+			Message: fmt.Sprintf("InferencePools not found: %s", strings.Join(missing, ", ")),
+		}
+	}
+	return resolvedRefsCondition()
+}
+
+func resolvedRefsCondition() metav1.Condition {
+	return metav1.Condition{
+		Type:    string(gatewayv1.RouteConditionResolvedRefs),
+		Status:  metav1.ConditionTrue,
+		Reason:  string(gatewayv1.RouteReasonResolvedRefs),
+		Message: "All references are resolved",
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/util/sets"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayfake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
+	gatewayinformers "sigs.k8s.io/gateway-api/pkg/client/informers/externalversions"
+
+	kthenafake "github.com/volcano-sh/kthena/client-go/clientset/versioned/fake"
+	informersv1alpha1 "github.com/volcano-sh/kthena/client-go/informers/externalversions"
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/gatewayapi"
+)
+
+func TestRouteStatusController_SyncHandler(t *testing.T) {
+	store := datastore.New()
+	require.NoError(t, store.AddOrUpdateGateway(&gatewayv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "gw-a"},
+		Spec: gatewayv1.GatewaySpec{
+			GatewayClassName: DefaultGatewayClassName,
+			Listeners:        []gatewayv1.Listener{{Name: "http", Port: 80}},
+		},
+	}))
+	require.NoError(t, store.AddOrUpdateModelServer(&aiv1alpha1.ModelServer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ms"},
+	}, sets.New[types.NamespacedName]()))
+
+	gwA := gatewayv1.ParentReference{Name: "gw-a"}
+	gwB := gatewayv1.ParentReference{Name: "gw-b"}
+	otherSection := gatewayv1.ParentReference{Name: "gw-a", SectionName: ptr.To(gatewayv1.SectionName("https"))}
+	modelRoute := &aiv1alpha1.ModelRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "route", Generation: 2},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName:  "model",
+			ParentRefs: []gatewayv1.ParentReference{gwA, gwB, otherSection},
+			Rules: []*aiv1alpha1.Rule{
+				{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "ms"}, {ModelServerName: "missing"}}},
+			},
+		},
+	}
+	otherParent := gatewayv1.RouteParentStatus{
+		ParentRef:      gatewayv1.ParentReference{Name: "istio"},
+		ControllerName: "istio.io/gateway-controller",
+		Conditions: []metav1.Condition{{
+			Type: string(gatewayv1.RouteConditionAccepted), Status: metav1.ConditionTrue, Reason: string(gatewayv1.RouteReasonAccepted),
+		}},
+	}
+	httpRoute := &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "route", Generation: 1},
+		Spec: gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: []gatewayv1.ParentReference{gwA, {Name: "istio"}}},
+			Rules: []gatewayv1.HTTPRouteRule{{
+				BackendRefs: []gatewayv1.HTTPBackendRef{{BackendRef: gatewayapi.InferencePoolBackendRef("pool")}},
+			}},
+		},
+		Status: gatewayv1.HTTPRouteStatus{RouteStatus: gatewayv1.RouteStatus{Parents: []gatewayv1.RouteParentStatus{otherParent}}},
+	}
+
+	kthenaClient := kthenafake.NewSimpleClientset(modelRoute)
+	gatewayClient := gatewayfake.NewSimpleClientset(httpRoute)
+	kthenaInformerFactory := informersv1alpha1.NewSharedInformerFactory(kthenaClient, 0)
+	gatewayInformerFactory := gatewayinformers.NewSharedInformerFactory(gatewayClient, 0)
+	controller := NewRouteStatusController(kthenaClient, kthenaInformerFactory, gatewayClient, gatewayInformerFactory, true, store)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	kthenaInformerFactory.Start(stop)
+	gatewayInformerFactory.Start(stop)
+	require.True(t, waitForCacheSync(t, 5*time.Second, controller.synced...))
+
+	ctx := context.Background()
+	require.NoError(t, controller.syncHandler(ctx, routeStatusKey{kind: modelRouteKind, name: types.NamespacedName{Namespace: "default", Name: "route"}}))
+	updated, err := kthenaClient.NetworkingV1alpha1().ModelRoutes("default").Get(ctx, "route", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, updated.Status.Parents, 3)
+	for i, want := range []struct {
+		parentRef      gatewayv1.ParentReference
+		acceptedReason gatewayv1.RouteConditionReason
+	}{
+		{parentRef: gwA, acceptedReason: gatewayv1.RouteReasonAccepted},
+		{parentRef: gwB, acceptedReason: gatewayv1.RouteReasonNoMatchingParent},
+		{parentRef: otherSection, acceptedReason: gatewayv1.RouteReasonNoMatchingParent},
+	} {
+		parent := updated.Status.Parents[i]
+		assert.Equal(t, want.parentRef, parent.ParentRef)
+		assert.Equal(t, gatewayv1.GatewayController(ControllerName), parent.ControllerName)
+		accepted := findCondition(parent.Conditions, gatewayv1.RouteConditionAccepted)
+		assert.Equal(t, string(want.acceptedReason), accepted.Reason)
+		assert.Equal(t, int64(2), accepted.ObservedGeneration)
+		resolvedRefs := findCondition(parent.Conditions, gatewayv1.RouteConditionResolvedRefs)
+		assert.Equal(t, metav1.ConditionFalse, resolvedRefs.Status)
+		assert.Equal(t, string(gatewayv1.RouteReasonBackendNotFound), resolvedRefs.Reason)
+		assert.Contains(t, resolvedRefs.Message, "missing")
+	}
+
+	require.NoError(t, controller.syncHandler(ctx, routeStatusKey{kind: httpRouteKind, name: types.NamespacedName{Namespace: "default", Name: "route"}}))
+	updatedHTTPRoute, err := gatewayClient.GatewayV1().HTTPRoutes("default").Get(ctx, "route", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, updatedHTTPRoute.Status.Parents, 2, "the parents not of the kthena-router are not reported")
+	assert.Equal(t, otherParent, updatedHTTPRoute.Status.Parents[0])
+	assert.Equal(t, gwA, updatedHTTPRoute.Status.Parents[1].ParentRef)
+	assert.Equal(t, string(gatewayv1.RouteReasonAccepted), findCondition(updatedHTTPRoute.Status.Parents[1].Conditions, gatewayv1.RouteConditionAccepted).Reason)
+	assert.Equal(t, string(gatewayv1.RouteReasonBackendNotFound), findCondition(updatedHTTPRoute.Status.Parents[1].Conditions, gatewayv1.RouteConditionResolvedRefs).Reason)
+
+	// The status is not updated again while it doesn't change.
+	require.True(t, waitForObjectInCache(t, 2*time.Second, func() bool {
+		cached, err := controller.modelRouteLister.ModelRoutes("default").Get("route")
+		return err == nil && len(cached.Status.Parents) == 3
+	}))
+	actions := len(kthenaClient.Actions())
+	require.NoError(t, controller.syncHandler(ctx, routeStatusKey{kind: modelRouteKind, name: types.NamespacedName{Namespace: "default", Name: "route"}}))
+	assert.Len(t, kthenaClient.Actions(), actions)
+}
+
+func findCondition(conditions []metav1.Condition, conditionType gatewayv1.RouteConditionType) metav1.Condition {
+	for _, condition := range conditions {
+		if condition.Type == string(conditionType) {
+			return condition
+		}
+	}
+	return metav1.Condition{}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+)
+
+// referencesKthenaGateway reports whether a route of the namespace has a parent Gateway of the kthena-router
+// GatewayClass.
+func referencesKthenaGateway(store datastore.Store, namespace string, parentRefs []gatewayv1.ParentReference) bool {
+	for _, parentRef := range parentRefs {
+		gatewayKey, ok := datastore.GatewayParentKey(namespace, parentRef)
+		if !ok {
+			continue
+		}
+		if gw := store.GetGateway(gatewayKey); gw != nil && string(gw.Spec.GatewayClassName) == DefaultGatewayClassName {
+			return true
+		}
+	}
+	return false
+}
+
+// referencesGateway reports whether a route of the namespace has the Gateway of the key as a parent.
+func referencesGateway(namespace string, parentRefs []gatewayv1.ParentReference, gatewayKey string) bool {
+	for _, parentRef := range parentRefs {
+		if key, ok := datastore.GatewayParentKey(namespace, parentRef); ok && key == gatewayKey {
+			return true
+		}
+	}
+	return false
+}
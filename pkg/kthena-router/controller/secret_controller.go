@@ -0,0 +1,222 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	informersv1alpha1 "github.com/volcano-sh/kthena/client-go/informers/externalversions"
+	listerv1alpha1 "github.com/volcano-sh/kthena/client-go/listers/networking/v1alpha1"
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+)
+
+// SecretController caches the Secrets holding the CA bundles verifying the https ModelServers.
+// The other Secrets are not kept in the store.
+type SecretController struct {
+	secretLister      corelisters.SecretLister
+	modelServerLister listerv1alpha1.ModelServerLister
+	secretSynced      cache.InformerSynced
+	modelServerSynced cache.InformerSynced
+
+	secretRegistration      cache.ResourceEventHandlerRegistration
+	modelServerRegistration cache.ResourceEventHandlerRegistration
+
+	workqueue   workqueue.TypedRateLimitingInterface[any]
+	initialSync *atomic.Bool
+	store       datastore.Store
+}
+
+func NewSecretController(
+	kubeInformerFactory informers.SharedInformerFactory,
+	kthenaInformerFactory informersv1alpha1.SharedInformerFactory,
+	store datastore.Store,
+) *SecretController {
+	secretInformer := kubeInformerFactory.Core().V1().Secrets()
+	modelServerInformer := kthenaInformerFactory.Networking().V1alpha1().ModelServers()
+
+	controller := &SecretController{
+		secretLister:      secretInformer.Lister(),
+		modelServerLister: modelServerInformer.Lister(),
+		secretSynced:      secretInformer.Informer().HasSynced,
+		modelServerSynced: modelServerInformer.Informer().HasSynced,
+		workqueue:         workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[any]()),
+		initialSync:       &atomic.Bool{},
+		store:             store,
+	}
+
+	controller.secretRegistration, _ = secretInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.enqueueSecret,
+		UpdateFunc: func(old, new interface{}) { controller.enqueueSecret(new) },
+		DeleteFunc: controller.enqueueSecret,
+	})
+	// A ModelServer starting or stopping to reference a Secret adds it to or removes it from the store.
+	controller.modelServerRegistration, _ = modelServerInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.enqueueCABundle,
+		UpdateFunc: func(old, new interface{}) {
+			controller.enqueueCABundle(old)
+			controller.enqueueCABundle(new)
+		},
+		DeleteFunc: controller.enqueueCABundle,
+	})
+
+	return controller
+}
+
+func (c *SecretController) Run(stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+	defer c.workqueue.ShutDown()
+
+	if ok := cache.WaitForCacheSync(stopCh, c.secretSynced, c.modelServerSynced); !ok {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+	c.workqueue.Add(initialSyncSignal)
+
+	go wait.Until(c.runWorker, time.Second, stopCh)
+
+	<-stopCh
+	return nil
+}
+
+func (c *SecretController) HasSynced() bool {
+	return c.initialSync.Load()
+}
+
+func (c *SecretController) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *SecretController) processNextWorkItem() bool {
+	obj, shutdown := c.workqueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.workqueue.Done(obj)
+
+	if obj == initialSyncSignal {
+		klog.V(2).Info("initial secrets have been synced")
+		c.workqueue.Forget(obj)
+		c.initialSync.Store(true)
+		return true
+	}
+
+	var key string
+	var ok bool
+	if key, ok = obj.(string); !ok {
+		c.workqueue.Forget(obj)
+		utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+		return true
+	}
+
+	if err := c.syncHandler(key); err != nil {
+		if c.workqueue.NumRequeues(key) < maxRetries {
+			klog.Errorf("error syncing secret %q: %s, requeuing", key, err.Error())
+			c.workqueue.AddRateLimited(key)
+			return true
+		}
+		klog.Errorf("giving up on syncing secret %q after %d retries: %s", key, maxRetries, err)
+		c.workqueue.Forget(obj)
+	}
+	return true
+}
+
+func (c *SecretController) syncHandler(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+		return nil
+	}
+	secretName := types.NamespacedName{Namespace: namespace, Name: name}
+
+	referenced, err := c.referencedByModelServer(secretName)
+	if err != nil {
+		return err
+	}
+	if !referenced {
+		_ = c.store.DeleteSecret(secretName)
+		return nil
+	}
+
+	secret, err := c.secretLister.Secrets(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		klog.Warningf("CA bundle secret %s not found", secretName)
+		_ = c.store.DeleteSecret(secretName)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return c.store.AddOrUpdateSecret(secret)
+}
+
+// referencedByModelServer reports whether a ModelServer gets its CA bundle from the secret.
+func (c *SecretController) referencedByModelServer(secretName types.NamespacedName) (bool, error) {
+	modelServers, err := c.modelServerLister.ModelServers(secretName.Namespace).List(labels.Everything())
+	if err != nil {
+		return false, err
+	}
+	for _, ms := range modelServers {
+		if ref := caBundleRef(ms); ref != nil && ref.Name == secretName.Name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *SecretController) enqueueSecret(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.workqueue.Add(key)
+}
+
+// enqueueCABundle enqueues the Secret holding the CA bundle of the ModelServer, if any.
+func (c *SecretController) enqueueCABundle(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	ms, ok := obj.(*aiv1alpha1.ModelServer)
+	if !ok {
+		return
+	}
+	if ref := caBundleRef(ms); ref != nil {
+		c.workqueue.Add(types.NamespacedName{Namespace: ms.Namespace, Name: ref.Name}.String())
+	}
+}
+
+func caBundleRef(ms *aiv1alpha1.ModelServer) *aiv1alpha1.SecretKeyReference {
+	if tls := ms.Spec.WorkloadPort.TLS; tls != nil {
+		return tls.CABundle
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	kthenafake "github.com/volcano-sh/kthena/client-go/clientset/versioned/fake"
+	informersv1alpha1 "github.com/volcano-sh/kthena/client-go/informers/externalversions"
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+)
+
+func TestSecretController_CABundles(t *testing.T) {
+	caSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "model-ca"},
+		Data:       map[string][]byte{"ca.crt": []byte("certificate")},
+	}
+	otherSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api-keys"}}
+	kubeClient := kubefake.NewSimpleClientset(caSecret, otherSecret)
+	kthenaClient := kthenafake.NewSimpleClientset()
+	kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	kthenaInformerFactory := informersv1alpha1.NewSharedInformerFactory(kthenaClient, 0)
+	store := datastore.New()
+	controller := NewSecretController(kubeInformerFactory, kthenaInformerFactory, store)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	kubeInformerFactory.Start(stop)
+	kthenaInformerFactory.Start(stop)
+	require.True(t, waitForCacheSync(t, 5*time.Second, controller.secretSynced, controller.modelServerSynced))
+
+	caSecretName := types.NamespacedName{Namespace: "default", Name: "model-ca"}
+	// Secrets not referenced by a ModelServer are not stored.
+	require.NoError(t, controller.syncHandler("default/model-ca"))
+	require.NoError(t, controller.syncHandler("default/api-keys"))
+	assert.Nil(t, store.GetSecret(caSecretName))
+	assert.Nil(t, store.GetSecret(types.NamespacedName{Namespace: "default", Name: "api-keys"}))
+
+	ms := &aiv1alpha1.ModelServer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ms-1"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			InferenceEngine: aiv1alpha1.VLLM,
+			WorkloadPort: aiv1alpha1.WorkloadPort{
+				Port:     8443,
+				Protocol: "https",
+				TLS:      &aiv1alpha1.BackendTLS{CABundle: &aiv1alpha1.SecretKeyReference{Name: "model-ca"}},
+			},
+		},
+	}
+	_, err := kthenaClient.NetworkingV1alpha1().ModelServers("default").Create(context.Background(), ms, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.True(t, waitForObjectInCache(t, 2*time.Second, func() bool {
+		_, err := controller.modelServerLister.ModelServers("default").Get("ms-1")
+		return err == nil
+	}))
+
+	require.NoError(t, controller.syncHandler("default/model-ca"))
+	require.NotNil(t, store.GetSecret(caSecretName))
+	assert.Equal(t, []byte("certificate"), store.GetSecret(caSecretName).Data["ca.crt"])
+
+	// The secret is removed from the store once no ModelServer references it.
+	require.NoError(t, kthenaClient.NetworkingV1alpha1().ModelServers("default").Delete(context.Background(), "ms-1", metav1.DeleteOptions{}))
+	require.True(t, waitForObjectInCache(t, 2*time.Second, func() bool {
+		_, err := controller.modelServerLister.ModelServers("default").Get("ms-1")
+		return err != nil
+	}))
+	require.NoError(t, controller.syncHandler("default/model-ca"))
+	assert.Nil(t, store.GetSecret(caSecretName))
+}
@@ -0,0 +1,155 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	informersv1alpha1 "github.com/volcano-sh/kthena/client-go/informers/externalversions"
+	listersv1alpha1 "github.com/volcano-sh/kthena/client-go/listers/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+)
+
+// TokenQuotaController caches the TokenQuotas, the budgets of tokens of the ModelRoutes they select.
+type TokenQuotaController struct {
+	tokenQuotaLister listersv1alpha1.TokenQuotaLister
+	tokenQuotaSynced cache.InformerSynced
+	registration     cache.ResourceEventHandlerRegistration
+
+	workqueue   workqueue.TypedRateLimitingInterface[any]
+	initialSync *atomic.Bool
+	store       datastore.Store
+}
+
+func NewTokenQuotaController(
+	kthenaInformerFactory informersv1alpha1.SharedInformerFactory,
+	store datastore.Store,
+) *TokenQuotaController {
+	tokenQuotaInformer := kthenaInformerFactory.Networking().V1alpha1().TokenQuotas()
+
+	controller := &TokenQuotaController{
+		tokenQuotaLister: tokenQuotaInformer.Lister(),
+		tokenQuotaSynced: tokenQuotaInformer.Informer().HasSynced,
+		workqueue:        workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[any]()),
+		initialSync:      &atomic.Bool{},
+		store:            store,
+	}
+
+	controller.registration, _ = tokenQuotaInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.enqueueTokenQuota,
+		UpdateFunc: func(old, new interface{}) { controller.enqueueTokenQuota(new) },
+		DeleteFunc: controller.enqueueTokenQuota,
+	})
+
+	return controller
+}
+
+func (c *TokenQuotaController) Run(stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+	defer c.workqueue.ShutDown()
+
+	if ok := cache.WaitForCacheSync(stopCh, c.tokenQuotaSynced); !ok {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+	c.workqueue.Add(initialSyncSignal)
+
+	go wait.Until(c.runWorker, time.Second, stopCh)
+
+	<-stopCh
+	return nil
+}
+
+func (c *TokenQuotaController) HasSynced() bool {
+	return c.initialSync.Load()
+}
+
+func (c *TokenQuotaController) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *TokenQuotaController) processNextWorkItem() bool {
+	obj, shutdown := c.workqueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.workqueue.Done(obj)
+
+	if obj == initialSyncSignal {
+		klog.V(2).Info("initial token quotas have been synced")
+		c.workqueue.Forget(obj)
+		c.initialSync.Store(true)
+		return true
+	}
+
+	var key string
+	var ok bool
+	if key, ok = obj.(string); !ok {
+		c.workqueue.Forget(obj)
+		utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+		return true
+	}
+
+	if err := c.syncHandler(key); err != nil {
+		if c.workqueue.NumRequeues(key) < maxRetries {
+			klog.Errorf("error syncing tokenquota %q: %s, requeuing", key, err.Error())
+			c.workqueue.AddRateLimited(key)
+			return true
+		}
+		klog.Errorf("giving up on syncing tokenquota %q after %d retries: %s", key, maxRetries, err)
+		c.workqueue.Forget(obj)
+	}
+	return true
+}
+
+func (c *TokenQuotaController) syncHandler(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+		return nil
+	}
+
+	quota, err := c.tokenQuotaLister.TokenQuotas(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		_ = c.store.DeleteTokenQuota(types.NamespacedName{Namespace: namespace, Name: name})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.store.AddOrUpdateTokenQuota(quota)
+}
+
+func (c *TokenQuotaController) enqueueTokenQuota(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.workqueue.Add(key)
+}
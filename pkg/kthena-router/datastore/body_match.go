@@ -0,0 +1,125 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+type requestBodyKey struct{}
+
+// WithRequestBody returns a copy of ctx carrying the parsed JSON body of the request, the body fields matched by
+// the rules of the ModelRoutes are looked up in it.
+func WithRequestBody(ctx context.Context, body map[string]any) context.Context {
+	return context.WithValue(ctx, requestBodyKey{}, body)
+}
+
+func requestBody(req *http.Request) map[string]any {
+	if req == nil {
+		return nil
+	}
+	body, _ := req.Context().Value(requestBodyKey{}).(map[string]any)
+	return body
+}
+
+// matchBodyFields reports whether the body of the request matches all the fields.
+func matchBodyFields(req *http.Request, fields []aiv1alpha1.BodyFieldMatch) bool {
+	if len(fields) == 0 {
+		return true
+	}
+	body := requestBody(req)
+	for i := range fields {
+		if !matchBodyField(body, &fields[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchBodyField(body map[string]any, match *aiv1alpha1.BodyFieldMatch) bool {
+	value, found := lookupBodyField(body, match.Path)
+	present := found && isPresent(value)
+	switch {
+	case match.Present != nil:
+		return present == *match.Present
+	case match.Value != nil:
+		s, ok := scalarString(value)
+		return present && ok && matchString(match.Value, s)
+	default:
+		return present
+	}
+}
+
+// lookupBodyField returns the value of the field at the dot-separated path, the elements of the arrays are addressed
+// by their index.
+func lookupBodyField(body map[string]any, path string) (any, bool) {
+	var value any = body
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			field, ok := v[key]
+			if !ok {
+				return nil, false
+			}
+			value = field
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func isPresent(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case []any:
+		return len(v) != 0
+	case map[string]any:
+		return len(v) != 0
+	default:
+		return true
+	}
+}
+
+// scalarString returns the strings as is and the numbers and the booleans as JSON, false for the objects and arrays.
+func scalarString(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+func newBodyRequest(t *testing.T, body string) *http.Request {
+	var parsed map[string]any
+	require.NoError(t, json.Unmarshal([]byte(body), &parsed))
+	req := &http.Request{URL: &url.URL{Path: "/v1/chat/completions"}}
+	return req.WithContext(WithRequestBody(context.Background(), parsed))
+}
+
+func TestMatchBodyFields(t *testing.T) {
+	body := `{
+		"model": "llama",
+		"metadata": {"team": "search", "tier": 2, "beta": true},
+		"messages": [{"role": "system", "content": "be brief"}],
+		"tools": [{"type": "function"}],
+		"functions": [],
+		"user": null
+	}`
+	tests := []struct {
+		name  string
+		field aiv1alpha1.BodyFieldMatch
+		want  bool
+	}{
+		{name: "nested string", field: aiv1alpha1.BodyFieldMatch{Path: "metadata.team", Value: &aiv1alpha1.StringMatch{Exact: ptr("search")}}, want: true},
+		{name: "nested string mismatch", field: aiv1alpha1.BodyFieldMatch{Path: "metadata.team", Value: &aiv1alpha1.StringMatch{Exact: ptr("ads")}}},
+		{name: "number", field: aiv1alpha1.BodyFieldMatch{Path: "metadata.tier", Value: &aiv1alpha1.StringMatch{Exact: ptr("2")}}, want: true},
+		{name: "boolean", field: aiv1alpha1.BodyFieldMatch{Path: "metadata.beta", Value: &aiv1alpha1.StringMatch{Exact: ptr("true")}}, want: true},
+		{name: "array element", field: aiv1alpha1.BodyFieldMatch{Path: "messages.0.role", Value: &aiv1alpha1.StringMatch{Prefix: ptr("sys")}}, want: true},
+		{name: "array out of range", field: aiv1alpha1.BodyFieldMatch{Path: "messages.1.role"}},
+		{name: "object value", field: aiv1alpha1.BodyFieldMatch{Path: "metadata", Value: &aiv1alpha1.StringMatch{Regex: ptr(".*")}}},
+		{name: "present by default", field: aiv1alpha1.BodyFieldMatch{Path: "tools"}, want: true},
+		{name: "present", field: aiv1alpha1.BodyFieldMatch{Path: "tools", Present: ptr(true)}, want: true},
+		{name: "empty array is not present", field: aiv1alpha1.BodyFieldMatch{Path: "functions", Present: ptr(false)}, want: true},
+		{name: "null is not present", field: aiv1alpha1.BodyFieldMatch{Path: "user"}},
+		{name: "missing", field: aiv1alpha1.BodyFieldMatch{Path: "metadata.owner.name", Present: ptr(false)}, want: true},
+	}
+	req := newBodyRequest(t, body)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchBodyFields(req, []aiv1alpha1.BodyFieldMatch{tt.field}))
+		})
+	}
+
+	// The request without a parsed body only matches the absent fields.
+	req = &http.Request{URL: &url.URL{Path: "/v1/chat/completions"}}
+	assert.False(t, matchBodyFields(req, []aiv1alpha1.BodyFieldMatch{{Path: "tools"}}))
+	assert.True(t, matchBodyFields(req, []aiv1alpha1.BodyFieldMatch{{Path: "tools", Present: ptr(false)}}))
+}
+
+func TestStoreMatchModelServerByBodyFields(t *testing.T) {
+	s := &store{
+		routeInfo:  make(map[string]*modelRouteInfo),
+		routes:     make(map[string][]*aiv1alpha1.ModelRoute),
+		loraRoutes: make(map[string][]*aiv1alpha1.ModelRoute),
+	}
+	require.NoError(t, s.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "llama"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "llama",
+			Rules: []*aiv1alpha1.Rule{
+				{
+					Name: "tools",
+					ModelMatch: &aiv1alpha1.ModelMatch{Body: &aiv1alpha1.BodyMatch{
+						Fields: []aiv1alpha1.BodyFieldMatch{{Path: "tools"}},
+					}},
+					TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "tool-server"}},
+				},
+				{
+					Name:         "default",
+					TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "default-server"}},
+				},
+			},
+		},
+	}))
+
+	server, _, _, err := s.MatchModelServer("llama", newBodyRequest(t, `{"model": "llama", "tools": [{"type": "function"}]}`), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "tool-server", server.Name)
+
+	server, _, _, err = s.MatchModelServer("llama", newBodyRequest(t, `{"model": "llama", "tools": []}`), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "default-server", server.Name)
+}
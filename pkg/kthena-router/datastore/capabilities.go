@@ -0,0 +1,86 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+type requiredCapabilitiesKey struct{}
+
+// WithRequiredCapabilities returns a copy of ctx carrying the capabilities the request requires, its targets whose
+// ModelServer doesn't declare them all are not selected.
+func WithRequiredCapabilities(ctx context.Context, capabilities []aiv1alpha1.Capability) context.Context {
+	return context.WithValue(ctx, requiredCapabilitiesKey{}, capabilities)
+}
+
+func requiredCapabilities(req *http.Request) []aiv1alpha1.Capability {
+	if req == nil {
+		return nil
+	}
+	capabilities, _ := req.Context().Value(requiredCapabilitiesKey{}).([]aiv1alpha1.Capability)
+	return capabilities
+}
+
+// MissingCapabilitiesError is returned when the rules matching a request only target ModelServers lacking some of the
+// capabilities it requires.
+type MissingCapabilitiesError struct {
+	Model        string
+	Capabilities []aiv1alpha1.Capability
+}
+
+func (e *MissingCapabilitiesError) Error() string {
+	return fmt.Sprintf("no model server of model %s has the capabilities %v", e.Model, e.Capabilities)
+}
+
+// capableTargets returns the targets of a rule of a ModelRoute in the namespace whose ModelServer declares all the
+// capabilities, the targets themselves if no capability is required.
+func (s *store) capableTargets(namespace string, targets []*aiv1alpha1.TargetModel, capabilities []aiv1alpha1.Capability) []*aiv1alpha1.TargetModel {
+	if len(capabilities) == 0 {
+		return targets
+	}
+	var capable []*aiv1alpha1.TargetModel
+	for _, target := range targets {
+		ms := s.GetModelServer(types.NamespacedName{Namespace: namespace, Name: target.ModelServerName})
+		if ms != nil && hasCapabilities(ms, capabilities) {
+			capable = append(capable, target)
+		}
+	}
+	return capable
+}
+
+// hasCapabilities reports whether the ModelServer declares all the capabilities. A ModelServer which declares no
+// capability is assumed to have them all but vision, so that it keeps serving the requests with tools, JSON mode or
+// reasoning it served before the capabilities were declared.
+func hasCapabilities(ms *aiv1alpha1.ModelServer, capabilities []aiv1alpha1.Capability) bool {
+	for _, capability := range capabilities {
+		if len(ms.Spec.Capabilities) == 0 && capability != aiv1alpha1.CapabilityVision {
+			continue
+		}
+		if !slices.Contains(ms.Spec.Capabilities, capability) {
+			return false
+		}
+	}
+	return true
+}
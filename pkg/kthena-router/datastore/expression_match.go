@@ -0,0 +1,63 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import (
+	"context"
+	"net/http"
+
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/celmatch"
+)
+
+type requestAttributesKey struct{}
+
+// requestAttributes are the attributes of a request computed by the router rather than read from the request.
+type requestAttributes struct {
+	tokens   int
+	consumer map[string]string
+}
+
+// WithRequestAttributes returns a copy of ctx carrying the input tokens of the request and the attributes of its
+// consumer, the CEL expressions matched by the rules of the ModelRoutes may read them.
+func WithRequestAttributes(ctx context.Context, tokens int, consumer map[string]string) context.Context {
+	return context.WithValue(ctx, requestAttributesKey{}, &requestAttributes{tokens: tokens, consumer: consumer})
+}
+
+// matchExpression reports whether the request for the model matches the CEL expression of a rule.
+func matchExpression(modelName string, req *http.Request, expression string) bool {
+	attributes := &celmatch.Attributes{
+		Model: modelName,
+		Body:  requestBody(req),
+	}
+	if req != nil {
+		attributes.Method = req.Method
+		attributes.Path = req.URL.Path
+		attributes.Headers = celmatch.Headers(req.Header)
+		if computed, ok := req.Context().Value(requestAttributesKey{}).(*requestAttributes); ok {
+			attributes.Tokens = computed.tokens
+			attributes.Consumer = computed.consumer
+		}
+	}
+	matched, err := celmatch.Match(expression, attributes)
+	if err != nil {
+		klog.V(4).Infof("expression %q doesn't match the request for model %s: %v", expression, modelName, err)
+		return false
+	}
+	return matched
+}
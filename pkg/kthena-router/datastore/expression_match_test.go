@@ -0,0 +1,104 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+func TestStoreMatchModelServerByExpression(t *testing.T) {
+	s := &store{
+		routeInfo:  make(map[string]*modelRouteInfo),
+		routes:     make(map[string][]*aiv1alpha1.ModelRoute),
+		loraRoutes: make(map[string][]*aiv1alpha1.ModelRoute),
+	}
+	require.NoError(t, s.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "llama"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "llama",
+			Rules: []*aiv1alpha1.Rule{
+				{
+					Name: "long-research-prompts",
+					ModelMatch: &aiv1alpha1.ModelMatch{
+						Expression: ptr(`request.tokens > 4000 && consumer.team == "research"`),
+					},
+					TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "long-context-server"}},
+				},
+				{
+					Name: "tools",
+					ModelMatch: &aiv1alpha1.ModelMatch{
+						Expression: ptr(`request.body.tools.exists(t, t.function.name == "search")`),
+					},
+					TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "tool-server"}},
+				},
+				{
+					Name:         "default",
+					TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "default-server"}},
+				},
+			},
+		},
+	}))
+
+	tests := []struct {
+		name     string
+		body     string
+		tokens   int
+		consumer map[string]string
+		want     string
+	}{
+		{
+			name:     "long prompt of the team",
+			body:     `{"model": "llama"}`,
+			tokens:   5000,
+			consumer: map[string]string{"subject": "alice", "team": "research"},
+			want:     "long-context-server",
+		},
+		{
+			name:     "long prompt of another team",
+			body:     `{"model": "llama"}`,
+			tokens:   5000,
+			consumer: map[string]string{"subject": "bob", "team": "ads"},
+			want:     "default-server",
+		},
+		{
+			name: "tool calling request",
+			body: `{"model": "llama", "tools": [{"type": "function", "function": {"name": "search"}}]}`,
+			want: "tool-server",
+		},
+		{
+			// The tools expression fails to evaluate on the requests without tools, and doesn't match.
+			name: "request without tools",
+			body: `{"model": "llama"}`,
+			want: "default-server",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newBodyRequest(t, tt.body)
+			req = req.WithContext(WithRequestAttributes(req.Context(), tt.tokens, tt.consumer))
+			server, _, _, err := s.MatchModelServer("llama", req, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, server.Name)
+		})
+	}
+}
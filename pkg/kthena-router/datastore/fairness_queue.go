@@ -20,6 +20,7 @@ import (
 	"container/heap"
 	"context"
 	"errors"
+	"math"
 	"sync"
 	"time"
 
@@ -34,6 +35,11 @@ type Request struct {
 	Priority    float64 // Priority (lower value means higher priority)
 	RequestTime time.Time
 	NotifyChan  chan struct{}
+	// Weight of the user for weighted fair queueing. When set, the queue replaces the priority with the virtual
+	// finish time of the request, so that users share the dequeued requests in proportion to their weights.
+	Weight float64
+	// Cost of the request for weighted fair queueing, e.g. its tokens. A cost below 1 counts as 1.
+	Cost float64
 }
 
 // RequestPriorityQueue implements the heap.Interface
@@ -43,6 +49,11 @@ type RequestPriorityQueue struct {
 	mu       sync.RWMutex     // Ensure concurrent safety with read/write locks
 	heap     []*Request       // Underlying storage structure
 	metrics  *metrics.Metrics // Metrics instance for recording queue stats
+
+	// Weighted fair queueing state: the virtual finish time of the last dequeued request,
+	// and the virtual finish time of the last queued request of each user
+	virtualTime float64
+	finishTimes map[string]float64
 }
 
 var _ heap.Interface = &RequestPriorityQueue{}
@@ -52,10 +63,11 @@ func NewRequestPriorityQueue(metricsInstance *metrics.Metrics) *RequestPriorityQ
 		metricsInstance = metrics.DefaultMetrics
 	}
 	pq := &RequestPriorityQueue{
-		stopCh:   make(chan struct{}),
-		notifyCh: make(chan struct{}, 1), // Buffered to prevent blocking
-		heap:     make([]*Request, 0),
-		metrics:  metricsInstance,
+		stopCh:      make(chan struct{}),
+		notifyCh:    make(chan struct{}, 1), // Buffered to prevent blocking
+		heap:        make([]*Request, 0),
+		metrics:     metricsInstance,
+		finishTimes: make(map[string]float64),
 	}
 	return pq
 }
@@ -66,7 +78,11 @@ func (pq *RequestPriorityQueue) Len() int { return len(pq.heap) }
 func (pq *RequestPriorityQueue) Less(i, j int) bool {
 	// same user, FIFO
 	if pq.heap[i].UserID == pq.heap[j].UserID {
-		return pq.heap[i].RequestTime.Before(pq.heap[j].RequestTime)
+		if !pq.heap[i].RequestTime.Equal(pq.heap[j].RequestTime) {
+			return pq.heap[i].RequestTime.Before(pq.heap[j].RequestTime)
+		}
+		// The virtual finish times of the requests of a user keep their order
+		return pq.heap[i].Priority < pq.heap[j].Priority
 	}
 	// different users, compare priority, actually token usage here
 	if pq.heap[i].Priority != pq.heap[j].Priority {
@@ -99,6 +115,12 @@ func (pq *RequestPriorityQueue) Pop() interface{} {
 func (pq *RequestPriorityQueue) PushRequest(r *Request) error {
 	pq.mu.Lock()
 	defer pq.mu.Unlock()
+	if r.Weight > 0 {
+		// A user starts where its previous request finishes, or now if it has no request queued
+		start := math.Max(pq.virtualTime, pq.finishTimes[r.UserID])
+		r.Priority = start + math.Max(r.Cost, 1)/r.Weight
+		pq.finishTimes[r.UserID] = r.Priority
+	}
 	heap.Push(pq, r)
 
 	// Update fairness queue size metrics
@@ -120,6 +142,13 @@ func (pq *RequestPriorityQueue) popWhenAvailable(ctx context.Context) (*Request,
 		pq.mu.Lock()
 		if len(pq.heap) > 0 {
 			req := heap.Pop(pq).(*Request)
+			if req.Weight > 0 {
+				pq.virtualTime = req.Priority
+				if pq.finishTimes[req.UserID] == req.Priority {
+					// The user has no request left in the queue
+					delete(pq.finishTimes, req.UserID)
+				}
+			}
 
 			// Update fairness queue size metrics and record queue duration
 			if pq.metrics != nil {
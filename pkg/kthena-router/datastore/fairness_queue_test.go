@@ -385,3 +385,41 @@ func TestClose(t *testing.T) {
 		t.Error("stopCh should be closed")
 	}
 }
+
+func TestWeightedFairQueueing(t *testing.T) {
+	pq := NewRequestPriorityQueue(nil)
+	defer pq.Close()
+
+	now := time.Now()
+	push := func(id, user string, weight, cost float64) *Request {
+		req := &Request{ReqID: id, UserID: user, ModelName: "model", RequestTime: now, Weight: weight, Cost: cost}
+		if err := pq.PushRequest(req); err != nil {
+			t.Fatalf("PushRequest failed: %v", err)
+		}
+		return req
+	}
+	a1 := push("a-1", "a", 2, 100)
+	a2 := push("a-2", "a", 2, 100)
+	b1 := push("b-1", "b", 1, 120)
+	if a1.Priority != 50 || a2.Priority != 100 || b1.Priority != 120 {
+		t.Errorf("Expected virtual finish times 50, 100 and 120, got %v, %v and %v", a1.Priority, a2.Priority, b1.Priority)
+	}
+
+	for _, want := range []string{"a-1", "a-2", "b-1"} {
+		req, err := pq.popWhenAvailable(context.Background())
+		if err != nil {
+			t.Fatalf("popWhenAvailable failed: %v", err)
+		}
+		if req.ReqID != want {
+			t.Errorf("Expected %s, got %s", want, req.ReqID)
+		}
+	}
+	if len(pq.finishTimes) != 0 {
+		t.Errorf("Expected the finish times of the users without queued requests to be dropped, got %v", pq.finishTimes)
+	}
+
+	// A user coming back starts at the virtual time, its past requests are not held against it.
+	if a3 := push("a-3", "a", 2, 0); a3.Priority != 120.5 {
+		t.Errorf("Expected virtual finish time 120.5, got %v", a3.Priority)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/simulation"
+)
+
+// TestFairnessQueueSimulation replays a heavy tenant sending twice the capacity of a model alongside a light tenant,
+// dispatching the requests by token usage as the router does, and asserts that the light tenant is not starved.
+func TestFairnessQueueSimulation(t *testing.T) {
+	const model = "model"
+	s := simulation.New(100 * time.Millisecond)
+	tracker := NewInMemorySlidingWindowTokenTracker(WithClock(s.Clock), WithWindowSize(time.Minute))
+	pq := NewRequestPriorityQueue(nil)
+	defer pq.Close()
+
+	trace := simulation.Merge(
+		simulation.Steady("heavy", 0, 50*time.Millisecond, 1200, 100),
+		simulation.Steady("light", 500*time.Millisecond, time.Second, 60, 100),
+	)
+	arrivals := make(map[string]time.Time)
+	costs := make(map[string]int)
+	waits := make(map[string][]time.Duration)
+	s.Run(trace, func(now time.Time, a simulation.Arrival) {
+		priority, err := tracker.GetTokenCount(a.Tenant, model)
+		require.NoError(t, err)
+		id := fmt.Sprintf("%s-%d", a.Tenant, len(arrivals))
+		arrivals[id], costs[id] = now, a.Cost
+		require.NoError(t, pq.PushRequest(&Request{ReqID: id, UserID: a.Tenant, ModelName: model, Priority: priority, RequestTime: now}))
+	}, func(now time.Time) bool {
+		if pq.Len() == 0 {
+			return false
+		}
+		req, err := pq.popWhenAvailable(context.Background())
+		require.NoError(t, err)
+		waits[req.UserID] = append(waits[req.UserID], now.Sub(arrivals[req.ReqID]))
+		require.NoError(t, tracker.UpdateTokenCount(req.UserID, model, float64(costs[req.ReqID]), 0))
+		return true
+	})
+
+	assert.Len(t, waits["heavy"], 1200, "every request is eventually dispatched")
+	require.Len(t, waits["light"], 60, "every request is eventually dispatched")
+	for i, wait := range waits["light"] {
+		assert.LessOrEqual(t, wait, 2*s.Tick, "light request %d waited behind the heavy tenant", i)
+	}
+}
+
+// TestWeightedFairQueueSimulation replays tenants of different weights sending more than the capacity of a model,
+// one of them in a large burst, and asserts that they are served in proportion to their weights.
+func TestWeightedFairQueueSimulation(t *testing.T) {
+	const model = "model"
+	weights := map[string]float64{"gold": 3, "silver": 1, "noisy": 1}
+	s := simulation.New(100 * time.Millisecond)
+	pq := NewRequestPriorityQueue(nil)
+	defer pq.Close()
+
+	trace := simulation.Merge(
+		simulation.Burst("noisy", 0, 1000, 100),
+		simulation.Steady("gold", time.Second, 50*time.Millisecond, 600, 100),
+		simulation.Steady("silver", time.Second, 50*time.Millisecond, 600, 100),
+	)
+	arrivals := make(map[string]time.Time)
+	dispatched := make(map[string]int)
+	var lightWaits []time.Duration
+	s.Run(trace, func(now time.Time, a simulation.Arrival) {
+		id := fmt.Sprintf("%s-%d", a.Tenant, len(arrivals))
+		arrivals[id] = now
+		require.NoError(t, pq.PushRequest(&Request{ReqID: id, UserID: a.Tenant, ModelName: model, RequestTime: now,
+			Weight: weights[a.Tenant], Cost: float64(a.Cost)}))
+	}, func(now time.Time) bool {
+		if pq.Len() == 0 {
+			return false
+		}
+		req, err := pq.popWhenAvailable(context.Background())
+		require.NoError(t, err)
+		// Count the dispatches while the three tenants are backlogged.
+		if now.Sub(simulation.Start) > time.Second && now.Sub(simulation.Start) <= 31*time.Second {
+			dispatched[req.UserID]++
+		}
+		if len(lightWaits) == 0 && req.UserID == "silver" {
+			lightWaits = append(lightWaits, now.Sub(arrivals[req.ReqID]))
+		}
+		return true
+	})
+
+	assert.Zero(t, pq.Len())
+	assert.InDelta(t, 3, float64(dispatched["gold"])/float64(dispatched["silver"]), 0.1)
+	assert.InDelta(t, 1, float64(dispatched["noisy"])/float64(dispatched["silver"]), 0.1)
+	require.Len(t, lightWaits, 1)
+	assert.LessOrEqual(t, lightWaits[0], 5*s.Tick, "the burst of the noisy tenant delayed the first silver request")
+}
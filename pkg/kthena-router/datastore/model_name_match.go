@@ -0,0 +1,126 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+// patternRoute is a ModelRoute matching the models of the requests by its ModelNameMatch.
+type patternRoute struct {
+	route  *aiv1alpha1.ModelRoute
+	prefix string
+	regex  *regexp.Regexp
+}
+
+func newPatternRoute(mr *aiv1alpha1.ModelRoute) (*patternRoute, error) {
+	route := &patternRoute{route: mr}
+	match := mr.Spec.ModelNameMatch
+	if match.Regex != nil {
+		regex, err := regexp.Compile(*match.Regex)
+		if err != nil {
+			return nil, err
+		}
+		route.regex = regex
+	} else if match.Prefix != nil {
+		route.prefix = *match.Prefix
+	}
+	return route, nil
+}
+
+func (p *patternRoute) key() string {
+	return p.route.Namespace + "/" + p.route.Name
+}
+
+func (p *patternRoute) matches(model string) bool {
+	if p.regex != nil {
+		return p.regex.MatchString(model)
+	}
+	return strings.HasPrefix(model, p.prefix)
+}
+
+// comparePatternRoutes orders the pattern routes by precedence: the prefixes before the regular expressions, the
+// longest prefixes first, then by namespace and name.
+func comparePatternRoutes(a, b *patternRoute) int {
+	if (a.regex == nil) != (b.regex == nil) {
+		if a.regex == nil {
+			return -1
+		}
+		return 1
+	}
+	if c := cmp.Compare(len(b.prefix), len(a.prefix)); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.key(), b.key())
+}
+
+// setPatternRoute indexes mr by its ModelNameMatch, replacing its previous version. The caller must hold routeMutex.
+func (s *store) setPatternRoute(mr *aiv1alpha1.ModelRoute) {
+	key := mr.Namespace + "/" + mr.Name
+	s.removePatternRoute(key)
+	if mr.Spec.ModelNameMatch == nil {
+		return
+	}
+	route, err := newPatternRoute(mr)
+	if err != nil {
+		klog.Errorf("ignoring the modelNameMatch of ModelRoute %s: %v", key, err)
+		return
+	}
+	s.patternRoutes = append(s.patternRoutes, route)
+	slices.SortFunc(s.patternRoutes, comparePatternRoutes)
+}
+
+// removePatternRoute removes the ModelRoute key from the pattern routes and returns it, if any. The caller must hold
+// routeMutex.
+func (s *store) removePatternRoute(key string) *aiv1alpha1.ModelRoute {
+	i := slices.IndexFunc(s.patternRoutes, func(p *patternRoute) bool { return p.key() == key })
+	if i < 0 {
+		return nil
+	}
+	route := s.patternRoutes[i].route
+	s.patternRoutes = slices.Delete(s.patternRoutes, i, i+1)
+	return route
+}
+
+// findPatternRoute returns the ModelRoute key if it matches the models by pattern. The caller must hold routeMutex.
+func (s *store) findPatternRoute(key string) *aiv1alpha1.ModelRoute {
+	for _, p := range s.patternRoutes {
+		if p.key() == key {
+			return p.route
+		}
+	}
+	return nil
+}
+
+// matchPatternRoutes returns the ModelRoutes whose ModelNameMatch matches model, in the order of their precedence.
+// The caller must hold routeMutex.
+func (s *store) matchPatternRoutes(model string) []*aiv1alpha1.ModelRoute {
+	var routes []*aiv1alpha1.ModelRoute
+	for _, p := range s.patternRoutes {
+		if p.matches(model) {
+			routes = append(routes, p.route)
+		}
+	}
+	return routes
+}
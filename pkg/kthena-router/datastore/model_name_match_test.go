@@ -0,0 +1,123 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+func newPatternTestRoute(name, modelName string, match *aiv1alpha1.StringMatch, modelServerName string) *aiv1alpha1.ModelRoute {
+	return &aiv1alpha1.ModelRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName:      modelName,
+			ModelNameMatch: match,
+			Rules: []*aiv1alpha1.Rule{
+				{
+					Name:         "default",
+					TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: modelServerName}},
+				},
+			},
+		},
+	}
+}
+
+func TestStoreMatchModelServerByModelNameMatch(t *testing.T) {
+	s := &store{
+		routeInfo:  make(map[string]*modelRouteInfo),
+		routes:     make(map[string][]*aiv1alpha1.ModelRoute),
+		loraRoutes: make(map[string][]*aiv1alpha1.ModelRoute),
+	}
+	for _, mr := range []*aiv1alpha1.ModelRoute{
+		newPatternTestRoute("exact", "llama-3-8b", nil, "exact-server"),
+		newPatternTestRoute("regex", "", &aiv1alpha1.StringMatch{Regex: ptr(`^llama-3-[0-9]+b-ft-.*$`)}, "regex-server"),
+		newPatternTestRoute("short-prefix", "", &aiv1alpha1.StringMatch{Prefix: ptr("llama-")}, "short-prefix-server"),
+		newPatternTestRoute("long-prefix", "", &aiv1alpha1.StringMatch{Prefix: ptr("llama-3-8b-")}, "long-prefix-server"),
+		newPatternTestRoute("b-prefix", "", &aiv1alpha1.StringMatch{Prefix: ptr("qwen-")}, "b-prefix-server"),
+		newPatternTestRoute("a-prefix", "", &aiv1alpha1.StringMatch{Prefix: ptr("qwen-")}, "a-prefix-server"),
+		newPatternTestRoute("invalid-regex", "", &aiv1alpha1.StringMatch{Regex: ptr("mistral-(")}, "invalid-regex-server"),
+	} {
+		assert.NoError(t, s.AddOrUpdateModelRoute(mr))
+	}
+
+	tests := []struct {
+		model          string
+		expectedServer string
+	}{
+		{model: "llama-3-8b", expectedServer: "exact-server"},
+		{model: "llama-3-8b-ft-support", expectedServer: "long-prefix-server"},
+		{model: "llama-3-70b-ft-support", expectedServer: "short-prefix-server"},
+		{model: "llama-2-7b", expectedServer: "short-prefix-server"},
+		{model: "qwen-2", expectedServer: "a-prefix-server"},
+		{model: "mistral-(7b", expectedServer: ""},
+	}
+	req := &http.Request{URL: &url.URL{Path: "/v1/chat/completions"}}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			server, isLora, _, err := s.MatchModelServer(tt.model, req, nil)
+			if tt.expectedServer == "" {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.False(t, isLora)
+			assert.Equal(t, types.NamespacedName{Namespace: "default", Name: tt.expectedServer}, server)
+		})
+	}
+
+	// The regular expressions are only tried once no prefix matches
+	assert.NoError(t, s.DeleteModelRoute("default/short-prefix"))
+	server, _, _, err := s.MatchModelServer("llama-3-70b-ft-support", req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, types.NamespacedName{Namespace: "default", Name: "regex-server"}, server)
+
+	assert.NotNil(t, s.GetModelRoute("default/regex"))
+	assert.Contains(t, s.GetAllModelRoutes(), "default/long-prefix")
+	assert.Nil(t, s.GetModelRoute("default/short-prefix"))
+	_, _, _, err = s.MatchModelServer("llama-2-7b", req, nil)
+	assert.Error(t, err)
+}
+
+func TestStoreMatchModelServerFallsBackToModelNameMatch(t *testing.T) {
+	s := &store{
+		routeInfo:  make(map[string]*modelRouteInfo),
+		routes:     make(map[string][]*aiv1alpha1.ModelRoute),
+		loraRoutes: make(map[string][]*aiv1alpha1.ModelRoute),
+	}
+	exact := newPatternTestRoute("exact", "llama-3-8b", nil, "exact-server")
+	exact.Spec.Rules[0].ModelMatch = &aiv1alpha1.ModelMatch{Headers: map[string]*aiv1alpha1.StringMatch{"X-Tenant": {Exact: ptr("a")}}}
+	assert.NoError(t, s.AddOrUpdateModelRoute(exact))
+	assert.NoError(t, s.AddOrUpdateModelRoute(newPatternTestRoute("prefix", "", &aiv1alpha1.StringMatch{Prefix: ptr("llama-")}, "prefix-server")))
+
+	req := &http.Request{URL: &url.URL{Path: "/v1/chat/completions"}, Header: http.Header{}}
+	server, _, _, err := s.MatchModelServer("llama-3-8b", req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, types.NamespacedName{Namespace: "default", Name: "prefix-server"}, server)
+
+	req.Header.Set("X-Tenant", "a")
+	server, _, _, err = s.MatchModelServer("llama-3-8b", req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, types.NamespacedName{Namespace: "default", Name: "exact-server"}, server)
+}
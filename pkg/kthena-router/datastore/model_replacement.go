@@ -0,0 +1,44 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import (
+	"sort"
+
+	"istio.io/istio/pkg/util/sets"
+)
+
+// PodsServingModel returns the pods still serving model, for the engines which swap the served model in place.
+// A pod is swapped once the models it reports, discovered periodically, don't include model anymore; the pods
+// whose models are not known yet are kept. When every pod is swapped, replacedBy lists the models they serve instead.
+func PodsServingModel(pods []*PodInfo, model string) (serving []*PodInfo, replacedBy []string) {
+	served := sets.New[string]()
+	for _, pod := range pods {
+		models := pod.GetModels()
+		if models.Len() == 0 || models.Contains(model) {
+			serving = append(serving, pod)
+			continue
+		}
+		served.Merge(models)
+	}
+	if len(serving) != 0 {
+		return serving, nil
+	}
+	replacedBy = served.UnsortedList()
+	sort.Strings(replacedBy)
+	return nil, replacedBy
+}
@@ -0,0 +1,44 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodsServingModel(t *testing.T) {
+	newPod := func(name string, models ...string) *PodInfo {
+		pod := &PodInfo{Pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}}
+		pod.UpdateModels(models)
+		return pod
+	}
+	unknown := newPod("unknown")
+	serving := newPod("serving", "llama-3", "lora-a")
+	swapped := newPod("swapped", "qwen-3")
+
+	pods, replacedBy := PodsServingModel([]*PodInfo{unknown, serving, swapped}, "llama-3")
+	assert.Equal(t, []*PodInfo{unknown, serving}, pods)
+	assert.Nil(t, replacedBy)
+
+	pods, replacedBy = PodsServingModel([]*PodInfo{swapped, newPod("swapped-2", "qwen-3", "mistral")}, "llama-3")
+	assert.Empty(t, pods)
+	assert.Equal(t, []string{"mistral", "qwen-3"}, replacedBy)
+}
@@ -108,13 +108,13 @@ func (m *modelServer) getPDGroupName(podLabels map[string]string) string {
 
 // removePodFromPDGroups removes a pod from all PDGroup categorizations
 func (m *modelServer) removePodFromPDGroups(podName types.NamespacedName, labels map[string]string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 	pdGroupName := m.getPDGroupName(labels)
 	if pdGroupName == "" {
 		return
 	}
 
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
 	if pdGroup, ok := m.pdGroups[pdGroupName]; ok {
 		pdGroup.RemovePod(podName)
 		// Clean up empty PDGroupPods
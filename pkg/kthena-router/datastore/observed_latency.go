@@ -0,0 +1,56 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import "time"
+
+const (
+	// observedTTFTWeight is the weight of a new observation in the moving average of the time to first token of a pod.
+	observedTTFTWeight = 0.2
+	// observedTTFTTTL is how long the time to first token observed on a pod is used, a pod left without requests
+	// because of its latency is probed again afterwards.
+	observedTTFTTTL = time.Minute
+)
+
+// RecordTTFT records the time to first token of a request the router sent to the pod.
+func (p *PodInfo) RecordTTFT(ttft time.Duration) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	now := time.Now()
+	if p.ttftObserved.IsZero() || now.Sub(p.ttftObserved) > observedTTFTTTL {
+		p.observedTTFT = ttft
+	} else {
+		p.observedTTFT += time.Duration(observedTTFTWeight * float64(ttft-p.observedTTFT))
+	}
+	p.ttftObserved = now
+}
+
+// ObservedTTFT returns the moving average of the time to first token observed by the router on the pod, false if
+// it has not been observed recently.
+func (p *PodInfo) ObservedTTFT() (time.Duration, bool) {
+	ttft, observed := p.getObservedTTFT()
+	if observed.IsZero() || time.Since(observed) > observedTTFTTTL {
+		return 0, false
+	}
+	return ttft, true
+}
+
+func (p *PodInfo) getObservedTTFT() (time.Duration, time.Time) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.observedTTFT, p.ttftObserved
+}
@@ -0,0 +1,70 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/types"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/utils"
+)
+
+func TestObservedTTFT(t *testing.T) {
+	pod := &PodInfo{}
+	_, observed := pod.ObservedTTFT()
+	assert.False(t, observed)
+
+	pod.RecordTTFT(100 * time.Millisecond)
+	ttft, observed := pod.ObservedTTFT()
+	assert.True(t, observed)
+	assert.Equal(t, 100*time.Millisecond, ttft)
+
+	pod.RecordTTFT(600 * time.Millisecond)
+	ttft, _ = pod.ObservedTTFT()
+	assert.Equal(t, 200*time.Millisecond, ttft)
+
+	// A stale observation is neither used nor averaged with the new ones.
+	pod.ttftObserved = time.Now().Add(-2 * observedTTFTTTL)
+	_, observed = pod.ObservedTTFT()
+	assert.False(t, observed)
+	pod.RecordTTFT(50 * time.Millisecond)
+	ttft, _ = pod.ObservedTTFT()
+	assert.Equal(t, 50*time.Millisecond, ttft)
+}
+
+func TestObservedTTFTKeptOnPodUpdate(t *testing.T) {
+	patch := setupMockBackend()
+	defer patch.Reset()
+
+	s := New()
+	ms := createTestModelServer("default", "model1", aiv1alpha1.VLLM)
+	pod := createTestPod("default", "pod1")
+	require.NoError(t, s.AddOrUpdateModelServer(ms, sets.New[types.NamespacedName]()))
+	require.NoError(t, s.AddOrUpdatePod(pod, []*aiv1alpha1.ModelServer{ms}))
+	s.GetPodInfo(utils.GetNamespaceName(pod)).RecordTTFT(time.Second)
+
+	require.NoError(t, s.AddOrUpdatePod(pod.DeepCopy(), []*aiv1alpha1.ModelServer{ms}))
+	ttft, observed := s.GetPodInfo(utils.GetNamespaceName(pod)).ObservedTTFT()
+	assert.True(t, observed)
+	assert.Equal(t, time.Second, ttft)
+}
@@ -284,3 +284,62 @@ func TestPDGroupPodRemoval(t *testing.T) {
 		t.Errorf("Expected 0 decode pods after deletion, got %d", len(decodePods))
 	}
 }
+
+func TestPDGroupPodRelabeling(t *testing.T) {
+	store := New()
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-model",
+			Namespace: "default",
+		},
+		Spec: aiv1alpha1.ModelServerSpec{
+			WorkloadSelector: &aiv1alpha1.WorkloadSelector{
+				PDGroup: &aiv1alpha1.PDGroup{
+					GroupKey:      "pd-group",
+					DecodeLabels:  map[string]string{"role": "decode"},
+					PrefillLabels: map[string]string{"role": "prefill"},
+				},
+			},
+		},
+	}
+	modelServerName := types.NamespacedName{Namespace: "default", Name: "test-model"}
+	if err := store.AddOrUpdateModelServer(modelServer, nil); err != nil {
+		t.Fatalf("Failed to add model server: %v", err)
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pd-pod",
+			Namespace: "default",
+			Labels:    map[string]string{"pd-group": "group-a", "role": "decode"},
+		},
+		Status: corev1.PodStatus{
+			PodIP: "10.0.0.1",
+		},
+	}
+	if err := store.AddOrUpdatePod(pod, []*aiv1alpha1.ModelServer{modelServer}); err != nil {
+		t.Fatalf("Failed to add pod: %v", err)
+	}
+
+	// The pod changes both its role and its PD group
+	relabeled := pod.DeepCopy()
+	relabeled.Labels = map[string]string{"pd-group": "group-b", "role": "prefill"}
+	if err := store.AddOrUpdatePod(relabeled, []*aiv1alpha1.ModelServer{modelServer}); err != nil {
+		t.Fatalf("Failed to update pod: %v", err)
+	}
+
+	decodePods, err := store.GetDecodePods(modelServerName)
+	if err != nil {
+		t.Fatalf("Failed to get decode pods: %v", err)
+	}
+	if len(decodePods) != 0 {
+		t.Errorf("Expected 0 decode pods after relabeling, got %d", len(decodePods))
+	}
+	prefillPods, err := store.GetPrefillPods(modelServerName)
+	if err != nil {
+		t.Fatalf("Failed to get prefill pods: %v", err)
+	}
+	if len(prefillPods) != 1 {
+		t.Errorf("Expected 1 prefill pod after relabeling, got %d", len(prefillPods))
+	}
+}
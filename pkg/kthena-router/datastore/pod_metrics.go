@@ -0,0 +1,53 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import "time"
+
+const (
+	// DefaultMetricsScrapeInterval is the default interval between two scrapes of the metrics of the pods.
+	DefaultMetricsScrapeInterval = time.Second
+	// metricsStaleIntervals is the number of scrape intervals after which the metrics of a pod not scraped are stale.
+	metricsStaleIntervals = 3
+)
+
+// metricsScrapeInterval is the interval between two scrapes of the metrics of the pods.
+var metricsScrapeInterval = DefaultMetricsScrapeInterval
+
+// SetMetricsScrapeInterval sets the interval between two scrapes of the metrics of the pods.
+// A non-positive interval restores the default.
+func SetMetricsScrapeInterval(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultMetricsScrapeInterval
+	}
+	metricsScrapeInterval = interval
+}
+
+// RecordMetricsScrape records that the metrics of the pod were scraped at now.
+func (p *PodInfo) RecordMetricsScrape(now time.Time) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.metricsScraped = now
+}
+
+// MetricsFresh returns whether the metrics of the pod were scraped within the last scrape intervals. The metrics of
+// a pod whose metrics endpoint doesn't answer, or not scraped yet, are stale.
+func (p *PodInfo) MetricsFresh() bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return !p.metricsScraped.IsZero() && time.Since(p.metricsScraped) <= metricsStaleIntervals*metricsScrapeInterval
+}
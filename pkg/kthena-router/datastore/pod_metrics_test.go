@@ -0,0 +1,68 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/backend"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/utils"
+)
+
+func TestUpdatePodMetricsStaleness(t *testing.T) {
+	var available bool
+	patch := gomonkey.ApplyFunc(backend.GetPodMetrics, func(engine string, pod *corev1.Pod, previousHistogram map[string]*dto.Histogram) (map[string]float64, map[string]*dto.Histogram) {
+		if !available {
+			return nil, nil
+		}
+		return map[string]float64{utils.GPUCacheUsage: 0.7}, map[string]*dto.Histogram{}
+	})
+	defer patch.Reset()
+
+	s := New().(*store)
+	pod := &PodInfo{Pod: createTestPod("default", "pod1"), engine: "vLLM"}
+	s.updatePodMetrics(pod)
+	assert.False(t, pod.MetricsFresh())
+
+	available = true
+	s.updatePodMetrics(pod)
+	assert.True(t, pod.MetricsFresh())
+	assert.Equal(t, 0.7, pod.GetGPUCacheUsage())
+
+	// The metrics are kept while unavailable, until they are stale.
+	available = false
+	s.updatePodMetrics(pod)
+	assert.True(t, pod.MetricsFresh())
+	assert.Equal(t, 0.7, pod.GetGPUCacheUsage())
+	pod.RecordMetricsScrape(time.Now().Add(-metricsStaleIntervals*metricsScrapeInterval - time.Second))
+	assert.False(t, pod.MetricsFresh())
+	assert.Equal(t, 0.7, pod.GetGPUCacheUsage())
+}
+
+func TestSetMetricsScrapeInterval(t *testing.T) {
+	defer SetMetricsScrapeInterval(DefaultMetricsScrapeInterval)
+	SetMetricsScrapeInterval(5 * time.Second)
+	assert.Equal(t, 5*time.Second, metricsScrapeInterval)
+	SetMetricsScrapeInterval(0)
+	assert.Equal(t, DefaultMetricsScrapeInterval, metricsScrapeInterval)
+}
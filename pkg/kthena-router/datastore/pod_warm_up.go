@@ -0,0 +1,45 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+// IsWarmingUp returns whether the router is sending the warm-up request of its model server to the pod. The pod is
+// not routed requests to meanwhile.
+func (p *PodInfo) IsWarmingUp() bool {
+	return p.warmingUp != nil && p.warmingUp.Load()
+}
+
+// FinishWarmUp marks the warm-up of the pod as done, whether the warm-up request succeeded or not.
+func (p *PodInfo) FinishWarmUp() {
+	if p.warmingUp != nil {
+		p.warmingUp.Store(false)
+	}
+}
+
+// PodsWarmedUp returns the pods which are not warming up. If all the pods are warming up, e.g. the pods of a new
+// model server or after the router restarted, they are all returned rather than rejecting the requests.
+func PodsWarmedUp(pods []*PodInfo) []*PodInfo {
+	warmedUp := make([]*PodInfo, 0, len(pods))
+	for _, pod := range pods {
+		if !pod.IsWarmingUp() {
+			warmedUp = append(warmedUp, pod)
+		}
+	}
+	if len(warmedUp) == 0 {
+		return pods
+	}
+	return warmedUp
+}
@@ -0,0 +1,86 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/types"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/utils"
+)
+
+func TestPodWarmUp(t *testing.T) {
+	patch := setupMockBackend()
+	defer patch.Reset()
+
+	s := New()
+	added := make(chan types.NamespacedName, 10)
+	s.RegisterCallback("Pod", func(data EventData) {
+		if data.EventType == EventAdd {
+			added <- data.Pod
+		}
+	})
+	ms := createTestModelServer("default", "model1", aiv1alpha1.VLLM)
+	ms.Spec.WarmUp = &aiv1alpha1.WarmUp{}
+	require.NoError(t, s.AddOrUpdateModelServer(ms, sets.New[types.NamespacedName]()))
+
+	pod := createTestPod("default", "pod1")
+	require.NoError(t, s.AddOrUpdatePod(pod, []*aiv1alpha1.ModelServer{ms}))
+	podName := utils.GetNamespaceName(pod)
+	select {
+	case name := <-added:
+		assert.Equal(t, podName, name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("pod added callback not triggered")
+	}
+	assert.True(t, s.GetPodInfo(podName).IsWarmingUp())
+
+	// The warm-up outlives the updates of the pod, which don't warm it up again.
+	require.NoError(t, s.AddOrUpdatePod(pod.DeepCopy(), []*aiv1alpha1.ModelServer{ms}))
+	assert.True(t, s.GetPodInfo(podName).IsWarmingUp())
+	s.GetPodInfo(podName).FinishWarmUp()
+	require.NoError(t, s.AddOrUpdatePod(pod.DeepCopy(), []*aiv1alpha1.ModelServer{ms}))
+	assert.False(t, s.GetPodInfo(podName).IsWarmingUp())
+	assert.Empty(t, added)
+
+	// The pods of the model servers without warm-up are not warmed up.
+	other := createTestModelServer("default", "model2", aiv1alpha1.VLLM)
+	require.NoError(t, s.AddOrUpdateModelServer(other, sets.New[types.NamespacedName]()))
+	otherPod := createTestPod("default", "pod2")
+	require.NoError(t, s.AddOrUpdatePod(otherPod, []*aiv1alpha1.ModelServer{other}))
+	assert.False(t, s.GetPodInfo(utils.GetNamespaceName(otherPod)).IsWarmingUp())
+}
+
+func TestPodsWarmedUp(t *testing.T) {
+	warmingUp := func() *PodInfo {
+		pod := &PodInfo{warmingUp: &atomic.Bool{}}
+		pod.warmingUp.Store(true)
+		return pod
+	}
+	warm, cold := &PodInfo{}, warmingUp()
+	assert.Equal(t, []*PodInfo{warm}, PodsWarmedUp([]*PodInfo{warm, cold}))
+	// The pods are all returned when they are all warming up.
+	other := warmingUp()
+	assert.Equal(t, []*PodInfo{cold, other}, PodsWarmedUp([]*PodInfo{cold, other}))
+}
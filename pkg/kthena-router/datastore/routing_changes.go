@@ -0,0 +1,199 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// routingChangeLogSize is the number of routing changes kept for the debug endpoint.
+	routingChangeLogSize = 256
+
+	// RoutingChangeType is the type of the JSON log lines of the routing changes,
+	// telling them apart from the access log entries written to the same output.
+	RoutingChangeType = "routing_change"
+
+	RoutingChangeAdded   = "Added"
+	RoutingChangeUpdated = "Updated"
+	RoutingChangeDeleted = "Deleted"
+)
+
+// RoutingChange records a change of a resource the router routes requests with.
+type RoutingChange struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Action    string    `json:"action"`
+	// Fields are the spec fields changed by an update.
+	Fields          []string `json:"fields,omitempty"`
+	Generation      int64    `json:"generation,omitempty"`
+	ResourceVersion string   `json:"resourceVersion,omitempty"`
+	// PropagationLatency is the time in milliseconds between the last write of the resource to the API server and
+	// the change of the routing table. Kubernetes records the writes with a second precision.
+	PropagationLatency int64 `json:"propagationLatency,omitempty"`
+}
+
+// routingChangeLog writes the routing changes as JSON lines and keeps the latest ones in a ring buffer.
+type routingChangeLog struct {
+	mutex   sync.Mutex
+	changes []RoutingChange
+	next    int
+	output  io.Writer
+}
+
+func newRoutingChangeLog() *routingChangeLog {
+	return &routingChangeLog{
+		changes: make([]RoutingChange, 0, routingChangeLogSize),
+		output:  os.Stdout,
+	}
+}
+
+// record logs the change of a resource from old to new, old is nil for an added resource and new for a deleted one.
+// An update not changing the spec, e.g. a status update, doesn't change the routing table and is not recorded.
+// The changes are not recorded by a nil log.
+func (l *routingChangeLog) record(kind string, old, new metav1.Object) {
+	if l == nil {
+		return
+	}
+	change := RoutingChange{Type: RoutingChangeType, Timestamp: time.Now(), Kind: kind}
+	current := new
+	switch {
+	case old == nil && new == nil:
+		return
+	case old == nil:
+		change.Action = RoutingChangeAdded
+	case new == nil:
+		change.Action = RoutingChangeDeleted
+		current = old
+	default:
+		change.Action = RoutingChangeUpdated
+		change.Fields = changedSpecFields(old, new)
+		if len(change.Fields) == 0 {
+			return
+		}
+	}
+	change.Namespace = current.GetNamespace()
+	change.Name = current.GetName()
+	change.Generation = current.GetGeneration()
+	change.ResourceVersion = current.GetResourceVersion()
+	if new != nil {
+		if written := lastWriteTime(new); !written.IsZero() {
+			change.PropagationLatency = change.Timestamp.Sub(written).Milliseconds()
+		}
+	} else if deleted := old.GetDeletionTimestamp(); deleted != nil {
+		change.PropagationLatency = change.Timestamp.Sub(deleted.Time).Milliseconds()
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if len(l.changes) < routingChangeLogSize {
+		l.changes = append(l.changes, change)
+	} else {
+		l.changes[l.next] = change
+	}
+	l.next = (l.next + 1) % routingChangeLogSize
+	data, err := json.Marshal(change)
+	if err != nil {
+		klog.Errorf("failed to marshal routing change of %s %s/%s: %v", kind, change.Namespace, change.Name, err)
+		return
+	}
+	if _, err := fmt.Fprintln(l.output, string(data)); err != nil {
+		klog.Errorf("failed to write routing change: %v", err)
+	}
+}
+
+// list returns the recorded routing changes, oldest first.
+func (l *routingChangeLog) list() []RoutingChange {
+	if l == nil {
+		return nil
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	changes := make([]RoutingChange, 0, len(l.changes))
+	if len(l.changes) == routingChangeLogSize {
+		changes = append(changes, l.changes[l.next:]...)
+		return append(changes, l.changes[:l.next]...)
+	}
+	return append(changes, l.changes...)
+}
+
+// changedSpecFields returns the top-level spec fields that differ between the objects.
+func changedSpecFields(old, new metav1.Object) []string {
+	oldSpec, newSpec := specFields(old), specFields(new)
+	var fields []string
+	for name, value := range newSpec {
+		if !bytes.Equal(oldSpec[name], value) {
+			fields = append(fields, name)
+		}
+	}
+	for name := range oldSpec {
+		if _, ok := newSpec[name]; !ok {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func specFields(obj metav1.Object) map[string]json.RawMessage {
+	var object struct {
+		Spec map[string]json.RawMessage `json:"spec"`
+	}
+	data, err := json.Marshal(obj)
+	if err == nil {
+		err = json.Unmarshal(data, &object)
+	}
+	if err != nil {
+		klog.Errorf("failed to read the spec of %s/%s: %v", obj.GetNamespace(), obj.GetName(), err)
+	}
+	return object.Spec
+}
+
+// lastWriteTime returns the time of the last write of the object to the API server.
+func lastWriteTime(obj metav1.Object) time.Time {
+	written := obj.GetCreationTimestamp().Time
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Time != nil && entry.Time.After(written) {
+			written = entry.Time.Time
+		}
+	}
+	return written
+}
+
+// nullable returns obj as a metav1.Object, nil if obj is a nil pointer.
+func nullable[T any, P interface {
+	*T
+	metav1.Object
+}](obj P) metav1.Object {
+	if obj == nil {
+		return nil
+	}
+	return obj
+}
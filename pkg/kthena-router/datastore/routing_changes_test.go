@@ -0,0 +1,93 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+func TestStore_RoutingChanges(t *testing.T) {
+	s := New().(*store)
+	var output bytes.Buffer
+	s.routingChanges.output = &output
+
+	written := metav1.NewTime(time.Now().Add(-2 * time.Second))
+	mr := &aiv1alpha1.ModelRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            "llama",
+			Generation:      1,
+			ResourceVersion: "100",
+			ManagedFields:   []metav1.ManagedFieldsEntry{{Manager: "kubectl", Time: &written}},
+		},
+		Spec: aiv1alpha1.ModelRouteSpec{ModelName: "llama"},
+	}
+	require.NoError(t, s.AddOrUpdateModelRoute(mr))
+
+	// A resync of the same route doesn't change the routing table.
+	require.NoError(t, s.AddOrUpdateModelRoute(mr.DeepCopy()))
+
+	updated := mr.DeepCopy()
+	updated.Generation = 2
+	updated.ResourceVersion = "101"
+	updated.Spec.LoraAdapters = []string{"lora-a"}
+	require.NoError(t, s.AddOrUpdateModelRoute(updated))
+	require.NoError(t, s.DeleteModelRoute("default/llama"))
+
+	changes := s.GetRoutingChanges()
+	require.Len(t, changes, 3)
+	assert.Equal(t, RoutingChangeAdded, changes[0].Action)
+	assert.Equal(t, "ModelRoute", changes[0].Kind)
+	assert.Equal(t, int64(1), changes[0].Generation)
+	assert.Equal(t, "100", changes[0].ResourceVersion)
+	assert.GreaterOrEqual(t, changes[0].PropagationLatency, int64(2000))
+	assert.Equal(t, RoutingChangeUpdated, changes[1].Action)
+	assert.Equal(t, []string{"loraAdapters"}, changes[1].Fields)
+	assert.Equal(t, int64(2), changes[1].Generation)
+	assert.Equal(t, RoutingChangeDeleted, changes[2].Action)
+	assert.Equal(t, "llama", changes[2].Name)
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	require.Len(t, lines, 3)
+	var logged RoutingChange
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &logged))
+	assert.Equal(t, RoutingChangeType, logged.Type)
+	assert.Equal(t, []string{"loraAdapters"}, logged.Fields)
+}
+
+func TestRoutingChangeLog_Ring(t *testing.T) {
+	l := newRoutingChangeLog()
+	l.output = &bytes.Buffer{}
+	for i := 0; i < routingChangeLogSize+10; i++ {
+		l.record("ModelServer", nil, &aiv1alpha1.ModelServer{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("ms-%d", i)}})
+	}
+	changes := l.list()
+	require.Len(t, changes, routingChangeLogSize)
+	assert.Equal(t, "ms-10", changes[0].Name)
+	assert.Equal(t, fmt.Sprintf("ms-%d", routingChangeLogSize+9), changes[routingChangeLogSize-1].Name)
+}
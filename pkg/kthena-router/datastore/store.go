@@ -17,12 +17,15 @@ limitations under the License.
 package datastore
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"maps"
 	"math/rand"
 	"net/http"
 	"os"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -39,6 +42,7 @@ import (
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	workloadv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/backend"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/utils"
 	inferencev1 "sigs.k8s.io/gateway-api-inference-extension/api/v1"
@@ -62,7 +66,6 @@ var (
 const (
 	// Configuration constants for fairness scheduling
 	defaultQueueQPS = 100
-	uppdateInterval = 1 * time.Second
 )
 
 // createTokenTracker creates a token tracker with configuration from environment variables
@@ -125,6 +128,9 @@ type EventData struct {
 
 	ModelName  string
 	ModelRoute *aiv1alpha1.ModelRoute
+
+	RateLimitPolicy types.NamespacedName
+	TokenQuota      types.NamespacedName
 }
 
 // CallbackFunc is the type of function that can be registered as a callback
@@ -148,7 +154,10 @@ type Store interface {
 	DeletePod(podName types.NamespacedName) error
 
 	// New methods for routing functionality
-	MatchModelServer(modelName string, request *http.Request, gatewayKey string) (types.NamespacedName, bool, *aiv1alpha1.ModelRoute, error)
+	// The gatewayKeys are the Gateways whose listeners received the request, none if it didn't come through a Gateway
+	MatchModelServer(modelName string, request *http.Request, gatewayKeys []string) (types.NamespacedName, bool, *aiv1alpha1.ModelRoute, error)
+	// MatchModelRoute is MatchModelServer returning the rule of the ModelRoute which matched as well
+	MatchModelRoute(modelName string, request *http.Request, gatewayKeys []string) (*RouteMatch, error)
 
 	// Model routing methods
 	AddOrUpdateModelRoute(mr *aiv1alpha1.ModelRoute) error
@@ -201,12 +210,45 @@ type Store interface {
 	GetHTTPRoute(key string) *gatewayv1.HTTPRoute
 	GetAllHTTPRoutes() []*gatewayv1.HTTPRoute
 	GetHTTPRoutesByGateway(gatewayKey string) []*gatewayv1.HTTPRoute
+
+	// GRPCRoute methods (using standard Gateway API)
+	AddOrUpdateGRPCRoute(grpcRoute *gatewayv1.GRPCRoute) error
+	DeleteGRPCRoute(key string) error
+	GetGRPCRoute(key string) *gatewayv1.GRPCRoute
+	GetAllGRPCRoutes() []*gatewayv1.GRPCRoute
+	GetGRPCRoutesByGateway(gatewayKey string) []*gatewayv1.GRPCRoute
+
+	// ModelServing methods, the ModelServings only describe the served models in the model catalog
+	AddOrUpdateModelServing(modelServing *workloadv1alpha1.ModelServing) error
+	DeleteModelServing(name types.NamespacedName) error
+	GetModelServing(name types.NamespacedName) *workloadv1alpha1.ModelServing
+
+	// Secret methods, only the Secrets holding the CA bundles of the https ModelServers are stored
+	AddOrUpdateSecret(secret *corev1.Secret) error
+	DeleteSecret(name types.NamespacedName) error
+	GetSecret(name types.NamespacedName) *corev1.Secret
+
+	// RateLimitPolicy methods
+	AddOrUpdateRateLimitPolicy(policy *aiv1alpha1.RateLimitPolicy) error
+	DeleteRateLimitPolicy(name types.NamespacedName) error
+	// GetRateLimitPolicies returns the RateLimitPolicies of a namespace, sorted by name
+	GetRateLimitPolicies(namespace string) []*aiv1alpha1.RateLimitPolicy
+
+	// TokenQuota methods
+	AddOrUpdateTokenQuota(quota *aiv1alpha1.TokenQuota) error
+	DeleteTokenQuota(name types.NamespacedName) error
+	// GetTokenQuotas returns the TokenQuotas of a namespace, or of all the namespaces if it is empty, sorted by
+	// namespace and name
+	GetTokenQuotas(namespace string) []*aiv1alpha1.TokenQuota
 	GetModelRoutesByGateway(gatewayKey string) []*aiv1alpha1.ModelRoute
 
 	// Debug interface methods
 	GetAllModelRoutes() map[string]*aiv1alpha1.ModelRoute
 	GetAllModelServers() map[types.NamespacedName]*aiv1alpha1.ModelServer
 	GetAllPods() map[types.NamespacedName]*PodInfo
+
+	// GetRoutingChanges returns the latest changes of the routing resources, oldest first
+	GetRoutingChanges() []RoutingChange
 }
 
 // QueueStat holds per-model queue metrics to aid scheduling decisions
@@ -229,6 +271,17 @@ type PodInfo struct {
 	TPOT               float64
 	TTFT               float64
 
+	// metricsScraped is when the metrics of the pod were last scraped.
+	metricsScraped time.Time
+
+	// observedTTFT is the moving average of the time to first token observed by the router, recorded at ttftObserved.
+	observedTTFT time.Duration
+	ttftObserved time.Time
+
+	// warmingUp is set while the router sends the warm-up request of its model server to the pod, it is shared by
+	// the successive PodInfo of the pod.
+	warmingUp *atomic.Bool
+
 	mutex sync.RWMutex // Protects concurrent access to metrics, models and modelServer fields
 	// Protected fields - use accessor methods for thread-safe access
 	models      sets.Set[string]               // running models. Including base model and lora adapters.
@@ -245,6 +298,9 @@ type modelRouteInfo struct {
 	// loras is a list of LoRA adapter names that this route serves.
 	// These adapters can be used to modify the behavior of the primary model.
 	loras []string
+
+	// aliases are the other names of the primary model that this route serves.
+	aliases []string
 }
 
 type store struct {
@@ -257,6 +313,7 @@ type store struct {
 	routes             map[string][]*aiv1alpha1.ModelRoute // key: model name, value: list of ModelRoutes
 	loraRoutes         map[string][]*aiv1alpha1.ModelRoute // key: lora name, value: list of ModelRoutes
 	gatewayModelRoutes map[string]sets.Set[string]         // key: gateway key (namespace/name), value: set of ModelRoute keys
+	patternRoutes      []*patternRoute                     // ModelRoutes matching the models by pattern, in the order of their precedence
 
 	// Gateway fields (using standard Gateway API)
 	gatewayMutex sync.RWMutex
@@ -270,6 +327,20 @@ type store struct {
 	httpRouteMutex sync.RWMutex
 	httpRoutes     map[string]*gatewayv1.HTTPRoute // key: namespace/name, value: *gatewayv1.HTTPRoute
 	gatewayRoutes  map[string]sets.Set[string]     // key: gateway key (namespace/name), value: set of HTTPRoute keys
+
+	// GRPCRoute fields (using standard Gateway API)
+	grpcRouteMutex    sync.RWMutex
+	grpcRoutes        map[string]*gatewayv1.GRPCRoute // key: namespace/name, value: *gatewayv1.GRPCRoute
+	gatewayGRPCRoutes map[string]sets.Set[string]     // key: gateway key (namespace/name), value: set of GRPCRoute keys
+
+	modelServings sync.Map // map[types.NamespacedName]*workloadv1alpha1.ModelServing
+	secrets       sync.Map // map[types.NamespacedName]*corev1.Secret
+
+	rateLimitPolicies sync.Map // map[types.NamespacedName]*aiv1alpha1.RateLimitPolicy
+	tokenQuotas       sync.Map // map[types.NamespacedName]*aiv1alpha1.TokenQuota
+
+	routingChanges *routingChangeLog
+
 	// New fields for callback management
 	callbacks map[string][]CallbackFunc
 
@@ -292,7 +363,10 @@ func New() Store {
 		inferencePools:      make(map[string]*inferencev1.InferencePool),
 		httpRoutes:          make(map[string]*gatewayv1.HTTPRoute),
 		gatewayRoutes:       make(map[string]sets.Set[string]),
+		grpcRoutes:          make(map[string]*gatewayv1.GRPCRoute),
+		gatewayGRPCRoutes:   make(map[string]sets.Set[string]),
 		callbacks:           make(map[string][]CallbackFunc),
+		routingChanges:      newRoutingChangeLog(),
 		initialSynced:       &atomic.Bool{},
 		requestWaitingQueue: sync.Map{},
 		// Create token tracker with environment-based configuration
@@ -307,15 +381,22 @@ func (s *store) Run(ctx context.Context) {
 			case <-ctx.Done():
 				return
 			default:
+				// The pods are scraped concurrently, so that a pod slow to answer doesn't delay the metrics of the others.
+				var wg sync.WaitGroup
 				s.pods.Range(func(key, value any) bool {
 					if p, ok := value.(*PodInfo); ok {
-						s.updatePodMetrics(p)
-						s.updatePodModels(p)
+						wg.Add(1)
+						go func() {
+							defer wg.Done()
+							s.updatePodMetrics(p)
+							s.updatePodModels(p)
+						}()
 					}
 					return true
 				})
+				wg.Wait()
 				s.initialSynced.Store(true)
-				time.Sleep(uppdateInterval)
+				time.Sleep(metricsScrapeInterval)
 			}
 		}
 	}()
@@ -381,18 +462,24 @@ func (s *store) GetPodInfo(podName types.NamespacedName) *PodInfo {
 func (s *store) AddOrUpdateModelServer(ms *aiv1alpha1.ModelServer, pods sets.Set[types.NamespacedName]) error {
 	name := utils.GetNamespaceName(ms)
 	var modelServerObj *modelServer
+	var old *aiv1alpha1.ModelServer
 	if value, ok := s.modelServer.Load(name); !ok {
 		modelServerObj = newModelServer(ms)
 	} else {
 		modelServerObj = value.(*modelServer)
-		modelServerObj.modelServer = ms
+		old = modelServerObj.modelServer
 	}
 
+	// The pods of the model server may be updated concurrently, e.g. by the discovery of its instances
+	modelServerObj.mutex.Lock()
+	modelServerObj.modelServer = ms
 	if len(pods) != 0 {
 		// do not operate s.pods here, which are done within pod handler
 		modelServerObj.pods = pods
 	}
+	modelServerObj.mutex.Unlock()
 	s.modelServer.Store(name, modelServerObj)
+	s.routingChanges.record("ModelServer", nullable(old), ms)
 	return nil
 }
 
@@ -402,6 +489,7 @@ func (s *store) DeleteModelServer(ms types.NamespacedName) error {
 		return nil
 	}
 	modelServerObj := value.(*modelServer)
+	s.routingChanges.record("ModelServer", modelServerObj.modelServer, nil)
 	podNames := modelServerObj.getPods()
 	// then delete the model server from all pod info
 	for _, podName := range podNames {
@@ -518,23 +606,36 @@ func (s *store) AddOrUpdatePod(pod *corev1.Pod, modelServers []*aiv1alpha1.Model
 		models:      sets.New[string](),
 	}
 
+	var oldPodInfo *PodInfo
+	if value, ok := s.pods.Load(podName); ok {
+		oldPodInfo = value.(*PodInfo)
+	}
+
 	for _, ms := range modelServers {
 		modelServerName := utils.GetNamespaceName(ms)
 		newPodInfo.AddModelServer(modelServerName)
+		if oldPodInfo == nil && ms.Spec.WarmUp != nil {
+			newPodInfo.warmingUp = &atomic.Bool{}
+			newPodInfo.warmingUp.Store(true)
+		}
 		// NOTE: even if a pod belongs to multiple model servers, the backend should be the same
 		newPodInfo.engine = string(ms.Spec.InferenceEngine)
 		if value, ok := s.modelServer.Load(modelServerName); ok {
 			ms := value.(*modelServer)
 			ms.addPod(podName)
+			// The role or the PD group of the pod may have changed with its labels
+			if oldPodInfo != nil && !maps.Equal(oldPodInfo.Pod.Labels, pod.Labels) {
+				ms.removePodFromPDGroups(podName, oldPodInfo.Pod.Labels)
+			}
 			// Categorize the pod for PDGroup scheduling
 			klog.V(4).Infof("Categorizing pod %s for PDGroup scheduling, model server %s", podName, modelServerName)
 			ms.categorizePodForPDGroup(podName, pod.Labels)
 		}
 	}
 
-	var oldPodInfo *PodInfo
-	if value, ok := s.pods.Load(podName); ok {
-		oldPodInfo = value.(*PodInfo)
+	if oldPodInfo != nil {
+		newPodInfo.observedTTFT, newPodInfo.ttftObserved = oldPodInfo.getObservedTTFT()
+		newPodInfo.warmingUp = oldPodInfo.warmingUp
 		oldModelServers := oldPodInfo.GetModelServers()
 		// Handle the case where the pod is no longer belong to some model servers
 		for msName := range oldModelServers.Difference(newPodInfo.modelServer) {
@@ -552,6 +653,12 @@ func (s *store) AddOrUpdatePod(pod *corev1.Pod, modelServers []*aiv1alpha1.Model
 	if oldPodInfo == nil {
 		s.updatePodMetrics(newPodInfo)
 		s.updatePodModels(newPodInfo)
+		if newPodInfo.IsWarmingUp() {
+			s.triggerCallbacks("Pod", EventData{
+				EventType: EventAdd,
+				Pod:       podName,
+			})
+		}
 	}
 
 	return nil
@@ -621,27 +728,36 @@ func (s *store) DeletePod(podName types.NamespacedName) error {
 
 // Model routing methods
 func (s *store) AddOrUpdateModelRoute(mr *aiv1alpha1.ModelRoute) error {
-	s.routeMutex.Lock()
 	key := mr.Namespace + "/" + mr.Name
+	old := s.GetModelRoute(key)
+	s.routeMutex.Lock()
+	// The models and LoRA adapters the previous version of the route served, and no longer serves, aren't routed to it
+	if info := s.routeInfo[key]; info != nil {
+		newModels := sets.New(routedModels(mr.Spec.ModelName, mr.Spec.Aliases)...)
+		newLoras := sets.New(mr.Spec.LoraAdapters...)
+		removeRoute(s.routes, sets.New(routedModels(info.model, info.aliases)...).Difference(newModels).UnsortedList(), key)
+		removeRoute(s.loraRoutes, sets.New(info.loras...).Difference(newLoras).UnsortedList(), key)
+	}
 	s.routeInfo[key] = &modelRouteInfo{
-		model: mr.Spec.ModelName,
-		loras: mr.Spec.LoraAdapters,
+		model:   mr.Spec.ModelName,
+		loras:   mr.Spec.LoraAdapters,
+		aliases: mr.Spec.Aliases,
 	}
 
-	if mr.Spec.ModelName != "" {
+	for _, model := range routedModels(mr.Spec.ModelName, mr.Spec.Aliases) {
 		// Check if this ModelRoute already exists in the slice
-		routes := s.routes[mr.Spec.ModelName]
+		routes := s.routes[model]
 		found := false
 		for i, route := range routes {
 			if route.Namespace == mr.Namespace && route.Name == mr.Name {
-				routes[i] = mr                       // Update existing
-				s.routes[mr.Spec.ModelName] = routes // Update the map
+				routes[i] = mr           // Update existing
+				s.routes[model] = routes // Update the map
 				found = true
 				break
 			}
 		}
 		if !found {
-			s.routes[mr.Spec.ModelName] = append(routes, mr)
+			s.routes[model] = append(routes, mr)
 		}
 	}
 
@@ -662,16 +778,12 @@ func (s *store) AddOrUpdateModelRoute(mr *aiv1alpha1.ModelRoute) error {
 		}
 	}
 
-	// Update gateway model routes mapping
-	for _, parentRef := range mr.Spec.ParentRefs {
-		if parentRef.Kind != nil && *parentRef.Kind == "Gateway" {
-			gatewayName := string(parentRef.Name)
-			gatewayNamespace := mr.Namespace
-			if parentRef.Namespace != nil {
-				gatewayNamespace = string(*parentRef.Namespace)
-			}
-			gatewayKey := fmt.Sprintf("%s/%s", gatewayNamespace, gatewayName)
+	s.setPatternRoute(mr)
 
+	// Update gateway model routes mapping, the previous parents may have been removed from the route
+	removeGatewayRoute(s.gatewayModelRoutes, key)
+	for _, parentRef := range mr.Spec.ParentRefs {
+		if gatewayKey, ok := GatewayParentKey(mr.Namespace, parentRef); ok {
 			if s.gatewayModelRoutes[gatewayKey] == nil {
 				s.gatewayModelRoutes[gatewayKey] = sets.New[string]()
 			}
@@ -680,6 +792,7 @@ func (s *store) AddOrUpdateModelRoute(mr *aiv1alpha1.ModelRoute) error {
 	}
 
 	s.routeMutex.Unlock()
+	s.routingChanges.record("ModelRoute", nullable(old), mr)
 
 	s.triggerCallbacks("ModelRoute", EventData{
 		EventType:  EventUpdate,
@@ -696,64 +809,17 @@ func (s *store) DeleteModelRoute(namespacedName string) error {
 	var deletedRoute *aiv1alpha1.ModelRoute
 	if info != nil {
 		modelName = info.model
-		// Remove from routes map
-		if modelName != "" {
-			routes := s.routes[modelName]
-			newRoutes := make([]*aiv1alpha1.ModelRoute, 0, len(routes))
-			for _, route := range routes {
-				routeKey := route.Namespace + "/" + route.Name
-				if routeKey != namespacedName {
-					newRoutes = append(newRoutes, route)
-				} else {
-					deletedRoute = route
-				}
-			}
-			if len(newRoutes) == 0 {
-				delete(s.routes, modelName)
-			} else {
-				s.routes[modelName] = newRoutes
-			}
-		}
-		// Remove from loraRoutes map
-		for _, lora := range info.loras {
-			loraRoutes := s.loraRoutes[lora]
-			newLoraRoutes := make([]*aiv1alpha1.ModelRoute, 0, len(loraRoutes))
-			for _, route := range loraRoutes {
-				routeKey := route.Namespace + "/" + route.Name
-				if routeKey != namespacedName {
-					newLoraRoutes = append(newLoraRoutes, route)
-				} else if deletedRoute == nil {
-					deletedRoute = route
-				}
-			}
-			if len(newLoraRoutes) == 0 {
-				delete(s.loraRoutes, lora)
-			} else {
-				s.loraRoutes[lora] = newLoraRoutes
-			}
+		deletedRoute = removeRoute(s.routes, routedModels(info.model, info.aliases), namespacedName)
+		if route := removeRoute(s.loraRoutes, info.loras, namespacedName); deletedRoute == nil {
+			deletedRoute = route
 		}
 	}
+	if route := s.removePatternRoute(namespacedName); deletedRoute == nil {
+		deletedRoute = route
+	}
 
 	// Remove from gateway model routes mapping
-	if deletedRoute != nil {
-		for _, parentRef := range deletedRoute.Spec.ParentRefs {
-			if parentRef.Kind != nil && *parentRef.Kind == "Gateway" {
-				gatewayName := string(parentRef.Name)
-				gatewayNamespace := deletedRoute.Namespace
-				if parentRef.Namespace != nil {
-					gatewayNamespace = string(*parentRef.Namespace)
-				}
-				gatewayKey := fmt.Sprintf("%s/%s", gatewayNamespace, gatewayName)
-
-				if routeSet, exists := s.gatewayModelRoutes[gatewayKey]; exists {
-					routeSet.Delete(namespacedName)
-					if routeSet.IsEmpty() {
-						delete(s.gatewayModelRoutes, gatewayKey)
-					}
-				}
-			}
-		}
-	}
+	removeGatewayRoute(s.gatewayModelRoutes, namespacedName)
 
 	delete(s.routeInfo, namespacedName)
 	s.routeMutex.Unlock()
@@ -767,16 +833,63 @@ func (s *store) DeleteModelRoute(namespacedName string) error {
 		}
 	}
 
+	s.routingChanges.record("ModelRoute", nullable(deletedRoute), nil)
+
 	// Trigger callbacks outside the lock to avoid potential deadlocks
 	s.triggerCallbacks("ModelRoute", EventData{
 		EventType:  EventDelete,
 		ModelName:  modelName,
-		ModelRoute: nil,
+		ModelRoute: deletedRoute,
 	})
 	return nil
 }
 
-func (s *store) MatchModelServer(model string, req *http.Request, gatewayKey string) (types.NamespacedName, bool, *aiv1alpha1.ModelRoute, error) {
+// removeRoute removes the ModelRoute of the key from the routes of the names, it returns the route removed.
+func removeRoute(routes map[string][]*aiv1alpha1.ModelRoute, names []string, key string) *aiv1alpha1.ModelRoute {
+	var removed *aiv1alpha1.ModelRoute
+	for _, name := range names {
+		remaining := make([]*aiv1alpha1.ModelRoute, 0, len(routes[name]))
+		for _, route := range routes[name] {
+			if route.Namespace+"/"+route.Name != key {
+				remaining = append(remaining, route)
+			} else {
+				removed = route
+			}
+		}
+		if len(remaining) == 0 {
+			delete(routes, name)
+		} else {
+			routes[name] = remaining
+		}
+	}
+	return removed
+}
+
+// routedModels returns the names a ModelRoute serving model under aliases is matched by.
+func routedModels(model string, aliases []string) []string {
+	if model == "" {
+		return nil
+	}
+	return append([]string{model}, aliases...)
+}
+
+// RouteMatch is the ModelServer selected for a request, and the ModelRoute and its rule which selected it.
+type RouteMatch struct {
+	ModelServer types.NamespacedName
+	IsLora      bool
+	ModelRoute  *aiv1alpha1.ModelRoute
+	Rule        *aiv1alpha1.Rule
+}
+
+func (s *store) MatchModelServer(model string, req *http.Request, gatewayKeys []string) (types.NamespacedName, bool, *aiv1alpha1.ModelRoute, error) {
+	match, err := s.MatchModelRoute(model, req, gatewayKeys)
+	if err != nil {
+		return types.NamespacedName{}, false, nil, err
+	}
+	return match.ModelServer, match.IsLora, match.ModelRoute, nil
+}
+
+func (s *store) MatchModelRoute(model string, req *http.Request, gatewayKeys []string) (*RouteMatch, error) {
 	s.routeMutex.RLock()
 	defer s.routeMutex.RUnlock()
 
@@ -788,36 +901,35 @@ func (s *store) MatchModelServer(model string, req *http.Request, gatewayKey str
 	if ok {
 		candidateRoutes = routes
 		isLora = false
-	} else {
+	} else if loraRoutes, ok := s.loraRoutes[model]; ok {
 		// Try to find routes by lora name
-		loraRoutes, ok := s.loraRoutes[model]
-		if !ok {
-			return types.NamespacedName{}, false, nil, fmt.Errorf("not found route rules for model %s", model)
-		}
 		candidateRoutes = loraRoutes
 		isLora = true
 	}
+	// The routes matching the model by pattern are tried after the ones naming it exactly
+	exactRoutes := len(candidateRoutes)
+	candidateRoutes = append(slices.Clip(candidateRoutes), s.matchPatternRoutes(model)...)
+	if len(candidateRoutes) == 0 {
+		return nil, fmt.Errorf("not found route rules for model %s", model)
+	}
 
+	capabilities := requiredCapabilities(req)
+	missingCapabilities := false
 	// Try each ModelRoute until we find one that matches
-	for _, mr := range candidateRoutes {
+	for i, mr := range candidateRoutes {
 		// Check parentRefs if specified
 		if len(mr.Spec.ParentRefs) > 0 {
-			// If gatewayKey is provided (not empty), check if ModelRoute matches the specific gateway
-			if gatewayKey != "" {
-				if !s.matchesSpecificGateway(mr, gatewayKey) {
-					continue // Try next ModelRoute
-				}
-			} else {
-				// If ModelRoute has parentRefs but gatewayKey is empty, skip it
-				continue // Skip ModelRoute with parentRefs when gatewayKey is not specified
+			// A ModelRoute with parentRefs only matches the requests received by one of its Gateways
+			if !s.matchesGateways(mr, gatewayKeys) {
+				continue // Try next ModelRoute
 			}
 		} else {
-			// If gatewayKey is specified, we only match ModelRoute with parentRefs
-			// ModelRoute without parentRefs should not match when gatewayKey is provided
-			if gatewayKey != "" {
-				continue // Skip ModelRoute without parentRefs when gatewayKey is specified
+			// If the request came through a Gateway, we only match ModelRoute with parentRefs
+			// ModelRoute without parentRefs should not match when gatewayKeys are provided
+			if len(gatewayKeys) > 0 {
+				continue // Skip ModelRoute without parentRefs when gatewayKeys are specified
 			}
-			// If gatewayKey is empty, ModelRoute without parentRefs can match
+			// If gatewayKeys are empty, ModelRoute without parentRefs can match
 			// (ModelRoute without parentRefs attaches to all Gateways in the same namespace)
 		}
 
@@ -827,52 +939,46 @@ func (s *store) MatchModelServer(model string, req *http.Request, gatewayKey str
 			continue // Try next ModelRoute
 		}
 
-		dst, err := s.selectDestination(rule.TargetModels)
+		// The targets lacking the capabilities required by the request are not selected
+		targets := s.capableTargets(mr.Namespace, rule.TargetModels, capabilities)
+		if len(targets) == 0 {
+			missingCapabilities = true
+			continue // Try next ModelRoute
+		}
+		dst, err := s.selectDestination(targets)
 		if err != nil {
 			continue // Try next ModelRoute
 		}
 
 		// Found a matching ModelRoute
-		return types.NamespacedName{Namespace: mr.Namespace, Name: dst.ModelServerName}, isLora, mr, nil
+		return &RouteMatch{
+			ModelServer: types.NamespacedName{Namespace: mr.Namespace, Name: rolloutDestination(mr, dst.ModelServerName)},
+			IsLora:      isLora && i < exactRoutes,
+			ModelRoute:  mr,
+			Rule:        rule,
+		}, nil
 	}
 
 	// No matching ModelRoute found
-	return types.NamespacedName{}, false, nil, fmt.Errorf("no matching ModelRoute found for model %s", model)
+	if missingCapabilities {
+		return nil, &MissingCapabilitiesError{Model: model, Capabilities: capabilities}
+	}
+	return nil, fmt.Errorf("no matching ModelRoute found for model %s", model)
 }
 
-// matchesSpecificGateway checks if the ModelRoute matches a specific gateway
-func (s *store) matchesSpecificGateway(mr *aiv1alpha1.ModelRoute, gatewayKey string) bool {
+// matchesGateways checks if the ModelRoute is attached to one of the gateways. The gateways sharing a listener
+// port and hostname all receive its requests, a ModelRoute may be attached to any of them.
+func (s *store) matchesGateways(mr *aiv1alpha1.ModelRoute, gatewayKeys []string) bool {
 	s.gatewayMutex.RLock()
 	defer s.gatewayMutex.RUnlock()
 
-	gatewayObj := s.gateways[gatewayKey]
-	if gatewayObj == nil {
-		return false
-	}
-
-	for _, parentRef := range mr.Spec.ParentRefs {
-		// Get namespace from parentRef, default to ModelRoute's namespace
-		namespace := mr.Namespace
-		if parentRef.Namespace != nil {
-			namespace = string(*parentRef.Namespace)
-		}
-
-		// Get name from parentRef
-		name := string(parentRef.Name)
-		key := fmt.Sprintf("%s/%s", namespace, name)
-
-		// Check if this parentRef matches the specified gateway
-		if key == gatewayKey {
-			// If sectionName is specified, check if the listener exists in the gateway
-			if parentRef.SectionName != nil {
-				sectionName := string(*parentRef.SectionName)
-				for _, listener := range gatewayObj.Spec.Listeners {
-					if string(listener.Name) == sectionName {
-						return true
-					}
-				}
-			} else {
-				// No sectionName specified, match any listener
+	for _, gatewayKey := range gatewayKeys {
+		gatewayObj := s.gateways[gatewayKey]
+		if gatewayObj == nil {
+			continue
+		}
+		for _, parentRef := range mr.Spec.ParentRefs {
+			if key, ok := GatewayParentKey(mr.Namespace, parentRef); ok && key == gatewayKey && ParentRefMatchesListener(parentRef, gatewayObj) {
 				return true
 			}
 		}
@@ -881,6 +987,45 @@ func (s *store) matchesSpecificGateway(mr *aiv1alpha1.ModelRoute, gatewayKey str
 	return false
 }
 
+// GatewayParentKey returns the key (namespace/name) of the Gateway referenced by a parentRef of a route in the
+// namespace, false if the parent is not a Gateway.
+func GatewayParentKey(namespace string, parentRef gatewayv1.ParentReference) (string, bool) {
+	if (parentRef.Kind != nil && *parentRef.Kind != "Gateway") ||
+		(parentRef.Group != nil && *parentRef.Group != gatewayv1.GroupName) {
+		return "", false
+	}
+	// Get namespace from parentRef, default to the route's namespace
+	if parentRef.Namespace != nil {
+		namespace = string(*parentRef.Namespace)
+	}
+	return fmt.Sprintf("%s/%s", namespace, string(parentRef.Name)), true
+}
+
+// ParentRefMatchesListener reports whether the gateway has a listener matching the sectionName and the port of a
+// parentRef, any listener matches if they are unset.
+func ParentRefMatchesListener(parentRef gatewayv1.ParentReference, gateway *gatewayv1.Gateway) bool {
+	for _, listener := range gateway.Spec.Listeners {
+		if parentRef.SectionName != nil && listener.Name != *parentRef.SectionName {
+			continue
+		}
+		if parentRef.Port != nil && listener.Port != *parentRef.Port {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// removeGatewayRoute removes a route from the routes of all the gateways.
+func removeGatewayRoute(gatewayRoutes map[string]sets.Set[string], routeKey string) {
+	for gatewayKey, routeSet := range gatewayRoutes {
+		routeSet.Delete(routeKey)
+		if routeSet.IsEmpty() {
+			delete(gatewayRoutes, gatewayKey)
+		}
+	}
+}
+
 func (s *store) selectRule(modelName string, req *http.Request, rules []*aiv1alpha1.Rule) (*aiv1alpha1.Rule, error) {
 	for _, rule := range rules {
 		if rule.ModelMatch == nil {
@@ -894,6 +1039,9 @@ func (s *store) selectRule(modelName string, req *http.Request, rules []*aiv1alp
 				continue // Skip this rule if model name doesn't match
 			}
 		}
+		if rule.ModelMatch.Body != nil && !matchBodyFields(req, rule.ModelMatch.Body.Fields) {
+			continue
+		}
 
 		headersMatched := true
 		for key, sm := range rule.ModelMatch.Headers {
@@ -918,6 +1066,11 @@ func (s *store) selectRule(modelName string, req *http.Request, rules []*aiv1alp
 			continue
 		}
 
+		// The expression is evaluated last, the static matches being cheaper
+		if expression := rule.ModelMatch.Expression; expression != nil && !matchExpression(modelName, req, *expression) {
+			continue
+		}
+
 		return rule, nil
 	}
 
@@ -949,6 +1102,20 @@ func (s *store) selectDestination(targets []*aiv1alpha1.TargetModel) (*aiv1alpha
 	return targets[index], nil
 }
 
+// rolloutDestination sends the canary weight of the requests the rules send to the stable ModelServer of the rollout of
+// the ModelRoute to its canary, as recorded in the status by the rollout controller.
+func rolloutDestination(mr *aiv1alpha1.ModelRoute, modelServerName string) string {
+	rollout, status := mr.Spec.Rollout, mr.Status.Rollout
+	if rollout == nil || status == nil || modelServerName != rollout.StableModelServerName ||
+		status.CanaryModelServerName != rollout.CanaryModelServerName || status.CanaryWeight <= 0 {
+		return modelServerName
+	}
+	if rand.Int31n(100) < status.CanaryWeight {
+		return rollout.CanaryModelServerName
+	}
+	return modelServerName
+}
+
 func toWeightedSlice(targets []*aiv1alpha1.TargetModel) ([]uint32, error) {
 	var isWeighted bool
 	if targets[0].Weight != nil {
@@ -956,6 +1123,7 @@ func toWeightedSlice(targets []*aiv1alpha1.TargetModel) ([]uint32, error) {
 	}
 
 	res := make([]uint32, len(targets))
+	var totalWeight uint32
 
 	for i, target := range targets {
 		if (isWeighted && target.Weight == nil) || (!isWeighted && target.Weight != nil) {
@@ -964,11 +1132,15 @@ func toWeightedSlice(targets []*aiv1alpha1.TargetModel) ([]uint32, error) {
 
 		if isWeighted {
 			res[i] = *target.Weight
+			totalWeight += *target.Weight
 		} else {
 			// If weight is not specified, set to 1.
 			res[i] = 1
 		}
 	}
+	if isWeighted && totalWeight == 0 {
+		return nil, fmt.Errorf("the weights of the targetModels must not all be zero")
+	}
 
 	return res, nil
 }
@@ -1001,8 +1173,13 @@ func (s *store) updatePodMetrics(pod *PodInfo) {
 
 	previousHistogram := getPreviousHistogram(pod)
 	gaugeMetrics, histogramMetrics := backend.GetPodMetrics(pod.engine, pod.Pod, previousHistogram)
+	if gaugeMetrics == nil {
+		// The metrics are unavailable, the last ones are kept until they are stale.
+		return
+	}
 	updateGaugeMetricsInfo(pod, gaugeMetrics)
 	updateHistogramMetrics(pod, histogramMetrics)
+	pod.RecordMetricsScrape(time.Now())
 }
 
 func (s *store) updatePodModels(podInfo *PodInfo) {
@@ -1299,6 +1476,9 @@ func (s *store) GetAllModelRoutes() map[string]*aiv1alpha1.ModelRoute {
 				}
 			}
 		}
+		if foundRoute == nil {
+			foundRoute = s.findPatternRoute(key)
+		}
 		if foundRoute != nil {
 			result[key] = foundRoute
 		}
@@ -1370,7 +1550,7 @@ func (s *store) GetModelRoute(namespacedName string) *aiv1alpha1.ModelRoute {
 		}
 	}
 
-	return nil
+	return s.findPatternRoute(namespacedName)
 }
 
 // Gateway methods (using standard Gateway API)
@@ -1379,8 +1559,10 @@ func (s *store) AddOrUpdateGateway(gateway *gatewayv1.Gateway) error {
 	key := fmt.Sprintf("%s/%s", gateway.Namespace, gateway.Name)
 
 	s.gatewayMutex.Lock()
+	old := s.gateways[key]
 	s.gateways[key] = gateway
 	s.gatewayMutex.Unlock()
+	s.routingChanges.record("Gateway", nullable(old), gateway)
 
 	klog.V(4).Infof("Added or updated Gateway: %s", key)
 
@@ -1402,8 +1584,10 @@ func (s *store) DeleteGateway(key string) error {
 	}
 
 	s.gatewayMutex.Lock()
+	old := s.gateways[key]
 	delete(s.gateways, key)
 	s.gatewayMutex.Unlock()
+	s.routingChanges.record("Gateway", nullable(old), nil)
 
 	klog.V(4).Infof("Deleted Gateway: %s", key)
 
@@ -1455,8 +1639,10 @@ func (s *store) AddOrUpdateInferencePool(inferencePool *inferencev1.InferencePoo
 	key := fmt.Sprintf("%s/%s", inferencePool.ObjectMeta.Namespace, inferencePool.ObjectMeta.Name)
 
 	s.inferencePoolMutex.Lock()
+	old := s.inferencePools[key]
 	s.inferencePools[key] = inferencePool
 	s.inferencePoolMutex.Unlock()
+	s.routingChanges.record("InferencePool", nullable(old), inferencePool)
 
 	klog.V(4).Infof("Added or updated InferencePool: %s", key)
 	return nil
@@ -1464,8 +1650,10 @@ func (s *store) AddOrUpdateInferencePool(inferencePool *inferencev1.InferencePoo
 
 func (s *store) DeleteInferencePool(key string) error {
 	s.inferencePoolMutex.Lock()
+	old := s.inferencePools[key]
 	delete(s.inferencePools, key)
 	s.inferencePoolMutex.Unlock()
+	s.routingChanges.record("InferencePool", nullable(old), nil)
 
 	klog.V(4).Infof("Deleted InferencePool: %s", key)
 	return nil
@@ -1531,18 +1719,13 @@ func (s *store) AddOrUpdateHTTPRoute(httpRoute *gatewayv1.HTTPRoute) error {
 	key := fmt.Sprintf("%s/%s", httpRoute.Namespace, httpRoute.Name)
 
 	s.httpRouteMutex.Lock()
+	old := s.httpRoutes[key]
 	s.httpRoutes[key] = httpRoute
 
-	// Update gateway routes mapping
+	// Update gateway routes mapping, the previous parents may have been removed from the route
+	removeGatewayRoute(s.gatewayRoutes, key)
 	for _, parentRef := range httpRoute.Spec.ParentRefs {
-		if parentRef.Kind != nil && *parentRef.Kind == "Gateway" {
-			gatewayName := string(parentRef.Name)
-			gatewayNamespace := httpRoute.Namespace
-			if parentRef.Namespace != nil {
-				gatewayNamespace = string(*parentRef.Namespace)
-			}
-			gatewayKey := fmt.Sprintf("%s/%s", gatewayNamespace, gatewayName)
-
+		if gatewayKey, ok := GatewayParentKey(httpRoute.Namespace, parentRef); ok {
 			if s.gatewayRoutes[gatewayKey] == nil {
 				s.gatewayRoutes[gatewayKey] = sets.New[string]()
 			}
@@ -1551,6 +1734,7 @@ func (s *store) AddOrUpdateHTTPRoute(httpRoute *gatewayv1.HTTPRoute) error {
 	}
 
 	s.httpRouteMutex.Unlock()
+	s.routingChanges.record("HTTPRoute", nullable(old), httpRoute)
 
 	klog.V(4).Infof("Added or updated HTTPRoute: %s", key)
 	return nil
@@ -1558,20 +1742,16 @@ func (s *store) AddOrUpdateHTTPRoute(httpRoute *gatewayv1.HTTPRoute) error {
 
 func (s *store) DeleteHTTPRoute(key string) error {
 	s.httpRouteMutex.Lock()
-	_, exists := s.httpRoutes[key]
+	old, exists := s.httpRoutes[key]
 	if exists {
 		// Remove from gateway routes mapping
-		for gatewayKey, routeSet := range s.gatewayRoutes {
-			routeSet.Delete(key)
-			if routeSet.IsEmpty() {
-				delete(s.gatewayRoutes, gatewayKey)
-			}
-		}
+		removeGatewayRoute(s.gatewayRoutes, key)
 		delete(s.httpRoutes, key)
 	}
 	s.httpRouteMutex.Unlock()
 
 	if exists {
+		s.routingChanges.record("HTTPRoute", old, nil)
 		klog.V(4).Infof("Deleted HTTPRoute: %s", key)
 	}
 	return nil
@@ -1610,6 +1790,177 @@ func (s *store) GetHTTPRoutesByGateway(gatewayKey string) []*gatewayv1.HTTPRoute
 	return result
 }
 
+// GRPCRoute methods (using standard Gateway API)
+
+func (s *store) AddOrUpdateGRPCRoute(grpcRoute *gatewayv1.GRPCRoute) error {
+	key := fmt.Sprintf("%s/%s", grpcRoute.Namespace, grpcRoute.Name)
+
+	s.grpcRouteMutex.Lock()
+	old := s.grpcRoutes[key]
+	// Drop the previous parents, they may have been removed from the route
+	removeGatewayRoute(s.gatewayGRPCRoutes, key)
+	s.grpcRoutes[key] = grpcRoute
+
+	// Update gateway routes mapping
+	for _, parentRef := range grpcRoute.Spec.ParentRefs {
+		if gatewayKey, ok := GatewayParentKey(grpcRoute.Namespace, parentRef); ok {
+			if s.gatewayGRPCRoutes[gatewayKey] == nil {
+				s.gatewayGRPCRoutes[gatewayKey] = sets.New[string]()
+			}
+			s.gatewayGRPCRoutes[gatewayKey].Insert(key)
+		}
+	}
+	s.grpcRouteMutex.Unlock()
+	s.routingChanges.record("GRPCRoute", nullable(old), grpcRoute)
+
+	klog.V(4).Infof("Added or updated GRPCRoute: %s", key)
+	return nil
+}
+
+func (s *store) DeleteGRPCRoute(key string) error {
+	s.grpcRouteMutex.Lock()
+	old, exists := s.grpcRoutes[key]
+	if exists {
+		// Remove from gateway routes mapping
+		removeGatewayRoute(s.gatewayGRPCRoutes, key)
+		delete(s.grpcRoutes, key)
+	}
+	s.grpcRouteMutex.Unlock()
+
+	if exists {
+		s.routingChanges.record("GRPCRoute", old, nil)
+		klog.V(4).Infof("Deleted GRPCRoute: %s", key)
+	}
+	return nil
+}
+
+func (s *store) GetGRPCRoute(key string) *gatewayv1.GRPCRoute {
+	s.grpcRouteMutex.RLock()
+	defer s.grpcRouteMutex.RUnlock()
+
+	return s.grpcRoutes[key]
+}
+
+func (s *store) GetAllGRPCRoutes() []*gatewayv1.GRPCRoute {
+	s.grpcRouteMutex.RLock()
+	defer s.grpcRouteMutex.RUnlock()
+
+	var result []*gatewayv1.GRPCRoute
+	for _, grpcRoute := range s.grpcRoutes {
+		result = append(result, grpcRoute)
+	}
+	return result
+}
+
+func (s *store) GetGRPCRoutesByGateway(gatewayKey string) []*gatewayv1.GRPCRoute {
+	s.grpcRouteMutex.RLock()
+	defer s.grpcRouteMutex.RUnlock()
+
+	var result []*gatewayv1.GRPCRoute
+	for routeKey := range s.gatewayGRPCRoutes[gatewayKey] {
+		if gr, ok := s.grpcRoutes[routeKey]; ok {
+			result = append(result, gr)
+		}
+	}
+	return result
+}
+
+func (s *store) AddOrUpdateModelServing(modelServing *workloadv1alpha1.ModelServing) error {
+	s.modelServings.Store(types.NamespacedName{Namespace: modelServing.Namespace, Name: modelServing.Name}, modelServing)
+	return nil
+}
+
+func (s *store) DeleteModelServing(name types.NamespacedName) error {
+	s.modelServings.Delete(name)
+	return nil
+}
+
+func (s *store) GetModelServing(name types.NamespacedName) *workloadv1alpha1.ModelServing {
+	if value, ok := s.modelServings.Load(name); ok {
+		return value.(*workloadv1alpha1.ModelServing)
+	}
+	return nil
+}
+
+func (s *store) AddOrUpdateSecret(secret *corev1.Secret) error {
+	s.secrets.Store(types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, secret)
+	return nil
+}
+
+func (s *store) DeleteSecret(name types.NamespacedName) error {
+	s.secrets.Delete(name)
+	return nil
+}
+
+func (s *store) GetSecret(name types.NamespacedName) *corev1.Secret {
+	if value, ok := s.secrets.Load(name); ok {
+		return value.(*corev1.Secret)
+	}
+	return nil
+}
+
+func (s *store) AddOrUpdateRateLimitPolicy(policy *aiv1alpha1.RateLimitPolicy) error {
+	name := types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name}
+	_, exists := s.rateLimitPolicies.Swap(name, policy)
+	eventType := EventAdd
+	if exists {
+		eventType = EventUpdate
+	}
+	s.triggerCallbacks("RateLimitPolicy", EventData{EventType: eventType, RateLimitPolicy: name})
+	return nil
+}
+
+func (s *store) DeleteRateLimitPolicy(name types.NamespacedName) error {
+	if _, exists := s.rateLimitPolicies.LoadAndDelete(name); exists {
+		s.triggerCallbacks("RateLimitPolicy", EventData{EventType: EventDelete, RateLimitPolicy: name})
+	}
+	return nil
+}
+
+func (s *store) GetRateLimitPolicies(namespace string) []*aiv1alpha1.RateLimitPolicy {
+	var policies []*aiv1alpha1.RateLimitPolicy
+	s.rateLimitPolicies.Range(func(key, value any) bool {
+		if key.(types.NamespacedName).Namespace == namespace {
+			policies = append(policies, value.(*aiv1alpha1.RateLimitPolicy))
+		}
+		return true
+	})
+	slices.SortFunc(policies, func(a, b *aiv1alpha1.RateLimitPolicy) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return policies
+}
+
+func (s *store) AddOrUpdateTokenQuota(quota *aiv1alpha1.TokenQuota) error {
+	s.tokenQuotas.Store(types.NamespacedName{Namespace: quota.Namespace, Name: quota.Name}, quota)
+	return nil
+}
+
+func (s *store) DeleteTokenQuota(name types.NamespacedName) error {
+	if _, exists := s.tokenQuotas.LoadAndDelete(name); exists {
+		s.triggerCallbacks("TokenQuota", EventData{EventType: EventDelete, TokenQuota: name})
+	}
+	return nil
+}
+
+func (s *store) GetTokenQuotas(namespace string) []*aiv1alpha1.TokenQuota {
+	var quotas []*aiv1alpha1.TokenQuota
+	s.tokenQuotas.Range(func(key, value any) bool {
+		if namespace == "" || key.(types.NamespacedName).Namespace == namespace {
+			quotas = append(quotas, value.(*aiv1alpha1.TokenQuota))
+		}
+		return true
+	})
+	slices.SortFunc(quotas, func(a, b *aiv1alpha1.TokenQuota) int {
+		return cmp.Or(strings.Compare(a.Namespace, b.Namespace), strings.Compare(a.Name, b.Name))
+	})
+	return quotas
+}
+
+func (s *store) GetRoutingChanges() []RoutingChange {
+	return s.routingChanges.list()
+}
+
 func (s *store) GetModelRoutesByGateway(gatewayKey string) []*aiv1alpha1.ModelRoute {
 	s.routeMutex.RLock()
 	defer s.routeMutex.RUnlock()
@@ -1629,6 +1980,8 @@ func (s *store) GetModelRoutesByGateway(gatewayKey string) []*aiv1alpha1.ModelRo
 							}
 						}
 					}
+				} else if route := s.findPatternRoute(routeKey); route != nil {
+					result = append(result, route)
 				}
 			}
 		}
@@ -35,6 +35,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
 // ptr is a helper function to get pointer to a value
@@ -817,6 +818,54 @@ func createComplexModelRoute() *aiv1alpha1.ModelRoute {
 	}
 }
 
+func TestStoreAddOrUpdateModelRoute_DropsAliases(t *testing.T) {
+	s := &store{
+		routeInfo:  make(map[string]*modelRouteInfo),
+		routes:     make(map[string][]*aiv1alpha1.ModelRoute),
+		loraRoutes: make(map[string][]*aiv1alpha1.ModelRoute),
+		callbacks:  make(map[string][]CallbackFunc),
+	}
+	other := &aiv1alpha1.ModelRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other"},
+		Spec:       aiv1alpha1.ModelRouteSpec{ModelName: "gpt-4o"},
+	}
+	mr := &aiv1alpha1.ModelRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "qwen"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName:    "qwen2.5-72b",
+			Aliases:      []string{"gpt-4o", "gpt-4"},
+			LoraAdapters: []string{"sql", "chat"},
+		},
+	}
+	assert.NoError(t, s.AddOrUpdateModelRoute(other))
+	assert.NoError(t, s.AddOrUpdateModelRoute(mr))
+	assert.Len(t, s.routes["gpt-4o"], 2)
+
+	// The alias gpt-4 and the LoRA adapter chat are dropped
+	updated := mr.DeepCopy()
+	updated.Spec.Aliases = []string{"gpt-4o"}
+	updated.Spec.LoraAdapters = []string{"sql"}
+	assert.NoError(t, s.AddOrUpdateModelRoute(updated))
+
+	assert.NotContains(t, s.routes, "gpt-4")
+	assert.NotContains(t, s.loraRoutes, "chat")
+	assert.Equal(t, []*aiv1alpha1.ModelRoute{other, updated}, s.routes["gpt-4o"], "the other routes of an alias keep their order")
+	assert.Equal(t, []*aiv1alpha1.ModelRoute{updated}, s.routes["qwen2.5-72b"])
+	assert.Equal(t, []*aiv1alpha1.ModelRoute{updated}, s.loraRoutes["sql"])
+
+	// The model of the route is renamed
+	renamed := updated.DeepCopy()
+	renamed.Spec.ModelName = "qwen3-72b"
+	assert.NoError(t, s.AddOrUpdateModelRoute(renamed))
+	assert.NotContains(t, s.routes, "qwen2.5-72b")
+	assert.Equal(t, []*aiv1alpha1.ModelRoute{renamed}, s.routes["qwen3-72b"])
+
+	assert.NoError(t, s.DeleteModelRoute("default/qwen"))
+	assert.Equal(t, []*aiv1alpha1.ModelRoute{other}, s.routes["gpt-4o"])
+	assert.NotContains(t, s.routes, "qwen3-72b")
+	assert.Empty(t, s.loraRoutes)
+}
+
 func TestStoreMatchModelServer(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -1204,7 +1253,7 @@ func TestStoreMatchModelServer(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := tt.setupStore()
-			server, isLora, _, err := s.MatchModelServer(tt.modelName, tt.request, "")
+			server, isLora, _, err := s.MatchModelServer(tt.modelName, tt.request, nil)
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -1217,3 +1266,178 @@ func TestStoreMatchModelServer(t *testing.T) {
 		})
 	}
 }
+
+func TestStoreSelectDestination_Weights(t *testing.T) {
+	s := &store{}
+	targets := []*aiv1alpha1.TargetModel{
+		{ModelServerName: "stable", Weight: ptr(uint32(90))},
+		{ModelServerName: "canary", Weight: ptr(uint32(10))},
+		{ModelServerName: "drained", Weight: ptr(uint32(0))},
+	}
+	selected := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		target, err := s.selectDestination(targets)
+		assert.NoError(t, err)
+		selected[target.ModelServerName]++
+	}
+	assert.InDelta(t, 900, selected["stable"], 100)
+	assert.InDelta(t, 100, selected["canary"], 100)
+	assert.Zero(t, selected["drained"])
+
+	_, err := s.selectDestination([]*aiv1alpha1.TargetModel{
+		{ModelServerName: "stable", Weight: ptr(uint32(90))},
+		{ModelServerName: "canary"},
+	})
+	assert.Error(t, err)
+
+	_, err = s.selectDestination([]*aiv1alpha1.TargetModel{
+		{ModelServerName: "stable", Weight: ptr(uint32(0))},
+		{ModelServerName: "canary", Weight: ptr(uint32(0))},
+	})
+	assert.EqualError(t, err, "the weights of the targetModels must not all be zero")
+}
+
+func TestRolloutDestination(t *testing.T) {
+	mr := &aiv1alpha1.ModelRoute{
+		Spec: aiv1alpha1.ModelRouteSpec{
+			Rollout: &aiv1alpha1.Rollout{StableModelServerName: "stable", CanaryModelServerName: "canary"},
+		},
+	}
+	count := func(weight int32, canary, modelServerName string) int {
+		mr.Status.Rollout = &aiv1alpha1.RolloutStatus{CanaryModelServerName: canary, CanaryWeight: weight}
+		n := 0
+		for i := 0; i < 1000; i++ {
+			if rolloutDestination(mr, modelServerName) == "canary" {
+				n++
+			}
+		}
+		return n
+	}
+
+	assert.InDelta(t, 250, count(25, "canary", "stable"), 100)
+	assert.Equal(t, 1000, count(100, "canary", "stable"))
+	assert.Zero(t, count(0, "canary", "stable"))
+	// Only the traffic of the stable ModelServer is shifted.
+	assert.Zero(t, count(100, "canary", "other"))
+	// The status of a previous canary is ignored until the controller restarts the rollout.
+	assert.Zero(t, count(100, "previous", "stable"))
+
+	mr.Status.Rollout = nil
+	assert.Equal(t, "stable", rolloutDestination(mr, "stable"))
+}
+
+func TestStoreMatchModelServer_MultipleGateways(t *testing.T) {
+	s := New()
+	for _, gateway := range []*gatewayv1.Gateway{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "gw-a"},
+			Spec:       gatewayv1.GatewaySpec{Listeners: []gatewayv1.Listener{{Name: "http", Port: 80}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "gw-b"},
+			Spec:       gatewayv1.GatewaySpec{Listeners: []gatewayv1.Listener{{Name: "http", Port: 8081}}},
+		},
+	} {
+		assert.NoError(t, s.AddOrUpdateGateway(gateway))
+	}
+	modelRoute := func(name, model string, parentRefs ...gatewayv1.ParentReference) *aiv1alpha1.ModelRoute {
+		return &aiv1alpha1.ModelRoute{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: aiv1alpha1.ModelRouteSpec{
+				ModelName:  model,
+				ParentRefs: parentRefs,
+				Rules: []*aiv1alpha1.Rule{
+					{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: name + "-server"}}},
+				},
+			},
+		}
+	}
+	gwA := gatewayv1.ParentReference{Name: "gw-a"}
+	gwB := gatewayv1.ParentReference{Name: "gw-b"}
+	assert.NoError(t, s.AddOrUpdateModelRoute(modelRoute("both", "both-model", gwA, gwB)))
+	assert.NoError(t, s.AddOrUpdateModelRoute(modelRoute("only-a", "a-model", gwA)))
+	assert.NoError(t, s.AddOrUpdateModelRoute(modelRoute("wrong-port", "port-model",
+		gatewayv1.ParentReference{Name: "gw-b", Port: ptr(gatewayv1.PortNumber(80))})))
+
+	request := &http.Request{URL: &url.URL{Path: "/v1/chat/completions"}}
+	match := func(model string, gatewayKeys ...string) error {
+		_, _, _, err := s.MatchModelServer(model, request, gatewayKeys)
+		return err
+	}
+	assert.NoError(t, match("both-model", "default/gw-a"))
+	assert.NoError(t, match("both-model", "default/gw-b"))
+	assert.Error(t, match("a-model", "default/gw-b"))
+	assert.NoError(t, match("a-model", "default/gw-b", "default/gw-a"), "gateways sharing a listener all match")
+	assert.Error(t, match("port-model", "default/gw-b"), "no listener of the gateway matches the port")
+
+	// Detaching the route from a gateway removes it from the routes of the gateway.
+	assert.NoError(t, s.AddOrUpdateModelRoute(modelRoute("both", "both-model", gwB)))
+	assert.Error(t, match("both-model", "default/gw-a"))
+	assert.NoError(t, match("both-model", "default/gw-b"))
+	assert.Equal(t, []*aiv1alpha1.ModelRoute{modelRoute("only-a", "a-model", gwA)}, s.GetModelRoutesByGateway("default/gw-a"))
+}
+
+func TestStoreMatchModelServer_RequiredCapabilities(t *testing.T) {
+	s := New()
+	for name, capabilities := range map[string][]aiv1alpha1.Capability{
+		"text":   nil,
+		"vision": {aiv1alpha1.CapabilityVision},
+	} {
+		assert.NoError(t, s.AddOrUpdateModelServer(&aiv1alpha1.ModelServer{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       aiv1alpha1.ModelServerSpec{Capabilities: capabilities},
+		}, nil))
+	}
+	modelRoute := func(name, model string, modelServers ...string) *aiv1alpha1.ModelRoute {
+		rule := &aiv1alpha1.Rule{}
+		for _, modelServer := range modelServers {
+			rule.TargetModels = append(rule.TargetModels, &aiv1alpha1.TargetModel{ModelServerName: modelServer})
+		}
+		return &aiv1alpha1.ModelRoute{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       aiv1alpha1.ModelRouteSpec{ModelName: model, Rules: []*aiv1alpha1.Rule{rule}},
+		}
+	}
+	assert.NoError(t, s.AddOrUpdateModelRoute(modelRoute("mixed", "llava", "text", "vision")))
+	assert.NoError(t, s.AddOrUpdateModelRoute(modelRoute("text-only", "llama", "text")))
+
+	request := &http.Request{URL: &url.URL{Path: "/v1/chat/completions"}}
+	visionRequest := request.WithContext(WithRequiredCapabilities(request.Context(), []aiv1alpha1.Capability{aiv1alpha1.CapabilityVision}))
+	for i := 0; i < 10; i++ {
+		modelServer, _, _, err := s.MatchModelServer("llava", visionRequest, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "vision", modelServer.Name, "the targets without the vision capability are not selected")
+	}
+
+	_, _, _, err := s.MatchModelServer("llama", request, nil)
+	assert.NoError(t, err)
+	_, _, _, err = s.MatchModelServer("llama", visionRequest, nil)
+	var missing *MissingCapabilitiesError
+	if assert.ErrorAs(t, err, &missing) {
+		assert.Equal(t, "llama", missing.Model)
+		assert.Equal(t, []aiv1alpha1.Capability{aiv1alpha1.CapabilityVision}, missing.Capabilities)
+	}
+}
+
+func TestHasCapabilities(t *testing.T) {
+	undeclared := &aiv1alpha1.ModelServer{}
+	declared := &aiv1alpha1.ModelServer{Spec: aiv1alpha1.ModelServerSpec{
+		Capabilities: []aiv1alpha1.Capability{aiv1alpha1.CapabilityVision, aiv1alpha1.CapabilityJSONMode},
+	}}
+	tests := []struct {
+		name         string
+		modelServer  *aiv1alpha1.ModelServer
+		capabilities []aiv1alpha1.Capability
+		want         bool
+	}{
+		{name: "undeclared are assumed", modelServer: undeclared, capabilities: []aiv1alpha1.Capability{aiv1alpha1.CapabilityTools, aiv1alpha1.CapabilityReasoning}, want: true},
+		{name: "undeclared vision is missing", modelServer: undeclared, capabilities: []aiv1alpha1.Capability{aiv1alpha1.CapabilityVision}},
+		{name: "declared", modelServer: declared, capabilities: []aiv1alpha1.Capability{aiv1alpha1.CapabilityJSONMode, aiv1alpha1.CapabilityVision}, want: true},
+		{name: "not declared", modelServer: declared, capabilities: []aiv1alpha1.Capability{aiv1alpha1.CapabilityVision, aiv1alpha1.CapabilityTools}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, hasCapabilities(tt.modelServer, tt.capabilities))
+		})
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 )
 
 // Sliding window configuration
@@ -55,6 +56,7 @@ type userBucketData struct {
 type InMemorySlidingWindowTokenTracker struct {
 	mu                sync.RWMutex
 	windowSize        time.Duration
+	clock             clock.PassiveClock
 	inputTokenWeight  float64
 	outputTokenWeight float64
 	userBucketStore   map[string]map[string]*userBucketData // [user][model] -> buckets
@@ -78,6 +80,13 @@ func WithWindowSize(size time.Duration) TokenTrackerOption {
 	}
 }
 
+// WithClock overrides the clock the sliding window moves with, e.g. with a fake clock in simulations
+func WithClock(c clock.PassiveClock) TokenTrackerOption {
+	return func(t *InMemorySlidingWindowTokenTracker) {
+		t.clock = c
+	}
+}
+
 // WithTokenWeights overrides the default token weights
 func WithTokenWeights(inputWeight, outputWeight float64) TokenTrackerOption {
 	return func(t *InMemorySlidingWindowTokenTracker) {
@@ -98,6 +107,7 @@ func NewInMemorySlidingWindowTokenTracker(opts ...TokenTrackerOption) TokenTrack
 		inputTokenWeight:  defaultInputTokenWeight,
 		outputTokenWeight: defaultOutputTokenWeight,
 		userBucketStore:   make(map[string]map[string]*userBucketData),
+		clock:             clock.RealClock{},
 	}
 
 	for _, opt := range opts {
@@ -107,7 +117,7 @@ func NewInMemorySlidingWindowTokenTracker(opts ...TokenTrackerOption) TokenTrack
 }
 
 func (t *InMemorySlidingWindowTokenTracker) getCutoffTimestamp() int64 {
-	cutoffTime := time.Now().Add(-t.windowSize)
+	cutoffTime := t.clock.Now().Add(-t.windowSize)
 	return cutoffTime.Unix()
 }
 
@@ -219,7 +229,7 @@ func (t *InMemorySlidingWindowTokenTracker) UpdateTokenCount(user, model string,
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	now := time.Now()
+	now := t.clock.Now()
 	currentTimestamp := now.Unix()
 	cutoff := t.getCutoffTimestamp()
 
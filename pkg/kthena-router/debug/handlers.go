@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"k8s.io/apimachinery/pkg/types"
@@ -63,6 +64,9 @@ type ModelServerResponse struct {
 	AssociatedPods []string                   `json:"associatedPods,omitempty"`
 	DecodePods     []string                   `json:"decodePods,omitempty"`
 	PrefillPods    []string                   `json:"prefillPods,omitempty"`
+	// ReplacedBy lists the models served by the pods instead of the model of the ModelServer,
+	// if their engine swapped it in place.
+	ReplacedBy []string `json:"replacedBy,omitempty"`
 }
 
 type PodResponse struct {
@@ -105,6 +109,13 @@ type HTTPRouteResponse struct {
 	Status    gatewayv1.HTTPRouteStatus `json:"status,omitempty"`
 }
 
+type GRPCRouteResponse struct {
+	Name      string                    `json:"name"`
+	Namespace string                    `json:"namespace"`
+	Spec      gatewayv1.GRPCRouteSpec   `json:"spec"`
+	Status    gatewayv1.GRPCRouteStatus `json:"status,omitempty"`
+}
+
 type InferencePoolResponse struct {
 	Name      string                          `json:"name"`
 	Namespace string                          `json:"namespace"`
@@ -158,6 +169,7 @@ func (h *DebugHandler) ListModelServers(c *gin.Context) {
 				}
 			}
 			response.AssociatedPods = podNames
+			response.ReplacedBy = replacedBy(ms, pods)
 		}
 
 		// Get decode pods
@@ -240,6 +252,27 @@ func (h *DebugHandler) ListHTTPRoutes(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"httproutes": responses})
 }
 
+// ListGRPCRoutes handles GET /debug/config_dump/grpcroutes
+func (h *DebugHandler) ListGRPCRoutes(c *gin.Context) {
+	grpcRoutes := h.store.GetAllGRPCRoutes()
+
+	var responses []GRPCRouteResponse
+	for _, gr := range grpcRoutes {
+		if gr == nil {
+			continue
+		}
+		response := GRPCRouteResponse{
+			Name:      gr.Name,
+			Namespace: gr.Namespace,
+			Spec:      gr.Spec,
+			Status:    gr.Status,
+		}
+		responses = append(responses, response)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"grpcroutes": responses})
+}
+
 // ListInferencePools handles GET /debug/config_dump/inferencepools
 func (h *DebugHandler) ListInferencePools(c *gin.Context) {
 	inferencePools := h.store.GetAllInferencePools()
@@ -261,6 +294,28 @@ func (h *DebugHandler) ListInferencePools(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"inferencepools": responses})
 }
 
+// ListRoutingChanges handles GET /debug/routing_changes, the optional `since` RFC 3339 timestamp
+// filters out the older changes.
+func (h *DebugHandler) ListRoutingChanges(c *gin.Context) {
+	var since time.Time
+	if value := c.Query("since"); value != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid since timestamp: %v", err)})
+			return
+		}
+	}
+
+	changes := []datastore.RoutingChange{}
+	for _, change := range h.store.GetRoutingChanges() {
+		if !change.Timestamp.Before(since) {
+			changes = append(changes, change)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"changes": changes})
+}
+
 // Get specific resource endpoints
 
 // GetModelRoute handles GET /debug/config_dump/namespaces/{namespace}/modelroutes/{name}
@@ -326,6 +381,7 @@ func (h *DebugHandler) GetModelServer(c *gin.Context) {
 			}
 		}
 		response.AssociatedPods = podNames
+		response.ReplacedBy = replacedBy(ms, pods)
 	}
 
 	// Get decode pods
@@ -353,6 +409,15 @@ func (h *DebugHandler) GetModelServer(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// replacedBy returns the models the pods of the ModelServer serve instead of its model.
+func replacedBy(ms *aiv1alpha1.ModelServer, pods []*datastore.PodInfo) []string {
+	if ms.Spec.Model == nil || len(pods) == 0 {
+		return nil
+	}
+	_, models := datastore.PodsServingModel(pods, *ms.Spec.Model)
+	return models
+}
+
 // GetPod handles GET /debug/config_dump/namespaces/{namespace}/pods/{name}
 func (h *DebugHandler) GetPod(c *gin.Context) {
 	namespace := c.Param("namespace")
@@ -434,6 +499,34 @@ func (h *DebugHandler) GetHTTPRoute(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// GetGRPCRoute handles GET /debug/config_dump/namespaces/{namespace}/grpcroutes/{name}
+func (h *DebugHandler) GetGRPCRoute(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	if namespace == "" || name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "namespace and name parameters are required"})
+		return
+	}
+
+	key := fmt.Sprintf("%s/%s", namespace, name)
+	gr := h.store.GetGRPCRoute(key)
+
+	if gr == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "GRPCRoute not found"})
+		return
+	}
+
+	response := GRPCRouteResponse{
+		Name:      name,
+		Namespace: namespace,
+		Spec:      gr.Spec,
+		Status:    gr.Status,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 // GetInferencePool handles GET /debug/config_dump/namespaces/{namespace}/inferencepools/{name}
 func (h *DebugHandler) GetInferencePool(c *gin.Context) {
 	namespace := c.Param("namespace")
@@ -38,6 +38,7 @@ import (
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	workloadv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
 )
 
@@ -87,8 +88,8 @@ func (m *MockStore) DeletePod(podName types.NamespacedName) error {
 	return args.Error(0)
 }
 
-func (m *MockStore) MatchModelServer(modelName string, request *http.Request, gatewayKey string) (types.NamespacedName, bool, *aiv1alpha1.ModelRoute, error) {
-	args := m.Called(modelName, request, gatewayKey)
+func (m *MockStore) MatchModelServer(modelName string, request *http.Request, gatewayKeys []string) (types.NamespacedName, bool, *aiv1alpha1.ModelRoute, error) {
+	args := m.Called(modelName, request, gatewayKeys)
 	var modelRoute *aiv1alpha1.ModelRoute
 	if args.Get(2) != nil {
 		modelRoute = args.Get(2).(*aiv1alpha1.ModelRoute)
@@ -96,6 +97,15 @@ func (m *MockStore) MatchModelServer(modelName string, request *http.Request, ga
 	return args.Get(0).(types.NamespacedName), args.Bool(1), modelRoute, args.Error(3)
 }
 
+func (m *MockStore) MatchModelRoute(modelName string, request *http.Request, gatewayKeys []string) (*datastore.RouteMatch, error) {
+	args := m.Called(modelName, request, gatewayKeys)
+	var match *datastore.RouteMatch
+	if args.Get(0) != nil {
+		match = args.Get(0).(*datastore.RouteMatch)
+	}
+	return match, args.Error(1)
+}
+
 func (m *MockStore) AddOrUpdateModelRoute(mr *aiv1alpha1.ModelRoute) error {
 	args := m.Called(mr)
 	return args.Error(0)
@@ -310,6 +320,120 @@ func (m *MockStore) GetAllHTTPRoutes() []*gatewayv1.HTTPRoute {
 	return args.Get(0).([]*gatewayv1.HTTPRoute)
 }
 
+func (m *MockStore) AddOrUpdateGRPCRoute(grpcRoute *gatewayv1.GRPCRoute) error {
+	args := m.Called(grpcRoute)
+	return args.Error(0)
+}
+
+func (m *MockStore) DeleteGRPCRoute(key string) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *MockStore) GetGRPCRoute(key string) *gatewayv1.GRPCRoute {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(*gatewayv1.GRPCRoute)
+}
+
+func (m *MockStore) GetAllGRPCRoutes() []*gatewayv1.GRPCRoute {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]*gatewayv1.GRPCRoute)
+}
+
+func (m *MockStore) GetGRPCRoutesByGateway(gatewayKey string) []*gatewayv1.GRPCRoute {
+	args := m.Called(gatewayKey)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]*gatewayv1.GRPCRoute)
+}
+
+func (m *MockStore) AddOrUpdateModelServing(modelServing *workloadv1alpha1.ModelServing) error {
+	args := m.Called(modelServing)
+	return args.Error(0)
+}
+
+func (m *MockStore) DeleteModelServing(name types.NamespacedName) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockStore) GetModelServing(name types.NamespacedName) *workloadv1alpha1.ModelServing {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(*workloadv1alpha1.ModelServing)
+}
+
+func (m *MockStore) AddOrUpdateSecret(secret *corev1.Secret) error {
+	args := m.Called(secret)
+	return args.Error(0)
+}
+
+func (m *MockStore) DeleteSecret(name types.NamespacedName) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockStore) AddOrUpdateRateLimitPolicy(policy *aiv1alpha1.RateLimitPolicy) error {
+	args := m.Called(policy)
+	return args.Error(0)
+}
+
+func (m *MockStore) DeleteRateLimitPolicy(name types.NamespacedName) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockStore) GetRateLimitPolicies(namespace string) []*aiv1alpha1.RateLimitPolicy {
+	args := m.Called(namespace)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]*aiv1alpha1.RateLimitPolicy)
+}
+
+func (m *MockStore) AddOrUpdateTokenQuota(quota *aiv1alpha1.TokenQuota) error {
+	args := m.Called(quota)
+	return args.Error(0)
+}
+
+func (m *MockStore) DeleteTokenQuota(name types.NamespacedName) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockStore) GetTokenQuotas(namespace string) []*aiv1alpha1.TokenQuota {
+	args := m.Called(namespace)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]*aiv1alpha1.TokenQuota)
+}
+
+func (m *MockStore) GetSecret(name types.NamespacedName) *corev1.Secret {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(*corev1.Secret)
+}
+
+func (m *MockStore) GetRoutingChanges() []datastore.RoutingChange {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]datastore.RoutingChange)
+}
+
 func (m *MockStore) GetAllInferencePools() []*inferencev1.InferencePool {
 	args := m.Called()
 	if args.Get(0) == nil {
@@ -413,6 +537,44 @@ func TestGetModelRoute(t *testing.T) {
 }
 
 // TestDebugServerIntegration tests the debug server as a whole, including server startup and endpoint accessibility
+func TestListRoutingChanges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockStore := &MockStore{}
+	handler := NewDebugHandler(mockStore)
+
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	mockStore.On("GetRoutingChanges").Return([]datastore.RoutingChange{
+		{Timestamp: start, Kind: "ModelRoute", Namespace: "default", Name: "llama2-route", Action: datastore.RoutingChangeAdded},
+		{Timestamp: start.Add(time.Minute), Kind: "ModelRoute", Namespace: "default", Name: "llama2-route",
+			Action: datastore.RoutingChangeUpdated, Fields: []string{"rules"}},
+	})
+
+	list := func(query string) (int, []datastore.RoutingChange) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("GET", "/debug/routing_changes"+query, nil)
+		handler.ListRoutingChanges(c)
+		var response struct {
+			Changes []datastore.RoutingChange `json:"changes"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response.Changes
+	}
+
+	code, changes := list("")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, changes, 2)
+
+	code, changes = list("?since=2026-01-01T10:00:30Z")
+	assert.Equal(t, http.StatusOK, code)
+	require.Len(t, changes, 1)
+	assert.Equal(t, []string{"rules"}, changes[0].Fields)
+
+	code, _ = list("?since=yesterday")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
 func TestDebugServerIntegration(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
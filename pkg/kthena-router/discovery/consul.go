@@ -0,0 +1,112 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+// consulTimeout bounds the queries of the Consul HTTP API.
+const consulTimeout = 5 * time.Second
+
+// consulDiscoverer queries the instances of a Consul service passing their health checks. The instances on another
+// port than the port of the model server are ignored.
+type consulDiscoverer struct {
+	client   *http.Client
+	resolver resolver
+	url      string
+	service  string
+	port     int32
+}
+
+// consulServiceEntry is the part of an entry of the /v1/health/service API the discoverer reads.
+type consulServiceEntry struct {
+	Node struct {
+		Address string
+	}
+	Service struct {
+		Address string
+		Port    int32
+	}
+}
+
+func newConsulDiscoverer(consul *aiv1alpha1.ConsulDiscovery, port int32) *consulDiscoverer {
+	query := url.Values{"passing": []string{"true"}}
+	if consul.Tag != "" {
+		query.Set("tag", consul.Tag)
+	}
+	if consul.Datacenter != "" {
+		query.Set("dc", consul.Datacenter)
+	}
+	return &consulDiscoverer{
+		client:   &http.Client{Timeout: consulTimeout},
+		resolver: net.DefaultResolver,
+		url:      fmt.Sprintf("%s/v1/health/service/%s?%s", strings.TrimSuffix(consul.Address, "/"), url.PathEscape(consul.Service), query.Encode()),
+		service:  consul.Service,
+		port:     port,
+	}
+}
+
+func (d *consulDiscoverer) Discover(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("consul returned status %d", resp.StatusCode)
+	}
+	var entries []consulServiceEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("failed to decode the instances of the consul service %s: %v", d.service, err)
+	}
+
+	var addresses []string
+	for _, entry := range entries {
+		// The address of the node is the address of the instances registered without one
+		address := entry.Service.Address
+		if address == "" {
+			address = entry.Node.Address
+		}
+		if entry.Service.Port != d.port {
+			klog.V(4).Infof("Ignoring the instance %s:%d of %s, the model server is served on port %d", address, entry.Service.Port, d.service, d.port)
+			continue
+		}
+		resolved, err := resolve(ctx, d.resolver, address)
+		if err != nil {
+			klog.Warningf("Failed to resolve the instance %s of %s: %v", address, d.service, err)
+			continue
+		}
+		addresses = append(addresses, resolved...)
+	}
+	return normalize(addresses), nil
+}
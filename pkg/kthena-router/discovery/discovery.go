@@ -0,0 +1,136 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package discovery finds the instances of the ModelServers which are not pods of the cluster, e.g. the inference
+// engines of bare-metal hosts, and adds them to the store as pods once they pass their health checks.
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+// Discoverer lists the IP addresses of the instances of a model server.
+type Discoverer interface {
+	Discover(ctx context.Context) ([]string, error)
+}
+
+// New returns the discoverer of a model server with discovery. endpointSlices is nil when the EndpointSlices are
+// not watched.
+func New(ms *aiv1alpha1.ModelServer, endpointSlices discoverylisters.EndpointSliceLister) (Discoverer, error) {
+	discovery := ms.Spec.Discovery
+	port := ms.Spec.WorkloadPort.Port
+	switch discovery.Type {
+	case aiv1alpha1.DiscoveryTypeEndpointSlice:
+		if discovery.EndpointSlice == nil {
+			return nil, fmt.Errorf("endpointSlice is not set")
+		}
+		if endpointSlices == nil {
+			return nil, fmt.Errorf("the EndpointSlices are not watched by the router")
+		}
+		return &endpointSliceDiscoverer{
+			lister:  endpointSlices.EndpointSlices(ms.Namespace),
+			service: discovery.EndpointSlice.ServiceName,
+			port:    port,
+		}, nil
+	case aiv1alpha1.DiscoveryTypeStatic:
+		if discovery.Static == nil {
+			return nil, fmt.Errorf("static is not set")
+		}
+		return staticDiscoverer(discovery.Static.Addresses), nil
+	case aiv1alpha1.DiscoveryTypeDNS:
+		if discovery.DNS == nil {
+			return nil, fmt.Errorf("dns is not set")
+		}
+		return &dnsDiscoverer{resolver: net.DefaultResolver, name: discovery.DNS.Name, port: port}, nil
+	case aiv1alpha1.DiscoveryTypeConsul:
+		if discovery.Consul == nil {
+			return nil, fmt.Errorf("consul is not set")
+		}
+		return newConsulDiscoverer(discovery.Consul, port), nil
+	default:
+		return nil, fmt.Errorf("unsupported discovery type %q", discovery.Type)
+	}
+}
+
+// staticDiscoverer returns its addresses.
+type staticDiscoverer []string
+
+func (d staticDiscoverer) Discover(context.Context) ([]string, error) {
+	return normalize(d), nil
+}
+
+// endpointSliceDiscoverer lists the ready endpoints of the EndpointSlices of a Service. The EndpointSlices which
+// declare ports but not the port of the model server are ignored.
+type endpointSliceDiscoverer struct {
+	lister  discoverylisters.EndpointSliceNamespaceLister
+	service string
+	port    int32
+}
+
+func (d *endpointSliceDiscoverer) Discover(context.Context) ([]string, error) {
+	endpointSlices, err := d.lister.List(labels.SelectorFromSet(labels.Set{discoveryv1.LabelServiceName: d.service}))
+	if err != nil {
+		return nil, err
+	}
+	var addresses []string
+	for _, endpointSlice := range endpointSlices {
+		if endpointSlice.AddressType == discoveryv1.AddressTypeFQDN || !servesPort(endpointSlice.Ports, d.port) {
+			continue
+		}
+		for _, endpoint := range endpointSlice.Endpoints {
+			if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+				continue
+			}
+			addresses = append(addresses, endpoint.Addresses...)
+		}
+	}
+	return normalize(addresses), nil
+}
+
+func servesPort(ports []discoveryv1.EndpointPort, port int32) bool {
+	if len(ports) == 0 {
+		return true
+	}
+	for _, endpointPort := range ports {
+		if endpointPort.Port != nil && *endpointPort.Port == port {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize returns the sorted distinct IP addresses of addresses, in their canonical form.
+func normalize(addresses []string) []string {
+	normalized := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		ip := net.ParseIP(address)
+		if ip == nil {
+			continue
+		}
+		normalized = append(normalized, ip.String())
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
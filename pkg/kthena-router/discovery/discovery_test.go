@@ -0,0 +1,166 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+func TestStaticDiscoverer(t *testing.T) {
+	addresses, err := staticDiscoverer{"10.0.0.2", "10.0.0.1", "10.0.0.2", "fd00:0::1"}.Discover(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "fd00::1"}, addresses)
+}
+
+func TestEndpointSliceDiscoverer(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	endpointSlice := func(name, service string, addressType discoveryv1.AddressType, port int32, endpoints ...discoveryv1.Endpoint) *discoveryv1.EndpointSlice {
+		return &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+				Labels:    map[string]string{discoveryv1.LabelServiceName: service},
+			},
+			AddressType: addressType,
+			Ports:       []discoveryv1.EndpointPort{{Port: ptr.To(port)}},
+			Endpoints:   endpoints,
+		}
+	}
+	for _, slice := range []*discoveryv1.EndpointSlice{
+		endpointSlice("vllm-hosts-1", "vllm-hosts", discoveryv1.AddressTypeIPv4, 8000,
+			discoveryv1.Endpoint{Addresses: []string{"10.0.0.1"}},
+			discoveryv1.Endpoint{Addresses: []string{"10.0.0.2"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)}},
+			discoveryv1.Endpoint{Addresses: []string{"10.0.0.3"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false)}}),
+		endpointSlice("vllm-hosts-2", "vllm-hosts", discoveryv1.AddressTypeFQDN, 8000,
+			discoveryv1.Endpoint{Addresses: []string{"gpu-1.example.com"}}),
+		endpointSlice("vllm-hosts-3", "vllm-hosts", discoveryv1.AddressTypeIPv4, 9000,
+			discoveryv1.Endpoint{Addresses: []string{"10.0.0.4"}}),
+		endpointSlice("sglang-hosts-1", "sglang-hosts", discoveryv1.AddressTypeIPv4, 8000,
+			discoveryv1.Endpoint{Addresses: []string{"10.0.1.1"}}),
+	} {
+		require.NoError(t, indexer.Add(slice))
+	}
+
+	ms := &aiv1alpha1.ModelServer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "llama"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			WorkloadPort: aiv1alpha1.WorkloadPort{Port: 8000},
+			Discovery: &aiv1alpha1.Discovery{
+				Type:          aiv1alpha1.DiscoveryTypeEndpointSlice,
+				EndpointSlice: &aiv1alpha1.EndpointSliceDiscovery{ServiceName: "vllm-hosts"},
+			},
+		},
+	}
+	_, err := New(ms, nil)
+	assert.EqualError(t, err, "the EndpointSlices are not watched by the router")
+
+	discoverer, err := New(ms, discoverylisters.NewEndpointSliceLister(indexer))
+	require.NoError(t, err)
+	addresses, err := discoverer.Discover(context.Background())
+	require.NoError(t, err)
+	// The not ready endpoints, the FQDN EndpointSlices and the EndpointSlices of other ports are ignored
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, addresses)
+}
+
+type fakeResolver struct {
+	srv map[string][]*net.SRV
+	ips map[string][]net.IPAddr
+}
+
+func (r *fakeResolver) LookupSRV(_ context.Context, _, _, name string) (string, []*net.SRV, error) {
+	records, ok := r.srv[name]
+	if !ok {
+		return "", nil, fmt.Errorf("no such host %s", name)
+	}
+	return name, records, nil
+}
+
+func (r *fakeResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := r.ips[host]
+	if !ok {
+		return nil, fmt.Errorf("no such host %s", host)
+	}
+	return ips, nil
+}
+
+func TestDNSDiscoverer(t *testing.T) {
+	resolver := &fakeResolver{
+		srv: map[string][]*net.SRV{
+			"_vllm._tcp.example.com": {
+				{Target: "gpu-1.example.com.", Port: 8000},
+				{Target: "gpu-2.example.com.", Port: 8000},
+				{Target: "gpu-3.example.com.", Port: 9000},
+				{Target: "gpu-4.example.com.", Port: 8000},
+			},
+		},
+		ips: map[string][]net.IPAddr{
+			"gpu-1.example.com.": {{IP: net.ParseIP("10.0.0.1")}},
+			"gpu-2.example.com.": {{IP: net.ParseIP("10.0.0.2")}, {IP: net.ParseIP("fd00::2")}},
+			"gpu-3.example.com.": {{IP: net.ParseIP("10.0.0.3")}},
+		},
+	}
+
+	discoverer := &dnsDiscoverer{resolver: resolver, name: "_vllm._tcp.example.com", port: 8000}
+	addresses, err := discoverer.Discover(context.Background())
+	require.NoError(t, err)
+	// The targets of other ports and the unresolved targets are ignored
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "fd00::2"}, addresses)
+
+	discoverer.name = "_sglang._tcp.example.com"
+	_, err = discoverer.Discover(context.Background())
+	assert.Error(t, err)
+}
+
+func TestConsulDiscoverer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/health/service/vllm" || r.URL.Query().Get("passing") != "true" ||
+			r.URL.Query().Get("tag") != "llama" || r.URL.Query().Get("dc") != "eu-west" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`[
+			{"Node": {"Address": "10.0.0.1"}, "Service": {"Address": "", "Port": 8000}},
+			{"Node": {"Address": "10.0.0.9"}, "Service": {"Address": "10.0.0.2", "Port": 8000}},
+			{"Node": {"Address": "10.0.0.3"}, "Service": {"Address": "", "Port": 9000}}
+		]`))
+	}))
+	defer server.Close()
+
+	consul := &aiv1alpha1.ConsulDiscovery{Address: server.URL + "/", Service: "vllm", Tag: "llama", Datacenter: "eu-west"}
+	addresses, err := newConsulDiscoverer(consul, 8000).Discover(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, addresses)
+
+	consul.Service = "sglang"
+	_, err = newConsulDiscoverer(consul, 8000).Discover(context.Background())
+	assert.EqualError(t, err, "consul returned status 404")
+}
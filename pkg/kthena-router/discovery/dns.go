@@ -0,0 +1,76 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"net"
+
+	"k8s.io/klog/v2"
+)
+
+// resolver is the subset of net.Resolver the discoverers use.
+type resolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// dnsDiscoverer resolves the targets of a SRV record. The targets on another port than the port of the model server
+// are ignored.
+type dnsDiscoverer struct {
+	resolver resolver
+	name     string
+	port     int32
+}
+
+func (d *dnsDiscoverer) Discover(ctx context.Context) ([]string, error) {
+	_, records, err := d.resolver.LookupSRV(ctx, "", "", d.name)
+	if err != nil {
+		return nil, err
+	}
+	var addresses []string
+	for _, record := range records {
+		if int32(record.Port) != d.port {
+			klog.V(4).Infof("Ignoring the target %s:%d of %s, the model server is served on port %d", record.Target, record.Port, d.name, d.port)
+			continue
+		}
+		resolved, err := resolve(ctx, d.resolver, record.Target)
+		if err != nil {
+			// The other targets are still discovered
+			klog.Warningf("Failed to resolve the target %s of %s: %v", record.Target, d.name, err)
+			continue
+		}
+		addresses = append(addresses, resolved...)
+	}
+	return normalize(addresses), nil
+}
+
+// resolve returns the IP addresses of a host, which may be an IP address itself.
+func resolve(ctx context.Context, resolver resolver, host string) ([]string, error) {
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+	ips, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	addresses := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		addresses = append(addresses, ip.IP.String())
+	}
+	return addresses, nil
+}
@@ -0,0 +1,396 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1"
+	"k8s.io/klog/v2"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/utils"
+)
+
+// DiscoveredByLabelKey labels the pods standing for the discovered instances in the store with the name of their
+// model server.
+const DiscoveredByLabelKey = "networking.serving.volcano.sh/discovered-by"
+
+const (
+	defaultRefreshInterval     = 30 * time.Second
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultHealthCheckTimeout  = 2 * time.Second
+	defaultUnhealthyThreshold  = 3
+)
+
+// IsDiscovered reports whether a pod of the store stands for a discovered instance.
+func IsDiscovered(pod *corev1.Pod) bool {
+	_, ok := pod.Labels[DiscoveredByLabelKey]
+	return ok
+}
+
+// Manager discovers and health checks the instances of the model servers with discovery. The healthy instances are
+// added to the store as pods of their model server, so that they are scheduled, scraped and routed to like the pods.
+type Manager struct {
+	store          datastore.Store
+	endpointSlices discoverylisters.EndpointSliceLister
+	// client sends the health checks. It doesn't verify the certificates of the https instances, the health checks
+	// carrying no data.
+	client *http.Client
+
+	mutex    sync.Mutex
+	watchers map[types.NamespacedName]*watcher
+}
+
+// NewManager returns a manager adding the instances to the store. endpointSlices is nil when the EndpointSlices are
+// not watched.
+func NewManager(store datastore.Store, endpointSlices discoverylisters.EndpointSliceLister) *Manager {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec
+	return &Manager{
+		store:          store,
+		endpointSlices: endpointSlices,
+		client:         &http.Client{Transport: transport},
+		watchers:       make(map[types.NamespacedName]*watcher),
+	}
+}
+
+// Sync starts discovering the instances of a model server with discovery, or restarts it if the spec of the model
+// server changed. The model server must be in the store.
+func (m *Manager) Sync(ms *aiv1alpha1.ModelServer) error {
+	name := utils.GetNamespaceName(ms)
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	previous := m.watchers[name]
+	if previous != nil && equality.Semantic.DeepEqual(previous.modelServer.Spec, ms.Spec) {
+		return nil
+	}
+	discoverer, err := New(ms, m.endpointSlices)
+	if err != nil {
+		return err
+	}
+
+	w := newWatcher(ms, discoverer, m.store, m.client)
+	if previous != nil {
+		// The healthy instances stay routed to until the new watcher discovers and checks them
+		previous.stop()
+		w.instances = previous.instances
+	}
+	m.watchers[name] = w
+	go w.run()
+	return nil
+}
+
+// Stop stops discovering the instances of a model server and removes them from the store.
+func (m *Manager) Stop(name types.NamespacedName) {
+	m.mutex.Lock()
+	w := m.watchers[name]
+	delete(m.watchers, name)
+	m.mutex.Unlock()
+
+	if w == nil {
+		return
+	}
+	w.stop()
+	for address, instance := range w.instances {
+		if instance.healthy {
+			_ = m.store.DeletePod(w.podName(address))
+		}
+	}
+}
+
+// StopAll stops discovering the instances of all the model servers.
+func (m *Manager) StopAll() {
+	m.mutex.Lock()
+	names := make([]types.NamespacedName, 0, len(m.watchers))
+	for name := range m.watchers {
+		names = append(names, name)
+	}
+	m.mutex.Unlock()
+
+	for _, name := range names {
+		m.Stop(name)
+	}
+}
+
+// RefreshService discovers again the instances of the model servers discovering the EndpointSlices of a Service,
+// e.g. when they changed.
+func (m *Manager) RefreshService(namespace, service string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for name, w := range m.watchers {
+		discovery := w.modelServer.Spec.Discovery
+		if name.Namespace != namespace || discovery.Type != aiv1alpha1.DiscoveryTypeEndpointSlice ||
+			discovery.EndpointSlice == nil || discovery.EndpointSlice.ServiceName != service {
+			continue
+		}
+		select {
+		case w.refresh <- struct{}{}:
+		default:
+			// A refresh is already pending
+		}
+	}
+}
+
+// instance is the health of a discovered instance.
+type instance struct {
+	healthy bool
+	// failures is the number of consecutive failed health checks.
+	failures int32
+}
+
+// watcher discovers and health checks the instances of a model server until it is stopped.
+type watcher struct {
+	modelServer *aiv1alpha1.ModelServer
+	discoverer  Discoverer
+	store       datastore.Store
+	client      *http.Client
+
+	refreshInterval    time.Duration
+	healthCheckScheme  string
+	healthCheckPath    string
+	healthCheckTimeout time.Duration
+	healthInterval     time.Duration
+	unhealthyThreshold int32
+
+	// instances are the discovered instances by address, they are only accessed by run until the watcher is stopped.
+	instances map[string]*instance
+	refresh   chan struct{}
+	cancel    context.CancelFunc
+	ctx       context.Context
+	done      chan struct{}
+}
+
+func newWatcher(ms *aiv1alpha1.ModelServer, discoverer Discoverer, store datastore.Store, client *http.Client) *watcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &watcher{
+		modelServer:        ms,
+		discoverer:         discoverer,
+		store:              store,
+		client:             client,
+		refreshInterval:    defaultRefreshInterval,
+		healthCheckTimeout: defaultHealthCheckTimeout,
+		healthInterval:     defaultHealthCheckInterval,
+		unhealthyThreshold: defaultUnhealthyThreshold,
+		instances:          make(map[string]*instance),
+		refresh:            make(chan struct{}, 1),
+		cancel:             cancel,
+		ctx:                ctx,
+		done:               make(chan struct{}),
+	}
+
+	discovery := ms.Spec.Discovery
+	if discovery.RefreshInterval != nil && discovery.RefreshInterval.Duration > 0 {
+		w.refreshInterval = discovery.RefreshInterval.Duration
+	}
+	w.healthCheckPath = defaultHealthCheckPath(ms.Spec.InferenceEngine)
+	if healthCheck := discovery.HealthCheck; healthCheck != nil {
+		if healthCheck.Path != "" {
+			w.healthCheckPath = healthCheck.Path
+		}
+		if healthCheck.Interval != nil && healthCheck.Interval.Duration > 0 {
+			w.healthInterval = healthCheck.Interval.Duration
+		}
+		if healthCheck.Timeout != nil && healthCheck.Timeout.Duration > 0 {
+			w.healthCheckTimeout = healthCheck.Timeout.Duration
+		}
+		if healthCheck.UnhealthyThreshold != nil && *healthCheck.UnhealthyThreshold > 0 {
+			w.unhealthyThreshold = *healthCheck.UnhealthyThreshold
+		}
+	}
+	w.healthCheckScheme = "http"
+	if ms.Spec.WorkloadPort.Protocol == "https" {
+		w.healthCheckScheme = "https"
+	}
+	return w
+}
+
+// defaultHealthCheckPath returns the path of the health endpoint of an inference engine.
+func defaultHealthCheckPath(engine aiv1alpha1.InferenceEngine) string {
+	if engine == aiv1alpha1.Triton {
+		return "/v2/health/ready"
+	}
+	return "/health"
+}
+
+func (w *watcher) stop() {
+	w.cancel()
+	<-w.done
+}
+
+func (w *watcher) run() {
+	defer close(w.done)
+
+	// The instances handed over by the previous watcher are routed to with the new spec of the model server
+	for address, instance := range w.instances {
+		if instance.healthy {
+			w.addPod(address)
+		}
+	}
+	w.discover()
+	w.check()
+
+	refreshTicker := time.NewTicker(w.refreshInterval)
+	defer refreshTicker.Stop()
+	healthTicker := time.NewTicker(w.healthInterval)
+	defer healthTicker.Stop()
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-w.refresh:
+			w.discover()
+			w.check()
+		case <-refreshTicker.C:
+			w.discover()
+		case <-healthTicker.C:
+			w.check()
+		}
+	}
+}
+
+// discover updates the instances with the discovered ones. The instances are kept if the discovery fails.
+func (w *watcher) discover() {
+	addresses, err := w.discoverer.Discover(w.ctx)
+	if err != nil {
+		if w.ctx.Err() == nil {
+			klog.Warningf("Failed to discover the instances of model server %s/%s: %v", w.modelServer.Namespace, w.modelServer.Name, err)
+		}
+		return
+	}
+
+	discovered := make(map[string]*instance, len(addresses))
+	for _, address := range addresses {
+		if instance, ok := w.instances[address]; ok {
+			discovered[address] = instance
+			continue
+		}
+		// The new instances are routed to once they pass a health check
+		discovered[address] = &instance{}
+	}
+	for address, instance := range w.instances {
+		if _, ok := discovered[address]; !ok && instance.healthy {
+			klog.V(2).Infof("Instance %s of model server %s/%s is no longer discovered", address, w.modelServer.Namespace, w.modelServer.Name)
+			_ = w.store.DeletePod(w.podName(address))
+		}
+	}
+	w.instances = discovered
+}
+
+// check health checks the instances concurrently, and adds them to or removes them from the store as their health
+// changes.
+func (w *watcher) check() {
+	addresses := make([]string, 0, len(w.instances))
+	for address := range w.instances {
+		addresses = append(addresses, address)
+	}
+	results := make([]bool, len(addresses))
+	var wg sync.WaitGroup
+	for i, address := range addresses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = w.healthy(address)
+		}()
+	}
+	wg.Wait()
+	if w.ctx.Err() != nil {
+		return
+	}
+
+	for i, address := range addresses {
+		instance := w.instances[address]
+		if results[i] {
+			instance.failures = 0
+			if !instance.healthy {
+				klog.V(2).Infof("Instance %s of model server %s/%s is healthy", address, w.modelServer.Namespace, w.modelServer.Name)
+				instance.healthy = true
+				w.addPod(address)
+			}
+			continue
+		}
+		instance.failures++
+		if instance.healthy && instance.failures >= w.unhealthyThreshold {
+			klog.V(2).Infof("Instance %s of model server %s/%s failed %d health checks", address, w.modelServer.Namespace, w.modelServer.Name, instance.failures)
+			instance.healthy = false
+			_ = w.store.DeletePod(w.podName(address))
+		}
+	}
+}
+
+// healthy sends a health check to an instance.
+func (w *watcher) healthy(address string) bool {
+	ctx, cancel := context.WithTimeout(w.ctx, w.healthCheckTimeout)
+	defer cancel()
+	url := fmt.Sprintf("%s://%s%s", w.healthCheckScheme, net.JoinHostPort(address, strconv.Itoa(int(w.modelServer.Spec.WorkloadPort.Port))), w.healthCheckPath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode >= 200 && resp.StatusCode < 300
+}
+
+func (w *watcher) podName(address string) types.NamespacedName {
+	return types.NamespacedName{Namespace: w.modelServer.Namespace, Name: w.modelServer.Name + "@" + address}
+}
+
+// addPod adds the pod standing for an instance to the store. Its name, which isn't a valid pod name, can't collide
+// with the names of the pods.
+func (w *watcher) addPod(address string) {
+	name := w.podName(address)
+	now := metav1.Now()
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: name.Namespace,
+			Name:      name.Name,
+			Labels:    map[string]string{DiscoveredByLabelKey: w.modelServer.Name},
+		},
+		Status: corev1.PodStatus{
+			Phase:     corev1.PodRunning,
+			PodIP:     address,
+			PodIPs:    []corev1.PodIP{{IP: address}},
+			StartTime: &now,
+			Conditions: []corev1.PodCondition{{
+				Type:               corev1.PodReady,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: now,
+			}},
+		},
+	}
+	if err := w.store.AddOrUpdatePod(pod, []*aiv1alpha1.ModelServer{w.modelServer}); err != nil {
+		klog.Warningf("Failed to add instance %s of model server %s/%s to the store: %v", address, w.modelServer.Namespace, w.modelServer.Name, err)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+)
+
+func TestManager_HealthChecks(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ready" || !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	ms := &aiv1alpha1.ModelServer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "llama"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			InferenceEngine: aiv1alpha1.VLLM,
+			WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(portNumber)},
+			Discovery: &aiv1alpha1.Discovery{
+				Type: aiv1alpha1.DiscoveryTypeStatic,
+				// Nothing listens on the port of the model server on 127.0.0.2
+				Static: &aiv1alpha1.StaticDiscovery{Addresses: []string{"127.0.0.1", "127.0.0.2"}},
+				HealthCheck: &aiv1alpha1.HealthCheck{
+					Path:               "/ready",
+					Interval:           &metav1.Duration{Duration: 20 * time.Millisecond},
+					Timeout:            &metav1.Duration{Duration: 100 * time.Millisecond},
+					UnhealthyThreshold: ptr.To[int32](2),
+				},
+			},
+		},
+	}
+	name := types.NamespacedName{Namespace: "default", Name: "llama"}
+	store := datastore.New()
+	require.NoError(t, store.AddOrUpdateModelServer(ms, nil))
+	manager := NewManager(store, nil)
+	require.NoError(t, manager.Sync(ms))
+	defer manager.StopAll()
+
+	routedAddresses := func() []string {
+		pods, _ := store.GetPodsByModelServer(name)
+		var addresses []string
+		for _, pod := range pods {
+			assert.True(t, IsDiscovered(pod.Pod))
+			addresses = append(addresses, pod.Pod.Status.PodIP)
+		}
+		return addresses
+	}
+	assert.Eventually(t, func() bool {
+		addresses := routedAddresses()
+		return len(addresses) == 1 && addresses[0] == "127.0.0.1"
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "llama@127.0.0.1", store.GetPodInfo(types.NamespacedName{Namespace: "default", Name: "llama@127.0.0.1"}).Pod.Name)
+
+	// The instance failing its health checks is no longer routed to, until it passes one again
+	healthy.Store(false)
+	assert.Eventually(t, func() bool { return len(routedAddresses()) == 0 }, 5*time.Second, 10*time.Millisecond)
+	healthy.Store(true)
+	assert.Eventually(t, func() bool { return len(routedAddresses()) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// The instance no longer listed is removed
+	updated := ms.DeepCopy()
+	updated.Spec.Discovery.Static.Addresses = []string{"127.0.0.2"}
+	require.NoError(t, store.AddOrUpdateModelServer(updated, nil))
+	require.NoError(t, manager.Sync(updated))
+	assert.Eventually(t, func() bool { return len(routedAddresses()) == 0 }, 5*time.Second, 10*time.Millisecond)
+
+	updated = ms.DeepCopy()
+	require.NoError(t, store.AddOrUpdateModelServer(updated, nil))
+	require.NoError(t, manager.Sync(updated))
+	assert.Eventually(t, func() bool { return len(routedAddresses()) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	manager.Stop(name)
+	assert.Empty(t, routedAddresses())
+	assert.Nil(t, store.GetPodInfo(types.NamespacedName{Namespace: "default", Name: "llama@127.0.0.1"}))
+}
+
+func TestDefaultHealthCheckPath(t *testing.T) {
+	assert.Equal(t, "/health", defaultHealthCheckPath(aiv1alpha1.VLLM))
+	assert.Equal(t, "/health", defaultHealthCheckPath(aiv1alpha1.SGLang))
+	assert.Equal(t, "/v2/health/ready", defaultHealthCheckPath(aiv1alpha1.Triton))
+}
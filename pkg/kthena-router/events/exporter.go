@@ -0,0 +1,224 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package events streams per-request usage and routing decision events to a message broker,
+// so that downstream analytics can consume them without scraping access logs.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+const (
+	BackendKafka = "kafka"
+	BackendNATS  = "nats"
+
+	// EventTypeUsage is the type of the event emitted once per completed request.
+	EventTypeUsage = "usage"
+
+	defaultBatchSize     = 100
+	defaultFlushInterval = time.Second
+	defaultQueueSize     = 10000
+
+	minRetryBackoff = 100 * time.Millisecond
+	maxRetryBackoff = 10 * time.Second
+	// drainTimeout bounds the time spent publishing buffered events on shutdown.
+	drainTimeout = 5 * time.Second
+)
+
+// Event is the record published for every request handled by the router.
+// It carries the same fields as the access log entry, plus the event type and the emitting router.
+type Event struct {
+	Type   string `json:"type"`
+	Source string `json:"source,omitempty"`
+	*accesslog.AccessLogEntry
+}
+
+// Sink publishes a batch of encoded events to a topic.
+// Publish must only return nil once every message of the batch has been accepted by the broker.
+type Sink interface {
+	Publish(ctx context.Context, topic string, messages [][]byte) error
+	Close() error
+}
+
+// Exporter buffers events and publishes them to a Sink in batches.
+// Delivery is at-least-once for events accepted into the queue: a failed batch is retried until it
+// succeeds, so consumers must tolerate duplicates. Events are dropped only when the queue is full.
+type Exporter struct {
+	sink          Sink
+	backend       string
+	topic         string
+	source        string
+	batchSize     int
+	flushInterval time.Duration
+	queue         chan []byte
+	done          chan struct{}
+}
+
+var _ accesslog.AccessLogger = &Exporter{}
+
+// NewExporter creates an exporter with the sink described by the configuration.
+func NewExporter(cfg conf.EventsConfig) (*Exporter, error) {
+	if cfg.Topic == "" {
+		return nil, fmt.Errorf("events topic must be set")
+	}
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("events endpoint must be set")
+	}
+
+	var sink Sink
+	switch cfg.Backend {
+	case BackendKafka:
+		sink = NewKafkaSink(cfg.Endpoint)
+	case BackendNATS:
+		sink = NewNATSSink(cfg.Endpoint)
+	default:
+		return nil, fmt.Errorf("unsupported events backend %q, valid backends are: %s, %s", cfg.Backend, BackendKafka, BackendNATS)
+	}
+	return newExporter(cfg, sink), nil
+}
+
+func newExporter(cfg conf.EventsConfig, sink Sink) *Exporter {
+	e := &Exporter{
+		sink:          sink,
+		backend:       cfg.Backend,
+		topic:         cfg.Topic,
+		source:        os.Getenv("POD_NAME"),
+		batchSize:     cfg.BatchSize,
+		flushInterval: time.Duration(cfg.FlushIntervalMs) * time.Millisecond,
+		done:          make(chan struct{}),
+	}
+	if e.batchSize <= 0 {
+		e.batchSize = defaultBatchSize
+	}
+	if e.flushInterval <= 0 {
+		e.flushInterval = defaultFlushInterval
+	}
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+	e.queue = make(chan []byte, queueSize)
+	return e
+}
+
+// Log enqueues the event of a completed request. It never blocks the request path.
+func (e *Exporter) Log(entry *accesslog.AccessLogEntry) error {
+	if entry == nil {
+		return nil
+	}
+	data, err := json.Marshal(&Event{Type: EventTypeUsage, Source: e.source, AccessLogEntry: entry})
+	if err != nil {
+		return fmt.Errorf("failed to encode usage event: %w", err)
+	}
+	select {
+	case e.queue <- data:
+		return nil
+	default:
+		metrics.DefaultMetrics.EventsDropped.WithLabelValues(e.backend).Inc()
+		return fmt.Errorf("usage event queue is full, event of request %s dropped", entry.RequestID)
+	}
+}
+
+// Run publishes queued events until the context is cancelled, then drains the queue.
+func (e *Exporter) Run(ctx context.Context) {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([][]byte, 0, e.batchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			e.drain(batch)
+			return
+		case data := <-e.queue:
+			batch = append(batch, data)
+			if len(batch) < e.batchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+		if !e.publish(ctx, batch) {
+			// Shutting down, the batch has not been published yet.
+			e.drain(batch)
+			return
+		}
+		batch = batch[:0]
+	}
+}
+
+// publish sends the batch and retries with backoff until it succeeds.
+// It returns false if the context is cancelled before the batch is published.
+func (e *Exporter) publish(ctx context.Context, batch [][]byte) bool {
+	backoff := minRetryBackoff
+	for {
+		err := e.sink.Publish(ctx, e.topic, batch)
+		if err == nil {
+			metrics.DefaultMetrics.EventsExported.WithLabelValues(e.backend).Add(float64(len(batch)))
+			return true
+		}
+		klog.Warningf("failed to publish %d usage events to %s, retrying in %v: %v", len(batch), e.backend, backoff, err)
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+// drain publishes the pending batch and the events left in the queue once, within drainTimeout.
+func (e *Exporter) drain(batch [][]byte) {
+	for len(e.queue) > 0 {
+		batch = append(batch, <-e.queue)
+	}
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+	for start := 0; start < len(batch); start += e.batchSize {
+		end := min(start+e.batchSize, len(batch))
+		if err := e.sink.Publish(ctx, e.topic, batch[start:end]); err != nil {
+			klog.Errorf("failed to publish %d usage events on shutdown: %v", len(batch)-start, err)
+			return
+		}
+		metrics.DefaultMetrics.EventsExported.WithLabelValues(e.backend).Add(float64(end - start))
+	}
+}
+
+// Close waits for Run to drain the queue and closes the sink.
+// The context passed to Run must be cancelled before calling Close.
+func (e *Exporter) Close() error {
+	<-e.done
+	return e.sink.Close()
+}
@@ -0,0 +1,226 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+type fakeSink struct {
+	mu        sync.Mutex
+	failures  int
+	published [][]byte
+	batches   int
+}
+
+func (s *fakeSink) Publish(_ context.Context, _ string, messages [][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("broker unavailable")
+	}
+	s.batches++
+	for _, msg := range messages {
+		s.published = append(s.published, append([]byte(nil), msg...))
+	}
+	return nil
+}
+
+func (s *fakeSink) Close() error { return nil }
+
+func (s *fakeSink) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.published)
+}
+
+func TestNewExporter(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         conf.EventsConfig
+		expectError bool
+	}{
+		{name: "kafka", cfg: conf.EventsConfig{Backend: BackendKafka, Endpoint: "http://kafka-rest:8082", Topic: "usage"}},
+		{name: "nats", cfg: conf.EventsConfig{Backend: BackendNATS, Endpoint: "nats://nats:4222", Topic: "usage"}},
+		{name: "unknown backend", cfg: conf.EventsConfig{Backend: "pulsar", Endpoint: "pulsar:6650", Topic: "usage"}, expectError: true},
+		{name: "missing topic", cfg: conf.EventsConfig{Backend: BackendNATS, Endpoint: "nats:4222"}, expectError: true},
+		{name: "missing endpoint", cfg: conf.EventsConfig{Backend: BackendNATS, Topic: "usage"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewExporter(tt.cfg)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestExporter_BatchesAndRetries(t *testing.T) {
+	sink := &fakeSink{failures: 2}
+	e := newExporter(conf.EventsConfig{Backend: "fake", Topic: "usage", BatchSize: 2, FlushIntervalMs: 10}, sink)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go e.Run(ctx)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, e.Log(&accesslog.AccessLogEntry{RequestID: fmt.Sprintf("req-%d", i), ModelName: "llama", InputTokens: 10}))
+	}
+	assert.Eventually(t, func() bool { return sink.count() == 3 }, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, e.Close())
+
+	var event map[string]any
+	require.NoError(t, json.Unmarshal(sink.published[0], &event))
+	assert.Equal(t, EventTypeUsage, event["type"])
+	assert.Equal(t, "req-0", event["request_id"])
+	assert.Equal(t, "llama", event["model_name"])
+	assert.EqualValues(t, 10, event["input_tokens"])
+}
+
+func TestExporter_DrainOnShutdown(t *testing.T) {
+	sink := &fakeSink{}
+	e := newExporter(conf.EventsConfig{Backend: "fake", Topic: "usage", BatchSize: 100, FlushIntervalMs: 60000}, sink)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go e.Run(ctx)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, e.Log(&accesslog.AccessLogEntry{RequestID: fmt.Sprintf("req-%d", i)}))
+	}
+	cancel()
+	require.NoError(t, e.Close())
+	assert.Equal(t, 5, sink.count())
+}
+
+func TestExporter_QueueFull(t *testing.T) {
+	e := newExporter(conf.EventsConfig{Backend: "fake", Topic: "usage", QueueSize: 1}, &fakeSink{})
+	assert.NoError(t, e.Log(&accesslog.AccessLogEntry{RequestID: "req-0"}))
+	assert.Error(t, e.Log(&accesslog.AccessLogEntry{RequestID: "req-1"}))
+}
+
+func TestKafkaSink_Publish(t *testing.T) {
+	var received kafkaProduceRequest
+	rejected := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/topics/usage", r.URL.Path)
+		assert.Equal(t, kafkaContentType, r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		assert.NoError(t, json.Unmarshal(body, &received))
+		if rejected {
+			_, _ = w.Write([]byte(`{"offsets":[{"partition":0,"offset":-1,"error_code":50002,"error":"broker unavailable"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"offsets":[{"partition":0,"offset":1,"error_code":null,"error":null}]}`))
+	}))
+	defer server.Close()
+
+	sink := NewKafkaSink(server.URL + "/")
+	defer sink.Close()
+
+	require.NoError(t, sink.Publish(context.Background(), "usage", [][]byte{[]byte(`{"request_id":"req-0"}`)}))
+	require.Len(t, received.Records, 1)
+	assert.JSONEq(t, `{"request_id":"req-0"}`, string(received.Records[0].Value))
+
+	rejected = true
+	assert.Error(t, sink.Publish(context.Background(), "usage", [][]byte{[]byte(`{}`)}))
+}
+
+// fakeNATSServer accepts a single client and records the payloads published to it.
+func fakeNATSServer(t *testing.T, errReply string) (string, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	payloads := make(chan string, 10)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n"))
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			switch {
+			case strings.HasPrefix(line, "PUB "):
+				var subject string
+				var size int
+				_, _ = fmt.Sscanf(line, "PUB %s %d", &subject, &size)
+				payload := make([]byte, size+2)
+				if _, err := io.ReadFull(reader, payload); err != nil {
+					return
+				}
+				payloads <- subject + " " + string(payload[:size])
+			case line == "PING":
+				if errReply != "" {
+					_, _ = conn.Write([]byte(errReply + "\r\n"))
+				} else {
+					_, _ = conn.Write([]byte("PONG\r\n"))
+				}
+			}
+		}
+	}()
+	return listener.Addr().String(), payloads
+}
+
+func TestNATSSink_Publish(t *testing.T) {
+	address, payloads := fakeNATSServer(t, "")
+	sink := NewNATSSink("nats://" + address)
+	defer sink.Close()
+
+	require.NoError(t, sink.Publish(context.Background(), "usage", [][]byte{[]byte(`{"a":1}`), []byte(`{"b":2}`)}))
+	assert.Equal(t, `usage {"a":1}`, <-payloads)
+	assert.Equal(t, `usage {"b":2}`, <-payloads)
+}
+
+func TestNATSSink_ServerError(t *testing.T) {
+	address, _ := fakeNATSServer(t, "-ERR 'Permissions Violation'")
+	sink := NewNATSSink(address)
+	defer sink.Close()
+
+	err := sink.Publish(context.Background(), "usage", [][]byte{[]byte(`{}`)})
+	assert.ErrorContains(t, err, "Permissions Violation")
+	assert.Nil(t, sink.conn)
+}
@@ -0,0 +1,114 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	kafkaContentType    = "application/vnd.kafka.json.v2+json"
+	kafkaRequestTimeout = 10 * time.Second
+)
+
+// KafkaSink publishes events through the Kafka REST proxy v2 API.
+type KafkaSink struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewKafkaSink creates a sink that produces to the Kafka REST proxy at the given URL.
+func NewKafkaSink(endpoint string) *KafkaSink {
+	return &KafkaSink{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		client:   &http.Client{Timeout: kafkaRequestTimeout},
+	}
+}
+
+type kafkaRecord struct {
+	Value json.RawMessage `json:"value"`
+}
+
+type kafkaProduceRequest struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		Partition int    `json:"partition"`
+		Offset    int64  `json:"offset"`
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// Publish produces the messages to the topic, it fails if any record is rejected by the proxy.
+func (s *KafkaSink) Publish(ctx context.Context, topic string, messages [][]byte) error {
+	produce := kafkaProduceRequest{Records: make([]kafkaRecord, 0, len(messages))}
+	for _, msg := range messages {
+		produce.Records = append(produce.Records, kafkaRecord{Value: msg})
+	}
+	body, err := json.Marshal(produce)
+	if err != nil {
+		return fmt.Errorf("failed to encode kafka produce request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint+"/topics/"+url.PathEscape(topic), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("kafka produce request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read kafka produce response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("kafka produce request returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var produced kafkaProduceResponse
+	if err := json.Unmarshal(respBody, &produced); err != nil {
+		return fmt.Errorf("failed to decode kafka produce response: %w", err)
+	}
+	for _, offset := range produced.Offsets {
+		if offset.ErrorCode != nil {
+			return fmt.Errorf("kafka rejected record on partition %d with error code %d: %s", offset.Partition, *offset.ErrorCode, offset.Error)
+		}
+	}
+	return nil
+}
+
+func (s *KafkaSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
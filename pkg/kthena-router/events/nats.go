@@ -0,0 +1,163 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const natsIOTimeout = 10 * time.Second
+
+// NATSSink publishes events to a NATS server using the core NATS text protocol.
+// Every batch is followed by a PING, and the batch is only considered published once the
+// matching PONG is received, which guarantees the server has processed all messages.
+type NATSSink struct {
+	address string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+}
+
+// NewNATSSink creates a sink for the NATS server at the given address, e.g. "nats://nats:4222".
+func NewNATSSink(address string) *NATSSink {
+	return &NATSSink{address: strings.TrimPrefix(address, "nats://")}
+}
+
+// Publish sends the messages to the subject, reconnecting first if the previous connection failed.
+func (s *NATSSink) Publish(ctx context.Context, subject string, messages [][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		if err := s.connect(ctx); err != nil {
+			return err
+		}
+	}
+	if err := s.publish(ctx, subject, messages); err != nil {
+		s.closeConn()
+		return err
+	}
+	return nil
+}
+
+func (s *NATSSink) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: natsIOTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to nats server %s: %w", s.address, err)
+	}
+	s.conn = conn
+	s.reader = bufio.NewReader(conn)
+	s.writer = bufio.NewWriter(conn)
+	s.setDeadline(ctx)
+
+	// The server greets every client with an INFO message.
+	line, err := s.readLine()
+	if err != nil {
+		s.closeConn()
+		return fmt.Errorf("failed to read nats server info: %w", err)
+	}
+	if !strings.HasPrefix(line, "INFO") {
+		s.closeConn()
+		return fmt.Errorf("unexpected nats server greeting: %q", line)
+	}
+	if _, err := s.writer.WriteString(`CONNECT {"verbose":false,"pedantic":false,"name":"kthena-router"}` + "\r\n"); err != nil {
+		s.closeConn()
+		return err
+	}
+	return nil
+}
+
+func (s *NATSSink) publish(ctx context.Context, subject string, messages [][]byte) error {
+	s.setDeadline(ctx)
+	for _, msg := range messages {
+		if _, err := fmt.Fprintf(s.writer, "PUB %s %d\r\n", subject, len(msg)); err != nil {
+			return err
+		}
+		if _, err := s.writer.Write(msg); err != nil {
+			return err
+		}
+		if _, err := s.writer.WriteString("\r\n"); err != nil {
+			return err
+		}
+	}
+	if _, err := s.writer.WriteString("PING\r\n"); err != nil {
+		return err
+	}
+	if err := s.writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write to nats server: %w", err)
+	}
+
+	for {
+		line, err := s.readLine()
+		if err != nil {
+			return fmt.Errorf("failed to read nats server reply: %w", err)
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := s.writer.WriteString("PONG\r\n"); err != nil {
+				return err
+			}
+			if err := s.writer.Flush(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats server error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+		// +OK and INFO updates are ignored.
+	}
+}
+
+func (s *NATSSink) setDeadline(ctx context.Context) {
+	deadline := time.Now().Add(natsIOTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = s.conn.SetDeadline(deadline)
+}
+
+func (s *NATSSink) readLine() (string, error) {
+	line, err := s.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (s *NATSSink) closeConn() {
+	if s.conn != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+}
+
+func (s *NATSSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closeConn()
+	return nil
+}
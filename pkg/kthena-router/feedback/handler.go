@@ -0,0 +1,75 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feedback
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+)
+
+// Path is the endpoint clients report feedback to.
+const Path = "/v1/feedback"
+
+// StatsResponse is returned by GET requests on the feedback endpoint.
+type StatsResponse struct {
+	Variants []VariantStats `json:"variants"`
+}
+
+// Handler serves the feedback endpoint:
+// POST reports the feedback of a request sent by the authenticated caller, GET returns the aggregated stats, optionally
+// filtered by the "model" query.
+func (t *Tracker) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost:
+			var f Feedback
+			if err := c.ShouldBindJSON(&f); err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid feedback: " + err.Error()})
+				return
+			}
+			if err := f.Validate(); err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid feedback: " + err.Error()})
+				return
+			}
+			if err := t.Submit(c.Request.Context(), c.GetString(common.UserIdKey), &f); err != nil {
+				switch {
+				case errors.Is(err, ErrUnknownRequest):
+					c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": err.Error()})
+				case errors.Is(err, ErrAlreadyReported):
+					c.AbortWithStatusJSON(http.StatusConflict, gin.H{"message": err.Error()})
+				default:
+					c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+				}
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"message": "feedback recorded"})
+		case http.MethodGet:
+			stats, err := t.Stats(c.Request.Context(), c.Query("model"))
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+				return
+			}
+			c.JSON(http.StatusOK, StatsResponse{Variants: stats})
+		default:
+			c.AbortWithStatusJSON(http.StatusMethodNotAllowed, gin.H{"message": "method not allowed"})
+		}
+	}
+}
@@ -0,0 +1,402 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feedback
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	requestKeyPrefix = "kthena:feedback:request:"
+	statsKey         = "kthena:feedback:stats"
+
+	fieldModel       = "model"
+	fieldModelServer = "modelServer"
+	fieldReported    = "reported"
+
+	counterPositive        = "positive"
+	counterNegative        = "negative"
+	counterScoreCount      = "score_count"
+	counterScoreSum        = "score_sum"
+	counterEvaluationCount = "evaluation_count"
+	counterEvaluationSum   = "evaluation_sum"
+)
+
+// Variant is a model served by one model server.
+type Variant struct {
+	Model       string
+	ModelServer string
+}
+
+// Store keeps the requests feedback can be reported for and the stats aggregated from the feedback. The requests are
+// identified by the caller which sent them and their request id, so that a caller can't report feedback on the
+// requests of another caller.
+type Store interface {
+	// Track records the variant which served the request of the caller.
+	Track(ctx context.Context, caller, requestID string, variant Variant) error
+	// Claim returns the variant which served the request of the caller and marks it reported, so that a single
+	// feedback is accepted per request. It returns ErrUnknownRequest if the request isn't tracked for the caller and
+	// ErrAlreadyReported if it was already claimed.
+	Claim(ctx context.Context, caller, requestID string) (Variant, error)
+	// Release unmarks a claimed request of the caller whose feedback couldn't be added, so that it can be reported
+	// again.
+	Release(ctx context.Context, caller, requestID string) error
+	// Add aggregates the feedback into the stats of the variant.
+	Add(ctx context.Context, variant Variant, f *Feedback) error
+	// AddEvaluation aggregates the score of an evaluation sink into the stats of the variant.
+	AddEvaluation(ctx context.Context, variant Variant, score float64) error
+	// Stats returns the stats of every variant.
+	Stats(ctx context.Context) ([]VariantStats, error)
+}
+
+type servedRequest struct {
+	caller      string
+	requestID   string
+	variant     Variant
+	completedAt time.Time
+	reported    bool
+}
+
+type requestKey struct {
+	caller    string
+	requestID string
+}
+
+// MemoryStore is a Store local to a router replica, feedback is only accepted by the replica which served the
+// request and the stats only cover the feedback it accepted.
+type MemoryStore struct {
+	maxRequests int
+	ttl         time.Duration
+	now         func() time.Time
+
+	mu       sync.Mutex
+	requests map[requestKey]*list.Element
+	// order holds the tracked requests from the oldest to the most recent.
+	order *list.List
+	stats map[Variant]*VariantStats
+}
+
+var _ Store = &MemoryStore{}
+
+// NewMemoryStore creates a store accepting feedback for the last maxRequests requests completed within ttl.
+func NewMemoryStore(maxRequests int, ttl time.Duration) *MemoryStore {
+	if maxRequests <= 0 {
+		maxRequests = DefaultMaxRequests
+	}
+	if ttl <= 0 {
+		ttl = DefaultRequestTTL
+	}
+	return &MemoryStore{
+		maxRequests: maxRequests,
+		ttl:         ttl,
+		now:         time.Now,
+		requests:    make(map[requestKey]*list.Element),
+		order:       list.New(),
+		stats:       make(map[Variant]*VariantStats),
+	}
+}
+
+func (s *MemoryStore) Track(_ context.Context, caller, requestID string, variant Variant) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	key := requestKey{caller: caller, requestID: requestID}
+	if elem, ok := s.requests[key]; ok {
+		s.order.Remove(elem)
+	}
+	s.requests[key] = s.order.PushBack(&servedRequest{
+		caller:      caller,
+		requestID:   requestID,
+		variant:     variant,
+		completedAt: now,
+	})
+	s.evictLocked(now)
+	return nil
+}
+
+// evictLocked drops the requests which are expired or exceed the capacity.
+func (s *MemoryStore) evictLocked(now time.Time) {
+	for elem := s.order.Front(); elem != nil; elem = s.order.Front() {
+		req := elem.Value.(*servedRequest)
+		if s.order.Len() <= s.maxRequests && now.Sub(req.completedAt) <= s.ttl {
+			return
+		}
+		s.order.Remove(elem)
+		delete(s.requests, requestKey{caller: req.caller, requestID: req.requestID})
+	}
+}
+
+func (s *MemoryStore) Claim(_ context.Context, caller, requestID string) (Variant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictLocked(s.now())
+	elem, ok := s.requests[requestKey{caller: caller, requestID: requestID}]
+	if !ok {
+		return Variant{}, ErrUnknownRequest
+	}
+	req := elem.Value.(*servedRequest)
+	if req.reported {
+		return Variant{}, ErrAlreadyReported
+	}
+	req.reported = true
+	return req.variant, nil
+}
+
+func (s *MemoryStore) Release(_ context.Context, caller, requestID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.requests[requestKey{caller: caller, requestID: requestID}]; ok {
+		elem.Value.(*servedRequest).reported = false
+	}
+	return nil
+}
+
+func (s *MemoryStore) Add(_ context.Context, variant Variant, f *Feedback) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.statsLocked(variant)
+	switch f.Rating {
+	case RatingPositive:
+		stats.Positive++
+	case RatingNegative:
+		stats.Negative++
+	}
+	if f.Score != nil {
+		stats.ScoreCount++
+		stats.scoreSum += *f.Score
+		stats.ScoreAverage = stats.scoreSum / float64(stats.ScoreCount)
+	}
+	return nil
+}
+
+func (s *MemoryStore) AddEvaluation(_ context.Context, variant Variant, score float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.statsLocked(variant)
+	stats.EvaluationCount++
+	stats.evaluationSum += score
+	stats.EvaluationAverage = stats.evaluationSum / float64(stats.EvaluationCount)
+	return nil
+}
+
+// statsLocked returns the stats of the variant, created if it has none yet.
+func (s *MemoryStore) statsLocked(variant Variant) *VariantStats {
+	stats, ok := s.stats[variant]
+	if !ok {
+		stats = &VariantStats{Model: variant.Model, ModelServer: variant.ModelServer}
+		s.stats[variant] = stats
+	}
+	return stats
+}
+
+func (s *MemoryStore) Stats(_ context.Context) ([]VariantStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]VariantStats, 0, len(s.stats))
+	for _, stats := range s.stats {
+		result = append(result, *stats)
+	}
+	return result, nil
+}
+
+// RedisStore is a Store shared by the router replicas through Redis, so that feedback can be reported to any replica
+// and the stats cover the feedback accepted by all of them.
+type RedisStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+var _ Store = &RedisStore{}
+
+// NewRedisStore creates a store accepting feedback for the requests completed within ttl.
+func NewRedisStore(client *redis.Client, ttl time.Duration) *RedisStore {
+	if ttl <= 0 {
+		ttl = DefaultRequestTTL
+	}
+	return &RedisStore{client: client, ttl: ttl}
+}
+
+// requestRedisKey returns the key of a request. The caller is hashed, so that it can't collide with the request id
+// whatever characters they contain.
+func requestRedisKey(caller, requestID string) string {
+	if caller == "" {
+		return requestKeyPrefix + requestID
+	}
+	hash := sha256.Sum256([]byte(caller))
+	return requestKeyPrefix + hex.EncodeToString(hash[:]) + ":" + requestID
+}
+
+// statsField returns the field of the stats hash holding a counter of the variant. The model and the model server
+// can't contain a NUL character.
+func statsField(variant Variant, counter string) string {
+	return variant.Model + "\x00" + variant.ModelServer + "\x00" + counter
+}
+
+func (s *RedisStore) Track(ctx context.Context, caller, requestID string, variant Variant) error {
+	key := requestRedisKey(caller, requestID)
+	pipe := s.client.TxPipeline()
+	pipe.Del(ctx, key)
+	pipe.HSet(ctx, key, fieldModel, variant.Model, fieldModelServer, variant.ModelServer)
+	pipe.Expire(ctx, key, s.ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to track request %s: %w", requestID, err)
+	}
+	return nil
+}
+
+// claimScript marks a tracked request reported and returns its model, model server and whether it was already
+// reported, or nil if it isn't tracked.
+var claimScript = redis.NewScript(`
+local request = redis.call("HMGET", KEYS[1], "model", "modelServer", "reported")
+if not request[1] then
+	return nil
+end
+if request[3] then
+	return {request[1], request[2], 1}
+end
+redis.call("HSET", KEYS[1], "reported", "1")
+return {request[1], request[2], 0}
+`)
+
+func (s *RedisStore) Claim(ctx context.Context, caller, requestID string) (Variant, error) {
+	result, err := claimScript.Run(ctx, s.client, []string{requestRedisKey(caller, requestID)}).Slice()
+	if err == redis.Nil {
+		return Variant{}, ErrUnknownRequest
+	}
+	if err != nil {
+		return Variant{}, fmt.Errorf("failed to claim request %s: %w", requestID, err)
+	}
+	if len(result) != 3 {
+		return Variant{}, fmt.Errorf("failed to claim request %s: unexpected result %v", requestID, result)
+	}
+	model, _ := result[0].(string)
+	modelServer, _ := result[1].(string)
+	if reported, _ := result[2].(int64); reported == 1 {
+		return Variant{}, ErrAlreadyReported
+	}
+	return Variant{Model: model, ModelServer: modelServer}, nil
+}
+
+func (s *RedisStore) Release(ctx context.Context, caller, requestID string) error {
+	if err := s.client.HDel(ctx, requestRedisKey(caller, requestID), fieldReported).Err(); err != nil {
+		return fmt.Errorf("failed to release request %s: %w", requestID, err)
+	}
+	return nil
+}
+
+func (s *RedisStore) Add(ctx context.Context, variant Variant, f *Feedback) error {
+	pipe := s.client.TxPipeline()
+	switch f.Rating {
+	case RatingPositive:
+		pipe.HIncrBy(ctx, statsKey, statsField(variant, counterPositive), 1)
+	case RatingNegative:
+		pipe.HIncrBy(ctx, statsKey, statsField(variant, counterNegative), 1)
+	}
+	if f.Score != nil {
+		pipe.HIncrBy(ctx, statsKey, statsField(variant, counterScoreCount), 1)
+		pipe.HIncrByFloat(ctx, statsKey, statsField(variant, counterScoreSum), *f.Score)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record feedback of %s/%s: %w", variant.Model, variant.ModelServer, err)
+	}
+	return nil
+}
+
+func (s *RedisStore) AddEvaluation(ctx context.Context, variant Variant, score float64) error {
+	pipe := s.client.TxPipeline()
+	pipe.HIncrBy(ctx, statsKey, statsField(variant, counterEvaluationCount), 1)
+	pipe.HIncrByFloat(ctx, statsKey, statsField(variant, counterEvaluationSum), score)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record evaluation of %s/%s: %w", variant.Model, variant.ModelServer, err)
+	}
+	return nil
+}
+
+func (s *RedisStore) Stats(ctx context.Context) ([]VariantStats, error) {
+	fields, err := s.client.HGetAll(ctx, statsKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback stats: %w", err)
+	}
+	variants := make(map[Variant]*VariantStats)
+	for field, value := range fields {
+		parts := strings.Split(field, "\x00")
+		if len(parts) != 3 {
+			continue
+		}
+		variant := Variant{Model: parts[0], ModelServer: parts[1]}
+		stats, ok := variants[variant]
+		if !ok {
+			stats = &VariantStats{Model: variant.Model, ModelServer: variant.ModelServer}
+			variants[variant] = stats
+		}
+		count, _ := strconv.ParseInt(value, 10, 64)
+		sum, _ := strconv.ParseFloat(value, 64)
+		switch parts[2] {
+		case counterPositive:
+			stats.Positive = count
+		case counterNegative:
+			stats.Negative = count
+		case counterScoreCount:
+			stats.ScoreCount = count
+		case counterScoreSum:
+			stats.scoreSum = sum
+		case counterEvaluationCount:
+			stats.EvaluationCount = count
+		case counterEvaluationSum:
+			stats.evaluationSum = sum
+		}
+	}
+
+	result := make([]VariantStats, 0, len(variants))
+	for _, stats := range variants {
+		if stats.ScoreCount > 0 {
+			stats.ScoreAverage = stats.scoreSum / float64(stats.ScoreCount)
+		}
+		if stats.EvaluationCount > 0 {
+			stats.EvaluationAverage = stats.evaluationSum / float64(stats.EvaluationCount)
+		}
+		result = append(result, *stats)
+	}
+	return result, nil
+}
+
+// sortStats orders the stats by model and model server.
+func sortStats(stats []VariantStats) {
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Model != stats[j].Model {
+			return stats[i].Model < stats[j].Model
+		}
+		return stats[i].ModelServer < stats[j].ModelServer
+	})
+}
@@ -0,0 +1,188 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package feedback collects client reported quality feedback on inference results and aggregates it
+// per model and variant (the model server that served the request), so that experiments such as
+// canary or A/B rollouts can be promoted based on result quality.
+package feedback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+)
+
+const (
+	RatingPositive = "positive"
+	RatingNegative = "negative"
+
+	// DefaultMaxRequests is the number of recent requests the memory store accepts feedback for.
+	DefaultMaxRequests = 100000
+	// DefaultRequestTTL is how long after completion feedback can be reported for a request.
+	DefaultRequestTTL = time.Hour
+
+	// storeTimeout bounds the calls to the store made on the completion of the requests.
+	storeTimeout = time.Second
+)
+
+var (
+	ErrUnknownRequest  = errors.New("request not found or feedback window expired")
+	ErrAlreadyReported = errors.New("feedback already reported for this request")
+)
+
+// Feedback is the quality report of a client for one inference request.
+type Feedback struct {
+	RequestID string `json:"request_id"`
+	// Rating is "positive" or "negative" (thumbs-up / thumbs-down), optional if Score is set.
+	Rating string `json:"rating,omitempty"`
+	// Score is a normalized quality score between 0 and 1, optional if Rating is set.
+	Score *float64 `json:"score,omitempty"`
+}
+
+// Validate checks that the feedback carries a request id and a valid rating or score.
+func (f *Feedback) Validate() error {
+	if f.RequestID == "" {
+		return fmt.Errorf("request_id is required")
+	}
+	if f.Rating == "" && f.Score == nil {
+		return fmt.Errorf("either rating or score is required")
+	}
+	if f.Rating != "" && f.Rating != RatingPositive && f.Rating != RatingNegative {
+		return fmt.Errorf("rating must be %q or %q, got %q", RatingPositive, RatingNegative, f.Rating)
+	}
+	if f.Score != nil && (math.IsNaN(*f.Score) || *f.Score < 0 || *f.Score > 1) {
+		return fmt.Errorf("score must be between 0 and 1, got %v", *f.Score)
+	}
+	return nil
+}
+
+// VariantStats is the feedback aggregated for a model served by one model server.
+type VariantStats struct {
+	Model       string `json:"model"`
+	ModelServer string `json:"model_server"`
+	Positive    int64  `json:"positive"`
+	Negative    int64  `json:"negative"`
+	// ScoreCount is the number of scores reported and ScoreAverage their mean.
+	ScoreCount   int64   `json:"score_count"`
+	ScoreAverage float64 `json:"score_average"`
+	// EvaluationCount is the number of responses scored by the evaluation sinks of the ModelRoutes and
+	// EvaluationAverage their mean score.
+	EvaluationCount   int64   `json:"evaluation_count"`
+	EvaluationAverage float64 `json:"evaluation_average"`
+
+	scoreSum      float64
+	evaluationSum float64
+}
+
+// ApprovalRate returns the share of positive ratings, or 0 if no rating was reported.
+func (s *VariantStats) ApprovalRate() float64 {
+	if s.Positive+s.Negative == 0 {
+		return 0
+	}
+	return float64(s.Positive) / float64(s.Positive+s.Negative)
+}
+
+// Tracker records the variant that served recent requests and aggregates the feedback reported on them in a Store.
+// It implements accesslog.AccessLogger so that it is fed with every completed request.
+type Tracker struct {
+	store Store
+}
+
+var _ accesslog.AccessLogger = &Tracker{}
+
+// NewTracker creates a tracker keeping the requests and the feedback in the store.
+func NewTracker(store Store) *Tracker {
+	return &Tracker{store: store}
+}
+
+// Log records the variant that served a successful inference request, and the caller which sent it.
+func (t *Tracker) Log(entry *accesslog.AccessLogEntry) error {
+	if entry == nil || entry.RequestID == "" || entry.ModelName == "" || entry.Error != nil || entry.StatusCode >= 400 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	return t.store.Track(ctx, entry.Consumer, entry.RequestID, Variant{Model: entry.ModelName, ModelServer: entry.ModelServer})
+}
+
+func (t *Tracker) Close() error {
+	return nil
+}
+
+// Submit aggregates the feedback of the caller into the stats of the variant which served the request. Only the
+// caller which sent the request can report feedback on it, and only once; the requests of the other callers are
+// unknown to it.
+func (t *Tracker) Submit(ctx context.Context, caller string, f *Feedback) error {
+	if err := f.Validate(); err != nil {
+		return err
+	}
+
+	variant, err := t.store.Claim(ctx, caller, f.RequestID)
+	if err != nil {
+		return err
+	}
+	if err := t.store.Add(ctx, variant, f); err != nil {
+		// The feedback wasn't counted, the request is released so that the caller can retry. The context of the
+		// request may be the cause of the failure, the release doesn't depend on it.
+		releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), storeTimeout)
+		defer cancel()
+		if releaseErr := t.store.Release(releaseCtx, caller, f.RequestID); releaseErr != nil {
+			klog.Errorf("Failed to release request %s after its feedback wasn't added: %v", f.RequestID, releaseErr)
+		}
+		return err
+	}
+	if f.Rating != "" {
+		metrics.DefaultMetrics.FeedbackTotal.WithLabelValues(variant.Model, variant.ModelServer, f.Rating).Inc()
+	}
+	if f.Score != nil {
+		metrics.DefaultMetrics.FeedbackScore.WithLabelValues(variant.Model, variant.ModelServer).Observe(*f.Score)
+	}
+	return nil
+}
+
+// Evaluate aggregates the score between 0 and 1 an evaluation sink gave to a response of the model served by the
+// model server. Unlike the feedback, the evaluations don't need the request to be tracked.
+func (t *Tracker) Evaluate(model, modelServer string, score float64) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := t.store.AddEvaluation(ctx, Variant{Model: model, ModelServer: modelServer}, score); err != nil {
+		klog.Errorf("Failed to record the evaluation of %s: %v", model, err)
+	}
+}
+
+// Stats returns the feedback aggregated per variant, restricted to the given model if it is not empty.
+func (t *Tracker) Stats(ctx context.Context, model string) ([]VariantStats, error) {
+	stats, err := t.store.Stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]VariantStats, 0, len(stats))
+	for _, s := range stats {
+		if model != "" && s.Model != model {
+			continue
+		}
+		result = append(result, s)
+	}
+	sortStats(result)
+	return result, nil
+}
@@ -0,0 +1,298 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feedback
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+)
+
+func served(requestID, model, modelServer string) *accesslog.AccessLogEntry {
+	return &accesslog.AccessLogEntry{RequestID: requestID, ModelName: model, ModelServer: modelServer, StatusCode: http.StatusOK, Consumer: "alice"}
+}
+
+func score(v float64) *float64 {
+	return &v
+}
+
+func TestFeedback_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		feedback    Feedback
+		expectError bool
+	}{
+		{name: "rating", feedback: Feedback{RequestID: "req", Rating: RatingPositive}},
+		{name: "score", feedback: Feedback{RequestID: "req", Score: score(0.5)}},
+		{name: "rating and score", feedback: Feedback{RequestID: "req", Rating: RatingNegative, Score: score(0)}},
+		{name: "missing request id", feedback: Feedback{Rating: RatingPositive}, expectError: true},
+		{name: "missing rating and score", feedback: Feedback{RequestID: "req"}, expectError: true},
+		{name: "invalid rating", feedback: Feedback{RequestID: "req", Rating: "meh"}, expectError: true},
+		{name: "score out of range", feedback: Feedback{RequestID: "req", Score: score(5)}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.feedback.Validate()
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// stores returns a tracker on each store, the Redis store being shared by two trackers as by two router replicas.
+func stores(t *testing.T) map[string][2]*Tracker {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	memory := NewTracker(NewMemoryStore(10, time.Hour))
+	return map[string][2]*Tracker{
+		"memory": {memory, memory},
+		"redis":  {NewTracker(NewRedisStore(client, time.Hour)), NewTracker(NewRedisStore(client, time.Hour))},
+	}
+}
+
+func TestTracker_Submit(t *testing.T) {
+	for name, trackers := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			tracker, replica := trackers[0], trackers[1]
+			require.NoError(t, tracker.Log(served("req-1", "llama", "default/llama-v1")))
+			require.NoError(t, tracker.Log(served("req-2", "llama", "default/llama-v2")))
+			require.NoError(t, tracker.Log(served("req-3", "llama", "default/llama-v2")))
+			// Failed requests can't receive feedback
+			require.NoError(t, tracker.Log(&accesslog.AccessLogEntry{RequestID: "req-4", ModelName: "llama", StatusCode: http.StatusTooManyRequests}))
+
+			assert.NoError(t, tracker.Submit(ctx, "alice", &Feedback{RequestID: "req-1", Rating: RatingNegative}))
+			assert.NoError(t, replica.Submit(ctx, "alice", &Feedback{RequestID: "req-2", Rating: RatingPositive, Score: score(0.8)}))
+			assert.NoError(t, tracker.Submit(ctx, "alice", &Feedback{RequestID: "req-3", Score: score(0.4)}))
+			assert.ErrorIs(t, replica.Submit(ctx, "alice", &Feedback{RequestID: "req-3", Rating: RatingPositive}), ErrAlreadyReported)
+			assert.ErrorIs(t, tracker.Submit(ctx, "alice", &Feedback{RequestID: "req-4", Rating: RatingPositive}), ErrUnknownRequest)
+
+			stats, err := replica.Stats(ctx, "llama")
+			require.NoError(t, err)
+			require.Len(t, stats, 2)
+			assert.Equal(t, "default/llama-v1", stats[0].ModelServer)
+			assert.Equal(t, int64(1), stats[0].Negative)
+			assert.Equal(t, 0.0, stats[0].ApprovalRate())
+			assert.Equal(t, "default/llama-v2", stats[1].ModelServer)
+			assert.Equal(t, int64(1), stats[1].Positive)
+			assert.Equal(t, int64(2), stats[1].ScoreCount)
+			assert.InDelta(t, 0.6, stats[1].ScoreAverage, 1e-9)
+			assert.Equal(t, 1.0, stats[1].ApprovalRate())
+
+			stats, err = tracker.Stats(ctx, "qwen")
+			require.NoError(t, err)
+			assert.Empty(t, stats)
+		})
+	}
+}
+
+func TestTracker_Submit_Caller(t *testing.T) {
+	for name, trackers := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			tracker := trackers[0]
+			require.NoError(t, tracker.Log(served("req-1", "llama", "default/llama")))
+			require.NoError(t, tracker.Log(served("req-2", "llama", "default/llama")))
+			require.NoError(t, tracker.Log(served("req-3", "llama", "default/llama")))
+
+			// The requests of another caller are unknown
+			assert.ErrorIs(t, tracker.Submit(ctx, "mallory", &Feedback{RequestID: "req-1", Rating: RatingNegative}), ErrUnknownRequest)
+			assert.ErrorIs(t, tracker.Submit(ctx, "", &Feedback{RequestID: "req-1", Rating: RatingNegative}), ErrUnknownRequest)
+			// and their feedback is left to their caller
+			assert.NoError(t, tracker.Submit(ctx, "alice", &Feedback{RequestID: "req-1", Rating: RatingPositive}))
+
+			// The unauthenticated requests only receive feedback without a caller
+			require.NoError(t, tracker.Log(&accesslog.AccessLogEntry{RequestID: "req-4", ModelName: "llama", ModelServer: "default/llama", StatusCode: http.StatusOK}))
+			assert.ErrorIs(t, tracker.Submit(ctx, "alice", &Feedback{RequestID: "req-4", Rating: RatingPositive}), ErrUnknownRequest)
+			assert.NoError(t, tracker.Submit(ctx, "", &Feedback{RequestID: "req-4", Rating: RatingPositive}))
+
+			stats, err := tracker.Stats(ctx, "llama")
+			require.NoError(t, err)
+			require.Len(t, stats, 1)
+			assert.Equal(t, int64(2), stats[0].Positive)
+			assert.Equal(t, int64(0), stats[0].Negative)
+		})
+	}
+}
+
+// failingStore is a Store failing to add the feedback while it has failures left.
+type failingStore struct {
+	Store
+	failures int
+}
+
+func (s *failingStore) Add(ctx context.Context, variant Variant, f *Feedback) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("store unavailable")
+	}
+	return s.Store.Add(ctx, variant, f)
+}
+
+func TestTracker_Submit_AddFailure(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	for name, store := range map[string]Store{"memory": NewMemoryStore(10, time.Hour), "redis": NewRedisStore(client, time.Hour)} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			tracker := NewTracker(&failingStore{Store: store, failures: 1})
+			require.NoError(t, tracker.Log(served("req-1", "llama", "default/llama")))
+
+			// The feedback which wasn't counted can be reported again
+			err := tracker.Submit(ctx, "alice", &Feedback{RequestID: "req-1", Rating: RatingPositive})
+			require.Error(t, err)
+			assert.NotErrorIs(t, err, ErrAlreadyReported)
+			assert.NoError(t, tracker.Submit(ctx, "alice", &Feedback{RequestID: "req-1", Rating: RatingPositive}))
+			assert.ErrorIs(t, tracker.Submit(ctx, "alice", &Feedback{RequestID: "req-1", Rating: RatingPositive}), ErrAlreadyReported)
+
+			stats, err := tracker.Stats(ctx, "llama")
+			require.NoError(t, err)
+			require.Len(t, stats, 1)
+			assert.Equal(t, int64(1), stats[0].Positive)
+		})
+	}
+}
+
+func TestTracker_Evaluate(t *testing.T) {
+	for name, trackers := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			tracker, replica := trackers[0], trackers[1]
+			require.NoError(t, tracker.Log(served("req-1", "llama", "default/llama-v1")))
+			require.NoError(t, tracker.Submit(ctx, "alice", &Feedback{RequestID: "req-1", Score: score(0.2)}))
+
+			// The evaluations are aggregated apart from the scores of the clients, without tracking the requests.
+			tracker.Evaluate("llama", "default/llama-v1", 0.9)
+			replica.Evaluate("llama", "default/llama-v1", 0.7)
+			tracker.Evaluate("llama", "default/llama-v2", 0.5)
+
+			stats, err := replica.Stats(ctx, "llama")
+			require.NoError(t, err)
+			require.Len(t, stats, 2)
+			assert.Equal(t, int64(1), stats[0].ScoreCount)
+			assert.InDelta(t, 0.2, stats[0].ScoreAverage, 1e-9)
+			assert.Equal(t, int64(2), stats[0].EvaluationCount)
+			assert.InDelta(t, 0.8, stats[0].EvaluationAverage, 1e-9)
+			assert.Equal(t, "default/llama-v2", stats[1].ModelServer)
+			assert.Equal(t, int64(1), stats[1].EvaluationCount)
+			assert.InDelta(t, 0.5, stats[1].EvaluationAverage, 1e-9)
+		})
+	}
+}
+
+func TestMemoryStore_Eviction(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := NewMemoryStore(2, time.Minute)
+	store.now = func() time.Time { return now }
+	tracker := NewTracker(store)
+
+	require.NoError(t, tracker.Log(served("req-1", "llama", "default/llama")))
+	require.NoError(t, tracker.Log(served("req-2", "llama", "default/llama")))
+	require.NoError(t, tracker.Log(served("req-3", "llama", "default/llama")))
+	// Capacity exceeded, the oldest request is forgotten
+	assert.ErrorIs(t, tracker.Submit(ctx, "alice", &Feedback{RequestID: "req-1", Rating: RatingPositive}), ErrUnknownRequest)
+	assert.NoError(t, tracker.Submit(ctx, "alice", &Feedback{RequestID: "req-2", Rating: RatingPositive}))
+
+	now = now.Add(2 * time.Minute)
+	assert.ErrorIs(t, tracker.Submit(ctx, "alice", &Feedback{RequestID: "req-3", Rating: RatingPositive}), ErrUnknownRequest)
+}
+
+func TestRedisStore_Expiration(t *testing.T) {
+	ctx := context.Background()
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	tracker := NewTracker(NewRedisStore(client, time.Minute))
+
+	require.NoError(t, tracker.Log(served("req-1", "llama", "default/llama")))
+	mr.FastForward(2 * time.Minute)
+	assert.ErrorIs(t, tracker.Submit(ctx, "alice", &Feedback{RequestID: "req-1", Rating: RatingPositive}), ErrUnknownRequest)
+}
+
+func TestTracker_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracker := NewTracker(NewMemoryStore(10, time.Hour))
+	require.NoError(t, tracker.Log(served("req-1", "llama", "default/llama")))
+
+	tests := []struct {
+		name       string
+		method     string
+		caller     string
+		body       string
+		wantStatus int
+	}{
+		{name: "invalid body", method: http.MethodPost, body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "invalid rating", method: http.MethodPost, body: `{"request_id":"req-1","rating":"meh"}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown request", method: http.MethodPost, caller: "alice", body: `{"request_id":"req-2","rating":"positive"}`, wantStatus: http.StatusNotFound},
+		{name: "request of another caller", method: http.MethodPost, caller: "mallory", body: `{"request_id":"req-1","rating":"negative"}`, wantStatus: http.StatusNotFound},
+		{name: "accepted", method: http.MethodPost, caller: "alice", body: `{"request_id":"req-1","rating":"positive"}`, wantStatus: http.StatusOK},
+		{name: "duplicate", method: http.MethodPost, caller: "alice", body: `{"request_id":"req-1","rating":"positive"}`, wantStatus: http.StatusConflict},
+		{name: "stats", method: http.MethodGet, wantStatus: http.StatusOK},
+		{name: "method not allowed", method: http.MethodDelete, wantStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(tt.method, Path, bytes.NewBufferString(tt.body))
+			if tt.caller != "" {
+				c.Set(common.UserIdKey, tt.caller)
+			}
+			tracker.Handler()(c)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, Path+"?model=llama", nil)
+	tracker.Handler()(c)
+	var resp StatsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Variants, 1)
+	assert.Equal(t, int64(1), resp.Variants[0].Positive)
+}
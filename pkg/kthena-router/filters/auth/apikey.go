@@ -0,0 +1,87 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+// apiKeyHeader is the header of the API key of a request.
+const apiKeyHeader = "X-API-Key"
+
+// apiKeyAuthenticator authenticates the callers from the API key of the X-API-Key header. The keys are looked up by
+// their SHA-256 digest, the configuration doesn't hold them.
+type apiKeyAuthenticator struct {
+	subjects map[string]string
+	models   *modelAccess
+}
+
+func newAPIKeyAuthenticator(keys []conf.APIKeyConfig, models *modelAccess) (*apiKeyAuthenticator, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("the apiKey authenticator requires API keys")
+	}
+	authenticator := &apiKeyAuthenticator{subjects: make(map[string]string, len(keys)), models: models}
+	for _, key := range keys {
+		digest := strings.ToLower(key.KeySHA256)
+		if decoded, err := hex.DecodeString(digest); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("the API key of %q is not a hex encoded SHA-256 digest", key.Subject)
+		}
+		if key.Subject == "" {
+			return nil, fmt.Errorf("an API key has no subject")
+		}
+		if _, ok := authenticator.subjects[digest]; ok {
+			return nil, fmt.Errorf("duplicate API key of %q", key.Subject)
+		}
+		authenticator.subjects[digest] = key.Subject
+	}
+	return authenticator, nil
+}
+
+// APIKey returns the API key of a request, sent in the X-API-Key header or as the bearer token of the Authorization
+// header, or "" if it has none.
+func APIKey(req *http.Request) string {
+	if key := req.Header.Get(apiKeyHeader); key != "" {
+		return key
+	}
+	return extractTokenFromHeader(req)
+}
+
+func (a *apiKeyAuthenticator) Name() string {
+	return AuthenticatorAPIKey
+}
+
+func (a *apiKeyAuthenticator) AuthenticateRequest(c *gin.Context) (bool, error) {
+	key := c.Request.Header.Get(apiKeyHeader)
+	if key == "" {
+		return false, nil
+	}
+	digest := sha256.Sum256([]byte(key))
+	subject, ok := a.subjects[hex.EncodeToString(digest[:])]
+	if !ok {
+		return true, fmt.Errorf("invalid API key")
+	}
+	a.models.setCaller(c, subject, nil)
+	return true, nil
+}
@@ -14,8 +14,9 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// Package auth provides JWT authentication and authorization functionality for the Kthena router.
-// This package handles JWT token validation, JWKS rotation, and provides middleware for Gin HTTP framework.
+// Package auth provides authentication and authorization functionality for the Kthena router.
+// This package handles the chain of authenticators (API key, JWT, mTLS client certificate, anonymous), JWT token
+// validation and JWKS rotation, and provides middleware for Gin HTTP framework.
 package auth
 
 import (
@@ -31,7 +32,6 @@ import (
 	"github.com/lestrrat-go/jwx/v3/jwt"
 	"k8s.io/klog/v2"
 
-	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
 )
 
@@ -51,6 +51,7 @@ func extractTokenFromHeader(req *http.Request) string {
 type JWTAuthenticator struct {
 	enabled bool         // Whether JWT authentication is enabled
 	rotator *JWKSRotator // JWKS rotator for automatic key updates
+	models  *modelAccess // Models the authenticated callers may call
 }
 
 // NewJWTAuthenticator creates a new JWTAuthenticator with JWKS rotation support
@@ -69,6 +70,7 @@ func NewJWTAuthenticator(routerConfig *conf.RouterConfiguration) *JWTAuthenticat
 	return &JWTAuthenticator{
 		enabled: true,
 		rotator: rotator,
+		models:  newModelAccess(routerConfig.Auth),
 	}
 }
 
@@ -79,26 +81,31 @@ func (j *JWTAuthenticator) Close() {
 	}
 }
 
-// authenticate validates the token and returns the subject
-func (j *JWTAuthenticator) authenticate(tokenStr string) (string, error) {
+// authenticate validates the token and returns it parsed
+func (j *JWTAuthenticator) authenticate(tokenStr string) (jwt.Token, error) {
 	// Get current JWKS from rotator
 	jwksValue := j.rotator.GetJwks()
 	if jwksValue.Jwks == nil {
-		return "", fmt.Errorf("no JWKS available for token validation")
+		return nil, fmt.Errorf("no JWKS available for token validation")
 	}
 
 	token, err := jwt.Parse([]byte(tokenStr), jwt.WithKeySet(jwksValue.Jwks, jws.WithInferAlgorithmFromKey(true)))
 	if err != nil {
-		return "", fmt.Errorf("failed to parse jwt: %w", err)
+		return nil, fmt.Errorf("failed to parse jwt: %w", err)
 	}
 
 	// Validate the claims in the token
 	if err := j.validateClaims(token, jwksValue); err != nil {
-		return "", fmt.Errorf("failed to validate claims: %w", err)
+		return nil, fmt.Errorf("failed to validate claims: %w", err)
 	}
 
+	return token, nil
+}
+
+// setCaller records the subject of the token and the models it may call on the request
+func (j *JWTAuthenticator) setCaller(c *gin.Context, token jwt.Token) {
 	sub, _ := token.Subject()
-	return sub, nil
+	j.models.setCaller(c, sub, token)
 }
 
 func (j *JWTAuthenticator) validateClaims(token jwt.Token, jwks *Jwks) error {
@@ -293,12 +300,12 @@ func (j *JWTAuthenticator) ValidateToken(ctx context.Context, c *gin.Context, to
 		return fmt.Errorf("authorization header missing or empty")
 	}
 
-	sub, err := j.authenticate(token)
+	parsed, err := j.authenticate(token)
 	if err != nil {
 		return fmt.Errorf("authentication failed: %w", err)
 	}
 
-	c.Set(common.UserIdKey, sub)
+	j.setCaller(c, parsed)
 	return nil
 }
 
@@ -307,6 +314,25 @@ func (j *JWTAuthenticator) IsEnabled() bool {
 	return j.enabled
 }
 
+// Name returns the name of the JWT authenticator in the chain.
+func (j *JWTAuthenticator) Name() string {
+	return AuthenticatorJWT
+}
+
+// AuthenticateRequest authenticates the caller of the request from the Bearer token of its Authorization header.
+func (j *JWTAuthenticator) AuthenticateRequest(c *gin.Context) (bool, error) {
+	token := extractTokenFromHeader(c.Request)
+	if token == "" {
+		return false, nil
+	}
+	parsed, err := j.authenticate(token)
+	if err != nil {
+		return true, err
+	}
+	j.setCaller(c, parsed)
+	return true, nil
+}
+
 // Authenticate returns a Gin middleware for JWT token validation
 func (j *JWTAuthenticator) Authenticate() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -318,12 +344,12 @@ func (j *JWTAuthenticator) Authenticate() gin.HandlerFunc {
 				return
 			}
 
-			sub, err := j.authenticate(token)
+			parsed, err := j.authenticate(token)
 			if err != nil {
 				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": fmt.Sprintf("Unauthorized: %v", err)})
 				return
 			}
-			c.Set(common.UserIdKey, sub)
+			j.setCaller(c, parsed)
 		}
 		c.Next()
 	}
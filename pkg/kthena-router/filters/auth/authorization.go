@@ -17,8 +17,152 @@ limitations under the License.
 package auth
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/gin-gonic/gin"
+	"github.com/lestrrat-go/jwx/v3/jwt"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+const (
+	// allowedModelsKey is the context key of the model allowlists of the caller, unset if it may call any model.
+	allowedModelsKey = "allowed_models"
+	// routesKey is the context key of the authenticators required by the routes, unset if none are required.
+	routesKey = "auth_routes"
 )
 
-func Authorize(ctx *gin.Context) {
+// ModelNotAllowedError is returned for a request calling a model its caller may not call.
+type ModelNotAllowedError struct {
+	Subject string
+	Model   string
+}
+
+func (e *ModelNotAllowedError) Error() string {
+	if e.Subject == "" {
+		return fmt.Sprintf("the caller is not allowed to call the model %q", e.Model)
+	}
+	return fmt.Sprintf("%q is not allowed to call the model %q", e.Subject, e.Model)
+}
+
+// AuthenticationRequiredError is returned for a request calling a model whose route requires its callers to be
+// authenticated by other authenticators.
+type AuthenticationRequiredError struct {
+	Model          string
+	Authenticators []string
+}
+
+func (e *AuthenticationRequiredError) Error() string {
+	return fmt.Sprintf("the model %q requires the caller to be authenticated by one of %s", e.Model,
+		strings.Join(e.Authenticators, ", "))
+}
+
+// authRoute requires the callers of models to be authenticated by some of the authenticators.
+type authRoute struct {
+	models         []string
+	authenticators []string
+}
+
+// modelAccess declares the models the authenticated callers may call.
+type modelAccess struct {
+	claim     string
+	consumers map[string][]string
+}
+
+func newModelAccess(config conf.AuthenticationConfig) *modelAccess {
+	access := &modelAccess{claim: config.ModelsClaim, consumers: make(map[string][]string, len(config.Consumers))}
+	for _, consumer := range config.Consumers {
+		access.consumers[consumer.Subject] = append(access.consumers[consumer.Subject], consumer.Models...)
+	}
+	return access
+}
+
+// allowlists returns the model allowlists restricting the caller with the subject, and the token if it is
+// authenticated by a JWT, a model must be in all of them.
+func (m *modelAccess) allowlists(subject string, token jwt.Token) [][]string {
+	if m == nil {
+		return nil
+	}
+	var allowlists [][]string
+	if subject != "" {
+		if models, ok := m.consumers[subject]; ok {
+			allowlists = append(allowlists, models)
+		}
+	}
+	if m.claim != "" && token != nil {
+		var claim interface{}
+		if err := token.Get(m.claim, &claim); err == nil {
+			allowlists = append(allowlists, claimedModels(claim))
+		}
+	}
+	return allowlists
+}
+
+// claimedModels returns the models listed by the value of a claim, none if it isn't a list of names.
+func claimedModels(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return strings.Fields(value)
+	case []string:
+		return value
+	case []interface{}:
+		models := make([]string, 0, len(value))
+		for _, item := range value {
+			if model, ok := item.(string); ok {
+				models = append(models, model)
+			}
+		}
+		return models
+	}
+	return []string{}
+}
+
+// setCaller records the subject of the caller authenticated on the request and its model allowlists.
+func (m *modelAccess) setCaller(c *gin.Context, subject string, token jwt.Token) {
+	c.Set(common.UserIdKey, subject)
+	if allowlists := m.allowlists(subject, token); len(allowlists) > 0 {
+		c.Set(allowedModelsKey, allowlists)
+	}
+}
+
+// AuthorizeModel returns an *AuthenticationRequiredError if the route of the model requires the caller to be
+// authenticated by another authenticator, and a *ModelNotAllowedError if the caller may not call the model.
+func AuthorizeModel(c *gin.Context, model string) error {
+	if value, ok := c.Get(routesKey); ok {
+		for _, route := range value.([]authRoute) {
+			if !modelAllowed(route.models, model) {
+				continue
+			}
+			if !slices.Contains(route.authenticators, c.GetString(authenticatorKey)) {
+				return &AuthenticationRequiredError{Model: model, Authenticators: route.authenticators}
+			}
+			break
+		}
+	}
+	value, ok := c.Get(allowedModelsKey)
+	if !ok {
+		return nil
+	}
+	for _, allowlist := range value.([][]string) {
+		if !modelAllowed(allowlist, model) {
+			return &ModelNotAllowedError{Subject: c.GetString(common.UserIdKey), Model: model}
+		}
+	}
+	return nil
+}
+
+func modelAllowed(allowlist []string, model string) bool {
+	for _, allowed := range allowlist {
+		if prefix, ok := strings.CutSuffix(allowed, "*"); ok {
+			if strings.HasPrefix(model, prefix) {
+				return true
+			}
+		} else if allowed == model {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,113 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lestrrat-go/jwx/v3/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+func TestAuthorizeModel(t *testing.T) {
+	access := newModelAccess(conf.AuthenticationConfig{
+		ModelsClaim: "models",
+		Consumers: []conf.ConsumerConfig{
+			{Subject: "team-a", Models: []string{"llama-3-8b", "qwen-*"}},
+		},
+	})
+
+	tests := []struct {
+		name    string
+		subject string
+		claim   interface{}
+		allowed []string
+		denied  []string
+	}{
+		{
+			name:    "unrestricted caller",
+			subject: "team-b",
+			allowed: []string{"deepseek-r1", "llama-3-8b"},
+		},
+		{
+			name:    "consumer",
+			subject: "team-a",
+			allowed: []string{"llama-3-8b", "qwen-2.5-7b"},
+			denied:  []string{"deepseek-r1", "llama-3-70b"},
+		},
+		{
+			name:    "claim array",
+			subject: "team-b",
+			claim:   []interface{}{"deepseek-r1", 1},
+			allowed: []string{"deepseek-r1"},
+			denied:  []string{"llama-3-8b"},
+		},
+		{
+			name:    "claim string",
+			subject: "team-b",
+			claim:   "deepseek-r1 llama-*",
+			allowed: []string{"deepseek-r1", "llama-3-8b"},
+			denied:  []string{"qwen-2.5-7b"},
+		},
+		{
+			name:    "consumer and claim",
+			subject: "team-a",
+			claim:   []string{"qwen-2.5-7b", "deepseek-r1"},
+			allowed: []string{"qwen-2.5-7b"},
+			denied:  []string{"deepseek-r1", "llama-3-8b"},
+		},
+		{
+			name:    "invalid claim",
+			subject: "team-b",
+			claim:   true,
+			denied:  []string{"deepseek-r1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := jwt.New()
+			require.NoError(t, token.Set(jwt.SubjectKey, tt.subject))
+			if tt.claim != nil {
+				require.NoError(t, token.Set("models", tt.claim))
+			}
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			(&JWTAuthenticator{models: access}).setCaller(c, token)
+
+			for _, model := range tt.allowed {
+				assert.NoError(t, AuthorizeModel(c, model), model)
+			}
+			for _, model := range tt.denied {
+				err := AuthorizeModel(c, model)
+				var notAllowed *ModelNotAllowedError
+				require.ErrorAs(t, err, &notAllowed, model)
+				assert.Equal(t, tt.subject, notAllowed.Subject)
+				assert.Equal(t, model, notAllowed.Model)
+			}
+		})
+	}
+}
+
+func TestAuthorizeModelWithoutAuthentication(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	assert.NoError(t, AuthorizeModel(c, "deepseek-r1"))
+}
@@ -0,0 +1,165 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+// The names of the authenticators of the chain.
+const (
+	AuthenticatorAPIKey    = "apiKey"
+	AuthenticatorJWT       = "jwt"
+	AuthenticatorMTLS      = "mtls"
+	AuthenticatorAnonymous = "anonymous"
+)
+
+// authenticatorKey is the context key of the name of the authenticator which authenticated the caller.
+const authenticatorKey = "authenticator"
+
+// Authenticator authenticates the callers of the router from the credentials of their requests.
+type Authenticator interface {
+	// Name returns the name of the authenticator in the chain.
+	Name() string
+	// AuthenticateRequest authenticates the caller of the request from its credentials, recording it on the request.
+	// It returns false if the request has no credentials for the authenticator, and an error if they are invalid.
+	AuthenticateRequest(c *gin.Context) (bool, error)
+}
+
+// Chain authenticates the callers with the first authenticator finding its credentials on their requests, so that
+// different populations of clients, e.g. internal services with client certificates and external applications with
+// JWTs, can share the router.
+type Chain struct {
+	authenticators []Authenticator
+	routes         []authRoute
+	jwt            *JWTAuthenticator
+}
+
+// NewChain creates the chain of authenticators of the router configuration. The chain is empty, and the callers are
+// not authenticated, if it doesn't configure any.
+func NewChain(routerConfig *conf.RouterConfiguration) (*Chain, error) {
+	chain := &Chain{}
+	if routerConfig == nil {
+		return chain, nil
+	}
+	config := routerConfig.Auth
+	names := config.Authenticators
+	if len(names) == 0 && config.JwksUri != "" {
+		names = []string{AuthenticatorJWT}
+	}
+
+	models := newModelAccess(config)
+	for _, name := range names {
+		if slices.ContainsFunc(chain.authenticators, func(a Authenticator) bool { return a.Name() == name }) {
+			return nil, fmt.Errorf("duplicate authenticator %q", name)
+		}
+		var authenticator Authenticator
+		switch name {
+		case AuthenticatorAPIKey:
+			apiKeys, err := newAPIKeyAuthenticator(config.APIKeys, models)
+			if err != nil {
+				return nil, err
+			}
+			authenticator = apiKeys
+		case AuthenticatorJWT:
+			if config.JwksUri == "" {
+				return nil, fmt.Errorf("the jwt authenticator requires a JWKS URI")
+			}
+			chain.jwt = NewJWTAuthenticator(routerConfig)
+			authenticator = chain.jwt
+		case AuthenticatorMTLS:
+			authenticator = &mtlsAuthenticator{models: models}
+		case AuthenticatorAnonymous:
+			authenticator = anonymousAuthenticator{}
+		default:
+			return nil, fmt.Errorf("unknown authenticator %q", name)
+		}
+		chain.authenticators = append(chain.authenticators, authenticator)
+	}
+
+	for i, route := range config.Routes {
+		if len(route.Models) == 0 || len(route.Authenticators) == 0 {
+			return nil, fmt.Errorf("route %d must have models and authenticators", i)
+		}
+		for _, name := range route.Authenticators {
+			if !slices.Contains(names, name) {
+				return nil, fmt.Errorf("authenticator %q of route %d is not in the chain", name, i)
+			}
+		}
+		chain.routes = append(chain.routes, authRoute{models: route.Models, authenticators: route.Authenticators})
+	}
+	return chain, nil
+}
+
+// Close gracefully closes the authenticators of the chain.
+func (ch *Chain) Close() {
+	if ch.jwt != nil {
+		ch.jwt.Close()
+	}
+}
+
+// Authenticate returns a Gin middleware authenticating the callers with the chain. The requests without credentials
+// for any authenticator are rejected, unless the chain ends with the anonymous authenticator.
+func (ch *Chain) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(ch.routes) > 0 {
+			c.Set(routesKey, ch.routes)
+		}
+		if len(ch.authenticators) == 0 {
+			c.Next()
+			return
+		}
+		for _, authenticator := range ch.authenticators {
+			found, err := authenticator.AuthenticateRequest(c)
+			if !found {
+				continue
+			}
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": fmt.Sprintf("Unauthorized: %v", err)})
+				return
+			}
+			c.Set(authenticatorKey, authenticator.Name())
+			c.Next()
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authentication credentials missing"})
+	}
+}
+
+// AuthenticatorOf returns the name of the authenticator which authenticated the caller of the request, empty if the
+// callers are not authenticated.
+func AuthenticatorOf(c *gin.Context) string {
+	return c.GetString(authenticatorKey)
+}
+
+// anonymousAuthenticator authenticates all the callers, without a subject. It ends the chain of a router letting the
+// callers without credentials in.
+type anonymousAuthenticator struct{}
+
+func (anonymousAuthenticator) Name() string {
+	return AuthenticatorAnonymous
+}
+
+func (anonymousAuthenticator) AuthenticateRequest(*gin.Context) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,259 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+func keySHA256(key string) string {
+	digest := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(digest[:])
+}
+
+func clientCertificate(commonName, uri string) *tls.ConnectionState {
+	certificate := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+	if uri != "" {
+		parsed, _ := url.Parse(uri)
+		certificate.URIs = []*url.URL{parsed}
+	}
+	return &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{certificate},
+		VerifiedChains:   [][]*x509.Certificate{{certificate}},
+	}
+}
+
+func TestNewChain(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   conf.AuthenticationConfig
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name: "no authentication",
+		},
+		{
+			name:     "jwt by default",
+			config:   conf.AuthenticationConfig{JwksUri: "invalid-url"},
+			expected: []string{AuthenticatorJWT},
+		},
+		{
+			name: "chain",
+			config: conf.AuthenticationConfig{
+				Authenticators: []string{AuthenticatorMTLS, AuthenticatorAPIKey, AuthenticatorAnonymous},
+				APIKeys:        []conf.APIKeyConfig{{Subject: "app", KeySHA256: keySHA256("secret")}},
+				Routes:         []conf.AuthRouteConfig{{Models: []string{"internal-*"}, Authenticators: []string{AuthenticatorMTLS}}},
+			},
+			expected: []string{AuthenticatorMTLS, AuthenticatorAPIKey, AuthenticatorAnonymous},
+		},
+		{
+			name:    "unknown authenticator",
+			config:  conf.AuthenticationConfig{Authenticators: []string{"oauth"}},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate authenticator",
+			config:  conf.AuthenticationConfig{Authenticators: []string{AuthenticatorMTLS, AuthenticatorMTLS}},
+			wantErr: true,
+		},
+		{
+			name:    "jwt without JWKS URI",
+			config:  conf.AuthenticationConfig{Authenticators: []string{AuthenticatorJWT}},
+			wantErr: true,
+		},
+		{
+			name:    "apiKey without keys",
+			config:  conf.AuthenticationConfig{Authenticators: []string{AuthenticatorAPIKey}},
+			wantErr: true,
+		},
+		{
+			name: "invalid key digest",
+			config: conf.AuthenticationConfig{
+				Authenticators: []string{AuthenticatorAPIKey},
+				APIKeys:        []conf.APIKeyConfig{{Subject: "app", KeySHA256: "secret"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "route authenticator not in the chain",
+			config: conf.AuthenticationConfig{
+				Authenticators: []string{AuthenticatorMTLS},
+				Routes:         []conf.AuthRouteConfig{{Models: []string{"*"}, Authenticators: []string{AuthenticatorAnonymous}}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, err := NewChain(&conf.RouterConfiguration{Auth: tt.config})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer chain.Close()
+			var names []string
+			for _, authenticator := range chain.authenticators {
+				names = append(names, authenticator.Name())
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestChainAuthenticate(t *testing.T) {
+	chain, err := NewChain(&conf.RouterConfiguration{Auth: conf.AuthenticationConfig{
+		Authenticators: []string{AuthenticatorAPIKey, AuthenticatorMTLS, AuthenticatorAnonymous},
+		APIKeys:        []conf.APIKeyConfig{{Subject: "app", KeySHA256: keySHA256("secret")}},
+		Consumers:      []conf.ConsumerConfig{{Subject: "app", Models: []string{"llama-*"}}},
+		Routes: []conf.AuthRouteConfig{
+			{Models: []string{"internal-*"}, Authenticators: []string{AuthenticatorMTLS}},
+			{Models: []string{"*"}, Authenticators: []string{AuthenticatorAPIKey, AuthenticatorMTLS}},
+		},
+	}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		apiKey        string
+		tls           *tls.ConnectionState
+		status        int
+		authenticator string
+		subject       string
+		allowed       []string
+		required      []string
+		denied        []string
+	}{
+		{
+			name:          "api key",
+			apiKey:        "secret",
+			status:        http.StatusOK,
+			authenticator: AuthenticatorAPIKey,
+			subject:       "app",
+			allowed:       []string{"llama-3-8b"},
+			required:      []string{"internal-llama"},
+			denied:        []string{"qwen-2.5-7b"},
+		},
+		{
+			name:   "invalid api key",
+			apiKey: "guess",
+			tls:    clientCertificate("search", ""),
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:          "client certificate common name",
+			tls:           clientCertificate("search", ""),
+			status:        http.StatusOK,
+			authenticator: AuthenticatorMTLS,
+			subject:       "search",
+			allowed:       []string{"internal-llama", "qwen-2.5-7b"},
+		},
+		{
+			name:          "client certificate URI",
+			tls:           clientCertificate("search", "spiffe://cluster.local/ns/default/sa/search"),
+			status:        http.StatusOK,
+			authenticator: AuthenticatorMTLS,
+			subject:       "spiffe://cluster.local/ns/default/sa/search",
+		},
+		{
+			name:          "unverified client certificate",
+			tls:           &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{}}},
+			status:        http.StatusOK,
+			authenticator: AuthenticatorAnonymous,
+			required:      []string{"internal-llama", "qwen-2.5-7b"},
+		},
+		{
+			name:          "anonymous",
+			status:        http.StatusOK,
+			authenticator: AuthenticatorAnonymous,
+			required:      []string{"llama-3-8b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/v1/completions", nil)
+			c.Request.TLS = tt.tls
+			if tt.apiKey != "" {
+				c.Request.Header.Set(apiKeyHeader, tt.apiKey)
+			}
+
+			chain.Authenticate()(c)
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status != http.StatusOK {
+				assert.True(t, c.IsAborted())
+				return
+			}
+			assert.Equal(t, tt.authenticator, c.GetString(authenticatorKey))
+			assert.Equal(t, tt.subject, c.GetString(common.UserIdKey))
+			for _, model := range tt.allowed {
+				assert.NoError(t, AuthorizeModel(c, model), model)
+			}
+			for _, model := range tt.required {
+				var required *AuthenticationRequiredError
+				assert.ErrorAs(t, AuthorizeModel(c, model), &required, model)
+			}
+			for _, model := range tt.denied {
+				var notAllowed *ModelNotAllowedError
+				assert.ErrorAs(t, AuthorizeModel(c, model), &notAllowed, model)
+			}
+		})
+	}
+}
+
+func TestChainAuthenticateWithoutCredentials(t *testing.T) {
+	chain, err := NewChain(&conf.RouterConfiguration{Auth: conf.AuthenticationConfig{
+		Authenticators: []string{AuthenticatorMTLS},
+	}})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/v1/completions", nil)
+	chain.Authenticate()(c)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.True(t, c.IsAborted())
+
+	// The callers are not authenticated by an empty chain.
+	chain, err = NewChain(nil)
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/v1/completions", nil)
+	chain.Authenticate()(c)
+	assert.False(t, c.IsAborted())
+	assert.NoError(t, AuthorizeModel(c, "llama-3-8b"))
+}
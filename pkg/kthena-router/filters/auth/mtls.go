@@ -0,0 +1,51 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mtlsAuthenticator authenticates the callers from the client certificates verified by the TLS listener of the
+// router, see the --tls-client-ca-file flag. The subject of a caller is the first URI SAN of its certificate, e.g. a
+// SPIFFE ID, or its common name.
+type mtlsAuthenticator struct {
+	models *modelAccess
+}
+
+func (a *mtlsAuthenticator) Name() string {
+	return AuthenticatorMTLS
+}
+
+func (a *mtlsAuthenticator) AuthenticateRequest(c *gin.Context) (bool, error) {
+	state := c.Request.TLS
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.PeerCertificates) == 0 {
+		return false, nil
+	}
+	certificate := state.PeerCertificates[0]
+	subject := certificate.Subject.CommonName
+	if len(certificate.URIs) > 0 {
+		subject = certificate.URIs[0].String()
+	}
+	if subject == "" {
+		return true, fmt.Errorf("the client certificate has no subject")
+	}
+	a.models.setCaller(c, subject, nil)
+	return true, nil
+}
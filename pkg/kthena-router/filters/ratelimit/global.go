@@ -82,8 +82,8 @@ type GlobalRateLimiter struct {
 	burst     int
 }
 
-// NewGlobalRateLimiter creates a new GlobalRateLimiter instance
-func NewGlobalRateLimiter(client *redis.Client, keyPrefix, modelName, tokenType string, limit uint32, unit networkingv1alpha1.RateLimitUnit) *GlobalRateLimiter {
+// NewGlobalRateLimiter creates a new GlobalRateLimiter instance, its bucket holds the tokens of burst units of time
+func NewGlobalRateLimiter(client *redis.Client, keyPrefix, modelName, tokenType string, limit uint32, unit networkingv1alpha1.RateLimitUnit, burst uint32) *GlobalRateLimiter {
 	return &GlobalRateLimiter{
 		client:    client,
 		keyPrefix: keyPrefix,
@@ -91,7 +91,7 @@ func NewGlobalRateLimiter(client *redis.Client, keyPrefix, modelName, tokenType
 		tokenType: tokenType,
 		limit:     limit,
 		unit:      unit,
-		burst:     int(limit),
+		burst:     int(limit) * int(burst),
 	}
 }
 
@@ -176,6 +176,54 @@ func (g *GlobalRateLimiter) AllowN(now time.Time, n int) bool {
 	return allowed == 1
 }
 
+// Burst implements Limiter interface, returning the capacity of the token bucket
+func (g *GlobalRateLimiter) Burst() int {
+	return g.burst
+}
+
+// SettleN implements Limiter interface, consuming n tokens even if they are not available or returning -n tokens
+func (g *GlobalRateLimiter) SettleN(now time.Time, n int) {
+	key := fmt.Sprintf("%s:%s:%s", g.keyPrefix, g.modelName, g.tokenType)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Lua script to settle tokens without a check
+	// Unlike AllowN, the tokens may go negative: the tokens generated by the requests already admitted are
+	// consumed whether available or not, and the following requests are rejected until the debt is refilled.
+	// Tokens reserved but not used are returned, up to the capacity of the bucket.
+	luaScript := `
+		-- Input parameters
+		local key = KEYS[1]                           -- Redis key name for the token bucket
+		local settled_tokens = tonumber(ARGV[1])      -- Number of tokens to consume, negative to return tokens
+		local capacity = tonumber(ARGV[2])            -- Maximum capacity of the token bucket
+		local refill_rate = tonumber(ARGV[3])         -- Token refill rate (tokens per second)
+		local expire_seconds = tonumber(ARGV[4])      -- Expiration time for Redis key (seconds)
+
+		-- Get current time from Redis for consistency across distributed systems
+		local time_result = redis.call('time')
+		local current_time = tonumber(time_result[1]) + tonumber(time_result[2]) / 1000000
+
+		-- Get current token bucket state, refilled up to now
+		local current_tokens = tonumber(redis.call('hget', key, 'tokens')) or capacity
+		local last_update = tonumber(redis.call('hget', key, 'last_update')) or current_time
+		local time_passed = math.max(0, current_time - last_update)
+		current_tokens = math.min(capacity, current_tokens + time_passed * refill_rate)
+
+		-- Settle the tokens, possibly going into debt
+		current_tokens = math.min(capacity, current_tokens - settled_tokens)
+
+		redis.call('hset', key, 'tokens', current_tokens, 'last_update', current_time)
+		redis.call('expire', key, expire_seconds)
+		return 1
+	`
+
+	result := g.client.Eval(ctx, luaScript, []string{key}, n, g.burst, g.getRefillRate(), g.getExpireSeconds())
+	if result.Err() != nil {
+		klog.Errorf("failed to execute token settlement lua script: %v", result.Err())
+	}
+}
+
 // getRefillRate calculates the token refill rate per second
 func (g *GlobalRateLimiter) getRefillRate() float64 {
 	duration := getTimeUnitDuration(g.unit)
@@ -192,7 +240,7 @@ func (g *GlobalRateLimiter) getRefillRate() float64 {
 // 3. Resource management: Ensures Redis memory usage stays bounded in production
 //
 // Expiration time calculation principle:
-// - Base duration: 3x the rate limit time unit (e.g., if unit is "minute", expire in 3 minutes)
+// - Base duration: 3x the time to refill the bucket, one rate limit time unit without a burst (e.g., if unit is "minute", expire in 3 minutes)
 // - Rationale: Allows sufficient time for token bucket refill cycles while ensuring cleanup
 //
 // Why 3x multiplier:
@@ -207,7 +255,10 @@ func (g *GlobalRateLimiter) getRefillRate() float64 {
 // - These bounds ensure reasonable Redis memory management regardless of configuration
 func (g *GlobalRateLimiter) getExpireSeconds() int {
 	duration := getTimeUnitDuration(g.unit)
-	// Set expire time to 3x the rate limit unit duration, with reasonable bounds
+	if g.limit > 0 && g.burst > int(g.limit) {
+		duration = duration * time.Duration(g.burst) / time.Duration(g.limit)
+	}
+	// Set expire time to 3x the refill duration, with reasonable bounds
 	expireSeconds := int(duration.Seconds() * 3)
 
 	// Set reasonable bounds
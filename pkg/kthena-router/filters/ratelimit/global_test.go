@@ -220,7 +220,7 @@ func newTestGlobalRateLimiter(t *testing.T, mr *miniredis.Miniredis, modelName,
 		Addr: mr.Addr(),
 	})
 	t.Cleanup(func() { client.Close() })
-	return NewGlobalRateLimiter(client, "kthena:ratelimit", modelName, tokenType, limit, unit)
+	return NewGlobalRateLimiter(client, "kthena:ratelimit", modelName, tokenType, limit, unit, 1)
 }
 
 func TestGlobalRateLimiter_Tokens_InitialCapacity(t *testing.T) {
@@ -350,7 +350,7 @@ func TestGlobalRateLimiter_Tokens_ConnectionFailure(t *testing.T) {
 	})
 	defer client.Close()
 
-	limiter := NewGlobalRateLimiter(client, "kthena:ratelimit", "model", "input", 10, networkingv1alpha1.Second)
+	limiter := NewGlobalRateLimiter(client, "kthena:ratelimit", "model", "input", 10, networkingv1alpha1.Second, 1)
 
 	// Tokens() should return 0 on connection failure
 	tokens := limiter.Tokens()
@@ -533,6 +533,22 @@ func TestGlobalRateLimiter_GetExpireSeconds(t *testing.T) {
 	}
 }
 
+func TestGlobalRateLimiter_Burst(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	limiter := NewGlobalRateLimiter(client, "kthena:ratelimit", "model", "input", 10, networkingv1alpha1.Minute, 30)
+
+	assert.Equal(t, 300, limiter.Burst())
+	assert.True(t, limiter.AllowN(time.Now(), 250), "the bucket holds the tokens of 30 minutes")
+	assert.False(t, limiter.AllowN(time.Now(), 100))
+	// The bucket outlives the time to refill it
+	assert.Equal(t, 5400, limiter.getExpireSeconds())
+}
+
 func TestGlobalRateLimiter_TokensAndAllowN_ConsistentArgs(t *testing.T) {
 	mr, err := miniredis.Run()
 	require.NoError(t, err)
@@ -582,7 +598,7 @@ func TestGlobalRateLimiter_NewGlobalRateLimiter(t *testing.T) {
 	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
 	defer client.Close()
 
-	limiter := NewGlobalRateLimiter(client, "prefix", "model", "input", 100, networkingv1alpha1.Second)
+	limiter := NewGlobalRateLimiter(client, "prefix", "model", "input", 100, networkingv1alpha1.Second, 1)
 
 	assert.NotNil(t, limiter)
 	assert.Equal(t, "prefix", limiter.keyPrefix)
@@ -615,7 +631,7 @@ func TestGlobalRateLimiter_AllowN_ConnectionFailure(t *testing.T) {
 	})
 	defer client.Close()
 
-	limiter := NewGlobalRateLimiter(client, "kthena:ratelimit", "model", "input", 10, networkingv1alpha1.Second)
+	limiter := NewGlobalRateLimiter(client, "kthena:ratelimit", "model", "input", 10, networkingv1alpha1.Second, 1)
 
 	// AllowN should return false on connection failure
 	allowed := limiter.AllowN(time.Now(), 1)
@@ -661,3 +677,22 @@ func TestGlobalRateLimiter_Tokens_AllModelsIsolated(t *testing.T) {
 		assert.True(t, mr.Exists(key), "Redis key should exist for %s", m.name)
 	}
 }
+
+func TestGlobalRateLimiter_SettleN(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	limiter := newTestGlobalRateLimiter(t, mr, "test-model", "output", 100, networkingv1alpha1.Hour)
+	assert.Equal(t, 100, limiter.Burst())
+
+	// Consuming more than available leaves the bucket in debt
+	limiter.SettleN(time.Now(), 150)
+	assert.InDelta(t, -50, limiter.Tokens(), 1)
+	assert.False(t, limiter.AllowN(time.Now(), 1))
+
+	// Returned tokens don't exceed the capacity
+	limiter.SettleN(time.Now(), -1000)
+	assert.InDelta(t, 100, limiter.Tokens(), 1)
+	assert.True(t, limiter.AllowN(time.Now(), 100))
+}
@@ -0,0 +1,237 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	networkingv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+// maxPolicyCallers is the number of callers whose limits are kept for each RateLimitPolicy before the idle ones are
+// dropped. A caller is idle once its limits are refilled, so dropping it doesn't reset a budget it consumed: the
+// callers which consumed their limits in the current window are kept beyond it.
+const maxPolicyCallers = 10000
+
+// PolicyRateLimitExceededError is returned when a caller exhausts its limits of a RateLimitPolicy.
+type PolicyRateLimitExceededError struct {
+	// Policy is the namespaced name of the policy.
+	Policy string
+	// LimitType is the limit exhausted: requests, input_tokens or output_tokens.
+	LimitType string
+}
+
+func (e *PolicyRateLimitExceededError) Error() string {
+	return fmt.Sprintf("%s rate limit of policy %s exceeded", strings.ReplaceAll(e.LimitType, "_", " "), e.Policy)
+}
+
+// PolicyLimiter enforces the RateLimitPolicies: each caller of the ModelRoutes selected by a policy, identified by
+// the key of the policy, has its own limits. The limiters are local to the router replica.
+type PolicyLimiter struct {
+	mutex    sync.Mutex
+	policies map[types.NamespacedName]*policyCallers
+}
+
+// policyCallers holds the limits of the callers of a generation of a RateLimitPolicy.
+type policyCallers struct {
+	generation int64
+	name       string
+	selector   labels.Selector
+	limits     conf.QuotaLimits
+	// costPerUnit is the cost limit in micro currency units per unit of time, 0 if the cost is not limited.
+	costPerUnit int64
+
+	mutex   sync.Mutex
+	callers map[string]*quotaLevel
+	// sweepAt is the number of callers from which the idle ones are dropped when a caller is added.
+	sweepAt int
+}
+
+// NewPolicyLimiter creates a PolicyLimiter without limits.
+func NewPolicyLimiter() *PolicyLimiter {
+	return &PolicyLimiter{policies: make(map[types.NamespacedName]*policyCallers)}
+}
+
+// Admit consumes a request, its input tokens and a reservation of up to maxOutputTokens output tokens, or
+// defaultOutputReservation if it is 0, from the limits of the caller at each of the policies selecting the
+// ModelRoute. callerKey returns the key of the caller for the key of a policy. When the limits of a policy are
+// exhausted, the limits consumed at the policies before it are returned with a PolicyRateLimitExceededError.
+func (p *PolicyLimiter) Admit(policies []*networkingv1alpha1.RateLimitPolicy, modelRoute *networkingv1alpha1.ModelRoute,
+	callerKey func(networkingv1alpha1.RateLimitKey) string, inputTokens, maxOutputTokens int) (*QuotaReservation, error) {
+	if p == nil || modelRoute == nil || len(policies) == 0 {
+		return nil, nil
+	}
+	if maxOutputTokens <= 0 {
+		maxOutputTokens = defaultOutputReservation
+	}
+
+	var levels []*quotaLevel
+	for _, policy := range policies {
+		callers := p.callersOf(policy)
+		if callers == nil || !callers.selector.Matches(labels.Set(modelRoute.Labels)) {
+			continue
+		}
+		level, err := callers.caller(callerKey(policy.Spec.Key))
+		if err != nil {
+			klog.Errorf("failed to limit the callers of rate limit policy %s: %v", callers.name, err)
+			continue
+		}
+		levels = append(levels, level)
+	}
+	if len(levels) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	reservation := &QuotaReservation{}
+	for i, level := range levels {
+		output, err := level.admit(now, inputTokens, maxOutputTokens)
+		if err != nil {
+			for _, admitted := range levels[:i] {
+				admitted.release(now, inputTokens)
+			}
+			reservation.Cancel()
+			var exceeded *QuotaExceededError
+			if errors.As(err, &exceeded) {
+				return nil, &PolicyRateLimitExceededError{Policy: level.name, LimitType: exceeded.LimitType}
+			}
+			return nil, err
+		}
+		if output != nil {
+			reservation.outputs = append(reservation.outputs, output)
+		}
+		if level.cost != nil {
+			reservation.costs = append(reservation.costs, level.cost)
+		}
+	}
+	return reservation, nil
+}
+
+// Delete drops the limits of the callers of a deleted RateLimitPolicy.
+func (p *PolicyLimiter) Delete(name types.NamespacedName) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	delete(p.policies, name)
+}
+
+// callersOf returns the limits of the callers of the current generation of a policy, those of a previous generation
+// are dropped. It returns nil if the selector of the policy is invalid.
+func (p *PolicyLimiter) callersOf(policy *networkingv1alpha1.RateLimitPolicy) *policyCallers {
+	name := types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if callers, ok := p.policies[name]; ok && callers.generation == policy.Generation {
+		return callers
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.ModelRouteSelector)
+	if err != nil {
+		klog.Errorf("invalid model route selector of rate limit policy %s: %v", name, err)
+		delete(p.policies, name)
+		return nil
+	}
+	callers := &policyCallers{
+		generation: policy.Generation,
+		name:       name.String(),
+		selector:   selector,
+		limits:     policyLimits(&policy.Spec),
+		callers:    make(map[string]*quotaLevel),
+		sweepAt:    maxPolicyCallers,
+	}
+	if policy.Spec.CostPerUnit != nil {
+		callers.costPerUnit = policy.Spec.CostPerUnit.ScaledValue(resource.Micro)
+	}
+	p.policies[name] = callers
+	return callers
+}
+
+// caller returns the limits of a caller of the policy, creating them on its first request.
+func (c *policyCallers) caller(key string) (*quotaLevel, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if level, ok := c.callers[key]; ok {
+		return level, nil
+	}
+	level, err := newQuotaLevel(nil, "policy", c.name, c.limits)
+	if err != nil {
+		return nil, err
+	}
+	if c.costPerUnit > 0 {
+		duration := getTimeUnitDuration(cmp.Or(networkingv1alpha1.RateLimitUnit(c.limits.Unit), defaultQuotaUnit))
+		level.cost = NewLocalLimiter(rate.Limit(float64(c.costPerUnit)/duration.Seconds()), int(c.costPerUnit))
+	}
+	if len(c.callers) >= c.sweepAt {
+		c.dropIdle()
+	}
+	c.callers[key] = level
+	return level, nil
+}
+
+// dropIdle drops the limits of the idle callers. The sweeps are spaced by the number of callers left, so that adding
+// a caller stays constant time on average while all of them are active.
+func (c *policyCallers) dropIdle() {
+	for key, level := range c.callers {
+		if level.idle() {
+			delete(c.callers, key)
+		}
+	}
+	c.sweepAt = max(maxPolicyCallers, 2*len(c.callers))
+}
+
+// policyLimits converts the limits of a policy to the limits of a quota level.
+func policyLimits(spec *networkingv1alpha1.RateLimitPolicySpec) conf.QuotaLimits {
+	limits := conf.QuotaLimits{Unit: string(spec.Unit)}
+	if spec.RequestsPerUnit != nil {
+		limits.RequestsPerUnit = *spec.RequestsPerUnit
+	}
+	if spec.InputTokensPerUnit != nil {
+		limits.InputTokensPerUnit = *spec.InputTokensPerUnit
+	}
+	if spec.OutputTokensPerUnit != nil {
+		limits.OutputTokensPerUnit = *spec.OutputTokensPerUnit
+	}
+	return limits
+}
+
+// RequestCost returns the cost of the tokens of a request priced by the Pricing of a model server, in micro currency
+// units rounded up, 0 if the model server has no pricing.
+func RequestCost(pricing *networkingv1alpha1.Pricing, inputTokens, outputTokens int) int64 {
+	if pricing == nil {
+		return 0
+	}
+	var cost int64
+	if pricing.InputPer1KTokens != nil {
+		cost += int64(inputTokens) * pricing.InputPer1KTokens.ScaledValue(resource.Micro)
+	}
+	if pricing.OutputPer1KTokens != nil {
+		cost += int64(outputTokens) * pricing.OutputPer1KTokens.ScaledValue(resource.Micro)
+	}
+	return (cost + 999) / 1000
+}
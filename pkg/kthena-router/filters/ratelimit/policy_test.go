@@ -0,0 +1,183 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	networkingv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+)
+
+func newTestPolicy(name string, matchLabels map[string]string, spec networkingv1alpha1.RateLimitPolicySpec) *networkingv1alpha1.RateLimitPolicy {
+	spec.ModelRouteSelector = metav1.LabelSelector{MatchLabels: matchLabels}
+	spec.Key = networkingv1alpha1.RateLimitKey{Type: networkingv1alpha1.RateLimitKeyAPIKey}
+	return &networkingv1alpha1.RateLimitPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Generation: 1},
+		Spec:       spec,
+	}
+}
+
+func TestPolicyLimiter_Admit(t *testing.T) {
+	limiter := NewPolicyLimiter()
+	route := &networkingv1alpha1.ModelRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "llama", Labels: map[string]string{"tier": "free"}},
+	}
+	policies := []*networkingv1alpha1.RateLimitPolicy{
+		newTestPolicy("free", map[string]string{"tier": "free"}, networkingv1alpha1.RateLimitPolicySpec{
+			RequestsPerUnit: ptr.To[uint32](2),
+			Unit:            networkingv1alpha1.Hour,
+		}),
+		newTestPolicy("paid", map[string]string{"tier": "paid"}, networkingv1alpha1.RateLimitPolicySpec{
+			RequestsPerUnit: ptr.To[uint32](1),
+			Unit:            networkingv1alpha1.Hour,
+		}),
+	}
+	keyOf := func(caller string) func(networkingv1alpha1.RateLimitKey) string {
+		return func(networkingv1alpha1.RateLimitKey) string { return caller }
+	}
+
+	// Each caller gets its own requests, the policy not selecting the ModelRoute doesn't apply.
+	for _, caller := range []string{"key-1", "key-2"} {
+		for range 2 {
+			_, err := limiter.Admit(policies, route, keyOf(caller), 10, 10)
+			require.NoError(t, err)
+		}
+	}
+	_, err := limiter.Admit(policies, route, keyOf("key-1"), 10, 10)
+	assert.Equal(t, &PolicyRateLimitExceededError{Policy: "default/free", LimitType: metrics.LimitTypeRequests}, err)
+	assert.EqualError(t, err, "requests rate limit of policy default/free exceeded")
+
+	// The callers without a key share the limits of an anonymous caller.
+	_, err = limiter.Admit(policies, route, keyOf(""), 10, 10)
+	require.NoError(t, err)
+
+	// A new generation of the policy starts with full limits.
+	policies[0].Generation = 2
+	_, err = limiter.Admit(policies, route, keyOf("key-1"), 10, 10)
+	assert.NoError(t, err)
+
+	// The limits of a deleted policy are dropped.
+	limiter.Delete(types.NamespacedName{Namespace: "default", Name: "free"})
+	assert.NotContains(t, limiter.policies, types.NamespacedName{Namespace: "default", Name: "free"})
+}
+
+func TestPolicyLimiter_OutputTokens(t *testing.T) {
+	limiter := NewPolicyLimiter()
+	route := &networkingv1alpha1.ModelRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "llama"}}
+	policies := []*networkingv1alpha1.RateLimitPolicy{
+		newTestPolicy("all", nil, networkingv1alpha1.RateLimitPolicySpec{
+			OutputTokensPerUnit: ptr.To[uint32](1000),
+			Unit:                networkingv1alpha1.Hour,
+		}),
+		newTestPolicy("strict", nil, networkingv1alpha1.RateLimitPolicySpec{
+			InputTokensPerUnit: ptr.To[uint32](100),
+			Unit:               networkingv1alpha1.Hour,
+		}),
+	}
+	callerKey := func(networkingv1alpha1.RateLimitKey) string { return "key-1" }
+
+	reservation, err := limiter.Admit(policies, route, callerKey, 10, 500)
+	require.NoError(t, err)
+	caller := limiter.policies[types.NamespacedName{Namespace: "default", Name: "all"}].callers["key-1"]
+	assert.InDelta(t, 500, caller.outputTokens.Tokens(), 1)
+	reservation.Settle(100)
+	assert.InDelta(t, 900, caller.outputTokens.Tokens(), 1)
+
+	// The output tokens reserved at the first policy are returned when the second one rejects the request.
+	_, err = limiter.Admit(policies, route, callerKey, 200, 500)
+	assert.Equal(t, &PolicyRateLimitExceededError{Policy: "default/strict", LimitType: metrics.LimitTypeInputTokens}, err)
+	assert.InDelta(t, 900, caller.outputTokens.Tokens(), 1)
+}
+
+func TestPolicyLimiter_Cost(t *testing.T) {
+	limiter := NewPolicyLimiter()
+	route := &networkingv1alpha1.ModelRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "llama"}}
+	policies := []*networkingv1alpha1.RateLimitPolicy{
+		newTestPolicy("budget", nil, networkingv1alpha1.RateLimitPolicySpec{
+			CostPerUnit: ptr.To(resource.MustParse("5")),
+			Unit:        networkingv1alpha1.Hour,
+		}),
+	}
+	keyOf := func(networkingv1alpha1.RateLimitKey) string { return "key-1" }
+
+	// The requests are admitted until their cost overruns the limit, the cost is charged once.
+	reservation, err := limiter.Admit(policies, route, keyOf, 10, 10)
+	require.NoError(t, err)
+	reservation.ChargeCost(4_000_000)
+	reservation.ChargeCost(4_000_000)
+	reservation, err = limiter.Admit(policies, route, keyOf, 10, 10)
+	require.NoError(t, err)
+	reservation.ChargeCost(2_000_000)
+	_, err = limiter.Admit(policies, route, keyOf, 10, 10)
+	assert.Equal(t, &PolicyRateLimitExceededError{Policy: "default/budget", LimitType: metrics.LimitTypeCost}, err)
+
+	// The other callers have their own budget.
+	_, err = limiter.Admit(policies, route, func(networkingv1alpha1.RateLimitKey) string { return "key-2" }, 10, 10)
+	assert.NoError(t, err)
+}
+
+func TestPolicyCallers_DropIdle(t *testing.T) {
+	limiter := NewPolicyLimiter()
+	route := &networkingv1alpha1.ModelRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "llama"}}
+	policies := []*networkingv1alpha1.RateLimitPolicy{
+		newTestPolicy("all", nil, networkingv1alpha1.RateLimitPolicySpec{
+			RequestsPerUnit: ptr.To[uint32](1),
+			Unit:            networkingv1alpha1.Hour,
+		}),
+	}
+	keyOf := func(caller string) func(networkingv1alpha1.RateLimitKey) string {
+		return func(networkingv1alpha1.RateLimitKey) string { return caller }
+	}
+
+	_, err := limiter.Admit(policies, route, keyOf("key-1"), 10, 10)
+	require.NoError(t, err)
+	callers := limiter.policies[types.NamespacedName{Namespace: "default", Name: "all"}]
+	idle, err := callers.caller("idle")
+	require.NoError(t, err)
+	assert.True(t, idle.idle())
+
+	// Past the maximum number of callers, the idle callers are dropped but those which consumed their limits in the
+	// current window are kept, their budget is not reset.
+	callers.sweepAt = len(callers.callers)
+	_, err = limiter.Admit(policies, route, keyOf("key-2"), 10, 10)
+	require.NoError(t, err)
+	assert.Len(t, callers.callers, 2)
+	assert.NotContains(t, callers.callers, "idle")
+	assert.Equal(t, maxPolicyCallers, callers.sweepAt)
+	_, err = limiter.Admit(policies, route, keyOf("key-1"), 10, 10)
+	assert.Equal(t, &PolicyRateLimitExceededError{Policy: "default/all", LimitType: metrics.LimitTypeRequests}, err)
+}
+
+func TestRequestCost(t *testing.T) {
+	pricing := &networkingv1alpha1.Pricing{
+		InputPer1KTokens:  ptr.To(resource.MustParse("0.0005")),
+		OutputPer1KTokens: ptr.To(resource.MustParse("0.0015")),
+	}
+	assert.Equal(t, int64(2000), RequestCost(pricing, 1000, 1000))
+	// The cost is rounded up to a micro unit.
+	assert.Equal(t, int64(1), RequestCost(pricing, 1, 0))
+	assert.Equal(t, int64(500), RequestCost(&networkingv1alpha1.Pricing{InputPer1KTokens: pricing.InputPer1KTokens}, 1000, 1000))
+	assert.Zero(t, RequestCost(nil, 1000, 1000))
+}
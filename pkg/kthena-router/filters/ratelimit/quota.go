@@ -0,0 +1,289 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"golang.org/x/time/rate"
+
+	networkingv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+// Levels of the quota hierarchy, from the top.
+const (
+	QuotaLevelOrg      = "org"
+	QuotaLevelTeam     = "team"
+	QuotaLevelConsumer = "consumer"
+)
+
+// defaultQuotaUnit is the unit of time of the quotas which don't set it.
+const defaultQuotaUnit = networkingv1alpha1.Minute
+
+// quotaKeyPrefix is the prefix of the Redis keys of the token buckets of the quotas.
+const quotaKeyPrefix = "kthena:quota"
+
+// QuotaExceededError is returned when a level of the quota hierarchy of the caller is exhausted.
+type QuotaExceededError struct {
+	// Level is the level of the quota hierarchy exhausted and Name its name.
+	Level string
+	Name  string
+	// LimitType is the limit exhausted: requests, input_tokens or output_tokens.
+	LimitType string
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s quota of %s %s exceeded", strings.ReplaceAll(e.LimitType, "_", " "), e.Level, e.Name)
+}
+
+// quotaLevel holds the limiters of a level of the quota hierarchy, nil for the limits it doesn't set.
+type quotaLevel struct {
+	level        string
+	name         string
+	requests     Limiter
+	inputTokens  Limiter
+	outputTokens Limiter
+	// cost is the limiter of the cost of the requests in micro currency units, charged once their usage is known.
+	cost Limiter
+}
+
+// QuotaLimiter enforces the quotas of the consumers top-down: a request is admitted when it passes the limits of its
+// org, of its team and its own. The limits of a level are shared by all the consumers below it. The limiters are
+// shared by the router replicas through Redis when it is configured, otherwise each replica enforces the limits on its
+// own requests.
+type QuotaLimiter struct {
+	// levels are the levels of the quotas of each consumer, from its org to itself.
+	levels map[string][]*quotaLevel
+}
+
+// NewQuotaLimiter creates the limiters of the quota hierarchy of the configuration.
+func NewQuotaLimiter(config conf.QuotaConfig) (*QuotaLimiter, error) {
+	var client *redis.Client
+	if config.Redis != nil && len(config.Orgs) > 0 {
+		client = redis.NewClient(&redis.Options{Addr: config.Redis.Address})
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := client.Ping(ctx).Err(); err != nil {
+			_ = client.Close()
+			return nil, fmt.Errorf("failed to connect to redis: %w", err)
+		}
+	}
+	q := &QuotaLimiter{levels: make(map[string][]*quotaLevel)}
+	for _, org := range config.Orgs {
+		orgLevel, err := newQuotaLevel(client, QuotaLevelOrg, org.Name, org.QuotaLimits)
+		if err != nil {
+			return nil, err
+		}
+		for _, team := range org.Teams {
+			teamLevel, err := newQuotaLevel(client, QuotaLevelTeam, org.Name+"/"+team.Name, team.QuotaLimits)
+			if err != nil {
+				return nil, err
+			}
+			for _, consumer := range team.Consumers {
+				if _, ok := q.levels[consumer.Subject]; ok {
+					return nil, fmt.Errorf("consumer %s belongs to several teams", consumer.Subject)
+				}
+				consumerLevel, err := newQuotaLevel(client, QuotaLevelConsumer, consumer.Subject, consumer.QuotaLimits)
+				if err != nil {
+					return nil, err
+				}
+				q.levels[consumer.Subject] = []*quotaLevel{orgLevel, teamLevel, consumerLevel}
+			}
+		}
+	}
+	return q, nil
+}
+
+// newQuotaLevel creates the limiters of the limits set at a level of the quota hierarchy, in Redis if the client is not
+// nil, else local to the router replica.
+func newQuotaLevel(client *redis.Client, level, name string, limits conf.QuotaLimits) (*quotaLevel, error) {
+	if name == "" || strings.HasSuffix(name, "/") {
+		return nil, fmt.Errorf("the %s quotas require a name", level)
+	}
+	unit := networkingv1alpha1.RateLimitUnit(limits.Unit)
+	switch unit {
+	case "":
+		unit = defaultQuotaUnit
+	case networkingv1alpha1.Second, networkingv1alpha1.Minute, networkingv1alpha1.Hour, networkingv1alpha1.Day, networkingv1alpha1.Month:
+	default:
+		return nil, fmt.Errorf("unknown unit %q of the quotas of %s %s", limits.Unit, level, name)
+	}
+	duration := getTimeUnitDuration(unit)
+	newLimiter := func(limitType string, limit uint32) Limiter {
+		if limit == 0 {
+			return nil
+		}
+		if client != nil {
+			return NewGlobalRateLimiter(client, quotaKeyPrefix+":"+level, name, limitType, limit, unit, 1)
+		}
+		return NewLocalLimiter(rate.Limit(float64(limit)/duration.Seconds()), int(limit))
+	}
+	return &quotaLevel{
+		level:        level,
+		name:         name,
+		requests:     newLimiter(metrics.LimitTypeRequests, limits.RequestsPerUnit),
+		inputTokens:  newLimiter(metrics.LimitTypeInputTokens, limits.InputTokensPerUnit),
+		outputTokens: newLimiter(metrics.LimitTypeOutputTokens, limits.OutputTokensPerUnit),
+	}, nil
+}
+
+// admit consumes a request, its input tokens and a reservation of its output tokens, or nothing if one of them is
+// not available.
+func (l *quotaLevel) admit(now time.Time, inputTokens, outputTokens int) (*OutputReservation, error) {
+	// The cost of a request is unknown until it completes, it is admitted as long as the cost limit isn't overrun.
+	if l.cost != nil && l.cost.Tokens() <= 0 {
+		return nil, l.exceeded(metrics.LimitTypeCost)
+	}
+	if l.requests != nil && !l.requests.AllowN(now, 1) {
+		return nil, l.exceeded(metrics.LimitTypeRequests)
+	}
+	if l.inputTokens != nil && !l.inputTokens.AllowN(now, inputTokens) {
+		l.release(now, 0)
+		return nil, l.exceeded(metrics.LimitTypeInputTokens)
+	}
+	if l.outputTokens == nil {
+		return nil, nil
+	}
+	reserved := min(outputTokens, l.outputTokens.Burst())
+	if !l.outputTokens.AllowN(now, reserved) {
+		l.release(now, inputTokens)
+		return nil, l.exceeded(metrics.LimitTypeOutputTokens)
+	}
+	return &OutputReservation{limiter: l.outputTokens, reserved: reserved, charged: reserved}, nil
+}
+
+// release returns a request and its input tokens.
+func (l *quotaLevel) release(now time.Time, inputTokens int) {
+	if l.requests != nil {
+		l.requests.SettleN(now, -1)
+	}
+	if l.inputTokens != nil && inputTokens > 0 {
+		l.inputTokens.SettleN(now, -inputTokens)
+	}
+}
+
+// idle reports whether the limits of the level are full, nothing was consumed from them in the current window.
+func (l *quotaLevel) idle() bool {
+	for _, limiter := range []Limiter{l.requests, l.inputTokens, l.outputTokens, l.cost} {
+		if limiter != nil && limiter.Tokens() < float64(limiter.Burst()) {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *quotaLevel) exceeded(limitType string) *QuotaExceededError {
+	return &QuotaExceededError{Level: l.level, Name: l.name, LimitType: limitType}
+}
+
+// Groups returns the team and the org of a consumer of the quotas, empty if the quotas don't list it.
+func (q *QuotaLimiter) Groups(subject string) (team, org string) {
+	if q == nil {
+		return "", ""
+	}
+	levels := q.levels[subject]
+	if len(levels) == 0 {
+		return "", ""
+	}
+	org = levels[0].name
+	return strings.TrimPrefix(levels[1].name, org+"/"), org
+}
+
+// Admit consumes a request, its input tokens and a reservation of up to maxOutputTokens output tokens, or
+// defaultOutputReservation if it is 0, at each level of the quotas of the consumer, from its org to itself. When a
+// level is exhausted, the quotas consumed at the levels above are returned with a QuotaExceededError. The reservation
+// is nil if the caller is not a consumer of the quotas.
+func (q *QuotaLimiter) Admit(subject string, inputTokens, maxOutputTokens int) (*QuotaReservation, error) {
+	if q == nil {
+		return nil, nil
+	}
+	levels := q.levels[subject]
+	if len(levels) == 0 {
+		return nil, nil
+	}
+	if maxOutputTokens <= 0 {
+		maxOutputTokens = defaultOutputReservation
+	}
+	now := time.Now()
+	reservation := &QuotaReservation{}
+	for i, level := range levels {
+		output, err := level.admit(now, inputTokens, maxOutputTokens)
+		if err != nil {
+			for _, admitted := range levels[:i] {
+				admitted.release(now, inputTokens)
+			}
+			reservation.Cancel()
+			return nil, err
+		}
+		if output != nil {
+			reservation.outputs = append(reservation.outputs, output)
+		}
+	}
+	return reservation, nil
+}
+
+// QuotaReservation holds the output tokens reserved for a request at the levels of its quotas.
+type QuotaReservation struct {
+	outputs []*OutputReservation
+	// costs are the cost limiters of the levels, charged once.
+	costs       []Limiter
+	costCharged atomic.Bool
+}
+
+// Settle charges the tokens generated by the request to all the levels of its quotas.
+func (r *QuotaReservation) Settle(tokens int) {
+	if r == nil {
+		return
+	}
+	for _, output := range r.outputs {
+		output.Settle(tokens)
+	}
+}
+
+// Charge charges the tokens generated so far by a streamed request to all the levels of its quotas.
+func (r *QuotaReservation) Charge(tokens int) {
+	if r == nil {
+		return
+	}
+	for _, output := range r.outputs {
+		output.Charge(tokens)
+	}
+}
+
+// Cancel returns the reserved tokens of a request failing without output, unless it is already settled.
+func (r *QuotaReservation) Cancel() {
+	r.Settle(0)
+}
+
+// ChargeCost charges the cost of the request, in micro currency units, to the cost limits of the levels. Only the
+// first charge counts, the cost limits are left in debt until it is refilled if it exceeds them.
+func (r *QuotaReservation) ChargeCost(cost int64) {
+	if r == nil || len(r.costs) == 0 || cost <= 0 || !r.costCharged.CompareAndSwap(false, true) {
+		return
+	}
+	now := time.Now()
+	for _, limiter := range r.costs {
+		limiter.SettleN(now, int(cost))
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+func newTestQuotaLimiter(t *testing.T) *QuotaLimiter {
+	quotas, err := NewQuotaLimiter(conf.QuotaConfig{
+		Orgs: []conf.QuotaOrgConfig{{
+			Name:        "acme",
+			QuotaLimits: conf.QuotaLimits{RequestsPerUnit: 3, OutputTokensPerUnit: 1000, Unit: "hour"},
+			Teams: []conf.QuotaTeamConfig{{
+				Name:        "search",
+				QuotaLimits: conf.QuotaLimits{InputTokensPerUnit: 100, Unit: "hour"},
+				Consumers: []conf.QuotaConsumerConfig{
+					{Subject: "key-1", QuotaLimits: conf.QuotaLimits{RequestsPerUnit: 2, Unit: "hour"}},
+					{Subject: "key-2"},
+				},
+			}},
+		}},
+	})
+	require.NoError(t, err)
+	return quotas
+}
+
+func TestQuotaLimiter_Admit(t *testing.T) {
+	quotas := newTestQuotaLimiter(t)
+
+	// The callers which are not consumers are not subject to quotas.
+	reservation, err := quotas.Admit("unknown", 1000, 0)
+	assert.NoError(t, err)
+	assert.Nil(t, reservation)
+
+	// The input tokens of the team are exhausted first, the request consumed at the org is returned.
+	_, err = quotas.Admit("key-2", 150, 10)
+	assert.Equal(t, &QuotaExceededError{Level: QuotaLevelTeam, Name: "acme/search", LimitType: metrics.LimitTypeInputTokens}, err)
+
+	for range 2 {
+		reservation, err = quotas.Admit("key-1", 10, 10)
+		require.NoError(t, err)
+		reservation.Settle(10)
+	}
+	// The consumer exhausted its own requests, the org still has one left for the other consumers of the team.
+	_, err = quotas.Admit("key-1", 10, 10)
+	assert.Equal(t, &QuotaExceededError{Level: QuotaLevelConsumer, Name: "key-1", LimitType: metrics.LimitTypeRequests}, err)
+	assert.EqualError(t, err, "requests quota of consumer key-1 exceeded")
+
+	_, err = quotas.Admit("key-2", 10, 10)
+	require.NoError(t, err)
+	_, err = quotas.Admit("key-2", 10, 10)
+	assert.Equal(t, &QuotaExceededError{Level: QuotaLevelOrg, Name: "acme", LimitType: metrics.LimitTypeRequests}, err)
+}
+
+func TestQuotaLimiter_OutputTokens(t *testing.T) {
+	quotas := newTestQuotaLimiter(t)
+	org := quotas.levels["key-1"][0]
+
+	reservation, err := quotas.Admit("key-1", 10, 0)
+	require.NoError(t, err)
+	assert.InDelta(t, 1000-defaultOutputReservation, org.outputTokens.Tokens(), 1)
+
+	// The output tokens generated are charged instead of the reservation.
+	reservation.Settle(100)
+	assert.InDelta(t, 900, org.outputTokens.Tokens(), 1)
+
+	// The reservation of a failed request is returned.
+	reservation, err = quotas.Admit("key-1", 10, 500)
+	require.NoError(t, err)
+	assert.InDelta(t, 400, org.outputTokens.Tokens(), 1)
+	reservation.Cancel()
+	assert.InDelta(t, 900, org.outputTokens.Tokens(), 1)
+
+	// A request needs the output tokens it may generate, up to the capacity of the limiter.
+	_, err = quotas.Admit("key-2", 10, 2000)
+	assert.Equal(t, &QuotaExceededError{Level: QuotaLevelOrg, Name: "acme", LimitType: metrics.LimitTypeOutputTokens}, err)
+}
+
+func TestQuotaLimiter_Redis(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	config := conf.QuotaConfig{
+		Orgs: []conf.QuotaOrgConfig{{
+			Name:        "acme",
+			QuotaLimits: conf.QuotaLimits{RequestsPerUnit: 3, Unit: "day"},
+			Teams: []conf.QuotaTeamConfig{{
+				Name:      "search",
+				Consumers: []conf.QuotaConsumerConfig{{Subject: "key-1"}},
+			}},
+		}},
+		Redis: &conf.QuotaRedisConfig{Address: mr.Addr()},
+	}
+	// Two router replicas share the budget of the org.
+	replica1, err := NewQuotaLimiter(config)
+	require.NoError(t, err)
+	replica2, err := NewQuotaLimiter(config)
+	require.NoError(t, err)
+
+	for _, quotas := range []*QuotaLimiter{replica1, replica2, replica1} {
+		_, err = quotas.Admit("key-1", 10, 10)
+		require.NoError(t, err)
+	}
+	_, err = replica2.Admit("key-1", 10, 10)
+	assert.Equal(t, &QuotaExceededError{Level: QuotaLevelOrg, Name: "acme", LimitType: metrics.LimitTypeRequests}, err)
+
+	config.Redis.Address = "127.0.0.1:1"
+	_, err = NewQuotaLimiter(config)
+	assert.ErrorContains(t, err, "failed to connect to redis")
+}
+
+func TestNewQuotaLimiter_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config conf.QuotaConfig
+		err    string
+	}{
+		{
+			name:   "org without name",
+			config: conf.QuotaConfig{Orgs: []conf.QuotaOrgConfig{{}}},
+			err:    "the org quotas require a name",
+		},
+		{
+			name: "unknown unit",
+			config: conf.QuotaConfig{Orgs: []conf.QuotaOrgConfig{{
+				Name:  "acme",
+				Teams: []conf.QuotaTeamConfig{{Name: "search", QuotaLimits: conf.QuotaLimits{Unit: "week"}}},
+			}}},
+			err: `unknown unit "week" of the quotas of team acme/search`,
+		},
+		{
+			name: "consumer of several teams",
+			config: conf.QuotaConfig{Orgs: []conf.QuotaOrgConfig{{
+				Name: "acme",
+				Teams: []conf.QuotaTeamConfig{
+					{Name: "search", Consumers: []conf.QuotaConsumerConfig{{Subject: "key-1"}}},
+					{Name: "ads", Consumers: []conf.QuotaConsumerConfig{{Subject: "key-1"}}},
+				},
+			}}},
+			err: "consumer key-1 belongs to several teams",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewQuotaLimiter(tt.config)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -19,6 +19,7 @@ package ratelimit
 import (
 	"context"
 	"fmt"
+	"math"
 	"sync"
 	"time"
 
@@ -48,6 +49,33 @@ func (e *OutputRateLimitExceededError) Error() string {
 	return "output token rate limit exceeded"
 }
 
+type ImageRateLimitExceededError struct{}
+
+func (e *ImageRateLimitExceededError) Error() string {
+	return "image rate limit exceeded"
+}
+
+type MegapixelRateLimitExceededError struct{}
+
+func (e *MegapixelRateLimitExceededError) Error() string {
+	return "megapixel rate limit exceeded"
+}
+
+type ItemRateLimitExceededError struct{}
+
+func (e *ItemRateLimitExceededError) Error() string {
+	return "item rate limit exceeded"
+}
+
+type ByteRateLimitExceededError struct{}
+
+func (e *ByteRateLimitExceededError) Error() string {
+	return "byte rate limit exceeded"
+}
+
+// kilopixelsPerMegapixel is the resolution megapixels are rate limited at, as limiters count whole units.
+const kilopixelsPerMegapixel = 1000
+
 // Limiter interface that both local and global rate limiters implement
 // Only includes methods that are actually used
 type Limiter interface {
@@ -55,6 +83,11 @@ type Limiter interface {
 	AllowN(now time.Time, n int) bool
 	// Tokens returns the number of tokens currently available
 	Tokens() float64
+	// Burst returns the maximum number of tokens
+	Burst() int
+	// SettleN consumes n tokens even if they are not available, leaving the limiter in debt until they are refilled,
+	// or returns -n tokens consumed but not used if n is negative
+	SettleN(now time.Time, n int)
 }
 
 // TokenRateLimiter provides rate limiting functionality for both input and output tokens
@@ -64,6 +97,12 @@ type TokenRateLimiter struct {
 	// Unified rate limiters using Limiter interface
 	inputLimiter  map[string]Limiter
 	outputLimiter map[string]Limiter
+	// Limiters of the generated images, megapixels are counted in kilopixels
+	imageLimiter     map[string]Limiter
+	megapixelLimiter map[string]Limiter
+	// Limiters of the size of the requests, in input items and in bytes
+	itemLimiter map[string]Limiter
+	byteLimiter map[string]Limiter
 
 	// Redis client for global rate limiting
 	redisClient *redis.Client
@@ -71,29 +110,77 @@ type TokenRateLimiter struct {
 	tokenizer tokenizer.Tokenizer
 }
 
-// LocalLimiter wraps golang.org/x/time/rate.Limiter to implement our Limiter interface
+// LocalLimiter is a token bucket of the router replica implementing our Limiter interface. Unlike
+// golang.org/x/time/rate.Limiter, its tokens can be returned.
 type LocalLimiter struct {
-	*rate.Limiter
+	mutex  sync.Mutex
+	limit  rate.Limit
+	burst  int
+	tokens float64
+	last   time.Time
 }
 
-// NewLocalLimiter creates a new LocalLimiter
+// NewLocalLimiter creates a new LocalLimiter, full
 func NewLocalLimiter(limit rate.Limit, burst int) *LocalLimiter {
 	return &LocalLimiter{
-		Limiter: rate.NewLimiter(limit, burst),
+		limit:  limit,
+		burst:  burst,
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// refill adds the tokens generated since the last update, the caller must hold the mutex
+func (l *LocalLimiter) refill(now time.Time) {
+	if now.After(l.last) {
+		l.tokens = math.Min(float64(l.burst), l.tokens+now.Sub(l.last).Seconds()*float64(l.limit))
+		l.last = now
 	}
 }
 
+// AllowN reports whether n tokens may be consumed and consumes them if so
+func (l *LocalLimiter) AllowN(now time.Time, n int) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.refill(now)
+	if float64(n) > l.tokens {
+		return false
+	}
+	l.tokens -= float64(n)
+	return true
+}
+
 // Tokens returns the number of tokens currently available
 func (l *LocalLimiter) Tokens() float64 {
-	return l.Limiter.Tokens()
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.refill(time.Now())
+	return l.tokens
+}
+
+// Burst returns the maximum number of tokens
+func (l *LocalLimiter) Burst() int {
+	return l.burst
+}
+
+// SettleN consumes n tokens even if they are not available, or returns -n tokens if n is negative
+func (l *LocalLimiter) SettleN(now time.Time, n int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.refill(now)
+	l.tokens = math.Min(float64(l.burst), l.tokens-float64(n))
 }
 
 // NewTokenRateLimiter creates a new TokenRateLimiter instance
 func NewTokenRateLimiter() *TokenRateLimiter {
 	return &TokenRateLimiter{
-		inputLimiter:  make(map[string]Limiter),
-		outputLimiter: make(map[string]Limiter),
-		tokenizer:     tokenizer.NewSimpleEstimateTokenizer(),
+		inputLimiter:     make(map[string]Limiter),
+		outputLimiter:    make(map[string]Limiter),
+		imageLimiter:     make(map[string]Limiter),
+		megapixelLimiter: make(map[string]Limiter),
+		itemLimiter:      make(map[string]Limiter),
+		byteLimiter:      make(map[string]Limiter),
+		tokenizer:        tokenizer.NewSimpleEstimateTokenizer(),
 	}
 }
 
@@ -105,7 +192,12 @@ func (r *TokenRateLimiter) RateLimit(model, prompt string) error {
 		klog.Errorf("failed to calculate token number: %v", err)
 		tokens = len(prompt) / 4 // fallback estimation
 	}
+	return r.RateLimitTokens(model, tokens)
+}
 
+// RateLimitTokens checks if a request of the given number of input tokens, counted by the tokenizer of its model, is
+// within rate limits for both input and output tokens
+func (r *TokenRateLimiter) RateLimitTokens(model string, tokens int) error {
 	r.mutex.RLock()
 	inputLimiter, hasInputLimit := r.inputLimiter[model]
 	outputLimiter, hasOutputLimit := r.outputLimiter[model]
@@ -125,14 +217,55 @@ func (r *TokenRateLimiter) RateLimit(model, prompt string) error {
 	return nil
 }
 
-// RecordOutputTokens records the actual output tokens consumed after response generation
+// ImageRateLimit checks if an image generation request is within the rate limits of the images and megapixels
+// generated, and consumes them if so. Image generation isn't subject to the token rate limits.
+func (r *TokenRateLimiter) ImageRateLimit(model string, images int, megapixels float64) error {
+	r.mutex.RLock()
+	imageLimiter, hasImageLimit := r.imageLimiter[model]
+	megapixelLimiter, hasMegapixelLimit := r.megapixelLimiter[model]
+	r.mutex.RUnlock()
+
+	now := time.Now()
+	if hasImageLimit && !imageLimiter.AllowN(now, images) {
+		return &ImageRateLimitExceededError{}
+	}
+	if hasMegapixelLimit && !megapixelLimiter.AllowN(now, int(math.Ceil(megapixels*kilopixelsPerMegapixel))) {
+		return &MegapixelRateLimitExceededError{}
+	}
+	return nil
+}
+
+// RequestSizeRateLimit checks if a request of the given number of input items and size in bytes is within the rate
+// limits of the items and bytes, and consumes them if so. The size limits apply in addition to the token limits.
+func (r *TokenRateLimiter) RequestSizeRateLimit(model string, items, bytes int) error {
+	r.mutex.RLock()
+	itemLimiter, hasItemLimit := r.itemLimiter[model]
+	byteLimiter, hasByteLimit := r.byteLimiter[model]
+	r.mutex.RUnlock()
+
+	now := time.Now()
+	if hasItemLimit && !itemLimiter.AllowN(now, items) {
+		return &ItemRateLimitExceededError{}
+	}
+	if hasByteLimit && !byteLimiter.AllowN(now, bytes) {
+		// The items of a request rejected for its bytes aren't consumed
+		if hasItemLimit {
+			itemLimiter.SettleN(now, -items)
+		}
+		return &ByteRateLimitExceededError{}
+	}
+	return nil
+}
+
+// RecordOutputTokens records the actual output tokens consumed after response generation.
+// The tokens exceeding the available ones are consumed too, the requests are rejected until they are refilled.
 func (r *TokenRateLimiter) RecordOutputTokens(model string, tokenCount int) {
 	r.mutex.RLock()
 	outputLimiter, exists := r.outputLimiter[model]
 	r.mutex.RUnlock()
 
 	if exists {
-		outputLimiter.AllowN(time.Now(), tokenCount)
+		outputLimiter.SettleN(time.Now(), tokenCount)
 	}
 }
 
@@ -144,6 +277,12 @@ func (r *TokenRateLimiter) AddOrUpdateLimiter(model string, ratelimit *networkin
 	// Determine if we should use global or local rate limiting
 	useGlobal := ratelimit.Global != nil && ratelimit.Global.Redis != nil
 
+	// The buckets hold the tokens of burst units of time
+	burst := uint32(1)
+	if ratelimit.Burst != nil && *ratelimit.Burst > 0 {
+		burst = *ratelimit.Burst
+	}
+
 	if useGlobal {
 		// Initialize Redis client if not already done
 		if r.redisClient == nil {
@@ -168,6 +307,7 @@ func (r *TokenRateLimiter) AddOrUpdateLimiter(model string, ratelimit *networkin
 				"input",
 				*ratelimit.InputTokensPerUnit,
 				ratelimit.Unit,
+				burst,
 			)
 		}
 
@@ -179,6 +319,55 @@ func (r *TokenRateLimiter) AddOrUpdateLimiter(model string, ratelimit *networkin
 				"output",
 				*ratelimit.OutputTokensPerUnit,
 				ratelimit.Unit,
+				burst,
+			)
+		}
+
+		if ratelimit.ImagesPerUnit != nil {
+			r.imageLimiter[model] = NewGlobalRateLimiter(
+				r.redisClient,
+				"kthena:ratelimit",
+				model,
+				"images",
+				*ratelimit.ImagesPerUnit,
+				ratelimit.Unit,
+				burst,
+			)
+		}
+
+		if ratelimit.MegapixelsPerUnit != nil {
+			r.megapixelLimiter[model] = NewGlobalRateLimiter(
+				r.redisClient,
+				"kthena:ratelimit",
+				model,
+				"kilopixels",
+				*ratelimit.MegapixelsPerUnit*kilopixelsPerMegapixel,
+				ratelimit.Unit,
+				burst,
+			)
+		}
+
+		if ratelimit.ItemsPerUnit != nil {
+			r.itemLimiter[model] = NewGlobalRateLimiter(
+				r.redisClient,
+				"kthena:ratelimit",
+				model,
+				"items",
+				*ratelimit.ItemsPerUnit,
+				ratelimit.Unit,
+				burst,
+			)
+		}
+
+		if ratelimit.BytesPerUnit != nil {
+			r.byteLimiter[model] = NewGlobalRateLimiter(
+				r.redisClient,
+				"kthena:ratelimit",
+				model,
+				"bytes",
+				*ratelimit.BytesPerUnit,
+				ratelimit.Unit,
+				burst,
 			)
 		}
 	} else {
@@ -188,14 +377,43 @@ func (r *TokenRateLimiter) AddOrUpdateLimiter(model string, ratelimit *networkin
 		if ratelimit.InputTokensPerUnit != nil {
 			r.inputLimiter[model] = NewLocalLimiter(
 				rate.Limit(float64(*ratelimit.InputTokensPerUnit)/duration.Seconds()),
-				int(*ratelimit.InputTokensPerUnit),
+				int(*ratelimit.InputTokensPerUnit)*int(burst),
 			)
 		}
 
 		if ratelimit.OutputTokensPerUnit != nil {
 			r.outputLimiter[model] = NewLocalLimiter(
 				rate.Limit(float64(*ratelimit.OutputTokensPerUnit)/duration.Seconds()),
-				int(*ratelimit.OutputTokensPerUnit),
+				int(*ratelimit.OutputTokensPerUnit)*int(burst),
+			)
+		}
+
+		if ratelimit.ImagesPerUnit != nil {
+			r.imageLimiter[model] = NewLocalLimiter(
+				rate.Limit(float64(*ratelimit.ImagesPerUnit)/duration.Seconds()),
+				int(*ratelimit.ImagesPerUnit)*int(burst),
+			)
+		}
+
+		if ratelimit.MegapixelsPerUnit != nil {
+			kilopixels := *ratelimit.MegapixelsPerUnit * kilopixelsPerMegapixel
+			r.megapixelLimiter[model] = NewLocalLimiter(
+				rate.Limit(float64(kilopixels)/duration.Seconds()),
+				int(kilopixels)*int(burst),
+			)
+		}
+
+		if ratelimit.ItemsPerUnit != nil {
+			r.itemLimiter[model] = NewLocalLimiter(
+				rate.Limit(float64(*ratelimit.ItemsPerUnit)/duration.Seconds()),
+				int(*ratelimit.ItemsPerUnit)*int(burst),
+			)
+		}
+
+		if ratelimit.BytesPerUnit != nil {
+			r.byteLimiter[model] = NewLocalLimiter(
+				rate.Limit(float64(*ratelimit.BytesPerUnit)/duration.Seconds()),
+				int(*ratelimit.BytesPerUnit)*int(burst),
 			)
 		}
 	}
@@ -210,6 +428,10 @@ func (r *TokenRateLimiter) DeleteLimiter(model string) {
 
 	delete(r.inputLimiter, model)
 	delete(r.outputLimiter, model)
+	delete(r.imageLimiter, model)
+	delete(r.megapixelLimiter, model)
+	delete(r.itemLimiter, model)
+	delete(r.byteLimiter, model)
 }
 
 func getTimeUnitDuration(unit networkingv1alpha1.RateLimitUnit) time.Duration {
@@ -53,6 +53,57 @@ func TestTokenRateLimiter_Basic(t *testing.T) {
 	}
 }
 
+func TestTokenRateLimiter_Burst(t *testing.T) {
+	rl := NewTokenRateLimiter()
+	model := "test-model"
+	prompt := "hello world" // 3 tokens
+	tokens := uint32(10)
+	burst := uint32(3)
+
+	rl.AddOrUpdateLimiter(model, &networkingv1alpha1.RateLimit{
+		InputTokensPerUnit: &tokens,
+		Unit:               networkingv1alpha1.Second,
+		Burst:              &burst,
+	})
+
+	// The bucket holds the tokens of 3 seconds
+	for i := 0; i < 10; i++ {
+		if err := rl.RateLimit(model, prompt); err != nil {
+			t.Fatalf("unexpected error on request %d of the burst: %v", i, err)
+		}
+	}
+	err := rl.RateLimit(model, prompt)
+	if _, ok := err.(*InputRateLimitExceededError); !ok {
+		t.Fatalf("expected InputRateLimitExceededError, got %T: %v", err, err)
+	}
+	if got := rl.inputLimiter[model].Burst(); got != 30 {
+		t.Fatalf("expected a burst of 30 tokens, got %d", got)
+	}
+}
+
+func TestTokenRateLimiter_RateLimitTokens(t *testing.T) {
+	rl := NewTokenRateLimiter()
+	model := "test-model"
+	tokens := uint32(10)
+
+	rl.AddOrUpdateLimiter(model, &networkingv1alpha1.RateLimit{
+		InputTokensPerUnit: &tokens,
+		Unit:               networkingv1alpha1.Second,
+	})
+
+	// The tokens counted by the tokenizer of the model are limited, not the estimate of the prompt
+	if err := rl.RateLimitTokens(model, 8); err != nil {
+		t.Fatalf("unexpected error on allowed request: %v", err)
+	}
+	err := rl.RateLimitTokens(model, 3)
+	if _, ok := err.(*InputRateLimitExceededError); !ok {
+		t.Fatalf("expected InputRateLimitExceededError, got %T: %v", err, err)
+	}
+	if err := rl.RateLimitTokens(model, 2); err != nil {
+		t.Fatalf("unexpected error on allowed request: %v", err)
+	}
+}
+
 func TestTokenRateLimiter_NoLimiter(t *testing.T) {
 	rl := NewTokenRateLimiter()
 	// No limiter added, should always allow
@@ -276,3 +327,78 @@ func TestTokenRateLimiter_InputAndOutputErrors(t *testing.T) {
 		t.Fatalf("expected OutputRateLimitExceededError, got %T: %v", err, err)
 	}
 }
+
+func TestTokenRateLimiter_ImageRateLimit(t *testing.T) {
+	rl := NewTokenRateLimiter()
+	model := "test-model"
+	images := uint32(4)
+	megapixels := uint32(3)
+
+	rl.AddOrUpdateLimiter(model, &networkingv1alpha1.RateLimit{
+		ImagesPerUnit:     &images,
+		MegapixelsPerUnit: &megapixels,
+		Unit:              networkingv1alpha1.Minute,
+	})
+
+	// Two 1024x1024 images are about 2.1 megapixels
+	if err := rl.ImageRateLimit(model, 2, 2.097152); err != nil {
+		t.Fatalf("first request should be allowed: %v", err)
+	}
+	// One more 1024x1024 image exceeds the megapixels
+	err := rl.ImageRateLimit(model, 1, 1.048576)
+	if _, ok := err.(*MegapixelRateLimitExceededError); !ok {
+		t.Fatalf("expected MegapixelRateLimitExceededError, got %T: %v", err, err)
+	}
+	// Images have been consumed by the rejected request
+	if err := rl.ImageRateLimit(model, 1, 0.262144); err != nil {
+		t.Fatalf("small image should be allowed: %v", err)
+	}
+	err = rl.ImageRateLimit(model, 1, 0.262144)
+	if _, ok := err.(*ImageRateLimitExceededError); !ok {
+		t.Fatalf("expected ImageRateLimitExceededError, got %T: %v", err, err)
+	}
+
+	// Image generation isn't subject to token limits and vice versa
+	if err := rl.RateLimit(model, "hello world"); err != nil {
+		t.Fatalf("expected nil for token rate limit, got %v", err)
+	}
+
+	rl.DeleteLimiter(model)
+	if err := rl.ImageRateLimit(model, 10, 10); err != nil {
+		t.Fatalf("expected nil after deletion, got %v", err)
+	}
+}
+
+func TestTokenRateLimiter_RequestSizeRateLimit(t *testing.T) {
+	rl := NewTokenRateLimiter()
+	model := "test-model"
+	items := uint32(10)
+	bytes := uint32(1000)
+
+	rl.AddOrUpdateLimiter(model, &networkingv1alpha1.RateLimit{
+		ItemsPerUnit: &items,
+		BytesPerUnit: &bytes,
+		Unit:         networkingv1alpha1.Minute,
+	})
+
+	if err := rl.RequestSizeRateLimit(model, 8, 400); err != nil {
+		t.Fatalf("first request should be allowed: %v", err)
+	}
+	err := rl.RequestSizeRateLimit(model, 1, 700)
+	if _, ok := err.(*ByteRateLimitExceededError); !ok {
+		t.Fatalf("expected ByteRateLimitExceededError, got %T: %v", err, err)
+	}
+	// The items of the request rejected for its bytes have been returned
+	if err := rl.RequestSizeRateLimit(model, 2, 100); err != nil {
+		t.Fatalf("request within the items left should be allowed: %v", err)
+	}
+	err = rl.RequestSizeRateLimit(model, 1, 100)
+	if _, ok := err.(*ItemRateLimitExceededError); !ok {
+		t.Fatalf("expected ItemRateLimitExceededError, got %T: %v", err, err)
+	}
+
+	rl.DeleteLimiter(model)
+	if err := rl.RequestSizeRateLimit(model, 100, 100000); err != nil {
+		t.Fatalf("expected nil after deletion, got %v", err)
+	}
+}
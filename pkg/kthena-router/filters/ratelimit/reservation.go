@@ -0,0 +1,107 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultOutputReservation is the number of output tokens reserved for a request not limiting its completion.
+const defaultOutputReservation = 256
+
+// OutputReservation holds the output tokens reserved for a request when it is admitted, until it is settled with
+// the tokens it generated. The requests streamed concurrently can't generate more than the tokens available when they
+// are admitted, plus the tokens they generate beyond their reservation, which are charged to the next requests.
+// The tokens streamed beyond the reservation are charged as they are generated, so that a stream interrupted before
+// its final usage can't generate more than it is charged.
+type OutputReservation struct {
+	limiter  Limiter
+	reserved int
+
+	mutex sync.Mutex
+	// charged is the number of tokens charged to the limiter, at least the reserved ones.
+	charged int
+	settled bool
+}
+
+// ReserveOutputTokens reserves the output tokens of a request generating up to maxTokens tokens, or
+// defaultOutputReservation if maxTokens is 0, capped at the capacity of the limiter. It returns an
+// OutputRateLimitExceededError if they are not available, and a nil reservation if the model has no output limit.
+func (r *TokenRateLimiter) ReserveOutputTokens(model string, maxTokens int) (*OutputReservation, error) {
+	r.mutex.RLock()
+	outputLimiter, exists := r.outputLimiter[model]
+	r.mutex.RUnlock()
+	if !exists {
+		return nil, nil
+	}
+
+	reserved := maxTokens
+	if reserved <= 0 {
+		reserved = defaultOutputReservation
+	}
+	reserved = min(reserved, outputLimiter.Burst())
+	if !outputLimiter.AllowN(time.Now(), reserved) {
+		return nil, &OutputRateLimitExceededError{}
+	}
+	return &OutputReservation{limiter: outputLimiter, reserved: reserved, charged: reserved}, nil
+}
+
+// Reserved returns the number of output tokens reserved.
+func (o *OutputReservation) Reserved() int {
+	if o == nil {
+		return 0
+	}
+	return o.reserved
+}
+
+// Settle charges the tokens generated by the request, consuming the tokens exceeding the reservation and returning
+// the ones not used. Only the first settlement of a reservation counts.
+func (o *OutputReservation) Settle(tokens int) {
+	if o == nil {
+		return
+	}
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.settled {
+		return
+	}
+	o.settled = true
+	if tokens != o.charged {
+		o.limiter.SettleN(time.Now(), tokens-o.charged)
+	}
+}
+
+// Charge charges the tokens generated so far by a streamed request exceeding the tokens already charged, before it
+// is settled. The tokens within the reservation are not charged again.
+func (o *OutputReservation) Charge(tokens int) {
+	if o == nil {
+		return
+	}
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.settled || tokens <= o.charged {
+		return
+	}
+	o.limiter.SettleN(time.Now(), tokens-o.charged)
+	o.charged = tokens
+}
+
+// Cancel returns the reserved tokens of a request failing without output, unless it is already settled.
+func (o *OutputReservation) Cancel() {
+	o.Settle(0)
+}
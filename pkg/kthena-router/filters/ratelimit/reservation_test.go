@@ -0,0 +1,159 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	networkingv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+func newOutputRateLimiter(t *testing.T, model string, tokens uint32) *TokenRateLimiter {
+	rl := NewTokenRateLimiter()
+	require.NoError(t, rl.AddOrUpdateLimiter(model, &networkingv1alpha1.RateLimit{
+		OutputTokensPerUnit: &tokens,
+		Unit:                networkingv1alpha1.Hour,
+	}))
+	return rl
+}
+
+func TestReserveOutputTokens_ConcurrentStreams(t *testing.T) {
+	model := "test-model"
+	rl := newOutputRateLimiter(t, model, 1000)
+
+	// 50 concurrent streams of up to 100 tokens: only the ones fitting in the limit are admitted.
+	var admitted atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reservation, err := rl.ReserveOutputTokens(model, 100)
+			if err != nil {
+				assert.IsType(t, &OutputRateLimitExceededError{}, err)
+				return
+			}
+			admitted.Add(1)
+			reservation.Settle(100)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(10), admitted.Load())
+	assert.Less(t, rl.outputLimiter[model].Tokens(), 1.0)
+}
+
+func TestReserveOutputTokens_Settle(t *testing.T) {
+	model := "test-model"
+	rl := newOutputRateLimiter(t, model, 1000)
+	limiter := rl.outputLimiter[model]
+
+	// The tokens not used are returned.
+	reservation, err := rl.ReserveOutputTokens(model, 400)
+	require.NoError(t, err)
+	assert.Equal(t, 400, reservation.Reserved())
+	assert.InDelta(t, 600, limiter.Tokens(), 1)
+	reservation.Settle(100)
+	assert.InDelta(t, 900, limiter.Tokens(), 1)
+	// Only the first settlement counts.
+	reservation.Settle(400)
+	reservation.Cancel()
+	assert.InDelta(t, 900, limiter.Tokens(), 1)
+
+	// A failed request returns its reservation.
+	reservation, err = rl.ReserveOutputTokens(model, 0)
+	require.NoError(t, err)
+	assert.Equal(t, defaultOutputReservation, reservation.Reserved())
+	reservation.Cancel()
+	assert.InDelta(t, 900, limiter.Tokens(), 1)
+
+	// The tokens generated beyond the reservation leave the limiter in debt, rejecting the next requests.
+	reservation, err = rl.ReserveOutputTokens(model, 800)
+	require.NoError(t, err)
+	reservation.Settle(1500)
+	assert.InDelta(t, -600, limiter.Tokens(), 1)
+	_, err = rl.ReserveOutputTokens(model, 1)
+	assert.IsType(t, &OutputRateLimitExceededError{}, err)
+	assert.IsType(t, &OutputRateLimitExceededError{}, rl.RateLimit(model, "hello"))
+}
+
+func TestReserveOutputTokens_CappedAtLimit(t *testing.T) {
+	model := "test-model"
+	rl := newOutputRateLimiter(t, model, 100)
+	reservation, err := rl.ReserveOutputTokens(model, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, 100, reservation.Reserved())
+}
+
+func TestReserveOutputTokens_NoLimit(t *testing.T) {
+	rl := NewTokenRateLimiter()
+	reservation, err := rl.ReserveOutputTokens("unknown-model", 100)
+	assert.NoError(t, err)
+	assert.Nil(t, reservation)
+	// A nil reservation is a no-op.
+	reservation.Settle(100)
+	reservation.Cancel()
+}
+
+func TestLocalLimiter_SettleN(t *testing.T) {
+	limiter := NewLocalLimiter(100, 100)
+	now := time.Now()
+	limiter.SettleN(now, 150)
+	assert.False(t, limiter.AllowN(now, 1))
+	// The debt is refilled at the limit rate.
+	assert.False(t, limiter.AllowN(now.Add(time.Second/4), 1))
+	assert.True(t, limiter.AllowN(now.Add(time.Second), 40))
+	// The tokens returned don't exceed the capacity.
+	limiter.SettleN(now.Add(time.Second), -1000)
+	assert.False(t, limiter.AllowN(now.Add(time.Second), 101))
+	assert.True(t, limiter.AllowN(now.Add(time.Second), 100))
+}
+
+func TestOutputReservation_Charge(t *testing.T) {
+	model := "test-model"
+	rl := newOutputRateLimiter(t, model, 1000)
+	limiter := rl.outputLimiter[model]
+
+	reservation, err := rl.ReserveOutputTokens(model, 100)
+	require.NoError(t, err)
+	assert.InDelta(t, 900, limiter.Tokens(), 1)
+
+	// The tokens streamed within the reservation are not charged again.
+	reservation.Charge(50)
+	reservation.Charge(100)
+	assert.InDelta(t, 900, limiter.Tokens(), 1)
+
+	// The tokens streamed beyond the reservation are charged as they are generated.
+	reservation.Charge(300)
+	assert.InDelta(t, 700, limiter.Tokens(), 1)
+	reservation.Charge(250)
+	assert.InDelta(t, 700, limiter.Tokens(), 1)
+
+	// The settlement only charges the difference with the tokens already charged.
+	reservation.Settle(280)
+	assert.InDelta(t, 720, limiter.Tokens(), 1)
+	reservation.Charge(500)
+	assert.InDelta(t, 720, limiter.Tokens(), 1)
+
+	var nilReservation *OutputReservation
+	nilReservation.Charge(10)
+}
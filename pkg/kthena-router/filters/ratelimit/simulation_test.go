@@ -0,0 +1,121 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	networkingv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/simulation"
+)
+
+// admission is a request admitted by a limiter during a simulation.
+type admission struct {
+	at   time.Time
+	cost int
+}
+
+// assertWithinLimit asserts that the requests admitted in any window never exceed the limit over the window plus the
+// burst, and that the limiter kept admitting requests up to its limit while the demand was above it.
+func assertWithinLimit(t *testing.T, admitted []admission, limit float64, burst int, saturated time.Duration) {
+	t.Helper()
+	require.NotEmpty(t, admitted)
+	for i := range admitted {
+		sum := 0
+		for j := i; j < len(admitted); j++ {
+			sum += admitted[j].cost
+			allowed := limit*admitted[j].at.Sub(admitted[i].at).Seconds() + float64(burst)
+			if float64(sum) > allowed+1e-6 {
+				t.Fatalf("admitted %d units between %v and %v, more than the %.1f allowed", sum,
+					admitted[i].at.Sub(simulation.Start), admitted[j].at.Sub(simulation.Start), allowed)
+			}
+		}
+	}
+	total := 0
+	for _, a := range admitted {
+		total += a.cost
+	}
+	assert.GreaterOrEqual(t, float64(total), limit*saturated.Seconds(), "the limiter admitted less than its limit")
+}
+
+// overloadTrace sends twice the limit of 10 units per second for a minute, with bursts of requests of various costs.
+var overloadTrace = simulation.Merge(
+	simulation.Steady("steady", 0, 100*time.Millisecond, 600, 2),
+	simulation.Burst("burst", 10*time.Second, 50, 1),
+	simulation.Burst("burst", 30*time.Second, 10, 15),
+	simulation.Steady("large", 0, 7*time.Second, 9, 25),
+)
+
+func TestLocalLimiterSimulation(t *testing.T) {
+	const limit, burst = 10, 30
+	s := simulation.New(time.Second)
+	limiter := NewLocalLimiter(rate.Limit(limit), burst)
+	limiter.last = s.Clock.Now()
+
+	var admitted []admission
+	s.Run(overloadTrace, func(now time.Time, a simulation.Arrival) {
+		if limiter.AllowN(now, a.Cost) {
+			admitted = append(admitted, admission{at: now, cost: a.Cost})
+		}
+	}, nil)
+	assertWithinLimit(t, admitted, limit, burst, overloadTrace.End())
+}
+
+func TestLocalLimiterSimulation_Settle(t *testing.T) {
+	// Each request reserves 8 units and settles the 3 it used once admitted, returning the others.
+	const limit, burst, reserved, used = 10, 20, 8, 3
+	s := simulation.New(time.Second)
+	limiter := NewLocalLimiter(rate.Limit(limit), burst)
+	limiter.last = s.Clock.Now()
+
+	var admitted []admission
+	trace := simulation.Steady("steady", 0, 50*time.Millisecond, 1200, reserved)
+	s.Run(trace, func(now time.Time, a simulation.Arrival) {
+		if limiter.AllowN(now, a.Cost) {
+			limiter.SettleN(now, used-a.Cost)
+			admitted = append(admitted, admission{at: now, cost: used})
+		}
+	}, nil)
+	assertWithinLimit(t, admitted, limit, burst, trace.End())
+}
+
+func TestGlobalRateLimiterSimulation(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	const limit, burst = 10, 3
+	s := simulation.New(time.Second)
+	mr.SetTime(s.Clock.Now())
+	limiter := newTestGlobalRateLimiter(t, mr, "test-model", "input", limit, networkingv1alpha1.Second)
+	limiter.burst = limit * burst
+
+	var admitted []admission
+	s.Run(overloadTrace, func(now time.Time, a simulation.Arrival) {
+		mr.SetTime(now)
+		if limiter.AllowN(now, a.Cost) {
+			admitted = append(admitted, admission{at: now, cost: a.Cost})
+		}
+	}, nil)
+	assertWithinLimit(t, admitted, limit, limit*burst, overloadTrace.End())
+}
@@ -0,0 +1,261 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	networkingv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+// TokenQuotaExceededError is returned when the tokens of a TokenQuota are exhausted for the current period.
+type TokenQuotaExceededError struct {
+	// Quota is the namespaced name of the quota.
+	Quota string
+}
+
+func (e *TokenQuotaExceededError) Error() string {
+	return fmt.Sprintf("token quota %s exhausted for the current period", e.Quota)
+}
+
+// PeriodStart returns the start of the period of the quota containing now: midnight UTC of the day for the day
+// period, of the first day of the month otherwise.
+func PeriodStart(period networkingv1alpha1.RateLimitUnit, now time.Time) time.Time {
+	year, month, day := now.UTC().Date()
+	if period == networkingv1alpha1.Day {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// pendingTokens are the tokens consumed in a period of a quota by the router replica, which are not flushed yet.
+type pendingTokens struct {
+	periodStart time.Time
+	tokens      int64
+}
+
+// TokenQuotaTracker counts the tokens consumed in the TokenQuotas by the requests of the router replica. The tokens
+// consumed by all the replicas are persisted in the status of the quotas, to which each replica adds the tokens it
+// consumed since its last flush. A quota is exhausted once the tokens of its status and the tokens not flushed yet
+// reach its limit, the tokens consumed by the other replicas since their last flush are not counted.
+type TokenQuotaTracker struct {
+	mutex   sync.Mutex
+	pending map[types.NamespacedName]*pendingTokens
+}
+
+// NewTokenQuotaTracker creates a TokenQuotaTracker without tokens consumed.
+func NewTokenQuotaTracker() *TokenQuotaTracker {
+	return &TokenQuotaTracker{pending: make(map[types.NamespacedName]*pendingTokens)}
+}
+
+// Admit charges the input tokens of a request to the TokenQuotas selecting its ModelRoute and its consumer, or
+// returns a TokenQuotaExceededError if one of them is exhausted for the current period. The output tokens are charged
+// as they are generated, a request is admitted as long as some tokens remain. The usage is nil if no quota applies.
+func (t *TokenQuotaTracker) Admit(quotas []*networkingv1alpha1.TokenQuota, modelRoute *networkingv1alpha1.ModelRoute,
+	subject string, inputTokens int) (*TokenQuotaUsage, error) {
+	if t == nil || modelRoute == nil || len(quotas) == 0 {
+		return nil, nil
+	}
+	var selected []*networkingv1alpha1.TokenQuota
+	for _, quota := range quotas {
+		if len(quota.Spec.Consumers) > 0 && !slices.Contains(quota.Spec.Consumers, subject) {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(&quota.Spec.ModelRouteSelector)
+		if err != nil {
+			klog.Errorf("invalid model route selector of token quota %s/%s: %v", quota.Namespace, quota.Name, err)
+			continue
+		}
+		if selector.Matches(labels.Set(modelRoute.Labels)) {
+			selected = append(selected, quota)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	usage := &TokenQuotaUsage{tracker: t, inputTokens: int64(inputTokens)}
+	for _, quota := range selected {
+		name := types.NamespacedName{Namespace: quota.Namespace, Name: quota.Name}
+		if t.consumed(quota, now) >= quota.Spec.Tokens {
+			return nil, &TokenQuotaExceededError{Quota: name.String()}
+		}
+		usage.quotas = append(usage.quotas, tokenQuotaRef{name: name, period: quota.Spec.Period})
+	}
+	usage.add(now, usage.inputTokens)
+	return usage, nil
+}
+
+// consumed returns the tokens consumed in the quota in the current period, the tokens of its status and those not
+// flushed yet. It must be called with the lock held.
+func (t *TokenQuotaTracker) consumed(quota *networkingv1alpha1.TokenQuota, now time.Time) int64 {
+	start := PeriodStart(quota.Spec.Period, now)
+	var consumed int64
+	if quota.Status.PeriodStart != nil && quota.Status.PeriodStart.Time.Equal(start) {
+		consumed = quota.Status.ConsumedTokens
+	}
+	if pending := t.pending[types.NamespacedName{Namespace: quota.Namespace, Name: quota.Name}]; pending != nil && pending.periodStart.Equal(start) {
+		consumed += pending.tokens
+	}
+	return consumed
+}
+
+// Pending returns the tokens of the quota not flushed yet and the start of their period, 0 if there are none.
+func (t *TokenQuotaTracker) Pending(name types.NamespacedName) (time.Time, int64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if pending := t.pending[name]; pending != nil {
+		return pending.periodStart, pending.tokens
+	}
+	return time.Time{}, 0
+}
+
+// Flushed removes the tokens of a period of the quota added to its status from the tokens not flushed yet.
+func (t *TokenQuotaTracker) Flushed(name types.NamespacedName, periodStart time.Time, tokens int64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	pending := t.pending[name]
+	if pending == nil || !pending.periodStart.Equal(periodStart) {
+		return
+	}
+	if pending.tokens -= tokens; pending.tokens == 0 {
+		delete(t.pending, name)
+	}
+}
+
+// Forget drops the tokens not flushed yet of a deleted quota.
+func (t *TokenQuotaTracker) Forget(name types.NamespacedName) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.pending, name)
+}
+
+// UpdateTokenQuotaStatus adds the tokens consumed in a period of the quota to its status, and reports whether the
+// status changed. The status of a previous period is reset, the tokens of a previous period are dropped.
+func UpdateTokenQuotaStatus(quota *networkingv1alpha1.TokenQuota, periodStart time.Time, tokens int64, now time.Time) bool {
+	status := quota.Status.DeepCopy()
+	start := PeriodStart(quota.Spec.Period, now)
+	if status.PeriodStart == nil || !status.PeriodStart.Time.Equal(start) {
+		status.PeriodStart = &metav1.Time{Time: start}
+		status.ConsumedTokens = 0
+	}
+	if periodStart.Equal(start) {
+		status.ConsumedTokens += tokens
+	}
+	status.RemainingTokens = max(quota.Spec.Tokens-status.ConsumedTokens, 0)
+	if status.PeriodStart.Equal(quota.Status.PeriodStart) && status.ConsumedTokens == quota.Status.ConsumedTokens &&
+		status.RemainingTokens == quota.Status.RemainingTokens {
+		return false
+	}
+	quota.Status = *status
+	return true
+}
+
+// tokenQuotaRef is a quota charged by a request.
+type tokenQuotaRef struct {
+	name   types.NamespacedName
+	period networkingv1alpha1.RateLimitUnit
+}
+
+// TokenQuotaUsage charges the tokens of a request to the TokenQuotas it was admitted by.
+type TokenQuotaUsage struct {
+	tracker     *TokenQuotaTracker
+	quotas      []tokenQuotaRef
+	inputTokens int64
+
+	mutex sync.Mutex
+	// charged is the number of output tokens charged.
+	charged int
+	settled bool
+}
+
+// add charges tokens to the quotas in their current period, it must be called with the lock of the tracker held.
+func (u *TokenQuotaUsage) add(now time.Time, tokens int64) {
+	for _, quota := range u.quotas {
+		start := PeriodStart(quota.period, now)
+		pending := u.tracker.pending[quota.name]
+		if pending == nil || !pending.periodStart.Equal(start) {
+			pending = &pendingTokens{periodStart: start}
+			u.tracker.pending[quota.name] = pending
+		}
+		pending.tokens += tokens
+	}
+}
+
+func (u *TokenQuotaUsage) charge(tokens int64) {
+	u.tracker.mutex.Lock()
+	defer u.tracker.mutex.Unlock()
+	u.add(time.Now(), tokens)
+}
+
+// Settle charges the output tokens generated by the request beyond the tokens already charged. Only the first
+// settlement counts.
+func (u *TokenQuotaUsage) Settle(tokens int) {
+	if u == nil {
+		return
+	}
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	if u.settled {
+		return
+	}
+	u.settled = true
+	if tokens > u.charged {
+		u.charge(int64(tokens - u.charged))
+	}
+}
+
+// Charge charges the output tokens generated so far by a streamed request beyond the tokens already charged.
+func (u *TokenQuotaUsage) Charge(tokens int) {
+	if u == nil {
+		return
+	}
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	if u.settled || tokens <= u.charged {
+		return
+	}
+	u.charge(int64(tokens - u.charged))
+	u.charged = tokens
+}
+
+// Cancel returns the input tokens of a request failing without output, unless it is already settled.
+func (u *TokenQuotaUsage) Cancel() {
+	if u == nil {
+		return
+	}
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	if u.settled {
+		return
+	}
+	u.settled = true
+	if u.charged == 0 {
+		u.charge(-u.inputTokens)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	networkingv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+func newTestTokenQuota(name string, tokens int64, consumers ...string) *networkingv1alpha1.TokenQuota {
+	return &networkingv1alpha1.TokenQuota{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: networkingv1alpha1.TokenQuotaSpec{
+			ModelRouteSelector: metav1.LabelSelector{MatchLabels: map[string]string{"team": "search"}},
+			Consumers:          consumers,
+			Tokens:             tokens,
+			Period:             networkingv1alpha1.Month,
+		},
+	}
+}
+
+func TestPeriodStart(t *testing.T) {
+	now := time.Date(2026, 3, 17, 23, 30, 0, 0, time.FixedZone("UTC-2", -2*3600))
+	assert.Equal(t, time.Date(2026, 3, 18, 0, 0, 0, 0, time.UTC), PeriodStart(networkingv1alpha1.Day, now))
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), PeriodStart(networkingv1alpha1.Month, now))
+}
+
+func TestTokenQuotaTracker_Admit(t *testing.T) {
+	tracker := NewTokenQuotaTracker()
+	route := &networkingv1alpha1.ModelRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "llama", Labels: map[string]string{"team": "search"}},
+	}
+	team := newTestTokenQuota("team", 100, "alice")
+	name := types.NamespacedName{Namespace: "default", Name: "team"}
+	quotas := []*networkingv1alpha1.TokenQuota{team}
+
+	// The quota of a team doesn't apply to the other consumers.
+	usage, err := tracker.Admit(quotas, route, "bob", 1000)
+	require.NoError(t, err)
+	assert.Nil(t, usage)
+
+	// The input tokens are charged on admission, the output tokens as they are generated.
+	usage, err = tracker.Admit(quotas, route, "alice", 40)
+	require.NoError(t, err)
+	usage.Charge(10)
+	usage.Settle(30)
+	usage.Settle(50)
+	_, pending := tracker.Pending(name)
+	assert.Equal(t, int64(70), pending)
+
+	// The tokens of a failed request are returned.
+	failed, err := tracker.Admit(quotas, route, "alice", 20)
+	require.NoError(t, err)
+	failed.Cancel()
+	periodStart, pending := tracker.Pending(name)
+	assert.Equal(t, int64(70), pending)
+
+	// The tokens flushed by another replica count, the quota is exhausted once they reach its limit.
+	team.Status = networkingv1alpha1.TokenQuotaStatus{PeriodStart: &metav1.Time{Time: periodStart}, ConsumedTokens: 25}
+	_, err = tracker.Admit(quotas, route, "alice", 10)
+	require.NoError(t, err)
+	_, err = tracker.Admit(quotas, route, "alice", 10)
+	assert.ErrorContains(t, err, "token quota default/team exhausted for the current period")
+
+	// The status of a previous period doesn't count.
+	team.Status.PeriodStart = &metav1.Time{Time: periodStart.AddDate(0, -1, 0)}
+	_, err = tracker.Admit(quotas, route, "alice", 10)
+	require.NoError(t, err)
+
+	tracker.Flushed(name, periodStart, 80)
+	_, pending = tracker.Pending(name)
+	assert.Equal(t, int64(10), pending)
+	tracker.Forget(name)
+	_, pending = tracker.Pending(name)
+	assert.Zero(t, pending)
+}
+
+func TestUpdateTokenQuotaStatus(t *testing.T) {
+	now := time.Date(2026, 3, 17, 12, 0, 0, 0, time.UTC)
+	march := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	quota := newTestTokenQuota("team", 100)
+
+	assert.True(t, UpdateTokenQuotaStatus(quota, march, 30, now))
+	assert.Equal(t, networkingv1alpha1.TokenQuotaStatus{PeriodStart: &metav1.Time{Time: march}, ConsumedTokens: 30, RemainingTokens: 70}, quota.Status)
+	assert.True(t, UpdateTokenQuotaStatus(quota, march, 90, now))
+	assert.Equal(t, int64(120), quota.Status.ConsumedTokens)
+	assert.Equal(t, int64(0), quota.Status.RemainingTokens)
+	assert.False(t, UpdateTokenQuotaStatus(quota, march, 0, now))
+
+	// A new period resets the status, the tokens of the previous one are dropped.
+	april := now.AddDate(0, 1, 0)
+	assert.True(t, UpdateTokenQuotaStatus(quota, march, 10, april))
+	assert.Equal(t, networkingv1alpha1.TokenQuotaStatus{
+		PeriodStart: &metav1.Time{Time: march.AddDate(0, 1, 0)}, ConsumedTokens: 0, RemainingTokens: 100,
+	}, quota.Status)
+}
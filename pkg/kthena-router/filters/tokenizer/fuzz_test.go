@@ -0,0 +1,50 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenizer
+
+import (
+	"testing"
+)
+
+// FuzzCalculateTokenNum feeds arbitrary prompts, including invalid UTF-8, to the tokenizers.
+func FuzzCalculateTokenNum(f *testing.F) {
+	for _, seed := range []string{
+		"hello world",
+		"<|im_start|>user\nhello<|im_end|>\n",
+		"<|endoftext|>",
+		"こんにちは世界 🌍",
+		"\xff\xfe\xfd",
+		"",
+	} {
+		f.Add(seed)
+	}
+	tokenizers := map[string]Tokenizer{
+		"estimate": NewSimpleEstimateTokenizer(),
+		"tiktoken": &TickToken{},
+	}
+	f.Fuzz(func(t *testing.T, prompt string) {
+		for name, tokenizer := range tokenizers {
+			tokens, err := tokenizer.CalculateTokenNum(prompt)
+			if err != nil {
+				t.Fatalf("%s tokenizer failed: %v", name, err)
+			}
+			if tokens < 0 || tokens > len(prompt) {
+				t.Fatalf("%s tokenizer counted %d tokens in %d bytes", name, tokens, len(prompt))
+			}
+		}
+	})
+}
@@ -0,0 +1,160 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenizer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// huggingFaceTokenizer is the part of a tokenizer.json file describing a byte-level BPE tokenizer.
+type huggingFaceTokenizer struct {
+	PreTokenizer *huggingFacePreTokenizer `json:"pre_tokenizer"`
+	Model        struct {
+		Type   string            `json:"type"`
+		Vocab  map[string]int    `json:"vocab"`
+		Merges []json.RawMessage `json:"merges"`
+	} `json:"model"`
+}
+
+type huggingFacePreTokenizer struct {
+	Type          string                    `json:"type"`
+	PreTokenizers []huggingFacePreTokenizer `json:"pretokenizers"`
+	Pattern       struct {
+		Regex string `json:"Regex"`
+	} `json:"pattern"`
+}
+
+// pattern returns the regular expression of the first Split pre-tokenizer, if any.
+func (p *huggingFacePreTokenizer) pattern() string {
+	if p == nil {
+		return ""
+	}
+	if p.Type == "Split" {
+		return p.Pattern.Regex
+	}
+	for i := range p.PreTokenizers {
+		if pattern := p.PreTokenizers[i].pattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return ""
+}
+
+// NewHuggingFaceTokenizer creates the tokenizer of the tokenizer.json file at path of a HuggingFace byte-level BPE
+// tokenizer. The prompts are split by pattern, or by the pattern of the Split pre-tokenizer of the file if it is empty,
+// or by the GPT-2 pattern if the file has none.
+func NewHuggingFaceTokenizer(path, pattern string) (*BPETokenizer, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file huggingFaceTokenizer
+	if err := json.Unmarshal(contents, &file); err != nil {
+		return nil, fmt.Errorf("invalid tokenizer file %s: %v", path, err)
+	}
+	if file.Model.Type != "BPE" {
+		return nil, fmt.Errorf("tokenizer file %s has a %s model, only BPE models are supported", path, file.Model.Type)
+	}
+	ranks, err := huggingFaceRanks(file.Model.Vocab, file.Model.Merges)
+	if err != nil {
+		return nil, fmt.Errorf("tokenizer file %s: %v", path, err)
+	}
+	if pattern == "" {
+		pattern = file.PreTokenizer.pattern()
+	}
+	if pattern == "" {
+		pattern = gpt2Pattern
+	}
+	return newBPETokenizer(ranks, pattern)
+}
+
+// huggingFaceRanks returns the ranks of the bytes of the tokens of a byte-level BPE vocabulary: the merged tokens are
+// ranked in the order of their merges, so that they are merged in the same order as by the HuggingFace tokenizer, or
+// in the order of their ids if the file has no merges.
+func huggingFaceRanks(vocab map[string]int, merges []json.RawMessage) (map[string]int, error) {
+	decode := func(token string) (string, error) {
+		var decoded strings.Builder
+		for _, r := range token {
+			b, ok := byteLevelDecoder[r]
+			if !ok {
+				return "", fmt.Errorf("token %q is not byte-level encoded", token)
+			}
+			decoded.WriteByte(b)
+		}
+		return decoded.String(), nil
+	}
+
+	ranks := make(map[string]int, len(vocab))
+	if len(merges) == 0 {
+		for token, id := range vocab {
+			decoded, err := decode(token)
+			if err != nil {
+				return nil, err
+			}
+			if rank, ok := ranks[decoded]; !ok || id < rank {
+				ranks[decoded] = id
+			}
+		}
+		return ranks, nil
+	}
+
+	// Only the pairs of the merges are ranked, so the single bytes only need distinct ranks below them.
+	for b := 0; b < 256; b++ {
+		ranks[string([]byte{byte(b)})] = b
+	}
+	for i, raw := range merges {
+		// The merges are "left right" strings, or ["left", "right"] pairs since tokenizers 0.20.
+		var pair []string
+		var merge string
+		if err := json.Unmarshal(raw, &merge); err == nil {
+			left, right, ok := strings.Cut(merge, " ")
+			if !ok {
+				return nil, fmt.Errorf("invalid merge %q", merge)
+			}
+			pair = []string{left, right}
+		} else if err := json.Unmarshal(raw, &pair); err != nil || len(pair) != 2 {
+			return nil, fmt.Errorf("invalid merge %s", string(raw))
+		}
+		decoded, err := decode(pair[0] + pair[1])
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := ranks[decoded]; !ok {
+			ranks[decoded] = 256 + i
+		}
+	}
+	return ranks, nil
+}
+
+// byteLevelDecoder maps the characters of the byte-level encoded tokens to their bytes: the printable bytes are
+// encoded as themselves, and the others as the characters from U+0100 on, as by the GPT-2 tokenizer.
+var byteLevelDecoder = func() map[rune]byte {
+	decoder := make(map[rune]byte, 256)
+	next := rune(256)
+	for b := 0; b < 256; b++ {
+		if ('!' <= b && b <= '~') || ('¡' <= b && b <= '¬') || ('®' <= b && b <= 'ÿ') {
+			decoder[rune(b)] = byte(b)
+			continue
+		}
+		decoder[next] = byte(b)
+		next++
+	}
+	return decoder
+}()
@@ -0,0 +1,111 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenizer
+
+import (
+	"encoding/base64"
+	"image"
+	// The sizes of the GIF, JPEG and PNG images of the data URLs are decoded.
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"strings"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+// defaultImageSide is the side of the images whose size isn't known, e.g. the images given by URL.
+const defaultImageSide = 1024
+
+// ImageTokens counts the tokens of the images of the chat messages of each model with the rule configured for it, and
+// with the openai rule for the other models.
+type ImageTokens struct {
+	rules []conf.ImageTokensConfig
+}
+
+// NewImageTokens returns the counter of the image tokens of the rules.
+func NewImageTokens(rules []conf.ImageTokensConfig) *ImageTokens {
+	return &ImageTokens{rules: rules}
+}
+
+// CalculateImageTokens returns the number of tokens of the images of a request to a model.
+func (t *ImageTokens) CalculateImageTokens(model string, images []common.Image) int {
+	if len(images) == 0 {
+		return 0
+	}
+	rule := conf.ImageTokensConfig{Rule: "openai"}
+	for _, r := range t.rules {
+		if modelMatches(r.Models, model) {
+			rule = r
+			break
+		}
+	}
+	tokens := 0
+	for _, img := range images {
+		tokens += imageTokens(rule, img)
+	}
+	return tokens
+}
+
+func imageTokens(rule conf.ImageTokensConfig, img common.Image) int {
+	switch rule.Rule {
+	case "fixed":
+		return rule.TokensPerImage
+	case "anthropic":
+		width, height := imageSize(img.URL)
+		width, height = fitWithin(width, height, 1568)
+		return max(1, (width*height+749)/750)
+	default:
+		if img.Detail == "low" {
+			return 85
+		}
+		width, height := imageSize(img.URL)
+		width, height = fitWithin(width, height, 2048)
+		if shortest := min(width, height); shortest > 768 {
+			width, height = width*768/shortest, height*768/shortest
+		}
+		tiles := ((width + 511) / 512) * ((height + 511) / 512)
+		return 85 + 170*tiles
+	}
+}
+
+// imageSize returns the size of the image of a data URL, or the default size if it isn't known.
+func imageSize(url string) (int, int) {
+	data, ok := strings.CutPrefix(url, "data:")
+	if !ok {
+		return defaultImageSide, defaultImageSide
+	}
+	_, encoded, ok := strings.Cut(data, ";base64,")
+	if !ok {
+		return defaultImageSide, defaultImageSide
+	}
+	// Only the header of the image is decoded.
+	config, _, err := image.DecodeConfig(base64.NewDecoder(base64.StdEncoding, strings.NewReader(encoded)))
+	if err != nil || config.Width <= 0 || config.Height <= 0 {
+		return defaultImageSide, defaultImageSide
+	}
+	return config.Width, config.Height
+}
+
+// fitWithin scales an image down, keeping its aspect ratio, so that its longest side is at most side.
+func fitWithin(width, height, side int) (int, int) {
+	if longest := max(width, height); longest > side {
+		return max(1, width*side/longest), max(1, height*side/longest)
+	}
+	return width, height
+}
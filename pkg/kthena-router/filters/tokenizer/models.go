@@ -0,0 +1,93 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenizer
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+// ModelTokenizers counts the tokens of the prompts of each model with the tokenizer configured for it, and estimates
+// the tokens of the prompts of the other models.
+type ModelTokenizers struct {
+	tokenizers []modelTokenizer
+	estimator  Tokenizer
+}
+
+type modelTokenizer struct {
+	models    []string
+	tokenizer Tokenizer
+}
+
+// NewModelTokenizers loads the tokenizers of the configuration, and fails if one of their files can't be loaded.
+func NewModelTokenizers(configs []conf.TokenizerConfig) (*ModelTokenizers, error) {
+	t := &ModelTokenizers{estimator: NewSimpleEstimateTokenizer()}
+	for i, config := range configs {
+		var tokenizer Tokenizer
+		var err error
+		switch config.Type {
+		case "tiktoken":
+			tokenizer, err = NewTiktokenTokenizer(config.Encoding, config.Path, config.Pattern)
+		case "huggingface":
+			tokenizer, err = NewHuggingFaceTokenizer(config.Path, config.Pattern)
+		default:
+			err = fmt.Errorf("unknown tokenizer type %s", config.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tokenizer %d of models %v: %v", i, config.Models, err)
+		}
+		t.tokenizers = append(t.tokenizers, modelTokenizer{models: config.Models, tokenizer: tokenizer})
+	}
+	return t, nil
+}
+
+// Tokenizer returns the tokenizer of a model: the first tokenizer configured for it, or the estimator.
+func (t *ModelTokenizers) Tokenizer(model string) Tokenizer {
+	for _, tokenizer := range t.tokenizers {
+		if modelMatches(tokenizer.models, model) {
+			return tokenizer.tokenizer
+		}
+	}
+	return t.estimator
+}
+
+func modelMatches(models []string, model string) bool {
+	for _, name := range models {
+		if prefix, ok := strings.CutSuffix(name, "*"); ok {
+			if strings.HasPrefix(model, prefix) {
+				return true
+			}
+		} else if name == model {
+			return true
+		}
+	}
+	return false
+}
+
+// CalculateTokenNum counts the tokens of a prompt of a model, falling back to their estimate if its tokenizer fails.
+func (t *ModelTokenizers) CalculateTokenNum(model, prompt string) int {
+	tokens, err := t.Tokenizer(model).CalculateTokenNum(prompt)
+	if err != nil {
+		klog.Errorf("failed to calculate token number of model %s: %v", model, err)
+		tokens, _ = t.estimator.CalculateTokenNum(prompt)
+	}
+	return tokens
+}
@@ -17,6 +17,12 @@ limitations under the License.
 package tokenizer
 
 import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
 	"github.com/pkoukk/tiktoken-go"
 	tiktokenloader "github.com/pkoukk/tiktoken-go-loader"
 )
@@ -33,3 +39,92 @@ func (t *TickToken) CalculateTokenNum(prompt string) (int, error) {
 	}
 	return len(encoding.Encode(prompt, nil, nil)), nil
 }
+
+const (
+	// gpt2Pattern is the pattern splitting the prompts of the GPT-2 byte-level BPE tokenizers, used by p50k_base and
+	// r50k_base.
+	gpt2Pattern = `'s|'t|'re|'ve|'m|'ll|'d| ?\p{L}+| ?\p{N}+| ?[^\s\p{L}\p{N}]+|\s+(?!\S)|\s+`
+	// cl100kPattern is the pattern splitting the prompts of cl100k_base, also used by Llama 3.
+	cl100kPattern = `(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+(?!\S)|\s+`
+)
+
+// encodingPatterns are the patterns of the tiktoken encodings bundled with the router.
+var encodingPatterns = map[string]string{
+	"cl100k_base": cl100kPattern,
+	"p50k_base":   gpt2Pattern,
+	"r50k_base":   gpt2Pattern,
+}
+
+// BPETokenizer counts the tokens of the prompts encoded by a byte-level BPE. Special tokens in the prompts are
+// encoded as ordinary text.
+type BPETokenizer struct {
+	encoding *tiktoken.Tiktoken
+}
+
+// NewTiktokenTokenizer creates the tokenizer of the tiktoken encoding bundled with the router, or of the .tiktoken
+// file at path if the encoding is empty. The prompts are split by pattern, or by the pattern of the encoding if it is
+// empty.
+func NewTiktokenTokenizer(encoding, path, pattern string) (*BPETokenizer, error) {
+	var ranks map[string]int
+	var err error
+	if encoding != "" {
+		encodingPattern, ok := encodingPatterns[encoding]
+		if !ok {
+			return nil, fmt.Errorf("unknown tiktoken encoding %s", encoding)
+		}
+		if pattern == "" {
+			pattern = encodingPattern
+		}
+		ranks, err = tiktokenloader.NewOfflineLoader().LoadTiktokenBpe(encoding + ".tiktoken")
+	} else {
+		if pattern == "" {
+			pattern = cl100kPattern
+		}
+		ranks, err = loadTiktokenFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newBPETokenizer(ranks, pattern)
+}
+
+func newBPETokenizer(ranks map[string]int, pattern string) (*BPETokenizer, error) {
+	bpe, err := tiktoken.NewCoreBPE(ranks, map[string]int{}, pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &BPETokenizer{encoding: tiktoken.NewTiktoken(bpe, nil, map[string]any{})}, nil
+}
+
+func (t *BPETokenizer) CalculateTokenNum(prompt string) (int, error) {
+	return len(t.encoding.EncodeOrdinary(prompt)), nil
+}
+
+// loadTiktokenFile loads the ranks of the tokens of a .tiktoken file, whose lines are the base64 encoded bytes of a
+// token and its rank.
+func loadTiktokenFile(path string) (map[string]int, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ranks := make(map[string]int)
+	for i, line := range strings.Split(string(contents), "\n") {
+		if line == "" {
+			continue
+		}
+		token, rank, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected a token and its rank", path, i+1)
+		}
+		tokenBytes, err := base64.StdEncoding.DecodeString(token)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid token: %v", path, i+1, err)
+		}
+		rankValue, err := strconv.Atoi(strings.TrimSpace(rank))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid rank: %v", path, i+1, err)
+		}
+		ranks[string(tokenBytes)] = rankValue
+	}
+	return ranks, nil
+}
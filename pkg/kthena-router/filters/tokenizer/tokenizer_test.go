@@ -0,0 +1,151 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenizer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+// testHuggingFaceTokenizer is a byte-level BPE merging "hello" and " w", whose space is encoded as "Ġ".
+const testHuggingFaceTokenizer = `{
+  "pre_tokenizer": {"type": "ByteLevel", "add_prefix_space": false, "use_regex": true},
+  "model": {
+    "type": "BPE",
+    "vocab": {"h": 0, "e": 1, "l": 2, "o": 3, "w": 4, "r": 5, "d": 6, "Ġ": 7, "he": 8, "ll": 9, "hell": 10, "hello": 11, "Ġw": 12},
+    "merges": ["h e", "l l", "he ll", ["hell", "o"], "Ġ w"]
+  }
+}`
+
+func writeFile(t *testing.T, name, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func TestTiktokenTokenizer(t *testing.T) {
+	encoding, err := NewTiktokenTokenizer("cl100k_base", "", "")
+	require.NoError(t, err)
+	tokens, err := encoding.CalculateTokenNum("hello world")
+	require.NoError(t, err)
+	assert.Equal(t, 2, tokens)
+
+	// "aGVsbG8=" is "hello" and "IHdvcmxk" is " world"
+	path := writeFile(t, "tokenizer.model", "aA== 0\nZQ== 1\nbA== 2\nbw== 3\nIA== 4\ndw== 5\ncg== 6\nZA== 7\naGVsbG8= 8\nIHdvcmxk 9\n")
+	file, err := NewTiktokenTokenizer("", path, "")
+	require.NoError(t, err)
+	tokens, err = file.CalculateTokenNum("hello world")
+	require.NoError(t, err)
+	assert.Equal(t, 2, tokens)
+	// " hello" isn't a token of the file, so it is encoded byte by byte
+	tokens, err = file.CalculateTokenNum("world hello")
+	require.NoError(t, err)
+	assert.Equal(t, 11, tokens)
+
+	_, err = NewTiktokenTokenizer("o200k_base", "", "")
+	assert.Error(t, err)
+	_, err = NewTiktokenTokenizer("", writeFile(t, "invalid.tiktoken", "aGVsbG8=\n"), "")
+	assert.Error(t, err)
+}
+
+func TestHuggingFaceTokenizer(t *testing.T) {
+	tokenizer, err := NewHuggingFaceTokenizer(writeFile(t, "tokenizer.json", testHuggingFaceTokenizer), "")
+	require.NoError(t, err)
+	tokens, err := tokenizer.CalculateTokenNum("hello world")
+	require.NoError(t, err)
+	// "hello", " w", "o", "r", "l", "d"
+	assert.Equal(t, 6, tokens)
+
+	// Splitting the prompt on each character prevents the merges
+	tokenizer, err = NewHuggingFaceTokenizer(writeFile(t, "tokenizer.json", testHuggingFaceTokenizer), ".")
+	require.NoError(t, err)
+	tokens, err = tokenizer.CalculateTokenNum("hello world")
+	require.NoError(t, err)
+	assert.Equal(t, 11, tokens)
+
+	_, err = NewHuggingFaceTokenizer(writeFile(t, "tokenizer.json", `{"model": {"type": "Unigram"}}`), "")
+	assert.ErrorContains(t, err, "only BPE models are supported")
+	_, err = NewHuggingFaceTokenizer(writeFile(t, "tokenizer.json", `{"model": {"type": "BPE", "vocab": {"▁hello": 0}}}`), "")
+	assert.ErrorContains(t, err, "is not byte-level encoded")
+}
+
+func TestModelTokenizers(t *testing.T) {
+	tokenizers, err := NewModelTokenizers([]conf.TokenizerConfig{
+		{Models: []string{"gpt-4*"}, Type: "tiktoken", Encoding: "cl100k_base"},
+		{Models: []string{"tiny"}, Type: "huggingface", Path: writeFile(t, "tokenizer.json", testHuggingFaceTokenizer)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, tokenizers.CalculateTokenNum("gpt-4o", "hello world"))
+	assert.Equal(t, 6, tokenizers.CalculateTokenNum("tiny", "hello world"))
+	// The prompts of the other models are estimated
+	assert.Equal(t, 3, tokenizers.CalculateTokenNum("llama", "hello world"))
+
+	_, err = NewModelTokenizers([]conf.TokenizerConfig{
+		{Models: []string{"llama"}, Type: "huggingface", Path: filepath.Join(t.TempDir(), "missing.json")},
+	})
+	assert.Error(t, err)
+}
+
+func pngDataURL(t *testing.T, width, height int) string {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))))
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestImageTokens(t *testing.T) {
+	wide := common.Image{URL: pngDataURL(t, 4096, 1024)}
+	remote := common.Image{URL: "https://example.com/cat.jpg"}
+	imageTokens := NewImageTokens([]conf.ImageTokensConfig{
+		{Models: []string{"claude-*"}, Rule: "anthropic"},
+		{Models: []string{"llava"}, Rule: "fixed", TokensPerImage: 576},
+	})
+
+	tests := []struct {
+		name     string
+		model    string
+		images   []common.Image
+		expected int
+	}{
+		// 2048x512 once scaled, 4 tiles
+		{name: "openai rule by default", model: "gpt-4o", images: []common.Image{wide}, expected: 765},
+		{name: "openai low detail", model: "gpt-4o", images: []common.Image{{URL: wide.URL, Detail: "low"}}, expected: 85},
+		// 768x768 once scaled, 4 tiles
+		{name: "openai image of unknown size", model: "gpt-4o", images: []common.Image{remote}, expected: 765},
+		// 1568x392 once scaled
+		{name: "anthropic rule", model: "claude-3", images: []common.Image{wide}, expected: 820},
+		{name: "anthropic image of unknown size", model: "claude-3", images: []common.Image{remote}, expected: 1399},
+		{name: "fixed rule", model: "llava", images: []common.Image{wide, remote}, expected: 1152},
+		{name: "invalid data URL", model: "llava", images: []common.Image{{URL: "data:image/png;base64,!!"}}, expected: 576},
+		{name: "no image", model: "gpt-4o"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, imageTokens.CalculateImageTokens(tt.model, tt.images))
+		})
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gatewayapi holds the typed clients of the Gateway API and Gateway API Inference Extension resources and the
+// helpers reading and writing their references, shared by the router, its controllers and the e2e tests.
+package gatewayapi
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	inferencev1 "sigs.k8s.io/gateway-api-inference-extension/api/v1"
+	inferenceclientset "sigs.k8s.io/gateway-api-inference-extension/client-go/clientset/versioned"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayclientset "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
+)
+
+// InferencePoolKind is the kind of the InferencePool backends of the routes.
+const InferencePoolKind = "InferencePool"
+
+// Clients are the typed clients of the Gateway API and Gateway API Inference Extension resources.
+type Clients struct {
+	Gateway   gatewayclientset.Interface
+	Inference inferenceclientset.Interface
+}
+
+// NewClients creates the clients of the cluster of the config.
+func NewClients(config *rest.Config) (*Clients, error) {
+	gatewayClient, err := gatewayclientset.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gateway API client: %w", err)
+	}
+	inferenceClient, err := inferenceclientset.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gateway API Inference Extension client: %w", err)
+	}
+	return &Clients{Gateway: gatewayClient, Inference: inferenceClient}, nil
+}
+
+// InferencePoolBackend returns the name of the InferencePool a backend of a route in the namespace references, false
+// if the backend is not an InferencePool.
+func InferencePoolBackend(namespace string, backendRef gatewayv1.BackendRef) (types.NamespacedName, bool) {
+	if backendRef.Group == nil || *backendRef.Group != inferencev1.GroupName ||
+		backendRef.Kind == nil || *backendRef.Kind != InferencePoolKind {
+		return types.NamespacedName{}, false
+	}
+	if backendRef.Namespace != nil {
+		namespace = string(*backendRef.Namespace)
+	}
+	return types.NamespacedName{Namespace: namespace, Name: string(backendRef.Name)}, true
+}
+
+// InferencePoolBackendRef returns the backend of a route referencing the InferencePool of the name in the namespace
+// of the route.
+func InferencePoolBackendRef(name string) gatewayv1.BackendRef {
+	group := gatewayv1.Group(inferencev1.GroupName)
+	kind := gatewayv1.Kind(InferencePoolKind)
+	return gatewayv1.BackendRef{
+		BackendObjectReference: gatewayv1.BackendObjectReference{
+			Group: &group,
+			Kind:  &kind,
+			Name:  gatewayv1.ObjectName(name),
+		},
+	}
+}
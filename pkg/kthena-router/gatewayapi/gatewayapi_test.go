@@ -0,0 +1,45 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gatewayapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestInferencePoolBackend(t *testing.T) {
+	ref := InferencePoolBackendRef("pool")
+	name, ok := InferencePoolBackend("default", ref)
+	assert.True(t, ok)
+	assert.Equal(t, types.NamespacedName{Namespace: "default", Name: "pool"}, name)
+
+	namespace := gatewayv1.Namespace("models")
+	ref.Namespace = &namespace
+	name, ok = InferencePoolBackend("default", ref)
+	assert.True(t, ok)
+	assert.Equal(t, types.NamespacedName{Namespace: "models", Name: "pool"}, name)
+
+	service := gatewayv1.Kind("Service")
+	ref.Kind = &service
+	_, ok = InferencePoolBackend("default", ref)
+	assert.False(t, ok)
+	_, ok = InferencePoolBackend("default", gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{Name: "pool"}})
+	assert.False(t, ok)
+}
@@ -0,0 +1,75 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+)
+
+var streamSeeds = []string{
+	"data: {\"id\":\"1\",\"choices\":[{\"delta\":{\"content\":\"Hi\"}}]}\n\n",
+	"data: {\"id\":\"1\",\"choices\":[],\"usage\":{\"prompt_tokens\":7,\"total_tokens\":17,\"completion_tokens\":10}}\n\ndata: [DONE]\n\n",
+	"data: {\"usage\":{\"completion_tokens\":\"10\"}}\n",
+	"data: {\"usage\":null}\n",
+	": keep-alive\n\n",
+	"event: error\ndata: {\"error\":{\"message\":\"overloaded\"}}\n\n",
+	"data: {\"id\":",
+	"data: [DONE]",
+	"\n\n\r\n",
+	"",
+}
+
+// FuzzParseStreamRespForUsage feeds arbitrary lines of the streamed responses to the parsing of their usage.
+func FuzzParseStreamRespForUsage(f *testing.F) {
+	for _, seed := range streamSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, line string) {
+		_ = ParseStreamRespForUsage(line)
+	})
+}
+
+// FuzzForwardStream checks that arbitrary streamed responses are forwarded unchanged, whatever their line breaks.
+func FuzzForwardStream(f *testing.F) {
+	for _, seed := range streamSeeds {
+		f.Add([]byte(seed), 16)
+	}
+	f.Fuzz(func(t *testing.T, body []byte, bufferSize int) {
+		c, w := newStreamContext(context.Background())
+		var lines int
+		err := ForwardStream(c, io.NopCloser(bytes.NewReader(body)), StreamOptions{
+			BufferSize: bufferSize % 64,
+			OnLine: func(line []byte) []byte {
+				lines++
+				_ = ParseStreamRespForUsage(string(line))
+				return line
+			},
+		})
+		if err != nil {
+			t.Fatalf("failed to forward the stream: %v", err)
+		}
+		if !bytes.Equal(w.Body.Bytes(), body) {
+			t.Fatalf("forwarded %q instead of %q", w.Body.Bytes(), body)
+		}
+		if expected := bytes.Count(body, []byte("\n")); lines < expected || lines > expected+1 {
+			t.Fatalf("%d lines of %q forwarded", lines, body)
+		}
+	})
+}
@@ -0,0 +1,188 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+// HeaderPolicyKey is the gin context key of the *HeaderPolicy applied to the request.
+const HeaderPolicyKey = "headerPolicy"
+
+// alwaysPropagatedHeaders describe the bodies, they are never filtered.
+var alwaysPropagatedHeaders = []string{"Content-Type", "Content-Length", "Content-Encoding", "Transfer-Encoding"}
+
+// defaultDeniedRequestHeaders carry the credentials of the clients to the router,
+// they are not forwarded to the model servers unless explicitly allowed.
+var defaultDeniedRequestHeaders = []string{"Authorization"}
+
+// HeaderPolicy filters the headers propagated between the clients and the model servers.
+type HeaderPolicy struct {
+	request  headerFilter
+	response headerFilter
+
+	requestModifier  *gatewayv1.HTTPHeaderFilter
+	responseModifier *gatewayv1.HTTPHeaderFilter
+}
+
+// headerFilter matches lower-cased header names, a pattern ending with `*` matches the names starting with it.
+type headerFilter struct {
+	allow []string
+	deny  []string
+}
+
+// NewHeaderPolicy returns the HeaderPolicy of a ModelRoute, policy may be nil.
+func NewHeaderPolicy(policy *v1alpha1.HeaderPolicy) *HeaderPolicy {
+	p := &HeaderPolicy{}
+	if policy != nil {
+		p.request = newHeaderFilter(policy.Request)
+		p.response = newHeaderFilter(policy.Response)
+		p.requestModifier = policy.RequestHeaderModifier
+		p.responseModifier = policy.ResponseHeaderModifier
+	}
+	for _, name := range defaultDeniedRequestHeaders {
+		name = strings.ToLower(name)
+		explicitlyAllowed := false
+		for _, pattern := range p.request.allow {
+			explicitlyAllowed = explicitlyAllowed || pattern == name
+		}
+		if !explicitlyAllowed {
+			p.request.deny = append(p.request.deny, name)
+		}
+	}
+	return p
+}
+
+func newHeaderFilter(filter *v1alpha1.HeaderFilter) headerFilter {
+	var f headerFilter
+	if filter == nil {
+		return f
+	}
+	for _, name := range filter.Allow {
+		f.allow = append(f.allow, strings.ToLower(name))
+	}
+	for _, name := range filter.Deny {
+		f.deny = append(f.deny, strings.ToLower(name))
+	}
+	return f
+}
+
+func matchHeader(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if pattern == name {
+			return true
+		}
+	}
+	return false
+}
+
+// allowed reports whether the header is propagated.
+func (f headerFilter) allowed(name string) bool {
+	for _, header := range alwaysPropagatedHeaders {
+		if strings.EqualFold(header, name) {
+			return true
+		}
+	}
+	name = strings.ToLower(name)
+	if matchHeader(f.deny, name) {
+		return false
+	}
+	return len(f.allow) == 0 || matchHeader(f.allow, name)
+}
+
+// FilterRequest removes the headers not forwarded to the model servers.
+func (p *HeaderPolicy) FilterRequest(header http.Header) {
+	for name := range header {
+		if !p.request.allowed(name) {
+			header.Del(name)
+		}
+	}
+}
+
+// AllowResponse reports whether the model server response header is returned to the client.
+func (p *HeaderPolicy) AllowResponse(name string) bool {
+	return p.response.allowed(name)
+}
+
+// ModifyRequest sets, adds and removes the headers of the request forwarded to the model servers.
+func (p *HeaderPolicy) ModifyRequest(header http.Header) {
+	modifyHeaders(header, p.requestModifier)
+}
+
+// ModifyResponse sets, adds and removes the headers of the response returned to the client.
+func (p *HeaderPolicy) ModifyResponse(header http.Header) {
+	modifyHeaders(header, p.responseModifier)
+}
+
+// modifyHeaders applies the modifier like the header modifier filters of the Gateway API HTTPRoutes: Set overwrites
+// the values of the headers, Add appends to them and Remove deletes the headers, the names are case-insensitive.
+func modifyHeaders(header http.Header, modifier *gatewayv1.HTTPHeaderFilter) {
+	if modifier == nil {
+		return
+	}
+	for _, h := range modifier.Set {
+		header.Set(string(h.Name), h.Value)
+	}
+	for _, h := range modifier.Add {
+		header.Add(string(h.Name), h.Value)
+	}
+	for _, name := range modifier.Remove {
+		header.Del(name)
+	}
+}
+
+// ApplyHeaderPolicy filters and modifies the headers of the request with the policy of its ModelRoute, and keeps the
+// policy for the headers of the response. modelRoute is nil if the request doesn't match a ModelRoute.
+func ApplyHeaderPolicy(c *gin.Context, modelRoute *v1alpha1.ModelRoute) {
+	var policy *v1alpha1.HeaderPolicy
+	if modelRoute != nil {
+		policy = modelRoute.Spec.HeaderPolicy
+	}
+	p := NewHeaderPolicy(policy)
+	p.FilterRequest(c.Request.Header)
+	p.ModifyRequest(c.Request.Header)
+	c.Set(HeaderPolicyKey, p)
+}
+
+// CopyResponseHeaders copies the headers of the model server response allowed by the header policy of the request,
+// then modifies them with the policy.
+func CopyResponseHeaders(c *gin.Context, header http.Header) {
+	value, _ := c.Get(HeaderPolicyKey)
+	policy, _ := value.(*HeaderPolicy)
+	for k, vv := range header {
+		if policy != nil && !policy.AllowResponse(k) {
+			continue
+		}
+		for _, v := range vv {
+			c.Header(k, v)
+		}
+	}
+	if policy != nil {
+		policy.ModifyResponse(c.Writer.Header())
+	}
+	GetServerTiming(c).setHeader(c, strings.HasPrefix(header.Get("Content-Type"), "text/event-stream"))
+}
@@ -0,0 +1,151 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+func TestHeaderPolicy_FilterRequest(t *testing.T) {
+	newHeader := func() http.Header {
+		header := http.Header{}
+		for _, name := range []string{"Authorization", "Content-Type", "X-Tenant-Id", "X-Tenant-Region", "X-Debug", "Cookie"} {
+			header.Set(name, "value")
+		}
+		return header
+	}
+	names := func(header http.Header) []string {
+		var names []string
+		for name := range header {
+			names = append(names, name)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name   string
+		policy *v1alpha1.HeaderPolicy
+		want   []string
+	}{
+		{
+			name: "no policy",
+			want: []string{"Content-Type", "X-Tenant-Id", "X-Tenant-Region", "X-Debug", "Cookie"},
+		},
+		{
+			name:   "deny",
+			policy: &v1alpha1.HeaderPolicy{Request: &v1alpha1.HeaderFilter{Deny: []string{"cookie", "x-debug"}}},
+			want:   []string{"Content-Type", "X-Tenant-Id", "X-Tenant-Region"},
+		},
+		{
+			name:   "allow prefix",
+			policy: &v1alpha1.HeaderPolicy{Request: &v1alpha1.HeaderFilter{Allow: []string{"X-Tenant-*"}}},
+			want:   []string{"Content-Type", "X-Tenant-Id", "X-Tenant-Region"},
+		},
+		{
+			name:   "prefix doesn't allow Authorization",
+			policy: &v1alpha1.HeaderPolicy{Request: &v1alpha1.HeaderFilter{Allow: []string{"*"}}},
+			want:   []string{"Content-Type", "X-Tenant-Id", "X-Tenant-Region", "X-Debug", "Cookie"},
+		},
+		{
+			name: "deny takes precedence",
+			policy: &v1alpha1.HeaderPolicy{Request: &v1alpha1.HeaderFilter{
+				Allow: []string{"Authorization", "X-Tenant-*"},
+				Deny:  []string{"X-Tenant-Region", "Content-Type"},
+			}},
+			want: []string{"Authorization", "Content-Type", "X-Tenant-Id"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := newHeader()
+			NewHeaderPolicy(tt.policy).FilterRequest(header)
+			assert.ElementsMatch(t, tt.want, names(header))
+		})
+	}
+}
+
+func TestHeaderPolicy_AllowResponse(t *testing.T) {
+	policy := NewHeaderPolicy(nil)
+	assert.True(t, policy.AllowResponse("X-Backend-Version"))
+
+	policy = NewHeaderPolicy(&v1alpha1.HeaderPolicy{Response: &v1alpha1.HeaderFilter{Allow: []string{"x-request-id"}}})
+	assert.True(t, policy.AllowResponse("X-Request-Id"))
+	assert.True(t, policy.AllowResponse("Content-Length"))
+	assert.False(t, policy.AllowResponse("X-Backend-Version"))
+	// The Authorization header is only denied by default in requests.
+	assert.False(t, policy.AllowResponse("Authorization"))
+	assert.True(t, NewHeaderPolicy(nil).AllowResponse("Authorization"))
+}
+
+func TestHeaderPolicy_ModifyHeaders(t *testing.T) {
+	modifier := &gatewayv1.HTTPHeaderFilter{
+		Set:    []gatewayv1.HTTPHeader{{Name: "x-tenant-id", Value: "tenant-b"}},
+		Add:    []gatewayv1.HTTPHeader{{Name: "X-Route", Value: "canary"}},
+		Remove: []string{"x-debug"},
+	}
+	newHeader := func() http.Header {
+		return http.Header{
+			"X-Tenant-Id": {"tenant-a"},
+			"X-Route":     {"default"},
+			"X-Debug":     {"true"},
+		}
+	}
+	want := http.Header{
+		"X-Tenant-Id": {"tenant-b"},
+		"X-Route":     {"default", "canary"},
+	}
+
+	header := newHeader()
+	NewHeaderPolicy(&v1alpha1.HeaderPolicy{RequestHeaderModifier: modifier}).ModifyRequest(header)
+	assert.Equal(t, want, header)
+
+	header = newHeader()
+	NewHeaderPolicy(&v1alpha1.HeaderPolicy{ResponseHeaderModifier: modifier}).ModifyResponse(header)
+	assert.Equal(t, want, header)
+
+	header = newHeader()
+	NewHeaderPolicy(nil).ModifyRequest(header)
+	assert.Equal(t, newHeader(), header)
+}
+
+func TestApplyHeaderPolicy_InjectsDeniedHeader(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	c.Request.Header.Set("Authorization", "Bearer client-token")
+	modelRoute := &v1alpha1.ModelRoute{Spec: v1alpha1.ModelRouteSpec{HeaderPolicy: &v1alpha1.HeaderPolicy{
+		RequestHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+			Set: []gatewayv1.HTTPHeader{{Name: "Authorization", Value: "Bearer backend-token"}},
+		},
+		ResponseHeaderModifier: &gatewayv1.HTTPHeaderFilter{Remove: []string{"X-Internal-Node"}},
+	}}}
+
+	// The client credentials are not forwarded, the ones of the model servers are injected.
+	ApplyHeaderPolicy(c, modelRoute)
+	assert.Equal(t, "Bearer backend-token", c.Request.Header.Get("Authorization"))
+
+	CopyResponseHeaders(c, http.Header{"X-Internal-Node": {"node-1"}, "X-Request-Id": {"id"}})
+	assert.Empty(t, c.Writer.Header().Get("X-Internal-Node"))
+	assert.Equal(t, "id", c.Writer.Header().Get("X-Request-Id"))
+}
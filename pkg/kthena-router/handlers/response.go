@@ -32,11 +32,29 @@ type Usage struct {
 
 // Define a struct to represent the OpenAI response body
 type OpenAIResponse struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Created int64  `json:"created"`
-	Model   string `json:"model"`
-	Usage   Usage  `json:"usage"`
+	ID      string   `json:"id"`
+	Object  string   `json:"object"`
+	Created int64    `json:"created"`
+	Model   string   `json:"model"`
+	Choices []Choice `json:"choices"`
+	Usage   Usage    `json:"usage"`
+}
+
+// Choice is a choice of a response, or the part of it generated by a chunk of a stream.
+type Choice struct {
+	// Text is the content of a completion.
+	Text string `json:"text"`
+	// Delta is the content of a chat completion chunk.
+	Delta struct {
+		Content          string          `json:"content"`
+		ReasoningContent string          `json:"reasoning_content"`
+		ToolCalls        json.RawMessage `json:"tool_calls"`
+	} `json:"delta"`
+}
+
+// generated reports whether the choice of a stream chunk carries generated content.
+func (c *Choice) generated() bool {
+	return c.Text != "" || c.Delta.Content != "" || c.Delta.ReasoningContent != "" || len(c.Delta.ToolCalls) > 0
 }
 
 // Function to parse the OpenAI response body
@@ -84,3 +102,27 @@ func ParseStreamRespForUsage(
 
 	return response
 }
+
+// OpenAIError is the error body returned by OpenAI compatible APIs
+type OpenAIError struct {
+	Error OpenAIErrorDetail `json:"error"`
+}
+
+type OpenAIErrorDetail struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Param   string `json:"param,omitempty"`
+	Code    string `json:"code,omitempty"`
+}
+
+// NewInvalidRequestError builds an OpenAI style error for a request rejected because of its content
+func NewInvalidRequestError(message, param, code string) OpenAIError {
+	return OpenAIError{
+		Error: OpenAIErrorDetail{
+			Message: message,
+			Type:    "invalid_request_error",
+			Param:   param,
+			Code:    code,
+		},
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServerTimingKey is the context key of the *ServerTiming of a request whose phases are reported to the client.
+const ServerTimingKey = "serverTiming"
+
+const serverTimingHeader = "Server-Timing"
+
+// ServerTiming times the phases of a request, reported to the client in Server-Timing headers so that it can see
+// where its latency goes: routing, queueing and prefill in the headers of the response, and the decode of a
+// non-streamed response too. The time to the first token and the streaming of a streamed response, only known once
+// it is complete, are reported in a Server-Timing trailer.
+type ServerTiming struct {
+	mutex         sync.Mutex
+	start         time.Time
+	queue         time.Duration
+	upstreamStart time.Time
+	prefill       time.Duration
+	decodeStart   time.Time
+	firstToken    time.Time
+}
+
+// StartServerTiming starts timing the phases of the request of c.
+func StartServerTiming(c *gin.Context) *ServerTiming {
+	timing := &ServerTiming{start: time.Now()}
+	c.Set(ServerTimingKey, timing)
+	return timing
+}
+
+// GetServerTiming returns the timing of the phases of the request of c, nil if they are not reported.
+func GetServerTiming(c *gin.Context) *ServerTiming {
+	if value, ok := c.Get(ServerTimingKey); ok {
+		return value.(*ServerTiming)
+	}
+	return nil
+}
+
+// Queued adds the time the request waited in a queue.
+func (t *ServerTiming) Queued(wait time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.queue += wait
+}
+
+// UpstreamStarted marks the end of the routing of the request, when it is first sent upstream.
+func (t *ServerTiming) UpstreamStarted() {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.upstreamStart.IsZero() {
+		t.upstreamStart = time.Now()
+		t.decodeStart = t.upstreamStart
+	}
+}
+
+// PrefillDone marks the end of the prefill of a PD disaggregated request, and the start of its decode.
+func (t *ServerTiming) PrefillDone() {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.decodeStart = time.Now()
+	if !t.upstreamStart.IsZero() {
+		t.prefill = t.decodeStart.Sub(t.upstreamStart)
+	}
+}
+
+// firstEvent marks the first event of a streamed response.
+func (t *ServerTiming) firstEvent() {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.firstToken.IsZero() {
+		t.firstToken = time.Now()
+	}
+}
+
+// setHeader sets the Server-Timing header of the response, before it is written.
+func (t *ServerTiming) setHeader(c *gin.Context, stream bool) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	now := time.Now()
+	upstreamStart := t.upstreamStart
+	if upstreamStart.IsZero() {
+		upstreamStart = now
+	}
+	metrics := []string{
+		serverTimingMetric("routing", upstreamStart.Sub(t.start)-t.queue),
+		serverTimingMetric("queue", t.queue),
+	}
+	if t.prefill > 0 {
+		metrics = append(metrics, serverTimingMetric("prefill", t.prefill))
+	}
+	if !stream && !t.decodeStart.IsZero() {
+		metrics = append(metrics, serverTimingMetric("decode", now.Sub(t.decodeStart)))
+	}
+	c.Writer.Header().Set(serverTimingHeader, strings.Join(metrics, ", "))
+}
+
+// setTrailer sets the Server-Timing trailer of a streamed response.
+func (t *ServerTiming) setTrailer(c *gin.Context) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.firstToken.IsZero() || t.decodeStart.IsZero() {
+		return
+	}
+	metrics := []string{
+		serverTimingMetric("ttft", t.firstToken.Sub(t.decodeStart)),
+		serverTimingMetric("stream", time.Since(t.firstToken)),
+	}
+	c.Writer.Header().Set(http.TrailerPrefix+serverTimingHeader, strings.Join(metrics, ", "))
+}
+
+// serverTimingMetric formats the duration of a phase as a Server-Timing metric, in milliseconds.
+func serverTimingMetric(name string, duration time.Duration) string {
+	return fmt.Sprintf("%s;dur=%.1f", name, float64(max(duration, 0).Microseconds())/1000)
+}
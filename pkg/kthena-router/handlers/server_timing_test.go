@@ -0,0 +1,70 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerTiming(t *testing.T) {
+	c, w := newStreamContext(context.Background())
+	timing := StartServerTiming(c)
+	assert.Same(t, timing, GetServerTiming(c))
+	timing.Queued(20 * time.Millisecond)
+	timing.UpstreamStarted()
+	timing.PrefillDone()
+
+	CopyResponseHeaders(c, http.Header{"Content-Type": []string{"application/json"}})
+	c.Writer.WriteHeaderNow()
+	metrics := w.Header().Get("Server-Timing")
+	assert.Regexp(t, `^routing;dur=\d+\.\d, queue;dur=20\.0, prefill;dur=\d+\.\d, decode;dur=\d+\.\d$`, metrics)
+}
+
+func TestServerTiming_Stream(t *testing.T) {
+	c, w := newStreamContext(context.Background())
+	timing := StartServerTiming(c)
+	timing.UpstreamStarted()
+
+	CopyResponseHeaders(c, http.Header{"Content-Type": []string{"text/event-stream"}})
+	body := io.NopCloser(strings.NewReader("data: {\"id\":\"1\"}\n\ndata: [DONE]\n\n"))
+	require.NoError(t, ForwardStream(c, body, StreamOptions{}))
+
+	result := w.Result()
+	// The decode of a stream is reported in its trailer.
+	assert.Regexp(t, `^routing;dur=\d+\.\d, queue;dur=0\.0$`, result.Header.Get("Server-Timing"))
+	assert.Regexp(t, `^ttft;dur=\d+\.\d, stream;dur=\d+\.\d$`, result.Trailer.Get("Server-Timing"))
+}
+
+func TestServerTiming_Disabled(t *testing.T) {
+	c, w := newStreamContext(context.Background())
+	assert.Nil(t, GetServerTiming(c))
+	GetServerTiming(c).Queued(time.Second)
+	GetServerTiming(c).UpstreamStarted()
+
+	CopyResponseHeaders(c, http.Header{"Content-Type": []string{"text/event-stream"}})
+	require.NoError(t, ForwardStream(c, io.NopCloser(strings.NewReader("data: [DONE]\n\n")), StreamOptions{}))
+	assert.Empty(t, w.Result().Header.Get("Server-Timing"))
+	assert.Empty(t, w.Result().Trailer)
+}
@@ -0,0 +1,159 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultStreamHeartbeatInterval is how long a stream may stay idle before a heartbeat is sent downstream.
+	DefaultStreamHeartbeatInterval = 15 * time.Second
+
+	defaultStreamBufferSize = 4096
+)
+
+// sseHeartbeat is an SSE comment line, ignored by clients but keeping idle connections
+// (and the proxies in front of them) from timing out.
+var sseHeartbeat = []byte(": keep-alive\n\n")
+
+// ErrClientGone is returned when the downstream client disconnected before the response completed.
+var ErrClientGone = errors.New("client disconnected")
+
+// streamHeartbeatInterval is the heartbeat interval of streamed responses, 0 disables heartbeats.
+var streamHeartbeatInterval = DefaultStreamHeartbeatInterval
+
+// SetStreamHeartbeatInterval sets the idle interval after which heartbeats are sent on streamed responses.
+// A non-positive interval disables heartbeats.
+func SetStreamHeartbeatInterval(interval time.Duration) {
+	if interval < 0 {
+		interval = 0
+	}
+	streamHeartbeatInterval = interval
+}
+
+// StreamOptions configures how an upstream event stream is forwarded downstream.
+type StreamOptions struct {
+	// BufferSize is the buffer size used to read the upstream stream.
+	BufferSize int
+	// OnLine is called with every line read from upstream before it is forwarded.
+	// The returned bytes are forwarded instead of the line, nil drops the line.
+	OnLine func(line []byte) []byte
+	// HeartbeatAfterFirstEvent holds the heartbeats until the first event is forwarded, so that the response is not
+	// sent downstream while the request can still be retried on another upstream, e.g. after a first token timeout.
+	HeartbeatAfterFirstEvent bool
+}
+
+type streamChunk struct {
+	line []byte
+	err  error
+}
+
+// ForwardStream relays the upstream event stream body to the client line by line.
+// Heartbeats are written between events while the upstream is idle, and the upstream body is
+// closed as soon as the client disconnects, so that the model server aborts the generation.
+// ErrClientGone is returned in the latter case.
+func ForwardStream(c *gin.Context, body io.ReadCloser, opts StreamOptions) error {
+	bufferSize := opts.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = defaultStreamBufferSize
+	}
+	timing := GetServerTiming(c)
+	defer timing.setTrailer(c)
+
+	chunks := make(chan streamChunk)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		reader := bufio.NewReaderSize(body, bufferSize)
+		for {
+			line, err := reader.ReadBytes('\n')
+			select {
+			case chunks <- streamChunk{line: line, err: err}:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var heartbeat <-chan time.Time
+	interval := streamHeartbeatInterval
+	var timer *time.Timer
+	if interval > 0 {
+		timer = time.NewTimer(interval)
+		defer timer.Stop()
+		heartbeat = timer.C
+	}
+
+	// atBoundary reports whether the last forwarded line terminated an event,
+	// heartbeats must not be interleaved with the lines of an event.
+	atBoundary := !opts.HeartbeatAfterFirstEvent
+	clientGone := c.Request.Context().Done()
+	for {
+		select {
+		case <-clientGone:
+			_ = body.Close()
+			return ErrClientGone
+		case <-heartbeat:
+			if atBoundary {
+				if _, err := c.Writer.Write(sseHeartbeat); err != nil {
+					_ = body.Close()
+					return ErrClientGone
+				}
+				c.Writer.Flush()
+			}
+			timer.Reset(interval)
+		case chunk := <-chunks:
+			out := chunk.line
+			if len(out) > 0 && opts.OnLine != nil {
+				out = opts.OnLine(out)
+			}
+			if len(out) > 0 {
+				timing.firstEvent()
+				if _, err := c.Writer.Write(out); err != nil {
+					_ = body.Close()
+					return ErrClientGone
+				}
+				c.Writer.Flush()
+				atBoundary = len(bytes.TrimSpace(chunk.line)) == 0
+				if timer != nil {
+					timer.Reset(interval)
+				}
+			}
+			if chunk.err != nil {
+				if chunk.err == io.EOF {
+					return nil
+				}
+				if c.Request.Context().Err() != nil {
+					return ErrClientGone
+				}
+				klog.Errorf("error reading stream body: %v", chunk.err)
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStreamContext(ctx context.Context) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequestWithContext(ctx, http.MethodPost, "/v1/chat/completions", nil)
+	return c, w
+}
+
+// closeTracker records whether the upstream body was closed.
+type closeTracker struct {
+	io.Reader
+	closed chan struct{}
+}
+
+func (b *closeTracker) Close() error {
+	close(b.closed)
+	return nil
+}
+
+func TestForwardStream(t *testing.T) {
+	c, w := newStreamContext(context.Background())
+	body := io.NopCloser(strings.NewReader("data: {\"id\":\"1\"}\n\ndata: {\"usage\":{}}\n\ndata: [DONE]\n\n"))
+
+	err := ForwardStream(c, body, StreamOptions{
+		OnLine: func(line []byte) []byte {
+			if strings.Contains(string(line), "usage") {
+				return nil
+			}
+			return line
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "data: {\"id\":\"1\"}\n\n\ndata: [DONE]\n\n", w.Body.String())
+}
+
+func TestForwardStream_Heartbeat(t *testing.T) {
+	defer SetStreamHeartbeatInterval(DefaultStreamHeartbeatInterval)
+	SetStreamHeartbeatInterval(20 * time.Millisecond)
+
+	pr, pw := io.Pipe()
+	c, w := newStreamContext(context.Background())
+	go func() {
+		_, _ = pw.Write([]byte("data: {\"id\":\"1\"}\n"))
+		_, _ = pw.Write([]byte("\n"))
+		time.Sleep(100 * time.Millisecond)
+		_, _ = pw.Write([]byte("data: [DONE]\n\n"))
+		pw.Close()
+	}()
+
+	require.NoError(t, ForwardStream(c, pr, StreamOptions{}))
+	out := w.Body.String()
+	assert.True(t, strings.HasPrefix(out, "data: {\"id\":\"1\"}\n\n: keep-alive\n\n"), out)
+	assert.True(t, strings.HasSuffix(out, "data: [DONE]\n\n"), out)
+}
+
+func TestForwardStream_HeartbeatAfterFirstEvent(t *testing.T) {
+	defer SetStreamHeartbeatInterval(DefaultStreamHeartbeatInterval)
+	SetStreamHeartbeatInterval(20 * time.Millisecond)
+
+	pr, pw := io.Pipe()
+	c, w := newStreamContext(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = pw.Write([]byte("data: {\"id\":\"1\"}\n\n"))
+		time.Sleep(100 * time.Millisecond)
+		_, _ = pw.Write([]byte("data: [DONE]\n\n"))
+		pw.Close()
+	}()
+
+	require.NoError(t, ForwardStream(c, pr, StreamOptions{HeartbeatAfterFirstEvent: true}))
+	out := w.Body.String()
+	assert.True(t, strings.HasPrefix(out, "data: {\"id\":\"1\"}\n\n: keep-alive\n\n"), out)
+	assert.True(t, strings.HasSuffix(out, "data: [DONE]\n\n"), out)
+}
+
+func TestForwardStream_ClientGone(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	body := &closeTracker{Reader: pr, closed: make(chan struct{})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c, _ := newStreamContext(ctx)
+	go func() {
+		_, _ = pw.Write([]byte("data: {\"id\":\"1\"}\n\n"))
+		cancel()
+	}()
+
+	err := ForwardStream(c, body, StreamOptions{})
+	assert.ErrorIs(t, err, ErrClientGone)
+	select {
+	case <-body.closed:
+	case <-time.After(time.Second):
+		t.Fatal("upstream body was not closed after the client disconnected")
+	}
+}
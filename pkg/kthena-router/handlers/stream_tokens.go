@@ -0,0 +1,77 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+)
+
+// StreamTokenCounter counts the output tokens of a streamed response as its chunks are forwarded, one per choice
+// generating content in a chunk, until its final usage frame reports them. The tokens counted are charged to the rate
+// limits of the request as they are streamed, so that a stream interrupted before its usage frame, or whose engine
+// doesn't report it, can't generate more tokens than it is charged.
+type StreamTokenCounter struct {
+	charge func(tokens int)
+	tokens int
+	usage  bool
+}
+
+// NewStreamTokenCounter returns a counter charging the tokens of the stream of c to the function stored under
+// common.OutputTokensChargeKey, if any.
+func NewStreamTokenCounter(c *gin.Context) *StreamTokenCounter {
+	counter := &StreamTokenCounter{}
+	if value, ok := c.Get(common.OutputTokensChargeKey); ok {
+		counter.charge, _ = value.(func(tokens int))
+	}
+	return counter
+}
+
+// Observe counts the tokens of a chunk of the stream. The usage frame takes over the tokens counted.
+func (s *StreamTokenCounter) Observe(chunk OpenAIResponse) {
+	if chunk.Usage.CompletionTokens > 0 {
+		s.usage = true
+		return
+	}
+	if s.usage {
+		return
+	}
+	generated := 0
+	for i := range chunk.Choices {
+		if chunk.Choices[i].generated() {
+			generated++
+		}
+	}
+	if generated == 0 {
+		return
+	}
+	s.tokens += generated
+	if s.charge != nil {
+		s.charge(s.tokens)
+	}
+}
+
+// Tokens returns the number of tokens counted from the chunks of the stream.
+func (s *StreamTokenCounter) Tokens() int {
+	return s.tokens
+}
+
+// UsageReported reports whether the stream reported its usage.
+func (s *StreamTokenCounter) UsageReported() bool {
+	return s.usage
+}
@@ -0,0 +1,60 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+)
+
+func TestStreamTokenCounter(t *testing.T) {
+	c, _ := newStreamContext(context.Background())
+	var charged []int
+	c.Set(common.OutputTokensChargeKey, func(tokens int) { charged = append(charged, tokens) })
+	counter := NewStreamTokenCounter(c)
+
+	lines := []string{
+		`data: {"choices":[{"index":0,"delta":{"role":"assistant"}}]}`,
+		`data: {"choices":[{"index":0,"delta":{"content":"Hello"}}]}`,
+		`data: {"choices":[{"index":0,"delta":{"reasoning_content":"thinking"}},{"index":1,"delta":{"content":"Hi"}}]}`,
+		`data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0}]}}]}`,
+		`data: {"choices":[{"index":0,"text":" world"}]}`,
+	}
+	for _, line := range lines {
+		counter.Observe(ParseStreamRespForUsage(line))
+	}
+	assert.Equal(t, 5, counter.Tokens())
+	assert.Equal(t, []int{1, 3, 4, 5}, charged)
+	assert.False(t, counter.UsageReported())
+
+	// The usage frame takes over the tokens counted.
+	counter.Observe(ParseStreamRespForUsage(`data: {"choices":[],"usage":{"prompt_tokens":3,"completion_tokens":6,"total_tokens":9}}`))
+	counter.Observe(ParseStreamRespForUsage(`data: {"choices":[{"index":0,"text":"late"}]}`))
+	assert.True(t, counter.UsageReported())
+	assert.Equal(t, 5, counter.Tokens())
+	assert.Equal(t, []int{1, 3, 4, 5}, charged)
+
+	// A request without rate limits is only counted.
+	c, _ = newStreamContext(context.Background())
+	counter = NewStreamTokenCounter(c)
+	counter.Observe(ParseStreamRespForUsage(`data: {"choices":[{"index":0,"text":"Hello"}]}`))
+	assert.Equal(t, 1, counter.Tokens())
+}
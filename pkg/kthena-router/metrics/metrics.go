@@ -36,6 +36,23 @@ const (
 	LabelModelRoute  = "model_route"
 	LabelModelServer = "model_server"
 	LabelUserID      = "user_id"
+	LabelBackend     = "backend"
+	LabelRating      = "rating"
+	LabelExperiment  = "experiment"
+	LabelObjective   = "objective"
+	LabelWindow      = "window"
+	LabelTimeout     = "timeout"
+	LabelPriority    = "priority"
+	LabelReason      = "reason"
+	LabelResult      = "result"
+	LabelLevel       = "level"
+	LabelPolicy      = "policy"
+	LabelSignal      = "signal"
+	LabelPod         = "pod"
+	LabelLimit       = "limit"
+	LabelQuota       = "quota"
+	LabelConsumer    = "consumer"
+	LabelRule        = "rule"
 
 	// Token type values
 	TokenTypeInput  = "input"
@@ -49,6 +66,15 @@ const (
 	LimitTypeInputTokens  = "input_tokens"
 	LimitTypeOutputTokens = "output_tokens"
 	LimitTypeRequests     = "requests"
+	LimitTypeImages       = "images"
+	LimitTypeMegapixels   = "megapixels"
+	LimitTypeCost         = "cost"
+	LimitTypeItems        = "items"
+	LimitTypeBytes        = "bytes"
+
+	// Stream result values
+	StreamResultCompleted = "completed"
+	StreamResultAbandoned = "abandoned"
 )
 
 // Metrics holds all Prometheus metrics for the kthena-router
@@ -56,6 +82,10 @@ type Metrics struct {
 	// Request counters
 	RequestsTotal prometheus.CounterVec
 
+	// Requests sent to the model servers of the ModelRoutes, the canaries of the rollouts are analyzed with them
+	ModelServerRequestsTotal   prometheus.CounterVec
+	ModelServerRequestDuration prometheus.HistogramVec
+
 	// Request duration histograms
 	RequestDuration        prometheus.HistogramVec
 	RequestPrefillDuration prometheus.HistogramVec
@@ -64,6 +94,16 @@ type Metrics struct {
 	// Token metrics
 	TokensTotal prometheus.CounterVec
 
+	// Duration of the audio transcribed or translated
+	AudioSecondsTotal prometheus.CounterVec
+
+	// Images generated and their megapixels
+	ImagesTotal          prometheus.CounterVec
+	ImageMegapixelsTotal prometheus.CounterVec
+
+	// Documents reranked and inputs classified
+	DocumentsTotal prometheus.CounterVec
+
 	// Scheduler plugin duration metrics
 	SchedulerPluginDuration prometheus.HistogramVec
 
@@ -75,6 +115,98 @@ type Metrics struct {
 	ActiveUpstreamRequests   prometheus.GaugeVec
 	FairnessQueueSize        prometheus.GaugeVec
 	FairnessQueueDuration    prometheus.HistogramVec
+
+	// Usage event export metrics
+	EventsExported prometheus.CounterVec
+	EventsDropped  prometheus.CounterVec
+
+	// Result quality feedback metrics
+	FeedbackTotal prometheus.CounterVec
+	FeedbackScore prometheus.HistogramVec
+
+	// Generations aborted because the client disconnected
+	CanceledGenerations prometheus.CounterVec
+
+	// Requests retried on the fallback ModelServers of their ModelRoute
+	FallbackRequests prometheus.CounterVec
+
+	// Requests exceeding the rate limit of their ModelRoute served by its degradation ModelServer
+	DegradedRequests prometheus.CounterVec
+
+	// Requests or attempts aborted by a timeout of their ModelRoute
+	RequestTimeouts prometheus.CounterVec
+
+	// PD-disaggregated generations resumed on another prefill/decode pair after their decode stream broke
+	DecodeResumptions prometheus.CounterVec
+
+	// Requests opted in an experiment
+	ExperimentRequests prometheus.CounterVec
+
+	// Requests queued while the router handles its maximum number of concurrent requests
+	QueueLength     prometheus.GaugeVec
+	QueueDuration   prometheus.HistogramVec
+	QueueRejections prometheus.CounterVec
+
+	// Self-protection of the router against the request bodies and the memory exhausting it
+	MemoryInUse          prometheus.Gauge
+	MemoryWatermark      prometheus.Gauge
+	BufferedBodyBytes    prometheus.Gauge
+	ProtectionRejections prometheus.CounterVec
+
+	// Requests in flight per ModelRoute and per model server pod, and the requests rejected by their limits
+	RouteInflightRequests      prometheus.GaugeVec
+	PodInflightRequests        prometheus.GaugeVec
+	ConcurrencyLimitRejections prometheus.CounterVec
+
+	// Shedding of the batch requests while the router pod is under memory or CPU pressure
+	PodPressure          prometheus.GaugeVec
+	LoadShedding         prometheus.Gauge
+	LoadSheddingEpisodes prometheus.CounterVec
+
+	// Requests rejected by the quotas of their consumer, team or org
+	QuotaExceeded prometheus.CounterVec
+
+	// Requests rejected by the limits of their caller at a RateLimitPolicy
+	RateLimitPolicyExceeded prometheus.CounterVec
+
+	// Requests rejected by an exhausted TokenQuota
+	TokenQuotaExceeded prometheus.CounterVec
+
+	// Streamed responses of each authenticated consumer, and the anomalies flagged by the rules on their streams
+	ConsumerStreams            prometheus.CounterVec
+	ConsumerStreamDuration     prometheus.HistogramVec
+	ConsumerStreamOutputTokens prometheus.HistogramVec
+	StreamAnomalies            prometheus.CounterVec
+
+	// Lookups of the semantic response cache
+	SemanticCacheLookups prometheus.CounterVec
+
+	// Requests mirrored to the candidate ModelServer of their ModelRoute
+	MirroredRequests prometheus.CounterVec
+
+	// Responses shadowed to the evaluation sink of their ModelRoute and the scores it returned, the canaries of the
+	// rollouts are analyzed with them
+	EvaluatedRequests prometheus.CounterVec
+	EvaluationScore   prometheus.HistogramVec
+
+	// Requests hedged on a second pod of their model server
+	HedgedRequests prometheus.CounterVec
+
+	// Requests retried on another pod of their model server after failing before their response started
+	RetriedRequests prometheus.CounterVec
+
+	// Responses of the requests asking for structured output which are not valid against their response_format
+	InvalidStructuredOutputs prometheus.CounterVec
+
+	// PD-disaggregated prefills coalesced with the prefill of a request sharing their prompt prefix
+	CoalescedPrefills prometheus.CounterVec
+
+	// Warm-up requests sent to the new pods of model servers
+	PodWarmUpDuration prometheus.HistogramVec
+
+	// Service level objectives declared on ModelRoutes and the burn rates of their error budgets
+	SLOTarget   prometheus.GaugeVec
+	SLOBurnRate prometheus.GaugeVec
 }
 
 // NewMetrics creates a new Metrics instance with all Prometheus metrics registered
@@ -88,6 +220,23 @@ func NewMetrics() *Metrics {
 			[]string{LabelModel, LabelPath, LabelStatusCode, LabelErrorType},
 		),
 
+		ModelServerRequestsTotal: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_model_server_requests_total",
+				Help: "Total number of requests sent to a model server through a ModelRoute",
+			},
+			[]string{LabelModelServer, LabelModelRoute, LabelStatusCode},
+		),
+
+		ModelServerRequestDuration: *promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "kthena_router_model_server_request_duration_seconds",
+				Help:    "End-to-end latency distribution of the requests sent to a model server through a ModelRoute",
+				Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+			},
+			[]string{LabelModelServer, LabelModelRoute},
+		),
+
 		RequestDuration: *promauto.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Name:    "kthena_router_request_duration_seconds",
@@ -123,6 +272,38 @@ func NewMetrics() *Metrics {
 			[]string{LabelModel, LabelPath, LabelTokenType},
 		),
 
+		AudioSecondsTotal: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_audio_seconds_total",
+				Help: "Total duration in seconds of the audio transcribed or translated",
+			},
+			[]string{LabelModel, LabelPath},
+		),
+
+		ImagesTotal: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_images_total",
+				Help: "Total images generated",
+			},
+			[]string{LabelModel, LabelPath},
+		),
+
+		ImageMegapixelsTotal: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_image_megapixels_total",
+				Help: "Total megapixels of the images generated",
+			},
+			[]string{LabelModel, LabelPath},
+		),
+
+		DocumentsTotal: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_documents_total",
+				Help: "Total documents reranked, inputs classified and embedded",
+			},
+			[]string{LabelModel, LabelPath},
+		),
+
 		SchedulerPluginDuration: *promauto.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Name:    "kthena_router_scheduler_plugin_duration_seconds",
@@ -172,6 +353,336 @@ func NewMetrics() *Metrics {
 			},
 			[]string{LabelModel, LabelUserID},
 		),
+
+		EventsExported: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_events_exported_total",
+				Help: "Number of usage events published to the message broker",
+			},
+			[]string{LabelBackend},
+		),
+
+		EventsDropped: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_events_dropped_total",
+				Help: "Number of usage events dropped because the export queue was full",
+			},
+			[]string{LabelBackend},
+		),
+
+		FeedbackTotal: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_feedback_total",
+				Help: "Number of positive and negative ratings reported by clients per model and model server",
+			},
+			[]string{LabelModel, LabelModelServer, LabelRating},
+		),
+
+		FeedbackScore: *promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "kthena_router_feedback_score",
+				Help:    "Distribution of the quality scores reported by clients per model and model server",
+				Buckets: []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1},
+			},
+			[]string{LabelModel, LabelModelServer},
+		),
+
+		CanceledGenerations: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_canceled_generations_total",
+				Help: "Number of generations aborted on the model server because the client disconnected",
+			},
+			[]string{LabelModel, LabelModelServer},
+		),
+
+		FallbackRequests: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_fallback_requests_total",
+				Help: "Number of requests retried on a fallback model server after the previous one failed",
+			},
+			[]string{LabelModel, LabelModelServer},
+		),
+
+		DegradedRequests: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_degraded_requests_total",
+				Help: "Number of requests exceeding the rate limit of their model route served by its degradation model server instead of being rejected",
+			},
+			[]string{LabelModel, LabelModelServer, LabelLimitType},
+		),
+
+		RequestTimeouts: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_request_timeouts_total",
+				Help: "Number of requests or attempts on a pod aborted by a connect, first token or request timeout of their model route",
+			},
+			[]string{LabelModel, LabelModelServer, LabelTimeout},
+		),
+
+		DecodeResumptions: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_decode_resumptions_total",
+				Help: "Number of PD-disaggregated generations resumed on another prefill/decode pair after their decode stream broke",
+			},
+			[]string{LabelModel, LabelModelServer},
+		),
+
+		ExperimentRequests: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_experiment_requests_total",
+				Help: "Number of requests scheduled with an experimental configuration per model and experiment",
+			},
+			[]string{LabelModel, LabelExperiment},
+		),
+
+		QueueLength: *promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "kthena_router_queue_length",
+				Help: "Current number of requests queued while the router handles its maximum number of concurrent requests",
+			},
+			[]string{LabelPriority},
+		),
+
+		QueueDuration: *promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "kthena_router_queue_duration_seconds",
+				Help:    "Time requests spend queued before being handled",
+				Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+			},
+			[]string{LabelPriority},
+		),
+
+		QueueRejections: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_queue_rejections_total",
+				Help: "Number of requests rejected because the queue was full or they timed out in the queue",
+			},
+			[]string{LabelPriority, LabelReason},
+		),
+
+		MemoryInUse: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "kthena_router_memory_in_use_bytes",
+				Help: "Memory used by the router, sampled when admitting requests",
+			},
+		),
+
+		MemoryWatermark: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "kthena_router_memory_watermark_bytes",
+				Help: "Memory used by the router from which new requests are rejected, 0 if there is no watermark",
+			},
+		),
+
+		BufferedBodyBytes: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "kthena_router_buffered_body_bytes",
+				Help: "Total size of the request bodies held by the router",
+			},
+		),
+
+		ProtectionRejections: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_protection_rejections_total",
+				Help: "Number of requests rejected to protect the router, by memory watermark, buffered body limit or load shedding",
+			},
+			[]string{LabelReason},
+		),
+
+		RouteInflightRequests: *promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "kthena_router_route_inflight_requests",
+				Help: "Number of requests of a ModelRoute in flight",
+			},
+			[]string{LabelModelRoute},
+		),
+
+		PodInflightRequests: *promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "kthena_router_pod_inflight_requests",
+				Help: "Number of requests in flight on a model server pod",
+			},
+			[]string{LabelModelServer, LabelPod},
+		),
+
+		ConcurrencyLimitRejections: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_concurrency_limit_rejections_total",
+				Help: "Number of requests rejected by the concurrency limit of their ModelRoute or of the pods of its model servers",
+			},
+			[]string{LabelModelRoute, LabelLimit},
+		),
+
+		PodPressure: *promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "kthena_router_pod_pressure_ratio",
+				Help: "Pressure of the router pod by signal: memory working set over memory limit, or share of the CPU periods throttled",
+			},
+			[]string{LabelSignal},
+		),
+
+		LoadShedding: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "kthena_router_load_shedding",
+				Help: "1 while the router sheds the batch requests because its pod is under pressure, 0 otherwise",
+			},
+		),
+
+		LoadSheddingEpisodes: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_load_shedding_episodes_total",
+				Help: "Number of episodes of load shedding, by the pressure signal which started them",
+			},
+			[]string{LabelSignal},
+		),
+
+		QuotaExceeded: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_quota_exceeded_total",
+				Help: "Number of requests rejected by a level of the quotas of their consumer: org, team or consumer",
+			},
+			[]string{LabelLevel, LabelLimitType},
+		),
+
+		RateLimitPolicyExceeded: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_rate_limit_policy_exceeded_total",
+				Help: "Number of requests rejected by the limits of their caller at a RateLimitPolicy",
+			},
+			[]string{LabelPolicy, LabelLimitType},
+		),
+
+		TokenQuotaExceeded: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_token_quota_exceeded_total",
+				Help: "Number of requests rejected by an exhausted TokenQuota",
+			},
+			[]string{LabelQuota},
+		),
+
+		ConsumerStreams: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_consumer_streams_total",
+				Help: "Number of streamed responses of each consumer, completed or abandoned by the client before their end",
+			},
+			[]string{LabelConsumer, LabelResult},
+		),
+
+		ConsumerStreamDuration: *promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "kthena_router_consumer_stream_duration_seconds",
+				Help:    "Duration distribution of the streamed responses of each consumer",
+				Buckets: []float64{1, 5, 10, 30, 60, 120, 300, 600, 1800, 3600},
+			},
+			[]string{LabelConsumer},
+		),
+
+		ConsumerStreamOutputTokens: *promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "kthena_router_consumer_stream_output_tokens",
+				Help:    "Distribution of the output tokens of the streamed responses of each consumer",
+				Buckets: prometheus.ExponentialBuckets(16, 4, 7),
+			},
+			[]string{LabelConsumer},
+		),
+
+		StreamAnomalies: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_stream_anomalies_total",
+				Help: "Number of anomalies flagged by the rules on the streams of the consumers",
+			},
+			[]string{LabelConsumer, LabelRule},
+		),
+
+		SemanticCacheLookups: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_semantic_cache_lookups_total",
+				Help: "Number of lookups of the semantic response cache, by result: hit, miss or error",
+			},
+			[]string{LabelModel, LabelResult},
+		),
+
+		MirroredRequests: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_mirrored_requests_total",
+				Help: "Number of requests mirrored to a model server whose responses are discarded, by result: succeeded, failed or dropped",
+			},
+			[]string{LabelModel, LabelModelServer, LabelResult},
+		),
+
+		EvaluatedRequests: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_evaluated_requests_total",
+				Help: "Number of responses shadowed to the evaluation sink of their ModelRoute, by result: succeeded, failed or dropped",
+			},
+			[]string{LabelModelRoute, LabelModelServer, LabelResult},
+		),
+
+		EvaluationScore: *promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "kthena_router_evaluation_score",
+				Help:    "Distribution of the scores of the responses evaluated by the evaluation sink of their ModelRoute",
+				Buckets: []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1},
+			},
+			[]string{LabelModelRoute, LabelModelServer},
+		),
+
+		HedgedRequests: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_hedged_requests_total",
+				Help: "Number of requests hedged on a second pod, by result: primary or hedge for the pod answering first, or failed",
+			},
+			[]string{LabelModel, LabelModelServer, LabelResult},
+		),
+
+		RetriedRequests: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_retried_requests_total",
+				Help: "Number of retries of requests on another pod after failing before their response started",
+			},
+			[]string{LabelModel, LabelModelServer},
+		),
+
+		InvalidStructuredOutputs: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_invalid_structured_outputs_total",
+				Help: "Number of responses not valid against the response_format of their request, by result: retried, or exhausted once the request has no retry left",
+			},
+			[]string{LabelModel, LabelModelServer, LabelResult},
+		),
+
+		CoalescedPrefills: *promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kthena_router_coalesced_prefills_total",
+				Help: "Number of PD-disaggregated prefills coalesced with the prefill of a request sharing their prompt prefix, by result: coalesced or timeout",
+			},
+			[]string{LabelModel, LabelModelServer, LabelResult},
+		),
+
+		PodWarmUpDuration: *promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "kthena_router_pod_warm_up_duration_seconds",
+				Help:    "Duration of the warm-up requests sent to the new pods of model servers, by result: succeeded, failed or timeout",
+				Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+			},
+			[]string{LabelModelServer, LabelResult},
+		),
+
+		SLOTarget: *promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "kthena_router_slo_target_ratio",
+				Help: "Target ratio of good requests of the service level objectives declared on ModelRoutes",
+			},
+			[]string{LabelModel, LabelModelRoute, LabelObjective},
+		),
+
+		SLOBurnRate: *promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "kthena_router_slo_burn_rate",
+				Help: "Error budget burn rate of the service level objectives declared on ModelRoutes, the ratio of bad requests over a window divided by the error budget",
+			},
+			[]string{LabelModel, LabelModelRoute, LabelObjective, LabelWindow},
+		),
 	}
 }
 
@@ -181,6 +692,151 @@ func (m *Metrics) RecordRequest(model, path, statusCode, errorType string, durat
 	m.RequestDuration.WithLabelValues(model, path, statusCode).Observe(duration.Seconds())
 }
 
+// RecordModelServerRequest records a completed request sent to a model server through a ModelRoute
+func (m *Metrics) RecordModelServerRequest(modelServer, modelRoute, statusCode string, duration time.Duration) {
+	m.ModelServerRequestsTotal.WithLabelValues(modelServer, modelRoute, statusCode).Inc()
+	m.ModelServerRequestDuration.WithLabelValues(modelServer, modelRoute).Observe(duration.Seconds())
+}
+
+// RecordCanceledGeneration records a generation aborted because the client disconnected
+func (m *Metrics) RecordCanceledGeneration(model, modelServer string) {
+	m.CanceledGenerations.WithLabelValues(model, modelServer).Inc()
+}
+
+// RecordFallbackRequest records a request retried on a fallback model server
+func (m *Metrics) RecordFallbackRequest(model, modelServer string) {
+	m.FallbackRequests.WithLabelValues(model, modelServer).Inc()
+}
+
+// RecordDegradedRequest records a request exceeding the rate limit of its model route served by its degradation
+// model server
+func (m *Metrics) RecordDegradedRequest(model, modelServer, limitType string) {
+	m.DegradedRequests.WithLabelValues(model, modelServer, limitType).Inc()
+}
+
+// RecordRequestTimeout records a request or an attempt on a pod aborted by a timeout of its model route
+func (m *Metrics) RecordRequestTimeout(model, modelServer, timeout string) {
+	m.RequestTimeouts.WithLabelValues(model, modelServer, timeout).Inc()
+}
+
+// RecordDecodeResumption records a PD-disaggregated generation resumed on another prefill/decode pair
+func (m *Metrics) RecordDecodeResumption(model, modelServer string) {
+	m.DecodeResumptions.WithLabelValues(model, modelServer).Inc()
+}
+
+// RecordExperimentRequest records a request opted in an experiment
+func (m *Metrics) RecordExperimentRequest(model, experiment string) {
+	m.ExperimentRequests.WithLabelValues(model, experiment).Inc()
+}
+
+// IncQueueLength increments the number of requests queued with a priority
+func (m *Metrics) IncQueueLength(priority string) {
+	m.QueueLength.WithLabelValues(priority).Inc()
+}
+
+// DecQueueLength decrements the number of requests queued with a priority
+func (m *Metrics) DecQueueLength(priority string) {
+	m.QueueLength.WithLabelValues(priority).Dec()
+}
+
+// RecordQueueDuration records the time a request spent queued
+func (m *Metrics) RecordQueueDuration(priority string, duration time.Duration) {
+	m.QueueDuration.WithLabelValues(priority).Observe(duration.Seconds())
+}
+
+// RecordQueueRejection records a request rejected by the queue
+func (m *Metrics) RecordQueueRejection(priority, reason string) {
+	m.QueueRejections.WithLabelValues(priority, reason).Inc()
+}
+
+// RecordProtectionRejection records a request rejected to protect the router
+func (m *Metrics) RecordProtectionRejection(reason string) {
+	m.ProtectionRejections.WithLabelValues(reason).Inc()
+}
+
+// RecordConcurrencyLimitRejection records a request rejected by the concurrency limit of its ModelRoute or of the
+// pods of its model server
+func (m *Metrics) RecordConcurrencyLimitRejection(modelRoute, limit string) {
+	m.ConcurrencyLimitRejections.WithLabelValues(modelRoute, limit).Inc()
+}
+
+// RecordSemanticCacheLookup records a lookup of the semantic response cache
+func (m *Metrics) RecordSemanticCacheLookup(model, result string) {
+	m.SemanticCacheLookups.WithLabelValues(model, result).Inc()
+}
+
+// RecordMirroredRequest records a request mirrored to a model server
+func (m *Metrics) RecordMirroredRequest(model, modelServer, result string) {
+	m.MirroredRequests.WithLabelValues(model, modelServer, result).Inc()
+}
+
+// RecordHedgedRequest records a request hedged on a second pod of a model server
+func (m *Metrics) RecordHedgedRequest(model, modelServer, result string) {
+	m.HedgedRequests.WithLabelValues(model, modelServer, result).Inc()
+}
+
+// RecordRetriedRequest records a retry of a request on another pod of a model server
+func (m *Metrics) RecordRetriedRequest(model, modelServer string) {
+	m.RetriedRequests.WithLabelValues(model, modelServer).Inc()
+}
+
+// RecordInvalidStructuredOutput records a response of a model server which is not valid against the response_format
+// of its request
+func (m *Metrics) RecordInvalidStructuredOutput(model, modelServer, result string) {
+	m.InvalidStructuredOutputs.WithLabelValues(model, modelServer, result).Inc()
+}
+
+// RecordCoalescedPrefill records a PD-disaggregated prefill coalesced with the prefill of another request
+func (m *Metrics) RecordCoalescedPrefill(model, modelServer, result string) {
+	m.CoalescedPrefills.WithLabelValues(model, modelServer, result).Inc()
+}
+
+// RecordQuotaExceeded records a request rejected by a level of the quotas of its consumer
+func (m *Metrics) RecordQuotaExceeded(level, limitType string) {
+	m.QuotaExceeded.WithLabelValues(level, limitType).Inc()
+}
+
+// RecordRateLimitPolicyExceeded records a request rejected by the limits of its caller at a RateLimitPolicy
+func (m *Metrics) RecordRateLimitPolicyExceeded(policy, limitType string) {
+	m.RateLimitPolicyExceeded.WithLabelValues(policy, limitType).Inc()
+}
+
+// RecordTokenQuotaExceeded records a request rejected by an exhausted TokenQuota
+func (m *Metrics) RecordTokenQuotaExceeded(quota string) {
+	m.TokenQuotaExceeded.WithLabelValues(quota).Inc()
+}
+
+// RecordConsumerStream records a streamed response of a consumer
+func (m *Metrics) RecordConsumerStream(consumer string, duration time.Duration, outputTokens int, abandoned bool) {
+	result := StreamResultCompleted
+	if abandoned {
+		result = StreamResultAbandoned
+	}
+	m.ConsumerStreams.WithLabelValues(consumer, result).Inc()
+	m.ConsumerStreamDuration.WithLabelValues(consumer).Observe(duration.Seconds())
+	m.ConsumerStreamOutputTokens.WithLabelValues(consumer).Observe(float64(outputTokens))
+}
+
+// RecordStreamAnomaly records an anomaly flagged by a rule on the streams of a consumer
+func (m *Metrics) RecordStreamAnomaly(consumer, rule string) {
+	m.StreamAnomalies.WithLabelValues(consumer, rule).Inc()
+}
+
+// RecordEvaluatedRequest records a response shadowed to the evaluation sink of its ModelRoute
+func (m *Metrics) RecordEvaluatedRequest(modelRoute, modelServer, result string) {
+	m.EvaluatedRequests.WithLabelValues(modelRoute, modelServer, result).Inc()
+}
+
+// RecordEvaluationScore records the score of a response returned by the evaluation sink of its ModelRoute
+func (m *Metrics) RecordEvaluationScore(modelRoute, modelServer string, score float64) {
+	m.EvaluationScore.WithLabelValues(modelRoute, modelServer).Observe(score)
+}
+
+// RecordPodWarmUp records the warm-up request sent to a new pod of a model server
+func (m *Metrics) RecordPodWarmUp(modelServer, result string, duration time.Duration) {
+	m.PodWarmUpDuration.WithLabelValues(modelServer, result).Observe(duration.Seconds())
+}
+
 // RecordPrefillDuration records prefill phase duration for PD-disaggregated requests
 func (m *Metrics) RecordPrefillDuration(model, path, statusCode string, duration time.Duration) {
 	m.RequestPrefillDuration.WithLabelValues(model, path, statusCode).Observe(duration.Seconds())
@@ -201,6 +857,28 @@ func (m *Metrics) RecordTokens(model, path string, inputTokens, outputTokens int
 	}
 }
 
+// RecordAudioSeconds records the duration of the audio of an audio request
+func (m *Metrics) RecordAudioSeconds(model, path string, seconds float64) {
+	if seconds > 0 {
+		m.AudioSecondsTotal.WithLabelValues(model, path).Add(seconds)
+	}
+}
+
+// RecordImages records the images generated by an image generation request and their megapixels
+func (m *Metrics) RecordImages(model, path string, images int, megapixels float64) {
+	if images > 0 {
+		m.ImagesTotal.WithLabelValues(model, path).Add(float64(images))
+		m.ImageMegapixelsTotal.WithLabelValues(model, path).Add(megapixels)
+	}
+}
+
+// RecordDocuments records the documents reranked or the inputs classified by a request
+func (m *Metrics) RecordDocuments(model, path string, documents int) {
+	if documents > 0 {
+		m.DocumentsTotal.WithLabelValues(model, path).Add(float64(documents))
+	}
+}
+
 // RecordRateLimitExceeded records when a request is rejected due to rate limiting
 func (m *Metrics) RecordRateLimitExceeded(model, limitType, path string) {
 	m.RateLimitExceeded.WithLabelValues(model, limitType, path).Inc()
@@ -289,6 +967,15 @@ func (r *RequestMetricsRecorder) SetUpstreamConnectionInfo(modelServer, modelRou
 	r.modelRoute = modelRoute
 }
 
+// FallBack records the failure of the model server the request was sent to and attributes the rest of the request to
+// the fallback model server.
+func (r *RequestMetricsRecorder) FallBack(modelServer, statusCode string) {
+	if r.modelServer != "" && r.modelRoute != "" {
+		r.metrics.RecordModelServerRequest(r.modelServer, r.modelRoute, statusCode, time.Since(r.startTime))
+	}
+	r.modelServer = modelServer
+}
+
 // RecordInputTokens records input token usage for this request
 func (r *RequestMetricsRecorder) RecordInputTokens(tokens int) {
 	if tokens > 0 {
@@ -340,6 +1027,9 @@ func (r *RequestMetricsRecorder) FinishDecodePhase(statusCode string) {
 func (r *RequestMetricsRecorder) Finish(statusCode, errorType string) {
 	duration := time.Since(r.startTime)
 	r.metrics.RecordRequest(r.model, r.path, statusCode, errorType, duration)
+	if r.modelServer != "" && r.modelRoute != "" {
+		r.metrics.RecordModelServerRequest(r.modelServer, r.modelRoute, statusCode, duration)
+	}
 }
 
 // RecordSchedulerPluginDuration records the execution time for a scheduler plugin
@@ -0,0 +1,170 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package profile defines the performance envelopes a kthena-router instance can be deployed with, and the router
+// features enabled in them. A profile is selected at install time, so several routers with different sizes can
+// coexist in one cluster.
+package profile
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	Small  = "small"
+	Medium = "medium"
+	Large  = "large"
+	// Custom leaves every knob to be configured explicitly.
+	Custom = "custom"
+
+	// DefaultStreamBufferSize is the buffer size used to read upstream streaming responses.
+	DefaultStreamBufferSize = 4096
+)
+
+// The router features a profile can enable or disable.
+const (
+	// FeatureQueue queues the requests beyond the concurrent request limit instead of rejecting them.
+	FeatureQueue = "queue"
+	// FeatureLoadShedding sheds the batch requests while the router pod is under memory or CPU pressure.
+	FeatureLoadShedding = "loadShedding"
+	// FeatureServerTiming reports the phases of the requests in Server-Timing response headers.
+	FeatureServerTiming = "serverTiming"
+)
+
+var features = []string{FeatureLoadShedding, FeatureQueue, FeatureServerTiming}
+
+// Profile describes the performance envelope of a router instance.
+type Profile struct {
+	Name string
+	// MaxConcurrentRequests is the maximum number of inference requests handled at the same time.
+	// Requests beyond this limit are rejected with 503, or queued if the request queue is enabled. 0 means no limit.
+	MaxConcurrentRequests int
+	// StreamBufferSize is the size in bytes of the buffer used to read upstream streaming responses.
+	StreamBufferSize int
+	// MaxBufferedBodyBytes is the maximum total size in bytes of the request bodies held by the router at the same time.
+	// Requests beyond this limit are rejected with 503. 0 means no limit.
+	MaxBufferedBodyBytes int64
+	// MemoryWatermarkBytes is the memory used by the router from which new requests are rejected with 503.
+	// 0 means no watermark.
+	MemoryWatermarkBytes int64
+	// Features enable or disable router features, overriding the enabled field of their router configuration.
+	// Their other settings are still read from the router configuration. The features the profile doesn't set are
+	// left to the router configuration.
+	Features map[string]bool
+}
+
+var presets = map[string]Profile{
+	Small: {
+		Name:                  Small,
+		MaxConcurrentRequests: 256,
+		StreamBufferSize:      4096,
+		MaxBufferedBodyBytes:  64 << 20,
+		MemoryWatermarkBytes:  384 << 20,
+		// A small router has no headroom to queue bursts, it rejects them.
+		Features: map[string]bool{FeatureLoadShedding: true},
+	},
+	Medium: {
+		Name:                  Medium,
+		MaxConcurrentRequests: 1024,
+		StreamBufferSize:      16384,
+		MaxBufferedBodyBytes:  256 << 20,
+		MemoryWatermarkBytes:  1536 << 20,
+		Features:              map[string]bool{FeatureLoadShedding: true, FeatureQueue: true},
+	},
+	Large: {
+		Name:                  Large,
+		MaxConcurrentRequests: 4096,
+		StreamBufferSize:      65536,
+		MaxBufferedBodyBytes:  1 << 30,
+		MemoryWatermarkBytes:  6 << 30,
+		Features:              map[string]bool{FeatureLoadShedding: true, FeatureQueue: true},
+	},
+	Custom: {
+		Name:                  Custom,
+		MaxConcurrentRequests: 0,
+		StreamBufferSize:      DefaultStreamBufferSize,
+	},
+}
+
+// Get returns the preset profile with the given name.
+// An empty name resolves to the custom profile.
+func Get(name string) (Profile, error) {
+	if name == "" {
+		name = Custom
+	}
+	p, ok := presets[strings.ToLower(name)]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown router profile %q, valid profiles are: %s", name, strings.Join(Names(), ", "))
+	}
+	// The features of the preset are copied, so that overriding them doesn't alter the preset.
+	presetFeatures := p.Features
+	p.Features = make(map[string]bool, len(presetFeatures))
+	for feature, enabled := range presetFeatures {
+		p.Features[feature] = enabled
+	}
+	return p, nil
+}
+
+// SetFeatures overrides the features of the profile with the given values, e.g. {"queue": "true"}.
+func (p *Profile) SetFeatures(values map[string]string) error {
+	if p.Features == nil {
+		p.Features = make(map[string]bool, len(values))
+	}
+	for feature, value := range values {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q of router feature %q: %w", value, feature, err)
+		}
+		p.Features[feature] = enabled
+	}
+	return nil
+}
+
+// Names returns the names of all preset profiles in alphabetical order.
+func Names() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks that the profile values are usable.
+func (p Profile) Validate() error {
+	if p.MaxConcurrentRequests < 0 {
+		return fmt.Errorf("max concurrent requests must not be negative, got %d", p.MaxConcurrentRequests)
+	}
+	if p.StreamBufferSize <= 0 {
+		return fmt.Errorf("stream buffer size must be positive, got %d", p.StreamBufferSize)
+	}
+	if p.MaxBufferedBodyBytes < 0 {
+		return fmt.Errorf("max buffered body bytes must not be negative, got %d", p.MaxBufferedBodyBytes)
+	}
+	if p.MemoryWatermarkBytes < 0 {
+		return fmt.Errorf("memory watermark bytes must not be negative, got %d", p.MemoryWatermarkBytes)
+	}
+	for feature := range p.Features {
+		if !slices.Contains(features, feature) {
+			return fmt.Errorf("unknown router feature %q, valid features are: %s", feature, strings.Join(features, ", "))
+		}
+	}
+	return nil
+}
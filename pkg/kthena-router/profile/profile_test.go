@@ -0,0 +1,90 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		name        string
+		profile     string
+		wantName    string
+		wantLimit   int
+		expectError bool
+	}{
+		{name: "empty defaults to custom", profile: "", wantName: Custom, wantLimit: 0},
+		{name: "small", profile: "small", wantName: Small, wantLimit: 256},
+		{name: "case insensitive", profile: "Large", wantName: Large, wantLimit: 4096},
+		{name: "unknown", profile: "huge", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Get(tt.profile)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantName, p.Name)
+			assert.Equal(t, tt.wantLimit, p.MaxConcurrentRequests)
+			assert.NoError(t, p.Validate())
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.Error(t, Profile{MaxConcurrentRequests: -1, StreamBufferSize: 1}.Validate())
+	assert.Error(t, Profile{StreamBufferSize: 0}.Validate())
+	assert.Error(t, Profile{StreamBufferSize: 1, MaxBufferedBodyBytes: -1}.Validate())
+	assert.Error(t, Profile{StreamBufferSize: 1, MemoryWatermarkBytes: -1}.Validate())
+	assert.Error(t, Profile{StreamBufferSize: 1, Features: map[string]bool{"semanticCache": true}}.Validate())
+	assert.NoError(t, Profile{MaxConcurrentRequests: 0, StreamBufferSize: 1}.Validate())
+	assert.NoError(t, Profile{StreamBufferSize: 1, Features: map[string]bool{FeatureQueue: false, FeatureServerTiming: true}}.Validate())
+}
+
+func TestSetFeatures(t *testing.T) {
+	p, err := Get(Medium)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{FeatureLoadShedding: true, FeatureQueue: true}, p.Features)
+
+	require.NoError(t, p.SetFeatures(map[string]string{FeatureQueue: "false", FeatureServerTiming: "true"}))
+	assert.Equal(t, map[string]bool{FeatureLoadShedding: true, FeatureQueue: false, FeatureServerTiming: true}, p.Features)
+	assert.Error(t, p.SetFeatures(map[string]string{FeatureQueue: "maybe"}))
+
+	// The preset is left untouched
+	p, err = Get(Medium)
+	require.NoError(t, err)
+	assert.True(t, p.Features[FeatureQueue])
+	assert.NotContains(t, p.Features, FeatureServerTiming)
+
+	// The custom profile leaves the features to the router configuration, unless they are set
+	p, err = Get(Custom)
+	require.NoError(t, err)
+	assert.Empty(t, p.Features)
+	require.NoError(t, p.SetFeatures(map[string]string{FeatureServerTiming: "true"}))
+	assert.Equal(t, map[string]bool{FeatureServerTiming: true}, p.Features)
+}
+
+func TestNames(t *testing.T) {
+	assert.Equal(t, []string{Custom, Large, Medium, Small}, Names())
+}
@@ -0,0 +1,81 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resume
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+type streamChunk struct {
+	Choices []struct {
+		Text  string `json:"text"`
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+}
+
+// GeneratedText returns the text generated so far, concatenated from the journaled events.
+func GeneratedText(events [][]byte) string {
+	var text strings.Builder
+	for _, event := range events {
+		var chunk streamChunk
+		if err := json.Unmarshal(bytes.TrimSpace(bytes.TrimPrefix(event, dataPrefix)), &chunk); err != nil {
+			continue
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		text.WriteString(chunk.Choices[0].Delta.Content)
+		text.WriteString(chunk.Choices[0].Text)
+	}
+	return text.String()
+}
+
+// Continue rewrites the request so that the backend continues the generation of the stream instead of
+// restarting it: the generated text is appended to the prompt, or as the final assistant message to continue
+// for chat completions, and the token budget is reduced by the number of events already generated.
+// Since the prompt prefix is unchanged, the backend which served the stream reuses its prefix cache.
+func Continue(request map[string]interface{}, state *State) {
+	// Every streamed event carries one token in general.
+	ContinueFrom(request, GeneratedText(state.Events), len(state.Events))
+}
+
+// ContinueFrom rewrites the request so that the backend continues a generation from the text generated so far,
+// made of the given number of tokens.
+func ContinueFrom(request map[string]interface{}, text string, tokens int) {
+	if text != "" {
+		if messages, ok := request["messages"].([]interface{}); ok {
+			request["messages"] = append(slices.Clip(messages), map[string]interface{}{"role": "assistant", "content": text})
+			// vLLM extensions continuing the last message instead of starting a new one
+			request["continue_final_message"] = true
+			request["add_generation_prompt"] = false
+		} else if prompt, ok := request["prompt"].(string); ok {
+			request["prompt"] = prompt + text
+		}
+	}
+
+	generated := float64(tokens)
+	for _, key := range []string{"max_tokens", "max_completion_tokens"} {
+		if v, ok := request[key].(float64); ok {
+			request[key] = max(v-generated, 1)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resume
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// LastEventIDHeader is the header SSE clients send the id of the last event they received in when reconnecting.
+	LastEventIDHeader = "Last-Event-Id"
+
+	// storeTimeout bounds the store operations done while streaming.
+	storeTimeout = time.Second
+
+	// flushEvents and flushInterval bound the events recorded by a journal before they are journaled.
+	flushEvents   = 16
+	flushInterval = 200 * time.Millisecond
+)
+
+var (
+	dataPrefix = []byte("data:")
+	doneEvent  = []byte("data: [DONE]")
+)
+
+// EventID returns the SSE event id of the seq-th event of the stream of a request.
+func EventID(requestID string, seq int) string {
+	return fmt.Sprintf("%s/%d", requestID, seq)
+}
+
+// ParseEventID returns the request id and the sequence number of an SSE event id.
+func ParseEventID(id string) (string, int, error) {
+	i := strings.LastIndex(id, "/")
+	if i <= 0 {
+		return "", 0, fmt.Errorf("invalid event id %q", id)
+	}
+	seq, err := strconv.Atoi(id[i+1:])
+	if err != nil || seq < 0 {
+		return "", 0, fmt.Errorf("invalid event id %q", id)
+	}
+	return id[:i], seq, nil
+}
+
+// Journal records the events of a stream while they are forwarded to the client.
+// Journal failures never fail the stream, they only make it not resumable.
+//
+// The events are journaled in batches, so that streaming doesn't wait for the store on every event. Their sequence
+// numbers are counted by the journal, and checked against the store when a batch is flushed. A router crash loses
+// the events of the batch not flushed yet, and the clients which received them can't resume the stream.
+type Journal struct {
+	store     Store
+	owner     string
+	requestID string
+	ctx       context.Context
+	// replay are the journaled events a resuming client missed, replayFrom is the sequence number of the first one.
+	replay     [][]byte
+	replayFrom int
+	// seq is the sequence number of the last event recorded, pending are the recorded events not journaled yet.
+	seq       int
+	pending   [][]byte
+	flushedAt time.Time
+	failed    bool
+	// completed reports whether the end of the stream was forwarded.
+	completed bool
+}
+
+// NewJournal creates the journal of a new stream of the request of owner, the subject authenticated on it.
+func NewJournal(ctx context.Context, store Store, owner, requestID string) *Journal {
+	return &Journal{store: store, owner: owner, requestID: requestID, ctx: context.WithoutCancel(ctx), flushedAt: time.Now()}
+}
+
+// Resume creates the journal continuing the stream of state, for a client which received the events up to lastSeq.
+func Resume(ctx context.Context, store Store, state *State, lastSeq int) *Journal {
+	j := NewJournal(ctx, store, state.Owner, state.RequestID)
+	j.seq = len(state.Events)
+	if lastSeq < len(state.Events) {
+		j.replay = state.Events[lastSeq:]
+		j.replayFrom = lastSeq + 1
+	}
+	return j
+}
+
+// RequestID returns the id of the request which started the stream.
+func (j *Journal) RequestID() string {
+	return j.requestID
+}
+
+// Start records the backend serving the stream and writes the events the client missed, if any.
+func (j *Journal) Start(w io.Writer, backend string) error {
+	ctx, cancel := context.WithTimeout(j.ctx, storeTimeout)
+	defer cancel()
+	if err := j.store.SetBackend(ctx, j.owner, j.requestID, backend); err != nil {
+		j.fail(err)
+	}
+	return j.WriteReplay(w)
+}
+
+// WriteReplay writes the events the resuming client missed.
+func (j *Journal) WriteReplay(w io.Writer) error {
+	for i, event := range j.replay {
+		if _, err := fmt.Fprintf(w, "id: %s\n%s\n\n", EventID(j.requestID, j.replayFrom+i), event); err != nil {
+			return err
+		}
+	}
+	j.replay = nil
+	return nil
+}
+
+// Record journals a line forwarded to the client and returns the bytes to forward instead:
+// data lines are preceded by their event id, so that the client can resume after them.
+func (j *Journal) Record(line []byte) []byte {
+	if bytes.HasPrefix(line, doneEvent) {
+		j.completed = true
+		return line
+	}
+	if j.failed || !bytes.HasPrefix(line, dataPrefix) {
+		return line
+	}
+	j.seq++
+	j.pending = append(j.pending, bytes.TrimRight(line, "\r\n"))
+	if len(j.pending) >= flushEvents || time.Since(j.flushedAt) >= flushInterval {
+		j.flush()
+	}
+	return append([]byte("id: "+EventID(j.requestID, j.seq)+"\n"), line...)
+}
+
+// flush journals the pending events.
+func (j *Journal) flush() {
+	j.flushedAt = time.Now()
+	if j.failed || len(j.pending) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(j.ctx, storeTimeout)
+	defer cancel()
+	seq, err := j.store.Append(ctx, j.owner, j.requestID, j.pending...)
+	j.pending = nil
+	if err != nil {
+		j.fail(err)
+		return
+	}
+	if seq != j.seq {
+		// The stream was journaled concurrently, e.g. by a request reusing the request id.
+		j.fail(fmt.Errorf("journaled %d events, %d forwarded", seq, j.seq))
+	}
+}
+
+// Finish journals the pending events, and marks the stream as completed if its end was forwarded. It is called
+// however the stream ends, streams cut before their end remain resumable.
+func (j *Journal) Finish() {
+	j.flush()
+	if j.failed || !j.completed {
+		return
+	}
+	ctx, cancel := context.WithTimeout(j.ctx, storeTimeout)
+	defer cancel()
+	if err := j.store.Finish(ctx, j.owner, j.requestID); err != nil {
+		klog.Errorf("failed to finish stream journal of request %s: %v", j.requestID, err)
+	}
+}
+
+// fail stops journaling. The stream is forgotten, as resuming it from an incomplete journal would
+// send the client output it already received.
+func (j *Journal) fail(err error) {
+	klog.Errorf("stream of request %s can't be resumed: %v", j.requestID, err)
+	j.failed = true
+	j.pending = nil
+	ctx, cancel := context.WithTimeout(j.ctx, storeTimeout)
+	defer cancel()
+	_ = j.store.Delete(ctx, j.owner, j.requestID)
+}
@@ -0,0 +1,202 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resume
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T) (*RedisStore, *miniredis.Miniredis) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisStore(client, time.Minute), mr
+}
+
+func TestParseEventID(t *testing.T) {
+	tests := []struct {
+		id          string
+		requestID   string
+		seq         int
+		expectError bool
+	}{
+		{id: EventID("req-1", 3), requestID: "req-1", seq: 3},
+		{id: "a/b/0", requestID: "a/b", seq: 0},
+		{id: "req-1", expectError: true},
+		{id: "/3", expectError: true},
+		{id: "req-1/x", expectError: true},
+		{id: "req-1/-1", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			requestID, seq, err := ParseEventID(tt.id)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.requestID, requestID)
+			assert.Equal(t, tt.seq, seq)
+		})
+	}
+}
+
+func TestJournal(t *testing.T) {
+	store, mr := newTestStore(t)
+	ctx := context.Background()
+
+	journal := NewJournal(ctx, store, "alice", "req-1")
+	var out bytes.Buffer
+	require.NoError(t, journal.Start(&out, "10.0.0.1:8000"))
+	assert.Equal(t, "id: req-1/1\ndata: {\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n",
+		string(journal.Record([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n"))))
+	assert.Equal(t, "\n", string(journal.Record([]byte("\n"))))
+	assert.Equal(t, "id: req-1/2\ndata: {\"choices\":[{\"delta\":{\"content\":\"lo\"}}]}\n",
+		string(journal.Record([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"lo\"}}]}\n"))))
+	// The stream was cut before its end
+	journal.Finish()
+
+	state, err := store.Get(ctx, "alice", "req-1")
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, "10.0.0.1:8000", state.Backend)
+	assert.Len(t, state.Events, 2)
+	assert.False(t, state.Done)
+	assert.Equal(t, "Hello", GeneratedText(state.Events))
+	assert.True(t, mr.TTL(stateKey("alice", "req-1")) > 0)
+
+	// The client received the first event only
+	resumed := Resume(ctx, store, state, 1)
+	out.Reset()
+	require.NoError(t, resumed.Start(&out, "10.0.0.1:8000"))
+	assert.Equal(t, "id: req-1/2\ndata: {\"choices\":[{\"delta\":{\"content\":\"lo\"}}]}\n\n", out.String())
+	assert.Equal(t, "id: req-1/3\ndata: {\"choices\":[{\"delta\":{\"content\":\"!\"}}]}\n",
+		string(resumed.Record([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"!\"}}]}\n"))))
+	assert.Equal(t, "data: [DONE]\n", string(resumed.Record([]byte("data: [DONE]\n"))))
+	resumed.Finish()
+
+	state, err = store.Get(ctx, "alice", "req-1")
+	require.NoError(t, err)
+	assert.True(t, state.Done)
+	assert.Len(t, state.Events, 3)
+
+	missing, err := store.Get(ctx, "alice", "req-2")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+	// The stream is bound to the caller which started it
+	missing, err = store.Get(ctx, "bob", "req-1")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+// countingStore counts the batches of events journaled.
+type countingStore struct {
+	*RedisStore
+	batches int
+}
+
+func (s *countingStore) Append(ctx context.Context, owner, requestID string, events ...[]byte) (int, error) {
+	s.batches++
+	return s.RedisStore.Append(ctx, owner, requestID, events...)
+}
+
+func TestJournal_Batches(t *testing.T) {
+	redisStore, _ := newTestStore(t)
+	store := &countingStore{RedisStore: redisStore}
+	ctx := context.Background()
+	journal := NewJournal(ctx, store, "alice", "req-1")
+	require.NoError(t, journal.Start(&bytes.Buffer{}, "10.0.0.1:8000"))
+
+	for i := 0; i < flushEvents+2; i++ {
+		journal.Record([]byte("data: {}\n"))
+	}
+	assert.Equal(t, 1, store.batches)
+	state, err := store.Get(ctx, "alice", "req-1")
+	require.NoError(t, err)
+	assert.Len(t, state.Events, flushEvents)
+
+	// The stream is cut, the events of the last batch are journaled
+	journal.Finish()
+	assert.Equal(t, 2, store.batches)
+	state, err = store.Get(ctx, "alice", "req-1")
+	require.NoError(t, err)
+	assert.Len(t, state.Events, flushEvents+2)
+	assert.False(t, state.Done)
+}
+
+// appendFailingStore fails to journal events.
+type appendFailingStore struct {
+	*RedisStore
+}
+
+func (s appendFailingStore) Append(context.Context, string, string, ...[]byte) (int, error) {
+	return 0, errors.New("server unavailable")
+}
+
+func TestJournal_StoreFailure(t *testing.T) {
+	store, _ := newTestStore(t)
+	journal := NewJournal(context.Background(), appendFailingStore{store}, "", "req-1")
+	require.NoError(t, journal.Start(&bytes.Buffer{}, "10.0.0.1:8000"))
+
+	line := []byte("data: {}\n")
+	// The line is forwarded before it is journaled, the stream is forgotten when journaling the batch fails
+	assert.Equal(t, "id: req-1/1\n"+string(line), string(journal.Record(line)))
+	journal.Finish()
+	assert.Equal(t, line, journal.Record(line))
+	state, err := store.Get(context.Background(), "", "req-1")
+	assert.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+func TestContinue(t *testing.T) {
+	state := &State{Events: [][]byte{
+		[]byte(`data: {"choices":[{"delta":{"role":"assistant"}}]}`),
+		[]byte(`data: {"choices":[{"delta":{"content":"Hello"}}]}`),
+		[]byte(`data: {"choices":[{"text":" world"}]}`),
+		[]byte(`data: {"choices":[],"usage":{"completion_tokens":2}}`),
+	}}
+
+	chat := map[string]interface{}{
+		"messages":   []interface{}{map[string]interface{}{"role": "user", "content": "Hi"}},
+		"max_tokens": float64(100),
+	}
+	Continue(chat, state)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"role": "user", "content": "Hi"},
+		map[string]interface{}{"role": "assistant", "content": "Hello world"},
+	}, chat["messages"])
+	assert.Equal(t, true, chat["continue_final_message"])
+	assert.Equal(t, false, chat["add_generation_prompt"])
+	assert.Equal(t, float64(96), chat["max_tokens"])
+
+	completion := map[string]interface{}{"prompt": "Say:", "max_completion_tokens": float64(2)}
+	Continue(completion, state)
+	assert.Equal(t, "Say:Hello world", completion["prompt"])
+	assert.Equal(t, float64(1), completion["max_completion_tokens"])
+}
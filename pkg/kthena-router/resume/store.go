@@ -0,0 +1,157 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package resume allows clients to resume streamed responses cut by a router restart.
+// The events of every stream are journaled in Redis, which is shared by all router replicas. A client
+// reconnecting with the Last-Event-Id header gets the events it missed replayed, and the generation is continued
+// on the backend which served the stream, from the output generated so far.
+package resume
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	keyPrefix = "kthena:resume:"
+
+	fieldBackend = "backend"
+	fieldDone    = "done"
+
+	// DefaultTTL is how long a stream can be resumed after its last event.
+	DefaultTTL = 10 * time.Minute
+)
+
+// State is the journaled state of a stream.
+type State struct {
+	// Owner is the subject authenticated on the request which started the stream, only that caller can resume it.
+	Owner     string
+	RequestID string
+	// Backend is the address (ip:port) of the model server pod which served the stream.
+	Backend string
+	// Events are the data lines forwarded to the client, the token offset of the stream is their count.
+	Events [][]byte
+	// Done reports whether the stream completed.
+	Done bool
+}
+
+// Store persists the state of streams. The streams are identified by the caller which started them and their request
+// id, so that a caller can't read nor write the streams of another caller reusing their request ids.
+type Store interface {
+	// Get returns the state of the stream of the request, or nil if there is none.
+	Get(ctx context.Context, owner, requestID string) (*State, error)
+	// SetBackend records the backend serving the stream.
+	SetBackend(ctx context.Context, owner, requestID, backend string) error
+	// Append journals events and returns the sequence number of the last one, starting from 1.
+	Append(ctx context.Context, owner, requestID string, events ...[]byte) (int, error)
+	// Finish marks the stream as completed.
+	Finish(ctx context.Context, owner, requestID string) error
+	// Delete forgets the stream, it can't be resumed anymore.
+	Delete(ctx context.Context, owner, requestID string) error
+}
+
+// RedisStore is a Store shared by the router replicas through Redis.
+type RedisStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+var _ Store = &RedisStore{}
+
+// NewRedisStore creates a store keeping the state of streams for ttl after their last update.
+func NewRedisStore(client *redis.Client, ttl time.Duration) *RedisStore {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &RedisStore{client: client, ttl: ttl}
+}
+
+// stateKey returns the key of the state of a stream. The owner is hashed, so that it can't collide with the
+// request id whatever characters they contain.
+func stateKey(owner, requestID string) string {
+	if owner == "" {
+		return keyPrefix + requestID
+	}
+	hash := sha256.Sum256([]byte(owner))
+	return keyPrefix + hex.EncodeToString(hash[:]) + ":" + requestID
+}
+
+func eventsKey(owner, requestID string) string {
+	return stateKey(owner, requestID) + ":events"
+}
+
+func (s *RedisStore) Get(ctx context.Context, owner, requestID string) (*State, error) {
+	pipe := s.client.Pipeline()
+	fields := pipe.HGetAll(ctx, stateKey(owner, requestID))
+	events := pipe.LRange(ctx, eventsKey(owner, requestID), 0, -1)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to get stream state of request %s: %w", requestID, err)
+	}
+	if len(fields.Val()) == 0 {
+		return nil, nil
+	}
+	state := &State{
+		Owner:     owner,
+		RequestID: requestID,
+		Backend:   fields.Val()[fieldBackend],
+		Done:      fields.Val()[fieldDone] == "true",
+	}
+	for _, event := range events.Val() {
+		state.Events = append(state.Events, []byte(event))
+	}
+	return state, nil
+}
+
+func (s *RedisStore) SetBackend(ctx context.Context, owner, requestID, backend string) error {
+	pipe := s.client.TxPipeline()
+	pipe.HSet(ctx, stateKey(owner, requestID), fieldBackend, backend, fieldDone, "false")
+	pipe.Expire(ctx, stateKey(owner, requestID), s.ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save stream state of request %s: %w", requestID, err)
+	}
+	return nil
+}
+
+func (s *RedisStore) Append(ctx context.Context, owner, requestID string, events ...[]byte) (int, error) {
+	values := make([]interface{}, len(events))
+	for i, event := range events {
+		values[i] = event
+	}
+	pipe := s.client.TxPipeline()
+	seq := pipe.RPush(ctx, eventsKey(owner, requestID), values...)
+	pipe.Expire(ctx, eventsKey(owner, requestID), s.ttl)
+	pipe.Expire(ctx, stateKey(owner, requestID), s.ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to journal stream event of request %s: %w", requestID, err)
+	}
+	return int(seq.Val()), nil
+}
+
+func (s *RedisStore) Finish(ctx context.Context, owner, requestID string) error {
+	if err := s.client.HSet(ctx, stateKey(owner, requestID), fieldDone, "true").Err(); err != nil {
+		return fmt.Errorf("failed to finish stream of request %s: %w", requestID, err)
+	}
+	return nil
+}
+
+func (s *RedisStore) Delete(ctx context.Context, owner, requestID string) error {
+	return s.client.Del(ctx, stateKey(owner, requestID), eventsKey(owner, requestID)).Err()
+}
@@ -0,0 +1,386 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+)
+
+const (
+	audioTranscriptionsPath = "/v1/audio/transcriptions"
+	audioTranslationsPath   = "/v1/audio/translations"
+
+	// defaultMaxAudioFileSizeMB is the file size limit of the OpenAI audio endpoints.
+	defaultMaxAudioFileSizeMB = 25
+	// audioFormOverhead bounds the size of the form fields other than the audio file.
+	audioFormOverhead = 1 << 20
+
+	audioFileTooLarge = "file_too_large"
+)
+
+var errAudioFileTooLarge = errors.New("audio file too large")
+
+// isAudioPath reports whether path is an OpenAI audio endpoint, whose requests are multipart forms.
+func isAudioPath(path string) bool {
+	return path == audioTranscriptionsPath || path == audioTranslationsPath
+}
+
+// audioRequest is the routing information read from the multipart form of an audio request.
+type audioRequest struct {
+	model    string
+	fileSize int64
+	// wavSeconds is the duration of the audio file if it is a WAV file, 0 otherwise.
+	wavSeconds float64
+}
+
+// handleAudio routes an audio transcription or translation request to the ModelServer of its model.
+// Audio requests are metered by the duration of the audio instead of tokens, so they aren't token rate limited.
+func (r *Router) handleAudio(c *gin.Context) {
+	limit := r.maxAudioFileSize + audioFormOverhead
+	if c.Request.ContentLength > limit {
+		r.rejectAudioFile(c)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, limit))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			r.rejectAudioFile(c)
+			return
+		}
+		accesslog.SetError(c, "request_parsing", err.Error())
+		c.AbortWithStatusJSON(http.StatusBadRequest, handlers.NewInvalidRequestError(err.Error(), "", ""))
+		return
+	}
+	contentType := c.Request.Header.Get("Content-Type")
+	audio, err := parseAudioRequest(body, contentType, r.maxAudioFileSize)
+	if errors.Is(err, errAudioFileTooLarge) {
+		r.rejectAudioFile(c)
+		return
+	}
+	if err != nil {
+		accesslog.SetError(c, "request_parsing", err.Error())
+		c.AbortWithStatusJSON(http.StatusBadRequest, handlers.NewInvalidRequestError(err.Error(), "", ""))
+		return
+	}
+
+	modelName := audio.model
+	accesslog.SetModelName(c, modelName)
+	c.Set("model", modelName)
+	path := c.Request.URL.Path
+	metricsRecorder := metrics.NewRequestMetricsRecorder(r.metrics, modelName, path)
+	r.metrics.IncActiveDownstreamRequests(modelName)
+	defer func() {
+		r.metrics.DecActiveDownstreamRequests(modelName)
+		reason := "successful_request"
+		if finishReason := c.GetString("finishReason"); finishReason != "" {
+			reason = finishReason
+		}
+		metricsRecorder.Finish(strconv.Itoa(c.Writer.Status()), reason)
+	}()
+	if !authorizeModel(c, modelName) {
+		return
+	}
+	accesslog.MarkRequestProcessingEnd(c)
+
+	modelServerName, isLora, modelRoute, err := r.store.MatchModelServer(modelName, c.Request, c.GetStringSlice(GatewayKey))
+	if err != nil {
+		accesslog.SetError(c, "model_server_matching", fmt.Sprintf("can't find corresponding model server: %v", err))
+		c.AbortWithStatusJSON(http.StatusNotFound, "route not found")
+		return
+	}
+	handlers.ApplyHeaderPolicy(c, modelRoute)
+	applyObservability(c, modelRoute)
+	c.Header(modelServerHeader, modelServerName.String())
+	pods, modelServer, err := r.getPodsAndServer(modelServerName)
+	if err != nil {
+		accesslog.SetError(c, "pod_discovery", fmt.Sprintf("can't find model server: %v", modelServerName))
+		c.AbortWithStatusJSON(http.StatusNotFound, fmt.Sprintf("can't find model server: %v", modelServerName))
+		return
+	}
+	var ok bool
+	if pods, ok = servingPods(c, pods, modelServer, isLora); !ok {
+		return
+	}
+	backend, err := r.newUpstream(modelServer, modelServer.Spec.WorkloadPort.Port)
+	if err != nil {
+		accesslog.SetError(c, "upstream", err.Error())
+		c.AbortWithStatusJSON(http.StatusBadGateway, err.Error())
+		return
+	}
+	model := resolveAlias(modelRoute, modelName, isLora)
+	if modelServer.Spec.Model != nil && !isLora {
+		model = *modelServer.Spec.Model
+	}
+	if model != modelName {
+		if body, err = rewriteAudioModel(body, contentType, model); err != nil {
+			accesslog.SetError(c, "request_parsing", err.Error())
+			c.AbortWithStatusJSON(http.StatusBadRequest, handlers.NewInvalidRequestError(err.Error(), "", ""))
+			return
+		}
+	}
+
+	ctx := &framework.Context{
+		Model:           modelName,
+		ModelServerName: modelServerName,
+		MetricsRecorder: metricsRecorder,
+		Experiment:      selectExperiment(c, r.experiments),
+	}
+	if err := r.scheduler.Schedule(ctx, pods); err != nil || len(ctx.BestPods) == 0 {
+		accesslog.SetError(c, "scheduling", fmt.Sprintf("can't schedule to target pod: %v", err))
+		c.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprintf("can't schedule to target pod: %v", err))
+		return
+	}
+
+	modelServerFullName := modelServerName.String()
+	modelRouteName := ""
+	if modelRoute != nil {
+		modelRouteName = fmt.Sprintf("%s/%s", modelRoute.Namespace, modelRoute.Name)
+	}
+	accesslog.MarkUpstreamStart(c)
+	handlers.GetServerTiming(c).UpstreamStarted()
+	defer accesslog.MarkUpstreamEnd(c)
+	for i, pod := range ctx.BestPods {
+		accesslog.SetRequestRouting(c, modelRouteName, modelServerFullName, pod.Pod.Name)
+		req := c.Request.Clone(c.Request.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+
+		r.metrics.IncActiveUpstreamRequests(modelServerFullName, modelRouteName)
+		seconds, err := proxyAudioRequest(c, req, pod.Pod.Status.PodIP, backend)
+		r.metrics.DecActiveUpstreamRequests(modelServerFullName, modelRouteName)
+		if err != nil {
+			if errors.Is(err, handlers.ErrClientGone) || req.Context().Err() != nil {
+				r.recordCanceledRequest(c, req, modelName, modelServerFullName)
+				return
+			}
+			klog.Errorf("audio request to pod %s failed: %v", pod.Pod.Name, err)
+			continue
+		}
+
+		// Not every engine reports the duration, e.g. for the text response format.
+		if seconds == 0 {
+			seconds = audio.wavSeconds
+		}
+		accesslog.SetAudioSeconds(c, seconds)
+		r.metrics.RecordAudioSeconds(modelName, path, seconds)
+		r.scheduler.RunPostHooks(ctx, i)
+		return
+	}
+	accesslog.SetError(c, "proxy", "request to all pods failed")
+	c.AbortWithStatusJSON(http.StatusNotFound, "request to all pods failed")
+}
+
+func (r *Router) rejectAudioFile(c *gin.Context) {
+	message := fmt.Sprintf("Maximum content size limit (%d bytes) exceeded.", r.maxAudioFileSize)
+	accesslog.SetError(c, audioFileTooLarge, message)
+	c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, handlers.NewInvalidRequestError(message, "file", audioFileTooLarge))
+}
+
+// proxyAudioRequest forwards the audio request to the pod, and returns the duration of the audio reported by
+// the model server, 0 if it isn't reported.
+func proxyAudioRequest(c *gin.Context, req *http.Request, podIP string, backend *upstream) (float64, error) {
+	resp, err := doRequest(req, podIP, backend)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	handlers.CopyResponseHeaders(c, resp.Header)
+	c.Status(resp.StatusCode)
+
+	var seconds float64
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		err := handlers.ForwardStream(c, resp.Body, handlers.StreamOptions{
+			BufferSize: streamBufferSize,
+			OnLine: func(line []byte) []byte {
+				if s := audioResponseSeconds(bytes.TrimSpace(bytes.TrimPrefix(line, []byte("data:")))); s > 0 {
+					seconds = s
+				}
+				return line
+			},
+		})
+		return seconds, err
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(c.Writer, io.TeeReader(resp.Body, &buf)); err != nil {
+		if req.Context().Err() != nil {
+			return 0, handlers.ErrClientGone
+		}
+		klog.Errorf("copy response to downstream failed: %v", err)
+	}
+	return audioResponseSeconds(buf.Bytes()), nil
+}
+
+// parseAudioRequest reads the model and the audio file of the multipart form of an audio request.
+// errAudioFileTooLarge is returned if the file is larger than maxFileSize.
+func parseAudioRequest(body []byte, contentType string, maxFileSize int64) (*audioRequest, error) {
+	reader, err := multipartReader(body, contentType)
+	if err != nil {
+		return nil, err
+	}
+	audio := &audioRequest{}
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid multipart form: %w", err)
+		}
+		switch part.FormName() {
+		case "model":
+			value, err := io.ReadAll(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid multipart form: %w", err)
+			}
+			audio.model = strings.TrimSpace(string(value))
+		case "file":
+			data, err := io.ReadAll(io.LimitReader(part, maxFileSize+1))
+			if err != nil {
+				return nil, fmt.Errorf("invalid multipart form: %w", err)
+			}
+			if int64(len(data)) > maxFileSize {
+				return nil, errAudioFileTooLarge
+			}
+			audio.fileSize = int64(len(data))
+			audio.wavSeconds = wavDuration(data)
+		}
+	}
+	if audio.model == "" {
+		return nil, fmt.Errorf("model is required")
+	}
+	if audio.fileSize == 0 {
+		return nil, fmt.Errorf("file is required")
+	}
+	return audio, nil
+}
+
+// rewriteAudioModel returns the multipart form of an audio request with its model replaced, the other parts are
+// copied as is.
+func rewriteAudioModel(body []byte, contentType, model string) ([]byte, error) {
+	reader, err := multipartReader(body, contentType)
+	if err != nil {
+		return nil, err
+	}
+	_, params, _ := mime.ParseMediaType(contentType)
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if err := writer.SetBoundary(params["boundary"]); err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid multipart form: %w", err)
+		}
+		if part.FormName() == "model" {
+			if err := writer.WriteField("model", model); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		w, err := writer.CreatePart(part.Header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(w, part); err != nil {
+			return nil, fmt.Errorf("invalid multipart form: %w", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func multipartReader(body []byte, contentType string) (*multipart.Reader, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/form-data" || params["boundary"] == "" {
+		return nil, fmt.Errorf("content type must be multipart/form-data, got %q", contentType)
+	}
+	return multipart.NewReader(bytes.NewReader(body), params["boundary"]), nil
+}
+
+// audioResponseSeconds returns the duration of the audio reported in a response or stream event of an audio
+// endpoint: the usage of the duration metered models, or the duration of the verbose_json response format.
+func audioResponseSeconds(body []byte) float64 {
+	var response struct {
+		Duration float64 `json:"duration"`
+		Usage    struct {
+			Type    string  `json:"type"`
+			Seconds float64 `json:"seconds"`
+		} `json:"usage"`
+	}
+	if len(body) == 0 || body[0] != '{' || json.Unmarshal(body, &response) != nil {
+		return 0
+	}
+	if response.Usage.Type == "duration" && response.Usage.Seconds > 0 {
+		return response.Usage.Seconds
+	}
+	return response.Duration
+}
+
+// wavDuration returns the duration of the audio of a WAV file, 0 if data isn't a WAV file.
+func wavDuration(data []byte) float64 {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return 0
+	}
+	var byteRate uint32
+	for offset := 12; offset+8 <= len(data); {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		offset += 8
+		switch id {
+		case "fmt ":
+			if offset+12 > len(data) {
+				return 0
+			}
+			byteRate = binary.LittleEndian.Uint32(data[offset+8 : offset+12])
+		case "data":
+			if byteRate == 0 {
+				return 0
+			}
+			// The size of the data of streamed WAV files is unknown, the data runs to the end of the file.
+			return float64(min(size, len(data)-offset)) / float64(byteRate)
+		}
+		// Chunks are word aligned.
+		offset += size + size%2
+	}
+	return 0
+}
@@ -0,0 +1,233 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+)
+
+// wavFile returns a 16 kHz mono 16-bit PCM WAV file of the given duration.
+func wavFile(seconds int) []byte {
+	const sampleRate, blockAlign = 16000, 2
+	data := make([]byte, seconds*sampleRate*blockAlign)
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+len(data)))
+	buf.WriteString("WAVEfmt ")
+	for _, v := range []any{
+		uint32(16), uint16(1), uint16(1), uint32(sampleRate), uint32(sampleRate * blockAlign), uint16(blockAlign), uint16(16),
+	} {
+		binary.Write(&buf, binary.LittleEndian, v)
+	}
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(len(data)))
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+// audioForm returns a multipart form of an audio request and its content type.
+func audioForm(t *testing.T, model string, file []byte) ([]byte, string) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	require.NoError(t, writer.WriteField("model", model))
+	part, err := writer.CreateFormFile("file", "speech.wav")
+	require.NoError(t, err)
+	_, err = part.Write(file)
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteField("response_format", "json"))
+	require.NoError(t, writer.Close())
+	return buf.Bytes(), writer.FormDataContentType()
+}
+
+func TestParseAudioRequest(t *testing.T) {
+	body, contentType := audioForm(t, "whisper", wavFile(3))
+	audio, err := parseAudioRequest(body, contentType, 1<<20)
+	require.NoError(t, err)
+	assert.Equal(t, "whisper", audio.model)
+	assert.Equal(t, int64(len(wavFile(3))), audio.fileSize)
+	assert.Equal(t, 3.0, audio.wavSeconds)
+
+	_, err = parseAudioRequest(body, contentType, 1024)
+	assert.ErrorIs(t, err, errAudioFileTooLarge)
+
+	_, err = parseAudioRequest(body, "application/json", 1<<20)
+	assert.Error(t, err)
+
+	body, contentType = audioForm(t, "", wavFile(1))
+	_, err = parseAudioRequest(body, contentType, 1<<20)
+	assert.EqualError(t, err, "model is required")
+
+	body, contentType = audioForm(t, "whisper", nil)
+	_, err = parseAudioRequest(body, contentType, 1<<20)
+	assert.EqualError(t, err, "file is required")
+}
+
+func TestRewriteAudioModel(t *testing.T) {
+	file := []byte("not a wav file")
+	body, contentType := audioForm(t, "whisper", file)
+	rewritten, err := rewriteAudioModel(body, contentType, "openai/whisper-large-v3")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, audioTranscriptionsPath, bytes.NewReader(rewritten))
+	req.Header.Set("Content-Type", contentType)
+	require.NoError(t, req.ParseMultipartForm(1<<20))
+	assert.Equal(t, "openai/whisper-large-v3", req.FormValue("model"))
+	assert.Equal(t, "json", req.FormValue("response_format"))
+	f, header, err := req.FormFile("file")
+	require.NoError(t, err)
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	assert.Equal(t, "speech.wav", header.Filename)
+	assert.Equal(t, file, data)
+}
+
+func TestAudioResponseSeconds(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want float64
+	}{
+		{name: "duration usage", body: `{"text":"hi","usage":{"type":"duration","seconds":12}}`, want: 12},
+		{name: "verbose json", body: `{"text":"hi","duration":8.5,"segments":[]}`, want: 8.5},
+		{name: "token usage", body: `{"text":"hi","usage":{"type":"tokens","input_tokens":10}}`, want: 0},
+		{name: "text", body: "hi", want: 0},
+		{name: "empty", body: "", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, audioResponseSeconds([]byte(tt.body)))
+		})
+	}
+}
+
+func TestWavDuration(t *testing.T) {
+	assert.Equal(t, 2.0, wavDuration(wavFile(2)))
+	// Truncated files are metered up to their end.
+	file := wavFile(2)
+	assert.Equal(t, 1.0, wavDuration(file[:len(file)-32000]))
+	assert.Equal(t, 0.0, wavDuration([]byte("ID3 mp3 file")))
+	assert.Equal(t, 0.0, wavDuration(file[:20]))
+}
+
+func TestRouter_HandlerFunc_Audio(t *testing.T) {
+	backendHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, audioTranscriptionsPath, r.URL.Path)
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, "whisper-large-v3", r.FormValue("model"))
+		w.Header().Set("Content-Type", "application/json")
+		if r.FormValue("response_format") == "text" {
+			fmt.Fprint(w, "hello")
+			return
+		}
+		fmt.Fprint(w, `{"text":"hello","usage":{"type":"duration","seconds":7}}`)
+	})
+	router, store, backend := setupTestRouter(backendHandler)
+	defer backend.Close()
+	router.maxAudioFileSize = 1 << 20
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: "whisper", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			Model:           ptr.To("whisper-large-v3"),
+			WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+			InferenceEngine: "vLLM",
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "whisper-0", Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}
+	store.AddOrUpdateModelServer(modelServer, sets.New(types.NamespacedName{Name: "whisper-0", Namespace: "default"}))
+	store.AddOrUpdatePod(pod, []*aiv1alpha1.ModelServer{modelServer})
+	store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "whisper", Namespace: "default"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "whisper",
+			Rules: []*aiv1alpha1.Rule{
+				{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "whisper"}}},
+			},
+		},
+	})
+
+	serve := func(body []byte, contentType string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, audioTranscriptionsPath, bytes.NewReader(body))
+		c.Request.Header.Set("Content-Type", contentType)
+		router.HandlerFunc()(c)
+		return w
+	}
+	audioSeconds := func() float64 {
+		return testutil.ToFloat64(metrics.DefaultMetrics.AudioSecondsTotal.WithLabelValues("whisper", audioTranscriptionsPath))
+	}
+	before := audioSeconds()
+
+	// The duration reported by the model server is metered.
+	body, contentType := audioForm(t, "whisper", wavFile(3))
+	w := serve(body, contentType)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"text":"hello"`)
+	assert.Equal(t, before+7, audioSeconds())
+
+	// The duration of the WAV file is metered when the model server doesn't report it.
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	require.NoError(t, writer.WriteField("model", "whisper"))
+	require.NoError(t, writer.WriteField("response_format", "text"))
+	part, err := writer.CreateFormFile("file", "speech.wav")
+	require.NoError(t, err)
+	part.Write(wavFile(3))
+	require.NoError(t, writer.Close())
+	w = serve(buf.Bytes(), writer.FormDataContentType())
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "hello", w.Body.String())
+	assert.Equal(t, before+10, audioSeconds())
+
+	// Files over the limit are rejected before reaching the model server.
+	body, contentType = audioForm(t, "whisper", wavFile(40))
+	w = serve(body, contentType)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), audioFileTooLarge)
+
+	body, contentType = audioForm(t, "unknown", wavFile(1))
+	assert.Equal(t, http.StatusNotFound, serve(body, contentType).Code)
+}
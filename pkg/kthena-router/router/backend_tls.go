@@ -0,0 +1,159 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/utils"
+)
+
+const (
+	protocolHTTPS = "https"
+	// defaultCABundleKey is the key of the CA bundle in its Secret if the ModelServer doesn't set it.
+	defaultCABundleKey = "ca.crt"
+)
+
+// backendTransports caches the transports to the pods of the https ModelServers, by ModelServer.
+type backendTransports struct {
+	mutex      sync.Mutex
+	transports map[types.NamespacedName]*backendTransport
+}
+
+// backendTransport is a transport and the TLS settings it was built with.
+type backendTransport struct {
+	verification v1alpha1.TLSVerification
+	serverName   string
+	caBundle     []byte
+	transport    *http.Transport
+}
+
+// upstream is how the requests are sent to the pods of a model server.
+type upstream struct {
+	scheme    string
+	port      int32
+	transport http.RoundTripper
+}
+
+// newUpstream returns how the requests are sent to the given port of the pods of the ModelServer,
+// which is nil for the InferencePool backends.
+func (r *Router) newUpstream(modelServer *v1alpha1.ModelServer, port int32) (*upstream, error) {
+	if modelServer == nil || modelServer.Spec.WorkloadPort.Protocol != protocolHTTPS {
+		return &upstream{scheme: "http", port: port, transport: http.DefaultTransport}, nil
+	}
+	var settings v1alpha1.BackendTLS
+	if modelServer.Spec.WorkloadPort.TLS != nil {
+		settings = *modelServer.Spec.WorkloadPort.TLS
+	}
+	var caBundle []byte
+	if ref := settings.CABundle; ref != nil {
+		secretName := types.NamespacedName{Namespace: modelServer.Namespace, Name: ref.Name}
+		secret := r.store.GetSecret(secretName)
+		if secret == nil {
+			return nil, fmt.Errorf("CA bundle secret %s not found", secretName)
+		}
+		key := ref.Key
+		if key == "" {
+			key = defaultCABundleKey
+		}
+		if caBundle = secret.Data[key]; len(caBundle) == 0 {
+			return nil, fmt.Errorf("CA bundle secret %s has no %q key", secretName, key)
+		}
+	}
+	transport, err := r.backendTransports.get(utils.GetNamespaceName(modelServer), settings, caBundle)
+	if err != nil {
+		return nil, err
+	}
+	return &upstream{scheme: protocolHTTPS, port: port, transport: transport}, nil
+}
+
+// get returns the transport of the ModelServer, built again when its TLS settings or its CA bundle change.
+func (b *backendTransports) get(name types.NamespacedName, settings v1alpha1.BackendTLS, caBundle []byte) (*http.Transport, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	cached := b.transports[name]
+	if cached != nil && cached.verification == settings.Verification && cached.serverName == settings.ServerName &&
+		bytes.Equal(cached.caBundle, caBundle) {
+		return cached.transport, nil
+	}
+
+	transport, err := newBackendTransport(settings, caBundle)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS settings of model server %s: %w", name, err)
+	}
+	if cached != nil {
+		cached.transport.CloseIdleConnections()
+	}
+	if b.transports == nil {
+		b.transports = make(map[types.NamespacedName]*backendTransport)
+	}
+	b.transports[name] = &backendTransport{
+		verification: settings.Verification,
+		serverName:   settings.ServerName,
+		caBundle:     caBundle,
+		transport:    transport,
+	}
+	return transport, nil
+}
+
+func newBackendTransport(settings v1alpha1.BackendTLS, caBundle []byte) (*http.Transport, error) {
+	config := &tls.Config{ServerName: settings.ServerName}
+	if len(caBundle) != 0 {
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(caBundle) {
+			return nil, errors.New("no PEM encoded certificate in the CA bundle")
+		}
+	}
+	switch settings.Verification {
+	case v1alpha1.TLSVerificationNone:
+		config.InsecureSkipVerify = true
+	case v1alpha1.TLSVerificationCertificateChain:
+		// The default verification also checks the server name, it is replaced by a verification of the chain only.
+		roots := config.RootCAs
+		config.InsecureSkipVerify = true
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			return verifyCertificateChain(state, roots)
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	return transport, nil
+}
+
+// verifyCertificateChain verifies the certificates presented by the server chain up to roots,
+// or the system roots if roots is nil.
+func verifyCertificateChain(state tls.ConnectionState, roots *x509.CertPool) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("no certificate presented by the model server")
+	}
+	options := x509.VerifyOptions{Roots: roots, Intermediates: x509.NewCertPool()}
+	for _, cert := range state.PeerCertificates[1:] {
+		options.Intermediates.AddCert(cert)
+	}
+	_, err := state.PeerCertificates[0].Verify(options)
+	return err
+}
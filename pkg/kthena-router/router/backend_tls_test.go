@@ -0,0 +1,159 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+)
+
+// caBundle returns the PEM encoded self-signed certificate of the TLS test server.
+func caBundle(server *httptest.Server) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+}
+
+func TestNewBackendTransport(t *testing.T) {
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer backend.Close()
+
+	tests := []struct {
+		name     string
+		settings aiv1alpha1.BackendTLS
+		caBundle []byte
+		wantErr  bool
+	}{
+		{name: "full verification", settings: aiv1alpha1.BackendTLS{Verification: aiv1alpha1.TLSVerificationFull}, caBundle: caBundle(backend)},
+		{name: "system roots", settings: aiv1alpha1.BackendTLS{}, wantErr: true},
+		{name: "server name mismatch", settings: aiv1alpha1.BackendTLS{ServerName: "model.internal"}, caBundle: caBundle(backend), wantErr: true},
+		{
+			name:     "certificate chain only",
+			settings: aiv1alpha1.BackendTLS{Verification: aiv1alpha1.TLSVerificationCertificateChain, ServerName: "model.internal"},
+			caBundle: caBundle(backend),
+		},
+		{name: "certificate chain with system roots", settings: aiv1alpha1.BackendTLS{Verification: aiv1alpha1.TLSVerificationCertificateChain}, wantErr: true},
+		{name: "no verification", settings: aiv1alpha1.BackendTLS{Verification: aiv1alpha1.TLSVerificationNone}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := newBackendTransport(tt.settings, tt.caBundle)
+			require.NoError(t, err)
+			req, _ := http.NewRequest(http.MethodGet, backend.URL, nil)
+			resp, err := transport.RoundTrip(req)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			resp.Body.Close()
+		})
+	}
+
+	_, err := newBackendTransport(aiv1alpha1.BackendTLS{}, []byte("not a certificate"))
+	assert.Error(t, err)
+}
+
+func TestBackendTransports_Get(t *testing.T) {
+	var transports backendTransports
+	name := types.NamespacedName{Namespace: "default", Name: "ms-1"}
+	settings := aiv1alpha1.BackendTLS{Verification: aiv1alpha1.TLSVerificationNone}
+	first, err := transports.get(name, settings, nil)
+	require.NoError(t, err)
+	second, err := transports.get(name, settings, nil)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	settings.ServerName = "model.internal"
+	third, err := transports.get(name, settings, nil)
+	require.NoError(t, err)
+	assert.NotSame(t, first, third)
+	assert.Equal(t, "model.internal", third.TLSClientConfig.ServerName)
+}
+
+func TestRouter_HandlerFunc_HTTPSBackend(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"response-id"}`)
+	}))
+	defer backend.Close()
+	store := datastore.New()
+	router := NewRouter(store, "")
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: "ms-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			WorkloadPort: aiv1alpha1.WorkloadPort{
+				Port:     int32(backendPort),
+				Protocol: "https",
+				TLS:      &aiv1alpha1.BackendTLS{CABundle: &aiv1alpha1.SecretKeyReference{Name: "model-ca"}},
+			},
+			InferenceEngine: "vLLM",
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "pod-1", Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}
+	store.AddOrUpdateModelServer(modelServer, sets.New(types.NamespacedName{Name: "pod-1", Namespace: "default"}))
+	store.AddOrUpdatePod(pod, []*aiv1alpha1.ModelServer{modelServer})
+	store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "mr-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "test-model",
+			Rules: []*aiv1alpha1.Rule{
+				{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "ms-1"}}},
+			},
+		},
+	})
+
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", bytes.NewBufferString(`{"model":"test-model","prompt":"hello"}`))
+		router.HandlerFunc()(c)
+		return w
+	}
+
+	// The CA bundle secret is not cached yet.
+	assert.Equal(t, http.StatusInternalServerError, serve().Code)
+
+	store.AddOrUpdateSecret(&corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{Name: "model-ca", Namespace: "default"},
+		Data:       map[string][]byte{defaultCABundleKey: caBundle(backend)},
+	})
+	w := serve()
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"response-id"`)
+}
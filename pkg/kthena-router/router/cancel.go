@@ -0,0 +1,129 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+)
+
+const (
+	// cancelPathPrefix and cancelPathSuffix delimit the endpoint clients cancel in-flight requests with:
+	// POST /v1/requests/{request_id}/cancel
+	cancelPathPrefix = "/v1/requests/"
+	cancelPathSuffix = "/cancel"
+
+	// requestCanceled is the error type and finish reason of requests canceled through the cancel endpoint.
+	requestCanceled = "request_canceled"
+)
+
+// errRequestCanceled is the cause of the context of requests canceled through the cancel endpoint.
+var errRequestCanceled = errors.New("request canceled by client")
+
+// inflightRequests tracks the requests being processed by caller and request id, so that they can be canceled.
+type inflightRequests struct {
+	mu       sync.Mutex
+	requests map[inflightKey]*inflightRequest
+}
+
+// inflightKey identifies an in-flight request. The request ids are chosen by the clients, so the requests of
+// different callers may share one.
+type inflightKey struct {
+	// owner is the subject authenticated on the request, empty when authentication is disabled.
+	owner     string
+	requestID string
+}
+
+type inflightRequest struct {
+	cancel context.CancelCauseFunc
+}
+
+func newInflightRequests() *inflightRequests {
+	return &inflightRequests{requests: make(map[inflightKey]*inflightRequest)}
+}
+
+// track makes the request of c cancelable by its request id and its caller, the returned function must be
+// called once the request completes.
+func (i *inflightRequests) track(c *gin.Context, requestID string) func() {
+	ctx, cancel := context.WithCancelCause(c.Request.Context())
+	c.Request = c.Request.WithContext(ctx)
+	key := inflightKey{owner: c.GetString(common.UserIdKey), requestID: requestID}
+	entry := &inflightRequest{cancel: cancel}
+
+	i.mu.Lock()
+	i.requests[key] = entry
+	i.mu.Unlock()
+
+	return func() {
+		i.mu.Lock()
+		// The request id is chosen by the client, so it may have been reused by a newer request of the same caller.
+		if i.requests[key] == entry {
+			delete(i.requests, key)
+		}
+		i.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// cancel aborts the in-flight request with the given id sent by owner, it returns false if there is no such
+// request. The requests of other callers are reported as missing, so that their ids can't be probed.
+func (i *inflightRequests) cancel(requestID, owner string) bool {
+	i.mu.Lock()
+	entry, ok := i.requests[inflightKey{owner: owner, requestID: requestID}]
+	i.mu.Unlock()
+	if !ok {
+		return false
+	}
+	entry.cancel(errRequestCanceled)
+	return true
+}
+
+// cancelRequestID returns the request id targeted by a cancel endpoint path.
+func cancelRequestID(path string) (string, bool) {
+	if !strings.HasPrefix(path, cancelPathPrefix) || !strings.HasSuffix(path, cancelPathSuffix) {
+		return "", false
+	}
+	requestID := strings.TrimSuffix(strings.TrimPrefix(path, cancelPathPrefix), cancelPathSuffix)
+	if requestID == "" || strings.Contains(requestID, "/") {
+		return "", false
+	}
+	return requestID, true
+}
+
+// handleCancel serves the cancel endpoint. Canceling the request context closes the upstream
+// connections, which makes the inference engine abort the generation, in the prefill as well as the decode phase.
+// Only the caller authenticated on a request can cancel it.
+func (r *Router) handleCancel(c *gin.Context, requestID string) {
+	if c.Request.Method != http.MethodPost {
+		c.AbortWithStatusJSON(http.StatusMethodNotAllowed, gin.H{"message": "method not allowed"})
+		return
+	}
+	if !r.inflightRequests.cancel(requestID, c.GetString(common.UserIdKey)) {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "request not found or already completed"})
+		return
+	}
+	klog.V(4).Infof("request %s canceled by client", requestID)
+	c.JSON(http.StatusOK, gin.H{"message": "request canceled"})
+}
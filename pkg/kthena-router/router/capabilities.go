@@ -0,0 +1,91 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
+)
+
+const unsupportedCapability = "model_capability_unsupported"
+
+// requireCapabilities records the capabilities a completion or chat completion request requires, so that it is only
+// routed to the ModelServers declaring them.
+func requireCapabilities(c *gin.Context, modelRequest ModelRequest, images []common.Image) {
+	if capabilities := requiredCapabilities(modelRequest, images); len(capabilities) > 0 {
+		c.Request = c.Request.WithContext(datastore.WithRequiredCapabilities(c.Request.Context(), capabilities))
+	}
+}
+
+// requiredCapabilities returns the capabilities a request requires: tools if it declares tools or functions it
+// doesn't forbid calling, json_mode if its response_format requests a JSON object or a JSON schema, vision if its
+// messages carry images, and reasoning if it sets the reasoning effort.
+func requiredCapabilities(modelRequest ModelRequest, images []common.Image) []v1alpha1.Capability {
+	var capabilities []v1alpha1.Capability
+	if requiresTools(modelRequest) {
+		capabilities = append(capabilities, v1alpha1.CapabilityTools)
+	}
+	if format, ok := modelRequest["response_format"].(map[string]interface{}); ok {
+		if formatType, _ := format["type"].(string); formatType == "json_object" || formatType == "json_schema" {
+			capabilities = append(capabilities, v1alpha1.CapabilityJSONMode)
+		}
+	}
+	if len(images) > 0 {
+		capabilities = append(capabilities, v1alpha1.CapabilityVision)
+	}
+	if effort, ok := modelRequest["reasoning_effort"].(string); ok && effort != "" {
+		capabilities = append(capabilities, v1alpha1.CapabilityReasoning)
+	} else if _, ok := modelRequest["reasoning"].(map[string]interface{}); ok {
+		capabilities = append(capabilities, v1alpha1.CapabilityReasoning)
+	}
+	return capabilities
+}
+
+// requiresTools reports whether a request declares tools, or the legacy functions, the model may call.
+func requiresTools(modelRequest ModelRequest) bool {
+	if choice, _ := modelRequest["tool_choice"].(string); choice == "none" {
+		return false
+	}
+	for _, field := range []string{"tools", "functions"} {
+		if declared, ok := modelRequest[field].([]interface{}); ok && len(declared) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// rejectMissingCapabilities rejects a request whose model isn't served by a ModelServer with the capabilities the
+// request requires, instead of failing in a model server which can't handle it.
+func rejectMissingCapabilities(c *gin.Context, err *datastore.MissingCapabilitiesError) {
+	capabilities := make([]string, len(err.Capabilities))
+	for i, capability := range err.Capabilities {
+		capabilities[i] = string(capability)
+	}
+	message := fmt.Sprintf("The model %s doesn't support %s.", err.Model, strings.Join(capabilities, ", "))
+	accesslog.SetError(c, unsupportedCapability, message)
+	c.AbortWithStatusJSON(http.StatusBadRequest, handlers.NewInvalidRequestError(message, "model", unsupportedCapability))
+	c.Set("finishReason", unsupportedCapability)
+}
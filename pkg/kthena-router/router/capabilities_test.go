@@ -0,0 +1,142 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
+)
+
+func TestRequiredCapabilities(t *testing.T) {
+	tools := []interface{}{map[string]interface{}{"type": "function", "function": map[string]interface{}{"name": "search"}}}
+	tests := []struct {
+		name    string
+		request ModelRequest
+		images  []common.Image
+		want    []aiv1alpha1.Capability
+	}{
+		{name: "plain chat", request: ModelRequest{"messages": []interface{}{}}},
+		{name: "tools", request: ModelRequest{"tools": tools}, want: []aiv1alpha1.Capability{aiv1alpha1.CapabilityTools}},
+		{name: "empty tools", request: ModelRequest{"tools": []interface{}{}}},
+		{name: "tools not to call", request: ModelRequest{"tools": tools, "tool_choice": "none"}},
+		{name: "legacy functions", request: ModelRequest{"functions": tools}, want: []aiv1alpha1.Capability{aiv1alpha1.CapabilityTools}},
+		{name: "json object", request: ModelRequest{"response_format": map[string]interface{}{"type": "json_object"}}, want: []aiv1alpha1.Capability{aiv1alpha1.CapabilityJSONMode}},
+		{name: "json schema", request: ModelRequest{"response_format": map[string]interface{}{"type": "json_schema"}}, want: []aiv1alpha1.Capability{aiv1alpha1.CapabilityJSONMode}},
+		{name: "text format", request: ModelRequest{"response_format": map[string]interface{}{"type": "text"}}},
+		{name: "images", request: ModelRequest{}, images: []common.Image{{}}, want: []aiv1alpha1.Capability{aiv1alpha1.CapabilityVision}},
+		{name: "reasoning effort", request: ModelRequest{"reasoning_effort": "high"}, want: []aiv1alpha1.Capability{aiv1alpha1.CapabilityReasoning}},
+		{name: "reasoning", request: ModelRequest{"reasoning": map[string]interface{}{"effort": "low"}}, want: []aiv1alpha1.Capability{aiv1alpha1.CapabilityReasoning}},
+		{
+			name:    "all",
+			request: ModelRequest{"tools": tools, "response_format": map[string]interface{}{"type": "json_object"}, "reasoning_effort": "medium"},
+			images:  []common.Image{{}},
+			want: []aiv1alpha1.Capability{
+				aiv1alpha1.CapabilityTools, aiv1alpha1.CapabilityJSONMode, aiv1alpha1.CapabilityVision, aiv1alpha1.CapabilityReasoning,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, requiredCapabilities(tt.request, tt.images))
+		})
+	}
+}
+
+func TestRouter_HandlerFunc_ToolCapability(t *testing.T) {
+	router, store, backend := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"response-id"}`)
+	}))
+	defer backend.Close()
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	var modelServers []*aiv1alpha1.ModelServer
+	for name, capabilities := range map[string][]aiv1alpha1.Capability{
+		"chat":  {aiv1alpha1.CapabilityJSONMode},
+		"agent": {aiv1alpha1.CapabilityTools, aiv1alpha1.CapabilityJSONMode},
+	} {
+		modelServer := &aiv1alpha1.ModelServer{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: aiv1alpha1.ModelServerSpec{
+				WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+				InferenceEngine: "vLLM",
+				Capabilities:    capabilities,
+			},
+		}
+		require.NoError(t, store.AddOrUpdateModelServer(modelServer, sets.New(types.NamespacedName{Name: "pod-1", Namespace: "default"})))
+		modelServers = append(modelServers, modelServer)
+	}
+	require.NoError(t, store.AddOrUpdatePod(&corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "pod-1", Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}, modelServers))
+	for model, targets := range map[string][]string{"qwen": {"chat", "agent"}, "phi": {"chat"}} {
+		rule := &aiv1alpha1.Rule{}
+		for _, target := range targets {
+			rule.TargetModels = append(rule.TargetModels, &aiv1alpha1.TargetModel{ModelServerName: target})
+		}
+		require.NoError(t, store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+			ObjectMeta: v1.ObjectMeta{Name: model, Namespace: "default"},
+			Spec:       aiv1alpha1.ModelRouteSpec{ModelName: model, Rules: []*aiv1alpha1.Rule{rule}},
+		}))
+	}
+
+	send := func(model string) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"model": %q, "messages": [{"role": "user", "content": "Find the weather in Paris"}],
+			"tools": [{"type": "function", "function": {"name": "get_weather"}}]}`, model)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/v1/chat/completions", bytes.NewBufferString(body))
+		router.HandlerFunc()(c)
+		return w
+	}
+
+	t.Run("tools routed to the model server calling tools", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			w := send("qwen")
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "default/agent", w.Header().Get(modelServerHeader))
+		}
+	})
+
+	t.Run("model without model server calling tools", func(t *testing.T) {
+		w := send("phi")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var resp handlers.OpenAIError
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, unsupportedCapability, resp.Error.Code)
+		assert.Equal(t, "The model phi doesn't support tools.", resp.Error.Message)
+	})
+}
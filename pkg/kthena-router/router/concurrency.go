@@ -0,0 +1,222 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+)
+
+const (
+	// limitRoute and limitPod are the concurrency limits a request is rejected by, the error type and finish reason
+	// of the request is the limit followed by "_concurrency_limit".
+	limitRoute = "route"
+	limitPod   = "pod"
+
+	defaultConcurrencyQueueTimeout = 30 * time.Second
+)
+
+// errConcurrencyLimit is returned when a request is beyond a concurrency limit, or waited longer than its queue
+// timeout for a slot.
+var errConcurrencyLimit = errors.New("concurrency limit reached")
+
+// concurrencyLimiter counts the requests in flight per ModelRoute and per model server pod, to keep them within the
+// MaxConcurrentRequests of the ModelRoutes and the MaxConcurrentRequestsPerPod of the ModelServers.
+type concurrencyLimiter struct {
+	mu     sync.Mutex
+	routes map[types.NamespacedName]int32
+	pods   map[types.NamespacedName]int32
+	// released is closed and replaced whenever a request releases a slot, to wake up the queued requests.
+	released chan struct{}
+	metrics  *metrics.Metrics
+}
+
+func newConcurrencyLimiter(m *metrics.Metrics) *concurrencyLimiter {
+	return &concurrencyLimiter{
+		routes:   make(map[types.NamespacedName]int32),
+		pods:     make(map[types.NamespacedName]int32),
+		released: make(chan struct{}),
+		metrics:  m,
+	}
+}
+
+// acquireRoute reserves a slot of the ModelRoute for a request, waiting for one with the Queue overflow. The returned
+// function must be called once the request completes.
+func (l *concurrencyLimiter) acquireRoute(ctx context.Context, modelRoute *v1alpha1.ModelRoute) (func(), error) {
+	name := types.NamespacedName{Namespace: modelRoute.Namespace, Name: modelRoute.Name}
+	concurrency := modelRoute.Spec.Concurrency
+	var limit int32
+	if concurrency != nil && concurrency.MaxConcurrentRequests != nil {
+		limit = *concurrency.MaxConcurrentRequests
+	}
+	err := l.wait(ctx, concurrency, func() bool {
+		if limit > 0 && l.routes[name] >= limit {
+			return false
+		}
+		l.routes[name]++
+		l.metrics.RouteInflightRequests.WithLabelValues(name.String()).Set(float64(l.routes[name]))
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.routes[name]--; l.routes[name] <= 0 {
+			delete(l.routes, name)
+			l.metrics.RouteInflightRequests.DeleteLabelValues(name.String())
+		} else {
+			l.metrics.RouteInflightRequests.WithLabelValues(name.String()).Set(float64(l.routes[name]))
+		}
+		l.broadcast()
+	}, nil
+}
+
+// availablePods returns the pods below the MaxConcurrentRequestsPerPod of their model server, waiting for one of
+// them to release a slot with the Queue overflow of the ModelRoute.
+func (l *concurrencyLimiter) availablePods(ctx context.Context, modelRoute *v1alpha1.ModelRoute, modelServer *v1alpha1.ModelServer,
+	pods []*datastore.PodInfo) ([]*datastore.PodInfo, error) {
+	limit := maxConcurrentRequestsPerPod(modelServer)
+	if limit == 0 {
+		return pods, nil
+	}
+	var available []*datastore.PodInfo
+	err := l.wait(ctx, modelRoute.Spec.Concurrency, func() bool {
+		available = available[:0]
+		for _, pod := range pods {
+			if l.pods[podName(pod)] < limit {
+				available = append(available, pod)
+			}
+		}
+		return len(available) > 0
+	})
+	return available, err
+}
+
+// tryAcquirePod reserves a slot of the pod of the model server for a request attempt, it returns false if the pod is
+// at the MaxConcurrentRequestsPerPod of the model server. The returned function must be called once the attempt
+// completes.
+func (l *concurrencyLimiter) tryAcquirePod(modelServer *v1alpha1.ModelServer, pod *datastore.PodInfo) (func(), bool) {
+	if modelServer == nil || pod == nil || pod.Pod == nil {
+		return func() {}, true
+	}
+	limit := maxConcurrentRequestsPerPod(modelServer)
+	name := podName(pod)
+	labels := []string{modelServer.Namespace + "/" + modelServer.Name, name.Name}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if limit > 0 && l.pods[name] >= limit {
+		return nil, false
+	}
+	l.pods[name]++
+	l.metrics.PodInflightRequests.WithLabelValues(labels...).Set(float64(l.pods[name]))
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.pods[name]--; l.pods[name] <= 0 {
+			delete(l.pods, name)
+			l.metrics.PodInflightRequests.DeleteLabelValues(labels...)
+		} else {
+			l.metrics.PodInflightRequests.WithLabelValues(labels...).Set(float64(l.pods[name]))
+		}
+		l.broadcast()
+	}, true
+}
+
+// wait calls try with the lock held until it succeeds. With the Queue overflow, try is called again each time a
+// slot is released until the queue timeout expires, it is only called once otherwise.
+func (l *concurrencyLimiter) wait(ctx context.Context, concurrency *v1alpha1.Concurrency, try func() bool) error {
+	var timeout <-chan time.Time
+	for {
+		l.mu.Lock()
+		acquired := try()
+		released := l.released
+		l.mu.Unlock()
+		if acquired {
+			return nil
+		}
+		if concurrency == nil || concurrency.Overflow != v1alpha1.ConcurrencyOverflowQueue {
+			return errConcurrencyLimit
+		}
+		if timeout == nil {
+			queueTimeout := defaultConcurrencyQueueTimeout
+			if concurrency.QueueTimeout != nil {
+				queueTimeout = concurrency.QueueTimeout.Duration
+			}
+			timer := time.NewTimer(queueTimeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		select {
+		case <-released:
+		case <-timeout:
+			return errConcurrencyLimit
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// broadcast wakes up the queued requests, it must be called with the lock held.
+func (l *concurrencyLimiter) broadcast() {
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+func maxConcurrentRequestsPerPod(modelServer *v1alpha1.ModelServer) int32 {
+	if modelServer == nil || modelServer.Spec.MaxConcurrentRequestsPerPod == nil {
+		return 0
+	}
+	return *modelServer.Spec.MaxConcurrentRequestsPerPod
+}
+
+func podName(pod *datastore.PodInfo) types.NamespacedName {
+	return types.NamespacedName{Namespace: pod.Pod.Namespace, Name: pod.Pod.Name}
+}
+
+// rejectConcurrencyLimit rejects a request beyond the concurrency limit of its ModelRoute or of the pods of its model
+// server, or whose client disconnected while it was queued.
+func (r *Router) rejectConcurrencyLimit(c *gin.Context, modelRoute *v1alpha1.ModelRoute, limit string, err error) {
+	if !errors.Is(err, errConcurrencyLimit) {
+		accesslog.SetError(c, clientDisconnected, "client disconnected while queued")
+		c.AbortWithStatus(statusClientClosedRequest)
+		return
+	}
+	modelRouteName := modelRoute.Namespace + "/" + modelRoute.Name
+	message := fmt.Sprintf("too many concurrent requests on model route %s", modelRouteName)
+	if limit == limitPod {
+		message = fmt.Sprintf("all the pods of model route %s are at their concurrency limit", modelRouteName)
+	}
+	r.metrics.RecordConcurrencyLimitRejection(modelRouteName, limit)
+	accesslog.SetError(c, limit+"_concurrency_limit", message)
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, message)
+	c.Set("finishReason", limit+"_concurrency_limit")
+}
@@ -0,0 +1,199 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+)
+
+func concurrencyRoute(concurrency *aiv1alpha1.Concurrency) *aiv1alpha1.ModelRoute {
+	return &aiv1alpha1.ModelRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "llama", Namespace: "default"},
+		Spec:       aiv1alpha1.ModelRouteSpec{Concurrency: concurrency},
+	}
+}
+
+func TestConcurrencyLimiterRoute(t *testing.T) {
+	l := newConcurrencyLimiter(metrics.DefaultMetrics)
+	inflight := func() float64 {
+		return testutil.ToFloat64(metrics.DefaultMetrics.RouteInflightRequests.WithLabelValues("default/llama"))
+	}
+
+	t.Run("reject", func(t *testing.T) {
+		route := concurrencyRoute(&aiv1alpha1.Concurrency{MaxConcurrentRequests: ptr.To[int32](1)})
+		release, err := l.acquireRoute(context.Background(), route)
+		require.NoError(t, err)
+		assert.Equal(t, 1.0, inflight())
+
+		_, err = l.acquireRoute(context.Background(), route)
+		assert.ErrorIs(t, err, errConcurrencyLimit)
+
+		release()
+		release, err = l.acquireRoute(context.Background(), route)
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("queue", func(t *testing.T) {
+		route := concurrencyRoute(&aiv1alpha1.Concurrency{
+			MaxConcurrentRequests: ptr.To[int32](1),
+			Overflow:              aiv1alpha1.ConcurrencyOverflowQueue,
+			QueueTimeout:          &v1.Duration{Duration: time.Minute},
+		})
+		release, err := l.acquireRoute(context.Background(), route)
+		require.NoError(t, err)
+
+		acquired := make(chan func())
+		go func() {
+			release, err := l.acquireRoute(context.Background(), route)
+			assert.NoError(t, err)
+			acquired <- release
+		}()
+		select {
+		case <-acquired:
+			t.Fatal("the queued request acquired a slot before a request completed")
+		case <-time.After(50 * time.Millisecond):
+		}
+		release()
+		(<-acquired)()
+	})
+
+	t.Run("queue timeout", func(t *testing.T) {
+		route := concurrencyRoute(&aiv1alpha1.Concurrency{
+			MaxConcurrentRequests: ptr.To[int32](1),
+			Overflow:              aiv1alpha1.ConcurrencyOverflowQueue,
+			QueueTimeout:          &v1.Duration{Duration: 10 * time.Millisecond},
+		})
+		release, err := l.acquireRoute(context.Background(), route)
+		require.NoError(t, err)
+		defer release()
+
+		_, err = l.acquireRoute(context.Background(), route)
+		assert.ErrorIs(t, err, errConcurrencyLimit)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		route.Spec.Concurrency.QueueTimeout = nil
+		_, err = l.acquireRoute(ctx, route)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		route := concurrencyRoute(nil)
+		for range 3 {
+			release, err := l.acquireRoute(context.Background(), route)
+			require.NoError(t, err)
+			defer release()
+		}
+		assert.Equal(t, 3.0, inflight())
+	})
+}
+
+func TestConcurrencyLimiterPods(t *testing.T) {
+	l := newConcurrencyLimiter(metrics.DefaultMetrics)
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: "llama", Namespace: "default"},
+		Spec:       aiv1alpha1.ModelServerSpec{MaxConcurrentRequestsPerPod: ptr.To[int32](1)},
+	}
+	var pods []*datastore.PodInfo
+	for _, name := range []string{"pod-1", "pod-2"} {
+		pods = append(pods, &datastore.PodInfo{Pod: &corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "default"}}})
+	}
+	route := concurrencyRoute(nil)
+
+	release, acquired := l.tryAcquirePod(modelServer, pods[0])
+	require.True(t, acquired)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.DefaultMetrics.PodInflightRequests.WithLabelValues("default/llama", "pod-1")))
+	_, acquired = l.tryAcquirePod(modelServer, pods[0])
+	assert.False(t, acquired)
+
+	available, err := l.availablePods(context.Background(), route, modelServer, pods)
+	require.NoError(t, err)
+	assert.Equal(t, pods[1:], available)
+
+	releaseOther, acquired := l.tryAcquirePod(modelServer, pods[1])
+	require.True(t, acquired)
+	_, err = l.availablePods(context.Background(), route, modelServer, pods)
+	assert.ErrorIs(t, err, errConcurrencyLimit)
+
+	release()
+	releaseOther()
+	available, err = l.availablePods(context.Background(), route, modelServer, pods)
+	require.NoError(t, err)
+	assert.Equal(t, pods, available)
+
+	// The pods of a model server without limit are counted but always available.
+	modelServer.Spec.MaxConcurrentRequestsPerPod = nil
+	release, acquired = l.tryAcquirePod(modelServer, pods[0])
+	require.True(t, acquired)
+	defer release()
+	_, acquired = l.tryAcquirePod(modelServer, pods[0])
+	assert.True(t, acquired)
+}
+
+func TestRouter_HandlerFunc_PodConcurrencyLimit(t *testing.T) {
+	unblock := make(chan struct{})
+	router, store, backend := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+		fmt.Fprint(w, `{"id":"response-id"}`)
+	}))
+	defer backend.Close()
+	registerTestModel(store, backend)
+	modelServer := store.GetModelServer(types.NamespacedName{Namespace: "default", Name: "llama"})
+	modelServer.Spec.MaxConcurrentRequestsPerPod = ptr.To[int32](1)
+
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/v1/completions", bytes.NewBufferString(`{"model": "test-model", "prompt": "hello"}`))
+		router.HandlerFunc()(c)
+		return w
+	}
+
+	first := make(chan *httptest.ResponseRecorder)
+	go func() { first <- send() }()
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.DefaultMetrics.PodInflightRequests.WithLabelValues("default/llama", "pod-llama")) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	w := send()
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "all the pods of model route default/mr-1 are at their concurrency limit")
+
+	close(unblock)
+	assert.Equal(t, http.StatusOK, (<-first).Code)
+	assert.Equal(t, http.StatusOK, send().Code)
+}
@@ -0,0 +1,221 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+const (
+	// streamedTokensKey is the context key of the output tokens of the streamed response, counted from its chunks until
+	// its usage reports them.
+	streamedTokensKey = "streamedTokens"
+
+	defaultStreamWindow = 5 * time.Minute
+	// streamAnomalyCalloutTimeout bounds the POST of an anomaly to the callout.
+	streamAnomalyCalloutTimeout = 5 * time.Second
+	// otherConsumers is the consumer label of the metrics of the consumers which are not consumers of the quotas.
+	otherConsumers = "other"
+)
+
+// streamAnomaly is an anomaly flagged by a rule on the streams of a consumer, POSTed to the callout.
+type streamAnomaly struct {
+	Consumer           string    `json:"consumer"`
+	Rule               string    `json:"rule"`
+	WindowStart        time.Time `json:"windowStart"`
+	Streams            int       `json:"streams"`
+	AbandonedStreams   int       `json:"abandonedStreams"`
+	AvgDurationSeconds float64   `json:"avgDurationSeconds"`
+	AvgOutputTokens    float64   `json:"avgOutputTokens"`
+}
+
+// streamWindow counts the streams of a consumer in the current window.
+type streamWindow struct {
+	streams      int
+	abandoned    int
+	duration     time.Duration
+	outputTokens int64
+	// flagged are the rules which already flagged the consumer in the window.
+	flagged sets.Set[string]
+}
+
+// consumerStreams counts the streamed responses of the consumers over tumbling windows, and flags the consumers whose
+// streams of the current window break a rule.
+type consumerStreams struct {
+	window     time.Duration
+	rules      []conf.StreamAnomalyRule
+	calloutURL string
+	// labeled are the consumers of the quotas, the only ones labeling the metrics so that their cardinality is bounded.
+	labeled sets.Set[string]
+
+	mutex       sync.Mutex
+	windowStart time.Time
+	consumers   map[string]*streamWindow
+}
+
+// newConsumerStreams creates the counters of the streams of the consumers, nil if they are disabled. The metrics are
+// labeled with the consumers of the quotas, the streams of the other consumers are counted together.
+func newConsumerStreams(config conf.ConsumerStreamsConfig, quotas conf.QuotaConfig) *consumerStreams {
+	if !config.Enabled {
+		return nil
+	}
+	window := time.Duration(config.WindowSeconds) * time.Second
+	if window <= 0 {
+		window = defaultStreamWindow
+	}
+	labeled := sets.New[string]()
+	for _, org := range quotas.Orgs {
+		for _, team := range org.Teams {
+			for _, consumer := range team.Consumers {
+				labeled.Insert(consumer.Subject)
+			}
+		}
+	}
+	return &consumerStreams{
+		window:     window,
+		rules:      config.Rules,
+		calloutURL: config.CalloutURL,
+		labeled:    labeled,
+		consumers:  make(map[string]*streamWindow),
+	}
+}
+
+// metricsLabel returns the consumer label of the metrics of a consumer.
+func (s *consumerStreams) metricsLabel(consumer string) string {
+	if s.labeled.Has(consumer) {
+		return consumer
+	}
+	return otherConsumers
+}
+
+// observe counts a stream of a consumer finished at now, and returns the anomalies of the rules it makes the consumer
+// break for the first time in the window. The counts of all the consumers are dropped when a new window starts.
+func (s *consumerStreams) observe(consumer string, now time.Time, duration time.Duration, outputTokens int, abandoned bool) []streamAnomaly {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if windowStart := now.Truncate(s.window); !windowStart.Equal(s.windowStart) {
+		s.windowStart = windowStart
+		clear(s.consumers)
+	}
+	w := s.consumers[consumer]
+	if w == nil {
+		w = &streamWindow{flagged: sets.New[string]()}
+		s.consumers[consumer] = w
+	}
+	w.streams++
+	if abandoned {
+		w.abandoned++
+	}
+	w.duration += duration
+	w.outputTokens += int64(outputTokens)
+
+	var anomalies []streamAnomaly
+	for _, rule := range s.rules {
+		if w.streams < max(rule.MinStreams, 1) || w.flagged.Has(rule.Name) || !w.breaks(&rule) {
+			continue
+		}
+		w.flagged.Insert(rule.Name)
+		anomalies = append(anomalies, streamAnomaly{
+			Consumer:           consumer,
+			Rule:               rule.Name,
+			WindowStart:        s.windowStart,
+			Streams:            w.streams,
+			AbandonedStreams:   w.abandoned,
+			AvgDurationSeconds: w.duration.Seconds() / float64(w.streams),
+			AvgOutputTokens:    float64(w.outputTokens) / float64(w.streams),
+		})
+	}
+	return anomalies
+}
+
+// breaks reports whether the streams of the window exceed one of the thresholds of the rule.
+func (w *streamWindow) breaks(rule *conf.StreamAnomalyRule) bool {
+	streams := int64(w.streams)
+	return (rule.MaxStreams > 0 && w.streams > rule.MaxStreams) ||
+		(rule.MaxAbandonPercent > 0 && w.abandoned*100 > rule.MaxAbandonPercent*w.streams) ||
+		(rule.MaxAvgDurationSeconds > 0 && w.duration > time.Duration(rule.MaxAvgDurationSeconds)*time.Second*time.Duration(streams)) ||
+		(rule.MaxAvgOutputTokens > 0 && w.outputTokens > int64(rule.MaxAvgOutputTokens)*streams)
+}
+
+// recordConsumerStream records the streamed response of the request started at start in the metrics of its consumer,
+// and reports the anomalies it flags. The streams of the unauthenticated callers and the requests rejected or failed
+// before streaming are not counted.
+func (r *Router) recordConsumerStream(c *gin.Context, start time.Time) {
+	consumer := c.GetString(common.UserIdKey)
+	if r.consumerStreams == nil || consumer == "" {
+		return
+	}
+	finishReason := c.GetString("finishReason")
+	abandoned := finishReason == clientDisconnected || finishReason == requestCanceled
+	if status := c.Writer.Status(); !abandoned && (status < 200 || status >= 300) {
+		return
+	}
+	outputTokens := c.GetInt(streamedTokensKey)
+	now := time.Now()
+	duration := now.Sub(start)
+	r.metrics.RecordConsumerStream(r.consumerStreams.metricsLabel(consumer), duration, outputTokens, abandoned)
+
+	for _, anomaly := range r.consumerStreams.observe(consumer, now, duration, outputTokens, abandoned) {
+		klog.Warningf("stream anomaly %q flagged for consumer %s: %d streams, %d abandoned, %.0fs and %.0f output tokens on average",
+			anomaly.Rule, consumer, anomaly.Streams, anomaly.AbandonedStreams, anomaly.AvgDurationSeconds, anomaly.AvgOutputTokens)
+		r.metrics.RecordStreamAnomaly(r.consumerStreams.metricsLabel(consumer), anomaly.Rule)
+		if r.consumerStreams.calloutURL != "" {
+			go func() {
+				if err := sendStreamAnomaly(r.consumerStreams.calloutURL, &anomaly); err != nil {
+					klog.Errorf("failed to send stream anomaly %q of consumer %s to the callout: %v", anomaly.Rule, consumer, err)
+				}
+			}()
+		}
+	}
+}
+
+// sendStreamAnomaly POSTs an anomaly to the callout.
+func sendStreamAnomaly(url string, anomaly *streamAnomaly) error {
+	body, err := json.Marshal(anomaly)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), streamAnomalyCalloutTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("callout returned status %d", resp.StatusCode)
+	}
+	return nil
+}
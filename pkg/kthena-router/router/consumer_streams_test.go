@@ -0,0 +1,130 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+func TestConsumerStreams_Observe(t *testing.T) {
+	streams := newConsumerStreams(conf.ConsumerStreamsConfig{
+		Enabled:       true,
+		WindowSeconds: 60,
+		Rules: []conf.StreamAnomalyRule{
+			{Name: "scraper", MinStreams: 3, MaxAbandonPercent: 50},
+			{Name: "runaway-agent", MaxAvgOutputTokens: 1000},
+		},
+	}, conf.QuotaConfig{})
+	now := time.Date(2026, 3, 17, 12, 0, 0, 0, time.UTC)
+
+	// The averages are only checked from the minimum number of streams.
+	assert.Empty(t, streams.observe("alice", now, time.Second, 100, true))
+	assert.Empty(t, streams.observe("alice", now, time.Second, 100, true))
+	anomalies := streams.observe("alice", now, time.Second, 100, false)
+	assert.Equal(t, []streamAnomaly{{
+		Consumer: "alice", Rule: "scraper", WindowStart: now, Streams: 3, AbandonedStreams: 2,
+		AvgDurationSeconds: 1, AvgOutputTokens: 100,
+	}}, anomalies)
+
+	// A rule flags a consumer once per window, the other consumers are counted apart.
+	assert.Empty(t, streams.observe("alice", now, time.Second, 100, true))
+	anomalies = streams.observe("bob", now, time.Minute, 5000, false)
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, "runaway-agent", anomalies[0].Rule)
+
+	// A new window starts from scratch.
+	assert.Empty(t, streams.observe("alice", now.Add(time.Minute), time.Second, 100, true))
+
+	assert.Nil(t, newConsumerStreams(conf.ConsumerStreamsConfig{}, conf.QuotaConfig{}))
+}
+
+func TestRouter_HandlerFunc_ConsumerStreams(t *testing.T) {
+	router, store, backend := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hi\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":1,\"completion_tokens\":7,\"total_tokens\":8}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer backend.Close()
+	registerTestModel(store, backend)
+
+	anomalies := make(chan streamAnomaly, 1)
+	callout := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var anomaly streamAnomaly
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&anomaly))
+		anomalies <- anomaly
+	}))
+	defer callout.Close()
+	router.consumerStreams = newConsumerStreams(conf.ConsumerStreamsConfig{
+		Enabled:    true,
+		Rules:      []conf.StreamAnomalyRule{{Name: "chatty", MaxStreams: 1}},
+		CalloutURL: callout.URL,
+	}, conf.QuotaConfig{Orgs: []conf.QuotaOrgConfig{{
+		Name:  "acme",
+		Teams: []conf.QuotaTeamConfig{{Name: "search", Consumers: []conf.QuotaConsumerConfig{{Subject: "carol"}}}},
+	}}})
+
+	send := func(userID string) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/v1/chat/completions",
+			bytes.NewBufferString(`{"model": "test-model", "stream": true, "messages": [{"role": "user", "content": "hello"}]}`))
+		c.Set(common.UserIdKey, userID)
+		router.HandlerFunc()(c)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+	completed := func(consumer string) float64 {
+		return testutil.ToFloat64(metrics.DefaultMetrics.ConsumerStreams.WithLabelValues(consumer, metrics.StreamResultCompleted))
+	}
+	before, beforeOther := completed("carol"), completed(otherConsumers)
+
+	send("carol")
+	send("carol")
+	// The streams of the unauthenticated callers are not counted.
+	send("")
+	assert.Equal(t, before+2, completed("carol"))
+	// The consumers which are not consumers of the quotas are counted together, the label values are bounded.
+	send("dave")
+	assert.Equal(t, beforeOther+1, completed(otherConsumers))
+	assert.Equal(t, float64(0), completed("dave"))
+
+	select {
+	case anomaly := <-anomalies:
+		assert.Equal(t, "carol", anomaly.Consumer)
+		assert.Equal(t, "chatty", anomaly.Rule)
+		assert.Equal(t, 2, anomaly.Streams)
+		assert.Equal(t, float64(7), anomaly.AvgOutputTokens)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the anomaly was not sent to the callout")
+	}
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.DefaultMetrics.StreamAnomalies.WithLabelValues("carol", "chatty")))
+}
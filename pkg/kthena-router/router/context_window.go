@@ -0,0 +1,59 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"fmt"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
+)
+
+const contextLengthExceeded = "context_length_exceeded"
+
+// requestedCompletionTokens returns the completion token budget requested by the client, 0 if unset.
+func requestedCompletionTokens(modelRequest ModelRequest) int {
+	for _, key := range []string{"max_completion_tokens", "max_tokens"} {
+		if v, ok := modelRequest[key].(float64); ok && v > 0 {
+			return int(v)
+		}
+	}
+	return 0
+}
+
+// checkContextWindow verifies that the prompt plus the requested completion fit in the model context window.
+// It returns the OpenAI style error to send back to the client if they don't.
+func checkContextWindow(modelRequest ModelRequest, promptTokens int, maxContextLength int32) *handlers.OpenAIError {
+	completionTokens := requestedCompletionTokens(modelRequest)
+	if promptTokens+completionTokens <= int(maxContextLength) {
+		return nil
+	}
+
+	param := "prompt"
+	if _, ok := modelRequest["messages"]; ok {
+		param = "messages"
+	}
+	var message string
+	if completionTokens > 0 {
+		message = fmt.Sprintf("This model's maximum context length is %d tokens. However, you requested %d tokens (%d in the %s, %d in the completion). Please reduce the length of the %s or completion.",
+			maxContextLength, promptTokens+completionTokens, promptTokens, param, completionTokens, param)
+	} else {
+		message = fmt.Sprintf("This model's maximum context length is %d tokens. However, your %s resulted in %d tokens. Please reduce the length of the %s.",
+			maxContextLength, param, promptTokens, param)
+	}
+	openAIError := handlers.NewInvalidRequestError(message, param, contextLengthExceeded)
+	return &openAIError
+}
@@ -0,0 +1,85 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/connectors"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/resume"
+)
+
+// decodeInterrupted is the error type and finish reason of the PD-disaggregated streams which broke midway and
+// could not be resumed on another prefill/decode pair.
+const decodeInterrupted = "decode_interrupted"
+
+// decodeProgress accumulates the completion forwarded to the client by the decode pods whose stream broke.
+type decodeProgress struct {
+	interruptions int
+	generated     string
+	tokens        int
+}
+
+func (p *decodeProgress) add(interrupted *connectors.DecodeInterruptedError) {
+	p.interruptions++
+	p.generated += interrupted.Generated
+	p.tokens += interrupted.Tokens
+}
+
+// resumedRequest returns the request continuing the generation of the original request after the completion
+// generated so far, like the stream resumption does, so that the next prefill pod computes the KV cache of the
+// completion too. It returns false if the completion can't be continued from its text.
+func resumedRequest(original ModelRequest, progress decodeProgress) (ModelRequest, bool) {
+	request := maps.Clone(original)
+	if progress.generated == "" {
+		return request, true
+	}
+	_, chat := original["messages"].([]interface{})
+	_, completion := original["prompt"].(string)
+	echo, _ := original["echo"].(bool)
+	if n, ok := original["n"].(float64); (ok && n > 1) || echo || (!chat && !completion) {
+		return nil, false
+	}
+	resume.ContinueFrom(request, progress.generated, progress.tokens)
+	return request, true
+}
+
+// finishInterruptedStream ends a stream whose generation completed but whose [DONE] event was lost.
+func finishInterruptedStream(c *gin.Context) {
+	_, _ = c.Writer.Write([]byte("data: [DONE]\n\n"))
+	c.Writer.Flush()
+}
+
+// abortInterruptedStream ends a stream which broke midway with an error event, its status has already been sent.
+func abortInterruptedStream(c *gin.Context, err error) {
+	message := fmt.Sprintf("generation interrupted: %v", err)
+	klog.Errorf("request %s: %s", c.Request.Header.Get("x-request-id"), message)
+	accesslog.SetError(c, decodeInterrupted, message)
+	c.Set("finishReason", decodeInterrupted)
+	data, _ := json.Marshal(handlers.OpenAIError{
+		Error: handlers.OpenAIErrorDetail{Message: message, Type: decodeInterrupted},
+	})
+	_, _ = fmt.Fprintf(c.Writer, "data: %s\n\n", data)
+	c.Writer.Flush()
+}
@@ -0,0 +1,107 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResumedRequest(t *testing.T) {
+	messages := []interface{}{map[string]interface{}{"role": "user", "content": "Say hello"}}
+	progress := decodeProgress{interruptions: 1, generated: "Hello wor", tokens: 3}
+	tests := []struct {
+		name     string
+		original ModelRequest
+		progress decodeProgress
+		want     ModelRequest
+		wantOK   bool
+	}{
+		{
+			name:     "nothing generated",
+			original: ModelRequest{"model": "m", "messages": messages, "max_tokens": float64(16), "n": float64(2)},
+			progress: decodeProgress{interruptions: 1},
+			want:     ModelRequest{"model": "m", "messages": messages, "max_tokens": float64(16), "n": float64(2)},
+			wantOK:   true,
+		},
+		{
+			name:     "chat completion",
+			original: ModelRequest{"model": "m", "messages": messages, "stream": true, "max_completion_tokens": float64(16)},
+			progress: progress,
+			want: ModelRequest{
+				"model": "m",
+				"messages": []interface{}{
+					map[string]interface{}{"role": "user", "content": "Say hello"},
+					map[string]interface{}{"role": "assistant", "content": "Hello wor"},
+				},
+				"stream":                 true,
+				"max_completion_tokens":  float64(13),
+				"continue_final_message": true,
+				"add_generation_prompt":  false,
+			},
+			wantOK: true,
+		},
+		{
+			name:     "completion",
+			original: ModelRequest{"model": "m", "prompt": "Say hello: ", "stream": true},
+			progress: progress,
+			want:     ModelRequest{"model": "m", "prompt": "Say hello: Hello wor", "stream": true},
+			wantOK:   true,
+		},
+		{
+			name:     "several choices",
+			original: ModelRequest{"model": "m", "messages": messages, "n": float64(2)},
+			progress: progress,
+		},
+		{
+			name:     "echoed prompt",
+			original: ModelRequest{"model": "m", "prompt": "Say hello: ", "echo": true},
+			progress: progress,
+		},
+		{
+			name:     "tokenized prompt",
+			original: ModelRequest{"model": "m", "prompt": []interface{}{float64(1), float64(2)}},
+			progress: progress,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := resumedRequest(tt.original, tt.progress)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	assert.Len(t, messages, 1, "the original conversation must not be modified")
+}
+
+func TestAbortInterruptedStream(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	c.Status(http.StatusOK)
+	_, _ = c.Writer.Write([]byte("data: {}\n\n"))
+
+	abortInterruptedStream(c, assert.AnError)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `data: {"error":{"message":"generation interrupted: `+assert.AnError.Error()+`","type":"decode_interrupted"}}`)
+	assert.Equal(t, decodeInterrupted, c.GetString("finishReason"))
+}
@@ -0,0 +1,100 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/filters/ratelimit"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+)
+
+// degradedModelServerKey is the context key of the ModelServer serving a request exceeding the rate limit of its
+// ModelRoute.
+const degradedModelServerKey = "degradedModelServer"
+
+// rateLimitDegradation tracks the ModelServers serving the requests exceeding the rate limits of the models.
+type rateLimitDegradation struct {
+	// modelServers maps the models to the degradation ModelServers of the rate limits of their ModelRoutes.
+	modelServers sync.Map
+}
+
+// update tracks the degradation ModelServer of the rate limit of the ModelRoute of a model, or stops tracking it if
+// the ModelRoute has none.
+func (d *rateLimitDegradation) update(modelName string, modelRoute *v1alpha1.ModelRoute) {
+	if modelRoute == nil || modelRoute.Spec.RateLimit == nil || modelRoute.Spec.RateLimit.DegradeTo == nil {
+		d.modelServers.Delete(modelName)
+		return
+	}
+	d.modelServers.Store(modelName, types.NamespacedName{Namespace: modelRoute.Namespace, Name: *modelRoute.Spec.RateLimit.DegradeTo})
+}
+
+// onModelRoute keeps the degradation ModelServers in sync with the ModelRoutes.
+func (d *rateLimitDegradation) onModelRoute(data datastore.EventData) {
+	switch data.EventType {
+	case datastore.EventAdd, datastore.EventUpdate:
+		d.update(data.ModelName, data.ModelRoute)
+	case datastore.EventDelete:
+		d.modelServers.Delete(data.ModelName)
+	}
+}
+
+// modelServer returns the degradation ModelServer of the rate limit of the model, if any.
+func (d *rateLimitDegradation) modelServer(modelName string) (types.NamespacedName, bool) {
+	value, ok := d.modelServers.Load(modelName)
+	if !ok {
+		return types.NamespacedName{}, false
+	}
+	return value.(types.NamespacedName), true
+}
+
+// degradeRateLimited degrades a request exceeding the token rate limits of its model with err to the degradation
+// ModelServer of its ModelRoute, and reports whether it does. The degraded request isn't counted against the limits
+// and is scheduled on the degradation ModelServer instead of the ones its ModelRoute matches.
+func (r *Router) degradeRateLimited(c *gin.Context, modelName string, err error) bool {
+	modelServerName, ok := r.rateLimitDegradation.modelServer(modelName)
+	if !ok {
+		return false
+	}
+	limitType := metrics.LimitTypeRequests
+	switch err.(type) {
+	case *ratelimit.InputRateLimitExceededError:
+		limitType = metrics.LimitTypeInputTokens
+	case *ratelimit.OutputRateLimitExceededError:
+		limitType = metrics.LimitTypeOutputTokens
+	}
+	klog.V(4).Infof("request for model %s degraded to model server %v: %v", modelName, modelServerName, err)
+	r.metrics.RecordDegradedRequest(modelName, modelServerName.String(), limitType)
+	c.Set(degradedModelServerKey, modelServerName)
+	return true
+}
+
+// degradedModelServer returns the degradation ModelServer the request was degraded to, if any.
+func degradedModelServer(c *gin.Context) (types.NamespacedName, bool) {
+	value, ok := c.Get(degradedModelServerKey)
+	if !ok {
+		return types.NamespacedName{}, false
+	}
+	modelServerName, ok := value.(types.NamespacedName)
+	return modelServerName, ok
+}
@@ -0,0 +1,137 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+func TestRouter_HandlerFunc_RateLimitDegradation(t *testing.T) {
+	router, store, primary := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"primary"}`)
+	}))
+	defer primary.Close()
+	overflow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var reqBody ModelRequest
+		_ = json.Unmarshal(body, &reqBody)
+		assert.Equal(t, "model-overflow", reqBody["model"])
+		fmt.Fprint(w, `{"id":"overflow"}`)
+	}))
+	defer overflow.Close()
+
+	for name, backend := range map[string]*httptest.Server{"primary": primary, "overflow": overflow} {
+		backendURL, _ := url.Parse(backend.URL)
+		backendPort, _ := strconv.Atoi(backendURL.Port())
+		modelServer := &aiv1alpha1.ModelServer{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: aiv1alpha1.ModelServerSpec{
+				Model:           ptr.To("model-" + name),
+				WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+				InferenceEngine: "vLLM",
+			},
+		}
+		podName := types.NamespacedName{Name: "pod-" + name, Namespace: "default"}
+		store.AddOrUpdateModelServer(modelServer, sets.New(podName))
+		store.AddOrUpdatePod(&corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{Name: podName.Name, Namespace: "default"},
+			Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+		}, []*aiv1alpha1.ModelServer{modelServer})
+	}
+
+	send := func(model string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(fmt.Sprintf(`{"model": %q, "prompt": "hello, how are you today?"}`, model)))
+		router.HandlerFunc()(c)
+		return w
+	}
+
+	tests := []struct {
+		name      string
+		model     string
+		degradeTo *string
+		wantCode  int
+		wantBody  string
+	}{
+		{
+			name:     "rejected without degradation",
+			model:    "model-a",
+			wantCode: http.StatusTooManyRequests,
+			wantBody: `"input token rate limit exceeded"`,
+		},
+		{
+			name:      "degraded to the overflow model server",
+			model:     "model-b",
+			degradeTo: ptr.To("overflow"),
+			wantCode:  http.StatusOK,
+			wantBody:  `{"id":"overflow"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+				ObjectMeta: v1.ObjectMeta{Name: "mr-" + tt.model, Namespace: "default"},
+				Spec: aiv1alpha1.ModelRouteSpec{
+					ModelName: tt.model,
+					Rules: []*aiv1alpha1.Rule{
+						{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "primary"}}},
+					},
+					RateLimit: &aiv1alpha1.RateLimit{
+						InputTokensPerUnit: ptr.To(uint32(1)),
+						Unit:               aiv1alpha1.Minute,
+						DegradeTo:          tt.degradeTo,
+					},
+				},
+			})
+			assert.Eventually(t, func() bool {
+				_, ok := router.rateLimitDegradation.modelServer(tt.model)
+				return ok == (tt.degradeTo != nil)
+			}, time.Second, 10*time.Millisecond)
+
+			// The prompts exceed the limit of a single input token.
+			var w *httptest.ResponseRecorder
+			assert.Eventually(t, func() bool {
+				w = send(tt.model)
+				return w.Code == tt.wantCode
+			}, time.Second, 10*time.Millisecond)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+			if tt.degradeTo != nil {
+				assert.Equal(t, "default/overflow", w.Header().Get(modelServerHeader))
+			}
+		})
+	}
+}
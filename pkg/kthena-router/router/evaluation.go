@@ -0,0 +1,206 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+const (
+	// evaluationEntryKey holds the evaluation of a request whose response is shadowed to the evaluation sink once it
+	// succeeds.
+	evaluationEntryKey = "evaluationEntry"
+
+	// maxEvaluatedRequests is the number of responses evaluated at once, the responses beyond are not evaluated.
+	maxEvaluatedRequests = 256
+	// defaultEvaluationPercentage is the percentage of the requests evaluated when the ModelRoute doesn't set it.
+	defaultEvaluationPercentage = 10
+	// defaultEvaluationTimeout bounds the evaluations of the ModelRoutes without an evaluation timeout.
+	defaultEvaluationTimeout = 30 * time.Second
+
+	evaluationSucceeded = "succeeded"
+	evaluationFailed    = "failed"
+	evaluationDropped   = "dropped"
+)
+
+// pendingEvaluation is a request whose response is shadowed to the evaluation sink of its ModelRoute once it is
+// received.
+type pendingEvaluation struct {
+	router      *Router
+	sink        *v1alpha1.Evaluation
+	modelRoute  string
+	modelServer string
+	model       string
+	requestID   string
+	request     []byte
+}
+
+// evaluationRecord is the pair of a request and its response POSTed to the evaluation sink.
+type evaluationRecord struct {
+	RequestID   string          `json:"request_id,omitempty"`
+	Model       string          `json:"model"`
+	ModelRoute  string          `json:"model_route"`
+	ModelServer string          `json:"model_server"`
+	Request     json.RawMessage `json:"request"`
+	Response    json.RawMessage `json:"response"`
+}
+
+// evaluationResult is the answer of the evaluation sink, the score of the response between 0 and 1.
+type evaluationResult struct {
+	Score *float64 `json:"score"`
+}
+
+// evaluates the response of the request, evaluation.Percentage percent of the time.
+func evaluates(evaluation *v1alpha1.Evaluation) bool {
+	if evaluation == nil {
+		return false
+	}
+	percentage := int32(defaultEvaluationPercentage)
+	if evaluation.Percentage != nil {
+		percentage = *evaluation.Percentage
+	}
+	if percentage >= 100 {
+		return true
+	}
+	return rand.Int32N(100) < percentage
+}
+
+// prepareEvaluation samples the request for the evaluation sink of the ModelRoute, the request is copied before it is
+// rewritten for the ModelServer serving it.
+func (r *Router) prepareEvaluation(
+	c *gin.Context,
+	modelRoute *v1alpha1.ModelRoute,
+	modelServerName types.NamespacedName,
+	modelRequest ModelRequest,
+	modelName string,
+) {
+	evaluation := modelRoute.Spec.Evaluation
+	if !evaluates(evaluation) {
+		return
+	}
+	request, err := json.Marshal(modelRequest)
+	if err != nil {
+		klog.V(4).Infof("failed to copy request %s for evaluation: %v", c.Request.Header.Get("x-request-id"), err)
+		return
+	}
+	c.Set(evaluationEntryKey, &pendingEvaluation{
+		router:      r,
+		sink:        evaluation,
+		modelRoute:  types.NamespacedName{Namespace: modelRoute.Namespace, Name: modelRoute.Name}.String(),
+		modelServer: modelServerName.String(),
+		model:       modelName,
+		requestID:   c.Request.Header.Get("x-request-id"),
+		request:     request,
+	})
+}
+
+// evaluateResponse shadows the response of a request sampled for evaluation to the evaluation sink of its ModelRoute
+// in the background. Only the non-streaming responses are evaluated.
+func evaluateResponse(c *gin.Context, response []byte) {
+	value, ok := c.Get(evaluationEntryKey)
+	if !ok {
+		return
+	}
+	entry := value.(*pendingEvaluation)
+	entry.router.evaluate(entry, response)
+}
+
+// evaluate sends the request of the entry and its response to the evaluation sink, and records the score returned.
+func (r *Router) evaluate(entry *pendingEvaluation, response []byte) {
+	select {
+	case r.evaluatedRequests <- struct{}{}:
+	default:
+		r.metrics.RecordEvaluatedRequest(entry.modelRoute, entry.modelServer, evaluationDropped)
+		return
+	}
+
+	body, err := json.Marshal(evaluationRecord{
+		RequestID:   entry.requestID,
+		Model:       entry.model,
+		ModelRoute:  entry.modelRoute,
+		ModelServer: entry.modelServer,
+		Request:     entry.request,
+		Response:    response,
+	})
+	if err != nil {
+		<-r.evaluatedRequests
+		klog.V(4).Infof("failed to encode the evaluation of request %s: %v", entry.requestID, err)
+		r.metrics.RecordEvaluatedRequest(entry.modelRoute, entry.modelServer, evaluationFailed)
+		return
+	}
+	go func() {
+		defer func() { <-r.evaluatedRequests }()
+		score, err := sendEvaluation(entry.sink, body)
+		if err != nil {
+			klog.V(4).Infof("evaluation of request %s by %s failed: %v", entry.requestID, entry.sink.URL, err)
+			r.metrics.RecordEvaluatedRequest(entry.modelRoute, entry.modelServer, evaluationFailed)
+			return
+		}
+		r.metrics.RecordEvaluatedRequest(entry.modelRoute, entry.modelServer, evaluationSucceeded)
+		r.metrics.RecordEvaluationScore(entry.modelRoute, entry.modelServer, score)
+		r.feedbackTracker.Evaluate(entry.model, entry.modelServer, score)
+	}()
+}
+
+// sendEvaluation POSTs the evaluation record to the sink and returns the score of the response.
+func sendEvaluation(sink *v1alpha1.Evaluation, body []byte) (float64, error) {
+	timeout := defaultEvaluationTimeout
+	if sink.Timeout != nil {
+		timeout = sink.Timeout.Duration
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sink.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("evaluation sink returned status %d", resp.StatusCode)
+	}
+	var result evaluationResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to decode the evaluation: %w", err)
+	}
+	if result.Score == nil {
+		return 0, errors.New("the evaluation has no score")
+	}
+	if math.IsNaN(*result.Score) || *result.Score < 0 || *result.Score > 1 {
+		return 0, fmt.Errorf("score must be between 0 and 1, got %v", *result.Score)
+	}
+	return *result.Score, nil
+}
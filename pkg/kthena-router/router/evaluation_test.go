@@ -0,0 +1,193 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+)
+
+func TestRouter_HandlerFunc_Evaluation(t *testing.T) {
+	router, store, backend := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"response-id","choices":[{"text":"world"}]}`)
+	}))
+	defer backend.Close()
+	records := make(chan evaluationRecord, 10)
+	score := `{"score":0.75}`
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var record evaluationRecord
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&record))
+		records <- record
+		fmt.Fprint(w, score)
+	}))
+	defer sink.Close()
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: "llama", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			Model:           ptr.To("llama-3"),
+			WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+			InferenceEngine: "vLLM",
+		},
+	}
+	podName := types.NamespacedName{Name: "pod-llama", Namespace: "default"}
+	store.AddOrUpdateModelServer(modelServer, sets.New(podName))
+	store.AddOrUpdatePod(&corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: podName.Name, Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}, []*aiv1alpha1.ModelServer{modelServer})
+
+	evaluated := func(result string) float64 {
+		return testutil.ToFloat64(metrics.DefaultMetrics.EvaluatedRequests.WithLabelValues("default/mr-1", "default/llama", result))
+	}
+	tests := []struct {
+		name       string
+		evaluation *aiv1alpha1.Evaluation
+		score      string
+		wantResult string
+	}{
+		{
+			name: "no evaluation",
+		},
+		{
+			name:       "response evaluated",
+			evaluation: &aiv1alpha1.Evaluation{URL: sink.URL, Percentage: ptr.To(int32(100))},
+			score:      `{"score":0.75}`,
+			wantResult: evaluationSucceeded,
+		},
+		{
+			name:       "score out of range",
+			evaluation: &aiv1alpha1.Evaluation{URL: sink.URL, Percentage: ptr.To(int32(100))},
+			score:      `{"score":7.5}`,
+			wantResult: evaluationFailed,
+		},
+		{
+			name:       "no response evaluated",
+			evaluation: &aiv1alpha1.Evaluation{URL: sink.URL, Percentage: ptr.To(int32(0))},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score = tt.score
+			store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+				ObjectMeta: v1.ObjectMeta{Name: "mr-1", Namespace: "default"},
+				Spec: aiv1alpha1.ModelRouteSpec{
+					ModelName: "test-model",
+					Rules: []*aiv1alpha1.Rule{
+						{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "llama"}}},
+					},
+					Evaluation: tt.evaluation,
+				},
+			})
+			before := evaluated(tt.wantResult)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/v1/completions", bytes.NewBufferString(`{"model": "test-model", "prompt": "hello"}`))
+			c.Request.Header.Set("x-request-id", "req-1")
+			router.HandlerFunc()(c)
+
+			// The evaluations don't affect the responses.
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Body.String(), `"id":"response-id"`)
+			if tt.wantResult != "" {
+				select {
+				case record := <-records:
+					assert.Equal(t, "req-1", record.RequestID)
+					assert.Equal(t, "test-model", record.Model)
+					assert.Equal(t, "default/mr-1", record.ModelRoute)
+					assert.Equal(t, "default/llama", record.ModelServer)
+					assert.JSONEq(t, `{"model": "test-model", "prompt": "hello"}`, string(record.Request))
+					assert.JSONEq(t, `{"id":"response-id","choices":[{"text":"world"}]}`, string(record.Response))
+				case <-time.After(5 * time.Second):
+					t.Fatal("response not evaluated")
+				}
+			}
+			require.Eventually(t, func() bool { return len(router.evaluatedRequests) == 0 }, 5*time.Second, 10*time.Millisecond)
+			assert.Empty(t, records)
+			if tt.wantResult != "" {
+				assert.Equal(t, before+1, evaluated(tt.wantResult))
+			}
+		})
+	}
+
+	stats, err := router.FeedbackStats(context.Background(), "test-model")
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, int64(1), stats[0].EvaluationCount)
+	assert.InDelta(t, 0.75, stats[0].EvaluationAverage, 1e-9)
+}
+
+func TestRouter_Evaluate_Dropped(t *testing.T) {
+	router, _, backend := setupTestRouter(http.NotFoundHandler())
+	defer backend.Close()
+	for range maxEvaluatedRequests {
+		router.evaluatedRequests <- struct{}{}
+	}
+
+	dropped := func() float64 {
+		return testutil.ToFloat64(metrics.DefaultMetrics.EvaluatedRequests.WithLabelValues("default/mr-1", "default/llama", evaluationDropped))
+	}
+	before := dropped()
+	router.evaluate(&pendingEvaluation{
+		router:      router,
+		sink:        &aiv1alpha1.Evaluation{URL: "http://127.0.0.1:1"},
+		modelRoute:  "default/mr-1",
+		modelServer: "default/llama",
+		model:       "test-model",
+		request:     []byte(`{}`),
+	}, []byte(`{}`))
+	assert.Len(t, router.evaluatedRequests, maxEvaluatedRequests)
+	assert.Equal(t, before+1, dropped())
+}
+
+func TestEvaluates(t *testing.T) {
+	assert.False(t, evaluates(nil))
+	assert.True(t, evaluates(&aiv1alpha1.Evaluation{URL: "http://sink", Percentage: ptr.To(int32(100))}))
+	assert.False(t, evaluates(&aiv1alpha1.Evaluation{URL: "http://sink", Percentage: ptr.To(int32(0))}))
+
+	evaluated := 0
+	for range 10000 {
+		if evaluates(&aiv1alpha1.Evaluation{URL: "http://sink"}) {
+			evaluated++
+		}
+	}
+	assert.InDelta(t, 1000, evaluated, 300)
+}
@@ -0,0 +1,48 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"slices"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+// experimentHeader opts a request in the experiment it names.
+const experimentHeader = "x-kthena-experiment"
+
+// selectExperiment returns the first configured experiment the request is opted in, by its header or its
+// consumer, or an empty string. Experiments which are not configured can't be opted in.
+func selectExperiment(c *gin.Context, experiments []conf.ExperimentConfig) string {
+	if len(experiments) == 0 {
+		return ""
+	}
+	header := c.Request.Header.Get(experimentHeader)
+	userID := c.GetString(common.UserIdKey)
+	for _, experiment := range experiments {
+		if header == experiment.Name && !experiment.DisableHeader {
+			return experiment.Name
+		}
+		if userID != "" && slices.Contains(experiment.Consumers, userID) {
+			return experiment.Name
+		}
+	}
+	return ""
+}
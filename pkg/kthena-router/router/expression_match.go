@@ -0,0 +1,39 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/filters/auth"
+)
+
+// setRequestAttributes records the input tokens of the request and the attributes of its consumer, read by the CEL
+// expressions matching the rules of the ModelRoutes.
+func (r *Router) setRequestAttributes(c *gin.Context, inputTokens int) {
+	subject := c.GetString(common.UserIdKey)
+	team, org := r.quotas.Groups(subject)
+	consumer := map[string]string{
+		"subject":       subject,
+		"authenticator": auth.AuthenticatorOf(c),
+		"team":          team,
+		"org":           org,
+	}
+	c.Request = c.Request.WithContext(datastore.WithRequestAttributes(c.Request.Context(), inputTokens, consumer))
+}
@@ -0,0 +1,61 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/utils"
+)
+
+// tenantHeader identifies the tenant of an unauthenticated request for fairness scheduling.
+const tenantHeader = "x-kthena-tenant"
+
+// defaultTenantWeight is the weight of the tenants the ModelRoute gives no weight to.
+const defaultTenantWeight = 1.0
+
+// requestTenant returns the tenant of the request: the subject of its API key if it is authenticated, the tenant
+// header otherwise. It returns an empty string if the tenant is unknown.
+func requestTenant(c *gin.Context) string {
+	if userID := c.GetString(common.UserIdKey); userID != "" {
+		return userID
+	}
+	return c.Request.Header.Get(tenantHeader)
+}
+
+// tenantWeight returns the weight of the tenant declared by the tenant weights annotation of the ModelRoute.
+func tenantWeight(modelRoute *aiv1alpha1.ModelRoute, tenant string) float64 {
+	if modelRoute == nil {
+		return defaultTenantWeight
+	}
+	value, ok := modelRoute.Annotations[aiv1alpha1.TenantWeightsAnnotationKey]
+	if !ok {
+		return defaultTenantWeight
+	}
+	weights, err := utils.ParseTenantWeights(value)
+	if err != nil {
+		klog.Warningf("ignoring the tenant weights of ModelRoute %s/%s: %v", modelRoute.Namespace, modelRoute.Name, err)
+		return defaultTenantWeight
+	}
+	if weight, ok := weights[tenant]; ok {
+		return weight
+	}
+	return defaultTenantWeight
+}
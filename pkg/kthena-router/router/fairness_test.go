@@ -0,0 +1,58 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+)
+
+func TestRequestTenant(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	assert.Empty(t, requestTenant(c))
+
+	c.Request.Header.Set(tenantHeader, "team-a")
+	assert.Equal(t, "team-a", requestTenant(c))
+
+	c.Set(common.UserIdKey, "alice")
+	assert.Equal(t, "alice", requestTenant(c), "the subject of the API key prevails over the header")
+}
+
+func TestTenantWeight(t *testing.T) {
+	route := func(weights string) *aiv1alpha1.ModelRoute {
+		return &aiv1alpha1.ModelRoute{ObjectMeta: metav1.ObjectMeta{
+			Name:        "route",
+			Namespace:   "default",
+			Annotations: map[string]string{aiv1alpha1.TenantWeightsAnnotationKey: weights},
+		}}
+	}
+	assert.Equal(t, 3.0, tenantWeight(route("team-a=3,team-b=0.5"), "team-a"))
+	assert.Equal(t, 0.5, tenantWeight(route("team-a=3,team-b=0.5"), "team-b"))
+	assert.Equal(t, defaultTenantWeight, tenantWeight(route("team-a=3"), "team-c"))
+	assert.Equal(t, defaultTenantWeight, tenantWeight(route("team-a=-1"), "team-a"), "an invalid annotation is ignored")
+	assert.Equal(t, defaultTenantWeight, tenantWeight(&aiv1alpha1.ModelRoute{}, "team-a"))
+	assert.Equal(t, defaultTenantWeight, tenantWeight(nil, "team-a"))
+}
@@ -0,0 +1,233 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+)
+
+// defaultFallbackStatusCodes are the status codes of the model server responses triggering a fallback if the
+// ModelRoute doesn't set them.
+var defaultFallbackStatusCodes = []int32{
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// upstreamStatusError is returned for a model server response with a non-2xx status code.
+type upstreamStatusError struct {
+	statusCode int
+}
+
+func (e *upstreamStatusError) Error() string {
+	return fmt.Sprintf("http resp error, http code is %d", e.statusCode)
+}
+
+// backendFailedError is returned when a request failed on every pod selected for it. Nothing has been sent to the
+// client yet, the request is answered with the status and message of the error unless a fallback serves it.
+type backendFailedError struct {
+	status  int
+	message string
+	// err is the error of the last pod tried.
+	err error
+}
+
+func (e *backendFailedError) Error() string {
+	return e.message
+}
+
+func (e *backendFailedError) Unwrap() error {
+	return e.err
+}
+
+// abortProxyFailure answers a request that could not be proxied to any backend.
+func abortProxyFailure(c *gin.Context, err error) {
+	var invalid *invalidStructuredOutputError
+	if errors.As(err, &invalid) && !c.Writer.Written() {
+		rejectInvalidStructuredOutput(c, invalid)
+		return
+	}
+	var failed *backendFailedError
+	if errors.As(err, &failed) && !c.Writer.Written() {
+		accesslog.SetError(c, "proxy", failed.message)
+		c.AbortWithStatusJSON(failed.status, failed.message)
+		return
+	}
+	if c.Writer.Written() {
+		abortStartedResponse(c, err)
+		return
+	}
+	accesslog.SetError(c, "proxy", "request processing failed")
+	c.AbortWithStatusJSON(http.StatusInternalServerError, "request processing failed")
+}
+
+// abortStartedResponse ends a response which was already started downstream when proxying failed. A stream is ended
+// with a terminal error event, as its status can't be changed anymore.
+func abortStartedResponse(c *gin.Context, err error) {
+	accesslog.SetError(c, "proxy", err.Error())
+	c.Abort()
+	if !strings.HasPrefix(c.Writer.Header().Get("Content-Type"), "text/event-stream") {
+		return
+	}
+	event, _ := json.Marshal(handlers.OpenAIError{
+		Error: handlers.OpenAIErrorDetail{Message: err.Error(), Type: "server_error"},
+	})
+	_, _ = fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", event)
+	c.Writer.Flush()
+}
+
+// triggersFallback reports whether a backend failing with err is replaced by the next fallback ModelServer.
+// The responses of the model servers trigger it according to their status code, any other failure, e.g. a
+// connection error or a timeout, always triggers it.
+func triggersFallback(fallback *v1alpha1.Fallback, err error) bool {
+	var statusErr *upstreamStatusError
+	if !errors.As(err, &statusErr) {
+		return true
+	}
+	statusCodes := fallback.StatusCodes
+	if len(statusCodes) == 0 {
+		statusCodes = defaultFallbackStatusCodes
+	}
+	return slices.Contains(statusCodes, int32(statusErr.statusCode))
+}
+
+// failureStatus returns the status code of the failure of a backend, 502 if it didn't respond.
+func failureStatus(err error) int {
+	var statusErr *upstreamStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.statusCode
+	}
+	var failed *backendFailedError
+	if errors.As(err, &failed) {
+		return failed.status
+	}
+	return http.StatusBadGateway
+}
+
+// proxyFallback retries the request which failed with err on the fallback ModelServers of the ModelRoute, in order,
+// as long as nothing has been sent to the client and the failure triggers the fallback. It returns the error of the
+// last backend tried, nil if one of them served the request.
+func (r *Router) proxyFallback(
+	c *gin.Context,
+	modelRoute *v1alpha1.ModelRoute,
+	modelRequest ModelRequest,
+	modelName string,
+	isLora bool,
+	prompt common.ChatMessage,
+	err error,
+) error {
+	fallback := modelRoute.Spec.Fallback
+	modelServerNames := fallback.ModelServerNames
+	if fallback.MaxDepth != nil && int(*fallback.MaxDepth) < len(modelServerNames) {
+		modelServerNames = modelServerNames[:*fallback.MaxDepth]
+	}
+	var metricsRecorder *metrics.RequestMetricsRecorder
+	if recorder, exists := c.Get("metricsRecorder"); exists {
+		metricsRecorder, _ = recorder.(*metrics.RequestMetricsRecorder)
+	}
+	for _, name := range modelServerNames {
+		if c.Writer.Written() || c.Request.Context().Err() != nil || !triggersFallback(fallback, err) {
+			return err
+		}
+		modelServerName := types.NamespacedName{Namespace: modelRoute.Namespace, Name: name}
+		klog.V(4).Infof("request %s falls back to model server %v: %v", c.Request.Header.Get("x-request-id"), modelServerName, err)
+		if metricsRecorder != nil {
+			metricsRecorder.FallBack(modelServerName.String(), strconv.Itoa(failureStatus(err)))
+		}
+		err = r.proxyFallbackModelServer(c, modelRoute, modelServerName, modelRequest, modelName, isLora, prompt)
+		if err != nil {
+			klog.Errorf("fallback to model server %v failed: %v", modelServerName, err)
+		}
+	}
+	return err
+}
+
+// proxyFallbackModelServer schedules and proxies the request to a fallback ModelServer.
+func (r *Router) proxyFallbackModelServer(
+	c *gin.Context,
+	modelRoute *v1alpha1.ModelRoute,
+	modelServerName types.NamespacedName,
+	modelRequest ModelRequest,
+	modelName string,
+	isLora bool,
+	prompt common.ChatMessage,
+) error {
+	pods, modelServer, err := r.getPodsAndServer(modelServerName)
+	if err != nil {
+		return err
+	}
+	modelRequest["model"] = modelName
+	if model := modelServer.Spec.Model; model != nil && !isLora {
+		if pods, _ = datastore.PodsServingModel(pods, *model); len(pods) == 0 {
+			return fmt.Errorf("model %s has been replaced on model server %v", *model, modelServerName)
+		}
+		modelRequest["model"] = *model
+	}
+	if limit := modelServer.Spec.MaxContextLength; limit != nil && checkContextWindow(modelRequest, c.GetInt("inputTokens"), *limit) != nil {
+		return fmt.Errorf("request exceeds the context window of model server %v", modelServerName)
+	}
+
+	var metricsRecorder *metrics.RequestMetricsRecorder
+	if recorder, exists := c.Get("metricsRecorder"); exists {
+		metricsRecorder, _ = recorder.(*metrics.RequestMetricsRecorder)
+	}
+	var pdGroup *v1alpha1.PDGroup
+	if modelServer.Spec.WorkloadSelector != nil {
+		pdGroup = modelServer.Spec.WorkloadSelector.PDGroup
+	}
+	ctx := &framework.Context{
+		Model:               modelName,
+		Prompt:              prompt,
+		ModelServerName:     modelServerName,
+		PDGroup:             pdGroup,
+		MetricsRecorder:     metricsRecorder,
+		LoadBalancingPolicy: modelServer.Spec.LoadBalancingPolicy,
+		SlowStart:           modelServer.Spec.SlowStart,
+		SessionID:           c.Request.Header.Get(sessionIDHeader),
+	}
+	if err := r.scheduler.Schedule(ctx, pods); err != nil {
+		return fmt.Errorf("can't schedule to target pod: %w", err)
+	}
+
+	r.metrics.RecordFallbackRequest(modelName, modelServerName.String())
+	c.Header(modelServerHeader, modelServerName.String())
+	selectedPod := ""
+	if len(ctx.BestPods) > 0 && ctx.BestPods[0].Pod != nil {
+		selectedPod = ctx.BestPods[0].Pod.Name
+	}
+	accesslog.SetRequestRouting(c, fmt.Sprintf("%s/%s", modelRoute.Namespace, modelRoute.Name), modelServerName.String(), selectedPod)
+	return r.proxyModelEndpoint(c, c.Request, ctx, modelRequest, modelServer.Spec.WorkloadPort.Port)
+}
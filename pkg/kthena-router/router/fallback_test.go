@@ -0,0 +1,195 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+func TestRouter_HandlerFunc_Fallback(t *testing.T) {
+	var primaryStatus atomic.Int32
+	router, store, primary := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(primaryStatus.Load()))
+	}))
+	defer primary.Close()
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+	var healthyCalls atomic.Int32
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		healthyCalls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		var reqBody ModelRequest
+		_ = json.Unmarshal(body, &reqBody)
+		assert.Equal(t, "model-healthy", reqBody["model"])
+		fmt.Fprint(w, `{"id":"response-id"}`)
+	}))
+	defer healthy.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	backends := map[string]*httptest.Server{"primary": primary, "unavailable": unavailable, "healthy": healthy, "down": down}
+	for name, backend := range backends {
+		backendURL, _ := url.Parse(backend.URL)
+		backendPort, _ := strconv.Atoi(backendURL.Port())
+		modelServer := &aiv1alpha1.ModelServer{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: aiv1alpha1.ModelServerSpec{
+				Model:           ptr.To("model-" + name),
+				WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+				InferenceEngine: "vLLM",
+			},
+		}
+		podName := types.NamespacedName{Name: "pod-" + name, Namespace: "default"}
+		store.AddOrUpdateModelServer(modelServer, sets.New(podName))
+		store.AddOrUpdatePod(&corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{Name: podName.Name, Namespace: "default"},
+			Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+		}, []*aiv1alpha1.ModelServer{modelServer})
+	}
+
+	tests := []struct {
+		name            string
+		modelServer     string
+		primaryStatus   int
+		fallback        *aiv1alpha1.Fallback
+		wantCode        int
+		wantModelServer string
+		wantHealthy     int32
+	}{
+		{
+			name:            "no fallback",
+			modelServer:     "primary",
+			primaryStatus:   http.StatusServiceUnavailable,
+			wantCode:        http.StatusNotFound,
+			wantModelServer: "default/primary",
+		},
+		{
+			name:            "primary returns 503",
+			modelServer:     "primary",
+			primaryStatus:   http.StatusServiceUnavailable,
+			fallback:        &aiv1alpha1.Fallback{ModelServerNames: []string{"unavailable", "healthy"}},
+			wantCode:        http.StatusOK,
+			wantModelServer: "default/healthy",
+			wantHealthy:     1,
+		},
+		{
+			name:            "primary is down",
+			modelServer:     "down",
+			fallback:        &aiv1alpha1.Fallback{ModelServerNames: []string{"healthy"}},
+			wantCode:        http.StatusOK,
+			wantModelServer: "default/healthy",
+			wantHealthy:     1,
+		},
+		{
+			name:            "missing fallback model server is skipped",
+			modelServer:     "primary",
+			primaryStatus:   http.StatusBadGateway,
+			fallback:        &aiv1alpha1.Fallback{ModelServerNames: []string{"missing", "healthy"}},
+			wantCode:        http.StatusOK,
+			wantModelServer: "default/healthy",
+			wantHealthy:     1,
+		},
+		{
+			name:            "max depth reached",
+			modelServer:     "primary",
+			primaryStatus:   http.StatusServiceUnavailable,
+			fallback:        &aiv1alpha1.Fallback{ModelServerNames: []string{"unavailable", "healthy"}, MaxDepth: ptr.To(int32(1))},
+			wantCode:        http.StatusNotFound,
+			wantModelServer: "default/unavailable",
+		},
+		{
+			name:            "status code not triggering the fallback",
+			modelServer:     "primary",
+			primaryStatus:   http.StatusTooManyRequests,
+			fallback:        &aiv1alpha1.Fallback{ModelServerNames: []string{"healthy"}},
+			wantCode:        http.StatusNotFound,
+			wantModelServer: "default/primary",
+		},
+		{
+			name:            "configured status code",
+			modelServer:     "primary",
+			primaryStatus:   http.StatusTooManyRequests,
+			fallback:        &aiv1alpha1.Fallback{ModelServerNames: []string{"healthy"}, StatusCodes: []int32{http.StatusTooManyRequests}},
+			wantCode:        http.StatusOK,
+			wantModelServer: "default/healthy",
+			wantHealthy:     1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primaryStatus.Store(int32(tt.primaryStatus))
+			healthyCalls.Store(0)
+			store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+				ObjectMeta: v1.ObjectMeta{Name: "mr-1", Namespace: "default"},
+				Spec: aiv1alpha1.ModelRouteSpec{
+					ModelName: "test-model",
+					Rules: []*aiv1alpha1.Rule{
+						{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: tt.modelServer}}},
+					},
+					Fallback: tt.fallback,
+				},
+			})
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(`{"model": "test-model", "prompt": "hello"}`))
+			router.HandlerFunc()(c)
+
+			require.Equal(t, tt.wantCode, w.Code)
+			if tt.wantCode == http.StatusOK {
+				assert.Contains(t, w.Body.String(), `"id":"response-id"`)
+			} else {
+				assert.Equal(t, `"request to all pods failed"`, w.Body.String())
+			}
+			assert.Equal(t, tt.wantModelServer, w.Header().Get(modelServerHeader))
+			assert.Equal(t, tt.wantHealthy, healthyCalls.Load())
+		})
+	}
+}
+
+func TestTriggersFallback(t *testing.T) {
+	fallback := &aiv1alpha1.Fallback{ModelServerNames: []string{"ms-2"}}
+	assert.True(t, triggersFallback(fallback, fmt.Errorf("dial tcp: connection refused")))
+	assert.True(t, triggersFallback(fallback, &backendFailedError{err: fmt.Errorf("decode request error: %w", &upstreamStatusError{statusCode: 504})}))
+	assert.False(t, triggersFallback(fallback, &backendFailedError{err: &upstreamStatusError{statusCode: 400}}))
+
+	fallback.StatusCodes = []int32{429}
+	assert.True(t, triggersFallback(fallback, &upstreamStatusError{statusCode: 429}))
+	assert.False(t, triggersFallback(fallback, &upstreamStatusError{statusCode: 503}))
+}
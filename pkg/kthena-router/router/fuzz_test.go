@@ -0,0 +1,98 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/filters/tokenizer"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/utils"
+)
+
+// The fuzz targets run their seed corpus with the unit tests, `make fuzz` fuzzes them.
+
+// FuzzParseModelRequest feeds arbitrary bodies to the parsing of the requests and of their prompts, which must reject
+// the malformed ones without panicking.
+func FuzzParseModelRequest(f *testing.F) {
+	for _, seed := range []string{
+		`{"model":"llama","prompt":"hello"}`,
+		`{"model":"llama","messages":[{"role":"user","content":"hello"},{"role":"assistant","content":"hi"}],"stream":true}`,
+		`{"model":"llama","messages":[{"role":"user","content":[{"type":"text","text":"hello"}]}]}`,
+		`{"model":"llama","messages":"hello"}`,
+		`{"model":"llama","prompt":["hello"]}`,
+		`{"model":1}`,
+		`{"model":"rerank","query":"capital","documents":["Paris",{"text":"Rome"}],"top_n":1}`,
+		`{"model":"sd","prompt":"a cat","n":2,"size":"512x512"}`,
+		`{"model":null}`,
+		`[]`,
+		`null`,
+		`{`,
+		``,
+	} {
+		f.Add([]byte(seed))
+	}
+	gin.SetMode(gin.TestMode)
+	estimator := tokenizer.NewSimpleEstimateTokenizer()
+	f.Fuzz(func(t *testing.T, body []byte) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", bytes.NewReader(body))
+		modelRequest, err := ParseModelRequest(c)
+		if err != nil {
+			if !c.IsAborted() {
+				t.Fatalf("the request is not aborted on error %v", err)
+			}
+			return
+		}
+
+		if prompt, err := utils.ParsePrompt(modelRequest); err == nil {
+			if _, err := estimator.CalculateTokenNum(utils.GetPromptString(prompt)); err != nil {
+				t.Fatalf("failed to count the tokens of the prompt: %v", err)
+			}
+		}
+		_, _ = parseImageRequest(modelRequest)
+		for _, endpoint := range scoringEndpoints {
+			_, _, _, _ = parseScoringRequest(modelRequest, endpoint)
+		}
+	})
+}
+
+// FuzzParseAudioRequest feeds arbitrary multipart forms to the parsing of the audio requests.
+func FuzzParseAudioRequest(f *testing.F) {
+	const contentType = "multipart/form-data; boundary=fuzz"
+	for _, seed := range [][]byte{
+		[]byte("--fuzz\r\nContent-Disposition: form-data; name=\"model\"\r\n\r\nwhisper\r\n" +
+			"--fuzz\r\nContent-Disposition: form-data; name=\"file\"; filename=\"speech.wav\"\r\n\r\n" +
+			string(wavFile(1)) + "\r\n--fuzz--\r\n"),
+		[]byte("--fuzz\r\nContent-Disposition: form-data; name=\"file\"; filename=\"speech.wav\"\r\n\r\nRIFF\r\n--fuzz--\r\n"),
+		[]byte("--fuzz\r\n\r\n--fuzz--"),
+		{},
+	} {
+		f.Add(seed, contentType)
+	}
+	f.Add([]byte(`{"model":"whisper"}`), "application/json")
+	f.Fuzz(func(t *testing.T, body []byte, contentType string) {
+		audio, err := parseAudioRequest(body, contentType, 1<<20)
+		if err == nil && (audio.model == "" || audio.fileSize > 1<<20) {
+			t.Fatalf("invalid audio request accepted: model %q, file of %d bytes", audio.model, audio.fileSize)
+		}
+	})
+}
@@ -0,0 +1,236 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/gatewayapi"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+)
+
+const (
+	// grpcContentType prefixes the content type of gRPC requests, e.g. application/grpc+proto.
+	grpcContentType = "application/grpc"
+	// grpcModelHeader carries the model of gRPC requests, whose protobuf body isn't parsed by the router.
+	// It is the header body based routing of the Gateway API Inference Extension sets.
+	grpcModelHeader = "x-gateway-model-name"
+)
+
+// gRPC status codes returned by the router, see https://grpc.github.io/grpc/core/md_doc_statuscodes.html
+const (
+	grpcStatusInvalidArgument   = 3
+	grpcStatusNotFound          = 5
+	grpcStatusPermissionDenied  = 7
+	grpcStatusResourceExhausted = 8
+	grpcStatusUnimplemented     = 12
+	grpcStatusUnavailable       = 14
+	grpcStatusUnauthenticated   = 16
+)
+
+// grpcTransport forwards gRPC requests to the model server pods over HTTP/2 cleartext.
+var grpcTransport = func() *http.Transport {
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Protocols = protocols
+	return transport
+}()
+
+// isGRPCRequest reports whether req is a gRPC call, which are routed by GRPCRoutes, or by model for the KServe v2
+// inference calls.
+func isGRPCRequest(req *http.Request) bool {
+	return req.ProtoMajor == 2 && strings.HasPrefix(req.Header.Get("Content-Type"), grpcContentType)
+}
+
+// handleGRPC routes a gRPC call to the InferencePool backend of the GRPCRoute of the gateway it matches.
+// The call is proxied as is, the body being streamed in both directions. The KServe v2 inference calls matching no
+// GRPCRoute are routed by their model.
+func (r *Router) handleGRPC(c *gin.Context) {
+	route, rule := matchGRPCRoute(routesOfGateways(c.GetStringSlice(GatewayKey), r.store.GetGRPCRoutesByGateway), c.Request)
+	if rule == nil && isKServeCall(c.Request) {
+		r.handleKServe(c)
+		return
+	}
+	if rule == nil {
+		writeGRPCError(c, grpcStatusUnimplemented, "route not found")
+		return
+	}
+
+	var inferencePoolName types.NamespacedName
+	found := false
+	for _, backendRef := range rule.BackendRefs {
+		if inferencePoolName, found = gatewayapi.InferencePoolBackend(route.Namespace, backendRef.BackendRef); found {
+			break
+		}
+	}
+	if !found {
+		writeGRPCError(c, grpcStatusUnimplemented, fmt.Sprintf("GRPCRoute %s/%s has no InferencePool backend", route.Namespace, route.Name))
+		return
+	}
+	// GRPCRoutes have no header policy, only the headers denied by default are removed.
+	handlers.ApplyHeaderPolicy(c, nil)
+	for _, filter := range rule.Filters {
+		if filter.Type == gatewayv1.GRPCRouteFilterRequestHeaderModifier && filter.RequestHeaderModifier != nil {
+			applyRequestHeaderModifier(c.Request, filter.RequestHeaderModifier)
+		}
+	}
+
+	inferencePool := r.store.GetInferencePool(inferencePoolName.String())
+	if inferencePool == nil || len(inferencePool.Spec.TargetPorts) == 0 {
+		writeGRPCError(c, grpcStatusUnavailable, fmt.Sprintf("can't find inference pool: %v", inferencePoolName))
+		return
+	}
+	pods, err := r.store.GetPodsByInferencePool(inferencePoolName)
+	if err != nil || len(pods) == 0 {
+		writeGRPCError(c, grpcStatusUnavailable, fmt.Sprintf("can't find pods for inference pool: %v", inferencePoolName))
+		return
+	}
+	port := int32(inferencePool.Spec.TargetPorts[0].Number)
+
+	ctx := &framework.Context{
+		Model:      c.Request.Header.Get(grpcModelHeader),
+		Experiment: selectExperiment(c, r.experiments),
+	}
+	if err := r.scheduler.Schedule(ctx, pods); err != nil || len(ctx.BestPods) == 0 {
+		writeGRPCError(c, grpcStatusUnavailable, fmt.Sprintf("can't schedule to target pod: %v", err))
+		return
+	}
+
+	// The request body is streamed to the pod, it can't be retried on another one.
+	podIP := ctx.BestPods[0].Pod.Status.PodIP
+	failed := false
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.Out.URL.Scheme = "http"
+			pr.Out.URL.Host = fmt.Sprintf("%s:%d", podIP, port)
+			pr.SetXForwarded()
+		},
+		Transport:     grpcTransport,
+		FlushInterval: -1,
+		ErrorHandler: func(_ http.ResponseWriter, req *http.Request, err error) {
+			failed = true
+			klog.Errorf("gRPC request %s to pod %s failed: %v", req.URL.Path, podIP, err)
+			writeGRPCError(c, grpcStatusUnavailable, "request to pod failed")
+		},
+	}
+	klog.V(4).Infof("GRPCRoute %s/%s routes %s to pod %s of inference pool %v", route.Namespace, route.Name, c.Request.URL.Path, podIP, inferencePoolName)
+	proxy.ServeHTTP(c.Writer, c.Request)
+	if !failed {
+		r.scheduler.RunPostHooks(ctx, 0)
+	}
+}
+
+// matchGRPCRoute returns the first route and rule matching the method and headers of req. Routes are
+// considered from the oldest to the newest, as the Gateway API resolves conflicts between routes.
+func matchGRPCRoute(routes []*gatewayv1.GRPCRoute, req *http.Request) (*gatewayv1.GRPCRoute, *gatewayv1.GRPCRouteRule) {
+	routes = slices.DeleteFunc(slices.Clone(routes), func(route *gatewayv1.GRPCRoute) bool { return route == nil })
+	slices.SortFunc(routes, func(a, b *gatewayv1.GRPCRoute) int {
+		if cmp := a.CreationTimestamp.Time.Compare(b.CreationTimestamp.Time); cmp != 0 {
+			return cmp
+		}
+		return strings.Compare(a.Namespace+"/"+a.Name, b.Namespace+"/"+b.Name)
+	})
+
+	// The path of gRPC calls is /<service>/<method>
+	service, method, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, "/"), "/")
+	for _, route := range routes {
+		for i := range route.Spec.Rules {
+			rule := &route.Spec.Rules[i]
+			if len(rule.Matches) == 0 {
+				return route, rule
+			}
+			for _, match := range rule.Matches {
+				if matchGRPCMethod(match.Method, service, method) && matchGRPCHeaders(match.Headers, req.Header) {
+					return route, rule
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+func matchGRPCMethod(match *gatewayv1.GRPCMethodMatch, service, method string) bool {
+	if match == nil {
+		return true
+	}
+	regex := match.Type != nil && *match.Type == gatewayv1.GRPCMethodMatchRegularExpression
+	return matchGRPCValue(match.Service, service, regex) && matchGRPCValue(match.Method, method, regex)
+}
+
+func matchGRPCHeaders(matches []gatewayv1.GRPCHeaderMatch, header http.Header) bool {
+	for _, match := range matches {
+		regex := match.Type != nil && *match.Type == gatewayv1.GRPCHeaderMatchRegularExpression
+		values, ok := header[http.CanonicalHeaderKey(string(match.Name))]
+		if !ok || !matchGRPCValue(&match.Value, strings.Join(values, ","), regex) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchGRPCValue matches value against pattern, a nil pattern matches any value.
+func matchGRPCValue(pattern *string, value string, regex bool) bool {
+	if pattern == nil {
+		return true
+	}
+	if !regex {
+		return *pattern == value
+	}
+	matched, err := regexp.MatchString("^(?:"+*pattern+")$", value)
+	if err != nil {
+		klog.Warningf("Invalid regex pattern '%s' in GRPCRoute: %v", *pattern, err)
+	}
+	return matched
+}
+
+func applyRequestHeaderModifier(req *http.Request, modifier *gatewayv1.HTTPHeaderFilter) {
+	for _, h := range modifier.Set {
+		req.Header.Set(string(h.Name), h.Value)
+	}
+	for _, h := range modifier.Add {
+		req.Header.Add(string(h.Name), h.Value)
+	}
+	for _, name := range modifier.Remove {
+		req.Header.Del(name)
+	}
+}
+
+// writeGRPCError ends a gRPC call with a trailers-only response carrying the status code and message.
+func writeGRPCError(c *gin.Context, code int, message string) {
+	if c.Writer.Written() {
+		return
+	}
+	c.Header("Content-Type", grpcContentType)
+	c.Header("Grpc-Status", strconv.Itoa(code))
+	c.Header("Grpc-Message", message)
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	c.Abort()
+}
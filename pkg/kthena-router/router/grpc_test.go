@@ -0,0 +1,235 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	inferencev1 "sigs.k8s.io/gateway-api-inference-extension/api/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func grpcMethodMatch(matchType gatewayv1.GRPCMethodMatchType, service, method string) gatewayv1.GRPCRouteMatch {
+	match := &gatewayv1.GRPCMethodMatch{Type: &matchType}
+	if service != "" {
+		match.Service = &service
+	}
+	if method != "" {
+		match.Method = &method
+	}
+	return gatewayv1.GRPCRouteMatch{Method: match}
+}
+
+func TestMatchGRPCRoute(t *testing.T) {
+	now := time.Now()
+	older := &gatewayv1.GRPCRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "older", Namespace: "default", CreationTimestamp: v1.NewTime(now.Add(-time.Hour))},
+		Spec: gatewayv1.GRPCRouteSpec{Rules: []gatewayv1.GRPCRouteRule{
+			{Name: ptr.To[gatewayv1.SectionName]("generate"), Matches: []gatewayv1.GRPCRouteMatch{
+				grpcMethodMatch(gatewayv1.GRPCMethodMatchExact, "inference.GRPCInferenceService", "ModelInfer"),
+			}},
+			{Name: ptr.To[gatewayv1.SectionName]("canary"), Matches: []gatewayv1.GRPCRouteMatch{{
+				Method: &gatewayv1.GRPCMethodMatch{Service: ptr.To("inference.GRPCInferenceService")},
+				Headers: []gatewayv1.GRPCHeaderMatch{
+					{Name: "x-canary", Value: "true"},
+				},
+			}}},
+			{Name: ptr.To[gatewayv1.SectionName]("health"), Matches: []gatewayv1.GRPCRouteMatch{
+				grpcMethodMatch(gatewayv1.GRPCMethodMatchRegularExpression, `grpc\.health\.v1\..*`, ""),
+			}},
+		}},
+	}
+	newer := &gatewayv1.GRPCRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "newer", Namespace: "default", CreationTimestamp: v1.NewTime(now)},
+		Spec: gatewayv1.GRPCRouteSpec{Rules: []gatewayv1.GRPCRouteRule{
+			{Name: ptr.To[gatewayv1.SectionName]("any")},
+		}},
+	}
+
+	tests := []struct {
+		name          string
+		routes        []*gatewayv1.GRPCRoute
+		path          string
+		header        map[string]string
+		expectedRoute string
+		expectedRule  gatewayv1.SectionName
+	}{
+		{
+			name:          "exact service and method",
+			routes:        []*gatewayv1.GRPCRoute{older},
+			path:          "/inference.GRPCInferenceService/ModelInfer",
+			expectedRoute: "older",
+			expectedRule:  "generate",
+		},
+		{
+			name:   "exact method not matched",
+			routes: []*gatewayv1.GRPCRoute{older},
+			path:   "/inference.GRPCInferenceService/ModelReady",
+		},
+		{
+			name:          "service with header",
+			routes:        []*gatewayv1.GRPCRoute{older},
+			path:          "/inference.GRPCInferenceService/ModelReady",
+			header:        map[string]string{"X-Canary": "true"},
+			expectedRoute: "older",
+			expectedRule:  "canary",
+		},
+		{
+			name:          "regular expression",
+			routes:        []*gatewayv1.GRPCRoute{older},
+			path:          "/grpc.health.v1.Health/Check",
+			expectedRoute: "older",
+			expectedRule:  "health",
+		},
+		{
+			name:          "regular expression matches the whole service",
+			routes:        []*gatewayv1.GRPCRoute{older, newer},
+			path:          "/my.grpc.health.v1.Health/Check",
+			expectedRoute: "newer",
+			expectedRule:  "any",
+		},
+		{
+			name:          "oldest route first",
+			routes:        []*gatewayv1.GRPCRoute{newer, older},
+			path:          "/inference.GRPCInferenceService/ModelInfer",
+			expectedRoute: "older",
+			expectedRule:  "generate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			route, rule := matchGRPCRoute(tt.routes, req)
+			if tt.expectedRoute == "" {
+				assert.Nil(t, rule)
+				return
+			}
+			require.NotNil(t, rule)
+			assert.Equal(t, tt.expectedRoute, route.Name)
+			assert.Equal(t, tt.expectedRule, *rule.Name)
+		})
+	}
+}
+
+func TestRouter_HandlerFunc_GRPC(t *testing.T) {
+	backendHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, 2, r.ProtoMajor)
+		assert.Equal(t, "/inference.GRPCInferenceService/ModelInfer", r.URL.Path)
+		assert.Equal(t, "llama", r.Header.Get("X-Model"))
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", grpcContentType)
+		w.Header().Set("Trailer", "Grpc-Status")
+		_, _ = w.Write(body)
+		w.Header().Set("Grpc-Status", "0")
+	})
+	backend := httptest.NewUnstartedServer(backendHandler)
+	backend.Config.Protocols = new(http.Protocols)
+	backend.Config.Protocols.SetUnencryptedHTTP2(true)
+	backend.Start()
+	defer backend.Close()
+	router, store, unused := setupTestRouter(nil)
+	unused.Close()
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	require.NoError(t, store.AddOrUpdateInferencePool(&inferencev1.InferencePool{
+		ObjectMeta: v1.ObjectMeta{Name: "triton", Namespace: "default"},
+		Spec: inferencev1.InferencePoolSpec{
+			Selector:    inferencev1.LabelSelector{MatchLabels: map[inferencev1.LabelKey]inferencev1.LabelValue{"app": "triton"}},
+			TargetPorts: []inferencev1.Port{{Number: inferencev1.PortNumber(backendPort)}},
+		},
+	}))
+	require.NoError(t, store.AddOrUpdatePod(&corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "triton-0", Namespace: "default", Labels: map[string]string{"app": "triton"}},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}, nil))
+	require.NoError(t, store.AddOrUpdateGRPCRoute(&gatewayv1.GRPCRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "triton", Namespace: "default"},
+		Spec: gatewayv1.GRPCRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: []gatewayv1.ParentReference{
+				{Kind: ptr.To[gatewayv1.Kind]("Gateway"), Name: "default"},
+			}},
+			Rules: []gatewayv1.GRPCRouteRule{{
+				Matches: []gatewayv1.GRPCRouteMatch{
+					grpcMethodMatch(gatewayv1.GRPCMethodMatchExact, "inference.GRPCInferenceService", "ModelInfer"),
+				},
+				Filters: []gatewayv1.GRPCRouteFilter{{
+					Type: gatewayv1.GRPCRouteFilterRequestHeaderModifier,
+					RequestHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+						Set: []gatewayv1.HTTPHeader{{Name: "x-model", Value: "llama"}},
+					},
+				}},
+				BackendRefs: []gatewayv1.GRPCBackendRef{{BackendRef: gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{
+					Group: ptr.To[gatewayv1.Group]("inference.networking.k8s.io"),
+					Kind:  ptr.To[gatewayv1.Kind]("InferencePool"),
+					Name:  "triton",
+				}}}},
+			}},
+		},
+	}))
+
+	engine := gin.New()
+	engine.Any("/*path", func(c *gin.Context) {
+		c.Set(GatewayKey, []string{"default/default"})
+		router.HandlerFunc()(c)
+	})
+	server := httptest.NewUnstartedServer(engine)
+	server.Config.Protocols = new(http.Protocols)
+	server.Config.Protocols.SetUnencryptedHTTP2(true)
+	server.Start()
+	defer server.Close()
+	client := &http.Client{Transport: grpcTransport}
+
+	call := func(path string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, server.URL+path, strings.NewReader("payload"))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/grpc+proto")
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := call("/inference.GRPCInferenceService/ModelInfer")
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "payload", string(body))
+	assert.Equal(t, "0", resp.Trailer.Get("Grpc-Status"))
+
+	resp = call("/grpc.health.v1.Health/Check")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, strconv.Itoa(grpcStatusUnimplemented), resp.Header.Get("Grpc-Status"))
+}
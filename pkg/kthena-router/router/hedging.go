@@ -0,0 +1,214 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+)
+
+const (
+	// routeHedgingKey is the gin context key of the hedging of the ModelRoute matched by a request.
+	routeHedgingKey = "routeHedging"
+
+	// Values of the result label of the hedged requests metric: the attempt answering first, or failed when both
+	// attempts failed.
+	hedgePrimary = "primary"
+	hedgeHedge   = "hedge"
+	hedgeFailed  = "failed"
+)
+
+// applyRouteHedging keeps the hedging of the ModelRoute for the attempts on the pods.
+func applyRouteHedging(c *gin.Context, hedging *v1alpha1.Hedging) {
+	if hedging != nil {
+		c.Set(routeHedgingKey, hedging)
+	}
+}
+
+// hedgeDelay returns the delay after which the request of c is hedged, zero when it is not hedged.
+func hedgeDelay(c *gin.Context) time.Duration {
+	if value, ok := c.Get(routeHedgingKey); ok {
+		return value.(*v1alpha1.Hedging).Delay.Duration
+	}
+	return 0
+}
+
+// hedgedAttempt is an attempt of a hedged request on a pod, it competes once the pod answered.
+type hedgedAttempt struct {
+	hedge      bool
+	pod        *datastore.PodInfo
+	attempt    *http.Request
+	resp       *http.Response
+	start      time.Time
+	firstToken func()
+	done       func()
+	// release releases the concurrency slot of the attempt on its pod.
+	release func()
+	err     error
+}
+
+// close releases the attempt once it failed or lost the race.
+func (a *hedgedAttempt) close() {
+	if a.resp != nil {
+		a.resp.Body.Close()
+	}
+	a.done()
+	a.release()
+}
+
+// sendHedgedAttempt sends req to the pod and waits for the response, and for its first event if it is streamed.
+func sendHedgedAttempt(c *gin.Context, req *http.Request, pod *datastore.PodInfo, backend *upstream, stream bool, hedge bool, release func()) *hedgedAttempt {
+	attempt, firstToken, done := withAttemptTimeouts(c, req, stream)
+	a := &hedgedAttempt{hedge: hedge, pod: pod, attempt: attempt, start: time.Now(), firstToken: firstToken, done: done, release: release}
+	resp, err := doRequest(attempt, pod.Pod.Status.PodIP, backend)
+	if err != nil {
+		a.err = fmt.Errorf("decode request error: %w", attemptError(attempt.Context(), err))
+		return a
+	}
+	a.resp = resp
+	if stream {
+		body := bufio.NewReader(resp.Body)
+		if _, err := body.Peek(1); err != nil {
+			a.err = fmt.Errorf("read response error: %w", attemptError(attempt.Context(), err))
+			return a
+		}
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{body, resp.Body}
+	}
+	return a
+}
+
+// proxyHedgedRequest sends req to the pod at index i of the pods selected for it, and to the next pod too when the
+// first one doesn't answer within delay. The response of the pod answering first is returned to downstream and the
+// other attempt is canceled. releasePod releases the concurrency slot of the first pod, held by the caller, and must
+// be safe to call more than once: the slot of an attempt is released as soon as it fails or loses the race, not when
+// the response of the winner completes. It returns the index of the pod serving the request, or of the last pod the
+// request was sent to when it failed.
+func (r *Router) proxyHedgedRequest(
+	c *gin.Context,
+	req *http.Request,
+	ctx *framework.Context,
+	i int,
+	backend *upstream,
+	stream bool,
+	delay time.Duration,
+	releasePod func(),
+	onUsage func(u handlers.OpenAIResponse),
+) (int, error) {
+	// Each attempt reads its own copy of the body.
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return i, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	results := make(chan *hedgedAttempt, 2)
+	var cancels []context.CancelFunc
+	var releases []func()
+	defer func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}()
+	send := func(index int, release func()) {
+		attemptCtx, cancel := context.WithCancel(req.Context())
+		cancels = append(cancels, cancel)
+		releases = append(releases, release)
+		attempt := req.Clone(attemptCtx)
+		attempt.Body = io.NopCloser(bytes.NewReader(body))
+		hedge := index > i
+		go func() {
+			results <- sendHedgedAttempt(c, attempt, ctx.BestPods[index], backend, stream, hedge, release)
+		}()
+	}
+	send(i, releasePod)
+	last := i
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var winner *hedgedAttempt
+	var lastErr error
+	for pending := 1; winner == nil && pending > 0; {
+		select {
+		case <-timer.C:
+			releaseHedge, acquired := r.concurrency.tryAcquirePod(r.store.GetModelServer(ctx.ModelServerName), ctx.BestPods[i+1])
+			if !acquired {
+				// The next pod is at its concurrency limit, the request isn't hedged.
+				continue
+			}
+			releaseHedge = sync.OnceFunc(releaseHedge)
+			defer releaseHedge()
+			last = i + 1
+			send(last, releaseHedge)
+			pending++
+		case a := <-results:
+			pending--
+			if a.err != nil {
+				a.close()
+				lastErr = a.err
+				continue
+			}
+			winner = a
+			if pending > 0 {
+				// The attempt still pending lost the race. It is canceled and its slot on its pod released right away,
+				// the rest of the attempt is released in the background.
+				loser := 0
+				if !a.hedge {
+					loser = 1
+				}
+				cancels[loser]()
+				releases[loser]()
+				go func() { (<-results).close() }()
+			}
+		}
+	}
+
+	if last > i {
+		result := hedgeFailed
+		if winner != nil {
+			result = hedgePrimary
+			if winner.hedge {
+				result = hedgeHedge
+			}
+		}
+		r.metrics.RecordHedgedRequest(ctx.Model, ctx.ModelServerName.String(), result)
+	}
+	if winner == nil {
+		return last, lastErr
+	}
+	defer winner.done()
+	served := i
+	if winner.hedge {
+		served = last
+	}
+	return served, forwardResponse(c, req, winner.attempt, winner.resp, winner.pod, winner.start, stream, winner.firstToken, onUsage)
+}
@@ -0,0 +1,131 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+)
+
+func TestProxyHedgedRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// Both pods are served by the same backend, the pod a request is sent to is told by its host. The primary pod
+	// sends the headers of streamed responses at once and their first event after slowDelay.
+	const slowDelay = 500 * time.Millisecond
+	var primarySlow atomic.Bool
+	var primaryCanceled, hedges atomic.Int32
+	listener, err := net.Listen("tcp", "0.0.0.0:0")
+	require.NoError(t, err)
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pod := "primary"
+		if strings.HasPrefix(r.Host, "127.0.0.2:") {
+			pod = "hedge"
+			hedges.Add(1)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		if pod == "primary" && primarySlow.Load() {
+			select {
+			case <-time.After(slowDelay):
+			case <-r.Context().Done():
+				primaryCanceled.Add(1)
+				return
+			}
+		}
+		fmt.Fprintf(w, "data: {\"id\":\"%s\"}\n\ndata: [DONE]\n\n", pod)
+	}))
+	backend.Listener.Close()
+	backend.Listener = listener
+	backend.Start()
+	defer backend.Close()
+
+	r := NewRouter(datastore.New(), "")
+	upstream, err := r.newUpstream(nil, int32(listener.Addr().(*net.TCPAddr).Port))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		slow       bool
+		wantServed int
+		wantBody   string
+		wantHedges int32
+		// wantReleased is whether the slot of the primary pod is released before the response completes.
+		wantReleased bool
+	}{
+		{
+			name:       "primary answers before the delay",
+			wantServed: 0,
+			wantBody:   "primary",
+			wantHedges: 0,
+		},
+		{
+			name:         "hedge answers first",
+			slow:         true,
+			wantServed:   1,
+			wantBody:     "hedge",
+			wantHedges:   1,
+			wantReleased: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primarySlow.Store(tt.slow)
+			hedges.Store(0)
+			primaryCanceled.Store(0)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(`{"model":"test-model","stream":true}`))
+			req := c.Request.Clone(c.Request.Context())
+			ctx := &framework.Context{
+				Model:           "test-model",
+				ModelServerName: types.NamespacedName{Namespace: "default", Name: "ms-1"},
+				BestPods:        []*datastore.PodInfo{buildPodInfo("pod-1", "127.0.0.1"), buildPodInfo("pod-2", "127.0.0.2")},
+			}
+
+			var released atomic.Bool
+			releasePrimary := sync.OnceFunc(func() { released.Store(true) })
+			served, err := r.proxyHedgedRequest(c, req, ctx, 0, upstream, true, 50*time.Millisecond, releasePrimary, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantReleased, released.Load(), "the slot of the pod losing the race must be released")
+			assert.Equal(t, tt.wantServed, served)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+			assert.Equal(t, tt.wantHedges, hedges.Load())
+			if tt.slow {
+				assert.Eventually(t, func() bool { return primaryCanceled.Load() == 1 }, time.Second, 10*time.Millisecond,
+					"the request on the pod answering last must be canceled")
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/filters/ratelimit"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+const (
+	imagesGenerationsPath = "/v1/images/generations"
+
+	// defaultImageSize is the size of the generated images if the request doesn't set it, or sets it to auto.
+	defaultImageSize = "1024x1024"
+	// imageCompletedEvent is the stream event carrying a generated image.
+	imageCompletedEvent = "image_generation.completed"
+)
+
+// isImagesPath reports whether path is the OpenAI image generation endpoint.
+func isImagesPath(path string) bool {
+	return path == imagesGenerationsPath
+}
+
+// imageRequest is the routing and metering information of an image generation request.
+type imageRequest struct {
+	model  string
+	n      int
+	width  int
+	height int
+}
+
+// megapixels returns the megapixels of the given number of images of the requested size.
+func (i *imageRequest) megapixels(images int) float64 {
+	return float64(images) * float64(i.width) * float64(i.height) / 1e6
+}
+
+// imageCost returns the price of the images as configured for the model, 0 if no price is configured.
+func imageCost(cost conf.ImageCostConfig, images int, megapixels float64) float64 {
+	return cost.PerImage*float64(images) + cost.PerMegapixel*megapixels
+}
+
+// handleImages routes an image generation request to the ModelServer of its model, typically a diffusion model.
+// Image generation is metered and rate limited by the images generated and their megapixels instead of tokens.
+func (r *Router) handleImages(c *gin.Context) {
+	modelRequest, err := r.parseModelRequest(c)
+	if err != nil {
+		accesslog.SetError(c, "request_parsing", err.Error())
+		return
+	}
+	image, err := parseImageRequest(modelRequest)
+	if err != nil {
+		accesslog.SetError(c, "request_parsing", err.Error())
+		c.AbortWithStatusJSON(http.StatusBadRequest, handlers.NewInvalidRequestError(err.Error(), "", ""))
+		return
+	}
+
+	modelName := image.model
+	accesslog.SetModelName(c, modelName)
+	c.Set("model", modelName)
+	path := c.Request.URL.Path
+	metricsRecorder := metrics.NewRequestMetricsRecorder(r.metrics, modelName, path)
+	r.metrics.IncActiveDownstreamRequests(modelName)
+	defer func() {
+		r.metrics.DecActiveDownstreamRequests(modelName)
+		reason := "successful_request"
+		if finishReason := c.GetString("finishReason"); finishReason != "" {
+			reason = finishReason
+		}
+		metricsRecorder.Finish(strconv.Itoa(c.Writer.Status()), reason)
+	}()
+	if !authorizeModel(c, modelName) {
+		return
+	}
+	accesslog.MarkRequestProcessingEnd(c)
+
+	if err := r.loadRateLimiter.ImageRateLimit(modelName, image.n, image.megapixels(image.n)); err != nil {
+		errorType, limitType := "image_rate_limit", metrics.LimitTypeImages
+		var megapixelErr *ratelimit.MegapixelRateLimitExceededError
+		if errors.As(err, &megapixelErr) {
+			errorType, limitType = "megapixel_rate_limit", metrics.LimitTypeMegapixels
+		}
+		accesslog.SetError(c, errorType, err.Error())
+		metricsRecorder.RecordRateLimitExceeded(limitType)
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, err.Error())
+		c.Set("finishReason", "rate_limit")
+		return
+	}
+
+	modelServerName, isLora, modelRoute, err := r.store.MatchModelServer(modelName, c.Request, c.GetStringSlice(GatewayKey))
+	if err != nil {
+		accesslog.SetError(c, "model_server_matching", fmt.Sprintf("can't find corresponding model server: %v", err))
+		c.AbortWithStatusJSON(http.StatusNotFound, "route not found")
+		return
+	}
+	handlers.ApplyHeaderPolicy(c, modelRoute)
+	applyObservability(c, modelRoute)
+	c.Header(modelServerHeader, modelServerName.String())
+	pods, modelServer, err := r.getPodsAndServer(modelServerName)
+	if err != nil {
+		accesslog.SetError(c, "pod_discovery", fmt.Sprintf("can't find model server: %v", modelServerName))
+		c.AbortWithStatusJSON(http.StatusNotFound, fmt.Sprintf("can't find model server: %v", modelServerName))
+		return
+	}
+	var ok bool
+	if pods, ok = servingPods(c, pods, modelServer, isLora); !ok {
+		return
+	}
+	backend, err := r.newUpstream(modelServer, modelServer.Spec.WorkloadPort.Port)
+	if err != nil {
+		accesslog.SetError(c, "upstream", err.Error())
+		c.AbortWithStatusJSON(http.StatusBadGateway, err.Error())
+		return
+	}
+	modelRequest["model"] = resolveAlias(modelRoute, modelName, isLora)
+	if model := modelServer.Spec.Model; model != nil && !isLora {
+		modelRequest["model"] = *model
+	}
+	body, err := json.Marshal(modelRequest)
+	if err != nil {
+		accesslog.SetError(c, "request_parsing", err.Error())
+		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx := &framework.Context{
+		Model:           modelName,
+		ModelServerName: modelServerName,
+		MetricsRecorder: metricsRecorder,
+		Experiment:      selectExperiment(c, r.experiments),
+	}
+	if err := r.scheduler.Schedule(ctx, pods); err != nil || len(ctx.BestPods) == 0 {
+		accesslog.SetError(c, "scheduling", fmt.Sprintf("can't schedule to target pod: %v", err))
+		c.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprintf("can't schedule to target pod: %v", err))
+		return
+	}
+
+	modelServerFullName := modelServerName.String()
+	modelRouteName := ""
+	if modelRoute != nil {
+		modelRouteName = fmt.Sprintf("%s/%s", modelRoute.Namespace, modelRoute.Name)
+	}
+	accesslog.MarkUpstreamStart(c)
+	handlers.GetServerTiming(c).UpstreamStarted()
+	defer accesslog.MarkUpstreamEnd(c)
+	for i, pod := range ctx.BestPods {
+		accesslog.SetRequestRouting(c, modelRouteName, modelServerFullName, pod.Pod.Name)
+		req := c.Request.Clone(c.Request.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+
+		r.metrics.IncActiveUpstreamRequests(modelServerFullName, modelRouteName)
+		images, err := proxyImagesRequest(c, req, pod.Pod.Status.PodIP, backend)
+		r.metrics.DecActiveUpstreamRequests(modelServerFullName, modelRouteName)
+		if err != nil {
+			if errors.Is(err, handlers.ErrClientGone) || req.Context().Err() != nil {
+				r.recordCanceledRequest(c, req, modelName, modelServerFullName)
+				return
+			}
+			klog.Errorf("image generation request to pod %s failed: %v", pod.Pod.Name, err)
+			continue
+		}
+
+		// The images may not be counted from a response the router can't parse.
+		if images == 0 {
+			images = image.n
+		}
+		megapixels := image.megapixels(images)
+		accesslog.SetImageUsage(c, images, megapixels, imageCost(r.imageCosts[modelName], images, megapixels))
+		r.metrics.RecordImages(modelName, path, images, megapixels)
+		r.scheduler.RunPostHooks(ctx, i)
+		return
+	}
+	accesslog.SetError(c, "proxy", "request to all pods failed")
+	c.AbortWithStatusJSON(http.StatusNotFound, "request to all pods failed")
+}
+
+// proxyImagesRequest forwards the image generation request to the pod, and returns the number of images in the
+// response, 0 if they can't be counted.
+func proxyImagesRequest(c *gin.Context, req *http.Request, podIP string, backend *upstream) (int, error) {
+	resp, err := doRequest(req, podIP, backend)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	handlers.CopyResponseHeaders(c, resp.Header)
+	c.Status(resp.StatusCode)
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		images := 0
+		err := handlers.ForwardStream(c, resp.Body, handlers.StreamOptions{
+			BufferSize: streamBufferSize,
+			OnLine: func(line []byte) []byte {
+				var event struct {
+					Type string `json:"type"`
+				}
+				data := bytes.TrimSpace(bytes.TrimPrefix(line, []byte("data:")))
+				if json.Unmarshal(data, &event) == nil && event.Type == imageCompletedEvent {
+					images++
+				}
+				return line
+			},
+		})
+		return images, err
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(c.Writer, io.TeeReader(resp.Body, &buf)); err != nil {
+		if req.Context().Err() != nil {
+			return 0, handlers.ErrClientGone
+		}
+		klog.Errorf("copy response to downstream failed: %v", err)
+	}
+	var response struct {
+		Data []json.RawMessage `json:"data"`
+	}
+	if json.Unmarshal(buf.Bytes(), &response) != nil {
+		return 0, nil
+	}
+	return len(response.Data), nil
+}
+
+// parseImageRequest reads the model, the number and the size of the images of an image generation request.
+func parseImageRequest(modelRequest ModelRequest) (*imageRequest, error) {
+	image := &imageRequest{model: modelRequest["model"].(string), n: 1}
+	if value, ok := modelRequest["n"]; ok && value != nil {
+		n, ok := value.(float64)
+		if !ok || n < 1 || n != float64(int(n)) {
+			return nil, fmt.Errorf("n must be a positive integer, got %v", value)
+		}
+		image.n = int(n)
+	}
+
+	size := defaultImageSize
+	if value, ok := modelRequest["size"]; ok && value != nil {
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("size must be a string, got %v", value)
+		}
+		if s != "" && s != "auto" {
+			size = s
+		}
+	}
+	width, height, found := strings.Cut(size, "x")
+	var err error
+	if image.width, err = strconv.Atoi(width); err == nil && found {
+		image.height, err = strconv.Atoi(height)
+	}
+	if err != nil || !found || image.width <= 0 || image.height <= 0 {
+		return nil, fmt.Errorf("invalid size %q, the size must be <width>x<height>, e.g. %s", size, defaultImageSize)
+	}
+	return image, nil
+}
@@ -0,0 +1,183 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+func TestParseImageRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		request string
+		want    *imageRequest
+		wantErr bool
+	}{
+		{name: "defaults", request: `{"model":"sdxl","prompt":"a cat"}`, want: &imageRequest{model: "sdxl", n: 1, width: 1024, height: 1024}},
+		{name: "auto size", request: `{"model":"sdxl","size":"auto","n":null}`, want: &imageRequest{model: "sdxl", n: 1, width: 1024, height: 1024}},
+		{name: "n and size", request: `{"model":"sdxl","n":3,"size":"512x768"}`, want: &imageRequest{model: "sdxl", n: 3, width: 512, height: 768}},
+		{name: "fractional n", request: `{"model":"sdxl","n":1.5}`, wantErr: true},
+		{name: "zero n", request: `{"model":"sdxl","n":0}`, wantErr: true},
+		{name: "invalid size", request: `{"model":"sdxl","size":"large"}`, wantErr: true},
+		{name: "negative size", request: `{"model":"sdxl","size":"-512x512"}`, wantErr: true},
+		{name: "size not a string", request: `{"model":"sdxl","size":512}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var modelRequest ModelRequest
+			require.NoError(t, json.Unmarshal([]byte(tt.request), &modelRequest))
+			got, err := parseImageRequest(modelRequest)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestImageCost(t *testing.T) {
+	image := &imageRequest{n: 2, width: 1024, height: 1024}
+	megapixels := image.megapixels(2)
+	assert.InDelta(t, 2.097152, megapixels, 1e-9)
+	assert.InDelta(t, 0.04+0.01*megapixels, imageCost(conf.ImageCostConfig{PerImage: 0.02, PerMegapixel: 0.01}, 2, megapixels), 1e-9)
+	assert.Equal(t, 0.0, imageCost(conf.ImageCostConfig{}, 2, megapixels))
+}
+
+type recordingAccessLogger struct {
+	entries []*accesslog.AccessLogEntry
+}
+
+func (l *recordingAccessLogger) Log(entry *accesslog.AccessLogEntry) error {
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+func (l *recordingAccessLogger) Close() error {
+	return nil
+}
+
+func TestRouter_HandlerFunc_Images(t *testing.T) {
+	backendHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, imagesGenerationsPath, r.URL.Path)
+		var request ModelRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "stabilityai/sdxl", request["model"])
+		n := 1
+		if value, ok := request["n"].(float64); ok {
+			n = int(value)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"created":1,"data":[%s]}`, strings.TrimSuffix(strings.Repeat(`{"url":"http://images/1.png"},`, n), ","))
+	})
+	router, store, backend := setupTestRouter(backendHandler)
+	defer backend.Close()
+	router.imageCosts = map[string]conf.ImageCostConfig{"sdxl": {Model: "sdxl", PerImage: 0.02}}
+	accessLogger := &recordingAccessLogger{}
+	router.accessLogger = accessLogger
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: "sdxl", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			Model:           ptr.To("stabilityai/sdxl"),
+			WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+			InferenceEngine: "vLLM",
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "sdxl-0", Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}
+	modelRoute := &aiv1alpha1.ModelRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "sdxl", Namespace: "default"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "sdxl",
+			Rules: []*aiv1alpha1.Rule{
+				{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "sdxl"}}},
+			},
+		},
+	}
+	store.AddOrUpdateModelServer(modelServer, sets.New(types.NamespacedName{Name: "sdxl-0", Namespace: "default"}))
+	store.AddOrUpdatePod(pod, []*aiv1alpha1.ModelServer{modelServer})
+	store.AddOrUpdateModelRoute(modelRoute)
+	// Configured directly rather than through the asynchronous ModelRoute callback.
+	require.NoError(t, router.loadRateLimiter.AddOrUpdateLimiter("sdxl", &aiv1alpha1.RateLimit{
+		ImagesPerUnit: ptr.To[uint32](4),
+		Unit:          aiv1alpha1.Hour,
+	}))
+
+	serve := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		_, engine := gin.CreateTestContext(w)
+		engine.Use(router.AccessLog())
+		engine.POST(imagesGenerationsPath, router.HandlerFunc())
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodPost, imagesGenerationsPath, bytes.NewBufferString(body)))
+		return w
+	}
+	images := func() float64 {
+		return testutil.ToFloat64(metrics.DefaultMetrics.ImagesTotal.WithLabelValues("sdxl", imagesGenerationsPath))
+	}
+	before := images()
+
+	w := serve(`{"model":"sdxl","prompt":"a cat","n":3,"size":"512x512"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, before+3, images())
+	require.Len(t, accessLogger.entries, 1)
+	entry := accessLogger.entries[0]
+	assert.Equal(t, 3, entry.Images)
+	assert.InDelta(t, 3*0.262144, entry.ImageMegapixels, 1e-9)
+	assert.InDelta(t, 0.06, entry.ImageCost, 1e-9)
+	assert.Zero(t, entry.InputTokens)
+
+	// The quota is expressed in images: 2 more images exceed the 4 images per hour.
+	w = serve(`{"model":"sdxl","prompt":"a dog","n":2}`)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, before+3, images())
+	require.Len(t, accessLogger.entries, 2)
+	assert.Equal(t, "image_rate_limit", accessLogger.entries[1].Error.Type)
+
+	assert.Equal(t, http.StatusOK, serve(`{"model":"sdxl","prompt":"a dog"}`).Code)
+	assert.Equal(t, before+4, images())
+
+	assert.Equal(t, http.StatusBadRequest, serve(`{"model":"sdxl","prompt":"a cat","size":"big"}`).Code)
+}
@@ -0,0 +1,234 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/encoding/protowire"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/filters/auth"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+)
+
+// kserveServicePath prefixes the paths of the calls of the KServe v2 inference gRPC service, implemented by Triton
+// among others, see https://github.com/kserve/open-inference-protocol.
+const kserveServicePath = "/inference.GRPCInferenceService/"
+
+// maxKServeMessageSize bounds the first message of a KServe v2 call, which is buffered to read its model.
+const maxKServeMessageSize = 64 << 20
+
+// kserveModelFields are the protobuf fields holding the model of the first request message of the KServe v2 calls
+// routed by model. The calls of the other methods, e.g. the ones managing the model repository of Triton, aren't routed.
+var kserveModelFields = map[string]protowire.Number{
+	"ModelReady":       1, // ModelReadyRequest.name
+	"ModelMetadata":    1, // ModelMetadataRequest.name
+	"ModelInfer":       1, // ModelInferRequest.model_name
+	"ModelStreamInfer": 1, // ModelInferRequest.model_name, of the first request of the stream
+	"ModelConfig":      1, // ModelConfigRequest.name
+	"ModelStatistics":  1, // ModelStatisticsRequest.name
+}
+
+// isKServeCall reports whether req is a call of the KServe v2 inference gRPC service.
+func isKServeCall(req *http.Request) bool {
+	return strings.HasPrefix(req.URL.Path, kserveServicePath)
+}
+
+// handleKServe routes a KServe v2 inference gRPC call to a pod of the ModelServer of its model, as the ModelRoutes
+// route the OpenAI requests. The model is read from the first request message, the call is then proxied as is, the
+// messages being streamed in both directions. The server liveness and readiness are answered by the router.
+func (r *Router) handleKServe(c *gin.Context) {
+	method := strings.TrimPrefix(c.Request.URL.Path, kserveServicePath)
+	switch method {
+	case "ServerLive", "ServerReady":
+		// ServerLiveResponse.live and ServerReadyResponse.ready
+		writeGRPCMessage(c, protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 1))
+		return
+	}
+	field, ok := kserveModelFields[method]
+	if !ok {
+		writeGRPCError(c, grpcStatusUnimplemented, fmt.Sprintf("method %s is not routed by the router", method))
+		return
+	}
+
+	frame, message, err := readGRPCMessage(c.Request)
+	if err != nil {
+		code := grpcStatusInvalidArgument
+		if errors.Is(err, errGRPCMessageTooLarge) {
+			code = grpcStatusResourceExhausted
+		}
+		writeGRPCError(c, code, err.Error())
+		return
+	}
+	model, err := protoStringField(message, field)
+	if err != nil {
+		writeGRPCError(c, grpcStatusInvalidArgument, fmt.Sprintf("invalid request message: %v", err))
+		return
+	}
+	if model == "" {
+		writeGRPCError(c, grpcStatusInvalidArgument, "the model name is required")
+		return
+	}
+	// The buffered message is sent again before the rest of the body.
+	c.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(frame), c.Request.Body), c.Request.Body}
+
+	if err := auth.AuthorizeModel(c, model); err != nil {
+		code := grpcStatusPermissionDenied
+		var required *auth.AuthenticationRequiredError
+		if errors.As(err, &required) {
+			code = grpcStatusUnauthenticated
+		}
+		writeGRPCError(c, code, err.Error())
+		return
+	}
+	modelServerName, _, _, err := r.store.MatchModelServer(model, c.Request, c.GetStringSlice(GatewayKey))
+	if err != nil {
+		writeGRPCError(c, grpcStatusNotFound, fmt.Sprintf("model %s not found", model))
+		return
+	}
+	handlers.ApplyHeaderPolicy(c, nil)
+	pods, modelServer, err := r.getPodsAndServer(modelServerName)
+	if err != nil || len(pods) == 0 {
+		writeGRPCError(c, grpcStatusUnavailable, fmt.Sprintf("can't find pods for model server: %v", modelServerName))
+		return
+	}
+	port := modelServer.Spec.WorkloadPort.Port
+	if modelServer.Spec.WorkloadPort.GRPCPort != nil {
+		port = *modelServer.Spec.WorkloadPort.GRPCPort
+	}
+
+	ctx := &framework.Context{
+		Model:           model,
+		ModelServerName: modelServerName,
+		Experiment:      selectExperiment(c, r.experiments),
+	}
+	if err := r.scheduler.Schedule(ctx, pods); err != nil || len(ctx.BestPods) == 0 {
+		writeGRPCError(c, grpcStatusUnavailable, fmt.Sprintf("can't schedule to target pod: %v", err))
+		return
+	}
+
+	// The request body is streamed to the pod, it can't be retried on another one.
+	podIP := ctx.BestPods[0].Pod.Status.PodIP
+	failed := false
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.Out.URL.Scheme = "http"
+			pr.Out.URL.Host = fmt.Sprintf("%s:%d", podIP, port)
+			pr.SetXForwarded()
+		},
+		Transport:     grpcTransport,
+		FlushInterval: -1,
+		ErrorHandler: func(_ http.ResponseWriter, req *http.Request, err error) {
+			failed = true
+			klog.Errorf("KServe call %s to pod %s failed: %v", req.URL.Path, podIP, err)
+			writeGRPCError(c, grpcStatusUnavailable, "request to pod failed")
+		},
+	}
+	klog.V(4).Infof("KServe call %s of model %s routed to pod %s of model server %v", method, model, podIP, modelServerName)
+	proxy.ServeHTTP(c.Writer, c.Request)
+	if !failed {
+		r.scheduler.RunPostHooks(ctx, 0)
+	}
+}
+
+var errGRPCMessageTooLarge = fmt.Errorf("request message is larger than %d bytes", maxKServeMessageSize)
+
+// readGRPCMessage reads the first length-prefixed message of the body of a gRPC call. It returns the frame read, to
+// be sent again, and the message, decompressed if the call is gzip encoded.
+func readGRPCMessage(req *http.Request) ([]byte, []byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(req.Body, header); err != nil {
+		return nil, nil, fmt.Errorf("failed to read the request message: %w", err)
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > maxKServeMessageSize {
+		return nil, nil, errGRPCMessageTooLarge
+	}
+	frame := make([]byte, 5+int(size))
+	copy(frame, header)
+	if _, err := io.ReadFull(req.Body, frame[5:]); err != nil {
+		return nil, nil, fmt.Errorf("failed to read the request message: %w", err)
+	}
+	message := frame[5:]
+	if header[0] == 0 {
+		return frame, message, nil
+	}
+	if encoding := req.Header.Get("Grpc-Encoding"); encoding != "gzip" {
+		return nil, nil, fmt.Errorf("unsupported message encoding %q", encoding)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(message))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decompress the request message: %w", err)
+	}
+	message, err = io.ReadAll(io.LimitReader(reader, maxKServeMessageSize+1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decompress the request message: %w", err)
+	}
+	if len(message) > maxKServeMessageSize {
+		return nil, nil, errGRPCMessageTooLarge
+	}
+	return frame, message, nil
+}
+
+// protoStringField returns the value of a string field of a protobuf message, empty if the message doesn't set it.
+func protoStringField(message []byte, field protowire.Number) (string, error) {
+	for len(message) > 0 {
+		number, typ, n := protowire.ConsumeTag(message)
+		if n < 0 {
+			return "", protowire.ParseError(n)
+		}
+		message = message[n:]
+		if number == field && typ == protowire.BytesType {
+			value, n := protowire.ConsumeBytes(message)
+			if n < 0 {
+				return "", protowire.ParseError(n)
+			}
+			return string(value), nil
+		}
+		n = protowire.ConsumeFieldValue(number, typ, message)
+		if n < 0 {
+			return "", protowire.ParseError(n)
+		}
+		message = message[n:]
+	}
+	return "", nil
+}
+
+// writeGRPCMessage ends a unary gRPC call with a successful response carrying the message.
+func writeGRPCMessage(c *gin.Context, message []byte) {
+	c.Header("Content-Type", grpcContentType)
+	c.Header("Trailer", "Grpc-Status")
+	c.Status(http.StatusOK)
+	frame := binary.BigEndian.AppendUint32([]byte{0}, uint32(len(message)))
+	_, _ = c.Writer.Write(append(frame, message...))
+	c.Writer.Header().Set("Grpc-Status", "0")
+	c.Abort()
+}
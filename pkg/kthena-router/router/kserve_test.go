@@ -0,0 +1,228 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+// kserveRequest returns a message setting the string field 1, the model of the KServe v2 requests, after another field.
+func kserveRequest(model string) []byte {
+	message := protowire.AppendTag(nil, 2, protowire.BytesType)
+	message = protowire.AppendString(message, "1")
+	message = protowire.AppendTag(message, 1, protowire.BytesType)
+	return protowire.AppendString(message, model)
+}
+
+func grpcFrame(compressed bool, message []byte) []byte {
+	flag := byte(0)
+	if compressed {
+		flag = 1
+	}
+	return append(binary.BigEndian.AppendUint32([]byte{flag}, uint32(len(message))), message...)
+}
+
+func TestProtoStringField(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  []byte
+		expected string
+		wantErr  bool
+	}{
+		{name: "field after another one", message: kserveRequest("resnet"), expected: "resnet"},
+		{name: "field after a varint", message: protowire.AppendString(protowire.AppendTag(protowire.AppendVarint(protowire.AppendTag(nil, 3, protowire.VarintType), 7), 1, protowire.BytesType), "resnet"), expected: "resnet"},
+		{name: "field unset", message: protowire.AppendString(protowire.AppendTag(nil, 2, protowire.BytesType), "resnet")},
+		{name: "empty message"},
+		{name: "truncated message", message: kserveRequest("resnet")[:6], wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, err := protoStringField(tt.message, 1)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, model)
+		})
+	}
+}
+
+func TestReadGRPCMessage(t *testing.T) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, _ = writer.Write(kserveRequest("resnet"))
+	require.NoError(t, writer.Close())
+
+	tests := []struct {
+		name     string
+		body     []byte
+		encoding string
+		wantErr  error
+	}{
+		{name: "uncompressed message", body: append(grpcFrame(false, kserveRequest("resnet")), "next"...)},
+		{name: "gzip message", body: grpcFrame(true, compressed.Bytes()), encoding: "gzip"},
+		{name: "unsupported encoding", body: grpcFrame(true, compressed.Bytes()), encoding: "snappy", wantErr: assert.AnError},
+		{name: "truncated message", body: grpcFrame(false, kserveRequest("resnet"))[:8], wantErr: assert.AnError},
+		{name: "message too large", body: binary.BigEndian.AppendUint32([]byte{0}, maxKServeMessageSize+1), wantErr: errGRPCMessageTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/inference.GRPCInferenceService/ModelInfer", bytes.NewReader(tt.body))
+			if tt.encoding != "" {
+				req.Header.Set("Grpc-Encoding", tt.encoding)
+			}
+			frame, message, err := readGRPCMessage(req)
+			if tt.wantErr != nil {
+				require.Error(t, err)
+				if tt.wantErr != assert.AnError {
+					assert.ErrorIs(t, err, tt.wantErr)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, kserveRequest("resnet"), message)
+			// The frame is sent again as read, the rest of the body is left unread.
+			assert.Equal(t, tt.body[:len(frame)], frame)
+			rest, _ := io.ReadAll(req.Body)
+			assert.Equal(t, tt.body[len(frame):], rest)
+		})
+	}
+}
+
+func TestRouter_HandlerFunc_KServe(t *testing.T) {
+	backendHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, 2, r.ProtoMajor)
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", grpcContentType)
+		w.Header().Set("Trailer", "Grpc-Status")
+		_, _ = w.Write(body)
+		w.Header().Set("Grpc-Status", "0")
+	})
+	backend := httptest.NewUnstartedServer(backendHandler)
+	backend.Config.Protocols = new(http.Protocols)
+	backend.Config.Protocols.SetUnencryptedHTTP2(true)
+	backend.Start()
+	defer backend.Close()
+	router, store, unused := setupTestRouter(nil)
+	unused.Close()
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	// The HTTP port isn't listened on, the calls must be sent to the gRPC port.
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: "resnet", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			WorkloadPort:    aiv1alpha1.WorkloadPort{Port: 1, GRPCPort: ptr.To(int32(backendPort))},
+			InferenceEngine: aiv1alpha1.Triton,
+		},
+	}
+	require.NoError(t, store.AddOrUpdateModelServer(modelServer, sets.New(types.NamespacedName{Name: "triton-0", Namespace: "default"})))
+	require.NoError(t, store.AddOrUpdatePod(&corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "triton-0", Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}, []*aiv1alpha1.ModelServer{modelServer}))
+	require.NoError(t, store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "resnet", Namespace: "default"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "resnet",
+			Rules: []*aiv1alpha1.Rule{{
+				TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "resnet"}},
+			}},
+		},
+	}))
+
+	engine := gin.New()
+	engine.Any("/*path", router.HandlerFunc())
+	server := httptest.NewUnstartedServer(engine)
+	server.Config.Protocols = new(http.Protocols)
+	server.Config.Protocols.SetUnencryptedHTTP2(true)
+	server.Start()
+	defer server.Close()
+	client := &http.Client{Transport: grpcTransport}
+
+	call := func(method string, body []byte) (*http.Response, []byte) {
+		req, err := http.NewRequest(http.MethodPost, server.URL+kserveServicePath+method, bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/grpc")
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		respBody, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, respBody
+	}
+	grpcStatus := func(resp *http.Response) string {
+		if status := resp.Header.Get("Grpc-Status"); status != "" {
+			return status
+		}
+		return resp.Trailer.Get("Grpc-Status")
+	}
+
+	t.Run("server liveness answered by the router", func(t *testing.T) {
+		resp, body := call("ServerLive", grpcFrame(false, nil))
+		assert.Equal(t, "0", grpcStatus(resp))
+		assert.Equal(t, grpcFrame(false, []byte{0x08, 0x01}), body)
+	})
+
+	t.Run("stream proxied to the model server", func(t *testing.T) {
+		// The messages after the first one are streamed to the pod as is.
+		request := append(grpcFrame(false, kserveRequest("resnet")), grpcFrame(false, kserveRequest("other"))...)
+		resp, body := call("ModelStreamInfer", request)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "0", grpcStatus(resp))
+		assert.Equal(t, request, body)
+	})
+
+	tests := []struct {
+		name     string
+		method   string
+		body     []byte
+		expected int
+	}{
+		{name: "unknown model", method: "ModelInfer", body: grpcFrame(false, kserveRequest("bert")), expected: grpcStatusNotFound},
+		{name: "model unset", method: "ModelReady", body: grpcFrame(false, nil), expected: grpcStatusInvalidArgument},
+		{name: "method not routed", method: "RepositoryIndex", body: grpcFrame(false, nil), expected: grpcStatusUnimplemented},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, _ := call(tt.method, tt.body)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, strconv.Itoa(tt.expected), grpcStatus(resp))
+		})
+	}
+}
@@ -0,0 +1,315 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+const (
+	// loadShedding is the error type of the batch requests shed while the router pod is under pressure.
+	loadShedding = "load_shedding"
+
+	// pressureMemory and pressureCPUThrottling are the pressure signals of the router pod.
+	pressureMemory        = "memory"
+	pressureCPUThrottling = "cpu_throttling"
+
+	// The reasons of the events of the router pod recording the episodes of load shedding.
+	loadSheddingStarted = "LoadSheddingStarted"
+	loadSheddingStopped = "LoadSheddingStopped"
+
+	defaultSheddingMemoryPercent       = 90
+	defaultSheddingCPUThrottledPercent = 50
+	defaultPressureInterval            = time.Second
+
+	// pressureHysteresis is how far below its threshold the pressure must fall to stop the shedding, so that a
+	// pressure oscillating around the threshold doesn't start an episode on every read.
+	pressureHysteresis = 0.05
+
+	// cgroupRoot is where the cgroup of the router pod is mounted.
+	cgroupRoot = "/sys/fs/cgroup"
+
+	// cgroupV1Unlimited is the memory limit of the cgroups v1 without limit, rounded down to the page size.
+	cgroupV1Unlimited = 1 << 62
+)
+
+// podPressure is a read of the pressure of the router pod, as ratios from 0 to 1. A signal of the pod without the
+// corresponding limit is 0.
+type podPressure struct {
+	memory       float64
+	cpuThrottled float64
+}
+
+// loadShedder sheds the batch requests while the router pod is under memory or CPU pressure, to keep the interactive
+// requests served until the pressure is relieved. The pressure is read in the background, each period the pressure
+// is above one of its thresholds is an episode of load shedding recorded as events of the pod.
+type loadShedder struct {
+	memoryThreshold       float64
+	cpuThrottledThreshold float64
+	interval              time.Duration
+	readPressure          func() (podPressure, error)
+	now                   func() time.Time
+	metrics               *metrics.Metrics
+
+	shedding atomic.Bool
+	// shed is the number of requests shed in the current episode.
+	shed atomic.Int64
+
+	// The state of the current episode, only accessed by the background loop.
+	startedAt time.Time
+	recorder  record.EventRecorder
+	pod       *corev1.ObjectReference
+}
+
+// newLoadShedder returns the load shedder of the given configuration, nil if it is not enabled.
+func newLoadShedder(config conf.LoadSheddingConfig, m *metrics.Metrics) *loadShedder {
+	if !config.Enabled {
+		return nil
+	}
+	s := &loadShedder{
+		memoryThreshold:       defaultSheddingMemoryPercent / 100.0,
+		cpuThrottledThreshold: defaultSheddingCPUThrottledPercent / 100.0,
+		interval:              defaultPressureInterval,
+		readPressure:          (&cgroupPressure{root: cgroupRoot}).read,
+		now:                   time.Now,
+		metrics:               m,
+	}
+	if config.MemoryPercent > 0 {
+		s.memoryThreshold = float64(config.MemoryPercent) / 100
+	}
+	if config.CPUThrottledPercent > 0 {
+		s.cpuThrottledThreshold = float64(config.CPUThrottledPercent) / 100
+	}
+	if config.IntervalSeconds > 0 {
+		s.interval = time.Duration(config.IntervalSeconds) * time.Second
+	}
+	return s
+}
+
+// active reports whether the batch requests are shed.
+func (s *loadShedder) active() bool {
+	return s != nil && s.shedding.Load()
+}
+
+// run reads the pressure of the pod every interval until the context is cancelled. The episodes are recorded as
+// events of the pod if the recorder is set. The shedding is disabled if the pressure can't be read.
+func (s *loadShedder) run(ctx context.Context, recorder record.EventRecorder, pod *corev1.ObjectReference) {
+	s.recorder = recorder
+	s.pod = pod
+	if _, err := s.readPressure(); err != nil {
+		klog.Warningf("load shedding is disabled, the pressure of the router pod can't be read: %v", err)
+		return
+	}
+	klog.Infof("load shedding enabled: memory threshold %.0f%%, CPU throttling threshold %.0f%%",
+		s.memoryThreshold*100, s.cpuThrottledThreshold*100)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pressure, err := s.readPressure()
+			if err != nil {
+				klog.V(4).Infof("failed to read the pressure of the router pod: %v", err)
+				continue
+			}
+			s.observe(pressure)
+		}
+	}
+}
+
+// observe starts an episode of load shedding when a signal reaches its threshold, and stops it once all the
+// signals are below their thresholds by the hysteresis.
+func (s *loadShedder) observe(pressure podPressure) {
+	s.metrics.PodPressure.WithLabelValues(pressureMemory).Set(pressure.memory)
+	s.metrics.PodPressure.WithLabelValues(pressureCPUThrottling).Set(pressure.cpuThrottled)
+
+	if !s.shedding.Load() {
+		var signals []string
+		if pressure.memory >= s.memoryThreshold {
+			signals = append(signals, pressureMemory)
+		}
+		if pressure.cpuThrottled >= s.cpuThrottledThreshold {
+			signals = append(signals, pressureCPUThrottling)
+		}
+		if len(signals) == 0 {
+			return
+		}
+		s.startedAt = s.now()
+		s.shed.Store(0)
+		s.shedding.Store(true)
+		s.metrics.LoadShedding.Set(1)
+		for _, signal := range signals {
+			s.metrics.LoadSheddingEpisodes.WithLabelValues(signal).Inc()
+		}
+		s.event(corev1.EventTypeWarning, loadSheddingStarted, "Shedding the batch requests, the router is under %s pressure: memory at %.0f%% of its limit, %.0f%% of the CPU periods throttled",
+			strings.Join(signals, " and "), pressure.memory*100, pressure.cpuThrottled*100)
+		return
+	}
+
+	if pressure.memory >= s.memoryThreshold-pressureHysteresis || pressure.cpuThrottled >= s.cpuThrottledThreshold-pressureHysteresis {
+		return
+	}
+	s.shedding.Store(false)
+	s.metrics.LoadShedding.Set(0)
+	s.event(corev1.EventTypeNormal, loadSheddingStopped, "Stopped shedding the batch requests after %s, %d requests shed",
+		s.now().Sub(s.startedAt).Round(time.Second), s.shed.Load())
+}
+
+func (s *loadShedder) event(eventType, reason, messageFmt string, args ...interface{}) {
+	message := fmt.Sprintf(messageFmt, args...)
+	klog.Infof("%s: %s", reason, message)
+	if s.recorder != nil && s.pod != nil {
+		s.recorder.Event(s.pod, eventType, reason, message)
+	}
+}
+
+// admitUnderPressure sheds a request marked as batch by the priority header while the router pod is under pressure,
+// before its body is read. It aborts the request and returns false if the request is shed.
+func (r *Router) admitUnderPressure(c *gin.Context) bool {
+	if !r.loadShedder.active() || !hasBatchPriority(c) {
+		return true
+	}
+	r.shedUnderPressure(c)
+	return false
+}
+
+// admitRouteUnderPressure sheds a request to a ModelRoute of the Batch class while the router pod is under pressure,
+// as soon as the model of the request is known, before its prompt is tokenized and the rate limits and quotas are
+// consumed. The ModelRoute of a request is only known once its body is parsed, the pressure doesn't make the router
+// read the bodies of the requests earlier. It aborts the request and returns false if the request is shed.
+func (r *Router) admitRouteUnderPressure(c *gin.Context, modelName string) bool {
+	if !r.loadShedder.active() {
+		return true
+	}
+	_, _, modelRoute, err := r.store.MatchModelServer(modelName, c.Request, c.GetStringSlice(GatewayKey))
+	if err != nil || !isBatchRoute(modelRoute) {
+		return true
+	}
+	r.shedUnderPressure(c)
+	return false
+}
+
+func (r *Router) shedUnderPressure(c *gin.Context) {
+	r.loadShedder.shed.Add(1)
+	r.metrics.RecordProtectionRejection(loadShedding)
+	rejectOverloaded(c, loadShedding, "router is under pressure, batch requests are shed")
+}
+
+// cgroupPressure reads the pressure of the router pod from its cgroup, v2 or v1. The throttling of the CPU is the
+// share of the CPU periods throttled since the previous read.
+type cgroupPressure struct {
+	root      string
+	periods   uint64
+	throttled uint64
+}
+
+func (c *cgroupPressure) read() (podPressure, error) {
+	memoryDir, cpuDir := c.root, c.root
+	usageFile, limitFile, inactiveFileKey := "memory.current", "memory.max", "inactive_file"
+	if _, err := os.Stat(filepath.Join(c.root, "cgroup.controllers")); err != nil {
+		memoryDir, cpuDir = filepath.Join(c.root, "memory"), filepath.Join(c.root, "cpu")
+		usageFile, limitFile, inactiveFileKey = "memory.usage_in_bytes", "memory.limit_in_bytes", "total_inactive_file"
+	}
+
+	var pressure podPressure
+	limit, err := readCgroupValue(filepath.Join(memoryDir, limitFile))
+	if err != nil {
+		return pressure, err
+	}
+	if limit > 0 && limit < cgroupV1Unlimited {
+		usage, err := readCgroupValue(filepath.Join(memoryDir, usageFile))
+		if err != nil {
+			return pressure, err
+		}
+		// The working set, as the kubelet computes it, excludes the page cache the kernel can reclaim.
+		stat, err := readCgroupStat(filepath.Join(memoryDir, "memory.stat"))
+		if err != nil {
+			return pressure, err
+		}
+		if inactive := stat[inactiveFileKey]; inactive < usage {
+			usage -= inactive
+		} else {
+			usage = 0
+		}
+		pressure.memory = float64(usage) / float64(limit)
+	}
+
+	stat, err := readCgroupStat(filepath.Join(cpuDir, "cpu.stat"))
+	if err != nil {
+		return pressure, err
+	}
+	periods, throttled := stat["nr_periods"], stat["nr_throttled"]
+	if periods > c.periods && throttled >= c.throttled {
+		pressure.cpuThrottled = float64(throttled-c.throttled) / float64(periods-c.periods)
+	}
+	c.periods, c.throttled = periods, throttled
+	return pressure, nil
+}
+
+// readCgroupValue reads a cgroup file holding a single value, "max" being no limit, read as 0.
+func readCgroupValue(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "max" {
+		return 0, nil
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+
+// readCgroupStat reads a cgroup file of "key value" lines.
+func readCgroupStat(path string) (map[string]uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	stat := make(map[string]uint64)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), " ")
+		if !found {
+			continue
+		}
+		if n, err := strconv.ParseUint(value, 10, 64); err == nil {
+			stat[key] = n
+		}
+	}
+	return stat, scanner.Err()
+}
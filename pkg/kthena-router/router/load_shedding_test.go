@@ -0,0 +1,258 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+func writeCgroupFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+}
+
+func TestCgroupPressure(t *testing.T) {
+	t.Run("cgroup v2", func(t *testing.T) {
+		root := t.TempDir()
+		writeCgroupFiles(t, root, map[string]string{
+			"cgroup.controllers": "cpu memory\n",
+			"memory.max":         "1000\n",
+			"memory.current":     "900\n",
+			"memory.stat":        "anon 700\ninactive_file 100\n",
+			"cpu.stat":           "usage_usec 100\nnr_periods 10\nnr_throttled 2\n",
+		})
+		c := &cgroupPressure{root: root}
+		pressure, err := c.read()
+		require.NoError(t, err)
+		assert.InDelta(t, 0.8, pressure.memory, 0.001)
+		assert.InDelta(t, 0.2, pressure.cpuThrottled, 0.001)
+
+		// The throttling is the share of the periods throttled since the previous read.
+		writeCgroupFiles(t, root, map[string]string{"cpu.stat": "nr_periods 20\nnr_throttled 10\n"})
+		pressure, err = c.read()
+		require.NoError(t, err)
+		assert.InDelta(t, 0.8, pressure.cpuThrottled, 0.001)
+	})
+
+	t.Run("cgroup v2 without limits", func(t *testing.T) {
+		root := t.TempDir()
+		writeCgroupFiles(t, root, map[string]string{
+			"cgroup.controllers": "cpu memory\n",
+			"memory.max":         "max\n",
+			"cpu.stat":           "usage_usec 100\n",
+		})
+		pressure, err := (&cgroupPressure{root: root}).read()
+		require.NoError(t, err)
+		assert.Equal(t, podPressure{}, pressure)
+	})
+
+	t.Run("cgroup v1", func(t *testing.T) {
+		root := t.TempDir()
+		writeCgroupFiles(t, root, map[string]string{
+			"memory/memory.limit_in_bytes": "2000\n",
+			"memory/memory.usage_in_bytes": "1500\n",
+			"memory/memory.stat":           "cache 600\ntotal_inactive_file 500\n",
+			"cpu/cpu.stat":                 "nr_periods 4\nnr_throttled 1\nthrottled_time 100\n",
+		})
+		pressure, err := (&cgroupPressure{root: root}).read()
+		require.NoError(t, err)
+		assert.InDelta(t, 0.5, pressure.memory, 0.001)
+		assert.InDelta(t, 0.25, pressure.cpuThrottled, 0.001)
+	})
+
+	t.Run("no cgroup", func(t *testing.T) {
+		_, err := (&cgroupPressure{root: filepath.Join(t.TempDir(), "missing")}).read()
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadShedderEpisodes(t *testing.T) {
+	s := newLoadShedder(conf.LoadSheddingConfig{Enabled: true, MemoryPercent: 80}, metrics.DefaultMetrics)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	recorder := record.NewFakeRecorder(10)
+	s.recorder = recorder
+	s.pod = &corev1.ObjectReference{Kind: "Pod", Namespace: "kthena-system", Name: "kthena-router-0"}
+	episodes := func() float64 {
+		return testutil.ToFloat64(metrics.DefaultMetrics.LoadSheddingEpisodes.WithLabelValues(pressureMemory))
+	}
+	before := episodes()
+
+	s.observe(podPressure{memory: 0.7, cpuThrottled: 0.1})
+	assert.False(t, s.active())
+
+	s.observe(podPressure{memory: 0.85, cpuThrottled: 0.1})
+	assert.True(t, s.active())
+	assert.Equal(t, before+1, episodes())
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.DefaultMetrics.LoadShedding))
+	assert.Equal(t, "Warning LoadSheddingStarted Shedding the batch requests, the router is under memory pressure: memory at 85% of its limit, 10% of the CPU periods throttled", <-recorder.Events)
+
+	// The shedding goes on until the pressure falls below the threshold by the hysteresis.
+	s.shed.Add(3)
+	s.observe(podPressure{memory: 0.78})
+	assert.True(t, s.active())
+	assert.Equal(t, before+1, episodes())
+
+	now = now.Add(time.Minute)
+	s.observe(podPressure{memory: 0.7})
+	assert.False(t, s.active())
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.DefaultMetrics.LoadShedding))
+	assert.Equal(t, "Normal LoadSheddingStopped Stopped shedding the batch requests after 1m0s, 3 requests shed", <-recorder.Events)
+}
+
+func TestRouterAdmitUnderPressure(t *testing.T) {
+	store := datastore.New()
+	for name, class := range map[string]aiv1alpha1.TrafficClass{"batch-model": aiv1alpha1.TrafficClassBatch, "chat-model": aiv1alpha1.TrafficClassInteractive} {
+		require.NoError(t, store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: aiv1alpha1.ModelRouteSpec{
+				ModelName: name,
+				Rules:     []*aiv1alpha1.Rule{{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: name}}}},
+				Priority:  &aiv1alpha1.Priority{Class: class},
+			},
+		}))
+	}
+	r := &Router{
+		loadShedder: newLoadShedder(conf.LoadSheddingConfig{Enabled: true}, metrics.DefaultMetrics),
+		metrics:     metrics.DefaultMetrics,
+		store:       store,
+	}
+	send := func(batch bool) (bool, int) {
+		c, w := bodyContext("{}", false)
+		if batch {
+			c.Request.Header.Set(priorityHeader, "batch")
+		}
+		admitted := r.admitUnderPressure(c)
+		return admitted, w.Code
+	}
+
+	admitted, _ := send(true)
+	assert.True(t, admitted, "the batch requests are admitted without pressure")
+
+	r.loadShedder.observe(podPressure{memory: 0.95})
+	admitted, code := send(true)
+	assert.False(t, admitted)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.EqualValues(t, 1, r.loadShedder.shed.Load())
+
+	admitted, _ = send(false)
+	assert.True(t, admitted, "the interactive requests are not shed")
+
+	// The body of a request isn't read before it is admitted, its ModelRoute is checked once its body is parsed.
+	c, _ := bodyContext(`{"model":"batch-model"}`, true)
+	c.Request.Header.Set("Content-Type", "application/json")
+	assert.True(t, r.admitUnderPressure(c))
+	body, err := io.ReadAll(c.Request.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"model":"batch-model"}`, string(body))
+	c, w := bodyContext("{}", false)
+	assert.False(t, r.admitRouteUnderPressure(c, "batch-model"))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	c, _ = bodyContext("{}", false)
+	assert.True(t, r.admitRouteUnderPressure(c, "chat-model"))
+	c, _ = bodyContext("{}", false)
+	assert.True(t, r.admitRouteUnderPressure(c, "unknown-model"))
+
+	// A router without load shedding admits all the requests.
+	r.loadShedder = nil
+	admitted, _ = send(true)
+	assert.True(t, admitted)
+	c, _ = bodyContext("{}", false)
+	assert.True(t, r.admitRouteUnderPressure(c, "batch-model"))
+}
+
+func TestRouterShedsBeforeRateLimits(t *testing.T) {
+	router, store, backend := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"response-id"}`)
+	}))
+	defer backend.Close()
+	router.loadShedder = newLoadShedder(conf.LoadSheddingConfig{Enabled: true}, metrics.DefaultMetrics)
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: "batch", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+			InferenceEngine: "vLLM",
+		},
+	}
+	store.AddOrUpdateModelServer(modelServer, sets.New(types.NamespacedName{Name: "pod-1", Namespace: "default"}))
+	require.NoError(t, store.AddOrUpdatePod(&corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "pod-1", Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}, []*aiv1alpha1.ModelServer{modelServer}))
+	require.NoError(t, store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "batch-model", Namespace: "default"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "batch-model",
+			Rules:     []*aiv1alpha1.Rule{{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "batch"}}}},
+			Priority:  &aiv1alpha1.Priority{Class: aiv1alpha1.TrafficClassBatch},
+		},
+	}))
+	// A single request fits in the input tokens of the hour.
+	require.NoError(t, router.loadRateLimiter.AddOrUpdateLimiter("batch-model", &aiv1alpha1.RateLimit{
+		InputTokensPerUnit: ptr.To(uint32(router.tokenizers.CalculateTokenNum("batch-model", "hello"))),
+		Unit:               aiv1alpha1.Hour,
+	}))
+
+	send := func() int {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/v1/completions", bytes.NewBufferString(`{"model": "batch-model", "prompt": "hello"}`))
+		router.HandlerFunc()(c)
+		return w.Code
+	}
+
+	router.loadShedder.observe(podPressure{memory: 0.95})
+	assert.Equal(t, http.StatusServiceUnavailable, send())
+	assert.Equal(t, http.StatusServiceUnavailable, send())
+
+	// The shed requests didn't consume the input tokens of the rate limit.
+	router.loadShedder.shedding.Store(false)
+	assert.Equal(t, http.StatusOK, send())
+	assert.Equal(t, http.StatusTooManyRequests, send())
+}
@@ -0,0 +1,175 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand/v2"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/utils"
+)
+
+const (
+	// mirrorHeader marks the mirrored requests, so that the mirror ModelServer can tell them apart.
+	mirrorHeader = "x-kthena-mirror"
+
+	// maxMirroredRequests is the number of requests mirrored at once, the requests beyond are not mirrored. Nobody
+	// waits on the mirrored requests, it bounds the resources of the router they use.
+	maxMirroredRequests = 256
+	// defaultMirrorTimeout bounds the mirrored requests of the ModelRoutes without a request timeout.
+	defaultMirrorTimeout = 10 * time.Minute
+
+	mirrorSucceeded = "succeeded"
+	mirrorFailed    = "failed"
+	mirrorDropped   = "dropped"
+)
+
+// mirrors the request, mirror.Percentage percent of the time.
+func mirrors(mirror *v1alpha1.Mirror) bool {
+	if mirror == nil {
+		return false
+	}
+	if mirror.Percentage == nil || *mirror.Percentage >= 100 {
+		return true
+	}
+	return rand.Int32N(100) < *mirror.Percentage
+}
+
+// mirrorRequest sends a copy of the request to the mirror ModelServer of the ModelRoute in the background, and
+// discards its response. It is called before the request is rewritten for the ModelServer serving it.
+func (r *Router) mirrorRequest(c *gin.Context, modelRoute *v1alpha1.ModelRoute, modelRequest ModelRequest, modelName string, isLora bool) {
+	mirror := modelRoute.Spec.Mirror
+	if !mirrors(mirror) {
+		return
+	}
+	modelServerName := types.NamespacedName{Namespace: modelRoute.Namespace, Name: mirror.ModelServerName}
+	select {
+	case r.mirroredRequests <- struct{}{}:
+	default:
+		r.metrics.RecordMirroredRequest(modelName, modelServerName.String(), mirrorDropped)
+		return
+	}
+
+	// The request is copied before it returns, the copy is sent once the client may be gone.
+	send, err := r.newMirroredRequest(c, modelRoute, modelServerName, modelRequest, modelName, isLora)
+	if err != nil {
+		<-r.mirroredRequests
+		klog.V(4).Infof("failed to mirror request %s to model server %v: %v", c.Request.Header.Get("x-request-id"), modelServerName, err)
+		r.metrics.RecordMirroredRequest(modelName, modelServerName.String(), mirrorFailed)
+		return
+	}
+	go func() {
+		defer func() { <-r.mirroredRequests }()
+		if err := send(); err != nil {
+			klog.V(4).Infof("mirrored request to model server %v failed: %v", modelServerName, err)
+			r.metrics.RecordMirroredRequest(modelName, modelServerName.String(), mirrorFailed)
+			return
+		}
+		r.metrics.RecordMirroredRequest(modelName, modelServerName.String(), mirrorSucceeded)
+	}()
+}
+
+// newMirroredRequest copies the request for the mirror ModelServer and returns the function sending the copy.
+func (r *Router) newMirroredRequest(
+	c *gin.Context,
+	modelRoute *v1alpha1.ModelRoute,
+	modelServerName types.NamespacedName,
+	modelRequest ModelRequest,
+	modelName string,
+	isLora bool,
+) (func() error, error) {
+	pods, modelServer, err := r.getPodsAndServer(modelServerName)
+	if err != nil {
+		return nil, err
+	}
+	if selector := modelServer.Spec.WorkloadSelector; selector != nil && selector.PDGroup != nil {
+		return nil, errors.New("the requests can't be mirrored to a prefill/decode disaggregated model server")
+	}
+	mirroredRequest := maps.Clone(modelRequest)
+	mirroredRequest["model"] = modelName
+	if model := modelServer.Spec.Model; model != nil && !isLora {
+		if pods, _ = datastore.PodsServingModel(pods, *model); len(pods) == 0 {
+			return nil, fmt.Errorf("model %s has been replaced on model server %v", *model, modelServerName)
+		}
+		mirroredRequest["model"] = *model
+	}
+	prompt, err := utils.ParsePrompt(mirroredRequest)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(mirroredRequest)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := r.newUpstream(modelServer, modelServer.Spec.WorkloadPort.Port)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := defaultMirrorTimeout
+	if timeouts := modelRoute.Spec.Timeouts; timeouts != nil && timeouts.Request != nil {
+		timeout = timeouts.Request.Duration
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	req := c.Request.Clone(ctx)
+	req.RequestURI = ""
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.Header.Del("Content-Length")
+	req.Header.Set(mirrorHeader, "true")
+
+	schedulingContext := &framework.Context{
+		Model:               modelName,
+		Prompt:              prompt,
+		ModelServerName:     modelServerName,
+		LoadBalancingPolicy: modelServer.Spec.LoadBalancingPolicy,
+		SlowStart:           modelServer.Spec.SlowStart,
+	}
+	return func() error {
+		defer cancel()
+		if err := r.scheduler.Schedule(schedulingContext, pods); err != nil {
+			return fmt.Errorf("can't schedule to target pod: %w", err)
+		}
+		if len(schedulingContext.BestPods) == 0 {
+			return errors.New("no pod selected")
+		}
+		resp, err := doRequest(req, schedulingContext.BestPods[0].Pod.Status.PodIP, backend)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+			return err
+		}
+		r.scheduler.RunPostHooks(schedulingContext, 0)
+		return nil
+	}, nil
+}
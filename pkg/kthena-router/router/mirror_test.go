@@ -0,0 +1,174 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+func TestRouter_HandlerFunc_Mirror(t *testing.T) {
+	router, store, primary := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get(mirrorHeader))
+		fmt.Fprint(w, `{"id":"response-id"}`)
+	}))
+	defer primary.Close()
+	var mirroredCalls atomic.Int32
+	mirrored := make(chan ModelRequest, 10)
+	candidate := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mirroredCalls.Add(1)
+		assert.Equal(t, "true", r.Header.Get(mirrorHeader))
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		var reqBody ModelRequest
+		_ = json.Unmarshal(body, &reqBody)
+		mirrored <- reqBody
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer candidate.Close()
+
+	for name, backend := range map[string]*httptest.Server{"primary": primary, "candidate": candidate} {
+		backendURL, _ := url.Parse(backend.URL)
+		backendPort, _ := strconv.Atoi(backendURL.Port())
+		modelServer := &aiv1alpha1.ModelServer{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: aiv1alpha1.ModelServerSpec{
+				Model:           ptr.To("model-" + name),
+				WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+				InferenceEngine: "vLLM",
+			},
+		}
+		podName := types.NamespacedName{Name: "pod-" + name, Namespace: "default"}
+		store.AddOrUpdateModelServer(modelServer, sets.New(podName))
+		store.AddOrUpdatePod(&corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{Name: podName.Name, Namespace: "default"},
+			Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+		}, []*aiv1alpha1.ModelServer{modelServer})
+	}
+
+	tests := []struct {
+		name         string
+		mirror       *aiv1alpha1.Mirror
+		wantMirrored int32
+	}{
+		{
+			name: "no mirror",
+		},
+		{
+			name:         "all requests mirrored",
+			mirror:       &aiv1alpha1.Mirror{ModelServerName: "candidate"},
+			wantMirrored: 1,
+		},
+		{
+			name:   "no request mirrored",
+			mirror: &aiv1alpha1.Mirror{ModelServerName: "candidate", Percentage: ptr.To(int32(0))},
+		},
+		{
+			name:   "missing mirror model server",
+			mirror: &aiv1alpha1.Mirror{ModelServerName: "missing"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mirroredCalls.Store(0)
+			store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+				ObjectMeta: v1.ObjectMeta{Name: "mr-1", Namespace: "default"},
+				Spec: aiv1alpha1.ModelRouteSpec{
+					ModelName: "test-model",
+					Rules: []*aiv1alpha1.Rule{
+						{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "primary"}}},
+					},
+					Mirror: tt.mirror,
+				},
+			})
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(`{"model": "test-model", "prompt": "hello"}`))
+			router.HandlerFunc()(c)
+
+			// The failures of the mirrored requests don't affect the responses.
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Body.String(), `"id":"response-id"`)
+			assert.Equal(t, "default/primary", w.Header().Get(modelServerHeader))
+			if tt.wantMirrored > 0 {
+				select {
+				case reqBody := <-mirrored:
+					assert.Equal(t, "model-candidate", reqBody["model"])
+					assert.Equal(t, "hello", reqBody["prompt"])
+				case <-time.After(5 * time.Second):
+					t.Fatal("request not mirrored")
+				}
+			}
+			require.Eventually(t, func() bool { return len(router.mirroredRequests) == 0 }, 5*time.Second, 10*time.Millisecond)
+			assert.Equal(t, tt.wantMirrored, mirroredCalls.Load())
+		})
+	}
+}
+
+func TestRouter_MirrorRequest_Dropped(t *testing.T) {
+	router, _, backend := setupTestRouter(http.NotFoundHandler())
+	defer backend.Close()
+	for range maxMirroredRequests {
+		router.mirroredRequests <- struct{}{}
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/v1/chat/completions", nil)
+	modelRoute := &aiv1alpha1.ModelRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "mr-1", Namespace: "default"},
+		Spec:       aiv1alpha1.ModelRouteSpec{Mirror: &aiv1alpha1.Mirror{ModelServerName: "candidate"}},
+	}
+	router.mirrorRequest(c, modelRoute, ModelRequest{"model": "test-model", "prompt": "hello"}, "test-model", false)
+	assert.Len(t, router.mirroredRequests, maxMirroredRequests)
+}
+
+func TestMirrors(t *testing.T) {
+	assert.False(t, mirrors(nil))
+	assert.True(t, mirrors(&aiv1alpha1.Mirror{ModelServerName: "candidate"}))
+	assert.True(t, mirrors(&aiv1alpha1.Mirror{ModelServerName: "candidate", Percentage: ptr.To(int32(100))}))
+	assert.False(t, mirrors(&aiv1alpha1.Mirror{ModelServerName: "candidate", Percentage: ptr.To(int32(0))}))
+
+	mirrored := 0
+	for range 10000 {
+		if mirrors(&aiv1alpha1.Mirror{ModelServerName: "candidate", Percentage: ptr.To(int32(30))}) {
+			mirrored++
+		}
+	}
+	assert.InDelta(t, 3000, mirrored, 300)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
+)
+
+const modelReplaced = "model_replaced"
+
+// resolveAlias returns the model name of the ModelRoute if modelName is one of its aliases, modelName otherwise.
+func resolveAlias(modelRoute *v1alpha1.ModelRoute, modelName string, isLora bool) string {
+	if modelRoute == nil || isLora || modelRoute.Spec.ModelName == "" || !slices.Contains(modelRoute.Spec.Aliases, modelName) {
+		return modelName
+	}
+	return modelRoute.Spec.ModelName
+}
+
+// servingPods drops the pods of the ModelServer whose engine swapped its model for another one in place, until
+// the ModelServer is updated with the new model. If no pod serves the model anymore, the request is aborted with
+// a model_replaced error and servingPods returns false.
+func servingPods(c *gin.Context, pods []*datastore.PodInfo, modelServer *v1alpha1.ModelServer, isLora bool) ([]*datastore.PodInfo, bool) {
+	model := modelServer.Spec.Model
+	if model == nil || isLora {
+		return pods, true
+	}
+	pods, replacedBy := datastore.PodsServingModel(pods, *model)
+	if len(pods) != 0 {
+		return pods, true
+	}
+	openAIError := handlers.NewInvalidRequestError(
+		fmt.Sprintf("The model %s has been replaced by %s on model server %s/%s.",
+			*model, strings.Join(replacedBy, ", "), modelServer.Namespace, modelServer.Name),
+		"model", modelReplaced)
+	accesslog.SetError(c, modelReplaced, openAIError.Error.Message)
+	c.AbortWithStatusJSON(http.StatusNotFound, openAIError)
+	c.Set("finishReason", modelReplaced)
+	return nil, false
+}
@@ -0,0 +1,153 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
+)
+
+func TestRouter_HandlerFunc_ModelReplaced(t *testing.T) {
+	router, store, backend := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"response-id"}`)
+	}))
+	defer backend.Close()
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: "ms-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			Model:           ptr.To("llama-3"),
+			WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+			InferenceEngine: "vLLM",
+		},
+	}
+	podName := types.NamespacedName{Name: "pod-1", Namespace: "default"}
+	store.AddOrUpdateModelServer(modelServer, sets.New(podName))
+	store.AddOrUpdatePod(&corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "pod-1", Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}, []*aiv1alpha1.ModelServer{modelServer})
+	store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "mr-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "llama",
+			Rules: []*aiv1alpha1.Rule{
+				{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "ms-1"}}},
+			},
+		},
+	})
+
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(`{"model": "llama", "prompt": "hello"}`))
+		router.HandlerFunc()(c)
+		return w
+	}
+
+	store.GetPodInfo(podName).UpdateModels([]string{"llama-3"})
+	assert.Equal(t, http.StatusOK, serve().Code)
+
+	// The engine swapped its model in place.
+	store.GetPodInfo(podName).UpdateModels([]string{"qwen-3"})
+	w := serve()
+	require.Equal(t, http.StatusNotFound, w.Code)
+	var openAIError handlers.OpenAIError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &openAIError))
+	assert.Equal(t, modelReplaced, openAIError.Error.Code)
+	assert.Equal(t, "The model llama-3 has been replaced by qwen-3 on model server default/ms-1.", openAIError.Error.Message)
+
+	// The ModelServer is updated with the new model.
+	modelServer = modelServer.DeepCopy()
+	modelServer.Spec.Model = ptr.To("qwen-3")
+	store.AddOrUpdateModelServer(modelServer, nil)
+	assert.Equal(t, http.StatusOK, serve().Code)
+}
+
+func TestRouter_HandlerFunc_Alias(t *testing.T) {
+	// The model server answers with the model of the request it received.
+	router, store, backend := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		fmt.Fprintf(w, `{"model":%q}`, request["model"])
+	}))
+	defer backend.Close()
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: "ms-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+			InferenceEngine: "vLLM",
+		},
+	}
+	podName := types.NamespacedName{Name: "pod-1", Namespace: "default"}
+	store.AddOrUpdateModelServer(modelServer, sets.New(podName))
+	store.AddOrUpdatePod(&corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "pod-1", Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}, []*aiv1alpha1.ModelServer{modelServer})
+	store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "mr-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "qwen2.5-72b",
+			Aliases:   []string{"gpt-4o"},
+			Rules: []*aiv1alpha1.Rule{
+				{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "ms-1"}}},
+			},
+		},
+	})
+
+	serve := func(model string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(fmt.Sprintf(`{"model": %q, "prompt": "hello"}`, model)))
+		router.HandlerFunc()(c)
+		return w
+	}
+
+	for _, model := range []string{"qwen2.5-72b", "gpt-4o"} {
+		w := serve(model)
+		require.Equal(t, http.StatusOK, w.Code, model)
+		assert.JSONEq(t, `{"model":"qwen2.5-72b"}`, w.Body.String(), model)
+	}
+
+	// The aliases are no longer matched once the ModelRoute is deleted.
+	require.NoError(t, store.DeleteModelRoute("default/mr-1"))
+	assert.Equal(t, http.StatusNotFound, serve("gpt-4o").Code)
+}
@@ -0,0 +1,105 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+// resolvedModelKey is the context key of the model resolved from the headers or the path of a request.
+const resolvedModelKey = "resolvedModel"
+
+// modelResolution resolves the model of the requests whose clients can't set the model field of the body, from a
+// header or a segment of the path, as configured in the router configuration.
+type modelResolution struct {
+	sources []conf.ModelSourceConfig
+	// sourcesFirst is true when the model resolved from the sources wins over the model field of the body.
+	sourcesFirst bool
+}
+
+// newModelResolution returns nil when no source is configured, the requests must then set the model field of the body.
+func newModelResolution(config conf.ModelResolutionConfig) *modelResolution {
+	if len(config.Sources) == 0 {
+		return nil
+	}
+	return &modelResolution{
+		sources:      config.Sources,
+		sourcesFirst: config.Precedence == "sources",
+	}
+}
+
+// resolve records the model of the first source resolving one for the request of c. The path of a request matching
+// the prefix of a source is rewritten without the prefix and the model, so that the model server receives the path
+// of the OpenAI API, even if the model is resolved by an earlier source.
+func (m *modelResolution) resolve(c *gin.Context) {
+	if m == nil {
+		return
+	}
+	path := c.Request.URL.Path
+	var model string
+	for _, source := range m.sources {
+		var value string
+		if source.Header != "" {
+			value = c.GetHeader(source.Header)
+		} else if rest, ok := strings.CutPrefix(path, source.PathPrefix); ok && c.Request.URL.Path == path {
+			// Only the first source matching the path rewrites it.
+			segment, remainder, _ := strings.Cut(rest, "/")
+			if segment == "" {
+				continue
+			}
+			value = segment
+			c.Request.URL.Path = "/v1/" + remainder
+			c.Request.URL.RawPath = ""
+		}
+		if model == "" {
+			model = sourceModel(source, value)
+		}
+	}
+	if model != "" {
+		klog.V(4).Infof("resolved model %s from the headers or path of the request", model)
+		c.Set(resolvedModelKey, model)
+	}
+}
+
+// model returns the model resolved for the request of c if it applies to the request, given the model field of its
+// body and the precedence of the sources.
+func (m *modelResolution) model(c *gin.Context, modelRequest ModelRequest) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	resolved := c.GetString(resolvedModelKey)
+	if resolved == "" {
+		return "", false
+	}
+	if model, _ := modelRequest["model"].(string); model != "" && !m.sourcesFirst {
+		return "", false
+	}
+	return resolved, true
+}
+
+// sourceModel returns the model named by the value of the header or path segment of the source, "" if none.
+func sourceModel(source conf.ModelSourceConfig, value string) string {
+	if value == "" || source.Models == nil {
+		return value
+	}
+	return source.Models[value]
+}
@@ -0,0 +1,147 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+func TestModelResolution(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sources := []conf.ModelSourceConfig{
+		{Header: "x-model"},
+		{PathPrefix: "/v1/deployments/", Models: map[string]string{"gpt-4": "llama"}},
+	}
+	tests := []struct {
+		name         string
+		precedence   string
+		path         string
+		header       string
+		body         string
+		expectModel  string
+		expectPath   string
+		expectStatus int
+	}{
+		{
+			name:        "body model wins",
+			path:        "/v1/chat/completions",
+			header:      "mistral",
+			body:        `{"model":"qwen"}`,
+			expectModel: "qwen",
+			expectPath:  "/v1/chat/completions",
+		},
+		{
+			name:        "header without body model",
+			path:        "/v1/chat/completions",
+			header:      "mistral",
+			body:        `{"prompt":"hello"}`,
+			expectModel: "mistral",
+			expectPath:  "/v1/chat/completions",
+		},
+		{
+			name:        "sources win over body model",
+			precedence:  "sources",
+			path:        "/v1/chat/completions",
+			header:      "mistral",
+			body:        `{"model":"qwen"}`,
+			expectModel: "mistral",
+			expectPath:  "/v1/chat/completions",
+		},
+		{
+			name:        "mapped path segment",
+			path:        "/v1/deployments/gpt-4/chat/completions",
+			body:        `{"prompt":"hello"}`,
+			expectModel: "llama",
+			expectPath:  "/v1/chat/completions",
+		},
+		{
+			name:        "earlier header source wins, path still rewritten",
+			path:        "/v1/deployments/gpt-4/chat/completions",
+			header:      "mistral",
+			body:        `{}`,
+			expectModel: "mistral",
+			expectPath:  "/v1/chat/completions",
+		},
+		{
+			name:         "path segment not mapped",
+			path:         "/v1/deployments/gpt-5/chat/completions",
+			body:         `{"prompt":"hello"}`,
+			expectPath:   "/v1/chat/completions",
+			expectStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolution := newModelResolution(conf.ModelResolutionConfig{Precedence: tt.precedence, Sources: sources})
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, tt.path, bytes.NewBufferString(tt.body))
+			if tt.header != "" {
+				c.Request.Header.Set("x-model", tt.header)
+			}
+
+			resolution.resolve(c)
+			assert.Equal(t, tt.expectPath, c.Request.URL.Path)
+			modelRequest, err := parseModelRequest(c, resolution)
+			if tt.expectStatus != 0 {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectStatus, c.Writer.Status())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectModel, modelRequest["model"])
+		})
+	}
+
+	assert.Nil(t, newModelResolution(conf.ModelResolutionConfig{}))
+}
+
+func TestRouter_HandlerFunc_ModelResolution(t *testing.T) {
+	var forwardedPath string
+	var forwardedBody map[string]interface{}
+	router, store, backend := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwardedPath = r.URL.Path
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &forwardedBody)
+		w.Write([]byte(`{"id":"response-id"}`))
+	}))
+	defer backend.Close()
+	registerTestModel(store, backend)
+	router.modelResolution = newModelResolution(conf.ModelResolutionConfig{
+		Sources: []conf.ModelSourceConfig{{PathPrefix: "/v1/deployments/"}},
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/deployments/test-model/completions", bytes.NewBufferString(`{"prompt":"hello"}`))
+	router.HandlerFunc()(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "/v1/completions", forwardedPath)
+	assert.Equal(t, "test-model", forwardedBody["model"])
+}
@@ -0,0 +1,32 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+)
+
+// applyObservability samples the request of c in the access log as configured by its ModelRoute.
+func applyObservability(c *gin.Context, modelRoute *v1alpha1.ModelRoute) {
+	if modelRoute == nil || modelRoute.Spec.Observability == nil || modelRoute.Spec.Observability.AccessLogPercentage == nil {
+		return
+	}
+	accesslog.SetSampling(c, *modelRoute.Spec.Observability.AccessLogPercentage)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/filters/ratelimit"
+)
+
+// outputReservationKey is the context key of the output tokens reserved for the request by the rate limiter.
+const outputReservationKey = "outputReservation"
+
+// reservedOutputTokens returns the number of output tokens to reserve for a request, 0 if it doesn't limit them.
+func reservedOutputTokens(modelRequest ModelRequest) int {
+	tokens := requestedCompletionTokens(modelRequest)
+	if n, ok := modelRequest["n"].(float64); ok && n > 1 {
+		tokens *= int(n)
+	}
+	return tokens
+}
+
+// recordOutputTokens charges the output tokens generated for the request to the output rate limit of the model, to
+// the quotas of its consumer, to the limits of its caller and to the TokenQuotas of its ModelRoute, settling the
+// tokens reserved when the request was admitted.
+func (r *Router) recordOutputTokens(c *gin.Context, model string, tokens int) {
+	if r.consumerStreams != nil {
+		// The usage of a stream takes over the tokens counted from its chunks.
+		c.Set(streamedTokensKey, tokens)
+	}
+	if value, ok := c.Get(quotaReservationKey); ok {
+		value.(*ratelimit.QuotaReservation).Settle(tokens)
+	}
+	if value, ok := c.Get(policyReservationKey); ok {
+		value.(*ratelimit.QuotaReservation).Settle(tokens)
+	}
+	if value, ok := c.Get(tokenQuotaUsageKey); ok {
+		value.(*ratelimit.TokenQuotaUsage).Settle(tokens)
+	}
+	if value, ok := c.Get(outputReservationKey); ok {
+		value.(*ratelimit.OutputReservation).Settle(tokens)
+		return
+	}
+	if r.loadRateLimiter != nil {
+		r.loadRateLimiter.RecordOutputTokens(model, tokens)
+	}
+}
+
+// chargeOutputTokens charges the output tokens streamed so far for the request beyond the tokens reserved for it, to
+// the output rate limit of the model, to the quotas of its consumer, to the limits of its caller and to the
+// TokenQuotas of its ModelRoute.
+func chargeOutputTokens(c *gin.Context, tokens int) {
+	if value, ok := c.Get(outputReservationKey); ok {
+		value.(*ratelimit.OutputReservation).Charge(tokens)
+	}
+	if value, ok := c.Get(quotaReservationKey); ok {
+		value.(*ratelimit.QuotaReservation).Charge(tokens)
+	}
+	if value, ok := c.Get(policyReservationKey); ok {
+		value.(*ratelimit.QuotaReservation).Charge(tokens)
+	}
+	if value, ok := c.Get(tokenQuotaUsageKey); ok {
+		value.(*ratelimit.TokenQuotaUsage).Charge(tokens)
+	}
+}
+
+// releaseOutputReservation returns the tokens reserved for a failed request. The reservation of a successful
+// request whose usage is unknown is kept as its estimate.
+func releaseOutputReservation(c *gin.Context, reservation *ratelimit.OutputReservation) {
+	if status := c.Writer.Status(); status < 200 || status >= 300 {
+		reservation.Cancel()
+	}
+}
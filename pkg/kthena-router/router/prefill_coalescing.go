@@ -0,0 +1,174 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cespare/xxhash"
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/connectors"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+)
+
+const (
+	// defaultCoalescingPrefixLength is the number of leading characters of the prompts the requests are coalesced on
+	// when the ModelServer doesn't set it.
+	defaultCoalescingPrefixLength = 1024
+
+	// coalescedPrefillResultCoalesced and coalescedPrefillResultTimeout are the results of a coalesced prefill: the
+	// prefill it waited for completed, or it stopped waiting after the maximum wait.
+	coalescedPrefillResultCoalesced = "coalesced"
+	coalescedPrefillResultTimeout   = "timeout"
+)
+
+// prefillFlight is a prefill in progress the requests with the same prompt prefix are coalesced with.
+type prefillFlight struct {
+	// prefill and decode are the prefill/decode pair the prefill is sent to.
+	prefill *datastore.PodInfo
+	decode  *datastore.PodInfo
+	// done is closed once the prefill completed.
+	done chan struct{}
+}
+
+// prefillCoalescer keeps the prefills in progress by ModelServer and prompt prefix fingerprint.
+type prefillCoalescer struct {
+	mu      sync.Mutex
+	flights map[string]*prefillFlight
+}
+
+func newPrefillCoalescer() *prefillCoalescer {
+	return &prefillCoalescer{flights: make(map[string]*prefillFlight)}
+}
+
+// join returns the prefill in progress for key. If there is none, it registers the prefill of the request on the
+// given pair and returns a nil flight with the function to call once the prefill completed.
+func (p *prefillCoalescer) join(key string, prefill, decode *datastore.PodInfo) (*prefillFlight, func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if flight, ok := p.flights[key]; ok {
+		return flight, nil
+	}
+	flight := &prefillFlight{prefill: prefill, decode: decode, done: make(chan struct{})}
+	p.flights[key] = flight
+	var once sync.Once
+	return nil, func() {
+		once.Do(func() {
+			p.mu.Lock()
+			delete(p.flights, key)
+			p.mu.Unlock()
+			close(flight.done)
+		})
+	}
+}
+
+// coalescePrefill coalesces the prefill of a PD disaggregated request with the prefill in progress of a request whose
+// prompt starts the same on the ModelServer: the pair of that request is tried first, once its prefill completed, so
+// that the prefill of the request hits the prefix cache of the prefill pod. Otherwise, the prefill of the request is
+// registered for the next ones. It returns the function to call when the request is done.
+func (r *Router) coalescePrefill(c *gin.Context, ctx *framework.Context, modelServerName string) func() {
+	modelServer := r.store.GetModelServer(ctx.ModelServerName)
+	if r.prefills == nil || modelServer == nil || modelServer.Spec.PrefillCoalescing == nil {
+		return func() {}
+	}
+	coalescing := modelServer.Spec.PrefillCoalescing
+	length := defaultCoalescingPrefixLength
+	if coalescing.MinPrefixLength != nil {
+		length = int(*coalescing.MinPrefixLength)
+	}
+	fingerprint := prefillFingerprint(ctx.Prompt, length)
+	first := firstPair(ctx)
+	if fingerprint == "" || first < 0 {
+		return func() {}
+	}
+
+	flight, release := r.prefills.join(modelServerName+"\x00"+fingerprint, ctx.PrefillPods[first], ctx.DecodePods[first])
+	if flight == nil {
+		connectors.OnPrefillDone(c, func(error) { release() })
+		return release
+	}
+
+	promotePair(ctx, flight.prefill, flight.decode)
+	timer := time.NewTimer(coalescing.MaxWait.Duration)
+	defer timer.Stop()
+	result := coalescedPrefillResultCoalesced
+	select {
+	case <-flight.done:
+	case <-timer.C:
+		result = coalescedPrefillResultTimeout
+	case <-c.Request.Context().Done():
+		return func() {}
+	}
+	klog.V(4).Infof("prefill of request %s coalesced on prefill pod %s: %s",
+		c.Request.Header.Get("x-request-id"), flight.prefill.Pod.Name, result)
+	r.metrics.RecordCoalescedPrefill(ctx.Model, modelServerName, result)
+	return func() {}
+}
+
+// prefillFingerprint returns the fingerprint of the first length characters of the prompt, empty if it is shorter.
+func prefillFingerprint(prompt common.ChatMessage, length int) string {
+	var prefix strings.Builder
+	for _, message := range prompt.Messages {
+		if prefix.Len() >= length {
+			break
+		}
+		prefix.WriteString(message.Role)
+		prefix.WriteByte(0)
+		prefix.WriteString(message.Content)
+		prefix.WriteByte(0)
+	}
+	if len(prompt.Messages) == 0 {
+		prefix.WriteString(prompt.Text)
+	}
+	if prefix.Len() < length {
+		return ""
+	}
+	return strconv.FormatUint(xxhash.Sum64String(prefix.String()[:length]), 16)
+}
+
+// firstPair returns the index of the first prefill/decode pair of the request, -1 if there is none.
+func firstPair(ctx *framework.Context) int {
+	for i := 0; i < len(ctx.PrefillPods) && i < len(ctx.DecodePods); i++ {
+		if ctx.PrefillPods[i] != nil && ctx.DecodePods[i] != nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// promotePair moves the given prefill/decode pair first in the pairs of the request, the pair is added if the
+// request doesn't have it.
+func promotePair(ctx *framework.Context, prefill, decode *datastore.PodInfo) {
+	prefillPods := []*datastore.PodInfo{prefill}
+	decodePods := []*datastore.PodInfo{decode}
+	for i := 0; i < len(ctx.PrefillPods) && i < len(ctx.DecodePods); i++ {
+		if ctx.PrefillPods[i] == prefill && ctx.DecodePods[i] == decode {
+			continue
+		}
+		prefillPods = append(prefillPods, ctx.PrefillPods[i])
+		decodePods = append(decodePods, ctx.DecodePods[i])
+	}
+	ctx.PrefillPods = prefillPods
+	ctx.DecodePods = decodePods
+}
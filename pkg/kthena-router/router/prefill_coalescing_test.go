@@ -0,0 +1,146 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+)
+
+func TestPrefillFingerprint(t *testing.T) {
+	prefix := strings.Repeat("few-shot example. ", 8)
+	chat := func(question string) common.ChatMessage {
+		return common.ChatMessage{Messages: []common.Message{
+			{Role: "system", Content: prefix},
+			{Role: "user", Content: question},
+		}}
+	}
+
+	assert.NotEmpty(t, prefillFingerprint(chat("first question"), 64))
+	assert.Equal(t, prefillFingerprint(chat("first question"), 64), prefillFingerprint(chat("second question"), 64))
+	assert.NotEqual(t, prefillFingerprint(chat("first question"), 64), prefillFingerprint(chat("second question"), 200))
+	assert.Empty(t, prefillFingerprint(chat("question"), 1024), "prompts shorter than the prefix are not coalesced")
+	assert.Equal(t,
+		prefillFingerprint(common.ChatMessage{Text: prefix + "first question"}, 64),
+		prefillFingerprint(common.ChatMessage{Text: prefix + "second question"}, 64))
+	assert.NotEqual(t,
+		prefillFingerprint(common.ChatMessage{Text: prefix}, 64),
+		prefillFingerprint(common.ChatMessage{Text: "other " + prefix}, 64))
+}
+
+func TestCoalescePrefill(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := datastore.New()
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "pd", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			Model:           ptr.To("model"),
+			InferenceEngine: aiv1alpha1.VLLM,
+			PrefillCoalescing: &aiv1alpha1.PrefillCoalescing{
+				MaxWait:         metav1.Duration{Duration: 200 * time.Millisecond},
+				MinPrefixLength: ptr.To[int32](32),
+			},
+		},
+	}
+	store.AddOrUpdateModelServer(modelServer, sets.New[types.NamespacedName]())
+	r := NewRouter(store, "")
+
+	pod := func(name string) *datastore.PodInfo {
+		return &datastore.PodInfo{Pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}}
+	}
+	prefill1, decode1, prefill2, decode2 := pod("prefill-1"), pod("decode-1"), pod("prefill-2"), pod("decode-2")
+	request := func(prompt string, prefillPods, decodePods []*datastore.PodInfo) (*gin.Context, *framework.Context) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("POST", "/v1/completions", nil)
+		return c, &framework.Context{
+			Model:           "model",
+			Prompt:          common.ChatMessage{Text: prompt},
+			ModelServerName: types.NamespacedName{Namespace: "default", Name: "pd"},
+			PrefillPods:     prefillPods,
+			DecodePods:      decodePods,
+		}
+	}
+	prompt := strings.Repeat("shared prefix ", 4)
+
+	t.Run("follower waits for the prefill of the leader", func(t *testing.T) {
+		c, leader := request(prompt+"first", []*datastore.PodInfo{prefill1, prefill2}, []*datastore.PodInfo{decode1, decode2})
+		release := r.coalescePrefill(c, leader, "default/pd")
+
+		c, follower := request(prompt+"second", []*datastore.PodInfo{prefill2, prefill1}, []*datastore.PodInfo{decode2, decode1})
+		returned := make(chan func())
+		go func() {
+			returned <- r.coalescePrefill(c, follower, "default/pd")
+		}()
+		select {
+		case <-returned:
+			t.Fatal("the follower didn't wait for the prefill of the leader")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		// The connector reports the end of the prefill of the leader.
+		release()
+		select {
+		case done := <-returned:
+			done()
+		case <-time.After(time.Second):
+			t.Fatal("the follower wasn't released by the prefill of the leader")
+		}
+		assert.Equal(t, []*datastore.PodInfo{prefill1, prefill2}, follower.PrefillPods)
+		assert.Equal(t, []*datastore.PodInfo{decode1, decode2}, follower.DecodePods)
+		release()
+	})
+
+	t.Run("follower stops waiting after the maximum wait", func(t *testing.T) {
+		c, leader := request(prompt+"first", []*datastore.PodInfo{prefill1}, []*datastore.PodInfo{decode1})
+		release := r.coalescePrefill(c, leader, "default/pd")
+		defer release()
+
+		c, follower := request(prompt+"second", []*datastore.PodInfo{prefill2}, []*datastore.PodInfo{decode2})
+		start := time.Now()
+		r.coalescePrefill(c, follower, "default/pd")()
+		assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+		assert.Equal(t, []*datastore.PodInfo{prefill1, prefill2}, follower.PrefillPods)
+		assert.Equal(t, []*datastore.PodInfo{decode1, decode2}, follower.DecodePods)
+	})
+
+	t.Run("short prompts are not coalesced", func(t *testing.T) {
+		c, leader := request("short", []*datastore.PodInfo{prefill1}, []*datastore.PodInfo{decode1})
+		release := r.coalescePrefill(c, leader, "default/pd")
+		defer release()
+
+		c, follower := request("short", []*datastore.PodInfo{prefill2}, []*datastore.PodInfo{decode2})
+		start := time.Now()
+		r.coalescePrefill(c, follower, "default/pd")()
+		assert.Less(t, time.Since(start), 100*time.Millisecond)
+		assert.Equal(t, []*datastore.PodInfo{prefill2}, follower.PrefillPods)
+	})
+}
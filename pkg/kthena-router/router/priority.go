@@ -0,0 +1,236 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+)
+
+const (
+	// batchConcurrencyLimit is the error type and finish reason of the batch requests rejected by the admission.
+	batchConcurrencyLimit = "batch_concurrency_limit"
+	// prioritySaturation is the error type and finish reason of the requests shed by the admission for the requests
+	// of a higher level on their saturated model server.
+	prioritySaturation = "priority_saturation"
+)
+
+// defaultSaturationPercent is the share of the capacity of a model server from which it is saturated.
+const defaultSaturationPercent = 90
+
+// weekdays are the days of the week by their name in BusinessHours.
+var weekdays = map[string]time.Weekday{
+	"Sunday":    time.Sunday,
+	"Monday":    time.Monday,
+	"Tuesday":   time.Tuesday,
+	"Wednesday": time.Wednesday,
+	"Thursday":  time.Thursday,
+	"Friday":    time.Friday,
+	"Saturday":  time.Saturday,
+}
+
+// defaultBusinessDays are the days of the business hours not setting them.
+var defaultBusinessDays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+// admission counts the requests in flight per ModelServer and per priority level, to admit the batch requests within
+// the concurrency left to them and to shed the requests of the lower levels once a model server saturates.
+type admission struct {
+	mu       sync.Mutex
+	inflight map[types.NamespacedName]int32
+	// levels counts the requests in flight per ModelServer by the level of their ModelRoute.
+	levels map[types.NamespacedName]map[int32]int32
+	// locations caches the time zones of the business hours by name.
+	locations sync.Map
+	now       func() time.Time
+}
+
+func newAdmission() *admission {
+	return &admission{
+		inflight: make(map[types.NamespacedName]int32),
+		levels:   make(map[types.NamespacedName]map[int32]int32),
+		now:      time.Now,
+	}
+}
+
+// admit admits a request with the given priority to the ModelServer whose pods handle capacity requests at the same
+// time, 0 if it is unbounded. It returns the reason the request is rejected, batchConcurrencyLimit or
+// prioritySaturation, or the function to call once the admitted request completes.
+func (a *admission) admit(modelServerName types.NamespacedName, priority *v1alpha1.Priority, capacity int32) (func(), string) {
+	limit, limited := a.batchLimit(priority)
+	level := priorityLevel(priority)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if limited && a.inflight[modelServerName] >= limit {
+		return nil, batchConcurrencyLimit
+	}
+	if a.saturated(modelServerName, priority, capacity) && a.higherLevelInflight(modelServerName, level) {
+		return nil, prioritySaturation
+	}
+	a.inflight[modelServerName]++
+	levels, ok := a.levels[modelServerName]
+	if !ok {
+		levels = make(map[int32]int32)
+		a.levels[modelServerName] = levels
+	}
+	levels[level]++
+	return func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if a.inflight[modelServerName]--; a.inflight[modelServerName] <= 0 {
+			delete(a.inflight, modelServerName)
+		}
+		if levels[level]--; levels[level] <= 0 {
+			delete(levels, level)
+		}
+		if len(levels) == 0 {
+			delete(a.levels, modelServerName)
+		}
+	}, ""
+}
+
+// saturated reports whether the requests in flight on a model server reach the share of its capacity from which it is
+// saturated for the requests with the given priority. It must be called with the lock held.
+func (a *admission) saturated(modelServerName types.NamespacedName, priority *v1alpha1.Priority, capacity int32) bool {
+	if capacity <= 0 {
+		return false
+	}
+	percent := int64(defaultSaturationPercent)
+	if priority != nil && priority.SaturationPercent != nil {
+		percent = int64(*priority.SaturationPercent)
+	}
+	return int64(a.inflight[modelServerName])*100 >= int64(capacity)*percent
+}
+
+// higherLevelInflight reports whether requests of a level higher than the given one are in flight on a model server.
+// It must be called with the lock held.
+func (a *admission) higherLevelInflight(modelServerName types.NamespacedName, level int32) bool {
+	for other, count := range a.levels[modelServerName] {
+		if other > level && count > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// priorityLevel returns the level of the requests with the given priority, 0 by default.
+func priorityLevel(priority *v1alpha1.Priority) int32 {
+	if priority == nil || priority.Level == nil {
+		return 0
+	}
+	return *priority.Level
+}
+
+// batchLimit returns the number of requests in flight on a model server from which the requests with the given
+// priority are rejected, limited is false if they are always admitted.
+func (a *admission) batchLimit(priority *v1alpha1.Priority) (limit int32, limited bool) {
+	if priority == nil || priority.Class != v1alpha1.TrafficClassBatch || priority.MaxConcurrentRequests == nil {
+		return 0, false
+	}
+	limit = *priority.MaxConcurrentRequests
+	if reservation := priority.InteractiveReservation; reservation != nil && a.inBusinessHours(&reservation.BusinessHours) {
+		limit -= limit * reservation.Percentage / 100
+	}
+	return limit, true
+}
+
+// inBusinessHours reports whether the current time is within the business hours.
+func (a *admission) inBusinessHours(hours *v1alpha1.BusinessHours) bool {
+	start, err := parseClock(hours.Start)
+	if err != nil {
+		klog.Errorf("invalid start of business hours: %v", err)
+		return false
+	}
+	end, err := parseClock(hours.End)
+	if err != nil {
+		klog.Errorf("invalid end of business hours: %v", err)
+		return false
+	}
+
+	now := a.now().In(a.location(hours.TimeZone))
+	clock := time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute
+	day := now.Weekday()
+	switch {
+	case start < end:
+		if clock < start || clock >= end {
+			return false
+		}
+	case clock >= start:
+	case clock < end:
+		// The window started the day before.
+		day = (day + 6) % 7
+	default:
+		return false
+	}
+
+	if len(hours.Days) == 0 {
+		return slices.Contains(defaultBusinessDays, day)
+	}
+	for _, name := range hours.Days {
+		if weekday, ok := weekdays[name]; ok && weekday == day {
+			return true
+		}
+	}
+	return false
+}
+
+// location returns the time zone of the given name, UTC if it is empty or unknown.
+func (a *admission) location(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	if location, ok := a.locations.Load(name); ok {
+		return location.(*time.Location)
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		klog.Errorf("unknown time zone %q of business hours, UTC is used: %v", name, err)
+		location = time.UTC
+	}
+	a.locations.Store(name, location)
+	return location
+}
+
+// parseClock parses a time of the day formatted as "HH:MM".
+func parseClock(value string) (time.Duration, error) {
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute, nil
+}
+
+// rejectAdmission rejects a request the admission did not admit to a model server for the given reason.
+func rejectAdmission(c *gin.Context, modelServerName types.NamespacedName, reason string) {
+	message := fmt.Sprintf("too many concurrent batch requests on model server %v", modelServerName)
+	if reason == prioritySaturation {
+		message = fmt.Sprintf("model server %v is saturated by requests of a higher priority", modelServerName)
+	}
+	accesslog.SetError(c, reason, message)
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, message)
+	c.Set("finishReason", reason)
+}
@@ -0,0 +1,251 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+func TestAdmissionInBusinessHours(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+	tests := []struct {
+		name  string
+		hours aiv1alpha1.BusinessHours
+		now   time.Time
+		want  bool
+	}{
+		{
+			name:  "weekday within the window",
+			hours: aiv1alpha1.BusinessHours{Start: "09:00", End: "18:00"},
+			now:   time.Date(2026, time.October, 14, 10, 30, 0, 0, time.UTC), // Wednesday
+			want:  true,
+		},
+		{
+			name:  "end of the window is excluded",
+			hours: aiv1alpha1.BusinessHours{Start: "09:00", End: "18:00"},
+			now:   time.Date(2026, time.October, 14, 18, 0, 0, 0, time.UTC),
+			want:  false,
+		},
+		{
+			name:  "weekend is off by default",
+			hours: aiv1alpha1.BusinessHours{Start: "09:00", End: "18:00"},
+			now:   time.Date(2026, time.October, 17, 10, 30, 0, 0, time.UTC), // Saturday
+			want:  false,
+		},
+		{
+			name:  "configured days",
+			hours: aiv1alpha1.BusinessHours{Days: []string{"Saturday"}, Start: "09:00", End: "18:00"},
+			now:   time.Date(2026, time.October, 17, 10, 30, 0, 0, time.UTC),
+			want:  true,
+		},
+		{
+			name:  "time zone",
+			hours: aiv1alpha1.BusinessHours{TimeZone: "Europe/Paris", Start: "09:00", End: "18:00"},
+			now:   time.Date(2026, time.October, 14, 8, 30, 0, 0, paris).UTC(),
+			want:  false,
+		},
+		{
+			name:  "overnight window started the day before",
+			hours: aiv1alpha1.BusinessHours{Days: []string{"Friday"}, Start: "22:00", End: "06:00"},
+			now:   time.Date(2026, time.October, 17, 3, 0, 0, 0, time.UTC), // Saturday
+			want:  true,
+		},
+		{
+			name:  "overnight window outside",
+			hours: aiv1alpha1.BusinessHours{Days: []string{"Friday"}, Start: "22:00", End: "06:00"},
+			now:   time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC), // Friday
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newAdmission()
+			a.now = func() time.Time { return tt.now }
+			assert.Equal(t, tt.want, a.inBusinessHours(&tt.hours))
+		})
+	}
+}
+
+func TestAdmissionAdmit(t *testing.T) {
+	a := newAdmission()
+	now := time.Date(2026, time.October, 14, 10, 30, 0, 0, time.UTC) // Wednesday
+	a.now = func() time.Time { return now }
+	modelServer := types.NamespacedName{Namespace: "default", Name: "ms-1"}
+	batch := &aiv1alpha1.Priority{
+		Class:                 aiv1alpha1.TrafficClassBatch,
+		MaxConcurrentRequests: ptr.To(int32(4)),
+		InteractiveReservation: &aiv1alpha1.InteractiveReservation{
+			Percentage:    50,
+			BusinessHours: aiv1alpha1.BusinessHours{Start: "09:00", End: "18:00"},
+		},
+	}
+
+	// During business hours, half of the concurrency is reserved to the interactive requests.
+	var releases []func()
+	for i := 0; i < 2; i++ {
+		release, rejection := a.admit(modelServer, batch, 0)
+		assert.Empty(t, rejection)
+		releases = append(releases, release)
+	}
+	_, rejection := a.admit(modelServer, batch, 0)
+	assert.Equal(t, batchConcurrencyLimit, rejection)
+	// Interactive requests are always admitted and count against the batch requests.
+	for i := 0; i < 4; i++ {
+		release, rejection := a.admit(modelServer, nil, 0)
+		assert.Empty(t, rejection)
+		releases = append(releases, release)
+	}
+	// The batch requests of other model servers are not affected.
+	release, rejection := a.admit(types.NamespacedName{Namespace: "default", Name: "ms-2"}, batch, 0)
+	assert.Empty(t, rejection)
+	release()
+
+	// Off-hours, the batch requests can use the whole concurrency.
+	now = time.Date(2026, time.October, 14, 20, 0, 0, 0, time.UTC)
+	_, rejection = a.admit(modelServer, batch, 0)
+	assert.Equal(t, batchConcurrencyLimit, rejection)
+	for _, release := range releases[:3] {
+		release()
+	}
+	release, rejection = a.admit(modelServer, batch, 0)
+	assert.Empty(t, rejection)
+	release()
+	for _, release := range releases[3:] {
+		release()
+	}
+	assert.Empty(t, a.inflight)
+	assert.Empty(t, a.levels)
+}
+
+func TestAdmissionPrioritySaturation(t *testing.T) {
+	a := newAdmission()
+	modelServer := types.NamespacedName{Namespace: "default", Name: "ms-1"}
+	low := &aiv1alpha1.Priority{}
+	high := &aiv1alpha1.Priority{Level: ptr.To(int32(10))}
+	eager := &aiv1alpha1.Priority{SaturationPercent: ptr.To(int32(50))}
+	admit := func(priority *aiv1alpha1.Priority, capacity int32, releases *[]func()) string {
+		release, rejection := a.admit(modelServer, priority, capacity)
+		if rejection == "" {
+			*releases = append(*releases, release)
+		}
+		return rejection
+	}
+
+	// Without requests of a higher level, the requests of the low level use the whole capacity.
+	var lowReleases, highReleases []func()
+	for i := 0; i < 10; i++ {
+		assert.Empty(t, admit(low, 10, &lowReleases))
+	}
+	for _, release := range lowReleases[4:] {
+		release()
+	}
+	lowReleases = lowReleases[:4]
+	// The requests of the high level are admitted whatever the saturation.
+	for i := 0; i < 4; i++ {
+		assert.Empty(t, admit(high, 10, &highReleases))
+	}
+	// The model server is saturated from 90% of its capacity, 9 requests.
+	assert.Empty(t, admit(low, 10, &lowReleases))
+	assert.Equal(t, prioritySaturation, admit(low, 10, &lowReleases))
+	assert.Equal(t, prioritySaturation, admit(eager, 18, &lowReleases))
+	assert.Empty(t, admit(high, 10, &highReleases))
+	// Model servers without capacity are never saturated.
+	assert.Empty(t, admit(low, 0, &lowReleases))
+
+	// Once the requests of the high level completed, the requests of the low level are admitted again.
+	for _, release := range highReleases {
+		release()
+	}
+	assert.Empty(t, admit(low, 10, &lowReleases))
+	for _, release := range lowReleases {
+		release()
+	}
+	assert.Empty(t, a.inflight)
+	assert.Empty(t, a.levels)
+}
+
+func TestRouter_HandlerFunc_BatchPriority(t *testing.T) {
+	router, store, backend := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"response-id"}`)
+	}))
+	defer backend.Close()
+	router.admission.now = func() time.Time { return time.Date(2026, time.October, 14, 10, 30, 0, 0, time.UTC) }
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: "ms-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+			InferenceEngine: "vLLM",
+		},
+	}
+	store.AddOrUpdateModelServer(modelServer, sets.New(types.NamespacedName{Name: "pod-1", Namespace: "default"}))
+	store.AddOrUpdatePod(&corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "pod-1", Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}, []*aiv1alpha1.ModelServer{modelServer})
+	for name, priority := range map[string]*aiv1alpha1.Priority{
+		"interactive": nil,
+		"batch": {
+			Class:                 aiv1alpha1.TrafficClassBatch,
+			MaxConcurrentRequests: ptr.To(int32(8)),
+			InteractiveReservation: &aiv1alpha1.InteractiveReservation{
+				Percentage:    100,
+				BusinessHours: aiv1alpha1.BusinessHours{Start: "09:00", End: "18:00"},
+			},
+		},
+	} {
+		store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: aiv1alpha1.ModelRouteSpec{
+				ModelName: name,
+				Rules:     []*aiv1alpha1.Rule{{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "ms-1"}}}},
+				Priority:  priority,
+			},
+		})
+	}
+
+	for model, wantCode := range map[string]int{"interactive": http.StatusOK, "batch": http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(fmt.Sprintf(`{"model": %q, "prompt": "hello"}`, model)))
+		router.HandlerFunc()(c)
+		assert.Equal(t, wantCode, w.Code, model)
+	}
+	assert.Empty(t, router.admission.inflight)
+}
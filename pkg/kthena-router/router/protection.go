@@ -0,0 +1,179 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	routermetrics "github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+)
+
+const (
+	// bodyBufferLimit and memoryWatermark are the error types of the requests rejected to protect the router.
+	bodyBufferLimit = "body_buffer_limit"
+	memoryWatermark = "memory_watermark"
+
+	// memorySampleInterval is the minimum time between two samples of the memory used by the router.
+	memorySampleInterval = 100 * time.Millisecond
+)
+
+// errBodyBufferLimit fails the read of a request body of unknown size exceeding the buffered body limit.
+var errBodyBufferLimit = errors.New("request body exceeds the buffered body limit of the router")
+
+// protection rejects the requests the router can't hold without exhausting its memory: the requests received while
+// the memory used by the router is above its watermark, and the requests whose body doesn't fit in the bytes left to
+// the buffered bodies.
+type protection struct {
+	maxBufferedBodyBytes int64
+	memoryWatermark      int64
+	buffered             atomic.Int64
+
+	mu         sync.Mutex
+	inUse      int64
+	sampledAt  time.Time
+	readMemory func() int64
+	now        func() time.Time
+
+	metrics *routermetrics.Metrics
+}
+
+// newProtection returns the protection of the given limits, nil if none is set.
+func newProtection(maxBufferedBodyBytes, memoryWatermark int64, m *routermetrics.Metrics) *protection {
+	m.MemoryWatermark.Set(float64(memoryWatermark))
+	if maxBufferedBodyBytes <= 0 && memoryWatermark <= 0 {
+		return nil
+	}
+	return &protection{
+		maxBufferedBodyBytes: maxBufferedBodyBytes,
+		memoryWatermark:      memoryWatermark,
+		readMemory:           readMemoryInUse,
+		now:                  time.Now,
+		metrics:              m,
+	}
+}
+
+// admit reserves the buffered body bytes of a request. It aborts the request and returns false if the request is
+// rejected. The returned function must be called once an admitted request completes.
+func (p *protection) admit(c *gin.Context) (func(), bool) {
+	if p == nil {
+		return func() {}, true
+	}
+	if p.memoryWatermark > 0 && p.memoryInUse() >= p.memoryWatermark {
+		p.reject(c, http.StatusServiceUnavailable, memoryWatermark, "router memory is above its watermark")
+		return nil, false
+	}
+	if p.maxBufferedBodyBytes <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return func() {}, true
+	}
+
+	if size := c.Request.ContentLength; size >= 0 {
+		if size > p.maxBufferedBodyBytes {
+			p.reject(c, http.StatusRequestEntityTooLarge, bodyBufferLimit, "request body is larger than the buffered body limit of the router")
+			return nil, false
+		}
+		if !p.reserve(size) {
+			p.reject(c, http.StatusServiceUnavailable, bodyBufferLimit, "too many request bodies buffered")
+			return nil, false
+		}
+		return func() { p.free(size) }, true
+	}
+
+	// The size of a chunked body is only known once it is read, its bytes are reserved as they are read.
+	body := &bufferedBody{ReadCloser: c.Request.Body, protection: p}
+	c.Request.Body = body
+	return func() { p.free(body.reserved.Load()) }, true
+}
+
+func (p *protection) reserve(n int64) bool {
+	for {
+		buffered := p.buffered.Load()
+		if buffered+n > p.maxBufferedBodyBytes {
+			return false
+		}
+		if p.buffered.CompareAndSwap(buffered, buffered+n) {
+			p.metrics.BufferedBodyBytes.Add(float64(n))
+			return true
+		}
+	}
+}
+
+func (p *protection) free(n int64) {
+	p.buffered.Add(-n)
+	p.metrics.BufferedBodyBytes.Sub(float64(n))
+}
+
+// memoryInUse returns the memory used by the router, sampled at most every memorySampleInterval.
+func (p *protection) memoryInUse() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if now := p.now(); now.Sub(p.sampledAt) >= memorySampleInterval {
+		p.inUse = p.readMemory()
+		p.sampledAt = now
+		p.metrics.MemoryInUse.Set(float64(p.inUse))
+	}
+	return p.inUse
+}
+
+func (p *protection) reject(c *gin.Context, status int, errorType, message string) {
+	p.metrics.RecordProtectionRejection(errorType)
+	accesslog.SetError(c, errorType, message)
+	c.AbortWithStatusJSON(status, message)
+}
+
+// bufferedBody reserves the bytes of a request body of unknown size as they are read.
+type bufferedBody struct {
+	io.ReadCloser
+	protection *protection
+	reserved   atomic.Int64
+}
+
+func (b *bufferedBody) Read(buf []byte) (int, error) {
+	n, err := b.ReadCloser.Read(buf)
+	if n > 0 {
+		if !b.protection.reserve(int64(n)) {
+			b.protection.metrics.RecordProtectionRejection(bodyBufferLimit)
+			return 0, errBodyBufferLimit
+		}
+		b.reserved.Add(int64(n))
+	}
+	return n, err
+}
+
+// memorySamples are the runtime metrics of the memory mapped by the Go runtime and of the part returned to the OS.
+var memorySamples = []string{"/memory/classes/total:bytes", "/memory/classes/heap/released:bytes"}
+
+// readMemoryInUse returns the memory mapped by the Go runtime and not returned to the OS.
+func readMemoryInUse() int64 {
+	samples := make([]metrics.Sample, len(memorySamples))
+	for i, name := range memorySamples {
+		samples[i].Name = name
+	}
+	metrics.Read(samples)
+	if samples[0].Value.Kind() != metrics.KindUint64 || samples[1].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return int64(samples[0].Value.Uint64() - samples[1].Value.Uint64())
+}
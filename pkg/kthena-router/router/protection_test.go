@@ -0,0 +1,126 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+)
+
+func bodyContext(body string, chunked bool) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	if chunked {
+		c.Request.ContentLength = -1
+	}
+	return c, w
+}
+
+func TestProtectionBufferedBodyLimit(t *testing.T) {
+	p := newProtection(10, 0, metrics.DefaultMetrics)
+
+	c, _ := bodyContext("123456", false)
+	release, ok := p.admit(c)
+	require.True(t, ok)
+
+	c, w := bodyContext("123456", false)
+	_, ok = p.admit(c)
+	assert.False(t, ok, "the body doesn't fit in the bytes left")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	c, w = bodyContext("12345678901", false)
+	_, ok = p.admit(c)
+	assert.False(t, ok, "the body is larger than the limit")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	release()
+	assert.Zero(t, p.buffered.Load())
+	c, _ = bodyContext("123456", false)
+	release, ok = p.admit(c)
+	assert.True(t, ok)
+	release()
+}
+
+func TestProtectionChunkedBody(t *testing.T) {
+	p := newProtection(10, 0, metrics.DefaultMetrics)
+
+	c, _ := bodyContext("1234", true)
+	release, ok := p.admit(c)
+	require.True(t, ok)
+	body, err := io.ReadAll(c.Request.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "1234", string(body))
+	assert.EqualValues(t, 4, p.buffered.Load())
+
+	c, _ = bodyContext("1234567", true)
+	releaseTooLarge, ok := p.admit(c)
+	require.True(t, ok, "the size of a chunked body is unknown on admission")
+	_, err = io.ReadAll(c.Request.Body)
+	assert.ErrorIs(t, err, errBodyBufferLimit)
+
+	releaseTooLarge()
+	release()
+	assert.Zero(t, p.buffered.Load())
+}
+
+func TestProtectionMemoryWatermark(t *testing.T) {
+	p := newProtection(0, 100, metrics.DefaultMetrics)
+	inUse := int64(50)
+	p.readMemory = func() int64 { return inUse }
+	now := time.Now()
+	p.now = func() time.Time { return now }
+
+	c, _ := bodyContext("{}", false)
+	release, ok := p.admit(c)
+	require.True(t, ok)
+	release()
+
+	// The memory is sampled at most every memorySampleInterval.
+	inUse = 100
+	c, _ = bodyContext("{}", false)
+	_, ok = p.admit(c)
+	assert.True(t, ok)
+
+	now = now.Add(memorySampleInterval)
+	c, w := bodyContext("{}", false)
+	_, ok = p.admit(c)
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "watermark")
+}
+
+func TestProtectionDisabled(t *testing.T) {
+	p := newProtection(0, 0, metrics.DefaultMetrics)
+	assert.Nil(t, p)
+	c, _ := bodyContext("{}", false)
+	release, ok := p.admit(c)
+	assert.True(t, ok)
+	release()
+	assert.Positive(t, readMemoryInUse())
+}
@@ -0,0 +1,267 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+const (
+	// priorityHeader marks a request as batch in the queue of the router, the requests of the ModelRoutes of the
+	// Batch class are always batch.
+	priorityHeader = "x-kthena-priority"
+
+	// queueFull and queueTimeout are the error types of the requests rejected by the queue.
+	queueFull    = "queue_full"
+	queueTimeout = "queue_timeout"
+
+	defaultQueueMaxDepth           = 1024
+	defaultInteractiveQueueTimeout = 30 * time.Second
+	defaultBatchQueueTimeout       = 5 * time.Minute
+
+	// defaultPeekLimit bounds the bytes of a body read to find the model of a request before it is admitted, when
+	// the router doesn't limit the buffered bodies.
+	defaultPeekLimit = 1 << 20
+)
+
+// queuedRequest is a request waiting for a concurrency slot of the router.
+type queuedRequest struct {
+	class    v1alpha1.TrafficClass
+	enqueued time.Time
+	// ready is closed when the request is handed the slot of a completed request.
+	ready chan struct{}
+}
+
+// requestQueue holds the requests received while the router handles its maximum number of concurrent requests.
+// The slot of a completed request is handed to the oldest interactive request, or to the oldest batch request if no
+// interactive request is queued.
+type requestQueue struct {
+	mu          sync.Mutex
+	interactive []*queuedRequest
+	batch       []*queuedRequest
+	maxDepth    int
+	timeouts    map[v1alpha1.TrafficClass]time.Duration
+	metrics     *metrics.Metrics
+}
+
+// newRequestQueue returns the queue of the given configuration, nil if it is not enabled.
+func newRequestQueue(config conf.QueueConfig, m *metrics.Metrics) *requestQueue {
+	if !config.Enabled {
+		return nil
+	}
+	q := &requestQueue{
+		maxDepth: defaultQueueMaxDepth,
+		timeouts: map[v1alpha1.TrafficClass]time.Duration{
+			v1alpha1.TrafficClassInteractive: defaultInteractiveQueueTimeout,
+			v1alpha1.TrafficClassBatch:       defaultBatchQueueTimeout,
+		},
+		metrics: m,
+	}
+	if config.MaxDepth > 0 {
+		q.maxDepth = config.MaxDepth
+	}
+	if config.InteractiveTimeoutSeconds > 0 {
+		q.timeouts[v1alpha1.TrafficClassInteractive] = time.Duration(config.InteractiveTimeoutSeconds) * time.Second
+	}
+	if config.BatchTimeoutSeconds > 0 {
+		q.timeouts[v1alpha1.TrafficClassBatch] = time.Duration(config.BatchTimeoutSeconds) * time.Second
+	}
+	return q
+}
+
+// push queues a request of the given class unless acquire gets it a slot. It returns nil and whether the request
+// got a slot if the request is not queued, which is the case when the queue is full.
+func (q *requestQueue) push(class v1alpha1.TrafficClass, acquire func() bool) (*queuedRequest, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	// A slot released since the request failed to acquire one is not handed to the queue if it was empty.
+	if acquire() {
+		return nil, true
+	}
+	if len(q.interactive)+len(q.batch) >= q.maxDepth {
+		return nil, false
+	}
+	req := &queuedRequest{class: class, enqueued: time.Now(), ready: make(chan struct{})}
+	if class == v1alpha1.TrafficClassBatch {
+		q.batch = append(q.batch, req)
+	} else {
+		q.interactive = append(q.interactive, req)
+	}
+	q.metrics.IncQueueLength(string(class))
+	return req, false
+}
+
+// release hands the slot of a completed request to the next queued request, or frees it with free if the queue is
+// empty.
+func (q *requestQueue) release(free func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var next *queuedRequest
+	switch {
+	case len(q.interactive) > 0:
+		next, q.interactive = q.interactive[0], q.interactive[1:]
+	case len(q.batch) > 0:
+		next, q.batch = q.batch[0], q.batch[1:]
+	default:
+		free()
+		return
+	}
+	q.metrics.DecQueueLength(string(next.class))
+	q.metrics.RecordQueueDuration(string(next.class), time.Since(next.enqueued))
+	close(next.ready)
+}
+
+// remove removes a request leaving the queue without a slot, it returns false if the request was handed a slot.
+func (q *requestQueue) remove(req *queuedRequest) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	queue := &q.interactive
+	if req.class == v1alpha1.TrafficClassBatch {
+		queue = &q.batch
+	}
+	i := slices.Index(*queue, req)
+	if i < 0 {
+		return false
+	}
+	*queue = slices.Delete(*queue, i, i+1)
+	q.metrics.DecQueueLength(string(req.class))
+	return true
+}
+
+// acquire reserves a concurrency slot for a new request, queueing the request while the router is at capacity if
+// the queue is enabled. It aborts the request and returns false if the request doesn't get a slot.
+func (r *Router) acquire(c *gin.Context) bool {
+	if r.tryAcquire() {
+		return true
+	}
+	if r.queue == nil {
+		rejectOverloaded(c, "concurrency_limit", "too many concurrent requests")
+		return false
+	}
+
+	class := r.requestClass(c)
+	req, acquired := r.queue.push(class, r.tryAcquire)
+	if req == nil {
+		if !acquired {
+			r.metrics.RecordQueueRejection(string(class), queueFull)
+			rejectOverloaded(c, queueFull, "too many concurrent requests")
+		}
+		return acquired
+	}
+
+	timer := time.NewTimer(r.queue.timeouts[class])
+	defer timer.Stop()
+	defer func() { handlers.GetServerTiming(c).Queued(time.Since(req.enqueued)) }()
+	select {
+	case <-req.ready:
+		return true
+	case <-timer.C:
+		if !r.queue.remove(req) {
+			return true
+		}
+		r.metrics.RecordQueueRejection(string(class), queueTimeout)
+		rejectOverloaded(c, queueTimeout, "request timed out in the queue")
+		return false
+	case <-c.Request.Context().Done():
+		if !r.queue.remove(req) {
+			r.release()
+		}
+		accesslog.SetError(c, clientDisconnected, "client disconnected while queued")
+		c.AbortWithStatus(statusClientClosedRequest)
+		return false
+	}
+}
+
+// requestClass returns the traffic class of a request in the queue: batch if the priority header says so or if the
+// ModelRoute of its model is of the Batch class, interactive otherwise.
+func (r *Router) requestClass(c *gin.Context) v1alpha1.TrafficClass {
+	if hasBatchPriority(c) {
+		return v1alpha1.TrafficClassBatch
+	}
+	model := peekModel(c.Request, r.peekLimit())
+	if model == "" {
+		return v1alpha1.TrafficClassInteractive
+	}
+	_, _, modelRoute, err := r.store.MatchModelServer(model, c.Request, c.GetStringSlice(GatewayKey))
+	if err == nil && isBatchRoute(modelRoute) {
+		return v1alpha1.TrafficClassBatch
+	}
+	return v1alpha1.TrafficClassInteractive
+}
+
+// hasBatchPriority reports whether the priority header marks the request as batch.
+func hasBatchPriority(c *gin.Context) bool {
+	return strings.EqualFold(c.Request.Header.Get(priorityHeader), string(v1alpha1.TrafficClassBatch))
+}
+
+// isBatchRoute reports whether the ModelRoute is of the Batch class.
+func isBatchRoute(modelRoute *v1alpha1.ModelRoute) bool {
+	return modelRoute != nil && modelRoute.Spec.Priority != nil && modelRoute.Spec.Priority.Class == v1alpha1.TrafficClassBatch
+}
+
+// peekLimit returns the maximum size of the bodies read to find the model of a request before it is admitted: the
+// buffered body limit of the router if set, as the larger bodies are rejected anyway.
+func (r *Router) peekLimit() int64 {
+	if r.protection != nil && r.protection.maxBufferedBodyBytes > 0 {
+		return r.protection.maxBufferedBodyBytes
+	}
+	return defaultPeekLimit
+}
+
+// peekModel returns the model of a JSON request, empty if it has none, leaving its body to be read again. The bodies
+// larger than limit are not read further than the limit, and have no model.
+func peekModel(req *http.Request, limit int64) string {
+	if req.Body == nil || !strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") || req.ContentLength > limit {
+		return ""
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, limit+1))
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+	if err != nil || int64(len(body)) > limit {
+		return ""
+	}
+	var request struct {
+		Model string `json:"model"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return ""
+	}
+	return request.Model
+}
+
+// rejectOverloaded rejects a request the router has no capacity for.
+func rejectOverloaded(c *gin.Context, errorType, message string) {
+	accesslog.SetError(c, errorType, message)
+	c.AbortWithStatusJSON(http.StatusServiceUnavailable, message)
+}
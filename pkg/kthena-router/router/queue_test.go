@@ -0,0 +1,192 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+func newQueueRouter(maxConcurrentRequests int, config conf.QueueConfig) *Router {
+	config.Enabled = true
+	return &Router{
+		store:    datastore.New(),
+		metrics:  metrics.DefaultMetrics,
+		inflight: make(chan struct{}, maxConcurrentRequests),
+		queue:    newRequestQueue(config, metrics.DefaultMetrics),
+	}
+}
+
+func queuedContext(class string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"chat"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	if class != "" {
+		c.Request.Header.Set(priorityHeader, class)
+	}
+	return c, w
+}
+
+func (q *requestQueue) length() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.interactive) + len(q.batch)
+}
+
+func TestQueueHandsSlotsToInteractiveRequestsFirst(t *testing.T) {
+	r := newQueueRouter(1, conf.QueueConfig{})
+	c, _ := queuedContext("")
+	require.True(t, r.acquire(c))
+
+	admitted := make(chan string, 3)
+	enqueue := func(name, class string) {
+		c, _ := queuedContext(class)
+		go func() {
+			if r.acquire(c) {
+				admitted <- name
+			}
+		}()
+	}
+	enqueue("batch", "Batch")
+	require.Eventually(t, func() bool { return r.queue.length() == 1 }, time.Second, time.Millisecond)
+	enqueue("interactive-1", "")
+	require.Eventually(t, func() bool { return r.queue.length() == 2 }, time.Second, time.Millisecond)
+	enqueue("interactive-2", "interactive")
+	require.Eventually(t, func() bool { return r.queue.length() == 3 }, time.Second, time.Millisecond)
+
+	for _, want := range []string{"interactive-1", "interactive-2", "batch"} {
+		r.release()
+		select {
+		case name := <-admitted:
+			assert.Equal(t, want, name)
+		case <-time.After(time.Second):
+			t.Fatalf("%s was not handed the released slot", want)
+		}
+	}
+	assert.Len(t, r.inflight, 1, "the slot is handed over, not freed")
+	r.release()
+	assert.Empty(t, r.inflight)
+}
+
+func TestQueueRejections(t *testing.T) {
+	r := newQueueRouter(1, conf.QueueConfig{MaxDepth: 1})
+	r.queue.timeouts[aiv1alpha1.TrafficClassInteractive] = 50 * time.Millisecond
+	c, _ := queuedContext("")
+	require.True(t, r.acquire(c))
+
+	timedOut := make(chan *httptest.ResponseRecorder)
+	go func() {
+		c, w := queuedContext("")
+		assert.False(t, r.acquire(c))
+		timedOut <- w
+	}()
+	require.Eventually(t, func() bool { return r.queue.length() == 1 }, time.Second, time.Millisecond)
+
+	c, w := queuedContext("")
+	assert.False(t, r.acquire(c), "the queue is full")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	w = <-timedOut
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "timed out in the queue")
+	assert.Zero(t, r.queue.length())
+
+	// A request canceled while queued leaves the queue.
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan bool)
+	go func() {
+		c, _ := queuedContext("")
+		c.Request = c.Request.WithContext(ctx)
+		canceled <- r.acquire(c)
+	}()
+	require.Eventually(t, func() bool { return r.queue.length() == 1 }, time.Second, time.Millisecond)
+	cancel()
+	assert.False(t, <-canceled)
+	assert.Zero(t, r.queue.length())
+
+	r.release()
+	assert.Empty(t, r.inflight)
+}
+
+func TestQueueDisabled(t *testing.T) {
+	r := &Router{inflight: make(chan struct{}, 1)}
+	c, _ := queuedContext("")
+	require.True(t, r.acquire(c))
+	c, w := queuedContext("")
+	assert.False(t, r.acquire(c))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	r.release()
+	assert.Empty(t, r.inflight)
+}
+
+func TestRequestClass(t *testing.T) {
+	r := newQueueRouter(1, conf.QueueConfig{})
+	c, _ := queuedContext("batch")
+	assert.Equal(t, aiv1alpha1.TrafficClassBatch, r.requestClass(c))
+
+	c, _ = queuedContext("")
+	assert.Equal(t, aiv1alpha1.TrafficClassInteractive, r.requestClass(c))
+	body, err := io.ReadAll(c.Request.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"model":"chat"}`, string(body), "the body is left to be read again")
+}
+
+func TestPeekModel(t *testing.T) {
+	body := `{"model":"chat","prompt":"` + strings.Repeat("a", 64) + `"}`
+	request := func(contentLength int64) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/v1/completions", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.ContentLength = contentLength
+		return req
+	}
+	tests := []struct {
+		name          string
+		contentLength int64
+		limit         int64
+		wantModel     string
+	}{
+		{name: "within the limit", contentLength: int64(len(body)), limit: 1024, wantModel: "chat"},
+		{name: "chunked within the limit", contentLength: -1, limit: 1024, wantModel: "chat"},
+		{name: "larger than the limit", contentLength: int64(len(body)), limit: 16},
+		{name: "chunked larger than the limit", contentLength: -1, limit: 16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := request(tt.contentLength)
+			assert.Equal(t, tt.wantModel, peekModel(req, tt.limit))
+			read, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			assert.Equal(t, body, string(read), "the body is left to be read again")
+		})
+	}
+}
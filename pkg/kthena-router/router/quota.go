@@ -0,0 +1,59 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/filters/ratelimit"
+)
+
+const (
+	// quotaReservationKey is the context key of the output tokens reserved for the request by the quotas of its
+	// consumer.
+	quotaReservationKey = "quotaReservation"
+
+	quotaExceeded = "quota_exceeded"
+)
+
+// admitQuota admits the request within the quotas of its consumer, the subject of its token, and returns the output
+// tokens reserved for it. It rejects the request and returns false when a level of the quotas is exhausted.
+func (r *Router) admitQuota(c *gin.Context, inputTokens, maxOutputTokens int) (*ratelimit.QuotaReservation, bool) {
+	reservation, err := r.quotas.Admit(c.GetString(common.UserIdKey), inputTokens, maxOutputTokens)
+	var exceeded *ratelimit.QuotaExceededError
+	if !errors.As(err, &exceeded) {
+		return reservation, true
+	}
+	accesslog.SetError(c, quotaExceeded, exceeded.Error())
+	r.metrics.RecordQuotaExceeded(exceeded.Level, exceeded.LimitType)
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, exceeded.Error())
+	c.Set("finishReason", quotaExceeded)
+	return nil, false
+}
+
+// releaseQuotaReservation returns the tokens reserved by the quotas for a failed request. The reservation of a
+// successful request whose usage is unknown is kept as its estimate.
+func releaseQuotaReservation(c *gin.Context, reservation *ratelimit.QuotaReservation) {
+	if status := c.Writer.Status(); status < 200 || status >= 300 {
+		reservation.Cancel()
+	}
+}
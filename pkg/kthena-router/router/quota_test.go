@@ -0,0 +1,154 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/filters/ratelimit"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+)
+
+func TestRouter_HandlerFunc_Quota(t *testing.T) {
+	router, store, backend := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"response-id","usage":{"prompt_tokens":1,"completion_tokens":5,"total_tokens":6}}`)
+	}))
+	defer backend.Close()
+	quotas, err := ratelimit.NewQuotaLimiter(conf.QuotaConfig{
+		Orgs: []conf.QuotaOrgConfig{{
+			Name: "acme",
+			Teams: []conf.QuotaTeamConfig{{
+				Name:        "search",
+				QuotaLimits: conf.QuotaLimits{RequestsPerUnit: 1, Unit: "hour"},
+				Consumers:   []conf.QuotaConsumerConfig{{Subject: "alice"}},
+			}},
+		}},
+	})
+	require.NoError(t, err)
+	router.quotas = quotas
+	registerTestModel(store, backend)
+
+	send := func(userID string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/v1/completions", bytes.NewBufferString(`{"model": "test-model", "prompt": "hello"}`))
+		c.Set(common.UserIdKey, userID)
+		router.HandlerFunc()(c)
+		return w
+	}
+	exceeded := func() float64 {
+		return testutil.ToFloat64(metrics.DefaultMetrics.QuotaExceeded.WithLabelValues(ratelimit.QuotaLevelTeam, metrics.LimitTypeRequests))
+	}
+	before := exceeded()
+
+	assert.Equal(t, http.StatusOK, send("alice").Code)
+	w := send("alice")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "requests quota of team acme/search exceeded")
+	assert.Equal(t, before+1, exceeded())
+
+	// The callers which are not consumers are not subject to quotas.
+	assert.Equal(t, http.StatusOK, send("bob").Code)
+}
+
+func TestRouter_HandlerFunc_QuotaStreamedTokens(t *testing.T) {
+	// The engine streams more tokens than requested and doesn't report its usage.
+	router, store, backend := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < 10; i++ {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"index\":0,\"text\":\"token%d\"}]}\n\n", i)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer backend.Close()
+	quotas, err := ratelimit.NewQuotaLimiter(conf.QuotaConfig{
+		Orgs: []conf.QuotaOrgConfig{{
+			Name:        "acme",
+			QuotaLimits: conf.QuotaLimits{OutputTokensPerUnit: 5, Unit: "hour"},
+			Teams: []conf.QuotaTeamConfig{{
+				Name:      "search",
+				Consumers: []conf.QuotaConsumerConfig{{Subject: "alice"}},
+			}},
+		}},
+	})
+	require.NoError(t, err)
+	router.quotas = quotas
+	registerTestModel(store, backend)
+
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/v1/completions",
+			bytes.NewBufferString(`{"model": "test-model", "prompt": "hello", "max_tokens": 2, "stream": true}`))
+		c.Set(common.UserIdKey, "alice")
+		router.HandlerFunc()(c)
+		return w
+	}
+
+	w := send()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "token9")
+	// The tokens counted from the chunks are charged instead of the 2 tokens reserved, exhausting the quota.
+	assert.Equal(t, http.StatusTooManyRequests, send().Code)
+}
+
+// registerTestModel registers the model test-model served by backend.
+func registerTestModel(store datastore.Store, backend *httptest.Server) {
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: "llama", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+			InferenceEngine: "vLLM",
+		},
+	}
+	podName := types.NamespacedName{Name: "pod-llama", Namespace: "default"}
+	store.AddOrUpdateModelServer(modelServer, sets.New(podName))
+	store.AddOrUpdatePod(&corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: podName.Name, Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}, []*aiv1alpha1.ModelServer{modelServer})
+	store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "mr-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "test-model",
+			Rules: []*aiv1alpha1.Rule{
+				{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "llama"}}},
+			},
+		},
+	})
+}
@@ -0,0 +1,103 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/filters/auth"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/filters/ratelimit"
+)
+
+const (
+	// policyReservationKey is the context key of the output tokens reserved for the request by the RateLimitPolicies
+	// of its ModelRoute.
+	policyReservationKey = "policyReservation"
+
+	policyRateLimitExceeded = "policy_rate_limit"
+)
+
+// admitRateLimitPolicies admits the request within the limits of its caller at the RateLimitPolicies selecting its
+// ModelRoute, and returns the output tokens reserved for it. It rejects the request and returns false when the
+// limits of the caller at a policy are exhausted.
+func (r *Router) admitRateLimitPolicies(c *gin.Context, modelName string, inputTokens, maxOutputTokens int) (*ratelimit.QuotaReservation, bool) {
+	_, _, modelRoute, err := r.store.MatchModelServer(modelName, c.Request, c.GetStringSlice(GatewayKey))
+	if err != nil || modelRoute == nil {
+		// The request without a ModelRoute is rejected by the load balancing.
+		return nil, true
+	}
+	policies := r.store.GetRateLimitPolicies(modelRoute.Namespace)
+	reservation, err := r.rateLimitPolicies.Admit(policies, modelRoute, func(key v1alpha1.RateLimitKey) string {
+		return rateLimitKey(c, key)
+	}, inputTokens, maxOutputTokens)
+	var exceeded *ratelimit.PolicyRateLimitExceededError
+	if !errors.As(err, &exceeded) {
+		return reservation, true
+	}
+	accesslog.SetError(c, policyRateLimitExceeded, exceeded.Error())
+	r.metrics.RecordRateLimitPolicyExceeded(exceeded.Policy, exceeded.LimitType)
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, exceeded.Error())
+	c.Set("finishReason", "rate_limit")
+	return nil, false
+}
+
+// chargeRequestCost charges the cost of the tokens of the request, priced by the ModelServer which served it, to the
+// cost limits of its caller at the RateLimitPolicies of its ModelRoute. The input tokens are those reported by the
+// ModelServer, the tokens estimated at admission are charged when it didn't report them.
+func (r *Router) chargeRequestCost(c *gin.Context, modelServerName types.NamespacedName, inputTokens, outputTokens int) {
+	value, ok := c.Get(policyReservationKey)
+	if !ok {
+		return
+	}
+	modelServer := r.store.GetModelServer(modelServerName)
+	if modelServer == nil || modelServer.Spec.Pricing == nil {
+		return
+	}
+	if inputTokens <= 0 {
+		inputTokens = c.GetInt("inputTokens")
+	}
+	cost := ratelimit.RequestCost(modelServer.Spec.Pricing, inputTokens, outputTokens)
+	value.(*ratelimit.QuotaReservation).ChargeCost(cost)
+}
+
+// rateLimitKey returns the key of the caller of the request for the key of a RateLimitPolicy. The API keys are
+// hashed, so that the router doesn't hold them.
+func rateLimitKey(c *gin.Context, key v1alpha1.RateLimitKey) string {
+	switch key.Type {
+	case v1alpha1.RateLimitKeyConsumer:
+		return c.GetString(common.UserIdKey)
+	case v1alpha1.RateLimitKeyAPIKey:
+		apiKey := auth.APIKey(c.Request)
+		if apiKey == "" {
+			return ""
+		}
+		digest := sha256.Sum256([]byte(apiKey))
+		return hex.EncodeToString(digest[:])
+	case v1alpha1.RateLimitKeyHeader:
+		return c.Request.Header.Get(key.Header)
+	}
+	return ""
+}
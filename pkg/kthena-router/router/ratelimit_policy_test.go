@@ -0,0 +1,91 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/filters/ratelimit"
+)
+
+func TestRouter_ChargeRequestCost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := datastore.New()
+	router := NewRouter(store, "")
+	modelServerName := types.NamespacedName{Namespace: "default", Name: "llama"}
+	require.NoError(t, store.AddOrUpdateModelServer(&v1alpha1.ModelServer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: modelServerName.Namespace, Name: modelServerName.Name},
+		Spec: v1alpha1.ModelServerSpec{
+			Pricing: &v1alpha1.Pricing{InputPer1KTokens: ptr.To(resource.MustParse("1"))},
+		},
+	}, sets.New[types.NamespacedName]()))
+
+	modelRoute := &v1alpha1.ModelRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "llama"}}
+	policies := []*v1alpha1.RateLimitPolicy{{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "budget", Generation: 1},
+		Spec: v1alpha1.RateLimitPolicySpec{
+			Key:         v1alpha1.RateLimitKey{Type: v1alpha1.RateLimitKeyConsumer},
+			CostPerUnit: ptr.To(resource.MustParse("1")),
+			Unit:        v1alpha1.Hour,
+		},
+	}}
+	limiter := ratelimit.NewPolicyLimiter()
+	callerKey := func(v1alpha1.RateLimitKey) string { return "alice" }
+
+	tests := []struct {
+		name         string
+		promptTokens int
+		wantAdmitted bool
+	}{
+		{
+			// The 10 estimated input tokens cost 0.01, the budget is not exhausted.
+			name:         "usage missing",
+			wantAdmitted: true,
+		},
+		{
+			// The 2000 input tokens reported by the ModelServer cost 2, over the budget.
+			name:         "usage reported",
+			promptTokens: 2000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter.Delete(types.NamespacedName{Namespace: "default", Name: "budget"})
+			reservation, err := limiter.Admit(policies, modelRoute, callerKey, 10, 10)
+			require.NoError(t, err)
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Set("inputTokens", 10)
+			c.Set(policyReservationKey, reservation)
+
+			router.chargeRequestCost(c, modelServerName, tt.promptTokens, 0)
+			_, err = limiter.Admit(policies, modelRoute, callerKey, 10, 10)
+			assert.Equal(t, tt.wantAdmitted, err == nil, "error: %v", err)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"strconv"
+
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+// applyDefaultParameters injects the default sampling parameters of the ModelRoute the client didn't set.
+func applyDefaultParameters(modelRequest ModelRequest, defaults *v1alpha1.DefaultParameters) {
+	if defaults == nil {
+		return
+	}
+
+	if defaults.MaxTokens != nil {
+		_, hasMaxTokens := modelRequest["max_tokens"]
+		_, hasMaxCompletionTokens := modelRequest["max_completion_tokens"]
+		if !hasMaxTokens && !hasMaxCompletionTokens {
+			modelRequest["max_tokens"] = float64(*defaults.MaxTokens)
+		}
+	}
+
+	if defaults.Temperature != nil {
+		if _, ok := modelRequest["temperature"]; !ok {
+			temperature, err := strconv.ParseFloat(*defaults.Temperature, 64)
+			if err != nil {
+				klog.Errorf("invalid default temperature %q: %v", *defaults.Temperature, err)
+				return
+			}
+			modelRequest["temperature"] = temperature
+		}
+	}
+}
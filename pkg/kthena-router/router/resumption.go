@@ -0,0 +1,118 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/resume"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+)
+
+const (
+	// resumeJournalKey and resumeBackendKey store the resumption state of a request in the gin context.
+	resumeJournalKey = "resumeJournal"
+	resumeBackendKey = "resumeBackend"
+
+	resumeLookupTimeout = 2 * time.Second
+)
+
+func getResumeJournal(c *gin.Context) *resume.Journal {
+	if v, ok := c.Get(resumeJournalKey); ok {
+		return v.(*resume.Journal)
+	}
+	return nil
+}
+
+// prepareResume sets up the journal of a streamed request. A request carrying the Last-Event-Id header of a
+// journaled stream is rewritten to continue the generation of that stream, on the backend which served it if possible.
+// Only the caller authenticated on the request which started a stream can resume it.
+// It returns false if the request has already been served, which is the case when the stream had completed.
+func (r *Router) prepareResume(c *gin.Context, modelRequest ModelRequest) bool {
+	requestID := c.Request.Header.Get("x-request-id")
+	owner := c.GetString(common.UserIdKey)
+	lastEventID := c.Request.Header.Get(resume.LastEventIDHeader)
+	if lastEventID == "" {
+		c.Set(resumeJournalKey, resume.NewJournal(c.Request.Context(), r.resumeStore, owner, requestID))
+		return true
+	}
+
+	state, lastSeq, err := r.lookupStream(c.Request.Context(), owner, lastEventID)
+	if err != nil || state == nil {
+		// The stream expired, was never journaled or was started by another caller, fall back to a new generation.
+		klog.V(4).Infof("stream of event %q can't be resumed, restarting generation: %v", lastEventID, err)
+		c.Set(resumeJournalKey, resume.NewJournal(c.Request.Context(), r.resumeStore, owner, requestID))
+		return true
+	}
+
+	journal := resume.Resume(c.Request.Context(), r.resumeStore, state, lastSeq)
+	if state.Done {
+		klog.V(4).Infof("stream of request %s already completed, replaying %d events", state.RequestID, len(state.Events)-lastSeq)
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		_ = journal.WriteReplay(c.Writer)
+		_, _ = c.Writer.Write([]byte("data: [DONE]\n\n"))
+		return false
+	}
+
+	klog.V(4).Infof("resuming stream of request %s after event %d on %s", state.RequestID, lastSeq, state.Backend)
+	resume.Continue(modelRequest, state)
+	c.Set(resumeJournalKey, journal)
+	c.Set(resumeBackendKey, state.Backend)
+	return true
+}
+
+// lookupStream returns the stream of owner an SSE event id belongs to, and the sequence number of the event.
+func (r *Router) lookupStream(ctx context.Context, owner, lastEventID string) (*resume.State, int, error) {
+	requestID, lastSeq, err := resume.ParseEventID(lastEventID)
+	if err != nil {
+		return nil, 0, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, resumeLookupTimeout)
+	defer cancel()
+	state, err := r.resumeStore.Get(ctx, owner, requestID)
+	if err != nil || state == nil {
+		return nil, 0, err
+	}
+	if lastSeq > len(state.Events) {
+		return nil, 0, fmt.Errorf("event %d of request %s was not journaled", lastSeq, requestID)
+	}
+	return state, lastSeq, nil
+}
+
+// preferBackend moves the backend which served a resumed stream to the front of the selected pods,
+// as its prefix cache holds the prompt and the output generated so far.
+func preferBackend(ctx *framework.Context, pods []*datastore.PodInfo, backend string, port int32) {
+	if ctx.BestPods == nil {
+		return
+	}
+	for _, pod := range pods {
+		if pod.Pod == nil || fmt.Sprintf("%s:%d", pod.Pod.Status.PodIP, port) != backend {
+			continue
+		}
+		moveToFront(ctx, pod)
+		return
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+// defaultRetryStatusCodes are the status codes of the model server responses retried on the next pod if the retry
+// policy of the ModelServer doesn't set them.
+var defaultRetryStatusCodes = []int32{
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// retryBudget tracks the retries of a request on the pods selected for it against the retry policy of its
+// ModelServer. Without a policy, the request is retried on every pod whatever the failure.
+type retryBudget struct {
+	policy  *v1alpha1.Retry
+	start   time.Time
+	retries int
+}
+
+func newRetryBudget(modelServer *v1alpha1.ModelServer) *retryBudget {
+	budget := &retryBudget{start: time.Now()}
+	if modelServer != nil && modelServer.Spec.TrafficPolicy != nil {
+		budget.policy = modelServer.Spec.TrafficPolicy.Retry
+	}
+	return budget
+}
+
+// allow reports whether the request which failed with err is retried on the next pod, and counts the retry.
+func (b *retryBudget) allow(err error) bool {
+	if b.policy == nil {
+		return true
+	}
+	if b.retries >= int(b.policy.Attempts) {
+		return false
+	}
+	if budget := duration(b.policy.Budget); budget > 0 && time.Since(b.start) >= budget {
+		return false
+	}
+	if !retriable(b.policy, err) {
+		return false
+	}
+	b.retries++
+	return true
+}
+
+// wait waits for the retry interval of the policy before the next attempt. It returns false if ctx is done first.
+func (b *retryBudget) wait(ctx context.Context) bool {
+	if b.policy == nil {
+		return true
+	}
+	interval := duration(b.policy.RetryInterval)
+	if interval <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// retriable reports whether a request failing on a pod with err is retried by the policy. The responses of the
+// model servers are retried according to their status code, any other failure, e.g. a connection error or a
+// connect or first token timeout, is always retried.
+func retriable(policy *v1alpha1.Retry, err error) bool {
+	var statusErr *upstreamStatusError
+	if !errors.As(err, &statusErr) {
+		return true
+	}
+	statusCodes := policy.StatusCodes
+	if len(statusCodes) == 0 {
+		statusCodes = defaultRetryStatusCodes
+	}
+	return slices.Contains(statusCodes, int32(statusErr.statusCode))
+}
+
+// rewindBody makes req send its body again on the next attempt, when it can be read again.
+func rewindBody(req *http.Request) {
+	if req.GetBody == nil {
+		return
+	}
+	if body, err := req.GetBody(); err == nil {
+		req.Body = body
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/util/sets"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+)
+
+func TestProxyRetry(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// The pods are served by the same backend, the pod a request is sent to is told by its host. The first two pods
+	// are being restarted and answer with a 503, the third one serves the request.
+	var mu sync.Mutex
+	var tried []string
+	listener, err := net.Listen("tcp", "0.0.0.0:0")
+	require.NoError(t, err)
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, _ := net.SplitHostPort(r.Host)
+		mu.Lock()
+		tried = append(tried, host)
+		mu.Unlock()
+		if host != "127.0.0.3" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "data: {\"id\":\"served\"}\n\ndata: [DONE]\n\n")
+	}))
+	backend.Listener.Close()
+	backend.Listener = listener
+	backend.Start()
+	defer backend.Close()
+	port := int32(listener.Addr().(*net.TCPAddr).Port)
+
+	tests := []struct {
+		name      string
+		retry     *aiv1alpha1.Retry
+		wantTried []string
+		wantCode  int
+	}{
+		{
+			name:      "no retry policy",
+			wantTried: []string{"127.0.0.1", "127.0.0.2", "127.0.0.3"},
+			wantCode:  http.StatusOK,
+		},
+		{
+			name:      "retried on the other pods",
+			retry:     &aiv1alpha1.Retry{Attempts: 2, RetryInterval: &v1.Duration{Duration: time.Millisecond}},
+			wantTried: []string{"127.0.0.1", "127.0.0.2", "127.0.0.3"},
+			wantCode:  http.StatusOK,
+		},
+		{
+			name:      "attempts exhausted",
+			retry:     &aiv1alpha1.Retry{Attempts: 1},
+			wantTried: []string{"127.0.0.1", "127.0.0.2"},
+			wantCode:  http.StatusNotFound,
+		},
+		{
+			name:      "status code not retried",
+			retry:     &aiv1alpha1.Retry{Attempts: 2, StatusCodes: []int32{http.StatusBadGateway}},
+			wantTried: []string{"127.0.0.1"},
+			wantCode:  http.StatusNotFound,
+		},
+		{
+			name:      "budget exhausted",
+			retry:     &aiv1alpha1.Retry{Attempts: 2, Budget: &v1.Duration{Duration: time.Nanosecond}},
+			wantTried: []string{"127.0.0.1"},
+			wantCode:  http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tried = nil
+			store := datastore.New()
+			modelServer := &aiv1alpha1.ModelServer{
+				ObjectMeta: v1.ObjectMeta{Name: "ms-1", Namespace: "default"},
+				Spec: aiv1alpha1.ModelServerSpec{
+					WorkloadPort:  aiv1alpha1.WorkloadPort{Port: port},
+					TrafficPolicy: &aiv1alpha1.TrafficPolicy{Retry: tt.retry},
+				},
+			}
+			store.AddOrUpdateModelServer(modelServer, sets.New[types.NamespacedName]())
+			r := NewRouter(store, "")
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(`{"model":"test-model","stream":true}`))
+			ctx := &framework.Context{
+				Model:           "test-model",
+				ModelServerName: types.NamespacedName{Namespace: "default", Name: "ms-1"},
+				BestPods: []*datastore.PodInfo{
+					buildPodInfo("pod-1", "127.0.0.1"), buildPodInfo("pod-2", "127.0.0.2"), buildPodInfo("pod-3", "127.0.0.3"),
+				},
+			}
+
+			err := r.proxy(c, c.Request, ctx, true, port, nil)
+			assert.Equal(t, tt.wantTried, tried)
+			if tt.wantCode == http.StatusOK {
+				require.NoError(t, err)
+				assert.True(t, strings.Contains(w.Body.String(), "served"))
+				return
+			}
+			var failed *backendFailedError
+			require.True(t, errors.As(err, &failed))
+			assert.Equal(t, tt.wantCode, failed.status)
+			assert.False(t, c.Writer.Written())
+		})
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	unavailable := &upstreamStatusError{statusCode: http.StatusServiceUnavailable}
+	badRequest := &upstreamStatusError{statusCode: http.StatusBadRequest}
+
+	budget := newRetryBudget(nil)
+	assert.True(t, budget.allow(badRequest))
+	assert.True(t, budget.wait(context.Background()))
+
+	policy := &aiv1alpha1.Retry{Attempts: 2}
+	assert.True(t, retriable(policy, errConnectTimeout))
+	assert.True(t, retriable(policy, fmt.Errorf("decode request error: %w", unavailable)))
+	assert.False(t, retriable(policy, badRequest))
+	assert.False(t, retriable(&aiv1alpha1.Retry{StatusCodes: []int32{http.StatusTooManyRequests}}, unavailable))
+
+	budget = &retryBudget{policy: policy, start: time.Now()}
+	assert.False(t, budget.allow(badRequest))
+	assert.True(t, budget.allow(unavailable))
+	assert.True(t, budget.allow(errFirstTokenTimeout))
+	assert.False(t, budget.allow(unavailable), "the attempts are exhausted")
+
+	// The wait for the retry interval ends with the request.
+	budget = &retryBudget{policy: &aiv1alpha1.Retry{RetryInterval: &v1.Duration{Duration: time.Hour}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(t, budget.wait(ctx))
+}
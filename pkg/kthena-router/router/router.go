@@ -17,43 +17,75 @@ limitations under the License.
 package router
 
 import (
-	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/catalog"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/connectors"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/events"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/feedback"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/filters/auth"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/filters/ratelimit"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/filters/tokenizer"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/gatewayapi"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/profile"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/resume"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/semcache"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/slo"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/utils"
 )
 
 const (
 	// Context keys for gin context
+	// GatewayKey holds the keys (namespace/name) of the Gateways whose listeners received the request, set by the
+	// Gateway listeners. The Gateways sharing a port and a hostname all receive its requests.
 	GatewayKey = "gatewayKey"
+
+	// clientDisconnected is the error type and finish reason of requests whose client went away.
+	clientDisconnected = "client_disconnected"
+	// statusClientClosedRequest is the non-standard status code conventionally used when the client
+	// closed the connection before the response was sent.
+	statusClientClosedRequest = 499
+	// modelServerHeader returns the ModelServer a request was routed to, to verify the traffic splitting of the ModelRoutes.
+	modelServerHeader = "x-kthena-model-server"
+	// sessionIDHeader identifies the conversation of a request, the prefix cache aware load balancing sends the
+	// requests of a session to the same pod.
+	sessionIDHeader = "x-kthena-session-id"
+	// modelNotAllowed is the error code of the requests calling a model their caller may not call.
+	modelNotAllowed = "model_not_allowed"
+	// authenticationRequired is the error code of the requests calling a model whose route requires another
+	// authenticator.
+	authenticationRequired = "authentication_required"
 )
 
 func getEnvBool(key string, fallback bool) bool {
@@ -67,17 +99,117 @@ func getEnvBool(key string, fallback bool) bool {
 
 var EnableFairnessScheduling = getEnvBool("ENABLE_FAIRNESS_SCHEDULING", false)
 
+// streamBufferSize is the buffer size used to read upstream streaming responses.
+// It is set from the router profile at startup.
+var streamBufferSize = profile.DefaultStreamBufferSize
+
 type Router struct {
 	scheduler       scheduler.Scheduler
-	authenticator   *auth.JWTAuthenticator
+	authenticator   *auth.Chain
 	store           datastore.Store
 	loadRateLimiter *ratelimit.TokenRateLimiter
 	accessLogger    accesslog.AccessLogger
 	metrics         *metrics.Metrics
-	tokenizer       tokenizer.Tokenizer
+	tokenizers      *tokenizer.ModelTokenizers
+
+	// quotas are the limits of the consumers rolled up into teams and orgs.
+	quotas *ratelimit.QuotaLimiter
+
+	// rateLimitPolicies are the limits of each caller of the ModelRoutes selected by the RateLimitPolicies.
+	rateLimitPolicies *ratelimit.PolicyLimiter
+	// tokenQuotas count the tokens consumed in the TokenQuotas of the ModelRoutes.
+	tokenQuotas *ratelimit.TokenQuotaTracker
+	// rateLimitDegradation tracks the ModelServers serving the requests exceeding the rate limits of the ModelRoutes.
+	rateLimitDegradation *rateLimitDegradation
 
 	// KV Connector management
 	connectorFactory *connectors.Factory
+
+	// inflight limits the number of concurrent requests, nil means no limit.
+	inflight chan struct{}
+
+	// eventExporter streams usage events to a message broker, nil if disabled.
+	eventExporter *events.Exporter
+
+	// feedbackTracker aggregates the result quality feedback reported by clients.
+	feedbackTracker *feedback.Tracker
+
+	// inflightRequests allows clients to cancel the requests being processed.
+	inflightRequests *inflightRequests
+
+	// resumeStore journals streamed responses so that they can be resumed, nil if disabled.
+	resumeStore resume.Store
+	// semanticCache answers the prompts similar to the ones already answered, nil if disabled.
+	semanticCache *semcache.Cache
+
+	// experiments are the experimental behaviors requests can be opted in.
+	experiments []conf.ExperimentConfig
+
+	// catalog lists the served models and their metadata.
+	catalog *catalog.Catalog
+
+	// maxAudioFileSize is the maximum size in bytes of the audio files of audio requests.
+	maxAudioFileSize int64
+
+	// imageCosts are the prices of the images generated by the models, by model name.
+	imageCosts map[string]conf.ImageCostConfig
+
+	// imageTokens counts the input tokens of the images of the chat completion requests.
+	imageTokens *tokenizer.ImageTokens
+	// maxVisionPayloadSize is the maximum size in bytes of the body of the requests with images.
+	maxVisionPayloadSize int64
+
+	// scoringBatchSize is the maximum number of inputs of the rerank and classification requests sent to a
+	// model server at once, 0 if the requests are not split.
+	scoringBatchSize int
+
+	// sloTracker exports the burn rates of the service level objectives declared on ModelRoutes.
+	sloTracker *slo.Tracker
+
+	// backendTransports are the transports to the pods of the https ModelServers.
+	backendTransports backendTransports
+
+	// admission admits the batch requests within the concurrency of the model servers left to them, and sheds the
+	// requests of the lower priority levels once a model server saturates.
+	admission *admission
+
+	// concurrency keeps the requests in flight within the limits of their ModelRoutes and of the model server pods.
+	concurrency *concurrencyLimiter
+
+	// protection rejects the requests which would exhaust the memory of the router, nil if it is not limited.
+	protection *protection
+
+	// loadShedder sheds the batch requests while the router pod is under pressure, nil if it is not enabled.
+	loadShedder *loadShedder
+	// loadSheddingConfig is kept for the router profile to enable or disable the load shedding.
+	loadSheddingConfig conf.LoadSheddingConfig
+
+	// consumerStreams counts the streamed responses of the consumers and flags the anomalous ones, nil if disabled.
+	consumerStreams *consumerStreams
+
+	// queue holds the requests received while the router is at capacity, nil if they are rejected.
+	queue *requestQueue
+	// queueConfig is kept for the router profile to enable or disable the queue.
+	queueConfig conf.QueueConfig
+
+	// sessions are the pods the sessions of the ModelRoutes with a consistent hash session affinity are pinned to.
+	sessions *sessionTable
+
+	// prefills are the prefills in progress the PD disaggregated requests sharing their prompt prefix are coalesced
+	// with.
+	prefills *prefillCoalescer
+
+	// mirroredRequests holds a slot per request being mirrored to the mirror ModelServer of its ModelRoute.
+	mirroredRequests chan struct{}
+
+	// evaluatedRequests holds a slot per response being evaluated by the evaluation sink of its ModelRoute.
+	evaluatedRequests chan struct{}
+
+	// serverTiming reports the phases of the requests in Server-Timing response headers.
+	serverTiming bool
+
+	// modelResolution resolves the model of the requests without model field from their headers or path.
+	modelResolution *modelResolution
 }
 
 func NewRouter(store datastore.Store, routerConfigPath string) *Router {
@@ -87,9 +219,6 @@ func NewRouter(store datastore.Store, routerConfigPath string) *Router {
 	// Use global metrics instance
 	metricsInstance := metrics.DefaultMetrics
 
-	// Initialize tokenizer
-	tokenizerInstance := tokenizer.NewSimpleEstimateTokenizer()
-
 	store.RegisterCallback("ModelRoute", func(data datastore.EventData) {
 		switch data.EventType {
 		case datastore.EventAdd, datastore.EventUpdate:
@@ -109,6 +238,19 @@ func NewRouter(store datastore.Store, routerConfigPath string) *Router {
 		}
 	})
 
+	sloTracker := slo.NewTracker(metricsInstance)
+	store.RegisterCallback("ModelRoute", func(data datastore.EventData) {
+		if data.ModelRoute == nil {
+			return
+		}
+		switch data.EventType {
+		case datastore.EventAdd, datastore.EventUpdate:
+			sloTracker.SetObjectives(data.ModelRoute)
+		case datastore.EventDelete:
+			sloTracker.Delete(data.ModelRoute)
+		}
+	})
+
 	routerConfig, err := conf.ParseRouterConfig(routerConfigPath)
 	if err != nil {
 		klog.Fatalf("failed to parse router config: %v", err)
@@ -145,24 +287,265 @@ func NewRouter(store datastore.Store, routerConfigPath string) *Router {
 		klog.Fatalf("failed to create access logger: %v", err)
 	}
 
-	return &Router{
-		store:            store,
-		scheduler:        scheduler.NewScheduler(store, routerConfig),
-		authenticator:    auth.NewJWTAuthenticator(routerConfig),
-		loadRateLimiter:  loadRateLimiter,
-		accessLogger:     accessLogger,
-		metrics:          metricsInstance,
-		tokenizer:        tokenizerInstance,
-		connectorFactory: connectors.NewDefaultFactory(),
+	feedbackTracker := feedback.NewTracker(newFeedbackStore(routerConfig.Feedback))
+	accessLogger = accesslog.NewMultiAccessLogger(accessLogger, feedbackTracker, sloTracker)
+
+	var eventExporter *events.Exporter
+	if routerConfig.Events.Enabled {
+		eventExporter, err = events.NewExporter(routerConfig.Events)
+		if err != nil {
+			klog.Fatalf("failed to create usage event exporter: %v", err)
+		}
+		accessLogger = accesslog.NewMultiAccessLogger(accessLogger, eventExporter)
+		klog.Infof("usage events are exported to %s topic %q", routerConfig.Events.Backend, routerConfig.Events.Topic)
+	}
+
+	var resumeStore resume.Store
+	if routerConfig.Resume.Enabled {
+		if client := utils.TryGetRedisClient(); client != nil {
+			resumeStore = resume.NewRedisStore(client, time.Duration(routerConfig.Resume.TTLSeconds)*time.Second)
+			klog.Info("stream resumption is enabled")
+		} else {
+			klog.Errorf("stream resumption requires redis, it is disabled")
+		}
+	}
+
+	quotas, err := ratelimit.NewQuotaLimiter(routerConfig.Quotas)
+	if err != nil {
+		klog.Fatalf("failed to create the quotas: %v", err)
+	}
+
+	authenticator, err := auth.NewChain(routerConfig)
+	if err != nil {
+		klog.Fatalf("failed to create the authenticators: %v", err)
+	}
+
+	tokenizers, err := tokenizer.NewModelTokenizers(routerConfig.Tokenizers)
+	if err != nil {
+		klog.Fatalf("failed to create the tokenizers: %v", err)
+	}
+
+	maxAudioFileSizeMB := routerConfig.Audio.MaxFileSizeMB
+	if maxAudioFileSizeMB <= 0 {
+		maxAudioFileSizeMB = defaultMaxAudioFileSizeMB
+	}
+
+	maxVisionPayloadSizeMB := routerConfig.Vision.MaxPayloadSizeMB
+	if maxVisionPayloadSizeMB <= 0 {
+		maxVisionPayloadSizeMB = defaultMaxVisionPayloadSizeMB
+	}
+
+	imageCosts := make(map[string]conf.ImageCostConfig, len(routerConfig.Images.Costs))
+	for _, cost := range routerConfig.Images.Costs {
+		imageCosts[cost.Model] = cost
+	}
+
+	r := &Router{
+		store:                store,
+		scheduler:            scheduler.NewScheduler(store, routerConfig),
+		authenticator:        authenticator,
+		loadRateLimiter:      loadRateLimiter,
+		quotas:               quotas,
+		rateLimitPolicies:    ratelimit.NewPolicyLimiter(),
+		tokenQuotas:          ratelimit.NewTokenQuotaTracker(),
+		rateLimitDegradation: &rateLimitDegradation{},
+		accessLogger:         accessLogger,
+		metrics:              metricsInstance,
+		tokenizers:           tokenizers,
+		connectorFactory:     connectors.NewDefaultFactory(),
+		eventExporter:        eventExporter,
+		feedbackTracker:      feedbackTracker,
+		catalog:              catalog.New(store),
+		sloTracker:           sloTracker,
+		maxAudioFileSize:     int64(maxAudioFileSizeMB) << 20,
+		imageCosts:           imageCosts,
+		imageTokens:          tokenizer.NewImageTokens(routerConfig.Vision.ImageTokens),
+		maxVisionPayloadSize: int64(maxVisionPayloadSizeMB) << 20,
+		scoringBatchSize:     routerConfig.Scoring.MaxBatchSize,
+		inflightRequests:     newInflightRequests(),
+		resumeStore:          resumeStore,
+		semanticCache:        newSemanticCache(routerConfig.SemanticCache),
+		experiments:          routerConfig.Experiments,
+		admission:            newAdmission(),
+		concurrency:          newConcurrencyLimiter(metricsInstance),
+		sessions:             newSessionTable(),
+		prefills:             newPrefillCoalescer(),
+		mirroredRequests:     make(chan struct{}, maxMirroredRequests),
+		evaluatedRequests:    make(chan struct{}, maxEvaluatedRequests),
+		queue:                newRequestQueue(routerConfig.Queue, metricsInstance),
+		queueConfig:          routerConfig.Queue,
+		loadShedder:          newLoadShedder(routerConfig.LoadShedding, metricsInstance),
+		loadSheddingConfig:   routerConfig.LoadShedding,
+		serverTiming:         routerConfig.ServerTiming.Enabled,
+		modelResolution:      newModelResolution(routerConfig.ModelResolution),
+		consumerStreams:      newConsumerStreams(routerConfig.ConsumerStreams, routerConfig.Quotas),
+	}
+	store.RegisterCallback("Pod", r.onPodAdded)
+	store.RegisterCallback("ModelRoute", r.rateLimitDegradation.onModelRoute)
+	store.RegisterCallback("RateLimitPolicy", func(data datastore.EventData) {
+		if data.EventType == datastore.EventDelete {
+			r.rateLimitPolicies.Delete(data.RateLimitPolicy)
+		}
+	})
+	store.RegisterCallback("TokenQuota", func(data datastore.EventData) {
+		if data.EventType == datastore.EventDelete {
+			r.tokenQuotas.Forget(data.TokenQuota)
+		}
+	})
+	return r
+}
+
+// newFeedbackStore creates the store of the result quality feedback of the configuration. The feedback is kept in
+// memory if its store is unknown or if Redis isn't available.
+func newFeedbackStore(config conf.FeedbackConfig) feedback.Store {
+	switch config.Store {
+	case "", "memory":
+	case "redis":
+		if client := utils.TryGetRedisClient(); client != nil {
+			return feedback.NewRedisStore(client, feedback.DefaultRequestTTL)
+		}
+		klog.Errorf("the feedback store requires redis, the feedback is kept in memory")
+	default:
+		klog.Errorf("unknown feedback store %q, the feedback is kept in memory", config.Store)
+	}
+	return feedback.NewMemoryStore(feedback.DefaultMaxRequests, feedback.DefaultRequestTTL)
+}
+
+// FeedbackStats returns the result quality feedback aggregated per model server for the given model,
+// or for all models if it is empty. It is the input of automated experiment promotion decisions.
+func (r *Router) FeedbackStats(ctx context.Context, model string) ([]feedback.VariantStats, error) {
+	return r.feedbackTracker.Stats(ctx, model)
+}
+
+// StartEventExport starts publishing usage events in the background if event export is enabled.
+// Buffered events are flushed when the context is cancelled.
+func (r *Router) StartEventExport(ctx context.Context) {
+	if r.eventExporter != nil {
+		go r.eventExporter.Run(ctx)
+	}
+}
+
+// StartSLOTracking exports the burn rates of the service level objectives declared on ModelRoutes in the background.
+func (r *Router) StartSLOTracking(ctx context.Context) {
+	go r.sloTracker.Run(ctx)
+}
+
+// StartLoadShedding reads the pressure of the router pod in the background if load shedding is enabled. podEvents
+// returns the recorder of the events of the router pod and its reference, it is only called if load shedding is
+// enabled and may return nil if the events are not recorded.
+func (r *Router) StartLoadShedding(ctx context.Context, podEvents func() (record.EventRecorder, *corev1.ObjectReference)) {
+	if r.loadShedder != nil {
+		recorder, pod := podEvents()
+		go r.loadShedder.run(ctx, recorder, pod)
+	}
+}
+
+// ApplyProfile applies the performance envelope of the given profile to the router.
+// It must be called before the router starts serving requests.
+func (r *Router) ApplyProfile(p profile.Profile) {
+	if p.MaxConcurrentRequests > 0 {
+		r.inflight = make(chan struct{}, p.MaxConcurrentRequests)
+	} else {
+		r.inflight = nil
+	}
+	if p.StreamBufferSize > 0 {
+		streamBufferSize = p.StreamBufferSize
+	}
+	r.protection = newProtection(p.MaxBufferedBodyBytes, p.MemoryWatermarkBytes, r.metrics)
+	if enabled, ok := p.Features[profile.FeatureQueue]; ok {
+		r.queueConfig.Enabled = enabled
+		r.queue = newRequestQueue(r.queueConfig, r.metrics)
+	}
+	if enabled, ok := p.Features[profile.FeatureLoadShedding]; ok {
+		r.loadSheddingConfig.Enabled = enabled
+		r.loadShedder = newLoadShedder(r.loadSheddingConfig, r.metrics)
+	}
+	if enabled, ok := p.Features[profile.FeatureServerTiming]; ok {
+		r.serverTiming = enabled
+	}
+	klog.Infof("router profile %q applied: maxConcurrentRequests=%d, streamBufferSize=%d, maxBufferedBodyBytes=%d, memoryWatermarkBytes=%d, queue=%t, loadShedding=%t, serverTiming=%t",
+		p.Name, p.MaxConcurrentRequests, streamBufferSize, p.MaxBufferedBodyBytes, p.MemoryWatermarkBytes,
+		r.queue != nil, r.loadShedder != nil, r.serverTiming)
+}
+
+// tryAcquire reserves a slot for a new request, it returns false if the router is at capacity.
+func (r *Router) tryAcquire() bool {
+	if r.inflight == nil {
+		return true
+	}
+	select {
+	case r.inflight <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// release frees the slot of a completed request, or hands it to the next queued request.
+func (r *Router) release() {
+	if r.inflight == nil {
+		return
+	}
+	if r.queue != nil {
+		r.queue.release(func() { <-r.inflight })
+		return
 	}
+	<-r.inflight
 }
 
 type ModelRequest map[string]interface{}
 
 func (r *Router) HandlerFunc() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if c.Request.URL.Path == feedback.Path {
+			r.feedbackTracker.Handler()(c)
+			return
+		}
+		if catalog.IsPath(c.Request.URL.Path) {
+			r.catalog.Handler(auth.AuthorizeModel)(c)
+			return
+		}
+		if requestID, ok := cancelRequestID(c.Request.URL.Path); ok {
+			r.handleCancel(c, requestID)
+			return
+		}
+		if r.serverTiming {
+			handlers.StartServerTiming(c)
+		}
+
+		if !r.admitUnderPressure(c) {
+			return
+		}
+		if !r.acquire(c) {
+			return
+		}
+		defer r.release()
+		releaseBody, admitted := r.protection.admit(c)
+		if !admitted {
+			return
+		}
+		defer releaseBody()
+
+		r.modelResolution.resolve(c)
+		if isGRPCRequest(c.Request) {
+			r.handleGRPC(c)
+			return
+		}
+		if isAudioPath(c.Request.URL.Path) {
+			r.handleAudio(c)
+			return
+		}
+		if isImagesPath(c.Request.URL.Path) {
+			r.handleImages(c)
+			return
+		}
+		if endpoint, ok := scoringEndpoints[c.Request.URL.Path]; ok {
+			r.handleScoring(c, endpoint)
+			return
+		}
+
 		// Step 1: Parse and validate request
-		modelRequest, err := ParseModelRequest(c)
+		modelRequest, err := r.parseModelRequest(c)
 		if err != nil {
 			accesslog.SetError(c, "request_parsing", err.Error())
 			return
@@ -183,9 +566,13 @@ func (r *Router) HandlerFunc() gin.HandlerFunc {
 
 		// Increment downstream request count at request start
 		r.metrics.IncActiveDownstreamRequests(modelName)
+		start := time.Now()
 		defer func() {
 			// Decrement downstream request count when request completes
 			r.metrics.DecActiveDownstreamRequests(modelName)
+			if isStreaming(modelRequest) {
+				r.recordConsumerStream(c, start)
+			}
 			if metricsRecorder != nil {
 				statusCode := strconv.Itoa(c.Writer.Status())
 				reason := "successful_request"
@@ -196,6 +583,14 @@ func (r *Router) HandlerFunc() gin.HandlerFunc {
 			}
 		}()
 
+		if !authorizeModel(c, modelName) {
+			return
+		}
+		// The batch requests are shed before their prompt is tokenized and the rate limits and quotas are consumed.
+		if !r.admitRouteUnderPressure(c, modelName) {
+			return
+		}
+
 		prompt, err := utils.ParsePrompt(modelRequest)
 		if err != nil {
 			accesslog.SetError(c, "prompt_parsing", "prompt not found")
@@ -204,16 +599,19 @@ func (r *Router) HandlerFunc() gin.HandlerFunc {
 			return
 		}
 		promptStr := utils.GetPromptString(prompt)
-
-		// Calculate input tokens for metrics using tokenizer
-		inputTokens, err := r.tokenizer.CalculateTokenNum(promptStr)
-		if err != nil {
-			klog.Errorf("failed to calculate token number: %v", err)
-			inputTokens = len(promptStr) / 4 // fallback estimation
+		if !r.admitImages(c, prompt.Images) {
+			return
 		}
+		requireCapabilities(c, modelRequest, prompt.Images)
+
+		// Calculate input tokens for metrics and rate limiting using the tokenizer of the model, the images being
+		// counted as the provider of the model does
+		inputTokens := prompt.TokenIDs + r.tokenizers.CalculateTokenNum(modelName, promptStr) +
+			r.imageTokens.CalculateImageTokens(modelName, prompt.Images)
 
 		// Calculate and set input tokens for access log
 		accesslog.SetTokenCounts(c, inputTokens, 0)
+		r.setRequestAttributes(c, inputTokens)
 
 		// Mark end of request processing phase
 		accesslog.MarkRequestProcessingEnd(c)
@@ -221,41 +619,84 @@ func (r *Router) HandlerFunc() gin.HandlerFunc {
 		// Record input tokens immediately
 		metricsRecorder.RecordInputTokens(inputTokens)
 
-		// Apply rate limiting using the unified rate limiter
-		if err := r.loadRateLimiter.RateLimit(modelName, promptStr); err != nil {
-			var errorMsg string
-			var errorType string
-			var tokenType string
-			switch err.(type) {
-			case *ratelimit.InputRateLimitExceededError:
-				errorMsg = "input token rate limit exceeded"
-				errorType = "input_rate_limit"
-				tokenType = metrics.LimitTypeInputTokens
-			case *ratelimit.OutputRateLimitExceededError:
-				errorMsg = "output token rate limit exceeded"
-				errorType = "output_rate_limit"
-				tokenType = metrics.LimitTypeOutputTokens
-			default:
-				errorMsg = "token usage exceeds rate limit"
-				errorType = "rate_limit"
-				tokenType = metrics.LimitTypeRequests
-			}
-			accesslog.SetError(c, errorType, errorMsg)
-
-			// Record rate limit exceeded
-			metricsRecorder.RecordRateLimitExceeded(tokenType)
-			c.AbortWithStatusJSON(http.StatusTooManyRequests, errorMsg)
-			c.Set("finishReason", "rate_limit")
+		// The responses of the requests with images aren't cached by their text
+		if !isStreaming(modelRequest) && len(prompt.Images) == 0 && r.serveCachedResponse(c, modelName, modelRequest, promptStr) {
+			c.Set("finishReason", "cache_hit")
 			return
 		}
 
+		// The requests exceeding the size limits are rejected, they aren't degraded to another model server.
+		if err := r.loadRateLimiter.RequestSizeRateLimit(modelName, requestItems(modelRequest), c.GetInt(requestBodySizeKey)); err != nil {
+			rejectRateLimited(c, metricsRecorder, err)
+			return
+		}
+
+		// Apply rate limiting using the unified rate limiter, the requests exceeding it are rejected unless they are
+		// degraded to another model server.
+		degraded := false
+		if err := r.loadRateLimiter.RateLimitTokens(modelName, inputTokens); err != nil {
+			if !r.degradeRateLimited(c, modelName, err) {
+				rejectRateLimited(c, metricsRecorder, err)
+				return
+			}
+			degraded = true
+		}
+		if !degraded {
+			// The output tokens are reserved on admission, so that concurrent streams can't overrun the output limit.
+			outputReservation, err := r.loadRateLimiter.ReserveOutputTokens(modelName, reservedOutputTokens(modelRequest))
+			if err != nil && !r.degradeRateLimited(c, modelName, err) {
+				rejectRateLimited(c, metricsRecorder, err)
+				return
+			}
+			if outputReservation != nil {
+				c.Set(outputReservationKey, outputReservation)
+				defer releaseOutputReservation(c, outputReservation)
+			}
+		}
+		quotaReservation, ok := r.admitQuota(c, inputTokens, reservedOutputTokens(modelRequest))
+		if !ok {
+			return
+		}
+		if quotaReservation != nil {
+			c.Set(quotaReservationKey, quotaReservation)
+			defer releaseQuotaReservation(c, quotaReservation)
+		}
+		policyReservation, ok := r.admitRateLimitPolicies(c, modelName, inputTokens, reservedOutputTokens(modelRequest))
+		if !ok {
+			return
+		}
+		if policyReservation != nil {
+			c.Set(policyReservationKey, policyReservation)
+			defer releaseQuotaReservation(c, policyReservation)
+		}
+		tokenQuotaUsage, ok := r.admitTokenQuotas(c, modelName, inputTokens)
+		if !ok {
+			return
+		}
+		if tokenQuotaUsage != nil {
+			c.Set(tokenQuotaUsageKey, tokenQuotaUsage)
+			defer releaseTokenQuotaUsage(c, tokenQuotaUsage)
+		}
+		c.Set(common.OutputTokensChargeKey, func(tokens int) {
+			chargeOutputTokens(c, tokens)
+			if r.consumerStreams != nil {
+				c.Set(streamedTokensKey, tokens)
+			}
+		})
+
 		requestID := uuid.New().String()
 		if c.Request.Header.Get("x-request-id") == "" {
 			c.Request.Header.Set("x-request-id", requestID)
 		}
+		defer r.inflightRequests.track(c, c.Request.Header.Get("x-request-id"))()
 
 		// Store metrics recorder in context for use in other functions
 		c.Set("metricsRecorder", metricsRecorder)
+		c.Set("inputTokens", inputTokens)
+
+		if r.resumeStore != nil && isStreaming(modelRequest) && !r.prepareResume(c, modelRequest) {
+			return
+		}
 
 		// step 3.1: load balancing
 		if !EnableFairnessScheduling {
@@ -272,6 +713,83 @@ func (r *Router) HandlerFunc() gin.HandlerFunc {
 	}
 }
 
+// requestItems returns the number of input items of a completions or chat request counted by the item rate limits: the
+// prompts of a completions request batching several of them, one otherwise.
+func requestItems(modelRequest ModelRequest) int {
+	prompts, ok := modelRequest["prompt"].([]interface{})
+	if !ok || len(prompts) == 0 || isTokenIDs(prompts) {
+		return 1
+	}
+	return len(prompts)
+}
+
+// rejectRateLimited rejects a request exceeding the rate limits of its model.
+func rejectRateLimited(c *gin.Context, metricsRecorder *metrics.RequestMetricsRecorder, err error) {
+	var errorMsg string
+	var errorType string
+	var tokenType string
+	switch err.(type) {
+	case *ratelimit.InputRateLimitExceededError:
+		errorMsg = "input token rate limit exceeded"
+		errorType = "input_rate_limit"
+		tokenType = metrics.LimitTypeInputTokens
+	case *ratelimit.OutputRateLimitExceededError:
+		errorMsg = "output token rate limit exceeded"
+		errorType = "output_rate_limit"
+		tokenType = metrics.LimitTypeOutputTokens
+	case *ratelimit.ItemRateLimitExceededError:
+		errorMsg = "item rate limit exceeded"
+		errorType = "item_rate_limit"
+		tokenType = metrics.LimitTypeItems
+	case *ratelimit.ByteRateLimitExceededError:
+		errorMsg = "byte rate limit exceeded"
+		errorType = "byte_rate_limit"
+		tokenType = metrics.LimitTypeBytes
+	default:
+		errorMsg = "token usage exceeds rate limit"
+		errorType = "rate_limit"
+		tokenType = metrics.LimitTypeRequests
+	}
+	accesslog.SetError(c, errorType, errorMsg)
+
+	// Record rate limit exceeded
+	metricsRecorder.RecordRateLimitExceeded(tokenType)
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, errorMsg)
+	c.Set("finishReason", "rate_limit")
+}
+
+// authorizeModel rejects the request if its caller may not call the model, and returns whether it may.
+func authorizeModel(c *gin.Context, modelName string) bool {
+	err := auth.AuthorizeModel(c, modelName)
+	if err == nil {
+		return true
+	}
+	accesslog.SetError(c, "model_access", err.Error())
+	var required *auth.AuthenticationRequiredError
+	if errors.As(err, &required) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, handlers.OpenAIError{
+			Error: handlers.OpenAIErrorDetail{
+				Message: err.Error(),
+				Type:    "authentication_error",
+				Param:   "model",
+				Code:    authenticationRequired,
+			},
+		})
+		c.Set("finishReason", "model_access")
+		return false
+	}
+	c.AbortWithStatusJSON(http.StatusForbidden, handlers.OpenAIError{
+		Error: handlers.OpenAIErrorDetail{
+			Message: err.Error(),
+			Type:    "permission_error",
+			Param:   "model",
+			Code:    modelNotAllowed,
+		},
+	})
+	c.Set("finishReason", "model_access")
+	return false
+}
+
 func (r *Router) doLoadbalance(c *gin.Context, modelRequest ModelRequest) {
 	modelName := modelRequest["model"].(string)
 
@@ -282,23 +800,50 @@ func (r *Router) doLoadbalance(c *gin.Context, modelRequest ModelRequest) {
 	var modelRoute *v1alpha1.ModelRoute
 	var modelServer *v1alpha1.ModelServer
 
-	// Get gateway key from context if available (set by Gateway listener)
-	var gatewayKey string
-	if key, exists := c.Get(GatewayKey); exists {
-		if k, ok := key.(string); ok {
-			gatewayKey = k
-		}
-	}
+	// Get gateway keys from context if available (set by Gateway listener)
+	gatewayKeys := c.GetStringSlice(GatewayKey)
 
 	var isLora bool
 	var err error
+	var rule *v1alpha1.Rule
 	// Try to match ModelRoute first
-	modelServerName, isLora, modelRoute, err = r.store.MatchModelServer(modelName, c.Request, gatewayKey)
+	match, err := r.store.MatchModelRoute(modelName, c.Request, gatewayKeys)
+	if err == nil {
+		modelServerName, isLora, modelRoute, rule = match.ModelServer, match.IsLora, match.ModelRoute, match.Rule
+	}
+	var missingCapabilities *datastore.MissingCapabilitiesError
+	if errors.As(err, &missingCapabilities) {
+		rejectMissingCapabilities(c, missingCapabilities)
+		return
+	}
 	if err != nil {
 		accesslog.SetError(c, "model_server_matching", fmt.Sprintf("can't find corresponding model server: %v", err))
 	}
+	var sess *session
+	if err == nil && modelRoute != nil {
+		if model := resolveAlias(modelRoute, modelName, isLora); model != modelName {
+			modelName = model
+			modelRequest["model"] = model
+		}
+		applyDefaultParameters(modelRequest, modelRoute.Spec.DefaultParameters)
+		defer applyRouteTimeouts(c, modelRoute.Spec.Timeouts)()
+		applyRouteHedging(c, modelRoute.Spec.Hedging)
+		prepareStructuredOutput(c, modelRoute.Spec.StructuredOutput, modelRequest)
+		sess = r.lookupSession(c, modelRequest, modelRoute)
+		if pinned, ok := sess.pinnedModelServer(modelRoute, rule); ok && pinned != modelServerName {
+			if _, _, err := r.getPodsAndServer(pinned); err == nil {
+				modelServerName = pinned
+			}
+		}
+		if degraded, ok := degradedModelServer(c); ok {
+			modelServerName = degraded
+		}
+	}
+	handlers.ApplyHeaderPolicy(c, modelRoute)
+	applyObservability(c, modelRoute)
 
 	if err == nil && strings.HasPrefix(c.Request.URL.Path, "/v1/") {
+		c.Header(modelServerHeader, modelServerName.String())
 		// Regular ModelServer request
 		// step 3: Find pods and model server details
 		klog.V(4).Infof("modelServer is %v, is_lora: %v", modelServerName, isLora)
@@ -310,6 +855,40 @@ func (r *Router) doLoadbalance(c *gin.Context, modelRequest ModelRequest) {
 			c.AbortWithStatusJSON(http.StatusNotFound, fmt.Sprintf("can't find model server: %v", modelServerName))
 			return
 		}
+		var ok bool
+		if pods, ok = servingPods(c, pods, modelServer, isLora); !ok {
+			return
+		}
+
+		if modelServer.Spec.MaxContextLength != nil {
+			if openAIError := checkContextWindow(modelRequest, c.GetInt("inputTokens"), *modelServer.Spec.MaxContextLength); openAIError != nil {
+				accesslog.SetError(c, contextLengthExceeded, openAIError.Error.Message)
+				c.AbortWithStatusJSON(http.StatusBadRequest, openAIError)
+				c.Set("finishReason", contextLengthExceeded)
+				return
+			}
+		}
+
+		capacity := maxConcurrentRequestsPerPod(modelServer) * int32(len(pods))
+		releaseRoute, err := r.concurrency.acquireRoute(c.Request.Context(), modelRoute)
+		if err != nil {
+			r.rejectConcurrencyLimit(c, modelRoute, limitRoute, err)
+			return
+		}
+		defer releaseRoute()
+		if pods, err = r.concurrency.availablePods(c.Request.Context(), modelRoute, modelServer, pods); err != nil {
+			r.rejectConcurrencyLimit(c, modelRoute, limitPod, err)
+			return
+		}
+
+		release, rejection := r.admission.admit(modelServerName, modelRoute.Spec.Priority, capacity)
+		if rejection != "" {
+			rejectAdmission(c, modelServerName, rejection)
+			return
+		}
+		defer release()
+		r.mirrorRequest(c, modelRoute, modelRequest, modelName, isLora)
+		r.prepareEvaluation(c, modelRoute, modelServerName, modelRequest, modelName)
 
 		model := modelServer.Spec.Model
 		if model != nil && !isLora {
@@ -317,7 +896,7 @@ func (r *Router) doLoadbalance(c *gin.Context, modelRequest ModelRequest) {
 		}
 
 		port = modelServer.Spec.WorkloadPort.Port
-	} else if matched, inferencePoolName := r.handleHTTPRoute(c, gatewayKey); matched {
+	} else if matched, inferencePoolName := r.handleHTTPRoute(c, gatewayKeys); matched {
 		// If ModelRoute is not matched, try to match HTTPRoute
 
 		// Get InferencePool from store
@@ -372,18 +951,32 @@ func (r *Router) doLoadbalance(c *gin.Context, modelRequest ModelRequest) {
 		}
 	}
 
-	// Get PDGroup if available (only for ModelServer)
+	// Get PDGroup and load balancing policy if available (only for ModelServer)
 	var pdGroup *v1alpha1.PDGroup
-	if modelServer != nil && modelServer.Spec.WorkloadSelector != nil {
-		pdGroup = modelServer.Spec.WorkloadSelector.PDGroup
+	var loadBalancingPolicy v1alpha1.LoadBalancingPolicy
+	var slowStart *v1alpha1.SlowStart
+	if modelServer != nil {
+		if modelServer.Spec.WorkloadSelector != nil {
+			pdGroup = modelServer.Spec.WorkloadSelector.PDGroup
+		}
+		loadBalancingPolicy = modelServer.Spec.LoadBalancingPolicy
+		slowStart = modelServer.Spec.SlowStart
 	}
 
 	ctx := &framework.Context{
-		Model:           modelName,
-		Prompt:          prompt,
-		ModelServerName: modelServerName,
-		PDGroup:         pdGroup,
-		MetricsRecorder: metricsRecorder,
+		Model:               modelName,
+		Prompt:              prompt,
+		ModelServerName:     modelServerName,
+		PDGroup:             pdGroup,
+		MetricsRecorder:     metricsRecorder,
+		Experiment:          selectExperiment(c, r.experiments),
+		LoadBalancingPolicy: loadBalancingPolicy,
+		SlowStart:           slowStart,
+		SessionID:           c.Request.Header.Get(sessionIDHeader),
+	}
+	if ctx.Experiment != "" {
+		klog.V(4).Infof("request %s is opted in experiment %q", c.Request.Header.Get("x-request-id"), ctx.Experiment)
+		r.metrics.RecordExperimentRequest(modelName, ctx.Experiment)
 	}
 
 	err = r.scheduler.Schedule(ctx, pods)
@@ -392,6 +985,11 @@ func (r *Router) doLoadbalance(c *gin.Context, modelRequest ModelRequest) {
 		c.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprintf("can't schedule to target pod: %v", err))
 		return
 	}
+	sess.pin(ctx, pods)
+	r.saveSession(c, sess)
+	if backend, ok := c.Get(resumeBackendKey); ok {
+		preferBackend(ctx, pods, backend.(string), port)
+	}
 
 	// Set complete request routing information in access log
 	modelServerFullName := fmt.Sprintf("%s/%s", modelServerName.Namespace, modelServerName.Name)
@@ -400,6 +998,9 @@ func (r *Router) doLoadbalance(c *gin.Context, modelRequest ModelRequest) {
 		modelRouteName = fmt.Sprintf("%s/%s", modelRoute.Namespace, modelRoute.Name)
 		// Set the model route name in context for upstream connections
 		c.Set("modelRouteName", modelRouteName)
+		if metricsRecorder != nil {
+			metricsRecorder.SetUpstreamConnectionInfo(modelServerFullName, modelRouteName)
+		}
 	}
 
 	if len(ctx.BestPods) > 0 && ctx.BestPods[0].Pod != nil {
@@ -412,30 +1013,54 @@ func (r *Router) doLoadbalance(c *gin.Context, modelRequest ModelRequest) {
 
 	req := c.Request
 	if err := r.proxyModelEndpoint(c, req, ctx, modelRequest, port); err != nil {
-		klog.Errorf("request failed reqID: %s: %v", c.Request.Header.Get("x-request-id"), err)
-		accesslog.SetError(c, "proxy", "request processing failed")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, "request processing failed")
+		if modelServer != nil && modelRoute != nil && modelRoute.Spec.Fallback != nil {
+			err = r.proxyFallback(c, modelRoute, modelRequest, modelName, isLora, prompt, err)
+		}
+		if err != nil {
+			klog.Errorf("request failed reqID: %s: %v", c.Request.Header.Get("x-request-id"), err)
+			abortProxyFailure(c, err)
+		}
 	}
 }
 
 func ParseModelRequest(c *gin.Context) (ModelRequest, error) {
+	return parseModelRequest(c, nil)
+}
+
+// parseModelRequest parses the request like ParseModelRequest, taking the model resolved from the headers or path of
+// the request by the model resolution of the router when it applies.
+func (r *Router) parseModelRequest(c *gin.Context) (ModelRequest, error) {
+	return parseModelRequest(c, r.modelResolution)
+}
+
+func parseModelRequest(c *gin.Context, resolution *modelResolution) (ModelRequest, error) {
 	bodyBytes, err := io.ReadAll(c.Request.Body)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, err)
 		return nil, err
 	}
+	c.Set(requestBodySizeKey, len(bodyBytes))
 	var modelRequest ModelRequest
 	if err := json.Unmarshal(bodyBytes, &modelRequest); err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, err)
 		return nil, err
 	}
 
+	if model, ok := resolution.model(c, modelRequest); ok {
+		if modelRequest == nil {
+			modelRequest = ModelRequest{}
+		}
+		// The model is set in the body forwarded to the model server too.
+		modelRequest["model"] = model
+	}
 	modelName, ok := modelRequest["model"].(string)
 	if !ok {
 		c.AbortWithStatusJSON(http.StatusNotFound, "model not found")
 		return nil, fmt.Errorf("model not found")
 	}
 	klog.V(4).Infof("model name is %v", modelName)
+	// The rules of the ModelRoutes may match other fields of the body
+	c.Request = c.Request.WithContext(datastore.WithRequestBody(c.Request.Context(), modelRequest))
 
 	return modelRequest, nil
 }
@@ -449,15 +1074,34 @@ func (r *Router) getPodsAndServer(modelServerName types.NamespacedName) ([]*data
 	if modelServer == nil {
 		return nil, nil, fmt.Errorf("can't find model server: %v", modelServerName)
 	}
-	return pods, modelServer, nil
+	return datastore.PodsWarmedUp(pods), modelServer, nil
+}
+
+// routesOfGateways returns the routes attached to the gateways, a route attached to several of them only once.
+func routesOfGateways[T metav1.Object](gatewayKeys []string, routesOf func(gatewayKey string) []T) []T {
+	if len(gatewayKeys) == 1 {
+		return routesOf(gatewayKeys[0])
+	}
+	var routes []T
+	seen := map[types.NamespacedName]bool{}
+	for _, gatewayKey := range gatewayKeys {
+		for _, route := range routesOf(gatewayKey) {
+			name := types.NamespacedName{Namespace: route.GetNamespace(), Name: route.GetName()}
+			if !seen[name] {
+				seen[name] = true
+				routes = append(routes, route)
+			}
+		}
+	}
+	return routes
 }
 
 // handleHTTPRoute handles HTTPRoute matching for non-/v1/ paths
 // Returns true if HTTPRoute was matched and request is being handled, false otherwise
 // Also returns the InferencePool NamespacedName if found
-func (r *Router) handleHTTPRoute(c *gin.Context, gatewayKey string) (bool, types.NamespacedName) {
-	// Find HTTPRoutes for this Gateway
-	httpRoutes := r.store.GetHTTPRoutesByGateway(gatewayKey)
+func (r *Router) handleHTTPRoute(c *gin.Context, gatewayKeys []string) (bool, types.NamespacedName) {
+	// Find HTTPRoutes for these Gateways
+	httpRoutes := routesOfGateways(gatewayKeys, r.store.GetHTTPRoutesByGateway)
 	if len(httpRoutes) == 0 {
 		return false, types.NamespacedName{}
 	}
@@ -535,14 +1179,7 @@ func (r *Router) handleHTTPRoute(c *gin.Context, gatewayKey string) (bool, types
 	for i := range matchedRoute.Spec.Rules {
 		rule := &matchedRoute.Spec.Rules[i]
 		for _, backendRef := range rule.BackendRefs {
-			if backendRef.Group != nil && *backendRef.Group == "inference.networking.k8s.io" &&
-				backendRef.Kind != nil && *backendRef.Kind == "InferencePool" {
-				inferencePoolName.Namespace = matchedRoute.Namespace
-				if backendRef.Namespace != nil {
-					inferencePoolName.Namespace = string(*backendRef.Namespace)
-				}
-				inferencePoolName.Name = string(backendRef.Name)
-				found = true
+			if inferencePoolName, found = gatewayapi.InferencePoolBackend(matchedRoute.Namespace, backendRef.BackendRef); found {
 				matchedRule = rule
 				break
 			}
@@ -636,26 +1273,135 @@ func (r *Router) proxy(
 		}
 	}
 
+	modelServer := r.store.GetModelServer(ctx.ModelServerName)
+	backend, err := r.newUpstream(modelServer, port)
+	if err != nil {
+		return err
+	}
+	onUsage, reportDiscarded := withDiscardedUsage(c, onUsage)
+	defer reportDiscarded()
+	var lastErr error
+	delay := hedgeDelay(c)
+	retries := newRetryBudget(modelServer)
+	attempts := 0
 	for i := 0; i < len(ctx.BestPods); i++ {
+		releasePod, acquired := r.concurrency.tryAcquirePod(modelServer, ctx.BestPods[i])
+		if !acquired {
+			// The pod reached its limit since the request was scheduled.
+			lastErr = errConcurrencyLimit
+			continue
+		}
+
+		if attempts > 0 {
+			// The body may have been sent to a pod the request failed on.
+			rewindBody(req)
+		}
+		attempts++
+
 		// Increment upstream request count with both modelServer and modelRoute
 		r.metrics.IncActiveUpstreamRequests(modelServerName, modelRouteName)
 
-		// Request dispatched to the pod.
-		err := proxyRequest(c, req, ctx.BestPods[i].Pod.Status.PodIP, port, stream, onUsage)
+		// Request dispatched to the pod, and to the next one too if it is hedged.
+		served := i
+		var err error
+		if delay > 0 && i+1 < len(ctx.BestPods) {
+			// The slot of the pod is released as soon as the hedge wins the race.
+			releasePod = sync.OnceFunc(releasePod)
+			served, err = r.proxyHedgedRequest(c, req, ctx, i, backend, stream, delay, releasePod, onUsage)
+		} else {
+			err = proxyRequest(c, req, ctx.BestPods[i], backend, stream, onUsage)
+		}
 
 		// Decrement upstream request count when request completes
 		r.metrics.DecActiveUpstreamRequests(modelServerName, modelRouteName)
+		releasePod()
 
 		if err != nil {
+			if errors.Is(err, handlers.ErrClientGone) || req.Context().Err() != nil {
+				// Retrying on another pod is pointless, nobody will read the response.
+				r.recordCanceledRequest(c, req, ctx.Model, modelServerName)
+				return nil
+			}
+			if isAttemptTimeout(err) {
+				r.metrics.RecordRequestTimeout(ctx.Model, modelServerName, attemptTimeoutLabel(err))
+			}
 			klog.Errorf(" pod request error: %v", err)
+			lastErr = err
+			i = served
+			var invalid *invalidStructuredOutputError
+			if errors.As(err, &invalid) {
+				chargeDiscardedOutput(c, invalid)
+				if !retryStructuredOutput(c) {
+					r.metrics.RecordInvalidStructuredOutput(ctx.Model, modelServerName, structuredOutputExhausted)
+					break
+				}
+				r.metrics.RecordInvalidStructuredOutput(ctx.Model, modelServerName, structuredOutputRetried)
+				// The output is sampled again on the same pod.
+				i--
+				continue
+			}
+			if c.Writer.Written() || i+1 >= len(ctx.BestPods) || !retries.allow(err) {
+				// A response which started can't be replaced by the one of another pod.
+				break
+			}
+			if !retries.wait(req.Context()) {
+				r.recordCanceledRequest(c, req, ctx.Model, modelServerName)
+				return nil
+			}
+			r.metrics.RecordRetriedRequest(ctx.Model, modelServerName)
 			continue
 		}
 		// record in prefix cache
-		r.scheduler.RunPostHooks(ctx, i)
+		r.scheduler.RunPostHooks(ctx, served)
 		return nil
 	}
-	c.AbortWithStatusJSON(http.StatusNotFound, "request to all pods failed")
-	return fmt.Errorf("request to all pods failed")
+	if isAttemptTimeout(lastErr) {
+		return &backendFailedError{status: http.StatusGatewayTimeout, message: "request to all pods timed out", err: lastErr}
+	}
+	if errors.Is(lastErr, errConcurrencyLimit) {
+		return &backendFailedError{status: http.StatusTooManyRequests, message: "all the pods selected are at their concurrency limit", err: lastErr}
+	}
+	var invalid *invalidStructuredOutputError
+	if errors.As(lastErr, &invalid) {
+		return &backendFailedError{status: http.StatusBadGateway, message: invalid.Error(), err: lastErr}
+	}
+	return &backendFailedError{status: http.StatusNotFound, message: "request to all pods failed", err: lastErr}
+}
+
+// recordCanceledRequest records a request whose client disconnected or canceled it, or which exceeded the request
+// timeout of its ModelRoute, before the response completed.
+// The upstream connection has been closed at this point, which aborts the generation on the model server.
+func (r *Router) recordCanceledRequest(c *gin.Context, req *http.Request, model, modelServerName string) {
+	if errors.Is(context.Cause(req.Context()), errRequestTimeout) {
+		klog.V(4).Infof("request timed out, generation of model %s on %s aborted", model, modelServerName)
+		r.metrics.RecordRequestTimeout(model, modelServerName, timeoutRequest)
+		accesslog.SetError(c, requestTimeout, errRequestTimeout.Error())
+		c.Set("finishReason", requestTimeout)
+		if !c.Writer.Written() {
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout, handlers.OpenAIError{
+				Error: handlers.OpenAIErrorDetail{Message: errRequestTimeout.Error(), Type: requestTimeout},
+			})
+		}
+		return
+	}
+	klog.V(4).Infof("request canceled, generation of model %s on %s aborted", model, modelServerName)
+	r.metrics.RecordCanceledGeneration(model, modelServerName)
+	if errors.Is(context.Cause(req.Context()), errRequestCanceled) {
+		// The client is still connected, let it know why the response ends here.
+		accesslog.SetError(c, requestCanceled, errRequestCanceled.Error())
+		c.Set("finishReason", requestCanceled)
+		if !c.Writer.Written() {
+			c.AbortWithStatusJSON(statusClientClosedRequest, handlers.OpenAIError{
+				Error: handlers.OpenAIErrorDetail{Message: errRequestCanceled.Error(), Type: requestCanceled},
+			})
+		}
+		return
+	}
+	accesslog.SetError(c, clientDisconnected, "client disconnected before the response completed")
+	c.Set("finishReason", clientDisconnected)
+	if !c.Writer.Written() {
+		c.Status(statusClientClosedRequest)
+	}
 }
 
 func (r *Router) proxyModelEndpoint(
@@ -667,6 +1413,7 @@ func (r *Router) proxyModelEndpoint(
 ) error {
 	// Mark start of upstream processing
 	accesslog.MarkUpstreamStart(c)
+	handlers.GetServerTiming(c).UpstreamStarted()
 
 	// Get metrics recorder from context
 	var metricsRecorder *metrics.RequestMetricsRecorder
@@ -691,9 +1438,8 @@ func (r *Router) proxyModelEndpoint(
 				return
 			}
 			// Record output tokens for rate limiting
-			if r.loadRateLimiter != nil {
-				r.loadRateLimiter.RecordOutputTokens(modelName, resp.Usage.CompletionTokens)
-			}
+			r.recordOutputTokens(c, modelName, resp.Usage.CompletionTokens)
+			r.chargeRequestCost(c, ctx.ModelServerName, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
 			// Update access log with output tokens
 			if accessCtx := accesslog.GetAccessLogContext(c); accessCtx != nil {
 				accessCtx.SetTokenCounts(accessCtx.InputTokens, resp.Usage.CompletionTokens)
@@ -727,7 +1473,7 @@ func (r *Router) proxyModelEndpoint(
 }
 
 func (r *Router) GetModelServer(modelName string, req *http.Request) (*v1alpha1.ModelServer, error) {
-	modelServerName, isLora, _, err := r.store.MatchModelServer(modelName, req, "")
+	modelServerName, isLora, _, err := r.store.MatchModelServer(modelName, req, nil)
 	if err != nil {
 		return nil, fmt.Errorf("can't find corresponding model server: %v", err)
 	}
@@ -754,34 +1500,69 @@ func (r *Router) AccessLog() gin.HandlerFunc {
 func proxyRequest(
 	c *gin.Context,
 	req *http.Request,
-	podIP string,
-	port int32,
+	pod *datastore.PodInfo,
+	backend *upstream,
 	stream bool,
 	onUsage func(u handlers.OpenAIResponse),
 ) error {
-	resp, err := doRequest(req, podIP, port)
+	attempt, firstToken, done := withAttemptTimeouts(c, req, stream)
+	defer done()
+	start := time.Now()
+	resp, err := doRequest(attempt, pod.Pod.Status.PodIP, backend)
 	if err != nil {
-		return fmt.Errorf("decode request error: %w", err)
+		return fmt.Errorf("decode request error: %w", attemptError(attempt.Context(), err))
 	}
-	for k, vv := range resp.Header {
-		for _, v := range vv {
-			c.Header(k, v)
+	return forwardResponse(c, req, attempt, resp, pod, start, stream, firstToken, onUsage)
+}
+
+// forwardResponse returns the response of the attempt made with req on the pod to downstream.
+func forwardResponse(
+	c *gin.Context,
+	req *http.Request,
+	attempt *http.Request,
+	resp *http.Response,
+	pod *datastore.PodInfo,
+	start time.Time,
+	stream bool,
+	firstToken func(),
+	onUsage func(u handlers.OpenAIResponse),
+) error {
+	if !stream {
+		// The response is validated before anything is sent downstream, so that the request can be sent again.
+		if err := validateStructuredOutput(c, attempt, resp); err != nil {
+			return err
 		}
 	}
+	handlers.CopyResponseHeaders(c, resp.Header)
 	defer resp.Body.Close()
 
 	c.Status(resp.StatusCode)
 
 	if stream {
+		journal := getResumeJournal(c)
+		if journal != nil {
+			if err := journal.Start(c.Writer, attempt.URL.Host); err != nil {
+				return handlers.ErrClientGone
+			}
+		}
 		// If the request is a streaming request, we need to stream the response body.
 		// Stream response: read and forward each event (line) one by one, and parse usage if present
-		c.Status(resp.StatusCode)
-		reader := bufio.NewReader(resp.Body)
-		c.Stream(func(w io.Writer) bool {
-			line, err := reader.ReadBytes('\n')
-			if len(line) > 0 {
+		// The time to first token observed on the pod is used by the leastLatency load balancing policy.
+		// The output tokens are counted from the chunks until the usage frame, for the rate limits.
+		ttftObserved := false
+		tokens := handlers.NewStreamTokenCounter(c)
+		err := handlers.ForwardStream(c, resp.Body, handlers.StreamOptions{
+			BufferSize:               streamBufferSize,
+			HeartbeatAfterFirstEvent: hasFirstTokenTimeout(c),
+			OnLine: func(line []byte) []byte {
+				firstToken()
+				if !ttftObserved {
+					ttftObserved = true
+					pod.RecordTTFT(time.Since(start))
+				}
 				// Try to parse usage from this line, assuming it's a data line
 				parsed := handlers.ParseStreamRespForUsage(string(line))
+				tokens.Observe(parsed)
 				if parsed.Usage.CompletionTokens > 0 {
 					klog.V(4).Infof("Parsed usage: %+v", parsed.Usage)
 
@@ -792,38 +1573,49 @@ func proxyRequest(
 
 					// The token usage is set by router, so remove it before sending to downstream
 					if v, ok := c.Get(common.TokenUsageKey); ok && v.(bool) {
-						return true
+						return nil
 					}
 				}
-				// Forward to downstream
-				_, _ = w.Write(line)
-			}
-			if err != nil {
-				if err != io.EOF {
-					klog.Errorf("error reading stream body: %v", err)
+				if journal != nil {
+					return journal.Record(line)
 				}
-				return false
-			}
-			return true
+				return line
+			},
 		})
-	} else {
-		// Non-stream: efficiently stream response while capturing for parsing
-		var buf bytes.Buffer
-		ttee := io.TeeReader(resp.Body, &buf)
+		if journal != nil {
+			journal.Finish()
+		}
+		// The stream ends without an error when reading the response fails, e.g. because of a first token timeout.
+		err = attemptError(attempt.Context(), err)
+		// A stream completed without a usage frame is settled with the tokens counted from its chunks.
+		if err == nil && !tokens.UsageReported() && tokens.Tokens() > 0 && onUsage != nil {
+			counted := tokens.Tokens()
+			onUsage(handlers.OpenAIResponse{Usage: handlers.Usage{CompletionTokens: counted, TotalTokens: counted}})
+		}
+		return err
+	}
 
-		_, err := io.Copy(c.Writer, ttee)
-		if err != nil {
-			klog.Errorf("copy response to downstream failed: %v", err)
-			return nil
+	// Non-stream: efficiently stream response while capturing for parsing
+	var buf bytes.Buffer
+	ttee := io.TeeReader(resp.Body, &buf)
+
+	if _, err := io.Copy(c.Writer, ttee); err != nil {
+		if req.Context().Err() != nil {
+			return handlers.ErrClientGone
 		}
+		klog.Errorf("copy response to downstream failed: %v", err)
+		return nil
+	}
 
-		// Parse usage if present
-		parsed, _ := handlers.ParseOpenAIResponseBody(buf.Bytes())
-		if parsed != nil && parsed.Usage.CompletionTokens > 0 {
-			klog.V(4).Infof("Parsed usage: %+v", parsed.Usage)
-			if onUsage != nil {
-				onUsage(*parsed)
-			}
+	cacheResponse(c, buf.Bytes())
+	evaluateResponse(c, buf.Bytes())
+
+	// Parse usage if present
+	parsed, _ := handlers.ParseOpenAIResponseBody(buf.Bytes())
+	if parsed != nil && parsed.Usage.CompletionTokens > 0 {
+		klog.V(4).Infof("Parsed usage: %+v", parsed.Usage)
+		if onUsage != nil {
+			onUsage(*parsed)
 		}
 	}
 
@@ -833,19 +1625,20 @@ func proxyRequest(
 func doRequest(
 	req *http.Request,
 	podIP string,
-	port int32,
+	backend *upstream,
 ) (*http.Response, error) {
 	// step 1: change request URL to prefill pod URL.
-	req.URL.Host = fmt.Sprintf("%s:%d", podIP, port)
+	req.URL.Scheme = backend.scheme
+	req.URL.Host = fmt.Sprintf("%s:%d", podIP, backend.port)
 
 	// step 2: use http.Transport to do request to prefill pod.
-	transport := http.DefaultTransport
-	resp, err := transport.RoundTrip(req)
+	resp, err := backend.transport.RoundTrip(req)
 	if err != nil {
 		return nil, err
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("http resp error, http code is %d", resp.StatusCode)
+		resp.Body.Close()
+		return nil, &upstreamStatusError{statusCode: resp.StatusCode}
 	}
 	return resp, nil
 }
@@ -913,12 +1706,24 @@ func (r *Router) proxyToPDDisaggregated(
 		metricsRecorder.SetUpstreamConnectionInfo(modelServerName, modelRouteName)
 	}
 
+	if timing := handlers.GetServerTiming(c); timing != nil {
+		connectors.OnPrefillDone(c, func(error) { timing.PrefillDone() })
+	}
+	// The requests sharing their prompt prefix wait for the prefill of the first one to hit its prefix cache.
+	defer r.coalescePrefill(c, ctx, modelServerName)()
+
 	// Try multiple prefill/decode pairs
 	maxRetry := len(ctx.DecodePods)
 	if len(ctx.PrefillPods) < maxRetry {
 		maxRetry = len(ctx.PrefillPods)
 	}
 
+	// The connectors modify the request body, every attempt starts from a copy of the original one.
+	original := maps.Clone(modelRequest)
+	request := modelRequest
+	var progress decodeProgress
+	attempts := 0
+	var lastErr error
 	for i := 0; i < maxRetry; i++ {
 		if ctx.PrefillPods[i] == nil || ctx.DecodePods[i] == nil {
 			continue
@@ -930,19 +1735,61 @@ func (r *Router) proxyToPDDisaggregated(
 
 		klog.V(4).Infof("Attempting PD disaggregated request: prefill=%s, decode=%s", prefillAddr, decodeAddr)
 
+		if attempts > 0 {
+			// A connector keeps the requests of its first attempt, whose bodies have been consumed.
+			var err error
+			if kvConnector, err = r.getKVConnector(ctx.ModelServerName); err != nil {
+				lastErr = err
+				break
+			}
+			if progress.interruptions > 0 {
+				klog.V(4).Infof("resuming the generation of request %s after %d tokens on prefill pod %s, decode pod %s",
+					c.Request.Header.Get("x-request-id"), progress.tokens, ctx.PrefillPods[i].Pod.Name, ctx.DecodePods[i].Pod.Name)
+				r.metrics.RecordDecodeResumption(ctx.Model, modelServerName)
+			}
+		}
+		attempts++
+
 		// Execute the PD disaggregated proxy operation
-		outputTokens, err := kvConnector.Proxy(c, modelRequest, prefillAddr, decodeAddr)
+		outputTokens, err := kvConnector.Proxy(c, request, prefillAddr, decodeAddr)
 
+		if err != nil && (errors.Is(err, handlers.ErrClientGone) || req.Context().Err() != nil) {
+			r.recordCanceledRequest(c, req, ctx.Model, modelServerName)
+			return nil
+		}
 		if err != nil {
 			klog.Errorf("proxy failed for prefill pod %s, decode pod %s: %v",
 				ctx.PrefillPods[i].Pod.Name, ctx.DecodePods[i].Pod.Name, err)
+			lastErr = err
+			var interrupted *connectors.DecodeInterruptedError
+			if errors.As(err, &interrupted) {
+				// The decode pod broke midway, e.g. it died. The generation is resumed on the next pair from the
+				// completion already forwarded to the client.
+				if !interrupted.Resumable {
+					break
+				}
+				progress.add(interrupted)
+				if interrupted.Finished {
+					finishInterruptedStream(c)
+					return nil
+				}
+			} else if c.Writer.Written() && progress.interruptions == 0 {
+				break
+			}
+			var ok bool
+			if request, ok = resumedRequest(original, progress); !ok {
+				break
+			}
 			continue
 		}
+		outputTokens += progress.tokens
 
 		// Record output tokens for rate limiting
-		if outputTokens > 0 && r.loadRateLimiter != nil {
-			r.loadRateLimiter.RecordOutputTokens(ctx.Model, outputTokens)
+		if outputTokens > 0 {
+			r.recordOutputTokens(c, ctx.Model, outputTokens)
 		}
+		// The connectors don't report the input tokens, the estimate is charged.
+		r.chargeRequestCost(c, ctx.ModelServerName, 0, outputTokens)
 
 		// Record output token metrics
 		if metricsRecorder != nil {
@@ -958,32 +1805,32 @@ func (r *Router) proxyToPDDisaggregated(
 		return nil
 	}
 
-	c.AbortWithStatusJSON(http.StatusInternalServerError, "all prefill/decode attempts failed")
-	return fmt.Errorf("all prefill/decode attempts failed")
+	if c.Writer.Written() {
+		// The response has started, it can only be ended.
+		abortInterruptedStream(c, lastErr)
+		return nil
+	}
+	return &backendFailedError{status: http.StatusInternalServerError, message: "all prefill/decode attempts failed", err: lastErr}
 }
 
 // handleFairnessScheduling handles the fairness scheduling flow for requests
 func (r *Router) handleFairnessScheduling(c *gin.Context, modelRequest ModelRequest, requestID string, modelName string) error {
-	userIdVal, ok := c.Get(common.UserIdKey)
-	if !ok {
-		c.AbortWithStatusJSON(http.StatusBadRequest, "missing userId in request body")
-		return fmt.Errorf("missing userId in request body")
-	}
-	userId, ok := userIdVal.(string)
-	if !ok {
-		c.AbortWithStatusJSON(http.StatusBadRequest, "userId is not a string")
-		return fmt.Errorf("userId is not a string")
+	tenant := requestTenant(c)
+	if tenant == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing tenant of the request, authenticate it or set the "+tenantHeader+" header")
+		return fmt.Errorf("missing tenant of the request")
 	}
 
-	// TODO: better cal priority based on input and output token count
-	pri, _ := r.store.GetTokenCount(userId, modelName)
+	// The tenants sharing the model are served in proportion to their weights, each request costing its tokens.
+	_, _, modelRoute, _ := r.store.MatchModelServer(modelName, c.Request, c.GetStringSlice(GatewayKey))
 	queueReq := &datastore.Request{
 		ReqID:       requestID,
-		UserID:      userId,
+		UserID:      tenant,
 		ModelName:   modelName,
-		Priority:    pri,
 		RequestTime: time.Now(),
 		NotifyChan:  make(chan struct{}),
+		Weight:      tenantWeight(modelRoute, tenant),
+		Cost:        float64(c.GetInt("inputTokens") + reservedOutputTokens(modelRequest)),
 	}
 
 	if err := r.store.Enqueue(queueReq); err != nil {
@@ -993,8 +1840,15 @@ func (r *Router) handleFairnessScheduling(c *gin.Context, modelRequest ModelRequ
 
 	select {
 	case <-queueReq.NotifyChan:
+		handlers.GetServerTiming(c).Queued(time.Since(queueReq.RequestTime))
 		r.doLoadbalance(c, modelRequest)
 		return nil
+	case <-c.Request.Context().Done():
+		// The queue entry is left behind, dequeuing it only closes its notify channel.
+		if !c.Writer.Written() {
+			c.Status(statusClientClosedRequest)
+		}
+		return fmt.Errorf("request canceled while queued: %w", context.Cause(c.Request.Context()))
 	case <-time.After(60 * time.Second):
 		// avoid blocking indefinitely
 		klog.Errorf("request %s processing timed out after 60 seconds", requestID)
@@ -18,6 +18,7 @@ package router
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"flag"
@@ -29,22 +30,30 @@ import (
 	"os"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/agiledragon/gomonkey/v2"
+	"github.com/alicebob/miniredis/v2"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"istio.io/istio/pkg/util/sets"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 
 	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/connectors"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/profile"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/resume"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
@@ -118,7 +127,7 @@ func TestProxyModelEndpoint(t *testing.T) {
 				patches.ApplyFunc(isStreaming, func(modelRequest ModelRequest) bool {
 					return false
 				})
-				patches.ApplyFunc(proxyRequest, func(c *gin.Context, req *http.Request, podIP string, port int32, stream bool) error {
+				patches.ApplyFunc(proxyRequest, func(c *gin.Context, req *http.Request, pod *datastore.PodInfo, backend *upstream, stream bool, onUsage func(u handlers.OpenAIResponse)) error {
 					return nil
 				})
 				return patches
@@ -139,7 +148,7 @@ func TestProxyModelEndpoint(t *testing.T) {
 				patches.ApplyFunc(isStreaming, func(modelRequest ModelRequest) bool {
 					return false
 				})
-				patches.ApplyFunc(proxyRequest, func(c *gin.Context, req *http.Request, podIP string, port int32, stream bool) error {
+				patches.ApplyFunc(proxyRequest, func(c *gin.Context, req *http.Request, pod *datastore.PodInfo, backend *upstream, stream bool, onUsage func(u handlers.OpenAIResponse)) error {
 					return errors.New("proxy error")
 				})
 				return patches
@@ -165,6 +174,287 @@ func TestProxyModelEndpoint(t *testing.T) {
 	}
 }
 
+func TestProxy_ClientDisconnected(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/", nil)
+	r := NewRouter(datastore.New(), "testdata/comfigmap.yaml")
+
+	calls := 0
+	patches := gomonkey.ApplyFunc(proxyRequest, func(c *gin.Context, req *http.Request, pod *datastore.PodInfo, backend *upstream, stream bool, onUsage func(u handlers.OpenAIResponse)) error {
+		calls++
+		return handlers.ErrClientGone
+	})
+	defer patches.Reset()
+
+	ctx := &framework.Context{
+		Model:    "test",
+		BestPods: []*datastore.PodInfo{buildPodInfo("decode1", "1.1.1.1"), buildPodInfo("decode2", "1.1.1.2")},
+	}
+	err := r.proxy(c, c.Request, ctx, true, 8080, nil)
+	assert.NoError(t, err)
+	// The request must not be retried on the next pod
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, statusClientClosedRequest, c.Writer.Status())
+	reason, _ := c.Get("finishReason")
+	assert.Equal(t, clientDisconnected, reason)
+}
+
+func TestCancelRequestID(t *testing.T) {
+	tests := []struct {
+		path      string
+		requestID string
+		ok        bool
+	}{
+		{path: "/v1/requests/req-1/cancel", requestID: "req-1", ok: true},
+		{path: "/v1/requests//cancel"},
+		{path: "/v1/requests/a/b/cancel"},
+		{path: "/v1/requests/req-1"},
+		{path: "/v1/chat/completions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			requestID, ok := cancelRequestID(tt.path)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.requestID, requestID)
+		})
+	}
+}
+
+func TestSelectExperiment(t *testing.T) {
+	experiments := []conf.ExperimentConfig{
+		{Name: "new-scorer", Consumers: []string{"alice"}},
+		{Name: "new-cache", DisableHeader: true, Consumers: []string{"bob"}},
+	}
+	tests := []struct {
+		name        string
+		experiments []conf.ExperimentConfig
+		header      string
+		userID      string
+		expected    string
+	}{
+		{name: "not opted in"},
+		{name: "header", experiments: experiments, header: "new-scorer", expected: "new-scorer"},
+		{name: "unknown experiment", experiments: experiments, header: "unknown"},
+		{name: "header disabled", experiments: experiments, header: "new-cache"},
+		{name: "consumer", experiments: experiments, userID: "bob", expected: "new-cache"},
+		{name: "other consumer", experiments: experiments, userID: "carol"},
+		{name: "no experiments", header: "new-scorer", userID: "alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+			if tt.header != "" {
+				c.Request.Header.Set(experimentHeader, tt.header)
+			}
+			if tt.userID != "" {
+				c.Set(common.UserIdKey, tt.userID)
+			}
+			assert.Equal(t, tt.expected, selectExperiment(c, tt.experiments))
+		})
+	}
+}
+
+func TestInflightRequestsSharedRequestID(t *testing.T) {
+	inflight := newInflightRequests()
+	send := func(caller string) (*gin.Context, func()) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		c.Set(common.UserIdKey, caller)
+		return c, inflight.track(c, "req-1")
+	}
+
+	// The requests of different callers with the same id are tracked apart.
+	alice, doneAlice := send("alice")
+	bob, doneBob := send("bob")
+	defer doneBob()
+	assert.True(t, inflight.cancel("req-1", "bob"))
+	assert.ErrorIs(t, context.Cause(bob.Request.Context()), errRequestCanceled)
+	assert.NoError(t, alice.Request.Context().Err(), "the request of another caller with the same id is not canceled")
+
+	// The completion of a request doesn't untrack the one of another caller with the same id.
+	_, doneMallory := send("mallory")
+	doneMallory()
+	assert.True(t, inflight.cancel("req-1", "alice"))
+	assert.ErrorIs(t, context.Cause(alice.Request.Context()), errRequestCanceled)
+	doneAlice()
+	assert.False(t, inflight.cancel("req-1", "alice"))
+}
+
+func TestRouter_HandlerFunc_CancelRequest(t *testing.T) {
+	started := make(chan struct{})
+	aborted := make(chan struct{})
+	backendHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"id\":\"1\"}\n\n")
+		w.(http.Flusher).Flush()
+		close(started)
+		// Generate until the router closes the connection
+		<-r.Context().Done()
+		close(aborted)
+	})
+	router, store, backend := setupTestRouter(backendHandler)
+	defer backend.Close()
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: "ms-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+			InferenceEngine: "vLLM",
+		},
+	}
+	pod1 := &corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "pod-1", Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}
+	modelRoute := &aiv1alpha1.ModelRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "mr-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "test-model",
+			Rules:     []*aiv1alpha1.Rule{{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "ms-1"}}}},
+		},
+	}
+	store.AddOrUpdateModelServer(modelServer, sets.New(types.NamespacedName{Name: "pod-1", Namespace: "default"}))
+	store.AddOrUpdatePod(pod1, []*aiv1alpha1.ModelServer{modelServer})
+	store.AddOrUpdateModelRoute(modelRoute)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(`{"model": "test-model", "prompt": "hello", "stream": true}`))
+	c.Request.Header.Set("x-request-id", "req-1")
+	c.Set(common.UserIdKey, "alice")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		router.HandlerFunc()(c)
+	}()
+	<-started
+
+	cancel := func(caller string) int {
+		cw := httptest.NewRecorder()
+		cc, _ := gin.CreateTestContext(cw)
+		cc.Request, _ = http.NewRequest(http.MethodPost, "/v1/requests/req-1/cancel", nil)
+		if caller != "" {
+			cc.Set(common.UserIdKey, caller)
+		}
+		router.HandlerFunc()(cc)
+		return cw.Code
+	}
+	// Other callers can't cancel the request
+	assert.Equal(t, http.StatusNotFound, cancel("bob"))
+	assert.Equal(t, http.StatusNotFound, cancel(""))
+	assert.Equal(t, http.StatusOK, cancel("alice"))
+
+	select {
+	case <-aborted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("backend generation was not aborted")
+	}
+	<-done
+	reason, _ := c.Get("finishReason")
+	assert.Equal(t, requestCanceled, reason)
+
+	// The request is not in-flight anymore
+	assert.Equal(t, http.StatusNotFound, cancel("alice"))
+}
+
+func TestRouter_HandlerFunc_ResumeStream(t *testing.T) {
+	backendHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody ModelRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&reqBody))
+		messages := reqBody["messages"].([]interface{})
+		if len(messages) == 1 {
+			// A new generation
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hi\"}}]}\n\ndata: [DONE]\n\n")
+			return
+		}
+		// The generation continues from the output already streamed
+		assert.Len(t, messages, 2)
+		assert.Equal(t, map[string]interface{}{"role": "assistant", "content": "Hello"}, messages[1])
+		assert.Equal(t, true, reqBody["continue_final_message"])
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"!\"}}]}\n\ndata: [DONE]\n\n")
+	})
+	router, store, backend := setupTestRouter(backendHandler)
+	defer backend.Close()
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	resumeStore := resume.NewRedisStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}), time.Minute)
+	router.resumeStore = resumeStore
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: "ms-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+			InferenceEngine: "vLLM",
+		},
+	}
+	pod1 := &corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "pod-1", Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}
+	modelRoute := &aiv1alpha1.ModelRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "mr-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "test-model",
+			Rules:     []*aiv1alpha1.Rule{{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "ms-1"}}}},
+		},
+	}
+	store.AddOrUpdateModelServer(modelServer, sets.New(types.NamespacedName{Name: "pod-1", Namespace: "default"}))
+	store.AddOrUpdatePod(pod1, []*aiv1alpha1.ModelServer{modelServer})
+	store.AddOrUpdateModelRoute(modelRoute)
+
+	// A stream cut by a router restart after two events, the client received the first one only
+	ctx := context.Background()
+	require.NoError(t, resumeStore.SetBackend(ctx, "alice", "req-0", backendURL.Host))
+	_, err = resumeStore.Append(ctx, "alice", "req-0", []byte(`data: {"choices":[{"delta":{"content":"Hel"}}]}`))
+	require.NoError(t, err)
+	_, err = resumeStore.Append(ctx, "alice", "req-0", []byte(`data: {"choices":[{"delta":{"content":"lo"}}]}`))
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(`{"model": "test-model", "messages": [{"role": "user", "content": "hi"}], "stream": true}`))
+	c.Request.Header.Set(resume.LastEventIDHeader, resume.EventID("req-0", 1))
+	c.Set(common.UserIdKey, "alice")
+	router.HandlerFunc()(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "id: req-0/2\ndata: {\"choices\":[{\"delta\":{\"content\":\"lo\"}}]}\n\n"+
+		"id: req-0/3\ndata: {\"choices\":[{\"delta\":{\"content\":\"!\"}}]}\n\ndata: [DONE]\n\n", w.Body.String())
+	state, err := resumeStore.Get(ctx, "alice", "req-0")
+	require.NoError(t, err)
+	assert.True(t, state.Done)
+
+	// Resuming a completed stream replays the missed events without generating
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(`{"model": "test-model", "messages": [{"role": "user", "content": "hi"}], "stream": true}`))
+	c.Request.Header.Set(resume.LastEventIDHeader, resume.EventID("req-0", 2))
+	c.Set(common.UserIdKey, "alice")
+	router.HandlerFunc()(c)
+	assert.Equal(t, "id: req-0/3\ndata: {\"choices\":[{\"delta\":{\"content\":\"!\"}}]}\n\ndata: [DONE]\n\n", w.Body.String())
+
+	// Another caller can't replay the stream, its request is served as a new one
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(`{"model": "test-model", "messages": [{"role": "user", "content": "hi"}], "stream": true}`))
+	c.Request.Header.Set(resume.LastEventIDHeader, resume.EventID("req-0", 2))
+	c.Set(common.UserIdKey, "bob")
+	router.HandlerFunc()(c)
+	assert.NotContains(t, w.Body.String(), "req-0/")
+	assert.Contains(t, w.Body.String(), `{"content":"Hi"}`)
+}
+
 // setupTestRouter initializes a router and its dependencies for testing.
 func setupTestRouter(backendHandler http.Handler) (*Router, datastore.Store, *httptest.Server) {
 	gin.SetMode(gin.TestMode)
@@ -176,6 +466,110 @@ func setupTestRouter(backendHandler http.Handler) (*Router, datastore.Store, *ht
 	return router, store, backend
 }
 
+func TestRouter_HandlerFunc_WeightedTargets(t *testing.T) {
+	router, store, backend := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"response-id"}`)
+	}))
+	defer backend.Close()
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	var modelServers []*aiv1alpha1.ModelServer
+	for _, name := range []string{"stable", "canary"} {
+		modelServer := &aiv1alpha1.ModelServer{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: aiv1alpha1.ModelServerSpec{
+				WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+				InferenceEngine: "vLLM",
+			},
+		}
+		store.AddOrUpdateModelServer(modelServer, sets.New(types.NamespacedName{Name: "pod-1", Namespace: "default"}))
+		modelServers = append(modelServers, modelServer)
+	}
+	store.AddOrUpdatePod(&corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "pod-1", Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}, modelServers)
+	store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "mr-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "test-model",
+			Rules: []*aiv1alpha1.Rule{
+				{
+					TargetModels: []*aiv1alpha1.TargetModel{
+						{ModelServerName: "stable", Weight: ptr.To(uint32(0))},
+						{ModelServerName: "canary", Weight: ptr.To(uint32(100))},
+					},
+				},
+			},
+		},
+	})
+
+	for i := 0; i < 10; i++ {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(`{"model": "test-model", "prompt": "hello"}`))
+		router.HandlerFunc()(c)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "default/canary", w.Header().Get(modelServerHeader))
+	}
+}
+
+func TestRouter_HandlerFunc_BodyFieldMatch(t *testing.T) {
+	router, store, backend := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"response-id"}`)
+	}))
+	defer backend.Close()
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	var modelServers []*aiv1alpha1.ModelServer
+	for _, name := range []string{"default", "tools"} {
+		modelServer := &aiv1alpha1.ModelServer{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: aiv1alpha1.ModelServerSpec{
+				WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+				InferenceEngine: "vLLM",
+			},
+		}
+		store.AddOrUpdateModelServer(modelServer, sets.New(types.NamespacedName{Name: "pod-1", Namespace: "default"}))
+		modelServers = append(modelServers, modelServer)
+	}
+	store.AddOrUpdatePod(&corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "pod-1", Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}, modelServers)
+	store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "mr-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "test-model",
+			Rules: []*aiv1alpha1.Rule{
+				{
+					ModelMatch: &aiv1alpha1.ModelMatch{Body: &aiv1alpha1.BodyMatch{
+						Fields: []aiv1alpha1.BodyFieldMatch{{Path: "tools"}},
+					}},
+					TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "tools"}},
+				},
+				{
+					TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "default"}},
+				},
+			},
+		},
+	})
+
+	for body, want := range map[string]string{
+		`{"model": "test-model", "messages": [], "tools": [{"type": "function"}]}`: "default/tools",
+		`{"model": "test-model", "messages": []}`:                                  "default/default",
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(body))
+		router.HandlerFunc()(c)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, want, w.Header().Get(modelServerHeader))
+	}
+}
+
 func TestRouter_HandlerFunc_AggregatedMode(t *testing.T) {
 	// 1. Setup backend mock
 	backendHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -240,6 +634,281 @@ func TestRouter_HandlerFunc_AggregatedMode(t *testing.T) {
 	// 5. Assertions
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Contains(t, w.Body.String(), `"id":"response-id"`)
+	assert.Equal(t, "default/ms-1", w.Header().Get(modelServerHeader))
+}
+
+func TestRouter_HandlerFunc_HeaderPolicy(t *testing.T) {
+	var upstreamHeader http.Header
+	backendHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamHeader = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Backend-Version", "0.9.1")
+		w.Header().Set("X-Request-Cost", "3")
+		fmt.Fprint(w, `{"id":"response-id"}`)
+	})
+	router, store, backend := setupTestRouter(backendHandler)
+	defer backend.Close()
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: "ms-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+			InferenceEngine: "vLLM",
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "pod-1", Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}
+	modelRoute := &aiv1alpha1.ModelRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "mr-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "test-model",
+			Rules: []*aiv1alpha1.Rule{
+				{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "ms-1"}}},
+			},
+		},
+	}
+	store.AddOrUpdateModelServer(modelServer, sets.New(types.NamespacedName{Name: "pod-1", Namespace: "default"}))
+	store.AddOrUpdatePod(pod, []*aiv1alpha1.ModelServer{modelServer})
+	store.AddOrUpdateModelRoute(modelRoute)
+
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", bytes.NewBufferString(`{"model":"test-model","prompt":"hello"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.Header.Set("Authorization", "Bearer secret")
+		c.Request.Header.Set("X-Tenant-Id", "tenant-a")
+		c.Request.Header.Set("X-Debug", "1")
+		router.HandlerFunc()(c)
+		return w
+	}
+
+	// Without a policy, only the Authorization header is removed.
+	w := serve()
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, upstreamHeader.Get("Authorization"))
+	assert.Equal(t, "tenant-a", upstreamHeader.Get("X-Tenant-Id"))
+	assert.Equal(t, "1", upstreamHeader.Get("X-Debug"))
+	assert.Equal(t, "0.9.1", w.Header().Get("X-Backend-Version"))
+
+	modelRoute = modelRoute.DeepCopy()
+	modelRoute.Spec.HeaderPolicy = &aiv1alpha1.HeaderPolicy{
+		Request:  &aiv1alpha1.HeaderFilter{Allow: []string{"authorization", "X-Tenant-*"}},
+		Response: &aiv1alpha1.HeaderFilter{Deny: []string{"X-Backend-Version"}},
+	}
+	store.AddOrUpdateModelRoute(modelRoute)
+
+	w = serve()
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Bearer secret", upstreamHeader.Get("Authorization"))
+	assert.Equal(t, "tenant-a", upstreamHeader.Get("X-Tenant-Id"))
+	assert.Empty(t, upstreamHeader.Get("X-Debug"))
+	assert.Equal(t, "application/json", upstreamHeader.Get("Content-Type"))
+	assert.Empty(t, w.Header().Get("X-Backend-Version"))
+	assert.Equal(t, "3", w.Header().Get("X-Request-Cost"))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+}
+
+func TestRouter_HandlerFunc_ContextLengthExceeded(t *testing.T) {
+	backendCalled := false
+	backendHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		backendCalled = true
+		w.WriteHeader(http.StatusOK)
+	})
+	router, store, backend := setupTestRouter(backendHandler)
+	defer backend.Close()
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	maxContextLength := int32(16)
+
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: "ms-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			WorkloadPort:     aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+			InferenceEngine:  "vLLM",
+			MaxContextLength: &maxContextLength,
+		},
+	}
+	pod1 := &corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "pod-1", Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}
+	modelRoute := &aiv1alpha1.ModelRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "mr-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "test-model",
+			Rules: []*aiv1alpha1.Rule{
+				{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "ms-1"}}},
+			},
+		},
+	}
+	store.AddOrUpdateModelServer(modelServer, sets.New(types.NamespacedName{Name: "pod-1", Namespace: "default"}))
+	store.AddOrUpdatePod(pod1, []*aiv1alpha1.ModelServer{modelServer})
+	store.AddOrUpdateModelRoute(modelRoute)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	// "hello" is estimated to 2 tokens, 2 + 20 exceeds the context window of 16 tokens
+	reqBody := `{"model": "test-model", "prompt": "hello", "max_tokens": 20}`
+	c.Request, _ = http.NewRequest("POST", "/v1/completions", bytes.NewBufferString(reqBody))
+	router.HandlerFunc()(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.False(t, backendCalled)
+	var resp handlers.OpenAIError
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "context_length_exceeded", resp.Error.Code)
+	assert.Equal(t, "invalid_request_error", resp.Error.Type)
+	assert.Equal(t, "prompt", resp.Error.Param)
+	assert.Contains(t, resp.Error.Message, "maximum context length is 16 tokens")
+}
+
+func TestRouter_HandlerFunc_LegacyCompletionPrompts(t *testing.T) {
+	var forwarded []string
+	router, store, backend := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = append(forwarded, string(readBody(r)))
+		fmt.Fprint(w, `{"id":"response-id","object":"text_completion"}`)
+	}))
+	defer backend.Close()
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	maxContextLength := int32(16)
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: "ms-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			WorkloadPort:     aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+			InferenceEngine:  "vLLM",
+			MaxContextLength: &maxContextLength,
+		},
+	}
+	store.AddOrUpdateModelServer(modelServer, sets.New(types.NamespacedName{Name: "pod-1", Namespace: "default"}))
+	store.AddOrUpdatePod(&corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "pod-1", Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}, []*aiv1alpha1.ModelServer{modelServer})
+	store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "mr-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "test-model",
+			Rules:     []*aiv1alpha1.Rule{{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "ms-1"}}}},
+		},
+	})
+
+	tests := []struct {
+		name     string
+		prompt   string
+		wantCode int
+	}{
+		{name: "string", prompt: `"hello"`, wantCode: http.StatusOK},
+		{name: "array of strings", prompt: `["hello", "world"]`, wantCode: http.StatusOK},
+		{name: "token ids", prompt: `[1, 2, 3]`, wantCode: http.StatusOK},
+		{name: "arrays of token ids", prompt: `[[1, 2, 3], [4, 5]]`, wantCode: http.StatusOK},
+		// The token ids are counted against the context window of 16 tokens.
+		{name: "too many token ids", prompt: `[1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17]`, wantCode: http.StatusBadRequest},
+		{name: "invalid prompt", prompt: `[{"text": "hello"}]`, wantCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forwarded = nil
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			body := fmt.Sprintf(`{"model": "test-model", "prompt": %s}`, tt.prompt)
+			c.Request, _ = http.NewRequest("POST", "/v1/completions", bytes.NewBufferString(body))
+			router.HandlerFunc()(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantCode != http.StatusOK {
+				assert.Empty(t, forwarded)
+				return
+			}
+			require.Len(t, forwarded, 1)
+			var request map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(forwarded[0]), &request))
+			var prompt interface{}
+			require.NoError(t, json.Unmarshal([]byte(tt.prompt), &prompt))
+			assert.Equal(t, prompt, request["prompt"])
+		})
+	}
+}
+
+func TestCheckContextWindow(t *testing.T) {
+	tests := []struct {
+		name         string
+		request      ModelRequest
+		promptTokens int
+		wantParam    string
+		wantExceeded bool
+	}{
+		{name: "fits", request: ModelRequest{"prompt": "hi", "max_tokens": float64(10)}, promptTokens: 6},
+		{name: "no max tokens", request: ModelRequest{"prompt": "hi"}, promptTokens: 16},
+		{name: "prompt too long", request: ModelRequest{"prompt": "hi"}, promptTokens: 17, wantParam: "prompt", wantExceeded: true},
+		{name: "completion too long", request: ModelRequest{"messages": []any{}, "max_tokens": float64(11)}, promptTokens: 6, wantParam: "messages", wantExceeded: true},
+		{name: "max completion tokens", request: ModelRequest{"messages": []any{}, "max_completion_tokens": float64(11)}, promptTokens: 6, wantParam: "messages", wantExceeded: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openAIError := checkContextWindow(tt.request, tt.promptTokens, 16)
+			if !tt.wantExceeded {
+				assert.Nil(t, openAIError)
+				return
+			}
+			if assert.NotNil(t, openAIError) {
+				assert.Equal(t, tt.wantParam, openAIError.Error.Param)
+				assert.Equal(t, contextLengthExceeded, openAIError.Error.Code)
+			}
+		})
+	}
+}
+
+func TestApplyDefaultParameters(t *testing.T) {
+	maxTokens := int32(512)
+	temperature := "0.7"
+	defaults := &aiv1alpha1.DefaultParameters{MaxTokens: &maxTokens, Temperature: &temperature}
+
+	tests := []struct {
+		name     string
+		request  ModelRequest
+		defaults *aiv1alpha1.DefaultParameters
+		expected ModelRequest
+	}{
+		{
+			name:     "no defaults",
+			request:  ModelRequest{"model": "m"},
+			expected: ModelRequest{"model": "m"},
+		},
+		{
+			name:     "parameters omitted",
+			request:  ModelRequest{"model": "m"},
+			defaults: defaults,
+			expected: ModelRequest{"model": "m", "max_tokens": float64(512), "temperature": 0.7},
+		},
+		{
+			name:     "parameters set by client",
+			request:  ModelRequest{"model": "m", "max_tokens": float64(10), "temperature": float64(0)},
+			defaults: defaults,
+			expected: ModelRequest{"model": "m", "max_tokens": float64(10), "temperature": float64(0)},
+		},
+		{
+			name:     "max completion tokens set by client",
+			request:  ModelRequest{"model": "m", "max_completion_tokens": float64(10)},
+			defaults: defaults,
+			expected: ModelRequest{"model": "m", "max_completion_tokens": float64(10), "temperature": 0.7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applyDefaultParameters(tt.request, tt.defaults)
+			assert.Equal(t, tt.expected, tt.request)
+		})
+	}
 }
 
 func TestRouter_HandlerFunc_DisaggregatedMode(t *testing.T) {
@@ -426,6 +1095,93 @@ func TestRouter_HandlerFunc_ScheduleFailure(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "can't schedule to target pod")
 }
 
+func TestRouter_HandlerFunc_ConcurrencyLimit(t *testing.T) {
+	router, _, backend := setupTestRouter(nil)
+	defer backend.Close()
+
+	router.ApplyProfile(profile.Profile{Name: "test", MaxConcurrentRequests: 1, StreamBufferSize: profile.DefaultStreamBufferSize})
+	defer router.ApplyProfile(profile.Profile{Name: profile.Custom, StreamBufferSize: profile.DefaultStreamBufferSize})
+
+	// Occupy the only slot
+	assert.True(t, router.tryAcquire())
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	reqBody := `{"model": "test-model", "prompt": "hello"}`
+	c.Request, _ = http.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(reqBody))
+	router.HandlerFunc()(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "too many concurrent requests")
+
+	// Once the slot is released, requests are handled again
+	router.release()
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(reqBody))
+	router.HandlerFunc()(c)
+	assert.NotEqual(t, http.StatusServiceUnavailable, w.Code)
+	assert.True(t, router.tryAcquire(), "slot should be released after the request completes")
+	router.release()
+}
+
+func TestRouter_HandlerFunc_ServerTiming(t *testing.T) {
+	router, store, backend := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"response-id","usage":{"prompt_tokens":1,"completion_tokens":5,"total_tokens":6}}`)
+	}))
+	defer backend.Close()
+	registerTestModel(store, backend)
+
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/v1/completions", bytes.NewBufferString(`{"model": "test-model", "prompt": "hello"}`))
+		router.HandlerFunc()(c)
+		return w
+	}
+
+	w := send()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Server-Timing"))
+
+	router.serverTiming = true
+	w = send()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Regexp(t, `^routing;dur=\d+\.\d, queue;dur=0\.0, decode;dur=\d+\.\d$`, w.Header().Get("Server-Timing"))
+}
+
+func TestRouter_ApplyProfile_Features(t *testing.T) {
+	router, _, backend := setupTestRouter(nil)
+	defer backend.Close()
+	defer router.ApplyProfile(profile.Profile{Name: profile.Custom, StreamBufferSize: profile.DefaultStreamBufferSize})
+	router.queueConfig.MaxDepth = 8
+
+	// The features the profile doesn't set are left to the router configuration
+	router.ApplyProfile(profile.Profile{Name: profile.Custom, StreamBufferSize: profile.DefaultStreamBufferSize})
+	assert.Nil(t, router.queue)
+	assert.Nil(t, router.loadShedder)
+	assert.False(t, router.serverTiming)
+
+	large, err := profile.Get(profile.Large)
+	require.NoError(t, err)
+	require.NoError(t, large.SetFeatures(map[string]string{profile.FeatureServerTiming: "true"}))
+	router.ApplyProfile(large)
+	require.NotNil(t, router.queue)
+	assert.Equal(t, 8, router.queue.maxDepth, "the other settings of a feature are read from the router configuration")
+	assert.NotNil(t, router.loadShedder)
+	assert.True(t, router.serverTiming)
+
+	router.ApplyProfile(profile.Profile{
+		Name:             profile.Custom,
+		StreamBufferSize: profile.DefaultStreamBufferSize,
+		Features:         map[string]bool{profile.FeatureQueue: false, profile.FeatureLoadShedding: false, profile.FeatureServerTiming: false},
+	})
+	assert.Nil(t, router.queue)
+	assert.Nil(t, router.loadShedder)
+	assert.False(t, router.serverTiming)
+}
+
 func TestAccessLogConfigurationFromEnv(t *testing.T) {
 	// Save original environment variables
 	originalEnabled := os.Getenv("ACCESS_LOG_ENABLED")
@@ -573,3 +1329,22 @@ func parseBool(str string) (bool, error) {
 	}
 	return false, &strconv.NumError{Func: "ParseBool", Num: str, Err: strconv.ErrSyntax}
 }
+
+func TestRequestItems(t *testing.T) {
+	tests := []struct {
+		name    string
+		request ModelRequest
+		want    int
+	}{
+		{name: "chat", request: ModelRequest{"messages": []interface{}{map[string]interface{}{"role": "user", "content": "hi"}}}, want: 1},
+		{name: "single prompt", request: ModelRequest{"prompt": "hi"}, want: 1},
+		{name: "prompt token ids", request: ModelRequest{"prompt": []interface{}{1.0, 2.0, 3.0}}, want: 1},
+		{name: "batched prompts", request: ModelRequest{"prompt": []interface{}{"a", "b", "c"}}, want: 3},
+		{name: "batched token ids", request: ModelRequest{"prompt": []interface{}{[]interface{}{1.0}, []interface{}{2.0}}}, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, requestItems(tt.request))
+		})
+	}
+}
@@ -0,0 +1,437 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+)
+
+const (
+	rerankPath     = "/v1/rerank"
+	classifyPath   = "/v1/classify"
+	embeddingsPath = "/v1/embeddings"
+)
+
+// scoringEndpoint describes an endpoint scoring a list of inputs, e.g. the documents of a rerank request or the
+// texts of an embeddings request, whose requests can be split into batches of inputs and their responses merged.
+type scoringEndpoint struct {
+	// inputsField is the request field of the inputs.
+	inputsField string
+	// resultsField is the response field of the results, each carrying the index of its input.
+	resultsField string
+	// ranked reports whether the results are sorted by decreasing relevance_score and limited to top_n.
+	ranked bool
+	// tokenized reports whether the inputs may be given as arrays of token ids rather than texts.
+	tokenized bool
+}
+
+var scoringEndpoints = map[string]scoringEndpoint{
+	// Cohere-compatible rerank API, as served by vLLM, TEI or Infinity.
+	rerankPath:   {inputsField: "documents", resultsField: "results", ranked: true},
+	classifyPath: {inputsField: "input", resultsField: "data"},
+	// OpenAI embeddings API, whose input is a text, an array of texts, an array of token ids or an array of them.
+	embeddingsPath: {inputsField: "input", resultsField: "data", tokenized: true},
+}
+
+// scoringResponse is a response of a model server to a batch of inputs.
+type scoringResponse struct {
+	header http.Header
+	body   []byte
+	pod    int
+}
+
+// handleScoring routes a rerank, classification or embeddings request to the ModelServer of its model. The inputs of
+// large requests are split into batches scored concurrently by the selected pods, and the results merged in one
+// response. The requests are metered by their input tokens and by the number of inputs scored.
+func (r *Router) handleScoring(c *gin.Context, endpoint scoringEndpoint) {
+	modelRequest, err := r.parseModelRequest(c)
+	if err != nil {
+		accesslog.SetError(c, "request_parsing", err.Error())
+		return
+	}
+	inputs, text, tokenIDs, err := parseScoringRequest(modelRequest, endpoint)
+	if err != nil {
+		accesslog.SetError(c, "request_parsing", err.Error())
+		c.AbortWithStatusJSON(http.StatusBadRequest, handlers.NewInvalidRequestError(err.Error(), endpoint.inputsField, ""))
+		return
+	}
+
+	modelName := modelRequest["model"].(string)
+	accesslog.SetModelName(c, modelName)
+	c.Set("model", modelName)
+	path := c.Request.URL.Path
+	metricsRecorder := metrics.NewRequestMetricsRecorder(r.metrics, modelName, path)
+	r.metrics.IncActiveDownstreamRequests(modelName)
+	defer func() {
+		r.metrics.DecActiveDownstreamRequests(modelName)
+		reason := "successful_request"
+		if finishReason := c.GetString("finishReason"); finishReason != "" {
+			reason = finishReason
+		}
+		metricsRecorder.Finish(strconv.Itoa(c.Writer.Status()), reason)
+	}()
+	if !authorizeModel(c, modelName) {
+		return
+	}
+
+	inputTokens := tokenIDs
+	if text != "" {
+		inputTokens += r.tokenizers.CalculateTokenNum(modelName, text)
+	}
+	accesslog.SetTokenCounts(c, inputTokens, 0)
+	r.setRequestAttributes(c, inputTokens)
+	accesslog.MarkRequestProcessingEnd(c)
+
+	if err := r.loadRateLimiter.RequestSizeRateLimit(modelName, len(inputs), c.GetInt(requestBodySizeKey)); err != nil {
+		rejectRateLimited(c, metricsRecorder, err)
+		return
+	}
+	if err := r.loadRateLimiter.RateLimitTokens(modelName, inputTokens); err != nil {
+		rejectRateLimited(c, metricsRecorder, err)
+		return
+	}
+
+	modelServerName, isLora, modelRoute, err := r.store.MatchModelServer(modelName, c.Request, c.GetStringSlice(GatewayKey))
+	if err != nil {
+		accesslog.SetError(c, "model_server_matching", fmt.Sprintf("can't find corresponding model server: %v", err))
+		c.AbortWithStatusJSON(http.StatusNotFound, "route not found")
+		return
+	}
+	handlers.ApplyHeaderPolicy(c, modelRoute)
+	applyObservability(c, modelRoute)
+	c.Header(modelServerHeader, modelServerName.String())
+	pods, modelServer, err := r.getPodsAndServer(modelServerName)
+	if err != nil {
+		accesslog.SetError(c, "pod_discovery", fmt.Sprintf("can't find model server: %v", modelServerName))
+		c.AbortWithStatusJSON(http.StatusNotFound, fmt.Sprintf("can't find model server: %v", modelServerName))
+		return
+	}
+	var ok bool
+	if pods, ok = servingPods(c, pods, modelServer, isLora); !ok {
+		return
+	}
+	backend, err := r.newUpstream(modelServer, modelServer.Spec.WorkloadPort.Port)
+	if err != nil {
+		accesslog.SetError(c, "upstream", err.Error())
+		c.AbortWithStatusJSON(http.StatusBadGateway, err.Error())
+		return
+	}
+	modelRequest["model"] = resolveAlias(modelRoute, modelName, isLora)
+	if model := modelServer.Spec.Model; model != nil && !isLora {
+		modelRequest["model"] = *model
+	}
+
+	ctx := &framework.Context{
+		Model:           modelName,
+		ModelServerName: modelServerName,
+		MetricsRecorder: metricsRecorder,
+		Experiment:      selectExperiment(c, r.experiments),
+	}
+	if err := r.scheduler.Schedule(ctx, pods); err != nil || len(ctx.BestPods) == 0 {
+		accesslog.SetError(c, "scheduling", fmt.Sprintf("can't schedule to target pod: %v", err))
+		c.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprintf("can't schedule to target pod: %v", err))
+		return
+	}
+
+	modelServerFullName := modelServerName.String()
+	modelRouteName := ""
+	if modelRoute != nil {
+		modelRouteName = fmt.Sprintf("%s/%s", modelRoute.Namespace, modelRoute.Name)
+	}
+	accesslog.SetRequestRouting(c, modelRouteName, modelServerFullName, ctx.BestPods[0].Pod.Name)
+
+	batches := splitBatches(inputs, r.scoringBatchSize)
+	responses := make([]*scoringResponse, len(batches))
+	errs := make([]error, len(batches))
+	accesslog.MarkUpstreamStart(c)
+	handlers.GetServerTiming(c).UpstreamStarted()
+	var wg sync.WaitGroup
+	for i, batch := range batches {
+		request := make(ModelRequest, len(modelRequest))
+		for k, v := range modelRequest {
+			request[k] = v
+		}
+		if len(batches) > 1 {
+			request[endpoint.inputsField] = batch
+		}
+		body, err := json.Marshal(request)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.metrics.IncActiveUpstreamRequests(modelServerFullName, modelRouteName)
+			defer r.metrics.DecActiveUpstreamRequests(modelServerFullName, modelRouteName)
+			// Spread the batches over the selected pods, falling back to the next ones.
+			responses[i], errs[i] = sendScoringBatch(c.Request, body, ctx.BestPods, i, backend)
+		}()
+	}
+	wg.Wait()
+	accesslog.MarkUpstreamEnd(c)
+
+	if c.Request.Context().Err() != nil {
+		r.recordCanceledRequest(c, c.Request, modelName, modelServerFullName)
+		return
+	}
+	if err := errors.Join(errs...); err != nil {
+		klog.Errorf("scoring request to model server %s failed: %v", modelServerFullName, err)
+		accesslog.SetError(c, "proxy", "request to all pods failed")
+		c.AbortWithStatusJSON(http.StatusNotFound, "request to all pods failed")
+		return
+	}
+
+	body := responses[0].body
+	if len(responses) > 1 {
+		topN := 0
+		if n, ok := modelRequest["top_n"].(float64); ok {
+			topN = int(n)
+		}
+		if body, err = mergeScoringResponses(endpoint, responses, batchOffsets(batches), topN); err != nil {
+			klog.Errorf("failed to merge the responses of model server %s: %v", modelServerFullName, err)
+			accesslog.SetError(c, "response_processing", err.Error())
+			c.AbortWithStatusJSON(http.StatusBadGateway, "invalid response from model server")
+			return
+		}
+	}
+
+	// Prefer the tokens counted by the model server to the estimation.
+	if tokens := scoringUsageTokens(body); tokens > 0 {
+		inputTokens = tokens
+	}
+	accesslog.SetTokenCounts(c, inputTokens, 0)
+	accesslog.SetDocuments(c, len(inputs))
+	metricsRecorder.RecordInputTokens(inputTokens)
+	r.metrics.RecordDocuments(modelName, path, len(inputs))
+
+	handlers.CopyResponseHeaders(c, responses[0].header)
+	c.Writer.Header().Del("Content-Length")
+	c.Data(http.StatusOK, "application/json", body)
+	r.scheduler.RunPostHooks(ctx, responses[0].pod)
+}
+
+// sendScoringBatch sends a batch to the pods, starting with the pod at index first and trying the next ones
+// if it fails.
+func sendScoringBatch(downstream *http.Request, body []byte, pods []*datastore.PodInfo, first int, backend *upstream) (*scoringResponse, error) {
+	var err error
+	for attempt := 0; attempt < len(pods); attempt++ {
+		i := (first + attempt) % len(pods)
+		req := downstream.Clone(downstream.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+
+		var resp *http.Response
+		resp, err = doRequest(req, pods[i].Pod.Status.PodIP, backend)
+		if err != nil {
+			if downstream.Context().Err() != nil {
+				return nil, err
+			}
+			klog.Errorf("scoring request to pod %s failed: %v", pods[i].Pod.Name, err)
+			continue
+		}
+		data, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if readErr != nil {
+			err = readErr
+			continue
+		}
+		return &scoringResponse{header: resp.Header, body: data, pod: i}, nil
+	}
+	return nil, err
+}
+
+// parseScoringRequest returns the inputs of a scoring request, the text they are scored on for the token estimation,
+// and the number of token ids of the inputs given as arrays of token ids. A rerank model scores the query along with
+// each document.
+func parseScoringRequest(modelRequest ModelRequest, endpoint scoringEndpoint) ([]interface{}, string, int, error) {
+	var inputs []interface{}
+	switch value := modelRequest[endpoint.inputsField].(type) {
+	case []interface{}:
+		inputs = value
+		if endpoint.tokenized && len(value) > 0 && isTokenIDs(value) {
+			// A single input given as an array of token ids.
+			inputs = []interface{}{value}
+		}
+	case string:
+		inputs = []interface{}{value}
+	}
+	if len(inputs) == 0 {
+		return nil, "", 0, fmt.Errorf("%s must be a non-empty list", endpoint.inputsField)
+	}
+
+	query := ""
+	if endpoint.ranked {
+		var ok bool
+		if query, ok = modelRequest["query"].(string); !ok || query == "" {
+			return nil, "", 0, fmt.Errorf("query must be a non-empty string")
+		}
+	}
+	var text strings.Builder
+	tokenIDs := 0
+	for _, input := range inputs {
+		if ids, ok := input.([]interface{}); ok && endpoint.tokenized {
+			if len(ids) == 0 || !isTokenIDs(ids) {
+				return nil, "", 0, fmt.Errorf("%s must be a text or an array of token ids", endpoint.inputsField)
+			}
+			tokenIDs += len(ids)
+			continue
+		}
+		text.WriteString(query)
+		text.WriteString(inputText(input))
+		text.WriteByte('\n')
+	}
+	return inputs, text.String(), tokenIDs, nil
+}
+
+// isTokenIDs reports whether all the values are token ids.
+func isTokenIDs(values []interface{}) bool {
+	for _, value := range values {
+		if _, ok := value.(float64); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// inputText returns the text of an input, either a string or an object with a text field like Cohere documents.
+func inputText(input interface{}) string {
+	switch value := input.(type) {
+	case string:
+		return value
+	case map[string]interface{}:
+		if text, ok := value["text"].(string); ok {
+			return text
+		}
+	}
+	data, _ := json.Marshal(input)
+	return string(data)
+}
+
+// splitBatches splits the inputs into batches of at most size inputs, or returns a single batch if size is 0.
+func splitBatches(inputs []interface{}, size int) [][]interface{} {
+	if size <= 0 || len(inputs) <= size {
+		return [][]interface{}{inputs}
+	}
+	var batches [][]interface{}
+	for start := 0; start < len(inputs); start += size {
+		batches = append(batches, inputs[start:min(start+size, len(inputs))])
+	}
+	return batches
+}
+
+// batchOffsets returns the index of the first input of each batch in the request.
+func batchOffsets(batches [][]interface{}) []int {
+	offsets := make([]int, len(batches))
+	for i := 1; i < len(batches); i++ {
+		offsets[i] = offsets[i-1] + len(batches[i-1])
+	}
+	return offsets
+}
+
+// mergeScoringResponses merges the responses of the batches of a request: the indexes of the results are offset by
+// the index of the first input of their batch, ranked results are sorted again and limited to topN if set, and the
+// usage is summed. The other fields are those of the first response.
+func mergeScoringResponses(endpoint scoringEndpoint, responses []*scoringResponse, offsets []int, topN int) ([]byte, error) {
+	var merged map[string]json.RawMessage
+	var results []map[string]interface{}
+	usage := map[string]float64{}
+	for i, response := range responses {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(response.body, &fields); err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			merged = fields
+		}
+		var batchResults []map[string]interface{}
+		if err := json.Unmarshal(fields[endpoint.resultsField], &batchResults); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", endpoint.resultsField, err)
+		}
+		for _, result := range batchResults {
+			if index, ok := result["index"].(float64); ok {
+				result["index"] = int(index) + offsets[i]
+			}
+		}
+		results = append(results, batchResults...)
+
+		var batchUsage map[string]interface{}
+		if json.Unmarshal(fields["usage"], &batchUsage) == nil {
+			for k, v := range batchUsage {
+				if n, ok := v.(float64); ok {
+					usage[k] += n
+				}
+			}
+		}
+	}
+
+	if endpoint.ranked {
+		sort.SliceStable(results, func(i, j int) bool {
+			si, _ := results[i]["relevance_score"].(float64)
+			sj, _ := results[j]["relevance_score"].(float64)
+			return si > sj
+		})
+		if topN > 0 && len(results) > topN {
+			results = results[:topN]
+		}
+	}
+	var err error
+	if merged[endpoint.resultsField], err = json.Marshal(results); err != nil {
+		return nil, err
+	}
+	if len(usage) > 0 {
+		if merged["usage"], err = json.Marshal(usage); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(merged)
+}
+
+// scoringUsageTokens returns the input tokens reported in the usage of a scoring response, 0 if not reported.
+func scoringUsageTokens(body []byte) int {
+	var response struct {
+		Usage struct {
+			PromptTokens int `json:"prompt_tokens"`
+			TotalTokens  int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	if json.Unmarshal(body, &response) != nil {
+		return 0
+	}
+	if response.Usage.PromptTokens > 0 {
+		return response.Usage.PromptTokens
+	}
+	return response.Usage.TotalTokens
+}
@@ -0,0 +1,330 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+)
+
+func TestParseScoringRequest(t *testing.T) {
+	rerank := scoringEndpoints[rerankPath]
+	inputs, text, tokenIDs, err := parseScoringRequest(ModelRequest{
+		"query":     "capital",
+		"documents": []interface{}{"Paris", map[string]interface{}{"text": "Berlin"}},
+	}, rerank)
+	require.NoError(t, err)
+	assert.Len(t, inputs, 2)
+	assert.Equal(t, "capitalParis\ncapitalBerlin\n", text)
+	assert.Zero(t, tokenIDs)
+
+	_, _, _, err = parseScoringRequest(ModelRequest{"documents": []interface{}{"Paris"}}, rerank)
+	assert.EqualError(t, err, "query must be a non-empty string")
+	_, _, _, err = parseScoringRequest(ModelRequest{"query": "capital", "documents": []interface{}{}}, rerank)
+	assert.EqualError(t, err, "documents must be a non-empty list")
+
+	inputs, text, _, err = parseScoringRequest(ModelRequest{"input": "great movie"}, scoringEndpoints[classifyPath])
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"great movie"}, inputs)
+	assert.Equal(t, "great movie\n", text)
+
+	embeddings := scoringEndpoints[embeddingsPath]
+	inputs, text, tokenIDs, err = parseScoringRequest(ModelRequest{"input": []interface{}{"hello", "world"}}, embeddings)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"hello", "world"}, inputs)
+	assert.Equal(t, "hello\nworld\n", text)
+	assert.Zero(t, tokenIDs)
+	// A single input of token ids.
+	inputs, text, tokenIDs, err = parseScoringRequest(ModelRequest{"input": []interface{}{9906.0, 1917.0, 0.0}}, embeddings)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{[]interface{}{9906.0, 1917.0, 0.0}}, inputs)
+	assert.Empty(t, text)
+	assert.Equal(t, 3, tokenIDs)
+	// Several inputs of token ids.
+	inputs, _, tokenIDs, err = parseScoringRequest(ModelRequest{"input": []interface{}{
+		[]interface{}{9906.0, 1917.0}, []interface{}{15339.0},
+	}}, embeddings)
+	require.NoError(t, err)
+	assert.Len(t, inputs, 2)
+	assert.Equal(t, 3, tokenIDs)
+	_, _, _, err = parseScoringRequest(ModelRequest{"input": []interface{}{[]interface{}{"hello"}}}, embeddings)
+	assert.EqualError(t, err, "input must be a text or an array of token ids")
+	_, _, _, err = parseScoringRequest(ModelRequest{"input": []interface{}{[]interface{}{}}}, embeddings)
+	assert.EqualError(t, err, "input must be a text or an array of token ids")
+}
+
+func TestSplitBatches(t *testing.T) {
+	inputs := []interface{}{"a", "b", "c", "d", "e"}
+	assert.Equal(t, [][]interface{}{inputs}, splitBatches(inputs, 0))
+	assert.Equal(t, [][]interface{}{inputs}, splitBatches(inputs, 5))
+	batches := splitBatches(inputs, 2)
+	assert.Equal(t, [][]interface{}{{"a", "b"}, {"c", "d"}, {"e"}}, batches)
+	assert.Equal(t, []int{0, 2, 4}, batchOffsets(batches))
+}
+
+func TestMergeScoringResponses(t *testing.T) {
+	responses := []*scoringResponse{
+		{body: []byte(`{"id":"rerank-1","model":"bge","results":[{"index":0,"relevance_score":0.2},{"index":1,"relevance_score":0.9}],"usage":{"total_tokens":10}}`)},
+		{body: []byte(`{"id":"rerank-2","model":"bge","results":[{"index":1,"relevance_score":0.5},{"index":0,"relevance_score":0.1}],"usage":{"total_tokens":7}}`)},
+	}
+	body, err := mergeScoringResponses(scoringEndpoints[rerankPath], responses, []int{0, 2}, 3)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"rerank-1","model":"bge","results":[
+		{"index":1,"relevance_score":0.9},{"index":3,"relevance_score":0.5},{"index":0,"relevance_score":0.2}
+	],"usage":{"total_tokens":17}}`, string(body))
+	assert.Equal(t, 17, scoringUsageTokens(body))
+
+	responses = []*scoringResponse{
+		{body: []byte(`{"object":"list","data":[{"index":0,"label":"positive"}],"usage":{"prompt_tokens":4,"total_tokens":4}}`)},
+		{body: []byte(`{"object":"list","data":[{"index":0,"label":"negative"}],"usage":{"prompt_tokens":3,"total_tokens":3}}`)},
+	}
+	body, err = mergeScoringResponses(scoringEndpoints[classifyPath], responses, []int{0, 1}, 0)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"object":"list","data":[{"index":0,"label":"positive"},{"index":1,"label":"negative"}],
+		"usage":{"prompt_tokens":7,"total_tokens":7}}`, string(body))
+
+	_, err = mergeScoringResponses(scoringEndpoints[rerankPath], []*scoringResponse{{body: []byte("oops")}, {body: []byte("{}")}}, []int{0, 1}, 0)
+	assert.Error(t, err)
+}
+
+func TestRouter_HandlerFunc_Rerank(t *testing.T) {
+	var upstreamRequests atomic.Int32
+	backendHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamRequests.Add(1)
+		assert.Equal(t, rerankPath, r.URL.Path)
+		var request struct {
+			Model     string   `json:"model"`
+			Query     string   `json:"query"`
+			Documents []string `json:"documents"`
+			TopN      int      `json:"top_n"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "BAAI/bge-reranker-v2-m3", request.Model)
+		assert.LessOrEqual(t, len(request.Documents), 2)
+		// Score the documents by their length.
+		var results []map[string]interface{}
+		for i, document := range request.Documents {
+			results = append(results, map[string]interface{}{"index": i, "relevance_score": float64(len(document)) / 10})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":      "rerank",
+			"results": results,
+			"usage":   map[string]int{"total_tokens": 5 * len(request.Documents)},
+		})
+	})
+	router, store, backend := setupTestRouter(backendHandler)
+	defer backend.Close()
+	router.scoringBatchSize = 2
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: "reranker", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			Model:           ptr.To("BAAI/bge-reranker-v2-m3"),
+			WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+			InferenceEngine: "vLLM",
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "reranker-0", Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}
+	store.AddOrUpdateModelServer(modelServer, sets.New(types.NamespacedName{Name: "reranker-0", Namespace: "default"}))
+	store.AddOrUpdatePod(pod, []*aiv1alpha1.ModelServer{modelServer})
+	store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "reranker", Namespace: "default"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "reranker",
+			Rules: []*aiv1alpha1.Rule{
+				{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "reranker"}}},
+			},
+		},
+	})
+
+	documents := func() float64 {
+		return testutil.ToFloat64(metrics.DefaultMetrics.DocumentsTotal.WithLabelValues("reranker", rerankPath))
+	}
+	before := documents()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, rerankPath, bytes.NewBufferString(
+		`{"model":"reranker","query":"longest","documents":["a","abcd","ab","abcde","abc"],"top_n":3}`))
+	router.HandlerFunc()(c)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, int32(3), upstreamRequests.Load())
+	assert.JSONEq(t, `{"id":"rerank","results":[
+		{"index":3,"relevance_score":0.5},{"index":1,"relevance_score":0.4},{"index":4,"relevance_score":0.3}
+	],"usage":{"total_tokens":25}}`, w.Body.String())
+	assert.Equal(t, before+5, documents())
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, rerankPath, bytes.NewBufferString(`{"model":"reranker","documents":["a"]}`))
+	router.HandlerFunc()(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRouter_HandlerFunc_Embeddings(t *testing.T) {
+	var upstreamRequests atomic.Int32
+	backendHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamRequests.Add(1)
+		assert.Equal(t, embeddingsPath, r.URL.Path)
+		var request struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "BAAI/bge-m3", request.Model)
+		assert.LessOrEqual(t, len(request.Input), 2)
+		// Embed the inputs by their length.
+		var data []map[string]interface{}
+		for i, input := range request.Input {
+			data = append(data, map[string]interface{}{"object": "embedding", "index": i, "embedding": []float64{float64(len(input))}})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"object": "list",
+			"data":   data,
+			"usage":  map[string]int{"prompt_tokens": 2 * len(request.Input), "total_tokens": 2 * len(request.Input)},
+		})
+	})
+	router, store, backend := setupTestRouter(backendHandler)
+	defer backend.Close()
+	router.scoringBatchSize = 2
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: "embedder", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			Model:           ptr.To("BAAI/bge-m3"),
+			WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+			InferenceEngine: "vLLM",
+		},
+	}
+	store.AddOrUpdateModelServer(modelServer, sets.New(types.NamespacedName{Name: "embedder-0", Namespace: "default"}))
+	store.AddOrUpdatePod(&corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "embedder-0", Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}, []*aiv1alpha1.ModelServer{modelServer})
+	store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "embedder", Namespace: "default"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "embedder",
+			Rules: []*aiv1alpha1.Rule{
+				{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "embedder"}}},
+			},
+		},
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, embeddingsPath, bytes.NewBufferString(
+		`{"model":"embedder","input":["a","abc","ab"]}`))
+	router.HandlerFunc()(c)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, int32(2), upstreamRequests.Load())
+	assert.JSONEq(t, `{"object":"list","data":[
+		{"object":"embedding","index":0,"embedding":[1]},
+		{"object":"embedding","index":1,"embedding":[3]},
+		{"object":"embedding","index":2,"embedding":[2]}
+	],"usage":{"prompt_tokens":6,"total_tokens":6}}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, embeddingsPath, bytes.NewBufferString(`{"model":"embedder","input":[]}`))
+	router.HandlerFunc()(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRouter_HandlerFunc_EmbeddingsItemRateLimit(t *testing.T) {
+	backendHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"object":"list","data":[]}`))
+	})
+	router, store, backend := setupTestRouter(backendHandler)
+	defer backend.Close()
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: "embedder", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+			InferenceEngine: "vLLM",
+		},
+	}
+	store.AddOrUpdateModelServer(modelServer, sets.New(types.NamespacedName{Name: "embedder-0", Namespace: "default"}))
+	store.AddOrUpdatePod(&corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "embedder-0", Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}, []*aiv1alpha1.ModelServer{modelServer})
+	store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "embedder", Namespace: "default"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "embedder",
+			Rules: []*aiv1alpha1.Rule{
+				{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "embedder"}}},
+			},
+			RateLimit: &aiv1alpha1.RateLimit{
+				ItemsPerUnit: ptr.To(uint32(4)),
+				Unit:         aiv1alpha1.Minute,
+			},
+		},
+	})
+
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, embeddingsPath, bytes.NewBufferString(
+			`{"model":"embedder","input":["a","b","c"]}`))
+		router.HandlerFunc()(c)
+		return w
+	}
+	// Once the limit is applied, the second batch of three inputs exceeds the four items per minute.
+	var w *httptest.ResponseRecorder
+	assert.Eventually(t, func() bool {
+		w = send()
+		return w.Code == http.StatusTooManyRequests
+	}, time.Second, 10*time.Millisecond)
+	assert.Contains(t, w.Body.String(), "item rate limit exceeded")
+}
@@ -0,0 +1,169 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/semcache"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/utils"
+)
+
+const (
+	// semanticCacheHeader tells the client whether the response was served from the semantic cache.
+	semanticCacheHeader = "x-kthena-cache"
+	// semanticCacheEntryKey holds the cache entry of a request whose response is cached once it succeeds.
+	semanticCacheEntryKey = "semanticCacheEntry"
+	// semanticCacheTimeout bounds the embedding of a prompt and the cache store operations.
+	semanticCacheTimeout = 2 * time.Second
+
+	cacheHit   = "hit"
+	cacheMiss  = "miss"
+	cacheError = "error"
+)
+
+// pendingCacheEntry is the entry of a response to cache once it is received.
+type pendingCacheEntry struct {
+	cache     *semcache.Cache
+	scope     string
+	embedding []float32
+}
+
+// newSemanticCache creates the semantic cache of the configuration, nil if it is disabled or can't be created.
+func newSemanticCache(config conf.SemanticCacheConfig) *semcache.Cache {
+	if !config.Enabled {
+		return nil
+	}
+	if config.EmbeddingEndpoint == "" {
+		klog.Errorf("the semantic cache requires an embedding endpoint, it is disabled")
+		return nil
+	}
+	ttl := time.Duration(config.TTLSeconds) * time.Second
+	storeType := config.Store
+	if storeType == "" {
+		storeType = "memory"
+	}
+	var store semcache.Store
+	switch storeType {
+	case "memory":
+		store = semcache.NewMemoryStore(ttl, config.MaxEntries, config.MaxTotalEntries)
+	case "redis":
+		client := utils.TryGetRedisClient()
+		if client == nil {
+			klog.Errorf("the semantic cache store requires redis, the semantic cache is disabled")
+			return nil
+		}
+		store = semcache.NewRedisStore(client, ttl, config.MaxEntries)
+	default:
+		klog.Errorf("unknown semantic cache store %q, the semantic cache is disabled", config.Store)
+		return nil
+	}
+	embedder := semcache.NewHTTPEmbedder(&http.Client{Timeout: semanticCacheTimeout}, config.EmbeddingEndpoint, config.EmbeddingModel)
+	klog.Infof("semantic cache is enabled with the %s store", storeType)
+	return semcache.New(embedder, store, config.SimilarityThreshold)
+}
+
+// serveCachedResponse answers the request with the cached response of a similar prompt and returns true, or prepares
+// the caching of its response and returns false. The responses are only shared by the requests of an authenticated
+// caller to a model with the same parameters. The requests with a no-cache or no-store Cache-Control header, or
+// without an authenticated caller, bypass the cache.
+func (r *Router) serveCachedResponse(c *gin.Context, modelName string, modelRequest ModelRequest, prompt string) bool {
+	if r.semanticCache == nil {
+		return false
+	}
+	if cacheControl := strings.ToLower(c.Request.Header.Get("Cache-Control")); strings.Contains(cacheControl, "no-cache") ||
+		strings.Contains(cacheControl, "no-store") {
+		return false
+	}
+	scope, ok := cacheScope(c, modelName, modelRequest)
+	if !ok {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), semanticCacheTimeout)
+	defer cancel()
+	response, embedding, err := r.semanticCache.Lookup(ctx, scope, prompt)
+	switch {
+	case err != nil:
+		klog.Warningf("semantic cache lookup of model %s failed: %v", modelName, err)
+		r.metrics.RecordSemanticCacheLookup(modelName, cacheError)
+		if embedding == nil {
+			return false
+		}
+	case response != nil:
+		r.metrics.RecordSemanticCacheLookup(modelName, cacheHit)
+		c.Header(semanticCacheHeader, cacheHit)
+		c.Data(http.StatusOK, "application/json", response)
+		return true
+	default:
+		r.metrics.RecordSemanticCacheLookup(modelName, cacheMiss)
+	}
+	c.Header(semanticCacheHeader, cacheMiss)
+	c.Set(semanticCacheEntryKey, &pendingCacheEntry{cache: r.semanticCache, scope: scope, embedding: embedding})
+	return false
+}
+
+// cacheScope returns the scope of the cached responses of a request: its model, its authenticated caller and a hash
+// of its parameters other than the prompt, e.g. its sampling parameters, tools and response_format, which change the
+// response of a prompt. The requests without an authenticated caller have no scope, as the tenant headers are set by
+// the clients and would let a client read the responses of another one.
+func cacheScope(c *gin.Context, modelName string, modelRequest ModelRequest) (string, bool) {
+	subject := c.GetString(common.UserIdKey)
+	if subject == "" {
+		return "", false
+	}
+	params := make(map[string]interface{}, len(modelRequest))
+	for name, value := range modelRequest {
+		switch name {
+		case "model", "messages", "prompt":
+		default:
+			params[name] = value
+		}
+	}
+	// The keys of the maps are marshaled in order, the same parameters always have the same hash.
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return "", false
+	}
+	hash := sha256.Sum256(encoded)
+	return modelName + "/" + subject + "/" + hex.EncodeToString(hash[:8]), true
+}
+
+// cacheResponse caches the response of a request which missed the semantic cache.
+func cacheResponse(c *gin.Context, response []byte) {
+	value, ok := c.Get(semanticCacheEntryKey)
+	if !ok {
+		return
+	}
+	entry := value.(*pendingCacheEntry)
+	ctx, cancel := context.WithTimeout(context.Background(), semanticCacheTimeout)
+	defer cancel()
+	if err := entry.cache.Store(ctx, entry.scope, entry.embedding, response); err != nil {
+		klog.Warningf("failed to cache response: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/semcache"
+)
+
+type promptEmbedder map[string][]float32
+
+func (e promptEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	return e[text], nil
+}
+
+func TestServeCachedResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	embedder := promptEmbedder{
+		"capital of France?":      {1, 0},
+		"what's France's capital": {0.99, 0.05},
+		"height of Everest?":      {0, 1},
+	}
+	r := &Router{
+		metrics:       metrics.DefaultMetrics,
+		semanticCache: semcache.New(embedder, semcache.NewMemoryStore(0, 0, 0), 0.9),
+	}
+	lookup := func(prompt, subject, cacheControl string, params ...string) (*gin.Context, *httptest.ResponseRecorder, bool) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		// The tenant header is set by the client, it doesn't scope the cache.
+		c.Request.Header.Set(tenantHeader, "alice")
+		if subject != "" {
+			c.Set(common.UserIdKey, subject)
+		}
+		if cacheControl != "" {
+			c.Request.Header.Set("Cache-Control", cacheControl)
+		}
+		modelRequest := ModelRequest{"model": "model", "prompt": prompt}
+		for i := 0; i+1 < len(params); i += 2 {
+			modelRequest[params[i]] = params[i+1]
+		}
+		return c, w, r.serveCachedResponse(c, "model", modelRequest, prompt)
+	}
+
+	c, w, served := lookup("capital of France?", "alice", "")
+	require.False(t, served)
+	assert.Equal(t, cacheMiss, w.Header().Get(semanticCacheHeader))
+	cacheResponse(c, []byte(`{"choices":[{"message":{"content":"Paris"}}]}`))
+
+	_, w, served = lookup("what's France's capital", "alice", "")
+	require.True(t, served)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, cacheHit, w.Header().Get(semanticCacheHeader))
+	assert.JSONEq(t, `{"choices":[{"message":{"content":"Paris"}}]}`, w.Body.String())
+
+	_, _, served = lookup("height of Everest?", "alice", "")
+	assert.False(t, served, "the prompt is not similar")
+	_, _, served = lookup("what's France's capital", "bob", "")
+	assert.False(t, served, "the responses are not shared between callers")
+	_, _, served = lookup("what's France's capital", "alice", "", "temperature", "1.5")
+	assert.False(t, served, "the responses are not shared between sampling parameters")
+	_, _, served = lookup("what's France's capital", "alice", "", "response_format", "json_object")
+	assert.False(t, served, "the responses are not shared between response formats")
+
+	c, w, served = lookup("what's France's capital", "alice", "no-cache")
+	assert.False(t, served, "the request bypasses the cache")
+	assert.Empty(t, w.Header().Get(semanticCacheHeader))
+	_, ok := c.Get(semanticCacheEntryKey)
+	assert.False(t, ok, "the response of a request bypassing the cache is not cached")
+
+	// The unauthenticated requests bypass the cache.
+	c, w, served = lookup("capital of France?", "", "")
+	assert.False(t, served)
+	assert.Empty(t, w.Header().Get(semanticCacheHeader))
+	_, ok = c.Get(semanticCacheEntryKey)
+	assert.False(t, ok)
+}
+
+func TestNewSemanticCache(t *testing.T) {
+	assert.Nil(t, newSemanticCache(conf.SemanticCacheConfig{}))
+	assert.Nil(t, newSemanticCache(conf.SemanticCacheConfig{Enabled: true}), "the embedding endpoint is required")
+	assert.Nil(t, newSemanticCache(conf.SemanticCacheConfig{Enabled: true, EmbeddingEndpoint: "http://embedder", Store: "etcd"}))
+	assert.NotNil(t, newSemanticCache(conf.SemanticCacheConfig{Enabled: true, EmbeddingEndpoint: "http://embedder"}))
+}
@@ -0,0 +1,252 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cespare/xxhash"
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins"
+)
+
+const (
+	defaultSessionCookieName = "kthena-session"
+	defaultSessionTTL        = 30 * time.Minute
+
+	// sessionSweepInterval is the minimum time between two removals of the expired sessions.
+	sessionSweepInterval = time.Minute
+)
+
+// session is the affinity of a request to the pod of its session.
+type session struct {
+	affinity *v1alpha1.SessionAffinity
+	// key identifies the session of the ConsistentHash type in the session table, empty for the Cookie type.
+	key string
+	// modelServer and pod are the name of the ModelServer and the hash of the pod the session is pinned to,
+	// the session is not pinned if pod is 0.
+	modelServer string
+	pod         uint64
+}
+
+// pinnedSession is a session of the ConsistentHash type in the session table.
+type pinnedSession struct {
+	modelServer string
+	pod         uint64
+	expires     time.Time
+}
+
+// sessionTable keeps the pods the sessions of the ConsistentHash type are pinned to, so that a session stays on its
+// pod when pods are added to its ModelServer.
+type sessionTable struct {
+	mu        sync.Mutex
+	sessions  map[string]pinnedSession
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+func newSessionTable() *sessionTable {
+	return &sessionTable{sessions: make(map[string]pinnedSession), now: time.Now}
+}
+
+func (t *sessionTable) get(key string) (pinnedSession, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	pinned, ok := t.sessions[key]
+	if !ok || !t.now().Before(pinned.expires) {
+		return pinnedSession{}, false
+	}
+	return pinned, true
+}
+
+func (t *sessionTable) put(key string, pinned pinnedSession) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	if now.After(t.nextSweep) {
+		for k, s := range t.sessions {
+			if !now.Before(s.expires) {
+				delete(t.sessions, k)
+			}
+		}
+		t.nextSweep = now.Add(sessionSweepInterval)
+	}
+	t.sessions[key] = pinned
+}
+
+// lookupSession returns the session of a request to the ModelRoute, nil if the ModelRoute has no session affinity
+// or the request has no session key.
+func (r *Router) lookupSession(c *gin.Context, modelRequest ModelRequest, modelRoute *v1alpha1.ModelRoute) *session {
+	if modelRoute == nil || modelRoute.Spec.SessionAffinity == nil {
+		return nil
+	}
+	affinity := modelRoute.Spec.SessionAffinity
+	s := &session{affinity: affinity}
+	if affinity.Type == v1alpha1.SessionAffinityCookie {
+		if cookie, err := c.Cookie(sessionCookieName(affinity)); err == nil {
+			s.modelServer, s.pod = parseSessionCookie(cookie)
+		}
+		return s
+	}
+
+	key := ""
+	if affinity.Header != "" {
+		key = c.Request.Header.Get(affinity.Header)
+	}
+	if key == "" {
+		key, _ = modelRequest["user"].(string)
+	}
+	if key == "" {
+		return nil
+	}
+	s.key = modelRoute.Namespace + "/" + modelRoute.Name + "\x00" + key
+	if pinned, ok := r.sessions.get(s.key); ok {
+		s.modelServer, s.pod = pinned.modelServer, pinned.pod
+	}
+	return s
+}
+
+// pinnedModelServer returns the ModelServer the session is pinned to if it is a target of the rule of the ModelRoute
+// which matched the request, so that a session keeps its ModelServer when the rule splits the traffic between several
+// of them. The canary of a rollout in progress counts as a target of the rules targeting its stable ModelServer, so
+// that the sessions sent to the canary stay on it. The session is pinned again when the request matches a rule not
+// targeting its ModelServer, so that the matches of the rules always apply.
+func (s *session) pinnedModelServer(modelRoute *v1alpha1.ModelRoute, rule *v1alpha1.Rule) (types.NamespacedName, bool) {
+	if s == nil || s.modelServer == "" || rule == nil {
+		return types.NamespacedName{}, false
+	}
+	canary, stable := rolloutCanary(modelRoute)
+	for _, target := range rule.TargetModels {
+		if target == nil {
+			continue
+		}
+		if target.ModelServerName == s.modelServer || (canary == s.modelServer && target.ModelServerName == stable) {
+			return types.NamespacedName{Namespace: modelRoute.Namespace, Name: s.modelServer}, true
+		}
+	}
+	return types.NamespacedName{}, false
+}
+
+// rolloutCanary returns the canary and the stable ModelServers of the rollout of the ModelRoute while the rollout
+// controller shifts traffic to the canary, empty names otherwise.
+func rolloutCanary(modelRoute *v1alpha1.ModelRoute) (string, string) {
+	rollout, status := modelRoute.Spec.Rollout, modelRoute.Status.Rollout
+	if rollout == nil || status == nil || status.CanaryModelServerName != rollout.CanaryModelServerName || status.CanaryWeight <= 0 {
+		return "", ""
+	}
+	return rollout.CanaryModelServerName, rollout.StableModelServerName
+}
+
+// pin moves the pod of the session to the front of the pods selected for the request and pins the session to the
+// first selected pod. The pod of a session not pinned yet, or pinned to a pod no longer serving, is the pod ranked
+// first by the consistent hash of its key for the ConsistentHash type, and the pod selected by the scheduler for the
+// Cookie type.
+func (s *session) pin(ctx *framework.Context, pods []*datastore.PodInfo) {
+	if s == nil || ctx.BestPods == nil {
+		return
+	}
+	var preferred *datastore.PodInfo
+	if s.pod != 0 && s.modelServer == ctx.ModelServerName.Name {
+		for _, pod := range pods {
+			if pod.Pod != nil && podHash(pod) == s.pod {
+				preferred = pod
+				break
+			}
+		}
+	}
+	if preferred == nil && s.key != "" {
+		if picked := plugins.NewPrefixCacheAware().Pick(&framework.Context{SessionID: s.key}, pods, 1); len(picked) > 0 {
+			preferred = picked[0]
+		}
+	}
+	if preferred != nil {
+		moveToFront(ctx, preferred)
+	}
+	if len(ctx.BestPods) == 0 || ctx.BestPods[0].Pod == nil {
+		return
+	}
+	s.modelServer = ctx.ModelServerName.Name
+	s.pod = podHash(ctx.BestPods[0])
+}
+
+// saveSession keeps the pod the session is pinned to for the TTL of the session affinity.
+func (r *Router) saveSession(c *gin.Context, s *session) {
+	if s == nil || s.pod == 0 {
+		return
+	}
+	ttl := defaultSessionTTL
+	if s.affinity.TTL != nil && s.affinity.TTL.Duration > 0 {
+		ttl = s.affinity.TTL.Duration
+	}
+	if s.affinity.Type == v1alpha1.SessionAffinityCookie {
+		http.SetCookie(c.Writer, &http.Cookie{
+			Name:     sessionCookieName(s.affinity),
+			Value:    s.modelServer + "." + strconv.FormatUint(s.pod, 16),
+			Path:     "/",
+			MaxAge:   int(ttl.Seconds()),
+			HttpOnly: true,
+		})
+		return
+	}
+	r.sessions.put(s.key, pinnedSession{modelServer: s.modelServer, pod: s.pod, expires: r.sessions.now().Add(ttl)})
+}
+
+func sessionCookieName(affinity *v1alpha1.SessionAffinity) string {
+	if affinity.CookieName != "" {
+		return affinity.CookieName
+	}
+	return defaultSessionCookieName
+}
+
+// parseSessionCookie returns the ModelServer and the pod hash of a session cookie, the pod is 0 if the cookie is
+// invalid. The cookie doesn't reveal the name of the pod.
+func parseSessionCookie(value string) (string, uint64) {
+	i := strings.LastIndexByte(value, '.')
+	if i <= 0 {
+		return "", 0
+	}
+	pod, err := strconv.ParseUint(value[i+1:], 16, 64)
+	if err != nil {
+		return "", 0
+	}
+	return value[:i], pod
+}
+
+func podHash(pod *datastore.PodInfo) uint64 {
+	return xxhash.Sum64String(pod.Pod.Namespace + "/" + pod.Pod.Name)
+}
+
+// moveToFront moves the pod to the front of the pods selected for the request.
+func moveToFront(ctx *framework.Context, pod *datastore.PodInfo) {
+	bestPods := make([]*datastore.PodInfo, 0, len(ctx.BestPods)+1)
+	bestPods = append(bestPods, pod)
+	for _, p := range ctx.BestPods {
+		if p != pod {
+			bestPods = append(bestPods, p)
+		}
+	}
+	ctx.BestPods = bestPods
+}
@@ -0,0 +1,239 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+)
+
+func sessionAffinityRoute(affinity *aiv1alpha1.SessionAffinity) *aiv1alpha1.ModelRoute {
+	return &aiv1alpha1.ModelRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "chat", Namespace: "default"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "chat",
+			Rules: []*aiv1alpha1.Rule{{
+				TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "server-a"}, {ModelServerName: "server-b"}},
+			}},
+			SessionAffinity: affinity,
+		},
+	}
+}
+
+func sessionRequest(header http.Header) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	for name, values := range header {
+		c.Request.Header[name] = values
+	}
+	return c, w
+}
+
+// schedule pins the session of a request scheduled on pods, the scheduler selecting the pods in order.
+func schedule(r *Router, c *gin.Context, s *session, modelServer string, pods []*datastore.PodInfo) *datastore.PodInfo {
+	ctx := &framework.Context{
+		ModelServerName: types.NamespacedName{Namespace: "default", Name: modelServer},
+		BestPods:        append([]*datastore.PodInfo(nil), pods...),
+	}
+	s.pin(ctx, pods)
+	r.saveSession(c, s)
+	return ctx.BestPods[0]
+}
+
+func TestConsistentHashSessionAffinity(t *testing.T) {
+	r := &Router{sessions: newSessionTable()}
+	now := time.Date(2026, time.October, 14, 10, 0, 0, 0, time.UTC)
+	r.sessions.now = func() time.Time { return now }
+	route := sessionAffinityRoute(&aiv1alpha1.SessionAffinity{
+		Type:   aiv1alpha1.SessionAffinityConsistentHash,
+		Header: "X-Conversation-Id",
+		TTL:    &v1.Duration{Duration: time.Minute},
+	})
+	pods := []*datastore.PodInfo{buildPodInfo("pod-1", "10.0.0.1"), buildPodInfo("pod-2", "10.0.0.2"), buildPodInfo("pod-3", "10.0.0.3")}
+
+	c, _ := sessionRequest(nil)
+	assert.Nil(t, r.lookupSession(c, ModelRequest{"model": "chat"}, route), "a request without a session key is not pinned")
+
+	c, _ = sessionRequest(http.Header{"X-Conversation-Id": {"conversation-1"}})
+	s := r.lookupSession(c, ModelRequest{"model": "chat"}, route)
+	require.NotNil(t, s)
+	first := schedule(r, c, s, "server-a", pods)
+	reversed := []*datastore.PodInfo{pods[2], pods[1], pods[0]}
+	assert.Same(t, first, schedule(r, c, s, "server-a", reversed), "the pod of a session doesn't depend on the scheduler")
+
+	// The session stays on its pod when a pod is added.
+	c, _ = sessionRequest(http.Header{"X-Conversation-Id": {"conversation-1"}})
+	s = r.lookupSession(c, ModelRequest{"model": "chat"}, route)
+	server, ok := s.pinnedModelServer(route, route.Spec.Rules[0])
+	assert.True(t, ok)
+	assert.Equal(t, types.NamespacedName{Namespace: "default", Name: "server-a"}, server)
+	scaled := append([]*datastore.PodInfo{buildPodInfo("pod-4", "10.0.0.4"), buildPodInfo("pod-5", "10.0.0.5")}, pods...)
+	assert.Same(t, first, schedule(r, c, s, "server-a", scaled))
+
+	// The user field is the key of the requests without the header.
+	c, _ = sessionRequest(nil)
+	s = r.lookupSession(c, ModelRequest{"model": "chat", "user": "conversation-1"}, route)
+	require.NotNil(t, s)
+	assert.Equal(t, "server-a", s.modelServer)
+
+	// The session expires TTL after its last request.
+	now = now.Add(time.Minute)
+	c, _ = sessionRequest(http.Header{"X-Conversation-Id": {"conversation-1"}})
+	s = r.lookupSession(c, ModelRequest{"model": "chat"}, route)
+	_, ok = s.pinnedModelServer(route, route.Spec.Rules[0])
+	assert.False(t, ok)
+}
+
+func TestConsistentHashSessionAffinityReplacesRemovedPod(t *testing.T) {
+	r := &Router{sessions: newSessionTable()}
+	route := sessionAffinityRoute(&aiv1alpha1.SessionAffinity{Type: aiv1alpha1.SessionAffinityConsistentHash})
+	pods := []*datastore.PodInfo{buildPodInfo("pod-1", "10.0.0.1"), buildPodInfo("pod-2", "10.0.0.2"), buildPodInfo("pod-3", "10.0.0.3")}
+
+	c, _ := sessionRequest(nil)
+	s := r.lookupSession(c, ModelRequest{"model": "chat", "user": "alice"}, route)
+	require.NotNil(t, s)
+	first := schedule(r, c, s, "server-a", pods)
+
+	var remaining []*datastore.PodInfo
+	for _, pod := range pods {
+		if pod != first {
+			remaining = append(remaining, pod)
+		}
+	}
+	s = r.lookupSession(c, ModelRequest{"model": "chat", "user": "alice"}, route)
+	second := schedule(r, c, s, "server-a", remaining)
+	assert.NotSame(t, first, second)
+
+	s = r.lookupSession(c, ModelRequest{"model": "chat", "user": "alice"}, route)
+	assert.Same(t, second, schedule(r, c, s, "server-a", pods), "the session stays on the pod replacing the removed one")
+}
+
+func TestCookieSessionAffinity(t *testing.T) {
+	r := &Router{sessions: newSessionTable()}
+	route := sessionAffinityRoute(&aiv1alpha1.SessionAffinity{
+		Type:       aiv1alpha1.SessionAffinityCookie,
+		CookieName: "chat-session",
+		TTL:        &v1.Duration{Duration: 10 * time.Minute},
+	})
+	pods := []*datastore.PodInfo{buildPodInfo("pod-1", "10.0.0.1"), buildPodInfo("pod-2", "10.0.0.2")}
+
+	c, w := sessionRequest(nil)
+	s := r.lookupSession(c, ModelRequest{"model": "chat"}, route)
+	require.NotNil(t, s, "the cookie sessions don't need a key")
+	assert.Same(t, pods[1], schedule(r, c, s, "server-b", []*datastore.PodInfo{pods[1], pods[0]}), "a new session is sent to the pod selected by the scheduler")
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "chat-session", cookies[0].Name)
+	assert.Equal(t, 600, cookies[0].MaxAge)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.NotContains(t, cookies[0].Value, "pod-2", "the cookie doesn't reveal the pod")
+
+	c, _ = sessionRequest(http.Header{"Cookie": {cookies[0].String()}})
+	s = r.lookupSession(c, ModelRequest{"model": "chat"}, route)
+	server, ok := s.pinnedModelServer(route, route.Spec.Rules[0])
+	assert.True(t, ok)
+	assert.Equal(t, "server-b", server.Name)
+	assert.Same(t, pods[1], schedule(r, c, s, "server-b", pods))
+
+	// An invalid cookie is ignored.
+	c, _ = sessionRequest(http.Header{"Cookie": {"chat-session=invalid"}})
+	s = r.lookupSession(c, ModelRequest{"model": "chat"}, route)
+	_, ok = s.pinnedModelServer(route, route.Spec.Rules[0])
+	assert.False(t, ok)
+	assert.Same(t, pods[0], schedule(r, c, s, "server-a", pods))
+}
+
+func TestSessionAffinityFollowsMatchedRule(t *testing.T) {
+	r := &Router{sessions: newSessionTable()}
+	route := sessionAffinityRoute(&aiv1alpha1.SessionAffinity{Type: aiv1alpha1.SessionAffinityConsistentHash})
+	premium := &aiv1alpha1.Rule{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "server-c"}}}
+	route.Spec.Rules = append([]*aiv1alpha1.Rule{premium}, route.Spec.Rules...)
+	pods := []*datastore.PodInfo{buildPodInfo("pod-1", "10.0.0.1"), buildPodInfo("pod-2", "10.0.0.2")}
+
+	c, _ := sessionRequest(nil)
+	s := r.lookupSession(c, ModelRequest{"model": "chat", "user": "alice"}, route)
+	schedule(r, c, s, "server-a", pods)
+
+	// The pin is not honored for a request matching a rule which doesn't target the ModelServer of the session
+	s = r.lookupSession(c, ModelRequest{"model": "chat", "user": "alice"}, route)
+	_, ok := s.pinnedModelServer(route, premium)
+	assert.False(t, ok)
+	_, ok = s.pinnedModelServer(route, nil)
+	assert.False(t, ok)
+
+	// The session is pinned again to the ModelServer selected by that rule
+	schedule(r, c, s, "server-c", pods)
+	s = r.lookupSession(c, ModelRequest{"model": "chat", "user": "alice"}, route)
+	server, ok := s.pinnedModelServer(route, premium)
+	assert.True(t, ok)
+	assert.Equal(t, "server-c", server.Name)
+	_, ok = s.pinnedModelServer(route, route.Spec.Rules[1])
+	assert.False(t, ok)
+}
+
+func TestSessionAffinityFollowsRolloutCanary(t *testing.T) {
+	r := &Router{sessions: newSessionTable()}
+	route := sessionAffinityRoute(&aiv1alpha1.SessionAffinity{Type: aiv1alpha1.SessionAffinityConsistentHash})
+	route.Spec.Rules[0].TargetModels = []*aiv1alpha1.TargetModel{{ModelServerName: "server-a"}}
+	route.Spec.Rollout = &aiv1alpha1.Rollout{StableModelServerName: "server-a", CanaryModelServerName: "server-a-v2"}
+	route.Status.Rollout = &aiv1alpha1.RolloutStatus{CanaryModelServerName: "server-a-v2", CanaryWeight: 20}
+	pods := []*datastore.PodInfo{buildPodInfo("pod-1", "10.0.0.1"), buildPodInfo("pod-2", "10.0.0.2")}
+
+	// A session sent to the canary of the rollout in progress stays on it
+	c, _ := sessionRequest(nil)
+	s := r.lookupSession(c, ModelRequest{"model": "chat", "user": "alice"}, route)
+	schedule(r, c, s, "server-a-v2", pods)
+	s = r.lookupSession(c, ModelRequest{"model": "chat", "user": "alice"}, route)
+	server, ok := s.pinnedModelServer(route, route.Spec.Rules[0])
+	assert.True(t, ok)
+	assert.Equal(t, types.NamespacedName{Namespace: "default", Name: "server-a-v2"}, server)
+
+	// but not through a rule which doesn't target the stable ModelServer
+	other := &aiv1alpha1.Rule{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "server-c"}}}
+	_, ok = s.pinnedModelServer(route, other)
+	assert.False(t, ok)
+
+	// nor once the rollout no longer shifts traffic to the canary
+	route.Status.Rollout.CanaryWeight = 0
+	_, ok = s.pinnedModelServer(route, route.Spec.Rules[0])
+	assert.False(t, ok)
+}
+
+func TestSessionAffinityDisabled(t *testing.T) {
+	r := &Router{sessions: newSessionTable()}
+	c, w := sessionRequest(nil)
+	s := r.lookupSession(c, ModelRequest{"model": "chat", "user": "alice"}, sessionAffinityRoute(nil))
+	assert.Nil(t, s)
+	pods := []*datastore.PodInfo{buildPodInfo("pod-1", "10.0.0.1"), buildPodInfo("pod-2", "10.0.0.2")}
+	assert.Same(t, pods[1], schedule(r, c, s, "server-a", []*datastore.PodInfo{pods[1], pods[0]}))
+	assert.Empty(t, w.Result().Cookies())
+}
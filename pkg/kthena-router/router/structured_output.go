@@ -0,0 +1,286 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
+)
+
+const (
+	// structuredOutputKey holds the validation of the responses of a request asking for structured output.
+	structuredOutputKey = "structuredOutput"
+
+	invalidStructuredOutput = "invalid_structured_output"
+
+	// defaultStructuredOutputRetries is the number of retries of the ModelRoutes which don't set it.
+	defaultStructuredOutputRetries = 1
+	// responseSchemaURL is the location the JSON schema of a response_format is compiled at.
+	responseSchemaURL = "response_format.json"
+
+	// Values of the result label of the invalid structured outputs metric.
+	structuredOutputRetried   = "retried"
+	structuredOutputExhausted = "exhausted"
+)
+
+// schemaErrorPrinter prints the violations of the JSON schemas.
+var schemaErrorPrinter = message.NewPrinter(language.English)
+
+// structuredOutput validates the responses of a request asking for structured output, and counts its retries left.
+type structuredOutput struct {
+	// schema is the JSON schema of the response_format, nil when the responses only have to be valid JSON.
+	schema  *jsonschema.Schema
+	retries int
+	// discarded is the usage of the responses discarded as invalid, charged with the usage of the response served.
+	discarded handlers.Usage
+	// reported is set once the usage of a response was reported.
+	reported bool
+}
+
+// invalidStructuredOutputError is the failure of an attempt whose response is not valid against the response_format
+// of its request.
+type invalidStructuredOutputError struct {
+	reason string
+	// usage is the usage of the response discarded.
+	usage handlers.Usage
+}
+
+func (e *invalidStructuredOutputError) Error() string {
+	return "the response of the model is not valid structured output: " + e.reason
+}
+
+// prepareStructuredOutput keeps the validation of the responses of the request when it asks for a JSON object or a
+// JSON schema with its response_format, and the ModelRoute validates structured output. The streaming responses are
+// forwarded as they are generated, they are not validated.
+func prepareStructuredOutput(c *gin.Context, config *v1alpha1.StructuredOutput, modelRequest ModelRequest) {
+	if config == nil || isStreaming(modelRequest) {
+		return
+	}
+	format, ok := modelRequest["response_format"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	validation := &structuredOutput{retries: defaultStructuredOutputRetries}
+	if config.Retries != nil {
+		validation.retries = int(*config.Retries)
+	}
+	switch format["type"] {
+	case "json_object":
+	case "json_schema":
+		schema, err := compileResponseSchema(format)
+		if err != nil {
+			// The schema is left to the model server, the responses still have to be valid JSON.
+			klog.V(4).Infof("JSON schema of request %s not enforced: %v", c.Request.Header.Get("x-request-id"), err)
+		}
+		validation.schema = schema
+	default:
+		return
+	}
+	c.Set(structuredOutputKey, validation)
+}
+
+// compileResponseSchema compiles the JSON schema of a json_schema response_format. The documents the schema
+// references are never loaded, e.g. from the files of the router.
+func compileResponseSchema(format map[string]interface{}) (*jsonschema.Schema, error) {
+	jsonSchema, _ := format["json_schema"].(map[string]interface{})
+	schema, ok := jsonSchema["schema"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("json_schema.schema is not an object")
+	}
+	// The schema is decoded again, keeping the precision of its numbers as the compiler expects.
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.UseLoader(jsonschema.SchemeURLLoader{})
+	if err := compiler.AddResource(responseSchemaURL, doc); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(responseSchemaURL)
+}
+
+// validate checks the outputs of the choices of a chat completion or completion response. The choices without an
+// output, e.g. calling tools or refusing to answer, are not validated, and neither are the responses which are not
+// completions.
+func (s *structuredOutput) validate(response []byte) error {
+	var parsed struct {
+		Choices []struct {
+			Message *struct {
+				Content *string `json:"content"`
+			} `json:"message"`
+			Text *string `json:"text"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(response, &parsed); err != nil {
+		return nil
+	}
+	for i, choice := range parsed.Choices {
+		output := choice.Text
+		if choice.Message != nil {
+			output = choice.Message.Content
+		}
+		if output == nil {
+			continue
+		}
+		instance, err := jsonschema.UnmarshalJSON(strings.NewReader(*output))
+		if err != nil {
+			return &invalidStructuredOutputError{reason: fmt.Sprintf("choice %d is not valid JSON: %v", i, err)}
+		}
+		if s.schema == nil {
+			continue
+		}
+		if err := s.schema.Validate(instance); err != nil {
+			reason := err.Error()
+			var validationErr *jsonschema.ValidationError
+			if errors.As(err, &validationErr) {
+				reason = schemaViolations(validationErr)
+			}
+			return &invalidStructuredOutputError{reason: fmt.Sprintf("choice %d doesn't match the JSON schema: %s", i, reason)}
+		}
+	}
+	return nil
+}
+
+// schemaViolations returns the leaf violations of a validation error on one line, e.g. "at '/age': got string, want
+// integer".
+func schemaViolations(err *jsonschema.ValidationError) string {
+	var violations []string
+	var collect func(err *jsonschema.ValidationError)
+	collect = func(err *jsonschema.ValidationError) {
+		if len(err.Causes) == 0 {
+			var location strings.Builder
+			for _, token := range err.InstanceLocation {
+				location.WriteString("/" + token)
+			}
+			violations = append(violations, fmt.Sprintf("at '%s': %s", location.String(), err.ErrorKind.LocalizedString(schemaErrorPrinter)))
+			return
+		}
+		for _, cause := range err.Causes {
+			collect(cause)
+		}
+	}
+	collect(err)
+	return strings.Join(violations, "; ")
+}
+
+// validateStructuredOutput reads the response of an attempt of a request asking for structured output, and returns
+// an invalidStructuredOutputError if it is not valid. Nothing has been sent downstream at this point, so the request
+// can be sent again. The response body is replaced by its content read.
+func validateStructuredOutput(c *gin.Context, attempt *http.Request, resp *http.Response) error {
+	value, ok := c.Get(structuredOutputKey)
+	if !ok {
+		return nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("read response error: %w", attemptError(attempt.Context(), err))
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	err = value.(*structuredOutput).validate(body)
+	var invalid *invalidStructuredOutputError
+	if errors.As(err, &invalid) {
+		var parsed handlers.OpenAIResponse
+		if json.Unmarshal(body, &parsed) == nil {
+			invalid.usage = parsed.Usage
+		}
+	}
+	return err
+}
+
+// chargeDiscardedOutput adds the usage of a response discarded as invalid structured output to the usage of the
+// request, and charges its output tokens to the limits of the request before it is sent again.
+func chargeDiscardedOutput(c *gin.Context, err *invalidStructuredOutputError) {
+	value, ok := c.Get(structuredOutputKey)
+	if !ok {
+		return
+	}
+	validation := value.(*structuredOutput)
+	validation.discarded.PromptTokens += err.usage.PromptTokens
+	validation.discarded.CompletionTokens += err.usage.CompletionTokens
+	validation.discarded.TotalTokens += err.usage.TotalTokens
+	chargeOutputTokens(c, validation.discarded.CompletionTokens)
+}
+
+// withDiscardedUsage returns the usage callback of a request asking for structured output, which adds the usage of
+// the responses discarded as invalid to the usage of the response served, and a function reporting the usage of the
+// discarded responses when no response reports its usage, e.g. when the retries are exhausted.
+func withDiscardedUsage(c *gin.Context, onUsage func(u handlers.OpenAIResponse)) (func(u handlers.OpenAIResponse), func()) {
+	value, ok := c.Get(structuredOutputKey)
+	if !ok || onUsage == nil {
+		return onUsage, func() {}
+	}
+	validation := value.(*structuredOutput)
+	withDiscarded := func(u handlers.OpenAIResponse) {
+		validation.reported = true
+		u.Usage.PromptTokens += validation.discarded.PromptTokens
+		u.Usage.CompletionTokens += validation.discarded.CompletionTokens
+		u.Usage.TotalTokens += validation.discarded.TotalTokens
+		onUsage(u)
+	}
+	reportDiscarded := func() {
+		if !validation.reported && validation.discarded.TotalTokens > 0 {
+			withDiscarded(handlers.OpenAIResponse{})
+		}
+	}
+	return withDiscarded, reportDiscarded
+}
+
+// retryStructuredOutput reports whether the request whose response is not valid structured output is sent again,
+// and counts the retry.
+func retryStructuredOutput(c *gin.Context) bool {
+	value, ok := c.Get(structuredOutputKey)
+	if !ok {
+		return false
+	}
+	validation := value.(*structuredOutput)
+	if validation.retries <= 0 {
+		return false
+	}
+	validation.retries--
+	return true
+}
+
+// rejectInvalidStructuredOutput answers the request whose responses were not valid structured output until its
+// retries were exhausted.
+func rejectInvalidStructuredOutput(c *gin.Context, err *invalidStructuredOutputError) {
+	accesslog.SetError(c, invalidStructuredOutput, err.Error())
+	c.AbortWithStatusJSON(http.StatusBadGateway, handlers.OpenAIError{
+		Error: handlers.OpenAIErrorDetail{Message: err.Error(), Type: "server_error", Code: invalidStructuredOutput},
+	})
+	c.Set("finishReason", invalidStructuredOutput)
+}
@@ -0,0 +1,330 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/util/sets"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+)
+
+const personSchema = `{
+	"type": "json_schema",
+	"json_schema": {
+		"name": "person",
+		"schema": {
+			"type": "object",
+			"properties": {"name": {"type": "string"}, "age": {"type": "integer", "minimum": 0}},
+			"required": ["name", "age"]
+		}
+	}
+}`
+
+func structuredOutputRequest(t *testing.T, body string) (*gin.Context, ModelRequest) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(body))
+	var modelRequest ModelRequest
+	require.NoError(t, json.Unmarshal([]byte(body), &modelRequest))
+	return c, modelRequest
+}
+
+func TestPrepareStructuredOutput(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config := &aiv1alpha1.StructuredOutput{}
+
+	tests := []struct {
+		name        string
+		config      *aiv1alpha1.StructuredOutput
+		request     string
+		wantChecked bool
+		wantSchema  bool
+		wantRetries int
+	}{
+		{
+			name:    "not validated by the ModelRoute",
+			request: `{"model":"llama","response_format":{"type":"json_object"}}`,
+		},
+		{
+			name:    "no response_format",
+			config:  config,
+			request: `{"model":"llama"}`,
+		},
+		{
+			name:    "text response_format",
+			config:  config,
+			request: `{"model":"llama","response_format":{"type":"text"}}`,
+		},
+		{
+			name:    "streaming",
+			config:  config,
+			request: `{"model":"llama","stream":true,"response_format":{"type":"json_object"}}`,
+		},
+		{
+			name:        "JSON object",
+			config:      config,
+			request:     `{"model":"llama","response_format":{"type":"json_object"}}`,
+			wantChecked: true,
+			wantRetries: defaultStructuredOutputRetries,
+		},
+		{
+			name:        "JSON schema",
+			config:      &aiv1alpha1.StructuredOutput{Retries: ptr.To[int32](3)},
+			request:     `{"model":"llama","response_format":` + personSchema + `}`,
+			wantChecked: true,
+			wantSchema:  true,
+			wantRetries: 3,
+		},
+		{
+			name:        "JSON schema referencing a file",
+			config:      config,
+			request:     `{"model":"llama","response_format":{"type":"json_schema","json_schema":{"schema":{"$ref":"file:///etc/passwd"}}}}`,
+			wantChecked: true,
+			wantRetries: defaultStructuredOutputRetries,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, modelRequest := structuredOutputRequest(t, tt.request)
+			prepareStructuredOutput(c, tt.config, modelRequest)
+			value, ok := c.Get(structuredOutputKey)
+			require.Equal(t, tt.wantChecked, ok)
+			if !ok {
+				return
+			}
+			validation := value.(*structuredOutput)
+			assert.Equal(t, tt.wantSchema, validation.schema != nil)
+			assert.Equal(t, tt.wantRetries, validation.retries)
+		})
+	}
+}
+
+func TestStructuredOutputValidate(t *testing.T) {
+	chatCompletion := func(contents ...string) string {
+		choices := make([]map[string]interface{}, len(contents))
+		for i, content := range contents {
+			choices[i] = map[string]interface{}{"index": i, "message": map[string]interface{}{"role": "assistant", "content": content}}
+		}
+		body, _ := json.Marshal(map[string]interface{}{"choices": choices})
+		return string(body)
+	}
+	schema, err := compileResponseSchema(map[string]interface{}{
+		"json_schema": map[string]interface{}{
+			"schema": map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"name": map[string]interface{}{"type": "string"}, "age": map[string]interface{}{"type": "integer"}},
+				"required":   []interface{}{"name", "age"},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		schema    bool
+		response  string
+		wantError string
+	}{
+		{
+			name:     "valid JSON",
+			response: chatCompletion(`{"answer": 42}`),
+		},
+		{
+			name:      "malformed JSON",
+			response:  chatCompletion(`{"answer": 42`),
+			wantError: "choice 0 is not valid JSON",
+		},
+		{
+			name:      "trailing text",
+			response:  chatCompletion(`{"answer": 42} Hope this helps!`),
+			wantError: "choice 0 is not valid JSON",
+		},
+		{
+			name:      "second choice malformed",
+			response:  chatCompletion(`{}`, "Sure! Here is the JSON"),
+			wantError: "choice 1 is not valid JSON",
+		},
+		{
+			name:     "legacy completion",
+			response: `{"choices":[{"index":0,"text":"[1, 2, 3]"}]}`,
+		},
+		{
+			name:     "tool call without content",
+			response: `{"choices":[{"index":0,"message":{"role":"assistant","content":null,"tool_calls":[{"id":"call_1"}]}}]}`,
+		},
+		{
+			name:     "not a completion",
+			response: `not json`,
+		},
+		{
+			name:     "matching the schema",
+			schema:   true,
+			response: chatCompletion(`{"name": "Ada", "age": 36}`),
+		},
+		{
+			name:      "not matching the schema",
+			schema:    true,
+			response:  chatCompletion(`{"name": "Ada", "age": "36"}`),
+			wantError: "choice 0 doesn't match the JSON schema: at '/age': got string, want integer",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validation := &structuredOutput{}
+			if tt.schema {
+				validation.schema = schema
+			}
+			err := validation.validate([]byte(tt.response))
+			if tt.wantError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var invalid *invalidStructuredOutputError
+			require.True(t, errors.As(err, &invalid))
+			assert.Contains(t, invalid.Error(), tt.wantError)
+		})
+	}
+}
+
+func TestProxyStructuredOutput(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// The model emits malformed JSON on the first requests.
+	var requests, malformed atomic.Int32
+	listener, err := net.Listen("tcp", "0.0.0.0:0")
+	require.NoError(t, err)
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content := `{"name": "Ada", "age": 36}`
+		if requests.Add(1) <= malformed.Load() {
+			content = `{"name": "Ada", "age": 36`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		body, _ := json.Marshal(map[string]interface{}{
+			"choices": []interface{}{map[string]interface{}{"message": map[string]interface{}{"content": content}}},
+			"usage":   map[string]interface{}{"prompt_tokens": 5, "completion_tokens": 10, "total_tokens": 15},
+		})
+		_, _ = w.Write(body)
+	}))
+	backend.Listener.Close()
+	backend.Listener = listener
+	backend.Start()
+	defer backend.Close()
+	port := int32(listener.Addr().(*net.TCPAddr).Port)
+
+	tests := []struct {
+		name         string
+		retries      int32
+		malformed    int32
+		wantRequests int32
+		wantCode     int
+	}{
+		{
+			name:         "valid",
+			retries:      1,
+			wantRequests: 1,
+			wantCode:     http.StatusOK,
+		},
+		{
+			name:         "retried",
+			retries:      2,
+			malformed:    2,
+			wantRequests: 3,
+			wantCode:     http.StatusOK,
+		},
+		{
+			name:         "retries exhausted",
+			retries:      1,
+			malformed:    2,
+			wantRequests: 2,
+			wantCode:     http.StatusBadGateway,
+		},
+		{
+			name:         "not retried",
+			malformed:    1,
+			wantRequests: 1,
+			wantCode:     http.StatusBadGateway,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.Store(0)
+			malformed.Store(tt.malformed)
+			store := datastore.New()
+			modelServer := &aiv1alpha1.ModelServer{
+				ObjectMeta: v1.ObjectMeta{Name: "ms-1", Namespace: "default"},
+				Spec:       aiv1alpha1.ModelServerSpec{WorkloadPort: aiv1alpha1.WorkloadPort{Port: port}},
+			}
+			store.AddOrUpdateModelServer(modelServer, sets.New[types.NamespacedName]())
+			r := NewRouter(store, "")
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			body := `{"model":"test-model","response_format":{"type":"json_object"}}`
+			c.Request, _ = http.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(body))
+			var modelRequest ModelRequest
+			require.NoError(t, json.Unmarshal([]byte(body), &modelRequest))
+			prepareStructuredOutput(c, &aiv1alpha1.StructuredOutput{Retries: ptr.To(tt.retries)}, modelRequest)
+			ctx := &framework.Context{
+				Model:           "test-model",
+				ModelServerName: types.NamespacedName{Namespace: "default", Name: "ms-1"},
+				BestPods:        []*datastore.PodInfo{buildPodInfo("pod-1", "127.0.0.1")},
+			}
+
+			// The usage of the responses discarded is reported with the usage of the response served, or on its own
+			// when the retries are exhausted.
+			var usages []handlers.Usage
+			err := r.proxy(c, c.Request, ctx, false, port, func(u handlers.OpenAIResponse) {
+				usages = append(usages, u.Usage)
+			})
+			assert.Equal(t, tt.wantRequests, requests.Load())
+			n := int(tt.wantRequests)
+			assert.Equal(t, []handlers.Usage{{PromptTokens: 5 * n, CompletionTokens: 10 * n, TotalTokens: 15 * n}}, usages)
+			if tt.wantCode == http.StatusOK {
+				require.NoError(t, err)
+				assert.Contains(t, w.Body.String(), `\"age\": 36}`)
+				return
+			}
+			var failed *backendFailedError
+			require.True(t, errors.As(err, &failed))
+			assert.Equal(t, tt.wantCode, failed.status)
+			require.False(t, c.Writer.Written())
+
+			abortProxyFailure(c, err)
+			assert.Equal(t, http.StatusBadGateway, w.Code)
+			var openAIError handlers.OpenAIError
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &openAIError))
+			assert.Equal(t, invalidStructuredOutput, openAIError.Error.Code)
+			assert.Equal(t, "the response of the model is not valid structured output: choice 0 is not valid JSON: unexpected EOF", openAIError.Error.Message)
+		})
+	}
+}
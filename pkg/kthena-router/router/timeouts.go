@@ -0,0 +1,146 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+)
+
+const (
+	// routeTimeoutsKey is the gin context key of the timeouts of the ModelRoute matched by a request.
+	routeTimeoutsKey = "routeTimeouts"
+
+	// requestTimeout is the error type and finish reason of requests aborted by the request timeout of their ModelRoute.
+	requestTimeout = "request_timeout"
+
+	// Values of the timeout label of the request timeouts metric.
+	timeoutConnect    = "connect"
+	timeoutFirstToken = "first_token"
+	timeoutRequest    = "request"
+)
+
+var (
+	// errRequestTimeout is the cause of the context of requests exceeding the request timeout of their ModelRoute.
+	errRequestTimeout = errors.New("request timed out")
+	// errConnectTimeout and errFirstTokenTimeout abort an attempt to send the request to a pod, the request is
+	// retried on the next pod selected for it.
+	errConnectTimeout    = errors.New("timed out connecting to the model server")
+	errFirstTokenTimeout = errors.New("timed out waiting for the first token")
+)
+
+// applyRouteTimeouts bounds the request of c by the request timeout of the ModelRoute and keeps the other timeouts
+// for the attempts on the pods. The returned function must be called once the request completes.
+func applyRouteTimeouts(c *gin.Context, timeouts *v1alpha1.Timeouts) func() {
+	if timeouts == nil {
+		return func() {}
+	}
+	c.Set(routeTimeoutsKey, timeouts)
+	d := duration(timeouts.Request)
+	if d <= 0 {
+		return func() {}
+	}
+	ctx, cancel := context.WithTimeoutCause(c.Request.Context(), d, errRequestTimeout)
+	c.Request = c.Request.WithContext(ctx)
+	return cancel
+}
+
+// withAttemptTimeouts bounds an attempt to send req to a pod by the connect timeout of the ModelRoute, and by its
+// first token timeout for a streaming request. firstToken must be called when the first event of the response is
+// received and done once the attempt completes.
+func withAttemptTimeouts(c *gin.Context, req *http.Request, stream bool) (attempt *http.Request, firstToken, done func()) {
+	var timeouts *v1alpha1.Timeouts
+	if value, ok := c.Get(routeTimeoutsKey); ok {
+		timeouts = value.(*v1alpha1.Timeouts)
+	}
+	connect, first := time.Duration(0), time.Duration(0)
+	if timeouts != nil {
+		connect = duration(timeouts.Connect)
+		if stream {
+			first = duration(timeouts.FirstToken)
+		}
+	}
+	if connect <= 0 && first <= 0 {
+		return req, func() {}, func() {}
+	}
+
+	ctx, cancel := context.WithCancelCause(req.Context())
+	var timers []*time.Timer
+	if connect > 0 {
+		timer := time.AfterFunc(connect, func() { cancel(errConnectTimeout) })
+		timers = append(timers, timer)
+		ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+			GotConn: func(httptrace.GotConnInfo) { timer.Stop() },
+		})
+	}
+	firstToken = func() {}
+	if first > 0 {
+		timer := time.AfterFunc(first, func() { cancel(errFirstTokenTimeout) })
+		timers = append(timers, timer)
+		firstToken = func() { timer.Stop() }
+	}
+	return req.WithContext(ctx), firstToken, func() {
+		for _, timer := range timers {
+			timer.Stop()
+		}
+		cancel(nil)
+	}
+}
+
+// hasFirstTokenTimeout reports whether the streamed responses of the request of c are bounded by a first token
+// timeout. No heartbeat is sent before their first event, as it would send the response downstream and the request
+// couldn't be retried on another pod after the timeout anymore.
+func hasFirstTokenTimeout(c *gin.Context) bool {
+	value, ok := c.Get(routeTimeoutsKey)
+	return ok && duration(value.(*v1alpha1.Timeouts).FirstToken) > 0
+}
+
+// attemptError returns the timeout which aborted the attempt made with ctx instead of err, if any.
+func attemptError(ctx context.Context, err error) error {
+	if cause := context.Cause(ctx); isAttemptTimeout(cause) {
+		return cause
+	}
+	return err
+}
+
+// isAttemptTimeout reports whether err is a timeout aborting an attempt on a pod.
+func isAttemptTimeout(err error) bool {
+	return errors.Is(err, errConnectTimeout) || errors.Is(err, errFirstTokenTimeout)
+}
+
+// attemptTimeoutLabel returns the timeout label value of the attempt timeout err.
+func attemptTimeoutLabel(err error) string {
+	if errors.Is(err, errConnectTimeout) {
+		return timeoutConnect
+	}
+	return timeoutFirstToken
+}
+
+func duration(d *metav1.Duration) time.Duration {
+	if d == nil {
+		return 0
+	}
+	return d.Duration
+}
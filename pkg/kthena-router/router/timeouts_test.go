@@ -0,0 +1,222 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
+)
+
+func TestRouter_HandlerFunc_Timeouts(t *testing.T) {
+	// The model server answers after a delay, streamed responses send their headers first.
+	const delay = 200 * time.Millisecond
+	router, store, backend := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stream := bytes.Contains(readBody(r), []byte(`"stream":true`))
+		if stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		if stream {
+			fmt.Fprint(w, "data: {\"id\":\"response-id\"}\n\ndata: [DONE]\n\n")
+			return
+		}
+		fmt.Fprint(w, `{"id":"response-id"}`)
+	}))
+	defer backend.Close()
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: "ms-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+			InferenceEngine: "vLLM",
+		},
+	}
+	podName := types.NamespacedName{Name: "pod-1", Namespace: "default"}
+	store.AddOrUpdateModelServer(modelServer, sets.New(podName))
+	store.AddOrUpdatePod(&corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: podName.Name, Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}, []*aiv1alpha1.ModelServer{modelServer})
+
+	short := &v1.Duration{Duration: 50 * time.Millisecond}
+	tests := []struct {
+		name     string
+		timeouts *aiv1alpha1.Timeouts
+		stream   bool
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "no timeouts",
+			wantCode: http.StatusOK,
+			wantBody: "response-id",
+		},
+		{
+			name:     "request timeout",
+			timeouts: &aiv1alpha1.Timeouts{Request: short},
+			wantCode: http.StatusGatewayTimeout,
+			wantBody: requestTimeout,
+		},
+		{
+			name:     "first token timeout",
+			timeouts: &aiv1alpha1.Timeouts{FirstToken: short},
+			stream:   true,
+			wantCode: http.StatusGatewayTimeout,
+			wantBody: "request to all pods timed out",
+		},
+		{
+			name:     "first token timeout ignored by non-streaming requests",
+			timeouts: &aiv1alpha1.Timeouts{FirstToken: short},
+			wantCode: http.StatusOK,
+			wantBody: "response-id",
+		},
+		{
+			name:     "connect timeout doesn't bound the response",
+			timeouts: &aiv1alpha1.Timeouts{Connect: short},
+			stream:   true,
+			wantCode: http.StatusOK,
+			wantBody: "response-id",
+		},
+		{
+			name:     "timeouts not reached",
+			timeouts: &aiv1alpha1.Timeouts{Connect: &v1.Duration{Duration: time.Second}, FirstToken: &v1.Duration{Duration: time.Second}, Request: &v1.Duration{Duration: time.Second}},
+			stream:   true,
+			wantCode: http.StatusOK,
+			wantBody: "response-id",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+				ObjectMeta: v1.ObjectMeta{Name: "mr-1", Namespace: "default"},
+				Spec: aiv1alpha1.ModelRouteSpec{
+					ModelName: "test-model",
+					Rules: []*aiv1alpha1.Rule{
+						{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "ms-1"}}},
+					},
+					Timeouts: tt.timeouts,
+				},
+			})
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			body := fmt.Sprintf(`{"model": "test-model", "prompt": "hello", "stream": %t}`, tt.stream)
+			c.Request, _ = http.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(body))
+			router.HandlerFunc()(c)
+
+			require.Equal(t, tt.wantCode, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestRouter_HandlerFunc_FirstTokenTimeoutWithHeartbeats(t *testing.T) {
+	// The heartbeats are due long before the first token timeout.
+	defer handlers.SetStreamHeartbeatInterval(handlers.DefaultStreamHeartbeatInterval)
+	handlers.SetStreamHeartbeatInterval(10 * time.Millisecond)
+	router, store, backend := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		select {
+		case <-time.After(time.Second):
+			fmt.Fprint(w, "data: {\"id\":\"response-id\"}\n\ndata: [DONE]\n\n")
+		case <-r.Context().Done():
+		}
+	}))
+	defer backend.Close()
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: "ms-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+			InferenceEngine: "vLLM",
+		},
+	}
+	podName := types.NamespacedName{Name: "pod-1", Namespace: "default"}
+	store.AddOrUpdateModelServer(modelServer, sets.New(podName))
+	store.AddOrUpdatePod(&corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: podName.Name, Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}, []*aiv1alpha1.ModelServer{modelServer})
+	store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+		ObjectMeta: v1.ObjectMeta{Name: "mr-1", Namespace: "default"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "test-model",
+			Rules:     []*aiv1alpha1.Rule{{TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "ms-1"}}}},
+			Timeouts:  &aiv1alpha1.Timeouts{FirstToken: &v1.Duration{Duration: 100 * time.Millisecond}},
+		},
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(`{"model": "test-model", "prompt": "hello", "stream": true}`))
+	router.HandlerFunc()(c)
+
+	// No heartbeat started the response before the timeout, which is answered with a proper error.
+	require.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.NotContains(t, w.Body.String(), "keep-alive")
+	assert.Contains(t, w.Body.String(), "request to all pods timed out")
+}
+
+func TestAbortProxyFailure_StartedStream(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/v1/chat/completions", nil)
+	c.Header("Content-Type", "text/event-stream")
+	c.Status(http.StatusOK)
+	_, _ = c.Writer.Write([]byte(": keep-alive\n\n"))
+
+	abortProxyFailure(c, &backendFailedError{status: http.StatusGatewayTimeout, message: "request to all pods timed out", err: errFirstTokenTimeout})
+
+	// The stream is ended with an error event instead of a JSON body.
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, ": keep-alive\n\nevent: error\ndata: {\"error\":{\"message\":\"request to all pods timed out\",\"type\":\"server_error\"}}\n\n", w.Body.String())
+}
+
+func readBody(r *http.Request) []byte {
+	var buf bytes.Buffer
+	_, _ = buf.ReadFrom(r.Body)
+	return bytes.ReplaceAll(buf.Bytes(), []byte(" "), nil)
+}
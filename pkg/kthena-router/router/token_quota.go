@@ -0,0 +1,130 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/client-go/clientset/versioned"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/filters/ratelimit"
+)
+
+const (
+	// tokenQuotaUsageKey is the context key of the tokens charged for the request to the TokenQuotas of its ModelRoute.
+	tokenQuotaUsageKey = "tokenQuotaUsage"
+
+	tokenQuotaExceeded = "token_quota_exceeded"
+
+	// tokenQuotaFlushInterval is the interval between two flushes of the tokens consumed by the router replica to the
+	// status of the TokenQuotas, the tokens not flushed yet are lost if the router is killed.
+	tokenQuotaFlushInterval = 10 * time.Second
+	// tokenQuotaFlushTimeout bounds the last flush when the router shuts down.
+	tokenQuotaFlushTimeout = 5 * time.Second
+)
+
+// admitTokenQuotas admits the request if the TokenQuotas selecting its ModelRoute and its consumer are not exhausted
+// for the current period, charging its input tokens to them. The request is rejected otherwise.
+func (r *Router) admitTokenQuotas(c *gin.Context, modelName string, inputTokens int) (*ratelimit.TokenQuotaUsage, bool) {
+	_, _, modelRoute, err := r.store.MatchModelServer(modelName, c.Request, c.GetStringSlice(GatewayKey))
+	if err != nil || modelRoute == nil {
+		// The request without a ModelRoute is rejected by the load balancing.
+		return nil, true
+	}
+	quotas := r.store.GetTokenQuotas(modelRoute.Namespace)
+	usage, err := r.tokenQuotas.Admit(quotas, modelRoute, c.GetString(common.UserIdKey), inputTokens)
+	var exceeded *ratelimit.TokenQuotaExceededError
+	if !errors.As(err, &exceeded) {
+		return usage, true
+	}
+	accesslog.SetError(c, tokenQuotaExceeded, exceeded.Error())
+	r.metrics.RecordTokenQuotaExceeded(exceeded.Quota)
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, exceeded.Error())
+	c.Set("finishReason", tokenQuotaExceeded)
+	return nil, false
+}
+
+// releaseTokenQuotaUsage returns the input tokens of a failed request to its TokenQuotas.
+func releaseTokenQuotaUsage(c *gin.Context, usage *ratelimit.TokenQuotaUsage) {
+	if status := c.Writer.Status(); status < 200 || status >= 300 {
+		usage.Cancel()
+	}
+}
+
+// StartTokenQuotaFlush adds the tokens consumed by the router replica to the status of the TokenQuotas in the
+// background, and a last time when ctx is done, so that they survive the restarts of the router.
+func (r *Router) StartTokenQuotaFlush(ctx context.Context, client versioned.Interface) {
+	go func() {
+		ticker := time.NewTicker(tokenQuotaFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.flushTokenQuotas(ctx, client)
+			case <-ctx.Done():
+				flushCtx, cancel := context.WithTimeout(context.Background(), tokenQuotaFlushTimeout)
+				defer cancel()
+				r.flushTokenQuotas(flushCtx, client)
+				return
+			}
+		}
+	}()
+}
+
+// flushTokenQuotas adds the tokens consumed by the router replica since its last flush to the status of the
+// TokenQuotas, with the tokens remaining in the period. The status of the quotas is also reset when a new period
+// starts. The tokens of a quota whose status can't be updated are kept for the next flush.
+func (r *Router) flushTokenQuotas(ctx context.Context, client versioned.Interface) {
+	now := time.Now()
+	for _, quota := range r.store.GetTokenQuotas("") {
+		name := types.NamespacedName{Namespace: quota.Namespace, Name: quota.Name}
+		periodStart, tokens := r.tokenQuotas.Pending(name)
+		if tokens == 0 && !ratelimit.UpdateTokenQuotaStatus(quota.DeepCopy(), periodStart, 0, now) {
+			continue
+		}
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			current, err := client.NetworkingV1alpha1().TokenQuotas(name.Namespace).Get(ctx, name.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			if !ratelimit.UpdateTokenQuotaStatus(current, periodStart, tokens, now) {
+				return nil
+			}
+			_, err = client.NetworkingV1alpha1().TokenQuotas(name.Namespace).UpdateStatus(ctx, current, metav1.UpdateOptions{})
+			return err
+		})
+		switch {
+		case apierrors.IsNotFound(err):
+			r.tokenQuotas.Forget(name)
+		case err != nil:
+			klog.Errorf("failed to update the status of token quota %s, its tokens are kept for the next flush: %v", name, err)
+		default:
+			r.tokenQuotas.Flushed(name, periodStart, tokens)
+		}
+	}
+}
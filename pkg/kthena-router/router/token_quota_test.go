@@ -0,0 +1,76 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/volcano-sh/kthena/client-go/clientset/versioned/fake"
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+)
+
+func TestRouter_FlushTokenQuotas(t *testing.T) {
+	ctx := context.Background()
+	quota := &aiv1alpha1.TokenQuota{
+		ObjectMeta: v1.ObjectMeta{Namespace: "default", Name: "search"},
+		Spec:       aiv1alpha1.TokenQuotaSpec{Tokens: 1000, Period: aiv1alpha1.Month},
+	}
+	client := fake.NewSimpleClientset(quota)
+	store := datastore.New()
+	require.NoError(t, store.AddOrUpdateTokenQuota(quota))
+	r := NewRouter(store, "")
+	route := &aiv1alpha1.ModelRoute{ObjectMeta: v1.ObjectMeta{Namespace: "default", Name: "llama"}}
+
+	// The status of a new quota reports its tokens before any request.
+	r.flushTokenQuotas(ctx, client)
+	current, err := client.NetworkingV1alpha1().TokenQuotas("default").Get(ctx, "search", v1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotNil(t, current.Status.PeriodStart)
+	assert.Equal(t, int64(1000), current.Status.RemainingTokens)
+
+	// The tokens consumed by the replica are added to the status, a second replica adds its own.
+	for range 2 {
+		usage, err := r.tokenQuotas.Admit([]*aiv1alpha1.TokenQuota{current}, route, "", 100)
+		require.NoError(t, err)
+		usage.Settle(50)
+		r.flushTokenQuotas(ctx, client)
+	}
+	current, err = client.NetworkingV1alpha1().TokenQuotas("default").Get(ctx, "search", v1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(300), current.Status.ConsumedTokens)
+	assert.Equal(t, int64(700), current.Status.RemainingTokens)
+	_, pending := r.tokenQuotas.Pending(types.NamespacedName{Namespace: "default", Name: "search"})
+	assert.Zero(t, pending)
+
+	// The tokens of a deleted quota are dropped.
+	usage, err := r.tokenQuotas.Admit([]*aiv1alpha1.TokenQuota{current}, route, "", 100)
+	require.NoError(t, err)
+	usage.Settle(0)
+	require.NoError(t, store.DeleteTokenQuota(types.NamespacedName{Namespace: "default", Name: "search"}))
+	assert.Eventually(t, func() bool {
+		_, pending := r.tokenQuotas.Pending(types.NamespacedName{Namespace: "default", Name: "search"})
+		return pending == 0
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,53 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
+)
+
+const (
+	// defaultMaxVisionPayloadSizeMB is the body size limit of the requests with images.
+	defaultMaxVisionPayloadSizeMB = 20
+	// requestBodySizeKey is the context key of the size in bytes of the body of a request.
+	requestBodySizeKey = "requestBodySize"
+
+	payloadTooLarge = "payload_too_large"
+)
+
+// admitImages checks that the body of a chat completion request with images fits in the payload size limit of the
+// requests with images. It aborts the request and returns false if it is rejected.
+func (r *Router) admitImages(c *gin.Context, images []common.Image) bool {
+	if len(images) == 0 {
+		return true
+	}
+	if size := c.GetInt(requestBodySizeKey); int64(size) > r.maxVisionPayloadSize {
+		message := fmt.Sprintf("Maximum payload size of the requests with images (%d bytes) exceeded.", r.maxVisionPayloadSize)
+		accesslog.SetError(c, payloadTooLarge, message)
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, handlers.NewInvalidRequestError(message, "messages", payloadTooLarge))
+		c.Set("finishReason", payloadTooLarge)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/handlers"
+)
+
+func TestRouter_HandlerFunc_Vision(t *testing.T) {
+	router, store, backend := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"response-id"}`)
+	}))
+	defer backend.Close()
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	var modelServers []*aiv1alpha1.ModelServer
+	for name, capabilities := range map[string][]aiv1alpha1.Capability{
+		"text":   nil,
+		"vision": {aiv1alpha1.CapabilityVision},
+	} {
+		modelServer := &aiv1alpha1.ModelServer{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: aiv1alpha1.ModelServerSpec{
+				WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+				InferenceEngine: "vLLM",
+				Capabilities:    capabilities,
+			},
+		}
+		require.NoError(t, store.AddOrUpdateModelServer(modelServer, sets.New(types.NamespacedName{Name: "pod-1", Namespace: "default"})))
+		modelServers = append(modelServers, modelServer)
+	}
+	require.NoError(t, store.AddOrUpdatePod(&corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "pod-1", Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}, modelServers))
+	for model, targets := range map[string][]string{"llava": {"text", "vision"}, "llama": {"text"}} {
+		rule := &aiv1alpha1.Rule{}
+		for _, target := range targets {
+			rule.TargetModels = append(rule.TargetModels, &aiv1alpha1.TargetModel{ModelServerName: target})
+		}
+		require.NoError(t, store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+			ObjectMeta: v1.ObjectMeta{Name: model, Namespace: "default"},
+			Spec:       aiv1alpha1.ModelRouteSpec{ModelName: model, Rules: []*aiv1alpha1.Rule{rule}},
+		}))
+	}
+
+	send := func(model string) (*httptest.ResponseRecorder, *gin.Context) {
+		body := fmt.Sprintf(`{"model": %q, "messages": [{"role": "user", "content": [
+			{"type": "text", "text": "What is in this image?"},
+			{"type": "image_url", "image_url": {"url": "https://example.com/cat.jpg"}}
+		]}]}`, model)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/v1/chat/completions", bytes.NewBufferString(body))
+		router.HandlerFunc()(c)
+		return w, c
+	}
+
+	t.Run("images routed to the vision model server", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			w, c := send("llava")
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "default/vision", w.Header().Get(modelServerHeader))
+			// The image of unknown size is counted as a 1024x1024 image with the openai rule.
+			assert.Greater(t, c.GetInt("inputTokens"), 765)
+		}
+	})
+
+	t.Run("model without vision model server", func(t *testing.T) {
+		w, _ := send("llama")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var resp handlers.OpenAIError
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, unsupportedCapability, resp.Error.Code)
+		assert.Equal(t, "The model llama doesn't support vision.", resp.Error.Message)
+	})
+
+	t.Run("payload too large", func(t *testing.T) {
+		maxVisionPayloadSize := router.maxVisionPayloadSize
+		router.maxVisionPayloadSize = 64
+		defer func() { router.maxVisionPayloadSize = maxVisionPayloadSize }()
+		w, _ := send("llava")
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		var resp handlers.OpenAIError
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, payloadTooLarge, resp.Error.Code)
+	})
+}
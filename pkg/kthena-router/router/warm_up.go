@@ -0,0 +1,155 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/utils"
+)
+
+const (
+	defaultWarmUpPrompt    = "Hello"
+	defaultWarmUpMaxTokens = 1
+	defaultWarmUpTimeout   = time.Minute
+
+	warmUpSucceeded = "succeeded"
+	warmUpFailed    = "failed"
+	warmUpTimedOut  = "timeout"
+)
+
+// onPodAdded warms up the new pods of the model servers with a warm-up request.
+func (r *Router) onPodAdded(data datastore.EventData) {
+	if data.EventType != datastore.EventAdd {
+		return
+	}
+	r.warmUpPod(data.Pod)
+}
+
+// warmUpPod sends the warm-up request of its model server to the pod, the pod is routed requests once it completes,
+// fails or times out.
+func (r *Router) warmUpPod(podName types.NamespacedName) {
+	pod := r.store.GetPodInfo(podName)
+	if pod == nil || !pod.IsWarmingUp() {
+		return
+	}
+	defer pod.FinishWarmUp()
+
+	modelServer := r.warmUpModelServer(pod)
+	if modelServer == nil {
+		return
+	}
+	warmUp := modelServer.Spec.WarmUp
+	timeout := defaultWarmUpTimeout
+	if warmUp.Timeout != nil {
+		timeout = warmUp.Timeout.Duration
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := r.sendWarmUpRequest(ctx, pod, modelServer)
+	duration := time.Since(start)
+	result := warmUpSucceeded
+	switch {
+	case err != nil && ctx.Err() != nil:
+		result = warmUpTimedOut
+	case err != nil:
+		result = warmUpFailed
+	}
+	if err != nil {
+		klog.Warningf("warm-up of pod %v failed after %v, routing requests to it anyway: %v", podName, duration, err)
+	} else {
+		klog.V(4).Infof("pod %v warmed up in %v", podName, duration)
+	}
+	r.metrics.RecordPodWarmUp(utils.GetNamespaceName(modelServer).String(), result, duration)
+}
+
+// warmUpModelServer returns the model server of the pod whose warm-up request is sent to it, nil if none of its
+// model servers warms up its pods.
+func (r *Router) warmUpModelServer(pod *datastore.PodInfo) *v1alpha1.ModelServer {
+	names := pod.GetModelServersList()
+	slices.SortFunc(names, func(a, b types.NamespacedName) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	for _, name := range names {
+		if modelServer := r.store.GetModelServer(name); modelServer != nil && modelServer.Spec.WarmUp != nil {
+			return modelServer
+		}
+	}
+	return nil
+}
+
+// sendWarmUpRequest sends a completion request of a few tokens to the pod and discards its response.
+func (r *Router) sendWarmUpRequest(ctx context.Context, pod *datastore.PodInfo, modelServer *v1alpha1.ModelServer) error {
+	var model string
+	if modelServer.Spec.Model != nil {
+		model = *modelServer.Spec.Model
+	} else if models := pod.GetModelsList(); len(models) != 0 {
+		slices.Sort(models)
+		model = models[0]
+	} else {
+		return errors.New("the model served by the pod is unknown")
+	}
+	warmUp := modelServer.Spec.WarmUp
+	prompt := warmUp.Prompt
+	if prompt == "" {
+		prompt = defaultWarmUpPrompt
+	}
+	maxTokens := int32(defaultWarmUpMaxTokens)
+	if warmUp.MaxTokens != nil {
+		maxTokens = *warmUp.MaxTokens
+	}
+	body, err := json.Marshal(map[string]any{
+		"model":      model,
+		"prompt":     prompt,
+		"max_tokens": maxTokens,
+	})
+	if err != nil {
+		return err
+	}
+
+	backend, err := r.newUpstream(modelServer, modelServer.Spec.WorkloadPort.Port)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/v1/completions", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := doRequest(req, pod.Pod.Status.PodIP, backend)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(io.Discard, resp.Body)
+	return err
+}
@@ -0,0 +1,157 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/utils"
+)
+
+func TestRouter_WarmUpPod(t *testing.T) {
+	warmUps := make(chan ModelRequest, 10)
+	release := make(chan struct{})
+	router, store, backend := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/completions", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		var reqBody ModelRequest
+		_ = json.Unmarshal(body, &reqBody)
+		warmUps <- reqBody
+		<-release
+		w.Write([]byte(`{"id":"warm-up"}`))
+	}))
+	defer backend.Close()
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	modelServerName := types.NamespacedName{Name: "ms", Namespace: "default"}
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: modelServerName.Name, Namespace: modelServerName.Namespace},
+		Spec: aiv1alpha1.ModelServerSpec{
+			Model:           ptr.To("llama"),
+			WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+			InferenceEngine: "vLLM",
+			WarmUp:          &aiv1alpha1.WarmUp{Prompt: "warm up", MaxTokens: ptr.To(int32(4))},
+		},
+	}
+	podNames := sets.New[types.NamespacedName]()
+	addPod := func(name string) *datastore.PodInfo {
+		podName := types.NamespacedName{Name: name, Namespace: "default"}
+		podNames.Insert(podName)
+		store.AddOrUpdateModelServer(modelServer, podNames)
+		store.AddOrUpdatePod(&corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "default"},
+			Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+		}, []*aiv1alpha1.ModelServer{modelServer})
+		return store.GetPodInfo(podName)
+	}
+	waitWarmUp := func() {
+		select {
+		case reqBody := <-warmUps:
+			assert.Equal(t, "llama", reqBody["model"])
+			assert.Equal(t, "warm up", reqBody["prompt"])
+			assert.Equal(t, float64(4), reqBody["max_tokens"])
+		case <-time.After(5 * time.Second):
+			t.Fatal("warm-up request not sent")
+		}
+	}
+
+	first := addPod("pod-1")
+	waitWarmUp()
+	assert.True(t, first.IsWarmingUp())
+	// The pod is routed requests anyway when all the pods are warming up.
+	pods, _, err := router.getPodsAndServer(modelServerName)
+	require.NoError(t, err)
+	assert.Len(t, pods, 1)
+	release <- struct{}{}
+	require.Eventually(t, func() bool { return !first.IsWarmingUp() }, 5*time.Second, 10*time.Millisecond)
+
+	second := addPod("pod-2")
+	waitWarmUp()
+	assert.True(t, second.IsWarmingUp())
+	pods, _, err = router.getPodsAndServer(modelServerName)
+	require.NoError(t, err)
+	require.Len(t, pods, 1)
+	assert.Equal(t, "pod-1", pods[0].Pod.Name)
+	release <- struct{}{}
+	require.Eventually(t, func() bool { return !second.IsWarmingUp() }, 5*time.Second, 10*time.Millisecond)
+	pods, _, err = router.getPodsAndServer(modelServerName)
+	require.NoError(t, err)
+	assert.Len(t, pods, 2)
+}
+
+func TestRouter_WarmUpPodTimeout(t *testing.T) {
+	release := make(chan struct{})
+	router, store, backend := setupTestRouter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer backend.Close()
+	defer close(release)
+
+	backendURL, _ := url.Parse(backend.URL)
+	backendPort, _ := strconv.Atoi(backendURL.Port())
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: v1.ObjectMeta{Name: "ms-timeout", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			Model:           ptr.To("llama"),
+			WorkloadPort:    aiv1alpha1.WorkloadPort{Port: int32(backendPort)},
+			InferenceEngine: "vLLM",
+			WarmUp:          &aiv1alpha1.WarmUp{Timeout: &v1.Duration{Duration: 50 * time.Millisecond}},
+		},
+	}
+	timedOutWarmUps := func() uint64 {
+		var warmUps dto.Metric
+		observer := metrics.DefaultMetrics.PodWarmUpDuration.WithLabelValues("default/ms-timeout", warmUpTimedOut)
+		require.NoError(t, observer.(prometheus.Metric).Write(&warmUps))
+		return warmUps.GetHistogram().GetSampleCount()
+	}
+	timedOut := timedOutWarmUps()
+	podName := types.NamespacedName{Name: "pod-1", Namespace: "default"}
+	store.AddOrUpdateModelServer(modelServer, sets.New(podName))
+	store.AddOrUpdatePod(&corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: podName.Name, Namespace: "default"},
+		Status:     corev1.PodStatus{PodIP: backendURL.Hostname(), Phase: corev1.PodRunning},
+	}, []*aiv1alpha1.ModelServer{modelServer})
+
+	pod := store.GetPodInfo(podName)
+	require.NotNil(t, pod)
+	require.Eventually(t, func() bool { return !pod.IsWarmingUp() }, 5*time.Second, 10*time.Millisecond)
+	// The pod is routed requests once the warm-up timed out.
+	pods, _, err := router.getPodsAndServer(utils.GetNamespaceName(modelServer))
+	require.NoError(t, err)
+	assert.Len(t, pods, 1)
+	assert.Equal(t, timedOut+1, timedOutWarmUps())
+}
@@ -66,7 +66,7 @@ func (r *PluginRegistry) getFilterPlugin(name string) (FilterPluginBuilder, bool
 func registerDefaultPlugins(registry *PluginRegistry) {
 	// scorePlugin
 	registry.registerScorePlugin(plugins.GPUCacheUsagePluginName, func(args runtime.RawExtension) framework.ScorePlugin {
-		return plugins.NewGPUCacheUsage()
+		return plugins.NewGPUCacheUsage(args)
 	})
 	registry.registerScorePlugin(plugins.LeastLatencyPluginName, func(args runtime.RawExtension) framework.ScorePlugin {
 		return plugins.NewLeastLatency(args)
@@ -89,6 +89,9 @@ func registerDefaultPlugins(registry *PluginRegistry) {
 	registry.registerFilterPlugin(plugins.LeastRequestPluginName, func(args runtime.RawExtension) framework.FilterPlugin {
 		return plugins.NewLeastRequest(args)
 	})
+	registry.registerFilterPlugin(plugins.GPUCacheUsagePluginName, func(args runtime.RawExtension) framework.FilterPlugin {
+		return plugins.NewGPUCacheUsage(args)
+	})
 	registry.registerFilterPlugin(plugins.LoraAffinityPluginName, func(args runtime.RawExtension) framework.FilterPlugin {
 		return plugins.NewLoraAffinity()
 	})
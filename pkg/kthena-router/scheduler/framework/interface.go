@@ -44,6 +44,16 @@ type Context struct {
 
 	// MetricsRecorder for recording scheduler plugin metrics
 	MetricsRecorder *metrics.RequestMetricsRecorder
+
+	// Experiment is the experiment the request is opted in, empty if none.
+	Experiment string
+
+	// LoadBalancingPolicy is the load balancing policy of the model server, empty to use the score plugins.
+	LoadBalancingPolicy aiv1alpha1.LoadBalancingPolicy
+	// SlowStart is the warm-up of the pods of the model server, nil if they get a full share of the requests at once.
+	SlowStart *aiv1alpha1.SlowStart
+	// SessionID identifies the conversation the request belongs to, empty if the client didn't set it.
+	SessionID string
 }
 
 type ScorePlugin interface {
@@ -59,6 +69,14 @@ type FilterPlugin interface {
 	Filter(ctx *Context, pods []*datastore.PodInfo) []*datastore.PodInfo
 }
 
+// LoadBalancer is a load balancing policy selecting the pods a request is sent to in place of the score plugins.
+type LoadBalancer interface {
+	Name() string
+	// Pick returns up to n of the pods that passed the filter plugins, best first. It returns nothing if it can't
+	// balance the request, which is then scheduled with the score plugins.
+	Pick(ctx *Context, pods []*datastore.PodInfo, n int) []*datastore.PodInfo
+}
+
 // PostHook is an interface that is executed after the scheduling is complete.
 type PostScheduleHook interface {
 	Name() string
@@ -28,6 +28,33 @@ import (
 type RouterConfiguration struct {
 	Scheduler SchedulerConfiguration `yaml:"scheduler"`
 	Auth      AuthenticationConfig   `yaml:"auth"`
+	Events    EventsConfig           `yaml:"events"`
+	Resume    ResumeConfig           `yaml:"resume"`
+	Audio     AudioConfig            `yaml:"audio"`
+	Images    ImagesConfig           `yaml:"images"`
+	Vision    VisionConfig           `yaml:"vision"`
+	Scoring   ScoringConfig          `yaml:"scoring"`
+	Queue     QueueConfig            `yaml:"queue"`
+	// SemanticCache serves the responses of prompts similar to prompts already answered from a cache.
+	SemanticCache SemanticCacheConfig `yaml:"semanticCache"`
+	// Feedback keeps the requests the clients can report result quality feedback on and the aggregated feedback.
+	Feedback FeedbackConfig `yaml:"feedback"`
+	// Experiments are experimental router behaviors only enabled for the requests opted in.
+	Experiments []ExperimentConfig `yaml:"experiments,omitempty"`
+	// Quotas are the limits shared by the consumers rolled up into teams and orgs.
+	Quotas QuotaConfig `yaml:"quotas"`
+	// ServerTiming reports the phases of the requests in Server-Timing response headers.
+	ServerTiming ServerTimingConfig `yaml:"serverTiming"`
+	// LoadShedding sheds the batch requests while the router pod is under memory or CPU pressure.
+	LoadShedding LoadSheddingConfig `yaml:"loadShedding"`
+	// ModelResolution resolves the model of the requests from a header or the path, for the clients which can't set
+	// the model field of the body.
+	ModelResolution ModelResolutionConfig `yaml:"modelResolution"`
+
+	ConsumerStreams ConsumerStreamsConfig `yaml:"consumerStreams"`
+	// Tokenizers count the input tokens of the prompts of the models with their own tokenizers, so that the rate limits
+	// and the usage match what the model servers bill. The tokens of the other models are estimated.
+	Tokenizers []TokenizerConfig `yaml:"tokenizers,omitempty"`
 }
 
 type SchedulerConfiguration struct {
@@ -64,18 +91,343 @@ type AuthenticationConfig struct {
 	Issuer    string   `yaml:"issuer"`
 	Audiences []string `yaml:"audiences"`
 	JwksUri   string   `yaml:"jwksUri"`
+	// ModelsClaim is the claim of the JWT listing the models the caller may call, as an array or a space separated
+	// string. The callers whose token doesn't have it may call any model, unless they are restricted by Consumers.
+	ModelsClaim string `yaml:"modelsClaim,omitempty"`
+	// Consumers restrict the models the callers authenticated with the subjects may call.
+	Consumers []ConsumerConfig `yaml:"consumers,omitempty"`
+	// Authenticators is the chain of authenticators evaluated in order: "apiKey", "jwt", "mtls" or "anonymous". The
+	// first authenticator finding its credentials on a request authenticates it, and a request with invalid
+	// credentials is rejected. Defaults to jwt when JwksUri is set, the callers are not authenticated otherwise.
+	Authenticators []string `yaml:"authenticators,omitempty"`
+	// APIKeys are the keys of the apiKey authenticator, sent in the X-API-Key header.
+	APIKeys []APIKeyConfig `yaml:"apiKeys,omitempty"`
+	// Routes require the callers of some models to be authenticated by some of the authenticators.
+	Routes []AuthRouteConfig `yaml:"routes,omitempty"`
+}
+
+// ConsumerConfig declares the models a caller may call.
+type ConsumerConfig struct {
+	// Subject is the subject of the caller: the subject of its JWT, the subject of its API key, or the URI SAN or
+	// common name of its client certificate.
+	Subject string `yaml:"subject"`
+	// Models are the names of the models the caller may call, a name ending with "*" matches the models starting
+	// with it.
+	Models []string `yaml:"models"`
+}
+
+// APIKeyConfig declares an API key of a caller.
+type APIKeyConfig struct {
+	// Subject is the subject of the caller authenticated with the key.
+	Subject string `yaml:"subject"`
+	// KeySHA256 is the hex encoded SHA-256 digest of the key, so that the key itself is not stored in the configuration.
+	KeySHA256 string `yaml:"keySha256"`
+}
+
+// AuthRouteConfig requires the callers of models to be authenticated by some of the authenticators.
+type AuthRouteConfig struct {
+	// Models are the names of the models of the route, a name ending with "*" matches the models starting with it.
+	// The first route matching a model applies.
+	Models []string `yaml:"models"`
+	// Authenticators are the authenticators accepted for the callers of the models.
+	Authenticators []string `yaml:"authenticators"`
+}
+
+// EventsConfig configures the export of per-request usage events to a message broker.
+type EventsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Backend is the message broker type, one of "kafka" or "nats".
+	Backend string `yaml:"backend"`
+	// Endpoint is the Kafka REST proxy URL for kafka, or the server address (host:port) for nats.
+	Endpoint string `yaml:"endpoint"`
+	// Topic is the Kafka topic or the NATS subject events are published to.
+	Topic string `yaml:"topic"`
+	// BatchSize is the maximum number of events published at once.
+	BatchSize int `yaml:"batchSize,omitempty"`
+	// FlushIntervalMs is the maximum time in milliseconds an event waits before its batch is published.
+	FlushIntervalMs int `yaml:"flushIntervalMs,omitempty"`
+	// QueueSize is the number of events buffered while the broker is slow or unavailable.
+	QueueSize int `yaml:"queueSize,omitempty"`
+}
+
+// ResumeConfig configures the resumption of streamed responses cut by a router restart.
+// The stream state is shared by the router replicas through Redis.
+type ResumeConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// TTLSeconds is how long a stream can be resumed after its last event.
+	TTLSeconds int `yaml:"ttlSeconds,omitempty"`
+}
+
+// AudioConfig configures the audio transcription and translation endpoints.
+type AudioConfig struct {
+	// MaxFileSizeMB is the maximum size of the uploaded audio files, 25 if unset.
+	MaxFileSizeMB int `yaml:"maxFileSizeMB,omitempty"`
+}
+
+// ImagesConfig configures the image generation endpoint.
+type ImagesConfig struct {
+	// Costs are the prices of the images generated by the models, reported with the usage of the requests.
+	Costs []ImageCostConfig `yaml:"costs,omitempty"`
 }
 
+// ImageCostConfig is the price of the images generated by a model: PerImage for each image plus
+// PerMegapixel for each megapixel of the image.
+type ImageCostConfig struct {
+	Model        string  `yaml:"model"`
+	PerImage     float64 `yaml:"perImage,omitempty"`
+	PerMegapixel float64 `yaml:"perMegapixel,omitempty"`
+}
+
+// VisionConfig configures the chat completion requests with images, which are only routed to the ModelServers with the
+// vision capability.
+type VisionConfig struct {
+	// MaxPayloadSizeMB is the maximum size of the body of the requests with images, 20 if unset.
+	MaxPayloadSizeMB int `yaml:"maxPayloadSizeMB,omitempty"`
+	// ImageTokens count the input tokens of the images of the models as their providers do. The images of the other
+	// models are counted with the openai rule.
+	ImageTokens []ImageTokensConfig `yaml:"imageTokens,omitempty"`
+}
+
+// ImageTokensConfig is the rule counting the tokens of the images of some models. The size of an image is read from
+// its data URL, the images given by URL are not downloaded and are counted as 1024x1024 images.
+type ImageTokensConfig struct {
+	// Models are the names of the models, a name ending with "*" matches the models starting with it. The first rule
+	// matching a model applies.
+	Models []string `yaml:"models"`
+	// Rule is "openai" for 85 tokens plus 170 per 512x512 tile of the image scaled to fit 2048x2048 with its shortest
+	// side at most 768, and 85 tokens for the low detail images, "anthropic" for a token per 750 pixels of the image
+	// scaled to fit 1568x1568, or "fixed" for TokensPerImage tokens per image.
+	Rule string `yaml:"rule"`
+	// TokensPerImage is the number of tokens of an image for the fixed rule.
+	TokensPerImage int `yaml:"tokensPerImage,omitempty"`
+}
+
+// ScoringConfig configures the rerank and classification endpoints.
+type ScoringConfig struct {
+	// MaxBatchSize is the maximum number of documents to rerank, or of inputs to classify, sent to a model server at
+	// once. Larger requests are split into batches scored concurrently. 0 disables the splitting.
+	MaxBatchSize int `yaml:"maxBatchSize,omitempty"`
+}
+
+// QueueConfig configures the queueing of the requests received while the router handles its maximum number of
+// concurrent requests, which are rejected with 503 otherwise. The interactive requests leave the queue before the
+// batch ones, in the order they arrived.
+type QueueConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// MaxDepth is the maximum number of queued requests, 1024 if unset. The requests beyond it are rejected.
+	MaxDepth int `yaml:"maxDepth,omitempty"`
+	// InteractiveTimeoutSeconds is how long an interactive request waits in the queue before being rejected, 30 if unset.
+	InteractiveTimeoutSeconds int `yaml:"interactiveTimeoutSeconds,omitempty"`
+	// BatchTimeoutSeconds is how long a batch request waits in the queue before being rejected, 300 if unset.
+	BatchTimeoutSeconds int `yaml:"batchTimeoutSeconds,omitempty"`
+}
+
+// ServerTimingConfig configures the Server-Timing headers breaking the latency of the requests down into routing,
+// queueing, prefill, decode or time to first token, and streaming, for the clients without access to the traces.
+type ServerTimingConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// LoadSheddingConfig configures the shedding of the batch requests while the router pod is under pressure, before the
+// kubelet OOM-kills it or the CPU throttling stalls the interactive requests. The pressure is read from the cgroup of
+// the pod: the memory working set relative to the memory limit, and the share of the CPU periods throttled.
+type LoadSheddingConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// MemoryPercent is the memory working set of the pod, in percent of its memory limit, from which the batch
+	// requests are shed, 90 if unset. It is ignored when the pod has no memory limit.
+	MemoryPercent int `yaml:"memoryPercent,omitempty"`
+	// CPUThrottledPercent is the share of the CPU periods of the pod throttled, in percent, from which the batch
+	// requests are shed, 50 if unset. It is ignored when the pod has no CPU limit.
+	CPUThrottledPercent int `yaml:"cpuThrottledPercent,omitempty"`
+	// IntervalSeconds is the interval between two reads of the pressure, 1 if unset.
+	IntervalSeconds int `yaml:"intervalSeconds,omitempty"`
+}
+
+// ModelResolutionConfig resolves the model of the requests of the legacy clients which can't set the model field of
+// their JSON body, or whose proxies strip it, from a header or a segment of the path. The sources are tried in order,
+// the first one resolving a model applies.
+type ModelResolutionConfig struct {
+	// Precedence is "body" for the model field of the body to win over the sources, or "sources" for the sources to
+	// win over it, e.g. when a proxy rewrites it. "body" if unset.
+	Precedence string              `yaml:"precedence,omitempty"`
+	Sources    []ModelSourceConfig `yaml:"sources,omitempty"`
+}
+
+// ModelSourceConfig reads the model of the requests from a header or from the segment of the path following a
+// prefix. Exactly one of Header and PathPrefix must be set.
+type ModelSourceConfig struct {
+	// Header is the name of the header holding the model, e.g. x-model.
+	Header string `yaml:"header,omitempty"`
+	// PathPrefix is the prefix of the paths whose next segment is the model, e.g. /v1/deployments/ for
+	// /v1/deployments/llama/chat/completions. It must start with /v1/ and end with /. The prefix and the model are
+	// replaced by /v1/ in the path forwarded to the model server, /v1/chat/completions in the example.
+	PathPrefix string `yaml:"pathPrefix,omitempty"`
+	// Models maps the values of the header or of the path segment to the names of the models. The values are the
+	// names of the models if unset, the values not mapped don't resolve a model otherwise.
+	Models map[string]string `yaml:"models,omitempty"`
+}
+
+// ConsumerStreamsConfig configures the metrics of the streamed responses of each authenticated consumer: their
+// duration, their output tokens and the share abandoned by the client, and the rules flagging the consumers whose
+// streams look anomalous, e.g. runaway agents or scrapers. The rules are evaluated over tumbling windows, each rule
+// flags a consumer at most once per window.
+type ConsumerStreamsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// WindowSeconds is the length of the windows the streams of the consumers are counted over, 300 if unset.
+	WindowSeconds int `yaml:"windowSeconds,omitempty"`
+	// Rules flag the consumers whose streams of the current window exceed one of their thresholds.
+	Rules []StreamAnomalyRule `yaml:"rules,omitempty"`
+	// CalloutURL receives a POST of each anomaly flagged as JSON, e.g. to revoke the API key of the consumer. The
+	// anomalies are only logged and counted if unset.
+	CalloutURL string `yaml:"calloutUrl,omitempty"`
+}
+
+// StreamAnomalyRule flags a consumer once it has streamed MinStreams responses in the window and its streams exceed
+// one of the thresholds set. The thresholds left to 0 are not checked.
+type StreamAnomalyRule struct {
+	Name string `yaml:"name"`
+	// MinStreams is the number of streams of the window from which the averages are checked, 1 if unset.
+	MinStreams int `yaml:"minStreams,omitempty"`
+	// MaxStreams is the maximum number of streams of a consumer in the window.
+	MaxStreams int `yaml:"maxStreams,omitempty"`
+	// MaxAbandonPercent is the maximum share of the streams abandoned by the client before their end, in percent.
+	MaxAbandonPercent int `yaml:"maxAbandonPercent,omitempty"`
+	// MaxAvgDurationSeconds is the maximum average duration of the streams.
+	MaxAvgDurationSeconds int `yaml:"maxAvgDurationSeconds,omitempty"`
+	// MaxAvgOutputTokens is the maximum average number of output tokens of the streams.
+	MaxAvgOutputTokens int `yaml:"maxAvgOutputTokens,omitempty"`
+}
+
+// TokenizerConfig configures the tokenizer counting the input tokens of the prompts of some models.
+type TokenizerConfig struct {
+	// Models are the names of the models tokenized, a name ending with "*" matches the models starting with it. The
+	// first tokenizer matching a model applies.
+	Models []string `yaml:"models"`
+	// Type is "tiktoken" for a tiktoken BPE, or "huggingface" for the tokenizer.json file of a HuggingFace byte-level
+	// BPE tokenizer, e.g. of the Llama 3, Qwen or Mistral Nemo models.
+	Type string `yaml:"type"`
+	// Encoding is the name of a tiktoken encoding bundled with the router: cl100k_base, p50k_base or r50k_base. Exactly
+	// one of Encoding and Path must be set for the tiktoken type.
+	Encoding string `yaml:"encoding,omitempty"`
+	// Path is the path of the tokenizer file mounted in the router pod: a .tiktoken file of base64 encoded tokens and
+	// their ranks, e.g. the tokenizer.model of Llama 3, or a tokenizer.json file.
+	Path string `yaml:"path,omitempty"`
+	// Pattern is the regular expression splitting the prompts before their BPE encoding. It defaults to the pattern of
+	// the encoding, to the pattern of cl100k_base for a .tiktoken file, and to the pattern of the pre-tokenizer of a
+	// tokenizer.json file.
+	Pattern string `yaml:"pattern,omitempty"`
+}
+
+// SemanticCacheConfig configures the cache of the responses of the non-streamed requests. The prompts are embedded by
+// an embedding model, and a request whose prompt is similar enough to a cached one is answered with its response,
+// without calling a model server.
+type SemanticCacheConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Store is where the responses are cached, "memory" for each router replica or "redis" to share them between the
+	// replicas. "memory" if unset.
+	Store string `yaml:"store,omitempty"`
+	// EmbeddingEndpoint is the URL of the OpenAI compatible embeddings API embedding the prompts,
+	// e.g. http://embedding-model/v1/embeddings.
+	EmbeddingEndpoint string `yaml:"embeddingEndpoint"`
+	// EmbeddingModel is the model of the embeddings API the prompts are embedded with.
+	EmbeddingModel string `yaml:"embeddingModel"`
+	// SimilarityThreshold is the minimum cosine similarity of the embeddings of a prompt and a cached one for the
+	// cached response to be served, 0.95 if unset.
+	SimilarityThreshold float64 `yaml:"similarityThreshold,omitempty"`
+	// TTLSeconds is how long a response is cached, 3600 if unset.
+	TTLSeconds int `yaml:"ttlSeconds,omitempty"`
+	// MaxEntries is the maximum number of responses cached for each model, caller and parameters, 1000 if unset.
+	MaxEntries int `yaml:"maxEntries,omitempty"`
+	// MaxTotalEntries is the maximum number of responses cached by each router replica with the "memory" store, for all
+	// the models, callers and parameters, 100000 if unset. The "redis" store is bounded by the memory policy of Redis.
+	MaxTotalEntries int `yaml:"maxTotalEntries,omitempty"`
+}
+
+// FeedbackConfig configures where the result quality feedback is kept.
+type FeedbackConfig struct {
+	// Store is where the requests and the aggregated feedback are kept, "memory" for each router replica, which only
+	// accepts the feedback on the requests it served, or "redis" to share them between the replicas. "memory" if unset.
+	Store string `yaml:"store,omitempty"`
+}
+
+// ExperimentConfig configures an experimental router behavior, so that it can be soak-tested on real traffic
+// before being enabled for every request. A request is opted in by naming the experiment in the
+// x-kthena-experiment header, or by being sent by one of its consumers.
+type ExperimentConfig struct {
+	Name string `yaml:"name"`
+	// Consumers are the authenticated users, identified by the subject of their token, always opted in.
+	Consumers []string `yaml:"consumers,omitempty"`
+	// DisableHeader ignores the x-kthena-experiment header, only the consumers are opted in.
+	DisableHeader bool `yaml:"disableHeader,omitempty"`
+	// Scheduler is the scheduler configuration used for the requests opted in, e.g. to try a new score plugin
+	// or a prefix cache with different arguments. Its plugins hold their own state, separate from the default ones.
+	Scheduler SchedulerConfiguration `yaml:"scheduler"`
+}
+
+// QuotaConfig declares the quota hierarchy of the consumers: each consumer belongs to a team, which belongs to an
+// org. A request of a consumer must pass the limits of its org, then of its team, then its own. The requests of the
+// callers which are not consumers of a team are not subject to quotas.
+type QuotaConfig struct {
+	Orgs []QuotaOrgConfig `yaml:"orgs,omitempty"`
+	// Redis shares the budgets of the quotas between the router replicas. If unset, each replica enforces the limits
+	// on the requests it handles, so the budgets are multiplied by the number of replicas.
+	Redis *QuotaRedisConfig `yaml:"redis,omitempty"`
+}
+
+// QuotaRedisConfig is the Redis server holding the budgets of the quotas.
+type QuotaRedisConfig struct {
+	// Address is the Redis server address in the format "host:port".
+	Address string `yaml:"address"`
+}
+
+// QuotaLimits are the limits of a level of the quota hierarchy, shared by all the consumers below it. The limits not
+// set are not enforced at that level.
+type QuotaLimits struct {
+	// RequestsPerUnit is the maximum number of requests per unit of time.
+	RequestsPerUnit uint32 `yaml:"requestsPerUnit,omitempty"`
+	// InputTokensPerUnit is the maximum number of input tokens per unit of time.
+	InputTokensPerUnit uint32 `yaml:"inputTokensPerUnit,omitempty"`
+	// OutputTokensPerUnit is the maximum number of output tokens per unit of time.
+	OutputTokensPerUnit uint32 `yaml:"outputTokensPerUnit,omitempty"`
+	// Unit is the unit of time of the limits, one of second, minute, hour, day or month. "minute" if unset.
+	Unit string `yaml:"unit,omitempty"`
+}
+
+// QuotaOrgConfig is the top level of the quota hierarchy.
+type QuotaOrgConfig struct {
+	Name        string `yaml:"name"`
+	QuotaLimits `yaml:",inline"`
+	Teams       []QuotaTeamConfig `yaml:"teams,omitempty"`
+}
+
+// QuotaTeamConfig is a team of an org.
+type QuotaTeamConfig struct {
+	Name        string `yaml:"name"`
+	QuotaLimits `yaml:",inline"`
+	Consumers   []QuotaConsumerConfig `yaml:"consumers,omitempty"`
+}
+
+// QuotaConsumerConfig is a consumer of a team, a caller identified by the subject of its token.
+type QuotaConsumerConfig struct {
+	Subject     string `yaml:"subject"`
+	QuotaLimits `yaml:",inline"`
+}
+
+// ParseRouterConfig reads and validates the router configuration file. The unknown fields are rejected, so that a
+// typo in a field name doesn't silently leave the setting at its default.
 func ParseRouterConfig(configMapPath string) (*RouterConfiguration, error) {
 	data, err := os.ReadFile(configMapPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file %s: %w", configMapPath, err)
 	}
 	var routerConfig RouterConfiguration
-	if err := yaml.Unmarshal(data, &routerConfig); err != nil {
+	if err := yaml.UnmarshalStrict(data, &routerConfig); err != nil {
 		klog.Errorf("failed to Unmarshal routerConfiguration: %v", err)
 		return nil, fmt.Errorf("failed to Unmarshal routerConfiguration: %v", err)
 	}
+	if err := routerConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid router configuration %s: %w", configMapPath, err)
+	}
 	return &routerConfig, nil
 }
 
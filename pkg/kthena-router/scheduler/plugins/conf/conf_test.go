@@ -40,7 +40,7 @@ func TestLoadSchedulerConfig(t *testing.T) {
 		{
 			name:       "invalid YAML syntax",
 			configFile: "../../../utils/testdata/configmap-invalid.yaml",
-			expectErrs: "failed to Unmarshal schedulerConfiguration",
+			expectErrs: `unknown field "pluginConfig"`,
 		},
 	}
 
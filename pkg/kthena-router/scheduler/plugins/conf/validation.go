@@ -0,0 +1,371 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conf
+
+import (
+	"encoding/hex"
+	"net/url"
+	"strings"
+
+	"github.com/dlclark/regexp2"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var (
+	supportedAuthenticators = []string{"apiKey", "jwt", "mtls", "anonymous"}
+	supportedEventBackends  = []string{"kafka", "nats"}
+	supportedCacheStores    = []string{"memory", "redis"}
+	supportedQuotaUnits     = []string{"second", "minute", "hour", "day", "month"}
+	supportedPrecedences    = []string{"body", "sources"}
+	supportedTokenizers     = []string{"tiktoken", "huggingface"}
+	supportedEncodings      = []string{"cl100k_base", "p50k_base", "r50k_base"}
+	supportedImageRules     = []string{"openai", "anthropic", "fixed"}
+)
+
+// Validate checks the values of the router configuration, so that a mistake is reported at startup with the path of
+// the field instead of silently disabling a feature or falling back to a default.
+func (c *RouterConfiguration) Validate() error {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validateScheduler(&c.Scheduler, field.NewPath("scheduler"))...)
+	allErrs = append(allErrs, validateAuth(&c.Auth, field.NewPath("auth"))...)
+	allErrs = append(allErrs, validateEvents(&c.Events, field.NewPath("events"))...)
+	allErrs = append(allErrs, validateNonNegative(field.NewPath("resume", "ttlSeconds"), c.Resume.TTLSeconds)...)
+	allErrs = append(allErrs, validateNonNegative(field.NewPath("audio", "maxFileSizeMB"), c.Audio.MaxFileSizeMB)...)
+	allErrs = append(allErrs, validateNonNegative(field.NewPath("scoring", "maxBatchSize"), c.Scoring.MaxBatchSize)...)
+
+	imagesPath := field.NewPath("images", "costs")
+	for i, cost := range c.Images.Costs {
+		if cost.Model == "" {
+			allErrs = append(allErrs, field.Required(imagesPath.Index(i).Child("model"), ""))
+		}
+		if cost.PerImage < 0 {
+			allErrs = append(allErrs, field.Invalid(imagesPath.Index(i).Child("perImage"), cost.PerImage, "must not be negative"))
+		}
+		if cost.PerMegapixel < 0 {
+			allErrs = append(allErrs, field.Invalid(imagesPath.Index(i).Child("perMegapixel"), cost.PerMegapixel, "must not be negative"))
+		}
+	}
+
+	allErrs = append(allErrs, validateVision(&c.Vision, field.NewPath("vision"))...)
+
+	queuePath := field.NewPath("queue")
+	allErrs = append(allErrs, validateNonNegative(queuePath.Child("maxDepth"), c.Queue.MaxDepth)...)
+	allErrs = append(allErrs, validateNonNegative(queuePath.Child("interactiveTimeoutSeconds"), c.Queue.InteractiveTimeoutSeconds)...)
+	allErrs = append(allErrs, validateNonNegative(queuePath.Child("batchTimeoutSeconds"), c.Queue.BatchTimeoutSeconds)...)
+
+	allErrs = append(allErrs, validateSemanticCache(&c.SemanticCache, field.NewPath("semanticCache"))...)
+	if c.Feedback.Store != "" && !sets.New(supportedCacheStores...).Has(c.Feedback.Store) {
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("feedback", "store"), c.Feedback.Store, supportedCacheStores))
+	}
+
+	experimentsPath := field.NewPath("experiments")
+	experiments := sets.New[string]()
+	for i := range c.Experiments {
+		experiment := &c.Experiments[i]
+		switch {
+		case experiment.Name == "":
+			allErrs = append(allErrs, field.Required(experimentsPath.Index(i).Child("name"), ""))
+		case experiments.Has(experiment.Name):
+			allErrs = append(allErrs, field.Duplicate(experimentsPath.Index(i).Child("name"), experiment.Name))
+		}
+		experiments.Insert(experiment.Name)
+		allErrs = append(allErrs, validateScheduler(&experiment.Scheduler, experimentsPath.Index(i).Child("scheduler"))...)
+	}
+
+	allErrs = append(allErrs, validateQuotas(&c.Quotas, field.NewPath("quotas"))...)
+	allErrs = append(allErrs, validateLoadShedding(&c.LoadShedding, field.NewPath("loadShedding"))...)
+	allErrs = append(allErrs, validateModelResolution(&c.ModelResolution, field.NewPath("modelResolution"))...)
+	allErrs = append(allErrs, validateConsumerStreams(&c.ConsumerStreams, field.NewPath("consumerStreams"))...)
+	allErrs = append(allErrs, validateTokenizers(c.Tokenizers, field.NewPath("tokenizers"))...)
+	return allErrs.ToAggregate()
+}
+
+func validateScheduler(config *SchedulerConfiguration, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, plugin := range config.PluginConfig {
+		if plugin.Name == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("pluginConfig").Index(i).Child("name"), ""))
+		}
+	}
+	for i, name := range config.Plugins.Filter.Enabled {
+		if name == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("plugins", "Filter", "enabled").Index(i), ""))
+		}
+	}
+	for i, plugin := range config.Plugins.Score.Enabled {
+		pluginPath := fldPath.Child("plugins", "Score", "enabled").Index(i)
+		if plugin.Name == "" {
+			allErrs = append(allErrs, field.Required(pluginPath.Child("name"), ""))
+		}
+		allErrs = append(allErrs, validateNonNegative(pluginPath.Child("weight"), plugin.Weight)...)
+	}
+	return allErrs
+}
+
+func validateAuth(config *AuthenticationConfig, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	authenticators := sets.New[string]()
+	for i, name := range config.Authenticators {
+		namePath := fldPath.Child("authenticators").Index(i)
+		switch {
+		case !sets.New(supportedAuthenticators...).Has(name):
+			allErrs = append(allErrs, field.NotSupported(namePath, name, supportedAuthenticators))
+		case authenticators.Has(name):
+			allErrs = append(allErrs, field.Duplicate(namePath, name))
+		case name == "jwt" && config.JwksUri == "":
+			allErrs = append(allErrs, field.Required(fldPath.Child("jwksUri"), "the jwt authenticator requires a JWKS URI"))
+		}
+		authenticators.Insert(name)
+	}
+	for i, key := range config.APIKeys {
+		keyPath := fldPath.Child("apiKeys").Index(i)
+		if key.Subject == "" {
+			allErrs = append(allErrs, field.Required(keyPath.Child("subject"), ""))
+		}
+		if digest, err := hex.DecodeString(key.KeySHA256); err != nil || len(digest) != 32 {
+			allErrs = append(allErrs, field.Invalid(keyPath.Child("keySha256"), key.KeySHA256, "must be a hex encoded SHA-256 digest"))
+		}
+	}
+	for i, route := range config.Routes {
+		routePath := fldPath.Child("routes").Index(i)
+		if len(route.Models) == 0 {
+			allErrs = append(allErrs, field.Required(routePath.Child("models"), ""))
+		}
+		if len(route.Authenticators) == 0 {
+			allErrs = append(allErrs, field.Required(routePath.Child("authenticators"), ""))
+		}
+	}
+	return allErrs
+}
+
+func validateEvents(config *EventsConfig, fldPath *field.Path) field.ErrorList {
+	if !config.Enabled {
+		return nil
+	}
+	var allErrs field.ErrorList
+	if !sets.New(supportedEventBackends...).Has(config.Backend) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("backend"), config.Backend, supportedEventBackends))
+	}
+	if config.Endpoint == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("endpoint"), ""))
+	}
+	if config.Topic == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("topic"), ""))
+	}
+	allErrs = append(allErrs, validateNonNegative(fldPath.Child("batchSize"), config.BatchSize)...)
+	allErrs = append(allErrs, validateNonNegative(fldPath.Child("flushIntervalMs"), config.FlushIntervalMs)...)
+	allErrs = append(allErrs, validateNonNegative(fldPath.Child("queueSize"), config.QueueSize)...)
+	return allErrs
+}
+
+func validateSemanticCache(config *SemanticCacheConfig, fldPath *field.Path) field.ErrorList {
+	if !config.Enabled {
+		return nil
+	}
+	var allErrs field.ErrorList
+	if config.Store != "" && !sets.New(supportedCacheStores...).Has(config.Store) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("store"), config.Store, supportedCacheStores))
+	}
+	if config.EmbeddingEndpoint == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("embeddingEndpoint"), ""))
+	}
+	if config.EmbeddingModel == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("embeddingModel"), ""))
+	}
+	if config.SimilarityThreshold < 0 || config.SimilarityThreshold > 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("similarityThreshold"), config.SimilarityThreshold, "must be between 0 and 1"))
+	}
+	allErrs = append(allErrs, validateNonNegative(fldPath.Child("ttlSeconds"), config.TTLSeconds)...)
+	allErrs = append(allErrs, validateNonNegative(fldPath.Child("maxEntries"), config.MaxEntries)...)
+	allErrs = append(allErrs, validateNonNegative(fldPath.Child("maxTotalEntries"), config.MaxTotalEntries)...)
+	return allErrs
+}
+
+func validateQuotas(config *QuotaConfig, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if config.Redis != nil && config.Redis.Address == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("redis", "address"), ""))
+	}
+	consumers := sets.New[string]()
+	for i, org := range config.Orgs {
+		orgPath := fldPath.Child("orgs").Index(i)
+		allErrs = append(allErrs, validateQuotaLevel(org.Name, "name", org.QuotaLimits, orgPath)...)
+		for j, team := range org.Teams {
+			teamPath := orgPath.Child("teams").Index(j)
+			allErrs = append(allErrs, validateQuotaLevel(team.Name, "name", team.QuotaLimits, teamPath)...)
+			for k, consumer := range team.Consumers {
+				consumerPath := teamPath.Child("consumers").Index(k)
+				allErrs = append(allErrs, validateQuotaLevel(consumer.Subject, "subject", consumer.QuotaLimits, consumerPath)...)
+				if consumers.Has(consumer.Subject) {
+					allErrs = append(allErrs, field.Duplicate(consumerPath.Child("subject"), consumer.Subject))
+				}
+				consumers.Insert(consumer.Subject)
+			}
+		}
+	}
+	return allErrs
+}
+
+func validateQuotaLevel(name, nameField string, limits QuotaLimits, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child(nameField), ""))
+	}
+	if limits.Unit != "" && !sets.New(supportedQuotaUnits...).Has(limits.Unit) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("unit"), limits.Unit, supportedQuotaUnits))
+	}
+	return allErrs
+}
+
+func validateLoadShedding(config *LoadSheddingConfig, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validatePercent(fldPath.Child("memoryPercent"), config.MemoryPercent)...)
+	allErrs = append(allErrs, validatePercent(fldPath.Child("cpuThrottledPercent"), config.CPUThrottledPercent)...)
+	allErrs = append(allErrs, validateNonNegative(fldPath.Child("intervalSeconds"), config.IntervalSeconds)...)
+	return allErrs
+}
+
+func validateModelResolution(config *ModelResolutionConfig, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if config.Precedence != "" && !sets.New(supportedPrecedences...).Has(config.Precedence) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("precedence"), config.Precedence, supportedPrecedences))
+	}
+	for i, source := range config.Sources {
+		sourcePath := fldPath.Child("sources").Index(i)
+		switch {
+		case source.Header == "" && source.PathPrefix == "":
+			allErrs = append(allErrs, field.Required(sourcePath, "one of header or pathPrefix must be set"))
+		case source.Header != "" && source.PathPrefix != "":
+			allErrs = append(allErrs, field.Forbidden(sourcePath.Child("pathPrefix"), "must not be set with header"))
+		case source.PathPrefix != "" && (!strings.HasPrefix(source.PathPrefix, "/v1/") || !strings.HasSuffix(source.PathPrefix, "/")):
+			allErrs = append(allErrs, field.Invalid(sourcePath.Child("pathPrefix"), source.PathPrefix, "must start with /v1/ and end with /"))
+		}
+		for value, model := range source.Models {
+			if model == "" {
+				allErrs = append(allErrs, field.Required(sourcePath.Child("models").Key(value), ""))
+			}
+		}
+	}
+	return allErrs
+}
+
+func validateConsumerStreams(config *ConsumerStreamsConfig, fldPath *field.Path) field.ErrorList {
+	if !config.Enabled {
+		return nil
+	}
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validateNonNegative(fldPath.Child("windowSeconds"), config.WindowSeconds)...)
+	rules := sets.New[string]()
+	for i, rule := range config.Rules {
+		rulePath := fldPath.Child("rules").Index(i)
+		switch {
+		case rule.Name == "":
+			allErrs = append(allErrs, field.Required(rulePath.Child("name"), ""))
+		case rules.Has(rule.Name):
+			allErrs = append(allErrs, field.Duplicate(rulePath.Child("name"), rule.Name))
+		}
+		rules.Insert(rule.Name)
+		if rule.MaxStreams == 0 && rule.MaxAbandonPercent == 0 && rule.MaxAvgDurationSeconds == 0 && rule.MaxAvgOutputTokens == 0 {
+			allErrs = append(allErrs, field.Required(rulePath, "one of maxStreams, maxAbandonPercent, maxAvgDurationSeconds or maxAvgOutputTokens must be set"))
+		}
+		allErrs = append(allErrs, validateNonNegative(rulePath.Child("minStreams"), rule.MinStreams)...)
+		allErrs = append(allErrs, validateNonNegative(rulePath.Child("maxStreams"), rule.MaxStreams)...)
+		allErrs = append(allErrs, validatePercent(rulePath.Child("maxAbandonPercent"), rule.MaxAbandonPercent)...)
+		allErrs = append(allErrs, validateNonNegative(rulePath.Child("maxAvgDurationSeconds"), rule.MaxAvgDurationSeconds)...)
+		allErrs = append(allErrs, validateNonNegative(rulePath.Child("maxAvgOutputTokens"), rule.MaxAvgOutputTokens)...)
+	}
+	if config.CalloutURL != "" {
+		if u, err := url.Parse(config.CalloutURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("calloutUrl"), config.CalloutURL, "must be an http or https URL"))
+		}
+	}
+	return allErrs
+}
+
+func validateTokenizers(tokenizers []TokenizerConfig, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, tokenizer := range tokenizers {
+		tokenizerPath := fldPath.Index(i)
+		if len(tokenizer.Models) == 0 {
+			allErrs = append(allErrs, field.Required(tokenizerPath.Child("models"), ""))
+		}
+		switch tokenizer.Type {
+		case "tiktoken":
+			switch {
+			case tokenizer.Encoding == "" && tokenizer.Path == "":
+				allErrs = append(allErrs, field.Required(tokenizerPath, "one of encoding or path must be set"))
+			case tokenizer.Encoding != "" && tokenizer.Path != "":
+				allErrs = append(allErrs, field.Forbidden(tokenizerPath.Child("path"), "must not be set with encoding"))
+			case tokenizer.Encoding != "" && !sets.New(supportedEncodings...).Has(tokenizer.Encoding):
+				allErrs = append(allErrs, field.NotSupported(tokenizerPath.Child("encoding"), tokenizer.Encoding, supportedEncodings))
+			}
+		case "huggingface":
+			if tokenizer.Path == "" {
+				allErrs = append(allErrs, field.Required(tokenizerPath.Child("path"), ""))
+			}
+			if tokenizer.Encoding != "" {
+				allErrs = append(allErrs, field.Forbidden(tokenizerPath.Child("encoding"), "must not be set for the huggingface type"))
+			}
+		default:
+			allErrs = append(allErrs, field.NotSupported(tokenizerPath.Child("type"), tokenizer.Type, supportedTokenizers))
+		}
+		if tokenizer.Pattern != "" {
+			if _, err := regexp2.Compile(tokenizer.Pattern, regexp2.None); err != nil {
+				allErrs = append(allErrs, field.Invalid(tokenizerPath.Child("pattern"), tokenizer.Pattern, err.Error()))
+			}
+		}
+	}
+	return allErrs
+}
+
+func validateVision(config *VisionConfig, fldPath *field.Path) field.ErrorList {
+	allErrs := validateNonNegative(fldPath.Child("maxPayloadSizeMB"), config.MaxPayloadSizeMB)
+	for i, rule := range config.ImageTokens {
+		rulePath := fldPath.Child("imageTokens").Index(i)
+		if len(rule.Models) == 0 {
+			allErrs = append(allErrs, field.Required(rulePath.Child("models"), ""))
+		}
+		switch rule.Rule {
+		case "fixed":
+			if rule.TokensPerImage <= 0 {
+				allErrs = append(allErrs, field.Invalid(rulePath.Child("tokensPerImage"), rule.TokensPerImage, "must be positive for the fixed rule"))
+			}
+		case "openai", "anthropic":
+			if rule.TokensPerImage != 0 {
+				allErrs = append(allErrs, field.Forbidden(rulePath.Child("tokensPerImage"), "must only be set for the fixed rule"))
+			}
+		default:
+			allErrs = append(allErrs, field.NotSupported(rulePath.Child("rule"), rule.Rule, supportedImageRules))
+		}
+	}
+	return allErrs
+}
+
+func validatePercent(fldPath *field.Path, value int) field.ErrorList {
+	if value < 0 || value > 100 {
+		return field.ErrorList{field.Invalid(fldPath, value, "must be between 0 and 100")}
+	}
+	return nil
+}
+
+func validateNonNegative(fldPath *field.Path, value int) field.ErrorList {
+	if value < 0 {
+		return field.ErrorList{field.Invalid(fldPath, value, "must not be negative")}
+	}
+	return nil
+}
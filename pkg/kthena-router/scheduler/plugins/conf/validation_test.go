@@ -0,0 +1,271 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRouterConfigValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		expectErr []string
+	}{
+		{
+			name: "valid configuration",
+			config: `
+scheduler:
+  plugins:
+    Score:
+      enabled:
+      - name: least-request
+        weight: 1
+auth:
+  authenticators: [apiKey]
+  apiKeys:
+  - subject: team-a
+    keySha256: 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+semanticCache:
+  enabled: true
+  store: redis
+  embeddingEndpoint: http://embedding/v1/embeddings
+  embeddingModel: bge
+feedback:
+  store: redis
+quotas:
+  orgs:
+  - name: acme
+    unit: hour
+loadShedding:
+  enabled: true
+  memoryPercent: 85
+modelResolution:
+  precedence: sources
+  sources:
+  - header: x-model
+  - pathPrefix: /v1/deployments/
+    models:
+      gpt-4: llama
+consumerStreams:
+  enabled: true
+  windowSeconds: 600
+  rules:
+  - name: runaway-agent
+    minStreams: 10
+    maxAvgDurationSeconds: 900
+    maxAvgOutputTokens: 16000
+  - name: scraper
+    maxStreams: 1000
+    maxAbandonPercent: 80
+  calloutUrl: http://anomalies.security.svc/flag
+tokenizers:
+- models: [gpt-4*]
+  type: tiktoken
+  encoding: cl100k_base
+- models: [llama-3*]
+  type: huggingface
+  path: /tokenizers/llama-3/tokenizer.json
+vision:
+  maxPayloadSizeMB: 50
+  imageTokens:
+  - models: [claude-*]
+    rule: anthropic
+  - models: [llava]
+    rule: fixed
+    tokensPerImage: 576
+`,
+		},
+		{
+			name: "unknown field",
+			config: `
+auth:
+  jwksUrl: https://example.com/jwks.json
+`,
+			expectErr: []string{`unknown field "jwksUrl"`},
+		},
+		{
+			name: "wrong type",
+			config: `
+resume:
+  enabled: true
+  ttlSeconds: 5m
+`,
+			expectErr: []string{"ttlSeconds", "int"},
+		},
+		{
+			name: "unsupported values",
+			config: `
+auth:
+  authenticators: [jwt, oauth]
+events:
+  enabled: true
+  backend: pulsar
+  endpoint: pulsar:6650
+  topic: usage
+semanticCache:
+  enabled: true
+  store: memcached
+  embeddingEndpoint: http://embedding/v1/embeddings
+  embeddingModel: bge
+  similarityThreshold: 1.5
+feedback:
+  store: memcached
+quotas:
+  orgs:
+  - name: acme
+    unit: week
+loadShedding:
+  enabled: true
+  memoryPercent: 120
+modelResolution:
+  precedence: header
+  sources:
+  - pathPrefix: /deployments/
+consumerStreams:
+  enabled: true
+  rules:
+  - name: scraper
+    maxAbandonPercent: 150
+  calloutUrl: anomalies.security.svc/flag
+tokenizers:
+- models: [gpt-4]
+  type: sentencepiece
+- models: [gpt-4o]
+  type: tiktoken
+  encoding: o200k_base
+  pattern: "(?<=x"
+vision:
+  imageTokens:
+  - models: [qwen-vl]
+    rule: patches
+`,
+			expectErr: []string{
+				`auth.jwksUri: Required value`,
+				`auth.authenticators[1]: Unsupported value: "oauth"`,
+				`events.backend: Unsupported value: "pulsar"`,
+				`semanticCache.store: Unsupported value: "memcached"`,
+				`semanticCache.similarityThreshold: Invalid value: 1.5`,
+				`feedback.store: Unsupported value: "memcached"`,
+				`quotas.orgs[0].unit: Unsupported value: "week"`,
+				`loadShedding.memoryPercent: Invalid value: 120: must be between 0 and 100`,
+				`modelResolution.precedence: Unsupported value: "header"`,
+				`modelResolution.sources[0].pathPrefix: Invalid value: "/deployments/"`,
+				`consumerStreams.rules[0].maxAbandonPercent: Invalid value: 150: must be between 0 and 100`,
+				`consumerStreams.calloutUrl: Invalid value: "anomalies.security.svc/flag": must be an http or https URL`,
+				`tokenizers[0].type: Unsupported value: "sentencepiece"`,
+				`tokenizers[1].encoding: Unsupported value: "o200k_base"`,
+				`tokenizers[1].pattern: Invalid value: "(?<=x"`,
+				`vision.imageTokens[0].rule: Unsupported value: "patches"`,
+			},
+		},
+		{
+			name: "missing and duplicate values",
+			config: `
+scheduler:
+  plugins:
+    Score:
+      enabled:
+      - name: least-request
+        weight: -1
+experiments:
+- name: new-scorer
+- name: new-scorer
+queue:
+  enabled: true
+  maxDepth: -10
+quotas:
+  redis: {}
+  orgs:
+  - name: acme
+    teams:
+    - name: search
+      consumers:
+      - subject: alice
+      - subject: alice
+modelResolution:
+  sources:
+  - models:
+      gpt-4: llama
+  - header: x-model
+    pathPrefix: /v1/deployments/
+consumerStreams:
+  enabled: true
+  rules:
+  - name: scraper
+    maxStreams: 1000
+  - name: scraper
+tokenizers:
+- type: tiktoken
+- models: [llama-3]
+  type: huggingface
+vision:
+  maxPayloadSizeMB: -1
+  imageTokens:
+  - rule: fixed
+  - models: [gpt-4o]
+    rule: openai
+    tokensPerImage: 765
+`,
+			expectErr: []string{
+				`scheduler.plugins.Score.enabled[0].weight: Invalid value: -1`,
+				`experiments[1].name: Duplicate value: "new-scorer"`,
+				`queue.maxDepth: Invalid value: -10`,
+				`quotas.redis.address: Required value`,
+				`quotas.orgs[0].teams[0].consumers[1].subject: Duplicate value: "alice"`,
+				`modelResolution.sources[0]: Required value: one of header or pathPrefix must be set`,
+				`modelResolution.sources[1].pathPrefix: Forbidden: must not be set with header`,
+				`consumerStreams.rules[1].name: Duplicate value: "scraper"`,
+				`consumerStreams.rules[1]: Required value: one of maxStreams, maxAbandonPercent, maxAvgDurationSeconds or maxAvgOutputTokens must be set`,
+				`tokenizers[0].models: Required value`,
+				`tokenizers[0]: Required value: one of encoding or path must be set`,
+				`tokenizers[1].path: Required value`,
+				`vision.maxPayloadSizeMB: Invalid value: -1`,
+				`vision.imageTokens[0].models: Required value`,
+				`vision.imageTokens[0].tokensPerImage: Invalid value: 0: must be positive for the fixed rule`,
+				`vision.imageTokens[1].tokensPerImage: Forbidden: must only be set for the fixed rule`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "routerConfiguration.yaml")
+			if err := os.WriteFile(path, []byte(tt.config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := ParseRouterConfig(path)
+			if len(tt.expectErr) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			for _, expected := range tt.expectErr {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("expected error %q to contain %q", err.Error(), expected)
+				}
+			}
+		})
+	}
+}
@@ -17,30 +17,78 @@ limitations under the License.
 package plugins
 
 import (
+	"github.com/stretchr/testify/assert/yaml"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+
 	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
 )
 
 var _ framework.ScorePlugin = &GPUCacheUsage{}
+var _ framework.FilterPlugin = &GPUCacheUsage{}
+
+const (
+	GPUCacheUsagePluginName = "gpu-usage"
 
-const GPUCacheUsagePluginName = "gpu-usage"
+	// defaultMaxGPUCacheUsage is the KV cache usage from which a pod is saturated.
+	defaultMaxGPUCacheUsage = 0.9
+)
 
+// GPUCacheUsage scores the pods on the free share of their KV cache, and filters out the pods whose KV cache is
+// saturated, which would queue or preempt the requests. The pods whose metrics are stale, e.g. because their metrics
+// endpoint doesn't answer, are scored last and not filtered out, their KV cache usage being unknown.
 type GPUCacheUsage struct {
-	name string
+	name             string
+	maxGPUCacheUsage float64
 }
 
-func NewGPUCacheUsage() *GPUCacheUsage {
+type GPUCacheUsageArgs struct {
+	MaxGPUCacheUsage float64 `yaml:"maxGPUCacheUsage,omitempty"`
+}
+
+func NewGPUCacheUsage(pluginArg runtime.RawExtension) *GPUCacheUsage {
+	var args GPUCacheUsageArgs
+	if err := yaml.Unmarshal(pluginArg.Raw, &args); err != nil {
+		klog.Errorf("Unmarshal GPUCacheUsageArgs error, setting default value: %v", err)
+		args = GPUCacheUsageArgs{}
+	}
+	if args.MaxGPUCacheUsage <= 0 || args.MaxGPUCacheUsage > 1 {
+		args.MaxGPUCacheUsage = defaultMaxGPUCacheUsage
+	}
+
 	return &GPUCacheUsage{
-		name: GPUCacheUsagePluginName,
+		name:             GPUCacheUsagePluginName,
+		maxGPUCacheUsage: args.MaxGPUCacheUsage,
 	}
 }
 
 func (g *GPUCacheUsage) Name() string {
 	return g.name
 }
+
+// Filter filters out the pods whose KV cache is saturated. If all the pods are saturated, none is filtered out.
+func (g *GPUCacheUsage) Filter(ctx *framework.Context, pods []*datastore.PodInfo) []*datastore.PodInfo {
+	available := make([]*datastore.PodInfo, 0, len(pods))
+	for _, info := range pods {
+		if !info.MetricsFresh() || info.GetGPUCacheUsage() < g.maxGPUCacheUsage {
+			available = append(available, info)
+		}
+	}
+	if len(available) == 0 {
+		klog.V(4).Infof("The KV cache of all the %d pods is saturated", len(pods))
+		return pods
+	}
+	return available
+}
+
 func (g *GPUCacheUsage) Score(ctx *framework.Context, pods []*datastore.PodInfo) map[*datastore.PodInfo]int {
 	scoreResults := make(map[*datastore.PodInfo]int)
 	for _, info := range pods {
+		if !info.MetricsFresh() {
+			scoreResults[info] = 0
+			continue
+		}
 		score := int((1.0 - info.GetGPUCacheUsage()) * 100)
 		scoreResults[info] = score
 	}
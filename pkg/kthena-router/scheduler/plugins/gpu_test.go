@@ -0,0 +1,77 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+)
+
+func newGPUCacheUsagePod(name string, usage float64, scraped time.Time) *datastore.PodInfo {
+	pod := &datastore.PodInfo{
+		Pod:           &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}},
+		GPUCacheUsage: usage,
+	}
+	if !scraped.IsZero() {
+		pod.RecordMetricsScrape(scraped)
+	}
+	return pod
+}
+
+func TestNewGPUCacheUsage(t *testing.T) {
+	assert.Equal(t, defaultMaxGPUCacheUsage, NewGPUCacheUsage(runtime.RawExtension{}).maxGPUCacheUsage)
+	assert.Equal(t, 0.8, NewGPUCacheUsage(runtime.RawExtension{Raw: []byte("maxGPUCacheUsage: 0.8")}).maxGPUCacheUsage)
+	assert.Equal(t, defaultMaxGPUCacheUsage, NewGPUCacheUsage(runtime.RawExtension{Raw: []byte("maxGPUCacheUsage: 2")}).maxGPUCacheUsage)
+}
+
+func TestGPUCacheUsage_Filter(t *testing.T) {
+	plugin := NewGPUCacheUsage(runtime.RawExtension{})
+	now := time.Now()
+	free := newGPUCacheUsagePod("free", 0.3, now)
+	saturated := newGPUCacheUsagePod("saturated", 0.95, now)
+	stale := newGPUCacheUsagePod("stale", 0.95, now.Add(-time.Minute))
+	unscraped := newGPUCacheUsagePod("unscraped", 0, time.Time{})
+
+	filtered := plugin.Filter(&framework.Context{}, []*datastore.PodInfo{free, saturated, stale, unscraped})
+	assert.Equal(t, []*datastore.PodInfo{free, stale, unscraped}, filtered)
+
+	// The saturated pods are kept if there are no others.
+	other := newGPUCacheUsagePod("other", 0.99, now)
+	filtered = plugin.Filter(&framework.Context{}, []*datastore.PodInfo{saturated, other})
+	assert.Equal(t, []*datastore.PodInfo{saturated, other}, filtered)
+}
+
+func TestGPUCacheUsage_Score(t *testing.T) {
+	plugin := NewGPUCacheUsage(runtime.RawExtension{})
+	now := time.Now()
+	free := newGPUCacheUsagePod("free", 0.3, now)
+	busy := newGPUCacheUsagePod("busy", 0.75, now)
+	stale := newGPUCacheUsagePod("stale", 0, now.Add(-time.Minute))
+
+	scores := plugin.Score(&framework.Context{}, []*datastore.PodInfo{free, busy, stale})
+	assert.Equal(t, 70, scores[free])
+	assert.Equal(t, 25, scores[busy])
+	assert.Equal(t, 0, scores[stale])
+}
@@ -0,0 +1,68 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import (
+	"math/rand/v2"
+	"sort"
+	"time"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+)
+
+const LeastObservedLatencyBalancerName = "least-observed-latency"
+
+var _ framework.LoadBalancer = &LeastObservedLatency{}
+
+// LeastObservedLatency picks the pods with the lowest moving average of the time to first token observed by the
+// router on the streamed responses. Unlike the least-latency score plugin, it doesn't depend on the metrics reported
+// by the inference engine, and accounts for the network and the queueing in front of the engine. The pods without a
+// recent observation are picked first, in a random order, so that new and recovered pods are probed.
+type LeastObservedLatency struct{}
+
+func NewLeastObservedLatency() *LeastObservedLatency {
+	return &LeastObservedLatency{}
+}
+
+func (l *LeastObservedLatency) Name() string {
+	return LeastObservedLatencyBalancerName
+}
+
+func (l *LeastObservedLatency) Pick(ctx *framework.Context, pods []*datastore.PodInfo, n int) []*datastore.PodInfo {
+	type rankedPod struct {
+		pod      *datastore.PodInfo
+		ttft     time.Duration
+		observed bool
+	}
+	ranked := make([]rankedPod, 0, len(pods))
+	for _, i := range rand.Perm(len(pods)) {
+		ttft, observed := pods[i].ObservedTTFT()
+		ranked = append(ranked, rankedPod{pod: pods[i], ttft: ttft, observed: observed})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].observed != ranked[j].observed {
+			return !ranked[i].observed
+		}
+		return ranked[i].ttft < ranked[j].ttft
+	})
+	picked := make([]*datastore.PodInfo, 0, min(n, len(ranked)))
+	for i := 0; i < len(ranked) && i < n; i++ {
+		picked = append(picked, ranked[i].pod)
+	}
+	return picked
+}
@@ -0,0 +1,45 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+)
+
+func TestLeastObservedLatency_Pick(t *testing.T) {
+	balancer := NewLeastObservedLatency()
+	assert.Equal(t, LeastObservedLatencyBalancerName, balancer.Name())
+	pods := newPrefixCacheAwarePods(4)
+	pods[0].RecordTTFT(300 * time.Millisecond)
+	pods[1].RecordTTFT(100 * time.Millisecond)
+	pods[2].RecordTTFT(200 * time.Millisecond)
+
+	// The pod never observed is probed first.
+	assert.Equal(t, []string{"pod-3", "pod-1", "pod-2"}, podNames(balancer.Pick(&framework.Context{}, pods, 3)))
+
+	pods[3].RecordTTFT(time.Second)
+	assert.Equal(t, []string{"pod-1", "pod-2", "pod-0", "pod-3"}, podNames(balancer.Pick(&framework.Context{}, pods, 4)))
+
+	// A slow response moves the average without replacing it.
+	pods[1].RecordTTFT(900 * time.Millisecond)
+	assert.Equal(t, []string{"pod-2", "pod-1"}, podNames(balancer.Pick(&framework.Context{}, pods, 2)))
+}
@@ -41,6 +41,10 @@ func (l *LoraAffinity) Name() string {
 }
 
 func (l *LoraAffinity) Filter(ctx *framework.Context, pods []*datastore.PodInfo) []*datastore.PodInfo {
+	if ctx.Model == "" {
+		// The model of gRPC calls is unknown without the model header.
+		return pods
+	}
 	return slices.FilterInPlace(pods, func(info *datastore.PodInfo) bool {
 		return info.Contains(ctx.Model)
 	})
@@ -0,0 +1,106 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cespare/xxhash"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+)
+
+const (
+	PrefixCacheAwareBalancerName = "prefix-cache-aware"
+
+	// prefixKeyLength is the length of the prompt prefix the requests without a session id are balanced on.
+	prefixKeyLength = 512
+)
+
+var _ framework.LoadBalancer = &PrefixCacheAware{}
+
+// PrefixCacheAware balances the requests on the hash of their session id, or of the prefix of their prompt, so that
+// the requests of the same conversation, or sharing a long system prompt, land on the same pod and hit its prefix
+// cache. Unlike the prefix-cache score plugin, it doesn't depend on the requests previously routed by the router
+// replica: the pods are ranked by rendezvous hashing, all the replicas pick the same pod for a key, and only the keys
+// of a removed pod move when the pods change.
+type PrefixCacheAware struct{}
+
+func NewPrefixCacheAware() *PrefixCacheAware {
+	return &PrefixCacheAware{}
+}
+
+func (p *PrefixCacheAware) Name() string {
+	return PrefixCacheAwareBalancerName
+}
+
+func (p *PrefixCacheAware) Pick(ctx *framework.Context, pods []*datastore.PodInfo, n int) []*datastore.PodInfo {
+	key := balancingKey(ctx)
+	if key == "" {
+		return nil
+	}
+	type rankedPod struct {
+		pod    *datastore.PodInfo
+		weight uint64
+	}
+	ranked := make([]rankedPod, 0, len(pods))
+	for _, pod := range pods {
+		if pod.Pod == nil {
+			continue
+		}
+		ranked = append(ranked, rankedPod{pod: pod, weight: xxhash.Sum64String(key + "\x00" + pod.Pod.Namespace + "/" + pod.Pod.Name)})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		return ranked[i].weight > ranked[j].weight
+	})
+	picked := make([]*datastore.PodInfo, 0, min(n, len(ranked)))
+	for i := 0; i < len(ranked) && i < n; i++ {
+		picked = append(picked, ranked[i].pod)
+	}
+	return picked
+}
+
+// balancingKey returns the key the request is balanced on: its session id if set, otherwise its leading system
+// messages, or the beginning of its prompt.
+func balancingKey(ctx *framework.Context) string {
+	if ctx.SessionID != "" {
+		return "session\x00" + ctx.SessionID
+	}
+	var prefix strings.Builder
+	for _, message := range ctx.Prompt.Messages {
+		if message.Role != "system" && message.Role != "developer" {
+			break
+		}
+		prefix.WriteString(message.Content)
+	}
+	if prefix.Len() == 0 && len(ctx.Prompt.Messages) > 0 {
+		prefix.WriteString(ctx.Prompt.Messages[0].Content)
+	}
+	if prefix.Len() == 0 {
+		prefix.WriteString(ctx.Prompt.Text)
+	}
+	if prefix.Len() == 0 {
+		return ""
+	}
+	key := prefix.String()
+	if len(key) > prefixKeyLength {
+		key = key[:prefixKeyLength]
+	}
+	return "prefix\x00" + ctx.Model + "\x00" + key
+}
@@ -0,0 +1,139 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+)
+
+func newPrefixCacheAwarePods(n int) []*datastore.PodInfo {
+	pods := make([]*datastore.PodInfo, 0, n)
+	for i := 0; i < n; i++ {
+		pods = append(pods, &datastore.PodInfo{
+			Pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i), Namespace: "default"}},
+		})
+	}
+	return pods
+}
+
+func chat(system, user string) common.ChatMessage {
+	return common.ChatMessage{Messages: []common.Message{
+		{Role: "system", Content: system},
+		{Role: "user", Content: user},
+	}}
+}
+
+func TestPrefixCacheAware_Pick(t *testing.T) {
+	balancer := NewPrefixCacheAware()
+	assert.Equal(t, PrefixCacheAwareBalancerName, balancer.Name())
+	pods := newPrefixCacheAwarePods(8)
+
+	t.Run("shared system prompt", func(t *testing.T) {
+		picked := balancer.Pick(&framework.Context{Model: "m", Prompt: chat("You are a helpful assistant.", "Hello")}, pods, 3)
+		require.Len(t, picked, 3)
+		for i := 0; i < 20; i++ {
+			other := balancer.Pick(&framework.Context{Model: "m", Prompt: chat("You are a helpful assistant.", fmt.Sprintf("Question %d", i))}, pods, 3)
+			assert.Equal(t, picked, other)
+		}
+	})
+
+	t.Run("session", func(t *testing.T) {
+		picked := balancer.Pick(&framework.Context{SessionID: "s-1", Prompt: chat("a", "b")}, pods, 1)
+		other := balancer.Pick(&framework.Context{SessionID: "s-1", Prompt: chat("c", "d")}, pods, 1)
+		assert.Equal(t, picked, other)
+	})
+
+	t.Run("requests are spread", func(t *testing.T) {
+		selected := map[string]bool{}
+		for i := 0; i < 100; i++ {
+			picked := balancer.Pick(&framework.Context{SessionID: fmt.Sprintf("s-%d", i)}, pods, 1)
+			selected[picked[0].Pod.Name] = true
+		}
+		assert.Len(t, selected, len(pods))
+	})
+
+	t.Run("removing another pod keeps the pick", func(t *testing.T) {
+		ctx := &framework.Context{Prompt: common.ChatMessage{Text: "Translate to French: cheese"}}
+		picked := balancer.Pick(ctx, pods, 1)[0]
+		var remaining []*datastore.PodInfo
+		for _, pod := range pods {
+			if pod != picked {
+				remaining = append(remaining, pod)
+			}
+		}
+		for i := range remaining {
+			withoutOne := append(append([]*datastore.PodInfo{}, remaining[:i]...), remaining[i+1:]...)
+			assert.Equal(t, picked, balancer.Pick(ctx, append(withoutOne, picked), 1)[0])
+		}
+	})
+
+	t.Run("nothing to balance on", func(t *testing.T) {
+		assert.Empty(t, balancer.Pick(&framework.Context{}, pods, 1))
+	})
+}
+
+func TestBalancingKey(t *testing.T) {
+	long := make([]byte, 2*prefixKeyLength)
+	for i := range long {
+		long[i] = 'a'
+	}
+	tests := []struct {
+		name string
+		ctx  *framework.Context
+		want string
+	}{
+		{
+			name: "session id",
+			ctx:  &framework.Context{Model: "m", SessionID: "s-1", Prompt: chat("system", "user")},
+			want: "session\x00s-1",
+		},
+		{
+			name: "system messages",
+			ctx: &framework.Context{Model: "m", Prompt: common.ChatMessage{Messages: []common.Message{
+				{Role: "system", Content: "one "},
+				{Role: "developer", Content: "two"},
+				{Role: "user", Content: "three"},
+			}}},
+			want: "prefix\x00m\x00one two",
+		},
+		{
+			name: "first message",
+			ctx:  &framework.Context{Model: "m", Prompt: common.ChatMessage{Messages: []common.Message{{Role: "user", Content: "hi"}}}},
+			want: "prefix\x00m\x00hi",
+		},
+		{
+			name: "truncated prompt",
+			ctx:  &framework.Context{Model: "m", Prompt: common.ChatMessage{Text: string(long)}},
+			want: "prefix\x00m\x00" + string(long[:prefixKeyLength]),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, balancingKey(tt.ctx))
+		})
+	}
+}
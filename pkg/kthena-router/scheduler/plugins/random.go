@@ -71,3 +71,29 @@ func (r *Random) Score(ctx *framework.Context, pods []*datastore.PodInfo) map[*d
 
 	return scoreResults
 }
+
+const RandomBalancerName = "random"
+
+var _ framework.LoadBalancer = &RandomBalancer{}
+
+// RandomBalancer picks random pods.
+type RandomBalancer struct{}
+
+func NewRandomBalancer() *RandomBalancer {
+	return &RandomBalancer{}
+}
+
+func (r *RandomBalancer) Name() string {
+	return RandomBalancerName
+}
+
+func (r *RandomBalancer) Pick(ctx *framework.Context, pods []*datastore.PodInfo, n int) []*datastore.PodInfo {
+	picked := make([]*datastore.PodInfo, 0, min(n, len(pods)))
+	for _, i := range rand.Perm(len(pods)) {
+		if len(picked) == n {
+			break
+		}
+		picked = append(picked, pods[i])
+	}
+	return picked
+}
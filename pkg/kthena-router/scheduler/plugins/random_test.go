@@ -88,3 +88,31 @@ func TestRandom_Score(t *testing.T) {
 		t.Log("Warning: All scores were identical across two runs, which is unlikely but possible")
 	}
 }
+
+func TestRandomBalancer_Pick(t *testing.T) {
+	balancer := NewRandomBalancer()
+	if balancer.Name() != RandomBalancerName {
+		t.Errorf("Expected balancer name %s, got %s", RandomBalancerName, balancer.Name())
+	}
+	pods := []*datastore.PodInfo{
+		{Pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1"}}},
+		{Pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod2"}}},
+		{Pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod3"}}},
+	}
+
+	selected := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		picked := balancer.Pick(&framework.Context{}, pods, 2)
+		if len(picked) != 2 || picked[0] == picked[1] {
+			t.Fatalf("Expected 2 distinct pods, got %v", picked)
+		}
+		selected[picked[0].Pod.Name] = true
+	}
+	if len(selected) != len(pods) {
+		t.Errorf("Expected all the pods to be picked first, got %v", selected)
+	}
+
+	if picked := balancer.Pick(&framework.Context{}, pods, 5); len(picked) != len(pods) {
+		t.Errorf("Expected %d pods, got %d", len(pods), len(picked))
+	}
+}
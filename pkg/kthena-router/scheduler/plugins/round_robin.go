@@ -0,0 +1,69 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+)
+
+const RoundRobinBalancerName = "round-robin"
+
+var _ framework.LoadBalancer = &RoundRobin{}
+
+// RoundRobin picks the pods of a model server in turn, in the order of their names.
+type RoundRobin struct {
+	// next is the index of the next pod to pick, by model server.
+	next sync.Map // types.NamespacedName -> *atomic.Uint64
+}
+
+func NewRoundRobin() *RoundRobin {
+	return &RoundRobin{}
+}
+
+func (r *RoundRobin) Name() string {
+	return RoundRobinBalancerName
+}
+
+func (r *RoundRobin) Pick(ctx *framework.Context, pods []*datastore.PodInfo, n int) []*datastore.PodInfo {
+	sorted := make([]*datastore.PodInfo, 0, len(pods))
+	for _, pod := range pods {
+		if pod.Pod != nil {
+			sorted = append(sorted, pod)
+		}
+	}
+	if len(sorted) == 0 {
+		return nil
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Pod.Namespace != sorted[j].Pod.Namespace {
+			return sorted[i].Pod.Namespace < sorted[j].Pod.Namespace
+		}
+		return sorted[i].Pod.Name < sorted[j].Pod.Name
+	})
+	value, _ := r.next.LoadOrStore(ctx.ModelServerName, &atomic.Uint64{})
+	start := int((value.(*atomic.Uint64).Add(1) - 1) % uint64(len(sorted)))
+	picked := make([]*datastore.PodInfo, 0, min(n, len(sorted)))
+	for i := 0; i < len(sorted) && i < n; i++ {
+		picked = append(picked, sorted[(start+i)%len(sorted)])
+	}
+	return picked
+}
@@ -0,0 +1,52 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+)
+
+func podNames(pods []*datastore.PodInfo) []string {
+	names := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		names = append(names, pod.Pod.Name)
+	}
+	return names
+}
+
+func TestRoundRobin_Pick(t *testing.T) {
+	balancer := NewRoundRobin()
+	assert.Equal(t, RoundRobinBalancerName, balancer.Name())
+	pods := newPrefixCacheAwarePods(3)
+	reversed := []*datastore.PodInfo{pods[2], pods[1], pods[0]}
+	ctx := &framework.Context{ModelServerName: types.NamespacedName{Namespace: "default", Name: "ms"}}
+	other := &framework.Context{ModelServerName: types.NamespacedName{Namespace: "default", Name: "other"}}
+
+	assert.Equal(t, []string{"pod-0", "pod-1"}, podNames(balancer.Pick(ctx, pods, 2)))
+	assert.Equal(t, []string{"pod-1", "pod-2"}, podNames(balancer.Pick(ctx, reversed, 2)))
+	// Each model server has its own turn.
+	assert.Equal(t, []string{"pod-0"}, podNames(balancer.Pick(other, pods, 1)))
+	assert.Equal(t, []string{"pod-2", "pod-0"}, podNames(balancer.Pick(ctx, pods, 2)))
+	assert.Equal(t, []string{"pod-0", "pod-1", "pod-2"}, podNames(balancer.Pick(ctx, pods, 5)))
+	assert.Empty(t, balancer.Pick(ctx, nil, 1))
+}
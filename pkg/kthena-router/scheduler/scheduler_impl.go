@@ -25,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
@@ -44,6 +45,12 @@ type SchedulerImpl struct {
 	scorePlugins  []*scorePlugin
 
 	postScheduleHooks []framework.PostScheduleHook
+
+	// loadBalancers pick the pods of the model servers with a load balancing policy, by policy.
+	loadBalancers map[aiv1alpha1.LoadBalancingPolicy]framework.LoadBalancer
+
+	// experiments are the schedulers of the requests opted in an experiment, by experiment name.
+	experiments map[string]*SchedulerImpl
 }
 
 type scorePlugin struct {
@@ -87,6 +94,28 @@ func NewScheduler(store datastore.Store, routerConfig *conf.RouterConfiguration)
 		}
 	}
 
+	s := newScheduler(store, registry, scorePluginMap, filterPluginMap, pluginsArgMap)
+	if routerConfig == nil {
+		return s
+	}
+	for _, experiment := range routerConfig.Experiments {
+		if experiment.Name == "" {
+			klog.Fatal("failed to Load Scheduler: experiment name is empty")
+		}
+		if _, ok := s.experiments[experiment.Name]; ok {
+			klog.Fatalf("failed to Load Scheduler: duplicate experiment %q", experiment.Name)
+		}
+		scorePluginMap, filterPluginMap, pluginsArgMap, err := conf.LoadSchedulerConfig(&experiment.Scheduler)
+		if err != nil {
+			klog.Fatalf("failed to Load Scheduler of experiment %q: %v", experiment.Name, err)
+		}
+		s.experiments[experiment.Name] = newScheduler(store, registry, scorePluginMap, filterPluginMap, pluginsArgMap)
+		klog.Infof("experiment %q is enabled", experiment.Name)
+	}
+	return s
+}
+
+func newScheduler(store datastore.Store, registry *PluginRegistry, scorePluginMap map[string]int, filterPluginMap []string, pluginsArgMap map[string]runtime.RawExtension) *SchedulerImpl {
 	prefixCache := plugins.NewPrefixCache(store, pluginsArgMap[plugins.PrefixCachePluginName])
 	return &SchedulerImpl{
 		store:         store,
@@ -95,10 +124,21 @@ func NewScheduler(store datastore.Store, routerConfig *conf.RouterConfiguration)
 		postScheduleHooks: []framework.PostScheduleHook{
 			prefixCache,
 		},
+		loadBalancers: map[aiv1alpha1.LoadBalancingPolicy]framework.LoadBalancer{
+			aiv1alpha1.LoadBalancingPolicyPrefixCacheAware: plugins.NewPrefixCacheAware(),
+			aiv1alpha1.LoadBalancingPolicyLeastLatency:     plugins.NewLeastObservedLatency(),
+			aiv1alpha1.LoadBalancingPolicyRoundRobin:       plugins.NewRoundRobin(),
+			aiv1alpha1.LoadBalancingPolicyRandom:           plugins.NewRandomBalancer(),
+		},
+		experiments: map[string]*SchedulerImpl{},
 	}
 }
 
 func (s *SchedulerImpl) Schedule(ctx *framework.Context, pods []*datastore.PodInfo) error {
+	if experiment, ok := s.experiments[ctx.Experiment]; ok {
+		return experiment.Schedule(ctx, pods)
+	}
+
 	// first filter out invalid pods that wonot be selected to loadbalance to.
 	pods, err := s.RunFilterPlugins(pods, ctx)
 	if err != nil {
@@ -120,11 +160,8 @@ func (s *SchedulerImpl) Schedule(ctx *framework.Context, pods []*datastore.PodIn
 		}
 
 		klog.V(4).Info("Running score plugins for decode pod")
-		scores := s.RunScorePlugins(decodePods, ctx)
-
-		topNDecodePods := TopNPodInfos(scores, topN)
-		ctx.DecodePods = topNDecodePods
-		prefillPods := make([]*datastore.PodInfo, len(topNDecodePods))
+		ctx.DecodePods = s.pickPods(ctx, decodePods, topN)
+		prefillPods := make([]*datastore.PodInfo, len(ctx.DecodePods))
 		validPairs := 0
 
 		for i, decodePod := range ctx.DecodePods {
@@ -140,8 +177,7 @@ func (s *SchedulerImpl) Schedule(ctx *framework.Context, pods []*datastore.PodIn
 			}
 
 			klog.V(4).Info("Running score plugins for prefill pod")
-			scores = s.RunScorePlugins(selectedPods, ctx)
-			bestPrefillPod := TopNPodInfos(scores, 1)
+			bestPrefillPod := s.pickPods(ctx, selectedPods, 1)
 			if len(bestPrefillPod) == 0 {
 				klog.V(4).InfoS("no valid prefill pods after scoring, skipping",
 					"decode instance", klog.KObj(decodePod.Pod))
@@ -158,12 +194,24 @@ func (s *SchedulerImpl) Schedule(ctx *framework.Context, pods []*datastore.PodIn
 	}
 
 	klog.V(4).Info("Running score plugins for PD aggregated pod")
-	scores := s.RunScorePlugins(pods, ctx)
-	ctx.BestPods = TopNPodInfos(scores, topN)
+	ctx.BestPods = s.pickPods(ctx, pods, topN)
 
 	return nil
 }
 
+// pickPods returns the n best pods for the request, picked by the load balancing policy of its model server if any,
+// otherwise by the score plugins.
+func (s *SchedulerImpl) pickPods(ctx *framework.Context, pods []*datastore.PodInfo, n int) []*datastore.PodInfo {
+	pods = warmUp(ctx.SlowStart, pods, time.Now())
+	if balancer, ok := s.loadBalancers[ctx.LoadBalancingPolicy]; ok {
+		if picked := balancer.Pick(ctx, pods, n); len(picked) > 0 {
+			klog.V(4).Infof("LoadBalancer: %s picked %d pods", balancer.Name(), len(picked))
+			return picked
+		}
+	}
+	return TopNPodInfos(s.RunScorePlugins(pods, ctx), n)
+}
+
 func (s *SchedulerImpl) RunFilterPlugins(pods []*datastore.PodInfo, ctx *framework.Context) ([]*datastore.PodInfo, error) {
 	for _, filterPlugin := range s.filterPlugins {
 		// Record filter plugin execution time
@@ -223,6 +271,10 @@ func (s *SchedulerImpl) RunScorePlugins(pods []*datastore.PodInfo, ctx *framewor
 }
 
 func (s *SchedulerImpl) RunPostHooks(ctx *framework.Context, index int) {
+	if experiment, ok := s.experiments[ctx.Experiment]; ok {
+		experiment.RunPostHooks(ctx, index)
+		return
+	}
 	for _, hook := range s.postScheduleHooks {
 		hook.PostSchedule(ctx, index)
 	}
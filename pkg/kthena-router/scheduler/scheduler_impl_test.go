@@ -18,6 +18,7 @@ package scheduler
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -28,6 +29,7 @@ import (
 	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
 	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/framework"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/scheduler/plugins/conf"
 )
 
 // TestTopNPodInfos tests the TopNPodInfos function
@@ -301,6 +303,79 @@ func TestRunScorePluginsEdgeCases(t *testing.T) {
 	}
 }
 
+// TestScheduleExperiment verifies that requests opted in an experiment are scheduled with its configuration
+func TestScheduleExperiment(t *testing.T) {
+	store := datastore.New()
+	routerConfig := &conf.RouterConfiguration{
+		Experiments: []conf.ExperimentConfig{
+			{
+				Name: "random",
+				Scheduler: conf.SchedulerConfiguration{
+					Plugins: conf.Plugins{
+						Score: conf.Score{Enabled: []conf.PluginWithWeight{{Name: "random", Weight: 1}}},
+					},
+				},
+			},
+		},
+	}
+	scheduler := NewScheduler(store, routerConfig).(*SchedulerImpl)
+	require.Contains(t, scheduler.experiments, "random")
+	pods := []*datastore.PodInfo{createTestPodInfo("pod1"), createTestPodInfo("pod2")}
+
+	// The default configuration has no score plugin, no pod is selected
+	ctx := &framework.Context{}
+	require.NoError(t, scheduler.Schedule(ctx, pods))
+	assert.Empty(t, ctx.BestPods)
+
+	ctx = &framework.Context{Experiment: "random"}
+	require.NoError(t, scheduler.Schedule(ctx, pods))
+	assert.Len(t, ctx.BestPods, 2)
+
+	// Experiments which are not configured fall back to the default configuration
+	ctx = &framework.Context{Experiment: "unknown"}
+	require.NoError(t, scheduler.Schedule(ctx, pods))
+	assert.Empty(t, ctx.BestPods)
+}
+
+// TestScheduleLoadBalancingPolicy verifies that the load balancing policy of the model server replaces the score plugins
+func TestScheduleLoadBalancingPolicy(t *testing.T) {
+	scheduler := NewScheduler(datastore.New(), &conf.RouterConfiguration{}).(*SchedulerImpl)
+	pods := []*datastore.PodInfo{createTestPodInfo("pod1"), createTestPodInfo("pod2"), createTestPodInfo("pod3")}
+
+	ctx := &framework.Context{SessionID: "session-1", LoadBalancingPolicy: aiv1alpha1.LoadBalancingPolicyPrefixCacheAware}
+	require.NoError(t, scheduler.Schedule(ctx, pods))
+	require.Len(t, ctx.BestPods, 3)
+	for i := 0; i < 5; i++ {
+		again := &framework.Context{SessionID: "session-1", LoadBalancingPolicy: aiv1alpha1.LoadBalancingPolicyPrefixCacheAware}
+		require.NoError(t, scheduler.Schedule(again, pods))
+		assert.Equal(t, ctx.BestPods, again.BestPods)
+	}
+
+	// A request without session id nor prompt is scheduled with the score plugins, of which there are none
+	ctx = &framework.Context{LoadBalancingPolicy: aiv1alpha1.LoadBalancingPolicyPrefixCacheAware}
+	require.NoError(t, scheduler.Schedule(ctx, pods))
+	assert.Empty(t, ctx.BestPods)
+
+	// The pods are picked in turn by round robin, and by their observed latency
+	var names []string
+	for i := 0; i < 4; i++ {
+		ctx = &framework.Context{LoadBalancingPolicy: aiv1alpha1.LoadBalancingPolicyRoundRobin}
+		require.NoError(t, scheduler.Schedule(ctx, pods))
+		require.NotEmpty(t, ctx.BestPods)
+		names = append(names, ctx.BestPods[0].Pod.Name)
+	}
+	assert.Equal(t, []string{"pod1", "pod2", "pod3", "pod1"}, names)
+
+	pods[0].RecordTTFT(200 * time.Millisecond)
+	pods[1].RecordTTFT(100 * time.Millisecond)
+	pods[2].RecordTTFT(300 * time.Millisecond)
+	ctx = &framework.Context{LoadBalancingPolicy: aiv1alpha1.LoadBalancingPolicyLeastLatency}
+	require.NoError(t, scheduler.Schedule(ctx, pods))
+	require.Len(t, ctx.BestPods, 3)
+	assert.Equal(t, "pod2", ctx.BestPods[0].Pod.Name)
+	assert.Equal(t, "pod1", ctx.BestPods[1].Pod.Name)
+}
+
 // Helper function to create test PodInfo
 func createTestPodInfo(name string) *datastore.PodInfo {
 	return &datastore.PodInfo{
@@ -0,0 +1,72 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"math/rand/v2"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+)
+
+// defaultSlowStartMinPercentage is the share of the requests of a pod which just became ready, in percent.
+const defaultSlowStartMinPercentage = 10
+
+// warmUp skips each pod warming up under the slow start of its model server with a probability decreasing linearly
+// over the window since it became ready, so that its share of the requests ramps up. The pods are all kept if they
+// are all skipped.
+func warmUp(slowStart *aiv1alpha1.SlowStart, pods []*datastore.PodInfo, now time.Time) []*datastore.PodInfo {
+	if slowStart == nil || slowStart.Window.Duration <= 0 {
+		return pods
+	}
+	minShare := float64(defaultSlowStartMinPercentage) / 100
+	if slowStart.MinPercentage != nil {
+		minShare = float64(*slowStart.MinPercentage) / 100
+	}
+	kept := make([]*datastore.PodInfo, 0, len(pods))
+	for _, pod := range pods {
+		share := 1.0
+		if readyTime, ok := readySince(pod.Pod); ok {
+			if elapsed := now.Sub(readyTime); elapsed < slowStart.Window.Duration {
+				share = minShare + (1-minShare)*float64(elapsed)/float64(slowStart.Window.Duration)
+			}
+		}
+		if share >= 1 || rand.Float64() < share {
+			kept = append(kept, pod)
+		}
+	}
+	if len(kept) == 0 {
+		return pods
+	}
+	return kept
+}
+
+// readySince returns the time the pod became ready, false if it is not ready or doesn't report it.
+func readySince(pod *corev1.Pod) (time.Time, bool) {
+	if pod == nil {
+		return time.Time{}, false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+			return condition.LastTransitionTime.Time, true
+		}
+	}
+	return time.Time{}, false
+}
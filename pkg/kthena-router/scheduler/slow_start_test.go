@@ -0,0 +1,110 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+)
+
+func readyPod(name string, readyTime time.Time) *datastore.PodInfo {
+	return &datastore.PodInfo{Pod: &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{
+			Type:               corev1.PodReady,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(readyTime),
+		}}},
+	}}
+}
+
+func TestWarmUp(t *testing.T) {
+	now := time.Now()
+	warm := readyPod("warm", now.Add(-time.Hour))
+	unknown := &datastore.PodInfo{Pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "unknown"}}}
+	cold := readyPod("cold", now)
+	window := metav1.Duration{Duration: 10 * time.Minute}
+
+	tests := []struct {
+		name      string
+		slowStart *aiv1alpha1.SlowStart
+		pods      []*datastore.PodInfo
+		want      []*datastore.PodInfo
+	}{
+		{
+			name: "no slow start",
+			pods: []*datastore.PodInfo{warm, cold},
+			want: []*datastore.PodInfo{warm, cold},
+		},
+		{
+			name:      "pod which just became ready skipped",
+			slowStart: &aiv1alpha1.SlowStart{Window: window, MinPercentage: ptr.To[int32](0)},
+			pods:      []*datastore.PodInfo{warm, unknown, cold},
+			want:      []*datastore.PodInfo{warm, unknown},
+		},
+		{
+			name:      "pod ready since the window is warm",
+			slowStart: &aiv1alpha1.SlowStart{Window: metav1.Duration{Duration: time.Minute}, MinPercentage: ptr.To[int32](0)},
+			pods:      []*datastore.PodInfo{readyPod("ready", now.Add(-time.Minute))},
+			want:      []*datastore.PodInfo{readyPod("ready", now.Add(-time.Minute))},
+		},
+		{
+			name:      "pods kept when all warming up",
+			slowStart: &aiv1alpha1.SlowStart{Window: window, MinPercentage: ptr.To[int32](0)},
+			pods:      []*datastore.PodInfo{cold},
+			want:      []*datastore.PodInfo{cold},
+		},
+		{
+			name:      "full minimum share",
+			slowStart: &aiv1alpha1.SlowStart{Window: window, MinPercentage: ptr.To[int32](100)},
+			pods:      []*datastore.PodInfo{warm, cold},
+			want:      []*datastore.PodInfo{warm, cold},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, warmUp(tt.slowStart, tt.pods, now))
+		})
+	}
+}
+
+func TestWarmUpRampsShare(t *testing.T) {
+	now := time.Now()
+	slowStart := &aiv1alpha1.SlowStart{Window: metav1.Duration{Duration: 10 * time.Minute}}
+	warm := readyPod("warm", now.Add(-time.Hour))
+	// Ready since half the window, the pod gets 10% + 90% * 0.5 = 55% of its requests.
+	warming := readyPod("warming", now.Add(-5*time.Minute))
+
+	const requests = 10000
+	kept := 0
+	for range requests {
+		for _, pod := range warmUp(slowStart, []*datastore.PodInfo{warm, warming}, now) {
+			if pod == warming {
+				kept++
+			}
+		}
+	}
+	assert.InDelta(t, 0.55, float64(kept)/requests, 0.05)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package semcache caches the responses of the router by the meaning of their prompts. The prompts are embedded by an
+// embedding model, and a prompt whose embedding is similar enough to the one of a cached prompt is answered with the
+// cached response.
+package semcache
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// DefaultSimilarityThreshold is the minimum cosine similarity of two prompts for one to be answered by the
+	// response of the other.
+	DefaultSimilarityThreshold = 0.95
+	// DefaultTTL is how long a response is cached.
+	DefaultTTL = time.Hour
+	// DefaultMaxEntries is the maximum number of responses cached in a scope.
+	DefaultMaxEntries = 1000
+	// DefaultMaxTotalEntries is the maximum number of responses cached by a MemoryStore in all the scopes.
+	DefaultMaxTotalEntries = 100000
+)
+
+// Cache looks up the responses of the prompts similar to a prompt.
+type Cache struct {
+	embedder  Embedder
+	store     Store
+	threshold float64
+}
+
+// New creates a cache embedding the prompts with the embedder and keeping the responses in the store.
+func New(embedder Embedder, store Store, threshold float64) *Cache {
+	if threshold <= 0 {
+		threshold = DefaultSimilarityThreshold
+	}
+	return &Cache{embedder: embedder, store: store, threshold: threshold}
+}
+
+// Lookup returns the cached response of the prompt of the scope most similar to the prompt, nil if none is similar
+// enough. The embedding of the prompt is returned to store its response with.
+func (c *Cache) Lookup(ctx context.Context, scope, prompt string) ([]byte, []float32, error) {
+	embedding, err := c.embedder.Embed(ctx, prompt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to embed prompt: %w", err)
+	}
+	response, err := c.store.Search(ctx, scope, embedding, c.threshold)
+	if err != nil {
+		return nil, embedding, fmt.Errorf("failed to search cached responses: %w", err)
+	}
+	return response, embedding, nil
+}
+
+// Store caches the response of the prompt of the given embedding in the scope.
+func (c *Cache) Store(ctx context.Context, scope string, embedding []float32, response []byte) error {
+	if err := c.store.Add(ctx, scope, embedding, response); err != nil {
+		return fmt.Errorf("failed to cache response: %w", err)
+	}
+	return nil
+}
+
+// similarity returns the cosine similarity of two embeddings, 0 if their dimensions differ.
+func similarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package semcache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Embedder embeds the prompts.
+type Embedder interface {
+	// Embed returns the embedding of the text.
+	Embed(ctx context.Context, text string) ([]float32, error)
+}
+
+// HTTPEmbedder embeds the prompts with an OpenAI compatible embeddings API.
+type HTTPEmbedder struct {
+	client   *http.Client
+	endpoint string
+	model    string
+}
+
+var _ Embedder = &HTTPEmbedder{}
+
+// NewHTTPEmbedder creates an embedder calling the embeddings API at endpoint with the model.
+func NewHTTPEmbedder(client *http.Client, endpoint, model string) *HTTPEmbedder {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPEmbedder{client: client, endpoint: endpoint, model: model}
+}
+
+type embeddingRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (e *HTTPEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	body, err := json.Marshal(embeddingRequest{Model: e.model, Input: text})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("embeddings API returned %d: %s", resp.StatusCode, message)
+	}
+	var embeddings embeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&embeddings); err != nil {
+		return nil, fmt.Errorf("failed to decode embeddings: %w", err)
+	}
+	if len(embeddings.Data) == 0 || len(embeddings.Data[0].Embedding) == 0 {
+		return nil, fmt.Errorf("embeddings API returned no embedding")
+	}
+	return embeddings.Data[0].Embedding, nil
+}
@@ -0,0 +1,172 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package semcache
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEmbedder embeds the prompts with fixed embeddings.
+type fakeEmbedder map[string][]float32
+
+func (e fakeEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	return e[text], nil
+}
+
+func TestSimilarity(t *testing.T) {
+	assert.InDelta(t, 1, similarity([]float32{1, 2}, []float32{2, 4}), 1e-9)
+	assert.InDelta(t, 0, similarity([]float32{1, 0}, []float32{0, 1}), 1e-9)
+	assert.InDelta(t, -1, similarity([]float32{1, 0}, []float32{-1, 0}), 1e-9)
+	assert.Zero(t, similarity([]float32{1, 0}, []float32{1, 0, 0}), "the dimensions differ")
+	assert.Zero(t, similarity([]float32{0, 0}, []float32{1, 0}))
+}
+
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	response, err := store.Search(ctx, "model/alice", []float32{1, 0}, 0.9)
+	require.NoError(t, err)
+	assert.Nil(t, response)
+
+	require.NoError(t, store.Add(ctx, "model/alice", []float32{1, 0}, []byte("east")))
+	require.NoError(t, store.Add(ctx, "model/alice", []float32{0, 1}, []byte("north")))
+
+	response, err = store.Search(ctx, "model/alice", []float32{1, 0.1}, 0.9)
+	require.NoError(t, err)
+	assert.Equal(t, "east", string(response))
+	response, err = store.Search(ctx, "model/alice", []float32{0.2, 1}, 0.9)
+	require.NoError(t, err)
+	assert.Equal(t, "north", string(response))
+	response, err = store.Search(ctx, "model/alice", []float32{1, 1}, 0.9)
+	require.NoError(t, err)
+	assert.Nil(t, response, "no prompt is similar enough")
+	response, err = store.Search(ctx, "model/bob", []float32{1, 0}, 0.9)
+	require.NoError(t, err)
+	assert.Nil(t, response, "the responses of a scope are not served to the others")
+
+	// The oldest response is evicted beyond the maximum number of entries.
+	require.NoError(t, store.Add(ctx, "model/alice", []float32{-1, 0}, []byte("west")))
+	response, err = store.Search(ctx, "model/alice", []float32{1, 0}, 0.9)
+	require.NoError(t, err)
+	assert.Nil(t, response)
+	response, err = store.Search(ctx, "model/alice", []float32{-1, 0}, 0.9)
+	require.NoError(t, err)
+	assert.Equal(t, "west", string(response))
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore(time.Minute, 2, 0)
+	testStore(t, store)
+
+	now := time.Now()
+	store.now = func() time.Time { return now.Add(time.Minute) }
+	response, err := store.Search(context.Background(), "model/alice", []float32{-1, 0}, 0.9)
+	require.NoError(t, err)
+	assert.Nil(t, response, "the responses expire")
+	assert.Empty(t, store.scopes)
+}
+
+func TestMemoryStoreTotalEntries(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(time.Minute, 2, 3)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	// The oldest responses of all the scopes are evicted beyond the maximum number of entries of the store.
+	for _, scope := range []string{"model/alice/1", "model/alice/2", "model/bob/1", "model/bob/2"} {
+		require.NoError(t, store.Add(ctx, scope, []float32{1, 0}, []byte(scope)))
+	}
+	assert.Equal(t, 3, store.entries.Len())
+	assert.NotContains(t, store.scopes, "model/alice/1")
+	response, err := store.Search(ctx, "model/alice/1", []float32{1, 0}, 0.9)
+	require.NoError(t, err)
+	assert.Nil(t, response)
+	response, err = store.Search(ctx, "model/bob/2", []float32{1, 0}, 0.9)
+	require.NoError(t, err)
+	assert.Equal(t, "model/bob/2", string(response))
+
+	// The expired responses of the scopes no longer used are removed when another scope is used.
+	store.now = func() time.Time { return now.Add(time.Minute) }
+	require.NoError(t, store.Add(ctx, "model/carol/1", []float32{1, 0}, []byte("model/carol/1")))
+	assert.Equal(t, 1, store.entries.Len())
+	assert.Len(t, store.scopes, 1)
+	assert.Contains(t, store.scopes, "model/carol/1")
+}
+
+func TestRedisStore(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	testStore(t, NewRedisStore(client, time.Minute, 2))
+	assert.Equal(t, []float32{1.5, -2}, decodeEmbedding(encodeEmbedding([]float32{1.5, -2})))
+}
+
+func TestCache(t *testing.T) {
+	embedder := fakeEmbedder{
+		"what is the capital of France?":     {1, 0.1, 0},
+		"what's the capital city of France?": {1, 0.12, 0},
+		"how tall is the Eiffel Tower?":      {0, 1, 1},
+	}
+	cache := New(embedder, NewMemoryStore(0, 0, 0), 0)
+	ctx := context.Background()
+
+	response, embedding, err := cache.Lookup(ctx, "model", "what is the capital of France?")
+	require.NoError(t, err)
+	assert.Nil(t, response)
+	require.NoError(t, cache.Store(ctx, "model", embedding, []byte("Paris")))
+
+	response, _, err = cache.Lookup(ctx, "model", "what's the capital city of France?")
+	require.NoError(t, err)
+	assert.Equal(t, "Paris", string(response))
+	response, _, err = cache.Lookup(ctx, "model", "how tall is the Eiffel Tower?")
+	require.NoError(t, err)
+	assert.Nil(t, response)
+}
+
+func TestHTTPEmbedder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request embeddingRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		if request.Input == "" {
+			http.Error(w, "empty input", http.StatusBadRequest)
+			return
+		}
+		assert.Equal(t, "embedder", request.Model)
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"object":"embedding","index":0,"embedding":[0.5,-0.25]}]}`))
+	}))
+	defer server.Close()
+
+	embedder := NewHTTPEmbedder(nil, server.URL+"/v1/embeddings", "embedder")
+	embedding, err := embedder.Embed(context.Background(), "hello")
+	require.NoError(t, err)
+	assert.Equal(t, []float32{0.5, -0.25}, embedding)
+
+	_, err = embedder.Embed(context.Background(), "")
+	assert.ErrorContains(t, err, "returned 400")
+}
@@ -0,0 +1,250 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package semcache
+
+import (
+	"container/list"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Store keeps the cached responses with the embeddings of their prompts, in scopes whose responses are never served
+// to the others.
+type Store interface {
+	// Search returns the response of the scope whose embedding is the most similar to the embedding, nil if none has
+	// a similarity of at least threshold.
+	Search(ctx context.Context, scope string, embedding []float32, threshold float64) ([]byte, error)
+	// Add caches a response of the scope.
+	Add(ctx context.Context, scope string, embedding []float32, response []byte) error
+}
+
+type entry struct {
+	scope     string
+	embedding []float32
+	response  []byte
+	expires   time.Time
+}
+
+// MemoryStore is a Store of the router replica. The oldest responses of a scope are evicted beyond its maximum
+// number of entries, and the oldest responses of all the scopes beyond the maximum number of entries of the store.
+type MemoryStore struct {
+	mu sync.Mutex
+	// entries holds the entries of all the scopes from the oldest, which is also the first one to expire.
+	entries         *list.List
+	scopes          map[string][]*list.Element
+	ttl             time.Duration
+	maxEntries      int
+	maxTotalEntries int
+	now             func() time.Time
+}
+
+var _ Store = &MemoryStore{}
+
+// NewMemoryStore creates a store caching up to maxEntries responses of each scope and maxTotalEntries responses of
+// all the scopes for ttl.
+func NewMemoryStore(ttl time.Duration, maxEntries, maxTotalEntries int) *MemoryStore {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	if maxTotalEntries <= 0 {
+		maxTotalEntries = DefaultMaxTotalEntries
+	}
+	return &MemoryStore{
+		entries:         list.New(),
+		scopes:          make(map[string][]*list.Element),
+		ttl:             ttl,
+		maxEntries:      maxEntries,
+		maxTotalEntries: maxTotalEntries,
+		now:             time.Now,
+	}
+}
+
+func (s *MemoryStore) Search(_ context.Context, scope string, embedding []float32, threshold float64) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeExpired()
+	var best []byte
+	bestSimilarity := threshold
+	for _, elem := range s.scopes[scope] {
+		e := elem.Value.(*entry)
+		if sim := similarity(embedding, e.embedding); sim >= bestSimilarity {
+			best, bestSimilarity = e.response, sim
+		}
+	}
+	return best, nil
+}
+
+func (s *MemoryStore) Add(_ context.Context, scope string, embedding []float32, response []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeExpired()
+	elem := s.entries.PushBack(&entry{scope: scope, embedding: embedding, response: response, expires: s.now().Add(s.ttl)})
+	s.scopes[scope] = append(s.scopes[scope], elem)
+	if len(s.scopes[scope]) > s.maxEntries {
+		s.removeOldest(scope)
+	}
+	for s.entries.Len() > s.maxTotalEntries {
+		s.removeOldest(s.entries.Front().Value.(*entry).scope)
+	}
+	return nil
+}
+
+// removeExpired removes the expired entries of all the scopes, so that the scopes no longer used don't keep their
+// entries. The caller must hold the mutex.
+func (s *MemoryStore) removeExpired() {
+	now := s.now()
+	for front := s.entries.Front(); front != nil && !front.Value.(*entry).expires.After(now); front = s.entries.Front() {
+		s.removeOldest(front.Value.(*entry).scope)
+	}
+}
+
+// removeOldest removes the oldest entry of the scope, and the scope with its last entry. The caller must hold the
+// mutex.
+func (s *MemoryStore) removeOldest(scope string) {
+	elems := s.scopes[scope]
+	s.entries.Remove(elems[0])
+	if len(elems) == 1 {
+		delete(s.scopes, scope)
+		return
+	}
+	s.scopes[scope] = elems[1:]
+}
+
+const (
+	keyPrefix = "kthena:semcache:"
+
+	fieldEmbedding = "embedding"
+	fieldResponse  = "response"
+)
+
+// RedisStore is a Store shared by the router replicas through Redis. The entries of a scope are indexed by a sorted
+// set scoring them by expiry time, the entries evicted from the index expire with their ttl.
+type RedisStore struct {
+	client     *redis.Client
+	ttl        time.Duration
+	maxEntries int
+}
+
+var _ Store = &RedisStore{}
+
+// NewRedisStore creates a store caching up to maxEntries responses of each scope for ttl.
+func NewRedisStore(client *redis.Client, ttl time.Duration, maxEntries int) *RedisStore {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &RedisStore{client: client, ttl: ttl, maxEntries: maxEntries}
+}
+
+func indexKey(scope string) string {
+	return keyPrefix + scope
+}
+
+func entryKey(scope, id string) string {
+	return keyPrefix + scope + ":" + id
+}
+
+func (s *RedisStore) Search(ctx context.Context, scope string, embedding []float32, threshold float64) ([]byte, error) {
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	ids, err := s.client.ZRangeByScore(ctx, indexKey(scope), &redis.ZRangeBy{Min: "(" + now, Max: "+inf"}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cached responses of %s: %w", scope, err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	pipe := s.client.Pipeline()
+	embeddings := make([]*redis.StringCmd, len(ids))
+	for i, id := range ids {
+		embeddings[i] = pipe.HGet(ctx, entryKey(scope, id), fieldEmbedding)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to get cached embeddings of %s: %w", scope, err)
+	}
+	best := ""
+	bestSimilarity := threshold
+	for i, cmd := range embeddings {
+		data, err := cmd.Bytes()
+		if err != nil {
+			// The entry expired since it was listed.
+			continue
+		}
+		if sim := similarity(embedding, decodeEmbedding(data)); sim >= bestSimilarity {
+			best, bestSimilarity = ids[i], sim
+		}
+	}
+	if best == "" {
+		return nil, nil
+	}
+	response, err := s.client.HGet(ctx, entryKey(scope, best), fieldResponse).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached response of %s: %w", scope, err)
+	}
+	return response, nil
+}
+
+func (s *RedisStore) Add(ctx context.Context, scope string, embedding []float32, response []byte) error {
+	// The ids of version 7 sort by creation time, so that the entries added in the same millisecond are evicted in order.
+	id, err := uuid.NewV7()
+	if err != nil {
+		return fmt.Errorf("failed to generate cache entry id: %w", err)
+	}
+	now := time.Now()
+	pipe := s.client.TxPipeline()
+	pipe.HSet(ctx, entryKey(scope, id.String()), fieldEmbedding, encodeEmbedding(embedding), fieldResponse, response)
+	pipe.Expire(ctx, entryKey(scope, id.String()), s.ttl)
+	pipe.ZAdd(ctx, indexKey(scope), redis.Z{Score: float64(now.Add(s.ttl).UnixMilli()), Member: id.String()})
+	pipe.ZRemRangeByScore(ctx, indexKey(scope), "-inf", strconv.FormatInt(now.UnixMilli(), 10))
+	pipe.ZRemRangeByRank(ctx, indexKey(scope), 0, int64(-s.maxEntries-1))
+	pipe.Expire(ctx, indexKey(scope), s.ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to cache response of %s: %w", scope, err)
+	}
+	return nil
+}
+
+func encodeEmbedding(embedding []float32) []byte {
+	data := make([]byte, 4*len(embedding))
+	for i, v := range embedding {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(v))
+	}
+	return data
+}
+
+func decodeEmbedding(data []byte) []float32 {
+	embedding := make([]float32, len(data)/4)
+	for i := range embedding {
+		embedding[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return embedding
+}
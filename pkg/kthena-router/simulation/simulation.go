@@ -0,0 +1,109 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package simulation replays traffic traces against the components of the router on a fake clock, so that their
+// invariants can be tested deterministically, without sleeping.
+package simulation
+
+import (
+	"sort"
+	"time"
+
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+// Start is the time every simulation starts at.
+var Start = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Arrival is a request of a trace.
+type Arrival struct {
+	// At is the time the request arrives at, from the start of the simulation.
+	At     time.Duration
+	Tenant string
+	// Cost is the number of units consumed by the request, e.g. its tokens.
+	Cost int
+}
+
+// Trace is a list of requests ordered by arrival time.
+type Trace []Arrival
+
+// Steady returns n requests of a tenant arriving every interval from start.
+func Steady(tenant string, start, interval time.Duration, n, cost int) Trace {
+	trace := make(Trace, 0, n)
+	for i := 0; i < n; i++ {
+		trace = append(trace, Arrival{At: start + time.Duration(i)*interval, Tenant: tenant, Cost: cost})
+	}
+	return trace
+}
+
+// Burst returns n requests of a tenant arriving all at once.
+func Burst(tenant string, at time.Duration, n, cost int) Trace {
+	return Steady(tenant, at, 0, n, cost)
+}
+
+// Merge returns the requests of the traces ordered by arrival time. The requests arriving at the same time keep the
+// order of the traces.
+func Merge(traces ...Trace) Trace {
+	var merged Trace
+	for _, trace := range traces {
+		merged = append(merged, trace...)
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].At < merged[j].At })
+	return merged
+}
+
+// End returns the arrival time of the last request of the trace.
+func (t Trace) End() time.Duration {
+	if len(t) == 0 {
+		return 0
+	}
+	return t[len(t)-1].At
+}
+
+// Simulation moves a fake clock through the arrivals of a trace and the ticks of a dispatcher.
+type Simulation struct {
+	Clock *testingclock.FakeClock
+	// Tick is the interval between the dispatches.
+	Tick time.Duration
+}
+
+// New returns a simulation dispatching every tick.
+func New(tick time.Duration) *Simulation {
+	return &Simulation{Clock: testingclock.NewFakeClock(Start), Tick: tick}
+}
+
+// Run calls arrive for each request of the trace and dispatch every tick, with the clock set to their time. The
+// requests arriving at a tick are passed to arrive before dispatch is called. Once the trace is replayed, the ticks go
+// on until dispatch returns false, telling there is nothing left to dispatch. dispatch may be nil.
+func (s *Simulation) Run(trace Trace, arrive func(now time.Time, a Arrival), dispatch func(now time.Time) bool) {
+	nextTick := Start.Add(s.Tick)
+	for i := 0; ; {
+		if i < len(trace) && (dispatch == nil || !Start.Add(trace[i].At).After(nextTick)) {
+			s.Clock.SetTime(Start.Add(trace[i].At))
+			arrive(s.Clock.Now(), trace[i])
+			i++
+			continue
+		}
+		if dispatch == nil {
+			return
+		}
+		s.Clock.SetTime(nextTick)
+		nextTick = nextTick.Add(s.Tick)
+		if busy := dispatch(s.Clock.Now()); !busy && i == len(trace) {
+			return
+		}
+	}
+}
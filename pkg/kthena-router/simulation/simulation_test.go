@@ -0,0 +1,61 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulation
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	trace := Merge(Steady("a", 0, time.Second, 3, 1), Burst("b", time.Second, 2, 5))
+	var got []string
+	for _, a := range trace {
+		got = append(got, fmt.Sprintf("%s@%v", a.Tenant, a.At))
+	}
+	assert.Equal(t, []string{"a@0s", "a@1s", "b@1s", "b@1s", "a@2s"}, got)
+	assert.Equal(t, 2*time.Second, trace.End())
+}
+
+func TestRun(t *testing.T) {
+	s := New(time.Second)
+	var events []string
+	pending := 0
+	s.Run(Steady("a", 500*time.Millisecond, 500*time.Millisecond, 3, 1),
+		func(now time.Time, a Arrival) {
+			events = append(events, fmt.Sprintf("arrive@%v", now.Sub(Start)))
+			pending++
+		},
+		func(now time.Time) bool {
+			events = append(events, fmt.Sprintf("dispatch@%v", now.Sub(Start)))
+			if pending > 0 {
+				pending--
+			}
+			return pending > 0
+		})
+	assert.Equal(t, []string{
+		"arrive@500ms", "arrive@1s", "dispatch@1s", "arrive@1.5s", "dispatch@2s", "dispatch@3s",
+	}, events)
+
+	// Without a dispatcher, the clock only goes through the arrivals.
+	s = New(time.Second)
+	s.Run(Burst("a", time.Minute, 2, 1), func(time.Time, Arrival) {}, nil)
+	assert.Equal(t, Start.Add(time.Minute), s.Clock.Now())
+}
@@ -0,0 +1,326 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package slo computes how fast the error budgets of the service level objectives declared on ModelRoutes are
+// consumed, per model and window, so that alerting can be driven off error budgets rather than static thresholds.
+package slo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+)
+
+const (
+	// ObjectiveAvailability is the share of the requests not failing with a server error.
+	ObjectiveAvailability = "availability"
+	// ObjectiveLatency is the share of the successful requests served within the latency threshold.
+	ObjectiveLatency = "latency"
+
+	// bucketDuration is the resolution the requests are counted at.
+	bucketDuration = time.Minute
+	// refreshInterval is how often the burn rates are exported.
+	refreshInterval = 30 * time.Second
+)
+
+// Windows are the windows the burn rates are exported over, sorted. They are those of the multiwindow, multi-burn-rate
+// alerts of the Google SRE workbook, e.g. paging when both the 1h and 5m burn rates exceed 14.4.
+var Windows = []time.Duration{
+	5 * time.Minute, 30 * time.Minute, time.Hour, 2 * time.Hour, 6 * time.Hour, 24 * time.Hour, 72 * time.Hour,
+}
+
+type objective struct {
+	name string
+	// target is the target ratio of good requests, e.g. 0.999.
+	target float64
+	// threshold is the latency threshold of the latency objective.
+	threshold time.Duration
+}
+
+// burnRate returns the ratio of bad requests divided by the error budget, 0 if there was no request.
+func (o objective) burnRate(c counts) float64 {
+	bad, valid := c.errors, c.requests
+	if o.name == ObjectiveLatency {
+		bad, valid = c.slow, c.successes
+	}
+	if valid == 0 {
+		return 0
+	}
+	return float64(bad) / float64(valid) / (1 - o.target)
+}
+
+type counts struct {
+	requests  uint64
+	errors    uint64
+	successes uint64
+	slow      uint64
+}
+
+// bucket counts the requests completed within a minute.
+type bucket struct {
+	minute    int64
+	requests  uint32
+	errors    uint32
+	successes uint32
+	slow      uint32
+}
+
+// series counts the requests of a model over the longest window, in a ring of buckets.
+type series struct {
+	buckets []bucket
+}
+
+func newSeries() *series {
+	return &series{buckets: make([]bucket, Windows[len(Windows)-1]/bucketDuration)}
+}
+
+func (s *series) bucket(minute int64) *bucket {
+	b := &s.buckets[minute%int64(len(s.buckets))]
+	if b.minute != minute {
+		*b = bucket{minute: minute}
+	}
+	return b
+}
+
+// totals returns the requests counted over each of the Windows ending at minute.
+func (s *series) totals(minute int64) []counts {
+	result := make([]counts, len(Windows))
+	var sum counts
+	w := 0
+	for i := int64(0); i < int64(len(s.buckets)); i++ {
+		if b := &s.buckets[(minute-i)%int64(len(s.buckets))]; b.minute == minute-i {
+			sum.requests += uint64(b.requests)
+			sum.errors += uint64(b.errors)
+			sum.successes += uint64(b.successes)
+			sum.slow += uint64(b.slow)
+		}
+		for w < len(Windows) && int64(Windows[w]/bucketDuration) == i+1 {
+			result[w] = sum
+			w++
+		}
+	}
+	return result
+}
+
+type route struct {
+	objectives []objective
+	// models are the request counts of the models requested through the route.
+	models map[string]*series
+}
+
+// Tracker counts the requests of the ModelRoutes declaring service level objectives and exports the burn rates of
+// their error budgets. It implements accesslog.AccessLogger so that it is fed with every completed request.
+type Tracker struct {
+	metrics *metrics.Metrics
+	now     func() time.Time
+
+	mu sync.Mutex
+	// routes are the tracked ModelRoutes by namespace/name.
+	routes map[string]*route
+}
+
+var _ accesslog.AccessLogger = &Tracker{}
+
+func NewTracker(m *metrics.Metrics) *Tracker {
+	return &Tracker{
+		metrics: m,
+		now:     time.Now,
+		routes:  make(map[string]*route),
+	}
+}
+
+// SetObjectives tracks the service level objectives of the ModelRoute, it stops tracking it if it declares none.
+func (t *Tracker) SetObjectives(modelRoute *aiv1alpha1.ModelRoute) {
+	key := modelRoute.Namespace + "/" + modelRoute.Name
+	objectives := parseObjectives(modelRoute)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(objectives) == 0 {
+		t.deleteLocked(key)
+		return
+	}
+	r, ok := t.routes[key]
+	if !ok {
+		r = &route{models: make(map[string]*series)}
+		t.routes[key] = r
+	} else if !slices.Equal(r.objectives, objectives) {
+		// Drop the series of the removed objectives, the others are exported again on the next refresh.
+		t.deleteMetrics(prometheus.Labels{metrics.LabelModelRoute: key})
+	}
+	r.objectives = objectives
+}
+
+// Delete stops tracking the ModelRoute.
+func (t *Tracker) Delete(modelRoute *aiv1alpha1.ModelRoute) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.deleteLocked(modelRoute.Namespace + "/" + modelRoute.Name)
+}
+
+func (t *Tracker) deleteLocked(key string) {
+	if _, ok := t.routes[key]; !ok {
+		return
+	}
+	delete(t.routes, key)
+	t.deleteMetrics(prometheus.Labels{metrics.LabelModelRoute: key})
+}
+
+func (t *Tracker) deleteMetrics(labels prometheus.Labels) {
+	t.metrics.SLOTarget.DeletePartialMatch(labels)
+	t.metrics.SLOBurnRate.DeletePartialMatch(labels)
+}
+
+func parseObjectives(modelRoute *aiv1alpha1.ModelRoute) []objective {
+	slo := modelRoute.Spec.SLO
+	if slo == nil {
+		return nil
+	}
+	var objectives []objective
+	if slo.Availability != nil {
+		target, err := parseTarget(*slo.Availability)
+		if err != nil {
+			klog.Warningf("Invalid availability objective of ModelRoute %s/%s: %v", modelRoute.Namespace, modelRoute.Name, err)
+		} else {
+			objectives = append(objectives, objective{name: ObjectiveAvailability, target: target})
+		}
+	}
+	if slo.Latency != nil {
+		target, err := parseTarget(slo.Latency.Target)
+		if err == nil && slo.Latency.Threshold.Duration <= 0 {
+			err = fmt.Errorf("threshold must be positive, got %v", slo.Latency.Threshold.Duration)
+		}
+		if err != nil {
+			klog.Warningf("Invalid latency objective of ModelRoute %s/%s: %v", modelRoute.Namespace, modelRoute.Name, err)
+		} else {
+			objectives = append(objectives, objective{name: ObjectiveLatency, target: target, threshold: slo.Latency.Threshold.Duration})
+		}
+	}
+	return objectives
+}
+
+// parseTarget converts a target percentage to a ratio.
+func parseTarget(value string) (float64, error) {
+	percent, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid target %q: %v", value, err)
+	}
+	if percent <= 0 || percent >= 100 {
+		return 0, fmt.Errorf("target must be between 0 and 100 exclusive, got %q", value)
+	}
+	return percent / 100, nil
+}
+
+// Log counts a request completed through a tracked ModelRoute. Server errors are bad requests for the availability
+// objective, successful requests slower than the threshold are bad requests for the latency objective.
+func (t *Tracker) Log(entry *accesslog.AccessLogEntry) error {
+	if entry == nil || entry.ModelRoute == "" || entry.StatusCode == 0 {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	r, ok := t.routes[entry.ModelRoute]
+	if !ok {
+		return nil
+	}
+	s, ok := r.models[entry.ModelName]
+	if !ok {
+		s = newSeries()
+		r.models[entry.ModelName] = s
+	}
+	b := s.bucket(t.now().Unix() / int64(bucketDuration/time.Second))
+	b.requests++
+	switch {
+	case entry.StatusCode >= 500:
+		b.errors++
+	case entry.StatusCode < 400:
+		b.successes++
+		for _, o := range r.objectives {
+			if o.name == ObjectiveLatency && time.Duration(entry.DurationTotal)*time.Millisecond > o.threshold {
+				b.slow++
+			}
+		}
+	}
+	return nil
+}
+
+func (t *Tracker) Close() error {
+	return nil
+}
+
+// Refresh exports the objectives and burn rates of the tracked models. The models without request over the longest
+// window are no longer exported.
+func (t *Tracker) Refresh() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	minute := t.now().Unix() / int64(bucketDuration/time.Second)
+	for key, r := range t.routes {
+		for model, s := range r.models {
+			totals := s.totals(minute)
+			if totals[len(totals)-1].requests == 0 {
+				delete(r.models, model)
+				t.deleteMetrics(prometheus.Labels{metrics.LabelModel: model, metrics.LabelModelRoute: key})
+				continue
+			}
+			for _, o := range r.objectives {
+				t.metrics.SLOTarget.WithLabelValues(model, key, o.name).Set(o.target)
+				for i, window := range Windows {
+					t.metrics.SLOBurnRate.WithLabelValues(model, key, o.name, windowLabel(window)).Set(o.burnRate(totals[i]))
+				}
+			}
+		}
+	}
+}
+
+// Run refreshes the exported burn rates until the context is cancelled.
+func (t *Tracker) Run(ctx context.Context) {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.Refresh()
+		}
+	}
+}
+
+// windowLabel formats a window as in Prometheus range selectors, e.g. 5m, 1h or 3d.
+func windowLabel(window time.Duration) string {
+	switch {
+	case window%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", window/(24*time.Hour))
+	case window%time.Hour == 0:
+		return fmt.Sprintf("%dh", window/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", window/time.Minute)
+	}
+}
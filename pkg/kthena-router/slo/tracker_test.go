@@ -0,0 +1,131 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slo
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/accesslog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/metrics"
+)
+
+func modelRoute(name string, slo *aiv1alpha1.SLO) *aiv1alpha1.ModelRoute {
+	return &aiv1alpha1.ModelRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       aiv1alpha1.ModelRouteSpec{ModelName: "llama", SLO: slo},
+	}
+}
+
+func completed(route string, statusCode int, duration time.Duration) *accesslog.AccessLogEntry {
+	return &accesslog.AccessLogEntry{
+		ModelName:     "llama",
+		ModelRoute:    "default/" + route,
+		StatusCode:    statusCode,
+		DurationTotal: duration.Milliseconds(),
+	}
+}
+
+func burnRate(route, objective, window string) float64 {
+	return testutil.ToFloat64(metrics.DefaultMetrics.SLOBurnRate.WithLabelValues("llama", "default/"+route, objective, window))
+}
+
+func TestTracker(t *testing.T) {
+	now := time.Now()
+	tracker := NewTracker(metrics.DefaultMetrics)
+	tracker.now = func() time.Time { return now }
+	tracker.SetObjectives(modelRoute("burn", &aiv1alpha1.SLO{
+		Availability: ptr.To("99"),
+		Latency: &aiv1alpha1.LatencyObjective{
+			Threshold: metav1.Duration{Duration: time.Second},
+			Target:    "90",
+		},
+	}))
+	tracker.SetObjectives(modelRoute("untracked", nil))
+
+	// An hour ago: 100 successful requests, 20 of them slow.
+	now = now.Add(-time.Hour)
+	for i := 0; i < 100; i++ {
+		duration := 100 * time.Millisecond
+		if i < 20 {
+			duration = 2 * time.Second
+		}
+		assert.NoError(t, tracker.Log(completed("burn", http.StatusOK, duration)))
+	}
+	// Now: 90 successful requests, 5 server errors and 5 client errors.
+	now = now.Add(time.Hour)
+	for i := 0; i < 90; i++ {
+		assert.NoError(t, tracker.Log(completed("burn", http.StatusOK, 100*time.Millisecond)))
+	}
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, tracker.Log(completed("burn", http.StatusServiceUnavailable, 0)))
+		assert.NoError(t, tracker.Log(completed("burn", http.StatusBadRequest, 0)))
+		assert.NoError(t, tracker.Log(completed("untracked", http.StatusServiceUnavailable, 0)))
+	}
+	tracker.Refresh()
+
+	assert.Equal(t, 0.99, testutil.ToFloat64(metrics.DefaultMetrics.SLOTarget.WithLabelValues("llama", "default/burn", ObjectiveAvailability)))
+	// 5 errors out of 100 requests with a 1% budget.
+	assert.InDelta(t, 5, burnRate("burn", ObjectiveAvailability, "5m"), 1e-9)
+	assert.InDelta(t, 5, burnRate("burn", ObjectiveAvailability, "1h"), 1e-9)
+	// 5 errors out of 200 requests.
+	assert.InDelta(t, 2.5, burnRate("burn", ObjectiveAvailability, "2h"), 1e-9)
+	assert.InDelta(t, 0, burnRate("burn", ObjectiveLatency, "5m"), 1e-9)
+	// 20 slow requests out of 190 successful ones with a 10% budget.
+	assert.InDelta(t, 20.0/190/0.1, burnRate("burn", ObjectiveLatency, "3d"), 1e-9)
+	assert.Equal(t, 2*len(Windows), testutil.CollectAndCount(metrics.DefaultMetrics.SLOBurnRate))
+
+	// Dropping the latency objective stops exporting its burn rates.
+	tracker.SetObjectives(modelRoute("burn", &aiv1alpha1.SLO{Availability: ptr.To("99.9")}))
+	tracker.Refresh()
+	assert.InDelta(t, 50, burnRate("burn", ObjectiveAvailability, "5m"), 1e-9)
+	assert.Equal(t, len(Windows), testutil.CollectAndCount(metrics.DefaultMetrics.SLOBurnRate))
+
+	// The models without request within the longest window are no longer exported.
+	now = now.Add(Windows[len(Windows)-1])
+	tracker.Refresh()
+	assert.Equal(t, 0, testutil.CollectAndCount(metrics.DefaultMetrics.SLOBurnRate))
+
+	tracker.Delete(modelRoute("burn", nil))
+	assert.Empty(t, tracker.routes)
+}
+
+func TestParseObjectives(t *testing.T) {
+	objectives := parseObjectives(modelRoute("invalid", &aiv1alpha1.SLO{
+		Availability: ptr.To("100"),
+		Latency: &aiv1alpha1.LatencyObjective{
+			Threshold: metav1.Duration{Duration: 2 * time.Second},
+			Target:    "99.5",
+		},
+	}))
+	assert.Equal(t, []objective{{name: ObjectiveLatency, target: 0.995, threshold: 2 * time.Second}}, objectives)
+}
+
+func TestWindowLabel(t *testing.T) {
+	var labels []string
+	for _, window := range Windows {
+		labels = append(labels, windowLabel(window))
+	}
+	assert.Equal(t, []string{"5m", "30m", "1h", "2h", "6h", "1d", "3d"}, labels)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Path is the endpoint serving the snapshots of the state of the router.
+const Path = "/v1alpha1/snapshot"
+
+// Handler serves the current snapshot on GET Path. With the watch=true query, the snapshots are streamed as
+// newline-delimited JSON, the current one first and then each new one. The current snapshot is not sent if the
+// revision query is its revision, e.g. when a watcher reconnects.
+func (p *Publisher) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		snapshot, changed := p.Current()
+		if watch, _ := strconv.ParseBool(c.Query("watch")); !watch {
+			c.JSON(http.StatusOK, snapshot)
+			return
+		}
+		var revision uint64
+		if value := c.Query("revision"); value != "" {
+			var err error
+			if revision, err = strconv.ParseUint(value, 10, 64); err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid revision " + value})
+				return
+			}
+		}
+
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Cache-Control", "no-cache")
+		c.Status(http.StatusOK)
+		c.Writer.Flush()
+		encoder := json.NewEncoder(c.Writer)
+		for {
+			if snapshot.Revision != revision {
+				if err := encoder.Encode(snapshot); err != nil {
+					return
+				}
+				c.Writer.Flush()
+				revision = snapshot.Revision
+			}
+			select {
+			case <-c.Request.Context().Done():
+				return
+			case <-changed:
+			}
+			snapshot, changed = p.Current()
+		}
+	}
+}
@@ -0,0 +1,239 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package snapshot publishes the state of the router, i.e. the models it serves, their ModelServers and the health and
+// load of their endpoints, for the external systems consuming it, e.g. custom schedulers, dashboards or capacity tools.
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/catalog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+)
+
+// DefaultInterval is the default interval the state of the router is checked for changes at.
+const DefaultInterval = time.Second
+
+// Snapshot is the state of the router at a point in time.
+type Snapshot struct {
+	// Revision increases with every change of the state.
+	Revision uint64 `json:"revision"`
+	// Timestamp is when the state changed to this snapshot.
+	Timestamp time.Time `json:"timestamp"`
+	State
+}
+
+// State is what the router knows of the models it serves.
+type State struct {
+	// Models are the models served, as listed by the /v1/models endpoint.
+	Models       []catalog.Model `json:"models"`
+	ModelServers []ModelServer   `json:"modelServers"`
+	Endpoints    []Endpoint      `json:"endpoints"`
+}
+
+// ModelServer is a ModelServer and its endpoints.
+type ModelServer struct {
+	Namespace       string `json:"namespace"`
+	Name            string `json:"name"`
+	Model           string `json:"model,omitempty"`
+	InferenceEngine string `json:"inferenceEngine"`
+	// Endpoints are the pods of the ModelServer, as namespace/name.
+	Endpoints []string `json:"endpoints"`
+	// ReadyEndpoints is the number of ready pods of the ModelServer.
+	ReadyEndpoints int `json:"readyEndpoints"`
+}
+
+// Endpoint is a pod the requests are sent to, with the load metrics the router schedules the requests with.
+type Endpoint struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Address   string `json:"address"`
+	NodeName  string `json:"nodeName,omitempty"`
+	Engine    string `json:"engine,omitempty"`
+	Ready     bool   `json:"ready"`
+	// ModelServers are the ModelServers of the pod, as namespace/name.
+	ModelServers []string `json:"modelServers"`
+	// Models are the models discovered on the pod, including the LoRA adapters.
+	Models  []string `json:"models"`
+	Metrics Metrics  `json:"metrics"`
+}
+
+type Metrics struct {
+	GPUCacheUsage     float64 `json:"gpuCacheUsage"`
+	RequestWaitingNum float64 `json:"requestWaitingNum"`
+	RequestRunningNum float64 `json:"requestRunningNum"`
+	TTFT              float64 `json:"ttft"`
+	TPOT              float64 `json:"tpot"`
+}
+
+// Publisher checks the state of the router for changes and notifies the watchers of the new snapshots.
+type Publisher struct {
+	store    datastore.Store
+	catalog  *catalog.Catalog
+	interval time.Duration
+	now      func() time.Time
+
+	mu      sync.RWMutex
+	current Snapshot
+	// state is the JSON encoding of the state of the current snapshot.
+	state []byte
+	// changed is closed when the current snapshot is replaced.
+	changed chan struct{}
+}
+
+func NewPublisher(store datastore.Store, interval time.Duration) *Publisher {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Publisher{
+		store:    store,
+		catalog:  catalog.New(store),
+		interval: interval,
+		now:      time.Now,
+		changed:  make(chan struct{}),
+	}
+}
+
+// Run checks the state for changes until the context is canceled.
+func (p *Publisher) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	p.refresh()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.refresh()
+		}
+	}
+}
+
+// Current returns the current snapshot, and a channel closed when it is replaced.
+func (p *Publisher) Current() (Snapshot, <-chan struct{}) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.current, p.changed
+}
+
+// refresh replaces the current snapshot if the state changed.
+func (p *Publisher) refresh() {
+	state := p.build()
+	data, err := json.Marshal(state)
+	if err != nil {
+		klog.Errorf("failed to encode the state of the router: %v", err)
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.current.Revision != 0 && bytes.Equal(data, p.state) {
+		return
+	}
+	p.current = Snapshot{Revision: p.current.Revision + 1, Timestamp: p.now(), State: state}
+	p.state = data
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
+
+// build returns the current state of the router, sorted by name.
+func (p *Publisher) build() State {
+	state := State{
+		Models:       p.catalog.List(),
+		ModelServers: []ModelServer{},
+		Endpoints:    []Endpoint{},
+	}
+
+	for name, modelServer := range p.store.GetAllModelServers() {
+		entry := ModelServer{
+			Namespace:       name.Namespace,
+			Name:            name.Name,
+			InferenceEngine: string(modelServer.Spec.InferenceEngine),
+			Endpoints:       []string{},
+		}
+		if modelServer.Spec.Model != nil {
+			entry.Model = *modelServer.Spec.Model
+		}
+		pods, _ := p.store.GetPodsByModelServer(name)
+		for _, pod := range pods {
+			entry.Endpoints = append(entry.Endpoints, pod.Pod.Namespace+"/"+pod.Pod.Name)
+			if isReady(pod.Pod) {
+				entry.ReadyEndpoints++
+			}
+		}
+		slices.Sort(entry.Endpoints)
+		state.ModelServers = append(state.ModelServers, entry)
+	}
+	slices.SortFunc(state.ModelServers, func(a, b ModelServer) int {
+		return strings.Compare(a.Namespace+"/"+a.Name, b.Namespace+"/"+b.Name)
+	})
+
+	for name, pod := range p.store.GetAllPods() {
+		if pod.Pod == nil {
+			continue
+		}
+		endpoint := Endpoint{
+			Namespace:    name.Namespace,
+			Name:         name.Name,
+			Address:      pod.Pod.Status.PodIP,
+			NodeName:     pod.Pod.Spec.NodeName,
+			Engine:       pod.GetEngine(),
+			Ready:        isReady(pod.Pod),
+			ModelServers: []string{},
+			Models:       pod.GetModelsList(),
+			Metrics: Metrics{
+				GPUCacheUsage:     pod.GetGPUCacheUsage(),
+				RequestWaitingNum: pod.GetRequestWaitingNum(),
+				RequestRunningNum: pod.GetRequestRunningNum(),
+				TTFT:              pod.GetTTFT(),
+				TPOT:              pod.GetTPOT(),
+			},
+		}
+		for _, modelServer := range pod.GetModelServersList() {
+			endpoint.ModelServers = append(endpoint.ModelServers, modelServer.String())
+		}
+		slices.Sort(endpoint.ModelServers)
+		if endpoint.Models == nil {
+			endpoint.Models = []string{}
+		}
+		slices.Sort(endpoint.Models)
+		state.Endpoints = append(state.Endpoints, endpoint)
+	}
+	slices.SortFunc(state.Endpoints, func(a, b Endpoint) int {
+		return strings.Compare(a.Namespace+"/"+a.Name, b.Namespace+"/"+b.Name)
+	})
+	return state
+}
+
+// isReady reports whether the pod is ready to serve requests.
+func isReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/datastore"
+)
+
+func newStore(t *testing.T) datastore.Store {
+	store := datastore.New()
+	modelServer := &aiv1alpha1.ModelServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama", Namespace: "default"},
+		Spec: aiv1alpha1.ModelServerSpec{
+			Model:           ptr.To("llama-3-8b"),
+			InferenceEngine: "vLLM",
+		},
+	}
+	pods := sets.New(types.NamespacedName{Namespace: "default", Name: "llama-0"}, types.NamespacedName{Namespace: "default", Name: "llama-1"})
+	require.NoError(t, store.AddOrUpdateModelServer(modelServer, pods))
+	for name := range pods {
+		ready := corev1.ConditionTrue
+		if name.Name == "llama-1" {
+			ready = corev1.ConditionFalse
+		}
+		require.NoError(t, store.AddOrUpdatePod(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name.Name, Namespace: "default"},
+			Spec:       corev1.PodSpec{NodeName: "node-0"},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				PodIP:      "10.0.0." + name.Name[len(name.Name)-1:],
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+			},
+		}, []*aiv1alpha1.ModelServer{modelServer}))
+	}
+	require.NoError(t, store.AddOrUpdateModelRoute(&aiv1alpha1.ModelRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama", Namespace: "default"},
+		Spec: aiv1alpha1.ModelRouteSpec{
+			ModelName: "llama",
+			Rules: []*aiv1alpha1.Rule{{
+				TargetModels: []*aiv1alpha1.TargetModel{{ModelServerName: "llama"}},
+			}},
+		},
+	}))
+	return store
+}
+
+func TestPublisherRefresh(t *testing.T) {
+	store := newStore(t)
+	publisher := NewPublisher(store, time.Second)
+	now := time.Date(2026, time.October, 14, 10, 30, 0, 0, time.UTC)
+	publisher.now = func() time.Time { return now }
+
+	publisher.refresh()
+	snapshot, changed := publisher.Current()
+	assert.Equal(t, uint64(1), snapshot.Revision)
+	assert.Equal(t, now, snapshot.Timestamp)
+	require.Len(t, snapshot.Models, 1)
+	assert.Equal(t, "llama", snapshot.Models[0].ID)
+	assert.Equal(t, []ModelServer{{
+		Namespace:       "default",
+		Name:            "llama",
+		Model:           "llama-3-8b",
+		InferenceEngine: "vLLM",
+		Endpoints:       []string{"default/llama-0", "default/llama-1"},
+		ReadyEndpoints:  1,
+	}}, snapshot.ModelServers)
+	require.Len(t, snapshot.Endpoints, 2)
+	assert.Equal(t, Endpoint{
+		Namespace:    "default",
+		Name:         "llama-0",
+		Address:      "10.0.0.0",
+		NodeName:     "node-0",
+		Engine:       "vLLM",
+		Ready:        true,
+		ModelServers: []string{"default/llama"},
+		Models:       []string{},
+	}, snapshot.Endpoints[0])
+	assert.False(t, snapshot.Endpoints[1].Ready)
+
+	// The snapshot is kept while the state doesn't change.
+	publisher.refresh()
+	unchanged, _ := publisher.Current()
+	assert.Equal(t, snapshot, unchanged)
+	select {
+	case <-changed:
+		t.Fatal("the watchers are notified of an unchanged state")
+	default:
+	}
+
+	require.NoError(t, store.DeletePod(types.NamespacedName{Namespace: "default", Name: "llama-1"}))
+	publisher.refresh()
+	<-changed
+	snapshot, _ = publisher.Current()
+	assert.Equal(t, uint64(2), snapshot.Revision)
+	assert.Len(t, snapshot.Endpoints, 1)
+}
+
+func TestHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := newStore(t)
+	publisher := NewPublisher(store, time.Second)
+	publisher.refresh()
+	engine := gin.New()
+	engine.GET(Path, publisher.Handler())
+	server := httptest.NewServer(engine)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + Path)
+	require.NoError(t, err)
+	var snapshot Snapshot
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&snapshot))
+	resp.Body.Close()
+	assert.Equal(t, uint64(1), snapshot.Revision)
+	assert.Len(t, snapshot.Endpoints, 2)
+
+	resp, err = http.Get(server.URL + Path + "?watch=true&revision=invalid")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+Path+"?watch=true", nil)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+	lines := bufio.NewScanner(resp.Body)
+
+	require.True(t, lines.Scan())
+	require.NoError(t, json.Unmarshal(lines.Bytes(), &snapshot))
+	assert.Equal(t, uint64(1), snapshot.Revision)
+
+	require.NoError(t, store.DeletePod(types.NamespacedName{Namespace: "default", Name: "llama-1"}))
+	publisher.refresh()
+	require.True(t, lines.Scan())
+	require.NoError(t, json.Unmarshal(lines.Bytes(), &snapshot))
+	assert.Equal(t, uint64(2), snapshot.Revision)
+	assert.Len(t, snapshot.Endpoints, 1)
+}
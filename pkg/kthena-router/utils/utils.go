@@ -19,6 +19,8 @@ package utils
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/volcano-sh/kthena/pkg/kthena-router/common"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -41,15 +43,13 @@ func GetNamespaceName(obj metav1.Object) types.NamespacedName {
 	}
 }
 
+// ParsePrompt returns the prompt of a completion request or the messages of a chat request. The prompt of the legacy
+// completions API is a string, an array of strings, or the token ids of one or several prompts: the strings are joined
+// by newlines and the token ids are only counted. The content of a chat message is a string or an array of parts: the
+// text parts are joined by newlines and the image parts are returned apart.
 func ParsePrompt(body map[string]interface{}) (common.ChatMessage, error) {
 	if prompt, ok := body["prompt"]; ok {
-		promptStr, ok := prompt.(string)
-		if !ok {
-			return common.ChatMessage{}, fmt.Errorf("prompt is not a string")
-		}
-		return common.ChatMessage{
-			Text: promptStr,
-		}, nil
+		return parseCompletionPrompt(prompt)
 	}
 
 	if messages, ok := body["messages"]; ok {
@@ -58,7 +58,7 @@ func ParsePrompt(body map[string]interface{}) (common.ChatMessage, error) {
 			return common.ChatMessage{}, fmt.Errorf("messages is not a list")
 		}
 
-		var msgs []common.Message
+		var chatMessage common.ChatMessage
 		for _, message := range messageList {
 			msgMap, ok := message.(map[string]interface{})
 			if !ok {
@@ -70,25 +70,100 @@ func ParsePrompt(body map[string]interface{}) (common.ChatMessage, error) {
 				continue
 			}
 
-			content, ok := msgMap["content"].(string)
-			if !ok {
+			var content string
+			switch value := msgMap["content"].(type) {
+			case string:
+				content = value
+			case []interface{}:
+				var images []common.Image
+				content, images = parseContentParts(value)
+				chatMessage.Images = append(chatMessage.Images, images...)
+			default:
 				continue
 			}
 
-			msgs = append(msgs, common.Message{
+			chatMessage.Messages = append(chatMessage.Messages, common.Message{
 				Role:    role,
 				Content: content,
 			})
 		}
 
-		return common.ChatMessage{
-			Messages: msgs,
-		}, nil
+		return chatMessage, nil
 	}
 
 	return common.ChatMessage{}, fmt.Errorf("prompt or messages not found in request body")
 }
 
+// parseContentParts returns the text and the images of the content parts of a chat message. The other parts, e.g.
+// audio, are ignored.
+func parseContentParts(parts []interface{}) (string, []common.Image) {
+	var texts []string
+	var images []common.Image
+	for _, item := range parts {
+		part, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch part["type"] {
+		case "text":
+			if text, ok := part["text"].(string); ok {
+				texts = append(texts, text)
+			}
+		case "image_url":
+			// The image_url is an object with the url and detail of the image, or the url itself for some clients.
+			switch imageURL := part["image_url"].(type) {
+			case string:
+				images = append(images, common.Image{URL: imageURL})
+			case map[string]interface{}:
+				url, _ := imageURL["url"].(string)
+				detail, _ := imageURL["detail"].(string)
+				images = append(images, common.Image{URL: url, Detail: detail})
+			}
+		}
+	}
+	return strings.Join(texts, "\n"), images
+}
+
+func parseCompletionPrompt(prompt interface{}) (common.ChatMessage, error) {
+	if promptStr, ok := prompt.(string); ok {
+		return common.ChatMessage{Text: promptStr}, nil
+	}
+	prompts, ok := prompt.([]interface{})
+	if !ok || len(prompts) == 0 {
+		return common.ChatMessage{}, fmt.Errorf("prompt is not a string, an array of strings or of token ids")
+	}
+	if isTokenIDs(prompts) {
+		return common.ChatMessage{TokenIDs: len(prompts)}, nil
+	}
+	var chatMessage common.ChatMessage
+	texts := make([]string, 0, len(prompts))
+	for _, item := range prompts {
+		switch value := item.(type) {
+		case string:
+			texts = append(texts, value)
+		case []interface{}:
+			if !isTokenIDs(value) {
+				return common.ChatMessage{}, fmt.Errorf("prompt is not a string, an array of strings or of token ids")
+			}
+			chatMessage.TokenIDs += len(value)
+		default:
+			return common.ChatMessage{}, fmt.Errorf("prompt is not a string, an array of strings or of token ids")
+		}
+	}
+	chatMessage.Text = strings.Join(texts, "\n")
+	return chatMessage, nil
+}
+
+// isTokenIDs reports whether the values of a JSON array are all numbers.
+func isTokenIDs(values []interface{}) bool {
+	for _, value := range values {
+		if _, ok := value.(float64); !ok {
+			return false
+		}
+	}
+	return true
+}
+
 func GetPromptString(chatMessage common.ChatMessage) string {
 	// If Text field is present, return text directly (for prompt format)
 	if chatMessage.Text != "" {
@@ -111,3 +186,25 @@ func LoadEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// ParseTenantWeights parses the tenant weights annotation of a ModelRoute, a comma separated list of tenant=weight.
+func ParseTenantWeights(value string) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		tenant, weight, ok := strings.Cut(item, "=")
+		tenant = strings.TrimSpace(tenant)
+		if !ok || tenant == "" {
+			return nil, fmt.Errorf("invalid tenant weight %q, expected tenant=weight", item)
+		}
+		w, err := strconv.ParseFloat(strings.TrimSpace(weight), 64)
+		if err != nil || w <= 0 {
+			return nil, fmt.Errorf("invalid weight of tenant %q: %q must be a positive number", tenant, weight)
+		}
+		weights[tenant] = w
+	}
+	return weights, nil
+}
@@ -20,17 +20,25 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
 	admissionv1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 
 	networkingv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/celmatch"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/utils"
+	"github.com/volcano-sh/kthena/pkg/tlsconfig"
 )
 
 const timeout = 30 * time.Second
@@ -41,15 +49,13 @@ type KthenaRouterValidator struct {
 	kubeClient kubernetes.Interface
 }
 
-// NewKthenaRouterValidator creates a new KthenaRouterValidator.
-func NewKthenaRouterValidator(kubeClient kubernetes.Interface, port int) *KthenaRouterValidator {
+// NewKthenaRouterValidator creates a new KthenaRouterValidator serving with tlsConfig.
+func NewKthenaRouterValidator(kubeClient kubernetes.Interface, port int, tlsConfig *tls.Config) *KthenaRouterValidator {
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", port),
 		ReadTimeout:  timeout,
 		WriteTimeout: timeout,
-		TLSConfig: &tls.Config{
-			MinVersion: tls.VersionTLS12,
-		},
+		TLSConfig:    tlsConfig.Clone(),
 	}
 
 	return &KthenaRouterValidator{
@@ -70,10 +76,18 @@ func (v *KthenaRouterValidator) Run(ctx context.Context, tlsCertFile, tlsPrivate
 	})
 	v.httpServer.Handler = mux
 
+	// The certificate is reloaded when it is rotated
+	reloader, err := tlsconfig.NewCertReloader(tlsCertFile, tlsPrivateKey)
+	if err != nil {
+		klog.Fatalf("failed to load webhook certificate: %v", err)
+	}
+	go reloader.Run(ctx)
+	v.httpServer.TLSConfig = reloader.ServerConfig(v.httpServer.TLSConfig)
+
 	// Start server
 	klog.Infof("Starting webhook server on %s", v.httpServer.Addr)
 	go func() {
-		if err := v.httpServer.ListenAndServeTLS(tlsCertFile, tlsPrivateKey); err != nil && err != http.ErrServerClosed {
+		if err := v.httpServer.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
 			klog.Fatalf("failed to listen and serve validating webhook: %v", err)
 		}
 	}()
@@ -160,8 +174,22 @@ func (v *KthenaRouterValidator) validateModelRoute(modelRoute *networkingv1alpha
 	var allErrs field.ErrorList
 	specField := field.NewPath("spec")
 
-	if modelRoute.Spec.ModelName == "" && len(modelRoute.Spec.LoraAdapters) == 0 {
-		allErrs = append(allErrs, field.Required(specField, "either modelName or loraAdapters must be specified"))
+	if modelRoute.Spec.ModelName == "" && len(modelRoute.Spec.LoraAdapters) == 0 && modelRoute.Spec.ModelNameMatch == nil {
+		allErrs = append(allErrs, field.Required(specField, "either modelName, loraAdapters or modelNameMatch must be specified"))
+	}
+
+	if match := modelRoute.Spec.ModelNameMatch; match != nil {
+		matchField := specField.Child("modelNameMatch")
+		switch {
+		case match.Exact != nil:
+			allErrs = append(allErrs, field.Forbidden(matchField.Child("exact"), "use modelName to match a model exactly"))
+		case (match.Prefix == nil) == (match.Regex == nil):
+			allErrs = append(allErrs, field.Required(matchField, "exactly one of prefix and regex must be set"))
+		case match.Regex != nil:
+			if _, err := regexp.Compile(*match.Regex); err != nil {
+				allErrs = append(allErrs, field.Invalid(matchField.Child("regex"), *match.Regex, fmt.Sprintf("invalid regular expression: %v", err)))
+			}
+		}
 	}
 
 	for i, lora := range modelRoute.Spec.LoraAdapters {
@@ -170,6 +198,107 @@ func (v *KthenaRouterValidator) validateModelRoute(modelRoute *networkingv1alpha
 		}
 	}
 
+	if len(modelRoute.Spec.Aliases) > 0 && modelRoute.Spec.ModelName == "" {
+		allErrs = append(allErrs, field.Required(specField.Child("modelName"), "modelName must be specified to declare aliases"))
+	}
+	for i, alias := range modelRoute.Spec.Aliases {
+		switch {
+		case alias == "":
+			allErrs = append(allErrs, field.Invalid(specField.Child("aliases").Index(i), alias, "alias cannot be an empty string"))
+		case alias == modelRoute.Spec.ModelName || slices.Contains(modelRoute.Spec.LoraAdapters, alias):
+			allErrs = append(allErrs, field.Invalid(specField.Child("aliases").Index(i), alias, "alias must differ from the model name and the lora adapters"))
+		}
+	}
+
+	for i, rule := range modelRoute.Spec.Rules {
+		if rule == nil {
+			continue
+		}
+		allErrs = append(allErrs, validateTargetModelWeights(rule.TargetModels, specField.Child("rules").Index(i).Child("targetModels"))...)
+		if rule.ModelMatch != nil && rule.ModelMatch.Body != nil {
+			allErrs = append(allErrs, validateBodyFields(rule.ModelMatch.Body.Fields, specField.Child("rules").Index(i).Child("modelMatch", "body", "fields"))...)
+		}
+		if rule.ModelMatch != nil && rule.ModelMatch.Expression != nil {
+			if _, err := celmatch.Compile(*rule.ModelMatch.Expression); err != nil {
+				allErrs = append(allErrs, field.Invalid(specField.Child("rules").Index(i).Child("modelMatch", "expression"), *rule.ModelMatch.Expression, fmt.Sprintf("invalid CEL expression: %v", err)))
+			}
+		}
+	}
+
+	if priority := modelRoute.Spec.Priority; priority != nil && priority.InteractiveReservation != nil {
+		if timeZone := priority.InteractiveReservation.BusinessHours.TimeZone; timeZone != "" {
+			if _, err := time.LoadLocation(timeZone); err != nil {
+				allErrs = append(allErrs, field.Invalid(specField.Child("priority", "interactiveReservation", "businessHours", "timeZone"), timeZone, "unknown time zone"))
+			}
+		}
+	}
+
+	if timeouts := modelRoute.Spec.Timeouts; timeouts != nil {
+		timeoutsField := specField.Child("timeouts")
+		for _, timeout := range []struct {
+			name     string
+			duration *metav1.Duration
+		}{
+			{"connect", timeouts.Connect},
+			{"firstToken", timeouts.FirstToken},
+			{"request", timeouts.Request},
+		} {
+			if timeout.duration != nil && timeout.duration.Duration <= 0 {
+				allErrs = append(allErrs, field.Invalid(timeoutsField.Child(timeout.name), timeout.duration.Duration.String(), "timeout must be positive"))
+			}
+		}
+	}
+
+	if hedging := modelRoute.Spec.Hedging; hedging != nil && hedging.Delay.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(specField.Child("hedging", "delay"), hedging.Delay.Duration.String(), "delay must be positive"))
+	}
+
+	if concurrency := modelRoute.Spec.Concurrency; concurrency != nil && concurrency.QueueTimeout != nil && concurrency.QueueTimeout.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(specField.Child("concurrency", "queueTimeout"), concurrency.QueueTimeout.Duration.String(), "queue timeout must be positive"))
+	}
+
+	if affinity := modelRoute.Spec.SessionAffinity; affinity != nil && affinity.TTL != nil && affinity.TTL.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(specField.Child("sessionAffinity", "ttl"), affinity.TTL.Duration.String(), "ttl must be positive"))
+	}
+
+	if mirror := modelRoute.Spec.Mirror; mirror != nil {
+		for _, rule := range modelRoute.Spec.Rules {
+			if rule != nil && slices.ContainsFunc(rule.TargetModels, func(target *networkingv1alpha1.TargetModel) bool {
+				return target != nil && target.ModelServerName == mirror.ModelServerName
+			}) {
+				allErrs = append(allErrs, field.Invalid(specField.Child("mirror", "modelServerName"), mirror.ModelServerName, "the requests can't be mirrored to a target model server of the rules"))
+				break
+			}
+		}
+	}
+
+	if evaluation := modelRoute.Spec.Evaluation; evaluation != nil {
+		if sink, err := url.Parse(evaluation.URL); err != nil || sink.Host == "" {
+			allErrs = append(allErrs, field.Invalid(specField.Child("evaluation", "url"), evaluation.URL, "url must be an absolute http or https URL"))
+		}
+		if evaluation.Timeout != nil && evaluation.Timeout.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(specField.Child("evaluation", "timeout"), evaluation.Timeout.Duration.String(), "timeout must be positive"))
+		}
+	}
+
+	if rollout := modelRoute.Spec.Rollout; rollout != nil {
+		allErrs = append(allErrs, validateRollout(rollout, modelRoute.Spec.Rules, specField.Child("rollout"))...)
+		if rollout.MinEvaluationScore != nil && modelRoute.Spec.Evaluation == nil {
+			allErrs = append(allErrs, field.Invalid(specField.Child("rollout", "minEvaluationScore"), *rollout.MinEvaluationScore, "the evaluation score requires the evaluation of the responses"))
+		}
+	}
+
+	if value, ok := modelRoute.Annotations[networkingv1alpha1.TenantWeightsAnnotationKey]; ok {
+		if _, err := utils.ParseTenantWeights(value); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "annotations").Key(networkingv1alpha1.TenantWeightsAnnotationKey), value, err.Error()))
+		}
+	}
+
+	return validationResult(allErrs)
+}
+
+// validationResult returns whether a resource is allowed and the reason it is not from its validation errors.
+func validationResult(allErrs field.ErrorList) (bool, string) {
 	if len(allErrs) > 0 {
 		var messages []string
 		for _, err := range allErrs {
@@ -180,9 +309,155 @@ func (v *KthenaRouterValidator) validateModelRoute(modelRoute *networkingv1alpha
 	return true, ""
 }
 
+// validateRollout checks that the canary of a rollout replaces a target model server of the rules with increasing steps.
+func validateRollout(rollout *networkingv1alpha1.Rollout, rules []*networkingv1alpha1.Rule, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if !slices.ContainsFunc(rules, func(rule *networkingv1alpha1.Rule) bool {
+		return rule != nil && slices.ContainsFunc(rule.TargetModels, func(target *networkingv1alpha1.TargetModel) bool {
+			return target != nil && target.ModelServerName == rollout.StableModelServerName
+		})
+	}) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("stableModelServerName"), rollout.StableModelServerName, "the stable model server must be a target model server of the rules"))
+	}
+	if rollout.CanaryModelServerName == rollout.StableModelServerName {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("canaryModelServerName"), rollout.CanaryModelServerName, "the canary model server must differ from the stable one"))
+	}
+	for i := 1; i < len(rollout.Steps); i++ {
+		if rollout.Steps[i] <= rollout.Steps[i-1] {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("steps").Index(i), rollout.Steps[i], "steps must be increasing"))
+			break
+		}
+	}
+	if rollout.Interval != nil && rollout.Interval.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("interval"), rollout.Interval.Duration.String(), "interval must be positive"))
+	}
+	return allErrs
+}
+
+// validateTargetModelWeights checks that the weights of the target models of a rule are either all set or all unset,
+// and that the weights don't add up to zero.
+func validateTargetModelWeights(targets []*networkingv1alpha1.TargetModel, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	weighted := 0
+	var totalWeight uint32
+	for _, target := range targets {
+		if target != nil && target.Weight != nil {
+			weighted++
+			totalWeight += *target.Weight
+		}
+	}
+	switch {
+	case weighted == 0:
+	case weighted != len(targets):
+		for i, target := range targets {
+			if target != nil && target.Weight == nil {
+				allErrs = append(allErrs, field.Required(fldPath.Index(i).Child("weight"), "weight must be set on all the target models of the rule or on none of them"))
+			}
+		}
+	case totalWeight == 0:
+		allErrs = append(allErrs, field.Forbidden(fldPath, "the weights of the target models must not all be zero"))
+	}
+	return allErrs
+}
+
+// validateBodyFields validates the body field matches of a rule.
+func validateBodyFields(fields []networkingv1alpha1.BodyFieldMatch, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, match := range fields {
+		fieldPath := fldPath.Index(i)
+		if slices.Contains(strings.Split(match.Path, "."), "") {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("path"), match.Path, "path must be dot-separated field names or array indexes"))
+		}
+		if match.Value != nil && match.Present != nil {
+			allErrs = append(allErrs, field.Forbidden(fieldPath, "value and present are mutually exclusive"))
+		}
+		if value := match.Value; value != nil && value.Regex != nil {
+			if _, err := regexp.Compile(*value.Regex); err != nil {
+				allErrs = append(allErrs, field.Invalid(fieldPath.Child("value", "regex"), *value.Regex, fmt.Sprintf("invalid regular expression: %v", err)))
+			}
+		}
+	}
+	return allErrs
+}
+
 // validateModelServer validates the ModelServer resource
-func (v *KthenaRouterValidator) validateModelServer(*networkingv1alpha1.ModelServer) (bool, string) {
-	return true, ""
+func (v *KthenaRouterValidator) validateModelServer(modelServer *networkingv1alpha1.ModelServer) (bool, string) {
+	var allErrs field.ErrorList
+	if slowStart := modelServer.Spec.SlowStart; slowStart != nil && slowStart.Window.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "slowStart", "window"), slowStart.Window.Duration.String(), "window must be positive"))
+	}
+	if coalescing := modelServer.Spec.PrefillCoalescing; coalescing != nil && coalescing.MaxWait.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "prefillCoalescing", "maxWait"), coalescing.MaxWait.Duration.String(), "maxWait must be positive"))
+	}
+	if warmUp := modelServer.Spec.WarmUp; warmUp != nil && warmUp.Timeout != nil && warmUp.Timeout.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "warmUp", "timeout"), warmUp.Timeout.Duration.String(), "timeout must be positive"))
+	}
+	if policy := modelServer.Spec.TrafficPolicy; policy != nil && policy.Retry != nil && policy.Retry.Budget != nil && policy.Retry.Budget.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "trafficPolicy", "retry", "budget"), policy.Retry.Budget.Duration.String(), "budget must be positive"))
+	}
+	if selector := modelServer.Spec.WorkloadSelector; selector != nil {
+		for i, requirement := range selector.MatchExpressions {
+			allErrs = append(allErrs, metav1validation.ValidateLabelSelectorRequirement(requirement, metav1validation.LabelSelectorValidationOptions{},
+				field.NewPath("spec", "workloadSelector", "matchExpressions").Index(i))...)
+		}
+	}
+	switch {
+	case modelServer.Spec.WorkloadSelector == nil && modelServer.Spec.Discovery == nil:
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "workloadSelector"), "workloadSelector or discovery must be set"))
+	case modelServer.Spec.WorkloadSelector != nil && modelServer.Spec.Discovery != nil:
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "discovery"), "discovery and workloadSelector are exclusive"))
+	case modelServer.Spec.Discovery != nil:
+		allErrs = append(allErrs, validateDiscovery(modelServer.Spec.Discovery, field.NewPath("spec", "discovery"))...)
+	}
+	return validationResult(allErrs)
+}
+
+// validateDiscovery checks that the field of the type of the discovery is set and valid.
+func validateDiscovery(discovery *networkingv1alpha1.Discovery, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch discovery.Type {
+	case networkingv1alpha1.DiscoveryTypeEndpointSlice:
+		if discovery.EndpointSlice == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("endpointSlice"), "endpointSlice must be set for the EndpointSlice discovery"))
+		}
+	case networkingv1alpha1.DiscoveryTypeStatic:
+		if discovery.Static == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("static"), "static must be set for the Static discovery"))
+			break
+		}
+		for i, address := range discovery.Static.Addresses {
+			if net.ParseIP(address) == nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("static", "addresses").Index(i), address, "must be an IP address"))
+			}
+		}
+	case networkingv1alpha1.DiscoveryTypeDNS:
+		if discovery.DNS == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("dns"), "dns must be set for the DNS discovery"))
+		}
+	case networkingv1alpha1.DiscoveryTypeConsul:
+		if discovery.Consul == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("consul"), "consul must be set for the Consul discovery"))
+			break
+		}
+		if u, err := url.Parse(discovery.Consul.Address); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("consul", "address"), discovery.Consul.Address, "must be an http or https URL"))
+		}
+	}
+	if discovery.RefreshInterval != nil && discovery.RefreshInterval.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("refreshInterval"), discovery.RefreshInterval.Duration.String(), "refreshInterval must be positive"))
+	}
+	if healthCheck := discovery.HealthCheck; healthCheck != nil {
+		if healthCheck.Path != "" && !strings.HasPrefix(healthCheck.Path, "/") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("healthCheck", "path"), healthCheck.Path, "path must start with /"))
+		}
+		if healthCheck.Interval != nil && healthCheck.Interval.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("healthCheck", "interval"), healthCheck.Interval.Duration.String(), "interval must be positive"))
+		}
+		if healthCheck.Timeout != nil && healthCheck.Timeout.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("healthCheck", "timeout"), healthCheck.Timeout.Duration.String(), "timeout must be positive"))
+		}
+	}
+	return allErrs
 }
 
 func (v *KthenaRouterValidator) shutdown() {
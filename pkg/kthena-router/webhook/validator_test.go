@@ -18,10 +18,12 @@ package webhook
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
 
 	networkingv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
 )
@@ -140,7 +142,7 @@ func TestValidateModelRoute(t *testing.T) {
 				},
 			},
 			expectValid:    false,
-			expectedReason: "validation failed:   - spec: Required value: either modelName or loraAdapters must be specified",
+			expectedReason: "validation failed:   - spec: Required value: either modelName, loraAdapters or modelNameMatch must be specified",
 		},
 		{
 			name: "invalid model route - empty string in lora adapters",
@@ -253,13 +255,699 @@ func TestValidateModelRoute(t *testing.T) {
 				},
 			},
 			expectValid:    false,
-			expectedReason: "validation failed:   - spec: Required value: either modelName or loraAdapters must be specified",
+			expectedReason: "validation failed:   - spec: Required value: either modelName, loraAdapters or modelNameMatch must be specified",
+		},
+		{
+			name: "valid model route with weighted target models",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "networking.serving.volcano.sh/v1alpha1",
+					Kind:       "ModelRoute",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelName: "test-model",
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "canary",
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "stable",
+									Weight:          ptr.To(uint32(90)),
+								},
+								{
+									ModelServerName: "canary",
+									Weight:          ptr.To(uint32(10)),
+								},
+							},
+						},
+					},
+				},
+			},
+			expectValid:    true,
+			expectedReason: "",
+		},
+		{
+			name: "invalid model route - weight set on some target models only",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "networking.serving.volcano.sh/v1alpha1",
+					Kind:       "ModelRoute",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelName: "test-model",
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "canary",
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "stable",
+									Weight:          ptr.To(uint32(90)),
+								},
+								{
+									ModelServerName: "canary",
+									Weight:          nil,
+								},
+							},
+						},
+					},
+				},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.rules[0].targetModels[1].weight: Required value: weight must be set on all the target models of the rule or on none of them",
+		},
+		{
+			name: "invalid model route - zero weights",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "networking.serving.volcano.sh/v1alpha1",
+					Kind:       "ModelRoute",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelName: "test-model",
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "canary",
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "stable",
+									Weight:          ptr.To(uint32(0)),
+								},
+								{
+									ModelServerName: "canary",
+									Weight:          ptr.To(uint32(0)),
+								},
+							},
+						},
+					},
+				},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.rules[0].targetModels: Forbidden: the weights of the target models must not all be zero",
+		},
+		{
+			name: "invalid model route - unknown time zone of the business hours",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "networking.serving.volcano.sh/v1alpha1",
+					Kind:       "ModelRoute",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelName: "test-model",
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "default",
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "test-server",
+								},
+							},
+						},
+					},
+					Priority: &networkingv1alpha1.Priority{
+						Class:                 networkingv1alpha1.TrafficClassBatch,
+						MaxConcurrentRequests: ptr.To(int32(16)),
+						InteractiveReservation: &networkingv1alpha1.InteractiveReservation{
+							Percentage: 50,
+							BusinessHours: networkingv1alpha1.BusinessHours{
+								TimeZone: "Europe/Nowhere",
+								Start:    "09:00",
+								End:      "18:00",
+							},
+						},
+					},
+				},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.priority.interactiveReservation.businessHours.timeZone: Invalid value: \"Europe/Nowhere\": unknown time zone",
+		},
+		{
+			name: "non-positive timeouts",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelName: "test-model",
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "default",
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "test-server",
+								},
+							},
+						},
+					},
+					Timeouts: &networkingv1alpha1.Timeouts{
+						Connect:    &metav1.Duration{Duration: 2 * time.Second},
+						FirstToken: &metav1.Duration{},
+						Request:    &metav1.Duration{Duration: -time.Minute},
+					},
+				},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.timeouts.firstToken: Invalid value: \"0s\": timeout must be positive  - spec.timeouts.request: Invalid value: \"-1m0s\": timeout must be positive",
+		},
+		{
+			name: "non-positive session affinity ttl",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelName: "test-model",
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "default",
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "test-server",
+								},
+							},
+						},
+					},
+					SessionAffinity: &networkingv1alpha1.SessionAffinity{
+						Type: networkingv1alpha1.SessionAffinityCookie,
+						TTL:  &metav1.Duration{},
+					},
+				},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.sessionAffinity.ttl: Invalid value: \"0s\": ttl must be positive",
+		},
+		{
+			name: "alias of the model name",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelName: "test-model",
+					Aliases:   []string{"gpt-4o", "test-model"},
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "default",
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "test-server",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.aliases[1]: Invalid value: \"test-model\": alias must differ from the model name and the lora adapters",
+		},
+		{
+			name: "model name prefix",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelNameMatch: &networkingv1alpha1.StringMatch{Prefix: ptr.To("llama-3")},
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "default",
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "test-server",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectValid:    true,
+			expectedReason: "",
+		},
+		{
+			name: "invalid model name regex",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelNameMatch: &networkingv1alpha1.StringMatch{Regex: ptr.To("llama-3.(")},
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "default",
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "test-server",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.modelNameMatch.regex: Invalid value: \"llama-3.(\": invalid regular expression: error parsing regexp: missing closing ): `llama-3.(`",
+		},
+		{
+			name: "model name prefix and regex",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelNameMatch: &networkingv1alpha1.StringMatch{Prefix: ptr.To("llama-3"), Regex: ptr.To("llama-3.*")},
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "default",
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "test-server",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.modelNameMatch: Required value: exactly one of prefix and regex must be set",
+		},
+		{
+			name: "valid body fields",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelName: "llama",
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "tools",
+							ModelMatch: &networkingv1alpha1.ModelMatch{
+								Body: &networkingv1alpha1.BodyMatch{
+									Fields: []networkingv1alpha1.BodyFieldMatch{{Path: "tools"}, {Path: "metadata.team", Value: &networkingv1alpha1.StringMatch{Exact: ptr.To("search")}}},
+								},
+							},
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "test-server",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectValid:    true,
+			expectedReason: "",
+		},
+		{
+			name: "body field path with an empty segment",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelName: "llama",
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "tools",
+							ModelMatch: &networkingv1alpha1.ModelMatch{
+								Body: &networkingv1alpha1.BodyMatch{
+									Fields: []networkingv1alpha1.BodyFieldMatch{{Path: "metadata..team"}},
+								},
+							},
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "test-server",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.rules[0].modelMatch.body.fields[0].path: Invalid value: \"metadata..team\": path must be dot-separated field names or array indexes",
+		},
+		{
+			name: "body field value and present",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelName: "llama",
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "tools",
+							ModelMatch: &networkingv1alpha1.ModelMatch{
+								Body: &networkingv1alpha1.BodyMatch{
+									Fields: []networkingv1alpha1.BodyFieldMatch{{Path: "tools", Value: &networkingv1alpha1.StringMatch{Exact: ptr.To("x")}, Present: ptr.To(true)}},
+								},
+							},
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "test-server",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.rules[0].modelMatch.body.fields[0]: Forbidden: value and present are mutually exclusive",
+		},
+		{
+			name: "invalid body field regex",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelName: "llama",
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "tools",
+							ModelMatch: &networkingv1alpha1.ModelMatch{
+								Body: &networkingv1alpha1.BodyMatch{
+									Fields: []networkingv1alpha1.BodyFieldMatch{{Path: "metadata.team", Value: &networkingv1alpha1.StringMatch{Regex: ptr.To("search(")}}},
+								},
+							},
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "test-server",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.rules[0].modelMatch.body.fields[0].value.regex: Invalid value: \"search(\": invalid regular expression: error parsing regexp: missing closing ): `search(`",
+		},
+		{
+			name: "valid CEL expression",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelName: "llama",
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "long-prompts",
+							ModelMatch: &networkingv1alpha1.ModelMatch{
+								Expression: ptr.To(`request.tokens > 4000 && consumer.team == "research"`),
+							},
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "test-server",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectValid:    true,
+			expectedReason: "",
+		},
+		{
+			name: "CEL expression not evaluating to a bool",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelName: "llama",
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "long-prompts",
+							ModelMatch: &networkingv1alpha1.ModelMatch{
+								Expression: ptr.To(`request.tokens + 1`),
+							},
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "test-server",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.rules[0].modelMatch.expression: Invalid value: \"request.tokens + 1\": invalid CEL expression: expression must evaluate to a bool, not int",
+		},
+		{
+			name: "non-positive hedging delay",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelName: "test-model",
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "default",
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "test-server",
+								},
+							},
+						},
+					},
+					Hedging: &networkingv1alpha1.Hedging{},
+				},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.hedging.delay: Invalid value: \"0s\": delay must be positive",
+		},
+		{
+			name: "non-positive concurrency queue timeout",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelName: "test-model",
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "default",
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "test-server",
+								},
+							},
+						},
+					},
+					Concurrency: &networkingv1alpha1.Concurrency{
+						Overflow:     networkingv1alpha1.ConcurrencyOverflowQueue,
+						QueueTimeout: &metav1.Duration{},
+					},
+				},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.concurrency.queueTimeout: Invalid value: \"0s\": queue timeout must be positive",
+		},
+		{
+			name: "mirror to a target model server",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelName: "test-model",
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "default",
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "test-server",
+								},
+							},
+						},
+					},
+					Mirror: &networkingv1alpha1.Mirror{
+						ModelServerName: "test-server",
+					},
+				},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.mirror.modelServerName: Invalid value: \"test-server\": the requests can't be mirrored to a target model server of the rules",
+		},
+		{
+			name: "evaluation without host and with a negative timeout",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelName: "test-model",
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "default",
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "test-server",
+								},
+							},
+						},
+					},
+					Evaluation: &networkingv1alpha1.Evaluation{
+						URL:     "http://",
+						Timeout: &metav1.Duration{Duration: -time.Second},
+					},
+				},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.evaluation.url: Invalid value: \"http://\": url must be an absolute http or https URL  - spec.evaluation.timeout: Invalid value: \"-1s\": timeout must be positive",
+		},
+		{
+			name: "rollout evaluation score without evaluation",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelName: "test-model",
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "default",
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "test-server",
+								},
+							},
+						},
+					},
+					Rollout: &networkingv1alpha1.Rollout{
+						StableModelServerName: "test-server",
+						CanaryModelServerName: "canary-server",
+						MinEvaluationScore:    ptr.To("0.8"),
+					},
+				},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.rollout.minEvaluationScore: Invalid value: \"0.8\": the evaluation score requires the evaluation of the responses",
+		},
+		{
+			name: "rollout with decreasing steps",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelName: "test-model",
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "default",
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "test-server",
+								},
+							},
+						},
+					},
+					Rollout: &networkingv1alpha1.Rollout{
+						StableModelServerName: "test-server",
+						CanaryModelServerName: "test-server-v2",
+						Steps:                 []int32{10, 50, 20},
+					},
+				},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.rollout.steps[2]: Invalid value: 20: steps must be increasing",
+		},
+		{
+			name: "rollout of a model server not targeted by the rules",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelName: "test-model",
+					Rules: []*networkingv1alpha1.Rule{
+						{
+							Name: "default",
+							TargetModels: []*networkingv1alpha1.TargetModel{
+								{
+									ModelServerName: "test-server",
+								},
+							},
+						},
+					},
+					Rollout: &networkingv1alpha1.Rollout{
+						StableModelServerName: "other-server",
+						CanaryModelServerName: "test-server-v2",
+					},
+				},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.rollout.stableModelServerName: Invalid value: \"other-server\": the stable model server must be a target model server of the rules",
+		},
+		{
+			name: "valid tenant weights",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-route",
+					Namespace:   "default",
+					Annotations: map[string]string{networkingv1alpha1.TenantWeightsAnnotationKey: "team-a=3, team-b=0.5"},
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelName: "test-model",
+				},
+			},
+			expectValid: true,
+		},
+		{
+			name: "invalid tenant weights",
+			modelRoute: &networkingv1alpha1.ModelRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-route",
+					Namespace:   "default",
+					Annotations: map[string]string{networkingv1alpha1.TenantWeightsAnnotationKey: "team-a=3,team-b=0"},
+				},
+				Spec: networkingv1alpha1.ModelRouteSpec{
+					ModelName: "test-model",
+				},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - metadata.annotations[networking.serving.volcano.sh/tenant-weights]: Invalid value: \"team-a=3,team-b=0\": invalid weight of tenant \"team-b\": \"0\" must be a positive number",
 		},
 	}
 
 	// Create a validator instance
 	kubeClient := fake.NewSimpleClientset()
-	validator := NewKthenaRouterValidator(kubeClient, 8080)
+	validator := NewKthenaRouterValidator(kubeClient, 8080, nil)
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -275,3 +963,180 @@ func TestValidateModelRoute(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateModelServer(t *testing.T) {
+	tests := []struct {
+		name              string
+		slowStart         *networkingv1alpha1.SlowStart
+		prefillCoalescing *networkingv1alpha1.PrefillCoalescing
+		workloadSelector  *networkingv1alpha1.WorkloadSelector
+		discovery         *networkingv1alpha1.Discovery
+		// noWorkloads unsets the default workload selector of the test cases setting no discovery.
+		noWorkloads    bool
+		warmUp         *networkingv1alpha1.WarmUp
+		trafficPolicy  *networkingv1alpha1.TrafficPolicy
+		expectValid    bool
+		expectedReason string
+	}{
+		{
+			name:        "no slow start",
+			expectValid: true,
+		},
+		{
+			name:        "valid slow start",
+			slowStart:   &networkingv1alpha1.SlowStart{Window: metav1.Duration{Duration: 5 * time.Minute}},
+			expectValid: true,
+		},
+		{
+			name:           "non-positive slow start window",
+			slowStart:      &networkingv1alpha1.SlowStart{},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.slowStart.window: Invalid value: \"0s\": window must be positive",
+		},
+		{
+			name:              "valid prefill coalescing",
+			prefillCoalescing: &networkingv1alpha1.PrefillCoalescing{MaxWait: metav1.Duration{Duration: 2 * time.Second}},
+			expectValid:       true,
+		},
+		{
+			name:              "non-positive prefill coalescing max wait",
+			prefillCoalescing: &networkingv1alpha1.PrefillCoalescing{},
+			expectValid:       false,
+			expectedReason:    "validation failed:   - spec.prefillCoalescing.maxWait: Invalid value: \"0s\": maxWait must be positive",
+		},
+		{
+			name: "valid match expressions",
+			workloadSelector: &networkingv1alpha1.WorkloadSelector{
+				MatchLabels: map[string]string{"app": "llama"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "version", Operator: metav1.LabelSelectorOpIn, Values: []string{"v2"}},
+				},
+			},
+			expectValid: true,
+		},
+		{
+			name: "match expression without values",
+			workloadSelector: &networkingv1alpha1.WorkloadSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "version", Operator: metav1.LabelSelectorOpIn},
+				},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.workloadSelector.matchExpressions[0].values: Required value: must be specified when `operator` is 'In' or 'NotIn'",
+		},
+		{
+			name:        "valid warm up",
+			warmUp:      &networkingv1alpha1.WarmUp{Prompt: "Hello", Timeout: &metav1.Duration{Duration: 30 * time.Second}},
+			expectValid: true,
+		},
+		{
+			name:           "non-positive warm up timeout",
+			warmUp:         &networkingv1alpha1.WarmUp{Timeout: &metav1.Duration{}},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.warmUp.timeout: Invalid value: \"0s\": timeout must be positive",
+		},
+		{
+			name:          "valid retry budget",
+			trafficPolicy: &networkingv1alpha1.TrafficPolicy{Retry: &networkingv1alpha1.Retry{Attempts: 2, Budget: &metav1.Duration{Duration: 5 * time.Second}}},
+			expectValid:   true,
+		},
+		{
+			name:           "non-positive retry budget",
+			trafficPolicy:  &networkingv1alpha1.TrafficPolicy{Retry: &networkingv1alpha1.Retry{Attempts: 2, Budget: &metav1.Duration{}}},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.trafficPolicy.retry.budget: Invalid value: \"0s\": budget must be positive",
+		},
+		{
+			name:           "neither workload selector nor discovery",
+			noWorkloads:    true,
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.workloadSelector: Required value: workloadSelector or discovery must be set",
+		},
+		{
+			name: "valid static discovery",
+			discovery: &networkingv1alpha1.Discovery{
+				Type:   networkingv1alpha1.DiscoveryTypeStatic,
+				Static: &networkingv1alpha1.StaticDiscovery{Addresses: []string{"10.0.0.1", "fd00::1"}},
+				HealthCheck: &networkingv1alpha1.HealthCheck{
+					Path:     "/health",
+					Interval: &metav1.Duration{Duration: 5 * time.Second},
+				},
+			},
+			expectValid: true,
+		},
+		{
+			name:             "workload selector and discovery",
+			workloadSelector: &networkingv1alpha1.WorkloadSelector{MatchLabels: map[string]string{"app": "llama"}},
+			discovery: &networkingv1alpha1.Discovery{
+				Type:   networkingv1alpha1.DiscoveryTypeStatic,
+				Static: &networkingv1alpha1.StaticDiscovery{Addresses: []string{"10.0.0.1"}},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.discovery: Forbidden: discovery and workloadSelector are exclusive",
+		},
+		{
+			name: "static discovery of a host name",
+			discovery: &networkingv1alpha1.Discovery{
+				Type:   networkingv1alpha1.DiscoveryTypeStatic,
+				Static: &networkingv1alpha1.StaticDiscovery{Addresses: []string{"gpu-1.example.com"}},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.discovery.static.addresses[0]: Invalid value: \"gpu-1.example.com\": must be an IP address",
+		},
+		{
+			name:           "discovery without the field of its type",
+			discovery:      &networkingv1alpha1.Discovery{Type: networkingv1alpha1.DiscoveryTypeDNS},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.discovery.dns: Required value: dns must be set for the DNS discovery",
+		},
+		{
+			name: "consul discovery without scheme",
+			discovery: &networkingv1alpha1.Discovery{
+				Type:   networkingv1alpha1.DiscoveryTypeConsul,
+				Consul: &networkingv1alpha1.ConsulDiscovery{Address: "consul.example.com:8500", Service: "vllm"},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.discovery.consul.address: Invalid value: \"consul.example.com:8500\": must be an http or https URL",
+		},
+		{
+			name: "non-positive health check timeout",
+			discovery: &networkingv1alpha1.Discovery{
+				Type:          networkingv1alpha1.DiscoveryTypeEndpointSlice,
+				EndpointSlice: &networkingv1alpha1.EndpointSliceDiscovery{ServiceName: "vllm-hosts"},
+				HealthCheck:   &networkingv1alpha1.HealthCheck{Timeout: &metav1.Duration{}},
+			},
+			expectValid:    false,
+			expectedReason: "validation failed:   - spec.discovery.healthCheck.timeout: Invalid value: \"0s\": timeout must be positive",
+		},
+	}
+
+	validator := NewKthenaRouterValidator(fake.NewSimpleClientset(), 8080, nil)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workloadSelector := tt.workloadSelector
+			if workloadSelector == nil && tt.discovery == nil && !tt.noWorkloads {
+				workloadSelector = &networkingv1alpha1.WorkloadSelector{MatchLabels: map[string]string{"app": "llama"}}
+			}
+			modelServer := &networkingv1alpha1.ModelServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-server",
+					Namespace: "default",
+				},
+				Spec: networkingv1alpha1.ModelServerSpec{
+					InferenceEngine:   networkingv1alpha1.VLLM,
+					SlowStart:         tt.slowStart,
+					PrefillCoalescing: tt.prefillCoalescing,
+					WarmUp:            tt.warmUp,
+					TrafficPolicy:     tt.trafficPolicy,
+					WorkloadSelector:  workloadSelector,
+					Discovery:         tt.discovery,
+				},
+			}
+			allowed, reason := validator.validateModelServer(modelServer)
+
+			assert.Equal(t, tt.expectValid, allowed)
+			assert.Equal(t, tt.expectedReason, reason)
+		})
+	}
+}
@@ -0,0 +1,429 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mockengine implements a mock OpenAI-compatible inference engine, exposing the API and the metrics of vLLM
+// without a GPU. It serves canned responses with a configurable latency profile and failure modes, to try
+// the kthena stack locally and to run the e2e tests.
+package mockengine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/klog/v2"
+)
+
+const (
+	DefaultModel              = "mock-model"
+	DefaultTTFT               = 100 * time.Millisecond
+	DefaultTPOT               = 20 * time.Millisecond
+	DefaultOutputTokens       = 16
+	DefaultMaxRunningRequests = 16
+)
+
+// responseWords are the tokens of the generated responses, repeated as needed.
+var responseWords = strings.Fields("This is a mock response generated by the kthena mock engine without running any model.")
+
+// Config configures the models served by the engine, its latency profile and its failure modes.
+type Config struct {
+	// Models are the names of the served models. The first one is reported in the metrics.
+	Models []string
+	// StrictModels rejects the requests for a model which is neither served nor a loaded LoRA adapter,
+	// like vLLM does. Otherwise any model is served.
+	StrictModels bool
+
+	// TTFT is the time to the first token of a response.
+	TTFT time.Duration
+	// TPOT is the time per output token after the first one.
+	TPOT time.Duration
+	// Jitter randomly varies the latencies by up to this fraction, between 0 and 1.
+	Jitter float64
+	// OutputTokens is the number of tokens of a response, unless the request sets a lower max_tokens.
+	OutputTokens int
+	// MaxRunningRequests is the number of requests generated concurrently, the others wait.
+	MaxRunningRequests int
+
+	// FailureRate is the fraction of the requests failing with FailureStatus, between 0 and 1.
+	FailureRate float64
+	// FailureStatus is the HTTP status of the failed requests.
+	FailureStatus int
+}
+
+// Engine is a mock inference engine.
+type Engine struct {
+	config Config
+
+	// running holds a slot per request being generated.
+	running chan struct{}
+	waiting atomic.Int64
+
+	lock sync.RWMutex
+	// loras are the paths of the loaded LoRA adapters by name.
+	loras map[string]string
+
+	registry *prometheus.Registry
+	metrics  *engineMetrics
+}
+
+// New returns an engine, the unset fields of config are defaulted.
+func New(config Config) *Engine {
+	if len(config.Models) == 0 {
+		config.Models = []string{DefaultModel}
+	}
+	if config.OutputTokens <= 0 {
+		config.OutputTokens = DefaultOutputTokens
+	}
+	if config.MaxRunningRequests <= 0 {
+		config.MaxRunningRequests = DefaultMaxRunningRequests
+	}
+	if config.FailureStatus == 0 {
+		config.FailureStatus = http.StatusInternalServerError
+	}
+
+	e := &Engine{
+		config:   config,
+		running:  make(chan struct{}, config.MaxRunningRequests),
+		loras:    map[string]string{},
+		registry: prometheus.NewRegistry(),
+	}
+	e.metrics = newEngineMetrics(e, config.Models[0])
+	return e
+}
+
+// Handler returns the HTTP handler of the OpenAI-compatible API, the LoRA management API, the health
+// check and the metrics of the engine.
+func (e *Engine) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.Handle("GET /metrics", promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("GET /v1/models", e.listModels)
+	mux.HandleFunc("POST /v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		e.generate(w, r, true)
+	})
+	mux.HandleFunc("POST /v1/completions", func(w http.ResponseWriter, r *http.Request) {
+		e.generate(w, r, false)
+	})
+	mux.HandleFunc("POST /v1/load_lora_adapter", e.loadLoRAAdapter)
+	mux.HandleFunc("POST /v1/unload_lora_adapter", e.unloadLoRAAdapter)
+	return mux
+}
+
+type model struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+	Root    string `json:"root"`
+	Parent  string `json:"parent,omitempty"`
+}
+
+func (e *Engine) listModels(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().Unix()
+	var models []model
+	for _, name := range e.config.Models {
+		models = append(models, model{ID: name, Object: "model", Created: now, OwnedBy: "kthena", Root: name})
+	}
+	e.lock.RLock()
+	for name, path := range e.loras {
+		models = append(models, model{ID: name, Object: "model", Created: now, OwnedBy: "kthena", Root: path, Parent: e.config.Models[0]})
+	}
+	e.lock.RUnlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{"object": "list", "data": models})
+}
+
+type loraRequest struct {
+	LoraName string `json:"lora_name"`
+	LoraPath string `json:"lora_path"`
+}
+
+func (e *Engine) loadLoRAAdapter(w http.ResponseWriter, r *http.Request) {
+	var req loraRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.LoraName == "" {
+		writeError(w, http.StatusBadRequest, "lora_name is required")
+		return
+	}
+	e.lock.Lock()
+	e.loras[req.LoraName] = req.LoraPath
+	e.lock.Unlock()
+	klog.Infof("Loaded LoRA adapter %s from %q", req.LoraName, req.LoraPath)
+	fmt.Fprintf(w, "Success: LoRA adapter '%s' added successfully.", req.LoraName)
+}
+
+func (e *Engine) unloadLoRAAdapter(w http.ResponseWriter, r *http.Request) {
+	var req loraRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.LoraName == "" {
+		writeError(w, http.StatusBadRequest, "lora_name is required")
+		return
+	}
+	e.lock.Lock()
+	_, ok := e.loras[req.LoraName]
+	delete(e.loras, req.LoraName)
+	e.lock.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("The lora adapter '%s' cannot be found.", req.LoraName))
+		return
+	}
+	klog.Infof("Unloaded LoRA adapter %s", req.LoraName)
+	fmt.Fprintf(w, "Success: LoRA adapter '%s' removed successfully.", req.LoraName)
+}
+
+// served reports whether the model is served or is a loaded LoRA adapter.
+func (e *Engine) served(name string) bool {
+	if slices.Contains(e.config.Models, name) {
+		return true
+	}
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	_, ok := e.loras[name]
+	return ok
+}
+
+type generateRequest struct {
+	Model               string            `json:"model"`
+	Messages            []json.RawMessage `json:"messages"`
+	Prompt              json.RawMessage   `json:"prompt"`
+	MaxTokens           *int              `json:"max_tokens"`
+	MaxCompletionTokens *int              `json:"max_completion_tokens"`
+	Stream              bool              `json:"stream"`
+	StreamOptions       *struct {
+		IncludeUsage bool `json:"include_usage"`
+	} `json:"stream_options"`
+}
+
+type usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+func (e *Engine) generate(w http.ResponseWriter, r *http.Request, chat bool) {
+	var req generateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", err))
+		return
+	}
+	if req.Model == "" {
+		req.Model = e.config.Models[0]
+	}
+	if e.config.StrictModels && !e.served(req.Model) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("The model `%s` does not exist.", req.Model))
+		return
+	}
+	if e.config.FailureRate > 0 && rand.Float64() < e.config.FailureRate {
+		writeError(w, e.config.FailureStatus, "injected failure")
+		return
+	}
+
+	// Wait for a running slot, like requests queued by the engine scheduler.
+	e.waiting.Add(1)
+	select {
+	case e.running <- struct{}{}:
+		e.waiting.Add(-1)
+	case <-r.Context().Done():
+		e.waiting.Add(-1)
+		return
+	}
+	defer func() { <-e.running }()
+
+	maxTokens := e.config.OutputTokens
+	for _, limit := range []*int{req.MaxTokens, req.MaxCompletionTokens} {
+		if limit != nil && *limit > 0 && *limit < maxTokens {
+			maxTokens = *limit
+		}
+	}
+	prompt := promptTokens(req)
+	g := &generation{
+		id:      uuid.New().String(),
+		model:   req.Model,
+		chat:    chat,
+		created: time.Now().Unix(),
+		usage:   usage{PromptTokens: prompt, CompletionTokens: maxTokens, TotalTokens: prompt + maxTokens},
+	}
+	e.metrics.promptTokens.Add(float64(g.usage.PromptTokens))
+
+	if req.Stream {
+		e.stream(r.Context(), w, g, req.StreamOptions != nil && req.StreamOptions.IncludeUsage)
+		return
+	}
+
+	var text strings.Builder
+	for i := range maxTokens {
+		if !e.nextToken(r.Context(), i) {
+			return
+		}
+		text.WriteString(g.token(i))
+	}
+	writeJSON(w, http.StatusOK, g.response(text.String()))
+}
+
+func (e *Engine) stream(ctx context.Context, w http.ResponseWriter, g *generation, includeUsage bool) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	send := func(data interface{}) {
+		b, _ := json.Marshal(data)
+		fmt.Fprintf(w, "data: %s\n\n", b)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	for i := range g.usage.CompletionTokens {
+		if !e.nextToken(ctx, i) {
+			return
+		}
+		var finishReason *string
+		if i == g.usage.CompletionTokens-1 {
+			reason := "length"
+			finishReason = &reason
+		}
+		send(g.chunk(g.token(i), finishReason))
+	}
+	if includeUsage {
+		send(g.usageChunk())
+	}
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
+
+// nextToken waits for the i-th token of a response to be generated. It returns false if the request is gone.
+func (e *Engine) nextToken(ctx context.Context, i int) bool {
+	latency, observer := e.config.TPOT, e.metrics.tpot
+	if i == 0 {
+		latency, observer = e.config.TTFT, e.metrics.ttft
+	}
+	latency = e.jitter(latency)
+	timer := time.NewTimer(latency)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		observer.Observe(latency.Seconds())
+		e.metrics.generationTokens.Inc()
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (e *Engine) jitter(d time.Duration) time.Duration {
+	if e.config.Jitter <= 0 || d <= 0 {
+		return d
+	}
+	return time.Duration(float64(d) * (1 + e.config.Jitter*(2*rand.Float64()-1)))
+}
+
+// promptTokens estimates the number of tokens of the prompt, at 4 characters per token.
+func promptTokens(req generateRequest) int {
+	n := len(req.Prompt)
+	for _, message := range req.Messages {
+		n += len(message)
+	}
+	return max(1, n/4)
+}
+
+type generation struct {
+	id      string
+	model   string
+	chat    bool
+	created int64
+	usage   usage
+}
+
+func (g *generation) token(i int) string {
+	word := responseWords[i%len(responseWords)]
+	if i == 0 {
+		return word
+	}
+	return " " + word
+}
+
+func (g *generation) response(text string) map[string]interface{} {
+	choice := map[string]interface{}{"index": 0, "finish_reason": "length"}
+	object := "text_completion"
+	if g.chat {
+		object = "chat.completion"
+		choice["message"] = map[string]interface{}{"role": "assistant", "content": text}
+	} else {
+		choice["text"] = text
+	}
+	return map[string]interface{}{
+		"id":      g.id,
+		"object":  object,
+		"created": g.created,
+		"model":   g.model,
+		"choices": []interface{}{choice},
+		"usage":   g.usage,
+	}
+}
+
+func (g *generation) chunk(text string, finishReason *string) map[string]interface{} {
+	choice := map[string]interface{}{"index": 0, "finish_reason": finishReason}
+	object := "text_completion"
+	if g.chat {
+		object = "chat.completion.chunk"
+		choice["delta"] = map[string]interface{}{"role": "assistant", "content": text}
+	} else {
+		choice["text"] = text
+	}
+	return map[string]interface{}{
+		"id":      g.id,
+		"object":  object,
+		"created": g.created,
+		"model":   g.model,
+		"choices": []interface{}{choice},
+	}
+}
+
+func (g *generation) usageChunk() map[string]interface{} {
+	chunk := g.chunk("", nil)
+	chunk["choices"] = []interface{}{}
+	chunk["usage"] = g.usage
+	return chunk
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		klog.Errorf("Failed to write response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]interface{}{
+			"message": message,
+			"type":    http.StatusText(status),
+			"code":    status,
+		},
+	})
+}
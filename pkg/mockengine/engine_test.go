@@ -0,0 +1,138 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockengine
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func post(t *testing.T, server *httptest.Server, path, body string) (int, string) {
+	resp, err := http.Post(server.URL+path, "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(data)
+}
+
+func TestChatCompletions(t *testing.T) {
+	server := httptest.NewServer(New(Config{Models: []string{"llama"}, OutputTokens: 8}).Handler())
+	defer server.Close()
+
+	status, body := post(t, server, "/v1/chat/completions", `{"model":"llama","messages":[{"role":"user","content":"hello"}],"max_tokens":4}`)
+	require.Equal(t, http.StatusOK, status)
+	var resp struct {
+		Model   string `json:"model"`
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+		Usage usage `json:"usage"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &resp))
+	assert.Equal(t, "llama", resp.Model)
+	assert.Equal(t, "This is a mock", resp.Choices[0].Message.Content)
+	assert.Equal(t, 4, resp.Usage.CompletionTokens)
+	assert.Equal(t, resp.Usage.PromptTokens+4, resp.Usage.TotalTokens)
+}
+
+func TestCompletionsStream(t *testing.T) {
+	server := httptest.NewServer(New(Config{OutputTokens: 3}).Handler())
+	defer server.Close()
+
+	status, body := post(t, server, "/v1/completions", `{"model":"mock-model","prompt":"hello","stream":true,"stream_options":{"include_usage":true}}`)
+	require.Equal(t, http.StatusOK, status)
+	events := strings.Split(strings.TrimSpace(body), "\n\n")
+	require.Len(t, events, 5)
+	assert.Contains(t, events[0], `"text":"This"`)
+	assert.Contains(t, events[2], `"finish_reason":"length"`)
+	assert.Contains(t, events[3], `"completion_tokens":3`)
+	assert.Equal(t, "data: [DONE]", events[4])
+}
+
+func TestLoRAAdapters(t *testing.T) {
+	server := httptest.NewServer(New(Config{Models: []string{"llama"}, StrictModels: true}).Handler())
+	defer server.Close()
+
+	status, _ := post(t, server, "/v1/chat/completions", `{"model":"lora-A","messages":[]}`)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, _ = post(t, server, "/v1/load_lora_adapter", `{"lora_name":"lora-A","lora_path":"/adapters/a"}`)
+	require.Equal(t, http.StatusOK, status)
+	status, body := post(t, server, "/v1/chat/completions", `{"model":"lora-A","messages":[]}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"model":"lora-A"`)
+
+	resp, err := http.Get(server.URL + "/v1/models")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var models struct {
+		Data []model `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&models))
+	assert.Equal(t, []model{
+		{ID: "llama", Object: "model", Created: models.Data[0].Created, OwnedBy: "kthena", Root: "llama"},
+		{ID: "lora-A", Object: "model", Created: models.Data[1].Created, OwnedBy: "kthena", Root: "/adapters/a", Parent: "llama"},
+	}, models.Data)
+
+	status, _ = post(t, server, "/v1/unload_lora_adapter", `{"lora_name":"lora-A"}`)
+	require.Equal(t, http.StatusOK, status)
+	status, _ = post(t, server, "/v1/unload_lora_adapter", `{"lora_name":"lora-A"}`)
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestFailures(t *testing.T) {
+	server := httptest.NewServer(New(Config{FailureRate: 1, FailureStatus: http.StatusServiceUnavailable}).Handler())
+	defer server.Close()
+
+	status, body := post(t, server, "/v1/chat/completions", `{"model":"any","messages":[]}`)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Contains(t, body, "injected failure")
+}
+
+func TestMetrics(t *testing.T) {
+	server := httptest.NewServer(New(Config{Models: []string{"llama"}, OutputTokens: 2}).Handler())
+	defer server.Close()
+
+	status, _ := post(t, server, "/v1/completions", `{"prompt":"hello"}`)
+	require.Equal(t, http.StatusOK, status)
+
+	resp, err := http.Get(server.URL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	for _, metric := range []string{
+		`vllm:num_requests_running{model_name="llama"} 0`,
+		`vllm:num_requests_waiting{model_name="llama"} 0`,
+		`vllm:gpu_cache_usage_perc{model_name="llama"} 0`,
+		`vllm:time_to_first_token_seconds_count{model_name="llama"} 1`,
+		`vllm:time_per_output_token_seconds_count{model_name="llama"} 1`,
+		`vllm:generation_tokens_total{model_name="llama"} 2`,
+	} {
+		assert.Contains(t, string(data), metric)
+	}
+}
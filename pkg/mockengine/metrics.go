@@ -0,0 +1,86 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockengine
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// engineMetrics are the vLLM metrics scraped by kthena-router to score the pods.
+type engineMetrics struct {
+	ttft             prometheus.Observer
+	tpot             prometheus.Observer
+	promptTokens     prometheus.Counter
+	generationTokens prometheus.Counter
+}
+
+func newEngineMetrics(e *Engine, modelName string) *engineMetrics {
+	labels := prometheus.Labels{"model_name": modelName}
+	register := func(c prometheus.Collector) {
+		e.registry.MustRegister(c)
+	}
+
+	register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "vllm:num_requests_running",
+		Help:        "Number of requests currently running on GPU.",
+		ConstLabels: labels,
+	}, func() float64 { return float64(len(e.running)) }))
+	register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "vllm:num_requests_waiting",
+		Help:        "Number of requests waiting to be processed.",
+		ConstLabels: labels,
+	}, func() float64 { return float64(e.waiting.Load()) }))
+	// The KV cache usage follows the running requests, as if each of them used the same share of the cache.
+	register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "vllm:gpu_cache_usage_perc",
+		Help:        "GPU KV-cache usage. 1 means 100 percent usage.",
+		ConstLabels: labels,
+	}, func() float64 { return float64(len(e.running)) / float64(cap(e.running)) }))
+
+	ttft := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:        "vllm:time_to_first_token_seconds",
+		Help:        "Histogram of time to first token in seconds.",
+		ConstLabels: labels,
+		Buckets:     []float64{0.001, 0.005, 0.01, 0.02, 0.04, 0.06, 0.08, 0.1, 0.25, 0.5, 0.75, 1.0, 2.5, 5.0, 7.5, 10.0},
+	})
+	tpot := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:        "vllm:time_per_output_token_seconds",
+		Help:        "Histogram of time per output token in seconds.",
+		ConstLabels: labels,
+		Buckets:     []float64{0.01, 0.025, 0.05, 0.075, 0.1, 0.15, 0.2, 0.3, 0.4, 0.5, 0.75, 1.0, 2.5},
+	})
+	promptTokens := prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        "vllm:prompt_tokens_total",
+		Help:        "Number of prefill tokens processed.",
+		ConstLabels: labels,
+	})
+	generationTokens := prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        "vllm:generation_tokens_total",
+		Help:        "Number of generation tokens processed.",
+		ConstLabels: labels,
+	})
+	for _, c := range []prometheus.Collector{ttft, tpot, promptTokens, generationTokens} {
+		register(c)
+	}
+
+	return &engineMetrics{
+		ttft:             ttft,
+		tpot:             tpot,
+		promptTokens:     promptTokens,
+		generationTokens: generationTokens,
+	}
+}
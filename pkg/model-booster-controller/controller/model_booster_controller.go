@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	networkingv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
@@ -42,6 +43,7 @@ import (
 	networkingLister "github.com/volcano-sh/kthena/client-go/listers/networking/v1alpha1"
 	workloadLister "github.com/volcano-sh/kthena/client-go/listers/workload/v1alpha1"
 	workload "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/controller/options"
 	"github.com/volcano-sh/kthena/pkg/model-booster-controller/config"
 	"github.com/volcano-sh/kthena/pkg/model-booster-controller/utils"
 )
@@ -78,23 +80,39 @@ type ModelBoosterController struct {
 	// loraUpdateCache stores the previous model version for LoRA adapter comparison
 	// Key format: "namespace/name:generation" to avoid version conflicts
 	loraUpdateCache map[string]*workload.ModelBooster
+	informersOnce   sync.Once
 }
 
 func (mc *ModelBoosterController) Run(ctx context.Context, workers int) {
 	defer utilruntime.HandleCrash()
 	defer mc.workQueue.ShutDown()
 
-	// start informers
-	go mc.modelsInformer.RunWithContext(ctx)
-	go mc.modelServingInformer.RunWithContext(ctx)
-	go mc.autoscalingPoliciesInformer.RunWithContext(ctx)
-	go mc.autoscalingPolicyBindingsInformer.RunWithContext(ctx)
-	go mc.podsInformer.RunWithContext(ctx)
-	go mc.modelServersInformer.RunWithContext(ctx)
-	go mc.modelRoutesInformer.RunWithContext(ctx)
+	mc.WarmUp(ctx)
 
-	// start Kubernetes informer factory
-	go mc.kubeInformerFactory.Start(ctx.Done())
+	klog.Info("start model controller")
+	for i := 0; i < workers; i++ {
+		go mc.worker(ctx)
+	}
+	<-ctx.Done()
+	klog.Info("shut down model controller")
+}
+
+// WarmUp starts the informers and waits for their caches to sync, without reconciling.
+// Informers are started once, ctx of the first call stops them.
+func (mc *ModelBoosterController) WarmUp(ctx context.Context) {
+	mc.informersOnce.Do(func() {
+		// start informers
+		go mc.modelsInformer.RunWithContext(ctx)
+		go mc.modelServingInformer.RunWithContext(ctx)
+		go mc.autoscalingPoliciesInformer.RunWithContext(ctx)
+		go mc.autoscalingPolicyBindingsInformer.RunWithContext(ctx)
+		go mc.podsInformer.RunWithContext(ctx)
+		go mc.modelServersInformer.RunWithContext(ctx)
+		go mc.modelRoutesInformer.RunWithContext(ctx)
+
+		// start Kubernetes informer factory
+		go mc.kubeInformerFactory.Start(ctx.Done())
+	})
 
 	cache.WaitForCacheSync(ctx.Done(),
 		mc.modelsInformer.HasSynced,
@@ -105,13 +123,6 @@ func (mc *ModelBoosterController) Run(ctx context.Context, workers int) {
 		mc.modelServersInformer.HasSynced,
 		mc.modelRoutesInformer.HasSynced,
 	)
-
-	klog.Info("start model controller")
-	for i := 0; i < workers; i++ {
-		go mc.worker(ctx)
-	}
-	<-ctx.Done()
-	klog.Info("shut down model controller")
 }
 
 func (mc *ModelBoosterController) worker(ctx context.Context) {
@@ -282,7 +293,7 @@ func (mc *ModelBoosterController) cleanupOutdatedLoraUpdateCache(modelBooster *w
 	}
 }
 
-func NewModelBoosterController(kubeClient kubernetes.Interface, client clientset.Interface) *ModelBoosterController {
+func NewModelBoosterController(kubeClient kubernetes.Interface, client clientset.Interface, opts options.InformerOptions) *ModelBoosterController {
 	selector, err := labels.NewRequirement(utils.ManageBy, selection.Equals, []string{workload.GroupName})
 	if err != nil {
 		klog.Errorf("cannot create label selector, err: %v", err)
@@ -291,13 +302,15 @@ func NewModelBoosterController(kubeClient kubernetes.Interface, client clientset
 
 	filterInformerFactory := informersv1alpha1.NewSharedInformerFactoryWithOptions(
 		client,
-		0,
+		opts.ResyncPeriod,
+		informersv1alpha1.WithNamespace(opts.Namespace),
 		informersv1alpha1.WithTweakListOptions(func(opts *metav1.ListOptions) {
 			opts.LabelSelector = selector.String()
 		}),
 	)
 
-	informerFactory := informersv1alpha1.NewSharedInformerFactory(client, 0)
+	informerFactory := informersv1alpha1.NewSharedInformerFactoryWithOptions(client, opts.ResyncPeriod,
+		informersv1alpha1.WithNamespace(opts.Namespace), informersv1alpha1.WithTweakListOptions(opts.TweakListOptions))
 	modelBoosterInformer := informerFactory.Workload().V1alpha1().ModelBoosters()
 	modelServingInformer := filterInformerFactory.Workload().V1alpha1().ModelServings()
 	modelServerInformer := filterInformerFactory.Networking().V1alpha1().ModelServers()
@@ -306,7 +319,7 @@ func NewModelBoosterController(kubeClient kubernetes.Interface, client clientset
 	autoscalingPolicyBindingsInformer := filterInformerFactory.Workload().V1alpha1().AutoscalingPolicyBindings()
 
 	// Initialize Kubernetes informer factory for pods
-	kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	kubeInformerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, opts.ResyncPeriod, informers.WithNamespace(opts.Namespace))
 	podsInformer := kubeInformerFactory.Core().V1().Pods().Informer()
 	podsLister := kubeInformerFactory.Core().V1().Pods().Lister()
 
@@ -343,7 +356,7 @@ func NewModelBoosterController(kubeClient kubernetes.Interface, client clientset
 		loraUpdateCache:                   make(map[string]*workload.ModelBooster),
 
 		workQueue: workqueue.NewTypedRateLimitingQueueWithConfig(workqueue.DefaultTypedControllerRateLimiter[any](),
-			workqueue.TypedRateLimitingQueueConfig[any]{}),
+			workqueue.TypedRateLimitingQueueConfig[any]{Name: "ModelBoosters"}),
 	}
 	klog.Info("Set the ModelBooster event handler")
 	_, err = modelBoosterInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
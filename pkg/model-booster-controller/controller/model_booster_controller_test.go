@@ -25,6 +25,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	kthenafake "github.com/volcano-sh/kthena/client-go/clientset/versioned/fake"
 	workload "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/controller/options"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
@@ -39,7 +40,7 @@ func TestReconcile(t *testing.T) {
 	// Create fake clients for Kubernetes and Kthena
 	kubeClient := fake.NewClientset()
 	kthenaClient := kthenafake.NewSimpleClientset()
-	controller := NewModelBoosterController(kubeClient, kthenaClient)
+	controller := NewModelBoosterController(kubeClient, kthenaClient, options.InformerOptions{})
 	assert.NotNil(t, controller)
 	// Start controller
 	go controller.Run(ctx, 1)
@@ -122,7 +123,7 @@ func TestReconcile_ReturnsError(t *testing.T) {
 	// Create fake clients for Kubernetes and Kthena
 	kubeClient := fake.NewClientset()
 	kthenaClient := kthenafake.NewSimpleClientset()
-	controller := NewModelBoosterController(kubeClient, kthenaClient)
+	controller := NewModelBoosterController(kubeClient, kthenaClient, options.InformerOptions{})
 	assert.NotNil(t, &controller)
 	// start informers
 	go controller.modelsInformer.RunWithContext(ctx)
@@ -166,14 +167,14 @@ func TestReconcile_ReturnsError(t *testing.T) {
 func TestCreateModel(t *testing.T) {
 	kubeClient := fake.NewClientset()
 	kthenaClient := kthenafake.NewClientset()
-	controller := NewModelBoosterController(kubeClient, kthenaClient)
+	controller := NewModelBoosterController(kubeClient, kthenaClient, options.InformerOptions{})
 	controller.createModelBooster("wrong")
 }
 
 func TestUpdateModel(t *testing.T) {
 	kubeClient := fake.NewClientset()
 	kthenaClient := kthenafake.NewClientset()
-	controller := NewModelBoosterController(kubeClient, kthenaClient)
+	controller := NewModelBoosterController(kubeClient, kthenaClient, options.InformerOptions{})
 	assert.NotNil(t, &controller)
 	model := loadYaml[workload.ModelBooster](t, "../convert/testdata/input/model.yaml")
 	// invalid old
@@ -187,14 +188,14 @@ func TestUpdateModel(t *testing.T) {
 func TestDeleteModel(t *testing.T) {
 	kubeClient := fake.NewClientset()
 	kthenaClient := kthenafake.NewClientset()
-	controller := NewModelBoosterController(kubeClient, kthenaClient)
+	controller := NewModelBoosterController(kubeClient, kthenaClient, options.InformerOptions{})
 	controller.deleteModelBooster("invalid")
 }
 
 func TestTriggerModel(t *testing.T) {
 	kubeClient := fake.NewClientset()
 	kthenaClient := kthenafake.NewClientset()
-	controller := NewModelBoosterController(kubeClient, kthenaClient)
+	controller := NewModelBoosterController(kubeClient, kthenaClient, options.InformerOptions{})
 	assert.NotNil(t, &controller)
 	modelServing := loadYaml[workload.ModelServing](t, "../convert/testdata/expected/model-serving.yaml")
 	// invalid new
@@ -82,7 +82,8 @@ func BuildModelServer(model *workload.ModelBooster) ([]*networking.ModelServer,
 					RetryInterval: &metav1.Duration{Duration: time.Duration(0) * time.Second},
 				},
 			},
-			KVConnector: kvConnector,
+			KVConnector:      kvConnector,
+			MaxContextLength: getMaxContextLength(backend),
 		},
 	}
 	modelServer.Labels = utils.GetModelControllerLabels(model, backend.Name, icUtils.Revision(modelServer.Spec))
@@ -91,6 +92,28 @@ func BuildModelServer(model *workload.ModelBooster) ([]*networking.ModelServer,
 	return modelServers, nil
 }
 
+// getMaxContextLength returns the smallest `max-model-len` configured on the backend workers,
+// or nil if none of them sets it.
+func getMaxContextLength(backend workload.ModelBackend) *int32 {
+	var maxContextLength *int32
+	for _, worker := range backend.Workers {
+		value, err := utils.TryGetField(worker.Config.Raw, "max-model-len")
+		if err != nil || value == nil {
+			continue
+		}
+		length, ok := value.(float64)
+		if !ok || length <= 0 {
+			klog.Warningf("invalid max-model-len %v for worker %s (backend %s)", value, worker.Type, backend.Name)
+			continue
+		}
+		if maxContextLength == nil || int32(length) < *maxContextLength {
+			l := int32(length)
+			maxContextLength = &l
+		}
+	}
+	return maxContextLength
+}
+
 func getKvConnectorSpec(backend workload.ModelBackend) (*networking.KVConnectorSpec, error) {
 	var connectorType *networking.KVConnectorType
 	foundConfig := false
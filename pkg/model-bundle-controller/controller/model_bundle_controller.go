@@ -0,0 +1,373 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clientset "github.com/volcano-sh/kthena/client-go/clientset/versioned"
+	informersv1alpha1 "github.com/volcano-sh/kthena/client-go/informers/externalversions"
+	networkingLister "github.com/volcano-sh/kthena/client-go/listers/networking/v1alpha1"
+	workloadLister "github.com/volcano-sh/kthena/client-go/listers/workload/v1alpha1"
+	networking "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	workload "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/controller/options"
+	icUtils "github.com/volcano-sh/kthena/pkg/model-serving-controller/utils"
+)
+
+const (
+	// BundleLabelKey is the label of the resources applied by a ModelBundle, set to the name of the ModelBundle.
+	BundleLabelKey = workload.GroupName + "/model-bundle"
+	// RevisionLabelKey is the label of the resources applied by a ModelBundle, set to the hash of their spec.
+	RevisionLabelKey = workload.GroupName + "/bundle-revision"
+)
+
+// ModelBundleController applies the ModelServing, ModelServer and ModelRoute of the ModelBundles. The ModelServing of
+// a generation of a ModelBundle is applied first, and its ModelServer and ModelRoute once the ModelServing is
+// available, so that the routers never send the requests of the model to a ModelServing which is not ready to serve
+// them. Until then, the ModelServer and ModelRoute of the previous generation stay in place.
+type ModelBundleController struct {
+	client clientset.Interface
+
+	modelBundleLister    workloadLister.ModelBundleLister
+	modelBundleInformer  cache.SharedIndexInformer
+	modelServingLister   workloadLister.ModelServingLister
+	modelServingInformer cache.SharedIndexInformer
+	modelServerLister    networkingLister.ModelServerLister
+	modelServerInformer  cache.SharedIndexInformer
+	modelRouteLister     networkingLister.ModelRouteLister
+	modelRouteInformer   cache.SharedIndexInformer
+
+	workQueue     workqueue.TypedRateLimitingInterface[string]
+	informersOnce sync.Once
+}
+
+func NewModelBundleController(client clientset.Interface, opts options.InformerOptions) *ModelBundleController {
+	informerFactory := informersv1alpha1.NewSharedInformerFactoryWithOptions(client, opts.ResyncPeriod,
+		informersv1alpha1.WithNamespace(opts.Namespace), informersv1alpha1.WithTweakListOptions(opts.TweakListOptions))
+	// The resources applied by the ModelBundles are selected by their bundle label.
+	bundleInformerFactory := informersv1alpha1.NewSharedInformerFactoryWithOptions(client, opts.ResyncPeriod,
+		informersv1alpha1.WithNamespace(opts.Namespace), informersv1alpha1.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = BundleLabelKey
+		}))
+	modelBundleInformer := informerFactory.Workload().V1alpha1().ModelBundles()
+	modelServingInformer := bundleInformerFactory.Workload().V1alpha1().ModelServings()
+	modelServerInformer := bundleInformerFactory.Networking().V1alpha1().ModelServers()
+	modelRouteInformer := bundleInformerFactory.Networking().V1alpha1().ModelRoutes()
+
+	c := &ModelBundleController{
+		client:               client,
+		modelBundleLister:    modelBundleInformer.Lister(),
+		modelBundleInformer:  modelBundleInformer.Informer(),
+		modelServingLister:   modelServingInformer.Lister(),
+		modelServingInformer: modelServingInformer.Informer(),
+		modelServerLister:    modelServerInformer.Lister(),
+		modelServerInformer:  modelServerInformer.Informer(),
+		modelRouteLister:     modelRouteInformer.Lister(),
+		modelRouteInformer:   modelRouteInformer.Informer(),
+		workQueue: workqueue.NewTypedRateLimitingQueueWithConfig(workqueue.DefaultTypedControllerRateLimiter[string](),
+			workqueue.TypedRateLimitingQueueConfig[string]{Name: "ModelBundles"}),
+	}
+
+	if _, err := c.modelBundleInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.enqueue,
+		UpdateFunc: func(_, obj any) { c.enqueue(obj) },
+	}); err != nil {
+		klog.Fatalf("Unable to add ModelBundle event handler: %v", err)
+	}
+	// A ModelServing becoming available, or a resource changed or deleted by someone else, reconciles its ModelBundle.
+	for _, informer := range []cache.SharedIndexInformer{c.modelServingInformer, c.modelServerInformer, c.modelRouteInformer} {
+		if _, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(_, obj any) { c.enqueueBundleOf(obj) },
+			DeleteFunc: c.enqueueBundleOf,
+		}); err != nil {
+			klog.Fatalf("Unable to add ModelBundle resources event handler: %v", err)
+		}
+	}
+	return c
+}
+
+func (c *ModelBundleController) Run(ctx context.Context, workers int) {
+	defer utilruntime.HandleCrash()
+	defer c.workQueue.ShutDown()
+
+	c.WarmUp(ctx)
+
+	klog.Info("start model bundle controller")
+	for i := 0; i < workers; i++ {
+		go c.worker(ctx)
+	}
+	<-ctx.Done()
+	klog.Info("shut down model bundle controller")
+}
+
+// WarmUp starts the informers and waits for their caches to sync, without reconciling.
+// Informers are started once, ctx of the first call stops them.
+func (c *ModelBundleController) WarmUp(ctx context.Context) {
+	c.informersOnce.Do(func() {
+		go c.modelBundleInformer.RunWithContext(ctx)
+		go c.modelServingInformer.RunWithContext(ctx)
+		go c.modelServerInformer.RunWithContext(ctx)
+		go c.modelRouteInformer.RunWithContext(ctx)
+	})
+
+	cache.WaitForCacheSync(ctx.Done(),
+		c.modelBundleInformer.HasSynced,
+		c.modelServingInformer.HasSynced,
+		c.modelServerInformer.HasSynced,
+		c.modelRouteInformer.HasSynced,
+	)
+}
+
+func (c *ModelBundleController) worker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *ModelBundleController) processNextWorkItem(ctx context.Context) bool {
+	key, quit := c.workQueue.Get()
+	if quit {
+		return false
+	}
+	defer c.workQueue.Done(key)
+
+	if err := c.reconcile(ctx, key); err != nil {
+		utilruntime.HandleError(fmt.Errorf("sync %q failed with %v", key, err))
+		c.workQueue.AddRateLimited(key)
+		return true
+	}
+	c.workQueue.Forget(key)
+	return true
+}
+
+func (c *ModelBundleController) enqueue(obj any) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.workQueue.Add(key)
+}
+
+// enqueueBundleOf enqueues the ModelBundle which applied a ModelServing, ModelServer or ModelRoute.
+func (c *ModelBundleController) enqueueBundleOf(obj any) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	object, err := meta.Accessor(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	if name := object.GetLabels()[BundleLabelKey]; name != "" {
+		c.workQueue.Add(object.GetNamespace() + "/" + name)
+	}
+}
+
+// reconcile applies the ModelServing of the current generation of a ModelBundle, and its ModelServer and ModelRoute
+// once the ModelServing is available. The progress is recorded in the status of the ModelBundle.
+func (c *ModelBundleController) reconcile(ctx context.Context, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return fmt.Errorf("invalid resource key: %s", err)
+	}
+	bundle, err := c.modelBundleLister.ModelBundles(namespace).Get(name)
+	if err != nil {
+		// The resources of a deleted ModelBundle are deleted with it by their owner reference.
+		return client.IgnoreNotFound(err)
+	}
+
+	modelServing := buildModelServing(bundle)
+	if err := c.applyModelServing(ctx, modelServing); err != nil {
+		return c.updateStatus(ctx, bundle, metav1.ConditionFalse, workload.ModelBundleApplyFailed, err)
+	}
+	if !c.isModelServingAvailable(modelServing) {
+		// The ModelBundle is reconciled again when its ModelServing is updated.
+		return c.updateStatus(ctx, bundle, metav1.ConditionFalse, workload.ModelBundleModelServingNotAvailable,
+			fmt.Errorf("waiting for ModelServing %s to be available", modelServing.Name))
+	}
+	if err := c.applyModelServer(ctx, buildModelServer(bundle)); err != nil {
+		return c.updateStatus(ctx, bundle, metav1.ConditionFalse, workload.ModelBundleApplyFailed, err)
+	}
+	if err := c.applyModelRoute(ctx, buildModelRoute(bundle)); err != nil {
+		return c.updateStatus(ctx, bundle, metav1.ConditionFalse, workload.ModelBundleApplyFailed, err)
+	}
+	if bundle.Status.ActiveGeneration != bundle.Generation {
+		klog.Infof("ModelBundle %s activated generation %d", klog.KObj(bundle), bundle.Generation)
+	}
+	return c.updateStatus(ctx, bundle, metav1.ConditionTrue, workload.ModelBundleApplied, nil)
+}
+
+// isModelServingAvailable returns whether the ModelServing applied is available at its spec: the ModelServing
+// controller observed it, it is available, and its rolling update is over unless it is partitioned.
+func (c *ModelBundleController) isModelServingAvailable(applied *workload.ModelServing) bool {
+	modelServing, err := c.modelServingLister.ModelServings(applied.Namespace).Get(applied.Name)
+	if err != nil || modelServing.Labels[RevisionLabelKey] != applied.Labels[RevisionLabelKey] {
+		return false
+	}
+	if modelServing.Status.ObservedGeneration < modelServing.Generation ||
+		!meta.IsStatusConditionTrue(modelServing.Status.Conditions, string(workload.ModelServingAvailable)) {
+		return false
+	}
+	strategy := modelServing.Spec.RolloutStrategy
+	if strategy != nil && strategy.RollingUpdateConfiguration != nil && strategy.RollingUpdateConfiguration.Partition != nil {
+		return true
+	}
+	replicas := int32(1)
+	if modelServing.Spec.Replicas != nil {
+		replicas = *modelServing.Spec.Replicas
+	}
+	return modelServing.Status.UpdatedReplicas >= replicas
+}
+
+func (c *ModelBundleController) applyModelServing(ctx context.Context, modelServing *workload.ModelServing) error {
+	existing, err := c.modelServingLister.ModelServings(modelServing.Namespace).Get(modelServing.Name)
+	switch {
+	case apierrors.IsNotFound(err):
+		_, err = c.client.WorkloadV1alpha1().ModelServings(modelServing.Namespace).Create(ctx, modelServing, metav1.CreateOptions{})
+	case err == nil && existing.Labels[RevisionLabelKey] != modelServing.Labels[RevisionLabelKey]:
+		modelServing.ResourceVersion = existing.ResourceVersion
+		modelServing.Status = existing.Status
+		_, err = c.client.WorkloadV1alpha1().ModelServings(modelServing.Namespace).Update(ctx, modelServing, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply ModelServing %s: %v", modelServing.Name, err)
+	}
+	return nil
+}
+
+func (c *ModelBundleController) applyModelServer(ctx context.Context, modelServer *networking.ModelServer) error {
+	existing, err := c.modelServerLister.ModelServers(modelServer.Namespace).Get(modelServer.Name)
+	switch {
+	case apierrors.IsNotFound(err):
+		_, err = c.client.NetworkingV1alpha1().ModelServers(modelServer.Namespace).Create(ctx, modelServer, metav1.CreateOptions{})
+	case err == nil && existing.Labels[RevisionLabelKey] != modelServer.Labels[RevisionLabelKey]:
+		modelServer.ResourceVersion = existing.ResourceVersion
+		_, err = c.client.NetworkingV1alpha1().ModelServers(modelServer.Namespace).Update(ctx, modelServer, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply ModelServer %s: %v", modelServer.Name, err)
+	}
+	return nil
+}
+
+func (c *ModelBundleController) applyModelRoute(ctx context.Context, modelRoute *networking.ModelRoute) error {
+	existing, err := c.modelRouteLister.ModelRoutes(modelRoute.Namespace).Get(modelRoute.Name)
+	switch {
+	case apierrors.IsNotFound(err):
+		_, err = c.client.NetworkingV1alpha1().ModelRoutes(modelRoute.Namespace).Create(ctx, modelRoute, metav1.CreateOptions{})
+	case err == nil && existing.Labels[RevisionLabelKey] != modelRoute.Labels[RevisionLabelKey]:
+		modelRoute.ResourceVersion = existing.ResourceVersion
+		// The status of the ModelRoute, e.g. the progress of its rollout, is kept by the update.
+		modelRoute.Status = existing.Status
+		_, err = c.client.NetworkingV1alpha1().ModelRoutes(modelRoute.Namespace).Update(ctx, modelRoute, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply ModelRoute %s: %v", modelRoute.Name, err)
+	}
+	return nil
+}
+
+// updateStatus records the Ready condition of the current generation of a ModelBundle, the active generation being
+// the current one once it is ready. It returns the error of the condition, nil if the ModelBundle is waiting for its
+// ModelServing, so that a failed apply is retried.
+func (c *ModelBundleController) updateStatus(ctx context.Context, bundle *workload.ModelBundle, status metav1.ConditionStatus, reason string, cause error) error {
+	updated := bundle.DeepCopy()
+	updated.Status.ObservedGeneration = bundle.Generation
+	message := "ModelServing, ModelServer and ModelRoute applied"
+	if status == metav1.ConditionTrue {
+		updated.Status.ActiveGeneration = bundle.Generation
+	} else {
+		message = cause.Error()
+	}
+	meta.SetStatusCondition(&updated.Status.Conditions, metav1.Condition{
+		Type:               string(workload.ModelBundleReady),
+		Status:             status,
+		ObservedGeneration: bundle.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	if reason == workload.ModelBundleModelServingNotAvailable {
+		cause = nil
+	}
+	if statusEqual(bundle.Status, updated.Status) {
+		return cause
+	}
+	if _, err := c.client.WorkloadV1alpha1().ModelBundles(bundle.Namespace).UpdateStatus(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update the status of ModelBundle %s: %v", klog.KObj(bundle), err)
+	}
+	return cause
+}
+
+// statusEqual compares the status of a ModelBundle, the transition times of the conditions aside.
+func statusEqual(a, b workload.ModelBundleStatus) bool {
+	if a.ObservedGeneration != b.ObservedGeneration || a.ActiveGeneration != b.ActiveGeneration || len(a.Conditions) != len(b.Conditions) {
+		return false
+	}
+	for i := range a.Conditions {
+		x, y := a.Conditions[i], b.Conditions[i]
+		if x.Type != y.Type || x.Status != y.Status || x.ObservedGeneration != y.ObservedGeneration || x.Reason != y.Reason || x.Message != y.Message {
+			return false
+		}
+	}
+	return true
+}
+
+// objectMeta returns the metadata of a resource applied by a ModelBundle, named after it and owned by it.
+func objectMeta(bundle *workload.ModelBundle, spec any) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      bundle.Name,
+		Namespace: bundle.Namespace,
+		Labels: map[string]string{
+			BundleLabelKey:   bundle.Name,
+			RevisionLabelKey: icUtils.Revision(spec),
+		},
+		OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(bundle, workload.ModelBundleKind)},
+	}
+}
+
+func buildModelServing(bundle *workload.ModelBundle) *workload.ModelServing {
+	return &workload.ModelServing{
+		ObjectMeta: objectMeta(bundle, bundle.Spec.ModelServing),
+		Spec:       *bundle.Spec.ModelServing.DeepCopy(),
+	}
+}
+
+func buildModelServer(bundle *workload.ModelBundle) *networking.ModelServer {
+	return &networking.ModelServer{
+		ObjectMeta: objectMeta(bundle, bundle.Spec.ModelServer),
+		Spec:       *bundle.Spec.ModelServer.DeepCopy(),
+	}
+}
+
+func buildModelRoute(bundle *workload.ModelBundle) *networking.ModelRoute {
+	return &networking.ModelRoute{
+		ObjectMeta: objectMeta(bundle, bundle.Spec.ModelRoute),
+		Spec:       *bundle.Spec.ModelRoute.DeepCopy(),
+	}
+}
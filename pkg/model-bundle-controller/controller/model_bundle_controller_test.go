@@ -0,0 +1,169 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	kthenafake "github.com/volcano-sh/kthena/client-go/clientset/versioned/fake"
+	networking "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	workload "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/controller/options"
+)
+
+func newModelBundle(image string, generation int64) *workload.ModelBundle {
+	return &workload.ModelBundle{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "llama", UID: "bundle-uid", Generation: generation},
+		Spec: workload.ModelBundleSpec{
+			ModelServing: workload.ModelServingSpec{
+				Replicas: ptr.To[int32](1),
+				Template: workload.ServingGroup{
+					Roles: []workload.Role{{
+						Name:          "server",
+						Replicas:      ptr.To[int32](1),
+						EntryTemplate: workload.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "vllm", Image: image}}}},
+					}},
+				},
+			},
+			ModelServer: networking.ModelServerSpec{
+				Model:            ptr.To("llama"),
+				InferenceEngine:  networking.VLLM,
+				WorkloadSelector: &networking.WorkloadSelector{MatchLabels: map[string]string{"app": "llama"}},
+			},
+			ModelRoute: networking.ModelRouteSpec{
+				ModelName: "llama",
+				Rules:     []*networking.Rule{{TargetModels: []*networking.TargetModel{{ModelServerName: "llama"}}}},
+			},
+		},
+	}
+}
+
+// syncListers copies the objects of the fake client into the listers of the controller, as the informers would.
+func syncListers(t *testing.T, c *ModelBundleController) {
+	ctx := context.Background()
+	bundles, err := c.client.WorkloadV1alpha1().ModelBundles("default").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	for i := range bundles.Items {
+		require.NoError(t, c.modelBundleInformer.GetIndexer().Update(&bundles.Items[i]))
+	}
+	modelServings, err := c.client.WorkloadV1alpha1().ModelServings("default").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	for i := range modelServings.Items {
+		require.NoError(t, c.modelServingInformer.GetIndexer().Update(&modelServings.Items[i]))
+	}
+	modelServers, err := c.client.NetworkingV1alpha1().ModelServers("default").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	for i := range modelServers.Items {
+		require.NoError(t, c.modelServerInformer.GetIndexer().Update(&modelServers.Items[i]))
+	}
+	modelRoutes, err := c.client.NetworkingV1alpha1().ModelRoutes("default").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	for i := range modelRoutes.Items {
+		require.NoError(t, c.modelRouteInformer.GetIndexer().Update(&modelRoutes.Items[i]))
+	}
+}
+
+// setModelServingAvailable records the rollout of the ModelServing of the bundle as the ModelServing controller would.
+func setModelServingAvailable(t *testing.T, c *ModelBundleController) {
+	ctx := context.Background()
+	modelServing, err := c.client.WorkloadV1alpha1().ModelServings("default").Get(ctx, "llama", metav1.GetOptions{})
+	require.NoError(t, err)
+	modelServing.Status.UpdatedReplicas = 1
+	modelServing.Status.AvailableReplicas = 1
+	meta.SetStatusCondition(&modelServing.Status.Conditions, metav1.Condition{
+		Type: string(workload.ModelServingAvailable), Status: metav1.ConditionTrue, Reason: "AllGroupsReady",
+	})
+	_, err = c.client.WorkloadV1alpha1().ModelServings("default").UpdateStatus(ctx, modelServing, metav1.UpdateOptions{})
+	require.NoError(t, err)
+}
+
+func getStatus(t *testing.T, c *ModelBundleController) workload.ModelBundleStatus {
+	bundle, err := c.client.WorkloadV1alpha1().ModelBundles("default").Get(context.Background(), "llama", metav1.GetOptions{})
+	require.NoError(t, err)
+	return bundle.Status
+}
+
+func TestModelBundleAppliesRouteOnceServingAvailable(t *testing.T) {
+	ctx := context.Background()
+	c := NewModelBundleController(kthenafake.NewSimpleClientset(newModelBundle("vllm:v1", 1)), options.InformerOptions{})
+	reconcile := func() {
+		syncListers(t, c)
+		require.NoError(t, c.reconcile(ctx, "default/llama"))
+		syncListers(t, c)
+	}
+
+	// The ModelServing is applied first, the ModelServer and ModelRoute wait for it.
+	reconcile()
+	modelServing, err := c.client.WorkloadV1alpha1().ModelServings("default").Get(ctx, "llama", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "llama", modelServing.Labels[BundleLabelKey])
+	assert.Equal(t, "bundle-uid", string(modelServing.OwnerReferences[0].UID))
+	_, err = c.client.NetworkingV1alpha1().ModelRoutes("default").Get(ctx, "llama", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+	status := getStatus(t, c)
+	assert.Equal(t, int64(0), status.ActiveGeneration)
+	ready := meta.FindStatusCondition(status.Conditions, string(workload.ModelBundleReady))
+	require.NotNil(t, ready)
+	assert.Equal(t, workload.ModelBundleModelServingNotAvailable, ready.Reason)
+
+	setModelServingAvailable(t, c)
+	reconcile()
+	_, err = c.client.NetworkingV1alpha1().ModelServers("default").Get(ctx, "llama", metav1.GetOptions{})
+	require.NoError(t, err)
+	route, err := c.client.NetworkingV1alpha1().ModelRoutes("default").Get(ctx, "llama", metav1.GetOptions{})
+	require.NoError(t, err)
+	status = getStatus(t, c)
+	assert.Equal(t, int64(1), status.ActiveGeneration)
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, string(workload.ModelBundleReady)))
+
+	// The ModelRoute of the previous generation stays in place until the ModelServing of the next one is available.
+	bundle := newModelBundle("vllm:v2", 2)
+	bundle.Spec.ModelRoute.Aliases = []string{"llama-latest"}
+	bundle.Status = status
+	_, err = c.client.WorkloadV1alpha1().ModelBundles("default").Update(ctx, bundle, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	reconcile()
+	modelServing, err = c.client.WorkloadV1alpha1().ModelServings("default").Get(ctx, "llama", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "vllm:v2", modelServing.Spec.Template.Roles[0].EntryTemplate.Spec.Containers[0].Image)
+	current, err := c.client.NetworkingV1alpha1().ModelRoutes("default").Get(ctx, "llama", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, route.Labels[RevisionLabelKey], current.Labels[RevisionLabelKey])
+	assert.Empty(t, current.Spec.Aliases)
+	assert.Equal(t, int64(1), getStatus(t, c).ActiveGeneration)
+
+	setModelServingAvailable(t, c)
+	reconcile()
+	current, err = c.client.NetworkingV1alpha1().ModelRoutes("default").Get(ctx, "llama", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"llama-latest"}, current.Spec.Aliases)
+	assert.Equal(t, int64(2), getStatus(t, c).ActiveGeneration)
+}
+
+func TestModelBundleDeleted(t *testing.T) {
+	c := NewModelBundleController(kthenafake.NewSimpleClientset(), options.InformerOptions{})
+	assert.NoError(t, c.reconcile(context.Background(), "default/llama"))
+}
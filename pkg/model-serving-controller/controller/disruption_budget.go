@@ -0,0 +1,148 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	workloadv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/controller/options"
+	"github.com/volcano-sh/kthena/pkg/model-serving-controller/utils"
+)
+
+// EnableDisruptionBudgets makes the controller manage the PodDisruptionBudgets of the ModelServings with a disruption
+// budget. It must be called before Run, the controller manager only calls it when it is granted the permissions on the
+// PodDisruptionBudgets.
+func (c *ModelServingController) EnableDisruptionBudgets(opts options.InformerOptions) error {
+	selector, err := labels.NewRequirement(workloadv1alpha1.ModelServingNameLabelKey, selection.Exists, nil)
+	if err != nil {
+		return fmt.Errorf("cannot create label selector, err: %v", err)
+	}
+	factory := informers.NewSharedInformerFactoryWithOptions(
+		c.kubeClientSet,
+		opts.ResyncPeriod,
+		informers.WithNamespace(opts.Namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = selector.String()
+		}),
+	)
+	pdbsInformer := factory.Policy().V1().PodDisruptionBudgets()
+	c.pdbsLister = pdbsInformer.Lister()
+	c.pdbsInformer = pdbsInformer.Informer()
+
+	_, err = c.pdbsInformer.AddEventHandler(c.activeHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			metaObj := getMetaObject(obj)
+			if metaObj == nil {
+				return false
+			}
+			return isOwnedByModelServing(metaObj)
+		},
+		Handler: cache.ResourceEventHandlerFuncs{
+			DeleteFunc: func(obj interface{}) {
+				c.deleteDisruptionBudget(obj)
+			},
+		},
+	}))
+	return err
+}
+
+func (c *ModelServingController) deleteDisruptionBudget(obj interface{}) {
+	metaObj := getMetaObject(obj)
+	if metaObj == nil {
+		klog.Error("failed to parse PodDisruptionBudget type when deleteDisruptionBudget")
+		return
+	}
+	msName := metaObj.GetLabels()[workloadv1alpha1.ModelServingNameLabelKey]
+	ms, err := c.modelServingLister.ModelServings(metaObj.GetNamespace()).Get(msName)
+	if err != nil {
+		klog.V(4).Infof("ModelServing of deleted PodDisruptionBudget %s/%s not found: %v", metaObj.GetNamespace(), metaObj.GetName(), err)
+		return
+	}
+	if !utils.IsOwnedByModelServingWithUID(metaObj, ms.UID) {
+		return
+	}
+	klog.V(4).Infof("PodDisruptionBudget %s/%s deleted, enqueuing ModelServing %s for reconcile", metaObj.GetNamespace(), metaObj.GetName(), ms.Name)
+	c.enqueueModelServing(ms)
+}
+
+// manageDisruptionBudgets creates, updates and deletes the PodDisruptionBudgets of a ModelServing to match its
+// disruption budget.
+func (c *ModelServingController) manageDisruptionBudgets(ctx context.Context, ms *workloadv1alpha1.ModelServing) error {
+	if c.pdbsLister == nil {
+		return nil
+	}
+	desired, err := utils.GenerateDisruptionBudgets(ms)
+	if err != nil {
+		return err
+	}
+	existing, err := c.pdbsLister.PodDisruptionBudgets(ms.Namespace).List(labels.SelectorFromSet(map[string]string{
+		workloadv1alpha1.ModelServingNameLabelKey: ms.Name,
+	}))
+	if err != nil {
+		return fmt.Errorf("cannot list PodDisruptionBudgets: %v", err)
+	}
+	existingByName := make(map[string]*policyv1.PodDisruptionBudget, len(existing))
+	for _, pdb := range existing {
+		if utils.IsOwnedByModelServingWithUID(pdb, ms.UID) {
+			existingByName[pdb.Name] = pdb
+		}
+	}
+
+	var errs []error
+	for _, pdb := range desired {
+		current, ok := existingByName[pdb.Name]
+		delete(existingByName, pdb.Name)
+		if !ok {
+			klog.V(4).Infof("Creating PodDisruptionBudget %s/%s", pdb.Namespace, pdb.Name)
+			if _, err := c.kubeClientSet.PolicyV1().PodDisruptionBudgets(ms.Namespace).Create(ctx, pdb, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+				errs = append(errs, fmt.Errorf("create PodDisruptionBudget %s failed: %v", pdb.Name, err))
+			}
+			continue
+		}
+		if reflect.DeepEqual(current.Spec, pdb.Spec) && reflect.DeepEqual(current.Labels, pdb.Labels) {
+			continue
+		}
+		updated := current.DeepCopy()
+		updated.Labels = pdb.Labels
+		updated.Spec = pdb.Spec
+		klog.V(4).Infof("Updating PodDisruptionBudget %s/%s", pdb.Namespace, pdb.Name)
+		if _, err := c.kubeClientSet.PolicyV1().PodDisruptionBudgets(ms.Namespace).Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+			errs = append(errs, fmt.Errorf("update PodDisruptionBudget %s failed: %v", pdb.Name, err))
+		}
+	}
+	// The remaining budgets belong to removed roles, or to a removed or reshaped disruption budget.
+	for name := range existingByName {
+		klog.V(4).Infof("Deleting PodDisruptionBudget %s/%s", ms.Namespace, name)
+		if err := c.kubeClientSet.PolicyV1().PodDisruptionBudgets(ms.Namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("delete PodDisruptionBudget %s failed: %v", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,217 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+
+	workloadv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/model-serving-controller/utils"
+)
+
+func TestManageDisruptionBudgets(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset()
+	pdbsInformer := informers.NewSharedInformerFactory(kubeClient, 0).Policy().V1().PodDisruptionBudgets()
+	controller := &ModelServingController{
+		kubeClientSet: kubeClient,
+		pdbsLister:    pdbsInformer.Lister(),
+	}
+	ctx := context.Background()
+
+	ms := &workloadv1alpha1.ModelServing{
+		ObjectMeta: metav1.ObjectMeta{Name: "llm", Namespace: "default", UID: "uid"},
+		Spec: workloadv1alpha1.ModelServingSpec{
+			Replicas: ptr.To[int32](2),
+			Template: workloadv1alpha1.ServingGroup{
+				Roles: []workloadv1alpha1.Role{
+					{Name: "prefill", Replicas: ptr.To[int32](1)},
+					{Name: "decode", Replicas: ptr.To[int32](2), WorkerReplicas: 3},
+				},
+			},
+			DisruptionBudget: &workloadv1alpha1.DisruptionBudget{
+				MinAvailable:     ptr.To(intstr.FromString("50%")),
+				MinRoleAvailable: map[string]intstr.IntOrString{"prefill": intstr.FromInt32(2)},
+			},
+		},
+	}
+	// pods returns the entry and worker pods of all the replicas of the roles in all the ServingGroups.
+	pods := func() []labels.Set {
+		var pods []labels.Set
+		for range *ms.Spec.Replicas {
+			for _, role := range ms.Spec.Template.Roles {
+				for range *role.Replicas * (1 + role.WorkerReplicas) {
+					pods = append(pods, labels.Set{
+						workloadv1alpha1.ModelServingNameLabelKey: ms.Name,
+						workloadv1alpha1.RoleLabelKey:             role.Name,
+					})
+				}
+			}
+		}
+		return pods
+	}
+	// syncBudgets reconciles the budgets and mirrors them in the lister, as the informer would. It returns the pods
+	// each budget selects and the number of them the disruption controller allows to evict while they are all healthy.
+	type budget struct{ selected, evictable int }
+	syncBudgets := func() map[string]budget {
+		require.NoError(t, controller.manageDisruptionBudgets(ctx, ms))
+		pdbs, err := kubeClient.PolicyV1().PodDisruptionBudgets("default").List(ctx, metav1.ListOptions{})
+		require.NoError(t, err)
+		store := pdbsInformer.Informer().GetStore()
+		require.NoError(t, store.Replace(nil, ""))
+		budgets := make(map[string]budget)
+		for i := range pdbs.Items {
+			pdb := &pdbs.Items[i]
+			require.NoError(t, store.Add(pdb))
+			// The budgets are counted in pods, not in the scale of their owner.
+			require.Nil(t, pdb.Spec.MaxUnavailable)
+			require.Equal(t, intstr.Int, pdb.Spec.MinAvailable.Type)
+			selector := labels.SelectorFromSet(pdb.Spec.Selector.MatchLabels)
+			selected := 0
+			for _, pod := range pods() {
+				if selector.Matches(pod) {
+					selected++
+				}
+			}
+			budgets[pdb.Name] = budget{selected: selected, evictable: max(selected-pdb.Spec.MinAvailable.IntValue(), 0)}
+		}
+		return budgets
+	}
+
+	// The 2 prefill replicas of a pod stay available. 2 of the 4 decode replicas of 4 pods stay available, a pod of
+	// each of the 2 others may be evicted.
+	assert.Equal(t, map[string]budget{"llm-prefill": {selected: 2, evictable: 0}, "llm-decode": {selected: 16, evictable: 2}}, syncBudgets())
+
+	pdb, err := kubeClient.PolicyV1().PodDisruptionBudgets("default").Get(ctx, "llm-decode", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		workloadv1alpha1.ModelServingNameLabelKey: "llm",
+		workloadv1alpha1.RoleLabelKey:             "decode",
+	}, pdb.Spec.Selector.MatchLabels)
+	assert.Equal(t, "llm", pdb.OwnerReferences[0].Name)
+
+	// Scaling the ServingGroups updates the budgets.
+	ms.Spec.Replicas = ptr.To[int32](4)
+	assert.Equal(t, map[string]budget{"llm-prefill": {selected: 4, evictable: 2}, "llm-decode": {selected: 32, evictable: 4}}, syncBudgets())
+
+	// Recreating whole ServingGroups replaces the budgets of the roles with one counting the ServingGroups: a pod of
+	// 2 of the 4 ServingGroups of 9 pods may be evicted.
+	ms.Spec.RecoveryPolicy = workloadv1alpha1.ServingGroupRecreate
+	assert.Equal(t, map[string]budget{"llm": {selected: 36, evictable: 2}}, syncBudgets())
+
+	// With the default of 1 available replica, a single ServingGroup of 2 decode replicas of 4 pods keeps a replica.
+	ms.Spec.RecoveryPolicy = ""
+	ms.Spec.Replicas = ptr.To[int32](1)
+	ms.Spec.Template.Roles = []workloadv1alpha1.Role{{Name: "decode", Replicas: ptr.To[int32](2), WorkerReplicas: 3}}
+	ms.Spec.DisruptionBudget = &workloadv1alpha1.DisruptionBudget{}
+	assert.Equal(t, map[string]budget{"llm-decode": {selected: 8, evictable: 1}}, syncBudgets())
+
+	// Removing the disruption budget deletes the budgets.
+	ms.Spec.DisruptionBudget = nil
+	assert.Empty(t, syncBudgets())
+}
+
+// TestDisruptionBudgetSpreadEvictions evicts a pod of each replica in turn, as a drain of nodes hosting a pod of every
+// replica would, and checks that the evictions allowed by the budget break no more replicas than it may.
+func TestDisruptionBudgetSpreadEvictions(t *testing.T) {
+	tests := []struct {
+		name               string
+		recoveryPolicy     workloadv1alpha1.RecoveryPolicy
+		servingGroups      int32
+		role               workloadv1alpha1.Role
+		minAvailable       intstr.IntOrString
+		replicas           int
+		podsPerReplica     int
+		expectMaxBroken    int
+		expectBudgetSuffix string
+	}{
+		{
+			name:               "4 decode replicas of 4 pods keep 2",
+			servingGroups:      2,
+			role:               workloadv1alpha1.Role{Name: "decode", Replicas: ptr.To[int32](2), WorkerReplicas: 3},
+			minAvailable:       intstr.FromString("50%"),
+			replicas:           4,
+			podsPerReplica:     4,
+			expectMaxBroken:    2,
+			expectBudgetSuffix: "-decode",
+		},
+		{
+			name:            "3 ServingGroups of 2 pods keep 2",
+			recoveryPolicy:  workloadv1alpha1.ServingGroupRecreate,
+			servingGroups:   3,
+			role:            workloadv1alpha1.Role{Name: "server", Replicas: ptr.To[int32](1), WorkerReplicas: 1},
+			minAvailable:    intstr.FromInt32(2),
+			replicas:        3,
+			podsPerReplica:  2,
+			expectMaxBroken: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := &workloadv1alpha1.ModelServing{
+				ObjectMeta: metav1.ObjectMeta{Name: "llm", Namespace: "default"},
+				Spec: workloadv1alpha1.ModelServingSpec{
+					Replicas:         ptr.To(tt.servingGroups),
+					RecoveryPolicy:   tt.recoveryPolicy,
+					Template:         workloadv1alpha1.ServingGroup{Roles: []workloadv1alpha1.Role{tt.role}},
+					DisruptionBudget: &workloadv1alpha1.DisruptionBudget{MinAvailable: ptr.To(tt.minAvailable)},
+				},
+			}
+			pdbs, err := utils.GenerateDisruptionBudgets(ms)
+			require.NoError(t, err)
+			require.Len(t, pdbs, 1)
+			assert.Equal(t, "llm"+tt.expectBudgetSuffix, pdbs[0].Name)
+			minAvailable := pdbs[0].Spec.MinAvailable.IntValue()
+
+			// healthy counts the healthy pods of each replica. The disruption controller allows an eviction while the
+			// healthy pods stay at or above minAvailable.
+			healthy := make([]int, tt.replicas)
+			total := tt.replicas * tt.podsPerReplica
+			for i := range healthy {
+				healthy[i] = tt.podsPerReplica
+			}
+			for evicted := true; evicted; {
+				evicted = false
+				for i := range healthy {
+					if healthy[i] == 0 || total-1 < minAvailable {
+						continue
+					}
+					healthy[i]--
+					total--
+					evicted = true
+				}
+			}
+			broken := 0
+			for _, pods := range healthy {
+				if pods < tt.podsPerReplica {
+					broken++
+				}
+			}
+			assert.Equal(t, tt.expectMaxBroken, broken)
+		})
+	}
+}
@@ -41,12 +41,14 @@ import (
 	kthenainformers "github.com/volcano-sh/kthena/client-go/informers/externalversions"
 	kthenalisters "github.com/volcano-sh/kthena/client-go/listers/workload/v1alpha1"
 	workloadv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/controller/options"
 )
 
 func InitializeLWSController(
 	cfg *rest.Config,
 	kubeClient kubernetes.Interface,
 	kthenaClient kthenaclientset.Interface,
+	opts options.InformerOptions,
 ) (*LWSController, error) {
 	exists, err := ResourceExists(kubeClient, "leaderworkerset.x-k8s.io/v1", "LeaderWorkerSet")
 	if err != nil {
@@ -61,8 +63,9 @@ func InitializeLWSController(
 		return nil, fmt.Errorf("failed to create lws client: %v", err)
 	}
 
-	lwsInformerFactory := lwsinformers.NewSharedInformerFactory(lwsClient, 0)
-	kthenaInformerFactory := kthenainformers.NewSharedInformerFactory(kthenaClient, 0)
+	lwsInformerFactory := lwsinformers.NewSharedInformerFactoryWithOptions(lwsClient, opts.ResyncPeriod,
+		lwsinformers.WithNamespace(opts.Namespace), lwsinformers.WithTweakListOptions(opts.TweakListOptions))
+	kthenaInformerFactory := kthenainformers.NewSharedInformerFactoryWithOptions(kthenaClient, opts.ResyncPeriod, kthenainformers.WithNamespace(opts.Namespace))
 
 	controller, err := NewLWSController(kubeClient, kthenaClient, lwsClient, lwsInformerFactory, kthenaInformerFactory)
 	if err != nil {
@@ -149,11 +152,7 @@ func (c *LWSController) Run(ctx context.Context, workers int) error {
 
 	klog.Info("Starting LWS controller")
 
-	c.lwsInformerFactory.Start(ctx.Done())
-	c.kthenaInformerFactory.Start(ctx.Done())
-
-	klog.Info("Waiting for informer caches to sync")
-	if ok := cache.WaitForCacheSync(ctx.Done(), c.lwsSynced, c.modelServingSynced); !ok {
+	if ok := c.WarmUp(ctx); !ok {
 		return fmt.Errorf("failed to wait for caches to sync")
 	}
 
@@ -169,6 +168,16 @@ func (c *LWSController) Run(ctx context.Context, workers int) error {
 	return nil
 }
 
+// WarmUp starts the informers and waits for their caches to sync, without reconciling.
+// It returns false if ctx is done before the caches are synced.
+func (c *LWSController) WarmUp(ctx context.Context) bool {
+	c.lwsInformerFactory.Start(ctx.Done())
+	c.kthenaInformerFactory.Start(ctx.Done())
+
+	klog.Info("Waiting for informer caches to sync")
+	return cache.WaitForCacheSync(ctx.Done(), c.lwsSynced, c.modelServingSynced)
+}
+
 func (c *LWSController) runWorker(ctx context.Context) {
 	for c.processNextWorkItem(ctx) {
 	}
@@ -24,6 +24,7 @@ import (
 	"reflect"
 	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -39,6 +40,7 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	listerv1 "k8s.io/client-go/listers/core/v1"
+	listerpolicyv1 "k8s.io/client-go/listers/policy/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
@@ -51,6 +53,7 @@ import (
 	informersv1alpha1 "github.com/volcano-sh/kthena/client-go/informers/externalversions"
 	listerv1alpha1 "github.com/volcano-sh/kthena/client-go/listers/workload/v1alpha1"
 	workloadv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/controller/options"
 	"github.com/volcano-sh/kthena/pkg/model-serving-controller/datastore"
 	"github.com/volcano-sh/kthena/pkg/model-serving-controller/plugins"
 	"github.com/volcano-sh/kthena/pkg/model-serving-controller/podgroupmanager"
@@ -77,6 +80,12 @@ type ModelServingController struct {
 	servicesInformer      cache.SharedIndexInformer
 	modelServingLister    listerv1alpha1.ModelServingLister
 	modelServingsInformer cache.SharedIndexInformer
+	// pdbsLister and pdbsInformer are nil unless the PodDisruptionBudgets are enabled.
+	pdbsLister   listerpolicyv1.PodDisruptionBudgetLister
+	pdbsInformer cache.SharedIndexInformer
+	// nodesLister and nodesInformer are nil unless the placement cost is enabled.
+	nodesLister   listerv1.NodeLister
+	nodesInformer cache.SharedIndexInformer
 
 	// nolint
 	workqueue       workqueue.RateLimitingInterface
@@ -85,9 +94,13 @@ type ModelServingController struct {
 	initialSync     bool     // indicates whether the initial sync has been completed
 	pluginsRegistry *plugins.Registry
 	recorder        record.EventRecorder
+	informersOnce   sync.Once
+	// standby is set while the controller only keeps its informer caches warm, events are ignored until Run,
+	// as handling them updates the ModelServing resources.
+	standby atomic.Bool
 }
 
-func NewModelServingController(kubeClientSet kubernetes.Interface, modelServingClient clientset.Interface, volcanoClient volcano.Interface, apiextClient apiextClientSet.Interface) (*ModelServingController, error) {
+func NewModelServingController(kubeClientSet kubernetes.Interface, modelServingClient clientset.Interface, volcanoClient volcano.Interface, apiextClient apiextClientSet.Interface, opts options.InformerOptions) (*ModelServingController, error) {
 	selector, err := labels.NewRequirement(workloadv1alpha1.GroupNameLabelKey, selection.Exists, nil)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create label selector, err: %v", err)
@@ -100,14 +113,16 @@ func NewModelServingController(kubeClientSet kubernetes.Interface, modelServingC
 
 	kubeInformerFactory := informers.NewSharedInformerFactoryWithOptions(
 		kubeClientSet,
-		0,
+		opts.ResyncPeriod,
+		informers.WithNamespace(opts.Namespace),
 		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
 			opts.LabelSelector = selector.String()
 		}),
 	)
 	podsInformer := kubeInformerFactory.Core().V1().Pods()
 	servicesInformer := kubeInformerFactory.Core().V1().Services()
-	modelServingInformerFactory := informersv1alpha1.NewSharedInformerFactory(modelServingClient, 0)
+	modelServingInformerFactory := informersv1alpha1.NewSharedInformerFactoryWithOptions(modelServingClient, opts.ResyncPeriod,
+		informersv1alpha1.WithNamespace(opts.Namespace), informersv1alpha1.WithTweakListOptions(opts.TweakListOptions))
 	modelServingInformer := modelServingInformerFactory.Workload().V1alpha1().ModelServings()
 
 	err = podsInformer.Informer().AddIndexers(cache.Indexers{
@@ -160,7 +175,7 @@ func NewModelServingController(kubeClientSet kubernetes.Interface, modelServingC
 		if c == nil || pgInformer == nil {
 			return
 		}
-		_, _ = pgInformer.AddEventHandler(cache.FilteringResourceEventHandler{
+		_, _ = pgInformer.AddEventHandler(c.activeHandler(cache.FilteringResourceEventHandler{
 			FilterFunc: func(obj interface{}) bool {
 				metaObj := getMetaObject(obj)
 				if metaObj == nil {
@@ -173,13 +188,13 @@ func NewModelServingController(kubeClientSet kubernetes.Interface, modelServingC
 					c.deletePodGroup(obj)
 				},
 			},
-		})
+		}))
 	}
 
 	c.podGroupManager = podgroupmanager.NewManager(kubeClientSet, volcanoClient, apiextClient, registerPodGroupHandler)
 
 	klog.Info("Set the ModelServing event handler")
-	_, _ = c.modelServingsInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+	_, _ = c.modelServingsInformer.AddEventHandler(c.activeHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
 			c.addModelServing(obj)
 		},
@@ -189,9 +204,9 @@ func NewModelServingController(kubeClientSet kubernetes.Interface, modelServingC
 		DeleteFunc: func(obj interface{}) {
 			c.deleteModelServing(obj)
 		},
-	})
+	}))
 
-	_, _ = c.podsInformer.AddEventHandler(cache.FilteringResourceEventHandler{
+	_, _ = c.podsInformer.AddEventHandler(c.activeHandler(cache.FilteringResourceEventHandler{
 		FilterFunc: func(obj interface{}) bool {
 			metaObj := getMetaObject(obj)
 			if metaObj == nil {
@@ -210,9 +225,9 @@ func NewModelServingController(kubeClientSet kubernetes.Interface, modelServingC
 				c.deletePod(obj)
 			},
 		},
-	})
+	}))
 
-	_, _ = c.servicesInformer.AddEventHandler(cache.FilteringResourceEventHandler{
+	_, _ = c.servicesInformer.AddEventHandler(c.activeHandler(cache.FilteringResourceEventHandler{
 		FilterFunc: func(obj interface{}) bool {
 			metaObj := getMetaObject(obj)
 			if metaObj == nil {
@@ -225,13 +240,23 @@ func NewModelServingController(kubeClientSet kubernetes.Interface, modelServingC
 				c.deleteService(obj)
 			},
 		},
-	})
+	}))
 
 	c.syncHandler = c.syncModelServing
 
 	return c, nil
 }
 
+// activeHandler drops the events received while the controller is on standby.
+func (c *ModelServingController) activeHandler(handler cache.ResourceEventHandler) cache.ResourceEventHandler {
+	return cache.FilteringResourceEventHandler{
+		FilterFunc: func(interface{}) bool {
+			return !c.standby.Load()
+		},
+		Handler: handler,
+	}
+}
+
 func (c *ModelServingController) addModelServing(obj interface{}) {
 	ms, ok := obj.(*workloadv1alpha1.ModelServing)
 	if !ok {
@@ -528,6 +553,16 @@ func (c *ModelServingController) syncModelServing(ctx context.Context, key strin
 	// and only modifying the role.replicas field will not affect the revision.
 	copy := utils.RemoveRoleReplicasForRevision(ms)
 	revision := utils.Revision(copy.Spec.Template.Roles)
+	placed := ms.Spec.Placement != nil && ms.Spec.Placement.Policy == workloadv1alpha1.PlacementPolicyLowestCost
+	if ms.Spec.Accelerator != nil || placed {
+		// The accelerator requirements and the LowestCost placement are expanded into the pods of the roles, so
+		// changing them rolls the pods.
+		inputs := []interface{}{copy.Spec.Template.Roles, ms.Spec.Accelerator}
+		if placed {
+			inputs = append(inputs, ms.Spec.Placement)
+		}
+		revision = utils.Revision(inputs)
+	}
 	if err := c.manageServingGroupReplicas(ctx, ms, revision); err != nil {
 		return fmt.Errorf("cannot manage ServingGroup replicas: %v", err)
 	}
@@ -544,6 +579,10 @@ func (c *ModelServingController) syncModelServing(ctx context.Context, key strin
 		return fmt.Errorf("cannot manage ModelServing: %v", err)
 	}
 
+	if err := c.manageDisruptionBudgets(ctx, ms); err != nil {
+		return fmt.Errorf("cannot manage PodDisruptionBudgets: %v", err)
+	}
+
 	if err := c.UpdateModelServingStatus(ms, revision); err != nil {
 		return fmt.Errorf("failed to update status of ms %s/%s: %v", namespace, name, err)
 	}
@@ -555,20 +594,8 @@ func (c *ModelServingController) Run(ctx context.Context, workers int) {
 	defer utilruntime.HandleCrash()
 	defer c.workqueue.ShutDown()
 
-	// start informers
-	go c.podsInformer.RunWithContext(ctx)
-	go c.servicesInformer.RunWithContext(ctx)
-	go c.modelServingsInformer.RunWithContext(ctx)
-
-	if err := c.podGroupManager.Run(ctx); err != nil {
-		klog.Errorf("failed to start PodGroup informer: %v", err)
-	}
-
-	cache.WaitForCacheSync(ctx.Done(),
-		c.podsInformer.HasSynced,
-		c.servicesInformer.HasSynced,
-		c.modelServingsInformer.HasSynced,
-	)
+	c.startInformers(ctx, false)
+	c.standby.Store(false)
 
 	// sync pods first
 	c.syncAll()
@@ -582,6 +609,47 @@ func (c *ModelServingController) Run(ctx context.Context, workers int) {
 	klog.Info("shut down modelServing controller")
 }
 
+// WarmUp starts the informers and waits for their caches to sync, without reconciling.
+// The events received until Run are ignored, Run syncs everything from the warm caches.
+func (c *ModelServingController) WarmUp(ctx context.Context) {
+	c.startInformers(ctx, true)
+}
+
+// startInformers starts the informers once, ctx of the first call stops them, and waits for their caches to sync.
+func (c *ModelServingController) startInformers(ctx context.Context, standby bool) {
+	c.informersOnce.Do(func() {
+		c.standby.Store(standby)
+
+		// start informers
+		go c.podsInformer.RunWithContext(ctx)
+		go c.servicesInformer.RunWithContext(ctx)
+		go c.modelServingsInformer.RunWithContext(ctx)
+		if c.pdbsInformer != nil {
+			go c.pdbsInformer.RunWithContext(ctx)
+		}
+		if c.nodesInformer != nil {
+			go c.nodesInformer.RunWithContext(ctx)
+		}
+
+		if err := c.podGroupManager.Run(ctx); err != nil {
+			klog.Errorf("failed to start PodGroup informer: %v", err)
+		}
+	})
+
+	cacheSyncs := []cache.InformerSynced{
+		c.podsInformer.HasSynced,
+		c.servicesInformer.HasSynced,
+		c.modelServingsInformer.HasSynced,
+	}
+	if c.pdbsInformer != nil {
+		cacheSyncs = append(cacheSyncs, c.pdbsInformer.HasSynced)
+	}
+	if c.nodesInformer != nil {
+		cacheSyncs = append(cacheSyncs, c.nodesInformer.HasSynced)
+	}
+	cache.WaitForCacheSync(ctx.Done(), cacheSyncs...)
+}
+
 func (c *ModelServingController) syncAll() {
 	pods, err := c.podsLister.List(labels.Everything())
 	if err != nil {
@@ -1713,6 +1781,11 @@ func (c *ModelServingController) UpdateModelServingStatus(ms *workloadv1alpha1.M
 			}
 		}
 
+		if cost := c.projectHourlyCost(latestMS); !equalQuantities(copy.Status.ProjectedHourlyCost, cost) {
+			shouldUpdate = true
+			copy.Status.ProjectedHourlyCost = cost
+		}
+
 		if copy.Status.ObservedGeneration != latestMS.Generation {
 			shouldUpdate = true
 			copy.Status.ObservedGeneration = latestMS.Generation
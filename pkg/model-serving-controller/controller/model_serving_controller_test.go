@@ -46,6 +46,7 @@ import (
 	kthenafake "github.com/volcano-sh/kthena/client-go/clientset/versioned/fake"
 	informersv1alpha1 "github.com/volcano-sh/kthena/client-go/informers/externalversions"
 	workloadv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/controller/options"
 	"github.com/volcano-sh/kthena/pkg/model-serving-controller/datastore"
 	"github.com/volcano-sh/kthena/pkg/model-serving-controller/utils"
 )
@@ -1066,7 +1067,7 @@ func TestModelServingControllerModelServingLifecycle(t *testing.T) {
 	kthenaInformerFactory := informersv1alpha1.NewSharedInformerFactory(kthenaClient, 0)
 
 	// Create controller
-	controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake)
+	controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake, options.InformerOptions{})
 	assert.NoError(t, err)
 
 	stop := make(chan struct{})
@@ -1984,7 +1985,7 @@ func TestScaleUpServingGroups(t *testing.T) {
 			apiextfake := apiextfake.NewSimpleClientset()
 
 			// Create controller without running it to avoid background sync interference
-			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake)
+			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake, options.InformerOptions{})
 			assert.NoError(t, err)
 
 			// Create a unique ModelServing for this test
@@ -2136,7 +2137,7 @@ func TestScaleUpRoles(t *testing.T) {
 			apiextfake := apiextfake.NewSimpleClientset()
 
 			// Create controller without running it to avoid background sync interference
-			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake)
+			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake, options.InformerOptions{})
 			assert.NoError(t, err)
 
 			// Create a unique ModelServing for this test
@@ -2285,7 +2286,7 @@ func TestManageRoleReplicas(t *testing.T) {
 			volcanoClient := volcanofake.NewSimpleClientset()
 			apiextClient := apiextfake.NewSimpleClientset(testhelper.CreatePodGroupCRD())
 
-			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextClient)
+			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextClient, options.InformerOptions{})
 			assert.NoError(t, err)
 
 			roleName := "default"
@@ -2422,7 +2423,7 @@ func TestScaleDownServingGroups(t *testing.T) {
 			volcanoClient := volcanofake.NewSimpleClientset()
 			apiextfake := apiextfake.NewSimpleClientset()
 
-			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake)
+			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake, options.InformerOptions{})
 			assert.NoError(t, err)
 
 			msName := fmt.Sprintf("test-scaledown-%d", idx)
@@ -2636,7 +2637,7 @@ func TestScaleDownServingGroupsWithPriorityAndDeletionCost(t *testing.T) {
 			kthenaClient := kthenafake.NewSimpleClientset()
 			volcanoClient := volcanofake.NewSimpleClientset()
 
-			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake.NewSimpleClientset())
+			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake.NewSimpleClientset(), options.InformerOptions{})
 			assert.NoError(t, err)
 
 			msName := fmt.Sprintf("test-priority-scaledown-%d", idx)
@@ -2883,7 +2884,7 @@ func TestScaleDownServingGroupsWithPartition(t *testing.T) {
 			kthenaClient := kthenafake.NewSimpleClientset()
 			volcanoClient := volcanofake.NewSimpleClientset()
 
-			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake.NewSimpleClientset())
+			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake.NewSimpleClientset(), options.InformerOptions{})
 			assert.NoError(t, err)
 
 			msName := fmt.Sprintf("test-partition-scaledown-%d", idx)
@@ -3124,7 +3125,7 @@ func TestModelServingVersionControl(t *testing.T) {
 			volcanoClient := volcanofake.NewSimpleClientset()
 			apiextfake := apiextfake.NewSimpleClientset()
 
-			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake)
+			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake, options.InformerOptions{})
 			assert.NoError(t, err)
 
 			msName := fmt.Sprintf("test-version-control-%d", idx)
@@ -3304,7 +3305,7 @@ func TestScaleUpServingGroups_TemplateRecovery(t *testing.T) {
 			volcanoClient := volcanofake.NewSimpleClientset()
 			apiextfake := apiextfake.NewSimpleClientset()
 
-			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake)
+			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake, options.InformerOptions{})
 			assert.NoError(t, err)
 
 			// Use short name to avoid Kubernetes label length limits
@@ -3512,7 +3513,7 @@ func TestUpdateModelServingStatusRevisionFields(t *testing.T) {
 			volcanoClient := volcanofake.NewSimpleClientset()
 			apiextfake := apiextfake.NewSimpleClientset()
 
-			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake)
+			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake, options.InformerOptions{})
 			assert.NoError(t, err)
 
 			msName := fmt.Sprintf("test-revision-fields-%d", idx)
@@ -3628,7 +3629,7 @@ func TestScaleDownRoles(t *testing.T) {
 			volcanoClient := volcanofake.NewSimpleClientset()
 			apiextfake := apiextfake.NewSimpleClientset()
 
-			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake)
+			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake, options.InformerOptions{})
 			assert.NoError(t, err)
 
 			msName := fmt.Sprintf("test-role-scaledown-%d", idx)
@@ -3868,7 +3869,7 @@ func TestScaleDownRolesWithPriorityAndDeletionCost(t *testing.T) {
 			kthenaClient := kthenafake.NewSimpleClientset()
 			volcanoClient := volcanofake.NewSimpleClientset()
 
-			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake.NewSimpleClientset())
+			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake.NewSimpleClientset(), options.InformerOptions{})
 			assert.NoError(t, err)
 
 			msName := fmt.Sprintf("test-role-priority-scaledown-%d", idx)
@@ -4002,7 +4003,7 @@ func TestCalculateRoleScore(t *testing.T) {
 	kthenaClient := kthenafake.NewSimpleClientset()
 	volcanoClient := volcanofake.NewSimpleClientset()
 
-	controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake.NewSimpleClientset())
+	controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake.NewSimpleClientset(), options.InformerOptions{})
 	assert.NoError(t, err)
 
 	ms := &workloadv1alpha1.ModelServing{
@@ -4219,7 +4220,7 @@ func TestScaleDownRolesRunningStatusDeprioritized(t *testing.T) {
 			volcanoClient := volcanofake.NewSimpleClientset()
 			apiextClient := apiextfake.NewSimpleClientset(testhelper.CreatePodGroupCRD())
 
-			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextClient)
+			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextClient, options.InformerOptions{})
 			assert.NoError(t, err)
 
 			// Create ModelServing
@@ -4334,7 +4335,7 @@ func TestCalculateServingGroupScore(t *testing.T) {
 	kthenaClient := kthenafake.NewSimpleClientset()
 	volcanoClient := volcanofake.NewSimpleClientset()
 
-	controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake.NewSimpleClientset())
+	controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake.NewSimpleClientset(), options.InformerOptions{})
 	assert.NoError(t, err)
 
 	ms := &workloadv1alpha1.ModelServing{
@@ -4568,7 +4569,7 @@ func TestCheckRoleReady(t *testing.T) {
 			kthenaClient := kthenafake.NewSimpleClientset()
 			volcanoClient := volcanofake.NewSimpleClientset()
 
-			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake.NewSimpleClientset())
+			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake.NewSimpleClientset(), options.InformerOptions{})
 			assert.NoError(t, err)
 
 			groupName := utils.GenerateServingGroupName(ms.Name, 0)
@@ -4830,7 +4831,7 @@ func TestManageHeadlessService(t *testing.T) {
 			apiextClient := apiextfake.NewSimpleClientset()
 
 			// Create controller
-			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextClient)
+			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextClient, options.InformerOptions{})
 			assert.NoError(t, err)
 
 			// Setup the datastore with serving groups and roles
@@ -4890,7 +4891,7 @@ func TestSyncAllWithFailedPods(t *testing.T) {
 	apiextClient := apiextfake.NewSimpleClientset()
 
 	// Create controller first to get access to informers
-	controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextClient)
+	controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextClient, options.InformerOptions{})
 	assert.NoError(t, err)
 
 	// Create the ModelServing resource with a UID for owner reference
@@ -4985,7 +4986,7 @@ func TestSyncAllWithContainerRestartedPods(t *testing.T) {
 	apiextClient := apiextfake.NewSimpleClientset()
 
 	// Create controller first to get access to informers
-	controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextClient)
+	controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextClient, options.InformerOptions{})
 	assert.NoError(t, err)
 
 	// Create the ModelServing resource
@@ -5078,7 +5079,7 @@ func TestSyncAllWithMixedPods(t *testing.T) {
 	apiextClient := apiextfake.NewSimpleClientset()
 
 	// Create controller first to get access to informers
-	controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextClient)
+	controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextClient, options.InformerOptions{})
 	assert.NoError(t, err)
 
 	// Create the ModelServing resource
@@ -5264,7 +5265,7 @@ func TestSyncAllBeforeFixBehavior(t *testing.T) {
 	apiextClient := apiextfake.NewSimpleClientset()
 
 	// Create controller first to get access to informers
-	controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextClient)
+	controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextClient, options.InformerOptions{})
 	assert.NoError(t, err)
 
 	ms := &workloadv1alpha1.ModelServing{
@@ -5475,7 +5476,7 @@ func TestUpdateModelServingWithNilGangPolicy(t *testing.T) {
 			volcanoClient := volcanofake.NewSimpleClientset()
 			apiextClient := apiextfake.NewSimpleClientset()
 
-			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextClient)
+			controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextClient, options.InformerOptions{})
 			assert.NoError(t, err)
 
 			// This should not panic - before fix it would panic with nil pointer dereference
@@ -5546,7 +5547,7 @@ func TestDeleteRoleRollbackOnFailure(t *testing.T) {
 			kthenaInformerFactory := informersv1alpha1.NewSharedInformerFactory(kthenaClient, 0)
 
 			// Create controller
-			controller, err := NewModelServingController(client, kthenaClient, volcanoClient, apiextfake)
+			controller, err := NewModelServingController(client, kthenaClient, volcanoClient, apiextfake, options.InformerOptions{})
 			assert.NoError(t, err)
 
 			stop := make(chan struct{})
@@ -6038,7 +6039,7 @@ func TestDeleteServingGroupRollbackOnFailure(t *testing.T) {
 			kthenaInformerFactory := informersv1alpha1.NewSharedInformerFactory(kthenaClient, 0)
 
 			// Create controller
-			controller, err := NewModelServingController(client, kthenaClient, volcanoClient, apiextfake)
+			controller, err := NewModelServingController(client, kthenaClient, volcanoClient, apiextfake, options.InformerOptions{})
 			assert.NoError(t, err)
 
 			stop := make(chan struct{})
@@ -6204,7 +6205,7 @@ func TestDeleteOutdatedServingGroups(t *testing.T) {
 			modelServingClient := kthenafake.NewSimpleClientset()
 			apiextensionsClient := apiextfake.NewSimpleClientset()
 
-			controller, err := NewModelServingController(kubeClient, modelServingClient, nil, apiextensionsClient)
+			controller, err := NewModelServingController(kubeClient, modelServingClient, nil, apiextensionsClient, options.InformerOptions{})
 			assert.NoError(t, err)
 
 			ms := &workloadv1alpha1.ModelServing{
@@ -6242,3 +6243,31 @@ func TestDeleteOutdatedServingGroups(t *testing.T) {
 		})
 	}
 }
+
+func TestModelServingController_WarmUp(t *testing.T) {
+	ms := &workloadv1alpha1.ModelServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "warm"},
+		Spec:       workloadv1alpha1.ModelServingSpec{Replicas: ptr.To[int32](1)},
+	}
+	kubeClient := kubefake.NewSimpleClientset()
+	kthenaClient := kthenafake.NewSimpleClientset(ms)
+	volcanoClient := volcanofake.NewSimpleClientset()
+
+	controller, err := NewModelServingController(kubeClient, kthenaClient, volcanoClient, apiextfake.NewSimpleClientset(), options.InformerOptions{})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	controller.WarmUp(ctx)
+
+	// The cache is warm but events are ignored on standby
+	_, err = controller.modelServingLister.ModelServings("default").Get("warm")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, controller.workqueue.Len())
+
+	// Taking over syncs everything from the cache
+	controller.startInformers(ctx, false)
+	controller.standby.Store(false)
+	controller.syncAll()
+	assert.Equal(t, 1, controller.workqueue.Len())
+}
@@ -0,0 +1,71 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/klog/v2"
+
+	workloadv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/controller/options"
+	"github.com/volcano-sh/kthena/pkg/model-serving-controller/utils"
+)
+
+// EnablePlacementCost makes the controller project the hourly cost of the ModelServings with a placement in their
+// status, from the pools of the nodes of their pods. It must be called before Run, the controller manager only calls
+// it when it is granted the permissions to read the nodes.
+func (c *ModelServingController) EnablePlacementCost(opts options.InformerOptions) {
+	// The nodes are cluster scoped, they are watched whatever the namespace of the ModelServings.
+	factory := informers.NewSharedInformerFactory(c.kubeClientSet, opts.ResyncPeriod)
+	nodesInformer := factory.Core().V1().Nodes()
+	c.nodesLister = nodesInformer.Lister()
+	c.nodesInformer = nodesInformer.Informer()
+}
+
+// projectHourlyCost returns the hourly cost of the pods of a ModelServing priced by the pools of its placement, nil
+// without a placement or when the nodes can't be read.
+func (c *ModelServingController) projectHourlyCost(ms *workloadv1alpha1.ModelServing) *resource.Quantity {
+	if ms.Spec.Placement == nil || c.nodesLister == nil {
+		return nil
+	}
+	pods, err := c.podsLister.Pods(ms.Namespace).List(labels.SelectorFromSet(map[string]string{
+		workloadv1alpha1.ModelServingNameLabelKey: ms.Name,
+	}))
+	if err != nil {
+		klog.Errorf("cannot list the pods of ModelServing %s/%s: %v", ms.Namespace, ms.Name, err)
+		return nil
+	}
+	cost := utils.ProjectHourlyCost(ms.Spec.Placement, pods, func(nodeName string) (map[string]string, bool) {
+		node, err := c.nodesLister.Get(nodeName)
+		if err != nil {
+			klog.V(4).Infof("cannot get node %s of the pods of ModelServing %s/%s: %v", nodeName, ms.Namespace, ms.Name, err)
+			return nil, false
+		}
+		return node.Labels, true
+	})
+	return &cost
+}
+
+// equalQuantities reports whether two optional quantities are equal.
+func equalQuantities(a, b *resource.Quantity) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Cmp(*b) == 0
+}
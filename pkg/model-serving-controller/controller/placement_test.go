@@ -0,0 +1,81 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	workloadv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+)
+
+func TestProjectHourlyCost(t *testing.T) {
+	factory := informers.NewSharedInformerFactory(kubefake.NewSimpleClientset(), 0)
+	podsInformer := factory.Core().V1().Pods()
+	nodesInformer := factory.Core().V1().Nodes()
+	controller := &ModelServingController{podsLister: podsInformer.Lister()}
+
+	ms := &workloadv1alpha1.ModelServing{
+		ObjectMeta: metav1.ObjectMeta{Name: "llm", Namespace: "default"},
+		Spec: workloadv1alpha1.ModelServingSpec{
+			Placement: &workloadv1alpha1.Placement{
+				Pools: []workloadv1alpha1.NodePool{
+					{Name: "spot", NodeSelector: map[string]string{"capacity-type": "spot"}, HourlyCost: resource.MustParse("9.6")},
+					{Name: "on-demand", NodeSelector: map[string]string{"capacity-type": "on-demand"}, HourlyCost: resource.MustParse("32")},
+				},
+			},
+		},
+	}
+	for _, node := range []*corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "spot-1", Labels: map[string]string{"capacity-type": "spot"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "on-demand-1", Labels: map[string]string{"capacity-type": "on-demand"}}},
+	} {
+		require.NoError(t, nodesInformer.Informer().GetStore().Add(node))
+	}
+	for _, pod := range []*corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "llm-0", Namespace: "default", Labels: map[string]string{workloadv1alpha1.ModelServingNameLabelKey: "llm"}}, Spec: corev1.PodSpec{NodeName: "spot-1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "llm-1", Namespace: "default", Labels: map[string]string{workloadv1alpha1.ModelServingNameLabelKey: "llm"}}, Spec: corev1.PodSpec{NodeName: "on-demand-1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "other-0", Namespace: "default", Labels: map[string]string{workloadv1alpha1.ModelServingNameLabelKey: "other"}}, Spec: corev1.PodSpec{NodeName: "on-demand-1"}},
+	} {
+		require.NoError(t, podsInformer.Informer().GetStore().Add(pod))
+	}
+
+	// The cost is not projected while the nodes can't be read.
+	assert.Nil(t, controller.projectHourlyCost(ms))
+
+	controller.nodesLister = nodesInformer.Lister()
+	cost := controller.projectHourlyCost(ms)
+	require.NotNil(t, cost)
+	assert.Equal(t, 0, cost.Cmp(resource.MustParse("41.6")))
+	assert.True(t, equalQuantities(cost, ptrQuantity("41600m")))
+	assert.False(t, equalQuantities(cost, nil))
+
+	ms.Spec.Placement = nil
+	assert.Nil(t, controller.projectHourlyCost(ms))
+}
+
+func ptrQuantity(value string) *resource.Quantity {
+	quantity := resource.MustParse(value)
+	return &quantity
+}
@@ -345,11 +345,15 @@ func (m *Manager) calculateRequirements(ms *workloadv1alpha1.ModelServing) (int,
 			minRoleMember[role.Name] = int32(podsPerRole)
 		}
 
-		// Aggregate resources
-		minResources = m.aggregateResources(minResources, &role.EntryTemplate.Spec, minRoleReplicas)
+		// Aggregate resources, including the accelerators the pods of the role are given
+		entrySpec := role.EntryTemplate.Spec
+		utils.ApplyAccelerator(&entrySpec, ms, role.Name)
+		minResources = m.aggregateResources(minResources, &entrySpec, minRoleReplicas)
 		if role.WorkerTemplate != nil {
+			workerSpec := role.WorkerTemplate.Spec
+			utils.ApplyAccelerator(&workerSpec, ms, role.Name)
 			for i := 0; i < int(role.WorkerReplicas); i++ {
-				minResources = m.aggregateResources(minResources, &role.WorkerTemplate.Spec, minRoleReplicas)
+				minResources = m.aggregateResources(minResources, &workerSpec, minRoleReplicas)
 			}
 		}
 	}
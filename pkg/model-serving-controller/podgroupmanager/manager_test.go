@@ -251,6 +251,26 @@ func TestCalculateRequirements(t *testing.T) {
 		}
 		assert.Equal(t, expectedRoleMembers, minRoleMember)
 	})
+
+	t.Run("accelerator requirements", func(t *testing.T) {
+		apiextfake := apiextfake.NewSimpleClientset(testhelper.CreatePodGroupCRD())
+		volcanofake := volcanofake.NewSimpleClientset()
+		manager := NewManager(nil, volcanofake, apiextfake, nil)
+		ms := createBasicModelServing()
+		ms.Spec.Accelerator = &workloadv1alpha1.AcceleratorRequirements{
+			Count: 8,
+			Roles: []string{"prefill"},
+		}
+
+		_, _, minResources := manager.calculateRequirements(ms)
+
+		// Prefill roles: 2*8 + 2*3*8 = 64 accelerators, the decode role has none
+		expectedGPU := resource.MustParse("64")
+		assert.True(t, expectedGPU.Equal(minResources["nvidia.com/gpu"]),
+			"Expected GPU %v, got %v", expectedGPU, minResources["nvidia.com/gpu"])
+		// The role templates are not modified
+		assert.NotContains(t, ms.Spec.Template.Roles[0].EntryTemplate.Spec.Containers[0].Resources.Requests, corev1.ResourceName("nvidia.com/gpu"))
+	})
 }
 
 func TestAggregateResources(t *testing.T) {
@@ -0,0 +1,141 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"slices"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	workloadv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+)
+
+const (
+	defaultAcceleratorResourceName   corev1.ResourceName = "nvidia.com/gpu"
+	defaultAcceleratorTypeLabelKey                       = "nvidia.com/gpu.product"
+	defaultAcceleratorMemoryLabelKey                     = "nvidia.com/gpu.memory"
+)
+
+// ApplyAccelerator expands the accelerator requirements of a ModelServing into the spec of a pod of a role: the first
+// container requests the accelerators, the pod tolerates their NoSchedule taints and selects the nodes of their type
+// and memory. The values already set by the role template are kept.
+// The spec may be shared with the role template, so the fields changed are copied first.
+func ApplyAccelerator(spec *corev1.PodSpec, ms *workloadv1alpha1.ModelServing, roleName string) {
+	accelerator := ms.Spec.Accelerator
+	if accelerator == nil || (len(accelerator.Roles) > 0 && !slices.Contains(accelerator.Roles, roleName)) {
+		return
+	}
+	resourceName := accelerator.ResourceName
+	if resourceName == "" {
+		resourceName = defaultAcceleratorResourceName
+	}
+
+	if len(spec.Containers) > 0 {
+		spec.Containers = slices.Clone(spec.Containers)
+		resources := spec.Containers[0].Resources.DeepCopy()
+		if _, ok := resources.Limits[resourceName]; !ok {
+			if resources.Limits == nil {
+				resources.Limits = corev1.ResourceList{}
+			}
+			limit := *resource.NewQuantity(int64(accelerator.Count), resource.DecimalSI)
+			if request, ok := resources.Requests[resourceName]; ok {
+				limit = request
+			}
+			resources.Limits[resourceName] = limit
+		}
+		// Extended resources can't be overcommitted, so their requests must equal their limits. They are set anyway
+		// for the minimum resources of the PodGroups to count them.
+		if _, ok := resources.Requests[resourceName]; !ok {
+			if resources.Requests == nil {
+				resources.Requests = corev1.ResourceList{}
+			}
+			resources.Requests[resourceName] = resources.Limits[resourceName]
+		}
+		spec.Containers[0].Resources = *resources
+	}
+
+	if !slices.ContainsFunc(spec.Tolerations, func(toleration corev1.Toleration) bool {
+		return toleration.Key == string(resourceName)
+	}) {
+		spec.Tolerations = append(slices.Clone(spec.Tolerations), corev1.Toleration{
+			Key:      string(resourceName),
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+		})
+	}
+
+	if accelerator.Type != "" {
+		typeLabelKey := accelerator.TypeLabelKey
+		if typeLabelKey == "" {
+			typeLabelKey = defaultAcceleratorTypeLabelKey
+		}
+		if _, ok := spec.NodeSelector[typeLabelKey]; !ok {
+			nodeSelector := make(map[string]string, len(spec.NodeSelector)+1)
+			for k, v := range spec.NodeSelector {
+				nodeSelector[k] = v
+			}
+			nodeSelector[typeLabelKey] = accelerator.Type
+			spec.NodeSelector = nodeSelector
+		}
+	}
+
+	if accelerator.Memory != nil {
+		memoryLabelKey := accelerator.MemoryLabelKey
+		if memoryLabelKey == "" {
+			memoryLabelKey = defaultAcceleratorMemoryLabelKey
+		}
+		applyAcceleratorMemory(spec, memoryLabelKey, accelerator.Memory)
+	}
+}
+
+// applyAcceleratorMemory requires the nodes of a pod spec to have a memory label of at least memory in MiB, unless the
+// node affinity of the pod already constrains the label. The requirement is added to each term of the node affinity,
+// as the terms are ORed.
+func applyAcceleratorMemory(spec *corev1.PodSpec, memoryLabelKey string, memory *resource.Quantity) {
+	// The Gt operator is exclusive, the label of the nodes with exactly memory must match too.
+	mebibytes := (memory.Value() + (1 << 20) - 1) >> 20
+	requirement := corev1.NodeSelectorRequirement{
+		Key:      memoryLabelKey,
+		Operator: corev1.NodeSelectorOpGt,
+		Values:   []string{strconv.FormatInt(mebibytes-1, 10)},
+	}
+
+	affinity := spec.Affinity.DeepCopy()
+	if affinity == nil {
+		affinity = &corev1.Affinity{}
+	}
+	if affinity.NodeAffinity == nil {
+		affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	nodeSelector := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if nodeSelector == nil || len(nodeSelector.NodeSelectorTerms) == 0 {
+		nodeSelector = &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{}}}
+	}
+	for i := range nodeSelector.NodeSelectorTerms {
+		term := &nodeSelector.NodeSelectorTerms[i]
+		if slices.ContainsFunc(term.MatchExpressions, func(expression corev1.NodeSelectorRequirement) bool {
+			return expression.Key == memoryLabelKey
+		}) {
+			continue
+		}
+		term.MatchExpressions = append(term.MatchExpressions, requirement)
+	}
+	affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = nodeSelector
+	spec.Affinity = affinity
+}
@@ -0,0 +1,104 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	workloadv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+)
+
+func TestGeneratePodsWithAccelerator(t *testing.T) {
+	memory := resource.MustParse("80Gi")
+	prefill := workloadv1alpha1.Role{
+		Name: "prefill",
+		EntryTemplate: workloadv1alpha1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "engine"}, {Name: "sidecar"}},
+			},
+		},
+		WorkerReplicas: 1,
+		WorkerTemplate: &workloadv1alpha1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers:   []corev1.Container{{Name: "engine"}},
+				NodeSelector: map[string]string{"nvidia.com/gpu.product": "NVIDIA-A100-SXM4-80GB"},
+				Affinity: &corev1.Affinity{
+					NodeAffinity: &corev1.NodeAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+							NodeSelectorTerms: []corev1.NodeSelectorTerm{
+								{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}}},
+								{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"b"}}}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	router := workloadv1alpha1.Role{
+		Name: "router",
+		EntryTemplate: workloadv1alpha1.PodTemplateSpec{
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "router"}}},
+		},
+	}
+	ms := &workloadv1alpha1.ModelServing{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama", Namespace: "default"},
+		Spec: workloadv1alpha1.ModelServingSpec{
+			Template: workloadv1alpha1.ServingGroup{Roles: []workloadv1alpha1.Role{prefill, router}},
+			Accelerator: &workloadv1alpha1.AcceleratorRequirements{
+				Count:  8,
+				Type:   "NVIDIA-H100-80GB-HBM3",
+				Memory: &memory,
+				Roles:  []string{"prefill"},
+			},
+		},
+	}
+	toleration := corev1.Toleration{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}
+	memoryRequirement := corev1.NodeSelectorRequirement{Key: "nvidia.com/gpu.memory", Operator: corev1.NodeSelectorOpGt, Values: []string{"81919"}}
+
+	entryPod := GenerateEntryPod(prefill, ms, "llama-0", 0, "revision")
+	assert.Equal(t, "8", entryPod.Spec.Containers[0].Resources.Limits.Name("nvidia.com/gpu", resource.DecimalSI).String())
+	assert.Empty(t, entryPod.Spec.Containers[1].Resources.Limits)
+	assert.Equal(t, []corev1.Toleration{toleration}, entryPod.Spec.Tolerations)
+	assert.Equal(t, map[string]string{"nvidia.com/gpu.product": "NVIDIA-H100-80GB-HBM3"}, entryPod.Spec.NodeSelector)
+	assert.Equal(t, []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{memoryRequirement}}},
+		entryPod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms)
+
+	// The node selector of the role template takes precedence, and the memory requirement is added to each term.
+	workerPod := GenerateWorkerPod(prefill, ms, entryPod, "llama-0", 0, 1, "revision")
+	assert.Equal(t, "8", workerPod.Spec.Containers[0].Resources.Limits.Name("nvidia.com/gpu", resource.DecimalSI).String())
+	assert.Equal(t, map[string]string{"nvidia.com/gpu.product": "NVIDIA-A100-SXM4-80GB"}, workerPod.Spec.NodeSelector)
+	for _, term := range workerPod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		assert.Equal(t, memoryRequirement, term.MatchExpressions[1])
+	}
+
+	// The role templates are not modified.
+	assert.Empty(t, prefill.EntryTemplate.Spec.Containers[0].Resources.Limits)
+	assert.Empty(t, prefill.EntryTemplate.Spec.Tolerations)
+	assert.Len(t, prefill.WorkerTemplate.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions, 1)
+
+	// The roles not listed are left untouched.
+	routerPod := GenerateEntryPod(router, ms, "llama-0", 0, "revision")
+	assert.Empty(t, routerPod.Spec.Containers[0].Resources.Limits)
+	assert.Empty(t, routerPod.Spec.Tolerations)
+	assert.Nil(t, routerPod.Spec.Affinity)
+}
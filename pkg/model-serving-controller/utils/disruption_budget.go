@@ -0,0 +1,129 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	workloadv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+)
+
+// GenerateDisruptionBudgets generates the PodDisruptionBudgets of the disruption budget of a ModelServing, none if it
+// has no disruption budget.
+// Evicting any pod of a multi-node replica breaks the whole replica, and the evictions allowed by a budget may land on
+// any replica, so each budget allows as many pod disruptions as there are replicas which may be unavailable: with the
+// ServingGroupRecreate recovery policy, a single budget selects all the pods and counts the ServingGroups, otherwise a
+// budget per role selects the pods of the role and counts its replicas in all the ServingGroups.
+// The budgets set an absolute MinAvailable counted in pods, since the pods are owned by the ModelServing whose scale
+// is the number of ServingGroups, not the number of pods they select.
+func GenerateDisruptionBudgets(ms *workloadv1alpha1.ModelServing) ([]*policyv1.PodDisruptionBudget, error) {
+	budget := ms.Spec.DisruptionBudget
+	if budget == nil {
+		return nil, nil
+	}
+	minAvailable := intstr.FromInt32(1)
+	if budget.MinAvailable != nil {
+		minAvailable = *budget.MinAvailable
+	}
+	groups := 1
+	if ms.Spec.Replicas != nil {
+		groups = int(*ms.Spec.Replicas)
+	}
+
+	if ms.Spec.RecoveryPolicy == workloadv1alpha1.ServingGroupRecreate {
+		podsPerGroup := 0
+		for _, role := range ms.Spec.Template.Roles {
+			podsPerGroup += roleReplicas(role) * podsPerReplica(role)
+		}
+		pdb, err := generateDisruptionBudget(ms, "", minAvailable, groups, podsPerGroup)
+		if err != nil {
+			return nil, err
+		}
+		return []*policyv1.PodDisruptionBudget{pdb}, nil
+	}
+
+	pdbs := make([]*policyv1.PodDisruptionBudget, 0, len(ms.Spec.Template.Roles))
+	for _, role := range ms.Spec.Template.Roles {
+		roleMinAvailable := minAvailable
+		if value, ok := budget.MinRoleAvailable[role.Name]; ok {
+			roleMinAvailable = value
+		}
+		pdb, err := generateDisruptionBudget(ms, role.Name, roleMinAvailable, groups*roleReplicas(role), podsPerReplica(role))
+		if err != nil {
+			return nil, err
+		}
+		pdbs = append(pdbs, pdb)
+	}
+	return pdbs, nil
+}
+
+// roleReplicas returns the number of replicas of a role in a ServingGroup.
+func roleReplicas(role workloadv1alpha1.Role) int {
+	if role.Replicas == nil {
+		return 1
+	}
+	return int(*role.Replicas)
+}
+
+// podsPerReplica returns the number of pods of a replica of a role, its entry pod and its worker pods.
+func podsPerReplica(role workloadv1alpha1.Role) int {
+	return 1 + int(role.WorkerReplicas)
+}
+
+// generateDisruptionBudget generates the PodDisruptionBudget of the pods of a role, or of all the pods if roleName is
+// empty, which keeps minAvailable of their replicas of podsPerReplica pods available.
+func generateDisruptionBudget(ms *workloadv1alpha1.ModelServing, roleName string, minAvailable intstr.IntOrString, replicas, podsPerReplica int) (*policyv1.PodDisruptionBudget, error) {
+	available, err := intstr.GetScaledValueFromIntOrPercent(&minAvailable, replicas, true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid minAvailable %s: %v", minAvailable.String(), err)
+	}
+	name := ms.Name
+	selector := map[string]string{
+		workloadv1alpha1.ModelServingNameLabelKey: ms.Name,
+	}
+	if roleName != "" {
+		name = fmt.Sprintf("%s-%s", ms.Name, roleName)
+		selector[workloadv1alpha1.RoleLabelKey] = roleName
+	}
+	labels := make(map[string]string, len(selector))
+	for k, v := range selector {
+		labels[k] = v
+	}
+
+	// A pod may be evicted per replica which may be unavailable: evicting the pods of a replica rather than a pod of
+	// each replica would let the evictions break more replicas than that.
+	unavailable := max(replicas-available, 0)
+	minAvailablePods := intstr.FromInt(replicas*podsPerReplica - unavailable)
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ms.Namespace,
+			Labels:    labels,
+			OwnerReferences: []metav1.OwnerReference{
+				newModelServingOwnerRef(ms),
+			},
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailablePods,
+			Selector:     &metav1.LabelSelector{MatchLabels: selector},
+		},
+	}, nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"slices"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+
+	workloadv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+)
+
+const (
+	// maxPlacementWeight and minPlacementWeight bound the weights of the preferred node affinity terms of the pools.
+	maxPlacementWeight = 100
+	minPlacementWeight = 1
+)
+
+// ApplyPlacement makes the pod spec of a ModelServing with the LowestCost placement policy prefer the pools by
+// increasing cost: the cheapest pools get the highest weight and the most expensive ones the lowest. The preferred
+// terms of the role template are kept.
+// The spec may be shared with the role template, so the affinity is copied first.
+func ApplyPlacement(spec *corev1.PodSpec, ms *workloadv1alpha1.ModelServing) {
+	placement := ms.Spec.Placement
+	if placement == nil || placement.Policy != workloadv1alpha1.PlacementPolicyLowestCost || len(placement.Pools) == 0 {
+		return
+	}
+
+	costs := make([]resource.Quantity, 0, len(placement.Pools))
+	for _, pool := range placement.Pools {
+		if !slices.ContainsFunc(costs, func(cost resource.Quantity) bool { return cost.Cmp(pool.HourlyCost) == 0 }) {
+			costs = append(costs, pool.HourlyCost)
+		}
+	}
+	sort.Slice(costs, func(i, j int) bool { return costs[i].Cmp(costs[j]) < 0 })
+
+	affinity := spec.Affinity.DeepCopy()
+	if affinity == nil {
+		affinity = &corev1.Affinity{}
+	}
+	if affinity.NodeAffinity == nil {
+		affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	for _, pool := range placement.Pools {
+		rank := slices.IndexFunc(costs, func(cost resource.Quantity) bool { return cost.Cmp(pool.HourlyCost) == 0 })
+		affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+			affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+			corev1.PreferredSchedulingTerm{
+				Weight:     placementWeight(rank, len(costs)),
+				Preference: nodeSelectorTerm(pool.NodeSelector),
+			},
+		)
+	}
+	spec.Affinity = affinity
+}
+
+// placementWeight returns the weight of the pools of the given rank among count distinct costs, spread evenly from
+// maxPlacementWeight for the cheapest to minPlacementWeight for the most expensive.
+func placementWeight(rank, count int) int32 {
+	if count <= 1 {
+		return maxPlacementWeight
+	}
+	return int32(maxPlacementWeight - rank*(maxPlacementWeight-minPlacementWeight)/(count-1))
+}
+
+// nodeSelectorTerm returns the node selector term matching the labels of a node selector, sorted by key.
+func nodeSelectorTerm(nodeSelector map[string]string) corev1.NodeSelectorTerm {
+	keys := make([]string, 0, len(nodeSelector))
+	for key := range nodeSelector {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	term := corev1.NodeSelectorTerm{}
+	for _, key := range keys {
+		term.MatchExpressions = append(term.MatchExpressions, corev1.NodeSelectorRequirement{
+			Key:      key,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   []string{nodeSelector[key]},
+		})
+	}
+	return term
+}
+
+// NodePoolOf returns the first pool of the placement whose node selector matches the labels of a node, nil if none
+// does.
+func NodePoolOf(placement *workloadv1alpha1.Placement, nodeLabels map[string]string) *workloadv1alpha1.NodePool {
+	if placement == nil {
+		return nil
+	}
+	for i := range placement.Pools {
+		if labels.SelectorFromSet(placement.Pools[i].NodeSelector).Matches(labels.Set(nodeLabels)) {
+			return &placement.Pools[i]
+		}
+	}
+	return nil
+}
+
+// ProjectHourlyCost returns the hourly cost of the pods priced by the pools of their nodes, whose labels are returned
+// by nodeLabels. The pods not scheduled yet, terminated, or on the nodes of none of the pools are not priced.
+func ProjectHourlyCost(placement *workloadv1alpha1.Placement, pods []*corev1.Pod, nodeLabels func(nodeName string) (map[string]string, bool)) resource.Quantity {
+	total := resource.Quantity{Format: resource.DecimalSI}
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" || pod.DeletionTimestamp != nil ||
+			pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		labelSet, ok := nodeLabels(pod.Spec.NodeName)
+		if !ok {
+			continue
+		}
+		if pool := NodePoolOf(placement, labelSet); pool != nil {
+			total.Add(pool.HourlyCost)
+		}
+	}
+	return total
+}
@@ -0,0 +1,161 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	workloadv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+)
+
+func newPlacement(policy workloadv1alpha1.PlacementPolicy) *workloadv1alpha1.Placement {
+	return &workloadv1alpha1.Placement{
+		Policy: policy,
+		Pools: []workloadv1alpha1.NodePool{
+			{
+				Name:         "on-demand",
+				NodeSelector: map[string]string{"capacity-type": "on-demand"},
+				HourlyCost:   resource.MustParse("32"),
+			},
+			{
+				Name:         "spot-eu",
+				NodeSelector: map[string]string{"region": "eu", "capacity-type": "spot"},
+				HourlyCost:   resource.MustParse("9.6"),
+			},
+			{
+				Name:         "spot-us",
+				NodeSelector: map[string]string{"region": "us", "capacity-type": "spot"},
+				HourlyCost:   resource.MustParse("9600m"),
+			},
+			{
+				Name:         "reserved",
+				NodeSelector: map[string]string{"capacity-type": "reserved"},
+				HourlyCost:   resource.MustParse("20"),
+			},
+		},
+	}
+}
+
+func TestGeneratePodsWithPlacement(t *testing.T) {
+	role := workloadv1alpha1.Role{
+		Name: "decode",
+		EntryTemplate: workloadv1alpha1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "engine"}},
+				Affinity: &corev1.Affinity{
+					NodeAffinity: &corev1.NodeAffinity{
+						PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{
+							{Weight: 10, Preference: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}}}},
+						},
+					},
+				},
+			},
+		},
+		WorkerReplicas: 1,
+		WorkerTemplate: &workloadv1alpha1.PodTemplateSpec{
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "engine"}}},
+		},
+	}
+	ms := &workloadv1alpha1.ModelServing{
+		ObjectMeta: metav1.ObjectMeta{Name: "deepseek", Namespace: "default"},
+		Spec: workloadv1alpha1.ModelServingSpec{
+			Template:  workloadv1alpha1.ServingGroup{Roles: []workloadv1alpha1.Role{role}},
+			Placement: newPlacement(workloadv1alpha1.PlacementPolicyLowestCost),
+		},
+	}
+
+	poolTerms := []corev1.PreferredSchedulingTerm{
+		{Weight: 1, Preference: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+			{Key: "capacity-type", Operator: corev1.NodeSelectorOpIn, Values: []string{"on-demand"}},
+		}}},
+		{Weight: 100, Preference: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+			{Key: "capacity-type", Operator: corev1.NodeSelectorOpIn, Values: []string{"spot"}},
+			{Key: "region", Operator: corev1.NodeSelectorOpIn, Values: []string{"eu"}},
+		}}},
+		{Weight: 100, Preference: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+			{Key: "capacity-type", Operator: corev1.NodeSelectorOpIn, Values: []string{"spot"}},
+			{Key: "region", Operator: corev1.NodeSelectorOpIn, Values: []string{"us"}},
+		}}},
+		{Weight: 51, Preference: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+			{Key: "capacity-type", Operator: corev1.NodeSelectorOpIn, Values: []string{"reserved"}},
+		}}},
+	}
+
+	entryPod := GenerateEntryPod(role, ms, "deepseek-0", 0, "rev")
+	assert.Equal(t, append(role.EntryTemplate.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution, poolTerms...),
+		entryPod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution)
+	// The role template is not modified.
+	assert.Len(t, role.EntryTemplate.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution, 1)
+
+	workerPod := GenerateWorkerPod(role, ms, entryPod, "deepseek-0", 0, 1, "rev")
+	assert.Equal(t, poolTerms, workerPod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution)
+	assert.Nil(t, role.WorkerTemplate.Spec.Affinity)
+
+	// The pools only price the pods without the LowestCost policy.
+	ms.Spec.Placement.Policy = workloadv1alpha1.PlacementPolicyNone
+	workerPod = GenerateWorkerPod(role, ms, entryPod, "deepseek-0", 0, 1, "rev")
+	assert.Nil(t, workerPod.Spec.Affinity)
+}
+
+func TestPlacementWeight(t *testing.T) {
+	assert.Equal(t, int32(100), placementWeight(0, 1))
+	assert.Equal(t, int32(100), placementWeight(0, 2))
+	assert.Equal(t, int32(1), placementWeight(1, 2))
+	assert.Equal(t, int32(67), placementWeight(1, 4))
+	assert.Equal(t, int32(1), placementWeight(3, 4))
+}
+
+func TestProjectHourlyCost(t *testing.T) {
+	placement := newPlacement(workloadv1alpha1.PlacementPolicyNone)
+	nodes := map[string]map[string]string{
+		"node-spot":      {"region": "eu", "capacity-type": "spot", "zone": "a"},
+		"node-on-demand": {"capacity-type": "on-demand"},
+		"node-other":     {"capacity-type": "preemptible"},
+	}
+	nodeLabels := func(nodeName string) (map[string]string, bool) {
+		nodeLabels, ok := nodes[nodeName]
+		return nodeLabels, ok
+	}
+	pod := func(nodeName string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{Spec: corev1.PodSpec{NodeName: nodeName}, Status: corev1.PodStatus{Phase: phase}}
+	}
+
+	assert.Equal(t, "spot-eu", NodePoolOf(placement, nodes["node-spot"]).Name)
+	assert.Nil(t, NodePoolOf(placement, nodes["node-other"]))
+	assert.Nil(t, NodePoolOf(nil, nodes["node-spot"]))
+
+	cost := ProjectHourlyCost(placement, []*corev1.Pod{
+		pod("node-spot", corev1.PodRunning),
+		pod("node-spot", corev1.PodRunning),
+		pod("node-on-demand", corev1.PodPending),
+		// Neither priced.
+		pod("", corev1.PodPending),
+		pod("node-other", corev1.PodRunning),
+		pod("node-unknown", corev1.PodRunning),
+		pod("node-on-demand", corev1.PodSucceeded),
+		pod("node-on-demand", corev1.PodFailed),
+	}, nodeLabels)
+	assert.Equal(t, 0, cost.Cmp(resource.MustParse("51.2")))
+
+	cost = ProjectHourlyCost(placement, nil, nodeLabels)
+	assert.True(t, cost.IsZero())
+}
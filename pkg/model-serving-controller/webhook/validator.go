@@ -89,6 +89,9 @@ func (v *ModelServingValidator) validateModelServing(modelServing *workloadv1alp
 	allErrs = append(allErrs, validateRollingUpdateConfiguration(modelServing)...)
 	allErrs = append(allErrs, validateGangPolicy(modelServing)...)
 	allErrs = append(allErrs, validateWorkerReplicas(modelServing)...)
+	allErrs = append(allErrs, validateDisruptionBudget(modelServing)...)
+	allErrs = append(allErrs, validateAccelerator(modelServing)...)
+	allErrs = append(allErrs, validatePlacement(modelServing)...)
 
 	if len(allErrs) > 0 {
 		var messages []string
@@ -287,6 +290,101 @@ func validateWorkerReplicas(ms *workloadv1alpha1.ModelServing) field.ErrorList {
 	return allErrs
 }
 
+// validateDisruptionBudget validates the minimum available replicas of the disruption budget
+func validateDisruptionBudget(ms *workloadv1alpha1.ModelServing) field.ErrorList {
+	var allErrs field.ErrorList
+
+	budget := ms.Spec.DisruptionBudget
+	if budget == nil {
+		return allErrs
+	}
+	budgetPath := field.NewPath("spec").Child("disruptionBudget")
+	if budget.MinAvailable != nil {
+		allErrs = append(allErrs, validateIntOrPercent(budget.MinAvailable, budgetPath.Child("minAvailable"))...)
+	}
+
+	roleNames := make(map[string]bool)
+	for _, role := range ms.Spec.Template.Roles {
+		roleNames[role.Name] = true
+	}
+	for roleName, minAvailable := range budget.MinRoleAvailable {
+		if !roleNames[roleName] {
+			allErrs = append(allErrs, field.Invalid(
+				budgetPath.Child("minRoleAvailable").Key(roleName),
+				roleName,
+				fmt.Sprintf("role %s does not exist in template.roles", roleName),
+			))
+			continue
+		}
+		allErrs = append(allErrs, validateIntOrPercent(&minAvailable, budgetPath.Child("minRoleAvailable").Key(roleName))...)
+	}
+
+	return allErrs
+}
+
+// validateAccelerator validates the count, memory and roles of the accelerator requirements
+func validateAccelerator(ms *workloadv1alpha1.ModelServing) field.ErrorList {
+	var allErrs field.ErrorList
+
+	accelerator := ms.Spec.Accelerator
+	if accelerator == nil {
+		return allErrs
+	}
+	acceleratorPath := field.NewPath("spec").Child("accelerator")
+	if accelerator.Count < 1 {
+		allErrs = append(allErrs, field.Invalid(acceleratorPath.Child("count"), accelerator.Count, "must be at least 1"))
+	}
+	if accelerator.Memory != nil && accelerator.Memory.Sign() <= 0 {
+		allErrs = append(allErrs, field.Invalid(acceleratorPath.Child("memory"), accelerator.Memory.String(), "must be positive"))
+	}
+
+	roleNames := make(map[string]bool)
+	for _, role := range ms.Spec.Template.Roles {
+		roleNames[role.Name] = true
+	}
+	for i, roleName := range accelerator.Roles {
+		if !roleNames[roleName] {
+			allErrs = append(allErrs, field.Invalid(
+				acceleratorPath.Child("roles").Index(i),
+				roleName,
+				fmt.Sprintf("role %s does not exist in template.roles", roleName),
+			))
+		}
+	}
+
+	return allErrs
+}
+
+// validatePlacement validates the names, node selectors and costs of the node pools of the placement
+func validatePlacement(ms *workloadv1alpha1.ModelServing) field.ErrorList {
+	var allErrs field.ErrorList
+
+	placement := ms.Spec.Placement
+	if placement == nil {
+		return allErrs
+	}
+	poolsPath := field.NewPath("spec").Child("placement").Child("pools")
+	if len(placement.Pools) == 0 {
+		allErrs = append(allErrs, field.Required(poolsPath, "at least one node pool is required"))
+	}
+	names := make(map[string]bool, len(placement.Pools))
+	for i, pool := range placement.Pools {
+		poolPath := poolsPath.Index(i)
+		if names[pool.Name] {
+			allErrs = append(allErrs, field.Duplicate(poolPath.Child("name"), pool.Name))
+		}
+		names[pool.Name] = true
+		if len(pool.NodeSelector) == 0 {
+			allErrs = append(allErrs, field.Required(poolPath.Child("nodeSelector"), "the nodes of the pool must be selected"))
+		}
+		if pool.HourlyCost.Sign() < 0 {
+			allErrs = append(allErrs, field.Invalid(poolPath.Child("hourlyCost"), pool.HourlyCost.String(), "must not be negative"))
+		}
+	}
+
+	return allErrs
+}
+
 func validateIntOrPercent(value *intstr.IntOrString, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	switch value.Type {
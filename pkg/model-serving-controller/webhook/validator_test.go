@@ -21,6 +21,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	workloadv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -914,3 +915,188 @@ func int32Ptr(i int32) *int32 {
 func int32PtrNil() *int32 {
 	return nil
 }
+
+func TestValidateDisruptionBudget(t *testing.T) {
+	roles := []workloadv1alpha1.Role{{Name: "prefill"}, {Name: "decode"}}
+	percent := intstr.FromString("50%")
+	invalidPercent := intstr.FromString("150%")
+	negative := intstr.FromInt32(-1)
+	tests := []struct {
+		name   string
+		budget *workloadv1alpha1.DisruptionBudget
+		want   field.ErrorList
+	}{
+		{
+			name:   "no disruption budget",
+			budget: nil,
+			want:   field.ErrorList(nil),
+		},
+		{
+			name: "valid disruption budget",
+			budget: &workloadv1alpha1.DisruptionBudget{
+				MinAvailable:     &percent,
+				MinRoleAvailable: map[string]intstr.IntOrString{"decode": intstr.FromInt32(2)},
+			},
+			want: field.ErrorList(nil),
+		},
+		{
+			name: "invalid minAvailable",
+			budget: &workloadv1alpha1.DisruptionBudget{
+				MinAvailable: &invalidPercent,
+			},
+			want: field.ErrorList{
+				field.Invalid(field.NewPath("spec").Child("disruptionBudget").Child("minAvailable"), &invalidPercent,
+					"must be a valid percent value (0-100)"),
+			},
+		},
+		{
+			name: "invalid minRoleAvailable",
+			budget: &workloadv1alpha1.DisruptionBudget{
+				MinRoleAvailable: map[string]intstr.IntOrString{"decode": negative},
+			},
+			want: field.ErrorList{
+				field.Invalid(field.NewPath("spec").Child("disruptionBudget").Child("minRoleAvailable").Key("decode"), int64(-1),
+					"must be a non-negative integer"),
+			},
+		},
+		{
+			name: "minRoleAvailable of an unknown role",
+			budget: &workloadv1alpha1.DisruptionBudget{
+				MinRoleAvailable: map[string]intstr.IntOrString{"router": intstr.FromInt32(1)},
+			},
+			want: field.ErrorList{
+				field.Invalid(field.NewPath("spec").Child("disruptionBudget").Child("minRoleAvailable").Key("router"), "router",
+					"role router does not exist in template.roles"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := &workloadv1alpha1.ModelServing{
+				Spec: workloadv1alpha1.ModelServingSpec{
+					Template:         workloadv1alpha1.ServingGroup{Roles: roles},
+					DisruptionBudget: tt.budget,
+				},
+			}
+			assert.Equal(t, tt.want, validateDisruptionBudget(ms))
+		})
+	}
+}
+
+func TestValidateAccelerator(t *testing.T) {
+	roles := []workloadv1alpha1.Role{{Name: "prefill"}, {Name: "decode"}}
+	memory := resource.MustParse("80Gi")
+	zero := resource.MustParse("0")
+	acceleratorPath := field.NewPath("spec").Child("accelerator")
+	tests := []struct {
+		name        string
+		accelerator *workloadv1alpha1.AcceleratorRequirements
+		want        field.ErrorList
+	}{
+		{
+			name:        "no accelerator",
+			accelerator: nil,
+			want:        field.ErrorList(nil),
+		},
+		{
+			name: "valid accelerator",
+			accelerator: &workloadv1alpha1.AcceleratorRequirements{
+				Count:  8,
+				Type:   "NVIDIA-H100-80GB-HBM3",
+				Memory: &memory,
+				Roles:  []string{"decode"},
+			},
+			want: field.ErrorList(nil),
+		},
+		{
+			name: "invalid count and memory",
+			accelerator: &workloadv1alpha1.AcceleratorRequirements{
+				Memory: &zero,
+			},
+			want: field.ErrorList{
+				field.Invalid(acceleratorPath.Child("count"), int32(0), "must be at least 1"),
+				field.Invalid(acceleratorPath.Child("memory"), "0", "must be positive"),
+			},
+		},
+		{
+			name: "unknown role",
+			accelerator: &workloadv1alpha1.AcceleratorRequirements{
+				Count: 1,
+				Roles: []string{"decode", "router"},
+			},
+			want: field.ErrorList{
+				field.Invalid(acceleratorPath.Child("roles").Index(1), "router", "role router does not exist in template.roles"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := &workloadv1alpha1.ModelServing{
+				Spec: workloadv1alpha1.ModelServingSpec{
+					Template:    workloadv1alpha1.ServingGroup{Roles: roles},
+					Accelerator: tt.accelerator,
+				},
+			}
+			assert.Equal(t, tt.want, validateAccelerator(ms))
+		})
+	}
+}
+
+func TestValidatePlacement(t *testing.T) {
+	poolsPath := field.NewPath("spec").Child("placement").Child("pools")
+	spot := workloadv1alpha1.NodePool{
+		Name:         "spot",
+		NodeSelector: map[string]string{"node.kubernetes.io/capacity-type": "spot"},
+		HourlyCost:   resource.MustParse("9.6"),
+	}
+	tests := []struct {
+		name      string
+		placement *workloadv1alpha1.Placement
+		want      field.ErrorList
+	}{
+		{
+			name:      "no placement",
+			placement: nil,
+			want:      field.ErrorList(nil),
+		},
+		{
+			name: "valid placement",
+			placement: &workloadv1alpha1.Placement{
+				Policy: workloadv1alpha1.PlacementPolicyLowestCost,
+				Pools:  []workloadv1alpha1.NodePool{spot},
+			},
+			want: field.ErrorList(nil),
+		},
+		{
+			name:      "no pools",
+			placement: &workloadv1alpha1.Placement{},
+			want: field.ErrorList{
+				field.Required(poolsPath, "at least one node pool is required"),
+			},
+		},
+		{
+			name: "invalid pools",
+			placement: &workloadv1alpha1.Placement{
+				Pools: []workloadv1alpha1.NodePool{
+					spot,
+					{Name: "spot", HourlyCost: resource.MustParse("-1")},
+				},
+			},
+			want: field.ErrorList{
+				field.Duplicate(poolsPath.Index(1).Child("name"), "spot"),
+				field.Required(poolsPath.Index(1).Child("nodeSelector"), "the nodes of the pool must be selected"),
+				field.Invalid(poolsPath.Index(1).Child("hourlyCost"), "-1", "must not be negative"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := &workloadv1alpha1.ModelServing{
+				Spec: workloadv1alpha1.ModelServingSpec{
+					Placement: tt.placement,
+				},
+			}
+			assert.Equal(t, tt.want, validatePlacement(ms))
+		})
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package permissions detects at startup whether the optional features of kthena are granted the RBAC permissions
+// they need, so that a feature missing permissions is disabled instead of failing on Forbidden errors, and detects
+// when the permissions of a disabled feature are granted afterwards, so that the operator restarts the component to
+// enable it.
+package permissions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+var (
+	featureEnabled = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kthena_feature_enabled",
+		Help: "Whether an optional feature is enabled (1) or disabled because of missing RBAC permissions (0)",
+	}, []string{"feature"})
+	featurePermissionsGranted = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kthena_feature_permissions_granted",
+		Help: "Whether an optional feature is granted its RBAC permissions (1) or not (0), a granted feature which is not enabled is enabled by a restart",
+	}, []string{"feature"})
+)
+
+// restartRequired is the reason of the events of a component which must be restarted to enable a feature.
+const restartRequired = "RestartRequired"
+
+// disabledFeature is a feature disabled by Enabled, with the namespace its permissions were reviewed in.
+type disabledFeature struct {
+	feature   Feature
+	namespace string
+}
+
+// disabled are the features disabled by Enabled and not granted their permissions since, keyed by feature and
+// namespace.
+var disabled = struct {
+	sync.Mutex
+	features map[string]disabledFeature
+}{features: map[string]disabledFeature{}}
+
+func disabledKey(feature Feature, namespace string) string {
+	return feature.Name + "/" + namespace
+}
+
+// Rule is a set of verbs on a resource required by a feature. Resource may name a subresource, e.g. "pods/status".
+type Rule struct {
+	Group    string
+	Resource string
+	Verbs    []string
+}
+
+func (r Rule) String() string {
+	resource := r.Resource
+	if r.Group != "" {
+		resource += "." + r.Group
+	}
+	return strings.Join(r.Verbs, ",") + " " + resource
+}
+
+// Feature is an optional feature and the permissions it requires.
+type Feature struct {
+	Name  string
+	Rules []Rule
+}
+
+// Missing returns the permissions of the feature which are not granted, in namespace or in all namespaces if empty.
+func (f Feature) Missing(ctx context.Context, client kubernetes.Interface, namespace string) ([]Rule, error) {
+	var missing []Rule
+	for _, rule := range f.Rules {
+		var denied []string
+		resource, subresource, _ := strings.Cut(rule.Resource, "/")
+		for _, verb := range rule.Verbs {
+			review := &authorizationv1.SelfSubjectAccessReview{
+				Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+					ResourceAttributes: &authorizationv1.ResourceAttributes{
+						Namespace:   namespace,
+						Verb:        verb,
+						Group:       rule.Group,
+						Resource:    resource,
+						Subresource: subresource,
+					},
+				},
+			}
+			result, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("failed to review access to %s: %w", rule.Resource, err)
+			}
+			if !result.Status.Allowed {
+				denied = append(denied, verb)
+			}
+		}
+		if len(denied) > 0 {
+			missing = append(missing, Rule{Group: rule.Group, Resource: rule.Resource, Verbs: denied})
+		}
+	}
+	return missing, nil
+}
+
+// Enabled reports whether the feature is granted its permissions, and records the result in the
+// kthena_feature_enabled and kthena_feature_permissions_granted metrics. A feature missing permissions is reported with the permissions to grant.
+// If the permissions can't be reviewed, the feature is assumed enabled and fails on its own if it is not.
+func Enabled(ctx context.Context, client kubernetes.Interface, namespace string, feature Feature) bool {
+	missing, err := feature.Missing(ctx, client, namespace)
+	if err != nil {
+		klog.Warningf("Unable to check the permissions of %s, assuming they are granted: %v", feature.Name, err)
+		missing = nil
+	}
+	disabled.Lock()
+	defer disabled.Unlock()
+	if len(missing) == 0 {
+		delete(disabled.features, disabledKey(feature, namespace))
+		featureEnabled.WithLabelValues(feature.Name).Set(1)
+		featurePermissionsGranted.WithLabelValues(feature.Name).Set(1)
+		return true
+	}
+
+	permissions := make([]string, 0, len(missing))
+	for _, rule := range missing {
+		permissions = append(permissions, rule.String())
+	}
+	klog.Warningf("%s is disabled, its service account is missing permissions: %s", feature.Name, strings.Join(permissions, "; "))
+	disabled.features[disabledKey(feature, namespace)] = disabledFeature{feature: feature, namespace: namespace}
+	featureEnabled.WithLabelValues(feature.Name).Set(0)
+	featurePermissionsGranted.WithLabelValues(feature.Name).Set(0)
+	return false
+}
+
+// WatchGranted reviews again, every interval with a jitter of up to another interval, the permissions of the features
+// disabled by Enabled until ctx is done. A feature granted its permissions is passed to granted. The jitter spreads
+// the reviews of the replicas of a component.
+func WatchGranted(ctx context.Context, client kubernetes.Interface, interval time.Duration, granted func(Feature)) {
+	wait.JitterUntilWithContext(ctx, func(ctx context.Context) {
+		for _, feature := range Recheck(ctx, client) {
+			granted(feature)
+		}
+	}, interval, 1.0, true)
+}
+
+// Recheck reviews again the permissions of the features disabled by Enabled, and returns the ones which are granted
+// their permissions now. They are recorded as granted in the kthena_feature_permissions_granted metric, and stay
+// disabled in the kthena_feature_enabled one until the component restarts.
+func Recheck(ctx context.Context, client kubernetes.Interface) []Feature {
+	disabled.Lock()
+	features := make([]disabledFeature, 0, len(disabled.features))
+	for _, feature := range disabled.features {
+		features = append(features, feature)
+	}
+	disabled.Unlock()
+
+	var granted []Feature
+	for _, d := range features {
+		missing, err := d.feature.Missing(ctx, client, d.namespace)
+		if err != nil {
+			klog.Warningf("Unable to check the permissions of %s: %v", d.feature.Name, err)
+			continue
+		}
+		if len(missing) > 0 {
+			continue
+		}
+		disabled.Lock()
+		delete(disabled.features, disabledKey(d.feature, d.namespace))
+		disabled.Unlock()
+		featurePermissionsGranted.WithLabelValues(d.feature.Name).Set(1)
+		granted = append(granted, d.feature)
+	}
+	return granted
+}
+
+// RestartRequired returns the granted function of WatchGranted of a component enabling its features at startup only.
+// It reports that the component must be restarted to enable the feature, in the log and with an event of the pod of
+// the component if recorder and pod are set; the restart is left to the operator.
+func RestartRequired(component string, recorder record.EventRecorder, pod *corev1.ObjectReference) func(Feature) {
+	return func(feature Feature) {
+		klog.Warningf("%s is granted its permissions, restart the %s to enable it", feature.Name, component)
+		if recorder != nil && pod != nil {
+			recorder.Eventf(pod, corev1.EventTypeWarning, restartRequired, "%s is granted its permissions, restart the %s to enable it",
+				feature.Name, component)
+		}
+	}
+}
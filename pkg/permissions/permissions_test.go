@@ -0,0 +1,168 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package permissions
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+)
+
+// newFakeClient returns a client whose access reviews allow the granted "verb resource/subresource" permissions only.
+func newFakeClient(granted ...string) *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attributes := review.Spec.ResourceAttributes
+		permission := attributes.Verb + " " + attributes.Resource
+		if attributes.Subresource != "" {
+			permission += "/" + attributes.Subresource
+		}
+		for _, g := range granted {
+			if g == permission {
+				review.Status.Allowed = true
+			}
+		}
+		return true, review, nil
+	})
+	return client
+}
+
+var testFeature = Feature{
+	Name: "test",
+	Rules: []Rule{
+		{Group: "workload.serving.volcano.sh", Resource: "autoscalingpolicies", Verbs: []string{"list", "watch"}},
+		{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions/status", Verbs: []string{"update"}},
+	},
+}
+
+func TestMissing(t *testing.T) {
+	tests := []struct {
+		name     string
+		granted  []string
+		expected []Rule
+	}{
+		{
+			name:    "all granted",
+			granted: []string{"list autoscalingpolicies", "watch autoscalingpolicies", "update customresourcedefinitions/status"},
+		},
+		{
+			name:    "some verbs denied",
+			granted: []string{"list autoscalingpolicies"},
+			expected: []Rule{
+				{Group: "workload.serving.volcano.sh", Resource: "autoscalingpolicies", Verbs: []string{"watch"}},
+				{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions/status", Verbs: []string{"update"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			missing, err := testFeature.Missing(context.Background(), newFakeClient(tt.granted...), "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, missing)
+		})
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	featureEnabled.Reset()
+	featurePermissionsGranted.Reset()
+
+	assert.False(t, Enabled(context.Background(), newFakeClient("list autoscalingpolicies"), "default", testFeature))
+	assert.Equal(t, float64(0), testutil.ToFloat64(featureEnabled.WithLabelValues("test")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(featurePermissionsGranted.WithLabelValues("test")))
+
+	assert.True(t, Enabled(context.Background(), newFakeClient("list autoscalingpolicies", "watch autoscalingpolicies",
+		"update customresourcedefinitions/status"), "default", testFeature))
+	assert.Equal(t, float64(1), testutil.ToFloat64(featureEnabled.WithLabelValues("test")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(featurePermissionsGranted.WithLabelValues("test")))
+
+	// A failed review doesn't disable the feature.
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("server unavailable")
+	})
+	assert.True(t, Enabled(context.Background(), client, "default", testFeature))
+}
+
+func TestRecheck(t *testing.T) {
+	featureEnabled.Reset()
+	featurePermissionsGranted.Reset()
+	ctx := context.Background()
+	allGranted := newFakeClient("list autoscalingpolicies", "watch autoscalingpolicies", "update customresourcedefinitions/status")
+
+	assert.False(t, Enabled(ctx, newFakeClient(), "default", testFeature))
+	assert.Empty(t, Recheck(ctx, newFakeClient("list autoscalingpolicies")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(featurePermissionsGranted.WithLabelValues("test")))
+
+	// The feature granted its permissions is cleared, and reported once. It stays disabled until the restart.
+	assert.Equal(t, []Feature{testFeature}, Recheck(ctx, allGranted))
+	assert.Equal(t, float64(1), testutil.ToFloat64(featurePermissionsGranted.WithLabelValues("test")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(featureEnabled.WithLabelValues("test")))
+	assert.Empty(t, Recheck(ctx, allGranted))
+
+	// A feature enabled at a later check is not reviewed again.
+	assert.False(t, Enabled(ctx, newFakeClient(), "default", testFeature))
+	assert.True(t, Enabled(ctx, allGranted, "default", testFeature))
+	assert.Empty(t, Recheck(ctx, allGranted))
+}
+
+func TestWatchGranted(t *testing.T) {
+	featureEnabled.Reset()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.False(t, Enabled(ctx, newFakeClient(), "", testFeature))
+
+	granted := make(chan Feature, 1)
+	go WatchGranted(ctx, newFakeClient("list autoscalingpolicies", "watch autoscalingpolicies",
+		"update customresourcedefinitions/status"), 10*time.Millisecond, func(feature Feature) { granted <- feature })
+	select {
+	case feature := <-granted:
+		assert.Equal(t, testFeature, feature)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the granted feature was not reported")
+	}
+}
+
+func TestRestartRequired(t *testing.T) {
+	recorder := record.NewFakeRecorder(1)
+	pod := &corev1.ObjectReference{Kind: "Pod", Namespace: "kthena-system", Name: "kthena-router-0"}
+	RestartRequired("router", recorder, pod)(testFeature)
+	assert.Equal(t, "Warning RestartRequired test is granted its permissions, restart the router to enable it", <-recorder.Events)
+
+	// Without the pod, the feature is only reported in the log.
+	RestartRequired("router", recorder, nil)(testFeature)
+	assert.Empty(t, recorder.Events)
+}
+
+func TestRuleString(t *testing.T) {
+	assert.Equal(t, "get,list pods", Rule{Resource: "pods", Verbs: []string{"get", "list"}}.String())
+	assert.Equal(t, "update customresourcedefinitions/status.apiextensions.k8s.io",
+		Rule{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions/status", Verbs: []string{"update"}}.String())
+}
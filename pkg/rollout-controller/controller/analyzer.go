@@ -0,0 +1,109 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	promapi "github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+// Analysis measures the requests sent to the canary of a rollout over an interval.
+type Analysis struct {
+	// Requests is the number of requests sent to the canary.
+	Requests float64
+	// ErrorRate is the percentage of the requests failing with a server error.
+	ErrorRate float64
+	// LatencyP99 is the 99th percentile of the end-to-end duration of the requests.
+	LatencyP99 time.Duration
+	// Evaluations is the number of responses of the canary scored by the evaluation sink of the ModelRoute, and
+	// EvaluationScore their average score.
+	Evaluations     float64
+	EvaluationScore float64
+}
+
+// Analyzer analyzes the requests sent to a ModelServer through a ModelRoute, both given as namespace/name.
+type Analyzer interface {
+	Analyze(ctx context.Context, modelRoute, modelServer string, interval time.Duration) (Analysis, error)
+}
+
+// prometheusAnalyzer analyzes the requests with the metrics of the routers scraped by Prometheus.
+type prometheusAnalyzer struct {
+	api promv1.API
+}
+
+// NewPrometheusAnalyzer returns an Analyzer querying the Prometheus server at address, e.g. "http://prometheus:9090".
+func NewPrometheusAnalyzer(address string) (Analyzer, error) {
+	client, err := promapi.NewClient(promapi.Config{Address: address})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Prometheus client: %w", err)
+	}
+	return &prometheusAnalyzer{api: promv1.NewAPI(client)}, nil
+}
+
+func (a *prometheusAnalyzer) Analyze(ctx context.Context, modelRoute, modelServer string, interval time.Duration) (Analysis, error) {
+	selector := fmt.Sprintf("model_route=%q,model_server=%q", modelRoute, modelServer)
+	window := model.Duration(interval).String()
+
+	var analysis Analysis
+	var err error
+	analysis.Requests, err = a.query(ctx, fmt.Sprintf("sum(increase(kthena_router_model_server_requests_total{%s}[%s]))", selector, window))
+	if err != nil || analysis.Requests == 0 {
+		return analysis, err
+	}
+	errors, err := a.query(ctx, fmt.Sprintf(`sum(increase(kthena_router_model_server_requests_total{%s,status_code=~"5.."}[%s]))`, selector, window))
+	if err != nil {
+		return analysis, err
+	}
+	analysis.ErrorRate = 100 * errors / analysis.Requests
+	latency, err := a.query(ctx, fmt.Sprintf("histogram_quantile(0.99, sum by (le) (rate(kthena_router_model_server_request_duration_seconds_bucket{%s}[%s])))", selector, window))
+	if err != nil {
+		return analysis, err
+	}
+	analysis.LatencyP99 = time.Duration(latency * float64(time.Second))
+	analysis.Evaluations, err = a.query(ctx, fmt.Sprintf("sum(increase(kthena_router_evaluation_score_count{%s}[%s]))", selector, window))
+	if err != nil || analysis.Evaluations == 0 {
+		return analysis, err
+	}
+	scores, err := a.query(ctx, fmt.Sprintf("sum(increase(kthena_router_evaluation_score_sum{%s}[%s]))", selector, window))
+	if err != nil {
+		return analysis, err
+	}
+	analysis.EvaluationScore = scores / analysis.Evaluations
+	return analysis, nil
+}
+
+// query returns the value of the first sample of an instant query, 0 if it returns none.
+func (a *prometheusAnalyzer) query(ctx context.Context, query string) (float64, error) {
+	value, _, err := a.api.Query(ctx, query, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to query %q: %w", query, err)
+	}
+	vector, ok := value.(model.Vector)
+	if !ok {
+		return 0, fmt.Errorf("query %q returned a %s instead of a vector", query, value.Type())
+	}
+	if len(vector) == 0 || math.IsNaN(float64(vector[0].Value)) || math.IsInf(float64(vector[0].Value), 0) {
+		return 0, nil
+	}
+	return float64(vector[0].Value), nil
+}
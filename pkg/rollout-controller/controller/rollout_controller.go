@@ -0,0 +1,239 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	clientset "github.com/volcano-sh/kthena/client-go/clientset/versioned"
+	networking "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/controller/metrics"
+	"github.com/volcano-sh/kthena/pkg/controller/options"
+)
+
+// DefaultInterval is the default time between two reconciliations of the rollouts.
+const DefaultInterval = 30 * time.Second
+
+// Defaults of the rollouts not setting them.
+const (
+	defaultAnalysisInterval = 5 * time.Minute
+	defaultMaxErrorRate     = 1.0
+	defaultFailureThreshold = 2
+)
+
+var defaultSteps = []int32{5, 25, 50, 100}
+
+// Results of the analyses of the canaries.
+const (
+	resultSucceeded    = "succeeded"
+	resultFailed       = "failed"
+	resultInconclusive = "inconclusive"
+	resultError        = "error"
+)
+
+// Options configure the rollouts.
+type Options struct {
+	// Interval is the time between two reconciliations of the rollouts. The canaries are analyzed at the interval of
+	// their rollout, the reconciliation interval bounds how late an analysis can be.
+	Interval time.Duration
+	// PrometheusAddress is the address of the Prometheus server scraping the metrics of the routers.
+	PrometheusAddress string
+}
+
+// RolloutController progresses the rollouts of the ModelRoutes. At the interval of a rollout, the requests sent to its
+// canary by the routers are analyzed: the canary weight advances to the next step when their error rate and latency
+// are within the thresholds of the rollout, and falls back to 0 after too many failed analyses. The routers apply the
+// canary weight recorded in the status of the ModelRoute. An interval without request to the canary is inconclusive,
+// the rollout waits for traffic.
+type RolloutController struct {
+	kthenaClient clientset.Interface
+	analyzer     Analyzer
+	namespace    string
+	options      Options
+
+	now func() time.Time
+}
+
+func NewRolloutController(kthenaClient clientset.Interface, informerOptions options.InformerOptions, opts Options) (*RolloutController, error) {
+	analyzer, err := NewPrometheusAnalyzer(opts.PrometheusAddress)
+	if err != nil {
+		return nil, err
+	}
+	return newRolloutController(kthenaClient, analyzer, informerOptions, opts), nil
+}
+
+func newRolloutController(kthenaClient clientset.Interface, analyzer Analyzer, informerOptions options.InformerOptions, opts Options) *RolloutController {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	return &RolloutController{
+		kthenaClient: kthenaClient,
+		analyzer:     analyzer,
+		namespace:    informerOptions.Namespace,
+		options:      opts,
+		now:          time.Now,
+	}
+}
+
+func (c *RolloutController) Run(ctx context.Context) {
+	defer utilruntime.HandleCrash()
+
+	klog.Infof("start rollout controller, interval %s, Prometheus %s", c.options.Interval, c.options.PrometheusAddress)
+	wait.UntilWithContext(ctx, c.reconcileAll, c.options.Interval)
+	klog.Info("shut down rollout controller")
+}
+
+// reconcileAll progresses the rollouts of the ModelRoutes and records their progress in their status.
+func (c *RolloutController) reconcileAll(ctx context.Context) {
+	modelRoutes, err := c.kthenaClient.NetworkingV1alpha1().ModelRoutes(c.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("failed to list ModelRoutes: %v", err)
+		return
+	}
+	for i := range modelRoutes.Items {
+		mr := &modelRoutes.Items[i]
+		key := mr.Namespace + "/" + mr.Name
+		status := c.reconcile(ctx, mr)
+		if status == nil {
+			metrics.DeleteCanaryWeight(key)
+		} else {
+			metrics.SetCanaryWeight(key, status.CanaryWeight)
+		}
+		if equality.Semantic.DeepEqual(status, mr.Status.Rollout) {
+			continue
+		}
+		mr = mr.DeepCopy()
+		mr.Status.Rollout = status
+		// A conflict is retried with the latest ModelRoute on the next reconciliation.
+		if _, err := c.kthenaClient.NetworkingV1alpha1().ModelRoutes(mr.Namespace).UpdateStatus(ctx, mr, metav1.UpdateOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			klog.Errorf("failed to update the rollout status of ModelRoute %s: %v", key, err)
+		}
+	}
+}
+
+// reconcile returns the rollout status of a ModelRoute, after analyzing its canary if the interval of the current step
+// elapsed. It returns nil for a ModelRoute without rollout.
+func (c *RolloutController) reconcile(ctx context.Context, mr *networking.ModelRoute) *networking.RolloutStatus {
+	rollout := mr.Spec.Rollout
+	if rollout == nil {
+		return nil
+	}
+	key := mr.Namespace + "/" + mr.Name
+	now := metav1.NewTime(c.now())
+	steps := rollout.Steps
+	if len(steps) == 0 {
+		steps = defaultSteps
+	}
+
+	status := mr.Status.Rollout
+	if status == nil || status.CanaryModelServerName != rollout.CanaryModelServerName {
+		klog.Infof("ModelRoute %s starts the rollout of %s at %d%%", key, rollout.CanaryModelServerName, steps[0])
+		return &networking.RolloutStatus{
+			CanaryModelServerName: rollout.CanaryModelServerName,
+			Phase:                 networking.RolloutProgressing,
+			CanaryWeight:          steps[0],
+			LastAnalysisTime:      now,
+			LastTransitionTime:    now,
+			Message:               "rollout started",
+		}
+	}
+	status = status.DeepCopy()
+	interval := defaultAnalysisInterval
+	if rollout.Interval != nil {
+		interval = rollout.Interval.Duration
+	}
+	if status.Phase != networking.RolloutProgressing || now.Sub(status.LastAnalysisTime.Time) < interval {
+		return status
+	}
+
+	analysis, err := c.analyzer.Analyze(ctx, key, mr.Namespace+"/"+rollout.CanaryModelServerName, interval)
+	if err != nil {
+		// The analysis is retried on the next reconciliation.
+		klog.Errorf("failed to analyze the canary %s of ModelRoute %s: %v", rollout.CanaryModelServerName, key, err)
+		metrics.RecordRolloutAnalysis(key, resultError)
+		return status
+	}
+	status.LastAnalysisTime = now
+	if analysis.Requests == 0 {
+		status.Message = fmt.Sprintf("no request sent to the canary in the last %s", interval)
+		metrics.RecordRolloutAnalysis(key, resultInconclusive)
+		return status
+	}
+
+	if reason := failure(rollout, analysis); reason != "" {
+		metrics.RecordRolloutAnalysis(key, resultFailed)
+		status.FailedAnalyses++
+		status.Message = reason
+		threshold := int32(defaultFailureThreshold)
+		if rollout.FailureThreshold != nil {
+			threshold = *rollout.FailureThreshold
+		}
+		if status.FailedAnalyses >= threshold {
+			klog.Infof("ModelRoute %s rolls back the canary %s: %s", key, rollout.CanaryModelServerName, reason)
+			status.Phase = networking.RolloutRolledBack
+			status.CanaryWeight = 0
+			status.LastTransitionTime = now
+		}
+		return status
+	}
+
+	metrics.RecordRolloutAnalysis(key, resultSucceeded)
+	status.Message = fmt.Sprintf("error rate %.2f%%, p99 latency %s over %.0f requests", analysis.ErrorRate, analysis.LatencyP99, analysis.Requests)
+	if int(status.Step) >= len(steps)-1 {
+		klog.Infof("ModelRoute %s completed the rollout of %s", key, rollout.CanaryModelServerName)
+		status.Phase = networking.RolloutSucceeded
+		return status
+	}
+	status.Step++
+	status.CanaryWeight = steps[status.Step]
+	status.LastTransitionTime = now
+	klog.Infof("ModelRoute %s shifts %d%% of the traffic to the canary %s", key, status.CanaryWeight, rollout.CanaryModelServerName)
+	return status
+}
+
+// failure returns why an analysis of the canary failed, or an empty string if the canary is within the thresholds.
+func failure(rollout *networking.Rollout, analysis Analysis) string {
+	maxErrorRate := defaultMaxErrorRate
+	if rollout.MaxErrorRate != nil {
+		if rate, err := strconv.ParseFloat(*rollout.MaxErrorRate, 64); err == nil {
+			maxErrorRate = rate
+		}
+	}
+	if analysis.ErrorRate > maxErrorRate {
+		return fmt.Sprintf("error rate %.2f%% above %g%%", analysis.ErrorRate, maxErrorRate)
+	}
+	if rollout.MaxLatency != nil && analysis.LatencyP99 > rollout.MaxLatency.Duration {
+		return fmt.Sprintf("p99 latency %s above %s", analysis.LatencyP99, rollout.MaxLatency.Duration)
+	}
+	// The score is not analyzed when no response of the canary was evaluated over the interval.
+	if rollout.MinEvaluationScore != nil && analysis.Evaluations > 0 {
+		if minScore, err := strconv.ParseFloat(*rollout.MinEvaluationScore, 64); err == nil && analysis.EvaluationScore < minScore {
+			return fmt.Sprintf("evaluation score %.2f below %g", analysis.EvaluationScore, minScore)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,232 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	kthenafake "github.com/volcano-sh/kthena/client-go/clientset/versioned/fake"
+	networking "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/controller/options"
+)
+
+// fakeAnalyzer returns the next of its analyses.
+type fakeAnalyzer struct {
+	analyses []Analysis
+	calls    []string
+}
+
+func (a *fakeAnalyzer) Analyze(_ context.Context, modelRoute, modelServer string, interval time.Duration) (Analysis, error) {
+	a.calls = append(a.calls, modelRoute+" "+modelServer+" "+interval.String())
+	analysis := a.analyses[0]
+	a.analyses = a.analyses[1:]
+	return analysis, nil
+}
+
+func newModelRoute(rollout *networking.Rollout) *networking.ModelRoute {
+	return &networking.ModelRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "llama"},
+		Spec: networking.ModelRouteSpec{
+			ModelName: "llama",
+			Rules: []*networking.Rule{{
+				TargetModels: []*networking.TargetModel{{ModelServerName: "llama-v1"}},
+			}},
+			Rollout: rollout,
+		},
+	}
+}
+
+// step advances the clock by a minute, reconciles the rollouts and returns the rollout status of the ModelRoute.
+func step(t *testing.T, c *RolloutController, now *time.Time) *networking.RolloutStatus {
+	*now = now.Add(time.Minute)
+	c.reconcileAll(context.Background())
+	mr, err := c.kthenaClient.NetworkingV1alpha1().ModelRoutes("default").Get(context.Background(), "llama", metav1.GetOptions{})
+	require.NoError(t, err)
+	return mr.Status.Rollout
+}
+
+func TestRolloutProgresses(t *testing.T) {
+	rollout := &networking.Rollout{
+		StableModelServerName: "llama-v1",
+		CanaryModelServerName: "llama-v2",
+		Steps:                 []int32{10, 50, 100},
+		Interval:              &metav1.Duration{Duration: time.Minute},
+		MaxErrorRate:          ptr.To("5"),
+		MaxLatency:            &metav1.Duration{Duration: 2 * time.Second},
+	}
+	analyzer := &fakeAnalyzer{analyses: []Analysis{
+		{Requests: 100, ErrorRate: 1, LatencyP99: time.Second},
+		{Requests: 0},
+		{Requests: 100, ErrorRate: 10},
+		{Requests: 100, LatencyP99: time.Second},
+		{Requests: 100, LatencyP99: time.Second},
+	}}
+	now := time.Now()
+	c := newRolloutController(kthenafake.NewSimpleClientset(newModelRoute(rollout)), analyzer, options.InformerOptions{}, Options{})
+	c.now = func() time.Time { return now }
+
+	status := step(t, c, &now)
+	assert.Equal(t, networking.RolloutProgressing, status.Phase)
+	assert.Equal(t, int32(10), status.CanaryWeight)
+	assert.Empty(t, analyzer.calls)
+
+	status = step(t, c, &now)
+	assert.Equal(t, int32(50), status.CanaryWeight)
+	assert.Equal(t, []string{"default/llama default/llama-v2 1m0s"}, analyzer.calls)
+
+	// An interval without traffic to the canary doesn't change the weight.
+	status = step(t, c, &now)
+	assert.Equal(t, int32(50), status.CanaryWeight)
+	assert.Equal(t, "no request sent to the canary in the last 1m0s", status.Message)
+
+	// A single failed analysis is below the failure threshold.
+	status = step(t, c, &now)
+	assert.Equal(t, int32(50), status.CanaryWeight)
+	assert.Equal(t, int32(1), status.FailedAnalyses)
+	assert.Equal(t, "error rate 10.00% above 5%", status.Message)
+
+	status = step(t, c, &now)
+	assert.Equal(t, int32(100), status.CanaryWeight)
+	assert.Equal(t, int32(2), status.Step)
+
+	status = step(t, c, &now)
+	assert.Equal(t, networking.RolloutSucceeded, status.Phase)
+	assert.Equal(t, int32(100), status.CanaryWeight)
+
+	// A completed rollout is no longer analyzed.
+	step(t, c, &now)
+	assert.Len(t, analyzer.calls, 5)
+}
+
+func TestRolloutRollsBack(t *testing.T) {
+	rollout := &networking.Rollout{
+		StableModelServerName: "llama-v1",
+		CanaryModelServerName: "llama-v2",
+		Interval:              &metav1.Duration{Duration: time.Minute},
+		MaxLatency:            &metav1.Duration{Duration: 2 * time.Second},
+		FailureThreshold:      ptr.To[int32](1),
+	}
+	analyzer := &fakeAnalyzer{analyses: []Analysis{{Requests: 100, LatencyP99: 3 * time.Second}}}
+	now := time.Now()
+	c := newRolloutController(kthenafake.NewSimpleClientset(newModelRoute(rollout)), analyzer, options.InformerOptions{}, Options{})
+	c.now = func() time.Time { return now }
+
+	status := step(t, c, &now)
+	assert.Equal(t, int32(5), status.CanaryWeight)
+
+	status = step(t, c, &now)
+	assert.Equal(t, networking.RolloutRolledBack, status.Phase)
+	assert.Zero(t, status.CanaryWeight)
+	assert.Equal(t, "p99 latency 3s above 2s", status.Message)
+
+	// A new canary restarts the rollout.
+	mr, err := c.kthenaClient.NetworkingV1alpha1().ModelRoutes("default").Get(context.Background(), "llama", metav1.GetOptions{})
+	require.NoError(t, err)
+	mr.Spec.Rollout.CanaryModelServerName = "llama-v3"
+	_, err = c.kthenaClient.NetworkingV1alpha1().ModelRoutes("default").Update(context.Background(), mr, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	status = step(t, c, &now)
+	assert.Equal(t, networking.RolloutProgressing, status.Phase)
+	assert.Equal(t, "llama-v3", status.CanaryModelServerName)
+	assert.Equal(t, int32(5), status.CanaryWeight)
+
+	// Removing the rollout clears its status.
+	mr, err = c.kthenaClient.NetworkingV1alpha1().ModelRoutes("default").Get(context.Background(), "llama", metav1.GetOptions{})
+	require.NoError(t, err)
+	mr.Spec.Rollout = nil
+	_, err = c.kthenaClient.NetworkingV1alpha1().ModelRoutes("default").Update(context.Background(), mr, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, step(t, c, &now))
+}
+
+func TestFailure(t *testing.T) {
+	rollout := &networking.Rollout{
+		StableModelServerName: "llama-v1",
+		CanaryModelServerName: "llama-v2",
+		MinEvaluationScore:    ptr.To("0.8"),
+	}
+	tests := []struct {
+		name     string
+		analysis Analysis
+		want     string
+	}{
+		{
+			name:     "within the thresholds",
+			analysis: Analysis{Requests: 100, Evaluations: 10, EvaluationScore: 0.85},
+		},
+		{
+			name:     "error rate above the default",
+			analysis: Analysis{Requests: 100, ErrorRate: 2, Evaluations: 10, EvaluationScore: 0.85},
+			want:     "error rate 2.00% above 1%",
+		},
+		{
+			name:     "evaluation score below the minimum",
+			analysis: Analysis{Requests: 100, Evaluations: 10, EvaluationScore: 0.62},
+			want:     "evaluation score 0.62 below 0.8",
+		},
+		{
+			name:     "no response evaluated",
+			analysis: Analysis{Requests: 100},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, failure(rollout, tt.analysis))
+		})
+	}
+}
+
+func TestPrometheusAnalyzer(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		query := r.Form.Get("query")
+		queries = append(queries, query)
+		value := "200"
+		switch {
+		case strings.Contains(query, "status_code"):
+			value = "4"
+		case strings.HasPrefix(query, "histogram_quantile"):
+			value = "1.5"
+		case strings.Contains(query, "evaluation_score_count"):
+			value = "40"
+		case strings.Contains(query, "evaluation_score_sum"):
+			value = "30"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"` + value + `"]}]}}`))
+	}))
+	defer server.Close()
+
+	analyzer, err := NewPrometheusAnalyzer(server.URL)
+	require.NoError(t, err)
+	analysis, err := analyzer.Analyze(context.Background(), "default/llama", "default/llama-v2", 5*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, Analysis{Requests: 200, ErrorRate: 2, LatencyP99: 1500 * time.Millisecond, Evaluations: 40, EvaluationScore: 0.75}, analysis)
+	require.Len(t, queries, 5)
+	assert.Equal(t, `sum(increase(kthena_router_model_server_requests_total{model_route="default/llama",model_server="default/llama-v2"}[5m]))`, queries[0])
+}
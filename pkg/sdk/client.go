@@ -0,0 +1,45 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sdk is the Go client of kthena for platform tools: the typed clients of the kthena resources and the client
+// of the APIs of the router.
+package sdk
+
+import (
+	"k8s.io/client-go/rest"
+
+	"github.com/volcano-sh/kthena/client-go/clientset/versioned"
+)
+
+// Client manages the kthena resources of a cluster, e.g. ModelRoutes, ModelServers and ModelServings, with the typed
+// clients of the generated clientset.
+type Client struct {
+	versioned.Interface
+}
+
+// NewForConfig creates a client of the cluster of the config.
+func NewForConfig(config *rest.Config) (*Client, error) {
+	clientset, err := versioned.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{Interface: clientset}, nil
+}
+
+// New creates a client from a clientset, e.g. a fake one in tests.
+func New(clientset versioned.Interface) *Client {
+	return &Client{Interface: clientset}
+}
@@ -0,0 +1,225 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/volcano-sh/kthena/pkg/kthena-router/catalog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/feedback"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/snapshot"
+)
+
+// ErrNotFound is returned when the router doesn't know the model or the request.
+var ErrNotFound = errors.New("not found")
+
+// RouterClient calls the APIs of a kthena router: the model catalog, the feedback and the cancellation of the
+// requests on its serving port, and the snapshots of its state on its state API port.
+type RouterClient struct {
+	baseURL    string
+	stateURL   string
+	token      string
+	httpClient *http.Client
+}
+
+// RouterOption configures a RouterClient.
+type RouterOption func(*RouterClient)
+
+// WithStateURL sets the URL of the state API of the router, e.g. http://kthena-router:8082.
+func WithStateURL(stateURL string) RouterOption {
+	return func(c *RouterClient) {
+		c.stateURL = strings.TrimSuffix(stateURL, "/")
+	}
+}
+
+// WithBearerToken authenticates the requests with the token, when the router authenticates the requests.
+func WithBearerToken(token string) RouterOption {
+	return func(c *RouterClient) {
+		c.token = token
+	}
+}
+
+// WithHTTPClient sets the HTTP client the APIs are called with, http.DefaultClient by default.
+func WithHTTPClient(httpClient *http.Client) RouterOption {
+	return func(c *RouterClient) {
+		c.httpClient = httpClient
+	}
+}
+
+// NewRouterClient creates a client of the router serving at baseURL, e.g. http://kthena-router.
+func NewRouterClient(baseURL string, opts ...RouterOption) *RouterClient {
+	c := &RouterClient{baseURL: strings.TrimSuffix(baseURL, "/"), httpClient: http.DefaultClient}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ListModels returns the models served by the router.
+func (c *RouterClient) ListModels(ctx context.Context) ([]catalog.Model, error) {
+	var list catalog.ModelList
+	if err := c.do(ctx, http.MethodGet, c.baseURL+catalog.Path, nil, &list); err != nil {
+		return nil, err
+	}
+	return list.Data, nil
+}
+
+// GetModel returns a model served by the router, ErrNotFound if it doesn't serve it.
+func (c *RouterClient) GetModel(ctx context.Context, id string) (*catalog.Model, error) {
+	var model catalog.Model
+	if err := c.do(ctx, http.MethodGet, c.baseURL+catalog.Path+"/"+id, nil, &model); err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+// SubmitFeedback reports the quality of the response of a request.
+func (c *RouterClient) SubmitFeedback(ctx context.Context, f *feedback.Feedback) error {
+	if err := f.Validate(); err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, c.baseURL+feedback.Path, f, nil)
+}
+
+// FeedbackStats returns the feedback aggregated per model server of the model, or of all the models if it is empty.
+func (c *RouterClient) FeedbackStats(ctx context.Context, model string) ([]feedback.VariantStats, error) {
+	endpoint := c.baseURL + feedback.Path
+	if model != "" {
+		endpoint += "?model=" + url.QueryEscape(model)
+	}
+	var stats feedback.StatsResponse
+	if err := c.do(ctx, http.MethodGet, endpoint, nil, &stats); err != nil {
+		return nil, err
+	}
+	return stats.Variants, nil
+}
+
+// CancelRequest cancels an in-flight request by the id of its x-request-id header, ErrNotFound if it is not in
+// flight anymore.
+func (c *RouterClient) CancelRequest(ctx context.Context, requestID string) error {
+	return c.do(ctx, http.MethodPost, c.baseURL+"/v1/requests/"+url.PathEscape(requestID)+"/cancel", nil, nil)
+}
+
+// Snapshot returns the current state of the router from its state API.
+func (c *RouterClient) Snapshot(ctx context.Context) (*snapshot.Snapshot, error) {
+	if c.stateURL == "" {
+		return nil, errors.New("the state API URL of the router is not set")
+	}
+	var s snapshot.Snapshot
+	if err := c.do(ctx, http.MethodGet, c.stateURL+snapshot.Path, nil, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// WatchSnapshots calls fn with the current state of the router and then with each new state, until the context is
+// canceled, the watch is closed by the router or fn returns an error. A watch resumed with the revision of the last
+// snapshot received doesn't receive it again.
+func (c *RouterClient) WatchSnapshots(ctx context.Context, revision uint64, fn func(*snapshot.Snapshot) error) error {
+	if c.stateURL == "" {
+		return errors.New("the state API URL of the router is not set")
+	}
+	endpoint := c.stateURL + snapshot.Path + "?watch=true"
+	if revision > 0 {
+		endpoint += "&revision=" + strconv.FormatUint(revision, 10)
+	}
+	resp, err := c.send(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	decoder := json.NewDecoder(bufio.NewReader(resp.Body))
+	for {
+		var s snapshot.Snapshot
+		if err := decoder.Decode(&s); err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("failed to decode snapshot: %w", err)
+		}
+		if err := fn(&s); err != nil {
+			return err
+		}
+	}
+}
+
+// do calls the API and decodes its JSON response into out, unless out is nil.
+func (c *RouterClient) do(ctx context.Context, method, endpoint string, in, out any) error {
+	resp, err := c.send(ctx, method, endpoint, in)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response of %s %s: %w", method, endpoint, err)
+	}
+	return nil
+}
+
+// send calls the API and returns its response, or an error if its status is not 200.
+func (c *RouterClient) send(ctx context.Context, method, endpoint string, in any) (*http.Response, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	var message struct {
+		Message string `json:"message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if json.Unmarshal(data, &message) != nil || message.Message == "" {
+		message.Message = strings.TrimSpace(string(data))
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s %s: %w: %s", method, endpoint, ErrNotFound, message.Message)
+	}
+	return nil, fmt.Errorf("%s %s returned %d: %s", method, endpoint, resp.StatusCode, message.Message)
+}
@@ -0,0 +1,119 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/volcano-sh/kthena/client-go/clientset/versioned/fake"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/catalog"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/feedback"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/snapshot"
+)
+
+func TestRouterClient(t *testing.T) {
+	var received feedback.Feedback
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/models", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		_ = json.NewEncoder(w).Encode(catalog.ModelList{Object: "list", Data: []catalog.Model{{ID: "llama", ReadyEndpoints: 2}}})
+	})
+	mux.HandleFunc("GET /v1/models/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprintf(w, `{"message":"model %s not found"}`, r.PathValue("id"))
+	})
+	mux.HandleFunc("POST /v1/feedback", func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		_, _ = w.Write([]byte(`{"message":"ok"}`))
+	})
+	mux.HandleFunc("GET /v1/feedback", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(feedback.StatsResponse{Variants: []feedback.VariantStats{{Model: r.URL.Query().Get("model"), Positive: 3}}})
+	})
+	mux.HandleFunc("POST /v1/requests/{id}/cancel", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") != "req-1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"message":"canceled"}`))
+	})
+	mux.HandleFunc("GET "+snapshot.Path, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("watch") != "true" {
+			_ = json.NewEncoder(w).Encode(snapshot.Snapshot{Revision: 7})
+			return
+		}
+		assert.Equal(t, "7", r.URL.Query().Get("revision"))
+		for revision := uint64(8); revision <= 9; revision++ {
+			_ = json.NewEncoder(w).Encode(snapshot.Snapshot{Revision: revision})
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx := context.Background()
+	client := NewRouterClient(server.URL+"/", WithStateURL(server.URL), WithBearerToken("secret"))
+
+	models, err := client.ListModels(ctx)
+	require.NoError(t, err)
+	require.Len(t, models, 1)
+	assert.Equal(t, "llama", models[0].ID)
+
+	_, err = client.GetModel(ctx, "mistral")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorContains(t, err, "model mistral not found")
+
+	assert.Error(t, client.SubmitFeedback(ctx, &feedback.Feedback{RequestID: "req-1", Rating: "great"}))
+	require.NoError(t, client.SubmitFeedback(ctx, &feedback.Feedback{RequestID: "req-1", Rating: feedback.RatingPositive}))
+	assert.Equal(t, feedback.Feedback{RequestID: "req-1", Rating: feedback.RatingPositive}, received)
+
+	stats, err := client.FeedbackStats(ctx, "llama")
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, "llama", stats[0].Model)
+
+	require.NoError(t, client.CancelRequest(ctx, "req-1"))
+	assert.ErrorIs(t, client.CancelRequest(ctx, "req-2"), ErrNotFound)
+
+	current, err := client.Snapshot(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), current.Revision)
+
+	var revisions []uint64
+	require.NoError(t, client.WatchSnapshots(ctx, current.Revision, func(s *snapshot.Snapshot) error {
+		revisions = append(revisions, s.Revision)
+		return nil
+	}))
+	assert.Equal(t, []uint64{8, 9}, revisions)
+
+	_, err = NewRouterClient(server.URL).Snapshot(ctx)
+	assert.Error(t, err)
+}
+
+func TestClient(t *testing.T) {
+	client := New(fake.NewSimpleClientset())
+	routes, err := client.NetworkingV1alpha1().ModelRoutes("default").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, routes.Items)
+}
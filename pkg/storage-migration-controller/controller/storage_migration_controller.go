@@ -0,0 +1,251 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apiextinformers "k8s.io/apiextensions-apiserver/pkg/client/informers/externalversions"
+	apiextlisters "k8s.io/apiextensions-apiserver/pkg/client/listers/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"github.com/volcano-sh/kthena/pkg/controller/options"
+)
+
+const (
+	// kthenaGroupSuffix is the API group suffix of the custom resources defined by kthena.
+	kthenaGroupSuffix = ".serving.volcano.sh"
+	// listPageSize is the number of objects listed at once while migrating a resource.
+	listPageSize = 500
+)
+
+// StorageMigrationController migrates the stored objects of the kthena CRDs to their storage version.
+// When an upgrade changes the storage version of a CRD, objects written before remain stored in the
+// previous version, which can't be removed from the CRD until they are rewritten. The controller rewrites
+// every object of such CRDs, which makes the API server store them in the current storage version,
+// then sets status.storedVersions to the storage version only.
+type StorageMigrationController struct {
+	apiextClient  apiextclient.Interface
+	dynamicClient dynamic.Interface
+
+	crdLister             apiextlisters.CustomResourceDefinitionLister
+	crdInformer           cache.SharedIndexInformer
+	apiextInformerFactory apiextinformers.SharedInformerFactory
+	workQueue             workqueue.TypedRateLimitingInterface[any]
+}
+
+func NewStorageMigrationController(apiextClient apiextclient.Interface, dynamicClient dynamic.Interface, opts options.InformerOptions) *StorageMigrationController {
+	// CRDs are cluster scoped and all their objects must be migrated, the namespace and the label selector don't apply.
+	factory := apiextinformers.NewSharedInformerFactory(apiextClient, opts.ResyncPeriod)
+	crdInformer := factory.Apiextensions().V1().CustomResourceDefinitions()
+
+	c := &StorageMigrationController{
+		apiextClient:          apiextClient,
+		dynamicClient:         dynamicClient,
+		crdLister:             crdInformer.Lister(),
+		crdInformer:           crdInformer.Informer(),
+		apiextInformerFactory: factory,
+		workQueue: workqueue.NewTypedRateLimitingQueueWithConfig(workqueue.DefaultTypedControllerRateLimiter[any](),
+			workqueue.TypedRateLimitingQueueConfig[any]{Name: "CustomResourceDefinitions"}),
+	}
+
+	_, _ = c.crdInformer.AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: isKthenaCRD,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc: c.enqueue,
+			UpdateFunc: func(_, newObj any) {
+				c.enqueue(newObj)
+			},
+		},
+	})
+	return c
+}
+
+func isKthenaCRD(obj any) bool {
+	crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition)
+	return ok && strings.HasSuffix(crd.Spec.Group, kthenaGroupSuffix)
+}
+
+func (c *StorageMigrationController) enqueue(obj any) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.workQueue.Add(key)
+}
+
+func (c *StorageMigrationController) Run(ctx context.Context, workers int) {
+	defer utilruntime.HandleCrash()
+	defer c.workQueue.ShutDown()
+
+	c.WarmUp(ctx)
+
+	klog.Info("start storage migration controller")
+	for i := 0; i < workers; i++ {
+		go c.worker(ctx)
+	}
+	<-ctx.Done()
+	klog.Info("shut down storage migration controller")
+}
+
+// WarmUp starts the informers and waits for their caches to sync, without reconciling.
+func (c *StorageMigrationController) WarmUp(ctx context.Context) {
+	c.apiextInformerFactory.Start(ctx.Done())
+	cache.WaitForCacheSync(ctx.Done(), c.crdInformer.HasSynced)
+}
+
+func (c *StorageMigrationController) worker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *StorageMigrationController) processNextWorkItem(ctx context.Context) bool {
+	key, quit := c.workQueue.Get()
+	if quit {
+		return false
+	}
+	defer c.workQueue.Done(key)
+
+	err := c.syncCRD(ctx, key.(string))
+	if err == nil {
+		c.workQueue.Forget(key)
+		return true
+	}
+	utilruntime.HandleError(fmt.Errorf("sync %q failed with %v", key, err))
+	c.workQueue.AddRateLimited(key)
+	return true
+}
+
+// storageVersion returns the version objects of the CRD are stored in.
+func storageVersion(crd *apiextensionsv1.CustomResourceDefinition) string {
+	for _, version := range crd.Spec.Versions {
+		if version.Storage {
+			return version.Name
+		}
+	}
+	return ""
+}
+
+// needsMigration reports whether objects of the CRD may be stored in another version than the storage version.
+func needsMigration(crd *apiextensionsv1.CustomResourceDefinition, version string) bool {
+	for _, stored := range crd.Status.StoredVersions {
+		if stored != version {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *StorageMigrationController) syncCRD(ctx context.Context, name string) error {
+	crd, err := c.crdLister.Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	version := storageVersion(crd)
+	if version == "" || !needsMigration(crd, version) {
+		return nil
+	}
+
+	klog.Infof("migrating %s from stored versions %v to %s", name, crd.Status.StoredVersions, version)
+	gvr := schema.GroupVersionResource{Group: crd.Spec.Group, Version: version, Resource: crd.Spec.Names.Plural}
+	migrated, err := c.migrateObjects(ctx, gvr)
+	if err != nil {
+		return fmt.Errorf("failed to migrate %s: %w", name, err)
+	}
+
+	// Objects created during the migration are already stored in the storage version.
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := c.apiextClient.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if storageVersion(latest) != version {
+			return fmt.Errorf("storage version of %s changed during the migration", name)
+		}
+		latest = latest.DeepCopy()
+		latest.Status.StoredVersions = []string{version}
+		_, err = c.apiextClient.ApiextensionsV1().CustomResourceDefinitions().UpdateStatus(ctx, latest, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update stored versions of %s: %w", name, err)
+	}
+	klog.Infof("migrated %d objects of %s to %s", migrated, name, version)
+	return nil
+}
+
+// migrateObjects rewrites every object of the resource, which makes the API server store it in the storage version.
+func (c *StorageMigrationController) migrateObjects(ctx context.Context, gvr schema.GroupVersionResource) (int, error) {
+	client := c.dynamicClient.Resource(gvr)
+	migrated := 0
+	opts := metav1.ListOptions{Limit: listPageSize}
+	for {
+		list, err := client.List(ctx, opts)
+		if err != nil {
+			return migrated, err
+		}
+		for i := range list.Items {
+			if err := c.migrateObject(ctx, gvr, &list.Items[i]); err != nil {
+				return migrated, err
+			}
+			migrated++
+		}
+		if list.GetContinue() == "" {
+			return migrated, nil
+		}
+		opts.Continue = list.GetContinue()
+	}
+}
+
+func (c *StorageMigrationController) migrateObject(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+	client := c.dynamicClient.Resource(gvr).Namespace(obj.GetNamespace())
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		// An update without changes is enough for the API server to re-encode the object in the storage version.
+		_, err := client.Update(ctx, obj, metav1.UpdateOptions{})
+		if apierrors.IsConflict(err) {
+			latest, getErr := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+			if getErr != nil {
+				return getErr
+			}
+			obj = latest
+		}
+		return err
+	})
+	if apierrors.IsNotFound(err) {
+		// Deleted in the meantime, nothing to migrate.
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,127 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/volcano-sh/kthena/pkg/controller/options"
+)
+
+var modelServingsGVR = schema.GroupVersionResource{Group: "workload.serving.volcano.sh", Version: "v1alpha2", Resource: "modelservings"}
+
+func newCRD(group string, storedVersions ...string) *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "modelservings." + group},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: group,
+			Names: apiextensionsv1.CustomResourceDefinitionNames{Plural: "modelservings", Kind: "ModelServing"},
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1alpha1", Served: true},
+				{Name: "v1alpha2", Served: true, Storage: true},
+			},
+		},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{StoredVersions: storedVersions},
+	}
+}
+
+func newModelServing(namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(modelServingsGVR.GroupVersion().String())
+	obj.SetKind("ModelServing")
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+func newTestController(t *testing.T, crd *apiextensionsv1.CustomResourceDefinition, objects ...runtime.Object) (*StorageMigrationController, *dynamicfake.FakeDynamicClient) {
+	apiextClient := apiextfake.NewSimpleClientset(crd)
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{modelServingsGVR: "ModelServingList"}, objects...)
+	c := NewStorageMigrationController(apiextClient, dynamicClient, options.InformerOptions{})
+	require.NoError(t, c.crdInformer.GetIndexer().Add(crd))
+	return c, dynamicClient
+}
+
+func countUpdates(client *dynamicfake.FakeDynamicClient) int {
+	updates := 0
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "update" {
+			updates++
+		}
+	}
+	return updates
+}
+
+func TestSyncCRD(t *testing.T) {
+	tests := []struct {
+		name            string
+		crd             *apiextensionsv1.CustomResourceDefinition
+		expectedUpdates int
+		expectedStored  []string
+	}{
+		{
+			name:            "stored in previous version",
+			crd:             newCRD("workload.serving.volcano.sh", "v1alpha1", "v1alpha2"),
+			expectedUpdates: 2,
+			expectedStored:  []string{"v1alpha2"},
+		},
+		{
+			name:            "stored in storage version",
+			crd:             newCRD("workload.serving.volcano.sh", "v1alpha2"),
+			expectedUpdates: 0,
+			expectedStored:  []string{"v1alpha2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, dynamicClient := newTestController(t, tt.crd, newModelServing("default", "a"), newModelServing("team", "b"))
+
+			require.NoError(t, c.syncCRD(context.Background(), tt.crd.Name))
+			assert.Equal(t, tt.expectedUpdates, countUpdates(dynamicClient))
+
+			crd, err := c.apiextClient.ApiextensionsV1().CustomResourceDefinitions().Get(context.Background(), tt.crd.Name, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStored, crd.Status.StoredVersions)
+		})
+	}
+}
+
+func TestSyncCRD_NotFound(t *testing.T) {
+	c, dynamicClient := newTestController(t, newCRD("workload.serving.volcano.sh", "v1alpha1"))
+	assert.NoError(t, c.syncCRD(context.Background(), "missing.workload.serving.volcano.sh"))
+	assert.Empty(t, dynamicClient.Actions())
+}
+
+func TestIsKthenaCRD(t *testing.T) {
+	assert.True(t, isKthenaCRD(newCRD("workload.serving.volcano.sh")))
+	assert.True(t, isKthenaCRD(newCRD("networking.serving.volcano.sh")))
+	assert.False(t, isKthenaCRD(newCRD("leaderworkerset.x-k8s.io")))
+	assert.False(t, isKthenaCRD(newModelServing("default", "a")))
+}
@@ -0,0 +1,476 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+
+	networking "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/controller/options"
+	icUtils "github.com/volcano-sh/kthena/pkg/model-serving-controller/utils"
+)
+
+const (
+	// TenantsConfigMapName is the ConfigMap listing the tenant routers, in the namespace of the shared router.
+	TenantsConfigMapName = "kthena-router-tenants"
+	// TenantsKey is the key of the tenants in the ConfigMap, a YAML list of Tenant.
+	TenantsKey = "tenants.yaml"
+	// SharedRouterName is the name of the Deployment and the Service of the shared router, which the tenant routers
+	// are made from.
+	SharedRouterName = "kthena-router"
+	// routerContainerName is the container of the router in the pods of the shared router.
+	routerContainerName = "kthena-router"
+	componentLabelKey   = "app.kubernetes.io/component"
+
+	// TenantRouterLabelKey is the label of the Deployment and the Service of a tenant router, set to its tenant.
+	TenantRouterLabelKey = networking.GroupName + "/tenant-router"
+	// RevisionLabelKey is the label of the Deployment and the Service of a tenant router, set to the hash of their spec.
+	RevisionLabelKey = networking.GroupName + "/tenant-router-revision"
+)
+
+// profileFlags are the router flags set from the profile of the shared router, dropped from the tenant routers with
+// their own profile.
+var profileFlags = []string{"--profile", "--max-concurrent-requests", "--stream-buffer-size", "--max-buffered-body-bytes",
+	"--memory-watermark-bytes", "--profile-features"}
+
+// Tenant is a router instance of a tenant, serving the ModelRoutes of the tenant only.
+type Tenant struct {
+	Name string `json:"name"`
+	// Replicas is the number of router replicas, 1 if unset.
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Profile is the router profile, the one of the shared router if unset.
+	Profile string `json:"profile,omitempty"`
+	// RouteSelector is the label selector of the ModelRoutes served, the ModelRoutes labeled with the tenant label set
+	// to the name of the tenant if unset.
+	RouteSelector string `json:"routeSelector,omitempty"`
+	// ServiceType is the type of the Service of the router, the one of the shared router if unset.
+	ServiceType corev1.ServiceType `json:"serviceType,omitempty"`
+	// Resources are the resources of the router container, the ones of the shared router if unset.
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Args are router flags added to the ones of the shared router, e.g. the overrides of the profile.
+	Args []string `json:"args,omitempty"`
+}
+
+// TenantRouterController provisions a router instance per tenant listed in the tenants ConfigMap, each with its own
+// Deployment and Service made from the ones of the shared router. A tenant router only serves the ModelRoutes of its
+// tenant. The routers of the tenants removed from the ConfigMap are deleted, and the routers are updated with the
+// shared router, e.g. when it is upgraded.
+type TenantRouterController struct {
+	kubeClient kubernetes.Interface
+	namespace  string
+
+	configMapLister    corelisters.ConfigMapLister
+	configMapInformer  cache.SharedIndexInformer
+	deploymentLister   appslisters.DeploymentLister
+	deploymentInformer cache.SharedIndexInformer
+	serviceLister      corelisters.ServiceLister
+	serviceInformer    cache.SharedIndexInformer
+
+	workQueue     workqueue.TypedRateLimitingInterface[string]
+	informersOnce sync.Once
+}
+
+// NewTenantRouterController creates a controller of the tenant routers of the shared router in the given namespace.
+func NewTenantRouterController(kubeClient kubernetes.Interface, namespace string, opts options.InformerOptions) *TenantRouterController {
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, opts.ResyncPeriod, informers.WithNamespace(namespace))
+	configMapInformer := informerFactory.Core().V1().ConfigMaps()
+	deploymentInformer := informerFactory.Apps().V1().Deployments()
+	serviceInformer := informerFactory.Core().V1().Services()
+
+	c := &TenantRouterController{
+		kubeClient:         kubeClient,
+		namespace:          namespace,
+		configMapLister:    configMapInformer.Lister(),
+		configMapInformer:  configMapInformer.Informer(),
+		deploymentLister:   deploymentInformer.Lister(),
+		deploymentInformer: deploymentInformer.Informer(),
+		serviceLister:      serviceInformer.Lister(),
+		serviceInformer:    serviceInformer.Informer(),
+		workQueue: workqueue.NewTypedRateLimitingQueueWithConfig(workqueue.DefaultTypedControllerRateLimiter[string](),
+			workqueue.TypedRateLimitingQueueConfig[string]{Name: "TenantRouters"}),
+	}
+
+	// The tenants ConfigMap, the shared router, or a tenant router changed or deleted by someone else reconciles the
+	// tenant routers.
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.enqueue,
+		UpdateFunc: func(_, obj any) { c.enqueue(obj) },
+		DeleteFunc: c.enqueue,
+	}
+	for _, informer := range []cache.SharedIndexInformer{c.configMapInformer, c.deploymentInformer, c.serviceInformer} {
+		if _, err := informer.AddEventHandler(handler); err != nil {
+			klog.Fatalf("Unable to add tenant router event handler: %v", err)
+		}
+	}
+	return c
+}
+
+func (c *TenantRouterController) Run(ctx context.Context, workers int) {
+	defer utilruntime.HandleCrash()
+	defer c.workQueue.ShutDown()
+
+	c.WarmUp(ctx)
+
+	klog.Info("start tenant router controller")
+	for i := 0; i < workers; i++ {
+		go c.worker(ctx)
+	}
+	<-ctx.Done()
+	klog.Info("shut down tenant router controller")
+}
+
+// WarmUp starts the informers and waits for their caches to sync, without reconciling.
+// Informers are started once, ctx of the first call stops them.
+func (c *TenantRouterController) WarmUp(ctx context.Context) {
+	c.informersOnce.Do(func() {
+		go c.configMapInformer.RunWithContext(ctx)
+		go c.deploymentInformer.RunWithContext(ctx)
+		go c.serviceInformer.RunWithContext(ctx)
+	})
+
+	cache.WaitForCacheSync(ctx.Done(),
+		c.configMapInformer.HasSynced,
+		c.deploymentInformer.HasSynced,
+		c.serviceInformer.HasSynced,
+	)
+}
+
+func (c *TenantRouterController) worker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *TenantRouterController) processNextWorkItem(ctx context.Context) bool {
+	key, quit := c.workQueue.Get()
+	if quit {
+		return false
+	}
+	defer c.workQueue.Done(key)
+
+	if err := c.reconcile(ctx); err != nil {
+		utilruntime.HandleError(fmt.Errorf("sync %q failed with %v", key, err))
+		c.workQueue.AddRateLimited(key)
+		return true
+	}
+	c.workQueue.Forget(key)
+	return true
+}
+
+// enqueue reconciles the tenant routers when the tenants ConfigMap, the shared router or a tenant router changes.
+// All the tenant routers are reconciled together, under the key of the tenants ConfigMap.
+func (c *TenantRouterController) enqueue(obj any) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	object, err := meta.Accessor(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	switch obj.(type) {
+	case *corev1.ConfigMap:
+		if object.GetName() != TenantsConfigMapName {
+			return
+		}
+	default:
+		if object.GetName() != SharedRouterName && object.GetLabels()[TenantRouterLabelKey] == "" {
+			return
+		}
+	}
+	c.workQueue.Add(c.namespace + "/" + TenantsConfigMapName)
+}
+
+// reconcile applies the Deployment and the Service of every tenant of the tenants ConfigMap, and deletes the ones of
+// the tenants which are not listed anymore.
+func (c *TenantRouterController) reconcile(ctx context.Context) error {
+	configMap, err := c.configMapLister.ConfigMaps(c.namespace).Get(TenantsConfigMapName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	var tenants []Tenant
+	if configMap != nil {
+		tenants, err = parseTenants(configMap.Data[TenantsKey])
+		if err != nil {
+			// The tenant routers are left as they are until the ConfigMap is fixed.
+			klog.Errorf("Invalid tenants in ConfigMap %s/%s: %v", c.namespace, TenantsConfigMapName, err)
+			return nil
+		}
+	}
+
+	desired := sets.New[string]()
+	if len(tenants) > 0 {
+		deployment, err := c.deploymentLister.Deployments(c.namespace).Get(SharedRouterName)
+		if err != nil {
+			return fmt.Errorf("failed to get the shared router Deployment: %v", err)
+		}
+		service, err := c.serviceLister.Services(c.namespace).Get(SharedRouterName)
+		if err != nil {
+			return fmt.Errorf("failed to get the shared router Service: %v", err)
+		}
+		for _, tenant := range tenants {
+			desired.Insert(tenant.Name)
+			tenantDeployment := buildDeployment(deployment, configMap, tenant)
+			if err := c.applyDeployment(ctx, tenantDeployment); err != nil {
+				return err
+			}
+			if err := c.applyService(ctx, buildService(service, tenantDeployment, configMap, tenant)); err != nil {
+				return err
+			}
+		}
+	}
+	return c.deleteRemoved(ctx, desired)
+}
+
+// parseTenants parses the tenants of the ConfigMap and checks that their names are unique DNS labels.
+func parseTenants(data string) ([]Tenant, error) {
+	var tenants []Tenant
+	if err := yaml.UnmarshalStrict([]byte(data), &tenants); err != nil {
+		return nil, err
+	}
+	names := sets.New[string]()
+	for _, tenant := range tenants {
+		// The name of the tenant is a suffix of the name of its Deployment and Service.
+		if errs := validation.IsDNS1123Label(SharedRouterName + "-" + tenant.Name); len(errs) > 0 || tenant.Name == "" {
+			return nil, fmt.Errorf("invalid tenant name %q: %s", tenant.Name, strings.Join(errs, ", "))
+		}
+		if names.Has(tenant.Name) {
+			return nil, fmt.Errorf("duplicate tenant %q", tenant.Name)
+		}
+		names.Insert(tenant.Name)
+	}
+	return tenants, nil
+}
+
+// tenantLabels returns the labels of the shared router with the component and the tenant of a tenant router.
+func tenantLabels(shared map[string]string, name string, tenant Tenant) map[string]string {
+	result := make(map[string]string, len(shared)+2)
+	for key, value := range shared {
+		result[key] = value
+	}
+	result[componentLabelKey] = name
+	result[TenantRouterLabelKey] = tenant.Name
+	return result
+}
+
+// objectMeta returns the metadata of a resource of a tenant router, owned by the tenants ConfigMap.
+func objectMeta(shared metav1.ObjectMeta, configMap *corev1.ConfigMap, tenant Tenant, spec any) metav1.ObjectMeta {
+	name := SharedRouterName + "-" + tenant.Name
+	objectLabels := tenantLabels(shared.Labels, name, tenant)
+	objectLabels[RevisionLabelKey] = icUtils.Revision(spec)
+	return metav1.ObjectMeta{
+		Name:            name,
+		Namespace:       shared.Namespace,
+		Labels:          objectLabels,
+		OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(configMap, corev1.SchemeGroupVersion.WithKind("ConfigMap"))},
+	}
+}
+
+// buildDeployment returns the Deployment of a tenant router, the one of the shared router serving the ModelRoutes of
+// the tenant only, with the replicas, profile and resources of the tenant.
+func buildDeployment(shared *appsv1.Deployment, configMap *corev1.ConfigMap, tenant Tenant) *appsv1.Deployment {
+	name := SharedRouterName + "-" + tenant.Name
+	spec := shared.Spec.DeepCopy()
+	spec.Replicas = ptr.To[int32](1)
+	if tenant.Replicas != nil {
+		spec.Replicas = ptr.To(*tenant.Replicas)
+	}
+	// The pods of the tenant router must not be selected by the shared router, nor by the other tenant routers.
+	selector := &metav1.LabelSelector{}
+	if spec.Selector != nil {
+		selector = spec.Selector.DeepCopy()
+	}
+	selector.MatchLabels = tenantLabels(selector.MatchLabels, name, tenant)
+	spec.Selector = selector
+	spec.Template.Labels = tenantLabels(spec.Template.Labels, name, tenant)
+
+	for i := range spec.Template.Spec.Containers {
+		container := &spec.Template.Spec.Containers[i]
+		if container.Name != routerContainerName && len(spec.Template.Spec.Containers) > 1 {
+			continue
+		}
+		container.Args = tenantArgs(container.Args, tenant)
+		if tenant.Resources != nil {
+			container.Resources = *tenant.Resources.DeepCopy()
+		}
+	}
+
+	return &appsv1.Deployment{
+		ObjectMeta: objectMeta(shared.ObjectMeta, configMap, tenant, spec),
+		Spec:       *spec,
+	}
+}
+
+// tenantArgs returns the router flags of a tenant router: the ones of the shared router, without its webhook, serving
+// the ModelRoutes of the tenant with the profile of the tenant.
+func tenantArgs(shared []string, tenant Tenant) []string {
+	dropped := []string{"--enable-webhook", "--model-route-selector"}
+	if tenant.Profile != "" {
+		dropped = append(dropped, profileFlags...)
+	}
+	args := make([]string, 0, len(shared)+3+len(tenant.Args))
+	for _, arg := range shared {
+		flag, _, _ := strings.Cut(arg, "=")
+		if !sets.New(dropped...).Has(flag) {
+			args = append(args, arg)
+		}
+	}
+	selector := tenant.RouteSelector
+	if selector == "" {
+		selector = networking.TenantLabelKey + "=" + tenant.Name
+	}
+	args = append(args, "--enable-webhook=false", "--model-route-selector="+selector)
+	if tenant.Profile != "" {
+		args = append(args, "--profile="+tenant.Profile)
+	}
+	return append(args, tenant.Args...)
+}
+
+// buildService returns the Service of a tenant router, with the ports of the one of the shared router.
+func buildService(shared *corev1.Service, deployment *appsv1.Deployment, configMap *corev1.ConfigMap, tenant Tenant) *corev1.Service {
+	spec := corev1.ServiceSpec{
+		Type:     shared.Spec.Type,
+		Selector: deployment.Spec.Selector.MatchLabels,
+	}
+	if tenant.ServiceType != "" {
+		spec.Type = tenant.ServiceType
+	}
+	for _, port := range shared.Spec.Ports {
+		port.NodePort = 0
+		spec.Ports = append(spec.Ports, port)
+	}
+	return &corev1.Service{
+		ObjectMeta: objectMeta(shared.ObjectMeta, configMap, tenant, spec),
+		Spec:       spec,
+	}
+}
+
+// applyDeployment creates the Deployment of a tenant router, or updates it when its revision or its spec differs, so
+// that the changes made to the Deployment by someone else are reverted.
+func (c *TenantRouterController) applyDeployment(ctx context.Context, deployment *appsv1.Deployment) error {
+	existing, err := c.deploymentLister.Deployments(deployment.Namespace).Get(deployment.Name)
+	switch {
+	case apierrors.IsNotFound(err):
+		_, err = c.kubeClient.AppsV1().Deployments(deployment.Namespace).Create(ctx, deployment, metav1.CreateOptions{})
+	case err == nil && !upToDate(existing.ObjectMeta, deployment.ObjectMeta, existing.Spec, deployment.Spec):
+		deployment.ResourceVersion = existing.ResourceVersion
+		_, err = c.kubeClient.AppsV1().Deployments(deployment.Namespace).Update(ctx, deployment, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply Deployment %s: %v", deployment.Name, err)
+	}
+	return nil
+}
+
+// applyService creates the Service of a tenant router, or updates it when its revision or its spec differs, so that
+// the changes made to the Service by someone else are reverted.
+func (c *TenantRouterController) applyService(ctx context.Context, service *corev1.Service) error {
+	existing, err := c.serviceLister.Services(service.Namespace).Get(service.Name)
+	if err == nil {
+		// The addresses and node ports allocated to the Service are immutable, they are kept by the update.
+		service.Spec.ClusterIP = existing.Spec.ClusterIP
+		service.Spec.ClusterIPs = existing.Spec.ClusterIPs
+		if service.Spec.Type == existing.Spec.Type {
+			for i := range service.Spec.Ports {
+				for _, port := range existing.Spec.Ports {
+					if port.Name == service.Spec.Ports[i].Name {
+						service.Spec.Ports[i].NodePort = port.NodePort
+					}
+				}
+			}
+		}
+	}
+	switch {
+	case apierrors.IsNotFound(err):
+		_, err = c.kubeClient.CoreV1().Services(service.Namespace).Create(ctx, service, metav1.CreateOptions{})
+	case err == nil && !upToDate(existing.ObjectMeta, service.ObjectMeta, existing.Spec, service.Spec):
+		service.ResourceVersion = existing.ResourceVersion
+		_, err = c.kubeClient.CoreV1().Services(service.Namespace).Update(ctx, service, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply Service %s: %v", service.Name, err)
+	}
+	return nil
+}
+
+// upToDate returns whether an object has the revision and the spec of the desired one. The fields the desired spec
+// leaves unset are ignored, as they are set to their defaults by the API server.
+func upToDate(existing, desired metav1.ObjectMeta, existingSpec, desiredSpec any) bool {
+	return existing.Labels[RevisionLabelKey] == desired.Labels[RevisionLabelKey] &&
+		equality.Semantic.DeepDerivative(desiredSpec, existingSpec)
+}
+
+// deleteRemoved deletes the Deployments and the Services of the tenant routers which are not desired anymore.
+func (c *TenantRouterController) deleteRemoved(ctx context.Context, desired sets.Set[string]) error {
+	requirement, err := labels.NewRequirement(TenantRouterLabelKey, selection.Exists, nil)
+	if err != nil {
+		return err
+	}
+	selector := labels.NewSelector().Add(*requirement)
+	deployments, err := c.deploymentLister.Deployments(c.namespace).List(selector)
+	if err != nil {
+		return err
+	}
+	for _, deployment := range deployments {
+		if desired.Has(deployment.Labels[TenantRouterLabelKey]) {
+			continue
+		}
+		err := c.kubeClient.AppsV1().Deployments(c.namespace).Delete(ctx, deployment.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete Deployment %s: %v", deployment.Name, err)
+		}
+		klog.Infof("Deleted the router Deployment %s of removed tenant %s", deployment.Name, deployment.Labels[TenantRouterLabelKey])
+	}
+	services, err := c.serviceLister.Services(c.namespace).List(selector)
+	if err != nil {
+		return err
+	}
+	for _, service := range services {
+		if desired.Has(service.Labels[TenantRouterLabelKey]) {
+			continue
+		}
+		err := c.kubeClient.CoreV1().Services(c.namespace).Delete(ctx, service.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete Service %s: %v", service.Name, err)
+		}
+		klog.Infof("Deleted the router Service %s of removed tenant %s", service.Name, service.Labels[TenantRouterLabelKey])
+	}
+	return nil
+}
@@ -0,0 +1,323 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+
+	"github.com/volcano-sh/kthena/pkg/controller/options"
+)
+
+const namespace = "kthena-system"
+
+func sharedRouter(image string) (*appsv1.Deployment, *corev1.Service) {
+	selector := map[string]string{componentLabelKey: SharedRouterName, "app.kubernetes.io/instance": "kthena"}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: SharedRouterName, Labels: selector},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To[int32](3),
+			Selector: &metav1.LabelSelector{MatchLabels: selector},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: selector},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name:  routerContainerName,
+					Image: image,
+					Args: []string{"--port=8080", "--enable-webhook=true", "--profile=large", "--max-concurrent-requests=8192",
+						"--model-route-selector=!networking.serving.volcano.sh/tenant"},
+				}}},
+			},
+		},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: SharedRouterName, Labels: selector},
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeLoadBalancer,
+			ClusterIP: "10.0.0.1",
+			Selector:  selector,
+			Ports:     []corev1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromInt32(8080), NodePort: 30080}},
+		},
+	}
+	return deployment, service
+}
+
+func tenantsConfigMap(tenants string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: TenantsConfigMapName, UID: "tenants-uid"},
+		Data:       map[string]string{TenantsKey: tenants},
+	}
+}
+
+func newTestController(t *testing.T, objects ...any) (*TenantRouterController, *fake.Clientset) {
+	client := fake.NewClientset()
+	c := NewTenantRouterController(client, namespace, options.InformerOptions{})
+	ctx := context.Background()
+	for _, object := range objects {
+		var err error
+		switch object := object.(type) {
+		case *corev1.ConfigMap:
+			_, err = client.CoreV1().ConfigMaps(namespace).Create(ctx, object, metav1.CreateOptions{})
+		case *appsv1.Deployment:
+			_, err = client.AppsV1().Deployments(namespace).Create(ctx, object, metav1.CreateOptions{})
+		case *corev1.Service:
+			_, err = client.CoreV1().Services(namespace).Create(ctx, object, metav1.CreateOptions{})
+		}
+		require.NoError(t, err)
+	}
+	return c, client
+}
+
+// syncListers replaces the objects of the listers of the controller with the ones of the fake client, as the
+// informers would.
+func syncListers(t *testing.T, c *TenantRouterController, client *fake.Clientset) {
+	ctx := context.Background()
+	configMaps, err := client.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	var objects []any
+	for i := range configMaps.Items {
+		objects = append(objects, &configMaps.Items[i])
+	}
+	require.NoError(t, c.configMapInformer.GetIndexer().Replace(objects, ""))
+	deployments, err := client.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	objects = nil
+	for i := range deployments.Items {
+		objects = append(objects, &deployments.Items[i])
+	}
+	require.NoError(t, c.deploymentInformer.GetIndexer().Replace(objects, ""))
+	services, err := client.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	objects = nil
+	for i := range services.Items {
+		objects = append(objects, &services.Items[i])
+	}
+	require.NoError(t, c.serviceInformer.GetIndexer().Replace(objects, ""))
+}
+
+func reconcile(t *testing.T, c *TenantRouterController, client *fake.Clientset) {
+	syncListers(t, c, client)
+	require.NoError(t, c.reconcile(context.Background()))
+}
+
+func getTenantRouter(t *testing.T, client *fake.Clientset, tenant string) (*appsv1.Deployment, *corev1.Service) {
+	ctx := context.Background()
+	deployment, err := client.AppsV1().Deployments(namespace).Get(ctx, SharedRouterName+"-"+tenant, metav1.GetOptions{})
+	require.NoError(t, err)
+	service, err := client.CoreV1().Services(namespace).Get(ctx, SharedRouterName+"-"+tenant, metav1.GetOptions{})
+	require.NoError(t, err)
+	return deployment, service
+}
+
+func TestTenantRouterController_Create(t *testing.T) {
+	deployment, service := sharedRouter("kthena-router:v1")
+	c, client := newTestController(t, deployment, service, tenantsConfigMap(`
+- name: team-a
+  replicas: 2
+  profile: medium
+  serviceType: ClusterIP
+  resources:
+    limits:
+      memory: 2Gi
+  args: ["--profile-features=queue=false"]
+- name: team-b
+  routeSelector: "team in (b, b-staging)"
+`))
+	reconcile(t, c, client)
+
+	teamA, teamAService := getTenantRouter(t, client, "team-a")
+	assert.Equal(t, int32(2), *teamA.Spec.Replicas)
+	assert.Equal(t, "team-a", teamA.Labels[TenantRouterLabelKey])
+	assert.Equal(t, TenantsConfigMapName, teamA.OwnerReferences[0].Name)
+	// The pods of the tenant router are not selected by the shared router
+	assert.Equal(t, "kthena-router-team-a", teamA.Spec.Selector.MatchLabels[componentLabelKey])
+	assert.Equal(t, teamA.Spec.Selector.MatchLabels, teamA.Spec.Template.Labels)
+	container := teamA.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "kthena-router:v1", container.Image)
+	// The profile of the shared router and its overrides are replaced with the profile of the tenant
+	assert.Equal(t, []string{"--port=8080", "--enable-webhook=false", "--model-route-selector=networking.serving.volcano.sh/tenant=team-a",
+		"--profile=medium", "--profile-features=queue=false"}, container.Args)
+	assert.Equal(t, resource.MustParse("2Gi"), container.Resources.Limits[corev1.ResourceMemory])
+
+	assert.Equal(t, corev1.ServiceTypeClusterIP, teamAService.Spec.Type)
+	assert.Equal(t, teamA.Spec.Selector.MatchLabels, teamAService.Spec.Selector)
+	require.Len(t, teamAService.Spec.Ports, 1)
+	assert.Equal(t, int32(80), teamAService.Spec.Ports[0].Port)
+	assert.Zero(t, teamAService.Spec.Ports[0].NodePort)
+	assert.Empty(t, teamAService.Spec.ClusterIP)
+
+	teamB, teamBService := getTenantRouter(t, client, "team-b")
+	assert.Equal(t, int32(1), *teamB.Spec.Replicas)
+	// Without a profile, the tenant router inherits the one of the shared router
+	assert.Equal(t, []string{"--port=8080", "--profile=large", "--max-concurrent-requests=8192", "--enable-webhook=false",
+		"--model-route-selector=team in (b, b-staging)"}, teamB.Spec.Template.Spec.Containers[0].Args)
+	assert.Equal(t, corev1.ServiceTypeLoadBalancer, teamBService.Spec.Type)
+
+	// The shared router is left untouched
+	shared, err := client.AppsV1().Deployments(namespace).Get(context.Background(), SharedRouterName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, deployment.Spec, shared.Spec)
+}
+
+func TestTenantRouterController_Update(t *testing.T) {
+	deployment, service := sharedRouter("kthena-router:v1")
+	configMap := tenantsConfigMap("- name: team-a\n  serviceType: NodePort\n")
+	c, client := newTestController(t, deployment, service, configMap)
+	reconcile(t, c, client)
+	_, teamAService := getTenantRouter(t, client, "team-a")
+
+	// The addresses allocated to the Service are kept by the updates
+	ctx := context.Background()
+	teamAService.Spec.ClusterIP = "10.0.0.2"
+	teamAService.Spec.Ports[0].NodePort = 31080
+	_, err := client.CoreV1().Services(namespace).Update(ctx, teamAService, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// A reconcile without change doesn't update the tenant router
+	client.ClearActions()
+	reconcile(t, c, client)
+	for _, action := range client.Actions() {
+		assert.NotEqual(t, "update", action.GetVerb(), "unexpected %v", action)
+	}
+
+	// The tenant routers follow the upgrades of the shared router
+	deployment.Spec.Template.Spec.Containers[0].Image = "kthena-router:v2"
+	_, err = client.AppsV1().Deployments(namespace).Update(ctx, deployment, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	reconcile(t, c, client)
+	teamA, _ := getTenantRouter(t, client, "team-a")
+	assert.Equal(t, "kthena-router:v2", teamA.Spec.Template.Spec.Containers[0].Image)
+
+	// and the changes of their tenant
+	configMap.Data[TenantsKey] = "- name: team-a\n  replicas: 4\n  serviceType: NodePort\n  profile: small\n"
+	_, err = client.CoreV1().ConfigMaps(namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	reconcile(t, c, client)
+	teamA, teamAService = getTenantRouter(t, client, "team-a")
+	assert.Equal(t, int32(4), *teamA.Spec.Replicas)
+	assert.Contains(t, teamA.Spec.Template.Spec.Containers[0].Args, "--profile=small")
+	assert.NotContains(t, teamA.Spec.Template.Spec.Containers[0].Args, "--max-concurrent-requests=8192")
+	assert.Equal(t, "10.0.0.2", teamAService.Spec.ClusterIP)
+	assert.Equal(t, int32(31080), teamAService.Spec.Ports[0].NodePort)
+
+	// A tenant router changed by someone else is restored
+	teamA.Spec.Replicas = ptr.To[int32](10)
+	teamA.Labels[RevisionLabelKey] = "edited"
+	_, err = client.AppsV1().Deployments(namespace).Update(ctx, teamA, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	reconcile(t, c, client)
+	teamA, _ = getTenantRouter(t, client, "team-a")
+	assert.Equal(t, int32(4), *teamA.Spec.Replicas)
+
+	// So is a tenant router whose spec is changed without changing its revision
+	teamA.Spec.Replicas = ptr.To[int32](10)
+	teamA.Spec.Template.Spec.Containers[0].Image = "kthena-router:edited"
+	_, err = client.AppsV1().Deployments(namespace).Update(ctx, teamA, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	_, teamAService = getTenantRouter(t, client, "team-a")
+	selector := teamAService.Spec.Selector
+	teamAService.Spec.Selector = map[string]string{"app": "other"}
+	_, err = client.CoreV1().Services(namespace).Update(ctx, teamAService, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	reconcile(t, c, client)
+	teamA, teamAService = getTenantRouter(t, client, "team-a")
+	assert.Equal(t, int32(4), *teamA.Spec.Replicas)
+	assert.Equal(t, "kthena-router:v2", teamA.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, selector, teamAService.Spec.Selector)
+}
+
+func TestTenantRouterController_Delete(t *testing.T) {
+	deployment, service := sharedRouter("kthena-router:v1")
+	configMap := tenantsConfigMap("- name: team-a\n- name: team-b\n")
+	c, client := newTestController(t, deployment, service, configMap)
+	reconcile(t, c, client)
+	getTenantRouter(t, client, "team-a")
+	getTenantRouter(t, client, "team-b")
+
+	assertDeleted := func(tenant string) {
+		ctx := context.Background()
+		_, err := client.AppsV1().Deployments(namespace).Get(ctx, SharedRouterName+"-"+tenant, metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err), "the Deployment of %s should be deleted", tenant)
+		_, err = client.CoreV1().Services(namespace).Get(ctx, SharedRouterName+"-"+tenant, metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err), "the Service of %s should be deleted", tenant)
+	}
+
+	// A tenant removed from the ConfigMap loses its router
+	ctx := context.Background()
+	configMap.Data[TenantsKey] = "- name: team-b\n"
+	_, err := client.CoreV1().ConfigMaps(namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	reconcile(t, c, client)
+	assertDeleted("team-a")
+	getTenantRouter(t, client, "team-b")
+
+	// An invalid ConfigMap leaves the routers as they are
+	configMap.Data[TenantsKey] = "- name: Team_B\n"
+	_, err = client.CoreV1().ConfigMaps(namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	reconcile(t, c, client)
+	getTenantRouter(t, client, "team-b")
+
+	// Without the ConfigMap, no tenant has a router
+	require.NoError(t, client.CoreV1().ConfigMaps(namespace).Delete(ctx, TenantsConfigMapName, metav1.DeleteOptions{}))
+	reconcile(t, c, client)
+	assertDeleted("team-b")
+	_, err = client.AppsV1().Deployments(namespace).Get(ctx, SharedRouterName, metav1.GetOptions{})
+	assert.NoError(t, err, "the shared router is not deleted")
+}
+
+func TestParseTenants(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		expectErr string
+	}{
+		{name: "empty"},
+		{name: "tenants", data: "- name: team-a\n  replicas: 2\n- name: team-b\n"},
+		{name: "missing name", data: "- replicas: 2\n", expectErr: `invalid tenant name ""`},
+		{name: "invalid name", data: "- name: Team_A\n", expectErr: `invalid tenant name "Team_A"`},
+		{name: "duplicate", data: "- name: team-a\n- name: team-a\n", expectErr: `duplicate tenant "team-a"`},
+		{name: "unknown field", data: "- name: team-a\n  replica: 2\n", expectErr: `unknown field "replica"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTenants(tt.data)
+			if tt.expectErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestTenantRouterController_WaitsForSharedRouter(t *testing.T) {
+	c, client := newTestController(t, tenantsConfigMap("- name: team-a\n"))
+	syncListers(t, c, client)
+	assert.Error(t, c.reconcile(context.Background()), "the tenant routers are made from the shared router")
+}
@@ -0,0 +1,115 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tlsconfig
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+// DefaultReloadInterval is the interval the certificate files are checked for changes at.
+const DefaultReloadInterval = 10 * time.Second
+
+// CertReloader serves a certificate loaded from files and reloads it when the files change, e.g. when the
+// Secret they are mounted from is rotated, so that listeners pick up new certificates without restarting.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+
+	lock     sync.RWMutex
+	cert     *tls.Certificate
+	certPEM  []byte
+	keyPEM   []byte
+	interval time.Duration
+}
+
+// NewCertReloader loads the certificate and key files. It fails if they can't be loaded.
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile, interval: DefaultReloadInterval}
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Run checks the certificate files for changes until ctx is done.
+func (r *CertReloader) Run(ctx context.Context) {
+	wait.UntilWithContext(ctx, func(context.Context) {
+		reloaded, err := r.reload()
+		if err != nil {
+			// Files may be caught in the middle of an update, keep serving the current certificate.
+			klog.Errorf("Failed to reload certificate %s, keeping the current one: %v", r.certFile, err)
+			return
+		}
+		if reloaded {
+			klog.Infof("Reloaded certificate %s", r.certFile)
+		}
+	}, r.interval)
+}
+
+// reload loads the certificate if the files changed. It reports whether a new certificate was loaded.
+func (r *CertReloader) reload() (bool, error) {
+	certPEM, err := os.ReadFile(r.certFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to read certificate: %w", err)
+	}
+	keyPEM, err := os.ReadFile(r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to read key: %w", err)
+	}
+
+	r.lock.RLock()
+	unchanged := bytes.Equal(certPEM, r.certPEM) && bytes.Equal(keyPEM, r.keyPEM)
+	r.lock.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return false, fmt.Errorf("failed to load key pair: %w", err)
+	}
+	r.lock.Lock()
+	r.cert, r.certPEM, r.keyPEM = &cert, certPEM, keyPEM
+	r.lock.Unlock()
+	return true, nil
+}
+
+// GetCertificate returns the current certificate, it is meant for tls.Config.GetCertificate.
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.cert, nil
+}
+
+// ServerConfig returns a copy of config serving the certificate of the reloader.
+func (r *CertReloader) ServerConfig(config *tls.Config) *tls.Config {
+	config = config.Clone()
+	if config == nil {
+		config = &tls.Config{}
+	}
+	config.GetCertificate = r.GetCertificate
+	return config
+}
@@ -0,0 +1,89 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/volcano-sh/kthena/pkg/webhook/cert"
+)
+
+func writeKeyPair(t *testing.T, dir string) *cert.CertBundle {
+	bundle, err := cert.GenerateSelfSignedCertificate([]string{"kthena.example.com"})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tls.crt"), bundle.CertPEM, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tls.key"), bundle.KeyPEM, 0600))
+	return bundle
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+
+	_, err := NewCertReloader(certFile, keyFile)
+	assert.Error(t, err)
+
+	first := writeKeyPair(t, dir)
+	reloader, err := NewCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+	current, err := reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	expected, err := tls.X509KeyPair(first.CertPEM, first.KeyPEM)
+	require.NoError(t, err)
+	assert.Equal(t, expected.Certificate, current.Certificate)
+
+	reloaded, err := reloader.reload()
+	require.NoError(t, err)
+	assert.False(t, reloaded)
+
+	// The certificate is rotated
+	second := writeKeyPair(t, dir)
+	reloaded, err = reloader.reload()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	current, _ = reloader.GetCertificate(nil)
+	expected, err = tls.X509KeyPair(second.CertPEM, second.KeyPEM)
+	require.NoError(t, err)
+	assert.Equal(t, expected.Certificate, current.Certificate)
+
+	// A key not matching the certificate is not loaded
+	require.NoError(t, os.WriteFile(keyFile, first.KeyPEM, 0600))
+	_, err = reloader.reload()
+	assert.Error(t, err)
+	current, _ = reloader.GetCertificate(nil)
+	assert.Equal(t, expected.Certificate, current.Certificate)
+}
+
+func TestServerConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeKeyPair(t, dir)
+	reloader, err := NewCertReloader(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"))
+	require.NoError(t, err)
+
+	base := &tls.Config{MinVersion: tls.VersionTLS13}
+	config := reloader.ServerConfig(base)
+	assert.Equal(t, uint16(tls.VersionTLS13), config.MinVersion)
+	assert.NotNil(t, config.GetCertificate)
+	assert.Nil(t, base.GetCertificate)
+	assert.NotNil(t, reloader.ServerConfig(nil).GetCertificate)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tlsconfig builds the TLS configuration shared by the listeners of the kthena components.
+package tlsconfig
+
+import (
+	"crypto/fips140"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultMinVersion is the minimum TLS version accepted by default.
+const DefaultMinVersion = "1.2"
+
+var versions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// fipsCipherSuites are the TLS 1.2 cipher suites approved for FIPS 140-3.
+var fipsCipherSuites = map[uint16]bool{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256: true,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384: true,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256:   true,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384:   true,
+}
+
+// FIPSEnabled reports whether the binary runs in FIPS 140-3 mode, i.e. it is built with GOFIPS140 or run
+// with GODEBUG=fips140=on. In this mode, crypto/tls only negotiates FIPS approved algorithms.
+func FIPSEnabled() bool {
+	return fips140.Enabled()
+}
+
+// New returns the TLS configuration of a listener accepting minVersion ("1.2" or "1.3") and above, and the
+// TLS 1.2 cipher suites named by cipherSuites, e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256. An empty minVersion
+// defaults to DefaultMinVersion, empty cipherSuites to the Go defaults. TLS 1.3 cipher suites are not configurable.
+// In FIPS mode, cipher suites which are not FIPS approved are rejected.
+func New(minVersion string, cipherSuites []string) (*tls.Config, error) {
+	if minVersion == "" {
+		minVersion = DefaultMinVersion
+	}
+	version, ok := versions[minVersion]
+	if !ok {
+		return nil, fmt.Errorf("unsupported TLS version %q, supported versions are %s", minVersion, strings.Join(supportedVersions(), ", "))
+	}
+	config := &tls.Config{MinVersion: version}
+
+	for _, name := range cipherSuites {
+		id, err := cipherSuiteID(name)
+		if err != nil {
+			return nil, err
+		}
+		if FIPSEnabled() && !fipsCipherSuites[id] {
+			return nil, fmt.Errorf("cipher suite %s is not FIPS approved", name)
+		}
+		config.CipherSuites = append(config.CipherSuites, id)
+	}
+	return config, nil
+}
+
+// WithClientCA returns a copy of config verifying the client certificates presented to the listener against the CA
+// certificates of the PEM file caFile. The clients without a certificate are still accepted.
+func WithClientCA(config *tls.Config, caFile string) (*tls.Config, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the client CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no CA certificate found in %s", caFile)
+	}
+	config = config.Clone()
+	if config == nil {
+		config = &tls.Config{}
+	}
+	config.ClientCAs = pool
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+	return config, nil
+}
+
+// cipherSuiteID returns the id of a secure TLS 1.2 cipher suite. Insecure cipher suites are rejected.
+func cipherSuiteID(name string) (uint16, error) {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name != name {
+			continue
+		}
+		for _, v := range suite.SupportedVersions {
+			if v == tls.VersionTLS12 {
+				return suite.ID, nil
+			}
+		}
+		return 0, fmt.Errorf("cipher suite %s is not configurable, only TLS 1.2 cipher suites are", name)
+	}
+	for _, suite := range tls.InsecureCipherSuites() {
+		if suite.Name == name {
+			return 0, fmt.Errorf("cipher suite %s is insecure", name)
+		}
+	}
+	return 0, fmt.Errorf("unknown cipher suite %s", name)
+}
+
+func supportedVersions() []string {
+	names := make([]string, 0, len(versions))
+	for name := range versions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
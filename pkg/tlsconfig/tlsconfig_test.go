@@ -0,0 +1,116 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name                 string
+		minVersion           string
+		cipherSuites         []string
+		expectedMinVersion   uint16
+		expectedCipherSuites []uint16
+		expectError          bool
+	}{
+		{
+			name:               "defaults",
+			expectedMinVersion: tls.VersionTLS12,
+		},
+		{
+			name:               "TLS 1.3",
+			minVersion:         "1.3",
+			expectedMinVersion: tls.VersionTLS13,
+		},
+		{
+			name:                 "cipher suites",
+			cipherSuites:         []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384", "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"},
+			expectedMinVersion:   tls.VersionTLS12,
+			expectedCipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384, tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+		},
+		{
+			name:        "unsupported version",
+			minVersion:  "1.1",
+			expectError: true,
+		},
+		{
+			name:         "insecure cipher suite",
+			cipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"},
+			expectError:  true,
+		},
+		{
+			name:         "TLS 1.3 cipher suite",
+			cipherSuites: []string{"TLS_AES_128_GCM_SHA256"},
+			expectError:  true,
+		},
+		{
+			name:         "unknown cipher suite",
+			cipherSuites: []string{"TLS_UNKNOWN"},
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := New(tt.minVersion, tt.cipherSuites)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedMinVersion, config.MinVersion)
+			assert.Equal(t, tt.expectedCipherSuites, config.CipherSuites)
+		})
+	}
+}
+
+func TestNew_FIPS(t *testing.T) {
+	if !FIPSEnabled() {
+		t.Skip("FIPS 140-3 mode is disabled, run with GODEBUG=fips140=on")
+	}
+	_, err := New("", []string{"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256"})
+	assert.Error(t, err)
+	_, err = New("", []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"})
+	assert.NoError(t, err)
+}
+
+func TestWithClientCA(t *testing.T) {
+	dir := t.TempDir()
+	bundle := writeKeyPair(t, dir)
+
+	base := &tls.Config{MinVersion: tls.VersionTLS13}
+	config, err := WithClientCA(base, filepath.Join(dir, "tls.crt"))
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), config.MinVersion)
+	assert.Equal(t, tls.VerifyClientCertIfGiven, config.ClientAuth)
+	assert.NotNil(t, config.ClientCAs)
+	assert.Nil(t, base.ClientCAs)
+
+	_, err = WithClientCA(nil, filepath.Join(dir, "missing.crt"))
+	assert.Error(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ca.crt"), bundle.KeyPEM, 0600))
+	_, err = WithClientCA(nil, filepath.Join(dir, "ca.crt"))
+	assert.Error(t, err)
+}
@@ -17,8 +17,8 @@ limitations under the License.
 package controller_manager
 
 import (
+	"context"
 	"testing"
-	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -42,15 +42,14 @@ func TestModelCR(t *testing.T) {
 	assert.NotNil(t, createdModel)
 	t.Logf("Created Model CR: %s/%s", createdModel.Namespace, createdModel.Name)
 	// Wait for the Model to be Active
-	require.Eventually(t, func() bool {
-		model, err := kthenaClient.WorkloadV1alpha1().ModelBoosters(testNamespace).Get(ctx, model.Name, metav1.GetOptions{})
-		if err != nil {
-			t.Logf("Get model error: %v", err)
-			return false
-		}
-		return true == meta.IsStatusConditionPresentAndEqual(model.Status.Conditions,
-			string(workload.ModelStatusConditionTypeActive), metav1.ConditionTrue)
-	}, 5*time.Minute, 5*time.Second, "Model did not become Active")
+	waitCtx, cancel := context.WithTimeout(ctx, utils.DefaultWaitTimeout)
+	defer cancel()
+	lw := utils.ListWatch(kthenaClient.WorkloadV1alpha1().RESTClient(), "modelboosters", testNamespace, utils.ByName(model.Name))
+	_, err = utils.WaitForCondition(waitCtx, lw, func(model *workload.ModelBooster) (bool, error) {
+		return meta.IsStatusConditionPresentAndEqual(model.Status.Conditions,
+			string(workload.ModelStatusConditionTypeActive), metav1.ConditionTrue), nil
+	})
+	require.NoError(t, err, "Model did not become Active")
 	// Test chat via port-forward
 	messages := []utils.ChatMessage{
 		utils.NewChatMessage("user", "Where is the capital of China?"),
@@ -27,8 +27,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
@@ -247,30 +247,26 @@ func TestModelServingServiceRecovery(t *testing.T) {
 	require.NoError(t, err, "Failed to delete headless Service")
 
 	// Wait for a new headless Service with same owner but different UID to appear
-	require.Eventually(t, func() bool {
-		serviceList, err := kubeClient.CoreV1().Services(testNamespace).List(ctx, metav1.ListOptions{
-			LabelSelector: labelSelector,
-		})
-		if err != nil {
-			return false
-		}
-		for _, svc := range serviceList.Items {
-			// Check if service is owned by the same ModelServing
-			ownedByMS := false
-			for _, ref := range svc.OwnerReferences {
-				if ref.UID == ms.UID {
-					ownedByMS = true
-					break
-				}
-			}
-			// Return true if it's a new service (different UID) owned by the ModelServing and is headless
-			if ownedByMS && string(svc.UID) != originalServiceUID && svc.Spec.ClusterIP == corev1.ClusterIPNone {
-				t.Logf("New Service created: %s (UID: %s)", svc.Name, svc.UID)
-				return true
+	waitCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	lw := utils.ListWatch(kubeClient.CoreV1().RESTClient(), "services", testNamespace, utils.ByLabels(labelSelector))
+	_, err = utils.WaitForCondition(waitCtx, lw, func(svc *corev1.Service) (bool, error) {
+		// Check if service is owned by the same ModelServing
+		ownedByMS := false
+		for _, ref := range svc.OwnerReferences {
+			if ref.UID == ms.UID {
+				ownedByMS = true
+				break
 			}
 		}
-		return false
-	}, 2*time.Minute, 5*time.Second, "Headless Service owned by ModelServing was not recreated after deletion")
+		// Return true if it's a new service (different UID) owned by the ModelServing and is headless
+		if ownedByMS && string(svc.UID) != originalServiceUID && svc.Spec.ClusterIP == corev1.ClusterIPNone {
+			t.Logf("New Service created: %s (UID: %s)", svc.Name, svc.UID)
+			return true, nil
+		}
+		return false, nil
+	})
+	require.NoError(t, err, "Headless Service owned by ModelServing was not recreated after deletion")
 
 	// Verify ModelServing is still ready
 	utils.WaitForModelServingReady(t, ctx, kthenaClient, testNamespace, modelServing.Name)
@@ -320,60 +316,52 @@ func TestModelServingWithDuplicateHostAliases(t *testing.T) {
 
 	// Verify that pods are created and running with the correct hostAliases
 	labelSelector := "modelserving.volcano.sh/name=" + modelServing.Name
-	require.Eventually(t, func() bool {
-		podList, err := kubeClient.CoreV1().Pods(testNamespace).List(ctx, metav1.ListOptions{
-			LabelSelector: labelSelector,
-		})
-		if err != nil {
-			return false
+	waitCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	lw := utils.ListWatch(kubeClient.CoreV1().RESTClient(), "pods", testNamespace, utils.ByLabels(labelSelector))
+	_, err = utils.WaitForCondition(waitCtx, lw, func(pod *corev1.Pod) (bool, error) {
+		// Check if pod is running
+		if pod.Status.Phase != corev1.PodRunning {
+			return false, nil
+		}
+		// Verify that hostAliases contains entries with duplicate IPs
+		hostAliases := pod.Spec.HostAliases
+		hasDuplicateIP := false
+
+		ipCount := make(map[string]int)
+		for _, alias := range hostAliases {
+			ipCount[alias.IP]++
+			if ipCount[alias.IP] > 1 {
+				hasDuplicateIP = true
+				break
+			}
 		}
 
-		// Check that we have at least one pod and it has the expected hostAliases
-		for _, pod := range podList.Items {
-			// Check if pod is running
-			if pod.Status.Phase == corev1.PodRunning {
-				// Verify that hostAliases contains entries with duplicate IPs
-				hostAliases := pod.Spec.HostAliases
-				hasDuplicateIP := false
-
-				ipCount := make(map[string]int)
-				for _, alias := range hostAliases {
-					ipCount[alias.IP]++
-					if ipCount[alias.IP] > 1 {
-						hasDuplicateIP = true
-						break
-					}
-				}
-
-				// Also check if we have the expected hostnames
-				expectedHostnames := map[string]bool{
-					"test.com":    true,
-					"example.com": true,
-					"test.org":    true,
-				}
-
-				foundHostnames := make(map[string]bool)
-				for _, alias := range hostAliases {
-					for _, hostname := range alias.Hostnames {
-						foundHostnames[hostname] = true
-					}
-				}
+		// Also check if we have the expected hostnames
+		expectedHostnames := map[string]bool{
+			"test.com":    true,
+			"example.com": true,
+			"test.org":    true,
+		}
 
-				allExpectedFound := true
-				for expected := range expectedHostnames {
-					if !foundHostnames[expected] {
-						allExpectedFound = false
-						break
-					}
-				}
+		foundHostnames := make(map[string]bool)
+		for _, alias := range hostAliases {
+			for _, hostname := range alias.Hostnames {
+				foundHostnames[hostname] = true
+			}
+		}
 
-				if hasDuplicateIP && allExpectedFound {
-					return true
-				}
+		allExpectedFound := true
+		for expected := range expectedHostnames {
+			if !foundHostnames[expected] {
+				allExpectedFound = false
+				break
 			}
 		}
-		return false
-	}, 2*time.Minute, 5*time.Second, "Pods were not created with duplicate IP hostAliases or did not reach running state")
+
+		return hasDuplicateIP && allExpectedFound, nil
+	})
+	require.NoError(t, err, "Pods were not created with duplicate IP hostAliases or did not reach running state")
 
 	t.Log("ModelServing with duplicate IP hostAliases test passed successfully")
 }
@@ -575,26 +563,8 @@ func TestModelServingControllerManagerRestart(t *testing.T) {
 
 	// Wait for controller-manager pods to restart and become ready
 	t.Log("Waiting for controller-manager to restart...")
-	require.Eventually(t, func() bool {
-		pods, err := kubeClient.CoreV1().Pods(kthenaNamespace).List(ctx, metav1.ListOptions{
-			LabelSelector: labelSelector,
-		})
-		if err != nil {
-			return false
-		}
-		// Check that at least one controller-manager pod is running and ready
-		for _, pod := range pods.Items {
-			if pod.Status.Phase == corev1.PodRunning {
-				for _, condition := range pod.Status.Conditions {
-					if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
-						t.Logf("Controller-manager pod is ready: %s", pod.Name)
-						return true
-					}
-				}
-			}
-		}
-		return false
-	}, 3*time.Minute, 5*time.Second, "Controller-manager did not restart and become ready")
+	pod := utils.WaitForPodReady(t, ctx, kubeClient, kthenaNamespace, labelSelector)
+	t.Logf("Controller-manager pod is ready: %s", pod.Name)
 
 	// Wait for ModelServing to be ready
 	t.Log("Waiting for ModelServing to be ready after controller-manager restart...")
@@ -666,34 +636,20 @@ func TestModelServingRoleStatusEvents(t *testing.T) {
 	// We expect at least one Creating event and one Running event for the role.
 	var sawCreatingEvent, sawRunningEvent bool
 
-	require.Eventually(t, func() bool {
-		eventList, err := kubeClient.CoreV1().Events(testNamespace).List(ctx, metav1.ListOptions{})
-		if err != nil {
-			return false
-		}
-
-		for _, ev := range eventList.Items {
-			if ev.InvolvedObject.Kind != "ModelServing" {
-				continue
-			}
-			if ev.InvolvedObject.UID != ms.UID {
-				continue
-			}
-
-			switch ev.Reason {
-			case "RoleCreating":
-				sawCreatingEvent = true
-			case "RoleRunning":
-				sawRunningEvent = true
-			}
-
-			if sawCreatingEvent && sawRunningEvent {
-				return true
-			}
+	waitCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	lw := utils.ListWatch(kubeClient.CoreV1().RESTClient(), "events", testNamespace,
+		utils.ByFields(fields.SelectorFromSet(fields.Set{"involvedObject.kind": "ModelServing", "involvedObject.uid": string(ms.UID)}).String()))
+	_, err = utils.WaitForCondition(waitCtx, lw, func(ev *corev1.Event) (bool, error) {
+		switch ev.Reason {
+		case "RoleCreating":
+			sawCreatingEvent = true
+		case "RoleRunning":
+			sawRunningEvent = true
 		}
-
-		return false
-	}, 2*time.Minute, 5*time.Second, "Did not observe both RoleCreating and RoleRunning events for ModelServing role")
+		return sawCreatingEvent && sawRunningEvent, nil
+	})
+	require.NoError(t, err, "Did not observe both RoleCreating and RoleRunning events for ModelServing role")
 
 	t.Log("ModelServing role status events test passed successfully")
 }
@@ -1018,15 +974,12 @@ func TestLWSAPIBasic(t *testing.T) {
 
 	// Wait for ModelServing to be created
 	t.Log("Waiting for ModelServing resource to be created")
-	var modelServing *workload.ModelServing
-	require.Eventually(t, func() bool {
-		ms, err := kthenaClient.WorkloadV1alpha1().ModelServings(testNamespace).Get(ctx, lwsName, metav1.GetOptions{})
-		if err != nil {
-			return false
-		}
-		modelServing = ms
-		return true
-	}, 2*time.Minute, 2*time.Second, "ModelServing was not created")
+	waitCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	modelServing, err := utils.WaitForCondition(waitCtx,
+		utils.ListWatch(kthenaClient.WorkloadV1alpha1().RESTClient(), "modelservings", testNamespace, utils.ByName(lwsName)),
+		utils.Exists[*workload.ModelServing])
+	require.NoError(t, err, "ModelServing was not created")
 
 	// Verify owner reference
 	t.Log("Verifying ModelServing owner reference")
@@ -1075,22 +1028,17 @@ func TestLWSAPIBasic(t *testing.T) {
 
 	// Wait for ModelServing to be deleted (via owner reference cascade deletion)
 	t.Log("Waiting for ModelServing to be deleted")
-	require.Eventually(t, func() bool {
-		_, err := kthenaClient.WorkloadV1alpha1().ModelServings(testNamespace).Get(ctx, lwsName, metav1.GetOptions{})
-		return errors.IsNotFound(err)
-	}, 2*time.Minute, 2*time.Second, "ModelServing was not deleted after LWS deletion")
+	deleteCtx, cancelDelete := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancelDelete()
+	err = utils.WaitForDeletion[*workload.ModelServing](deleteCtx,
+		utils.ListWatch(kthenaClient.WorkloadV1alpha1().RESTClient(), "modelservings", testNamespace, utils.ByName(lwsName)))
+	require.NoError(t, err, "ModelServing was not deleted after LWS deletion")
 
 	// Wait for all pods to be deleted
 	t.Log("Waiting for all pods to be deleted")
-	require.Eventually(t, func() bool {
-		podList, err := kubeClient.CoreV1().Pods(testNamespace).List(ctx, metav1.ListOptions{
-			LabelSelector: labelSelector,
-		})
-		if err != nil {
-			return false
-		}
-		return len(podList.Items) == 0
-	}, 2*time.Minute, 2*time.Second, "Pods were not deleted after LWS deletion")
+	err = utils.WaitForDeletion[*corev1.Pod](deleteCtx,
+		utils.ListWatch(kubeClient.CoreV1().RESTClient(), "pods", testNamespace, utils.ByLabels(labelSelector)))
+	require.NoError(t, err, "Pods were not deleted after LWS deletion")
 
 	t.Log("LWS API basic test passed successfully")
 }
@@ -29,7 +29,6 @@ import (
 	"github.com/volcano-sh/kthena/test/e2e/utils"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -93,13 +92,7 @@ func TestMain(m *testing.M) {
 	// Wait for namespace to be deleted
 	waitCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
 	defer cancel()
-	err = wait.PollUntilContextCancel(waitCtx, 2*time.Second, true, func(ctx context.Context) (bool, error) {
-		_, err := kubeClient.CoreV1().Namespaces().Get(ctx, testNamespace, metav1.GetOptions{})
-		if err != nil {
-			return true, nil // namespace is gone
-		}
-		return false, nil
-	})
+	err = utils.WaitForDeletion[*corev1.Namespace](waitCtx, utils.ListWatch(kubeClient.CoreV1().RESTClient(), "namespaces", "", utils.ByName(testNamespace)))
 	if err != nil {
 		fmt.Printf("Timeout waiting for namespace %s deletion: %v\n", testNamespace, err)
 	}
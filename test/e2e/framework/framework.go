@@ -17,50 +17,121 @@ limitations under the License.
 package framework
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/volcano-sh/kthena/test/e2e/utils"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayclientset "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
 )
 
 var (
 	pfForwarder utils.PortForwarder
 )
 
-// KthenaConfig holds the configuration for installing kthena
+// redisManifest deploys the Redis server used as the global rate limiting store.
+const redisManifest = "examples/redis/redis-standalone.yaml"
+
+// KthenaConfig holds the configuration for installing kthena.
+// The optional features are toggled independently, so that each test package only installs what it tests.
 type KthenaConfig struct {
 	Namespace                 string
 	WorkloadEnabled           bool
 	NetworkingEnabled         bool
 	GatewayAPIEnabled         bool
 	InferenceExtensionEnabled bool
-	ImageTag                  string
-	ChartPath                 string
+	// AutoscalerEnabled runs the autoscaler controller in the controller manager.
+	AutoscalerEnabled bool
+	// RedisEnabled deploys Redis in the kthena namespace before installing kthena, which makes the router
+	// use it as the global rate limiting store.
+	RedisEnabled bool
+	// FairnessEnabled enables the fairness scheduling of the router.
+	FairnessEnabled bool
+	// Values are additional Helm values, e.g. {"networking.kthenaRouter.resume.enabled": "true"}, to toggle
+	// the features without a dedicated field.
+	Values    map[string]string
+	ImageTag  string
+	ChartPath string
 }
 
 // NewDefaultConfig returns a default configuration for kthena installation
 // consistent with charts/kthena/values.yaml
 func NewDefaultConfig() *KthenaConfig {
-	_, filename, _, _ := runtime.Caller(0)
-	// filename is /.../test/e2e/framework/framework.go
-	dir := filepath.Dir(filename)
-	// dir is /.../test/e2e/framework
-	chartPath := filepath.Join(dir, "../../../charts/kthena")
-
 	return &KthenaConfig{
 		Namespace:                 "dev",
 		WorkloadEnabled:           true,
 		NetworkingEnabled:         true,
 		GatewayAPIEnabled:         false,
 		InferenceExtensionEnabled: false,
+		AutoscalerEnabled:         true,
+		RedisEnabled:              false,
+		FairnessEnabled:           false,
 		ImageTag:                  os.Getenv("TAG"),
-		ChartPath:                 chartPath,
+		ChartPath:                 filepath.Join(projectRoot(), "charts/kthena"),
+	}
+}
+
+// projectRoot returns the root directory of the repository.
+func projectRoot() string {
+	_, filename, _, _ := runtime.Caller(0)
+	// filename is /.../test/e2e/framework/framework.go
+	return filepath.Join(filepath.Dir(filename), "../../..")
+}
+
+// helmValues returns the Helm values set by the configuration.
+func (cfg *KthenaConfig) helmValues() []string {
+	values := []string{
+		fmt.Sprintf("workload.enabled=%v", cfg.WorkloadEnabled),
+		fmt.Sprintf("networking.enabled=%v", cfg.NetworkingEnabled),
+		fmt.Sprintf("networking.kthenaRouter.gatewayAPI.enabled=%v", cfg.GatewayAPIEnabled),
+		fmt.Sprintf("networking.kthenaRouter.gatewayAPI.inferenceExtension=%v", cfg.InferenceExtensionEnabled),
+		fmt.Sprintf("networking.kthenaRouter.fairness.enabled=%v", cfg.FairnessEnabled),
+		fmt.Sprintf("networking.kthenaRouter.image.tag=%s", cfg.ImageTag),
+		fmt.Sprintf("networking.webhook.image.tag=%s", cfg.ImageTag),
+		fmt.Sprintf("workload.controllerManager.image.tag=%s", cfg.ImageTag),
+		fmt.Sprintf("workload.controllerManager.downloaderImage.tag=%s", cfg.ImageTag),
+		fmt.Sprintf("workload.controllerManager.runtimeImage.tag=%s", cfg.ImageTag),
+	}
+	if !cfg.AutoscalerEnabled {
+		// Commas separate the values of --set, they are escaped to set a comma-separated list.
+		values = append(values, `workload.controllerManager.controllers=*\,-autoscaler`)
+	}
+
+	keys := make([]string, 0, len(cfg.Values))
+	for key := range cfg.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values = append(values, fmt.Sprintf("%s=%s", key, cfg.Values[key]))
 	}
+	return values
+}
+
+// installRedis deploys Redis in the kthena namespace and waits for it to be available.
+func installRedis(namespace string) error {
+	fmt.Printf("Deploying Redis in namespace %s...\n", namespace)
+	// The namespace must exist before the chart is installed for the router to find the Redis configuration.
+	_ = exec.Command("kubectl", "create", "namespace", namespace).Run()
+	for _, args := range [][]string{
+		{"apply", "-n", namespace, "-f", filepath.Join(projectRoot(), redisManifest)},
+		{"rollout", "status", "deployment/redis-server", "-n", namespace, "--timeout=300s"},
+	} {
+		cmd := exec.Command("kubectl", args...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to deploy redis: %v", err)
+		}
+	}
+	return nil
 }
 
 // InstallKthena installs kthena via helm
@@ -69,19 +140,19 @@ func InstallKthena(cfg *KthenaConfig) error {
 		cfg.ImageTag = "latest"
 	}
 
+	if cfg.RedisEnabled {
+		if err := installRedis(cfg.Namespace); err != nil {
+			return err
+		}
+	}
+
 	args := []string{
 		"install", "kthena", cfg.ChartPath,
 		"--namespace", cfg.Namespace,
 		"--create-namespace",
-		"--set", fmt.Sprintf("workload.enabled=%v", cfg.WorkloadEnabled),
-		"--set", fmt.Sprintf("networking.enabled=%v", cfg.NetworkingEnabled),
-		"--set", fmt.Sprintf("networking.kthenaRouter.gatewayAPI.enabled=%v", cfg.GatewayAPIEnabled),
-		"--set", fmt.Sprintf("networking.kthenaRouter.gatewayAPI.inferenceExtension=%v", cfg.InferenceExtensionEnabled),
-		"--set", fmt.Sprintf("networking.kthenaRouter.image.tag=%s", cfg.ImageTag),
-		"--set", fmt.Sprintf("networking.webhook.image.tag=%s", cfg.ImageTag),
-		"--set", fmt.Sprintf("workload.controllerManager.image.tag=%s", cfg.ImageTag),
-		"--set", fmt.Sprintf("workload.controllerManager.downloaderImage.tag=%s", cfg.ImageTag),
-		"--set", fmt.Sprintf("workload.controllerManager.runtimeImage.tag=%s", cfg.ImageTag),
+	}
+	for _, value := range cfg.helmValues() {
+		args = append(args, "--set", value)
 	}
 
 	cmd := exec.Command("helm", args...)
@@ -104,20 +175,21 @@ func InstallKthena(cfg *KthenaConfig) error {
 	// Wait for auto-generated Gateway if Gateway API is enabled
 	if cfg.GatewayAPIEnabled {
 		fmt.Printf("Waiting for auto-generated Gateway 'default' in '%s' namespace...\n", cfg.Namespace)
-		// We use a simple loop with timeout as Gateway API resources might take a moment to be created by the controller
-		start := time.Now()
-		timeout := 2 * time.Minute
-		for {
-			cmd := exec.Command("kubectl", "get", "gateway", "default", "-n", cfg.Namespace)
-			if err := cmd.Run(); err == nil {
-				fmt.Println("Gateway 'default' is ready")
-				break
-			}
-			if time.Since(start) > timeout {
-				return fmt.Errorf("timeout waiting for auto-generated Gateway 'default' in namespace %s", cfg.Namespace)
-			}
-			time.Sleep(5 * time.Second)
+		config, err := utils.GetKubeConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get kubeconfig: %v", err)
 		}
+		gatewayClient, err := gatewayclientset.NewForConfig(config)
+		if err != nil {
+			return fmt.Errorf("failed to create gateway client: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+		lw := utils.ListWatch(gatewayClient.GatewayV1().RESTClient(), "gateways", cfg.Namespace, utils.ByName("default"))
+		if _, err := utils.WaitForCondition(ctx, lw, utils.Exists[*gatewayv1.Gateway]); err != nil {
+			return fmt.Errorf("timeout waiting for auto-generated Gateway 'default' in namespace %s: %v", cfg.Namespace, err)
+		}
+		fmt.Println("Gateway 'default' is ready")
 	}
 
 	// Setup port-forward to router service if networking is enabled
@@ -151,6 +223,9 @@ func UninstallKthena(namespace string) error {
 	// Ignore error if already uninstalled
 	_ = cmd.Run()
 
+	// Remove Redis if it was deployed
+	_ = exec.Command("kubectl", "delete", "-n", namespace, "-f", filepath.Join(projectRoot(), redisManifest), "--ignore-not-found").Run()
+
 	// Kill any leftover port-forward processes as a fallback
 	_ = exec.Command("pkill", "-f", "kubectl port-forward.*svc/kthena-router").Run()
 
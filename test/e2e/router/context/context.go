@@ -19,16 +19,15 @@ package context
 import (
 	stdcontext "context"
 	"fmt"
-	"time"
 
 	clientset "github.com/volcano-sh/kthena/client-go/clientset/versioned"
 	networkingv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/gatewayapi"
 	"github.com/volcano-sh/kthena/test/e2e/utils"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	inferenceclientset "sigs.k8s.io/gateway-api-inference-extension/client-go/clientset/versioned"
 	gatewayclientset "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
@@ -45,8 +44,8 @@ const (
 type RouterTestContext struct {
 	KubeClient      *kubernetes.Clientset
 	KthenaClient    *clientset.Clientset
-	GatewayClient   *gatewayclientset.Clientset
-	InferenceClient *inferenceclientset.Clientset
+	GatewayClient   gatewayclientset.Interface
+	InferenceClient inferenceclientset.Interface
 	Namespace       string
 }
 
@@ -64,20 +63,16 @@ func NewRouterTestContext(namespace string) (*RouterTestContext, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create kthena client: %w", err)
 	}
-	gatewayClient, err := gatewayclientset.NewForConfig(config)
+	gatewayClients, err := gatewayapi.NewClients(config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Gateway client: %w", err)
-	}
-	inferenceClient, err := inferenceclientset.NewForConfig(config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Inference client: %w", err)
+		return nil, err
 	}
 
 	return &RouterTestContext{
 		KubeClient:      kubeClient,
 		KthenaClient:    kthenaClient,
-		GatewayClient:   gatewayClient,
-		InferenceClient: inferenceClient,
+		GatewayClient:   gatewayClients.Gateway,
+		InferenceClient: gatewayClients.Inference,
 		Namespace:       namespace,
 	}, nil
 }
@@ -134,22 +129,16 @@ func (c *RouterTestContext) SetupCommonComponents() error {
 
 	// Wait for deployments to be ready
 	fmt.Println("Waiting for deployments to be ready...")
-	timeoutCtx, cancel := stdcontext.WithTimeout(ctx, 5*time.Minute)
+	timeoutCtx, cancel := stdcontext.WithTimeout(ctx, utils.DefaultWaitTimeout)
 	defer cancel()
-	err = wait.PollUntilContextTimeout(timeoutCtx, 5*time.Second, 5*time.Minute, true, func(ctx stdcontext.Context) (bool, error) {
-		deploy1_5b, err := c.KubeClient.AppsV1().Deployments(c.Namespace).Get(ctx, Deployment1_5bName, metav1.GetOptions{})
+	for _, name := range []string{Deployment1_5bName, Deployment7bName} {
+		lw := utils.ListWatch(c.KubeClient.AppsV1().RESTClient(), "deployments", c.Namespace, utils.ByName(name))
+		_, err = utils.WaitForCondition(timeoutCtx, lw, func(deployment *appsv1.Deployment) (bool, error) {
+			return utils.IsDeploymentReady(deployment), nil
+		})
 		if err != nil {
-			return false, nil
+			return fmt.Errorf("deployment %s did not become ready: %w", name, err)
 		}
-		deploy7b, err := c.KubeClient.AppsV1().Deployments(c.Namespace).Get(ctx, Deployment7bName, metav1.GetOptions{})
-		if err != nil {
-			return false, nil
-		}
-		return deploy1_5b.Status.ReadyReplicas == *deploy1_5b.Spec.Replicas &&
-			deploy7b.Status.ReadyReplicas == *deploy7b.Spec.Replicas, nil
-	})
-	if err != nil {
-		return fmt.Errorf("deployments did not become ready: %w", err)
 	}
 
 	// Deploy ModelServer DS1.5B
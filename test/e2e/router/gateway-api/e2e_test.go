@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -30,6 +31,7 @@ import (
 	routercontext "github.com/volcano-sh/kthena/test/e2e/router/context"
 	"github.com/volcano-sh/kthena/test/e2e/utils"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
@@ -182,78 +184,10 @@ func TestDuplicateModelName(t *testing.T) {
 		}
 	})
 
-	// 2. Create custom Gateway with port 8081
-	t.Log("Creating custom Gateway with port 8081...")
-	customGateway := utils.LoadYAMLFromFile[gatewayv1.Gateway]("examples/kthena-router/Gateway.yaml")
-	customGateway.Namespace = kthenaNamespace
-	customGateway.Name = "kthena-gateway-custom"
-	customGateway.Spec.Listeners[0].Port = gatewayv1.PortNumber(8081)
-
-	createdGateway, err := testCtx.GatewayClient.GatewayV1().Gateways(kthenaNamespace).Create(ctx, customGateway, metav1.CreateOptions{})
-	require.NoError(t, err, "Failed to create custom Gateway")
-	assert.NotNil(t, createdGateway)
-	t.Logf("Created Gateway: %s/%s", createdGateway.Namespace, createdGateway.Name)
-
-	// Register cleanup for Gateway
-	t.Cleanup(func() {
-		cleanupCtx := context.Background()
-		if err := testCtx.GatewayClient.GatewayV1().Gateways(kthenaNamespace).Delete(cleanupCtx, createdGateway.Name, metav1.DeleteOptions{}); err != nil {
-			t.Logf("Warning: Failed to delete Gateway %s/%s: %v", createdGateway.Namespace, createdGateway.Name, err)
-		}
-	})
-
-	// 3. Update kthena-router Service to add port 8081
-	t.Log("Updating kthena-router Service to add port 8081...")
-	svc, err := testCtx.KubeClient.CoreV1().Services(kthenaNamespace).Get(ctx, "kthena-router", metav1.GetOptions{})
-	require.NoError(t, err, "Failed to get kthena-router Service")
-
-	// Add port 8081 to the Service
-	port8081 := corev1.ServicePort{
-		Name:       "http-8081",
-		Port:       8081,
-		TargetPort: intstr.FromInt(8081),
-		Protocol:   corev1.ProtocolTCP,
-	}
-	svc.Spec.Ports = append(svc.Spec.Ports, port8081)
-
-	_, err = testCtx.KubeClient.CoreV1().Services(kthenaNamespace).Update(ctx, svc, metav1.UpdateOptions{})
-	require.NoError(t, err, "Failed to update kthena-router Service")
-	t.Log("Updated kthena-router Service with port 8081")
-
-	// Register cleanup to restore Service
-	t.Cleanup(func() {
-		cleanupCtx := context.Background()
-		svc, err := testCtx.KubeClient.CoreV1().Services(kthenaNamespace).Get(cleanupCtx, "kthena-router", metav1.GetOptions{})
-		if err != nil {
-			t.Logf("Warning: Failed to get Service for cleanup: %v", err)
-			return
-		}
-		// Remove port 8081
-		var ports []corev1.ServicePort
-		for _, p := range svc.Spec.Ports {
-			if p.Name != "http-8081" {
-				ports = append(ports, p)
-			}
-		}
-		svc.Spec.Ports = ports
-		if _, err := testCtx.KubeClient.CoreV1().Services(kthenaNamespace).Update(cleanupCtx, svc, metav1.UpdateOptions{}); err != nil {
-			t.Logf("Warning: Failed to restore kthena-router Service during cleanup: %v", err)
-		}
-	})
-
-	// 4. Setup port-forward for port 8081
-	t.Log("Setting up port-forward for port 8081...")
-	pf, err := utils.SetupPortForward(kthenaNamespace, "kthena-router", "8081", "8081")
-	require.NoError(t, err, "Failed to setup port-forward for 8081")
+	// 2. Create custom Gateway with port 8081, exposed by the kthena-router Service
+	createdGateway := createExposedGateway(t, ctx, "kthena-gateway-custom", 8081)
 
-	// Register cleanup to kill port-forward
-	t.Cleanup(func() {
-		if pf != nil {
-			pf.Close()
-		}
-	})
-
-	// 5. Create second ModelRoute with same modelName but bound to custom Gateway
+	// 3. Create second ModelRoute with same modelName but bound to custom Gateway
 	t.Log("Creating second ModelRoute with same modelName bound to custom Gateway...")
 	modelRoute2 := modelRoute1.DeepCopy()
 	modelRoute2.Name = "deepseek-simple-custom"
@@ -281,7 +215,7 @@ func TestDuplicateModelName(t *testing.T) {
 		}
 	})
 
-	// 6. Verify access through different ports routes to different models
+	// 4. Verify access through different ports routes to different models
 	t.Log("Verifying access through default Gateway (port 8080) routes to deepseek-r1-1-5b...")
 	messages := []utils.ChatMessage{
 		utils.NewChatMessage("user", "Hello from default Gateway"),
@@ -299,3 +233,146 @@ func TestDuplicateModelName(t *testing.T) {
 
 	t.Log("Test completed successfully: same modelName routes to different models via different ports")
 }
+
+// TestMultipleGatewayParents tests that a ModelRoute attached to several Gateways is routed through each of them, and
+// that its status reports whether each parent accepted it.
+func TestMultipleGatewayParents(t *testing.T) {
+	ctx := context.Background()
+	secondGateway := createExposedGateway(t, ctx, "kthena-gateway-second", 8082)
+
+	// 1. Attach a ModelRoute to the default Gateway and to the second one
+	t.Log("Creating ModelRoute attached to the default and the second Gateway...")
+	ktNamespace := gatewayv1.Namespace(kthenaNamespace)
+	modelRoute := utils.LoadYAMLFromFile[networkingv1alpha1.ModelRoute]("examples/kthena-router/ModelRouteSimple.yaml")
+	modelRoute.Namespace = testNamespace
+	modelRoute.Name = "deepseek-multi-gateway"
+	modelRoute.Spec.ParentRefs = []gatewayv1.ParentReference{
+		{Name: "default", Namespace: &ktNamespace},
+		{Name: gatewayv1.ObjectName(secondGateway.Name), Namespace: &ktNamespace},
+	}
+	createdModelRoute, err := testCtx.KthenaClient.NetworkingV1alpha1().ModelRoutes(testNamespace).Create(ctx, modelRoute, metav1.CreateOptions{})
+	require.NoError(t, err, "Failed to create ModelRoute")
+	t.Cleanup(func() {
+		if err := testCtx.KthenaClient.NetworkingV1alpha1().ModelRoutes(testNamespace).Delete(context.Background(), createdModelRoute.Name, metav1.DeleteOptions{}); err != nil {
+			t.Logf("Warning: Failed to delete ModelRoute %s/%s: %v", createdModelRoute.Namespace, createdModelRoute.Name, err)
+		}
+	})
+
+	// 2. Both parents accept the ModelRoute
+	t.Log("Waiting for both Gateways to accept the ModelRoute...")
+	status := waitForModelRouteParents(t, ctx, createdModelRoute.Name, func(parents []gatewayv1.RouteParentStatus) bool {
+		return len(parents) == 2 &&
+			meta.IsStatusConditionTrue(parents[0].Conditions, string(gatewayv1.RouteConditionAccepted)) &&
+			meta.IsStatusConditionTrue(parents[1].Conditions, string(gatewayv1.RouteConditionAccepted))
+	})
+	for i, parent := range status.Parents {
+		assert.Equal(t, createdModelRoute.Spec.ParentRefs[i], parent.ParentRef)
+		assert.Equal(t, gatewayv1.GatewayController("volcano.sh/kthena-router"), parent.ControllerName)
+		assert.True(t, meta.IsStatusConditionTrue(parent.Conditions, string(gatewayv1.RouteConditionResolvedRefs)),
+			"The ModelServers of the ModelRoute should be resolved")
+	}
+
+	// 3. The ModelRoute is routed through both Gateways
+	messages := []utils.ChatMessage{utils.NewChatMessage("user", "Hello from both Gateways")}
+	for _, port := range []string{"8080", "8082"} {
+		t.Logf("Verifying access through the Gateway of port %s...", port)
+		response := utils.CheckChatCompletionsWithURL(t, "http://127.0.0.1:"+port+"/v1/chat/completions", modelRoute.Spec.ModelName, messages)
+		require.Equal(t, 200, response.StatusCode, "Expected HTTP 200 through the Gateway of port %s", port)
+	}
+
+	// 4. A missing parent is reported separately, the other parents still accept the ModelRoute
+	t.Log("Attaching the ModelRoute to a missing Gateway...")
+	current, err := testCtx.KthenaClient.NetworkingV1alpha1().ModelRoutes(testNamespace).Get(ctx, createdModelRoute.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	current.Spec.ParentRefs = append(current.Spec.ParentRefs, gatewayv1.ParentReference{Name: "missing", Namespace: &ktNamespace})
+	_, err = testCtx.KthenaClient.NetworkingV1alpha1().ModelRoutes(testNamespace).Update(ctx, current, metav1.UpdateOptions{})
+	require.NoError(t, err, "Failed to update ModelRoute")
+
+	status = waitForModelRouteParents(t, ctx, createdModelRoute.Name, func(parents []gatewayv1.RouteParentStatus) bool {
+		return len(parents) == 3
+	})
+	accepted := meta.FindStatusCondition(status.Parents[2].Conditions, string(gatewayv1.RouteConditionAccepted))
+	require.NotNil(t, accepted)
+	assert.Equal(t, metav1.ConditionFalse, accepted.Status)
+	assert.Equal(t, string(gatewayv1.RouteReasonNoMatchingParent), accepted.Reason)
+	assert.True(t, meta.IsStatusConditionTrue(status.Parents[0].Conditions, string(gatewayv1.RouteConditionAccepted)))
+	assert.True(t, meta.IsStatusConditionTrue(status.Parents[1].Conditions, string(gatewayv1.RouteConditionAccepted)))
+	response := utils.CheckChatCompletionsWithURL(t, "http://127.0.0.1:8082/v1/chat/completions", modelRoute.Spec.ModelName, messages)
+	require.Equal(t, 200, response.StatusCode, "Expected HTTP 200 through the second Gateway")
+}
+
+// waitForModelRouteParents waits for the parents of the status of a ModelRoute of the test namespace to satisfy the
+// condition, and returns the status.
+func waitForModelRouteParents(t *testing.T, ctx context.Context, name string, condition func(parents []gatewayv1.RouteParentStatus) bool) networkingv1alpha1.ModelRouteStatus {
+	t.Helper()
+	timeoutCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	lw := utils.ListWatch(testCtx.KthenaClient.NetworkingV1alpha1().RESTClient(), "modelroutes", testNamespace, utils.ByName(name))
+	modelRoute, err := utils.WaitForCondition(timeoutCtx, lw, func(modelRoute *networkingv1alpha1.ModelRoute) (bool, error) {
+		return condition(modelRoute.Status.Parents), nil
+	})
+	require.NoError(t, err, "The parents of ModelRoute %s/%s were not reported", testNamespace, name)
+	return modelRoute.Status
+}
+
+// createExposedGateway creates a Gateway of the kthena-router listening on the port, adds the port to the
+// kthena-router Service and forwards it locally. Everything is undone when the test ends.
+func createExposedGateway(t *testing.T, ctx context.Context, name string, port int32) *gatewayv1.Gateway {
+	t.Helper()
+	t.Logf("Creating custom Gateway with port %d...", port)
+	customGateway := utils.LoadYAMLFromFile[gatewayv1.Gateway]("examples/kthena-router/Gateway.yaml")
+	customGateway.Namespace = kthenaNamespace
+	customGateway.Name = name
+	customGateway.Spec.Listeners[0].Port = gatewayv1.PortNumber(port)
+
+	createdGateway, err := testCtx.GatewayClient.GatewayV1().Gateways(kthenaNamespace).Create(ctx, customGateway, metav1.CreateOptions{})
+	require.NoError(t, err, "Failed to create custom Gateway")
+	t.Logf("Created Gateway: %s/%s", createdGateway.Namespace, createdGateway.Name)
+	t.Cleanup(func() {
+		if err := testCtx.GatewayClient.GatewayV1().Gateways(kthenaNamespace).Delete(context.Background(), createdGateway.Name, metav1.DeleteOptions{}); err != nil {
+			t.Logf("Warning: Failed to delete Gateway %s/%s: %v", createdGateway.Namespace, createdGateway.Name, err)
+		}
+	})
+
+	t.Logf("Updating kthena-router Service to add port %d...", port)
+	portName := fmt.Sprintf("http-%d", port)
+	svc, err := testCtx.KubeClient.CoreV1().Services(kthenaNamespace).Get(ctx, "kthena-router", metav1.GetOptions{})
+	require.NoError(t, err, "Failed to get kthena-router Service")
+	svc.Spec.Ports = append(svc.Spec.Ports, corev1.ServicePort{
+		Name:       portName,
+		Port:       port,
+		TargetPort: intstr.FromInt32(port),
+		Protocol:   corev1.ProtocolTCP,
+	})
+	_, err = testCtx.KubeClient.CoreV1().Services(kthenaNamespace).Update(ctx, svc, metav1.UpdateOptions{})
+	require.NoError(t, err, "Failed to update kthena-router Service")
+	t.Cleanup(func() {
+		cleanupCtx := context.Background()
+		svc, err := testCtx.KubeClient.CoreV1().Services(kthenaNamespace).Get(cleanupCtx, "kthena-router", metav1.GetOptions{})
+		if err != nil {
+			t.Logf("Warning: Failed to get Service for cleanup: %v", err)
+			return
+		}
+		var ports []corev1.ServicePort
+		for _, p := range svc.Spec.Ports {
+			if p.Name != portName {
+				ports = append(ports, p)
+			}
+		}
+		svc.Spec.Ports = ports
+		if _, err := testCtx.KubeClient.CoreV1().Services(kthenaNamespace).Update(cleanupCtx, svc, metav1.UpdateOptions{}); err != nil {
+			t.Logf("Warning: Failed to restore kthena-router Service during cleanup: %v", err)
+		}
+	})
+
+	t.Logf("Setting up port-forward for port %d...", port)
+	localPort := fmt.Sprint(port)
+	pf, err := utils.SetupPortForward(kthenaNamespace, "kthena-router", localPort, localPort)
+	require.NoError(t, err, "Failed to setup port-forward for %d", port)
+	t.Cleanup(func() {
+		if pf != nil {
+			pf.Close()
+		}
+	})
+	return createdGateway
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	networkingv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/networking/v1alpha1"
+	"github.com/volcano-sh/kthena/pkg/kthena-router/gatewayapi"
 	"github.com/volcano-sh/kthena/test/e2e/framework"
 	routercontext "github.com/volcano-sh/kthena/test/e2e/router/context"
 	"github.com/volcano-sh/kthena/test/e2e/utils"
@@ -217,8 +218,7 @@ func TestBothAPIsConfigured(t *testing.T) {
 
 	// Update backendRefs to point to the 7b InferencePool
 	if len(httpRoute.Spec.Rules) > 0 && len(httpRoute.Spec.Rules[0].BackendRefs) > 0 {
-		backendRefName := gatewayv1.ObjectName("deepseek-r1-7b")
-		httpRoute.Spec.Rules[0].BackendRefs[0].Name = backendRefName
+		httpRoute.Spec.Rules[0].BackendRefs[0].BackendRef = gatewayapi.InferencePoolBackendRef(inferencePool7b.Name)
 	}
 
 	createdHTTPRoute, err := testCtx.GatewayClient.GatewayV1().HTTPRoutes(testNamespace).Create(ctx, httpRoute, metav1.CreateOptions{})
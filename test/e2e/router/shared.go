@@ -80,6 +80,16 @@ func matchLabels(metricLabels []*dto.LabelPair, wantLabels map[string]string) bo
 	return true
 }
 
+// waitForModelRoute waits for a ModelRoute to be visible to the watches, like those of the router.
+func waitForModelRoute(t *testing.T, ctx context.Context, testCtx *routercontext.RouterTestContext, namespace, name string) {
+	t.Helper()
+	waitCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	lw := utils.ListWatch(testCtx.KthenaClient.NetworkingV1alpha1().RESTClient(), "modelroutes", namespace, utils.ByName(name))
+	_, err := utils.WaitForCondition(waitCtx, lw, utils.Exists[*networkingv1alpha1.ModelRoute])
+	require.NoError(t, err, "ModelRoute should be created")
+}
+
 // setupModelRouteWithGatewayAPI configures ModelRoute with ParentRefs to default Gateway if useGatewayAPI is true.
 func setupModelRouteWithGatewayAPI(modelRoute *networkingv1alpha1.ModelRoute, useGatewayAPI bool, kthenaNamespace string) {
 	if useGatewayAPI {
@@ -304,18 +314,8 @@ func TestModelRouteSubsetShared(t *testing.T, testCtx *routercontext.RouterTestC
 	require.NoError(t, err, "Failed to create Canary deployment v2")
 
 	// Wait for deployments to be ready
-	require.Eventually(t, func() bool {
-		deployV1, err := testCtx.KubeClient.AppsV1().Deployments(testNamespace).Get(ctx, "deepseek-r1-1-5b-v1", metav1.GetOptions{})
-		if err != nil {
-			return false
-		}
-		deployV2, err := testCtx.KubeClient.AppsV1().Deployments(testNamespace).Get(ctx, "deepseek-r1-1-5b-v2", metav1.GetOptions{})
-		if err != nil {
-			return false
-		}
-		return deployV1.Status.ReadyReplicas == *deployV1.Spec.Replicas &&
-			deployV2.Status.ReadyReplicas == *deployV2.Spec.Replicas
-	}, 5*time.Minute, 5*time.Second, "Canary deployments should be ready")
+	utils.WaitForDeploymentReady(t, ctx, testCtx.KubeClient, testNamespace, "deepseek-r1-1-5b-v1")
+	utils.WaitForDeploymentReady(t, ctx, testCtx.KubeClient, testNamespace, "deepseek-r1-1-5b-v2")
 
 	// Deploy Canary ModelServers from YAML file
 	canaryModelServers := utils.LoadMultiResourceYAMLFromFile[networkingv1alpha1.ModelServer]("examples/kthena-router/ModelServer-ds1.5b-Canary.yaml")
@@ -538,10 +538,7 @@ func TestModelRouteWithRateLimitShared(t *testing.T, testCtx *routercontext.Rout
 			}
 		})
 
-		require.Eventually(t, func() bool {
-			mr, err := testCtx.KthenaClient.NetworkingV1alpha1().ModelRoutes(testNamespace).Get(ctx, createdModelRoute.Name, metav1.GetOptions{})
-			return err == nil && mr != nil
-		}, 2*time.Minute, 2*time.Second, "ModelRoute should be created")
+		waitForModelRoute(t, ctx, testCtx, testNamespace, createdModelRoute.Name)
 
 		// First request: use CheckChatCompletions to handle router reconciliation
 		resp := utils.CheckChatCompletions(t, createdModelRoute.Spec.ModelName, standardMessage)
@@ -600,10 +597,7 @@ func TestModelRouteWithRateLimitShared(t *testing.T, testCtx *routercontext.Rout
 			}
 		})
 
-		require.Eventually(t, func() bool {
-			mr, err := testCtx.KthenaClient.NetworkingV1alpha1().ModelRoutes(testNamespace).Get(ctx, createdModelRoute.Name, metav1.GetOptions{})
-			return err == nil && mr != nil
-		}, 2*time.Minute, 2*time.Second, "ModelRoute should be created")
+		waitForModelRoute(t, ctx, testCtx, testNamespace, createdModelRoute.Name)
 
 		// First request: handle reconciliation and track tokens
 		resp := utils.CheckChatCompletions(t, createdModelRoute.Spec.ModelName, standardMessage)
@@ -669,10 +663,7 @@ func TestModelRouteWithRateLimitShared(t *testing.T, testCtx *routercontext.Rout
 			}
 		})
 
-		require.Eventually(t, func() bool {
-			mr, err := testCtx.KthenaClient.NetworkingV1alpha1().ModelRoutes(testNamespace).Get(ctx, createdModelRoute.Name, metav1.GetOptions{})
-			return err == nil && mr != nil
-		}, 2*time.Minute, 2*time.Second, "ModelRoute should be created")
+		waitForModelRoute(t, ctx, testCtx, testNamespace, createdModelRoute.Name)
 
 		// First request: handle reconciliation and track tokens
 		resp := utils.CheckChatCompletions(t, createdModelRoute.Spec.ModelName, standardMessage)
@@ -735,10 +726,7 @@ func TestModelRouteWithRateLimitShared(t *testing.T, testCtx *routercontext.Rout
 			}
 		})
 
-		require.Eventually(t, func() bool {
-			mr, err := testCtx.KthenaClient.NetworkingV1alpha1().ModelRoutes(testNamespace).Get(ctx, createdModelRoute.Name, metav1.GetOptions{})
-			return err == nil && mr != nil
-		}, 2*time.Minute, 2*time.Second, "ModelRoute should be created")
+		waitForModelRoute(t, ctx, testCtx, testNamespace, createdModelRoute.Name)
 
 		// Update ModelRoute to disable input token limit
 		createdModelRoute.Spec.RateLimit.InputTokensPerUnit = nil
@@ -45,6 +45,7 @@ kind load docker-image ${HUB}/kthena-router:${TAG} --name "${CLUSTER_NAME}"
 kind load docker-image ${HUB}/kthena-controller-manager:${TAG} --name "${CLUSTER_NAME}"
 kind load docker-image ${HUB}/downloader:${TAG} --name "${CLUSTER_NAME}"
 kind load docker-image ${HUB}/runtime:${TAG} --name "${CLUSTER_NAME}"
+kind load docker-image ${HUB}/kthena-mock-engine:${TAG} --name "${CLUSTER_NAME}"
 
 # Install cert-manager
 echo "Start to install cert-manager"
@@ -19,26 +19,21 @@ package utils
 import (
 	"context"
 	"testing"
-	"time"
 
 	"github.com/stretchr/testify/require"
 	clientset "github.com/volcano-sh/kthena/client-go/clientset/versioned"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/wait"
+	workloadv1alpha1 "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
 )
 
 // WaitForModelServingReady waits for a ModelServing to become ready by checking
 // if all expected replicas are available.
-func WaitForModelServingReady(t *testing.T, ctx context.Context, kthenaClient *clientset.Clientset, namespace, name string) {
+func WaitForModelServingReady(t *testing.T, ctx context.Context, kthenaClient clientset.Interface, namespace, name string) {
+	t.Helper()
 	t.Log("Waiting for ModelServing to be ready...")
-	timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	timeoutCtx, cancel := context.WithTimeout(ctx, DefaultWaitTimeout)
 	defer cancel()
-	err := wait.PollUntilContextTimeout(timeoutCtx, 5*time.Second, 5*time.Minute, true, func(ctx context.Context) (bool, error) {
-		ms, err := kthenaClient.WorkloadV1alpha1().ModelServings(namespace).Get(ctx, name, metav1.GetOptions{})
-		if err != nil {
-			t.Logf("Error getting ModelServing %s, retrying: %v", name, err)
-			return false, err
-		}
+	lw := ListWatch(kthenaClient.WorkloadV1alpha1().RESTClient(), "modelservings", namespace, ByName(name))
+	_, err := WaitForCondition(timeoutCtx, lw, func(ms *workloadv1alpha1.ModelServing) (bool, error) {
 		// Check if all replicas are available
 		expectedReplicas := int32(1)
 		if ms.Spec.Replicas != nil {
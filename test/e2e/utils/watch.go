@@ -0,0 +1,180 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+// DefaultWaitTimeout is the time the readiness helpers wait for a resource.
+const DefaultWaitTimeout = 5 * time.Minute
+
+// ListOption selects the objects of a ListWatch.
+type ListOption func(options *metav1.ListOptions)
+
+// ByName selects the object of the given name.
+func ByName(name string) ListOption {
+	return func(options *metav1.ListOptions) {
+		options.FieldSelector = fields.OneTermEqualSelector(metav1.ObjectNameField, name).String()
+	}
+}
+
+// ByLabels selects the objects matching a label selector, e.g. "app=router".
+func ByLabels(selector string) ListOption {
+	return func(options *metav1.ListOptions) {
+		options.LabelSelector = selector
+	}
+}
+
+// ByFields selects the objects matching a field selector, e.g. "involvedObject.uid=...".
+func ByFields(selector string) ListOption {
+	return func(options *metav1.ListOptions) {
+		options.FieldSelector = selector
+	}
+}
+
+// ListWatch lists and watches a resource of a namespace, all namespaces or a cluster-scoped resource if it is empty.
+// The client is the REST client of the API group of the resource, e.g.
+// kthenaClient.WorkloadV1alpha1().RESTClient() for "modelservings".
+func ListWatch(client cache.Getter, resource, namespace string, options ...ListOption) cache.ListerWatcher {
+	return cache.NewFilteredListWatchFromClient(client, resource, namespace, func(listOptions *metav1.ListOptions) {
+		for _, option := range options {
+			option(listOptions)
+		}
+	})
+}
+
+// newObject returns an empty object of the type T, a pointer to an API type.
+func newObject[T runtime.Object]() T {
+	var zero T
+	return reflect.New(reflect.TypeOf(zero).Elem()).Interface().(T)
+}
+
+// WaitForCondition watches the objects of lw until the condition holds for one of them, and returns it. The objects
+// existing when the wait starts are checked first, the condition returns at once if it already holds. The wait ends
+// with ctx, or with the first error of the condition.
+func WaitForCondition[T runtime.Object](ctx context.Context, lw cache.ListerWatcher, condition func(obj T) (bool, error)) (T, error) {
+	var zero T
+	event, err := watchtools.UntilWithSync(ctx, lw, newObject[T](), nil, func(event watch.Event) (bool, error) {
+		if event.Type != watch.Added && event.Type != watch.Modified {
+			return false, nil
+		}
+		obj, ok := event.Object.(T)
+		if !ok {
+			return false, nil
+		}
+		return condition(obj)
+	})
+	if err != nil {
+		return zero, err
+	}
+	return event.Object.(T), nil
+}
+
+// Exists is the condition of WaitForCondition waiting for an object to exist.
+func Exists[T runtime.Object](T) (bool, error) {
+	return true, nil
+}
+
+// WaitForDeletion watches the objects of lw until none is left, e.g. after the deletion of their owner.
+func WaitForDeletion[T runtime.Object](ctx context.Context, lw cache.ListerWatcher) error {
+	remaining := sets.New[string]()
+	precondition := func(store cache.Store) (bool, error) {
+		remaining.Insert(store.ListKeys()...)
+		return remaining.Len() == 0, nil
+	}
+	_, err := watchtools.UntilWithSync(ctx, lw, newObject[T](), precondition, func(event watch.Event) (bool, error) {
+		key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(event.Object)
+		if err != nil {
+			return false, err
+		}
+		switch event.Type {
+		case watch.Added, watch.Modified:
+			remaining.Insert(key)
+		case watch.Deleted:
+			remaining.Delete(key)
+		}
+		return remaining.Len() == 0, nil
+	})
+	return err
+}
+
+// IsPodReady reports whether a pod is running, ready and not being deleted.
+func IsPodReady(pod *corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// IsDeploymentReady reports whether all the replicas of the latest revision of a Deployment are ready.
+func IsDeploymentReady(deployment *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	return deployment.Status.ObservedGeneration >= deployment.Generation &&
+		deployment.Status.UpdatedReplicas == replicas &&
+		deployment.Status.ReadyReplicas == replicas
+}
+
+// WaitForDeploymentReady waits for all the replicas of the latest revision of a Deployment to be ready.
+func WaitForDeploymentReady(t *testing.T, ctx context.Context, kubeClient kubernetes.Interface, namespace, name string) {
+	t.Helper()
+	t.Logf("Waiting for Deployment %s/%s to be ready...", namespace, name)
+	timeoutCtx, cancel := context.WithTimeout(ctx, DefaultWaitTimeout)
+	defer cancel()
+	lw := ListWatch(kubeClient.AppsV1().RESTClient(), "deployments", namespace, ByName(name))
+	_, err := WaitForCondition(timeoutCtx, lw, func(deployment *appsv1.Deployment) (bool, error) {
+		return IsDeploymentReady(deployment), nil
+	})
+	require.NoError(t, err, "Deployment %s/%s did not become ready", namespace, name)
+}
+
+// WaitForPodReady waits for a pod matching the label selector to be ready, and returns it.
+func WaitForPodReady(t *testing.T, ctx context.Context, kubeClient kubernetes.Interface, namespace, labelSelector string) *corev1.Pod {
+	t.Helper()
+	t.Logf("Waiting for a pod %s in namespace %s to be ready...", labelSelector, namespace)
+	timeoutCtx, cancel := context.WithTimeout(ctx, DefaultWaitTimeout)
+	defer cancel()
+	lw := ListWatch(kubeClient.CoreV1().RESTClient(), "pods", namespace, ByLabels(labelSelector))
+	pod, err := WaitForCondition(timeoutCtx, lw, func(pod *corev1.Pod) (bool, error) {
+		return IsPodReady(pod), nil
+	})
+	require.NoError(t, err, "No pod %s in namespace %s became ready", labelSelector, namespace)
+	return pod
+}
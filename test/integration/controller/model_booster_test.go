@@ -0,0 +1,89 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	workload "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+)
+
+func TestModelBoosterGeneratedResources(t *testing.T) {
+	namespace := setUp(t)
+	ctx := context.Background()
+	booster := &workload.ModelBooster{
+		ObjectMeta: metav1.ObjectMeta{Name: "booster", Namespace: namespace},
+		Spec: workload.ModelBoosterSpec{
+			Backend: workload.ModelBackend{
+				Name:        "backend",
+				Type:        workload.ModelBackendTypeVLLM,
+				ModelURI:    "hf://Qwen/Qwen2.5-0.5B-Instruct",
+				MinReplicas: 1,
+				MaxReplicas: 1,
+				Workers: []workload.ModelWorker{{
+					Type:     workload.ModelWorkerTypeServer,
+					Image:    "vllm/vllm-openai:latest",
+					Replicas: 1,
+					Pods:     1,
+					Config:   apiextensionsv1.JSON{Raw: []byte(`{"served-model-name": "qwen"}`)},
+				}},
+			},
+		},
+	}
+	booster, err := kthenaClient.WorkloadV1alpha1().ModelBoosters(namespace).Create(ctx, booster, metav1.CreateOptions{})
+	require.NoError(t, err, "Failed to create ModelBooster")
+
+	// The ModelBooster generates a ModelServing, a ModelServer and a ModelRoute it owns.
+	var owners [][]metav1.OwnerReference
+	require.Eventually(t, func() bool {
+		servings, err := kthenaClient.WorkloadV1alpha1().ModelServings(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil || len(servings.Items) != 1 {
+			return false
+		}
+		servers, err := kthenaClient.NetworkingV1alpha1().ModelServers(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil || len(servers.Items) != 1 {
+			return false
+		}
+		routes, err := kthenaClient.NetworkingV1alpha1().ModelRoutes(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil || len(routes.Items) != 1 {
+			return false
+		}
+		owners = [][]metav1.OwnerReference{servings.Items[0].OwnerReferences, servers.Items[0].OwnerReferences, routes.Items[0].OwnerReferences}
+		return true
+	}, timeout, interval, "The resources of the ModelBooster were not generated")
+	for _, ownerReferences := range owners {
+		require.Len(t, ownerReferences, 1)
+		assert.Equal(t, booster.UID, ownerReferences[0].UID, "The generated resources should be owned by the ModelBooster")
+	}
+
+	// The ModelBooster becomes active once its ModelServing is available.
+	require.Eventually(t, func() bool {
+		readyPods(t, namespace)
+		booster, err := kthenaClient.WorkloadV1alpha1().ModelBoosters(namespace).Get(ctx, "booster", metav1.GetOptions{})
+		return err == nil && meta.IsStatusConditionTrue(booster.Status.Conditions, string(workload.ModelStatusConditionTypeActive))
+	}, timeout, interval, "The ModelBooster should be active once its ModelServing is available")
+	booster, err = kthenaClient.WorkloadV1alpha1().ModelBoosters(namespace).Get(ctx, "booster", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, meta.IsStatusConditionTrue(booster.Status.Conditions, string(workload.ModelStatusConditionTypeInitialized)))
+}
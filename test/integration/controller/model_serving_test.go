@@ -0,0 +1,183 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/ptr"
+
+	workload "github.com/volcano-sh/kthena/pkg/apis/workload/v1alpha1"
+)
+
+func TestModelServingGeneratedResources(t *testing.T) {
+	namespace := setUp(t)
+	ctx := context.Background()
+	ms, err := kthenaClient.WorkloadV1alpha1().ModelServings(namespace).Create(ctx, newModelServing(namespace, "generated", 2, 1), metav1.CreateOptions{})
+	require.NoError(t, err, "Failed to create ModelServing")
+
+	// Each of the 2 serving groups has an entry pod and a worker pod.
+	var pods []corev1.Pod
+	require.Eventually(t, func() bool {
+		list, err := kubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return false
+		}
+		pods = list.Items
+		return len(pods) == 4
+	}, timeout, interval, "The pods of the ModelServing were not created")
+	groups := map[string]int{}
+	for _, pod := range pods {
+		require.Len(t, pod.OwnerReferences, 1, "Pod %s should be owned by the ModelServing", pod.Name)
+		assert.Equal(t, ms.UID, pod.OwnerReferences[0].UID, "Pod %s should be owned by the ModelServing", pod.Name)
+		assert.Equal(t, ms.Name, pod.Labels[workload.ModelServingNameLabelKey])
+		assert.Equal(t, "prefill", pod.Labels[workload.RoleLabelKey])
+		assert.NotEmpty(t, pod.Labels[workload.RevisionLabelKey], "Pod %s should have a revision", pod.Name)
+		groups[pod.Labels[workload.GroupNameLabelKey]]++
+	}
+	assert.Equal(t, map[string]int{"generated-0": 2, "generated-1": 2}, groups)
+
+	// The roles with workers get a headless service selecting their pods.
+	require.Eventually(t, func() bool {
+		list, err := kubeClient.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+		return err == nil && len(list.Items) == 2
+	}, timeout, interval, "The headless services of the ModelServing were not created")
+	services, err := kubeClient.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	for _, svc := range services.Items {
+		assert.Equal(t, corev1.ClusterIPNone, svc.Spec.ClusterIP, "Service %s should be headless", svc.Name)
+		assert.Equal(t, svc.Labels[workload.GroupNameLabelKey], svc.Spec.Selector[workload.GroupNameLabelKey])
+		require.Len(t, svc.OwnerReferences, 1, "Service %s should be owned by the ModelServing", svc.Name)
+		assert.Equal(t, ms.UID, svc.OwnerReferences[0].UID)
+	}
+}
+
+func TestModelServingStatusConditions(t *testing.T) {
+	namespace := setUp(t)
+	ctx := context.Background()
+	_, err := kthenaClient.WorkloadV1alpha1().ModelServings(namespace).Create(ctx, newModelServing(namespace, "conditions", 2, 0), metav1.CreateOptions{})
+	require.NoError(t, err, "Failed to create ModelServing")
+
+	// The serving groups progress until their pods are ready.
+	require.Eventually(t, func() bool {
+		ms, err := kthenaClient.WorkloadV1alpha1().ModelServings(namespace).Get(ctx, "conditions", metav1.GetOptions{})
+		return err == nil && ms.Status.Replicas == 2 && ms.Status.AvailableReplicas == 0 &&
+			meta.IsStatusConditionTrue(ms.Status.Conditions, string(workload.ModelServingProgressing))
+	}, timeout, interval, "The ModelServing should be progressing")
+
+	require.Eventually(t, func() bool {
+		readyPods(t, namespace)
+		ms, err := kthenaClient.WorkloadV1alpha1().ModelServings(namespace).Get(ctx, "conditions", metav1.GetOptions{})
+		return err == nil && ms.Status.AvailableReplicas == 2 &&
+			meta.IsStatusConditionTrue(ms.Status.Conditions, string(workload.ModelServingAvailable))
+	}, timeout, interval, "The ModelServing should be available once its pods are ready")
+	ms, err := kthenaClient.WorkloadV1alpha1().ModelServings(namespace).Get(ctx, "conditions", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.False(t, meta.IsStatusConditionTrue(ms.Status.Conditions, string(workload.ModelServingProgressing)),
+		"Available and Progressing should not be true at the same time")
+	assert.Equal(t, ms.Status.UpdateRevision, ms.Status.CurrentRevision)
+}
+
+func TestModelServingRollingUpdate(t *testing.T) {
+	namespace := setUp(t)
+	ctx := context.Background()
+	const replicas = 3
+	_, err := kthenaClient.WorkloadV1alpha1().ModelServings(namespace).Create(ctx, newModelServing(namespace, "rollout", replicas, 0), metav1.CreateOptions{})
+	require.NoError(t, err, "Failed to create ModelServing")
+	var initialRevision string
+	require.Eventually(t, func() bool {
+		readyPods(t, namespace)
+		ms, err := kthenaClient.WorkloadV1alpha1().ModelServings(namespace).Get(ctx, "rollout", metav1.GetOptions{})
+		if err != nil {
+			return false
+		}
+		initialRevision = ms.Status.UpdateRevision
+		return ms.Status.AvailableReplicas == replicas
+	}, timeout, interval, "The ModelServing should be available")
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		ms, err := kthenaClient.WorkloadV1alpha1().ModelServings(namespace).Get(ctx, "rollout", metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		ms.Spec.Template.Roles[0].EntryTemplate.Spec.Containers[0].Image = "nginx:alpine"
+		_, err = kthenaClient.WorkloadV1alpha1().ModelServings(namespace).Update(ctx, ms, metav1.UpdateOptions{})
+		return err
+	})
+	require.NoError(t, err, "Failed to update the image of the ModelServing")
+
+	// The serving groups are replaced one at a time, the default maxUnavailable being 1.
+	maxUnavailable := int32(0)
+	require.Eventually(t, func() bool {
+		readyPods(t, namespace)
+		ms, err := kthenaClient.WorkloadV1alpha1().ModelServings(namespace).Get(ctx, "rollout", metav1.GetOptions{})
+		if err != nil {
+			return false
+		}
+		maxUnavailable = max(maxUnavailable, replicas-ms.Status.AvailableReplicas)
+		return ms.Status.UpdateRevision != initialRevision && ms.Status.UpdatedReplicas == replicas &&
+			ms.Status.AvailableReplicas == replicas && ms.Status.CurrentRevision == ms.Status.UpdateRevision
+	}, timeout, interval, "The ModelServing should be rolled out")
+	assert.LessOrEqual(t, maxUnavailable, int32(1), "More serving groups than maxUnavailable were unavailable")
+
+	pods, err := kubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, pods.Items, replicas)
+	for _, pod := range pods.Items {
+		assert.Equal(t, "nginx:alpine", pod.Spec.Containers[0].Image, "Pod %s should have been updated", pod.Name)
+		assert.NotEqual(t, initialRevision, pod.Labels[workload.RevisionLabelKey], "Pod %s should have been updated", pod.Name)
+	}
+}
+
+// newModelServing returns a ModelServing with the given number of serving groups, each with a prefill role of one
+// entry pod and the given number of worker pods.
+func newModelServing(namespace, name string, replicas, workers int32) *workload.ModelServing {
+	template := func(container string) workload.PodTemplateSpec {
+		return workload.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name:  container,
+					Image: "nginx:latest",
+					Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 80}},
+				}},
+			},
+		}
+	}
+	role := workload.Role{
+		Name:           "prefill",
+		Replicas:       ptr.To[int32](1),
+		EntryTemplate:  template("entry"),
+		WorkerReplicas: workers,
+	}
+	if workers > 0 {
+		role.WorkerTemplate = ptr.To(template("worker"))
+	}
+	return &workload.ModelServing{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: workload.ModelServingSpec{
+			Replicas: ptr.To(replicas),
+			Template: workload.ServingGroup{Roles: []workload.Role{role}},
+		},
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controller holds the integration tests of the controllers. They run against a real API server and etcd
+// started by envtest, without a cluster: no scheduler, kubelet or garbage collector is running, the tests drive the
+// status of the pods themselves.
+//
+// The tests are skipped unless KUBEBUILDER_ASSETS points to the envtest binaries, run them with
+// `make test-integration`.
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apiextclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	clientset "github.com/volcano-sh/kthena/client-go/clientset/versioned"
+	"github.com/volcano-sh/kthena/pkg/controller/options"
+	modelbooster "github.com/volcano-sh/kthena/pkg/model-booster-controller/controller"
+	modelserving "github.com/volcano-sh/kthena/pkg/model-serving-controller/controller"
+)
+
+const (
+	// timeout and interval bound the wait for the controllers to reconcile a change.
+	timeout  = 30 * time.Second
+	interval = 100 * time.Millisecond
+)
+
+var (
+	// kubeClient and kthenaClient are nil when the API server couldn't be started, the tests are then skipped.
+	kubeClient   kubernetes.Interface
+	kthenaClient clientset.Interface
+)
+
+func TestMain(m *testing.M) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		fmt.Println("KUBEBUILDER_ASSETS is not set, skipping the controller integration tests")
+		os.Exit(m.Run())
+	}
+
+	crds := filepath.Join("..", "..", "..", "charts", "kthena", "charts")
+	testEnv := &envtest.Environment{
+		CRDDirectoryPaths: []string{
+			filepath.Join(crds, "workload", "crds"),
+			filepath.Join(crds, "networking", "crds"),
+		},
+		ErrorIfCRDPathMissing: true,
+	}
+	config, err := testEnv.Start()
+	if err != nil {
+		fmt.Printf("Failed to start the test environment: %v\n", err)
+		os.Exit(1)
+	}
+
+	kubeClient = kubernetes.NewForConfigOrDie(config)
+	kthenaClient = clientset.NewForConfigOrDie(config)
+	apiextClient := apiextclient.NewForConfigOrDie(config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	// Without a volcano client, gang scheduling is disabled.
+	msc, err := modelserving.NewModelServingController(kubeClient, kthenaClient, nil, apiextClient, options.InformerOptions{})
+	if err != nil {
+		fmt.Printf("Failed to create the ModelServing controller: %v\n", err)
+		_ = testEnv.Stop()
+		os.Exit(1)
+	}
+	go msc.Run(ctx, 2)
+	mbc := modelbooster.NewModelBoosterController(kubeClient, kthenaClient, options.InformerOptions{})
+	go mbc.Run(ctx, 1)
+
+	code := m.Run()
+
+	cancel()
+	if err := testEnv.Stop(); err != nil {
+		fmt.Printf("Failed to stop the test environment: %v\n", err)
+	}
+	os.Exit(code)
+}
+
+// setUp skips the test without an API server, otherwise it returns a new namespace for the test.
+func setUp(t *testing.T) string {
+	t.Helper()
+	if kubeClient == nil {
+		t.Skip("the test environment is not available")
+	}
+	namespace := "integration-" + rand.String(5)
+	_, err := kubeClient.CoreV1().Namespaces().Create(context.Background(), &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: namespace},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err, "Failed to create namespace %s", namespace)
+	return namespace
+}
+
+// readyPods marks the pods of the namespace running and ready, as the kubelet would.
+func readyPods(t *testing.T, namespace string) {
+	t.Helper()
+	ctx := context.Background()
+	pods, err := kubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	require.NoError(t, err, "Failed to list the pods of namespace %s", namespace)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodRunning {
+			continue
+		}
+		pod.Status.Phase = corev1.PodRunning
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type:               corev1.PodReady,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+		}}
+		// The pod may have been updated or deleted since it was listed, it is marked on the next call.
+		_, _ = kubeClient.CoreV1().Pods(namespace).UpdateStatus(ctx, pod, metav1.UpdateOptions{})
+	}
+}